@@ -37,9 +37,14 @@ type Client interface {
 	SetOptionAsync(types.RequestSetOption, ResponseCallback) *ReqRes
 	DeliverTxAsync(types.RequestDeliverTx, ResponseCallback) *ReqRes
 	CheckTxAsync(types.RequestCheckTx, ResponseCallback) *ReqRes
+	CheckTxBatchAsync([]types.RequestCheckTx, []ResponseCallback) []*ReqRes
 	QueryAsync(types.RequestQuery, ResponseCallback) *ReqRes
 	CommitAsync(ResponseCallback) *ReqRes
 	InitChainAsync(types.RequestInitChain, ResponseCallback) *ReqRes
+	PrepareProposalAsync(ocabci.RequestPrepareProposal, ResponseCallback) *ReqRes
+	ProcessProposalAsync(ocabci.RequestProcessProposal, ResponseCallback) *ReqRes
+	ExtendVoteAsync(ocabci.RequestExtendVote, ResponseCallback) *ReqRes
+	VerifyVoteExtensionAsync(ocabci.RequestVerifyVoteExtension, ResponseCallback) *ReqRes
 	BeginBlockAsync(ocabci.RequestBeginBlock, ResponseCallback) *ReqRes
 	EndBlockAsync(types.RequestEndBlock, ResponseCallback) *ReqRes
 	BeginRecheckTxAsync(ocabci.RequestBeginRecheckTx, ResponseCallback) *ReqRes
@@ -58,6 +63,10 @@ type Client interface {
 	QuerySync(types.RequestQuery) (*types.ResponseQuery, error)
 	CommitSync() (*types.ResponseCommit, error)
 	InitChainSync(types.RequestInitChain) (*types.ResponseInitChain, error)
+	PrepareProposalSync(ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error)
+	ProcessProposalSync(ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error)
+	ExtendVoteSync(ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error)
+	VerifyVoteExtensionSync(ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error)
 	BeginBlockSync(ocabci.RequestBeginBlock) (*types.ResponseBeginBlock, error)
 	EndBlockSync(types.RequestEndBlock) (*types.ResponseEndBlock, error)
 	BeginRecheckTxSync(ocabci.RequestBeginRecheckTx) (*ocabci.ResponseBeginRecheckTx, error)
@@ -192,6 +192,16 @@ func (cli *grpcClient) CheckTxAsync(params types.RequestCheckTx, cb ResponseCall
 	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_CheckTx{CheckTx: res}}, cb)
 }
 
+// CheckTxBatchAsync checks the txs one by one, the gRPC ABCI having no batch
+// of CheckTx.
+func (cli *grpcClient) CheckTxBatchAsync(reqs []types.RequestCheckTx, cbs []ResponseCallback) []*ReqRes {
+	reqRess := make([]*ReqRes, len(reqs))
+	for i, req := range reqs {
+		reqRess[i] = cli.CheckTxAsync(req, cbs[i])
+	}
+	return reqRess
+}
+
 func (cli *grpcClient) QueryAsync(params types.RequestQuery, cb ResponseCallback) *ReqRes {
 	req := ocabci.ToRequestQuery(params)
 	res, err := cli.client.Query(context.Background(), req.GetQuery(), grpc.WaitForReady(true))
@@ -219,6 +229,42 @@ func (cli *grpcClient) InitChainAsync(params types.RequestInitChain, cb Response
 	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_InitChain{InitChain: res}}, cb)
 }
 
+func (cli *grpcClient) PrepareProposalAsync(params ocabci.RequestPrepareProposal, cb ResponseCallback) *ReqRes {
+	req := ocabci.ToRequestPrepareProposal(params)
+	res, err := cli.client.PrepareProposal(context.Background(), req.GetPrepareProposal(), grpc.WaitForReady(true))
+	if err != nil {
+		cli.StopForError(err)
+	}
+	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_PrepareProposal{PrepareProposal: res}}, cb)
+}
+
+func (cli *grpcClient) ProcessProposalAsync(params ocabci.RequestProcessProposal, cb ResponseCallback) *ReqRes {
+	req := ocabci.ToRequestProcessProposal(params)
+	res, err := cli.client.ProcessProposal(context.Background(), req.GetProcessProposal(), grpc.WaitForReady(true))
+	if err != nil {
+		cli.StopForError(err)
+	}
+	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_ProcessProposal{ProcessProposal: res}}, cb)
+}
+
+func (cli *grpcClient) ExtendVoteAsync(params ocabci.RequestExtendVote, cb ResponseCallback) *ReqRes {
+	req := ocabci.ToRequestExtendVote(params)
+	res, err := cli.client.ExtendVote(context.Background(), req.GetExtendVote(), grpc.WaitForReady(true))
+	if err != nil {
+		cli.StopForError(err)
+	}
+	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_ExtendVote{ExtendVote: res}}, cb)
+}
+
+func (cli *grpcClient) VerifyVoteExtensionAsync(params ocabci.RequestVerifyVoteExtension, cb ResponseCallback) *ReqRes {
+	req := ocabci.ToRequestVerifyVoteExtension(params)
+	res, err := cli.client.VerifyVoteExtension(context.Background(), req.GetVerifyVoteExtension(), grpc.WaitForReady(true))
+	if err != nil {
+		cli.StopForError(err)
+	}
+	return cli.finishAsyncCall(req, &ocabci.Response{Value: &ocabci.Response_VerifyVoteExtension{VerifyVoteExtension: res}}, cb)
+}
+
 func (cli *grpcClient) BeginBlockAsync(params ocabci.RequestBeginBlock, cb ResponseCallback) *ReqRes {
 	req := ocabci.ToRequestBeginBlock(params)
 	res, err := cli.client.BeginBlock(context.Background(), req.GetBeginBlock(), grpc.WaitForReady(true))
@@ -366,6 +412,30 @@ func (cli *grpcClient) InitChainSync(params types.RequestInitChain) (*types.Resp
 	return reqres.Response.GetInitChain(), cli.Error()
 }
 
+func (cli *grpcClient) PrepareProposalSync(params ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error) {
+	reqres := cli.PrepareProposalAsync(params, nil)
+	reqres.Wait()
+	return reqres.Response.GetPrepareProposal(), cli.Error()
+}
+
+func (cli *grpcClient) ProcessProposalSync(params ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error) {
+	reqres := cli.ProcessProposalAsync(params, nil)
+	reqres.Wait()
+	return reqres.Response.GetProcessProposal(), cli.Error()
+}
+
+func (cli *grpcClient) ExtendVoteSync(params ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error) {
+	reqres := cli.ExtendVoteAsync(params, nil)
+	reqres.Wait()
+	return reqres.Response.GetExtendVote(), cli.Error()
+}
+
+func (cli *grpcClient) VerifyVoteExtensionSync(params ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error) {
+	reqres := cli.VerifyVoteExtensionAsync(params, nil)
+	reqres.Wait()
+	return reqres.Response.GetVerifyVoteExtension(), cli.Error()
+}
+
 func (cli *grpcClient) BeginBlockSync(params ocabci.RequestBeginBlock) (*types.ResponseBeginBlock, error) {
 	reqres := cli.BeginBlockAsync(params, nil)
 	reqres.Wait()
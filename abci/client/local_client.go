@@ -1,6 +1,8 @@
 package abcicli
 
 import (
+	"fmt"
+
 	"github.com/tendermint/tendermint/abci/types"
 
 	ocabci "github.com/Finschia/ostracon/abci/types"
@@ -119,6 +121,35 @@ func (app *localClient) CheckTxAsync(req types.RequestCheckTx, cb ResponseCallba
 	return reqRes
 }
 
+// CheckTxBatchAsync checks the txs in one call if the application is a
+// BatchApplication, or one by one otherwise.
+func (app *localClient) CheckTxBatchAsync(reqs []types.RequestCheckTx, cbs []ResponseCallback) []*ReqRes {
+	reqRess := make([]*ReqRes, len(reqs))
+	for i, req := range reqs {
+		reqRess[i] = NewReqRes(ocabci.ToRequestCheckTx(req), cbs[i])
+	}
+
+	batchApp, ok := app.Application.(ocabci.BatchApplication)
+	if !ok {
+		for i, req := range reqs {
+			reqRes := reqRess[i]
+			app.Application.CheckTxAsync(req, func(r ocabci.ResponseCheckTx) {
+				app.done(reqRes, ocabci.ToResponseCheckTx(r))
+			})
+		}
+		return reqRess
+	}
+	batchApp.CheckTxBatchAsync(reqs, func(rs []ocabci.ResponseCheckTx) {
+		if len(rs) != len(reqs) {
+			panic(fmt.Sprintf("%d responses to %d CheckTx requests", len(rs), len(reqs)))
+		}
+		for i, r := range rs {
+			app.done(reqRess[i], ocabci.ToResponseCheckTx(r))
+		}
+	})
+	return reqRess
+}
+
 func (app *localClient) QueryAsync(req types.RequestQuery, cb ResponseCallback) *ReqRes {
 	app.mtx.Lock()
 	defer app.mtx.Unlock()
@@ -146,6 +177,42 @@ func (app *localClient) InitChainAsync(req types.RequestInitChain, cb ResponseCa
 	return app.done(reqRes, ocabci.ToResponseInitChain(res))
 }
 
+func (app *localClient) PrepareProposalAsync(req ocabci.RequestPrepareProposal, cb ResponseCallback) *ReqRes {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	reqRes := NewReqRes(ocabci.ToRequestPrepareProposal(req), cb)
+	res := app.Application.PrepareProposal(req)
+	return app.done(reqRes, ocabci.ToResponsePrepareProposal(res))
+}
+
+func (app *localClient) ProcessProposalAsync(req ocabci.RequestProcessProposal, cb ResponseCallback) *ReqRes {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	reqRes := NewReqRes(ocabci.ToRequestProcessProposal(req), cb)
+	res := app.Application.ProcessProposal(req)
+	return app.done(reqRes, ocabci.ToResponseProcessProposal(res))
+}
+
+func (app *localClient) ExtendVoteAsync(req ocabci.RequestExtendVote, cb ResponseCallback) *ReqRes {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	reqRes := NewReqRes(ocabci.ToRequestExtendVote(req), cb)
+	res := app.Application.ExtendVote(req)
+	return app.done(reqRes, ocabci.ToResponseExtendVote(res))
+}
+
+func (app *localClient) VerifyVoteExtensionAsync(req ocabci.RequestVerifyVoteExtension, cb ResponseCallback) *ReqRes {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	reqRes := NewReqRes(ocabci.ToRequestVerifyVoteExtension(req), cb)
+	res := app.Application.VerifyVoteExtension(req)
+	return app.done(reqRes, ocabci.ToResponseVerifyVoteExtension(res))
+}
+
 func (app *localClient) BeginBlockAsync(req ocabci.RequestBeginBlock, cb ResponseCallback) *ReqRes {
 	app.mtx.Lock()
 	defer app.mtx.Unlock()
@@ -290,6 +357,38 @@ func (app *localClient) InitChainSync(req types.RequestInitChain) (*types.Respon
 	return &res, nil
 }
 
+func (app *localClient) PrepareProposalSync(req ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.PrepareProposal(req)
+	return &res, nil
+}
+
+func (app *localClient) ProcessProposalSync(req ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.ProcessProposal(req)
+	return &res, nil
+}
+
+func (app *localClient) ExtendVoteSync(req ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.ExtendVote(req)
+	return &res, nil
+}
+
+func (app *localClient) VerifyVoteExtensionSync(req ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.VerifyVoteExtension(req)
+	return &res, nil
+}
+
 func (app *localClient) BeginBlockSync(req ocabci.RequestBeginBlock) (*types.ResponseBeginBlock, error) {
 	app.mtx.Lock()
 	defer app.mtx.Unlock()
@@ -48,6 +48,8 @@ func TestLocalClientCalls(t *testing.T) {
 	c.SetOptionAsync(types.RequestSetOption{}, getResponseCallback(t))
 	c.DeliverTxAsync(types.RequestDeliverTx{}, getResponseCallback(t))
 	c.CheckTxAsync(types.RequestCheckTx{}, getResponseCallback(t))
+	c.CheckTxBatchAsync([]types.RequestCheckTx{{}, {}},
+		[]ResponseCallback{getResponseCallback(t), getResponseCallback(t)})
 	c.QueryAsync(types.RequestQuery{}, getResponseCallback(t))
 	c.CommitAsync(getResponseCallback(t))
 	c.InitChainAsync(types.RequestInitChain{}, getResponseCallback(t))
@@ -111,3 +113,43 @@ func TestLocalClientCalls(t *testing.T) {
 	_, err = c.ApplySnapshotChunkSync(types.RequestApplySnapshotChunk{})
 	require.NoError(t, err)
 }
+
+type batchApp struct {
+	ocabci.BaseApplication
+	batches int
+}
+
+func (app *batchApp) CheckTxBatchAsync(reqs []types.RequestCheckTx, callback ocabci.CheckTxBatchCallback) {
+	app.batches++
+	res := make([]ocabci.ResponseCheckTx, len(reqs))
+	for i, req := range reqs {
+		res[i] = ocabci.ResponseCheckTx{GasWanted: int64(len(req.Tx))}
+	}
+	callback(res)
+}
+
+func TestLocalClientCheckTxBatch(t *testing.T) {
+	app := &batchApp{}
+	c := NewLocalClient(nil, app)
+
+	var globalCbs int
+	c.SetGlobalCallback(func(*ocabci.Request, *ocabci.Response) {
+		globalCbs++
+	})
+
+	// the txs are checked in one call, the responses in the order of the requests
+	var gasWanted []int64
+	cb := func(res *ocabci.Response) {
+		gasWanted = append(gasWanted, res.GetCheckTx().GasWanted)
+	}
+	reqRess := c.CheckTxBatchAsync(
+		[]types.RequestCheckTx{{Tx: []byte("a")}, {Tx: []byte("bb")}, {Tx: []byte("ccc")}},
+		[]ResponseCallback{cb, cb, cb})
+	require.Equal(t, 1, app.batches)
+	require.Equal(t, []int64{1, 2, 3}, gasWanted)
+	require.Equal(t, 3, globalCbs)
+	for i, reqRes := range reqRess {
+		reqRes.Wait()
+		require.Equal(t, int64(i+1), reqRes.Response.GetCheckTx().GasWanted)
+	}
+}
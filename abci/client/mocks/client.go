@@ -160,6 +160,22 @@ func (_m *Client) CheckTxAsync(_a0 types.RequestCheckTx, _a1 abcicli.ResponseCal
 	return r0
 }
 
+// CheckTxBatchAsync provides a mock function with given fields: _a0, _a1
+func (_m *Client) CheckTxBatchAsync(_a0 []types.RequestCheckTx, _a1 []abcicli.ResponseCallback) []*abcicli.ReqRes {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*abcicli.ReqRes
+	if rf, ok := ret.Get(0).(func([]types.RequestCheckTx, []abcicli.ResponseCallback) []*abcicli.ReqRes); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*abcicli.ReqRes)
+		}
+	}
+
+	return r0
+}
+
 // CheckTxSync provides a mock function with given fields: _a0
 func (_m *Client) CheckTxSync(_a0 types.RequestCheckTx) (*abcitypes.ResponseCheckTx, error) {
 	ret := _m.Called(_a0)
@@ -410,6 +426,48 @@ func (_m *Client) Error() error {
 	return r0
 }
 
+// ExtendVoteAsync provides a mock function with given fields: _a0, _a1
+func (_m *Client) ExtendVoteAsync(_a0 abcitypes.RequestExtendVote, _a1 abcicli.ResponseCallback) *abcicli.ReqRes {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *abcicli.ReqRes
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestExtendVote, abcicli.ResponseCallback) *abcicli.ReqRes); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcicli.ReqRes)
+		}
+	}
+
+	return r0
+}
+
+// ExtendVoteSync provides a mock function with given fields: _a0
+func (_m *Client) ExtendVoteSync(_a0 abcitypes.RequestExtendVote) (*abcitypes.ResponseExtendVote, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *abcitypes.ResponseExtendVote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestExtendVote) (*abcitypes.ResponseExtendVote, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestExtendVote) *abcitypes.ResponseExtendVote); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcitypes.ResponseExtendVote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(abcitypes.RequestExtendVote) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FlushAsync provides a mock function with given fields: _a0
 func (_m *Client) FlushAsync(_a0 abcicli.ResponseCallback) *abcicli.ReqRes {
 	ret := _m.Called(_a0)
@@ -725,6 +783,90 @@ func (_m *Client) OnStop() {
 	_m.Called()
 }
 
+// PrepareProposalAsync provides a mock function with given fields: _a0, _a1
+func (_m *Client) PrepareProposalAsync(_a0 abcitypes.RequestPrepareProposal, _a1 abcicli.ResponseCallback) *abcicli.ReqRes {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *abcicli.ReqRes
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestPrepareProposal, abcicli.ResponseCallback) *abcicli.ReqRes); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcicli.ReqRes)
+		}
+	}
+
+	return r0
+}
+
+// PrepareProposalSync provides a mock function with given fields: _a0
+func (_m *Client) PrepareProposalSync(_a0 abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *abcitypes.ResponsePrepareProposal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestPrepareProposal) (*abcitypes.ResponsePrepareProposal, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestPrepareProposal) *abcitypes.ResponsePrepareProposal); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcitypes.ResponsePrepareProposal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(abcitypes.RequestPrepareProposal) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProcessProposalAsync provides a mock function with given fields: _a0, _a1
+func (_m *Client) ProcessProposalAsync(_a0 abcitypes.RequestProcessProposal, _a1 abcicli.ResponseCallback) *abcicli.ReqRes {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *abcicli.ReqRes
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestProcessProposal, abcicli.ResponseCallback) *abcicli.ReqRes); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcicli.ReqRes)
+		}
+	}
+
+	return r0
+}
+
+// ProcessProposalSync provides a mock function with given fields: _a0
+func (_m *Client) ProcessProposalSync(_a0 abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *abcitypes.ResponseProcessProposal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestProcessProposal) (*abcitypes.ResponseProcessProposal, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestProcessProposal) *abcitypes.ResponseProcessProposal); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcitypes.ResponseProcessProposal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(abcitypes.RequestProcessProposal) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryAsync provides a mock function with given fields: _a0, _a1
 func (_m *Client) QueryAsync(_a0 types.RequestQuery, _a1 abcicli.ResponseCallback) *abcicli.ReqRes {
 	ret := _m.Called(_a0, _a1)
@@ -891,6 +1033,48 @@ func (_m *Client) String() string {
 	return r0
 }
 
+// VerifyVoteExtensionAsync provides a mock function with given fields: _a0, _a1
+func (_m *Client) VerifyVoteExtensionAsync(_a0 abcitypes.RequestVerifyVoteExtension, _a1 abcicli.ResponseCallback) *abcicli.ReqRes {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *abcicli.ReqRes
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestVerifyVoteExtension, abcicli.ResponseCallback) *abcicli.ReqRes); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcicli.ReqRes)
+		}
+	}
+
+	return r0
+}
+
+// VerifyVoteExtensionSync provides a mock function with given fields: _a0
+func (_m *Client) VerifyVoteExtensionSync(_a0 abcitypes.RequestVerifyVoteExtension) (*abcitypes.ResponseVerifyVoteExtension, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *abcitypes.ResponseVerifyVoteExtension
+	var r1 error
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestVerifyVoteExtension) (*abcitypes.ResponseVerifyVoteExtension, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestVerifyVoteExtension) *abcitypes.ResponseVerifyVoteExtension); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*abcitypes.ResponseVerifyVoteExtension)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(abcitypes.RequestVerifyVoteExtension) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClient(t interface {
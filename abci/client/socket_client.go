@@ -250,6 +250,16 @@ func (cli *socketClient) CheckTxAsync(req types.RequestCheckTx, cb ResponseCallb
 	return cli.queueRequest(ocabci.ToRequestCheckTx(req), cb)
 }
 
+// CheckTxBatchAsync queues the CheckTx requests, flushed at once.
+func (cli *socketClient) CheckTxBatchAsync(reqs []types.RequestCheckTx, cbs []ResponseCallback) []*ReqRes {
+	reqRess := make([]*ReqRes, len(reqs))
+	for i, req := range reqs {
+		reqRess[i] = cli.queueRequest(ocabci.ToRequestCheckTx(req), cbs[i])
+	}
+	cli.queueRequest(ocabci.ToRequestFlush(), nil)
+	return reqRess
+}
+
 func (cli *socketClient) QueryAsync(req types.RequestQuery, cb ResponseCallback) *ReqRes {
 	return cli.queueRequest(ocabci.ToRequestQuery(req), cb)
 }
@@ -262,6 +272,22 @@ func (cli *socketClient) InitChainAsync(req types.RequestInitChain, cb ResponseC
 	return cli.queueRequest(ocabci.ToRequestInitChain(req), cb)
 }
 
+func (cli *socketClient) PrepareProposalAsync(req ocabci.RequestPrepareProposal, cb ResponseCallback) *ReqRes {
+	return cli.queueRequest(ocabci.ToRequestPrepareProposal(req), cb)
+}
+
+func (cli *socketClient) ProcessProposalAsync(req ocabci.RequestProcessProposal, cb ResponseCallback) *ReqRes {
+	return cli.queueRequest(ocabci.ToRequestProcessProposal(req), cb)
+}
+
+func (cli *socketClient) ExtendVoteAsync(req ocabci.RequestExtendVote, cb ResponseCallback) *ReqRes {
+	return cli.queueRequest(ocabci.ToRequestExtendVote(req), cb)
+}
+
+func (cli *socketClient) VerifyVoteExtensionAsync(req ocabci.RequestVerifyVoteExtension, cb ResponseCallback) *ReqRes {
+	return cli.queueRequest(ocabci.ToRequestVerifyVoteExtension(req), cb)
+}
+
 func (cli *socketClient) BeginBlockAsync(req ocabci.RequestBeginBlock, cb ResponseCallback) *ReqRes {
 	return cli.queueRequest(ocabci.ToRequestBeginBlock(req), cb)
 }
@@ -377,6 +403,42 @@ func (cli *socketClient) InitChainSync(req types.RequestInitChain) (*types.Respo
 	return reqres.Response.GetInitChain(), cli.Error()
 }
 
+func (cli *socketClient) PrepareProposalSync(req ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error) {
+	reqres := cli.queueRequest(ocabci.ToRequestPrepareProposal(req), nil)
+	if _, err := cli.FlushSync(); err != nil {
+		return nil, err
+	}
+
+	return reqres.Response.GetPrepareProposal(), cli.Error()
+}
+
+func (cli *socketClient) ProcessProposalSync(req ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error) {
+	reqres := cli.queueRequest(ocabci.ToRequestProcessProposal(req), nil)
+	if _, err := cli.FlushSync(); err != nil {
+		return nil, err
+	}
+
+	return reqres.Response.GetProcessProposal(), cli.Error()
+}
+
+func (cli *socketClient) ExtendVoteSync(req ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error) {
+	reqres := cli.queueRequest(ocabci.ToRequestExtendVote(req), nil)
+	if _, err := cli.FlushSync(); err != nil {
+		return nil, err
+	}
+
+	return reqres.Response.GetExtendVote(), cli.Error()
+}
+
+func (cli *socketClient) VerifyVoteExtensionSync(req ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error) {
+	reqres := cli.queueRequest(ocabci.ToRequestVerifyVoteExtension(req), nil)
+	if _, err := cli.FlushSync(); err != nil {
+		return nil, err
+	}
+
+	return reqres.Response.GetVerifyVoteExtension(), cli.Error()
+}
+
 func (cli *socketClient) BeginBlockSync(req ocabci.RequestBeginBlock) (*types.ResponseBeginBlock, error) {
 	reqres := cli.queueRequest(ocabci.ToRequestBeginBlock(req), nil)
 	if _, err := cli.FlushSync(); err != nil {
@@ -513,6 +575,14 @@ func resMatchesReq(req *ocabci.Request, res *ocabci.Response) (ok bool) {
 		_, ok = res.Value.(*ocabci.Response_Query)
 	case *ocabci.Request_InitChain:
 		_, ok = res.Value.(*ocabci.Response_InitChain)
+	case *ocabci.Request_PrepareProposal:
+		_, ok = res.Value.(*ocabci.Response_PrepareProposal)
+	case *ocabci.Request_ProcessProposal:
+		_, ok = res.Value.(*ocabci.Response_ProcessProposal)
+	case *ocabci.Request_ExtendVote:
+		_, ok = res.Value.(*ocabci.Response_ExtendVote)
+	case *ocabci.Request_VerifyVoteExtension:
+		_, ok = res.Value.(*ocabci.Response_VerifyVoteExtension)
 	case *ocabci.Request_BeginBlock:
 		_, ok = res.Value.(*ocabci.Response_BeginBlock)
 	case *ocabci.Request_EndBlock:
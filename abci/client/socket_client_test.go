@@ -145,6 +145,8 @@ func TestSockerClientCalls(t *testing.T) {
 	c.SetOptionAsync(types.RequestSetOption{}, getResponseCallback(t))
 	c.DeliverTxAsync(types.RequestDeliverTx{}, getResponseCallback(t))
 	c.CheckTxAsync(types.RequestCheckTx{}, getResponseCallback(t))
+	c.CheckTxBatchAsync([]types.RequestCheckTx{{}, {}},
+		[]abcicli.ResponseCallback{getResponseCallback(t), getResponseCallback(t)})
 	c.QueryAsync(types.RequestQuery{}, getResponseCallback(t))
 	c.CommitAsync(getResponseCallback(t))
 	c.InitChainAsync(types.RequestInitChain{}, getResponseCallback(t))
@@ -97,6 +97,22 @@ func (app *PersistentKVStoreApplication) EndRecheckTx(req ocabci.RequestEndReche
 	return app.app.EndRecheckTx(req)
 }
 
+func (app *PersistentKVStoreApplication) PrepareProposal(req ocabci.RequestPrepareProposal) ocabci.ResponsePrepareProposal {
+	return app.app.PrepareProposal(req)
+}
+
+func (app *PersistentKVStoreApplication) ProcessProposal(req ocabci.RequestProcessProposal) ocabci.ResponseProcessProposal {
+	return app.app.ProcessProposal(req)
+}
+
+func (app *PersistentKVStoreApplication) ExtendVote(req ocabci.RequestExtendVote) ocabci.ResponseExtendVote {
+	return app.app.ExtendVote(req)
+}
+
+func (app *PersistentKVStoreApplication) VerifyVoteExtension(req ocabci.RequestVerifyVoteExtension) ocabci.ResponseVerifyVoteExtension {
+	return app.app.VerifyVoteExtension(req)
+}
+
 // Commit will panic if InitChain was not called
 func (app *PersistentKVStoreApplication) Commit() types.ResponseCommit {
 	return app.app.Commit()
@@ -10,6 +10,8 @@ import (
 
 type CheckTxCallback func(ResponseCheckTx)
 
+type CheckTxBatchCallback func([]ResponseCheckTx)
+
 // Application is an interface that enables any finite, deterministic state machine
 // to be driven by a blockchain-based replication engine via the ABCI.
 // All methods take a RequestXxx argument and return a ResponseXxx argument,
@@ -27,11 +29,15 @@ type Application interface {
 	EndRecheckTx(RequestEndRecheckTx) ResponseEndRecheckTx       // Signals the end of rechecking
 
 	// Consensus Connection
-	InitChain(types.RequestInitChain) types.ResponseInitChain // Initialize blockchain w validators/other info from OstraconCore
-	BeginBlock(RequestBeginBlock) types.ResponseBeginBlock    // Signals the beginning of a block
-	DeliverTx(types.RequestDeliverTx) types.ResponseDeliverTx // Deliver a tx for full processing
-	EndBlock(types.RequestEndBlock) types.ResponseEndBlock    // Signals the end of a block, returns changes to the validator set
-	Commit() types.ResponseCommit                             // Commit the state and return the application Merkle root hash
+	InitChain(types.RequestInitChain) types.ResponseInitChain                   // Initialize blockchain w validators/other info from OstraconCore
+	PrepareProposal(RequestPrepareProposal) ResponsePrepareProposal             // Return the txs of the block to propose
+	ProcessProposal(RequestProcessProposal) ResponseProcessProposal             // Accept or reject a proposed block before prevoting it
+	ExtendVote(RequestExtendVote) ResponseExtendVote                            // Return the extension of the precommit for a block
+	VerifyVoteExtension(RequestVerifyVoteExtension) ResponseVerifyVoteExtension // Accept or reject the vote extension of a validator
+	BeginBlock(RequestBeginBlock) types.ResponseBeginBlock                      // Signals the beginning of a block
+	DeliverTx(types.RequestDeliverTx) types.ResponseDeliverTx                   // Deliver a tx for full processing
+	EndBlock(types.RequestEndBlock) types.ResponseEndBlock                      // Signals the end of a block, returns changes to the validator set
+	Commit() types.ResponseCommit                                               // Commit the state and return the application Merkle root hash
 
 	// State Sync Connection
 	ListSnapshots(types.RequestListSnapshots) types.ResponseListSnapshots                // List available snapshots
@@ -40,6 +46,18 @@ type Application interface {
 	ApplySnapshotChunk(types.RequestApplySnapshotChunk) types.ResponseApplySnapshotChunk // Apply a shapshot chunk
 }
 
+// BatchApplication is an Application which can validate a batch of txs for
+// the mempool in one call, saving its overhead by call. The local clients call
+// CheckTxBatchAsync for the batches of txs of the mempool, and CheckTxAsync
+// for each tx of a batch for the other applications.
+type BatchApplication interface {
+	Application
+
+	// Asynchronously validate the txs for the mempool, calling back with their
+	// responses in the order of the requests
+	CheckTxBatchAsync([]types.RequestCheckTx, CheckTxBatchCallback)
+}
+
 //-------------------------------------------------------
 // BaseApplication is a base form of Application
 
@@ -92,6 +110,26 @@ func (BaseApplication) InitChain(req types.RequestInitChain) types.ResponseInitC
 	return types.ResponseInitChain{}
 }
 
+// PrepareProposal returns the txs of the mempool as they are.
+func (BaseApplication) PrepareProposal(req RequestPrepareProposal) ResponsePrepareProposal {
+	return ResponsePrepareProposal{Txs: req.Txs}
+}
+
+// ProcessProposal accepts any proposal.
+func (BaseApplication) ProcessProposal(req RequestProcessProposal) ResponseProcessProposal {
+	return ResponseProcessProposal{Status: ResponseProcessProposal_ACCEPT}
+}
+
+// ExtendVote doesn't extend the votes.
+func (BaseApplication) ExtendVote(req RequestExtendVote) ResponseExtendVote {
+	return ResponseExtendVote{}
+}
+
+// VerifyVoteExtension accepts any vote extension.
+func (BaseApplication) VerifyVoteExtension(req RequestVerifyVoteExtension) ResponseVerifyVoteExtension {
+	return ResponseVerifyVoteExtension{Status: ResponseVerifyVoteExtension_ACCEPT}
+}
+
 func (BaseApplication) BeginBlock(req RequestBeginBlock) types.ResponseBeginBlock {
 	return types.ResponseBeginBlock{}
 }
@@ -123,6 +161,8 @@ type GRPCApplication struct {
 	app Application
 }
 
+var _ ABCIApplicationServer = (*GRPCApplication)(nil)
+
 func NewGRPCApplication(app Application) *GRPCApplication {
 	return &GRPCApplication{app}
 }
@@ -181,6 +221,30 @@ func (app *GRPCApplication) InitChain(ctx context.Context, req *types.RequestIni
 	return &res, nil
 }
 
+func (app *GRPCApplication) PrepareProposal(
+	ctx context.Context, req *RequestPrepareProposal) (*ResponsePrepareProposal, error) {
+	res := app.app.PrepareProposal(*req)
+	return &res, nil
+}
+
+func (app *GRPCApplication) ProcessProposal(
+	ctx context.Context, req *RequestProcessProposal) (*ResponseProcessProposal, error) {
+	res := app.app.ProcessProposal(*req)
+	return &res, nil
+}
+
+func (app *GRPCApplication) ExtendVote(
+	ctx context.Context, req *RequestExtendVote) (*ResponseExtendVote, error) {
+	res := app.app.ExtendVote(*req)
+	return &res, nil
+}
+
+func (app *GRPCApplication) VerifyVoteExtension(
+	ctx context.Context, req *RequestVerifyVoteExtension) (*ResponseVerifyVoteExtension, error) {
+	res := app.app.VerifyVoteExtension(*req)
+	return &res, nil
+}
+
 func (app *GRPCApplication) BeginBlock(ctx context.Context, req *RequestBeginBlock) (*types.ResponseBeginBlock, error) {
 	res := app.app.BeginBlock(*req)
 	return &res, nil
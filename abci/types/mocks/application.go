@@ -131,6 +131,20 @@ func (_m *Application) EndRecheckTx(_a0 abcitypes.RequestEndRecheckTx) abcitypes
 	return r0
 }
 
+// ExtendVote provides a mock function with given fields: _a0
+func (_m *Application) ExtendVote(_a0 abcitypes.RequestExtendVote) abcitypes.ResponseExtendVote {
+	ret := _m.Called(_a0)
+
+	var r0 abcitypes.ResponseExtendVote
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestExtendVote) abcitypes.ResponseExtendVote); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(abcitypes.ResponseExtendVote)
+	}
+
+	return r0
+}
+
 // Info provides a mock function with given fields: _a0
 func (_m *Application) Info(_a0 types.RequestInfo) types.ResponseInfo {
 	ret := _m.Called(_a0)
@@ -201,6 +215,34 @@ func (_m *Application) OfferSnapshot(_a0 types.RequestOfferSnapshot) types.Respo
 	return r0
 }
 
+// PrepareProposal provides a mock function with given fields: _a0
+func (_m *Application) PrepareProposal(_a0 abcitypes.RequestPrepareProposal) abcitypes.ResponsePrepareProposal {
+	ret := _m.Called(_a0)
+
+	var r0 abcitypes.ResponsePrepareProposal
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestPrepareProposal) abcitypes.ResponsePrepareProposal); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(abcitypes.ResponsePrepareProposal)
+	}
+
+	return r0
+}
+
+// ProcessProposal provides a mock function with given fields: _a0
+func (_m *Application) ProcessProposal(_a0 abcitypes.RequestProcessProposal) abcitypes.ResponseProcessProposal {
+	ret := _m.Called(_a0)
+
+	var r0 abcitypes.ResponseProcessProposal
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestProcessProposal) abcitypes.ResponseProcessProposal); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(abcitypes.ResponseProcessProposal)
+	}
+
+	return r0
+}
+
 // Query provides a mock function with given fields: _a0
 func (_m *Application) Query(_a0 types.RequestQuery) types.ResponseQuery {
 	ret := _m.Called(_a0)
@@ -229,6 +271,20 @@ func (_m *Application) SetOption(_a0 types.RequestSetOption) types.ResponseSetOp
 	return r0
 }
 
+// VerifyVoteExtension provides a mock function with given fields: _a0
+func (_m *Application) VerifyVoteExtension(_a0 abcitypes.RequestVerifyVoteExtension) abcitypes.ResponseVerifyVoteExtension {
+	ret := _m.Called(_a0)
+
+	var r0 abcitypes.ResponseVerifyVoteExtension
+	if rf, ok := ret.Get(0).(func(abcitypes.RequestVerifyVoteExtension) abcitypes.ResponseVerifyVoteExtension); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(abcitypes.ResponseVerifyVoteExtension)
+	}
+
+	return r0
+}
+
 // NewApplication creates a new instance of Application. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewApplication(t interface {
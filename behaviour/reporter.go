@@ -25,7 +25,8 @@ func NewSwitchReporter(sw *p2p.Switch) *SwitchReporter {
 	}
 }
 
-// Report reports the behaviour of a peer to the Switch.
+// Report reports the behaviour of a peer to the Switch, scoring the peer (see
+// p2p.Switch.ReportPeerBehavior).
 func (spbr *SwitchReporter) Report(behaviour PeerBehaviour) error {
 	peer := spbr.sw.Peers().Get(behaviour.peerID)
 	if peer == nil {
@@ -33,12 +34,14 @@ func (spbr *SwitchReporter) Report(behaviour PeerBehaviour) error {
 	}
 
 	switch reason := behaviour.reason.(type) {
-	case consensusVote, blockPart:
-		spbr.sw.MarkPeerAsGood(peer)
+	case consensusVote:
+		spbr.sw.ReportPeerBehavior(peer.ID(), p2p.UsefulMessage(reason.explanation))
+	case blockPart:
+		spbr.sw.ReportPeerBehavior(peer.ID(), p2p.UsefulMessage(reason.explanation))
 	case badMessage:
-		spbr.sw.StopPeerForError(peer, reason.explanation)
+		spbr.sw.ReportPeerBehavior(peer.ID(), p2p.InvalidMessage(reason.explanation))
 	case messageOutOfOrder:
-		spbr.sw.StopPeerForError(peer, reason.explanation)
+		spbr.sw.ReportPeerBehavior(peer.ID(), p2p.InvalidMessage(reason.explanation))
 	default:
 		return errors.New("unknown reason reported")
 	}
@@ -60,6 +60,19 @@ func ValidateMsg(pb proto.Message) error {
 	return nil
 }
 
+// ValidateMsgBasic validates a message as ValidateMsg, without decoding the
+// block of a BlockResponse, so that it is cheap enough to run before the message
+// is queued (see p2p.MessageValidator).
+func ValidateMsgBasic(pb proto.Message) error {
+	if msg, ok := pb.(*ocbcproto.BlockResponse); ok {
+		if msg.Block == nil {
+			return errors.New("nil block")
+		}
+		return nil
+	}
+	return ValidateMsg(pb)
+}
+
 // EncodeMsg encodes a Protobuf message
 //
 // Deprecated: Will be removed in v0.37.
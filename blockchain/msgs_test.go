@@ -84,6 +84,22 @@ func TestBcStatusResponseMessageValidateBasic(t *testing.T) {
 	}
 }
 
+func TestValidateMsgBasic(t *testing.T) {
+	block := types.MakeBlock(int64(3), []types.Tx{types.Tx("Hello World")}, nil, nil, sm.InitStateVersion.Consensus)
+	bpb, err := block.ToProto()
+	require.NoError(t, err)
+	// the block isn't decoded
+	bpb.Header.Height = -1
+
+	assert.NoError(t, ValidateMsgBasic(&ocbcproto.BlockResponse{Block: bpb}))
+	assert.Error(t, ValidateMsg(&ocbcproto.BlockResponse{Block: bpb}))
+	assert.Error(t, ValidateMsgBasic(&ocbcproto.BlockResponse{}))
+	// the other messages are validated as ValidateMsg
+	assert.NoError(t, ValidateMsgBasic(&bcproto.BlockRequest{Height: 1}))
+	assert.Error(t, ValidateMsgBasic(&bcproto.BlockRequest{Height: -1}))
+	assert.Error(t, ValidateMsgBasic(&bcproto.StatusResponse{Base: 2, Height: 1}))
+}
+
 // nolint:lll // ignore line length in tests
 func TestBlockchainMessageVectors(t *testing.T) {
 	block := types.MakeBlock(int64(3), []types.Tx{types.Tx("Hello World")}, nil, nil, sm.InitStateVersion.Consensus)
@@ -77,6 +77,10 @@ type BlockPool struct {
 
 	requestsCh chan<- BlockRequest
 	errorsCh   chan<- peerError
+
+	// canSend returns false while the send queue to the peer is full, for the
+	// requests to go to the other peers meanwhile. nil if always true.
+	canSend func(peerID p2p.ID) bool
 }
 
 // NewBlockPool returns a new BlockPool with the height equal to start. Block
@@ -203,6 +207,27 @@ func (pool *BlockPool) PeekTwoBlocks() (first *types.Block, second *types.Block)
 	return
 }
 
+// PeekBlocks returns up to max consecutive blocks starting at pool.height,
+// stopping at the first one not received yet.
+func (pool *BlockPool) PeekBlocks(max int) []*types.Block {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	var blocks []*types.Block
+	for height := pool.height; len(blocks) < max; height++ {
+		r := pool.requesters[height]
+		if r == nil {
+			break
+		}
+		block := r.getBlock()
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
 // PopRequest pops the first block at pool.height.
 // It must have been validated by 'second'.Commit from PeekTwoBlocks().
 func (pool *BlockPool) PopRequest() {
@@ -241,6 +266,23 @@ func (pool *BlockPool) RedoRequest(height int64) p2p.ID {
 	return peerID
 }
 
+// RetryRequest redoes the request of the block at the height, which couldn't
+// be sent to the peer, with another peer (or the same one once the requests
+// can be sent to it again), without waiting for requestRetrySeconds.
+func (pool *BlockPool) RetryRequest(height int64, peerID p2p.ID) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	requester := pool.requesters[height]
+	if requester == nil || requester.getPeerID() != peerID {
+		return
+	}
+	if peer := pool.peers[peerID]; peer != nil {
+		peer.cancelPending()
+	}
+	requester.redo(peerID)
+}
+
 // AddBlock validates that the block comes from the peer it was expected from and calls the requester to store it.
 // TODO: ensure that blocks come in order for each peer.
 func (pool *BlockPool) AddBlock(peerID p2p.ID, block *types.Block, blockSize int) {
@@ -366,6 +408,9 @@ func (pool *BlockPool) pickIncrAvailablePeer(height int64) *bpPeer {
 		if height < peer.base || height > peer.height {
 			continue
 		}
+		if pool.canSend != nil && !pool.canSend(peer.id) {
+			continue
+		}
 		peer.incrPending()
 		return peer
 	}
@@ -494,6 +539,14 @@ func (peer *bpPeer) decrPending(recvSize int) {
 	}
 }
 
+// cancelPending cancels a pending request which wasn't sent to the peer.
+func (peer *bpPeer) cancelPending() {
+	peer.numPending--
+	if peer.numPending == 0 {
+		peer.timeout.Stop()
+	}
+}
+
 func (peer *bpPeer) onTimeout() {
 	peer.pool.mtx.Lock()
 	defer peer.pool.mtx.Unlock()
@@ -2,6 +2,7 @@ package v0
 
 import (
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -240,3 +241,49 @@ func TestBlockPoolRemovePeer(t *testing.T) {
 
 	assert.EqualValues(t, 0, pool.MaxPeerHeight())
 }
+
+func TestBlockPoolRetryRequest(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	errorsCh := make(chan peerError, 10)
+
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	var full atomic.Bool
+	pool.canSend = func(peerID p2p.ID) bool {
+		return peerID != "1" || !full.Load()
+	}
+	err := pool.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := pool.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	pool.SetPeerRange("1", 1, 1)
+	request := <-requestsCh
+	require.Equal(t, BlockRequest{1, "1"}, request)
+
+	// the send queue to the peer is full, the request goes to the other peer
+	full.Store(true)
+	pool.SetPeerRange("2", 1, 1)
+	pool.RetryRequest(1, "1")
+	select {
+	case request = <-requestsCh:
+		assert.Equal(t, BlockRequest{1, "2"}, request)
+	case <-time.After(time.Second):
+		t.Fatal("the request wasn't retried")
+	}
+
+	pool.mtx.Lock()
+	assert.Zero(t, pool.peers["1"].numPending)
+	pool.mtx.Unlock()
+
+	// not the request of the peer anymore
+	pool.RetryRequest(1, "1")
+	select {
+	case request = <-requestsCh:
+		t.Fatalf("unexpected request %v", request)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
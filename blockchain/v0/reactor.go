@@ -59,6 +59,7 @@ type BlockchainReactor struct {
 	blockExec *sm.BlockExecutor
 	store     *store.BlockStore
 	pool      *BlockPool
+	verifier  *blockVerifier
 	fastSync  bool
 
 	requestsCh <-chan BlockRequest
@@ -90,18 +91,34 @@ func NewBlockchainReactor(state sm.State, blockExec *sm.BlockExecutor, store *st
 		blockExec:    blockExec,
 		store:        store,
 		pool:         pool,
+		verifier:     newBlockVerifier(state),
 		fastSync:     fastSync,
 		requestsCh:   requestsCh,
 		errorsCh:     errorsCh,
 	}
 	bcR.BaseReactor = *p2p.NewBaseReactor("BlockchainReactor", bcR, async, recvBufSize)
+	pool.canSend = bcR.canSendRequest
 	return bcR
 }
 
+// canSendRequest returns false while the send queue to the peer is full, the
+// block requests then going to the other peers rather than being dropped.
+func (bcR *BlockchainReactor) canSendRequest(peerID p2p.ID) bool {
+	if bcR.Switch == nil {
+		return true
+	}
+	peer := bcR.Switch.Peers().Get(peerID)
+	if peer == nil {
+		return true
+	}
+	return !p2p.PeerSendStatus(peer, BlockchainChannel).Full()
+}
+
 // SetLogger implements service.Service by setting the logger on reactor and pool.
 func (bcR *BlockchainReactor) SetLogger(l log.Logger) {
 	bcR.BaseService.Logger = l
 	bcR.pool.Logger = l
+	bcR.verifier.SetLogger(l)
 }
 
 // OnStart implements service.Service.
@@ -117,6 +134,9 @@ func (bcR *BlockchainReactor) OnStart() error {
 		if err != nil {
 			return err
 		}
+		if err := bcR.verifier.workers.Start(); err != nil {
+			return err
+		}
 		go bcR.poolRoutine(false)
 	}
 	return nil
@@ -132,6 +152,9 @@ func (bcR *BlockchainReactor) SwitchToFastSync(state sm.State) error {
 	if err != nil {
 		return err
 	}
+	if err := bcR.verifier.workers.Start(); err != nil {
+		return err
+	}
 	go bcR.poolRoutine(true)
 	return nil
 }
@@ -143,6 +166,11 @@ func (bcR *BlockchainReactor) OnStop() {
 			bcR.Logger.Error("Error stopping pool", "err", err)
 		}
 	}
+	if bcR.verifier.workers.IsRunning() {
+		if err := bcR.verifier.workers.Stop(); err != nil {
+			bcR.Logger.Error("Error stopping the block verifier", "err", err)
+		}
+	}
 }
 
 // GetChannels implements Reactor
@@ -155,6 +183,7 @@ func (bcR *BlockchainReactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvBufferCapacity:  50 * 4096,
 			RecvMessageCapacity: bc.MaxMsgSize,
 			MessageType:         &ocbcproto.Message{},
+			Compress:            true,
 		},
 	}
 }
@@ -203,6 +232,12 @@ func (bcR *BlockchainReactor) respondToPeer(msg *bcproto.BlockRequest,
 	}, bcR.Logger)
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the invalid messages before they are queued, see bc.ValidateMsgBasic.
+func (bcR *BlockchainReactor) ValidateMessage(chID byte, msg proto.Message) error {
+	return bc.ValidateMsgBasic(msg)
+}
+
 func (bcR *BlockchainReactor) ReceiveEnvelope(e p2p.Envelope) {
 	if err := bc.ValidateMsg(e.Message); err != nil {
 		bcR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", e.Message, "err", err)
@@ -279,6 +314,8 @@ func (bcR *BlockchainReactor) poolRoutine(stateSynced bool) {
 	lastHundred := time.Now()
 	lastRate := 0.0
 
+	bcR.verifier.setState(state)
+
 	didProcessCh := make(chan struct{}, 1)
 
 	go func() {
@@ -293,12 +330,14 @@ func (bcR *BlockchainReactor) poolRoutine(stateSynced bool) {
 				if peer == nil {
 					continue
 				}
-				queued := p2p.TrySendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
+				status := p2p.TrySendEnvelopeStatus(peer, p2p.Envelope{
 					ChannelID: BlockchainChannel,
 					Message:   &bcproto.BlockRequest{Height: request.Height},
 				}, bcR.Logger)
-				if !queued {
-					bcR.Logger.Debug("Send queue is full, drop block request", "peer", peer.ID(), "height", request.Height)
+				if !status.Queued {
+					bcR.Logger.Debug("Send queue is full, retry block request", "peer", peer.ID(), "height", request.Height,
+						"queued", status.QueueSize, "pending_bytes", status.PendingBytes)
+					bcR.pool.RetryRequest(request.Height, request.PeerID)
 				}
 			case err := <-bcR.errorsCh:
 				peer := bcR.Switch.Peers().Get(err.peerID)
@@ -327,6 +366,9 @@ FOR_LOOP:
 				if err := bcR.pool.Stop(); err != nil {
 					bcR.Logger.Error("Error stopping pool", "err", err)
 				}
+				if err := bcR.verifier.workers.Stop(); err != nil {
+					bcR.Logger.Error("Error stopping the block verifier", "err", err)
+				}
 				conR, ok := bcR.Switch.Reactor("CONSENSUS").(consensusReactor)
 				if ok {
 					conR.SwitchToConsensus(state, blocksSynced > 0 || stateSynced)
@@ -353,6 +395,10 @@ FOR_LOOP:
 			// coupling them as it's written here.  TODO uncouple from request
 			// routine.
 
+			// Verify the blocks received ahead of the one to sync on the
+			// workers, while this one is applied.
+			bcR.verifier.schedule(bcR.pool.PeekBlocks(maxVerifyAheadBlocks + 1))
+
 			// See if there are any blocks to sync.
 			first, second := bcR.pool.PeekTwoBlocks()
 			// bcR.Logger.Info("TrySync peeked", "first", first, "second", second)
@@ -364,14 +410,23 @@ FOR_LOOP:
 				didProcessCh <- struct{}{}
 			}
 
-			firstParts := first.MakePartSet(types.BlockPartSizeBytes)
-			firstPartSetHeader := firstParts.Header()
-			firstID := types.BlockID{Hash: first.Hash(), PartSetHeader: firstPartSetHeader}
-			// Finally, verify the first block using the second's commit
-			// NOTE: we can probably make this more efficient, but note that calling
-			// first.Hash() doesn't verify the tx contents, so MakePartSet() is
-			// currently necessary.
-			err := state.Validators.VerifyCommitLight(chainID, firstID, first.Height, second.LastCommit)
+			var (
+				firstParts *types.PartSet
+				firstID    types.BlockID
+				err        error
+			)
+			if verified := bcR.verifier.result(first, second); verified != nil {
+				// verified by the workers with the validator set of the state
+				firstParts, firstID, err = verified.parts, verified.blockID, verified.err
+			} else {
+				firstParts = first.MakePartSet(state.BlockPartSize())
+				firstPartSetHeader := firstParts.Header()
+				firstID = types.BlockID{Hash: first.Hash(), PartSetHeader: firstPartSetHeader}
+				// Finally, verify the first block using the second's commit
+				// NOTE: note that calling first.Hash() doesn't verify the tx
+				// contents, so MakePartSet() is currently necessary.
+				err = state.Validators.VerifyCommitLight(chainID, firstID, first.Height, second.LastCommit)
+			}
 			if err == nil {
 				// validate the block before we persist it
 				err = bcR.blockExec.ValidateBlock(state, first.Round, first)
@@ -410,6 +465,8 @@ FOR_LOOP:
 				// TODO This is bad, are we zombie?
 				panic(fmt.Sprintf("Failed to process committed block (%d:%X): %v", first.Height, first.Hash(), err))
 			}
+			bcR.verifier.setState(state)
+			bcR.verifier.prune(first.Height + 1)
 			blocksSynced++
 
 			if blocksSynced%100 == 0 {
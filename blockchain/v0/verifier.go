@@ -0,0 +1,165 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+
+	tmasync "github.com/Finschia/ostracon/libs/async"
+	"github.com/Finschia/ostracon/libs/log"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	sm "github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	// maximum number of blocks of the pool verified ahead of the one applied
+	maxVerifyAheadBlocks = 128
+)
+
+// verifiedBlock is the result of the verification of a block with the commit
+// of the next one, by the validator set of valsHash.
+type verifiedBlock struct {
+	block, next *types.Block
+	valsHash    []byte
+	partSize    uint32
+
+	// closed once the verification returned, or was dropped
+	done chan struct{}
+	// set before done is closed, if the verification completed
+	completed bool
+	parts     *types.PartSet
+	blockID   types.BlockID
+	err       error
+}
+
+// blockVerifier verifies the blocks of the pool ahead of the one applied, in
+// batches on a pool of workers, while the blocks are applied one after the
+// other by the poolRoutine: the part sets of the blocks are made and their
+// commits (the LastCommit of the next blocks) verified with the latest
+// validator set known, the one of the state applied.
+//
+// As the validator set rarely changes, the results are mostly used by the
+// poolRoutine as they are; they are discarded, and the block verified again,
+// when the validator set of the state, or the blocks of the pool (e.g.
+// requested again from another peer), aren't the ones verified.
+type blockVerifier struct {
+	chainID string
+	workers *tmasync.Pool
+
+	mtx      tmsync.Mutex
+	vals     *types.ValidatorSet
+	valsHash []byte
+	partSize uint32
+	verified map[int64]*verifiedBlock
+}
+
+func newBlockVerifier(state sm.State) *blockVerifier {
+	v := &blockVerifier{
+		chainID:  state.ChainID,
+		workers:  tmasync.NewPool("BlockVerify", runtime.NumCPU(), maxVerifyAheadBlocks),
+		verified: make(map[int64]*verifiedBlock),
+	}
+	v.setState(state)
+	return v
+}
+
+func (v *blockVerifier) SetLogger(l log.Logger) {
+	v.workers.SetLogger(l)
+}
+
+// setState sets the validator set and the block part size of the state the
+// next blocks are verified with, discarding the results of the other ones.
+func (v *blockVerifier) setState(state sm.State) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	valsHash := state.Validators.Hash()
+	if v.vals != nil && bytes.Equal(valsHash, v.valsHash) && state.BlockPartSize() == v.partSize {
+		return
+	}
+	// the copy is shared by the workers, its total voting power being computed
+	// beforehand not to be lazily set concurrently
+	v.vals = state.Validators.Copy()
+	v.vals.TotalVotingPower()
+	v.valsHash = valsHash
+	v.partSize = state.BlockPartSize()
+	for height, verified := range v.verified {
+		if !bytes.Equal(verified.valsHash, valsHash) || verified.partSize != v.partSize {
+			delete(v.verified, height)
+		}
+	}
+}
+
+// schedule submits to the workers the verification of the consecutive blocks
+// of the pool, each one being verified with the commit of the next one, unless
+// it's already verified or being verified. The blocks aren't scheduled past
+// the first one the queue of the workers is full at.
+func (v *blockVerifier) schedule(blocks []*types.Block) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	for i := 0; i+1 < len(blocks); i++ {
+		block, next := blocks[i], blocks[i+1]
+		if verified := v.verified[block.Height]; verified != nil && verified.block == block && verified.next == next {
+			continue
+		}
+		verified := &verifiedBlock{
+			block:    block,
+			next:     next,
+			valsHash: v.valsHash,
+			partSize: v.partSize,
+			done:     make(chan struct{}),
+		}
+		vals := v.vals
+		if !v.workers.TrySubmit(func(context.Context) { v.verify(verified, vals) }) {
+			delete(v.verified, block.Height)
+			return
+		}
+		v.verified[block.Height] = verified
+	}
+}
+
+func (v *blockVerifier) verify(verified *verifiedBlock, vals *types.ValidatorSet) {
+	defer close(verified.done)
+
+	parts := verified.block.MakePartSet(verified.partSize)
+	blockID := types.BlockID{Hash: verified.block.Hash(), PartSetHeader: parts.Header()}
+	err := vals.VerifyCommitLight(v.chainID, blockID, verified.block.Height, verified.next.LastCommit)
+
+	verified.parts, verified.blockID, verified.err = parts, blockID, err
+	verified.completed = true
+}
+
+// result waits for the verification of the block with the commit of next by
+// the current validator set, and returns it, or nil if it wasn't scheduled or
+// didn't complete, the block being to be verified by the caller then.
+func (v *blockVerifier) result(block, next *types.Block) *verifiedBlock {
+	v.mtx.Lock()
+	verified := v.verified[block.Height]
+	v.mtx.Unlock()
+	if verified == nil || verified.block != block || verified.next != next {
+		return nil
+	}
+
+	select {
+	case <-verified.done:
+	case <-v.workers.Quit():
+		return nil
+	}
+	if !verified.completed {
+		return nil
+	}
+	return verified
+}
+
+// prune discards the results of the blocks below height.
+func (v *blockVerifier) prune(height int64) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	for h := range v.verified {
+		if h < height {
+			delete(v.verified, h)
+		}
+	}
+}
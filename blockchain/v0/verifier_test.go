@@ -0,0 +1,65 @@
+package v0
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	sm "github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestBlockVerifier(t *testing.T) {
+	config = cfg.ResetTestRoot("blockchain_verifier_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc(1, false, 30)
+
+	const maxBlockHeight = 10
+	pair := newBlockchainReactor(log.TestingLogger(), genDoc, privVals, maxBlockHeight,
+		config.P2P.RecvAsync, config.P2P.BlockchainRecvBufSize)
+	defer func() {
+		require.NoError(t, pair.app.Stop())
+	}()
+	blocks := make([]*types.Block, 0, maxBlockHeight)
+	for height := int64(1); height <= maxBlockHeight; height++ {
+		blocks = append(blocks, pair.reactor.store.LoadBlock(height))
+	}
+
+	v := newBlockVerifier(pair.reactor.initialState)
+	v.SetLogger(log.TestingLogger())
+	require.NoError(t, v.workers.Start())
+	defer func() {
+		require.NoError(t, v.workers.Stop())
+	}()
+
+	// each block is verified with the commit of the next one
+	v.schedule(blocks)
+	for i := 0; i+1 < len(blocks); i++ {
+		verified := v.result(blocks[i], blocks[i+1])
+		require.NotNil(t, verified, "height %d", blocks[i].Height)
+		require.NoError(t, verified.err)
+		assert.Equal(t, pair.reactor.store.LoadBlockMeta(blocks[i].Height).BlockID, verified.blockID)
+	}
+	assert.Nil(t, v.result(blocks[len(blocks)-1], nil))
+
+	// the results are discarded once the blocks are received again
+	assert.Nil(t, v.result(blocks[0], pair.reactor.store.LoadBlock(2)))
+
+	// or the validator set changes
+	otherGenDoc, _ := randGenesisDoc(1, false, 30)
+	otherState, err := sm.MakeGenesisState(otherGenDoc)
+	require.NoError(t, err)
+	v.setState(otherState)
+	assert.Nil(t, v.result(blocks[0], blocks[1]))
+	v.schedule(blocks[:2])
+	verified := v.result(blocks[0], blocks[1])
+	require.NotNil(t, verified)
+	assert.Error(t, verified.err)
+
+	v.prune(2)
+	assert.Nil(t, v.result(blocks[0], blocks[1]))
+}
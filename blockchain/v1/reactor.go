@@ -183,6 +183,7 @@ func (bcR *BlockchainReactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvBufferCapacity:  50 * 4096,
 			RecvMessageCapacity: bc.MaxMsgSize,
 			MessageType:         &ocbcproto.Message{},
+			Compress:            true,
 		},
 	}
 }
@@ -251,6 +252,12 @@ func (bcR *BlockchainReactor) RemovePeer(peer p2p.Peer, reason interface{}) {
 	bcR.errorsForFSMCh <- msgData
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the invalid messages before they are queued, see bc.ValidateMsgBasic.
+func (bcR *BlockchainReactor) ValidateMessage(chID byte, msg proto.Message) error {
+	return bc.ValidateMsgBasic(msg)
+}
+
 // Receive implements Reactor by handling 4 types of messages (look below).
 func (bcR *BlockchainReactor) ReceiveEnvelope(e p2p.Envelope) {
 	if err := bc.ValidateMsg(e.Message); err != nil {
@@ -477,7 +484,7 @@ func (bcR *BlockchainReactor) processBlock() error {
 
 	chainID := bcR.initialState.ChainID
 
-	firstParts := first.MakePartSet(types.BlockPartSizeBytes)
+	firstParts := first.MakePartSet(bcR.state.BlockPartSize())
 	firstPartSetHeader := firstParts.Header()
 	firstID := types.BlockID{Hash: first.Hash(), PartSetHeader: firstPartSetHeader}
 	// Finally, verify the first block using the second's commit
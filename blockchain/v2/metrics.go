@@ -3,8 +3,8 @@ package v2
 import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 )
 
 const (
@@ -40,66 +40,72 @@ type Metrics struct {
 // PrometheusMetrics returns metrics for in and out events, errors, etc. handled by routines.
 // Can we burn in the routine name here?
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns the metrics of PrometheusMetrics, build using the
+// provider.
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		EventsIn: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EventsIn: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "events_in",
 			Help:      "Events read from the channel.",
 		}, labels).With(labelsAndValues...),
-		EventsHandled: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EventsHandled: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "events_handled",
 			Help:      "Events handled",
 		}, labels).With(labelsAndValues...),
-		EventsOut: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EventsOut: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "events_out",
 			Help:      "Events output from routine.",
 		}, labels).With(labelsAndValues...),
-		ErrorsIn: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ErrorsIn: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "errors_in",
 			Help:      "Errors read from the channel.",
 		}, labels).With(labelsAndValues...),
-		ErrorsHandled: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ErrorsHandled: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "errors_handled",
 			Help:      "Errors handled.",
 		}, labels).With(labelsAndValues...),
-		ErrorsOut: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ErrorsOut: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "errors_out",
 			Help:      "Errors output from routine.",
 		}, labels).With(labelsAndValues...),
-		ErrorsSent: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ErrorsSent: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "errors_sent",
 			Help:      "Errors sent to routine.",
 		}, labels).With(labelsAndValues...),
-		ErrorsShed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ErrorsShed: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "errors_shed",
 			Help:      "Errors dropped from sending.",
 		}, labels).With(labelsAndValues...),
-		EventsSent: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EventsSent: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "events_sent",
 			Help:      "Events sent to routine.",
 		}, labels).With(labelsAndValues...),
-		EventsShed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EventsShed: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "events_shed",
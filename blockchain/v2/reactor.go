@@ -457,6 +457,12 @@ func (r *BlockchainReactor) Stop() error {
 	return nil
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the invalid messages before they are queued, see bc.ValidateMsgBasic.
+func (r *BlockchainReactor) ValidateMessage(chID byte, msg proto.Message) error {
+	return bc.ValidateMsgBasic(msg)
+}
+
 // Receive implements Reactor by handling different message types.
 func (r *BlockchainReactor) ReceiveEnvelope(e p2p.Envelope) {
 	if err := bc.ValidateMsg(e.Message); err != nil {
@@ -572,6 +578,7 @@ func (r *BlockchainReactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvBufferCapacity:  50 * 4096,
 			RecvMessageCapacity: bc.MaxMsgSize,
 			MessageType:         &ocbcproto.Message{},
+			Compress:            true,
 		},
 	}
 }
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/Finschia/ostracon/config"
+	tmos "github.com/Finschia/ostracon/libs/os"
+)
+
+// the databases of the node, which are migrated if they exist
+var migratedDBs = []string{"blockstore", "state", "evidence", "tx_index"}
+
+// the number of keys written to the target database in one batch
+const migrateBatchSize = 10000
+
+// MigrateDBCmd copies the databases of the node to another db_backend.
+var MigrateDBCmd = &cobra.Command{
+	Use:   "experimental-db-migrate",
+	Short: "copy the databases of the node to another db_backend",
+	Long: `
+Copies the block store, state, evidence and tx_index databases of a stopped node from
+the configured db_backend to another one (given with --to), in the directory given
+with --target-dir (by default the db_dir followed by the name of the target backend).
+The target databases must not exist yet.
+
+The source databases are left as they are. Once the migration completed, set
+db_backend to the target backend and db_dir to the target directory in the
+configuration file before restarting the node. The statesync chunks and the
+archived snapshots of the db_dir aren't copied, they are fetched or archived again.
+
+The backends other than goleveldb must be built in, e.g. badgerdb with the badgerdb
+build tag (included by make build).
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+		targetDir, err := cmd.Flags().GetString("target-dir")
+		if err != nil {
+			return err
+		}
+		if targetDir == "" {
+			targetDir = config.DBDir() + "-" + to
+		}
+
+		if err := MigrateDBs(config, dbm.BackendType(to), targetDir); err != nil {
+			return fmt.Errorf("failed to migrate the databases: %w", err)
+		}
+		fmt.Printf("Migrated the databases to %s in %s\n", to, targetDir)
+		fmt.Printf("Set db_backend = %q and db_dir = %q to use them\n", to, targetDir)
+		return nil
+	},
+}
+
+func init() {
+	MigrateDBCmd.Flags().String("to", "", "db_backend to migrate the databases to, e.g. badgerdb")
+	MigrateDBCmd.Flags().String("target-dir", "",
+		"directory of the migrated databases (default: the db_dir followed by the target backend)")
+}
+
+// MigrateDBs copies the databases of the node from its db_backend to the
+// backend to in targetDir, see MigrateDBCmd.
+func MigrateDBs(config *cfg.Config, to dbm.BackendType, targetDir string) error {
+	from := dbm.BackendType(config.DBBackend)
+	if from == dbm.MemDBBackend || to == dbm.MemDBBackend {
+		return errors.New("the memdb databases can't be migrated")
+	}
+	if to == "" {
+		return errors.New("no target backend given, see --to")
+	}
+	if from == to && filepath.Clean(targetDir) == filepath.Clean(config.DBDir()) {
+		return errors.New("the databases can't be migrated to themselves")
+	}
+
+	var names []string
+	for _, name := range migratedDBs {
+		if dbExists(from, config.DBDir(), name) {
+			names = append(names, name)
+		}
+		if dbExists(to, targetDir, name) {
+			return fmt.Errorf("the %s database already exists in %s", name, targetDir)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no %s database in %s", from, config.DBDir())
+	}
+	if err := tmos.EnsureDir(targetDir, 0o700); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		logger.Info("Migrating database", "db", name, "from", from, "to", to)
+		n, err := migrateDB(name, from, config.DBDir(), to, targetDir)
+		if err != nil {
+			return fmt.Errorf("failed to migrate the %s database: %w", name, err)
+		}
+		logger.Info("Migrated database", "db", name, "keys", n)
+	}
+	return nil
+}
+
+// migrateDB copies the keys of a database, returning their number.
+func migrateDB(name string, from dbm.BackendType, sourceDir string, to dbm.BackendType, targetDir string) (int, error) {
+	source, err := dbm.NewDB(name, from, sourceDir)
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+	target, err := dbm.NewDB(name, to, targetDir)
+	if err != nil {
+		return 0, err
+	}
+	defer target.Close()
+
+	iter, err := source.Iterator(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	n := 0
+	batch := target.NewBatch()
+	defer func() { batch.Close() }()
+	for ; iter.Valid(); iter.Next() {
+		if err := batch.Set(iter.Key(), iter.Value()); err != nil {
+			return n, err
+		}
+		n++
+		if n%migrateBatchSize == 0 {
+			if err := batch.Write(); err != nil {
+				return n, err
+			}
+			batch.Close()
+			batch = target.NewBatch()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return n, err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// dbExists reports whether the database of the backend exists in dir, the
+// badgerdb databases being directories named after them, and the other ones
+// having the .db suffix.
+func dbExists(backend dbm.BackendType, dir, name string) bool {
+	path := filepath.Join(dir, name+".db")
+	if backend == dbm.BadgerDBBackend {
+		path = filepath.Join(dir, name)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+)
+
+func TestMigrateDBs(t *testing.T) {
+	config := cfg.TestConfig()
+	config.SetRoot(t.TempDir())
+	config.DBBackend = string(dbm.GoLevelDBBackend)
+	logger = log.TestingLogger()
+
+	// the keys are written in several batches
+	const keys = migrateBatchSize + 10
+	for _, name := range []string{"blockstore", "state"} {
+		db, err := dbm.NewDB(name, dbm.GoLevelDBBackend, config.DBDir())
+		require.NoError(t, err)
+		for i := 0; i < keys; i++ {
+			require.NoError(t, db.Set([]byte(fmt.Sprintf("%s-%d", name, i)), []byte{byte(i)}))
+		}
+		require.NoError(t, db.Close())
+	}
+
+	targetDir := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, MigrateDBs(config, dbm.GoLevelDBBackend, targetDir))
+	for _, name := range []string{"blockstore", "state"} {
+		db, err := dbm.NewDB(name, dbm.GoLevelDBBackend, targetDir)
+		require.NoError(t, err)
+		for _, i := range []int{0, migrateBatchSize, keys - 1} {
+			value, err := db.Get([]byte(fmt.Sprintf("%s-%d", name, i)))
+			require.NoError(t, err)
+			assert.Equal(t, []byte{byte(i)}, value)
+		}
+		require.NoError(t, db.Close())
+	}
+	// the databases which don't exist aren't created
+	assert.False(t, dbExists(dbm.GoLevelDBBackend, targetDir, "evidence"))
+
+	// the existing databases aren't overwritten
+	assert.Error(t, MigrateDBs(config, dbm.GoLevelDBBackend, targetDir))
+	assert.Error(t, MigrateDBs(config, dbm.GoLevelDBBackend, config.DBDir()))
+	assert.Error(t, MigrateDBs(config, dbm.MemDBBackend, t.TempDir()))
+	assert.Error(t, MigrateDBs(config, "unknown", t.TempDir()))
+}
@@ -12,10 +12,12 @@ var (
 	nodeRPCAddr string
 	profAddr    string
 	frequency   uint
+	timeout     uint
 
 	flagNodeRPCAddr = "rpc-laddr"
 	flagProfAddr    = "pprof-laddr"
 	flagFrequency   = "frequency"
+	flagTimeout     = "timeout"
 
 	logger = log.NewOCLogger(log.NewSyncWriter(os.Stdout))
 )
@@ -35,6 +37,12 @@ func init() {
 		"tcp://localhost:26657",
 		"the Ostracon node's RPC address (<host>:<port>)",
 	)
+	DebugCmd.PersistentFlags().UintVar(
+		&timeout,
+		flagTimeout,
+		10,
+		"the timeout (seconds) of the requests to the node, e.g. to a node whose consensus is stuck",
+	)
 
 	DebugCmd.AddCommand(killCmd)
 	DebugCmd.AddCommand(dumpCmd)
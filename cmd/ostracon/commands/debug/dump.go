@@ -21,7 +21,10 @@ var dumpCmd = &cobra.Command{
 	Long: `Continuously poll an Ostracon process and dump debugging data into a single
 location at a specified frequency. At each frequency interval, an archived and compressed
 file will contain node debugging information including the goroutine and heap profiles
-if enabled.`,
+if enabled. With a frequency of 0, the data is dumped once.
+
+The data which can't be obtained, e.g. from a node which doesn't answer some of the
+requests, is skipped, the rest being dumped.`,
 	Args: cobra.ExactArgs(1),
 	RunE: dumpCmdHandler,
 }
@@ -31,7 +34,7 @@ func init() {
 		&frequency,
 		flagFrequency,
 		30,
-		"the frequency (seconds) in which to poll, aggregate and dump Ostracon debug data",
+		"the frequency (seconds) in which to poll, aggregate and dump Ostracon debug data (0: dump once)",
 	)
 
 	dumpCmd.Flags().StringVar(
@@ -48,17 +51,13 @@ func dumpCmdHandler(_ *cobra.Command, args []string) error {
 		return errors.New("invalid output directory")
 	}
 
-	if frequency == 0 {
-		return errors.New("frequency must be positive")
-	}
-
 	if _, err := os.Stat(outDir); os.IsNotExist(err) {
 		if err := os.Mkdir(outDir, os.ModePerm); err != nil {
 			return fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 
-	rpc, err := rpchttp.New(nodeRPCAddr, "/websocket")
+	rpc, err := rpchttp.NewWithTimeout(nodeRPCAddr, "/websocket", timeout)
 	if err != nil {
 		return fmt.Errorf("failed to create new http client: %w", err)
 	}
@@ -69,6 +68,9 @@ func dumpCmdHandler(_ *cobra.Command, args []string) error {
 	cfg.EnsureRoot(conf.RootDir)
 
 	dumpDebugData(outDir, conf, rpc)
+	if frequency == 0 {
+		return nil
+	}
 
 	ticker := time.NewTicker(time.Duration(frequency) * time.Second)
 	for range ticker.C {
@@ -91,38 +93,32 @@ func dumpDebugData(outDir string, conf *cfg.Config, rpc *rpchttp.HTTP) {
 	logger.Info("getting node status...")
 	if err := dumpStatus(rpc, tmpDir, "status.json"); err != nil {
 		logger.Error("failed to dump node status", "error", err)
-		return
 	}
 
 	logger.Info("getting node network info...")
 	if err := dumpNetInfo(rpc, tmpDir, "net_info.json"); err != nil {
 		logger.Error("failed to dump node network info", "error", err)
-		return
 	}
 
 	logger.Info("getting node consensus state...")
 	if err := dumpConsensusState(rpc, tmpDir, "consensus_state.json"); err != nil {
 		logger.Error("failed to dump node consensus state", "error", err)
-		return
 	}
 
 	logger.Info("copying node WAL...")
 	if err := copyWAL(conf, tmpDir); err != nil {
 		logger.Error("failed to copy node WAL", "error", err)
-		return
 	}
 
 	if profAddr != "" {
 		logger.Info("getting node goroutine profile...")
 		if err := dumpProfile(tmpDir, profAddr, "goroutine", 2); err != nil {
 			logger.Error("failed to dump goroutine profile", "error", err)
-			return
 		}
 
 		logger.Info("getting node heap profile...")
 		if err := dumpProfile(tmpDir, profAddr, "heap", 2); err != nil {
 			logger.Error("failed to dump heap profile", "error", err)
-			return
 		}
 	}
 
@@ -24,7 +24,8 @@ var killCmd = &cobra.Command{
 	Long: `Kill an Ostracon process while also aggregating Ostracon process data
 such as the latest node state, including consensus and networking state,
 go-routine state, and the node's WAL and config information. This aggregated data
-is packaged into a compressed archive.
+is packaged into a compressed archive. The node state which can't be obtained,
+e.g. from a node whose consensus is stuck, is skipped.
 
 Example:
 $ ostracon debug 34255 /path/to/tm-debug.zip`,
@@ -43,7 +44,7 @@ func killCmdHandler(cmd *cobra.Command, args []string) error {
 		return errors.New("invalid output file")
 	}
 
-	rpc, err := rpchttp.New(nodeRPCAddr, "/websocket")
+	rpc, err := rpchttp.NewWithTimeout(nodeRPCAddr, "/websocket", timeout)
 	if err != nil {
 		return fmt.Errorf("failed to create new http client: %w", err)
 	}
@@ -63,17 +64,17 @@ func killCmdHandler(cmd *cobra.Command, args []string) error {
 
 	logger.Info("getting node status...")
 	if err := dumpStatus(rpc, tmpDir, "status.json"); err != nil {
-		return err
+		logger.Error("failed to dump node status", "error", err)
 	}
 
 	logger.Info("getting node network info...")
 	if err := dumpNetInfo(rpc, tmpDir, "net_info.json"); err != nil {
-		return err
+		logger.Error("failed to dump node network info", "error", err)
 	}
 
 	logger.Info("getting node consensus state...")
 	if err := dumpConsensusState(rpc, tmpDir, "consensus_state.json"); err != nil {
-		return err
+		logger.Error("failed to dump node consensus state", "error", err)
 	}
 
 	logger.Info("copying node WAL...")
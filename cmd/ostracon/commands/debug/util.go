@@ -68,7 +68,7 @@ func copyConfig(home, dir string) error {
 func dumpProfile(dir, addr, profile string, debug int) error {
 	endpoint := fmt.Sprintf("%s/debug/pprof/%s?debug=%d", addr, profile, debug)
 
-	resp, err := net.HttpGet(endpoint, time.Duration(frequency)*time.Second)
+	resp, err := net.HttpGet(endpoint, time.Duration(timeout)*time.Second)
 	if err != nil {
 		return fmt.Errorf("failed to query for %s profile: %w", profile, err)
 	}
@@ -0,0 +1,346 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	tmstrings "github.com/Finschia/ostracon/libs/strings"
+	"github.com/Finschia/ostracon/types"
+)
+
+var genesisFilePath string
+
+// GenesisCmd is the root command of the subcommands editing the genesis file.
+var GenesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "Edit and validate the genesis file",
+	Long: `
+The genesis subcommands edit the genesis file (the one of the configuration by default)
+and validate the resulting document before replacing the file. The app_state, which can
+be very large, is never loaded in memory at once: it is streamed from the original file,
+module by module (i.e. by top-level key) when merging fragments into it.
+`,
+}
+
+func init() {
+	GenesisCmd.PersistentFlags().StringVar(&genesisFilePath, "genesis", "",
+		"path to the genesis file (defaults to the one of the configuration)")
+	ValidateGenesisCmd.Flags().StringVar(&genesisFilePath, "genesis", "",
+		"path to the genesis file (defaults to the one of the configuration)")
+
+	addValidatorCmd.Flags().Int64("power", 10, "voting power of the validator")
+	addValidatorCmd.Flags().String("name", "", "name of the validator")
+
+	GenesisCmd.AddCommand(addValidatorCmd)
+	GenesisCmd.AddCommand(removeValidatorCmd)
+	GenesisCmd.AddCommand(setConsensusParamsCmd)
+	GenesisCmd.AddCommand(mergeAppStateCmd)
+	GenesisCmd.AddCommand(validateGenesisCmd)
+	GenesisCmd.AddCommand(genesisHashCmd)
+}
+
+var addValidatorCmd = &cobra.Command{
+	Use:   "add-validator [pubkey]",
+	Short: "Add a validator to the genesis file",
+	Long: `
+Add-validator adds the validator with the given public key, as output by show-validator
+(e.g. {"type":"tendermint/PubKeyEd25519","value":"..."}), to the genesis file.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		power, err := cmd.Flags().GetInt64("power")
+		if err != nil {
+			return err
+		}
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		var pubKey crypto.PubKey
+		if err := tmjson.Unmarshal([]byte(args[0]), &pubKey); err != nil {
+			return fmt.Errorf("invalid public key %q: %w", args[0], err)
+		}
+		return editGenesisFile(func(doc *types.GenesisDoc) error {
+			return addGenesisValidator(doc, pubKey, power, name)
+		})
+	},
+}
+
+var removeValidatorCmd = &cobra.Command{
+	Use:   "remove-validator [address]",
+	Short: "Remove a validator from the genesis file",
+	Long: `
+Remove-validator removes the validator with the given (hex encoded) address from the
+genesis file.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, err := hex.DecodeString(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", args[0], err)
+		}
+		return editGenesisFile(func(doc *types.GenesisDoc) error {
+			return removeGenesisValidator(doc, address)
+		})
+	},
+}
+
+var setConsensusParamsCmd = &cobra.Command{
+	Use:   "set-consensus-params [key=value...]",
+	Short: "Set consensus params in the genesis file",
+	Long: `
+Set-consensus-params sets the given consensus params in the genesis file, by their key
+in the JSON document, e.g.:
+
+  ostracon genesis set-consensus-params block.max_bytes=2097152 \
+    evidence.max_age_duration=48h validator.pub_key_types=ed25519
+
+The durations are given as Go durations, and the lists as comma separated values.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editGenesisFile(func(doc *types.GenesisDoc) error {
+			if doc.ConsensusParams == nil {
+				doc.ConsensusParams = types.DefaultConsensusParams()
+			}
+			for _, arg := range args {
+				key, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("invalid consensus param %q, expected key=value", arg)
+				}
+				if err := setConsensusParam(doc.ConsensusParams, key, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var mergeAppStateCmd = &cobra.Command{
+	Use:   "merge-app-state [fragment.json...]",
+	Short: "Merge JSON fragments into the app_state of the genesis file",
+	Long: `
+Merge-app-state merges the JSON objects in the given files into the app_state of the
+genesis file, in order: the objects are merged recursively, and the other values of the
+fragments (including the arrays) replace the ones of the app_state.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fragment := make(map[string]json.RawMessage)
+		for _, file := range args {
+			bz, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			var f map[string]json.RawMessage
+			if err := json.Unmarshal(bz, &f); err != nil {
+				return fmt.Errorf("invalid fragment %s, expected a JSON object: %w", file, err)
+			}
+			for key, value := range f {
+				if existing, ok := fragment[key]; ok {
+					value = mergeJSON(existing, value)
+				}
+				fragment[key] = value
+			}
+		}
+
+		path := genesisPath()
+		g, err := types.OpenGenesisFile(path)
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		if err := writeGenesisFile(path, g.Doc, func(w io.Writer) error {
+			return mergeAppState(g, w, fragment)
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("Merged %d fragment(s) into the app_state of %s\n", len(args), path)
+		return nil
+	},
+}
+
+var validateGenesisCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the genesis file",
+	Args:  cobra.NoArgs,
+	RunE:  validateGenesis,
+}
+
+// ValidateGenesisCmd validates the genesis file, as "genesis validate".
+var ValidateGenesisCmd = &cobra.Command{
+	Use:   "validate-genesis",
+	Short: "Validate the genesis file",
+	Long: `
+Validate-genesis validates the genesis file (the one of the configuration by default) and
+its validators, without loading its app_state in memory, which is only checked to be valid
+JSON.
+`,
+	Args: cobra.NoArgs,
+	RunE: validateGenesis,
+}
+
+func validateGenesis(cmd *cobra.Command, args []string) error {
+	path := genesisPath()
+	g, err := types.OpenGenesisFile(path)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	if err := g.Doc.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid genesis document: %w", err)
+	}
+	var power int64
+	for _, val := range g.Doc.Validators {
+		power += val.Power
+	}
+	fmt.Printf("%s is valid: chain %s with %d validator(s) of total power %d\n",
+		path, g.Doc.ChainID, len(g.Doc.Validators), power)
+	return nil
+}
+
+var genesisHashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "Show the hash of the genesis document",
+	Long: `
+Hash shows the hash of the genesis document, as served by the genesis_chunked RPC of the
+nodes, to set statesync.genesis_hash to on the nodes fetching their genesis document
+from the RPC servers.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc, err := types.GenesisDocFromFile(genesisPath())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%X\n", doc.Hash())
+		return nil
+	},
+}
+
+func genesisPath() string {
+	if genesisFilePath != "" {
+		return genesisFilePath
+	}
+	return config.GenesisFile()
+}
+
+// editGenesisFile applies edit to the genesis document of the file and writes
+// it back with the same app_state, once validated.
+func editGenesisFile(edit func(doc *types.GenesisDoc) error) error {
+	path := genesisPath()
+	g, err := types.OpenGenesisFile(path)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+	if err := edit(g.Doc); err != nil {
+		return err
+	}
+	var writeAppState func(w io.Writer) error
+	if g.HasAppState() {
+		writeAppState = copyAppState(g)
+	}
+	if err := writeGenesisFile(path, g.Doc, writeAppState); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", path)
+	return nil
+}
+
+func addGenesisValidator(doc *types.GenesisDoc, pubKey crypto.PubKey, power int64, name string) error {
+	if power <= 0 {
+		return fmt.Errorf("the voting power must be positive, got %d", power)
+	}
+	for _, val := range doc.Validators {
+		if val.PubKey.Equals(pubKey) {
+			return fmt.Errorf("validator %s is already in the genesis file", pubKey.Address())
+		}
+	}
+	doc.Validators = append(doc.Validators, types.GenesisValidator{
+		Address: pubKey.Address(),
+		PubKey:  pubKey,
+		Power:   power,
+		Name:    name,
+	})
+	return nil
+}
+
+func removeGenesisValidator(doc *types.GenesisDoc, address []byte) error {
+	for i, val := range doc.Validators {
+		if bytes.Equal(val.PubKey.Address(), address) {
+			doc.Validators = append(doc.Validators[:i], doc.Validators[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("validator %X is not in the genesis file", address)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setConsensusParam sets the consensus param with the given key, made of the
+// JSON names of the fields (e.g. "block.max_bytes"), to the value.
+func setConsensusParam(params *tmproto.ConsensusParams, key, value string) error {
+	v := reflect.ValueOf(params).Elem()
+	for _, name := range strings.Split(key, ".") {
+		field, ok := fieldByJSONName(v, name)
+		if !ok {
+			return fmt.Errorf("unknown consensus param %q", key)
+		}
+		v = field
+	}
+
+	var err error
+	switch {
+	case v.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(value); err == nil {
+			v.SetInt(int64(d))
+		}
+	case v.Kind() == reflect.Int64 || v.Kind() == reflect.Int32:
+		var i int64
+		if i, err = strconv.ParseInt(value, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case v.Kind() == reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(value, 10, 64); err == nil {
+			v.SetUint(u)
+		}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		v.Set(reflect.ValueOf(tmstrings.SplitAndTrim(value, ",", " ")))
+	default:
+		return fmt.Errorf("%q is not a consensus param but a group of them", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q of consensus param %q: %w", value, key, err)
+	}
+	return nil
+}
+
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		tag := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if tag == name && tag != "" && tag != "-" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	"github.com/Finschia/ostracon/types"
+)
+
+// copyAppState returns a function writing the app_state of the file as it is.
+func copyAppState(g *types.GenesisFile) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		r, err := g.AppStateReader()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		return err
+	}
+}
+
+// mergeAppState writes the app_state of the file merged with the fragment:
+// the objects are merged recursively, the other values of the fragment replace
+// the ones of the app_state. The app_state is read module by module (i.e. by
+// top-level key), so that only one of them is loaded in memory at once.
+func mergeAppState(g *types.GenesisFile, w io.Writer, fragment map[string]json.RawMessage) error {
+	keys := make([]string, 0, len(fragment))
+	for key := range fragment {
+		keys = append(keys, key)
+	}
+	merged := make(map[string]bool, len(fragment))
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("{"); err != nil {
+		return err
+	}
+	first := true
+	writeMember := func(key string, value json.RawMessage) error {
+		if !first {
+			if _, err := bw.WriteString(","); err != nil {
+				return err
+			}
+		}
+		first = false
+		bz, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(append(bz, ':')); err != nil {
+			return err
+		}
+		_, err = bw.Write(value)
+		return err
+	}
+
+	if g.HasAppState() {
+		r, err := g.AppStateReader()
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(r)
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return errors.New("app_state must be an object")
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return fmt.Errorf("invalid app_state %s: %w", key, err)
+			}
+			if fragmentValue, ok := fragment[key]; ok {
+				value = mergeJSON(value, fragmentValue)
+				merged[key] = true
+			}
+			if err := writeMember(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the modules which aren't in the app_state yet are added in order
+	sort.Strings(keys)
+	for _, key := range keys {
+		if merged[key] {
+			continue
+		}
+		if err := writeMember(key, fragment[key]); err != nil {
+			return err
+		}
+	}
+	if _, err := bw.WriteString("}"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// mergeJSON merges the JSON value b into a: if both are objects, they are merged
+// recursively, otherwise b replaces a.
+func mergeJSON(a, b json.RawMessage) json.RawMessage {
+	var objA, objB map[string]json.RawMessage
+	if json.Unmarshal(a, &objA) != nil || json.Unmarshal(b, &objB) != nil || objA == nil || objB == nil {
+		return b
+	}
+	for key, valueB := range objB {
+		if valueA, ok := objA[key]; ok {
+			objA[key] = mergeJSON(valueA, valueB)
+		} else {
+			objA[key] = valueB
+		}
+	}
+	merged, err := json.Marshal(objA)
+	if err != nil {
+		// the values are valid JSON, as they have been unmarshaled
+		panic(err)
+	}
+	return merged
+}
+
+// writeGenesisFile writes the genesis document and the app_state written by
+// writeAppState (if not nil) to the file at path, through a temporary file which
+// is validated before replacing the file.
+func writeGenesisFile(path string, doc *types.GenesisDoc, writeAppState func(w io.Writer) error) error {
+	if err := doc.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid genesis document: %w", err)
+	}
+	docCopy := *doc
+	docCopy.AppState = nil
+	bz, err := tmjson.MarshalIndent(&docCopy, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	if writeAppState == nil {
+		_, err = w.Write(bz)
+	} else {
+		// splice the app_state in as the last field of the document
+		_, err = w.Write(append(bytes.TrimSuffix(bz, []byte("\n}")), []byte(",\n  \"app_state\": ")...))
+		if err == nil {
+			err = writeAppState(w)
+		}
+		if err == nil {
+			_, err = w.WriteString("\n}")
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the genesis file: %w", err)
+	}
+
+	// re-validate the resulting document before replacing the file
+	g, err := types.OpenGenesisFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	err = g.Doc.ValidateBasic()
+	g.Close()
+	if err != nil {
+		return fmt.Errorf("invalid genesis document: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
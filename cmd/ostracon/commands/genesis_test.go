@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/secp256k1"
+	"github.com/Finschia/ostracon/types"
+)
+
+const testGenesis = `{
+  "genesis_time": "2023-01-01T00:00:00Z",
+  "chain_id": "test-chain",
+  "initial_height": "1",
+  "app_hash": "",
+  "app_state": {
+    "bank": {"balances": [{"address": "a", "amount": "1"}], "params": {"send_enabled": true}},
+    "auth": {"accounts": []}
+  }
+}`
+
+func writeTestGenesis(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(path, []byte(testGenesis), 0o644))
+	return path
+}
+
+func TestGenesisFileEdit(t *testing.T) {
+	path := writeTestGenesis(t)
+	g, err := types.OpenGenesisFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "test-chain", g.Doc.ChainID)
+	require.Nil(t, g.Doc.AppState)
+	require.True(t, g.HasAppState())
+
+	pubKey := ed25519.GenPrivKey().PubKey()
+	require.NoError(t, addGenesisValidator(g.Doc, pubKey, 10, "val"))
+	require.Error(t, addGenesisValidator(g.Doc, pubKey, 10, "val"))
+	require.Error(t, addGenesisValidator(g.Doc, ed25519.GenPrivKey().PubKey(), 0, "val"))
+	require.NoError(t, writeGenesisFile(path, g.Doc, copyAppState(g)))
+	require.NoError(t, g.Close())
+
+	// the app_state is kept as it is
+	genDoc, err := types.GenesisDocFromFile(path)
+	require.NoError(t, err)
+	require.Len(t, genDoc.Validators, 1)
+	require.Equal(t, pubKey, genDoc.Validators[0].PubKey)
+	require.JSONEq(t, `{
+		"bank": {"balances": [{"address": "a", "amount": "1"}], "params": {"send_enabled": true}},
+		"auth": {"accounts": []}
+	}`, string(genDoc.AppState))
+
+	g, err = types.OpenGenesisFile(path)
+	require.NoError(t, err)
+	defer g.Close()
+	require.Error(t, removeGenesisValidator(g.Doc, ed25519.GenPrivKey().PubKey().Address()))
+	require.NoError(t, removeGenesisValidator(g.Doc, pubKey.Address()))
+	require.Empty(t, g.Doc.Validators)
+}
+
+func TestGenesisFileInvalid(t *testing.T) {
+	path := writeTestGenesis(t)
+	g, err := types.OpenGenesisFile(path)
+	require.NoError(t, err)
+	defer g.Close()
+
+	// the keys must be allowed by the consensus params
+	require.NoError(t, addGenesisValidator(g.Doc, secp256k1.GenPrivKey().PubKey(), 10, "val"))
+	err = writeGenesisFile(path, g.Doc, copyAppState(g))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't allowed by the consensus params")
+
+	// the file is left unchanged
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, testGenesis, string(bz))
+
+	// the app_state must be valid JSON
+	require.NoError(t, os.WriteFile(path, []byte(`{"chain_id": "test-chain", "app_state": {"bank": [}`), 0o644))
+	_, err = types.OpenGenesisFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid app_state")
+}
+
+func TestGenesisFileMergeAppState(t *testing.T) {
+	path := writeTestGenesis(t)
+	g, err := types.OpenGenesisFile(path)
+	require.NoError(t, err)
+	defer g.Close()
+
+	fragment := map[string]json.RawMessage{
+		"bank":    json.RawMessage(`{"balances": [], "params": {"default_send_enabled": false}}`),
+		"staking": json.RawMessage(`{"validators": []}`),
+	}
+	require.NoError(t, writeGenesisFile(path, g.Doc, func(w io.Writer) error {
+		return mergeAppState(g, w, fragment)
+	}))
+
+	genDoc, err := types.GenesisDocFromFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"bank": {"balances": [], "params": {"send_enabled": true, "default_send_enabled": false}},
+		"auth": {"accounts": []},
+		"staking": {"validators": []}
+	}`, string(genDoc.AppState))
+}
+
+func TestSetConsensusParam(t *testing.T) {
+	params := types.DefaultConsensusParams()
+	require.NoError(t, setConsensusParam(params, "block.max_bytes", "2097152"))
+	require.NoError(t, setConsensusParam(params, "evidence.max_age_duration", "24h"))
+	require.NoError(t, setConsensusParam(params, "validator.pub_key_types", "ed25519, secp256k1"))
+	require.NoError(t, setConsensusParam(params, "version.app_version", "3"))
+	assert.EqualValues(t, 2097152, params.Block.MaxBytes)
+	assert.Equal(t, 24*time.Hour, params.Evidence.MaxAgeDuration)
+	assert.Equal(t, []string{"ed25519", "secp256k1"}, params.Validator.PubKeyTypes)
+	assert.EqualValues(t, 3, params.Version.AppVersion)
+
+	require.Error(t, setConsensusParam(params, "block.unknown", "1"))
+	require.Error(t, setConsensusParam(params, "block", "1"))
+	require.Error(t, setConsensusParam(params, "block.max_bytes", "abc"))
+}
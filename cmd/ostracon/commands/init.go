@@ -8,6 +8,7 @@ import (
 	cfg "github.com/Finschia/ostracon/config"
 	tmos "github.com/Finschia/ostracon/libs/os"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
+	"github.com/Finschia/ostracon/node"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/privval"
 	"github.com/Finschia/ostracon/types"
@@ -32,14 +33,24 @@ func initFilesWithConfig(config *cfg.Config) error {
 	// private validator
 	privValKeyFile := config.PrivValidatorKeyFile()
 	privValStateFile := config.PrivValidatorStateFile()
-	var pv *privval.FilePV
-	if tmos.FileExists(privValKeyFile) {
+	var pv types.PrivValidator
+	if config.PrivValidatorBackend == cfg.PrivValidatorBackendPKCS11 {
+		// the key is in the token
+		pkcs11PV, err := node.CreatePrivValidatorPKCS11(config)
+		if err != nil {
+			return err
+		}
+		defer pkcs11PV.Close()
+		pv = pkcs11PV
+		logger.Info("Found private validator in the PKCS#11 token", "stateFile", privValStateFile)
+	} else if tmos.FileExists(privValKeyFile) {
 		pv = privval.LoadFilePV(privValKeyFile, privValStateFile)
 		logger.Info("Found private validator", "keyFile", privValKeyFile,
 			"stateFile", privValStateFile)
 	} else {
-		pv = privval.GenFilePV(privValKeyFile, privValStateFile)
-		pv.Save()
+		filePV := privval.GenFilePV(privValKeyFile, privValStateFile)
+		filePV.Save()
+		pv = filePV
 		logger.Info("Generated private validator", "keyFile", privValKeyFile,
 			"stateFile", privValStateFile)
 	}
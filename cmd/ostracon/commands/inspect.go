@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Finschia/ostracon/inspect"
+	tmos "github.com/Finschia/ostracon/libs/os"
+)
+
+// InspectCmd serves a subset of the RPC endpoints from the data directory,
+// without running the node.
+var InspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "serve the blocks, results, validators and transactions of a stopped node over RPC",
+	Long: `
+Inspect opens the data directory of a stopped (e.g. crashed) node read-only and serves
+the following RPC endpoints from it, on the rpc.laddr address:
+
+	health, blockchain, block, block_by_hash, block_results, commit,
+	tx, tx_search, block_search, validators, consensus_params
+
+Neither the p2p layer nor consensus are started, the application isn't connected and
+nothing is written to the data directory, so the node can be debugged safely.
+Only the goleveldb database backend is supported.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ins, err := inspect.NewFromConfig(config, logger)
+		if err != nil {
+			return fmt.Errorf("failed to open the data directory: %w", err)
+		}
+		if err := ins.Start(); err != nil {
+			return fmt.Errorf("failed to start inspecting: %w", err)
+		}
+		logger.Info("Inspecting the data directory", "dir", config.DBDir(), "rpc", ins.Addrs())
+
+		// Stop upon receiving SIGTERM or CTRL-C.
+		tmos.TrapSignal(logger, func() {
+			if err := ins.Stop(); err != nil {
+				logger.Error("unable to stop inspecting", "error", err)
+			}
+		})
+
+		// Run forever.
+		select {}
+	},
+}
+
+func init() {
+	InspectCmd.Flags().String("rpc.laddr", config.RPC.ListenAddress, "RPC listen address. Port required")
+	InspectCmd.Flags().String("db_dir", config.DBPath, "database directory")
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -22,7 +23,10 @@ import (
 	lproxy "github.com/Finschia/ostracon/light/proxy"
 	lrpc "github.com/Finschia/ostracon/light/rpc"
 	dbs "github.com/Finschia/ostracon/light/store/db"
+	rpcclient "github.com/Finschia/ostracon/rpc/client"
+	rpchttp "github.com/Finschia/ostracon/rpc/client/http"
 	rpcserver "github.com/Finschia/ostracon/rpc/jsonrpc/server"
+	"github.com/Finschia/ostracon/types"
 )
 
 // LightCmd represents the base command when called without any subcommands
@@ -62,10 +66,12 @@ var (
 	maxOpenConnections int
 
 	sequential     bool
+	verifyEntropy  bool
 	trustingPeriod time.Duration
 	trustedHeight  int64
 	trustedHash    []byte
 	trustLevelStr  string
+	blockPartSize  uint32
 
 	verbose bool
 
@@ -98,6 +104,14 @@ func init() {
 	LightCmd.Flags().BoolVar(&sequential, "sequential", false,
 		"sequential verification. Verify all headers sequentially as opposed to using skipping verification",
 	)
+	LightCmd.Flags().BoolVar(&verifyEntropy, "verify-entropy", false,
+		"verify the VRF proof and round of every new block, i.e. that its proposer was elected. "+
+			"Fetches two blocks from the primary per new header",
+	)
+	LightCmd.Flags().Uint32Var(&blockPartSize, "block-part-size", 0,
+		"size of the block parts of the chain, needed to verify the blocks. "+
+			"Read from the genesis of the primary if zero",
+	)
 }
 
 func runProxy(cmd *cobra.Command, args []string) error {
@@ -171,6 +185,9 @@ func runProxy(cmd *cobra.Command, args []string) error {
 	} else {
 		options = append(options, light.SkippingVerification(trustLevel))
 	}
+	if verifyEntropy {
+		options = append(options, light.EntropyVerification())
+	}
 
 	var c *light.Client
 	if trustedHeight > 0 && len(trustedHash) > 0 { // fresh installation
@@ -213,7 +230,15 @@ func runProxy(cmd *cobra.Command, args []string) error {
 		cfg.WriteTimeout = config.RPC.TimeoutBroadcastTxCommit + 1*time.Second
 	}
 
-	p, err := lproxy.NewProxy(c, listenAddr, primaryAddr, cfg, logger, lrpc.KeyPathFn(lrpc.DefaultMerkleKeyPathFn()))
+	if blockPartSize == 0 {
+		blockPartSize, err = genesisBlockPartSize(context.Background(), primaryAddr)
+		if err != nil {
+			return fmt.Errorf("can't get the block part size from the primary (set --block-part-size): %w", err)
+		}
+	}
+
+	p, err := lproxy.NewProxy(c, listenAddr, primaryAddr, cfg, logger,
+		lrpc.KeyPathFn(lrpc.DefaultMerkleKeyPathFn()), lrpc.BlockPartSize(blockPartSize))
 	if err != nil {
 		return err
 	}
@@ -232,6 +257,25 @@ func runProxy(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// genesisBlockPartSize returns the size of the block parts set in the genesis
+// of the node. The genesis isn't verified, but a wrong size can only make the
+// blocks fail to be verified, as their IDs are checked against the trusted ones.
+func genesisBlockPartSize(ctx context.Context, addr string) (uint32, error) {
+	c, err := rpchttp.New(addr, "/websocket")
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	if err := rpcclient.WriteGenesis(ctx, c, &buf); err != nil {
+		return 0, err
+	}
+	genDoc, err := types.GenesisDocFromJSON(buf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	return genDoc.BlockPartSizeBytes, nil
+}
+
 func checkForExistingProviders(db dbm.DB) (string, []string, error) {
 	primaryBytes, err := db.Get(primaryKey)
 	if err != nil {
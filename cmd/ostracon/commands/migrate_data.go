@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	tmos "github.com/Finschia/ostracon/libs/os"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/privval"
+	"github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/types"
+)
+
+// migrationSources are the versions of Tendermint whose data can be migrated.
+var migrationSources = map[string]struct{}{
+	"tendermint-v0.34": {},
+}
+
+// tendermintSuffix is appended to the files set aside by the migration.
+const tendermintSuffix = ".tendermint"
+
+// MigrateDataCmd converts the data of a Tendermint node to Ostracon.
+var MigrateDataCmd = &cobra.Command{
+	Use:   "migrate-data",
+	Short: "migrate the data of a Tendermint node to Ostracon",
+	Long: `
+Migrate-data converts the home directory of a stopped Tendermint node (given with --home)
+to Ostracon in place, so that an existing network can switch to Ostracon from its latest
+height without a genesis restart:
+
+  - the genesis, the node key and the validator key and last signed state are checked to
+    be readable by Ostracon, which uses the same formats; the validator key must be an
+    ed25519 key, as it has to generate the VRF proofs of the blocks;
+  - the configuration file is rewritten with the Ostracon settings, keeping the values it
+    sets (the original file is kept with the .tendermint suffix);
+  - the state is converted: Ostracon selects the proposers with the VRF output of the
+    previous block (the proof hash), which is derived from the hash of the last block
+    committed by Tendermint; the blocks are kept as they are;
+  - the consensus WAL is set aside (with the .tendermint suffix), as the last signed
+    state of the validator already prevents it from double signing.
+
+All the validators of the network must be stopped at the same height and migrated
+before restarting with Ostracon, and all the validators must have ed25519 keys. The
+application must support the Ostracon ABCI. The blocks committed before the migration
+have no VRF proof, so the nodes joining the network afterwards must state sync from a
+snapshot taken after the migration height.
+
+Back up the home directory before migrating it.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		st, err := MigrateData(config, from)
+		if err != nil {
+			return fmt.Errorf("failed to migrate data: %w", err)
+		}
+
+		fmt.Printf("Migrated the data of %s at height %d and hash %X\n", from, st.LastBlockHeight, st.AppHash)
+		fmt.Printf("The proof hash of height %d is %X\n", st.LastBlockHeight+1, st.LastProofHash)
+		return nil
+	},
+}
+
+func init() {
+	MigrateDataCmd.Flags().String("from", "", "version of the data to migrate: tendermint-v0.34")
+}
+
+// MigrateData converts the home directory of a Tendermint node of the given
+// version to Ostracon, see MigrateDataCmd. It returns the migrated state.
+func MigrateData(config *cfg.Config, from string) (state.State, error) {
+	if _, ok := migrationSources[from]; !ok {
+		return state.State{}, fmt.Errorf("unsupported source %q, expected tendermint-v0.34", from)
+	}
+
+	// check everything which isn't converted before changing anything
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	if err != nil {
+		return state.State{}, err
+	}
+	if err := checkKeyFiles(config); err != nil {
+		return state.State{}, err
+	}
+
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return state.State{}, err
+	}
+	defer func() {
+		_ = blockStore.Close()
+		_ = stateStore.Close()
+	}()
+	st, err := state.MigrateFromTendermint(blockStore, stateStore, genDoc)
+	if err != nil {
+		return state.State{}, err
+	}
+	logger.Info("Migrated state", "height", st.LastBlockHeight, "proof_hash", fmt.Sprintf("%X", st.LastProofHash))
+
+	walDir := filepath.Dir(config.Consensus.WalFile())
+	if tmos.FileExists(walDir) {
+		if err := os.Rename(walDir, walDir+tendermintSuffix); err != nil {
+			return state.State{}, fmt.Errorf("failed to set the consensus WAL aside: %w", err)
+		}
+		logger.Info("Set the consensus WAL aside", "path", walDir+tendermintSuffix)
+	}
+
+	if err := migrateConfigFile(config); err != nil {
+		return state.State{}, err
+	}
+	return st, nil
+}
+
+// checkKeyFiles checks that the node key and the validator key and last signed
+// state can be read by Ostracon, and that the validator can generate VRF proofs.
+func checkKeyFiles(config *cfg.Config) error {
+	if _, err := p2p.LoadNodeKey(config.NodeKeyFile()); err != nil {
+		return fmt.Errorf("failed to read the node key: %w", err)
+	}
+
+	// there is no key file with a remote signer nor a PKCS#11 token
+	if config.PrivValidatorListenAddr != "" || config.PrivValidatorGRPCAddr != "" ||
+		config.PrivValidatorBackend == cfg.PrivValidatorBackendPKCS11 || !tmos.FileExists(config.PrivValidatorKeyFile()) {
+		return nil
+	}
+	keyJSONBytes, err := os.ReadFile(config.PrivValidatorKeyFile())
+	if err != nil {
+		return err
+	}
+	pvKey := privval.FilePVKey{}
+	if err := tmjson.Unmarshal(keyJSONBytes, &pvKey); err != nil {
+		return fmt.Errorf("failed to read the validator key %s: %w", config.PrivValidatorKeyFile(), err)
+	}
+	if _, ok := pvKey.PrivKey.(ed25519.PrivKey); !ok {
+		return fmt.Errorf("the validator key is a %s key, which doesn't support VRF", pvKey.PrivKey.Type())
+	}
+	if !pvKey.PrivKey.PubKey().Equals(pvKey.PubKey) {
+		return errors.New("the public key of the validator key doesn't match its private key")
+	}
+
+	stateJSONBytes, err := os.ReadFile(config.PrivValidatorStateFile())
+	if err != nil {
+		return err
+	}
+	pvState := privval.FilePVLastSignState{}
+	if err := tmjson.Unmarshal(stateJSONBytes, &pvState); err != nil {
+		return fmt.Errorf("failed to read the validator state %s: %w", config.PrivValidatorStateFile(), err)
+	}
+	return nil
+}
+
+// migrateConfigFile rewrites the configuration file with the Ostracon settings
+// and the values of the parsed configuration, and keeps the original one. The
+// settings which don't exist in Ostracon are dropped.
+func migrateConfigFile(config *cfg.Config) error {
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		configFile = filepath.Join(config.RootDir, "config", "config.toml")
+	}
+	if !tmos.FileExists(configFile) {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+	for _, issue := range cfg.CheckKeys(v.AllKeys()) {
+		logger.Info("Dropping setting", "key", issue.Key, "reason", issue.Message)
+	}
+
+	if err := tmos.CopyFile(configFile, configFile+tendermintSuffix); err != nil {
+		return fmt.Errorf("failed to keep the original configuration: %w", err)
+	}
+	cfg.WriteConfigFile(configFile, config)
+	logger.Info("Rewrote configuration", "path", configFile, "original", configFile+tendermintSuffix)
+	return nil
+}
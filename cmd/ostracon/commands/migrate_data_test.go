@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestMigrateDataUnsupportedSource(t *testing.T) {
+	config := cfg.TestConfig()
+	_, err := MigrateData(config, "tendermint-v0.38")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported source")
+}
+
+func TestMigrateData(t *testing.T) {
+	config := cfg.ResetTestRoot("migrate_data_test")
+	t.Cleanup(func() { _ = os.RemoveAll(config.RootDir) })
+	config.DBBackend = string(dbm.GoLevelDBBackend)
+	_, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+
+	// the state of a Tendermint node which has just initialized the chain
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	require.NoError(t, err)
+	st, err := state.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+	stateDB, err := dbm.NewDB("state", dbm.BackendType(config.DBBackend), config.DBDir())
+	require.NoError(t, err)
+	require.NoError(t, state.NewStore(stateDB, state.StoreOptions{}).Save(st))
+	// Tendermint doesn't have proof hashes
+	st.LastProofHash = nil
+	require.NoError(t, stateDB.Delete([]byte("proofHashKey:1")))
+	require.NoError(t, stateDB.Set([]byte("stateKey"), st.Bytes()))
+	require.NoError(t, stateDB.Close())
+	blockStoreDB, err := dbm.NewDB("blockstore", dbm.BackendType(config.DBBackend), config.DBDir())
+	require.NoError(t, err)
+	require.NoError(t, blockStoreDB.Close())
+
+	migrated, err := MigrateData(config, "tendermint-v0.34")
+	require.NoError(t, err)
+	require.EqualValues(t, genDoc.Hash(), migrated.LastProofHash)
+	require.FileExists(t, config.RootDir+"/config/config.toml"+tendermintSuffix)
+
+	_, err = MigrateData(config, "tendermint-v0.34")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already been migrated")
+}
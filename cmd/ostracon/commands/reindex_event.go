@@ -46,27 +46,28 @@ Note: This operation requires ABCIResponses. Do not set DiscardABCIResponses to
 want to use this command.
 	`,
 	Example: `
-	tendermint reindex-event
-	tendermint reindex-event --start-height 2
-	tendermint reindex-event --end-height 10
-	tendermint reindex-event --start-height 2 --end-height 10
+	ostracon reindex-event
+	ostracon reindex-event --start-height 2
+	ostracon reindex-event --end-height 10
+	ostracon reindex-event --start-height 2 --end-height 10
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		bs, ss, err := loadStateAndBlockStore(config)
 		if err != nil {
-			fmt.Println(reindexFailed, err)
-			return
+			return fmt.Errorf("%s%w", reindexFailed, err)
 		}
+		defer func() {
+			_ = bs.Close()
+			_ = ss.Close()
+		}()
 
 		if err := checkValidHeight(bs); err != nil {
-			fmt.Println(reindexFailed, err)
-			return
+			return fmt.Errorf("%s%w", reindexFailed, err)
 		}
 
 		bi, ti, err := loadEventSinks(config)
 		if err != nil {
-			fmt.Println(reindexFailed, err)
-			return
+			return fmt.Errorf("%s%w", reindexFailed, err)
 		}
 
 		riArgs := eventReIndexArgs{
@@ -78,10 +79,11 @@ want to use this command.
 			stateStore:   ss,
 		}
 		if err := eventReIndex(cmd, riArgs); err != nil {
-			panic(fmt.Errorf("%s: %w", reindexFailed, err))
+			return fmt.Errorf("%s%w", reindexFailed, err)
 		}
 
 		fmt.Println("event re-index finished")
+		return nil
 	},
 }
 
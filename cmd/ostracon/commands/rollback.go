@@ -1,45 +1,76 @@
 package commands
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	dbm "github.com/tendermint/tm-db"
 
 	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/libs/os"
 	"github.com/Finschia/ostracon/state"
 	"github.com/Finschia/ostracon/store"
 )
 
+var removeBlock bool
+
+func init() {
+	RollbackStateCmd.Flags().BoolVar(&removeBlock, "hard", false,
+		"also remove the last block, instead of keeping it to be re-executed")
+}
+
 var RollbackStateCmd = &cobra.Command{
-	Use:   "rollback",
-	Short: "rollback ostracon state by one height",
+	Use:   "rollback [n]",
+	Short: "rollback ostracon state by n heights (one by default)",
 	Long: `
 A state rollback is performed to recover from an incorrect application state transition,
 when Ostracon has persisted an incorrect app hash and is thus unable to make
-progress. Rollback overwrites a state at height n with the state at height n - 1.
-The application should also roll back to height n - 1. No blocks are removed, so upon
-restarting Ostracon the transactions in block n will be re-executed against the
-application.
+progress. Rollback overwrites a state at height h with the state at height h - n
+(n being one by default) and removes the blocks above height h - n + 1.
+The application should also roll back to height h - n. Block h - n + 1 is not removed
+(unless --hard is given), so upon restarting Ostracon the transactions in block
+h - n + 1 will be re-executed against the application, and the following blocks will
+be fetched from the peers again. The last signed state of the validator is not
+rolled back, so that it can't double sign the heights it has already signed.
 `,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		height, hash, err := RollbackState(config)
+		n := int64(1)
+		if len(args) == 1 {
+			var err error
+			n, err = strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number of heights %q: %w", args[0], err)
+			}
+		}
+
+		height, hash, err := RollbackState(config, n, removeBlock)
 		if err != nil {
 			return fmt.Errorf("failed to rollback state: %w", err)
 		}
 
-		fmt.Printf("Rolled back state to height %d and hash %v", height, hash)
+		fmt.Printf("Rolled back state to height %d and hash %X\n", height, hash)
+		fmt.Printf("The application must also be rolled back to height %d\n", height)
 		return nil
 	},
 }
 
-// RollbackState takes the state at the current height n and overwrites it with the state
-// at height n - 1. Note state here refers to ostracon state not application state.
+// RollbackState rolls back the state and the block store by n heights: the state at
+// the block store height h is overwritten with the state at height h - n, and the blocks
+// above height h - n + 1 are removed (above height h - n if removeBlock is true).
+// Note state here refers to ostracon state not application state.
 // Returns the latest state height and app hash alongside an error if there was one.
-func RollbackState(config *cfg.Config) (int64, []byte, error) {
+func RollbackState(config *cfg.Config, n int64, removeBlock bool) (int64, []byte, error) {
+	if n < 1 {
+		return -1, nil, fmt.Errorf("the number of heights to roll back must be at least 1, got %d", n)
+	}
+
 	// use the parsed config to load the block and state store
 	blockStore, stateStore, err := loadStateAndBlockStore(config)
 	if err != nil {
@@ -50,8 +81,75 @@ func RollbackState(config *cfg.Config) (int64, []byte, error) {
 		_ = stateStore.Close()
 	}()
 
+	// check everything needed is there before changing anything, to not leave the
+	// node half rolled back
+	if err := checkRollback(blockStore, stateStore, n); err != nil {
+		return -1, nil, err
+	}
+
 	// rollback the last state
-	return state.Rollback(blockStore, stateStore)
+	height, hash, err := state.Rollback(blockStore, stateStore)
+	for i := int64(1); i < n && err == nil; i++ {
+		// remove the block kept to be re-executed, to roll back one more height
+		if err = blockStore.DeleteLatestBlock(); err == nil {
+			height, hash, err = state.Rollback(blockStore, stateStore)
+		}
+	}
+	if err != nil {
+		return -1, nil, err
+	}
+
+	if removeBlock {
+		if err := blockStore.DeleteLatestBlock(); err != nil {
+			return -1, nil, fmt.Errorf("failed to remove block at height %d: %w", height+1, err)
+		}
+	}
+	return height, hash, nil
+}
+
+// checkRollback checks that the blocks and the state needed to roll back n heights
+// are available, and that the proof hash of every state to restore matches the VRF
+// proof of its block, as the proof hash selects the proposers of the next height.
+func checkRollback(bs *store.BlockStore, ss state.Store, n int64) error {
+	st, err := ss.Load()
+	if err != nil {
+		return err
+	}
+	if st.IsEmpty() {
+		return errors.New("no state found")
+	}
+
+	target := bs.Height() - n
+	if target < bs.Base() || target < st.InitialHeight {
+		return fmt.Errorf("cannot roll back to height %d, below the first available block (base %d, initial height %d)",
+			target, bs.Base(), st.InitialHeight)
+	}
+
+	for h := target; h < st.LastBlockHeight; h++ {
+		if _, err := ss.LoadValidators(h); err != nil {
+			return fmt.Errorf("cannot roll back to height %d: %w", h, err)
+		}
+		if _, err := ss.LoadConsensusParams(h + 1); err != nil {
+			return fmt.Errorf("cannot roll back to height %d: %w", h, err)
+		}
+		proofHash, err := ss.LoadProofHash(h + 1)
+		if err != nil {
+			return fmt.Errorf("cannot roll back to height %d: %w", h, err)
+		}
+		block := bs.LoadBlock(h)
+		if block == nil {
+			return fmt.Errorf("cannot roll back to height %d: block not found", h)
+		}
+		blockProofHash, err := ed25519.ProofToHash(block.Entropy.Proof.Bytes())
+		if err != nil {
+			return fmt.Errorf("invalid VRF proof of block at height %d: %w", h, err)
+		}
+		if !bytes.Equal(proofHash, blockProofHash) {
+			return fmt.Errorf("proof hash %X of the state at height %d doesn't match the VRF proof of the block (%X)",
+				proofHash, h, blockProofHash)
+		}
+	}
+	return nil
 }
 
 func loadStateAndBlockStore(config *cfg.Config) (*store.BlockStore, state.Store, error) {
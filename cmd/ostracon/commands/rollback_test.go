@@ -12,3 +12,14 @@ func TestRollbackStateCmd(t *testing.T) {
 	err := RollbackStateCmd.RunE(RollbackStateCmd, nil)
 	require.Error(t, err)
 }
+
+func TestRollbackStateCmdInvalidHeights(t *testing.T) {
+	config = cfg.TestConfig()
+	err := RollbackStateCmd.RunE(RollbackStateCmd, []string{"abc"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid number of heights")
+
+	_, _, err = RollbackState(config, 0, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must be at least 1")
+}
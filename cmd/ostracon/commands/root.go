@@ -60,7 +60,8 @@ var RootCmd = &cobra.Command{
 	Use:   "ostracon",
 	Short: "BFT state machine replication for applications in any programming languages",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
-		if cmd.Name() == VersionCmd.Name() {
+		// the configuration is checked by validate-config itself
+		if cmd.Name() == VersionCmd.Name() || cmd.Name() == ValidateConfigCmd.Name() {
 			return nil
 		}
 
@@ -69,24 +70,40 @@ var RootCmd = &cobra.Command{
 			return err
 		}
 
-		if config.LogFormat == cfg.LogFormatJSON {
-			logger = log.NewOCJSONLogger(log.NewSyncWriter(os.Stdout))
-		}
-
-		logger, err = log.ParseLogLevel(config.LogLevel, logger, cfg.DefaultLogLevel)
+		// the logger can be reloaded with the log_level and log_format settings
+		rl, err := log.NewReloadableLogger(buildLogger, config.LogFormat, config.LogLevel)
 		if err != nil {
 			return err
 		}
-
-		if viper.GetBool(cli.TraceFlag) {
-			logger = log.NewTracingLogger(logger)
-		}
+		logger = rl
 
 		logger = logger.With("module", "main")
 		return nil
 	},
 }
 
+// buildLogger builds the logger of the node for the given format and level. It
+// also writes to the log file of the configuration, if any.
+func buildLogger(format, level string) (log.Logger, error) {
+	zeroLogConfig := log.NewZeroLogConfig(
+		format == cfg.LogFormatPlain,
+		level,
+		config.LogPath,
+		config.LogMaxAge,
+		config.LogMaxSize,
+		config.LogMaxBackups,
+	)
+	l, err := log.NewZeroLogLogger(zeroLogConfig, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if viper.GetBool(cli.TraceFlag) {
+		l = log.NewTracingLogger(l)
+	}
+	return l, nil
+}
+
 // deprecateSnakeCase is a util function for 0.34.1. Should be removed in 0.35
 func deprecateSnakeCase(cmd *cobra.Command, args []string) {
 	if strings.Contains(cmd.CalledAs(), "_") {
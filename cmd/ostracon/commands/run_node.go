@@ -3,11 +3,13 @@ package commands
 import (
 	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	cfg "github.com/Finschia/ostracon/config"
 	tmos "github.com/Finschia/ostracon/libs/os"
@@ -23,12 +25,18 @@ var (
 func AddNodeFlags(cmd *cobra.Command) {
 	// bind flags
 	cmd.Flags().String("moniker", config.Moniker, "node name")
+	cmd.Flags().Bool("strict", false,
+		"refuse to start on any issue found in the configuration (see validate-config)")
 
 	// priv val flags
 	cmd.Flags().String(
 		"priv_validator_laddr",
 		config.PrivValidatorListenAddr,
 		"socket address to listen on for connections from external priv_validator process")
+	cmd.Flags().String(
+		"priv_validator_grpc_addr",
+		config.PrivValidatorGRPCAddr,
+		"gRPC address of external priv_validator process to dial")
 
 	// node flags
 	cmd.Flags().Bool("fast_sync", config.FastSyncMode, "fast blockchain syncing")
@@ -70,9 +78,14 @@ func AddNodeFlags(cmd *cobra.Command) {
 	cmd.Flags().String("p2p.unconditional_peer_ids",
 		config.P2P.UnconditionalPeerIDs, "comma-delimited IDs of unconditional peers")
 	cmd.Flags().Bool("p2p.upnp", config.P2P.UPNP, "enable/disable UPNP port forwarding")
+	cmd.Flags().Bool("p2p.nat_pmp", config.P2P.NATPMP, "enable/disable NAT-PMP port forwarding")
+	cmd.Flags().Bool("p2p.learn_external_address", config.P2P.LearnExternalAddress,
+		"advertise the IP the outbound peers report the node is seen from")
 	cmd.Flags().Bool("p2p.pex", config.P2P.PexReactor, "enable/disable Peer-Exchange")
 	cmd.Flags().Bool("p2p.seed_mode", config.P2P.SeedMode, "enable/disable seed mode")
 	cmd.Flags().String("p2p.private_peer_ids", config.P2P.PrivatePeerIDs, "comma-delimited private peer IDs")
+	cmd.Flags().String("p2p.compression", config.P2P.Compression,
+		"compress the block parts and the snapshot chunks with snappy or zstd")
 
 	// consensus flags
 	cmd.Flags().Bool(
@@ -103,6 +116,19 @@ func NewRunNodeCmd(nodeProvider nm.Provider) *cobra.Command {
 		Aliases: []string{"node", "run"},
 		Short:   "Run the ostracon node",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if strict, _ := cmd.Flags().GetBool("strict"); strict {
+				issues, err := checkConfig(config, viper.ConfigFileUsed())
+				if err != nil {
+					return err
+				}
+				if len(issues) > 0 {
+					for _, issue := range issues {
+						logger.Error("Configuration issue", "issue", issue)
+					}
+					return errors.New("refusing to start with --strict as the configuration has issues")
+				}
+			}
+
 			if err := checkGenesisHash(config); err != nil {
 				return err
 			}
@@ -111,6 +137,12 @@ func NewRunNodeCmd(nodeProvider nm.Provider) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to create node: %w", err)
 			}
+			n.SetConfigLoader(func() (*cfg.Config, error) {
+				if err := viper.ReadInConfig(); err != nil {
+					return nil, err
+				}
+				return ParseConfig(cmd)
+			})
 
 			if err := n.Start(); err != nil {
 				return fmt.Errorf("failed to start node: %w", err)
@@ -118,6 +150,16 @@ func NewRunNodeCmd(nodeProvider nm.Provider) *cobra.Command {
 
 			logger.Info("Started node", "nodeInfo", n.Switch().NodeInfo())
 
+			// Reload the configuration upon receiving SIGHUP.
+			tmos.TrapReloadSignal(logger, func() {
+				changed, restart, err := n.ReloadConfigFile()
+				if err != nil {
+					logger.Error("unable to reload the configuration", "error", err)
+					return
+				}
+				logger.Info("Reloaded configuration", "changed", changed, "require_restart", restart)
+			})
+
 			// Stop upon receiving SIGTERM or CTRL-C.
 			tmos.TrapSignal(logger, func() {
 				if n.IsRunning() {
@@ -25,23 +25,11 @@ var ShowValidatorCmd = &cobra.Command{
 }
 
 func showValidator(cmd *cobra.Command, args []string, config *cfg.Config) error {
-	var pv types.PrivValidator
-	if config.PrivValidatorListenAddr != "" {
-		chainID, err := loadChainID(config)
-		if err != nil {
-			return err
-		}
-		pv, err = node.CreateAndStartPrivValidatorSocketClient(config, chainID, logger)
-		if err != nil {
-			return err
-		}
-	} else {
-		keyFilePath := config.PrivValidatorKeyFile()
-		if !tmos.FileExists(keyFilePath) {
-			return fmt.Errorf("private validator file %s does not exist", keyFilePath)
-		}
-		pv = privval.LoadFilePV(keyFilePath, config.PrivValidatorStateFile())
+	pv, closePV, err := loadPrivValidator(config)
+	if err != nil {
+		return err
 	}
+	defer closePV()
 
 	pubKey, err := pv.GetPubKey()
 	if err != nil {
@@ -57,6 +45,44 @@ func showValidator(cmd *cobra.Command, args []string, config *cfg.Config) error
 	return nil
 }
 
+// loadPrivValidator returns the private validator of the node, the remote
+// signer if configured, and the function releasing it.
+func loadPrivValidator(config *cfg.Config) (types.PrivValidator, func(), error) {
+	if config.PrivValidatorListenAddr != "" {
+		chainID, err := loadChainID(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		pv, err := node.CreateAndStartPrivValidatorSocketClient(config, chainID, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pv, func() {}, nil
+	} else if config.PrivValidatorGRPCAddr != "" {
+		chainID, err := loadChainID(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		pv, err := node.CreatePrivValidatorGRPCClient(config, chainID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pv, func() {}, nil
+	} else if config.PrivValidatorBackend == cfg.PrivValidatorBackendPKCS11 {
+		pkcs11PV, err := node.CreatePrivValidatorPKCS11(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pkcs11PV, func() { pkcs11PV.Close() }, nil
+	}
+
+	keyFilePath := config.PrivValidatorKeyFile()
+	if !tmos.FileExists(keyFilePath) {
+		return nil, nil, fmt.Errorf("private validator file %s does not exist", keyFilePath)
+	}
+	return privval.LoadFilePV(keyFilePath, config.PrivValidatorStateFile()), func() {}, nil
+}
+
 func loadChainID(config *cfg.Config) (string, error) {
 	stateDB, err := node.DefaultDBProvider(&node.DBContext{ID: "state", Config: config})
 	if err != nil {
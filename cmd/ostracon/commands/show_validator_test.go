@@ -190,3 +190,32 @@ func captureStdout(f func()) (string, error) {
 	output := buffer.String()
 	return output[:len(output)-1], nil
 }
+
+func TestStageValidatorKey(t *testing.T) {
+	original := config
+	defer func() {
+		config = original
+	}()
+
+	setupEnv(t)
+	config = cfg.DefaultConfig()
+	err := RootCmd.PersistentPreRunE(RootCmd, nil)
+	require.NoError(t, err)
+	init := NewInitCmd()
+	err = init.RunE(init, nil)
+	require.NoError(t, err)
+
+	require.Error(t, stageValidatorKey(config, 0))
+	output, err := captureStdout(func() {
+		err = stageValidatorKey(config, 100)
+		require.NoError(t, err)
+	})
+	require.NoError(t, err)
+
+	// the output is the staged key
+	pv := privval.LoadFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+	require.EqualValues(t, 100, pv.Key.NextKeyHeight)
+	bz, err := tmjson.Marshal(pv.Key.NextPubKey)
+	require.NoError(t, err)
+	require.Equal(t, string(bz), output)
+}
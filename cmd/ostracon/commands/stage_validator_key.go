@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	tmos "github.com/Finschia/ostracon/libs/os"
+	"github.com/Finschia/ostracon/privval"
+)
+
+// StageValidatorKeyCmd stages a new key of the validator, which signs from a
+// height on.
+var StageValidatorKeyCmd = &cobra.Command{
+	Use:   "stage-validator-key",
+	Short: "Stage a new key of the validator, which signs from a height on",
+	Long: `
+Generates a new key of the local private validator and stages it in the key file: the
+votes and proposals are signed with the new key from the height given with --height
+on, and with the current key below. The public key of the new key is printed, for the
+application to replace the current key with it in the validator set at that height
+(the validator updates returned in EndBlock at height H take effect at height H+2).
+
+As the last sign state is shared by both keys, they can't double sign during the
+transition. The node must be stopped while the key is staged.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		height, err := cmd.Flags().GetInt64("height")
+		if err != nil {
+			return err
+		}
+		return stageValidatorKey(config, height)
+	},
+}
+
+func init() {
+	StageValidatorKeyCmd.Flags().Int64("height", 0, "height from which the new key signs")
+}
+
+func stageValidatorKey(config *cfg.Config, height int64) error {
+	if height <= 0 {
+		return errors.New("the height of the new key must be given with --height")
+	}
+	keyFilePath := config.PrivValidatorKeyFile()
+	if !tmos.FileExists(keyFilePath) {
+		return fmt.Errorf("private validator file %s does not exist", keyFilePath)
+	}
+	pv := privval.LoadFilePV(keyFilePath, config.PrivValidatorStateFile())
+
+	privKey := ed25519.GenPrivKey()
+	if err := pv.StageKey(privKey, height); err != nil {
+		return fmt.Errorf("failed to stage the key: %w", err)
+	}
+	logger.Info("Staged the new key of the private validator", "height", height,
+		"address", privKey.PubKey().Address())
+
+	bz, err := tmjson.Marshal(privKey.PubKey())
+	if err != nil {
+		return fmt.Errorf("failed to marshal the new pubkey: %w", err)
+	}
+	fmt.Println(string(bz))
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -34,6 +35,15 @@ var (
 	hostnames               []string
 	p2pPort                 int
 	randomMonikers          bool
+
+	topology      string
+	nRegions      int
+	dockerCompose bool
+	dockerImage   string
+	linkLatency   time.Duration
+	linkLoss      float64
+	regionLatency time.Duration
+	regionLoss    float64
 )
 
 const (
@@ -74,6 +84,24 @@ func init() {
 		"P2P Port")
 	TestnetFilesCmd.Flags().BoolVar(&randomMonikers, "random-monikers", false,
 		"randomize the moniker for each generated node")
+
+	TestnetFilesCmd.Flags().StringVar(&topology, "topology", topologyFull,
+		"topology of the persistent peers: full | star | ring | regions")
+	TestnetFilesCmd.Flags().IntVar(&nRegions, "regions", 2,
+		"number of regions of the \"regions\" topology")
+	TestnetFilesCmd.Flags().BoolVar(&dockerCompose, "docker-compose", false,
+		"write a docker-compose.yml file running the testnet (requires starting-ip-address)")
+	TestnetFilesCmd.Flags().StringVar(&dockerImage, "docker-image", "ostracon/localnode",
+		"docker image of the nodes in the docker-compose.yml file")
+	TestnetFilesCmd.Flags().DurationVar(&linkLatency, "latency", 0,
+		"latency injected on the links between the nodes (requires starting-ip-address)")
+	TestnetFilesCmd.Flags().Float64Var(&linkLoss, "loss", 0,
+		"packet loss in percent injected on the links between the nodes (requires starting-ip-address)")
+	TestnetFilesCmd.Flags().DurationVar(&regionLatency, "region-latency", 0,
+		"latency added on the links between regions of the \"regions\" topology")
+	TestnetFilesCmd.Flags().Float64Var(&regionLoss, "region-loss", 0,
+		"packet loss in percent added on the links between regions of the \"regions\" topology"+
+			" (100 partitions the regions)")
 }
 
 // TestnetFilesCmd allows initialisation of files for an Ostracon testnet.
@@ -86,10 +114,25 @@ necessary files (private validator, genesis, config, etc.).
 Note, strict routability for addresses is turned off in the config file.
 
 Optionally, it will fill in persistent_peers list in config file using either hostnames or IPs.
+The persistent peers of each node follow the topology:
+
+  - full: every node has all the nodes as persistent peers (default)
+  - star: every node has the first node as persistent peer, which has all the nodes
+  - ring: every node has the previous and the next nodes as persistent peers
+  - regions: the nodes are split in regions, fully connected inside a region,
+    and the first node of each region is connected to the first node of the next one
+
+Latency and packet loss can be injected on the links between the nodes, and
+between the regions, with tc/netem: a netem.sh script is written in the node
+directories, which the ostracon/localnode docker image runs on start. A
+docker-compose.yml file running the testnet can be written as well.
 
 Example:
 
 	ostracon testnet --v 4 --o ./output --populate-persistent-peers --starting-ip-address 192.168.10.2
+
+	ostracon testnet --v 8 --o ./output --starting-ip-address 192.167.10.2 --topology regions --regions 2 \
+		--latency 20ms --region-latency 150ms --region-loss 1 --docker-compose
 	`,
 	RunE: testnetFiles,
 }
@@ -102,6 +145,16 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 		)
 	}
 
+	if err := validateNetworkConditions(); err != nil {
+		return err
+	}
+
+	nNodes := nValidators + nNonValidators
+	peers, err := topologyPeers(topology, nNodes, nRegions)
+	if err != nil {
+		return err
+	}
+
 	config := cfg.DefaultConfig()
 
 	// overwrite default config if set and valid
@@ -196,12 +249,9 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 	}
 
 	// Gather persistent peer addresses.
-	var (
-		persistentPeers string
-		err             error
-	)
+	var addresses []string
 	if populatePersistentPeers {
-		persistentPeers, err = persistentPeersString(config)
+		addresses, err = nodeAddresses(config)
 		if err != nil {
 			_ = os.RemoveAll(outputDir)
 			return err
@@ -215,13 +265,18 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 		config.P2P.AddrBookStrict = false
 		config.P2P.AllowDuplicateIP = true
 		if populatePersistentPeers {
-			config.P2P.PersistentPeers = persistentPeers
+			config.P2P.PersistentPeers = persistentPeersString(addresses, peers[i])
 		}
 		config.Moniker = moniker(i)
 
 		cfg.WriteConfigFile(filepath.Join(nodeDir, "config", "config.toml"), config)
 	}
 
+	if err := writeNetworkConditions(nNodes); err != nil {
+		_ = os.RemoveAll(outputDir)
+		return err
+	}
+
 	fmt.Printf("Successfully initialized %v node directories\n", nValidators+nNonValidators)
 	return nil
 }
@@ -246,18 +301,26 @@ func hostnameOrIP(i int) string {
 	return ip.String()
 }
 
-func persistentPeersString(config *cfg.Config) (string, error) {
-	persistentPeers := make([]string, nValidators+nNonValidators)
+func nodeAddresses(config *cfg.Config) ([]string, error) {
+	addresses := make([]string, nValidators+nNonValidators)
 	for i := 0; i < nValidators+nNonValidators; i++ {
 		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i))
 		config.SetRoot(nodeDir)
 		nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		persistentPeers[i] = p2p.IDAddressString(nodeKey.ID(), fmt.Sprintf("%s:%d", hostnameOrIP(i), p2pPort))
+		addresses[i] = p2p.IDAddressString(nodeKey.ID(), fmt.Sprintf("%s:%d", hostnameOrIP(i), p2pPort))
+	}
+	return addresses, nil
+}
+
+func persistentPeersString(addresses []string, peers []int) string {
+	persistentPeers := make([]string, len(peers))
+	for i, peer := range peers {
+		persistentPeers[i] = addresses[peer]
 	}
-	return strings.Join(persistentPeers, ","), nil
+	return strings.Join(persistentPeers, ",")
 }
 
 func moniker(i int) string {
@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Topologies of the persistent peers of a testnet.
+const (
+	// every node has all the nodes as persistent peers
+	topologyFull = "full"
+	// every node has the first node as persistent peer, which has all the nodes
+	topologyStar = "star"
+	// every node has the previous and the next nodes as persistent peers
+	topologyRing = "ring"
+	// the nodes are split in regions, fully connected inside a region, and the
+	// first node of each region is connected to the first node of the next one
+	topologyRegions = "regions"
+)
+
+const (
+	dockerComposeFile = "docker-compose.yml"
+	netemScriptFile   = "netem.sh"
+
+	dockerHostP2PPort = 26656
+	dockerRPCPort     = 26657
+)
+
+// topologyPeers returns the indexes of the persistent peers of each of the n
+// nodes for the given topology.
+func topologyPeers(topology string, n, numRegions int) ([][]int, error) {
+	peers := make([][]int, n)
+	switch topology {
+	case topologyFull:
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				peers[i] = append(peers[i], j)
+			}
+		}
+	case topologyStar:
+		for i := 1; i < n; i++ {
+			peers[0] = append(peers[0], i)
+			peers[i] = []int{0}
+		}
+	case topologyRing:
+		if n < 2 {
+			break
+		}
+		for i := 0; i < n; i++ {
+			peers[i] = appendPeer(peers[i], i, (i+n-1)%n)
+			peers[i] = appendPeer(peers[i], i, (i+1)%n)
+		}
+	case topologyRegions:
+		if numRegions < 1 || numRegions > n {
+			return nil, fmt.Errorf("number of regions must be between 1 and the number of nodes (%d), got %d",
+				n, numRegions)
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j && region(i, n, numRegions) == region(j, n, numRegions) {
+					peers[i] = append(peers[i], j)
+				}
+			}
+		}
+		if numRegions > 1 {
+			for r := 0; r < numRegions; r++ {
+				gateway := regionGateway(r, n, numRegions)
+				prev := regionGateway((r+numRegions-1)%numRegions, n, numRegions)
+				next := regionGateway((r+1)%numRegions, n, numRegions)
+				peers[gateway] = appendPeer(peers[gateway], gateway, prev)
+				peers[gateway] = appendPeer(peers[gateway], gateway, next)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown topology %q (must be %q, %q, %q or %q)",
+			topology, topologyFull, topologyStar, topologyRing, topologyRegions)
+	}
+	return peers, nil
+}
+
+// appendPeer appends peer to the peers of node i, unless it is i itself or
+// already one of them.
+func appendPeer(peers []int, i, peer int) []int {
+	if peer == i {
+		return peers
+	}
+	for _, p := range peers {
+		if p == peer {
+			return peers
+		}
+	}
+	return append(peers, peer)
+}
+
+// region returns the region of node i when splitting n nodes in numRegions
+// contiguous regions of (almost) the same size.
+func region(i, n, numRegions int) int {
+	return i * numRegions / n
+}
+
+// regionGateway returns the first node of region r.
+func regionGateway(r, n, numRegions int) int {
+	return (r*n + numRegions - 1) / numRegions
+}
+
+// linkCondition is the latency and the packet loss (in percent) injected on
+// the link from a node to another.
+type linkCondition struct {
+	Latency time.Duration
+	Loss    float64
+}
+
+func (c linkCondition) isZero() bool {
+	return c.Latency <= 0 && c.Loss <= 0
+}
+
+// linkConditions returns the conditions of the links from node i to each of
+// the n nodes. Links between regions have the region conditions added.
+func linkConditions(i, n int) []linkCondition {
+	conds := make([]linkCondition, n)
+	for j := 0; j < n; j++ {
+		if j == i {
+			continue
+		}
+		conds[j] = linkCondition{Latency: linkLatency, Loss: linkLoss}
+		if topology == topologyRegions && region(i, n, nRegions) != region(j, n, nRegions) {
+			conds[j].Latency += regionLatency
+			conds[j].Loss += regionLoss
+		}
+		if conds[j].Loss > 100 {
+			conds[j].Loss = 100
+		}
+	}
+	return conds
+}
+
+// netemScript returns a script injecting the given link conditions with tc/netem
+// on the traffic to the given IP addresses, or an empty string if there is no
+// condition to inject.
+func netemScript(conds []linkCondition, ips []string) string {
+	var sb strings.Builder
+	for j, cond := range conds {
+		if cond.isZero() {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("#!/bin/sh\n")
+			sb.WriteString("# Generated by ostracon testnet: conditions of the links to the other nodes\n")
+			sb.WriteString("set -e\n")
+			sb.WriteString("tc qdisc add dev eth0 root handle 1: htb default 1\n")
+			sb.WriteString("tc class add dev eth0 parent 1: classid 1:1 htb rate 10gbit\n")
+		}
+		class := j + 2
+		fmt.Fprintf(&sb, "tc class add dev eth0 parent 1: classid 1:%x htb rate 10gbit\n", class)
+		fmt.Fprintf(&sb, "tc qdisc add dev eth0 parent 1:%x handle %x: netem delay %dms loss %g%%\n",
+			class, class, cond.Latency.Milliseconds(), cond.Loss)
+		fmt.Fprintf(&sb, "tc filter add dev eth0 protocol ip parent 1: prio 1 u32 match ip dst %s/32 flowid 1:%x\n",
+			ips[j], class)
+	}
+	return sb.String()
+}
+
+type dockerComposeNode struct {
+	Name        string
+	ID          int
+	IP          string
+	HostP2PPort int
+	HostRPCPort int
+	NetAdmin    bool
+}
+
+type dockerComposeConfig struct {
+	Image   string
+	P2PPort int
+	RPCPort int
+	Subnet  string
+	Nodes   []dockerComposeNode
+}
+
+var dockerComposeTemplate = template.Must(template.New("docker-compose").Parse(`# Generated by ostracon testnet.
+# Copy the ostracon binary to this directory before running docker-compose up.
+version: '3'
+
+services:
+{{- range .Nodes }}
+  {{ .Name }}:
+    container_name: {{ .Name }}
+    image: "{{ $.Image }}"
+    ports:
+      - "{{ .HostP2PPort }}:{{ $.P2PPort }}"
+      - "{{ .HostRPCPort }}:{{ $.RPCPort }}"
+    environment:
+      - ID={{ .ID }}
+      - NODE_DIR={{ .Name }}
+      - LOG=${LOG:-ostracon.log}
+{{- if .NetAdmin }}
+    cap_add:
+      - NET_ADMIN
+{{- end }}
+    volumes:
+      - ./:/ostracon:Z
+    networks:
+      localnet:
+        ipv4_address: {{ .IP }}
+{{ end }}
+networks:
+  localnet:
+    driver: bridge
+    ipam:
+      driver: default
+      config:
+      -
+        subnet: {{ .Subnet }}
+`))
+
+// injectsLinkConditions returns whether latency or packet loss are injected on
+// the links between the nodes.
+func injectsLinkConditions() bool {
+	return linkLatency > 0 || linkLoss > 0 ||
+		(topology == topologyRegions && (regionLatency > 0 || regionLoss > 0))
+}
+
+// validateNetworkConditions returns an error if link conditions or the
+// docker-compose file are requested without IP addresses.
+func validateNetworkConditions() error {
+	if !injectsLinkConditions() && !dockerCompose {
+		return nil
+	}
+	if startingIPAddress == "" || len(hostnames) > 0 {
+		return errors.New("--starting-ip-address (and no --hostname) is required with --docker-compose," +
+			" --latency, --loss, --region-latency and --region-loss")
+	}
+	if net.ParseIP(startingIPAddress).To4() == nil {
+		return fmt.Errorf("%v: non ipv4 address", startingIPAddress)
+	}
+	return nil
+}
+
+// writeNetworkConditions writes the netem script of each node injecting the
+// link conditions, and the docker-compose file if requested.
+func writeNetworkConditions(n int) error {
+	if !injectsLinkConditions() && !dockerCompose {
+		return nil
+	}
+
+	ips := make([]string, n)
+	for i := 0; i < n; i++ {
+		ips[i] = hostnameOrIP(i)
+	}
+
+	nodes := make([]dockerComposeNode, n)
+	for i := 0; i < n; i++ {
+		nodeDirName := fmt.Sprintf("%s%d", nodeDirPrefix, i)
+		script := netemScript(linkConditions(i, n), ips)
+		if script != "" {
+			err := os.WriteFile(filepath.Join(outputDir, nodeDirName, netemScriptFile), []byte(script), 0o755) //nolint:gosec
+			if err != nil {
+				return err
+			}
+		}
+		nodes[i] = dockerComposeNode{
+			Name:        nodeDirName,
+			ID:          i,
+			IP:          ips[i],
+			HostP2PPort: dockerHostP2PPort + 2*i,
+			HostRPCPort: dockerHostP2PPort + 2*i + 1,
+			NetAdmin:    script != "",
+		}
+	}
+
+	if !dockerCompose {
+		return nil
+	}
+	ip := net.ParseIP(startingIPAddress).To4()
+	f, err := os.Create(filepath.Join(outputDir, dockerComposeFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return dockerComposeTemplate.Execute(f, dockerComposeConfig{
+		Image:   dockerImage,
+		P2PPort: p2pPort,
+		RPCPort: dockerRPCPort,
+		Subnet:  fmt.Sprintf("%d.%d.0.0/16", ip[0], ip[1]),
+		Nodes:   nodes,
+	})
+}
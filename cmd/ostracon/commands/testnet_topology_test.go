@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyPeers(t *testing.T) {
+	testCases := []struct {
+		topology   string
+		n          int
+		numRegions int
+		want       [][]int
+	}{
+		{topologyFull, 3, 0, [][]int{{0, 1, 2}, {0, 1, 2}, {0, 1, 2}}},
+		{topologyStar, 4, 0, [][]int{{1, 2, 3}, {0}, {0}, {0}}},
+		{topologyRing, 4, 0, [][]int{{3, 1}, {0, 2}, {1, 3}, {2, 0}}},
+		{topologyRing, 1, 0, [][]int{nil}},
+		{topologyRegions, 4, 1, [][]int{{1, 2, 3}, {0, 2, 3}, {0, 1, 3}, {0, 1, 2}}},
+		{topologyRegions, 5, 2, [][]int{{1, 2, 3}, {0, 2}, {0, 1}, {4, 0}, {3}}},
+		{topologyRegions, 6, 3, [][]int{{1, 4, 2}, {0}, {3, 0, 4}, {2}, {5, 2, 0}, {4}}},
+	}
+	for _, tc := range testCases {
+		peers, err := topologyPeers(tc.topology, tc.n, tc.numRegions)
+		require.NoError(t, err, tc.topology)
+		assert.Equal(t, tc.want, peers, "%s with %d nodes and %d regions", tc.topology, tc.n, tc.numRegions)
+	}
+
+	_, err := topologyPeers(topologyRegions, 2, 3)
+	assert.Error(t, err)
+	_, err = topologyPeers("mesh", 2, 0)
+	assert.Error(t, err)
+}
+
+func TestNetemScript(t *testing.T) {
+	ips := []string{"192.167.10.2", "192.167.10.3", "192.167.10.4"}
+	assert.Empty(t, netemScript([]linkCondition{{}, {}, {}}, ips))
+
+	script := netemScript([]linkCondition{{}, {}, {Latency: 150 * time.Millisecond, Loss: 1.5}}, ips)
+	assert.Contains(t, script, "tc qdisc add dev eth0 parent 1:4 handle 4: netem delay 150ms loss 1.5%\n")
+	assert.Contains(t, script, "match ip dst 192.167.10.4/32 flowid 1:4\n")
+	assert.NotContains(t, script, "192.167.10.3")
+}
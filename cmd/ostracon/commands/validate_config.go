@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/cli"
+)
+
+// validateConfigResult is the output of ValidateConfigCmd in JSON.
+type validateConfigResult struct {
+	File   string      `json:"file"`
+	Valid  bool        `json:"valid"`
+	Issues []cfg.Issue `json:"issues"`
+}
+
+// ValidateConfigCmd checks the configuration file.
+var ValidateConfigCmd = &cobra.Command{
+	Use:   "validate-config",
+	Short: "check the configuration file",
+	Long: `
+Validate-config checks the configuration file: the types and the ranges of the values,
+the constraints between settings (e.g. between timeouts), the unknown keys, which are
+ignored, and the deprecated keys, with their replacements.
+
+The configuration is invalid if there is any error, or any issue at all with --strict.
+With --output json, the result is written as a JSON object with the "valid" and
+"issues" fields, each issue having a "key", a "severity" ("error" or "warning"), a
+"message" and possibly a "replacement".
+
+The same checks are done when starting the node with --strict.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString(cli.OutputFlag)
+		if err != nil {
+			return err
+		}
+		if output != "text" && output != "json" {
+			return fmt.Errorf("unsupported output format: %s", output)
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+
+		configFile := viper.ConfigFileUsed()
+		if configFile == "" {
+			return errors.New("no configuration file found")
+		}
+		issues, err := validateConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+
+		res := validateConfigResult{File: configFile, Valid: isValidConfig(issues, strict), Issues: issues}
+		if err := writeValidateConfigResult(cmd.OutOrStdout(), output, res); err != nil {
+			return err
+		}
+		if !res.Valid {
+			return errors.New("the configuration is invalid")
+		}
+		return nil
+	},
+}
+
+func init() {
+	ValidateConfigCmd.Flags().StringP(cli.OutputFlag, "o", "text", "output format: text | json")
+	ValidateConfigCmd.Flags().Bool("strict", false, "consider the configuration invalid on warnings too")
+}
+
+// validateConfigFile checks the configuration file with the default values of
+// the settings it doesn't set.
+func validateConfigFile(configFile string) ([]cfg.Issue, error) {
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	var issues []cfg.Issue
+	conf := cfg.DefaultConfig()
+	if err := v.Unmarshal(conf); err != nil {
+		issues = append(issues, decodingIssues(err)...)
+	}
+	issues = append(issues, cfg.CheckKeys(v.AllKeys())...)
+	issues = append(issues, cfg.CheckConfig(conf)...)
+	return issues, nil
+}
+
+// checkConfig checks the configuration the node is about to run with, and
+// the keys of its configuration file (if any).
+func checkConfig(conf *cfg.Config, configFile string) ([]cfg.Issue, error) {
+	var issues []cfg.Issue
+	if configFile != "" {
+		v := viper.New()
+		v.SetConfigFile(configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+		}
+		issues = append(issues, cfg.CheckKeys(v.AllKeys())...)
+	}
+	return append(issues, cfg.CheckConfig(conf)...), nil
+}
+
+// decodingIssues returns an issue for each setting which couldn't be decoded,
+// e.g. because of a wrong type.
+func decodingIssues(err error) []cfg.Issue {
+	var issues []cfg.Issue
+	for _, line := range strings.Split(err.Error(), "\n") {
+		// the decoding errors are listed as "* cannot parse 'mempool.size' as int: ..."
+		msg := strings.TrimPrefix(strings.TrimSpace(line), "* ")
+		if msg == strings.TrimSpace(line) {
+			continue
+		}
+		issue := cfg.Issue{Severity: cfg.SeverityError, Message: msg}
+		if parts := strings.SplitN(msg, "'", 3); len(parts) == 3 {
+			issue.Key = parts[1]
+		}
+		issues = append(issues, issue)
+	}
+	if len(issues) == 0 {
+		issues = append(issues, cfg.Issue{Severity: cfg.SeverityError, Message: err.Error()})
+	}
+	return issues
+}
+
+func isValidConfig(issues []cfg.Issue, strict bool) bool {
+	for _, issue := range issues {
+		if strict || issue.Severity == cfg.SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+func writeValidateConfigResult(w io.Writer, output string, res validateConfigResult) error {
+	if output == "json" {
+		if res.Issues == nil {
+			res.Issues = []cfg.Issue{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	for _, issue := range res.Issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+	if res.Valid {
+		_, err := fmt.Fprintf(w, "%s is valid\n", res.File)
+		return err
+	}
+	return nil
+}
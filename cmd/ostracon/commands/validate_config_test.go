@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	cfg.WriteConfigFile(configFile, cfg.DefaultConfig())
+	issues, err := validateConfigFile(configFile)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	f, err := os.OpenFile(configFile, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n[unknown]\nsize = 1\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	issues, err = validateConfigFile(configFile)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "unknown.size", issues[0].Key)
+	assert.True(t, isValidConfig(issues, false))
+	assert.False(t, isValidConfig(issues, true))
+
+	var buf bytes.Buffer
+	res := validateConfigResult{File: configFile, Valid: false, Issues: issues}
+	require.NoError(t, writeValidateConfigResult(&buf, "json", res))
+	var decoded validateConfigResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, res, decoded)
+}
+
+func TestValidateConfigFileWrongType(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte("[mempool]\nsize = \"abc\"\n"), 0o600))
+	issues, err := validateConfigFile(configFile)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "mempool.size", issues[0].Key)
+	assert.Equal(t, cfg.SeverityError, issues[0].Severity)
+	assert.False(t, isValidConfig(issues, false))
+}
@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	tmbytes "github.com/Finschia/ostracon/libs/bytes"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	"github.com/Finschia/ostracon/privval"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// vrfKeyCheckMessage is the message proved and verified by show-vrf-key to
+// check the private validator can generate the proofs.
+var vrfKeyCheckMessage = []byte("ostracon show-vrf-key")
+
+// ShowVRFKeyCmd shows the VRF public key of the private validator, checking
+// it generates valid proofs.
+var ShowVRFKeyCmd = &cobra.Command{
+	Use:   "show-vrf-key",
+	Short: "Show this node's VRF public key",
+	Long: `
+Show the VRF public key of the private validator of this node, which is the validator key,
+once checked it generates proofs the key verifies. Only the ed25519 keys support VRF: a
+validator whose key doesn't can't propose blocks.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showVRFKey(config)
+	},
+}
+
+type vrfKey struct {
+	Address   types.Address    `json:"address"`
+	PubKey    crypto.PubKey    `json:"pub_key"`
+	VRFPubKey tmbytes.HexBytes `json:"vrf_pub_key"`
+}
+
+func showVRFKey(config *cfg.Config) error {
+	pv, closePV, err := loadPrivValidator(config)
+	if err != nil {
+		return err
+	}
+	defer closePV()
+
+	pubKey, err := pv.GetPubKey()
+	if err != nil {
+		return fmt.Errorf("can't get pubkey: %w", err)
+	}
+	proof, err := pv.GenerateVRFProof(vrfKeyCheckMessage)
+	if err != nil {
+		return fmt.Errorf("the %s key can't generate VRF proofs: %w", pubKey.Type(), err)
+	}
+	if _, err := pubKey.VRFVerify(proof, vrfKeyCheckMessage); err != nil {
+		return fmt.Errorf("the %s key doesn't verify its VRF proofs: %w", pubKey.Type(), err)
+	}
+
+	bz, err := tmjson.Marshal(vrfKey{
+		Address:   pubKey.Address(),
+		PubKey:    pubKey,
+		VRFPubKey: pubKey.Bytes(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal VRF key: %w", err)
+	}
+	fmt.Println(string(bz))
+	return nil
+}
+
+// GenVRFKeyCmd generates a validator key supporting VRF.
+var GenVRFKeyCmd = &cobra.Command{
+	Use:   "gen-vrf-key",
+	Short: "Generate new VRF keypair",
+	Long: `
+Generate a new ed25519 keypair, which supports VRF, and print it in the format of
+priv_validator_key.json.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return genVRFKey()
+	},
+}
+
+func genVRFKey() error {
+	privKey := ed25519.GenPrivKey()
+	key := privval.FilePVKey{
+		Address: privKey.PubKey().Address(),
+		PubKey:  privKey.PubKey(),
+		PrivKey: privKey,
+	}
+	bz, err := tmjson.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal VRF key: %w", err)
+	}
+	fmt.Println(string(bz))
+	return nil
+}
+
+var (
+	inspectProofBlockFile      string
+	inspectProofValidatorsFile string
+	inspectProofLastBlockFile  string
+	inspectProofLastProofHash  string
+	inspectProofGenesisFile    string
+)
+
+// InspectProofCmd verifies the VRF proof of a block offline.
+var InspectProofCmd = &cobra.Command{
+	Use:   "inspect-proof",
+	Short: "Verify the VRF proof of a block and print the elected proposer",
+	Long: `
+Verify offline that a block was proposed by the proposer elected from the validator set,
+and that its VRF proof was generated by it, then print the round and the elected proposer
+of the block, and the VRF output seeding the election at the next height.
+
+The block and the validators are given in the JSON output of the block and validators RPC
+endpoints at the height of the block, with all the validators (see the per_page parameter).
+The election at the height is seeded with the VRF output of the previous block, given with
+--last-block (the output of the block endpoint at the previous height) or --last-proof-hash,
+or the genesis file with --genesis for the initial block.
+
+	ostracon inspect-proof --block block.json --validators validators.json --last-block last.json
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return inspectProof()
+	},
+}
+
+func init() {
+	InspectProofCmd.Flags().StringVar(&inspectProofBlockFile, "block", "",
+		"JSON output of the block RPC endpoint")
+	InspectProofCmd.Flags().StringVar(&inspectProofValidatorsFile, "validators", "",
+		"JSON output of the validators RPC endpoint at the height of the block")
+	InspectProofCmd.Flags().StringVar(&inspectProofLastBlockFile, "last-block", "",
+		"JSON output of the block RPC endpoint at the previous height")
+	InspectProofCmd.Flags().StringVar(&inspectProofLastProofHash, "last-proof-hash", "",
+		"VRF output of the previous block, in hex")
+	InspectProofCmd.Flags().StringVar(&inspectProofGenesisFile, "genesis", "",
+		"genesis file, for the initial block")
+	_ = InspectProofCmd.MarkFlagRequired("block")
+	_ = InspectProofCmd.MarkFlagRequired("validators")
+}
+
+type proofInspection struct {
+	Height          int64            `json:"height"`
+	Round           int32            `json:"round"`
+	ProposerAddress types.Address    `json:"proposer_address"`
+	ElectedProposer types.Address    `json:"elected_proposer"`
+	Proof           tmbytes.HexBytes `json:"proof"`
+	ProofHash       tmbytes.HexBytes `json:"proof_hash,omitempty"`
+	Valid           bool             `json:"valid"`
+	Error           string           `json:"error,omitempty"`
+}
+
+func inspectProof() error {
+	var res ctypes.ResultBlock
+	if err := readRPCResult(inspectProofBlockFile, &res); err != nil {
+		return err
+	}
+	block := res.Block
+	if block == nil {
+		return fmt.Errorf("no block in %s", inspectProofBlockFile)
+	}
+	var resVals ctypes.ResultValidators
+	if err := readRPCResult(inspectProofValidatorsFile, &resVals); err != nil {
+		return err
+	}
+	if resVals.BlockHeight != block.Height {
+		return fmt.Errorf("the validators are those of height %d, not of the block at height %d",
+			resVals.BlockHeight, block.Height)
+	}
+	if len(resVals.Validators) != resVals.Total {
+		return fmt.Errorf("%s has %d of the %d validators", inspectProofValidatorsFile,
+			len(resVals.Validators), resVals.Total)
+	}
+	vals, err := types.ValidatorSetFromExistingValidators(resVals.Validators)
+	if err != nil {
+		return err
+	}
+	lastProofHash, err := loadLastProofHash(block.Height)
+	if err != nil {
+		return err
+	}
+
+	ins := proofInspection{
+		Height:          block.Height,
+		Round:           block.Round,
+		ProposerAddress: block.ProposerAddress,
+		ElectedProposer: vals.SelectProposer(lastProofHash, block.Height, block.Round).Address,
+		Proof:           block.Proof,
+	}
+	output, verifyErr := types.VerifyEntropy(block.Entropy, vals, lastProofHash, block.Height-1,
+		block.Height, block.ProposerAddress)
+	if verifyErr == nil {
+		ins.ProofHash = tmbytes.HexBytes(output)
+		ins.Valid = true
+	} else {
+		ins.Error = verifyErr.Error()
+	}
+
+	bz, err := tmjson.MarshalIndent(ins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the inspection: %w", err)
+	}
+	fmt.Println(string(bz))
+	if verifyErr != nil {
+		return fmt.Errorf("invalid VRF proof: %w", verifyErr)
+	}
+	return nil
+}
+
+// loadLastProofHash returns the VRF output seeding the proposer election at
+// the height, from the flag given.
+func loadLastProofHash(height int64) ([]byte, error) {
+	switch {
+	case inspectProofLastBlockFile != "":
+		var res ctypes.ResultBlock
+		if err := readRPCResult(inspectProofLastBlockFile, &res); err != nil {
+			return nil, err
+		}
+		if res.Block == nil {
+			return nil, fmt.Errorf("no block in %s", inspectProofLastBlockFile)
+		}
+		if res.Block.Height != height-1 {
+			return nil, fmt.Errorf("the last block is at height %d, not %d", res.Block.Height, height-1)
+		}
+		return res.Block.ProofHash()
+	case inspectProofLastProofHash != "":
+		return hex.DecodeString(strings.TrimPrefix(inspectProofLastProofHash, "0x"))
+	case inspectProofGenesisFile != "":
+		genDoc, err := types.GenesisDocFromFile(inspectProofGenesisFile)
+		if err != nil {
+			return nil, err
+		}
+		if genDoc.InitialHeight != height {
+			return nil, fmt.Errorf("the block at height %d isn't the initial block", height)
+		}
+		return genDoc.Hash(), nil
+	default:
+		return nil, errors.New("one of --last-block, --last-proof-hash or --genesis is required")
+	}
+}
+
+// readRPCResult decodes the result of an RPC endpoint saved in the file, with
+// or without its JSON-RPC envelope.
+func readRPCResult(file string, result interface{}) error {
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(bz, &envelope); err == nil && len(envelope.Result) > 0 &&
+		!bytes.Equal(envelope.Result, []byte("null")) {
+		bz = envelope.Result
+	}
+	if err := tmjson.Unmarshal(bz, result); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+
+	cfg "github.com/Finschia/ostracon/config"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+func writeJSON(t *testing.T, dir, name string, v interface{}) string {
+	bz, err := tmjson.Marshal(v)
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, bz, 0o600))
+	return path
+}
+
+func TestShowVRFKey(t *testing.T) {
+	original := config
+	defer func() {
+		config = original
+	}()
+
+	setupEnv(t)
+	config = cfg.DefaultConfig()
+	err := RootCmd.PersistentPreRunE(RootCmd, nil)
+	require.NoError(t, err)
+	init := NewInitCmd()
+	err = init.RunE(init, nil)
+	require.NoError(t, err)
+	output, err := captureStdout(func() {
+		err = ShowVRFKeyCmd.RunE(ShowVRFKeyCmd, nil)
+		require.NoError(t, err)
+	})
+	require.NoError(t, err)
+
+	// the VRF key is the locally stored priv_validator key
+	privKey := loadFilePVKey(t, config.PrivValidatorKeyFile())
+	var key vrfKey
+	require.NoError(t, tmjson.Unmarshal([]byte(output), &key))
+	require.Equal(t, privKey.Address, key.Address)
+	require.Equal(t, privKey.PubKey.Bytes(), []byte(key.VRFPubKey))
+}
+
+func TestInspectProof(t *testing.T) {
+	dir := t.TempDir()
+	privVals := []types.MockPV{types.NewMockPV(), types.NewMockPV()}
+	vals := make([]*types.Validator, len(privVals))
+	genVals := make([]types.GenesisValidator, len(privVals))
+	for i, pv := range privVals {
+		pubKey, err := pv.GetPubKey()
+		require.NoError(t, err)
+		vals[i] = types.NewValidator(pubKey, 10)
+		genVals[i] = types.GenesisValidator{PubKey: pubKey, Power: 10}
+	}
+	valSet := types.NewValidatorSet(vals)
+	genDoc := &types.GenesisDoc{ChainID: "test-chain", Validators: genVals}
+	require.NoError(t, genDoc.ValidateAndComplete())
+	genesisFile := filepath.Join(dir, "genesis.json")
+	require.NoError(t, genDoc.SaveAs(genesisFile))
+
+	// the initial block, proposed by the elected proposer
+	proposer := valSet.SelectProposer(genDoc.Hash(), 1, 0)
+	var proposerPV types.MockPV
+	for i, val := range vals {
+		if val.Address.String() == proposer.Address.String() {
+			proposerPV = privVals[i]
+		}
+	}
+	proof, err := proposerPV.GenerateVRFProof(types.MakeRoundHash(genDoc.Hash(), 0, 0))
+	require.NoError(t, err)
+	block := types.MakeBlock(1, nil, nil, nil, tmversion.Consensus{})
+	block.ProposerAddress = proposer.Address
+	block.Entropy.Populate(0, proof)
+
+	// the block is given with its JSON-RPC envelope
+	res, err := tmjson.Marshal(&ctypes.ResultBlock{Block: block})
+	require.NoError(t, err)
+	inspectProofBlockFile = writeJSON(t, dir, "block.json",
+		rpctypes.RPCResponse{JSONRPC: "2.0", Result: res})
+	inspectProofValidatorsFile = writeJSON(t, dir, "validators.json", &ctypes.ResultValidators{
+		BlockHeight: 1,
+		Validators:  valSet.Validators,
+		Count:       len(vals),
+		Total:       len(vals),
+	})
+	inspectProofGenesisFile = genesisFile
+	defer func() {
+		inspectProofBlockFile, inspectProofValidatorsFile, inspectProofGenesisFile = "", "", ""
+		inspectProofLastProofHash = ""
+	}()
+
+	output, err := captureStdout(func() {
+		require.NoError(t, inspectProof())
+	})
+	require.NoError(t, err)
+	var ins proofInspection
+	require.NoError(t, tmjson.Unmarshal([]byte(output), &ins))
+	require.True(t, ins.Valid)
+	require.Equal(t, proposer.Address, ins.ElectedProposer)
+	proofHash, err := block.ProofHash()
+	require.NoError(t, err)
+	require.Equal(t, proofHash, []byte(ins.ProofHash))
+
+	// the proof doesn't seed the election with another proof hash
+	inspectProofGenesisFile = ""
+	inspectProofLastProofHash = strings.Repeat("AB", 32)
+	_, err = captureStdout(func() {
+		require.Error(t, inspectProof())
+	})
+	require.NoError(t, err)
+
+	// an incomplete validator set is rejected
+	inspectProofValidatorsFile = writeJSON(t, dir, "validators.json", &ctypes.ResultValidators{
+		BlockHeight: 1,
+		Validators:  valSet.Validators[:1],
+		Count:       1,
+		Total:       len(vals),
+	})
+	require.Error(t, inspectProof())
+}
@@ -24,12 +24,22 @@ func main() {
 		cmd.ResetPrivValidatorCmd,
 		cmd.ResetStateCmd,
 		cmd.ShowValidatorCmd,
+		cmd.StageValidatorKeyCmd,
 		cmd.TestnetFilesCmd,
 		cmd.ShowNodeIDCmd,
 		cmd.GenNodeKeyCmd,
 		cmd.VersionCmd,
 		cmd.RollbackStateCmd,
 		cmd.CompactGoLevelDBCmd,
+		cmd.MigrateDBCmd,
+		cmd.InspectCmd,
+		cmd.ValidateConfigCmd,
+		cmd.ValidateGenesisCmd,
+		cmd.MigrateDataCmd,
+		cmd.GenesisCmd,
+		cmd.ShowVRFKeyCmd,
+		cmd.GenVRFKeyCmd,
+		cmd.InspectProofCmd,
 		debug.DebugCmd,
 		cli.NewCompletionCmd(rootCmd, true),
 	)
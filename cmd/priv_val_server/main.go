@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"os"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/Finschia/ostracon/libs/log"
 	tmnet "github.com/Finschia/ostracon/libs/net"
 	tmos "github.com/Finschia/ostracon/libs/os"
+	"github.com/Finschia/ostracon/libs/service"
 
 	"github.com/Finschia/ostracon/privval"
 )
@@ -19,6 +21,10 @@ func main() {
 		chainID          = flag.String("chain-id", "mychain", "chain id")
 		privValKeyPath   = flag.String("priv-key", "", "priv val key file path")
 		privValStatePath = flag.String("priv-state", "", "priv val state file path")
+		grpcAddr         = flag.String("grpc-addr", "", "Address to serve the gRPC protocol on, instead of connecting to addr")
+		certFile         = flag.String("cert", "", "TLS certificate file path of the gRPC server")
+		keyFile          = flag.String("key", "", "TLS key file path of the gRPC server")
+		caFile           = flag.String("ca", "", "CA file path of the certificates of the gRPC clients")
 
 		logger = log.NewOCLogger(
 			log.NewSyncWriter(os.Stdout),
@@ -36,22 +42,13 @@ func main() {
 
 	pv := privval.LoadFilePV(*privValKeyPath, *privValStatePath)
 
-	var dialer privval.SocketDialer
-	protocol, address := tmnet.ProtocolAndAddress(*addr)
-	switch protocol {
-	case "unix":
-		dialer = privval.DialUnixFn(address)
-	case "tcp":
-		connTimeout := 3 * time.Second // TODO
-		dialer = privval.DialTCPFn(address, connTimeout, ed25519.GenPrivKey())
-	default:
-		logger.Error("Unknown protocol", "protocol", protocol)
-		os.Exit(1)
+	var ss service.Service
+	if *grpcAddr != "" {
+		ss = newGRPCSignerServer(logger, *grpcAddr, *chainID, pv, *certFile, *keyFile, *caFile)
+	} else {
+		ss = newSignerServer(logger, *addr, *chainID, pv)
 	}
 
-	sd := privval.NewSignerDialerEndpoint(logger, dialer)
-	ss := privval.NewSignerServer(sd, *chainID, pv)
-
 	err := ss.Start()
 	if err != nil {
 		panic(err)
@@ -68,3 +65,44 @@ func main() {
 	// Run forever.
 	select {}
 }
+
+func newSignerServer(logger log.Logger, addr, chainID string, pv *privval.FilePV) service.Service {
+	var dialer privval.SocketDialer
+	protocol, address := tmnet.ProtocolAndAddress(addr)
+	switch protocol {
+	case "unix":
+		dialer = privval.DialUnixFn(address)
+	case "tcp":
+		connTimeout := 3 * time.Second // TODO
+		dialer = privval.DialTCPFn(address, connTimeout, ed25519.GenPrivKey())
+	default:
+		logger.Error("Unknown protocol", "protocol", protocol)
+		os.Exit(1)
+	}
+
+	sd := privval.NewSignerDialerEndpoint(logger, dialer)
+	return privval.NewSignerServer(sd, chainID, pv)
+}
+
+func newGRPCSignerServer(
+	logger log.Logger,
+	addr, chainID string,
+	pv *privval.FilePV,
+	certFile, keyFile, caFile string,
+) service.Service {
+	var tlsConfig *tls.Config
+	if certFile != "" {
+		var err error
+		tlsConfig, err = privval.NewMTLSConfig(certFile, keyFile, caFile)
+		if err != nil {
+			logger.Error("Failed to load the TLS config", "err", err)
+			os.Exit(1)
+		}
+	} else {
+		logger.Info("Serving the gRPC protocol without TLS")
+	}
+
+	ss := privval.NewGRPCSignerServer(addr, chainID, pv, tlsConfig)
+	ss.SetLogger(logger)
+	return ss
+}
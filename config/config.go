@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,6 +28,11 @@ const (
 
 	DefaultDBBackend = "goleveldb"
 
+	// ModeFull is the mode of a node running all the services
+	ModeFull = "full"
+	// ModeSeed is the mode of a node only running the PEX reactor in seed mode
+	ModeSeed = "seed"
+
 	// Mempool versions.
 	// Default is v0.
 
@@ -33,6 +40,28 @@ const (
 	MempoolV0 = "v0"
 	// MempoolV1 is prioritized mempool
 	MempoolV1 = "v1"
+
+	// TracingOTLPProtocolGRPC exports the traces with OTLP over gRPC
+	TracingOTLPProtocolGRPC = "grpc"
+	// TracingOTLPProtocolHTTP exports the traces with OTLP over HTTP
+	TracingOTLPProtocolHTTP = "http"
+
+	// MetricsBackendPrometheus serves the metrics to Prometheus
+	MetricsBackendPrometheus = "prometheus"
+	// MetricsBackendStatsd pushes the metrics to a StatsD agent
+	MetricsBackendStatsd = "statsd"
+	// MetricsBackendOTLP pushes the metrics to an OpenTelemetry collector with OTLP
+	MetricsBackendOTLP = "otlp"
+
+	// PrivValidatorBackendFile signs with the key of priv_validator_key_file
+	PrivValidatorBackendFile = "file"
+	// PrivValidatorBackendPKCS11 signs with the key of a PKCS#11 token (HSM)
+	PrivValidatorBackendPKCS11 = "pkcs11"
+
+	// PrivValidatorStateBackendFile persists the last sign state in priv_validator_state_file
+	PrivValidatorStateBackendFile = "file"
+	// PrivValidatorStateBackendPsql persists the last sign state in a PostgreSQL database
+	PrivValidatorStateBackendPsql = "psql"
 )
 
 // NOTE: Most of the structs & relevant comments + the
@@ -123,6 +152,7 @@ func (cfg *Config) SetRoot(root string) *Config {
 	cfg.P2P.RootDir = root
 	cfg.Mempool.RootDir = root
 	cfg.Consensus.RootDir = root
+	cfg.Instrumentation.RootDir = root
 	return cfg
 }
 
@@ -150,6 +180,9 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Consensus.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [consensus] section: %w", err)
 	}
+	if err := cfg.Storage.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [storage] section: %w", err)
+	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
@@ -169,17 +202,45 @@ type BaseConfig struct { //nolint: maligned
 	RootDir string `mapstructure:"home"`
 
 	// TCP or UNIX socket address of the ABCI application,
-	// or the name of an ABCI application compiled in with the Ostracon binary
+	// or the name of an ABCI application compiled in with the Ostracon binary,
+	// or the command line of the application the node runs, prefixed with
+	// "exec:", e.g. "exec:/usr/bin/myapp start --address {abci_addr}". The
+	// application must listen to the address replacing {abci_addr}, also set
+	// in its OSTRACON_ABCI_ADDR environment variable.
 	ProxyApp string `mapstructure:"proxy_app"`
 
+	// Supervision of the application run by the node: the time it has to
+	// accept the connections once started, the number of times it's restarted
+	// if it doesn't, the time it has to exit once sent SIGTERM before being
+	// killed, and the interval of the health checks stopping it (and so the
+	// node) once they failed 3 times in a row (0 not to check).
+	ProxyAppStartTimeout        time.Duration `mapstructure:"proxy_app_start_timeout"`
+	ProxyAppMaxRestarts         int           `mapstructure:"proxy_app_max_restarts"`
+	ProxyAppStopTimeout         time.Duration `mapstructure:"proxy_app_stop_timeout"`
+	ProxyAppHealthCheckInterval time.Duration `mapstructure:"proxy_app_health_check_interval"`
+
 	// A custom human readable name for this node
 	Moniker string `mapstructure:"moniker"`
 
+	// Mode of the node: full | seed
+	// * full: runs all the services (default)
+	// * seed: only runs the PEX reactor in seed mode and the address book,
+	//   without consensus, mempool, block store nor application. The node
+	//   crawls the network and shares the addresses it knows with the
+	//   connecting peers. Its RPC server only serves /health, /net_info and
+	//   /crawl_stats.
+	Mode string `mapstructure:"mode"`
+
 	// If this node is many blocks behind the tip of the chain, FastSync
 	// allows them to catchup quickly by downloading blocks in parallel
 	// and verifying their commits
 	FastSyncMode bool `mapstructure:"fast_sync"`
 
+	// Maximum time the node waits, when stopping, for the height it's voting
+	// in to be committed, not to stop between its votes of a height. RPC
+	// writes and mempool gossip are stopped first. 0 not to wait.
+	ShutdownDrainTimeout time.Duration `mapstructure:"shutdown_drain_timeout"`
+
 	// Database backend: goleveldb | cleveldb | boltdb | rocksdb
 	// * goleveldb (github.com/syndtr/goleveldb - most popular implementation)
 	//   - pure go
@@ -197,8 +258,10 @@ type BaseConfig struct { //nolint: maligned
 	//   - requires gcc
 	//   - use rocksdb build tag (go build -tags rocksdb)
 	// * badgerdb (uses github.com/dgraph-io/badger)
-	//   - EXPERIMENTAL
-	//   - use badgerdb build tag (go build -tags badgerdb)
+	//   - no stalls on the compactions of the levels
+	//   - use badgerdb build tag (go build -tags badgerdb), included by make build
+	// The databases are migrated to another backend with the
+	// experimental-db-migrate command.
 	DBBackend string `mapstructure:"db_backend"`
 
 	// Database directory
@@ -240,6 +303,41 @@ type BaseConfig struct { //nolint: maligned
 	// Path to the JSON file containing the last sign state of a validator
 	PrivValidatorState string `mapstructure:"priv_validator_state_file"`
 
+	// Backend of the local validator key: file | pkcs11
+	// The pkcs11 backend signs with the key of a PKCS#11 token (e.g. an HSM),
+	// which never exists on disk (Ostracon must be built with the pkcs11 tag)
+	PrivValidatorBackend string `mapstructure:"priv_validator_backend"`
+
+	// Path to the PKCS#11 module (shared library) of the token
+	PrivValidatorPKCS11Module string `mapstructure:"priv_validator_pkcs11_module"`
+
+	// Slot of the token
+	PrivValidatorPKCS11Slot uint `mapstructure:"priv_validator_pkcs11_slot"`
+
+	// PIN of the user of the token (better set with the OC_PRIV_VALIDATOR_PKCS11_PIN
+	// environment variable)
+	PrivValidatorPKCS11PIN string `mapstructure:"priv_validator_pkcs11_pin"`
+
+	// Label of the Ed25519 key pair of the validator in the token
+	PrivValidatorPKCS11KeyLabel string `mapstructure:"priv_validator_pkcs11_key_label"`
+
+	// Vendor-defined mechanism of the token generating the VRF proofs of the
+	// key, 0 if none (the validator can then sign but not propose)
+	PrivValidatorPKCS11VRFMechanism uint `mapstructure:"priv_validator_pkcs11_vrf_mechanism"`
+
+	// Backend of the last sign state of the local validator: file | psql
+	// The psql backend persists it in a PostgreSQL database shared by an active
+	// and a passive validator of the same key, only one of them signing at a
+	// time: the one holding the lease of the state
+	PrivValidatorStateBackend string `mapstructure:"priv_validator_state_backend"`
+
+	// The PostgreSQL connection string of the psql backend
+	PrivValidatorStatePsqlConn string `mapstructure:"priv_validator_state_psql_conn"`
+
+	// Time for which the validator leases the state, since its last signature,
+	// before a passive validator can take over
+	PrivValidatorStateLeaseTTL time.Duration `mapstructure:"priv_validator_state_lease_ttl"`
+
 	// TCP or UNIX socket address for Ostracon to listen on for
 	// connections from an external PrivValidator process
 	// example) tcp://0.0.0.0:26659
@@ -252,37 +350,74 @@ type BaseConfig struct { //nolint: maligned
 	// example) [ "127.0.0.1", "192.168.1.2" ]
 	PrivValidatorRemoteAddresses []string `mapstructure:"priv_validator_raddrs"`
 
+	// TCP or UNIX socket address of an external PrivValidator process serving
+	// the gRPC protocol, for Ostracon to dial (instead of listening on
+	// priv_validator_laddr)
+	// example) tcp://signer:26659
+	PrivValidatorGRPCAddr string `mapstructure:"priv_validator_grpc_addr"`
+
+	// Paths to the PEM files of the certificate and key of the node, and of the
+	// CA of the certificate of the PrivValidator process, for the mutual TLS
+	// authentication of the gRPC connection. The connection is insecure if they
+	// are empty. The certificate and key can be rotated by replacing the files.
+	PrivValidatorGRPCCert   string `mapstructure:"priv_validator_grpc_cert_file"`
+	PrivValidatorGRPCKey    string `mapstructure:"priv_validator_grpc_key_file"`
+	PrivValidatorGRPCRootCA string `mapstructure:"priv_validator_grpc_ca_file"`
+
 	// A JSON file containing the private key to use for p2p authenticated encryption
 	NodeKey string `mapstructure:"node_key_file"`
 
 	// Mechanism to connect to the ABCI application: socket | grpc
 	ABCI string `mapstructure:"abci"`
 
+	// Numbers of connections to the ABCI application the queries (e.g. the
+	// abci_query RPC) and the CheckTx requests are spread over, so a slow
+	// request doesn't block the others, if the application serves them
+	// concurrently. With several mempool connections, the txs are checked out
+	// of order.
+	ProxyQueryConnections   int `mapstructure:"proxy_query_connections"`
+	ProxyMempoolConnections int `mapstructure:"proxy_mempool_connections"`
+
 	// If true, query the ABCI app on connecting to a new peer
 	// so the app can decide if we should keep the connection or not
 	FilterPeers bool `mapstructure:"filter_peers"` // false
+
+	// The names of the custom reactors to add to the node, among the ones
+	// compiled in the binary (see node.RegisterReactor)
+	CustomReactors []string `mapstructure:"custom_reactors"`
 }
 
 // DefaultBaseConfig returns a default base configuration for an Ostracon node
 func DefaultBaseConfig() BaseConfig {
 	return BaseConfig{
-		Genesis:            defaultGenesisJSONPath,
-		PrivValidatorKey:   defaultPrivValKeyPath,
-		PrivValidatorState: defaultPrivValStatePath,
-		NodeKey:            defaultNodeKeyPath,
-		Moniker:            defaultMoniker,
-		ProxyApp:           "tcp://127.0.0.1:26658",
-		ABCI:               "socket",
-		LogLevel:           DefaultPackageLogLevels(),
-		LogFormat:          LogFormatPlain,
-		LogPath:            "",
-		LogMaxAge:          0,
-		LogMaxSize:         100,
-		LogMaxBackups:      0,
-		FastSyncMode:       true,
-		FilterPeers:        false,
-		DBBackend:          DefaultDBBackend,
-		DBPath:             "data",
+		Genesis:                     defaultGenesisJSONPath,
+		PrivValidatorKey:            defaultPrivValKeyPath,
+		PrivValidatorState:          defaultPrivValStatePath,
+		PrivValidatorBackend:        PrivValidatorBackendFile,
+		PrivValidatorStateBackend:   PrivValidatorStateBackendFile,
+		PrivValidatorStateLeaseTTL:  30 * time.Second,
+		NodeKey:                     defaultNodeKeyPath,
+		Moniker:                     defaultMoniker,
+		Mode:                        ModeFull,
+		ProxyApp:                    "tcp://127.0.0.1:26658",
+		ProxyAppStartTimeout:        30 * time.Second,
+		ProxyAppMaxRestarts:         3,
+		ProxyAppStopTimeout:         10 * time.Second,
+		ProxyAppHealthCheckInterval: 10 * time.Second,
+		ShutdownDrainTimeout:        10 * time.Second,
+		ABCI:                        "socket",
+		ProxyQueryConnections:       1,
+		ProxyMempoolConnections:     1,
+		LogLevel:                    DefaultPackageLogLevels(),
+		LogFormat:                   LogFormatPlain,
+		LogPath:                     "",
+		LogMaxAge:                   0,
+		LogMaxSize:                  100,
+		LogMaxBackups:               0,
+		FastSyncMode:                true,
+		FilterPeers:                 false,
+		DBBackend:                   DefaultDBBackend,
+		DBPath:                      "data",
 	}
 }
 
@@ -315,6 +450,24 @@ func (cfg BaseConfig) PrivValidatorStateFile() string {
 	return rootify(cfg.PrivValidatorState, cfg.RootDir)
 }
 
+// PrivValidatorGRPCCertFile returns the full path to the certificate file of
+// the gRPC connection to the PrivValidator
+func (cfg BaseConfig) PrivValidatorGRPCCertFile() string {
+	return rootify(cfg.PrivValidatorGRPCCert, cfg.RootDir)
+}
+
+// PrivValidatorGRPCKeyFile returns the full path to the key file of the gRPC
+// connection to the PrivValidator
+func (cfg BaseConfig) PrivValidatorGRPCKeyFile() string {
+	return rootify(cfg.PrivValidatorGRPCKey, cfg.RootDir)
+}
+
+// PrivValidatorGRPCRootCAFile returns the full path to the CA file of the gRPC
+// connection to the PrivValidator
+func (cfg BaseConfig) PrivValidatorGRPCRootCAFile() string {
+	return rootify(cfg.PrivValidatorGRPCRootCA, cfg.RootDir)
+}
+
 // NodeKeyFile returns the full path to the node_key.json file
 func (cfg BaseConfig) NodeKeyFile() string {
 	return rootify(cfg.NodeKey, cfg.RootDir)
@@ -333,6 +486,85 @@ func (cfg BaseConfig) ValidateBasic() error {
 	default:
 		return errors.New("unknown log_format (must be 'plain' or 'json')")
 	}
+	switch cfg.Mode {
+	case ModeFull, ModeSeed:
+	default:
+		return errors.New("unknown mode (must be 'full' or 'seed')")
+	}
+	if strings.HasPrefix(cfg.ProxyApp, "exec:") && strings.TrimSpace(strings.TrimPrefix(cfg.ProxyApp, "exec:")) == "" {
+		return errors.New("proxy_app has no command line after exec:")
+	}
+	if cfg.ProxyAppStartTimeout <= 0 {
+		return errors.New("proxy_app_start_timeout must be positive")
+	}
+	if cfg.ProxyAppMaxRestarts < 0 {
+		return errors.New("proxy_app_max_restarts can't be negative")
+	}
+	if cfg.ProxyAppStopTimeout < 0 {
+		return errors.New("proxy_app_stop_timeout can't be negative")
+	}
+	if cfg.ProxyAppHealthCheckInterval < 0 {
+		return errors.New("proxy_app_health_check_interval can't be negative")
+	}
+	if cfg.ShutdownDrainTimeout < 0 {
+		return errors.New("shutdown_drain_timeout can't be negative")
+	}
+	if cfg.ProxyQueryConnections < 1 {
+		return errors.New("proxy_query_connections must be at least 1")
+	}
+	if cfg.ProxyMempoolConnections < 1 {
+		return errors.New("proxy_mempool_connections must be at least 1")
+	}
+	names := make(map[string]struct{}, len(cfg.CustomReactors))
+	for _, name := range cfg.CustomReactors {
+		if name == "" {
+			return errors.New("custom_reactors can't contain an empty name")
+		}
+		if _, ok := names[name]; ok {
+			return fmt.Errorf("custom_reactors contains %q more than once", name)
+		}
+		names[name] = struct{}{}
+	}
+	switch cfg.PrivValidatorBackend {
+	case PrivValidatorBackendFile:
+	case PrivValidatorBackendPKCS11:
+		if cfg.PrivValidatorPKCS11Module == "" {
+			return errors.New("priv_validator_pkcs11_module must be set with the pkcs11 priv_validator_backend")
+		}
+		if cfg.PrivValidatorListenAddr != "" || cfg.PrivValidatorGRPCAddr != "" {
+			return errors.New("the pkcs11 priv_validator_backend can't be used with a remote signer")
+		}
+	default:
+		return errors.New("unknown priv_validator_backend (must be 'file' or 'pkcs11')")
+	}
+	switch cfg.PrivValidatorStateBackend {
+	case PrivValidatorStateBackendFile:
+	case PrivValidatorStateBackendPsql:
+		if cfg.PrivValidatorStatePsqlConn == "" {
+			return errors.New("priv_validator_state_psql_conn must be set with the psql priv_validator_state_backend")
+		}
+		if cfg.PrivValidatorListenAddr != "" || cfg.PrivValidatorGRPCAddr != "" {
+			return errors.New("the psql priv_validator_state_backend can't be used with a remote signer")
+		}
+	default:
+		return errors.New("unknown priv_validator_state_backend (must be 'file' or 'psql')")
+	}
+	if cfg.PrivValidatorStateLeaseTTL <= 0 {
+		return errors.New("priv_validator_state_lease_ttl must be positive")
+	}
+	if cfg.PrivValidatorGRPCAddr != "" && cfg.PrivValidatorListenAddr != "" {
+		return errors.New("priv_validator_grpc_addr and priv_validator_laddr can't be both set")
+	}
+	tlsFiles := 0
+	for _, file := range []string{cfg.PrivValidatorGRPCCert, cfg.PrivValidatorGRPCKey, cfg.PrivValidatorGRPCRootCA} {
+		if file != "" {
+			tlsFiles++
+		}
+	}
+	if tlsFiles != 0 && tlsFiles != 3 {
+		return errors.New("priv_validator_grpc_cert_file, priv_validator_grpc_key_file and " +
+			"priv_validator_grpc_ca_file must be all set or all empty")
+	}
 	return nil
 }
 
@@ -365,9 +597,13 @@ type RPCConfig struct {
 	CORSAllowedHeaders []string `mapstructure:"cors_allowed_headers"`
 
 	// TCP or UNIX socket address for the gRPC server to listen on
-	// NOTE: This server only supports /broadcast_tx_commit
+	// NOTE: This server only supports /broadcast_tx_commit, unless GRPCFullAPI
 	GRPCListenAddress string `mapstructure:"grpc_laddr"`
 
+	// Serve /status, /block, /block_results, /validators, /tx, /tx_search and
+	// the event subscriptions over gRPC as well
+	GRPCFullAPI bool `mapstructure:"grpc_full_api"`
+
 	// Maximum number of simultaneous connections.
 	// Does not include RPC (HTTP&WebSocket) connections. See max_open_connections
 	// If you want to accept a larger number than the default, make sure
@@ -425,6 +661,12 @@ type RPCConfig struct {
 	// returning `ErrOutOfCapacity`.
 	SubscriptionBufferSize int `mapstructure:"experimental_subscription_buffer_size"`
 
+	// What to do when the buffer of a subscription is full: "disconnect" (the
+	// subscription is closed with `ErrOutOfCapacity`), "drop_oldest" or
+	// "drop_new" (an event is dropped), or "block" (the events are not
+	// delivered to any subscriber until the slow one catches up).
+	SubscriptionOverflowPolicy string `mapstructure:"experimental_subscription_overflow_policy"`
+
 	// The maximum number of responses that can be buffered per WebSocket
 	// client. If clients cannot read from the WebSocket endpoint fast enough,
 	// they will be disconnected, so increasing this parameter may reduce the
@@ -459,6 +701,37 @@ type RPCConfig struct {
 	// Maximum size of request header, in bytes
 	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
 
+	// Maximum number of requests of a client (by IP address) per
+	// rate_limit_window. The requests over it are rejected with the HTTP
+	// status 429 (Too Many Requests).
+	// 0 - unlimited.
+	RateLimitRequests int `mapstructure:"rate_limit_requests"`
+
+	// Maximum number of requests of all the clients per rate_limit_window.
+	// 0 - unlimited.
+	RateLimitTotalRequests int `mapstructure:"rate_limit_total_requests"`
+
+	// Window of the rate limits of the requests (sliding over time)
+	RateLimitWindow time.Duration `mapstructure:"rate_limit_window"`
+
+	// Maximum number of calls of a method by a client (by IP address) per
+	// rate_limit_window, by method, as "method:calls", e.g.
+	// ["broadcast_tx_commit:10", "tx_search:100"]. The calls over it are
+	// rejected with an error.
+	RateLimitMethods []string `mapstructure:"rate_limit_methods"`
+
+	// The only methods served if not empty, e.g. ["status", "block", "tx"].
+	EnabledMethods []string `mapstructure:"enabled_methods"`
+
+	// The methods not served, e.g. ["broadcast_tx_commit", "dial_peers"].
+	DisabledMethods []string `mapstructure:"disabled_methods"`
+
+	// Maximum number of responses of /block, /block_results, /validators and
+	// /consensus_params at the past heights cached in memory, which don't
+	// change. The least recently used ones are evicted first.
+	// 0 - no cache.
+	ResponseCacheSize int `mapstructure:"response_cache_size"`
+
 	// The path to a file containing certificate that is used to create the HTTPS server.
 	// Might be either absolute path or path related to Ostracon's config directory.
 	//
@@ -489,6 +762,7 @@ func DefaultRPCConfig() *RPCConfig {
 		CORSAllowedMethods:     []string{http.MethodHead, http.MethodGet, http.MethodPost},
 		CORSAllowedHeaders:     []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "X-Server-Time"},
 		GRPCListenAddress:      "",
+		GRPCFullAPI:            false,
 		GRPCMaxOpenConnections: 900,
 
 		Unsafe:             false,
@@ -497,16 +771,27 @@ func DefaultRPCConfig() *RPCConfig {
 		WriteTimeout:       10 * time.Second,
 		IdleTimeout:        60 * time.Second,
 
-		MaxSubscriptionClients:    100,
-		MaxSubscriptionsPerClient: 5,
-		SubscriptionBufferSize:    defaultSubscriptionBufferSize,
-		TimeoutBroadcastTxCommit:  10 * time.Second,
-		WebSocketWriteBufferSize:  defaultSubscriptionBufferSize,
+		MaxSubscriptionClients:     100,
+		MaxSubscriptionsPerClient:  5,
+		SubscriptionBufferSize:     defaultSubscriptionBufferSize,
+		SubscriptionOverflowPolicy: "disconnect",
+		TimeoutBroadcastTxCommit:   10 * time.Second,
+		WebSocketWriteBufferSize:   defaultSubscriptionBufferSize,
 
 		MaxBodyBytes:       int64(1000000), // 1MB
 		MaxBatchRequestNum: 10,
 		MaxHeaderBytes:     1 << 20, // same as the net/http default
 
+		RateLimitRequests:      0,
+		RateLimitTotalRequests: 0,
+		RateLimitWindow:        time.Second,
+		RateLimitMethods:       []string{},
+
+		EnabledMethods:  []string{},
+		DisabledMethods: []string{},
+
+		ResponseCacheSize: 0,
+
 		TLSCertFile: "",
 		TLSKeyFile:  "",
 	}
@@ -551,6 +836,11 @@ func (cfg *RPCConfig) ValidateBasic() error {
 			minSubscriptionBufferSize,
 		)
 	}
+	switch cfg.SubscriptionOverflowPolicy {
+	case "disconnect", "drop_oldest", "drop_new", "block":
+	default:
+		return fmt.Errorf("unknown experimental_subscription_overflow_policy %q", cfg.SubscriptionOverflowPolicy)
+	}
 	if cfg.WebSocketWriteBufferSize < cfg.SubscriptionBufferSize {
 		return fmt.Errorf(
 			"experimental_websocket_write_buffer_size must be >= experimental_subscription_buffer_size (%d)",
@@ -569,9 +859,49 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxHeaderBytes < 0 {
 		return errors.New("max_header_bytes can't be negative")
 	}
+	if cfg.RateLimitRequests < 0 {
+		return errors.New("rate_limit_requests can't be negative")
+	}
+	if cfg.RateLimitTotalRequests < 0 {
+		return errors.New("rate_limit_total_requests can't be negative")
+	}
+	if cfg.RateLimitWindow < 0 {
+		return errors.New("rate_limit_window can't be negative")
+	}
+	if cfg.RateLimitWindow == 0 && (cfg.RateLimitRequests > 0 || cfg.RateLimitTotalRequests > 0) {
+		return errors.New("rate_limit_window must be positive when the requests are limited")
+	}
+	methodRateLimits, err := cfg.MethodRateLimits()
+	if err != nil {
+		return fmt.Errorf("wrong rate_limit_methods: %w", err)
+	}
+	if cfg.RateLimitWindow == 0 && len(methodRateLimits) > 0 {
+		return errors.New("rate_limit_window must be positive when the methods are limited")
+	}
+	if cfg.ResponseCacheSize < 0 {
+		return errors.New("response_cache_size can't be negative")
+	}
 	return nil
 }
 
+// MethodRateLimits returns the maximum numbers of calls by method of
+// RateLimitMethods.
+func (cfg *RPCConfig) MethodRateLimits() (map[string]int, error) {
+	limits := make(map[string]int, len(cfg.RateLimitMethods))
+	for _, limit := range cfg.RateLimitMethods {
+		i := strings.LastIndex(limit, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q isn't method:calls", limit)
+		}
+		calls, err := strconv.Atoi(limit[i+1:])
+		if err != nil || calls <= 0 {
+			return nil, fmt.Errorf("the calls of %q must be a positive number", limit)
+		}
+		limits[limit[:i]] = calls
+	}
+	return limits, nil
+}
+
 // IsCorsEnabled returns true if cross-origin resource sharing is enabled.
 func (cfg *RPCConfig) IsCorsEnabled() bool {
 	return len(cfg.CORSAllowedOrigins) != 0
@@ -607,19 +937,48 @@ type P2PConfig struct { //nolint: maligned
 	// Address to listen for incoming connections
 	ListenAddress string `mapstructure:"laddr"`
 
+	// Addresses to listen for incoming connections, instead of ListenAddress
+	// if set, e.g. on an IPv4 and an IPv6 address
+	ListenAddresses []string `mapstructure:"laddrs"`
+
 	// Address to advertise to peers for them to dial
 	ExternalAddress string `mapstructure:"external_address"`
 
+	// Addresses to advertise to peers for them to dial, in the order of
+	// preference, instead of ExternalAddress if set
+	ExternalAddresses []string `mapstructure:"external_addresses"`
+
 	// Comma separated list of seed nodes to connect to
 	// We only use these if we can’t connect to peers in the addrbook
 	Seeds string `mapstructure:"seeds"`
 
+	// DNS name of the SRV records of the peers to dial, in addition to the
+	// seeds, e.g. "_ostracon._tcp.example.com". The target of a record is the
+	// host of a peer, prefixed with its ID, e.g. "<ID>.node1.example.com".
+	DiscoverySRV string `mapstructure:"discovery_srv"`
+
+	// Set true to dial the current validators, whose addresses (ID@host:port)
+	// the application returns for the ABCI query "/p2p/validators" as a comma
+	// separated list.
+	DiscoverValidators bool `mapstructure:"discover_validators"`
+
 	// Comma separated list of nodes to keep persistent connections to
 	PersistentPeers string `mapstructure:"persistent_peers"`
 
-	// UPNP port forwarding
+	// UPNP port forwarding: map the port of the laddr on the gateway, and
+	// advertise its external address unless ExternalAddress is set
 	UPNP bool `mapstructure:"upnp"`
 
+	// NAT-PMP port forwarding, as UPNP, tried first if both are set
+	NATPMP bool `mapstructure:"nat_pmp"`
+
+	// Lease of the port mappings of UPNP and NATPMP, renewed at half of it
+	NATLeaseDuration time.Duration `mapstructure:"nat_lease_duration"`
+
+	// Set true to advertise the IP the outbound peers report the node is seen
+	// from, with the port of the laddr, unless ExternalAddress is set
+	LearnExternalAddress bool `mapstructure:"learn_external_address"`
+
 	// Path to address book
 	AddrBook string `mapstructure:"addr_book_file"`
 
@@ -651,6 +1010,41 @@ type P2PConfig struct { //nolint: maligned
 	// Rate at which packets can be received, in bytes/second
 	RecvRate int64 `mapstructure:"recv_rate"`
 
+	// Rate at which messages can be received from a peer, in messages/second.
+	// A peer exceeding it is disconnected.
+	// 0 - unlimited.
+	RecvMessageRate float64 `mapstructure:"recv_message_rate"`
+
+	// Number of messages which can be received at once from a peer, above
+	// recv_message_rate.
+	// 0 - one second of messages.
+	RecvMessageBurst int `mapstructure:"recv_message_burst"`
+
+	// Bytes of the messages received from all the peers which can be queued
+	// for the reactors or being handled by them. Once reached, the node stops
+	// reading from the peers until the reactors catch up.
+	// 0 - unlimited.
+	MaxPendingRecvBytes int64 `mapstructure:"max_pending_recv_bytes"`
+
+	// Algorithm the block parts and the snapshot chunks are compressed with,
+	// for the peers which can decompress them: "snappy", "zstd" or "" for
+	// none. A node decompresses the messages of the peers whatever it is.
+	Compression string `mapstructure:"compression"`
+
+	// Half-life of the scores of the peers, changed by the misbehaviors (e.g.
+	// the invalid messages) and the useful messages the reactors report.
+	// 0 - the scores don't decay.
+	PeerScoreHalfLife time.Duration `mapstructure:"peer_score_half_life"`
+
+	// Score at which a peer is disconnected, e.g. -50 for an invalid message.
+	// 0 - never.
+	PeerDisconnectScore float64 `mapstructure:"peer_disconnect_score"`
+
+	// Score at which a peer is banned for peer_ban_duration.
+	// 0 - never.
+	PeerBanScore    float64       `mapstructure:"peer_ban_score"`
+	PeerBanDuration time.Duration `mapstructure:"peer_ban_duration"`
+
 	// Set true to enable the peer-exchange reactor
 	PexReactor bool `mapstructure:"pex"`
 
@@ -674,7 +1068,7 @@ type P2PConfig struct { //nolint: maligned
 	// Reactor async receive
 	RecvAsync bool `mapstructure:"recv_async"`
 
-	// Size of receive buffer used in async receiving
+	// Size of receive buffer used in async receiving, by channel of the reactor
 	PexRecvBufSize        int `mapstructure:"pex_recv_buf_size"`
 	EvidenceRecvBufSize   int `mapstructure:"evidence_recv_buf_size"`
 	MempoolRecvBufSize    int `mapstructure:"mempool_recv_buf_size"`
@@ -694,17 +1088,27 @@ type P2PConfig struct { //nolint: maligned
 func DefaultP2PConfig() *P2PConfig {
 	return &P2PConfig{
 		ListenAddress:                "tcp://0.0.0.0:26656",
+		ListenAddresses:              []string{},
 		ExternalAddress:              "",
+		ExternalAddresses:            []string{},
 		UPNP:                         false,
+		NATPMP:                       false,
+		NATLeaseDuration:             time.Hour,
+		LearnExternalAddress:         false,
 		AddrBook:                     defaultAddrBookPath,
 		AddrBookStrict:               true,
 		MaxNumInboundPeers:           40,
 		MaxNumOutboundPeers:          10,
 		PersistentPeersMaxDialPeriod: 0 * time.Second,
 		FlushThrottleTimeout:         100 * time.Millisecond,
-		MaxPacketMsgPayloadSize:      1024,    // 1 kB
-		SendRate:                     5120000, // 5 mB/s
-		RecvRate:                     5120000, // 5 mB/s
+		MaxPacketMsgPayloadSize:      1024,      // 1 kB
+		SendRate:                     5120000,   // 5 mB/s
+		RecvRate:                     5120000,   // 5 mB/s
+		MaxPendingRecvBytes:          268435456, // 256 mB
+		PeerScoreHalfLife:            10 * time.Minute,
+		PeerDisconnectScore:          -50,
+		PeerBanScore:                 -100,
+		PeerBanDuration:              time.Hour,
 		PexReactor:                   true,
 		SeedMode:                     false,
 		AllowDuplicateIP:             false,
@@ -737,9 +1141,50 @@ func (cfg *P2PConfig) AddrBookFile() string {
 	return rootify(cfg.AddrBook, cfg.RootDir)
 }
 
+// ListenAddrs returns the addresses to listen on, ListenAddresses if set or
+// else ListenAddress. The first one is the primary, whose port is mapped by
+// UPNP and NATPMP.
+func (cfg *P2PConfig) ListenAddrs() []string {
+	if len(cfg.ListenAddresses) > 0 {
+		return cfg.ListenAddresses
+	}
+	if cfg.ListenAddress == "" {
+		return nil
+	}
+	return []string{cfg.ListenAddress}
+}
+
+// ExternalAddrs returns the addresses to advertise, in the order of
+// preference, ExternalAddresses if set or else ExternalAddress.
+func (cfg *P2PConfig) ExternalAddrs() []string {
+	if len(cfg.ExternalAddresses) > 0 {
+		return cfg.ExternalAddresses
+	}
+	if cfg.ExternalAddress == "" {
+		return nil
+	}
+	return []string{cfg.ExternalAddress}
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg *P2PConfig) ValidateBasic() error {
+	for _, addr := range cfg.ListenAddresses {
+		if addr == "" {
+			return errors.New("laddrs can't contain an empty address")
+		}
+	}
+	for _, addr := range cfg.ExternalAddresses {
+		if addr == "" {
+			return errors.New("external_addresses can't contain an empty address")
+		}
+	}
+	if cfg.NATLeaseDuration < 0 {
+		return errors.New("nat_lease_duration can't be negative")
+	}
+	if (cfg.UPNP || cfg.NATPMP) && cfg.NATLeaseDuration < time.Second {
+		return errors.New("nat_lease_duration must be at least 1s with upnp or nat_pmp")
+	}
 	if cfg.MaxNumInboundPeers < 0 {
 		return errors.New("max_num_inbound_peers can't be negative")
 	}
@@ -761,6 +1206,32 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return errors.New("recv_rate can't be negative")
 	}
+	if cfg.RecvMessageRate < 0 {
+		return errors.New("recv_message_rate can't be negative")
+	}
+	if cfg.RecvMessageBurst < 0 {
+		return errors.New("recv_message_burst can't be negative")
+	}
+	if cfg.MaxPendingRecvBytes < 0 {
+		return errors.New("max_pending_recv_bytes can't be negative")
+	}
+	switch cfg.Compression {
+	case "", "snappy", "zstd":
+	default:
+		return fmt.Errorf("unknown compression %q, must be snappy, zstd or empty", cfg.Compression)
+	}
+	if cfg.PeerScoreHalfLife < 0 {
+		return errors.New("peer_score_half_life can't be negative")
+	}
+	if cfg.PeerDisconnectScore > 0 {
+		return errors.New("peer_disconnect_score can't be positive")
+	}
+	if cfg.PeerBanScore > 0 {
+		return errors.New("peer_ban_score can't be positive")
+	}
+	if cfg.PeerBanDuration < 0 {
+		return errors.New("peer_ban_duration can't be negative")
+	}
 	return nil
 }
 
@@ -791,10 +1262,8 @@ func DefaultFuzzConnConfig() *FuzzConnConfig {
 type MempoolConfig struct {
 	// Mempool version to use:
 	//  1) "v0" - (default) FIFO mempool.
-	//  2) "v1" - prioritized mempool.
-	// WARNING: There's a known memory leak with the prioritized mempool
-	// that the team are working on. Read more here:
-	// https://github.com/tendermint/tendermint/issues/8775
+	//  2) "v1" - prioritized mempool, reaping the txs by the priorities of
+	//     their CheckTx responses and evicting the lowest ones when full.
 	Version   string `mapstructure:"version"`
 	RootDir   string `mapstructure:"home"`
 	Recheck   bool   `mapstructure:"recheck"`
@@ -835,6 +1304,61 @@ type MempoolConfig struct {
 	// has existed in the mempool at least TTLNumBlocks number of blocks or if
 	// it's insertion time into the mempool is beyond TTLDuration.
 	TTLNumBlocks int64 `mapstructure:"ttl-num-blocks"`
+
+	// TTLRecheck, if true, re-CheckTx's the transactions outliving their TTL
+	// instead of removing them: the ones still valid are kept for a new TTL.
+	// The transactions removed are kept in the cache, not to be received again
+	// from the peers still gossiping them.
+	TTLRecheck bool `mapstructure:"ttl-recheck"`
+
+	// Rate at which txs are accepted from a peer, in txs/second. The txs over
+	// it are dropped, without being checked.
+	// 0 - unlimited.
+	PeerTxRate float64 `mapstructure:"peer_tx_rate"`
+
+	// Number of txs which can be accepted at once from a peer, above
+	// peer_tx_rate.
+	// 0 - one second of txs.
+	PeerTxBurst int `mapstructure:"peer_tx_burst"`
+
+	// Rate at which txs are accepted from all the peers, in txs/second.
+	// 0 - unlimited.
+	TotalPeerTxRate float64 `mapstructure:"total_peer_tx_rate"`
+
+	// Number of txs which can be accepted at once from all the peers, above
+	// total_peer_tx_rate.
+	// 0 - one second of txs.
+	TotalPeerTxBurst int `mapstructure:"total_peer_tx_burst"`
+
+	// Rate at which the txs are sent to a peer, in bytes/second, capping the
+	// bandwidth of the txs gossip without throttling the consensus messages.
+	// 0 - unlimited.
+	PeerSendRate int64 `mapstructure:"peer_send_rate"`
+
+	// Rate at which the txs are received from a peer, in bytes/second. The
+	// peers sending more (with a margin) are disconnected, so it should not be
+	// less than the peer_send_rate of the peers.
+	// 0 - unlimited.
+	PeerRecvRate int64 `mapstructure:"peer_recv_rate"`
+
+	// Number of recent txs remembered as known by each peer, sent by it or
+	// announced by it, not to be sent to it again. The txs are remembered
+	// in bloom filters, so that a few txs not known by a peer may not be sent
+	// to it (it receives them from the other peers).
+	// 0 - disabled, the txs are sent to all the peers but their senders.
+	HaveTxFilterSize int `mapstructure:"have_tx_filter_size"`
+
+	// Time for which the txs received are collected to be checked by the app
+	// in one call, if it's a local app implementing BatchApplication (the
+	// other apps check them one by one). It delays the txs by up to this time.
+	// 0 - disabled, the txs are checked as soon as received.
+	CheckTxBatchWindow time.Duration `mapstructure:"check_tx_batch_window"`
+
+	// PersistToDisk, if true, saves the txs of the mempool and its cache to
+	// data/mempool.txs when the node stops gracefully, and re-CheckTx's them
+	// when it starts again: the ones still valid are back in the mempool, and
+	// the cache keeps filtering the txs seen before the restart.
+	PersistToDisk bool `mapstructure:"persist-to-disk"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the Ostracon mempool
@@ -852,6 +1376,11 @@ func DefaultMempoolConfig() *MempoolConfig {
 		MaxTxBytes:   1024 * 1024, // 1MB
 		TTLDuration:  0 * time.Second,
 		TTLNumBlocks: 0,
+		TTLRecheck:   false,
+
+		HaveTxFilterSize:   10000,
+		CheckTxBatchWindow: 0,
+		PersistToDisk:      false,
 	}
 }
 
@@ -872,6 +1401,12 @@ func (cfg *MempoolConfig) WalEnabled() bool {
 	return cfg.WalPath != ""
 }
 
+// PersistFile returns the full path to the file the txs are saved to with
+// PersistToDisk.
+func (cfg *MempoolConfig) PersistFile() string {
+	return rootify(filepath.Join(defaultDataDir, "mempool.txs"), cfg.RootDir)
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg *MempoolConfig) ValidateBasic() error {
@@ -887,6 +1422,36 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.MaxTxBytes < 0 {
 		return errors.New("max_tx_bytes can't be negative")
 	}
+	if cfg.TTLDuration < 0 {
+		return errors.New("ttl-duration can't be negative")
+	}
+	if cfg.TTLNumBlocks < 0 {
+		return errors.New("ttl-num-blocks can't be negative")
+	}
+	if cfg.PeerTxRate < 0 {
+		return errors.New("peer_tx_rate can't be negative")
+	}
+	if cfg.PeerTxBurst < 0 {
+		return errors.New("peer_tx_burst can't be negative")
+	}
+	if cfg.TotalPeerTxRate < 0 {
+		return errors.New("total_peer_tx_rate can't be negative")
+	}
+	if cfg.TotalPeerTxBurst < 0 {
+		return errors.New("total_peer_tx_burst can't be negative")
+	}
+	if cfg.PeerSendRate < 0 {
+		return errors.New("peer_send_rate can't be negative")
+	}
+	if cfg.PeerRecvRate < 0 {
+		return errors.New("peer_recv_rate can't be negative")
+	}
+	if cfg.HaveTxFilterSize < 0 {
+		return errors.New("have_tx_filter_size can't be negative")
+	}
+	if cfg.CheckTxBatchWindow < 0 {
+		return errors.New("check_tx_batch_window can't be negative")
+	}
 	return nil
 }
 
@@ -904,6 +1469,27 @@ type StateSyncConfig struct {
 	DiscoveryTime       time.Duration `mapstructure:"discovery_time"`
 	ChunkRequestTimeout time.Duration `mapstructure:"chunk_request_timeout"`
 	ChunkFetchers       int32         `mapstructure:"chunk_fetchers"`
+
+	// Interval of the heights at which the node archives the latest snapshot
+	// of the app, in <db_dir>/snapshots, to serve it to the peers. 0 - disabled
+	SnapshotInterval uint64 `mapstructure:"snapshot_interval"`
+	// Number of the most recent archived snapshots to keep. 0 - keep all
+	SnapshotKeepRecent uint32 `mapstructure:"snapshot_keep_recent"`
+
+	// Hash of the genesis document (types.GenesisDoc.Hash, in hex). If set and
+	// the node has no genesis file, the genesis document is fetched in chunks
+	// from the rpc_servers and checked against it. Empty - the genesis file is
+	// required
+	GenesisHash string `mapstructure:"genesis_hash"`
+}
+
+// GenesisHashBytes returns the GenesisHash, validated in ValidateBasic.
+func (cfg *StateSyncConfig) GenesisHashBytes() []byte {
+	bytes, err := hex.DecodeString(cfg.GenesisHash)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
 }
 
 func (cfg *StateSyncConfig) TrustHashBytes() []byte {
@@ -922,6 +1508,7 @@ func DefaultStateSyncConfig() *StateSyncConfig {
 		DiscoveryTime:       15 * time.Second,
 		ChunkRequestTimeout: 10 * time.Second,
 		ChunkFetchers:       4,
+		SnapshotKeepRecent:  2,
 	}
 }
 
@@ -975,6 +1562,10 @@ func (cfg *StateSyncConfig) ValidateBasic() error {
 		if cfg.ChunkFetchers <= 0 {
 			return errors.New("chunk_fetchers is required")
 		}
+
+		if _, err := hex.DecodeString(cfg.GenesisHash); err != nil {
+			return fmt.Errorf("invalid genesis_hash: %w", err)
+		}
 	}
 
 	return nil
@@ -1024,6 +1615,13 @@ type ConsensusConfig struct {
 	WalPath string `mapstructure:"wal_file"`
 	walFile string // overrides WalPath if set
 
+	// Compress the entries of the WAL with zstd
+	WalCompression bool `mapstructure:"wal_compression"`
+	// Number of entries of the WAL buffered for the routine writing them and
+	// syncing the WAL, so that the consensus only waits for the sync at the
+	// commits and before signing. 0 - the entries are written by the consensus.
+	WalWriteBufferSize int `mapstructure:"wal_write_buffer_size"`
+
 	// How long we wait for a proposal block before prevoting nil
 	TimeoutPropose time.Duration `mapstructure:"timeout_propose"`
 	// How much timeout_propose increases with each round
@@ -1042,6 +1640,18 @@ type ConsensusConfig struct {
 	// NOTE: when modifying, make sure to update time_iota_ms genesis parameter
 	TimeoutCommit time.Duration `mapstructure:"timeout_commit"`
 
+	// Named timeouts overriding the ones above: "lan", "wan" or "global" (see
+	// TimeoutProfiles). "" - the timeouts above.
+	TimeoutProfile string `mapstructure:"timeout_profile"`
+
+	// Adapt the timeouts of the propose, prevote and precommit steps to the
+	// durations these steps took in the last rounds committing a block,
+	// bounded by TimeoutAdaptiveMin and TimeoutAdaptiveMax. The deltas still
+	// increase them with each round.
+	TimeoutAdaptive    bool          `mapstructure:"timeout_adaptive"`
+	TimeoutAdaptiveMin time.Duration `mapstructure:"timeout_adaptive_min"`
+	TimeoutAdaptiveMax time.Duration `mapstructure:"timeout_adaptive_max"`
+
 	// Make progress as soon as we have all the precommits (as if TimeoutCommit = 0)
 	SkipTimeoutCommit bool `mapstructure:"skip_timeout_commit"`
 
@@ -1057,12 +1667,37 @@ type ConsensusConfig struct {
 	PeerQueryMaj23SleepDuration time.Duration `mapstructure:"peer_query_maj23_sleep_duration"`
 
 	DoubleSignCheckHeight int64 `mapstructure:"double_sign_check_height"`
+
+	// ShadowMode makes the node follow the consensus as the validator of its
+	// key without ever signing nor proposing: the votes it would sign are
+	// compared to the ones of the validator and the divergences are reported
+	// by the metrics.
+	ShadowMode bool `mapstructure:"shadow_mode"`
+
+	// How long the application has to answer ProcessProposal before the
+	// proposal is prevoted according to ProcessProposalFailOpen. 0 - no timeout.
+	ProcessProposalTimeout time.Duration `mapstructure:"process_proposal_timeout"`
+	// Prevote the proposal if the application doesn't answer ProcessProposal in
+	// time or fails to (fail-open), instead of prevoting nil (fail-closed).
+	ProcessProposalFailOpen bool `mapstructure:"process_proposal_fail_open"`
+
+	// The block parts and votes of the current height are gossiped first to
+	// the peers which are validators: a peer which isn't is sent a block part
+	// or a vote once the validator peers following the height have it.
+	PrioritizeValidatorGossip bool `mapstructure:"prioritize_validator_gossip"`
+
+	// The validators of the peers, as "node_id:validator_address", e.g.
+	// ["a8c3e1...:6F2B...", "2d0f...:0A1C..."]. A peer whose node key is its
+	// validator key is known to be the validator without being listed.
+	ValidatorPeers []string `mapstructure:"validator_peers"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service
 func DefaultConsensusConfig() *ConsensusConfig {
 	return &ConsensusConfig{
 		WalPath:                     filepath.Join(defaultDataDir, "cs.wal", "wal"),
+		WalCompression:              false,
+		WalWriteBufferSize:          1000,
 		TimeoutPropose:              3000 * time.Millisecond,
 		TimeoutProposeDelta:         500 * time.Millisecond,
 		TimeoutPrevote:              1000 * time.Millisecond,
@@ -1070,12 +1705,20 @@ func DefaultConsensusConfig() *ConsensusConfig {
 		TimeoutPrecommit:            1000 * time.Millisecond,
 		TimeoutPrecommitDelta:       500 * time.Millisecond,
 		TimeoutCommit:               1000 * time.Millisecond,
+		TimeoutProfile:              "",
+		TimeoutAdaptive:             false,
+		TimeoutAdaptiveMin:          100 * time.Millisecond,
+		TimeoutAdaptiveMax:          10 * time.Second,
 		SkipTimeoutCommit:           false,
 		CreateEmptyBlocks:           true,
 		CreateEmptyBlocksInterval:   0 * time.Second,
 		PeerGossipSleepDuration:     100 * time.Millisecond,
 		PeerQueryMaj23SleepDuration: 2000 * time.Millisecond,
 		DoubleSignCheckHeight:       int64(0),
+		ProcessProposalTimeout:      1000 * time.Millisecond,
+		ProcessProposalFailOpen:     false,
+		PrioritizeValidatorGossip:   false,
+		ValidatorPeers:              []string{},
 	}
 }
 
@@ -1102,31 +1745,95 @@ func (cfg *ConsensusConfig) WaitForTxs() bool {
 	return !cfg.CreateEmptyBlocks || cfg.CreateEmptyBlocksInterval > 0
 }
 
+// ConsensusTimeouts are the timeouts of the steps of the consensus.
+type ConsensusTimeouts struct {
+	Propose        time.Duration
+	ProposeDelta   time.Duration
+	Prevote        time.Duration
+	PrevoteDelta   time.Duration
+	Precommit      time.Duration
+	PrecommitDelta time.Duration
+	Commit         time.Duration
+}
+
+// TimeoutProfiles are the timeouts of the timeout_profile values, for the
+// validators of a local network, of a network within a region (the default
+// timeouts) and of a network spanning the world.
+var TimeoutProfiles = map[string]ConsensusTimeouts{
+	"lan": {
+		Propose:        1000 * time.Millisecond,
+		ProposeDelta:   200 * time.Millisecond,
+		Prevote:        200 * time.Millisecond,
+		PrevoteDelta:   100 * time.Millisecond,
+		Precommit:      200 * time.Millisecond,
+		PrecommitDelta: 100 * time.Millisecond,
+		Commit:         500 * time.Millisecond,
+	},
+	"wan": {
+		Propose:        3000 * time.Millisecond,
+		ProposeDelta:   500 * time.Millisecond,
+		Prevote:        1000 * time.Millisecond,
+		PrevoteDelta:   500 * time.Millisecond,
+		Precommit:      1000 * time.Millisecond,
+		PrecommitDelta: 500 * time.Millisecond,
+		Commit:         1000 * time.Millisecond,
+	},
+	"global": {
+		Propose:        6000 * time.Millisecond,
+		ProposeDelta:   1000 * time.Millisecond,
+		Prevote:        2000 * time.Millisecond,
+		PrevoteDelta:   1000 * time.Millisecond,
+		Precommit:      2000 * time.Millisecond,
+		PrecommitDelta: 1000 * time.Millisecond,
+		Commit:         2000 * time.Millisecond,
+	},
+}
+
+// Timeouts returns the timeouts of TimeoutProfile, or the timeouts configured
+// if there is no profile.
+func (cfg *ConsensusConfig) Timeouts() ConsensusTimeouts {
+	if t, ok := TimeoutProfiles[cfg.TimeoutProfile]; ok {
+		return t
+	}
+	return ConsensusTimeouts{
+		Propose:        cfg.TimeoutPropose,
+		ProposeDelta:   cfg.TimeoutProposeDelta,
+		Prevote:        cfg.TimeoutPrevote,
+		PrevoteDelta:   cfg.TimeoutPrevoteDelta,
+		Precommit:      cfg.TimeoutPrecommit,
+		PrecommitDelta: cfg.TimeoutPrecommitDelta,
+		Commit:         cfg.TimeoutCommit,
+	}
+}
+
 // Propose returns the amount of time to wait for a proposal
 func (cfg *ConsensusConfig) Propose(round int32) time.Duration {
+	t := cfg.Timeouts()
 	return time.Duration(
-		cfg.TimeoutPropose.Nanoseconds()+cfg.TimeoutProposeDelta.Nanoseconds()*int64(round),
+		t.Propose.Nanoseconds()+t.ProposeDelta.Nanoseconds()*int64(round),
 	) * time.Nanosecond
 }
 
 // Prevote returns the amount of time to wait for straggler votes after receiving any +2/3 prevotes
 func (cfg *ConsensusConfig) Prevote(round int32) time.Duration {
+	t := cfg.Timeouts()
 	return time.Duration(
-		cfg.TimeoutPrevote.Nanoseconds()+cfg.TimeoutPrevoteDelta.Nanoseconds()*int64(round),
+		t.Prevote.Nanoseconds()+t.PrevoteDelta.Nanoseconds()*int64(round),
 	) * time.Nanosecond
 }
 
 // Precommit returns the amount of time to wait for straggler votes after receiving any +2/3 precommits
 func (cfg *ConsensusConfig) Precommit(round int32) time.Duration {
+	t := cfg.Timeouts()
 	return time.Duration(
-		cfg.TimeoutPrecommit.Nanoseconds()+cfg.TimeoutPrecommitDelta.Nanoseconds()*int64(round),
+		t.Precommit.Nanoseconds()+t.PrecommitDelta.Nanoseconds()*int64(round),
 	) * time.Nanosecond
 }
 
 // Commit returns the amount of time to wait for straggler votes after receiving +2/3 precommits
 // for a single block (ie. a commit).
 func (cfg *ConsensusConfig) Commit(t time.Time) time.Time {
-	return t.Add(cfg.TimeoutCommit)
+	return t.Add(cfg.Timeouts().Commit)
 }
 
 // WalFile returns the full path to the write-ahead log file
@@ -1166,6 +1873,15 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.TimeoutCommit < 0 {
 		return errors.New("timeout_commit can't be negative")
 	}
+	if _, ok := TimeoutProfiles[cfg.TimeoutProfile]; cfg.TimeoutProfile != "" && !ok {
+		return fmt.Errorf("unknown timeout_profile %q", cfg.TimeoutProfile)
+	}
+	if cfg.TimeoutAdaptiveMin < 0 {
+		return errors.New("timeout_adaptive_min can't be negative")
+	}
+	if cfg.TimeoutAdaptiveMax < cfg.TimeoutAdaptiveMin {
+		return errors.New("timeout_adaptive_max can't be less than timeout_adaptive_min")
+	}
 	if cfg.CreateEmptyBlocksInterval < 0 {
 		return errors.New("create_empty_blocks_interval can't be negative")
 	}
@@ -1178,9 +1894,36 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.DoubleSignCheckHeight < 0 {
 		return errors.New("double_sign_check_height can't be negative")
 	}
+	if cfg.WalWriteBufferSize < 0 {
+		return errors.New("wal_write_buffer_size can't be negative")
+	}
+	if cfg.ProcessProposalTimeout < 0 {
+		return errors.New("process_proposal_timeout can't be negative")
+	}
+	if _, err := cfg.ValidatorPeerAddresses(); err != nil {
+		return fmt.Errorf("wrong validator_peers: %w", err)
+	}
 	return nil
 }
 
+// ValidatorPeerAddresses returns the validator addresses by node ID of
+// ValidatorPeers.
+func (cfg *ConsensusConfig) ValidatorPeerAddresses() (map[string][]byte, error) {
+	addrs := make(map[string][]byte, len(cfg.ValidatorPeers))
+	for _, peer := range cfg.ValidatorPeers {
+		i := strings.Index(peer, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q isn't node_id:validator_address", peer)
+		}
+		addr, err := hex.DecodeString(peer[i+1:])
+		if err != nil || len(addr) == 0 {
+			return nil, fmt.Errorf("the validator address of %q must be in hex", peer)
+		}
+		addrs[strings.ToLower(peer[:i])] = addr
+	}
+	return addrs, nil
+}
+
 //-----------------------------------------------------------------------------
 // StorageConfig
 
@@ -1191,6 +1934,20 @@ type StorageConfig struct {
 	// required for `/block_results` RPC queries, and to reindex events in the
 	// command-line tool.
 	DiscardABCIResponses bool `mapstructure:"discard_abci_responses"`
+
+	// Number of recent blocks to retain, pruning the older blocks, states and
+	// ABCI responses in the background. 0 retains all the blocks, unless the
+	// app returns a retain height in Commit. The blocks of the evidence which
+	// hasn't expired are always retained.
+	RetainBlocks int64 `mapstructure:"retain_blocks"`
+
+	// Interval the blocks are pruned at.
+	PruningInterval time.Duration `mapstructure:"pruning_interval"`
+
+	// Set true to retain the states of the pruned blocks: their validator
+	// sets, consensus params and ABCI responses (unless discarded), served
+	// by /validators, /consensus_params and /block_results at any height.
+	RetainStates bool `mapstructure:"retain_states"`
 }
 
 // DefaultStorageConfig returns the default configuration options relating to
@@ -1198,6 +1955,9 @@ type StorageConfig struct {
 func DefaultStorageConfig() *StorageConfig {
 	return &StorageConfig{
 		DiscardABCIResponses: false,
+		RetainBlocks:         0,
+		PruningInterval:      10 * time.Second,
+		RetainStates:         false,
 	}
 }
 
@@ -1206,9 +1966,23 @@ func DefaultStorageConfig() *StorageConfig {
 func TestStorageConfig() *StorageConfig {
 	return &StorageConfig{
 		DiscardABCIResponses: false,
+		RetainBlocks:         0,
+		PruningInterval:      10 * time.Second,
+		RetainStates:         false,
 	}
 }
 
+// ValidateBasic performs basic validation.
+func (cfg *StorageConfig) ValidateBasic() error {
+	if cfg.RetainBlocks < 0 {
+		return errors.New("retain_blocks can't be negative")
+	}
+	if cfg.PruningInterval <= 0 {
+		return errors.New("pruning_interval must be positive")
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // TxIndexConfig
 // Remember that Event has the following structure:
@@ -1254,6 +2028,8 @@ func TestTxIndexConfig() *TxIndexConfig {
 
 // InstrumentationConfig defines the configuration for metrics reporting.
 type InstrumentationConfig struct {
+	RootDir string `mapstructure:"home"`
+
 	// When true, Prometheus metrics are served under /metrics on
 	// PrometheusListenAddr.
 	// Check out the documentation for the list of available metrics.
@@ -1270,16 +2046,108 @@ type InstrumentationConfig struct {
 
 	// Instrumentation namespace.
 	Namespace string `mapstructure:"namespace"`
+
+	// Backend of the metrics: prometheus | statsd | otlp. The Prometheus
+	// metrics are enabled by Prometheus, while the StatsD and OTLP ones are
+	// pushed every MetricsPushInterval.
+	MetricsBackend string `mapstructure:"metrics_backend"`
+
+	// Address (host:port) of the StatsD agent to which the metrics are pushed
+	// over UDP.
+	StatsdAddress string `mapstructure:"statsd_address"`
+
+	// Address of the OpenTelemetry collector to which the metrics are pushed
+	// with OTLP.
+	MetricsOTLPEndpoint string `mapstructure:"metrics_otlp_endpoint"`
+
+	// Protocol used to push the metrics: grpc | http.
+	MetricsOTLPProtocol string `mapstructure:"metrics_otlp_protocol"`
+
+	// When true, the metrics are pushed without TLS.
+	MetricsOTLPInsecure bool `mapstructure:"metrics_otlp_insecure"`
+
+	// Interval between the pushes of the StatsD and OTLP metrics.
+	MetricsPushInterval time.Duration `mapstructure:"metrics_push_interval"`
+
+	// Address to listen for the liveness (/livez) and readiness (/readyz)
+	// probes, e.g. of Kubernetes. The probes are disabled if empty. They are
+	// also served by the RPC server on /health/live and /health/ready.
+	ProbesListenAddr string `mapstructure:"probes_listen_addr"`
+
+	// Maximum time the application and the signer have to answer the
+	// readiness probe.
+	ProbeTimeout time.Duration `mapstructure:"probe_timeout"`
+
+	// Maximum age of the latest block for the node to be ready. The age of
+	// the latest block isn't checked if 0.
+	ReadinessMaxBlockAge time.Duration `mapstructure:"readiness_max_block_age"`
+
+	// Maximum number of blocks the node can be behind its most advanced peer
+	// for the node to be ready. It isn't checked if 0.
+	ReadinessMaxBlocksBehind int64 `mapstructure:"readiness_max_blocks_behind"`
+
+	// Address of the OpenTelemetry collector to which the traces of the
+	// transactions are exported with OTLP. The tracing is disabled if empty.
+	TracingOTLPEndpoint string `mapstructure:"tracing_otlp_endpoint"`
+
+	// Protocol used to export the traces: grpc | http.
+	TracingOTLPProtocol string `mapstructure:"tracing_otlp_protocol"`
+
+	// When true, the traces are exported without TLS.
+	TracingOTLPInsecure bool `mapstructure:"tracing_otlp_insecure"`
+
+	// Fraction of the transactions submitted to the RPC server which are
+	// traced, between 0 and 1.
+	TracingSampleRate float64 `mapstructure:"tracing_sample_rate"`
+
+	// When true, the messages of the peers are traced too, sampled at
+	// TracingSampleRate: their sends and receipts by the switch and the
+	// connections, and their handling by the consensus reactor.
+	TracingEnabled bool `mapstructure:"tracing_enabled"`
+
+	// The profiles of the node are captured when a consensus round lasts more
+	// than WatchdogRoundTimeout (disabled if 0).
+	WatchdogRoundTimeout time.Duration `mapstructure:"watchdog_round_timeout"`
+
+	// The profiles of the node are captured when it doesn't commit any block
+	// for WatchdogCommitTimeout while it isn't syncing (disabled if 0).
+	WatchdogCommitTimeout time.Duration `mapstructure:"watchdog_commit_timeout"`
+
+	// Directory where the profiles captured by the watchdog are written.
+	WatchdogProfilesDir string `mapstructure:"watchdog_profiles_dir"`
+
+	// Maximum number of captures kept in WatchdogProfilesDir, the oldest ones
+	// are removed first.
+	WatchdogMaxCaptures int `mapstructure:"watchdog_max_captures"`
 }
 
 // DefaultInstrumentationConfig returns a default configuration for metrics
 // reporting.
 func DefaultInstrumentationConfig() *InstrumentationConfig {
 	return &InstrumentationConfig{
-		Prometheus:           false,
-		PrometheusListenAddr: ":26660",
-		MaxOpenConnections:   3,
-		Namespace:            "ostracon",
+		Prometheus:               false,
+		PrometheusListenAddr:     ":26660",
+		MaxOpenConnections:       3,
+		Namespace:                "ostracon",
+		MetricsBackend:           MetricsBackendPrometheus,
+		StatsdAddress:            "127.0.0.1:8125",
+		MetricsOTLPEndpoint:      "",
+		MetricsOTLPProtocol:      TracingOTLPProtocolGRPC,
+		MetricsOTLPInsecure:      false,
+		MetricsPushInterval:      10 * time.Second,
+		ProbesListenAddr:         "",
+		ProbeTimeout:             3 * time.Second,
+		ReadinessMaxBlockAge:     0,
+		ReadinessMaxBlocksBehind: 0,
+		TracingOTLPEndpoint:      "",
+		TracingOTLPProtocol:      TracingOTLPProtocolGRPC,
+		TracingOTLPInsecure:      false,
+		TracingSampleRate:        0.1,
+		TracingEnabled:           false,
+		WatchdogRoundTimeout:     0,
+		WatchdogCommitTimeout:    0,
+		WatchdogProfilesDir:      "data/profiles",
+		WatchdogMaxCaptures:      10,
 	}
 }
 
@@ -1295,9 +2163,81 @@ func (cfg *InstrumentationConfig) ValidateBasic() error {
 	if cfg.MaxOpenConnections < 0 {
 		return errors.New("max_open_connections can't be negative")
 	}
+	if cfg.ProbeTimeout <= 0 {
+		return errors.New("probe_timeout must be positive")
+	}
+	if cfg.ReadinessMaxBlockAge < 0 {
+		return errors.New("readiness_max_block_age can't be negative")
+	}
+	if cfg.ReadinessMaxBlocksBehind < 0 {
+		return errors.New("readiness_max_blocks_behind can't be negative")
+	}
+	switch cfg.MetricsBackend {
+	case MetricsBackendPrometheus:
+	case MetricsBackendStatsd:
+		if cfg.StatsdAddress == "" {
+			return errors.New("statsd_address can't be empty when the metrics_backend is 'statsd'")
+		}
+	case MetricsBackendOTLP:
+		if cfg.MetricsOTLPEndpoint == "" {
+			return errors.New("metrics_otlp_endpoint can't be empty when the metrics_backend is 'otlp'")
+		}
+		switch cfg.MetricsOTLPProtocol {
+		case TracingOTLPProtocolGRPC, TracingOTLPProtocolHTTP:
+		default:
+			return errors.New("unknown metrics_otlp_protocol (must be 'grpc' or 'http')")
+		}
+	default:
+		return errors.New("unknown metrics_backend (must be 'prometheus', 'statsd' or 'otlp')")
+	}
+	if cfg.MetricsBackend != MetricsBackendPrometheus && cfg.MetricsPushInterval <= 0 {
+		return errors.New("metrics_push_interval must be positive")
+	}
+	switch cfg.TracingOTLPProtocol {
+	case TracingOTLPProtocolGRPC, TracingOTLPProtocolHTTP:
+	default:
+		return errors.New("unknown tracing_otlp_protocol (must be 'grpc' or 'http')")
+	}
+	if cfg.TracingSampleRate < 0 || cfg.TracingSampleRate > 1 {
+		return errors.New("tracing_sample_rate must be between 0 and 1")
+	}
+	if cfg.TracingEnabled && cfg.TracingOTLPEndpoint == "" {
+		return errors.New("tracing_otlp_endpoint can't be empty when tracing_enabled is true")
+	}
+	if cfg.WatchdogRoundTimeout < 0 {
+		return errors.New("watchdog_round_timeout can't be negative")
+	}
+	if cfg.WatchdogCommitTimeout < 0 {
+		return errors.New("watchdog_commit_timeout can't be negative")
+	}
+	if cfg.WatchdogEnabled() {
+		if cfg.WatchdogProfilesDir == "" {
+			return errors.New("watchdog_profiles_dir can't be empty when the watchdog is enabled")
+		}
+		if cfg.WatchdogMaxCaptures <= 0 {
+			return errors.New("watchdog_max_captures must be positive when the watchdog is enabled")
+		}
+	}
 	return nil
 }
 
+// MetricsEnabled returns true if the metrics are served to Prometheus, or
+// pushed to StatsD or OTLP.
+func (cfg *InstrumentationConfig) MetricsEnabled() bool {
+	return cfg.Prometheus || cfg.MetricsBackend != MetricsBackendPrometheus
+}
+
+// WatchdogEnabled returns true if the profiling watchdog is enabled.
+func (cfg *InstrumentationConfig) WatchdogEnabled() bool {
+	return cfg.WatchdogRoundTimeout > 0 || cfg.WatchdogCommitTimeout > 0
+}
+
+// ProfilesDir returns the full path to the directory of the profiles captured
+// by the watchdog.
+func (cfg *InstrumentationConfig) ProfilesDir() string {
+	return rootify(cfg.WatchdogProfilesDir, cfg.RootDir)
+}
+
 //-----------------------------------------------------------------------------
 // Utils
 
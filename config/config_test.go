@@ -61,6 +61,85 @@ func TestBaseConfigValidateBasic(t *testing.T) {
 	// tamper with log format
 	cfg.LogFormat = "invalid"
 	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with mode
+	cfg = TestBaseConfig()
+	cfg.Mode = ModeSeed
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.Mode = "validator"
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with the application run by the node
+	cfg = TestBaseConfig()
+	cfg.ProxyApp = "exec:/usr/bin/myapp --address {abci_addr}"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.ProxyApp = "exec: "
+	assert.Error(t, cfg.ValidateBasic())
+	cfg = TestBaseConfig()
+	cfg.ProxyAppStartTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg = TestBaseConfig()
+	cfg.ProxyAppMaxRestarts = -1
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with the shutdown
+	cfg = TestBaseConfig()
+	cfg.ShutdownDrainTimeout = -1
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with the proxy connections
+	cfg = TestBaseConfig()
+	cfg.ProxyQueryConnections = 4
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.ProxyQueryConnections = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg = TestBaseConfig()
+	cfg.ProxyMempoolConnections = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with custom reactors
+	cfg = TestBaseConfig()
+	cfg.CustomReactors = []string{"myapp.oracle", "myapp.feed"}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.CustomReactors = []string{"myapp.oracle", ""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.CustomReactors = []string{"myapp.oracle", "myapp.oracle"}
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with the gRPC remote signer
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorGRPCAddr = "tcp://signer:26659"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.PrivValidatorListenAddr = "tcp://0.0.0.0:26659"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorListenAddr = ""
+	cfg.PrivValidatorGRPCCert = "config/node.crt"
+	cfg.PrivValidatorGRPCKey = "config/node.key"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorGRPCRootCA = "config/ca.crt"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// tamper with the priv validator backend
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorBackend = "vault"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorBackend = PrivValidatorBackendPKCS11
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorPKCS11Module = "/usr/lib/softhsm/libsofthsm2.so"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.PrivValidatorListenAddr = "tcp://0.0.0.0:26659"
+	assert.Error(t, cfg.ValidateBasic())
+
+	// tamper with the priv validator state backend
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorStateBackend = "etcd"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorStateBackend = PrivValidatorStateBackendPsql
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PrivValidatorStatePsqlConn = "postgresql://localhost/validator"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.PrivValidatorStateLeaseTTL = 0
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestRPCConfigValidateBasic(t *testing.T) {
@@ -76,6 +155,10 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		"MaxBodyBytes",
 		"MaxBatchRequestNum",
 		"MaxHeaderBytes",
+		"RateLimitRequests",
+		"RateLimitTotalRequests",
+		"RateLimitWindow",
+		"ResponseCacheSize",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -83,6 +166,27 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.SubscriptionOverflowPolicy = "drop_oldest"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.SubscriptionOverflowPolicy = "drop_all"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.SubscriptionOverflowPolicy = "disconnect"
+
+	cfg.RateLimitRequests = 10
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RateLimitWindow = time.Second
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.RateLimitMethods = []string{"broadcast_tx_commit:10", "tx_search:100"}
+	assert.NoError(t, cfg.ValidateBasic())
+	limits, err := cfg.MethodRateLimits()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"broadcast_tx_commit": 10, "tx_search": 100}, limits)
+	for _, limit := range []string{"tx_search", ":10", "tx_search:0", "tx_search:many"} {
+		cfg.RateLimitMethods = []string{limit}
+		assert.Error(t, cfg.ValidateBasic(), limit)
+	}
 }
 
 func TestP2PConfigValidateBasic(t *testing.T) {
@@ -96,6 +200,11 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"RecvMessageBurst",
+		"MaxPendingRecvBytes",
+		"PeerScoreHalfLife",
+		"PeerBanDuration",
+		"NATLeaseDuration",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -103,6 +212,49 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.RecvMessageRate = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RecvMessageRate = 0
+
+	for _, fieldName := range []string{"PeerDisconnectScore", "PeerBanScore"} {
+		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetFloat(1)
+		assert.Error(t, cfg.ValidateBasic())
+		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetFloat(0)
+	}
+
+	// the port mappings must be leased
+	cfg.NATPMP = true
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.NATLeaseDuration = time.Minute
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.Compression = "gzip"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.Compression = "zstd"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.ListenAddresses = []string{"tcp://0.0.0.0:26656", ""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ListenAddresses = nil
+	cfg.ExternalAddresses = []string{""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ExternalAddresses = nil
+}
+
+func TestP2PConfigAddrs(t *testing.T) {
+	cfg := TestP2PConfig()
+	assert.Equal(t, []string{cfg.ListenAddress}, cfg.ListenAddrs())
+	assert.Empty(t, cfg.ExternalAddrs())
+
+	cfg.ExternalAddress = "1.2.3.4:26656"
+	assert.Equal(t, []string{"1.2.3.4:26656"}, cfg.ExternalAddrs())
+
+	// the lists take precedence
+	cfg.ListenAddresses = []string{"tcp://0.0.0.0:26656", "tcp://[::]:26656"}
+	cfg.ExternalAddresses = []string{"[2001:db8::1]:26656", "1.2.3.4:26656"}
+	assert.Equal(t, cfg.ListenAddresses, cfg.ListenAddrs())
+	assert.Equal(t, cfg.ExternalAddresses, cfg.ExternalAddrs())
 }
 
 func TestMempoolConfigValidateBasic(t *testing.T) {
@@ -114,6 +266,14 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		"MaxTxsBytes",
 		"CacheSize",
 		"MaxTxBytes",
+		"TTLDuration",
+		"TTLNumBlocks",
+		"PeerTxBurst",
+		"TotalPeerTxBurst",
+		"PeerSendRate",
+		"PeerRecvRate",
+		"HaveTxFilterSize",
+		"CheckTxBatchWindow",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -121,6 +281,12 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	for _, fieldName := range []string{"PeerTxRate", "TotalPeerTxRate"} {
+		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetFloat(-1)
+		assert.Error(t, cfg.ValidateBasic())
+		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetFloat(0)
+	}
 }
 
 func TestStateSyncConfigValidateBasic(t *testing.T) {
@@ -153,6 +319,9 @@ func TestStateSyncConfigValidateBasic(t *testing.T) {
 	cfg.TrustHash = "0"
 	testVerify("invalid trusted_hash: encoding/hex: odd length hex string")
 	cfg.TrustHash = "00"
+	cfg.GenesisHash = "zz"
+	testVerify("invalid genesis_hash: encoding/hex: invalid byte: U+007A 'z'")
+	cfg.GenesisHash = "00"
 	// Success with Enabled
 	require.NoError(t, cfg.ValidateBasic())
 }
@@ -169,6 +338,22 @@ func TestFastSyncConfigValidateBasic(t *testing.T) {
 	assert.Error(t, cfg.ValidateBasic())
 }
 
+func TestStorageConfigValidateBasic(t *testing.T) {
+	cfg := TestStorageConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// tamper with retain blocks
+	cfg.RetainBlocks = 100
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.RetainBlocks = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RetainBlocks = 0
+
+	// tamper with pruning interval
+	cfg.PruningInterval = 0
+	assert.Error(t, cfg.ValidateBasic())
+}
+
 //nolint:lll
 func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	// nolint: lll
@@ -190,11 +375,21 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 		"TimeoutPrecommitDelta negative":       {func(c *ConsensusConfig) { c.TimeoutPrecommitDelta = -1 }, true},
 		"TimeoutCommit":                        {func(c *ConsensusConfig) { c.TimeoutCommit = time.Second }, false},
 		"TimeoutCommit negative":               {func(c *ConsensusConfig) { c.TimeoutCommit = -1 }, true},
+		"TimeoutProfile":                       {func(c *ConsensusConfig) { c.TimeoutProfile = "global" }, false},
+		"TimeoutProfile unknown":               {func(c *ConsensusConfig) { c.TimeoutProfile = "moon" }, true},
+		"TimeoutAdaptiveMin negative":          {func(c *ConsensusConfig) { c.TimeoutAdaptiveMin = -1 }, true},
+		"TimeoutAdaptiveMax less than min":     {func(c *ConsensusConfig) { c.TimeoutAdaptiveMax = c.TimeoutAdaptiveMin - 1 }, true},
 		"PeerGossipSleepDuration":              {func(c *ConsensusConfig) { c.PeerGossipSleepDuration = time.Second }, false},
 		"PeerGossipSleepDuration negative":     {func(c *ConsensusConfig) { c.PeerGossipSleepDuration = -1 }, true},
 		"PeerQueryMaj23SleepDuration":          {func(c *ConsensusConfig) { c.PeerQueryMaj23SleepDuration = time.Second }, false},
 		"PeerQueryMaj23SleepDuration negative": {func(c *ConsensusConfig) { c.PeerQueryMaj23SleepDuration = -1 }, true},
 		"DoubleSignCheckHeight negative":       {func(c *ConsensusConfig) { c.DoubleSignCheckHeight = -1 }, true},
+		"WalWriteBufferSize negative":          {func(c *ConsensusConfig) { c.WalWriteBufferSize = -1 }, true},
+		"ProcessProposalTimeout":               {func(c *ConsensusConfig) { c.ProcessProposalTimeout = 0 }, false},
+		"ProcessProposalTimeout negative":      {func(c *ConsensusConfig) { c.ProcessProposalTimeout = -1 }, true},
+		"ValidatorPeers":                       {func(c *ConsensusConfig) { c.ValidatorPeers = []string{"a8c3:6F2B"} }, false},
+		"ValidatorPeers no address":            {func(c *ConsensusConfig) { c.ValidatorPeers = []string{"a8c3"} }, true},
+		"ValidatorPeers address not hex":       {func(c *ConsensusConfig) { c.ValidatorPeers = []string{"a8c3:xyz"} }, true},
 	}
 	for desc, tc := range testcases {
 		tc := tc // appease linter
@@ -212,6 +407,26 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	}
 }
 
+func TestConsensusConfigValidatorPeerAddresses(t *testing.T) {
+	cfg := DefaultConsensusConfig()
+	cfg.ValidatorPeers = []string{"A8C3E1:6f2b", "2d0f:0A1C"}
+	addrs, err := cfg.ValidatorPeerAddresses()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a8c3e1": {0x6f, 0x2b}, "2d0f": {0x0a, 0x1c}}, addrs)
+}
+
+func TestConsensusConfigTimeoutProfile(t *testing.T) {
+	cfg := DefaultConsensusConfig()
+	assert.Equal(t, cfg.TimeoutPropose+2*cfg.TimeoutProposeDelta, cfg.Propose(2))
+
+	cfg.TimeoutProfile = "lan"
+	lan := TimeoutProfiles["lan"]
+	assert.Equal(t, lan.Propose+2*lan.ProposeDelta, cfg.Propose(2))
+	assert.Equal(t, lan.Prevote+2*lan.PrevoteDelta, cfg.Prevote(2))
+	assert.Equal(t, lan.Precommit+2*lan.PrecommitDelta, cfg.Precommit(2))
+	assert.Equal(t, time.Unix(0, 0).Add(lan.Commit), cfg.Commit(time.Unix(0, 0)))
+}
+
 func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	cfg := TestInstrumentationConfig()
 	assert.NoError(t, cfg.ValidateBasic())
@@ -219,4 +434,67 @@ func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	// tamper with maximum open connections
 	cfg.MaxOpenConnections = -1
 	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.ProbeTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.ReadinessMaxBlockAge = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.ReadinessMaxBlocksBehind = -1
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.MetricsBackend = "graphite"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MetricsBackend = MetricsBackendStatsd
+	assert.NoError(t, cfg.ValidateBasic())
+	assert.True(t, cfg.MetricsEnabled())
+	cfg.StatsdAddress = ""
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.MetricsBackend = MetricsBackendOTLP
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MetricsOTLPEndpoint = "localhost:4317"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.MetricsOTLPProtocol = "zipkin"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MetricsOTLPProtocol = TracingOTLPProtocolHTTP
+	cfg.MetricsPushInterval = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.TracingOTLPProtocol = TracingOTLPProtocolHTTP
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.TracingOTLPProtocol = "zipkin"
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.TracingSampleRate = 1.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TracingSampleRate = -0.1
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.TracingEnabled = true
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TracingOTLPEndpoint = "localhost:4317"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.WatchdogRoundTimeout = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WatchdogRoundTimeout = time.Minute
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.WatchdogMaxCaptures = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.WatchdogCommitTimeout = time.Minute
+	cfg.WatchdogProfilesDir = ""
+	assert.Error(t, cfg.ValidateBasic())
 }
@@ -81,17 +81,44 @@ const defaultConfigTemplate = `# This is a TOML config file.
 #######################################################################
 
 # TCP or UNIX socket address of the ABCI application,
-# or the name of an ABCI application compiled in with the Ostracon binary
+# or the name of an ABCI application compiled in with the Ostracon binary,
+# or the command line of the application the node runs, prefixed with "exec:",
+# e.g. "exec:/usr/bin/myapp start --address {abci_addr}". The application must
+# listen to the address replacing {abci_addr}, also set in its
+# OSTRACON_ABCI_ADDR environment variable.
 proxy_app = "{{ .BaseConfig.ProxyApp }}"
 
+# Supervision of the application run by the node: the time it has to accept
+# the connections once started, the number of times it's restarted if it
+# doesn't, the time it has to exit once sent SIGTERM before being killed, and
+# the interval of the health checks stopping it (and so the node) once they
+# failed 3 times in a row (0 not to check).
+proxy_app_start_timeout = "{{ .BaseConfig.ProxyAppStartTimeout }}"
+proxy_app_max_restarts = {{ .BaseConfig.ProxyAppMaxRestarts }}
+proxy_app_stop_timeout = "{{ .BaseConfig.ProxyAppStopTimeout }}"
+proxy_app_health_check_interval = "{{ .BaseConfig.ProxyAppHealthCheckInterval }}"
+
 # A custom human readable name for this node
 moniker = "{{ .BaseConfig.Moniker }}"
 
+# Mode of the node: full | seed
+# * full: runs all the services (default)
+# * seed: only runs the PEX reactor in seed mode and the address book, without
+#   consensus, mempool, block store nor application. The node crawls the network
+#   and shares the addresses it knows with the connecting peers. Its RPC server
+#   only serves /health, /net_info and /crawl_stats, the statistics of the crawl.
+mode = "{{ .BaseConfig.Mode }}"
+
 # If this node is many blocks behind the tip of the chain, FastSync
 # allows them to catchup quickly by downloading blocks in parallel
 # and verifying their commits
 fast_sync = {{ .BaseConfig.FastSyncMode }}
 
+# Maximum time the node waits, when stopping, for the height it's voting in to
+# be committed, not to stop between its votes of a height. RPC writes and
+# mempool gossip are stopped first. 0 not to wait.
+shutdown_drain_timeout = "{{ .BaseConfig.ShutdownDrainTimeout }}"
+
 # Database backend: goleveldb | cleveldb | boltdb | rocksdb | badgerdb
 # * goleveldb (github.com/syndtr/goleveldb - most popular implementation)
 #   - pure go
@@ -109,17 +136,20 @@ fast_sync = {{ .BaseConfig.FastSyncMode }}
 #   - requires gcc
 #   - use rocksdb build tag (go build -tags rocksdb)
 # * badgerdb (uses github.com/dgraph-io/badger)
-#   - EXPERIMENTAL
-#   - use badgerdb build tag (go build -tags badgerdb)
+#   - no stalls on the compactions of the levels
+#   - use badgerdb build tag (go build -tags badgerdb), included by make build
+# The databases are migrated to another backend with the
+# experimental-db-migrate command.
 db_backend = "{{ .BaseConfig.DBBackend }}"
 
 # Database directory
 db_dir = "{{ js .BaseConfig.DBPath }}"
 
 # Output level for logging, including package level options
+# It can be changed while running with the unsafe_set_log_level RPC endpoint.
 log_level = "{{ .BaseConfig.LogLevel }}"
 
-# Output format: 'plain' (colored text) or 'json'
+# Output format: 'plain' (colored text) or 'json' (a JSON object per line)
 log_format = "{{ .BaseConfig.LogFormat }}"
 
 # LogPath is the file to write logs to(log dir + log filename)
@@ -154,6 +184,45 @@ priv_validator_key_file = "{{ js .BaseConfig.PrivValidatorKey }}"
 # Path to the JSON file containing the last sign state of a validator
 priv_validator_state_file = "{{ js .BaseConfig.PrivValidatorState }}"
 
+# Backend of the local validator key: file | pkcs11
+# The pkcs11 backend signs with the key of a PKCS#11 token (e.g. an HSM), which never exists
+# on disk (Ostracon must be built with the pkcs11 tag). Only the last sign state is in
+# priv_validator_state_file.
+priv_validator_backend = "{{ .BaseConfig.PrivValidatorBackend }}"
+
+# Path to the PKCS#11 module (shared library) of the token
+# example) /usr/lib/softhsm/libsofthsm2.so
+priv_validator_pkcs11_module = "{{ js .BaseConfig.PrivValidatorPKCS11Module }}"
+
+# Slot of the token
+priv_validator_pkcs11_slot = {{ .BaseConfig.PrivValidatorPKCS11Slot }}
+
+# PIN of the user of the token
+# Better set with the OC_PRIV_VALIDATOR_PKCS11_PIN environment variable.
+priv_validator_pkcs11_pin = "{{ js .BaseConfig.PrivValidatorPKCS11PIN }}"
+
+# Label of the Ed25519 key pair of the validator in the token
+priv_validator_pkcs11_key_label = "{{ js .BaseConfig.PrivValidatorPKCS11KeyLabel }}"
+
+# Vendor-defined mechanism of the token generating the VRF (ECVRF-EDWARDS25519-SHA512-TAI)
+# proofs of the key with C_Sign, as the VRF isn't a standard PKCS#11 mechanism.
+# 0 if the token doesn't support it: the validator can then sign but not propose.
+priv_validator_pkcs11_vrf_mechanism = {{ .BaseConfig.PrivValidatorPKCS11VRFMechanism }}
+
+# Backend of the last sign state of the local validator: file | psql
+# The psql backend persists it in a PostgreSQL database shared by an active and a passive
+# validator of the same key, for a safe failover: only the one holding the lease of the state
+# signs, the other one taking over once the lease expired (and fencing the previous one).
+priv_validator_state_backend = "{{ .BaseConfig.PrivValidatorStateBackend }}"
+
+# The PostgreSQL connection string of the psql backend
+# example) postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
+priv_validator_state_psql_conn = "{{ js .BaseConfig.PrivValidatorStatePsqlConn }}"
+
+# Time for which the validator leases the state, since its last signature, before a passive
+# validator can take over. It must be longer than the time between the blocks.
+priv_validator_state_lease_ttl = "{{ .BaseConfig.PrivValidatorStateLeaseTTL }}"
+
 # TCP or UNIX socket address for Ostracon to listen on for
 # connections from an external PrivValidator process
 # If this value is set, key file(priv_validator_key.json) will not be generated.
@@ -167,16 +236,41 @@ priv_validator_laddr = "{{ .BaseConfig.PrivValidatorListenAddr }}"
 # example) [ "127.0.0.1", "192.168.1.2" ]
 priv_validator_raddrs = [ "127.0.0.1" ]
 
+# TCP or UNIX socket address of an external PrivValidator process serving
+# the gRPC protocol, for Ostracon to dial (instead of listening on priv_validator_laddr)
+# If this value is set, key file(priv_validator_key.json) will not be generated.
+# example) tcp://signer:26659
+priv_validator_grpc_addr = "{{ .BaseConfig.PrivValidatorGRPCAddr }}"
+
+# Paths to the PEM files of the certificate and key of the node, and of the CA of the
+# certificate of the PrivValidator process, for the mutual TLS authentication of the
+# gRPC connection. The connection is insecure if they are empty.
+# The certificate and key can be rotated by replacing the files.
+priv_validator_grpc_cert_file = "{{ js .BaseConfig.PrivValidatorGRPCCert }}"
+priv_validator_grpc_key_file = "{{ js .BaseConfig.PrivValidatorGRPCKey }}"
+priv_validator_grpc_ca_file = "{{ js .BaseConfig.PrivValidatorGRPCRootCA }}"
+
 # Path to the JSON file containing the private key to use for node authentication in the p2p protocol
 node_key_file = "{{ js .BaseConfig.NodeKey }}"
 
 # Mechanism to connect to the ABCI application: socket | grpc
 abci = "{{ .BaseConfig.ABCI }}"
 
+# Numbers of connections to the ABCI application the queries (e.g. the
+# abci_query RPC) and the CheckTx requests are spread over, so a slow request
+# doesn't block the others, if the application serves them concurrently. With
+# several mempool connections, the txs are checked out of order.
+proxy_query_connections = {{ .BaseConfig.ProxyQueryConnections }}
+proxy_mempool_connections = {{ .BaseConfig.ProxyMempoolConnections }}
+
 # If true, query the ABCI app on connecting to a new peer
 # so the app can decide if we should keep the connection or not
 filter_peers = {{ .BaseConfig.FilterPeers }}
 
+# The names of the custom reactors to add to the node, among the ones compiled
+# in the binary by the application, e.g. ["myapp.oracle"]
+custom_reactors = [{{ range .BaseConfig.CustomReactors }}{{ printf "%q, " . }}{{end}}]
+
 
 #######################################################################
 ###                 Advanced Configuration Options                  ###
@@ -202,9 +296,14 @@ cors_allowed_methods = [{{ range .RPC.CORSAllowedMethods }}{{ printf "%q, " . }}
 cors_allowed_headers = [{{ range .RPC.CORSAllowedHeaders }}{{ printf "%q, " . }}{{end}}]
 
 # TCP or UNIX socket address for the gRPC server to listen on
-# NOTE: This server only supports /broadcast_tx_commit
+# NOTE: This server only supports /broadcast_tx_commit, unless grpc_full_api
 grpc_laddr = "{{ .RPC.GRPCListenAddress }}"
 
+# Serve /status, /block, /block_results, /validators, /tx, /tx_search and the
+# event subscriptions (with from_height and filter_events, see /subscribe)
+# over gRPC as well, see proto/ostracon/rpc/grpc/query.proto
+grpc_full_api = {{ .RPC.GRPCFullAPI }}
+
 # Maximum number of simultaneous connections.
 # Does not include RPC (HTTP&WebSocket) connections. See max_open_connections
 # If you want to accept a larger number than the default, make sure
@@ -265,6 +364,15 @@ max_subscriptions_per_client = {{ .RPC.MaxSubscriptionsPerClient }}
 # higher event throughput rates (and will use more memory).
 experimental_subscription_buffer_size = {{ .RPC.SubscriptionBufferSize }}
 
+# Experimental parameter to specify what the node does when the buffer of a
+# subscription is full:
+#   1) "disconnect" - the subscription is closed with an error (default)
+#   2) "drop_oldest" - the oldest buffered event is dropped
+#   3) "drop_new" - the new event is dropped
+#   4) "block" - the node waits for the subscriber, which delays the delivery
+#      of the events to all the other subscribers (not recommended)
+experimental_subscription_overflow_policy = "{{ .RPC.SubscriptionOverflowPolicy }}"
+
 # Experimental parameter to specify the maximum number of RPC responses that
 # can be buffered per WebSocket client. If clients cannot read from the
 # WebSocket endpoint fast enough, they will be disconnected, so increasing this
@@ -301,6 +409,41 @@ max_batch_request_num = {{ .RPC.MaxBatchRequestNum }}
 # Maximum size of request header, in bytes
 max_header_bytes = {{ .RPC.MaxHeaderBytes }}
 
+# Maximum number of requests of a client (by IP address) per
+# rate_limit_window. The requests over it are rejected with the HTTP status
+# 429 (Too Many Requests). A batch of requests, or a WebSocket connection,
+# counts as a single request.
+# 0 - unlimited.
+rate_limit_requests = {{ .RPC.RateLimitRequests }}
+
+# Maximum number of requests of all the clients per rate_limit_window.
+# 0 - unlimited.
+rate_limit_total_requests = {{ .RPC.RateLimitTotalRequests }}
+
+# Window of the rate limits of the requests (sliding over time)
+rate_limit_window = "{{ .RPC.RateLimitWindow }}"
+
+# Maximum number of calls of a method by a client (by IP address) per
+# rate_limit_window, by method, as "method:calls", e.g.
+# ["broadcast_tx_commit:10", "tx_search:100"]. The calls over it are rejected
+# with an error, the calls of a batch of requests, or of a WebSocket
+# connection, counting as several calls.
+rate_limit_methods = [{{ range .RPC.RateLimitMethods }}{{ printf "%q, " . }}{{end}}]
+
+# The only methods served if not empty, e.g. ["status", "block", "tx"].
+enabled_methods = [{{ range .RPC.EnabledMethods }}{{ printf "%q, " . }}{{end}}]
+
+# The methods not served, e.g. ["broadcast_tx_commit", "dial_peers"]. They
+# are answered as unknown methods.
+disabled_methods = [{{ range .RPC.DisabledMethods }}{{ printf "%q, " . }}{{end}}]
+
+# Maximum number of responses of /block, /block_results, /validators and
+# /consensus_params at the past heights cached in memory, which don't change,
+# for the nodes serving historical heights to many clients. The least recently
+# used ones are evicted first, the ones of the pruned heights as well.
+# 0 - no cache.
+response_cache_size = {{ .RPC.ResponseCacheSize }}
+
 # The path to a file containing certificate that is used to create the HTTPS server.
 # Might be either absolute path or path related to Ostracon's config directory.
 # If the certificate is signed by a certificate authority,
@@ -327,22 +470,54 @@ pprof_laddr = "{{ .RPC.PprofListenAddress }}"
 # Address to listen for incoming connections
 laddr = "{{ .P2P.ListenAddress }}"
 
+# Addresses to listen for incoming connections, instead of laddr if set, e.g.
+# [ "tcp://0.0.0.0:26656", "tcp://[::]:26656" ]. The port of the first one is
+# mapped by upnp and nat_pmp
+laddrs = [{{ range .P2P.ListenAddresses }}{{ printf "%q, " . }}{{end}}]
+
 # Address to advertise to peers for them to dial
-# If empty, will use the same port as the laddr,
-# and will introspect on the listener or use UPnP
-# to figure out the address. ip and port are required
+# If empty, will use the laddr, or the address learned with upnp, nat_pmp or
+# learn_external_address. ip and port are required
 # example: 159.89.10.97:26656
 external_address = "{{ .P2P.ExternalAddress }}"
 
+# Addresses to advertise to peers for them to dial, in the order of preference,
+# instead of external_address if set. The peers dial the next one when they
+# fail to dial one
+external_addresses = [{{ range .P2P.ExternalAddresses }}{{ printf "%q, " . }}{{end}}]
+
 # Comma separated list of seed nodes to connect to
 seeds = "{{ .P2P.Seeds }}"
 
+# DNS name of the SRV records of the peers to dial, in addition to the seeds,
+# e.g. "_ostracon._tcp.example.com". The target of a record is the host of a
+# peer, prefixed with its ID, e.g. "<ID>.node1.example.com".
+discovery_srv = "{{ .P2P.DiscoverySRV }}"
+
+# Set true to dial the current validators, whose addresses (ID@host:port) the
+# application returns for the ABCI query "/p2p/validators" as a comma
+# separated list, e.g. the ones in the app_state of the genesis.
+discover_validators = {{ .P2P.DiscoverValidators }}
+
 # Comma separated list of nodes to keep persistent connections to
 persistent_peers = "{{ .P2P.PersistentPeers }}"
 
-# UPNP port forwarding
+# UPNP port forwarding: map the port of the laddr on the gateway, and advertise
+# its external address unless external_address is set
 upnp = {{ .P2P.UPNP }}
 
+# NAT-PMP port forwarding, as upnp, tried first if both are set
+nat_pmp = {{ .P2P.NATPMP }}
+
+# Lease of the port mappings of upnp and nat_pmp, renewed at half of it
+nat_lease_duration = "{{ .P2P.NATLeaseDuration }}"
+
+# Set true to advertise the IP the outbound peers report the node is seen
+# from, with the port of the laddr, unless external_address is set. Useful
+# behind a NAT whose port is forwarded by hand, the address being learned
+# once 3 peers report the same routable IP
+learn_external_address = {{ .P2P.LearnExternalAddress }}
+
 # Path to address book
 addr_book_file = "{{ js .P2P.AddrBook }}"
 
@@ -374,13 +549,49 @@ send_rate = {{ .P2P.SendRate }}
 # Rate at which packets can be received, in bytes/second
 recv_rate = {{ .P2P.RecvRate }}
 
+# Rate at which messages can be received from a peer, in messages/second.
+# A peer exceeding it is disconnected.
+# 0 - unlimited.
+recv_message_rate = {{ .P2P.RecvMessageRate }}
+
+# Number of messages which can be received at once from a peer, above
+# recv_message_rate.
+# 0 - one second of messages.
+recv_message_burst = {{ .P2P.RecvMessageBurst }}
+
+# Bytes of the messages received from all the peers which can be queued for the
+# reactors or being handled by them. Once reached, the node stops reading from
+# the peers until the reactors catch up.
+# 0 - unlimited.
+max_pending_recv_bytes = {{ .P2P.MaxPendingRecvBytes }}
+
+# Algorithm the block parts and the snapshot chunks are compressed with, for
+# the peers which can decompress them: "snappy", "zstd" or "" for none. A node
+# decompresses the messages of the peers whatever it is.
+compression = "{{ .P2P.Compression }}"
+
+# Half-life of the scores of the peers, changed by the misbehaviors (e.g. the
+# invalid messages) and the useful messages the reactors report.
+# 0 - the scores don't decay.
+peer_score_half_life = "{{ .P2P.PeerScoreHalfLife }}"
+
+# Score at which a peer is disconnected, e.g. -50 for an invalid message.
+# 0 - never.
+peer_disconnect_score = {{ .P2P.PeerDisconnectScore }}
+
+# Score at which a peer is banned for peer_ban_duration.
+# 0 - never.
+peer_ban_score = {{ .P2P.PeerBanScore }}
+peer_ban_duration = "{{ .P2P.PeerBanDuration }}"
+
 # Set true to enable the peer-exchange reactor
 pex = {{ .P2P.PexReactor }}
 
 # Seed mode, in which node constantly crawls the network and looks for
 # peers. If another node asks it for addresses, it responds and disconnects.
 #
-# Does not work if the peer-exchange reactor is disabled.
+# Does not work if the peer-exchange reactor is disabled. The full node keeps
+# running all its services: see mode = "seed" for a node only serving addresses.
 seed_mode = {{ .P2P.SeedMode }}
 
 # Comma separated list of peer IDs to keep private (will not be gossiped to other peers)
@@ -396,7 +607,7 @@ dial_timeout = "{{ .P2P.DialTimeout }}"
 # Sync/async of reactor's receive function
 recv_async = {{ .P2P.RecvAsync }}
 
-# Size of channel buffer of reactor
+# Size of channel buffer of reactor, for each of its channels
 pex_recv_buf_size = {{ .P2P.PexRecvBufSize }}
 mempool_recv_buf_size = {{ .P2P.MempoolRecvBufSize }}
 evidence_recv_buf_size = {{ .P2P.EvidenceRecvBufSize }}
@@ -411,7 +622,8 @@ statesync_recv_buf_size = {{ .P2P.StatesyncRecvBufSize }}
 
 # Mempool version to use:
 #   1) "v0" - (default) FIFO mempool.
-#   2) "v1" - prioritized mempool.
+#   2) "v1" - prioritized mempool, reaping the txs by the priorities of
+#      their CheckTx responses and evicting the lowest ones when full.
 version = "{{ .Mempool.Version }}"
 
 recheck = {{ .Mempool.Recheck }}
@@ -459,6 +671,60 @@ ttl-duration = "{{ .Mempool.TTLDuration }}"
 # it's insertion time into the mempool is beyond ttl-duration.
 ttl-num-blocks = {{ .Mempool.TTLNumBlocks }}
 
+# ttl-recheck, if true, re-CheckTx's the transactions outliving their TTL
+# instead of removing them: the ones still valid are kept for a new TTL.
+# The transactions removed are kept in the cache, not to be received again from
+# the peers still gossiping them.
+ttl-recheck = {{ .Mempool.TTLRecheck }}
+
+# Rate at which txs are accepted from a peer, in txs/second. The txs over it
+# are dropped, without being checked.
+# 0 - unlimited.
+peer_tx_rate = {{ .Mempool.PeerTxRate }}
+
+# Number of txs which can be accepted at once from a peer, above peer_tx_rate.
+# 0 - one second of txs.
+peer_tx_burst = {{ .Mempool.PeerTxBurst }}
+
+# Rate at which txs are accepted from all the peers, in txs/second.
+# 0 - unlimited.
+total_peer_tx_rate = {{ .Mempool.TotalPeerTxRate }}
+
+# Number of txs which can be accepted at once from all the peers, above
+# total_peer_tx_rate.
+# 0 - one second of txs.
+total_peer_tx_burst = {{ .Mempool.TotalPeerTxBurst }}
+
+# Rate at which txs are sent to a peer, in bytes/second, capping the bandwidth
+# of the txs gossip without throttling the consensus messages.
+# 0 - unlimited.
+peer_send_rate = {{ .Mempool.PeerSendRate }}
+
+# Rate at which txs are received from a peer, in bytes/second. The peers
+# sending more (with a margin) are disconnected, so it should not be less than
+# the peer_send_rate of the peers.
+# 0 - unlimited.
+peer_recv_rate = {{ .Mempool.PeerRecvRate }}
+
+# Number of recent txs remembered as known by each peer, sent by it or
+# announced by it, not to be sent to it again. The txs are remembered in bloom
+# filters, so that a few txs not known by a peer may not be sent to it (it
+# receives them from the other peers).
+# 0 - disabled, the txs are sent to all the peers but their senders.
+have_tx_filter_size = {{ .Mempool.HaveTxFilterSize }}
+
+# Time for which the txs received are collected to be checked by the app in
+# one call, if it's a local app implementing BatchApplication (the other apps
+# check them one by one). It delays the txs by up to this time.
+# 0 - disabled, the txs are checked as soon as received.
+check_tx_batch_window = "{{ .Mempool.CheckTxBatchWindow }}"
+
+# If true, the txs of the mempool and its cache are saved to data/mempool.txs
+# when the node stops gracefully, and re-checked by the app when it starts
+# again: the ones still valid are back in the mempool, instead of having to be
+# resubmitted by the clients.
+persist-to-disk = {{ .Mempool.PersistToDisk }}
+
 #######################################################
 ###         State Sync Configuration Options        ###
 #######################################################
@@ -484,8 +750,9 @@ trust_period = "{{ .StateSync.TrustPeriod }}"
 # Time to spend discovering snapshots before initiating a restore.
 discovery_time = "{{ .StateSync.DiscoveryTime }}"
 
-# Temporary directory for state sync snapshot chunks, defaults to the OS tempdir (typically /tmp).
-# Will create a new, randomly named directory within, and remove it when done.
+# Directory for the chunks of the snapshot being restored, defaults to db_dir/statesync.
+# They're kept until the snapshot is restored or rejected, so that a restarted node resumes the
+# restoration without fetching them again (the app still has to apply them again).
 temp_dir = "{{ .StateSync.TempDir }}"
 
 # The timeout duration before re-requesting a chunk, possibly from a different
@@ -495,6 +762,20 @@ chunk_request_timeout = "{{ .StateSync.ChunkRequestTimeout }}"
 # The number of concurrent chunk fetchers to run (default: 1).
 chunk_fetchers = "{{ .StateSync.ChunkFetchers }}"
 
+# Interval of the heights at which the node archives the latest snapshot taken by the app, in
+# db_dir/snapshots, and serves it to the peers along with the snapshots of the app (0 - disabled).
+# The app must take the snapshots, e.g. at the same interval; the node archives them with the
+# ABCI ListSnapshots and LoadSnapshotChunk, and reports the progress with the /snapshots RPC.
+snapshot_interval = {{ .StateSync.SnapshotInterval }}
+
+# Number of the most recent archived snapshots to keep (0 - keep all).
+snapshot_keep_recent = {{ .StateSync.SnapshotKeepRecent }}
+
+# Hash of the genesis document (as shown by "ostracon genesis hash"), obtained from a trusted source.
+# If set and the node has no genesis file, the genesis document is fetched in chunks from the
+# rpc_servers, whatever its size, written to the genesis file and checked against it.
+genesis_hash = "{{ .StateSync.GenesisHash }}"
+
 #######################################################
 ###       Fast Sync Configuration Connections       ###
 #######################################################
@@ -513,6 +794,16 @@ version = "{{ .FastSync.Version }}"
 
 wal_file = "{{ js .Consensus.WalPath }}"
 
+# Compress the entries of the WAL with zstd (the WAL of either kind is read).
+# The WAL compressed can't be read by the versions without it.
+wal_compression = {{ .Consensus.WalCompression }}
+
+# Number of entries of the WAL buffered for the routine writing them and syncing
+# the WAL, so that the consensus only waits for the WAL to be synced at the
+# commits and before signing.
+# 0 - the entries are written by the consensus.
+wal_write_buffer_size = {{ .Consensus.WalWriteBufferSize }}
+
 # How long we wait for a proposal block before prevoting nil
 timeout_propose = "{{ .Consensus.TimeoutPropose }}"
 # How much timeout_propose increases with each round
@@ -530,12 +821,51 @@ timeout_precommit_delta = "{{ .Consensus.TimeoutPrecommitDelta }}"
 # though we already have +2/3).
 timeout_commit = "{{ .Consensus.TimeoutCommit }}"
 
+# Named timeouts used instead of the seven timeouts above:
+#   1) "lan" - for the validators of a local network
+#   2) "wan" - for the validators of a network within a region (the default timeouts)
+#   3) "global" - for the validators of a network spanning the world
+# "" - the timeouts above.
+timeout_profile = "{{ .Consensus.TimeoutProfile }}"
+
+# When true, the timeouts of the propose, prevote and precommit steps adapt to the durations
+# these steps took in the last rounds committing a block, bounded by timeout_adaptive_min and
+# timeout_adaptive_max, instead of being fixed. The deltas still increase them with each round.
+timeout_adaptive = {{ .Consensus.TimeoutAdaptive }}
+timeout_adaptive_min = "{{ .Consensus.TimeoutAdaptiveMin }}"
+timeout_adaptive_max = "{{ .Consensus.TimeoutAdaptiveMax }}"
+
 # How many blocks to look back to check existence of the node's consensus votes before joining consensus
 # When non-zero, the node will panic upon restart
 # if the same consensus key was used to sign {double_sign_check_height} last blocks.
 # So, validators should stop the state machine, wait for some blocks, and then restart the state machine to avoid panic.
 double_sign_check_height = {{ .Consensus.DoubleSignCheckHeight }}
 
+# When true, the node follows the consensus as the validator of its key (priv_validator_key_file
+# or priv_validator_laddr) without ever signing nor proposing: the votes it would sign are compared
+# to the ones of the validator running elsewhere with the same key, and the divergences are reported
+# by the consensus_shadow_* metrics. This allows to soak-test new infrastructure before moving the
+# validator onto it; the double_sign_check_height check is skipped.
+shadow_mode = {{ .Consensus.ShadowMode }}
+
+# How long the application has to accept or reject a proposal with ProcessProposal before the
+# validator prevotes. 0 - no timeout.
+process_proposal_timeout = "{{ .Consensus.ProcessProposalTimeout }}"
+
+# When true, the proposal is prevoted if the application doesn't answer ProcessProposal within
+# process_proposal_timeout or fails to (fail-open), otherwise nil is prevoted (fail-closed).
+process_proposal_fail_open = {{ .Consensus.ProcessProposalFailOpen }}
+
+# When true, the block parts and votes of the current height are gossiped first to the peers which
+# are validators of the height, which need them to make progress, before the other full nodes: a
+# peer which isn't a validator is sent a block part or a vote once the validator peers following
+# the height have it.
+prioritize_validator_gossip = {{ .Consensus.PrioritizeValidatorGossip }}
+
+# The validators of the peers, as "node_id:validator_address". A peer whose node key is its
+# validator key is known to be the validator without being listed.
+validator_peers = [{{ range .Consensus.ValidatorPeers }}{{ printf "%q, " . }}{{end}}]
+
 # Make progress as soon as we have all the precommits (as if TimeoutCommit = 0)
 skip_timeout_commit = {{ .Consensus.SkipTimeoutCommit }}
 
@@ -561,6 +891,24 @@ peer_query_maj23_sleep_duration = "{{ .Consensus.PeerQueryMaj23SleepDuration }}"
 # reindex events in the command-line tool.
 discard_abci_responses = {{ .Storage.DiscardABCIResponses}}
 
+# Number of recent blocks to retain, pruning the older blocks, states and ABCI
+# responses in the background. 0 retains all the blocks, unless the app returns
+# a retain height in Commit, the lowest of the two retain heights being used
+# when both are set. The blocks of the evidence which hasn't expired (see the
+# evidence params) are always retained.
+retain_blocks = {{ .Storage.RetainBlocks }}
+
+# Interval the blocks are pruned at. The pruning may also be triggered with
+# the unsafe_prune RPC endpoint.
+pruning_interval = "{{ .Storage.PruningInterval }}"
+
+# Set true to retain the states of the pruned blocks: their validator sets,
+# consensus params and ABCI responses (unless discarded), for /validators,
+# /consensus_params and /block_results to be served at any height, e.g. for the
+# IBC relayers to get the historical validator sets of a pruning node. Only the
+# blocks are pruned then.
+retain_states = {{ .Storage.RetainStates }}
+
 #######################################################
 ###   Transaction Indexer Configuration Options     ###
 #######################################################
@@ -604,6 +952,83 @@ max_open_connections = {{ .Instrumentation.MaxOpenConnections }}
 
 # Instrumentation namespace
 namespace = "{{ .Instrumentation.Namespace }}"
+
+# Backend of the metrics: prometheus | statsd | otlp
+#   1) "prometheus" - the metrics are served to Prometheus if prometheus is true
+#   2) "statsd" - the metrics are pushed to the StatsD agent at statsd_address,
+#     with their labels as DogStatsD tags
+#   3) "otlp" - the metrics are pushed to the OpenTelemetry collector at
+#     metrics_otlp_endpoint
+metrics_backend = "{{ .Instrumentation.MetricsBackend }}"
+
+# Address (host:port) of the StatsD agent, over UDP
+statsd_address = "{{ .Instrumentation.StatsdAddress }}"
+
+# Address of the OpenTelemetry collector. Example: "localhost:4317"
+metrics_otlp_endpoint = "{{ .Instrumentation.MetricsOTLPEndpoint }}"
+
+# Protocol used to push the metrics with OTLP: grpc | http
+metrics_otlp_protocol = "{{ .Instrumentation.MetricsOTLPProtocol }}"
+
+# If true, the metrics are pushed with OTLP without TLS
+metrics_otlp_insecure = {{ .Instrumentation.MetricsOTLPInsecure }}
+
+# Interval between the pushes of the StatsD and OTLP metrics
+metrics_push_interval = "{{ .Instrumentation.MetricsPushInterval }}"
+
+# Address to listen for the liveness (/livez) and readiness (/readyz) probes,
+# e.g. of Kubernetes. The node is live while it is running, and ready when it is
+# caught up, participates in consensus, the application is connected and the
+# signer is reachable. The probes are disabled if empty. Example: ":26661"
+# They are also served by the RPC server on /health/live and /health/ready,
+# outside JSON-RPC.
+probes_listen_addr = "{{ .Instrumentation.ProbesListenAddr }}"
+
+# Maximum time the application and the signer have to answer the readiness probe
+probe_timeout = "{{ .Instrumentation.ProbeTimeout }}"
+
+# Maximum age of the latest block for the node to be ready (not checked if 0)
+readiness_max_block_age = "{{ .Instrumentation.ReadinessMaxBlockAge }}"
+
+# Maximum number of blocks the node can be behind its most advanced peer for the
+# node to be ready (not checked if 0)
+readiness_max_blocks_behind = {{ .Instrumentation.ReadinessMaxBlocksBehind }}
+
+# Address of the OpenTelemetry collector to which the traces of the transactions
+# (from the RPC request submitting them to their execution in a block) are
+# exported with OTLP. The tracing is disabled if empty. Example: "localhost:4317"
+tracing_otlp_endpoint = "{{ .Instrumentation.TracingOTLPEndpoint }}"
+
+# Protocol used to export the traces: grpc | http
+tracing_otlp_protocol = "{{ .Instrumentation.TracingOTLPProtocol }}"
+
+# If true, the traces are exported without TLS
+tracing_otlp_insecure = {{ .Instrumentation.TracingOTLPInsecure }}
+
+# Fraction of the transactions submitted to the RPC server which are traced,
+# between 0 and 1. The transactions of the requests carrying a sampled trace
+# context (W3C traceparent header) are always traced.
+tracing_sample_rate = {{ .Instrumentation.TracingSampleRate }}
+
+# If true, the messages of the peers are traced too, sampled at
+# tracing_sample_rate: their sends and receipts by the switch and the
+# connections, and their handling by the consensus reactor. The trace of a
+# message is derived from its hash, so that its spans on the node sending it and
+# on the ones receiving it are in the same trace. Requires tracing_otlp_endpoint.
+tracing_enabled = {{ .Instrumentation.TracingEnabled }}
+
+# The watchdog captures the CPU, heap and goroutine profiles of the node when a
+# consensus round lasts more than watchdog_round_timeout, or when no block is
+# committed for watchdog_commit_timeout while the node isn't syncing, for
+# post-mortem analysis. Both are disabled if 0.
+watchdog_round_timeout = "{{ .Instrumentation.WatchdogRoundTimeout }}"
+watchdog_commit_timeout = "{{ .Instrumentation.WatchdogCommitTimeout }}"
+
+# Directory where the profiles are written, in a subdirectory per capture
+watchdog_profiles_dir = "{{ js .Instrumentation.WatchdogProfilesDir }}"
+
+# Maximum number of captures kept, the oldest ones are removed first
+watchdog_max_captures = {{ .Instrumentation.WatchdogMaxCaptures }}
 `
 
 /****** these are for test settings ***********/
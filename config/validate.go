@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Severities of the issues found in a configuration.
+const (
+	// SeverityError is the severity of the issues preventing the node to run.
+	SeverityError = "error"
+	// SeverityWarning is the severity of the issues which are likely mistakes,
+	// e.g. unknown or deprecated keys.
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in a configuration by CheckConfig or CheckKeys.
+type Issue struct {
+	// Key of the setting (e.g. "mempool.size") or section (e.g. "rpc") at
+	// fault, empty if unknown.
+	Key      string `json:"key,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Replacement of a deprecated key, if any.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+func (i Issue) String() string {
+	s := i.Severity + ": "
+	if i.Key != "" {
+		s += i.Key + ": "
+	}
+	s += i.Message
+	if i.Replacement != "" {
+		s += fmt.Sprintf(" (use %s instead)", i.Replacement)
+	}
+	return s
+}
+
+// deprecatedKey is a key which is still accepted but shouldn't be used anymore.
+type deprecatedKey struct {
+	replacement string
+	reason      string
+}
+
+// deprecatedKeys are the deprecated keys, which are no longer known keys of the
+// configuration.
+var deprecatedKeys = map[string]deprecatedKey{
+	"prof_laddr": {
+		replacement: "rpc.pprof_laddr",
+		reason:      "the pprof listen address was moved to the [rpc] section",
+	},
+	"p2p.max_num_peers": {
+		replacement: "p2p.max_num_inbound_peers and p2p.max_num_outbound_peers",
+		reason:      "the maximum number of peers was split into inbound and outbound peers",
+	},
+}
+
+// CheckKeys checks the keys set in a configuration file (as returned by
+// viper's AllKeys), reporting the deprecated keys and the unknown ones, which
+// are ignored.
+func CheckKeys(keys []string) []Issue {
+	known := make(map[string]struct{})
+	for _, key := range Keys() {
+		known[key] = struct{}{}
+	}
+
+	var issues []Issue
+	for _, key := range keys {
+		if deprecated, ok := deprecatedKeys[key]; ok {
+			issues = append(issues, Issue{
+				Key:         key,
+				Severity:    SeverityWarning,
+				Message:     "deprecated: " + deprecated.reason,
+				Replacement: deprecated.replacement,
+			})
+			continue
+		}
+		if _, ok := known[key]; !ok {
+			issues = append(issues, Issue{
+				Key:      key,
+				Severity: SeverityWarning,
+				Message:  "unknown key, it is ignored",
+			})
+		}
+	}
+	return issues
+}
+
+// Keys returns the sorted keys of all the settings of the configuration, e.g.
+// "log_level" or "mempool.size".
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			// the base config is squashed into the top level
+			keys = append(keys, sectionKeys(field.Type, "")...)
+			continue
+		}
+		keys = append(keys, sectionKeys(field.Type.Elem(), mapstructureName(field)+".")...)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sectionKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() {
+			keys = append(keys, prefix+mapstructureName(field))
+		}
+	}
+	return keys
+}
+
+func mapstructureName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+// CheckConfig checks the values of the configuration. Unlike ValidateBasic, it
+// reports all the invalid sections instead of the first one only, and checks
+// the constraints between settings.
+func CheckConfig(cfg *Config) []Issue {
+	var issues []Issue
+	check := func(section string, err error) {
+		if err != nil {
+			issues = append(issues, Issue{Key: section, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	check("", cfg.BaseConfig.ValidateBasic())
+	check("rpc", cfg.RPC.ValidateBasic())
+	check("p2p", cfg.P2P.ValidateBasic())
+	check("mempool", cfg.Mempool.ValidateBasic())
+	check("statesync", cfg.StateSync.ValidateBasic())
+	check("fastsync", cfg.FastSync.ValidateBasic())
+	check("consensus", cfg.Consensus.ValidateBasic())
+	check("instrumentation", cfg.Instrumentation.ValidateBasic())
+
+	if cfg.FastSync.Version == "v1" || cfg.FastSync.Version == "v2" {
+		issues = append(issues, Issue{
+			Key:         "fastsync.version",
+			Severity:    SeverityWarning,
+			Message:     fmt.Sprintf("deprecated: fast sync %s will be removed", cfg.FastSync.Version),
+			Replacement: "v0",
+		})
+	}
+
+	if cfg.Mempool.MaxTxsBytes > 0 && int64(cfg.Mempool.MaxTxBytes) > cfg.Mempool.MaxTxsBytes {
+		issues = append(issues, Issue{
+			Key:      "mempool.max_tx_bytes",
+			Severity: SeverityError,
+			Message: fmt.Sprintf("the maximum size of a transaction (%d) is greater than the maximum size "+
+				"of all the transactions of the mempool (mempool.max_txs_bytes = %d)",
+				cfg.Mempool.MaxTxBytes, cfg.Mempool.MaxTxsBytes),
+		})
+	}
+	if cfg.RPC.TimeoutBroadcastTxCommit > cfg.RPC.WriteTimeout {
+		issues = append(issues, Issue{
+			Key:      "rpc.timeout_broadcast_tx_commit",
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("greater than rpc.write_timeout (%v), the write timeout of all the "+
+				"RPC connections is raised to %v", cfg.RPC.WriteTimeout, cfg.RPC.TimeoutBroadcastTxCommit+time.Second),
+		})
+	}
+	if cfg.Consensus.PeerGossipSleepDuration >= cfg.Consensus.TimeoutPropose {
+		issues = append(issues, Issue{
+			Key:      "consensus.peer_gossip_sleep_duration",
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("not lower than consensus.timeout_propose (%v), the proposals can't be "+
+				"gossiped in time", cfg.Consensus.TimeoutPropose),
+		})
+	}
+	for _, step := range []struct {
+		name           string
+		timeout, delta time.Duration
+	}{
+		{"propose", cfg.Consensus.TimeoutPropose, cfg.Consensus.TimeoutProposeDelta},
+		{"prevote", cfg.Consensus.TimeoutPrevote, cfg.Consensus.TimeoutPrevoteDelta},
+		{"precommit", cfg.Consensus.TimeoutPrecommit, cfg.Consensus.TimeoutPrecommitDelta},
+	} {
+		if step.timeout+step.delta == 0 {
+			issues = append(issues, Issue{
+				Key:      "consensus.timeout_" + step.name,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("the timeout and delta of the %s step can't both be 0", step.name),
+			})
+		}
+	}
+	if addr := cfg.Instrumentation.ProbesListenAddr; addr != "" && cfg.Instrumentation.Prometheus &&
+		addr == cfg.Instrumentation.PrometheusListenAddr {
+		issues = append(issues, Issue{
+			Key:      "instrumentation.probes_listen_addr",
+			Severity: SeverityError,
+			Message:  "the same address as instrumentation.prometheus_listen_addr",
+		})
+	}
+	return issues
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckKeys(t *testing.T) {
+	// the keys of the default configuration are known
+	assert.Empty(t, CheckKeys(Keys()))
+	assert.Contains(t, Keys(), "log_level")
+	assert.Contains(t, Keys(), "mempool.size")
+	assert.Contains(t, Keys(), "instrumentation.probes_listen_addr")
+
+	issues := CheckKeys([]string{"mempool.size", "mempool.sizee", "prof_laddr"})
+	require.Len(t, issues, 2)
+	assert.Equal(t, Issue{
+		Key:      "mempool.sizee",
+		Severity: SeverityWarning,
+		Message:  "unknown key, it is ignored",
+	}, issues[0])
+	assert.Equal(t, "prof_laddr", issues[1].Key)
+	assert.Equal(t, SeverityWarning, issues[1].Severity)
+	assert.Equal(t, "rpc.pprof_laddr", issues[1].Replacement)
+}
+
+func TestCheckConfig(t *testing.T) {
+	assert.Empty(t, CheckConfig(DefaultConfig()))
+	assert.Empty(t, CheckConfig(TestConfig()))
+
+	cfg := DefaultConfig()
+	cfg.LogFormat = "xml"
+	cfg.Mempool.Size = -1
+	cfg.Mempool.MaxTxBytes = int(cfg.Mempool.MaxTxsBytes) + 1
+	cfg.RPC.TimeoutBroadcastTxCommit = cfg.RPC.WriteTimeout + time.Second
+	issues := CheckConfig(cfg)
+
+	keys := make(map[string]string)
+	for _, issue := range issues {
+		keys[issue.Key] = issue.Severity
+	}
+	// all the invalid sections are reported
+	assert.Equal(t, map[string]string{
+		"":                                SeverityError,
+		"mempool":                         SeverityError,
+		"mempool.max_tx_bytes":            SeverityError,
+		"rpc.timeout_broadcast_tx_commit": SeverityWarning,
+	}, keys)
+
+	cfg = DefaultConfig()
+	cfg.Consensus.TimeoutPrevote = 0
+	cfg.Consensus.TimeoutPrevoteDelta = 0
+	issues = CheckConfig(cfg)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "consensus.timeout_prevote", issues[0].Key)
+	assert.Equal(t, "the timeout and delta of the prevote step can't both be 0", issues[0].Message)
+}
@@ -26,8 +26,7 @@ import (
 
 	cfg "github.com/Finschia/ostracon/config"
 	mempoolv0 "github.com/Finschia/ostracon/mempool/v0"
-
-	//mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
+	mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
 	"github.com/Finschia/ostracon/p2p"
 	sm "github.com/Finschia/ostracon/state"
 	"github.com/Finschia/ostracon/store"
@@ -81,17 +80,13 @@ func TestByzantinePrevoteEquivocation(t *testing.T) {
 				state.LastBlockHeight,
 				mempoolv0.WithPreCheck(sm.TxPreCheck(state)),
 				mempoolv0.WithPostCheck(sm.TxPostCheck(state)))
-		case cfg.MempoolV1: // XXX Deprecated
-			panic("Deprecated MempoolV1")
-			/*
-				mempool = mempoolv1.NewTxMempool(logger,
-					config.Mempool,
-					proxyAppConnConMem,
-					state.LastBlockHeight,
-					mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-					mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-				)
-			*/
+		case cfg.MempoolV1:
+			mempool = mempoolv1.NewTxMempool(config.Mempool,
+				proxyAppConnConMem,
+				state.LastBlockHeight,
+				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+			)
 		}
 
 		if thisConfig.Consensus.WaitForTxs() {
@@ -233,9 +228,13 @@ func TestByzantinePrevoteEquivocation(t *testing.T) {
 
 		message := lazyProposer.state.MakeHashMessage(lazyProposer.Round)
 		proof, _ := lazyProposer.privValidator.GenerateVRFProof(message)
-		block, blockParts := lazyProposer.blockExec.CreateProposalBlock(
-			lazyProposer.Height, lazyProposer.state, commit, proposerAddr, lazyProposer.Round, proof, 0,
+		block, blockParts, err := lazyProposer.blockExec.CreateProposalBlock(
+			lazyProposer.Height, lazyProposer.state, commit, proposerAddr, lazyProposer.Round, proof, 0, nil,
 		)
+		if err != nil {
+			lazyProposer.Logger.Error("enterPropose: Cannot create the proposal block", "err", err)
+			return
+		}
 
 		// Flush the WAL. Otherwise, we may not recompute the same proposal to sign,
 		// and the privValidator will refuse to sign anything.
@@ -629,6 +628,10 @@ func (br *ByzantineReactor) RecvRoutine() {
 	br.reactor.RecvRoutine()
 }
 
-func (br *ByzantineReactor) GetRecvChan() chan *p2p.BufferedMsg {
-	return br.reactor.GetRecvChan()
+func (br *ByzantineReactor) QueueMsg(msg *p2p.BufferedMsg) bool {
+	return br.reactor.QueueMsg(msg)
+}
+
+func (br *ByzantineReactor) RecvQueueSize(chID byte) int {
+	return br.reactor.RecvQueueSize(chID)
 }
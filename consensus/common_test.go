@@ -33,8 +33,7 @@ import (
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	mempl "github.com/Finschia/ostracon/mempool"
 	mempoolv0 "github.com/Finschia/ostracon/mempool/v0"
-
-	//mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
+	mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/privval"
 	sm "github.com/Finschia/ostracon/state"
@@ -45,6 +44,8 @@ import (
 
 const (
 	testSubscriber = "test-client"
+	// capacity of the channels of the test subscriptions
+	testSubscriptionCapacity = 100
 )
 
 // A cleanupFunc cleans up any config / test files created for a particular
@@ -244,7 +245,12 @@ func createProposalBlockSlim(cs *State, vs *validatorStub, round int32) (*types.
 		cs.Logger.Error("enterPropose: Cannot generate vrf proof: %s", err.Error())
 		return nil, nil
 	}
-	return cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr, round, proof, 0)
+	block, blockParts, err := cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr, round, proof, 0, nil)
+	if err != nil {
+		cs.Logger.Error("createProposalBlockSlim: Cannot create the proposal block", "err", err)
+		return nil, nil
+	}
+	return block, blockParts
 }
 
 func addVotes(to *State, votes ...*types.Vote) {
@@ -441,19 +447,15 @@ func newStateWithConfigAndBlockStoreWithLoggers(
 			mempoolv0.WithPreCheck(sm.TxPreCheck(state)),
 			mempoolv0.WithPostCheck(sm.TxPostCheck(state)))
 		mempool.(*mempoolv0.CListMempool).SetLogger(loggers.memLogger.With("module", "mempool"))
-	case cfg.MempoolV1: // XXX Deprecated
-		panic("Deprecated MempoolV1")
-		/*
-			logger := consensusLogger()
-			mempool = mempoolv1.NewTxMempool(logger,
-				config.Mempool,
-				proxyAppConnConMem,
-				state.LastBlockHeight,
-				mempoolv1.WithMetrics(memplMetrics),
-				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-			)
-		*/
+	case cfg.MempoolV1:
+		mempool = mempoolv1.NewTxMempool(config.Mempool,
+			proxyAppConnConMem,
+			state.LastBlockHeight,
+			mempoolv1.WithMetrics(memplMetrics),
+			mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+			mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+		)
+		mempool.(*mempoolv1.TxMempool).SetLogger(loggers.memLogger.With("module", "mempool"))
 	}
 	if thisConfig.Consensus.WaitForTxs() {
 		mempool.EnableTxsAvailable()
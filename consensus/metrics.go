@@ -4,8 +4,8 @@ import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
 
-	"github.com/go-kit/kit/metrics/prometheus"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/Finschia/ostracon/libs/async"
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 )
 
 const (
@@ -84,6 +84,11 @@ type Metrics struct {
 	// Number of blocks that are we couldn't receive
 	MissingProposal metrics.Gauge
 
+	// Number of proposal blocks rejected by the application with ProcessProposal.
+	RejectedProposals metrics.Counter
+	// Number of ProcessProposal calls which timed out or failed.
+	ProcessProposalFailures metrics.Counter
+
 	// Number of rounds turned over.
 	RoundFailures metrics.Histogram
 
@@ -103,250 +108,330 @@ type Metrics struct {
 	DurationGaugeCommitCommitting   metrics.Gauge
 	DurationGaugeCommitRechecking   metrics.Gauge
 	DurationGaugeWaitingForNewRound metrics.Gauge
+
+	// ////////////////////////////////////
+	// Metrics of the shadow mode
+	// ////////////////////////////////////
+
+	// Number of votes the node would have signed, by type.
+	ShadowVotes metrics.Counter
+	// Number of votes the node would have signed which match the ones of the
+	// validator, by type.
+	ShadowVoteMatches metrics.Counter
+	// Number of votes the node would have signed which differ from the ones
+	// of the validator, by type.
+	ShadowVoteDivergences metrics.Counter
+	// Number of votes the node would have signed without receiving the ones
+	// of the validator, by type.
+	ShadowValidatorMissedVotes metrics.Counter
+	// Number of votes of the validator the node wouldn't have signed, by type.
+	ShadowMissedVotes metrics.Counter
+	// Number of proposals the node would have made.
+	ShadowProposals metrics.Counter
+
+	// Metrics of the pool verifying the votes received from peers.
+	VoteVerifyPool *async.Metrics
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		Height: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Height: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "height",
 			Help:      "Height of the chain.",
 		}, labels).With(labelsAndValues...),
-		Rounds: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Rounds: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "rounds",
 			Help:      "Number of rounds.",
 		}, labels).With(labelsAndValues...),
 
-		Validators: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Validators: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "validators",
 			Help:      "Number of validators.",
 		}, labels).With(labelsAndValues...),
-		ValidatorLastSignedHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ValidatorLastSignedHeight: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "validator_last_signed_height",
 			Help:      "Last signed height for a validator",
 		}, append(labels, "validator_address")).With(labelsAndValues...),
-		ValidatorMissedBlocks: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ValidatorMissedBlocks: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "validator_missed_blocks",
 			Help:      "Total missed blocks for a validator",
 		}, append(labels, "validator_address")).With(labelsAndValues...),
-		ValidatorsPower: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ValidatorsPower: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "validators_power",
 			Help:      "Total power of all validators.",
 		}, labels).With(labelsAndValues...),
-		ValidatorPower: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ValidatorPower: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "validator_power",
 			Help:      "Power of a validator",
 		}, append(labels, "validator_address")).With(labelsAndValues...),
-		MissingValidators: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		MissingValidators: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "missing_validators",
 			Help:      "Number of validators who did not sign.",
 		}, labels).With(labelsAndValues...),
-		MissingValidatorsPower: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		MissingValidatorsPower: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "missing_validators_power",
 			Help:      "Total power of the missing validators.",
 		}, labels).With(labelsAndValues...),
-		ByzantineValidators: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ByzantineValidators: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "byzantine_validators",
 			Help:      "Number of validators who tried to double sign.",
 		}, labels).With(labelsAndValues...),
-		ByzantineValidatorsPower: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		ByzantineValidatorsPower: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "byzantine_validators_power",
 			Help:      "Total power of the byzantine validators.",
 		}, labels).With(labelsAndValues...),
-		BlockIntervalSeconds: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockIntervalSeconds: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_interval_seconds",
 			Help:      "Time between this and the last block.",
 		}, labels).With(labelsAndValues...),
-		NumTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		NumTxs: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "num_txs",
 			Help:      "Number of transactions.",
 		}, labels).With(labelsAndValues...),
-		BlockSizeBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockSizeBytes: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_size_bytes",
 			Help:      "Size of the block.",
 		}, labels).With(labelsAndValues...),
-		TotalTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		TotalTxs: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "total_txs",
 			Help:      "Total number of transactions.",
 		}, labels).With(labelsAndValues...),
-		CommittedHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		CommittedHeight: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "latest_block_height",
 			Help:      "The latest block height.",
 		}, labels).With(labelsAndValues...),
-		FastSyncing: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		FastSyncing: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "fast_syncing",
 			Help:      "Whether or not a node is fast syncing. 1 if yes, 0 if no.",
 		}, labels).With(labelsAndValues...),
-		StateSyncing: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		StateSyncing: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "state_syncing",
 			Help:      "Whether or not a node is state syncing. 1 if yes, 0 if no.",
 		}, labels).With(labelsAndValues...),
-		BlockParts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		BlockParts: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_parts",
 			Help:      "Number of blockparts transmitted by peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
-		QuorumPrevoteMessageDelay: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		QuorumPrevoteMessageDelay: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "quorum_prevote_message_delay",
 			Help: "Difference in seconds between the proposal timestamp and the timestamp " +
 				"of the latest prevote that achieved a quorum in the prevote step.",
 		}, labels).With(labelsAndValues...),
-		FullPrevoteMessageDelay: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		FullPrevoteMessageDelay: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "full_prevote_message_delay",
 			Help: "Difference in seconds between the proposal timestamp and the timestamp " +
 				"of the latest prevote that achieved 100% of the voting power in the prevote step.",
 		}, labels).With(labelsAndValues...),
-		MissingProposal: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		MissingProposal: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "missing_proposal",
 			Help:      "Number of blocks we couldn't receive",
 		}, labels).With(labelsAndValues...),
-		RoundFailures: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		RejectedProposals: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_proposals",
+			Help:      "Number of proposal blocks rejected by the application",
+		}, labels).With(labelsAndValues...),
+		ProcessProposalFailures: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "process_proposal_failures",
+			Help:      "Number of ProcessProposal calls which timed out or failed",
+		}, labels).With(labelsAndValues...),
+		RoundFailures: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "round_failures",
 			Help:      "Number of rounds failed on consensus",
-			Buckets:   stdprometheus.LinearBuckets(0, 1, 5),
+			Buckets:   tmmetrics.LinearBuckets(0, 1, 5),
 		}, labels).With(labelsAndValues...),
-		DurationProposal: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationProposal: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_proposal",
 			Help:      "Duration of proposal step",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationPrevote: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationPrevote: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_prevote",
 			Help:      "Duration of prevote step",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationPrecommit: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationPrecommit: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_precommit",
 			Help:      "Duration of precommit step",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationCommitExecuting: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationCommitExecuting: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_commit_executing",
 			Help:      "Duration of executing block txs",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationCommitCommitting: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationCommitCommitting: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_commit_committing",
 			Help:      "Duration of committing updated state",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationCommitRechecking: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationCommitRechecking: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_commit_rechecking",
 			Help:      "Duration of rechecking mempool txs",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationWaitingForNewRound: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		DurationWaitingForNewRound: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_waiting_for_new_round",
 			Help:      "Duration of waiting for next new round",
-			Buckets:   stdprometheus.LinearBuckets(100, 100, 10),
+			Buckets:   tmmetrics.LinearBuckets(100, 100, 10),
 		}, labels).With(labelsAndValues...),
-		DurationGaugeProposal: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugeProposal: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_proposal",
 			Help:      "Duration of proposal step",
 		}, labels).With(labelsAndValues...),
-		DurationGaugePrevote: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugePrevote: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_prevote",
 			Help:      "Duration of prevote step",
 		}, labels).With(labelsAndValues...),
-		DurationGaugePrecommit: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugePrecommit: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_precommit",
 			Help:      "Duration of precommit step",
 		}, labels).With(labelsAndValues...),
-		DurationGaugeCommitExecuting: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugeCommitExecuting: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_commit_executing",
 			Help:      "Duration of executing block txs",
 		}, labels).With(labelsAndValues...),
-		DurationGaugeCommitCommitting: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugeCommitCommitting: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_commit_committing",
 			Help:      "Duration of committing updated state",
 		}, labels).With(labelsAndValues...),
-		DurationGaugeCommitRechecking: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugeCommitRechecking: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_commit_rechecking",
 			Help:      "Duration of rechecking mempool txs",
 		}, labels).With(labelsAndValues...),
-		DurationGaugeWaitingForNewRound: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		DurationGaugeWaitingForNewRound: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "duration_gauge_waiting_for_new_round",
 			Help:      "Duration of waiting for next new round",
 		}, labels).With(labelsAndValues...),
+		ShadowVotes: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_votes",
+			Help:      "Number of votes the node would have signed in shadow mode.",
+		}, append(labels, "type")).With(labelsAndValues...),
+		ShadowVoteMatches: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_vote_matches",
+			Help:      "Number of votes the node would have signed in shadow mode which match the ones of the validator.",
+		}, append(labels, "type")).With(labelsAndValues...),
+		ShadowVoteDivergences: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_vote_divergences",
+			Help:      "Number of votes the node would have signed in shadow mode which differ from the ones of the validator.",
+		}, append(labels, "type")).With(labelsAndValues...),
+		ShadowValidatorMissedVotes: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_validator_missed_votes",
+			Help:      "Number of votes the node would have signed in shadow mode without receiving the ones of the validator.",
+		}, append(labels, "type")).With(labelsAndValues...),
+		ShadowMissedVotes: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_missed_votes",
+			Help:      "Number of votes of the validator the node wouldn't have signed in shadow mode.",
+		}, append(labels, "type")).With(labelsAndValues...),
+		ShadowProposals: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shadow_proposals",
+			Help:      "Number of proposals the node would have made in shadow mode.",
+		}, labels).With(labelsAndValues...),
+
+		VoteVerifyPool: async.NewMetrics(provider, namespace, MetricsSubsystem+"_vote_verify", labelsAndValues...),
 	}
 }
 
@@ -380,8 +465,10 @@ func NopMetrics() *Metrics {
 		QuorumPrevoteMessageDelay: discard.NewGauge(),
 		FullPrevoteMessageDelay:   discard.NewGauge(),
 
-		MissingProposal: discard.NewGauge(),
-		RoundFailures:   discard.NewHistogram(),
+		MissingProposal:         discard.NewGauge(),
+		RejectedProposals:       discard.NewCounter(),
+		ProcessProposalFailures: discard.NewCounter(),
+		RoundFailures:           discard.NewHistogram(),
 
 		DurationProposal:           discard.NewHistogram(),
 		DurationPrevote:            discard.NewHistogram(),
@@ -398,5 +485,14 @@ func NopMetrics() *Metrics {
 		DurationGaugeCommitCommitting:   discard.NewGauge(),
 		DurationGaugeCommitRechecking:   discard.NewGauge(),
 		DurationGaugeWaitingForNewRound: discard.NewGauge(),
+
+		ShadowVotes:                discard.NewCounter(),
+		ShadowVoteMatches:          discard.NewCounter(),
+		ShadowVoteDivergences:      discard.NewCounter(),
+		ShadowValidatorMissedVotes: discard.NewCounter(),
+		ShadowMissedVotes:          discard.NewCounter(),
+		ShadowProposals:            discard.NewCounter(),
+
+		VoteVerifyPool: async.NopMetrics(),
 	}
 }
@@ -1,23 +1,31 @@
 package consensus
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
 	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 	tmcons "github.com/tendermint/tendermint/proto/tendermint/consensus"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	cstypes "github.com/Finschia/ostracon/consensus/types"
+	tmasync "github.com/Finschia/ostracon/libs/async"
 	"github.com/Finschia/ostracon/libs/bits"
 	tmevents "github.com/Finschia/ostracon/libs/events"
 	tmjson "github.com/Finschia/ostracon/libs/json"
 	"github.com/Finschia/ostracon/libs/log"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tracing"
 	"github.com/Finschia/ostracon/p2p"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	sm "github.com/Finschia/ostracon/state"
 	"github.com/Finschia/ostracon/types"
 	tmtime "github.com/Finschia/ostracon/types/time"
@@ -28,8 +36,13 @@ const (
 	DataChannel        = byte(0x21)
 	VoteChannel        = byte(0x22)
 	VoteSetBitsChannel = byte(0x23)
+	// VoteExtensionChannel gossips the vote extensions, ocproto.VoteExtension
+	// messages, not consensus messages.
+	VoteExtensionChannel = byte(0x24)
 
-	maxMsgSize = 1048576 // 1MB; NOTE/TODO: keep in sync with types.PartSet sizes.
+	maxMsgSize = 2097152 // 2MB; NOTE: keep in sync with types.MaxBlockPartSizeBytes.
+	// the extension and the other fields of a vote extension
+	maxVoteExtensionMsgSize = types.MaxVoteExtensionSize + 1024
 
 	blocksToContributeToBecomeGoodPeer = 10000
 	votesToContributeToBecomeGoodPeer  = 10000
@@ -48,6 +61,15 @@ type Reactor struct {
 	eventBus *types.EventBus
 	rs       *cstypes.RoundState
 
+	// verifies the signatures of the votes received from peers
+	voteVerifyPool *tmasync.Pool
+	// the votes received, verified in a batch by the next task of the pool
+	voteBatchMtx tmsync.Mutex
+	voteBatch    []msgInfo
+
+	// the validator addresses of the peers, from ValidatorPeers
+	validatorPeers map[p2p.ID][]byte
+
 	Metrics *Metrics
 }
 
@@ -64,9 +86,18 @@ func NewReactor(consensusState *State, waitSync bool, async bool, recvBufSize in
 	}
 	conR.BaseReactor = *p2p.NewBaseReactor("Consensus", conR, async, recvBufSize)
 
+	// ValidatorPeers was checked by ConsensusConfig.ValidateBasic
+	addrs, _ := consensusState.config.ValidatorPeerAddresses()
+	conR.validatorPeers = make(map[p2p.ID][]byte, len(addrs))
+	for id, addr := range addrs {
+		conR.validatorPeers[p2p.ID(id)] = addr
+	}
+
 	for _, option := range options {
 		option(conR)
 	}
+	conR.voteVerifyPool = tmasync.NewPool("VoteVerify", runtime.NumCPU(), msgQueueSize,
+		tmasync.PoolMetrics(conR.Metrics.VoteVerifyPool))
 
 	return conR
 }
@@ -82,6 +113,10 @@ func (conR *Reactor) OnStart() error {
 		return err
 	}
 
+	if err := conR.voteVerifyPool.Start(); err != nil {
+		return err
+	}
+
 	// start routine that computes peer statistics for evaluating peer quality
 	go conR.peerStatsRoutine()
 
@@ -102,6 +137,9 @@ func (conR *Reactor) OnStart() error {
 // state.
 func (conR *Reactor) OnStop() {
 	conR.unsubscribeFromBroadcastEvents()
+	if err := conR.voteVerifyPool.Stop(); err != nil {
+		conR.Logger.Error("Error stopping the vote verification", "err", err)
+	}
 	if err := conR.conS.Stop(); err != nil {
 		conR.Logger.Error("Error stopping consensus state", "err", err)
 	}
@@ -110,6 +148,57 @@ func (conR *Reactor) OnStop() {
 	}
 }
 
+// SetLogger implements Service.
+func (conR *Reactor) SetLogger(l log.Logger) {
+	conR.Logger = l
+	conR.voteVerifyPool.SetLogger(l)
+}
+
+// batchVoteVerification adds the vote received from a peer to the batch
+// verified by the next task of the vote-verify pool, submitting the task if
+// none is pending, and reports whether it was; it isn't if the batch or the
+// pool is full. So the votes received while the workers are busy are verified
+// in a batch.
+func (conR *Reactor) batchVoteVerification(mi msgInfo) bool {
+	conR.voteBatchMtx.Lock()
+	defer conR.voteBatchMtx.Unlock()
+	switch {
+	case len(conR.voteBatch) >= msgQueueSize:
+		return false
+	case len(conR.voteBatch) == 0:
+		if !conR.voteVerifyPool.TrySubmit(conR.verifyVoteBatch) {
+			return false
+		}
+	}
+	conR.voteBatch = append(conR.voteBatch, mi)
+	return true
+}
+
+// verifyVoteBatch verifies the signatures of the votes of the batch at once,
+// and queues them to the consensus state.
+func (conR *Reactor) verifyVoteBatch(ctx context.Context) {
+	conR.voteBatchMtx.Lock()
+	mis := conR.voteBatch
+	conR.voteBatch = nil
+	conR.voteBatchMtx.Unlock()
+
+	votes := make([]*types.Vote, len(mis))
+	for i, mi := range mis {
+		votes[i] = mi.Msg.(*VoteMessage).Vote
+	}
+	conR.conS.verifyVotes(votes)
+	for i, mi := range mis {
+		select {
+		case conR.conS.peerMsgQueue <- mi:
+		case <-ctx.Done():
+			for _, vote := range votes[i:] {
+				conR.conS.verifiedVotes.Delete(vote)
+			}
+			return
+		}
+	}
+}
+
 // SwitchToConsensus switches from fast_sync mode to consensus mode.
 // It resets the state, turns off fast_sync, and starts the consensus state-machine
 func (conR *Reactor) SwitchToConsensus(state sm.State, skipWAL bool) {
@@ -164,6 +253,7 @@ func (conR *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvBufferCapacity:  50 * 4096,
 			RecvMessageCapacity: maxMsgSize,
 			MessageType:         &tmcons.Message{},
+			Compress:            true,
 		},
 		{
 			ID:                  VoteChannel,
@@ -181,6 +271,14 @@ func (conR *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvMessageCapacity: maxMsgSize,
 			MessageType:         &tmcons.Message{},
 		},
+		{
+			ID:                  VoteExtensionChannel,
+			Priority:            5,
+			SendQueueCapacity:   100,
+			RecvBufferCapacity:  100 * 100,
+			RecvMessageCapacity: maxVoteExtensionMsgSize,
+			MessageType:         &ocproto.VoteExtension{},
+		},
 	}
 }
 
@@ -206,6 +304,7 @@ func (conR *Reactor) AddPeer(peer p2p.Peer) {
 	go conR.gossipDataRoutine(peer, peerState)
 	go conR.gossipVotesRoutine(peer, peerState)
 	go conR.queryMaj23Routine(peer, peerState)
+	go conR.gossipVoteExtensionsRoutine(peer, peerState)
 
 	// Send our state to peer.
 	// If we're fast_syncing, broadcast a RoundStepMessage later upon SwitchToConsensus().
@@ -227,6 +326,50 @@ func (conR *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
 	// ps.Disconnect()
 }
 
+// msgSpanAttributes returns the attributes of the span handling the message:
+// its type, and the height and the round of the proposals, the block parts and
+// the votes, to follow their propagation.
+func msgSpanAttributes(msg Message) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("message_type", fmt.Sprintf("%T", msg))}
+	switch msg := msg.(type) {
+	case *ProposalMessage:
+		attrs = append(attrs, attribute.Int64("height", msg.Proposal.Height), attribute.Int("round", int(msg.Proposal.Round)))
+	case *BlockPartMessage:
+		attrs = append(attrs, attribute.Int64("height", msg.Height), attribute.Int("round", int(msg.Round)),
+			attribute.Int("part", int(msg.Part.Index)))
+	case *VoteMessage:
+		attrs = append(attrs, attribute.Int64("height", msg.Vote.Height), attribute.Int("round", int(msg.Vote.Round)),
+			attribute.String("vote_type", msg.Vote.Type.String()))
+	}
+	return attrs
+}
+
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the messages failing to be decoded or their ValidateBasic before
+// they are queued, e.g. the block parts over the max part size.
+func (conR *Reactor) ValidateMessage(chID byte, msg proto.Message) error {
+	if chID == VoteExtensionChannel {
+		pb, ok := msg.(*ocproto.VoteExtension)
+		if !ok {
+			return fmt.Errorf("unknown message type %T", msg)
+		}
+		_, err := types.VoteExtensionFromProto(pb)
+		return err
+	}
+	if wm, ok := msg.(p2p.Wrapper); ok {
+		msg = wm.Wrap()
+	}
+	pb, ok := msg.(*tmcons.Message)
+	if !ok {
+		return fmt.Errorf("unknown message type %T", msg)
+	}
+	m, err := MsgFromProto(pb)
+	if err != nil {
+		return err
+	}
+	return m.ValidateBasic()
+}
+
 // Receive implements Reactor
 // NOTE: We process these messages even when we're fast_syncing.
 // Messages affect either a peer state or the consensus state.
@@ -238,20 +381,32 @@ func (conR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 		conR.Logger.Debug("Receive", "src", e.Src, "chId", e.ChannelID)
 		return
 	}
+	_, span := tracing.StartMessageSpan(e.Context(), "consensus.handle", trace.WithAttributes(
+		attribute.String("peer_id", string(e.Src.ID())),
+		attribute.String("channel", fmt.Sprintf("%#x", e.ChannelID)),
+	))
+	defer span.End()
+	if e.ChannelID == VoteExtensionChannel {
+		conR.receiveVoteExtension(e)
+		return
+	}
 	m := e.Message
 	if wm, ok := m.(p2p.Wrapper); ok {
 		m = wm.Wrap()
 	}
 	msg, err := MsgFromProto(m.(*tmcons.Message))
+	if err == nil && span.IsRecording() {
+		span.SetAttributes(msgSpanAttributes(msg)...)
+	}
 	if err != nil {
 		conR.Logger.Error("Error decoding message", "src", e.Src, "chId", e.ChannelID, "err", err)
-		conR.Switch.StopPeerForError(e.Src, err)
+		conR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
 		return
 	}
 
 	if err = msg.ValidateBasic(); err != nil {
 		conR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", e.Message, "err", err)
-		conR.Switch.StopPeerForError(e.Src, err)
+		conR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
 		return
 	}
 
@@ -272,7 +427,7 @@ func (conR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 			conR.conS.mtx.Unlock()
 			if err = msg.ValidateHeight(initialHeight); err != nil {
 				conR.Logger.Error("Peer sent us invalid msg", "peer", e.Src, "msg", msg, "err", err)
-				conR.Switch.StopPeerForError(e.Src, err)
+				conR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
 				return
 			}
 			ps.ApplyNewRoundStepMessage(msg)
@@ -356,7 +511,12 @@ func (conR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 			ps.EnsureVoteBitArrays(height-1, lastCommitSize)
 			ps.SetHasVote(msg.Vote)
 
-			cs.peerMsgQueue <- msgInfo{msg, e.Src.ID()}
+			// the votes are queued once their signatures are verified by the pool,
+			// or right away if it's full
+			mi := msgInfo{msg, e.Src.ID()}
+			if !conR.batchVoteVerification(mi) {
+				cs.peerMsgQueue <- mi
+			}
 
 		default:
 			// don't punish (leave room for soft upgrades)
@@ -400,6 +560,18 @@ func (conR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 }
 
 func (conR *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
+	if chID == VoteExtensionChannel {
+		ext := &ocproto.VoteExtension{}
+		if err := proto.Unmarshal(msgBytes, ext); err != nil {
+			panic(err)
+		}
+		conR.ReceiveEnvelope(p2p.Envelope{
+			ChannelID: chID,
+			Src:       peer,
+			Message:   ext,
+		})
+		return
+	}
 	msg := &tmcons.Message{}
 	err := proto.Unmarshal(msgBytes, msg)
 	if err != nil {
@@ -565,6 +737,7 @@ func (conR *Reactor) getRoundState() *cstypes.RoundState {
 
 func (conR *Reactor) gossipDataRoutine(peer p2p.Peer, ps *PeerState) {
 	logger := conR.Logger.With("peer", peer)
+	var priority gossipPriority
 
 OUTER_LOOP:
 	for {
@@ -577,7 +750,11 @@ OUTER_LOOP:
 
 		// Send proposal Block parts?
 		if rs.ProposalBlockParts.HasHeader(prs.ProposalBlockPartSetHeader) {
-			if index, ok := rs.ProposalBlockParts.BitArray().Sub(prs.ProposalBlockParts.Copy()).PickRandom(); ok {
+			parts := rs.ProposalBlockParts.BitArray()
+			if conR.defersToValidators(peer, rs, &priority) {
+				parts = conR.validatorBlockParts(rs, parts)
+			}
+			if index, ok := parts.Sub(prs.ProposalBlockParts.Copy()).PickRandom(); ok {
 				part := rs.ProposalBlockParts.GetPart(index)
 				parts, err := part.ToProto()
 				if err != nil {
@@ -725,6 +902,7 @@ func (conR *Reactor) gossipVotesRoutine(peer p2p.Peer, ps *PeerState) {
 
 	// Simple hack to throttle logs upon sleep.
 	var sleeping = 0
+	var priority gossipPriority
 
 OUTER_LOOP:
 	for {
@@ -748,7 +926,7 @@ OUTER_LOOP:
 		// If height matches, then send LastCommit, Prevotes, Precommits.
 		if rs.Height == prs.Height {
 			heightLogger := logger.With("height", prs.Height)
-			if conR.gossipVotesForHeight(heightLogger, rs, prs, ps) {
+			if conR.gossipVotesForHeight(heightLogger, rs, prs, ps, conR.defersToValidators(peer, rs, &priority)) {
 				continue OUTER_LOOP
 			}
 		}
@@ -797,11 +975,20 @@ func (conR *Reactor) gossipVotesForHeight(
 	rs *cstypes.RoundState,
 	prs *cstypes.PeerRoundState,
 	ps *PeerState,
+	deferred bool,
 ) bool {
 
+	// the votes are picked among those the validator peers have if deferred
+	pick := ps.PickSendVote
+	if deferred {
+		pick = func(votes types.VoteSetReader) bool {
+			return ps.PickSendVote(conR.validatorVotes(rs, votes))
+		}
+	}
+
 	// If there are lastCommits to send...
 	if prs.Step == cstypes.RoundStepNewHeight {
-		if ps.PickSendVote(rs.LastCommit) {
+		if pick(rs.LastCommit) {
 			logger.Debug("Picked rs.LastCommit to send")
 			return true
 		}
@@ -809,7 +996,7 @@ func (conR *Reactor) gossipVotesForHeight(
 	// If there are POL prevotes to send...
 	if prs.Step <= cstypes.RoundStepPropose && prs.Round != -1 && prs.Round <= rs.Round && prs.ProposalPOLRound != -1 {
 		if polPrevotes := rs.Votes.Prevotes(prs.ProposalPOLRound); polPrevotes != nil {
-			if ps.PickSendVote(polPrevotes) {
+			if pick(polPrevotes) {
 				logger.Debug("Picked rs.Prevotes(prs.ProposalPOLRound) to send",
 					"round", prs.ProposalPOLRound)
 				return true
@@ -818,21 +1005,21 @@ func (conR *Reactor) gossipVotesForHeight(
 	}
 	// If there are prevotes to send...
 	if prs.Step <= cstypes.RoundStepPrevoteWait && prs.Round != -1 && prs.Round <= rs.Round {
-		if ps.PickSendVote(rs.Votes.Prevotes(prs.Round)) {
+		if pick(rs.Votes.Prevotes(prs.Round)) {
 			logger.Debug("Picked rs.Prevotes(prs.Round) to send", "round", prs.Round)
 			return true
 		}
 	}
 	// If there are precommits to send...
 	if prs.Step <= cstypes.RoundStepPrecommitWait && prs.Round != -1 && prs.Round <= rs.Round {
-		if ps.PickSendVote(rs.Votes.Precommits(prs.Round)) {
+		if pick(rs.Votes.Precommits(prs.Round)) {
 			logger.Debug("Picked rs.Precommits(prs.Round) to send", "round", prs.Round)
 			return true
 		}
 	}
 	// If there are prevotes to send...Needed because of validBlock mechanism
 	if prs.Round != -1 && prs.Round <= rs.Round {
-		if ps.PickSendVote(rs.Votes.Prevotes(prs.Round)) {
+		if pick(rs.Votes.Prevotes(prs.Round)) {
 			logger.Debug("Picked rs.Prevotes(prs.Round) to send", "round", prs.Round)
 			return true
 		}
@@ -840,7 +1027,7 @@ func (conR *Reactor) gossipVotesForHeight(
 	// If there are POLPrevotes to send...
 	if prs.ProposalPOLRound != -1 {
 		if polPrevotes := rs.Votes.Prevotes(prs.ProposalPOLRound); polPrevotes != nil {
-			if ps.PickSendVote(polPrevotes) {
+			if pick(polPrevotes) {
 				logger.Debug("Picked rs.Prevotes(prs.ProposalPOLRound) to send",
 					"round", prs.ProposalPOLRound)
 				return true
@@ -851,6 +1038,109 @@ func (conR *Reactor) gossipVotesForHeight(
 	return false
 }
 
+// gossipPriority is whether the peer of a gossip routine is a validator of
+// the height it was last checked at.
+type gossipPriority struct {
+	height    int64
+	validator bool
+}
+
+// defersToValidators returns whether the block parts and the votes of the
+// height of rs are sent to the peer once the validator peers have them, which
+// need them to make progress: when the validator gossip is prioritized and the
+// peer isn't a validator of the height. The proposers of the rounds are among
+// the validators.
+func (conR *Reactor) defersToValidators(peer p2p.Peer, rs *cstypes.RoundState, priority *gossipPriority) bool {
+	if !conR.conS.config.PrioritizeValidatorGossip {
+		return false
+	}
+	if priority.height != rs.Height {
+		priority.height = rs.Height
+		priority.validator = conR.isValidatorPeer(peer, rs.Validators)
+	}
+	return !priority.validator
+}
+
+// validatorPeerStates returns the states of the peers which are validators of
+// the height of rs, and following it.
+func (conR *Reactor) validatorPeerStates(rs *cstypes.RoundState) []*PeerState {
+	var states []*PeerState
+	for _, peer := range conR.Switch.Peers().List() {
+		if !conR.isValidatorPeer(peer, rs.Validators) {
+			continue
+		}
+		ps, ok := peer.Get(types.PeerStateKey).(*PeerState)
+		if !ok || ps.GetHeight() != rs.Height {
+			continue
+		}
+		states = append(states, ps)
+	}
+	return states
+}
+
+// validatorBlockParts returns the parts, among those of the proposal block,
+// which the validator peers receiving the proposal block have.
+func (conR *Reactor) validatorBlockParts(rs *cstypes.RoundState, parts *bits.BitArray) *bits.BitArray {
+	header := rs.ProposalBlockParts.Header()
+	for _, ps := range conR.validatorPeerStates(rs) {
+		prs := ps.GetRoundState()
+		if prs.ProposalBlockParts != nil && prs.ProposalBlockPartSetHeader.Equals(header) {
+			parts = parts.And(prs.ProposalBlockParts)
+		}
+	}
+	return parts
+}
+
+// validatorVotes returns the votes, restricted to those the validator peers
+// tracking them have.
+func (conR *Reactor) validatorVotes(rs *cstypes.RoundState, votes types.VoteSetReader) types.VoteSetReader {
+	if votes.Size() == 0 {
+		return votes
+	}
+	var have *bits.BitArray
+	for _, ps := range conR.validatorPeerStates(rs) {
+		peerVotes := ps.voteBitArray(votes.GetHeight(), votes.GetRound(), tmproto.SignedMsgType(votes.Type()))
+		switch {
+		case peerVotes == nil:
+		case have == nil:
+			have = peerVotes
+		default:
+			have = have.And(peerVotes)
+		}
+	}
+	if have == nil {
+		return votes
+	}
+	return maskedVoteSet{VoteSetReader: votes, mask: have}
+}
+
+// maskedVoteSet is the votes of a VoteSetReader among those of a bit array.
+type maskedVoteSet struct {
+	types.VoteSetReader
+	mask *bits.BitArray
+}
+
+func (vs maskedVoteSet) BitArray() *bits.BitArray {
+	return vs.VoteSetReader.BitArray().And(vs.mask)
+}
+
+// isValidatorPeer returns whether the peer is one of the validators: the
+// validator its node ID is mapped to by ValidatorPeers or, otherwise, the
+// validator of its node key, whose address is its node ID.
+func (conR *Reactor) isValidatorPeer(peer p2p.Peer, vals *types.ValidatorSet) bool {
+	if vals == nil {
+		return false
+	}
+	addr, ok := conR.validatorPeers[peer.ID()]
+	if !ok {
+		var err error
+		if addr, err = hex.DecodeString(string(peer.ID())); err != nil {
+			return false
+		}
+	}
+	return vals.HasAddress(addr)
+}
+
 // NOTE: `queryMaj23Routine` has a simple crude design since it only comes
 // into play for liveness when there's a signature DDoS attack happening.
 func (conR *Reactor) queryMaj23Routine(peer p2p.Peer, ps *PeerState) {
@@ -977,11 +1267,11 @@ func (conR *Reactor) peerStatsRoutine() {
 			switch msg.Msg.(type) {
 			case *VoteMessage:
 				if numVotes := ps.RecordVote(); numVotes%votesToContributeToBecomeGoodPeer == 0 {
-					conR.Switch.MarkPeerAsGood(peer)
+					conR.Switch.ReportPeerBehavior(peer.ID(), p2p.UsefulMessage("votes"))
 				}
 			case *BlockPartMessage:
 				if numParts := ps.RecordBlockPart(); numParts%blocksToContributeToBecomeGoodPeer == 0 {
-					conR.Switch.MarkPeerAsGood(peer)
+					conR.Switch.ReportPeerBehavior(peer.ID(), p2p.UsefulMessage("block parts"))
 				}
 			}
 		case <-conR.conS.Quit():
@@ -1039,6 +1329,10 @@ type PeerState struct {
 	mtx   sync.Mutex             // NOTE: Modify below using setters, never directly.
 	PRS   cstypes.PeerRoundState `json:"round_state"` // Exposed.
 	Stats *peerStateStats        `json:"stats"`       // Exposed.
+
+	// the vote extensions the peer has, by height, then by validator address
+	// and block hash
+	voteExtensions map[int64]map[string]struct{}
 }
 
 // peerStateStats holds internal statistics for a peer.
@@ -1063,7 +1357,8 @@ func NewPeerState(peer p2p.Peer) *PeerState {
 			LastCommitRound:    -1,
 			CatchupCommitRound: -1,
 		},
-		Stats: &peerStateStats{},
+		Stats:          &peerStateStats{},
+		voteExtensions: make(map[int64]map[string]struct{}),
 	}
 }
 
@@ -1204,6 +1499,15 @@ func (ps *PeerState) PickVoteToSend(votes types.VoteSetReader) (vote *types.Vote
 	return nil, false
 }
 
+// voteBitArray returns a copy of the bit array of the votes the peer has, nil
+// if it doesn't track them.
+func (ps *PeerState) voteBitArray(height int64, round int32, votesType tmproto.SignedMsgType) *bits.BitArray {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	return ps.getVoteBitArray(height, round, votesType).Copy()
+}
+
 func (ps *PeerState) getVoteBitArray(height int64, round int32, votesType tmproto.SignedMsgType) *bits.BitArray {
 	if !types.IsVoteTypeValid(votesType) {
 		return nil
@@ -1356,6 +1660,46 @@ func (ps *PeerState) SetHasVote(vote *types.Vote) {
 	ps.setHasVote(vote.Height, vote.Round, vote.Type, vote.ValidatorIndex)
 }
 
+// SetHasVoteExtension sets the given vote extension as known by the peer.
+func (ps *PeerState) SetHasVoteExtension(ext *types.VoteExtension) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	byKey, ok := ps.voteExtensions[ext.Height]
+	if !ok {
+		byKey = make(map[string]struct{})
+		ps.voteExtensions[ext.Height] = byKey
+	}
+	byKey[voteExtensionKey(ext)] = struct{}{}
+}
+
+// HasVoteExtension returns true if the given vote extension is known by the
+// peer.
+func (ps *PeerState) HasVoteExtension(ext *types.VoteExtension) bool {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	_, ok := ps.voteExtensions[ext.Height][voteExtensionKey(ext)]
+	return ok
+}
+
+// pruneVoteExtensions forgets the vote extensions known by the peer before the
+// height.
+func (ps *PeerState) pruneVoteExtensions(height int64) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	for h := range ps.voteExtensions {
+		if h < height {
+			delete(ps.voteExtensions, h)
+		}
+	}
+}
+
+func voteExtensionKey(ext *types.VoteExtension) string {
+	return string(ext.ValidatorAddress) + string(ext.BlockHash)
+}
+
 func (ps *PeerState) setHasVote(height int64, round int32, voteType tmproto.SignedMsgType, index int32) {
 	ps.logger.Debug("setHasVote",
 		"peerH/R",
@@ -25,18 +25,20 @@ import (
 	"github.com/Finschia/ostracon/abci/example/kvstore"
 	cfg "github.com/Finschia/ostracon/config"
 	cstypes "github.com/Finschia/ostracon/consensus/types"
+	"github.com/Finschia/ostracon/crypto"
 	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	"github.com/Finschia/ostracon/libs/bits"
 	"github.com/Finschia/ostracon/libs/bytes"
 	"github.com/Finschia/ostracon/libs/log"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	mempl "github.com/Finschia/ostracon/mempool"
 	mempoolv0 "github.com/Finschia/ostracon/mempool/v0"
-
-	//mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
+	mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
 	"github.com/Finschia/ostracon/p2p"
 	p2pmock "github.com/Finschia/ostracon/p2p/mock"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	sm "github.com/Finschia/ostracon/state"
 	statemocks "github.com/Finschia/ostracon/state/mocks"
 	"github.com/Finschia/ostracon/store"
@@ -176,18 +178,14 @@ func TestReactorWithEvidence(t *testing.T) {
 				mempoolv0.WithMetrics(memplMetrics),
 				mempoolv0.WithPreCheck(sm.TxPreCheck(state)),
 				mempoolv0.WithPostCheck(sm.TxPostCheck(state)))
-		case cfg.MempoolV1: // XXX Deprecated MempoolV1
-			panic("Deprecated MempoolV1")
-			/*
-				mempool = mempoolv1.NewTxMempool(logger,
-					config.Mempool,
-					proxyAppConnConMem,
-					state.LastBlockHeight,
-					mempoolv1.WithMetrics(memplMetrics),
-					mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-					mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-				)
-			*/
+		case cfg.MempoolV1:
+			mempool = mempoolv1.NewTxMempool(config.Mempool,
+				proxyAppConnConMem,
+				state.LastBlockHeight,
+				mempoolv1.WithMetrics(memplMetrics),
+				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+			)
 		}
 		if thisConfig.Consensus.WaitForTxs() {
 			mempool.EnableTxsAvailable()
@@ -315,6 +313,44 @@ func TestLegacyReactorReceiveBasic(t *testing.T) {
 	})
 }
 
+func TestReactorValidateMessage(t *testing.T) {
+	conR := &Reactor{}
+	ext := &ocproto.VoteExtension{
+		Height:           1,
+		BlockHash:        tmhash.Sum([]byte("block")),
+		ValidatorAddress: crypto.AddressHash([]byte("validator_address")),
+		Extension:        []byte("extension"),
+		Signature:        []byte("signature"),
+	}
+	testCases := []struct {
+		name      string
+		chID      byte
+		msg       proto.Message
+		expectErr bool
+	}{
+		{"has vote", StateChannel, &tmcons.HasVote{Height: 1, Round: 1, Index: 1, Type: tmproto.PrevoteType}, false},
+		{"has vote negative round", StateChannel,
+			&tmcons.HasVote{Height: 1, Round: -1, Index: 1, Type: tmproto.PrevoteType}, true},
+		{"wrapped has vote", StateChannel,
+			(&tmcons.HasVote{Height: 1, Round: 1, Index: 1, Type: tmproto.PrevoteType}).Wrap(), false},
+		{"block part without part", DataChannel, &tmcons.BlockPart{Height: 1}, true},
+		{"vote extension", VoteExtensionChannel, ext, false},
+		{"vote extension without signature", VoteExtensionChannel,
+			&ocproto.VoteExtension{Height: 1, BlockHash: ext.BlockHash, ValidatorAddress: ext.ValidatorAddress,
+				Extension: ext.Extension}, true},
+		{"consensus message on vote extension channel", VoteExtensionChannel,
+			&tmcons.HasVote{Height: 1, Round: 1, Index: 1, Type: tmproto.PrevoteType}, true},
+	}
+	for _, tc := range testCases {
+		err := conR.ValidateMessage(tc.chID, tc.msg)
+		if tc.expectErr {
+			assert.Error(t, err, tc.name)
+		} else {
+			assert.NoError(t, err, tc.name)
+		}
+	}
+}
+
 func TestReactorReceivePanicsIfInitPeerHasntBeenCalledYet(t *testing.T) {
 	N := 1
 	css, cleanup := randConsensusNet(N, "consensus_reactor_test", newMockTickerFunc(true), newCounter)
@@ -340,6 +376,82 @@ func TestReactorReceivePanicsIfInitPeerHasntBeenCalledYet(t *testing.T) {
 	})
 }
 
+// nodeKeyPeer is a peer whose node key is the validator key of pubKey.
+type nodeKeyPeer struct {
+	*p2pmock.Peer
+	pubKey crypto.PubKey
+}
+
+func (p nodeKeyPeer) ID() p2p.ID { return p2p.PubKeyToID(p.pubKey) }
+
+func TestReactorGossipPriority(t *testing.T) {
+	cs, vss := randState(3)
+	pubKey1, err := vss[1].GetPubKey()
+	require.NoError(t, err)
+	pubKey2, err := vss[2].GetPubKey()
+	require.NoError(t, err)
+
+	listed := p2pmock.NewPeer(nil)
+	cs.config.ValidatorPeers = []string{fmt.Sprintf("%s:%X", listed.ID(), pubKey1.Address())}
+	cs.config.PrioritizeValidatorGossip = true
+	conR := NewReactor(cs, true, true, 1000)
+	vals := cs.GetRoundState().Validators
+
+	nodeKey := nodeKeyPeer{Peer: p2pmock.NewPeer(nil), pubKey: pubKey2}
+	other := p2pmock.NewPeer(nil)
+	assert.True(t, conR.isValidatorPeer(listed, vals))
+	assert.True(t, conR.isValidatorPeer(nodeKey, vals))
+	assert.False(t, conR.isValidatorPeer(other, vals))
+	assert.False(t, conR.isValidatorPeer(other, nil))
+
+	rs := cs.GetRoundState()
+	var validatorPriority, otherPriority gossipPriority
+	assert.False(t, conR.defersToValidators(listed, rs, &validatorPriority))
+	assert.True(t, conR.defersToValidators(other, rs, &otherPriority))
+	assert.Equal(t, gossipPriority{height: rs.Height, validator: true}, validatorPriority)
+	assert.Equal(t, gossipPriority{height: rs.Height, validator: false}, otherPriority)
+
+	// the other peers are sent the votes and the block parts the validator
+	// peers following the height have
+	sw := p2p.MakeSwitch(config.P2P, 1, "testing", "123.123.123", func(i int, sw *p2p.Switch, _ *cfg.P2PConfig) *p2p.Switch { return sw })
+	conR.SetSwitch(sw)
+	ps := NewPeerState(listed)
+	listed.Set(types.PeerStateKey, ps)
+	p2p.AddPeerToSwitchPeerSet(sw, listed)
+	p2p.AddPeerToSwitchPeerSet(sw, other)
+	ps.ApplyNewRoundStepMessage(&NewRoundStepMessage{Height: rs.Height, Round: 0, Step: cstypes.RoundStepPrevote})
+	ps.EnsureVoteBitArrays(rs.Height, vals.Size())
+
+	blockID := types.BlockID{Hash: tmrand.Bytes(tmhash.Size), PartSetHeader: types.PartSetHeader{Total: 1, Hash: tmrand.Bytes(tmhash.Size)}}
+	prevotes := types.NewVoteSet(cs.state.ChainID, rs.Height, 0, tmproto.PrevoteType, vals)
+	vote1 := signVote(vss[1], tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader)
+	vote2 := signVote(vss[2], tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader)
+	for _, vote := range []*types.Vote{vote1, vote2} {
+		_, err := prevotes.AddVote(vote)
+		require.NoError(t, err)
+	}
+	ps.SetHasVote(vote1)
+	votes := conR.validatorVotes(rs, prevotes).BitArray()
+	assert.True(t, votes.GetIndex(int(vote1.ValidatorIndex)))
+	assert.False(t, votes.GetIndex(int(vote2.ValidatorIndex)))
+
+	partSet := types.NewPartSetFromData(tmrand.Bytes(3*int(types.BlockPartSizeBytes)), types.BlockPartSizeBytes)
+	rs.ProposalBlockParts = partSet
+	ps.InitProposalBlockParts(partSet.Header())
+	ps.SetHasProposalBlockPart(rs.Height, 0, 1)
+	parts := conR.validatorBlockParts(rs, partSet.BitArray())
+	assert.Equal(t, []bool{false, true, false}, []bool{parts.GetIndex(0), parts.GetIndex(1), parts.GetIndex(2)})
+
+	// unless they're at another height
+	ps.ApplyNewRoundStepMessage(&NewRoundStepMessage{Height: rs.Height + 1, Step: cstypes.RoundStepNewHeight})
+	assert.Same(t, prevotes, conR.validatorVotes(rs, prevotes))
+	assert.Equal(t, partSet.BitArray(), conR.validatorBlockParts(rs, partSet.BitArray()))
+
+	// no priority
+	cs.config.PrioritizeValidatorGossip = false
+	assert.False(t, conR.defersToValidators(other, rs, &gossipPriority{}))
+}
+
 // Test we record stats about votes and block parts from other peers.
 func TestReactorRecordsVotesAndBlockParts(t *testing.T) {
 	N := 4
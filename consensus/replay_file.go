@@ -311,6 +311,11 @@ func newConsensusStateForReplay(config cfg.BaseConfig, csConfig *cfg.ConsensusCo
 
 	// Create proxyAppConn connection (consensus, mempool, query)
 	clientCreator := proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir())
+	if p, ok := clientCreator.(*proxy.AppProcess); ok {
+		if err := p.Start(); err != nil {
+			tmos.Exit(fmt.Sprintf("Error starting the application: %v", err))
+		}
+	}
 	proxyApp := proxy.NewAppConns(clientCreator)
 	err = proxyApp.Start()
 	if err != nil {
@@ -29,6 +29,10 @@ func (emptyMempool) CheckTxAsync(_ types.Tx, _ mempl.TxInfo, _ func(error), _ fu
 func (emptyMempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs          { return types.Txs{} }
 func (emptyMempool) ReapMaxBytesMaxGasMaxTxs(_, _, _ int64) types.Txs { return types.Txs{} }
 func (emptyMempool) ReapMaxTxs(n int) types.Txs                       { return types.Txs{} }
+func (emptyMempool) GetPendingTx(_ types.TxKey) (mempl.PendingTx, bool) {
+	return mempl.PendingTx{}, false
+}
+func (emptyMempool) PendingTxs() []mempl.PendingTx { return nil }
 
 func (txmp emptyMempool) RemoveTxByKey(txKey types.TxKey) error {
 	return nil
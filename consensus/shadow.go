@@ -0,0 +1,100 @@
+package consensus
+
+import (
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/types"
+)
+
+// shadowVoteKey identifies the vote of the validator for a step of the
+// consensus.
+type shadowVoteKey struct {
+	height int64
+	round  int32
+	typ    tmproto.SignedMsgType
+}
+
+// shadowVotes pairs the votes a node in shadow mode (see
+// cfg.ConsensusConfig.ShadowMode) would sign with the ones actually signed by
+// the validator of its key. It is only accessed by the receive routine of the
+// State.
+type shadowVotes struct {
+	// the block IDs of the votes which haven't been paired yet
+	wouldSign map[shadowVoteKey]types.BlockID
+	signed    map[shadowVoteKey]types.BlockID
+}
+
+func newShadowVotes() *shadowVotes {
+	return &shadowVotes{
+		wouldSign: make(map[shadowVoteKey]types.BlockID),
+		signed:    make(map[shadowVoteKey]types.BlockID),
+	}
+}
+
+func shadowVoteType(typ tmproto.SignedMsgType) string {
+	switch typ {
+	case tmproto.PrevoteType:
+		return "prevote"
+	case tmproto.PrecommitType:
+		return "precommit"
+	default:
+		return typ.String()
+	}
+}
+
+// shadowSignVote records the vote the node would sign in shadow mode instead
+// of signing it.
+func (cs *State) shadowSignVote(vote *types.Vote) {
+	cs.metrics.ShadowVotes.With("type", shadowVoteType(vote.Type)).Add(1)
+	key := shadowVoteKey{vote.Height, vote.Round, vote.Type}
+	if signed, ok := cs.shadow.signed[key]; ok {
+		delete(cs.shadow.signed, key)
+		cs.compareShadowVote(key, vote.BlockID, signed)
+		return
+	}
+	cs.shadow.wouldSign[key] = vote.BlockID
+}
+
+// shadowObserveVote pairs the vote signed by the validator of the key with the
+// one the node would sign in shadow mode.
+func (cs *State) shadowObserveVote(vote *types.Vote) {
+	key := shadowVoteKey{vote.Height, vote.Round, vote.Type}
+	if wouldSign, ok := cs.shadow.wouldSign[key]; ok {
+		delete(cs.shadow.wouldSign, key)
+		cs.compareShadowVote(key, wouldSign, vote.BlockID)
+		return
+	}
+	cs.shadow.signed[key] = vote.BlockID
+}
+
+func (cs *State) compareShadowVote(key shadowVoteKey, wouldSign, signed types.BlockID) {
+	typ := shadowVoteType(key.typ)
+	if wouldSign.Equals(signed) {
+		cs.metrics.ShadowVoteMatches.With("type", typ).Add(1)
+		return
+	}
+	cs.metrics.ShadowVoteDivergences.With("type", typ).Add(1)
+	cs.Logger.Error("shadow mode: the validator signed a different vote",
+		"height", key.height, "round", key.round, "type", typ,
+		"would_sign", wouldSign, "signed", signed)
+}
+
+// pruneShadowVotes forgets the votes before the previous height, which can't
+// be paired anymore (the precommits of the previous height are still gossiped
+// while waiting for the commit timeout), and reports them as missed.
+func (cs *State) pruneShadowVotes(height int64) {
+	for key := range cs.shadow.wouldSign {
+		if key.height < height-1 {
+			// the validator didn't vote, or its vote wasn't received
+			cs.metrics.ShadowValidatorMissedVotes.With("type", shadowVoteType(key.typ)).Add(1)
+			delete(cs.shadow.wouldSign, key)
+		}
+	}
+	for key := range cs.shadow.signed {
+		if key.height < height-1 {
+			// the node didn't reach the step in time
+			cs.metrics.ShadowMissedVotes.With("type", shadowVoteType(key.typ)).Add(1)
+			delete(cs.shadow.signed, key)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package consensus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/types"
+)
+
+// testCounter is a counter ignoring the labels.
+type testCounter struct {
+	value *int64
+}
+
+func newTestCounter() testCounter {
+	return testCounter{value: new(int64)}
+}
+
+func (c testCounter) With(labelValues ...string) metrics.Counter { return c }
+func (c testCounter) Add(delta float64)                          { atomic.AddInt64(c.value, int64(delta)) }
+func (c testCounter) Value() int64                               { return atomic.LoadInt64(c.value) }
+
+func TestStateShadowMode(t *testing.T) {
+	cs1, vss := randState(4)
+	cs1.shadow = newShadowVotes()
+	matches, divergences := newTestCounter(), newTestCounter()
+	cs1.metrics.ShadowVoteMatches = matches
+	cs1.metrics.ShadowVoteDivergences = divergences
+	height, round := cs1.Height, cs1.Round
+	// vs1 is the validator of the key of cs1, signing elsewhere
+	vs1, vs2, vs3, vs4 := vss[0], vss[1], vss[2], vss[3]
+	incrementHeight(vs1)
+
+	forceProposer(cs1, vss, []int{1}, []int64{height}, []int32{round})
+
+	voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+	proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+	newRoundCh := subscribe(cs1.eventBus, types.EventQueryNewRound)
+
+	proposal, propBlock := decideProposal(cs1, vs2, height, round)
+	propBlockParts := propBlock.MakePartSet(types.BlockPartSizeBytes)
+	require.NoError(t, cs1.SetProposalAndBlock(proposal, propBlock, propBlockParts, "some peer"))
+
+	startTestRound(cs1, height, round)
+	ensureNewRound(newRoundCh, height, round)
+	ensureNewProposal(proposalCh, height, round)
+
+	// cs1 doesn't prevote itself
+	ensureNoNewEventOnChannel(voteCh)
+	cs1.mtx.RLock()
+	assert.Nil(t, cs1.Votes.Prevotes(round).GetByIndex(vs1.Index))
+	cs1.mtx.RUnlock()
+
+	// the prevote of the validator matches the one cs1 would sign
+	signAddVotes(cs1, tmproto.PrevoteType, propBlock.Hash(), propBlockParts.Header(), vs1, vs2, vs3)
+	for i := 0; i < 3; i++ {
+		ensurePrevote(voteCh, height, round)
+	}
+	require.Eventually(t, func() bool { return matches.Value() == 1 }, time.Second, 10*time.Millisecond)
+
+	// the precommit of the validator differs from the one cs1 would sign
+	signAddVotes(cs1, tmproto.PrecommitType, nil, types.PartSetHeader{}, vs1)
+	ensurePrecommit(voteCh, height, round)
+	require.Eventually(t, func() bool { return divergences.Value() == 1 }, time.Second, 10*time.Millisecond)
+	assert.EqualValues(t, 1, matches.Value())
+
+	// the block is committed without cs1 signing anything
+	signAddVotes(cs1, tmproto.PrecommitType, propBlock.Hash(), propBlockParts.Header(), vs2, vs3, vs4)
+	ensureNewRound(newRoundCh, height+1, 0)
+	cs1.mtx.RLock()
+	assert.Nil(t, cs1.LastCommit.GetByIndex(vs1.Index).BlockID.Hash)
+	cs1.mtx.RUnlock()
+}
@@ -2,11 +2,13 @@ package consensus
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"runtime/debug"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -25,6 +27,7 @@ import (
 	tmos "github.com/Finschia/ostracon/libs/os"
 	"github.com/Finschia/ostracon/libs/service"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tempfile"
 	"github.com/Finschia/ostracon/p2p"
 	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	sm "github.com/Finschia/ostracon/state"
@@ -36,6 +39,7 @@ import (
 var (
 	ErrInvalidProposalSignature   = errors.New("error invalid proposal signature")
 	ErrInvalidProposalPOLRound    = errors.New("error invalid proposal POL round")
+	ErrInvalidProposalPartSetSize = errors.New("error invalid proposal part set size")
 	ErrAddingVote                 = errors.New("error adding vote")
 	ErrSignatureFoundInPastBlocks = errors.New("found signature from the same key")
 
@@ -44,6 +48,9 @@ var (
 
 var msgQueueSize = 1000
 
+// how often WaitForCommit checks whether the height is committed
+const waitForCommitPollInterval = 10 * time.Millisecond
+
 // msgs from the reactor which may update the state
 type msgInfo struct {
 	Msg    Message `json:"msg"`
@@ -157,6 +164,9 @@ type State struct {
 	peerMsgQueue     chan msgInfo
 	internalMsgQueue chan msgInfo
 	timeoutTicker    TimeoutTicker
+	// public keys the votes queued in peerMsgQueue were verified with by the
+	// reactor, by vote
+	verifiedVotes sync.Map
 
 	// information about about added votes and block parts are written on this channel
 	// so statistics can be computed by reactor
@@ -192,6 +202,17 @@ type State struct {
 
 	// times of each step
 	stepTimes *StepTimes
+
+	// the votes compared in shadow mode, nil otherwise
+	shadow *shadowVotes
+
+	// the timeouts adapted to the durations of the steps, nil unless adaptive
+	adaptiveTimeouts *adaptiveTimeouts
+
+	// the vote extensions of the current and the last heights, and those
+	// received from peers whose signatures were verified by the reactor
+	voteExtensions     *voteExtensionStore
+	voteExtensionQueue chan *types.VoteExtension
 }
 
 // StateOption sets an optional parameter on the State.
@@ -208,21 +229,23 @@ func NewState(
 	options ...StateOption,
 ) *State {
 	cs := &State{
-		config:           config,
-		blockExec:        blockExec,
-		blockStore:       blockStore,
-		txNotifier:       txNotifier,
-		peerMsgQueue:     make(chan msgInfo, msgQueueSize),
-		internalMsgQueue: make(chan msgInfo, msgQueueSize),
-		timeoutTicker:    NewTimeoutTicker(),
-		statsMsgQueue:    make(chan msgInfo, msgQueueSize),
-		done:             make(chan struct{}),
-		doWALCatchup:     true,
-		wal:              nilWAL{},
-		evpool:           evpool,
-		evsw:             tmevents.NewEventSwitch(),
-		metrics:          NopMetrics(),
-		stepTimes:        &StepTimes{},
+		config:             config,
+		blockExec:          blockExec,
+		blockStore:         blockStore,
+		txNotifier:         txNotifier,
+		peerMsgQueue:       make(chan msgInfo, msgQueueSize),
+		internalMsgQueue:   make(chan msgInfo, msgQueueSize),
+		timeoutTicker:      NewTimeoutTicker(),
+		statsMsgQueue:      make(chan msgInfo, msgQueueSize),
+		done:               make(chan struct{}),
+		doWALCatchup:       true,
+		wal:                nilWAL{},
+		evpool:             evpool,
+		evsw:               tmevents.NewEventSwitch(),
+		metrics:            NopMetrics(),
+		stepTimes:          &StepTimes{},
+		voteExtensions:     newVoteExtensionStore(),
+		voteExtensionQueue: make(chan *types.VoteExtension, msgQueueSize),
 	}
 
 	// set function defaults (may be overwritten before calling Start)
@@ -230,6 +253,13 @@ func NewState(
 	cs.doPrevote = cs.defaultDoPrevote
 	cs.setProposal = cs.defaultSetProposal
 
+	if config.ShadowMode {
+		cs.shadow = newShadowVotes()
+	}
+	if config.TimeoutAdaptive {
+		cs.adaptiveTimeouts = newAdaptiveTimeouts(config)
+	}
+
 	// We have no votes, so reconstruct LastCommit from SeenCommit.
 	if state.LastBlockHeight > 0 {
 		cs.reconstructLastCommit(state)
@@ -404,7 +434,7 @@ func (cs *State) OnStart() error {
 
 			cs.Logger.Debug("backed up WAL file", "src", cs.config.WalFile(), "dst", corruptedFile)
 
-			// 3) try to repair (WAL file will be overwritten!)
+			// 3) try to repair (WAL file will be replaced!)
 			if err := repairWalFile(corruptedFile, cs.config.WalFile()); err != nil {
 				cs.Logger.Error("the WAL repair failed", "err", err)
 				return err
@@ -481,6 +511,39 @@ func (cs *State) Wait() {
 	<-cs.done
 }
 
+// FlushWAL flushes and fsync's the WAL, e.g. before stopping the node.
+func (cs *State) FlushWAL() error {
+	return cs.wal.FlushAndSync()
+}
+
+// WaitForCommit blocks, if the node is a validator which has started voting
+// in the height in progress, until the height is committed or the context is
+// done, e.g. before stopping the node not to stop between its votes of the
+// height. It returns the error of the context if it's done first.
+func (cs *State) WaitForCommit(ctx context.Context) error {
+	cs.mtx.RLock()
+	height := cs.Height
+	voting := cs.isVoter() && cs.Step >= cstypes.RoundStepPrevote
+	cs.mtx.RUnlock()
+	if !voting {
+		return nil
+	}
+
+	cs.Logger.Info("Waiting for the height in progress to be committed", "height", height)
+	ticker := time.NewTicker(waitForCommitPollInterval)
+	defer ticker.Stop()
+	for cs.GetLastHeight() < height {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-cs.Quit():
+			return nil
+		}
+	}
+	return nil
+}
+
 // OpenWAL opens a file to log all consensus messages and timeouts for
 // deterministic accountability.
 func (cs *State) OpenWAL(walFile string) (WAL, error) {
@@ -491,6 +554,8 @@ func (cs *State) OpenWAL(walFile string) (WAL, error) {
 	}
 
 	wal.SetLogger(cs.Logger.With("wal", walFile))
+	wal.SetCompression(cs.config.WalCompression)
+	wal.SetWriteBuffer(cs.config.WalWriteBufferSize)
 
 	if err := wal.Start(); err != nil {
 		cs.Logger.Error("failed to start WAL", "err", err)
@@ -727,6 +792,11 @@ func (cs *State) updateToState(state sm.State) {
 
 	cs.state = state
 
+	if cs.shadow != nil {
+		cs.pruneShadowVotes(height)
+	}
+	cs.voteExtensions.prune(height - 1)
+
 	// Finally, broadcast RoundState
 	cs.newStep()
 }
@@ -828,6 +898,11 @@ func (cs *State) receiveRoutine(maxSteps int) {
 			// handles proposals, block parts, votes
 			cs.handleMsg(mi)
 
+		case ext := <-cs.voteExtensionQueue:
+			// the vote extensions aren't needed to replay the consensus, so
+			// they aren't written to the WAL
+			cs.handleVoteExtension(ext)
+
 		case ti := <-cs.timeoutTicker.Chan(): // tockChan:
 			if err := cs.wal.Write(ti); err != nil {
 				cs.Logger.Error("failed writing to WAL", "err", err)
@@ -899,7 +974,11 @@ func (cs *State) handleMsg(mi msgInfo) {
 	case *VoteMessage:
 		// attempt to add the vote and dupeout the validator if its a duplicate signature
 		// if the vote gives us a 2/3-any or 2/3-one, we transition
-		added, err = cs.tryAddVote(msg.Vote, peerID)
+		var verifiedWith crypto.PubKey
+		if pubKey, ok := cs.verifiedVotes.LoadAndDelete(msg.Vote); ok {
+			verifiedWith = pubKey.(crypto.PubKey)
+		}
+		added, err = cs.tryAddVote(msg.Vote, peerID, verifiedWith)
 		if added {
 			cs.statsMsgQueue <- mi
 		}
@@ -1129,7 +1208,7 @@ func (cs *State) enterPropose(height int64, round int32) {
 	}()
 
 	// If we don't get the proposal and all block parts quick enough, enterPrevote
-	cs.scheduleTimeout(cs.config.Propose(round), height, round, cstypes.RoundStepPropose)
+	cs.scheduleTimeout(cs.stepTimeouts().Propose(round), height, round, cstypes.RoundStepPropose)
 
 	// Nothing more to do if we're not a validator
 	if cs.privValidator == nil {
@@ -1155,7 +1234,10 @@ func (cs *State) enterPropose(height int64, round int32) {
 	logger.Debug("node is a validator")
 
 	// I'm a proposer, but I might not be a validator
-	if cs.isProposer(address) {
+	if cs.isProposer(address) && cs.shadow != nil {
+		logger.Info("propose step; our turn to propose, but not proposing in shadow mode", "proposer", address)
+		cs.metrics.ShadowProposals.Add(1)
+	} else if cs.isProposer(address) {
 		logger.Debug("propose step; our turn to propose", "proposer", address)
 		cs.decideProposal(height, round)
 	} else {
@@ -1266,13 +1348,27 @@ func (cs *State) createProposalBlock(round int32) (block *types.Block, blockPart
 
 	message := cs.state.MakeHashMessage(round)
 
-	proof, err := cs.privValidator.GenerateVRFProof(message)
+	var (
+		proof crypto.Proof
+		err   error
+	)
+	if pv, ok := cs.privValidator.(types.KeyRotatingPrivValidator); ok {
+		proof, err = pv.GenerateVRFProofAt(cs.Height, message)
+	} else {
+		proof, err = cs.privValidator.GenerateVRFProof(message)
+	}
 	if err != nil {
 		cs.Logger.Error(fmt.Sprintf("enterPropose: Cannot generate vrf proof: %s", err.Error()))
 		return
 	}
 
-	return cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr, round, proof, cs.config.MaxTxs)
+	block, blockParts, err = cs.blockExec.CreateProposalBlock(
+		cs.Height, cs.state, commit, proposerAddr, round, proof, cs.config.MaxTxs, cs.lastVoteExtensions(commit))
+	if err != nil {
+		cs.Logger.Error("propose step; failed to create the proposal block", "err", err)
+		return nil, nil
+	}
+	return block, blockParts
 }
 
 // Enter: `timeoutPropose` after entering Propose.
@@ -1335,6 +1431,14 @@ func (cs *State) defaultDoPrevote(height int64, round int32) {
 		return
 	}
 
+	// Let the application reject the proposal block, if the node votes.
+	if cs.isVoter() && !cs.processProposal(logger, cs.ProposalBlock) {
+		logger.Error("prevote step: ProposalBlock is rejected by the application")
+		cs.metrics.RejectedProposals.Add(1)
+		cs.signAddVote(tmproto.PrevoteType, nil, types.PartSetHeader{})
+		return
+	}
+
 	// Prevote cs.ProposalBlock
 	// NOTE: the proposal signature is validated when it is received,
 	// and the proposal block parts are validated as they are received (against the merkle hash in the proposal)
@@ -1342,6 +1446,48 @@ func (cs *State) defaultDoPrevote(height int64, round int32) {
 	cs.signAddVote(tmproto.PrevoteType, cs.ProposalBlock.Hash(), cs.ProposalBlockParts.Header())
 }
 
+// isVoter returns true if the node signs the votes of a validator of the
+// height (or would sign them in shadow mode).
+func (cs *State) isVoter() bool {
+	return cs.privValidator != nil && cs.privValidatorPubKey != nil &&
+		cs.Validators.HasAddress(cs.privValidatorPubKey.Address())
+}
+
+// processProposal returns true if the application accepts the block with
+// ProcessProposal. If it doesn't answer within process_proposal_timeout or
+// fails to, the block is accepted only if process_proposal_fail_open is set.
+func (cs *State) processProposal(logger log.Logger, block *types.Block) bool {
+	type result struct {
+		accepted bool
+		err      error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		accepted, err := cs.blockExec.ProcessProposal(block)
+		resCh <- result{accepted, err}
+	}()
+
+	var timeout <-chan time.Time
+	if cs.config.ProcessProposalTimeout > 0 {
+		timer := time.NewTimer(cs.config.ProcessProposalTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case res := <-resCh:
+		if res.err == nil {
+			return res.accepted
+		}
+		logger.Error("prevote step: ProcessProposal failed",
+			"err", res.err, "fail_open", cs.config.ProcessProposalFailOpen)
+	case <-timeout:
+		logger.Error("prevote step: ProcessProposal timed out",
+			"timeout", cs.config.ProcessProposalTimeout, "fail_open", cs.config.ProcessProposalFailOpen)
+	}
+	cs.metrics.ProcessProposalFailures.Add(1)
+	return cs.config.ProcessProposalFailOpen
+}
+
 // Enter: any +2/3 prevotes at next round.
 func (cs *State) enterPrevoteWait(height int64, round int32) {
 	logger := cs.Logger.With("height", height, "round", round)
@@ -1370,7 +1516,7 @@ func (cs *State) enterPrevoteWait(height int64, round int32) {
 	}()
 
 	// Wait for some more prevotes; enterPrecommit
-	cs.scheduleTimeout(cs.config.Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
+	cs.scheduleTimeout(cs.stepTimeouts().Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
 }
 
 // Enter: `timeoutPrevote` after any +2/3 prevotes.
@@ -1532,7 +1678,7 @@ func (cs *State) enterPrecommitWait(height int64, round int32) {
 	}()
 
 	// wait for some more precommits; enterNewRound
-	cs.scheduleTimeout(cs.config.Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
+	cs.scheduleTimeout(cs.stepTimeouts().Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
 }
 
 // Enter: +2/3 precommits for block
@@ -1745,6 +1891,10 @@ func (cs *State) finalizeCommit(height int64) {
 	// must be called before we update state
 	cs.recordMetrics(height, block)
 
+	if cs.adaptiveTimeouts != nil && !cs.replayMode {
+		cs.adaptiveTimeouts.observe(cs.stepTimes)
+	}
+
 	// NewHeightStep!
 	cs.updateToState(stateCopy)
 	fail.Fail() // XXX
@@ -1766,6 +1916,14 @@ func (cs *State) finalizeCommit(height int64) {
 	cs.stepTimes.StartWaiting()
 }
 
+// stepTimeouts returns the timeouts of the propose, prevote and precommit steps.
+func (cs *State) stepTimeouts() stepTimeouts {
+	if cs.adaptiveTimeouts != nil {
+		return cs.adaptiveTimeouts
+	}
+	return cs.config
+}
+
 func (cs *State) pruneBlocks(retainHeight int64) (uint64, error) {
 	base := cs.blockStore.Base()
 	if retainHeight <= base {
@@ -1917,6 +2075,12 @@ func (cs *State) defaultSetProposal(proposal *types.Proposal) error {
 		return ErrInvalidProposalPOLRound
 	}
 
+	// Verify the number of block parts, which can't be more than the maximum
+	// block size split into parts of the block part size of the chain.
+	if proposal.BlockID.PartSetHeader.Total > cs.state.MaxBlockPartsCount() {
+		return ErrInvalidProposalPartSetSize
+	}
+
 	// If consensus does not enterNewRound yet, cs.Proposer may be nil or prior proposer, so don't use cs.Proposer
 	proposer := cs.Validators.SelectProposer(cs.state.LastProofHash, proposal.Height, proposal.Round)
 
@@ -1970,6 +2134,12 @@ func (cs *State) addProposalBlockPart(msg *BlockPartMessage, peerID p2p.ID) (add
 		return false, nil
 	}
 
+	// The parts are at most of the block part size of the chain, which may be
+	// smaller than the maximum checked by Part.ValidateBasic.
+	if partSize := cs.state.BlockPartSize(); len(part.Bytes) > int(partSize) {
+		return false, fmt.Errorf("block part too big: %d bytes, max: %d", len(part.Bytes), partSize)
+	}
+
 	added, err = cs.ProposalBlockParts.AddPart(part)
 	if err != nil {
 		return added, err
@@ -2043,9 +2213,51 @@ func (cs *State) handleCompleteProposal(blockHeight int64) {
 	}
 }
 
+// verifyVotes verifies the signatures of the votes received from peers with
+// the public keys of their validators, in a batch, before they're queued, so
+// that the signatures are verified in parallel off the receive routine. The
+// signature of a vote which isn't for the current or the last height, or
+// which fails verification here, is verified again when adding it.
+func (cs *State) verifyVotes(votes []*types.Vote) {
+	cs.mtx.RLock()
+	height, valSet, lastValSet := cs.Height, cs.Validators, cs.LastValidators
+	chainID := cs.state.ChainID
+	cs.mtx.RUnlock()
+
+	var (
+		batch   = types.NewSignatureBatch()
+		batched []*types.Vote
+		pubKeys []crypto.PubKey
+	)
+	for _, vote := range votes {
+		var vals *types.ValidatorSet
+		switch vote.Height {
+		case height:
+			vals = valSet
+		case height - 1:
+			vals = lastValSet
+		}
+		if vals == nil {
+			continue
+		}
+		_, val := vals.GetByIndex(vote.ValidatorIndex)
+		if val == nil || !bytes.Equal(val.PubKey.Address(), vote.ValidatorAddress) {
+			continue
+		}
+		batch.Add(val.PubKey, types.VoteSignBytes(chainID, vote.ToProto()), vote.Signature)
+		batched = append(batched, vote)
+		pubKeys = append(pubKeys, val.PubKey)
+	}
+	for i, valid := range batch.Verify() {
+		if valid {
+			cs.verifiedVotes.Store(batched[i], pubKeys[i])
+		}
+	}
+}
+
 // Attempt to add the vote. if its a duplicate signature, dupeout the validator
-func (cs *State) tryAddVote(vote *types.Vote, peerID p2p.ID) (bool, error) {
-	added, err := cs.addVote(vote, peerID)
+func (cs *State) tryAddVote(vote *types.Vote, peerID p2p.ID, verifiedWith crypto.PubKey) (bool, error) {
+	added, err := cs.addVote(vote, peerID, verifiedWith)
 	if err != nil {
 		// If the vote height is off, we'll just ignore it,
 		// But if it's a conflicting sig, add it to the cs.evpool.
@@ -2089,10 +2301,17 @@ func (cs *State) tryAddVote(vote *types.Vote, peerID p2p.ID) (bool, error) {
 		}
 	}
 
+	if added && cs.shadow != nil && cs.privValidatorPubKey != nil &&
+		bytes.Equal(vote.ValidatorAddress, cs.privValidatorPubKey.Address()) {
+		cs.shadowObserveVote(vote)
+	}
+
 	return added, nil
 }
 
-func (cs *State) addVote(vote *types.Vote, peerID p2p.ID) (added bool, err error) {
+// addVote adds the vote, verifying its signature unless it was verified with
+// the public key verifiedWith, if not nil.
+func (cs *State) addVote(vote *types.Vote, peerID p2p.ID, verifiedWith crypto.PubKey) (added bool, err error) {
 	cs.Logger.Debug(
 		"adding vote",
 		"vote_height", vote.Height,
@@ -2110,7 +2329,7 @@ func (cs *State) addVote(vote *types.Vote, peerID p2p.ID) (added bool, err error
 			return
 		}
 
-		added, err = cs.LastCommit.AddVote(vote)
+		added, err = cs.LastCommit.AddVerifiedVote(vote, verifiedWith)
 		if !added {
 			return
 		}
@@ -2140,7 +2359,7 @@ func (cs *State) addVote(vote *types.Vote, peerID p2p.ID) (added bool, err error
 	}
 
 	height := cs.Height
-	added, err = cs.Votes.AddVote(vote, peerID)
+	added, err = cs.Votes.AddVerifiedVote(vote, peerID, verifiedWith)
 	if !added {
 		// Either duplicate, or error upon cs.Votes.AddByIndex()
 		return
@@ -2341,11 +2560,28 @@ func (cs *State) signAddVote(msgType tmproto.SignedMsgType, hash []byte, header
 		return nil
 	}
 
+	if cs.shadow != nil {
+		addr := cs.privValidatorPubKey.Address()
+		valIdx, _ := cs.Validators.GetByAddress(addr)
+		cs.shadowSignVote(&types.Vote{
+			ValidatorAddress: addr,
+			ValidatorIndex:   valIdx,
+			Height:           cs.Height,
+			Round:            cs.Round,
+			Type:             msgType,
+			BlockID:          types.BlockID{Hash: hash, PartSetHeader: header},
+		})
+		return nil
+	}
+
 	// TODO: pass pubKey to signVote
 	vote, err := cs.signVote(msgType, hash, header)
 	if err == nil {
 		cs.sendInternalMessage(msgInfo{&VoteMessage{vote}, ""})
 		cs.Logger.Debug("signed and pushed vote", "height", cs.Height, "round", cs.Round, "vote", vote)
+		if msgType == tmproto.PrecommitType && len(hash) > 0 {
+			cs.extendVote(vote)
+		}
 		return vote
 	}
 
@@ -2354,14 +2590,21 @@ func (cs *State) signAddVote(msgType tmproto.SignedMsgType, hash []byte, header
 }
 
 // updatePrivValidatorPubKey get's the private validator public key and
-// memoizes it. This func returns an error if the private validator is not
-// responding or responds with an error.
+// memoizes it, the one of the current height if the key is rotated. This func
+// returns an error if the private validator is not responding or responds with
+// an error.
 func (cs *State) updatePrivValidatorPubKey() error {
 	if cs.privValidator == nil {
 		return nil
 	}
 
-	pubKey, err := cs.privValidator.GetPubKey()
+	var pubKey crypto.PubKey
+	var err error
+	if pv, ok := cs.privValidator.(types.KeyRotatingPrivValidator); ok {
+		pubKey, err = pv.GetPubKeyAt(cs.Height)
+	} else {
+		pubKey, err = cs.privValidator.GetPubKey()
+	}
 	if err != nil {
 		return err
 	}
@@ -2371,6 +2614,10 @@ func (cs *State) updatePrivValidatorPubKey() error {
 
 // look back to check existence of the node's consensus votes before joining consensus
 func (cs *State) checkDoubleSigningRisk(height int64) error {
+	// the validator is expected to be signing elsewhere in shadow mode
+	if cs.shadow != nil {
+		return nil
+	}
 	if cs.privValidator != nil && cs.privValidatorPubKey != nil && cs.config.DoubleSignCheckHeight > 0 && height > 0 {
 		valAddr := cs.privValidatorPubKey.Address()
 		doubleSignCheckHeight := cs.config.DoubleSignCheckHeight
@@ -2381,8 +2628,16 @@ func (cs *State) checkDoubleSigningRisk(height int64) error {
 		for i := int64(1); i < doubleSignCheckHeight; i++ {
 			lastCommit := cs.blockStore.LoadSeenCommit(height - i)
 			if lastCommit != nil {
+				// while the key is rotated, the signatures of both keys are
+				// looked for
+				addrs := [][]byte{valAddr}
+				if pv, ok := cs.privValidator.(types.KeyRotatingPrivValidator); ok {
+					if pubKey, err := pv.GetPubKeyAt(height - i); err == nil && !bytes.Equal(pubKey.Address(), valAddr) {
+						addrs = append(addrs, pubKey.Address())
+					}
+				}
 				for sigIdx, s := range lastCommit.Signatures {
-					if s.BlockIDFlag == types.BlockIDFlagCommit && bytes.Equal(s.ValidatorAddress, valAddr) {
+					if s.BlockIDFlag == types.BlockIDFlagCommit && containsAddress(addrs, s.ValidatorAddress) {
 						cs.Logger.Info("found signature from the same key", "sig", s, "idx", sigIdx, "height", height-i)
 						return ErrSignatureFoundInPastBlocks
 					}
@@ -2394,6 +2649,15 @@ func (cs *State) checkDoubleSigningRisk(height int64) error {
 	return nil
 }
 
+func containsAddress(addrs [][]byte, addr []byte) bool {
+	for _, a := range addrs {
+		if bytes.Equal(a, addr) {
+			return true
+		}
+	}
+	return false
+}
+
 func (cs *State) calculatePrevoteMessageDelayMetrics() {
 	if cs.Proposal == nil {
 		return
@@ -2442,7 +2706,8 @@ func CompareHRS(h1 int64, r1 int32, s1 cstypes.RoundStepType, h2 int64, r2 int32
 }
 
 // repairWalFile decodes messages from src (until the decoder errors) and
-// writes them to dst.
+// writes them to dst, atomically: dst is left untouched if the repair fails,
+// or is interrupted.
 func repairWalFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -2450,29 +2715,25 @@ func repairWalFile(src, dst string) error {
 	}
 	defer in.Close()
 
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	var (
-		dec = NewWALDecoder(in)
-		enc = NewWALEncoder(out)
-	)
+	return tempfile.WriteFileAtomicFunc(dst, 0o600, func(out io.Writer) error {
+		var (
+			dec = NewWALDecoder(in)
+			enc = NewWALEncoder(out)
+		)
 
-	// best-case repair (until first error is encountered)
-	for {
-		msg, err := dec.Decode()
-		if err != nil {
-			break
-		}
+		// best-case repair (until first error is encountered)
+		for {
+			msg, err := dec.Decode()
+			if err != nil {
+				break
+			}
 
-		err = enc.Encode(msg)
-		if err != nil {
-			return fmt.Errorf("failed to encode msg: %w", err)
+			err = enc.Encode(msg)
+			if err != nil {
+				return fmt.Errorf("failed to encode msg: %w", err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
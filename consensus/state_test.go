@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -18,11 +19,14 @@ import (
 	ocabci "github.com/Finschia/ostracon/abci/types"
 	"github.com/Finschia/ostracon/abci/types/mocks"
 	cstypes "github.com/Finschia/ostracon/consensus/types"
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	"github.com/Finschia/ostracon/libs/log"
 	tmpubsub "github.com/Finschia/ostracon/libs/pubsub"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	p2pmock "github.com/Finschia/ostracon/p2p/mock"
+	"github.com/Finschia/ostracon/privval"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -241,6 +245,70 @@ func TestStateBadProposal(t *testing.T) {
 	signAddVotes(cs1, tmproto.PrecommitType, propBlock.Hash(), propBlock.MakePartSet(partSize).Header(), vs2)
 }
 
+// processProposalApp answers ProcessProposal with its status, after its delay.
+type processProposalApp struct {
+	ocabci.Application
+	status ocabci.ResponseProcessProposal_ProposalStatus
+	delay  time.Duration
+}
+
+func (app *processProposalApp) ProcessProposal(req ocabci.RequestProcessProposal) ocabci.ResponseProcessProposal {
+	time.Sleep(app.delay)
+	return ocabci.ResponseProcessProposal{Status: app.status}
+}
+
+func TestStateProcessProposal(t *testing.T) {
+	testCases := map[string]struct {
+		status   ocabci.ResponseProcessProposal_ProposalStatus
+		delay    time.Duration
+		failOpen bool
+		prevoted bool
+	}{
+		"accepted":               {ocabci.ResponseProcessProposal_ACCEPT, 0, false, true},
+		"rejected":               {ocabci.ResponseProcessProposal_REJECT, 0, true, false},
+		"unknown":                {ocabci.ResponseProcessProposal_UNKNOWN, 0, false, false},
+		"timed out, fail-closed": {ocabci.ResponseProcessProposal_ACCEPT, 500 * time.Millisecond, false, false},
+		"timed out, fail-open":   {ocabci.ResponseProcessProposal_REJECT, 500 * time.Millisecond, true, true},
+	}
+	for desc, tc := range testCases {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			app := &processProposalApp{Application: counter.NewApplication(true), status: tc.status}
+			cs1, vss := randStateWithApp(2, app)
+			cs1.config.ProcessProposalTimeout = 50 * time.Millisecond
+			cs1.config.ProcessProposalFailOpen = tc.failOpen
+			height, round := cs1.Height, cs1.Round
+			vs2 := vss[1]
+
+			proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+			voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+
+			propBlock, propBlockParts := cs1.createProposalBlock(round)
+			app.delay = tc.delay
+
+			// make the second validator the proposer by incrementing round
+			round++
+			incrementRound(vss[1:]...)
+
+			blockID := types.BlockID{Hash: propBlock.Hash(), PartSetHeader: propBlockParts.Header()}
+			proposal := types.NewProposal(vs2.Height, round, -1, blockID)
+			p := proposal.ToProto()
+			require.NoError(t, vs2.SignProposal(config.ChainID(), p))
+			proposal.Signature = p.Signature
+			require.NoError(t, cs1.SetProposalAndBlock(proposal, propBlock, propBlockParts, "some peer"))
+
+			startTestRound(cs1, height, round)
+			ensureProposal(proposalCh, height, round, blockID)
+			ensurePrevote(voteCh, height, round)
+			if tc.prevoted {
+				validatePrevote(t, cs1, round, vss[0], propBlock.Hash())
+			} else {
+				validatePrevote(t, cs1, round, vss[0], nil)
+			}
+		})
+	}
+}
+
 func TestStateOversizedBlock(t *testing.T) {
 	cs1, vss := randState(2)
 	cs1.state.ConsensusParams.Block.MaxBytes = 2000
@@ -305,6 +373,45 @@ func TestStateOversizedBlock(t *testing.T) {
 // FullRoundSuite
 
 // propose, prevote, and precommit a block
+func TestStateWaitForCommit(t *testing.T) {
+	cs1, vss := randState(2)
+	vs2 := vss[1]
+	height, round := cs1.Height, cs1.Round
+
+	// not voting
+	require.NoError(t, cs1.WaitForCommit(context.Background()))
+
+	voteCh := subscribeUnBuffered(cs1.eventBus, types.EventQueryVote)
+
+	startTestRound(cs1, height, round)
+	ensurePrevote(voteCh, height, round)
+
+	// the height isn't committed without the votes of vs2
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cs1.WaitForCommit(ctx), context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cs1.WaitForCommit(context.Background())
+	}()
+	rs := cs1.GetRoundState()
+	propBlockHash, propPartSetHeader := rs.ProposalBlock.Hash(), rs.ProposalBlockParts.Header()
+	signAddVotes(cs1, tmproto.PrevoteType, propBlockHash, propPartSetHeader, vs2)
+	ensurePrevote(voteCh, height, round)
+	ensurePrecommit(voteCh, height, round)
+	signAddVotes(cs1, tmproto.PrecommitType, propBlockHash, propPartSetHeader, vs2)
+	ensurePrecommit(voteCh, height, round)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+		assert.Equal(t, height, cs1.GetLastHeight())
+	case <-time.After(ensureTimeout):
+		t.Fatal("Timeout expired while waiting for the height to be committed")
+	}
+}
+
 func TestStateFullRound1(t *testing.T) {
 	cs, vss := randState(1)
 	height, round := cs.Height, cs.Round
@@ -330,8 +437,17 @@ func TestStateFullRound1(t *testing.T) {
 
 	ensureNewRound(newRoundCh, height, round)
 
-	ensureNewProposal(propCh, height, round)
-	propBlockHash := cs.GetRoundState().ProposalBlock.Hash()
+	// the hash of the block is taken from the event, as the state may already
+	// be locked, publishing the prevote to the unbuffered voteCh
+	var propBlockHash []byte
+	select {
+	case msg := <-propCh:
+		proposalEvent := msg.Data().(types.EventDataCompleteProposal)
+		require.Equal(t, height, proposalEvent.Height)
+		propBlockHash = proposalEvent.BlockID.Hash
+	case <-time.After(ensureTimeout):
+		t.Fatal("Timeout expired while waiting for NewProposal event")
+	}
 
 	ensurePrevote(voteCh, height, round) // wait for prevote
 	validatePrevote(t, cs, round, vss[0], propBlockHash)
@@ -2057,6 +2173,45 @@ func TestStateOutputVoteStats(t *testing.T) {
 
 }
 
+func TestStateVerifyVotes(t *testing.T) {
+	cs, vss := randState(4)
+	randBytes := tmrand.Bytes(tmhash.Size)
+
+	// the signature of a vote for the current height is verified and its key
+	// kept for adding the vote
+	vote := signVote(vss[1], tmproto.PrevoteType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes})
+	cs.verifyVotes([]*types.Vote{vote})
+	pubKey, ok := cs.verifiedVotes.Load(vote)
+	require.True(t, ok)
+	assert.True(t, pubKey.(crypto.PubKey).Equals(cs.Validators.Validators[vote.ValidatorIndex].PubKey))
+
+	// but not the ones of the votes failing verification, or for another height
+	vote = signVote(vss[1], tmproto.PrecommitType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes})
+	vote.Signature = randBytes
+	cs.verifyVotes([]*types.Vote{vote})
+	_, ok = cs.verifiedVotes.Load(vote)
+	assert.False(t, ok)
+
+	incrementHeight(vss[1])
+	vote = signVote(vss[1], tmproto.PrevoteType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes})
+	cs.verifyVotes([]*types.Vote{vote})
+	_, ok = cs.verifiedVotes.Load(vote)
+	assert.False(t, ok)
+
+	// the votes verified in a batch, with an invalid one
+	votes := []*types.Vote{
+		signVote(vss[2], tmproto.PrevoteType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes}),
+		signVote(vss[3], tmproto.PrevoteType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes}),
+		signVote(vss[2], tmproto.PrecommitType, randBytes, types.PartSetHeader{Total: 1, Hash: randBytes}),
+	}
+	votes[1].Signature = randBytes
+	cs.verifyVotes(votes)
+	for i, vote := range votes {
+		_, ok = cs.verifiedVotes.Load(vote)
+		assert.Equal(t, i != 1, ok, i)
+	}
+}
+
 func TestSignSameVoteTwice(t *testing.T) {
 	_, vss := randState(2)
 
@@ -2077,9 +2232,44 @@ func TestSignSameVoteTwice(t *testing.T) {
 	require.Equal(t, vote, vote2)
 }
 
-// subscribe subscribes test client to the given query and returns a channel with cap = 1.
+// subscribe subscribes test client to the given query and returns a channel
+// with cap = testSubscriptionCapacity, so that the events published in a burst
+// don't terminate the subscription before the test reads them.
+// the validator signs with the key staged for the height from which the
+// validator set has it
+func TestStateKeyRotation(t *testing.T) {
+	state, privVals := randGenesisState(1, false, 10)
+	state.LastProofHash = []byte{2}
+	dir := t.TempDir()
+	pv := privval.NewFilePV(privVals[0].(types.MockPV).PrivKey,
+		filepath.Join(dir, "priv_validator_key.json"), filepath.Join(dir, "priv_validator_state.json"))
+	oldAddr := pv.GetAddress()
+	newKey := ed25519.GenPrivKey()
+	require.NoError(t, pv.StageKey(newKey, 2))
+	state.NextValidators = types.NewValidatorSet([]*types.Validator{types.NewValidator(newKey.PubKey(), 10)})
+
+	cs1 := newState(state, pv, counter.NewApplication(true))
+	newBlockCh := subscribe(cs1.eventBus, types.EventQueryNewBlock)
+	startTestRound(cs1, 1, 0)
+	defer func() { _ = cs1.Stop() }()
+	for height := int64(1); height <= 3; height++ {
+		ensureNewBlock(newBlockCh, height)
+	}
+
+	block1, block2, block3 := cs1.blockStore.LoadBlock(1), cs1.blockStore.LoadBlock(2), cs1.blockStore.LoadBlock(3)
+	assert.Equal(t, oldAddr, block1.ProposerAddress)
+	assert.Equal(t, oldAddr, block2.LastCommit.Signatures[0].ValidatorAddress)
+	assert.Equal(t, newKey.PubKey().Address(), block2.ProposerAddress)
+	assert.Equal(t, newKey.PubKey().Address(), block3.LastCommit.Signatures[0].ValidatorAddress)
+
+	// the signatures of the previous key are looked for too before joining the
+	// consensus
+	cs1.config.DoubleSignCheckHeight = 10
+	assert.ErrorIs(t, cs1.checkDoubleSigningRisk(2), ErrSignatureFoundInPastBlocks)
+}
+
 func subscribe(eventBus *types.EventBus, q tmpubsub.Query) <-chan tmpubsub.Message {
-	sub, err := eventBus.Subscribe(context.Background(), testSubscriber, q)
+	sub, err := eventBus.Subscribe(context.Background(), testSubscriber, q, testSubscriptionCapacity)
 	if err != nil {
 		panic(fmt.Sprintf("failed to subscribe %s to %v", testSubscriber, q))
 	}
@@ -2102,6 +2292,12 @@ func TestPruneBlocks(t *testing.T) {
 	mockApp.On("EndBlock", mock.Anything).Return(abci.ResponseEndBlock{})
 	mockApp.On("BeginRecheckTx", mock.Anything).Return(ocabci.ResponseBeginRecheckTx{Code: ocabci.CodeTypeOK})
 	mockApp.On("EndRecheckTx", mock.Anything).Return(ocabci.ResponseEndRecheckTx{Code: ocabci.CodeTypeOK})
+	mockApp.On("PrepareProposal", mock.Anything).Return(
+		func(req ocabci.RequestPrepareProposal) ocabci.ResponsePrepareProposal {
+			return ocabci.ResponsePrepareProposal{Txs: req.Txs}
+		})
+	mockApp.On("ProcessProposal", mock.Anything).Return(
+		ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_ACCEPT})
 	// Mocking behaviour to response `RetainHeight` for pruneBlocks
 	mockApp.On("Commit", mock.Anything, mock.Anything).Return(abci.ResponseCommit{RetainHeight: 1})
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
 )
 
 var (
@@ -100,16 +101,8 @@ func (t *timeoutTicker) timeoutRoutine() {
 			t.Logger.Debug("Received tick", "old_ti", ti, "new_ti", newti)
 
 			// ignore tickers for old height/round/step
-			if newti.Height < ti.Height {
+			if isStaleTimeout(newti, ti) {
 				continue
-			} else if newti.Height == ti.Height {
-				if newti.Round < ti.Round {
-					continue
-				} else if newti.Round == ti.Round {
-					if ti.Step > 0 && newti.Step <= ti.Step {
-						continue
-					}
-				}
 			}
 
 			// stop the last timer
@@ -132,3 +125,91 @@ func (t *timeoutTicker) timeoutRoutine() {
 		}
 	}
 }
+
+// isStaleTimeout returns whether newti is for a height/round/step which is not
+// later than the one of the last scheduled timeout ti.
+func isStaleTimeout(newti, ti timeoutInfo) bool {
+	if newti.Height != ti.Height {
+		return newti.Height < ti.Height
+	}
+	if newti.Round != ti.Round {
+		return newti.Round < ti.Round
+	}
+	return ti.Step > 0 && newti.Step <= ti.Step
+}
+
+//-------------------------------------------------------------
+
+// ManualTimeoutTicker is a TimeoutTicker whose clock only moves when advanced
+// with Advance, instead of following the wall clock. The timeouts fire once the
+// clock has moved past them, which makes the consensus progress deterministic
+// in tests.
+type ManualTimeoutTicker struct {
+	service.BaseService
+
+	mtx      tmsync.Mutex
+	now      time.Duration // time elapsed on the clock
+	ti       timeoutInfo   // last scheduled timeout
+	deadline time.Duration // when ti fires
+	pending  bool          // whether ti has yet to fire
+
+	tockChan chan timeoutInfo // for notifying about timeouts
+}
+
+var _ TimeoutTicker = (*ManualTimeoutTicker)(nil)
+
+// NewManualTimeoutTicker returns a new ManualTimeoutTicker.
+func NewManualTimeoutTicker() *ManualTimeoutTicker {
+	t := &ManualTimeoutTicker{
+		tockChan: make(chan timeoutInfo, tickTockBufferSize),
+	}
+	t.BaseService = *service.NewBaseService(nil, "ManualTimeoutTicker", t)
+	return t
+}
+
+// Chan returns a channel on which timeouts are sent.
+func (t *ManualTimeoutTicker) Chan() <-chan timeoutInfo {
+	return t.tockChan
+}
+
+// ScheduleTimeout schedules a new timeout, replacing the last one unless it was
+// for a later height/round/step. Non-positive timeouts fire immediately.
+func (t *ManualTimeoutTicker) ScheduleTimeout(newti timeoutInfo) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if isStaleTimeout(newti, t.ti) {
+		return
+	}
+	t.ti = newti
+	t.deadline = t.now + newti.Duration
+	t.pending = true
+	t.fire()
+}
+
+// Advance moves the clock forward by d, firing the scheduled timeout if it is
+// due.
+func (t *ManualTimeoutTicker) Advance(d time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.now += d
+	t.fire()
+}
+
+// fire sends the scheduled timeout if it is due. Must be called with the lock
+// held.
+func (t *ManualTimeoutTicker) fire() {
+	if !t.pending || t.deadline > t.now {
+		return
+	}
+	t.pending = false
+	t.Logger.Info("Timed out", "dur", t.ti.Duration, "height", t.ti.Height, "round", t.ti.Round, "step", t.ti.Step)
+	// go routine here guarantees ScheduleTimeout doesn't block the receiveRoutine.
+	go func(toi timeoutInfo) {
+		select {
+		case t.tockChan <- toi:
+		case <-t.Quit():
+		}
+	}(t.ti)
+}
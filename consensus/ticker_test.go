@@ -0,0 +1,45 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cstypes "github.com/Finschia/ostracon/consensus/types"
+)
+
+func TestManualTimeoutTicker(t *testing.T) {
+	ticker := NewManualTimeoutTicker()
+	require.NoError(t, ticker.Start())
+	defer ticker.Stop() //nolint:errcheck // ignore for tests
+
+	expectTimeout := func(want *timeoutInfo) {
+		t.Helper()
+		select {
+		case ti := <-ticker.Chan():
+			require.NotNil(t, want, "unexpected timeout %v", ti)
+			assert.Equal(t, *want, ti)
+		case <-time.After(50 * time.Millisecond):
+			require.Nil(t, want, "expected timeout %v", want)
+		}
+	}
+
+	ti := timeoutInfo{Duration: time.Second, Height: 1, Round: 0, Step: cstypes.RoundStepPropose}
+	ticker.ScheduleTimeout(ti)
+	expectTimeout(nil)
+	ticker.Advance(999 * time.Millisecond)
+	expectTimeout(nil)
+	ticker.Advance(time.Millisecond)
+	expectTimeout(&ti)
+
+	// timeouts for old steps are ignored
+	ticker.ScheduleTimeout(timeoutInfo{Duration: 0, Height: 1, Round: 0, Step: cstypes.RoundStepNewHeight})
+	expectTimeout(nil)
+
+	// non-positive timeouts fire immediately
+	ti = timeoutInfo{Duration: 0, Height: 2, Round: 0, Step: cstypes.RoundStepNewHeight}
+	ticker.ScheduleTimeout(ti)
+	expectTimeout(&ti)
+}
@@ -0,0 +1,96 @@
+package consensus
+
+import (
+	"time"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	// the adaptive timeouts are this many times the average durations of the
+	// steps, leaving room for the slower rounds
+	adaptiveTimeoutFactor = 2
+	// the weight of the last duration of a step in its moving average, out of 1
+	adaptiveTimeoutWeight = 0.2
+)
+
+// stepTimeouts are the timeouts of the propose, prevote and precommit steps
+// by round.
+type stepTimeouts interface {
+	Propose(round int32) time.Duration
+	Prevote(round int32) time.Duration
+	Precommit(round int32) time.Duration
+}
+
+// adaptiveTimeouts adapts the timeouts of the propose, prevote and precommit
+// steps to the moving averages of the durations these steps took in the
+// rounds committing a block (see cfg.ConsensusConfig.TimeoutAdaptive). It is
+// only accessed by the receive routine of the State.
+type adaptiveTimeouts struct {
+	config *cfg.ConsensusConfig
+	// the average durations of the steps, 0 until observed
+	propose   time.Duration
+	prevote   time.Duration
+	precommit time.Duration
+}
+
+func newAdaptiveTimeouts(config *cfg.ConsensusConfig) *adaptiveTimeouts {
+	return &adaptiveTimeouts{config: config}
+}
+
+// observe adds the durations of the steps of the round which committed the
+// last block. The propose step isn't observed when the proposer waits for
+// transactions, as it then lasts until there are some.
+func (a *adaptiveTimeouts) observe(st *StepTimes) {
+	if !a.config.WaitForTxs() {
+		a.propose = movingAverage(a.propose, st.Proposal)
+	}
+	a.prevote = movingAverage(a.prevote, st.Prevote)
+	a.precommit = movingAverage(a.precommit, st.Precommit)
+}
+
+func movingAverage(avg time.Duration, step types.StepDuration) time.Duration {
+	if !step.End.After(step.Start) {
+		return avg
+	}
+	d := step.End.Sub(step.Start)
+	if avg == 0 {
+		return d
+	}
+	return avg + time.Duration(adaptiveTimeoutWeight*float64(d-avg))
+}
+
+// Propose returns the amount of time to wait for a proposal in the round.
+func (a *adaptiveTimeouts) Propose(round int32) time.Duration {
+	return a.adapt(a.propose, a.config.Propose(0)) + a.config.Propose(round) - a.config.Propose(0)
+}
+
+// Prevote returns the amount of time to wait for straggler votes after
+// receiving any +2/3 prevotes in the round.
+func (a *adaptiveTimeouts) Prevote(round int32) time.Duration {
+	return a.adapt(a.prevote, a.config.Prevote(0)) + a.config.Prevote(round) - a.config.Prevote(0)
+}
+
+// Precommit returns the amount of time to wait for straggler votes after
+// receiving any +2/3 precommits in the round.
+func (a *adaptiveTimeouts) Precommit(round int32) time.Duration {
+	return a.adapt(a.precommit, a.config.Precommit(0)) + a.config.Precommit(round) - a.config.Precommit(0)
+}
+
+// adapt returns the timeout of a step of the average duration, bounded by
+// TimeoutAdaptiveMin and TimeoutAdaptiveMax, or the timeout configured as
+// long as the step hasn't been observed.
+func (a *adaptiveTimeouts) adapt(avg, configured time.Duration) time.Duration {
+	if avg == 0 {
+		return configured
+	}
+	timeout := adaptiveTimeoutFactor * avg
+	if timeout < a.config.TimeoutAdaptiveMin {
+		return a.config.TimeoutAdaptiveMin
+	}
+	if timeout > a.config.TimeoutAdaptiveMax {
+		return a.config.TimeoutAdaptiveMax
+	}
+	return timeout
+}
@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/types"
+)
+
+func stepDuration(d time.Duration) types.StepDuration {
+	start := time.Unix(0, 0)
+	return types.StepDuration{Start: start, End: start.Add(d)}
+}
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	config := cfg.DefaultConsensusConfig()
+	config.TimeoutAdaptive = true
+	config.TimeoutAdaptiveMin = 100 * time.Millisecond
+	config.TimeoutAdaptiveMax = 2 * time.Second
+	a := newAdaptiveTimeouts(config)
+
+	// the timeouts configured until the steps are observed
+	assert.Equal(t, config.Propose(1), a.Propose(1))
+	assert.Equal(t, config.Prevote(1), a.Prevote(1))
+	assert.Equal(t, config.Precommit(1), a.Precommit(1))
+
+	a.observe(&StepTimes{
+		Proposal:  stepDuration(400 * time.Millisecond),
+		Prevote:   stepDuration(10 * time.Millisecond),
+		Precommit: stepDuration(5 * time.Second),
+	})
+	assert.Equal(t, 800*time.Millisecond, a.Propose(0))
+	assert.Equal(t, 800*time.Millisecond+config.TimeoutProposeDelta, a.Propose(1))
+	// bounded by the min and the max
+	assert.Equal(t, 100*time.Millisecond, a.Prevote(0))
+	assert.Equal(t, 2*time.Second, a.Precommit(0))
+
+	// moving average
+	a.observe(&StepTimes{
+		Proposal:  stepDuration(900 * time.Millisecond),
+		Prevote:   stepDuration(10 * time.Millisecond),
+		Precommit: stepDuration(5 * time.Second),
+	})
+	assert.Equal(t, 1000*time.Millisecond, a.Propose(0))
+
+	// steps which didn't end aren't observed
+	a.observe(&StepTimes{Proposal: types.StepDuration{Start: time.Unix(1, 0)}})
+	assert.Equal(t, 1000*time.Millisecond, a.Propose(0))
+}
+
+func TestAdaptiveTimeoutsWaitForTxs(t *testing.T) {
+	config := cfg.DefaultConsensusConfig()
+	config.TimeoutAdaptive = true
+	config.CreateEmptyBlocks = false
+	a := newAdaptiveTimeouts(config)
+
+	// the propose step lasts until there are transactions
+	a.observe(&StepTimes{
+		Proposal: stepDuration(time.Minute),
+		Prevote:  stepDuration(200 * time.Millisecond),
+	})
+	assert.Equal(t, config.Propose(0), a.Propose(0))
+	assert.Equal(t, 400*time.Millisecond, a.Prevote(0))
+}
@@ -8,6 +8,7 @@ import (
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	"github.com/Finschia/ostracon/crypto"
 	tmjson "github.com/Finschia/ostracon/libs/json"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	"github.com/Finschia/ostracon/p2p"
@@ -116,6 +117,15 @@ func (hvs *HeightVoteSet) addRound(round int32) {
 // Duplicate votes return added=false, err=nil.
 // By convention, peerID is "" if origin is self.
 func (hvs *HeightVoteSet) AddVote(vote *types.Vote, peerID p2p.ID) (added bool, err error) {
+	return hvs.AddVerifiedVote(vote, peerID, nil)
+}
+
+// AddVerifiedVote adds the vote like AddVote, but doesn't verify its signature
+// again if it was verified with pubKey beforehand, see
+// types.VoteSet.AddVerifiedVote.
+func (hvs *HeightVoteSet) AddVerifiedVote(
+	vote *types.Vote, peerID p2p.ID, pubKey crypto.PubKey,
+) (added bool, err error) {
 	hvs.mtx.Lock()
 	defer hvs.mtx.Unlock()
 	if !types.IsVoteTypeValid(vote.Type) {
@@ -133,7 +143,7 @@ func (hvs *HeightVoteSet) AddVote(vote *types.Vote, peerID p2p.ID) (added bool,
 			return
 		}
 	}
-	added, err = voteSet.AddVote(vote)
+	added, err = voteSet.AddVerifiedVote(vote, pubKey)
 	return
 }
 
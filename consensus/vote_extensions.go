@@ -0,0 +1,298 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/p2p"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// a validator precommits a few blocks at a height at most, one by round, so
+// the extensions of a validator for more blocks are dropped
+const maxVoteExtensionsPerValidator = 4
+
+var errVoteExtensionRejected = errors.New("vote extension rejected by the application")
+
+// voteExtensionStore holds the vote extensions of the validators for the
+// blocks of the current and the last heights, those of the last height being
+// given to the application preparing the proposal of the current one.
+type voteExtensionStore struct {
+	mtx tmsync.Mutex
+	// by height, then by validator address
+	extensions map[int64]map[string][]*types.VoteExtension
+}
+
+func newVoteExtensionStore() *voteExtensionStore {
+	return &voteExtensionStore{
+		extensions: make(map[int64]map[string][]*types.VoteExtension),
+	}
+}
+
+// has returns true if the extension of the validator for the block is stored.
+func (s *voteExtensionStore) has(ext *types.VoteExtension) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, e := range s.extensions[ext.Height][string(ext.ValidatorAddress)] {
+		if bytes.Equal(e.BlockHash, ext.BlockHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// add stores the extension, returning false if the validator already has one
+// for the block, or too many at the height.
+func (s *voteExtensionStore) add(ext *types.VoteExtension) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	byValidator, ok := s.extensions[ext.Height]
+	if !ok {
+		byValidator = make(map[string][]*types.VoteExtension)
+		s.extensions[ext.Height] = byValidator
+	}
+	exts := byValidator[string(ext.ValidatorAddress)]
+	if len(exts) >= maxVoteExtensionsPerValidator {
+		return false
+	}
+	for _, e := range exts {
+		if bytes.Equal(e.BlockHash, ext.BlockHash) {
+			return false
+		}
+	}
+	byValidator[string(ext.ValidatorAddress)] = append(exts, ext)
+	return true
+}
+
+// get returns the extensions of the validators for the block.
+func (s *voteExtensionStore) get(height int64, blockHash []byte) []*types.VoteExtension {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var exts []*types.VoteExtension
+	for _, byBlock := range s.extensions[height] {
+		for _, e := range byBlock {
+			if bytes.Equal(e.BlockHash, blockHash) {
+				exts = append(exts, e)
+			}
+		}
+	}
+	return exts
+}
+
+// list returns the extensions of the validators at the height.
+func (s *voteExtensionStore) list(height int64) []*types.VoteExtension {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var exts []*types.VoteExtension
+	for _, byBlock := range s.extensions[height] {
+		exts = append(exts, byBlock...)
+	}
+	return exts
+}
+
+// prune removes the extensions of the heights before the height.
+func (s *voteExtensionStore) prune(height int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for h := range s.extensions {
+		if h < height {
+			delete(s.extensions, h)
+		}
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+// extendVote asks the application for the extension of the precommit of the
+// validator for a block, then signs and stores it, the reactor gossiping it.
+// The votes aren't extended if the private validator can't sign the
+// extensions.
+func (cs *State) extendVote(vote *types.Vote) {
+	signer, ok := cs.privValidator.(types.VoteExtensionSigner)
+	if !ok {
+		return
+	}
+	extension, err := cs.blockExec.ExtendVote(vote)
+	if err != nil {
+		cs.Logger.Error("failed to extend the vote", "height", vote.Height, "round", vote.Round, "err", err)
+		return
+	}
+	if len(extension) == 0 {
+		return
+	}
+
+	pb := &ocproto.VoteExtension{
+		Height:           vote.Height,
+		BlockHash:        vote.BlockID.Hash,
+		ValidatorAddress: vote.ValidatorAddress,
+		Extension:        extension,
+	}
+	if err := signer.SignVoteExtension(cs.state.ChainID, pb); err != nil {
+		cs.Logger.Error("failed signing vote extension", "height", vote.Height, "round", vote.Round, "err", err)
+		return
+	}
+	ext, err := types.VoteExtensionFromProto(pb)
+	if err != nil {
+		cs.Logger.Error("invalid vote extension", "height", vote.Height, "round", vote.Round, "err", err)
+		return
+	}
+	cs.voteExtensions.add(ext)
+}
+
+// verifyVoteExtension verifies the signature of the vote extension received
+// from a peer, for the current or the last height, off the consensus routine.
+// It returns false if the extension is already stored or isn't for these
+// heights.
+func (cs *State) verifyVoteExtension(ext *types.VoteExtension) (bool, error) {
+	cs.mtx.RLock()
+	chainID := cs.state.ChainID
+	valSet := cs.voteExtensionValidators(ext)
+	cs.mtx.RUnlock()
+
+	if valSet == nil || cs.voteExtensions.has(ext) {
+		return false, nil
+	}
+	_, val := valSet.GetByAddress(ext.ValidatorAddress)
+	if val == nil {
+		return false, fmt.Errorf("vote extension of %X, not a validator at height %d", ext.ValidatorAddress, ext.Height)
+	}
+	if err := ext.Verify(chainID, val.PubKey); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// addVoteExtension asks the application to verify the vote extension whose
+// signature was verified, and stores it. It runs on the consensus routine, not
+// to interleave the requests of the application with the execution of the
+// blocks. It returns false if the extension is already stored or no longer
+// for the current or the last height.
+func (cs *State) addVoteExtension(ext *types.VoteExtension) (bool, error) {
+	if cs.voteExtensionValidators(ext) == nil || cs.voteExtensions.has(ext) {
+		return false, nil
+	}
+	ok, err := cs.blockExec.VerifyVoteExtension(ext)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errVoteExtensionRejected
+	}
+	return cs.voteExtensions.add(ext), nil
+}
+
+// handleVoteExtension adds the vote extension queued to voteExtensionQueue.
+func (cs *State) handleVoteExtension(ext *types.VoteExtension) {
+	if _, err := cs.addVoteExtension(ext); err != nil {
+		cs.Logger.Debug("Ignoring vote extension", "ext", ext, "err", err)
+	}
+}
+
+// voteExtensionValidators returns the validators of the height of the vote
+// extension, nil if it's not the current or the last height.
+func (cs *State) voteExtensionValidators(ext *types.VoteExtension) *types.ValidatorSet {
+	switch ext.Height {
+	case cs.Height:
+		return cs.Validators
+	case cs.Height - 1:
+		return cs.LastValidators
+	}
+	return nil
+}
+
+// lastVoteExtensions returns the vote extensions for the last block of the
+// validators whose precommit for it is in the commit, the one of the
+// proposal.
+func (cs *State) lastVoteExtensions(commit *types.Commit) []*types.VoteExtension {
+	if cs.state.LastBlockHeight == 0 {
+		return nil
+	}
+	committed := make(map[string]bool, len(commit.Signatures))
+	for _, sig := range commit.Signatures {
+		if sig.ForBlock() {
+			committed[string(sig.ValidatorAddress)] = true
+		}
+	}
+	var exts []*types.VoteExtension
+	for _, ext := range cs.voteExtensions.get(cs.state.LastBlockHeight, cs.state.LastBlockID.Hash) {
+		if committed[string(ext.ValidatorAddress)] {
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+//-----------------------------------------------------------------------------
+
+// receiveVoteExtension verifies the signature of the vote extension received
+// from the peer with the pool, dropping it if the pool is full, and queues it
+// to the consensus routine if it's new.
+func (conR *Reactor) receiveVoteExtension(e p2p.Envelope) {
+	if conR.WaitSync() {
+		return
+	}
+	pb, ok := e.Message.(*ocproto.VoteExtension)
+	if !ok {
+		conR.Logger.Error("Unknown message type", "type", fmt.Sprintf("%T", e.Message))
+		return
+	}
+	ext, err := types.VoteExtensionFromProto(pb)
+	if err != nil {
+		conR.Logger.Error("Peer sent us invalid vote extension", "peer", e.Src, "err", err)
+		conR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
+		return
+	}
+	if ps, ok := e.Src.Get(types.PeerStateKey).(*PeerState); ok {
+		ps.SetHasVoteExtension(ext)
+	}
+
+	conR.voteVerifyPool.TrySubmit(func(ctx context.Context) {
+		ok, err := conR.conS.verifyVoteExtension(ext)
+		switch {
+		case errors.Is(err, types.ErrVoteExtensionInvalidSignature):
+			// the peers only relay the extensions whose signature they
+			// verified
+			conR.Logger.Error("Peer sent us vote extension with invalid signature", "peer", e.Src, "ext", ext)
+			conR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
+		case err != nil:
+			conR.Logger.Debug("Ignoring vote extension", "peer", e.Src, "ext", ext, "err", err)
+		case ok:
+			select {
+			case conR.conS.voteExtensionQueue <- ext:
+			case <-ctx.Done():
+			}
+		}
+	})
+}
+
+// gossipVoteExtensionsRoutine sends the vote extensions of the current and
+// the last heights to the peer, those it doesn't have, so that the peers
+// connecting late get them too.
+func (conR *Reactor) gossipVoteExtensionsRoutine(peer p2p.Peer, ps *PeerState) {
+	for {
+		if !peer.IsRunning() || !conR.IsRunning() {
+			return
+		}
+		height := conR.getRoundState().Height
+		ps.pruneVoteExtensions(height - 1)
+		for _, h := range []int64{height - 1, height} {
+			for _, ext := range conR.conS.voteExtensions.list(h) {
+				if ps.HasVoteExtension(ext) {
+					continue
+				}
+				if p2p.SendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
+					ChannelID: VoteExtensionChannel,
+					Message:   ext.ToProto(),
+				}, conR.Logger) {
+					ps.SetHasVoteExtension(ext)
+				}
+			}
+		}
+		time.Sleep(conR.conS.config.PeerGossipSleepDuration)
+	}
+}
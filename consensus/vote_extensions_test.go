@@ -0,0 +1,221 @@
+package consensus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/abci/example/counter"
+	ocabci "github.com/Finschia/ostracon/abci/types"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	"github.com/Finschia/ostracon/libs/log"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+	p2pmocks "github.com/Finschia/ostracon/p2p/mocks"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// voteExtensionApp extends the votes with their height, rejects the
+// extensions after reject, and records the vote extensions of the proposals
+// it prepares.
+type voteExtensionApp struct {
+	ocabci.Application
+	reject    bool
+	proposals chan []ocabci.ExtendedVoteInfo
+}
+
+func (app *voteExtensionApp) ExtendVote(req ocabci.RequestExtendVote) ocabci.ResponseExtendVote {
+	return ocabci.ResponseExtendVote{VoteExtension: []byte(fmt.Sprintf("height %d", req.Height))}
+}
+
+func (app *voteExtensionApp) VerifyVoteExtension(
+	req ocabci.RequestVerifyVoteExtension) ocabci.ResponseVerifyVoteExtension {
+	if app.reject {
+		return ocabci.ResponseVerifyVoteExtension{Status: ocabci.ResponseVerifyVoteExtension_REJECT}
+	}
+	return ocabci.ResponseVerifyVoteExtension{Status: ocabci.ResponseVerifyVoteExtension_ACCEPT}
+}
+
+func (app *voteExtensionApp) PrepareProposal(req ocabci.RequestPrepareProposal) ocabci.ResponsePrepareProposal {
+	select {
+	case app.proposals <- req.VoteExtensions:
+	default:
+	}
+	return app.Application.PrepareProposal(req)
+}
+
+func signedVoteExtension(t *testing.T, vs *validatorStub, height int64, blockHash []byte) *types.VoteExtension {
+	pubKey, err := vs.GetPubKey()
+	require.NoError(t, err)
+	pb := &ocproto.VoteExtension{
+		Height:           height,
+		BlockHash:        blockHash,
+		ValidatorAddress: pubKey.Address(),
+		Extension:        []byte("extension"),
+	}
+	require.NoError(t, vs.PrivValidator.(types.VoteExtensionSigner).SignVoteExtension(config.ChainID(), pb))
+	ext, err := types.VoteExtensionFromProto(pb)
+	require.NoError(t, err)
+	return ext
+}
+
+func TestVoteExtensionStore(t *testing.T) {
+	store := newVoteExtensionStore()
+	addr := tmrand.Bytes(20)
+	hash := tmhash.Sum([]byte("block"))
+	ext := &types.VoteExtension{Height: 2, BlockHash: hash, ValidatorAddress: addr}
+
+	assert.False(t, store.has(ext))
+	assert.True(t, store.add(ext))
+	assert.True(t, store.has(ext))
+	assert.False(t, store.add(ext))
+
+	// the extensions of a validator are limited by height
+	for i := 1; i < maxVoteExtensionsPerValidator; i++ {
+		assert.True(t, store.add(&types.VoteExtension{Height: 2, BlockHash: tmrand.Bytes(32), ValidatorAddress: addr}))
+	}
+	assert.False(t, store.add(&types.VoteExtension{Height: 2, BlockHash: tmrand.Bytes(32), ValidatorAddress: addr}))
+	assert.True(t, store.add(&types.VoteExtension{Height: 2, BlockHash: hash, ValidatorAddress: tmrand.Bytes(20)}))
+	assert.True(t, store.add(&types.VoteExtension{Height: 3, BlockHash: hash, ValidatorAddress: addr}))
+
+	assert.Len(t, store.get(2, hash), 2)
+	assert.Len(t, store.get(3, hash), 1)
+	assert.Empty(t, store.get(4, hash))
+	assert.Len(t, store.list(2), maxVoteExtensionsPerValidator+1)
+
+	store.prune(3)
+	assert.Empty(t, store.get(2, hash))
+	assert.Len(t, store.get(3, hash), 1)
+}
+
+func TestStateExtendVote(t *testing.T) {
+	app := &voteExtensionApp{
+		Application: counter.NewApplication(true),
+		proposals:   make(chan []ocabci.ExtendedVoteInfo, 1),
+	}
+	cs1, vss := randStateWithApp(1, app)
+	height, round := cs1.Height, cs1.Round
+
+	newBlockCh := subscribe(cs1.eventBus, types.EventQueryNewBlock)
+	startTestRound(cs1, height, round)
+	ensureNewBlock(newBlockCh, height)
+	<-app.proposals // no vote extensions at the first height
+
+	// the precommit for the first block is extended
+	blockHash := cs1.GetState().LastBlockID.Hash
+	exts := cs1.voteExtensions.get(height, blockHash)
+	require.Len(t, exts, 1)
+	pubKey, err := vss[0].GetPubKey()
+	require.NoError(t, err)
+	assert.NoError(t, exts[0].Verify(config.ChainID(), pubKey))
+	assert.Equal(t, []byte(fmt.Sprintf("height %d", height)), exts[0].Extension)
+
+	// and given to the application preparing the next proposal
+	ensureNewBlock(newBlockCh, height+1)
+	infos := <-app.proposals
+	require.Len(t, infos, 1)
+	assert.Equal(t, pubKey.Address().Bytes(), infos[0].ValidatorAddress)
+	assert.Equal(t, vss[0].VotingPower, infos[0].Power)
+	assert.Equal(t, exts[0].Extension, infos[0].VoteExtension)
+	assert.Equal(t, exts[0].Signature, infos[0].ExtensionSignature)
+}
+
+func TestStateAddVoteExtension(t *testing.T) {
+	app := &voteExtensionApp{Application: counter.NewApplication(true)}
+	cs1, vss := randStateWithApp(2, app)
+	vs2 := vss[1]
+	height := cs1.Height
+	hash := tmhash.Sum([]byte("block"))
+
+	ext := signedVoteExtension(t, vs2, height, hash)
+	ok, err := cs1.verifyVoteExtension(ext)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	added, err := cs1.addVoteExtension(ext)
+	require.NoError(t, err)
+	assert.True(t, added)
+	ok, err = cs1.verifyVoteExtension(ext)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	added, err = cs1.addVoteExtension(ext)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	// the extensions of other heights are ignored
+	ok, err = cs1.verifyVoteExtension(signedVoteExtension(t, vs2, height+1, hash))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	added, err = cs1.addVoteExtension(signedVoteExtension(t, vs2, height+1, hash))
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	invalid := signedVoteExtension(t, vs2, height, tmhash.Sum([]byte("other block")))
+	invalid.Extension = []byte("modified")
+	_, err = cs1.verifyVoteExtension(invalid)
+	assert.ErrorIs(t, err, types.ErrVoteExtensionInvalidSignature)
+
+	nonValidator := newValidatorStub(types.NewMockPV(), 2)
+	_, err = cs1.verifyVoteExtension(signedVoteExtension(t, nonValidator, height, hash))
+	assert.Error(t, err)
+
+	app.reject = true
+	_, err = cs1.addVoteExtension(signedVoteExtension(t, vs2, height, tmhash.Sum([]byte("other block"))))
+	assert.ErrorIs(t, err, errVoteExtensionRejected)
+}
+
+func TestStateLastVoteExtensions(t *testing.T) {
+	cs1, vss := randState(2)
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block"))}
+	cs1.state.LastBlockHeight = cs1.Height
+	cs1.state.LastBlockID = blockID
+	for _, vs := range vss {
+		require.True(t, cs1.voteExtensions.add(signedVoteExtension(t, vs, cs1.state.LastBlockHeight, blockID.Hash)))
+	}
+
+	// only the extensions of the validators whose precommit is in the commit
+	// are given to the application
+	pubKey, err := vss[1].GetPubKey()
+	require.NoError(t, err)
+	commit := types.NewCommit(cs1.state.LastBlockHeight, 0, blockID, []types.CommitSig{
+		types.NewCommitSigAbsent(),
+		{BlockIDFlag: types.BlockIDFlagCommit, ValidatorAddress: pubKey.Address()},
+	})
+	exts := cs1.lastVoteExtensions(commit)
+	require.Len(t, exts, 1)
+	assert.Equal(t, pubKey.Address(), exts[0].ValidatorAddress)
+}
+
+func TestReactorGossipVoteExtensions(t *testing.T) {
+	cs1, vss := randState(2)
+	reactor := NewReactor(cs1, true, false, 1000)
+	reactor.SetLogger(log.TestingLogger())
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { _ = reactor.Stop() })
+
+	ext := signedVoteExtension(t, vss[1], cs1.Height, tmhash.Sum([]byte("block")))
+	require.True(t, cs1.voteExtensions.add(ext))
+
+	// the extensions stored are sent to the peers, once
+	peer := p2pmocks.NewPeer(t)
+	ps := NewPeerState(peer)
+	sent := make(chan struct{}, 2)
+	peer.On("IsRunning").Return(true)
+	peer.On("Send", VoteExtensionChannel, mock.Anything).Return(true).Run(func(mock.Arguments) { sent <- struct{}{} })
+	go reactor.gossipVoteExtensionsRoutine(peer, ps)
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("the vote extension wasn't sent")
+	}
+	assert.True(t, ps.HasVoteExtension(ext))
+	select {
+	case <-sent:
+		t.Fatal("the vote extension was sent twice")
+	case <-time.After(3 * config.Consensus.PeerGossipSleepDuration):
+	}
+}
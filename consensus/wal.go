@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/klauspost/compress/zstd"
 
 	tmcons "github.com/tendermint/tendermint/proto/tendermint/consensus"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/Finschia/ostracon/libs/log"
 	tmos "github.com/Finschia/ostracon/libs/os"
 	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
 	tmtime "github.com/Finschia/ostracon/types/time"
 )
 
@@ -27,8 +29,13 @@ const (
 
 	// how often the WAL should be sync'd during period sync'ing
 	walDefaultFlushInterval = 2 * time.Second
+
+	// set in the length of the entries compressed, see WALEncoder
+	walCompressedFlag = 1 << 31
 )
 
+var errWALStopped = errors.New("the WAL is stopped")
+
 //--------------------------------------------------------
 // types and functions for savings consensus messages
 
@@ -83,6 +90,21 @@ type BaseWAL struct {
 
 	flushTicker   *time.Ticker
 	flushInterval time.Duration
+
+	// the entries written by the write routine, see SetWriteBuffer
+	writes       chan walWrite
+	stopWrites   chan struct{}
+	writesDone   chan struct{}
+	writeErrMtx  tmsync.Mutex
+	writeErr     error // the first error of the write routine
+	writeBufSize int
+}
+
+// walWrite is an encoded entry written by the write routine, or a barrier
+// synced once the entries before it are written if synced isn't nil.
+type walWrite struct {
+	entry  []byte
+	synced chan error
 }
 
 var _ WAL = &BaseWAL{}
@@ -113,6 +135,22 @@ func (wal *BaseWAL) SetFlushInterval(i time.Duration) {
 	wal.flushInterval = i
 }
 
+// SetCompression sets whether the new entries are compressed with zstd. The
+// entries of both kinds are read.
+func (wal *BaseWAL) SetCompression(compress bool) {
+	wal.enc.compress = compress
+}
+
+// SetWriteBuffer makes the entries written, and the WAL synced, by a routine
+// of the WAL rather than by the callers of Write, which only encode them.
+// Up to size entries are buffered for the routine, Write blocking once the
+// buffer is full. FlushAndSync and WriteSync still return once the entries
+// written before are synced to disk. Must be called before Start; 0, the
+// default, writes the entries in Write.
+func (wal *BaseWAL) SetWriteBuffer(size int) {
+	wal.writeBufSize = size
+}
+
 func (wal *BaseWAL) Group() *auto.Group {
 	return wal.group
 }
@@ -123,6 +161,12 @@ func (wal *BaseWAL) SetLogger(l log.Logger) {
 }
 
 func (wal *BaseWAL) OnStart() error {
+	if wal.writeBufSize > 0 {
+		wal.writes = make(chan walWrite, wal.writeBufSize)
+		wal.stopWrites = make(chan struct{})
+		wal.writesDone = make(chan struct{})
+		go wal.writeRoutine()
+	}
 	size, err := wal.group.Head.Size()
 	if err != nil {
 		return err
@@ -153,10 +197,71 @@ func (wal *BaseWAL) processFlushTicks() {
 	}
 }
 
+// writeRoutine writes the entries of Write and syncs the WAL at the barriers
+// of FlushAndSync, in order, until stopped. See SetWriteBuffer.
+func (wal *BaseWAL) writeRoutine() {
+	defer close(wal.writesDone)
+	for {
+		select {
+		case w := <-wal.writes:
+			wal.write(w)
+		case <-wal.stopWrites:
+			for {
+				select {
+				case w := <-wal.writes:
+					wal.write(w)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (wal *BaseWAL) write(w walWrite) {
+	if w.synced != nil {
+		err := wal.group.FlushAndSync()
+		if err == nil {
+			err = wal.writeError()
+		}
+		w.synced <- err
+		return
+	}
+	if _, err := wal.group.Write(w.entry); err != nil {
+		wal.Logger.Error("Error writing msg to consensus wal. WARNING: recover may not be possible for the current height",
+			"err", err)
+		wal.writeErrMtx.Lock()
+		if wal.writeErr == nil {
+			wal.writeErr = err
+		}
+		wal.writeErrMtx.Unlock()
+	}
+}
+
+// writeError returns the first error of the write routine, which is returned
+// by the next writes.
+func (wal *BaseWAL) writeError() error {
+	wal.writeErrMtx.Lock()
+	defer wal.writeErrMtx.Unlock()
+	return wal.writeErr
+}
+
 // FlushAndSync flushes and fsync's the underlying group's data to disk.
 // See auto#FlushAndSync
 func (wal *BaseWAL) FlushAndSync() error {
-	return wal.group.FlushAndSync()
+	if wal.writes == nil {
+		return wal.group.FlushAndSync()
+	}
+	synced := make(chan error, 1)
+	if err := wal.send(walWrite{synced: synced}); err != nil {
+		return err
+	}
+	select {
+	case err := <-synced:
+		return err
+	case <-wal.writesDone:
+		return errWALStopped
+	}
 }
 
 // Stop the underlying autofile group.
@@ -167,6 +272,10 @@ func (wal *BaseWAL) OnStop() {
 	if err := wal.FlushAndSync(); err != nil {
 		wal.Logger.Error("error on flush data to disk", "error", err)
 	}
+	if wal.writes != nil {
+		close(wal.stopWrites)
+		<-wal.writesDone
+	}
 	if err := wal.group.Stop(); err != nil {
 		wal.Logger.Error("error trying to stop wal", "error", err)
 	}
@@ -187,13 +296,40 @@ func (wal *BaseWAL) Write(msg WALMessage) error {
 		return nil
 	}
 
-	if err := wal.enc.Encode(&TimedWALMessage{tmtime.Now(), msg}); err != nil {
+	if wal.writes == nil {
+		if err := wal.enc.Encode(&TimedWALMessage{tmtime.Now(), msg}); err != nil {
+			wal.Logger.Error("Error writing msg to consensus wal. WARNING: recover may not be possible for the current height",
+				"err", err, "msg", msg)
+			return err
+		}
+		return nil
+	}
+
+	entry, err := wal.enc.encode(&TimedWALMessage{tmtime.Now(), msg})
+	if err != nil {
 		wal.Logger.Error("Error writing msg to consensus wal. WARNING: recover may not be possible for the current height",
 			"err", err, "msg", msg)
 		return err
 	}
+	if err := wal.writeError(); err != nil {
+		return err
+	}
+	return wal.send(walWrite{entry: entry})
+}
 
-	return nil
+// send sends w to the write routine, unless it's stopped.
+func (wal *BaseWAL) send(w walWrite) error {
+	select {
+	case <-wal.writesDone:
+		return errWALStopped
+	default:
+	}
+	select {
+	case wal.writes <- w:
+		return nil
+	case <-wal.writesDone:
+		return errWALStopped
+	}
 }
 
 // WriteSync is called when we receive a msg from ourselves
@@ -286,23 +422,44 @@ func (wal *BaseWAL) SearchForEndHeight(
 // A WALEncoder writes custom-encoded WAL messages to an output stream.
 //
 // Format: 4 bytes CRC sum + 4 bytes length + arbitrary-length value
+//
+// If compressed, the value is compressed with zstd when it's smaller so, the
+// highest bit of the length being set (the length of the compressed value).
 type WALEncoder struct {
-	wr io.Writer
+	wr       io.Writer
+	compress bool
 }
 
 // NewWALEncoder returns a new encoder that writes to wr.
 func NewWALEncoder(wr io.Writer) *WALEncoder {
-	return &WALEncoder{wr}
+	return &WALEncoder{wr: wr}
 }
 
+// the zstd encoder and decoder of the values of the entries, whose EncodeAll
+// and DecodeAll are goroutine-safe
+var (
+	walZstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	walZstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxMsgSizeBytes))
+)
+
 // Encode writes the custom encoding of v to the stream. It returns an error if
 // the encoded size of v is greater than 1MB. Any error encountered
 // during the write is also returned.
 func (enc *WALEncoder) Encode(v *TimedWALMessage) error {
-	pbMsg, err := WALToProto(v.Msg)
+	msg, err := enc.encode(v)
 	if err != nil {
 		return err
 	}
+	_, err = enc.wr.Write(msg)
+	return err
+}
+
+// encode returns the custom encoding of v, see Encode.
+func (enc *WALEncoder) encode(v *TimedWALMessage) ([]byte, error) {
+	pbMsg, err := WALToProto(v.Msg)
+	if err != nil {
+		return nil, err
+	}
 	pv := tmcons.TimedWALMessage{
 		Time: v.Time,
 		Msg:  pbMsg,
@@ -313,20 +470,26 @@ func (enc *WALEncoder) Encode(v *TimedWALMessage) error {
 		panic(fmt.Errorf("encode timed wall message failure: %w", err))
 	}
 
-	crc := crc32.Checksum(data, crc32c)
 	length := uint32(len(data))
 	if length > maxMsgSizeBytes {
-		return fmt.Errorf("msg is too big: %d bytes, max: %d bytes", length, maxMsgSizeBytes)
+		return nil, fmt.Errorf("msg is too big: %d bytes, max: %d bytes", length, maxMsgSizeBytes)
+	}
+	var flags uint32
+	if enc.compress {
+		if compressed := walZstdEncoder.EncodeAll(data, nil); len(compressed) < len(data) {
+			data = compressed
+			length = uint32(len(data))
+			flags = walCompressedFlag
+		}
 	}
+	crc := crc32.Checksum(data, crc32c)
 	totalLength := 8 + int(length)
 
 	msg := make([]byte, totalLength)
 	binary.BigEndian.PutUint32(msg[0:4], crc)
-	binary.BigEndian.PutUint32(msg[4:8], length)
+	binary.BigEndian.PutUint32(msg[4:8], length|flags)
 	copy(msg[8:], data)
-
-	_, err = enc.wr.Write(msg)
-	return err
+	return msg, nil
 }
 
 // IsDataCorruptionError returns true if data has been corrupted inside WAL.
@@ -381,6 +544,8 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 		return nil, DataCorruptionError{fmt.Errorf("failed to read length: %v", err)}
 	}
 	length := binary.BigEndian.Uint32(b)
+	compressed := length&walCompressedFlag != 0
+	length &^= walCompressedFlag
 
 	if length > maxMsgSizeBytes {
 		return nil, DataCorruptionError{fmt.Errorf(
@@ -400,6 +565,18 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	if actualCRC != crc {
 		return nil, DataCorruptionError{fmt.Errorf("checksums do not match: read: %v, actual: %v", crc, actualCRC)}
 	}
+	if compressed {
+		data, err = walZstdDecoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, DataCorruptionError{fmt.Errorf("failed to decompress data: %v", err)}
+		}
+		if len(data) > maxMsgSizeBytes {
+			return nil, DataCorruptionError{fmt.Errorf(
+				"decompressed length %d exceeded maximum possible value of %d bytes",
+				len(data),
+				maxMsgSizeBytes)}
+		}
+	}
 
 	var res = new(tmcons.TimedWALMessage)
 	err = proto.Unmarshal(data, res)
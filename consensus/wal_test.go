@@ -107,6 +107,83 @@ func TestWALEncoderDecoder(t *testing.T) {
 	}
 }
 
+func TestWALEncoderDecoderCompressed(t *testing.T) {
+	now := tmtime.Now()
+	msgs := []TimedWALMessage{
+		{Time: now, Msg: EndHeightMessage{0}},
+		{Time: now, Msg: msgInfo{Msg: &BlockPartMessage{Height: 1, Round: 1, Part: &tmtypes.Part{
+			Bytes: make([]byte, 10000),
+			Proof: merkle.Proof{Total: 1, LeafHash: make([]byte, 32)},
+		}}}},
+	}
+
+	// the entries of both kinds are decoded, the small ones not being compressed
+	b := new(bytes.Buffer)
+	plain := NewWALEncoder(b)
+	compressed := NewWALEncoder(b)
+	compressed.compress = true
+	for _, msg := range msgs {
+		msg := msg
+		require.NoError(t, plain.Encode(&msg))
+		size := b.Len()
+		require.NoError(t, compressed.Encode(&msg))
+		if _, ok := msg.Msg.(msgInfo); ok {
+			assert.Less(t, b.Len()-size, 1000)
+		}
+	}
+
+	dec := NewWALDecoder(b)
+	for _, msg := range msgs {
+		for i := 0; i < 2; i++ {
+			decoded, err := dec.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, msg.Time.UTC(), decoded.Time)
+			assert.Equal(t, msg.Msg, decoded.Msg)
+		}
+	}
+}
+
+func TestWALWriteBuffer(t *testing.T) {
+	walDir := t.TempDir()
+	walFile := filepath.Join(walDir, "wal")
+
+	wal, err := NewWAL(walFile)
+	require.NoError(t, err)
+	wal.SetLogger(log.TestingLogger())
+	wal.SetCompression(true)
+	wal.SetWriteBuffer(2)
+	require.NoError(t, wal.Start())
+
+	// the entries are written by the routine, in order, and synced at the barriers
+	for h := int64(1); h <= 10; h++ {
+		require.NoError(t, wal.Write(tmtypes.EventDataRoundState{Height: h, Round: 0, Step: ""}))
+		require.NoError(t, wal.WriteSync(EndHeightMessage{h}))
+	}
+	require.NoError(t, wal.Write(tmtypes.EventDataRoundState{Height: 11, Round: 0, Step: ""}))
+	require.NoError(t, wal.FlushAndSync())
+	assert.Zero(t, wal.Group().Buffered())
+
+	gr, found, err := wal.SearchForEndHeight(10, &WALSearchOptions{})
+	require.NoError(t, err)
+	require.True(t, found)
+	msg, err := NewWALDecoder(gr).Decode()
+	require.NoError(t, err)
+	assert.Equal(t, tmtypes.EventDataRoundState{Height: 11, Round: 0, Step: ""}, msg.Msg)
+	gr.Close()
+
+	// the messages too big are still rejected by Write
+	err = wal.Write(msgInfo{Msg: &BlockPartMessage{Height: 1, Round: 1, Part: &tmtypes.Part{
+		Bytes: nBytes(maxMsgSizeBytes),
+		Proof: merkle.Proof{Total: 1, LeafHash: make([]byte, 32)},
+	}}})
+	assert.Error(t, err)
+
+	require.NoError(t, wal.Stop())
+	wal.Wait()
+	assert.ErrorIs(t, wal.Write(EndHeightMessage{11}), errWALStopped)
+	assert.ErrorIs(t, wal.FlushAndSync(), errWALStopped)
+}
+
 func TestWALWrite(t *testing.T) {
 	walDir, err := os.MkdirTemp("", "wal")
 	require.NoError(t, err)
@@ -0,0 +1,28 @@
+// Package batch creates the batch verifiers of the key types supporting them.
+package batch
+
+import (
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+)
+
+// CreateBatchVerifier returns a batch verifier of the signatures by the keys
+// of the type of pk, and false if the key type doesn't support the batch
+// verification. Only ed25519 supports it.
+func CreateBatchVerifier(pk crypto.PubKey) (crypto.BatchVerifier, bool) {
+	switch pk.Type() {
+	case ed25519.KeyType:
+		return ed25519.NewBatchVerifier(), true
+	}
+	return nil, false
+}
+
+// SupportsBatchVerifier returns true if the signatures by the keys of the
+// type of pk can be verified in batches.
+func SupportsBatchVerifier(pk crypto.PubKey) bool {
+	switch pk.Type() {
+	case ed25519.KeyType:
+		return true
+	}
+	return false
+}
@@ -47,3 +47,13 @@ type Symmetric interface {
 	Encrypt(plaintext []byte, secret []byte) (ciphertext []byte)
 	Decrypt(ciphertext []byte, secret []byte) (plaintext []byte, err error)
 }
+
+// BatchVerifier verifies the signatures of several messages at once. The key
+// types supporting it are registered in crypto/batch.
+type BatchVerifier interface {
+	// Add appends the signature of the message by the key to the batch.
+	Add(key PubKey, message, signature []byte) error
+	// Verify returns true if all the signatures of the batch are valid, and
+	// the validity of each of them in the order they were added.
+	Verify() (bool, []bool)
+}
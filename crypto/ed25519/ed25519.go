@@ -190,3 +190,40 @@ func (pubKey PubKey) Equals(other crypto.PubKey) bool {
 
 	return false
 }
+
+//-------------------------------------
+
+var _ crypto.BatchVerifier = &BatchVerifier{}
+
+// BatchVerifier implements crypto.BatchVerifier for the ed25519 signatures,
+// accepting the same signatures as PubKey.VerifySignature.
+type BatchVerifier struct {
+	*ed25519.BatchVerifier
+}
+
+func NewBatchVerifier() crypto.BatchVerifier {
+	return &BatchVerifier{ed25519.NewBatchVerifier()}
+}
+
+// Add implements crypto.BatchVerifier.
+func (b *BatchVerifier) Add(key crypto.PubKey, msg, signature []byte) error {
+	pubKey, ok := key.(PubKey)
+	if !ok {
+		return fmt.Errorf("pubkey is not ed25519: %s", key.Type())
+	}
+	if len(pubKey) != PubKeySize {
+		return fmt.Errorf("pubkey size is incorrect; expected: %d, got %d", PubKeySize, len(pubKey))
+	}
+	// make sure we use the same algorithm to sign
+	if len(signature) != SignatureSize {
+		return fmt.Errorf("signature size is incorrect; expected: %d, got %d", SignatureSize, len(signature))
+	}
+	b.BatchVerifier.Add(ed25519.PublicKey(pubKey), msg, signature)
+	return nil
+}
+
+// Verify implements crypto.BatchVerifier. If the batch is invalid, each
+// signature is verified individually.
+func (b *BatchVerifier) Verify() (bool, []bool) {
+	return b.BatchVerifier.Verify(crypto.CReader())
+}
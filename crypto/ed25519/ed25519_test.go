@@ -9,6 +9,7 @@ import (
 
 	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/secp256k1"
 )
 
 func TestSignAndValidateEd25519(t *testing.T) {
@@ -60,3 +61,29 @@ func TestVRFProveAndVRFVerify(t *testing.T) {
 	_, err3 := pubKey.VRFVerify(invalidProof, message)
 	assert.Error(t, err3)
 }
+
+func TestBatchVerifier(t *testing.T) {
+	bv := ed25519.NewBatchVerifier()
+	msgs := make([][]byte, 4)
+	for i := range msgs {
+		privKey := ed25519.GenPrivKey()
+		msgs[i] = crypto.CRandBytes(32)
+		sig, err := privKey.Sign(msgs[i])
+		require.NoError(t, err)
+		if i == 2 {
+			// the signature of another message
+			sig, err = privKey.Sign(crypto.CRandBytes(32))
+			require.NoError(t, err)
+		}
+		require.NoError(t, bv.Add(privKey.PubKey(), msgs[i], sig))
+	}
+
+	ok, valid := bv.Verify()
+	assert.False(t, ok)
+	assert.Equal(t, []bool{true, true, false, true}, valid)
+
+	// the entries which can't be valid are rejected
+	privKey := ed25519.GenPrivKey()
+	assert.Error(t, bv.Add(privKey.PubKey(), msgs[0], []byte("short")))
+	assert.Error(t, bv.Add(secp256k1.GenPrivKey().PubKey(), msgs[0], make([]byte, ed25519.SignatureSize)))
+}
@@ -48,5 +48,21 @@ As all evidence (including POLC's) are bounded by an expiration date, those that
 and hence pruned. Currently, only committed evidence in which a marker to the height that the evidence was committed
 and hence very small is saved. All updates are made from the `Update(block, state)` function which should be called
 when a new block is committed.
+
+# Amnesia
+
+Amnesia-style misbehavior (a validator voting for a different block in a later round after having
+locked on a block, without having seen a POLC that justifies the unlock) is not handled by this package.
+
+1. The DuplicateVoteEvidence can't capture it as the conflicting votes belong to different rounds.
+
+2. It can't be proven from the two votes alone: the accused validator must be able to show the POLC
+it unlocked on, which requires an interactive trial protocol rather than a self-contained piece of evidence.
+
+3. The wire formats (tendermint.types.Evidence in blocks and gossip, abci.EvidenceType towards the
+application) are defined by the Tendermint proto types Ostracon depends on, and have no amnesia variant.
+
+Amnesia attacks on light clients are still detected, as the LightClientAttackEvidence
+(see types/evidence.go#GetByzantineValidators).
 */
 package evidence
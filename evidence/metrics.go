@@ -0,0 +1,93 @@
+package evidence
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	"github.com/Finschia/ostracon/libs/async"
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "evidence"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of pending evidence in the pool.
+	NumPending metrics.Gauge
+	// Number of evidence committed in blocks.
+	NumCommitted metrics.Counter
+	// Number of pending evidence pruned because it expired before being committed.
+	NumExpired metrics.Counter
+	// Number of evidence rejected because it failed verification.
+	NumInvalid metrics.Counter
+	// Number of committed evidence markers pruned from the store.
+	NumPrunedCommitted metrics.Counter
+	// Metrics of the pool verifying the evidence received from peers.
+	VerifyPool *async.Metrics
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		NumPending: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "num_pending",
+			Help:      "Number of pending evidence in the pool.",
+		}, labels).With(labelsAndValues...),
+		NumCommitted: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "num_committed",
+			Help:      "Number of evidence committed in blocks.",
+		}, labels).With(labelsAndValues...),
+		NumExpired: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "num_expired",
+			Help:      "Number of pending evidence pruned because it expired before being committed.",
+		}, labels).With(labelsAndValues...),
+		NumInvalid: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "num_invalid",
+			Help:      "Number of evidence rejected because it failed verification.",
+		}, labels).With(labelsAndValues...),
+		NumPrunedCommitted: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "num_pruned_committed",
+			Help:      "Number of committed evidence markers pruned from the store.",
+		}, labels).With(labelsAndValues...),
+		VerifyPool: async.NewMetrics(provider, namespace, MetricsSubsystem+"_verify", labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		NumPending:         discard.NewGauge(),
+		NumCommitted:       discard.NewCounter(),
+		NumExpired:         discard.NewCounter(),
+		NumInvalid:         discard.NewCounter(),
+		NumPrunedCommitted: discard.NewCounter(),
+		VerifyPool:         async.NopMetrics(),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,8 +23,20 @@ import (
 const (
 	baseKeyCommitted = byte(0x00)
 	baseKeyPending   = byte(0x01)
+	baseKeyVersion   = byte(0x02)
+	// light client attacks are kept in full once committed, so they can be analyzed even after
+	// the blocks containing them have been pruned
+	baseKeyLightClientAttack = byte(0x03)
+	// committed evidence indexed by the address of the misbehaving validators
+	baseKeyValidatorEvidence = byte(0x04)
 )
 
+// storeVersion is the version of the format evidence is persisted with:
+//
+//	1: committed evidence is recorded with its height (no version key)
+//	2: committed evidence is recorded with its time, so it can be pruned once expired
+const storeVersion = int64(2)
+
 // Pool maintains a pool of valid evidence to be broadcasted and committed
 type Pool struct {
 	logger log.Logger
@@ -47,11 +60,44 @@ type Pool struct {
 
 	pruningHeight int64
 	pruningTime   time.Time
+
+	// re-verify pending evidence against the latest state when starting
+	verifyOnStart bool
+
+	metrics *Metrics
+
+	// notified of the evidence committed in blocks (guarded by mtx)
+	committedHandlers []CommittedEvidenceHandler
+}
+
+// CommittedEvidenceHandler is notified of the evidence committed in a block,
+// e.g. by alerting systems and slashing monitors. height is the height of the
+// block containing the evidence. The handlers are called synchronously while
+// processing the block, so they must not block.
+type CommittedEvidenceHandler interface {
+	OnDuplicateVoteEvidenceCommitted(ev *types.DuplicateVoteEvidence, height int64)
+	OnLightClientAttackEvidenceCommitted(ev *types.LightClientAttackEvidence, height int64)
+}
+
+// PoolOption sets an optional parameter on the evidence pool.
+type PoolOption func(*Pool)
+
+// WithMetrics sets the metrics.
+func WithMetrics(metrics *Metrics) PoolOption {
+	return func(evpool *Pool) { evpool.metrics = metrics }
+}
+
+// WithStartupVerification makes the pool re-verify all the pending evidence
+// found in the store when it is created, dropping the evidence which is no
+// longer valid (e.g. because the node was down for a long time).
+func WithStartupVerification() PoolOption {
+	return func(evpool *Pool) { evpool.verifyOnStart = true }
 }
 
 // NewPool creates an evidence pool. If using an existing evidence store,
-// it will add all pending evidence to the concurrent list.
-func NewPool(evidenceDB dbm.DB, stateDB sm.Store, blockStore BlockStore) (*Pool, error) {
+// it will migrate it to the latest format and add all pending evidence to the
+// concurrent list.
+func NewPool(evidenceDB dbm.DB, stateDB sm.Store, blockStore BlockStore, options ...PoolOption) (*Pool, error) {
 
 	state, err := stateDB.Load()
 	if err != nil {
@@ -66,19 +112,41 @@ func NewPool(evidenceDB dbm.DB, stateDB sm.Store, blockStore BlockStore) (*Pool,
 		evidenceStore:   evidenceDB,
 		evidenceList:    clist.New(),
 		consensusBuffer: make([]duplicateVoteSet, 0),
+		metrics:         NopMetrics(),
+	}
+	for _, option := range options {
+		option(pool)
+	}
+
+	if err := pool.migrateStore(); err != nil {
+		return nil, err
 	}
 
 	// if pending evidence already in db, in event of prior failure, then check for expiration,
 	// update the size and load it back to the evidenceList
-	pool.pruningHeight, pool.pruningTime = pool.removeExpiredPendingEvidence()
 	evList, _, err := pool.listEvidence(baseKeyPending, -1)
 	if err != nil {
 		return nil, err
 	}
 	atomic.StoreUint32(&pool.evidenceSize, uint32(len(evList)))
+	pool.pruningHeight, pool.pruningTime = pool.removeExpiredPendingEvidence()
 	for _, ev := range evList {
+		if !pool.isPending(ev) {
+			// expired
+			continue
+		}
+		if pool.verifyOnStart {
+			if err := pool.verify(ev); err != nil {
+				pool.logger.Error("Pending evidence is no longer valid, removing it", "err", err, "ev", ev)
+				pool.metrics.NumInvalid.Add(1)
+				pool.removePendingEvidence(ev)
+				continue
+			}
+		}
 		pool.evidenceList.PushBack(ev)
 	}
+	pool.pruneExpiredCommittedEvidence()
+	pool.metrics.NumPending.Set(float64(pool.Size()))
 
 	return pool, nil
 }
@@ -101,7 +169,10 @@ func (evpool *Pool) PendingEvidence(maxBytes int64) ([]types.Evidence, int64) {
 //     DuplicateVoteEvidence and add it to the pool.
 //  2. Update the pool's state which contains evidence params relating to expiry.
 //  3. Moves pending evidence that has now been committed into the committed pool.
-//  4. Removes any expired evidence based on both height and time.
+//  4. Removes any expired evidence based on both height and time. All the pending evidence is
+//     re-evaluated when the evidence params have changed.
+//  5. Removes the markers of committed evidence which has expired.
+//  6. Notifies the committed evidence handlers.
 func (evpool *Pool) Update(state sm.State, ev types.EvidenceList) {
 	// sanity check
 	if state.LastBlockHeight <= evpool.state.LastBlockHeight {
@@ -114,6 +185,10 @@ func (evpool *Pool) Update(state sm.State, ev types.EvidenceList) {
 	evpool.logger.Debug("Updating evidence pool", "last_block_height", state.LastBlockHeight,
 		"last_block_time", state.LastBlockTime)
 
+	// the evidence params can be changed by the application (see ConsensusParamUpdates), in
+	// which case the expiry of all the pending evidence has to be re-evaluated
+	paramsChanged := state.ConsensusParams.Evidence != evpool.State().ConsensusParams.Evidence
+
 	// flush conflicting vote pairs from the buffer, producing DuplicateVoteEvidence and
 	// adding it to the pool
 	evpool.processConsensusBuffer(state)
@@ -124,10 +199,28 @@ func (evpool *Pool) Update(state sm.State, ev types.EvidenceList) {
 	evpool.markEvidenceAsCommitted(ev)
 
 	// prune pending evidence when it has expired. This also updates when the next evidence will expire
-	if evpool.Size() > 0 && state.LastBlockHeight > evpool.pruningHeight &&
-		state.LastBlockTime.After(evpool.pruningTime) {
+	if evpool.Size() > 0 && (paramsChanged ||
+		state.LastBlockHeight > evpool.pruningHeight && state.LastBlockTime.After(evpool.pruningTime)) {
+		if paramsChanged {
+			evpool.logger.Info("Evidence params changed, re-evaluating pending evidence",
+				"params", state.ConsensusParams.Evidence)
+		}
 		evpool.pruningHeight, evpool.pruningTime = evpool.removeExpiredPendingEvidence()
 	}
+
+	// committed evidence can't be proposed again once it has expired, so there's no need
+	// to remember it any longer
+	evpool.pruneExpiredCommittedEvidence()
+
+	evpool.notifyCommittedEvidence(ev, state.LastBlockHeight)
+}
+
+// AddCommittedEvidenceHandler registers a handler notified of the evidence
+// committed in every future block.
+func (evpool *Pool) AddCommittedEvidenceHandler(h CommittedEvidenceHandler) {
+	evpool.mtx.Lock()
+	defer evpool.mtx.Unlock()
+	evpool.committedHandlers = append(evpool.committedHandlers, h)
 }
 
 // AddEvidence checks the evidence is valid and adds it to the pool.
@@ -151,6 +244,7 @@ func (evpool *Pool) AddEvidence(ev types.Evidence) error {
 	// 1) Verify against state.
 	err := evpool.verify(ev)
 	if err != nil {
+		evpool.metrics.NumInvalid.Add(1)
 		return types.NewErrInvalidEvidence(ev, err)
 	}
 
@@ -205,6 +299,7 @@ func (evpool *Pool) CheckEvidence(evList types.EvidenceList) error {
 
 			err := evpool.verify(ev)
 			if err != nil {
+				evpool.metrics.NumInvalid.Add(1)
 				return err
 			}
 
@@ -229,6 +324,63 @@ func (evpool *Pool) CheckEvidence(evList types.EvidenceList) error {
 	return nil
 }
 
+// LightClientAttacks returns the committed light client attack evidence, including the complete
+// conflicting light block signed by the attackers, whose height is within [minHeight, maxHeight],
+// from oldest to newest.
+func (evpool *Pool) LightClientAttacks(minHeight, maxHeight int64) ([]*types.LightClientAttackEvidence, error) {
+	start := append([]byte{baseKeyLightClientAttack}, []byte(bE(minHeight))...)
+	end := append([]byte{baseKeyLightClientAttack}, []byte(bE(maxHeight+1))...)
+	iter, err := evpool.evidenceStore.Iterator(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer iter.Close()
+
+	var attacks []*types.LightClientAttackEvidence
+	for ; iter.Valid(); iter.Next() {
+		ev, err := bytesToEv(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		lcae, ok := ev.(*types.LightClientAttackEvidence)
+		if !ok {
+			return nil, fmt.Errorf("unexpected evidence type %T", ev)
+		}
+		attacks = append(attacks, lcae)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return attacks, nil
+}
+
+// SearchEvidence returns the committed evidence against the validator with the given address
+// whose height is within [minHeight, maxHeight], from oldest to newest. Only the evidence
+// committed since the index was introduced is returned.
+func (evpool *Pool) SearchEvidence(address types.Address, minHeight, maxHeight int64) ([]types.Evidence, error) {
+	prefix := append([]byte{baseKeyValidatorEvidence}, address...)
+	start := append(append([]byte{}, prefix...), []byte(bE(minHeight))...)
+	end := append(append([]byte{}, prefix...), []byte(bE(maxHeight+1))...)
+	iter, err := evpool.evidenceStore.Iterator(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+	defer iter.Close()
+
+	var evidence []types.Evidence
+	for ; iter.Valid(); iter.Next() {
+		ev, err := bytesToEv(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		evidence = append(evidence, ev)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
 // EvidenceFront goes to the first evidence in the clist
 func (evpool *Pool) EvidenceFront() *clist.CElement {
 	return evpool.evidenceList.Front()
@@ -310,15 +462,65 @@ func (evpool *Pool) addPendingEvidence(ev types.Evidence) error {
 		return fmt.Errorf("can't persist evidence: %w", err)
 	}
 	atomic.AddUint32(&evpool.evidenceSize, 1)
+	evpool.metrics.NumPending.Set(float64(evpool.Size()))
 	return nil
 }
 
+func (evpool *Pool) saveLightClientAttack(ev *types.LightClientAttackEvidence) error {
+	evpb, err := types.EvidenceToProto(ev)
+	if err != nil {
+		return fmt.Errorf("unable to convert to proto, err: %w", err)
+	}
+
+	evBytes, err := evpb.Marshal()
+	if err != nil {
+		return fmt.Errorf("unable to marshal evidence: %w", err)
+	}
+
+	return evpool.evidenceStore.Set(keyLightClientAttack(ev), evBytes)
+}
+
+// indexEvidence saves the evidence under the address of every misbehaving validator.
+func (evpool *Pool) indexEvidence(ev types.Evidence) error {
+	var addresses []types.Address
+	switch ev := ev.(type) {
+	case *types.DuplicateVoteEvidence:
+		addresses = append(addresses, ev.VoteA.ValidatorAddress)
+	case *types.LightClientAttackEvidence:
+		for _, val := range ev.ByzantineValidators {
+			addresses = append(addresses, val.Address)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	evpb, err := types.EvidenceToProto(ev)
+	if err != nil {
+		return fmt.Errorf("unable to convert to proto, err: %w", err)
+	}
+	evBytes, err := evpb.Marshal()
+	if err != nil {
+		return fmt.Errorf("unable to marshal evidence: %w", err)
+	}
+
+	batch := evpool.evidenceStore.NewBatch()
+	defer batch.Close()
+	for _, address := range addresses {
+		if err := batch.Set(keyValidatorEvidence(address, ev), evBytes); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
 func (evpool *Pool) removePendingEvidence(evidence types.Evidence) {
 	key := keyPending(evidence)
 	if err := evpool.evidenceStore.Delete(key); err != nil {
 		evpool.logger.Error("Unable to delete pending evidence", "err", err)
 	} else {
 		atomic.AddUint32(&evpool.evidenceSize, ^uint32(0))
+		evpool.metrics.NumPending.Set(float64(evpool.Size()))
 		evpool.logger.Debug("Deleted pending evidence", "evidence", evidence)
 	}
 }
@@ -334,11 +536,11 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList) {
 		}
 
 		// Add evidence to the committed list. As the evidence is stored in the block store
-		// we only need to record the height that it was saved at.
+		// we only need to record the time of the evidence (the height is part of the key) to
+		// know when it expires.
 		key := keyCommitted(ev)
 
-		h := gogotypes.Int64Value{Value: ev.Height()}
-		evBytes, err := proto.Marshal(&h)
+		evBytes, err := proto.Marshal(mustTimestampProto(ev.Time()))
 		if err != nil {
 			evpool.logger.Error("failed to marshal committed evidence", "err", err, "key(height/hash)", key)
 			continue
@@ -346,6 +548,17 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList) {
 
 		if err := evpool.evidenceStore.Set(key, evBytes); err != nil {
 			evpool.logger.Error("Unable to save committed evidence", "err", err, "key(height/hash)", key)
+			continue
+		}
+		evpool.metrics.NumCommitted.Add(1)
+
+		if lcae, ok := ev.(*types.LightClientAttackEvidence); ok {
+			if err := evpool.saveLightClientAttack(lcae); err != nil {
+				evpool.logger.Error("Unable to save light client attack", "err", err, "ev", lcae)
+			}
+		}
+		if err := evpool.indexEvidence(ev); err != nil {
+			evpool.logger.Error("Unable to index committed evidence", "err", err, "ev", ev)
 		}
 	}
 
@@ -424,6 +637,7 @@ func (evpool *Pool) removeExpiredPendingEvidence() (int64, time.Time) {
 				ev.Time().Add(evpool.State().ConsensusParams.Evidence.MaxAgeDuration).Add(time.Second)
 		}
 		evpool.removePendingEvidence(ev)
+		evpool.metrics.NumExpired.Add(1)
 		blockEvidenceMap[evMapKey(ev)] = struct{}{}
 	}
 	// We either have no pending evidence or all evidence has expired
@@ -433,6 +647,120 @@ func (evpool *Pool) removeExpiredPendingEvidence() (int64, time.Time) {
 	return evpool.State().LastBlockHeight, evpool.State().LastBlockTime
 }
 
+// pruneExpiredCommittedEvidence removes the committed evidence markers, from
+// oldest to newest, until it finds one which hasn't expired yet.
+func (evpool *Pool) pruneExpiredCommittedEvidence() {
+	iter, err := dbm.IteratePrefix(evpool.evidenceStore, []byte{baseKeyCommitted})
+	if err != nil {
+		evpool.logger.Error("Unable to iterate over committed evidence", "err", err)
+		return
+	}
+	defer iter.Close()
+
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		height, evTime, err := committedEvidenceFromEntry(iter.Key(), iter.Value())
+		if err != nil {
+			evpool.logger.Error("Error in decoding committed evidence", "err", err, "key(height/hash)", iter.Key())
+			continue
+		}
+		if !evpool.isExpired(height, evTime) {
+			break
+		}
+		keys = append(keys, iter.Key())
+	}
+	if err := iter.Error(); err != nil {
+		evpool.logger.Error("Unable to iterate over committed evidence", "err", err)
+	}
+
+	for _, key := range keys {
+		if err := evpool.evidenceStore.Delete(key); err != nil {
+			evpool.logger.Error("Unable to delete committed evidence", "err", err, "key(height/hash)", key)
+			continue
+		}
+		evpool.metrics.NumPrunedCommitted.Add(1)
+	}
+}
+
+// migrateStore upgrades the evidence store to storeVersion.
+func (evpool *Pool) migrateStore() error {
+	version, err := evpool.loadStoreVersion()
+	if err != nil {
+		return err
+	}
+	switch {
+	case version == storeVersion:
+		return nil
+	case version > storeVersion:
+		return fmt.Errorf("unsupported evidence store version %d (latest: %d)", version, storeVersion)
+	}
+
+	// version 1: committed evidence was recorded with its height only. Use the time of the
+	// block at that height as the evidence time. If the block has been pruned, the evidence is
+	// so old that it will be pruned according to its height only.
+	iter, err := dbm.IteratePrefix(evpool.evidenceStore, []byte{baseKeyCommitted})
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	batch := evpool.evidenceStore.NewBatch()
+	defer batch.Close()
+	for ; iter.Valid(); iter.Next() {
+		var h gogotypes.Int64Value
+		if err := proto.Unmarshal(iter.Value(), &h); err != nil {
+			iter.Close()
+			return fmt.Errorf("unable to decode committed evidence %X: %w", iter.Key(), err)
+		}
+		var evTime time.Time
+		if blockMeta := evpool.blockStore.LoadBlockMeta(h.Value); blockMeta != nil {
+			evTime = blockMeta.Header.Time
+		}
+		evBytes, err := proto.Marshal(mustTimestampProto(evTime))
+		if err != nil {
+			iter.Close()
+			return err
+		}
+		if err := batch.Set(iter.Key(), evBytes); err != nil {
+			iter.Close()
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		iter.Close()
+		return err
+	}
+	iter.Close()
+
+	versionBytes, err := proto.Marshal(&gogotypes.Int64Value{Value: storeVersion})
+	if err != nil {
+		return err
+	}
+	if err := batch.Set([]byte{baseKeyVersion}, versionBytes); err != nil {
+		return err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return fmt.Errorf("unable to migrate evidence store to version %d: %w", storeVersion, err)
+	}
+	evpool.logger.Info("Migrated evidence store", "from", version, "to", storeVersion)
+	return nil
+}
+
+// loadStoreVersion returns the version of the evidence store. A store without
+// a version key is of version 1.
+func (evpool *Pool) loadStoreVersion() (int64, error) {
+	bz, err := evpool.evidenceStore.Get([]byte{baseKeyVersion})
+	if err != nil {
+		return 0, fmt.Errorf("database error: %v", err)
+	}
+	if len(bz) == 0 {
+		return 1, nil
+	}
+	var version gogotypes.Int64Value
+	if err := proto.Unmarshal(bz, &version); err != nil {
+		return 0, fmt.Errorf("unable to decode evidence store version: %w", err)
+	}
+	return version.Value, nil
+}
+
 func (evpool *Pool) removeEvidenceFromList(
 	blockEvidenceMap map[string]struct{}) {
 
@@ -446,6 +774,26 @@ func (evpool *Pool) removeEvidenceFromList(
 	}
 }
 
+func (evpool *Pool) notifyCommittedEvidence(evidence types.EvidenceList, height int64) {
+	if len(evidence) == 0 {
+		return
+	}
+	evpool.mtx.Lock()
+	handlers := evpool.committedHandlers
+	evpool.mtx.Unlock()
+
+	for _, h := range handlers {
+		for _, ev := range evidence {
+			switch ev := ev.(type) {
+			case *types.DuplicateVoteEvidence:
+				h.OnDuplicateVoteEvidenceCommitted(ev, height)
+			case *types.LightClientAttackEvidence:
+				h.OnLightClientAttackEvidenceCommitted(ev, height)
+			}
+		}
+	}
+}
+
 func (evpool *Pool) updateState(state sm.State) {
 	evpool.mtx.Lock()
 	defer evpool.mtx.Unlock()
@@ -543,6 +891,35 @@ func bytesToEv(evBytes []byte) (types.Evidence, error) {
 	return types.EvidenceFromProto(&evpb)
 }
 
+// committedEvidenceFromEntry returns the height and the time of committed evidence.
+func committedEvidenceFromEntry(key, value []byte) (int64, time.Time, error) {
+	// key: baseKeyCommitted + big endian padded hex height + "/" + hash
+	if len(key) < 17 {
+		return 0, time.Time{}, fmt.Errorf("invalid committed evidence key %X", key)
+	}
+	height, err := strconv.ParseInt(string(key[1:17]), 16, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	var ts gogotypes.Timestamp
+	if err := proto.Unmarshal(value, &ts); err != nil {
+		return 0, time.Time{}, err
+	}
+	evTime, err := gogotypes.TimestampFromProto(&ts)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return height, evTime, nil
+}
+
+func mustTimestampProto(t time.Time) *gogotypes.Timestamp {
+	ts, err := gogotypes.TimestampProto(t)
+	if err != nil {
+		panic(err)
+	}
+	return ts
+}
+
 func evMapKey(ev types.Evidence) string {
 	return string(ev.Hash())
 }
@@ -560,6 +937,15 @@ func keyPending(evidence types.Evidence) []byte {
 	return append([]byte{baseKeyPending}, keySuffix(evidence)...)
 }
 
+func keyLightClientAttack(evidence types.Evidence) []byte {
+	return append([]byte{baseKeyLightClientAttack}, keySuffix(evidence)...)
+}
+
+func keyValidatorEvidence(address types.Address, evidence types.Evidence) []byte {
+	key := append([]byte{baseKeyValidatorEvidence}, address...)
+	return append(key, keySuffix(evidence)...)
+}
+
 func keySuffix(evidence types.Evidence) []byte {
 	return []byte(fmt.Sprintf("%s/%X", bE(evidence.Height()), evidence.Hash()))
 }
@@ -1,10 +1,13 @@
 package evidence_test
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -213,6 +216,102 @@ func TestEvidencePoolUpdate(t *testing.T) {
 	}
 }
 
+type committedEvidenceRecorder struct {
+	duplicateVotes     []*types.DuplicateVoteEvidence
+	lightClientAttacks []*types.LightClientAttackEvidence
+	heights            []int64
+}
+
+func (r *committedEvidenceRecorder) OnDuplicateVoteEvidenceCommitted(ev *types.DuplicateVoteEvidence,
+	height int64) {
+	r.duplicateVotes = append(r.duplicateVotes, ev)
+	r.heights = append(r.heights, height)
+}
+
+func (r *committedEvidenceRecorder) OnLightClientAttackEvidenceCommitted(ev *types.LightClientAttackEvidence,
+	height int64) {
+	r.lightClientAttacks = append(r.lightClientAttacks, ev)
+	r.heights = append(r.heights, height)
+}
+
+func TestCommittedEvidenceHandler(t *testing.T) {
+	height := int64(21)
+	pool, val := defaultTestPool(height)
+	state := pool.State()
+
+	recorder := &committedEvidenceRecorder{}
+	pool.AddCommittedEvidenceHandler(recorder)
+
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, defaultEvidenceTime.Add(21*time.Minute),
+		val, evidenceChainID)
+	require.NoError(t, pool.CheckEvidence(types.EvidenceList{ev}))
+
+	state.LastBlockHeight = height + 1
+	state.LastBlockTime = defaultEvidenceTime.Add(22 * time.Minute)
+	pool.Update(state, types.EvidenceList{ev})
+
+	// no evidence in this block
+	state.LastBlockHeight++
+	state.LastBlockTime = state.LastBlockTime.Add(time.Minute)
+	pool.Update(state, types.EvidenceList{})
+
+	assert.Equal(t, []*types.DuplicateVoteEvidence{ev}, recorder.duplicateVotes)
+	assert.Empty(t, recorder.lightClientAttacks)
+	assert.Equal(t, []int64{height + 1}, recorder.heights)
+}
+
+func TestSearchEvidence(t *testing.T) {
+	height := int64(21)
+	pool, val := defaultTestPool(height)
+	state := pool.State()
+	address := val.PrivKey.PubKey().Address()
+
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, defaultEvidenceTime.Add(21*time.Minute),
+		val, evidenceChainID)
+	require.NoError(t, pool.CheckEvidence(types.EvidenceList{ev}))
+	state.LastBlockHeight = height + 1
+	state.LastBlockTime = defaultEvidenceTime.Add(22 * time.Minute)
+	pool.Update(state, types.EvidenceList{ev})
+
+	found, err := pool.SearchEvidence(address, 1, height)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Evidence{ev}, found)
+
+	found, err = pool.SearchEvidence(address, height+1, height+1)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = pool.SearchEvidence(crypto.AddressHash([]byte("other")), 1, height)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+// Tests that pending evidence is re-evaluated when the evidence params change
+func TestEvidencePoolUpdateEvidenceParams(t *testing.T) {
+	height := int64(10)
+	pool, val := defaultTestPool(height)
+	state := pool.State()
+
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(1, defaultEvidenceTime.Add(1*time.Minute),
+		val, evidenceChainID)
+	require.NoError(t, pool.AddEvidence(ev))
+
+	state.LastBlockHeight = height + 1
+	state.LastBlockTime = defaultEvidenceTime.Add(11 * time.Minute)
+	pool.Update(state, types.EvidenceList{})
+	assert.EqualValues(t, 1, pool.Size())
+
+	// the application reduces the max age of evidence
+	state.LastBlockHeight++
+	state.LastBlockTime = state.LastBlockTime.Add(1 * time.Minute)
+	state.ConsensusParams.Evidence.MaxAgeNumBlocks = 5
+	state.ConsensusParams.Evidence.MaxAgeDuration = 5 * time.Minute
+	pool.Update(state, types.EvidenceList{})
+	assert.EqualValues(t, 0, pool.Size())
+	evList, _ := pool.PendingEvidence(defaultEvidenceMaxBytes)
+	assert.Empty(t, evList)
+}
+
 func TestVerifyPendingEvidencePasses(t *testing.T) {
 	var height int64 = 1
 	pool, val := defaultTestPool(height)
@@ -295,6 +394,26 @@ func TestLightClientAttackEvidenceLifecycle(t *testing.T) {
 
 	remaindingEv, _ = pool.PendingEvidence(state.ConsensusParams.Evidence.MaxBytes)
 	require.Empty(t, remaindingEv)
+
+	// the complete committed evidence can be retrieved
+	attacks, err := pool.LightClientAttacks(1, height)
+	require.NoError(t, err)
+	require.Len(t, attacks, 1)
+	assert.Equal(t, hash, attacks[0].Hash())
+	assert.Equal(t, ev.ConflictingBlock.Hash(), attacks[0].ConflictingBlock.Hash())
+	assert.Equal(t, ev.ConflictingBlock.ValidatorSet.Hash(), attacks[0].ConflictingBlock.ValidatorSet.Hash())
+	assert.Equal(t, ev.ConflictingBlock.Commit.Hash(), attacks[0].ConflictingBlock.Commit.Hash())
+
+	attacks, err = pool.LightClientAttacks(commonHeight+1, height)
+	require.NoError(t, err)
+	assert.Empty(t, attacks)
+	// and found by the misbehaving validators
+	for _, val := range ev.ByzantineValidators {
+		found, err := pool.SearchEvidence(val.Address, 1, height)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, hash, found[0].Hash())
+	}
 }
 
 // Tests that restarting the evidence pool after a potential failure will recover the
@@ -346,6 +465,111 @@ func TestRecoverPendingEvidence(t *testing.T) {
 
 }
 
+func TestPruneExpiredCommittedEvidence(t *testing.T) {
+	height := int64(21)
+	pool, val := defaultTestPool(height)
+	state := pool.State()
+
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, defaultEvidenceTime.Add(21*time.Minute),
+		val, evidenceChainID)
+	require.NoError(t, pool.CheckEvidence(types.EvidenceList{ev}))
+	state.LastBlockHeight = height + 1
+	state.LastBlockTime = defaultEvidenceTime.Add(22 * time.Minute)
+	pool.Update(state, types.EvidenceList{ev})
+
+	err := pool.CheckEvidence(types.EvidenceList{ev})
+	if assert.Error(t, err) {
+		assert.Equal(t, "evidence was already committed", err.(*types.ErrInvalidEvidence).Reason.Error())
+	}
+
+	// the evidence has expired both in height and time: the committed marker is pruned and the
+	// evidence is rejected for being too old instead
+	state.LastBlockHeight = height + 30
+	state.LastBlockTime = defaultEvidenceTime.Add(60 * time.Minute)
+	pool.Update(state, types.EvidenceList{})
+
+	err = pool.CheckEvidence(types.EvidenceList{ev})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is too old")
+	}
+}
+
+func TestMigrateEvidenceStore(t *testing.T) {
+	height := int64(10)
+	val := types.NewMockPV()
+	evidenceDB := dbm.NewMemDB()
+	stateStore := initializeValidatorState(val, height)
+	state, err := stateStore.Load()
+	require.NoError(t, err)
+	blockStore := initializeBlockStore(dbm.NewMemDB(), state, val.PrivKey)
+
+	// committed evidence recorded in the version 1 format
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, defaultEvidenceTime.Add(10*time.Minute),
+		val, evidenceChainID)
+	h, err := proto.Marshal(&gogotypes.Int64Value{Value: ev.Height()})
+	require.NoError(t, err)
+	key := append([]byte{0x00}, []byte(fmt.Sprintf("%0.16X/%X", ev.Height(), ev.Hash()))...)
+	require.NoError(t, evidenceDB.Set(key, h))
+
+	pool, err := evidence.NewPool(evidenceDB, stateStore, blockStore)
+	require.NoError(t, err)
+	pool.SetLogger(log.TestingLogger())
+
+	version, err := evidenceDB.Get([]byte{0x02})
+	require.NoError(t, err)
+	assert.NotEmpty(t, version)
+
+	err = pool.CheckEvidence(types.EvidenceList{ev})
+	if assert.Error(t, err) {
+		assert.Equal(t, "evidence was already committed", err.(*types.ErrInvalidEvidence).Reason.Error())
+	}
+
+	// reopening a migrated store
+	_, err = evidence.NewPool(evidenceDB, stateStore, blockStore)
+	require.NoError(t, err)
+
+	// unknown future version
+	v, err := proto.Marshal(&gogotypes.Int64Value{Value: 100})
+	require.NoError(t, err)
+	require.NoError(t, evidenceDB.Set([]byte{0x02}, v))
+	_, err = evidence.NewPool(evidenceDB, stateStore, blockStore)
+	assert.Error(t, err)
+}
+
+// Tests that restarting the evidence pool with startup verification drops the pending
+// evidence which is no longer valid
+func TestStartupVerification(t *testing.T) {
+	height := int64(10)
+	val := types.NewMockPV()
+	evidenceDB := dbm.NewMemDB()
+	stateStore := initializeValidatorState(val, height)
+	state, err := stateStore.Load()
+	require.NoError(t, err)
+	blockStore := initializeBlockStore(dbm.NewMemDB(), state, val.PrivKey)
+	pool, err := evidence.NewPool(evidenceDB, stateStore, blockStore)
+	require.NoError(t, err)
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height,
+		defaultEvidenceTime.Add(10*time.Minute), val, evidenceChainID)
+	require.NoError(t, pool.AddEvidence(ev))
+
+	// the validator set at the evidence height isn't the one we verified against
+	otherVals, _ := types.RandValidatorSet(1, 10)
+	newStateStore := &smmocks.Store{}
+	newStateStore.On("Load").Return(state, nil)
+	newStateStore.On("LoadValidators", mock.AnythingOfType("int64")).Return(otherVals, nil)
+
+	newPool, err := evidence.NewPool(evidenceDB, newStateStore, blockStore)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, newPool.Size())
+
+	newPool, err = evidence.NewPool(evidenceDB, newStateStore, blockStore, evidence.WithStartupVerification())
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, newPool.Size())
+	assert.Nil(t, newPool.EvidenceFront())
+	evList, _ := newPool.PendingEvidence(defaultEvidenceMaxBytes)
+	assert.Empty(t, evList)
+}
+
 func initializeStateFromValidatorSet(valSet *types.ValidatorSet, height int64) sm.Store {
 	stateDB := dbm.NewMemDB()
 	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
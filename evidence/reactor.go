@@ -1,12 +1,14 @@
 package evidence
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	tmasync "github.com/Finschia/ostracon/libs/async"
 	clist "github.com/Finschia/ostracon/libs/clist"
 	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/p2p"
@@ -25,6 +27,16 @@ const (
 	broadcastEvidenceIntervalS = 10
 	// If a message fails wait this much before sending it again
 	peerRetryMessageIntervalMS = 100
+	// Give up the initial sync with a peer if its state is not known after this long
+	peerSyncTimeoutS = 10
+
+	// number of routines verifying the evidence received from peers
+	numVerifyWorkers = 4
+	// max number of received evidence waiting to be verified. Evidence received while the
+	// queue is full is dropped; honest peers gossip it again later.
+	maxVerifyQueueSize = 1000
+	// received evidence not verified within this time is dropped
+	verifyTimeoutS = 30
 )
 
 // Reactor handles evpool evidence broadcasting amongst peers.
@@ -32,21 +44,45 @@ type Reactor struct {
 	p2p.BaseReactor
 	evpool   *Pool
 	eventBus *types.EventBus
+
+	// verifies the evidence received from the peers
+	verifyPool *tmasync.Pool
 }
 
 // NewReactor returns a new Reactor with the given config and evpool.
 func NewReactor(evpool *Pool, async bool, recvBufSize int) *Reactor {
 	evR := &Reactor{
 		evpool: evpool,
+		verifyPool: tmasync.NewPool("EvidenceVerify", numVerifyWorkers, maxVerifyQueueSize,
+			tmasync.PoolTimeout(verifyTimeoutS*time.Second),
+			tmasync.PoolMetrics(evpool.metrics.VerifyPool)),
 	}
 	evR.BaseReactor = *p2p.NewBaseReactor("Evidence", evR, async, recvBufSize)
 	return evR
 }
 
+// OnStart implements Service by starting the pool verifying the received
+// evidence.
+func (evR *Reactor) OnStart() error {
+	if err := evR.BaseReactor.OnStart(); err != nil {
+		return err
+	}
+	return evR.verifyPool.Start()
+}
+
+// OnStop implements Service by stopping the pool verifying the received
+// evidence.
+func (evR *Reactor) OnStop() {
+	if err := evR.verifyPool.Stop(); err != nil {
+		evR.Logger.Error("Error stopping the evidence verification", "err", err)
+	}
+}
+
 // SetLogger sets the Logger on the reactor and the underlying Evidence.
 func (evR *Reactor) SetLogger(l log.Logger) {
 	evR.Logger = l
 	evR.evpool.SetLogger(l)
+	evR.verifyPool.SetLogger(l)
 }
 
 // GetChannels implements Reactor.
@@ -54,8 +90,15 @@ func (evR *Reactor) SetLogger(l log.Logger) {
 func (evR *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 	return []*p2p.ChannelDescriptor{
 		{
-			ID:                  EvidenceChannel,
-			Priority:            6,
+			ID: EvidenceChannel,
+			// The priorities are the shares of the bandwidth of a congested
+			// connection (the channel sending next is the one with the least
+			// recently sent bytes by priority). Evidence must reach the proposers
+			// before it expires, so it's sent along with the votes (7), ahead of
+			// the consensus state (6), blockchain, mempool and state sync (5)
+			// channels, but after the block parts (10). As it's small and rare, it
+			// hardly takes anything from the votes.
+			Priority:            7,
 			RecvMessageCapacity: maxMsgSize,
 			MessageType:         &tmproto.EvidenceList{},
 		},
@@ -67,8 +110,18 @@ func (evR *Reactor) AddPeer(peer p2p.Peer) {
 	go evR.broadcastEvidenceRoutine(peer)
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the messages with evidence failing to be decoded or its
+// ValidateBasic before they are queued.
+func (evR *Reactor) ValidateMessage(chID byte, msg proto.Message) error {
+	_, err := evidenceListFromProto(msg)
+	return err
+}
+
 // Receive implements Reactor.
-// It adds any received evidence to the evpool.
+// It queues any received evidence to be verified and added to the evpool by
+// the verification pool, so that the receive path isn't stalled by the
+// verification of many pieces of evidence.
 func (evR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 	evis, err := evidenceListFromProto(e.Message)
 	if err != nil {
@@ -78,17 +131,38 @@ func (evR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 	}
 
 	for _, ev := range evis {
-		err := evR.evpool.AddEvidence(ev)
-		switch err.(type) {
-		case *types.ErrInvalidEvidence:
-			evR.Logger.Error(err.Error())
-			// punish peer
-			evR.Switch.StopPeerForError(e.Src, err)
+		ev, src := ev, e.Src
+		if !evR.verifyPool.TrySubmit(func(context.Context) { evR.verifyEvidence(ev, src) }) {
+			evR.Logger.Info("Evidence verification queue is full, dropping evidence", "evidence", ev, "src", e.Src)
+		}
+	}
+}
+
+// verifyEvidence verifies the evidence and adds it to the evpool, punishing
+// the peer which sent it if it's invalid.
+func (evR *Reactor) verifyEvidence(ev types.Evidence, src p2p.Peer) {
+	err := evR.evpool.AddEvidence(ev)
+	switch err.(type) {
+	case *types.ErrInvalidEvidence:
+		evR.Logger.Error(err.Error())
+		// punish peer
+		evR.Switch.StopPeerForError(src, err)
+	case nil:
+	default:
+		evR.Logger.Error("Evidence has not been added", "evidence", ev, "err", err)
+	}
+}
+
+// DrainQueue blocks until all the received evidence has been verified (and
+// added to the evpool if valid), or the reactor is stopped, so that no evidence
+// is lost on shutdown.
+func (evR *Reactor) DrainQueue() {
+	evR.BaseReactor.DrainQueue()
+	for evR.verifyPool.Pending() > 0 {
+		select {
+		case <-time.After(peerRetryMessageIntervalMS * time.Millisecond):
+		case <-evR.Quit():
 			return
-		case nil:
-		default:
-			// continue to the next piece of evidence
-			evR.Logger.Error("Evidence has not been added", "evidence", evis, "err", err)
 		}
 	}
 }
@@ -112,12 +186,17 @@ func (evR *Reactor) SetEventBus(b *types.EventBus) {
 }
 
 // Modeled after the mempool routine.
+// - On start, all the pending evidence the peer can accept is sent at once, so
+// that two peers connecting exchange their inventories immediately. It isn't
+// sent again until the iteration starts from the beginning again.
 // - Evidence accumulates in a clist.
 // - Each peer has a routine that iterates through the clist,
 // sending available evidence to the peer.
 // - If we're waiting for new evidence and the list is not empty,
 // start iterating from the beginning again.
 func (evR *Reactor) broadcastEvidenceRoutine(peer p2p.Peer) {
+	synced := evR.syncPendingEvidence(peer)
+
 	var next *clist.CElement
 	for {
 		// This happens because the CElement we were looking at got garbage
@@ -139,7 +218,22 @@ func (evR *Reactor) broadcastEvidenceRoutine(peer p2p.Peer) {
 		}
 
 		ev := next.Value.(types.Evidence)
-		evis := evR.prepareEvidenceMessage(peer, ev)
+		var (
+			evis  []types.Evidence
+			retry bool
+		)
+		if _, ok := synced[string(ev.Hash())]; !ok {
+			evis, retry = evR.prepareEvidenceMessage(peer, ev)
+		}
+		if retry {
+			// the peer may be able to accept the evidence soon, don't wait for the
+			// next tick to send it, unless it has been committed or expired meanwhile
+			time.Sleep(peerRetryMessageIntervalMS * time.Millisecond)
+			if next.Removed() {
+				next = nil
+			}
+			continue
+		}
 		if len(evis) > 0 {
 			evR.Logger.Debug("Gossiping evidence to peer", "ev", ev, "peer", peer)
 			evp, err := evidenceListToProto(evis)
@@ -163,6 +257,7 @@ func (evR *Reactor) broadcastEvidenceRoutine(peer p2p.Peer) {
 			// start from the beginning every tick.
 			// TODO: only do this if we're at the end of the list!
 			next = nil
+			synced = nil
 		case <-next.NextWaitChan():
 			// see the start of the for loop for nil check
 			next = next.Next()
@@ -174,12 +269,65 @@ func (evR *Reactor) broadcastEvidenceRoutine(peer p2p.Peer) {
 	}
 }
 
+// syncPendingEvidence sends all the pending evidence (up to maxMsgSize) which
+// is valid for the peer in a single message, and returns the hashes of the
+// evidence sent. It waits for the peer state to be known for at most
+// peerSyncTimeoutS.
+func (evR *Reactor) syncPendingEvidence(peer p2p.Peer) map[string]struct{} {
+	if evR.evpool.Size() == 0 {
+		return nil
+	}
+
+	timeout := time.After(peerSyncTimeoutS * time.Second)
+	for {
+		if _, ok := peer.Get(types.PeerStateKey).(PeerState); ok {
+			break
+		}
+		select {
+		case <-time.After(peerRetryMessageIntervalMS * time.Millisecond):
+		case <-timeout:
+			return nil
+		case <-peer.Quit():
+			return nil
+		case <-evR.Quit():
+			return nil
+		}
+	}
+
+	pending, _ := evR.evpool.PendingEvidence(maxMsgSize)
+	evis := make([]types.Evidence, 0, len(pending))
+	for _, ev := range pending {
+		evs, _ := evR.prepareEvidenceMessage(peer, ev)
+		evis = append(evis, evs...)
+	}
+	if len(evis) == 0 {
+		return nil
+	}
+
+	evR.Logger.Debug("Syncing pending evidence with peer", "num", len(evis), "peer", peer)
+	evp, err := evidenceListToProto(evis)
+	if err != nil {
+		panic(err)
+	}
+	if !p2p.SendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
+		ChannelID: EvidenceChannel,
+		Message:   evp,
+	}, evR.Logger) {
+		return nil
+	}
+	synced := make(map[string]struct{}, len(evis))
+	for _, ev := range evis {
+		synced[string(ev.Hash())] = struct{}{}
+	}
+	return synced
+}
+
 // Returns the message to send to the peer, or nil if the evidence is invalid for the peer.
-// If message is nil, we should sleep and try again.
-func (evR Reactor) prepareEvidenceMessage(
+// If message is nil and retry is true, we should sleep and try again.
+func (evR *Reactor) prepareEvidenceMessage(
 	peer p2p.Peer,
 	ev types.Evidence,
-) (evis []types.Evidence) {
+) (evis []types.Evidence, retry bool) {
 
 	// make sure the peer is up to date
 	evHeight := ev.Height()
@@ -190,7 +338,7 @@ func (evR Reactor) prepareEvidenceMessage(
 		// different every time due to us using a map. Sometimes other reactors
 		// will be initialized before the consensus reactor. We should wait a few
 		// milliseconds and retry.
-		return nil
+		return nil, true
 	}
 
 	// NOTE: We only send evidence to peers where
@@ -202,7 +350,7 @@ func (evR Reactor) prepareEvidenceMessage(
 	)
 
 	if peerHeight <= evHeight { // peer is behind. sleep while he catches up
-		return nil
+		return nil, true
 	} else if ageNumBlocks > params.MaxAgeNumBlocks { // evidence is too old relative to the peer, skip
 
 		// NOTE: if evidence is too old for an honest peer, then we're behind and
@@ -216,11 +364,11 @@ func (evR Reactor) prepareEvidenceMessage(
 			"peer", peer,
 		)
 
-		return nil
+		return nil, false
 	}
 
 	// send evidence
-	return []types.Evidence{ev}
+	return []types.Evidence{ev}, false
 }
 
 // PeerState describes the state of a peer.
@@ -246,7 +394,10 @@ func evidenceListToProto(evis []types.Evidence) (*tmproto.EvidenceList, error) {
 }
 
 func evidenceListFromProto(m proto.Message) ([]types.Evidence, error) {
-	lm := m.(*tmproto.EvidenceList)
+	lm, ok := m.(*tmproto.EvidenceList)
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %T", m)
+	}
 
 	evis := make([]types.Evidence, len(lm.Evidence))
 	for i := 0; i < len(lm.Evidence); i++ {
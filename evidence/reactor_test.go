@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -232,6 +233,158 @@ func TestReactorBroadcastEvidenceMemoryLeak(t *testing.T) {
 	_ = sendEvidence(t, pool, val, 2)
 }
 
+// Tests that the pending evidence is sent to a new peer at once
+func TestReactorSyncsPendingEvidenceOnAddPeer(t *testing.T) {
+	config := cfg.TestConfig()
+	evidenceTime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("int64")).Return(
+		&types.BlockMeta{Header: types.Header{Time: evidenceTime}},
+	)
+	val := types.NewMockPV()
+	stateStore := initializeValidatorState(val, 10)
+	pool, err := evidence.NewPool(dbm.NewMemDB(), stateStore, blockStore)
+	require.NoError(t, err)
+	evList := sendEvidence(t, pool, val, 3)
+
+	synced := make(chan int, 1)
+	var sent int32
+	p := &Peer{Peer: &p2pmocks.Peer{}, EnvelopeSender: &p2pmocks.EnvelopeSender{}}
+	p.EnvelopeSender.On("SendEnvelope", mock.MatchedBy(func(i interface{}) bool {
+		e, ok := i.(p2p.Envelope)
+		return ok && e.ChannelID == evidence.EvidenceChannel
+	})).Run(func(args mock.Arguments) {
+		atomic.AddInt32(&sent, 1)
+		msg := args.Get(0).(p2p.Envelope).Message.(*tmproto.EvidenceList)
+		select {
+		case synced <- len(msg.Evidence):
+		default:
+		}
+	}).Return(true)
+	quitChan := make(chan struct{})
+	defer close(quitChan)
+	p.Peer.On("Quit").Return((<-chan struct{})(quitChan))
+	p.Peer.On("IsRunning").Return(true)
+	p.Peer.On("Get", types.PeerStateKey).Return(peerState{10})
+	p.Peer.On("ID").Return("ABC")
+	p.Peer.On("String").Return("mock")
+
+	r := evidence.NewReactor(pool, config.P2P.RecvAsync, config.P2P.EvidenceRecvBufSize)
+	r.SetLogger(log.TestingLogger())
+	r.AddPeer(p)
+
+	select {
+	case n := <-synced:
+		assert.Equal(t, len(evList), n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending evidence was not sent to the peer after 5s")
+	}
+
+	// and isn't sent again by the broadcast routine
+	time.Sleep(500 * time.Millisecond)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&sent))
+}
+
+// Tests that receiving more evidence than can be verified doesn't block the
+// receive path
+func TestReactorReceiveDoesNotBlockWhenVerificationQueueIsFull(t *testing.T) {
+	config := cfg.TestConfig()
+	val := types.NewMockPV()
+	stateStore := initializeValidatorState(val, 1)
+	pool, err := evidence.NewPool(dbm.NewMemDB(), stateStore, &mocks.BlockStore{})
+	require.NoError(t, err)
+	// the reactor isn't started so nothing is verified
+	r := evidence.NewReactor(pool, config.P2P.RecvAsync, config.P2P.EvidenceRecvBufSize)
+	r.SetLogger(log.TestingLogger())
+
+	evis := make([]types.Evidence, 1100)
+	for i := range evis {
+		evis[i] = types.NewMockDuplicateVoteEvidence(1, defaultEvidenceTime, evidenceChainID)
+	}
+	evList, err := evidenceListToProto(evis)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		r.ReceiveEnvelope(p2p.Envelope{ChannelID: evidence.EvidenceChannel, Src: &p2pmocks.Peer{}, Message: evList})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("receiving evidence blocked")
+	}
+	assert.EqualValues(t, 0, pool.Size())
+}
+
+func TestReactorValidateMessage(t *testing.T) {
+	config := cfg.TestConfig()
+	val := types.NewMockPV()
+	stateStore := initializeValidatorState(val, 1)
+	pool, err := evidence.NewPool(dbm.NewMemDB(), stateStore, &mocks.BlockStore{})
+	require.NoError(t, err)
+	r := evidence.NewReactor(pool, config.P2P.RecvAsync, config.P2P.EvidenceRecvBufSize)
+
+	evList, err := evidenceListToProto([]types.Evidence{
+		types.NewMockDuplicateVoteEvidence(1, defaultEvidenceTime, evidenceChainID),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, r.ValidateMessage(evidence.EvidenceChannel, evList))
+
+	// the evidence without votes fails to be decoded
+	invalid := &tmproto.EvidenceList{Evidence: []tmproto.Evidence{{
+		Sum: &tmproto.Evidence_DuplicateVoteEvidence{DuplicateVoteEvidence: &tmproto.DuplicateVoteEvidence{}},
+	}}}
+	assert.Error(t, r.ValidateMessage(evidence.EvidenceChannel, invalid))
+	assert.Error(t, r.ValidateMessage(evidence.EvidenceChannel, &tmproto.Evidence{}))
+}
+
+// Tests that DrainQueue waits for the received evidence to be verified and
+// added to the pool
+func TestReactorDrainQueue(t *testing.T) {
+	config := cfg.TestConfig()
+	evidenceTime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("int64")).Return(
+		&types.BlockMeta{Header: types.Header{Time: evidenceTime}},
+	)
+	val := types.NewMockPV()
+	stateStore := initializeValidatorState(val, 10)
+	pool, err := evidence.NewPool(dbm.NewMemDB(), stateStore, blockStore)
+	require.NoError(t, err)
+	r := evidence.NewReactor(pool, config.P2P.RecvAsync, config.P2P.EvidenceRecvBufSize)
+	r.SetLogger(log.TestingLogger())
+	require.NoError(t, r.Start())
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	evis := make([]types.Evidence, 5)
+	for i := range evis {
+		evis[i] = types.NewMockDuplicateVoteEvidenceWithValidator(int64(i+1), evidenceTime, val, evidenceChainID)
+	}
+	evList, err := evidenceListToProto(evis)
+	require.NoError(t, err)
+	r.ReceiveEnvelope(p2p.Envelope{ChannelID: evidence.EvidenceChannel, Src: &p2pmocks.Peer{}, Message: evList})
+
+	r.DrainQueue()
+	assert.EqualValues(t, len(evis), pool.Size())
+}
+
+func evidenceListToProto(evis []types.Evidence) (*tmproto.EvidenceList, error) {
+	evi := make([]tmproto.Evidence, len(evis))
+	for i := range evis {
+		ev, err := types.EvidenceToProto(evis[i])
+		if err != nil {
+			return nil, err
+		}
+		evi[i] = *ev
+	}
+	return &tmproto.EvidenceList{Evidence: evi}, nil
+}
+
 // evidenceLogger is a TestingLogger which uses a different
 // color for each validator ("validator" key must exist).
 func evidenceLogger() log.Logger {
@@ -0,0 +1,252 @@
+// Package inspect serves the data of a stopped node over RPC, see Inspector.
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	tmos "github.com/Finschia/ostracon/libs/os"
+	"github.com/Finschia/ostracon/libs/service"
+	tmstrings "github.com/Finschia/ostracon/libs/strings"
+	rpccore "github.com/Finschia/ostracon/rpc/core"
+	rpcserver "github.com/Finschia/ostracon/rpc/jsonrpc/server"
+	sm "github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/state/indexer"
+	blockidxkv "github.com/Finschia/ostracon/state/indexer/block/kv"
+	blockidxnull "github.com/Finschia/ostracon/state/indexer/block/null"
+	"github.com/Finschia/ostracon/state/indexer/sink/psql"
+	"github.com/Finschia/ostracon/state/txindex"
+	"github.com/Finschia/ostracon/state/txindex/kv"
+	"github.com/Finschia/ostracon/state/txindex/null"
+	"github.com/Finschia/ostracon/store"
+)
+
+// routeNames are the RPC endpoints served by the Inspector. They only read
+// from the block store, the state store and the indexers.
+var routeNames = []string{
+	"health",
+	"blockchain",
+	"block",
+	"block_by_hash",
+	"block_results",
+	"commit",
+	"tx",
+	"tx_search",
+	"block_search",
+	"validators",
+	"consensus_params",
+}
+
+// Routes returns the RPC endpoints served by the Inspector.
+func Routes() map[string]*rpcserver.RPCFunc {
+	routes := make(map[string]*rpcserver.RPCFunc, len(routeNames))
+	for _, name := range routeNames {
+		routes[name] = rpccore.Routes[name]
+	}
+	return routes
+}
+
+// Inspector serves a subset of the RPC endpoints (see Routes) from the stores
+// of a node, without starting p2p, consensus or the application, so that the
+// data of a crashed node can be looked at safely.
+//
+// Like Node, it sets the environment of the rpc/core package when started, so
+// there can only be one running Inspector (or Node) per process.
+type Inspector struct {
+	service.BaseService
+
+	config *cfg.RPCConfig
+
+	blockStore   sm.BlockStore
+	stateStore   sm.Store
+	txIndexer    txindex.TxIndexer
+	blockIndexer indexer.BlockIndexer
+
+	dbs       []dbm.DB // closed on stop, if opened by NewFromConfig
+	listeners []net.Listener
+}
+
+// New returns an Inspector serving the RPC endpoints from the given stores.
+func New(
+	config *cfg.RPCConfig,
+	blockStore sm.BlockStore,
+	stateStore sm.Store,
+	txIndexer txindex.TxIndexer,
+	blockIndexer indexer.BlockIndexer,
+	logger log.Logger,
+) *Inspector {
+	ins := &Inspector{
+		config:       config,
+		blockStore:   blockStore,
+		stateStore:   stateStore,
+		txIndexer:    txIndexer,
+		blockIndexer: blockIndexer,
+	}
+	ins.BaseService = *service.NewBaseService(logger, "Inspector", ins)
+	return ins
+}
+
+// NewFromConfig returns an Inspector serving the RPC endpoints from the data
+// directory of the node with the given configuration. The databases are opened
+// read-only, which is only supported by goleveldb.
+func NewFromConfig(config *cfg.Config, logger log.Logger) (_ *Inspector, err error) {
+	var dbs []dbm.DB
+	defer func() {
+		if err != nil {
+			for _, db := range dbs {
+				db.Close()
+			}
+		}
+	}()
+	openDB := func(name string) (dbm.DB, error) {
+		db, err := openReadOnlyDB(name, config)
+		if err == nil {
+			dbs = append(dbs, db)
+		}
+		return db, err
+	}
+
+	blockStoreDB, err := openDB("blockstore")
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := openDB("state")
+	if err != nil {
+		return nil, err
+	}
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
+	})
+
+	var (
+		txIndexer    txindex.TxIndexer
+		blockIndexer indexer.BlockIndexer
+	)
+	switch config.TxIndex.Indexer {
+	case "kv":
+		indexDB, err := openDB("tx_index")
+		if err != nil {
+			return nil, err
+		}
+		txIndexer = kv.NewTxIndex(indexDB)
+		blockIndexer = blockidxkv.New(dbm.NewPrefixDB(indexDB, []byte("block_events")))
+
+	case "psql":
+		if config.TxIndex.PsqlConn == "" {
+			return nil, errors.New(`no psql-conn is set for the "psql" indexer`)
+		}
+		state, err := stateStore.Load()
+		if err != nil {
+			return nil, err
+		}
+		es, err := psql.NewEventSink(config.TxIndex.PsqlConn, state.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("creating psql indexer: %w", err)
+		}
+		txIndexer = es.TxIndexer()
+		blockIndexer = es.BlockIndexer()
+
+	default:
+		txIndexer = &null.TxIndex{}
+		blockIndexer = &blockidxnull.BlockerIndexer{}
+	}
+
+	ins := New(config.RPC, store.NewBlockStore(blockStoreDB), stateStore, txIndexer, blockIndexer, logger)
+	ins.dbs = dbs
+	return ins, nil
+}
+
+// openReadOnlyDB opens a database of the node in read-only mode.
+func openReadOnlyDB(name string, config *cfg.Config) (dbm.DB, error) {
+	if dbm.BackendType(config.DBBackend) != dbm.GoLevelDBBackend {
+		return nil, fmt.Errorf("only goleveldb databases can be opened read-only, got %s", config.DBBackend)
+	}
+	if !tmos.FileExists(filepath.Join(config.DBDir(), name+".db")) {
+		return nil, fmt.Errorf("no %s database found in %v", name, config.DBDir())
+	}
+	db, err := dbm.NewGoLevelDBWithOpts(name, config.DBDir(), &opt.Options{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s database (is the node still running?): %w", name, err)
+	}
+	return db, nil
+}
+
+// OnStart implements service.Service by listening on the RPC addresses.
+func (ins *Inspector) OnStart() error {
+	rpccore.SetEnvironment(&rpccore.Environment{
+		BlockStore:   ins.blockStore,
+		StateStore:   ins.stateStore,
+		TxIndexer:    ins.txIndexer,
+		BlockIndexer: ins.blockIndexer,
+
+		Logger: ins.Logger.With("module", "rpc"),
+
+		Config: *ins.config,
+	})
+
+	config := rpcserver.DefaultConfig()
+	config.MaxBodyBytes = ins.config.MaxBodyBytes
+	config.MaxHeaderBytes = ins.config.MaxHeaderBytes
+	config.MaxOpenConnections = ins.config.MaxOpenConnections
+	config.ReadTimeout = ins.config.ReadTimeout
+	config.WriteTimeout = ins.config.WriteTimeout
+	config.IdleTimeout = ins.config.IdleTimeout
+
+	routes := Routes()
+	rpcLogger := ins.Logger.With("module", "rpc-server")
+	for _, listenAddr := range tmstrings.SplitAndTrim(ins.config.ListenAddress, ",", " ") {
+		if listenAddr == "" {
+			continue
+		}
+		mux := http.NewServeMux()
+		rpcserver.RegisterRPCFuncs(mux, routes, rpcLogger)
+		listener, err := rpcserver.Listen(listenAddr, config)
+		if err != nil {
+			ins.closeListeners()
+			return err
+		}
+		ins.listeners = append(ins.listeners, listener)
+		go func() {
+			if err := rpcserver.Serve(listener, mux, rpcLogger, config); err != nil {
+				ins.Logger.Error("Error serving server", "err", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// OnStop implements service.Service by closing the listeners and the databases.
+func (ins *Inspector) OnStop() {
+	ins.closeListeners()
+	for _, db := range ins.dbs {
+		if err := db.Close(); err != nil {
+			ins.Logger.Error("Error closing database", "err", err)
+		}
+	}
+}
+
+func (ins *Inspector) closeListeners() {
+	for _, l := range ins.listeners {
+		if err := l.Close(); err != nil {
+			ins.Logger.Error("Error closing listener", "listener", l, "err", err)
+		}
+	}
+	ins.listeners = nil
+}
+
+// Addrs returns the addresses the Inspector is listening on, once started.
+func (ins *Inspector) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(ins.listeners))
+	for i, l := range ins.listeners {
+		addrs[i] = l.Addr()
+	}
+	return addrs
+}
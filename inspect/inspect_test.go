@@ -0,0 +1,76 @@
+package inspect_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/inspect"
+	"github.com/Finschia/ostracon/libs/log"
+	tmnet "github.com/Finschia/ostracon/libs/net"
+	rpchttp "github.com/Finschia/ostracon/rpc/client/http"
+	sm "github.com/Finschia/ostracon/state"
+)
+
+func TestInspectorFromConfig(t *testing.T) {
+	config := cfg.ResetTestRoot("inspect_test")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	config.DBBackend = string(dbm.GoLevelDBBackend)
+	config.TxIndex.Indexer = "kv"
+	port, err := tmnet.GetFreePort()
+	require.NoError(t, err)
+	config.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", port)
+
+	// save the genesis state, as a node would have done
+	state, err := sm.MakeGenesisStateFromFile(config.GenesisFile())
+	require.NoError(t, err)
+	for _, name := range []string{"blockstore", "state", "tx_index"} {
+		db, err := dbm.NewDB(name, dbm.GoLevelDBBackend, config.DBDir())
+		require.NoError(t, err)
+		if name == "state" {
+			require.NoError(t, sm.NewStore(db, sm.StoreOptions{}).Save(state))
+		}
+		require.NoError(t, db.Close())
+	}
+
+	ins, err := inspect.NewFromConfig(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, ins.Start())
+	t.Cleanup(func() {
+		if err := ins.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	c, err := rpchttp.New(config.RPC.ListenAddress, "/websocket")
+	require.NoError(t, err)
+
+	res, err := c.Validators(context.Background(), nil, nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.BlockHeight)
+	assert.Equal(t, state.Validators.Validators, res.Validators)
+
+	txs, err := c.TxSearch(context.Background(), "tx.height > 0", false, nil, nil, "")
+	require.NoError(t, err)
+	assert.Zero(t, txs.TotalCount)
+
+	// the endpoints needing a running node aren't served
+	_, err = c.Status(context.Background())
+	require.Error(t, err)
+}
+
+func TestInspectorFromConfigOnlyGoLevelDB(t *testing.T) {
+	config := cfg.ResetTestRoot("inspect_test")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	config.DBBackend = string(dbm.MemDBBackend)
+
+	_, err := inspect.NewFromConfig(config, log.TestingLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only goleveldb")
+}
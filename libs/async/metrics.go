@@ -0,0 +1,84 @@
+package async
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+)
+
+// Metrics contains the metrics of a Pool.
+type Metrics struct {
+	// Number of tasks waiting in the queue of the pool.
+	QueueSize metrics.Gauge
+	// Number of tasks being run by the workers of the pool.
+	RunningTasks metrics.Gauge
+	// Number of tasks dropped because the queue of the pool was full.
+	DroppedTasks metrics.Counter
+	// Number of tasks whose timeout passed before or while they were run.
+	TimedOutTasks metrics.Counter
+	// Number of tasks which panicked.
+	PanickedTasks metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace, subsystem string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, subsystem, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. The subsystem names the pool (e.g. "evidence_verify"), as
+// the packages using a pool register its metrics along with their own ones.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace, subsystem string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		QueueSize: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pool_queue_size",
+			Help:      "Number of tasks waiting in the queue of the pool.",
+		}, labels).With(labelsAndValues...),
+		RunningTasks: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pool_running_tasks",
+			Help:      "Number of tasks being run by the workers of the pool.",
+		}, labels).With(labelsAndValues...),
+		DroppedTasks: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pool_dropped_tasks",
+			Help:      "Number of tasks dropped because the queue of the pool was full.",
+		}, labels).With(labelsAndValues...),
+		TimedOutTasks: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pool_timed_out_tasks",
+			Help:      "Number of tasks whose timeout passed before or while they were run.",
+		}, labels).With(labelsAndValues...),
+		PanickedTasks: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pool_panicked_tasks",
+			Help:      "Number of tasks which panicked.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		QueueSize:     discard.NewGauge(),
+		RunningTasks:  discard.NewGauge(),
+		DroppedTasks:  discard.NewCounter(),
+		TimedOutTasks: discard.NewCounter(),
+		PanickedTasks: discard.NewCounter(),
+	}
+}
@@ -0,0 +1,169 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Finschia/ostracon/libs/service"
+)
+
+// PoolTask is a task run by a Pool. Its context is done once the timeout of
+// the pool passed since the task was submitted, or when the pool is stopped;
+// the tasks which may run long must return then.
+type PoolTask func(ctx context.Context)
+
+// Pool runs the tasks submitted to it on a fixed number of workers, taking
+// them from a bounded queue, so that a burst of work (e.g. of messages from
+// the peers) can't spawn an unbounded number of goroutines. The tasks submitted
+// while the queue is full are dropped, those whose timeout passed while they
+// were queued aren't run, and the panics of the tasks are recovered and
+// logged, not to take down the node.
+type Pool struct {
+	service.BaseService
+
+	workers int
+	timeout time.Duration
+	metrics *Metrics
+
+	queue chan poolTask
+	// number of tasks queued or running
+	pending int32
+
+	// done when the pool is stopped
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type poolTask struct {
+	run      PoolTask
+	deadline time.Time // zero without timeout
+}
+
+// PoolOption sets an optional parameter on the Pool.
+type PoolOption func(*Pool)
+
+// PoolTimeout sets the time after which a task submitted is timed out: it
+// isn't run if it was queued longer than that, and its context is done once
+// that time has passed. Default: no timeout.
+func PoolTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) { p.timeout = timeout }
+}
+
+// PoolMetrics sets the metrics.
+func PoolMetrics(metrics *Metrics) PoolOption {
+	return func(p *Pool) { p.metrics = metrics }
+}
+
+// NewPool returns a Pool running the tasks on the given number of workers,
+// and queueing up to queueSize tasks. The tasks may be submitted before the
+// pool is started; they are run once it is.
+func NewPool(name string, workers, queueSize int, options ...PoolOption) *Pool {
+	if workers <= 0 {
+		panic(fmt.Sprintf("async: pool %s needs at least one worker, got %d", name, workers))
+	}
+	p := &Pool{
+		workers: workers,
+		metrics: NopMetrics(),
+		queue:   make(chan poolTask, queueSize),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.BaseService = *service.NewBaseService(nil, name, p)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// OnStart implements Service by starting the workers.
+func (p *Pool) OnStart() error {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.workerRoutine()
+	}
+	return nil
+}
+
+// OnStop implements Service by canceling the context of the running tasks,
+// and waiting for them to return. The tasks left in the queue are dropped.
+func (p *Pool) OnStop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// TrySubmit queues the task without blocking, and reports whether it was;
+// it isn't if the queue is full or the pool is stopped.
+func (p *Pool) TrySubmit(task PoolTask) bool {
+	select {
+	case <-p.ctx.Done():
+		return false
+	default:
+	}
+
+	t := poolTask{run: task}
+	if p.timeout > 0 {
+		t.deadline = time.Now().Add(p.timeout)
+	}
+	atomic.AddInt32(&p.pending, 1)
+	p.metrics.QueueSize.Add(1)
+	select {
+	case p.queue <- t:
+		return true
+	default:
+		atomic.AddInt32(&p.pending, -1)
+		p.metrics.QueueSize.Add(-1)
+		p.metrics.DroppedTasks.Add(1)
+		return false
+	}
+}
+
+// Pending returns the number of tasks queued or running.
+func (p *Pool) Pending() int {
+	return int(atomic.LoadInt32(&p.pending))
+}
+
+func (p *Pool) workerRoutine() {
+	defer p.wg.Done()
+	for {
+		select {
+		case t := <-p.queue:
+			p.metrics.QueueSize.Add(-1)
+			p.run(t)
+			atomic.AddInt32(&p.pending, -1)
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// run runs the task, unless its timeout has passed, recovering from its panic.
+func (p *Pool) run(t poolTask) {
+	ctx := p.ctx
+	if !t.deadline.IsZero() {
+		if time.Now().After(t.deadline) {
+			p.metrics.TimedOutTasks.Add(1)
+			p.Logger.Info("Task timed out in the queue, dropping it", "pool", p.String())
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(p.ctx, t.deadline)
+		defer cancel()
+	}
+
+	p.metrics.RunningTasks.Add(1)
+	defer p.metrics.RunningTasks.Add(-1)
+	defer func() {
+		if r := recover(); r != nil {
+			p.metrics.PanickedTasks.Add(1)
+			p.Logger.Error("Task panicked", "pool", p.String(), "err", r, "stack", string(debug.Stack()))
+		}
+	}()
+	t.run(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		p.metrics.TimedOutTasks.Add(1)
+	}
+}
@@ -0,0 +1,102 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolBounded(t *testing.T) {
+	p := NewPool("test", 2, 2)
+	release := make(chan struct{})
+	var running, maxRunning int32
+	task := func(ctx context.Context) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+	}
+
+	// the tasks submitted before the start are queued, until the queue is full
+	assert.True(t, p.TrySubmit(task))
+	assert.True(t, p.TrySubmit(task))
+	assert.False(t, p.TrySubmit(task), "queue full")
+	require.NoError(t, p.Start())
+	defer p.Stop() //nolint:errcheck // ignore for tests
+
+	// two tasks run, two more wait in the queue
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&running) == 2 }, time.Second, time.Millisecond)
+	assert.True(t, p.TrySubmit(task))
+	assert.True(t, p.TrySubmit(task))
+	assert.False(t, p.TrySubmit(task), "queue full")
+	assert.Equal(t, 4, p.Pending())
+
+	close(release)
+	assert.Eventually(t, func() bool { return p.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&maxRunning))
+}
+
+func TestPoolTimeout(t *testing.T) {
+	p := NewPool("test", 1, 10, PoolTimeout(50*time.Millisecond))
+	require.NoError(t, p.Start())
+	defer p.Stop() //nolint:errcheck // ignore for tests
+
+	// the context of a task is done after the timeout
+	var timedOut, run int32
+	require.True(t, p.TrySubmit(func(ctx context.Context) {
+		<-ctx.Done()
+		if ctx.Err() == context.DeadlineExceeded {
+			atomic.StoreInt32(&timedOut, 1)
+		}
+	}))
+	// and a task queued longer than the timeout isn't run
+	require.True(t, p.TrySubmit(func(ctx context.Context) {
+		atomic.StoreInt32(&run, 1)
+	}))
+	assert.Eventually(t, func() bool { return p.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&timedOut))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&run))
+}
+
+func TestPoolPanic(t *testing.T) {
+	p := NewPool("test", 1, 10)
+	require.NoError(t, p.Start())
+	defer p.Stop() //nolint:errcheck // ignore for tests
+
+	// the worker survives the panic of a task
+	done := make(chan struct{})
+	require.True(t, p.TrySubmit(func(ctx context.Context) { panic("boom") }))
+	require.True(t, p.TrySubmit(func(ctx context.Context) { close(done) }))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the task after the panic wasn't run")
+	}
+}
+
+func TestPoolStop(t *testing.T) {
+	p := NewPool("test", 1, 10)
+	require.NoError(t, p.Start())
+
+	// stopping cancels the running tasks, and the pool takes no more tasks
+	started := make(chan struct{})
+	var canceled int32
+	require.True(t, p.TrySubmit(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		atomic.StoreInt32(&canceled, 1)
+	}))
+	<-started
+	require.NoError(t, p.Stop())
+	assert.EqualValues(t, 1, atomic.LoadInt32(&canceled))
+	assert.False(t, p.TrySubmit(func(ctx context.Context) {}))
+}
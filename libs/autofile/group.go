@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/libs/tempfile"
 )
 
 const (
@@ -322,6 +323,11 @@ func (g *Group) RotateFile() {
 	if err := os.Rename(headPath, indexPath); err != nil {
 		panic(err)
 	}
+	// persist the rename, otherwise the rotated file could be lost, or be
+	// found again as the head, after a power loss
+	if err := tempfile.SyncDir(filepath.Dir(headPath)); err != nil {
+		panic(err)
+	}
 
 	g.maxIndex++
 }
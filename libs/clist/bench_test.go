@@ -1,6 +1,10 @@
 package clist
 
-import "testing"
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
 
 func BenchmarkDetaching(b *testing.B) {
 	lst := New()
@@ -11,7 +15,7 @@ func BenchmarkDetaching(b *testing.B) {
 	nxt := start.Next()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		start.removed = true
+		start.removed.Store(true)
 		start.DetachNext()
 		start.DetachPrev()
 		tmp := nxt
@@ -44,3 +48,116 @@ func BenchmarkPushBack(b *testing.B) {
 		lst.PushBack(i)
 	}
 }
+
+// newFilledList returns a list of n elements.
+func newFilledList(n int) *CList {
+	lst := New()
+	for i := 0; i < n; i++ {
+		lst.PushBack(i)
+	}
+	return lst
+}
+
+// BenchmarkParallelScan measures the traversals of the list by concurrent
+// goroutines, e.g. the gossip routines of the peers.
+func BenchmarkParallelScan(b *testing.B) {
+	lst := newFilledList(1000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		e := lst.Front()
+		for pb.Next() {
+			if e = e.Next(); e == nil {
+				e = lst.Front()
+			}
+		}
+	})
+}
+
+// BenchmarkParallelLen measures the concurrent calls of Len, e.g. to check the
+// size of the mempool.
+func BenchmarkParallelLen(b *testing.B) {
+	lst := newFilledList(10)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = lst.Len()
+		}
+	})
+}
+
+// BenchmarkParallelScanWithWriter measures the traversals of the list by
+// concurrent goroutines, while another goroutine adds and removes elements.
+func BenchmarkParallelScanWithWriter(b *testing.B) {
+	lst := newFilledList(1000)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			front := lst.Front()
+			lst.Remove(front)
+			front.DetachNext()
+			lst.PushBack(i)
+		}
+	}()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		e := lst.Front()
+		for pb.Next() {
+			if e = e.Next(); e == nil {
+				e = lst.Front()
+			}
+			_ = e.Removed()
+		}
+	})
+	b.StopTimer()
+	close(done)
+	<-stopped
+}
+
+// BenchmarkPushBackRemove measures the updates of the list, as the txs added
+// to and removed from the mempool.
+func BenchmarkPushBackRemove(b *testing.B) {
+	lst := newFilledList(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		front := lst.Front()
+		lst.Remove(front)
+		front.DetachPrev()
+		front.DetachNext()
+		lst.PushBack(i)
+	}
+}
+
+// BenchmarkNextWaitChan measures the delivery of the new elements to the
+// goroutines waiting for them, as the txs to the gossip routines.
+func BenchmarkNextWaitChan(b *testing.B) {
+	for _, readers := range []int{1, 16, 64} {
+		b.Run(fmt.Sprintf("readers=%d", readers), func(b *testing.B) {
+			lst := New()
+			first := lst.PushBack(-1)
+			var wg sync.WaitGroup
+			wg.Add(readers)
+			for r := 0; r < readers; r++ {
+				go func() {
+					defer wg.Done()
+					e := first
+					for n := 0; n < b.N; n++ {
+						<-e.NextWaitChan()
+						e = e.Next()
+					}
+				}()
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				lst.PushBack(i)
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -13,7 +13,7 @@ to ensure garbage collection of removed elements.
 
 import (
 	"fmt"
-	"sync"
+	"sync/atomic"
 
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 )
@@ -27,48 +27,48 @@ const MaxLength = int(^uint(0) >> 1)
 CElement is an element of a linked-list
 Traversal from a CElement is goroutine-safe.
 
-We can't avoid using WaitGroups or for-loops given the documentation
-spec without re-implementing the primitives that already exist in
-golang/sync. Notice that WaitGroup allows many go-routines to be
-simultaneously released, which is what we want. Mutex doesn't do
-this. RWMutex does this, but it's clumsy to use in the way that a
-WaitGroup would be used -- and we'd end up having two RWMutex's for
-prev/next each, which is doubly confusing.
-
-sync.Cond would be sort-of useful, but we don't need a write-lock in
-the for-loop. Use sync.Cond when you need serial access to the
-"condition". In our case our condition is if `next != nil || removed`,
-and there's no reason to serialize that condition for goroutines
-waiting on NextWait() (since it's just a read operation).
+The traversals don't take any lock: the links of the elements are
+atomic pointers, so that the goroutines scanning the list (e.g. the
+gossip routines of each peer) don't contend with each other, nor with
+the goroutine updating it. Only the updates of an element are
+serialized, by its mutex.
+
+The goroutines waiting for the next (or previous) element wait for a
+channel, closed when the element is linked to it, or removed. The
+channel is replaced when the link is cleared, and always before it is,
+so that a waiter who sees no link has the channel which will be closed
+when the link is set again.
 */
 type CElement struct {
-	mtx        tmsync.RWMutex
-	prev       *CElement
-	prevWg     *sync.WaitGroup
-	prevWaitCh chan struct{}
-	next       *CElement
-	nextWg     *sync.WaitGroup
-	nextWaitCh chan struct{}
-	removed    bool
+	mtx        tmsync.Mutex // serializes the updates
+	prev       atomic.Pointer[CElement]
+	prevWaitCh atomic.Pointer[chan struct{}]
+	next       atomic.Pointer[CElement]
+	nextWaitCh atomic.Pointer[chan struct{}]
+	removed    atomic.Bool
 
 	Value interface{} // immutable
 }
 
+func newCElement(v interface{}) *CElement {
+	e := &CElement{Value: v}
+	e.prevWaitCh.Store(newWaitCh())
+	e.nextWaitCh.Store(newWaitCh())
+	return e
+}
+
 // Blocking implementation of Next().
 // May return nil iff CElement was tail and got removed.
 func (e *CElement) NextWait() *CElement {
 	for {
-		e.mtx.RLock()
-		next := e.next
-		nextWg := e.nextWg
-		removed := e.removed
-		e.mtx.RUnlock()
-
-		if next != nil || removed {
+		// the channel must be loaded before the link, see CElement
+		waitCh := e.nextWaitCh.Load()
+		next := e.next.Load()
+		if next != nil || e.removed.Load() {
 			return next
 		}
 
-		nextWg.Wait()
+		<-*waitCh
 		// e.next doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -78,135 +78,103 @@ func (e *CElement) NextWait() *CElement {
 // May return nil iff CElement was head and got removed.
 func (e *CElement) PrevWait() *CElement {
 	for {
-		e.mtx.RLock()
-		prev := e.prev
-		prevWg := e.prevWg
-		removed := e.removed
-		e.mtx.RUnlock()
-
-		if prev != nil || removed {
+		waitCh := e.prevWaitCh.Load()
+		prev := e.prev.Load()
+		if prev != nil || e.removed.Load() {
 			return prev
 		}
 
-		prevWg.Wait()
+		<-*waitCh
 	}
 }
 
 // PrevWaitChan can be used to wait until Prev becomes not nil. Once it does,
 // channel will be closed.
 func (e *CElement) PrevWaitChan() <-chan struct{} {
-	e.mtx.RLock()
-	defer e.mtx.RUnlock()
-
-	return e.prevWaitCh
+	return *e.prevWaitCh.Load()
 }
 
 // NextWaitChan can be used to wait until Next becomes not nil. Once it does,
 // channel will be closed.
 func (e *CElement) NextWaitChan() <-chan struct{} {
-	e.mtx.RLock()
-	defer e.mtx.RUnlock()
-
-	return e.nextWaitCh
+	return *e.nextWaitCh.Load()
 }
 
 // Nonblocking, may return nil if at the end.
 func (e *CElement) Next() *CElement {
-	e.mtx.RLock()
-	val := e.next
-	e.mtx.RUnlock()
-	return val
+	return e.next.Load()
 }
 
 // Nonblocking, may return nil if at the end.
 func (e *CElement) Prev() *CElement {
-	e.mtx.RLock()
-	prev := e.prev
-	e.mtx.RUnlock()
-	return prev
+	return e.prev.Load()
 }
 
 func (e *CElement) Removed() bool {
-	e.mtx.RLock()
-	isRemoved := e.removed
-	e.mtx.RUnlock()
-	return isRemoved
+	return e.removed.Load()
 }
 
 func (e *CElement) DetachNext() {
 	e.mtx.Lock()
-	if !e.removed {
+	if !e.removed.Load() {
 		e.mtx.Unlock()
 		panic("DetachNext() must be called after Remove(e)")
 	}
-	e.next = nil
+	e.next.Store(nil)
 	e.mtx.Unlock()
 }
 
 func (e *CElement) DetachPrev() {
 	e.mtx.Lock()
-	if !e.removed {
+	if !e.removed.Load() {
 		e.mtx.Unlock()
 		panic("DetachPrev() must be called after Remove(e)")
 	}
-	e.prev = nil
+	e.prev.Store(nil)
 	e.mtx.Unlock()
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on nextWg.
+// concurrent goroutines waiting on NextWait.
 func (e *CElement) SetNext(newNext *CElement) {
 	e.mtx.Lock()
-
-	oldNext := e.next
-	e.next = newNext
-	if oldNext != nil && newNext == nil {
-		// See https://golang.org/pkg/sync/:
-		//
-		// If a WaitGroup is reused to wait for several independent sets of
-		// events, new Add calls must happen after all previous Wait calls have
-		// returned.
-		e.nextWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.nextWaitCh = make(chan struct{})
-	}
-	if oldNext == nil && newNext != nil {
-		e.nextWg.Done()
-		close(e.nextWaitCh)
-	}
+	setLink(&e.next, &e.nextWaitCh, newNext)
 	e.mtx.Unlock()
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on prevWg
+// concurrent goroutines waiting on PrevWait.
 func (e *CElement) SetPrev(newPrev *CElement) {
 	e.mtx.Lock()
+	setLink(&e.prev, &e.prevWaitCh, newPrev)
+	e.mtx.Unlock()
+}
 
-	oldPrev := e.prev
-	e.prev = newPrev
-	if oldPrev != nil && newPrev == nil {
-		e.prevWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.prevWaitCh = make(chan struct{})
+// setLink sets the link (next or prev) of an element, closing its wait channel
+// if it is set, or replacing it if it is cleared.
+func setLink(link *atomic.Pointer[CElement], waitCh *atomic.Pointer[chan struct{}], newLink *CElement) {
+	oldLink := link.Load()
+	if oldLink != nil && newLink == nil {
+		// the channel is replaced before the link is cleared (see CElement)
+		waitCh.Store(newWaitCh())
 	}
-	if oldPrev == nil && newPrev != nil {
-		e.prevWg.Done()
-		close(e.prevWaitCh)
+	link.Store(newLink)
+	if oldLink == nil && newLink != nil {
+		close(*waitCh.Load())
 	}
-	e.mtx.Unlock()
 }
 
 func (e *CElement) SetRemoved() {
 	e.mtx.Lock()
 
-	e.removed = true
+	e.removed.Store(true)
 
 	// This wakes up anyone waiting in either direction.
-	if e.prev == nil {
-		e.prevWg.Done()
-		close(e.prevWaitCh)
+	if e.prev.Load() == nil {
+		close(*e.prevWaitCh.Load())
 	}
-	if e.next == nil {
-		e.nextWg.Done()
-		close(e.nextWaitCh)
+	if e.next.Load() == nil {
+		close(*e.nextWaitCh.Load())
 	}
 	e.mtx.Unlock()
 }
@@ -217,24 +185,25 @@ func (e *CElement) SetRemoved() {
 // The zero value for CList is an empty list ready to use.
 // Operations are goroutine-safe.
 // Panics if length grows beyond the max.
+//
+// The reads (Len, Front, Back and the traversals) don't take any lock, only
+// the updates (PushBack and Remove) are serialized.
 type CList struct {
-	mtx    tmsync.RWMutex
-	wg     *sync.WaitGroup
-	waitCh chan struct{}
-	head   *CElement // first element
-	tail   *CElement // last element
-	len    int       // list length
-	maxLen int       // max list length
+	mtx    tmsync.Mutex // serializes the updates
+	waitCh atomic.Pointer[chan struct{}]
+	head   atomic.Pointer[CElement] // first element
+	tail   atomic.Pointer[CElement] // last element
+	len    atomic.Int64             // list length
+	maxLen int                      // max list length
 }
 
 func (l *CList) Init() *CList {
 	l.mtx.Lock()
 
-	l.wg = waitGroup1()
-	l.waitCh = make(chan struct{})
-	l.head = nil
-	l.tail = nil
-	l.len = 0
+	l.waitCh.Store(newWaitCh())
+	l.head.Store(nil)
+	l.tail.Store(nil)
+	l.len.Store(0)
 	l.mtx.Unlock()
 	return l
 }
@@ -251,53 +220,38 @@ func newWithMax(maxLength int) *CList {
 }
 
 func (l *CList) Len() int {
-	l.mtx.RLock()
-	len := l.len
-	l.mtx.RUnlock()
-	return len
+	return int(l.len.Load())
 }
 
 func (l *CList) Front() *CElement {
-	l.mtx.RLock()
-	head := l.head
-	l.mtx.RUnlock()
-	return head
+	return l.head.Load()
 }
 
 func (l *CList) FrontWait() *CElement {
 	// Loop until the head is non-nil else wait and try again
 	for {
-		l.mtx.RLock()
-		head := l.head
-		wg := l.wg
-		l.mtx.RUnlock()
-
+		waitCh := l.waitCh.Load()
+		head := l.head.Load()
 		if head != nil {
 			return head
 		}
-		wg.Wait()
+		<-*waitCh
 		// NOTE: If you think l.head exists here, think harder.
 	}
 }
 
 func (l *CList) Back() *CElement {
-	l.mtx.RLock()
-	back := l.tail
-	l.mtx.RUnlock()
-	return back
+	return l.tail.Load()
 }
 
 func (l *CList) BackWait() *CElement {
 	for {
-		l.mtx.RLock()
-		tail := l.tail
-		wg := l.wg
-		l.mtx.RUnlock()
-
+		waitCh := l.waitCh.Load()
+		tail := l.tail.Load()
 		if tail != nil {
 			return tail
 		}
-		wg.Wait()
+		<-*waitCh
 		// l.tail doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -306,10 +260,7 @@ func (l *CList) BackWait() *CElement {
 // WaitChan can be used to wait until Front or Back becomes not nil. Once it
 // does, channel will be closed.
 func (l *CList) WaitChan() <-chan struct{} {
-	l.mtx.Lock()
-	defer l.mtx.Unlock()
-
-	return l.waitCh
+	return *l.waitCh.Load()
 }
 
 // Panics if list grows beyond its max length.
@@ -317,35 +268,29 @@ func (l *CList) PushBack(v interface{}) *CElement {
 	l.mtx.Lock()
 
 	// Construct a new element
-	e := &CElement{
-		prev:       nil,
-		prevWg:     waitGroup1(),
-		prevWaitCh: make(chan struct{}),
-		next:       nil,
-		nextWg:     waitGroup1(),
-		nextWaitCh: make(chan struct{}),
-		removed:    false,
-		Value:      v,
-	}
+	e := newCElement(v)
 
-	// Release waiters on FrontWait/BackWait maybe
-	if l.len == 0 {
-		l.wg.Done()
-		close(l.waitCh)
-	}
-	if l.len >= l.maxLen {
+	length := int(l.len.Load())
+	if length >= l.maxLen {
+		l.mtx.Unlock()
 		panic(fmt.Sprintf("clist: maximum length list reached %d", l.maxLen))
 	}
-	l.len++
+	l.len.Store(int64(length + 1))
 
 	// Modify the tail
-	if l.tail == nil {
-		l.head = e
-		l.tail = e
+	tail := l.tail.Load()
+	if tail == nil {
+		l.head.Store(e)
+		l.tail.Store(e)
 	} else {
-		e.SetPrev(l.tail) // We must init e first.
-		l.tail.SetNext(e) // This will make e accessible.
-		l.tail = e        // Update the list.
+		e.SetPrev(tail) // We must init e first.
+		tail.SetNext(e) // This will make e accessible.
+		l.tail.Store(e) // Update the list.
+	}
+
+	// Release waiters on FrontWait/BackWait maybe
+	if length == 0 {
+		close(*l.waitCh.Load())
 	}
 	l.mtx.Unlock()
 	return e
@@ -359,49 +304,50 @@ func (l *CList) Remove(e *CElement) interface{} {
 	prev := e.Prev()
 	next := e.Next()
 
-	if l.head == nil || l.tail == nil {
+	head, tail := l.head.Load(), l.tail.Load()
+	if head == nil || tail == nil {
 		l.mtx.Unlock()
 		panic("Remove(e) on empty CList")
 	}
-	if prev == nil && l.head != e {
+	if prev == nil && head != e {
 		l.mtx.Unlock()
 		panic("Remove(e) with false head")
 	}
-	if next == nil && l.tail != e {
+	if next == nil && tail != e {
 		l.mtx.Unlock()
 		panic("Remove(e) with false tail")
 	}
 
-	// If we're removing the only item, make CList FrontWait/BackWait wait.
-	if l.len == 1 {
-		l.wg = waitGroup1() // WaitGroups are difficult to re-use.
-		l.waitCh = make(chan struct{})
+	// If we're removing the only item, make CList FrontWait/BackWait wait,
+	// replacing the channel before the head and the tail are cleared.
+	length := l.len.Load()
+	if length == 1 {
+		l.waitCh.Store(newWaitCh())
 	}
 
 	// Update l.len
-	l.len--
+	l.len.Store(length - 1)
 
 	// Connect next/prev and set head/tail
 	if prev == nil {
-		l.head = next
+		l.head.Store(next)
 	} else {
 		prev.SetNext(next)
 	}
 	if next == nil {
-		l.tail = prev
+		l.tail.Store(prev)
 	} else {
 		next.SetPrev(prev)
 	}
 
-	// Set .Done() on e, otherwise waiters will wait forever.
+	// Close the wait channels of e, otherwise waiters will wait forever.
 	e.SetRemoved()
 
 	l.mtx.Unlock()
 	return e.Value
 }
 
-func waitGroup1() (wg *sync.WaitGroup) {
-	wg = &sync.WaitGroup{}
-	wg.Add(1)
-	return
+func newWaitCh() *chan struct{} {
+	ch := make(chan struct{})
+	return &ch
 }
@@ -3,6 +3,7 @@ package clist
 import (
 	"fmt"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -312,3 +313,44 @@ FOR_LOOP2:
 		t.Fatalf("number of pushed items (%d) not equal to number of seen items (%d)", pushed, seen)
 	}
 }
+
+func TestConcurrentNextWait(t *testing.T) {
+	const n = 1000
+	l := New()
+	first := l.PushBack(-1)
+
+	// the readers see all the elements in order, the tail being removed and
+	// pushed again while they are waiting for it
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := first
+			for i := 0; i < n; {
+				next := e.NextWait()
+				if next == nil {
+					// the tail was removed, start again from the front
+					e = l.FrontWait()
+					continue
+				}
+				e = next
+				if v := e.Value.(int); v >= 0 {
+					assert.Equal(t, i, v)
+					i++
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		tmp := l.PushBack(-2)
+		l.PushBack(i)
+		l.Remove(tmp)
+		if i%10 == 0 {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, n+1, l.Len())
+}
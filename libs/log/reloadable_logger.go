@@ -0,0 +1,79 @@
+package log
+
+import (
+	"sync"
+)
+
+// BuildLoggerFunc builds a logger for the given format and level.
+type BuildLoggerFunc func(format, level string) (Logger, error)
+
+type reloadableBase struct {
+	mtx    sync.RWMutex
+	logger Logger
+	build  BuildLoggerFunc
+}
+
+// ReloadableLogger is a Logger whose format and level can be changed while
+// running, e.g. when reloading the configuration. The loggers returned by With
+// share the same underlying logger, so reloading one reloads all of them.
+type ReloadableLogger struct {
+	base    *reloadableBase
+	keyvals []interface{}
+}
+
+var _ Logger = (*ReloadableLogger)(nil)
+
+// NewReloadableLogger returns a ReloadableLogger using build to create the
+// underlying logger for the given format and level.
+func NewReloadableLogger(build BuildLoggerFunc, format, level string) (*ReloadableLogger, error) {
+	logger, err := build(format, level)
+	if err != nil {
+		return nil, err
+	}
+	return &ReloadableLogger{base: &reloadableBase{logger: logger, build: build}}, nil
+}
+
+// Reload replaces the underlying logger by a new one with the given format
+// and level. On error, the underlying logger is left unchanged.
+func (l *ReloadableLogger) Reload(format, level string) error {
+	logger, err := l.base.build(format, level)
+	if err != nil {
+		return err
+	}
+	l.base.mtx.Lock()
+	l.base.logger = logger
+	l.base.mtx.Unlock()
+	return nil
+}
+
+func (l *ReloadableLogger) current() Logger {
+	l.base.mtx.RLock()
+	defer l.base.mtx.RUnlock()
+	if len(l.keyvals) == 0 {
+		return l.base.logger
+	}
+	return l.base.logger.With(l.keyvals...)
+}
+
+// Debug implements Logger.
+func (l *ReloadableLogger) Debug(msg string, keyvals ...interface{}) {
+	l.current().Debug(msg, keyvals...)
+}
+
+// Info implements Logger.
+func (l *ReloadableLogger) Info(msg string, keyvals ...interface{}) {
+	l.current().Info(msg, keyvals...)
+}
+
+// Error implements Logger.
+func (l *ReloadableLogger) Error(msg string, keyvals ...interface{}) {
+	l.current().Error(msg, keyvals...)
+}
+
+// With implements Logger.
+func (l *ReloadableLogger) With(keyvals ...interface{}) Logger {
+	kvs := make([]interface{}, 0, len(l.keyvals)+len(keyvals))
+	kvs = append(kvs, l.keyvals...)
+	kvs = append(kvs, keyvals...)
+	return &ReloadableLogger{base: l.base, keyvals: kvs}
+}
@@ -0,0 +1,43 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/libs/log"
+)
+
+func TestReloadableLogger(t *testing.T) {
+	var buf bytes.Buffer
+	build := func(format, level string) (log.Logger, error) {
+		if format != "json" {
+			return nil, errors.New("unsupported format")
+		}
+		return log.ParseLogLevel(level, log.NewOCJSONLoggerNoTS(&buf), "info")
+	}
+
+	rl, err := log.NewReloadableLogger(build, "json", "info")
+	require.NoError(t, err)
+	logger := rl.With("module", "test")
+
+	logger.Debug("before")
+	logger.Info("before")
+	assert.Equal(t, `{"_msg":"before","level":"info","module":"test"}`, strings.TrimSpace(buf.String()))
+
+	// the loggers created by With are reloaded too
+	buf.Reset()
+	require.NoError(t, rl.Reload("json", "debug"))
+	logger.Debug("after")
+	assert.Equal(t, `{"_msg":"after","level":"debug","module":"test"}`, strings.TrimSpace(buf.String()))
+
+	// a failed reload leaves the logger unchanged
+	buf.Reset()
+	require.Error(t, rl.Reload("plain", "info"))
+	logger.Debug("failed")
+	assert.Equal(t, `{"_msg":"failed","level":"debug","module":"test"}`, strings.TrimSpace(buf.String()))
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewSampledLogger returns a Logger logging a message at most once per
+// interval for each key, so that the messages repeated on a hot path (e.g. for
+// each message of a misbehaving peer) don't flood the logs. The key of a
+// message is its level, its text and the values of keys (e.g. "peer") in its
+// key-value pairs or the ones of With.
+//
+// The number of messages dropped since the last one logged for a key is
+// logged as "suppressed" with the next one, unless it doesn't come within two
+// intervals.
+func NewSampledLogger(next Logger, interval time.Duration, keys ...string) Logger {
+	return &sampledLogger{
+		next: next,
+		sampler: &sampler{
+			interval: interval,
+			keys:     keys,
+			samples:  make(map[string]*sample),
+		},
+	}
+}
+
+type sampledLogger struct {
+	next    Logger
+	sampler *sampler
+	context string // values of the keys in the key-value pairs of With
+}
+
+func (l *sampledLogger) Debug(msg string, keyvals ...interface{}) {
+	if keyvals, ok := l.sample("debug", msg, keyvals); ok {
+		l.next.Debug(msg, keyvals...)
+	}
+}
+
+func (l *sampledLogger) Info(msg string, keyvals ...interface{}) {
+	if keyvals, ok := l.sample("info", msg, keyvals); ok {
+		l.next.Info(msg, keyvals...)
+	}
+}
+
+func (l *sampledLogger) Error(msg string, keyvals ...interface{}) {
+	if keyvals, ok := l.sample("error", msg, keyvals); ok {
+		l.next.Error(msg, keyvals...)
+	}
+}
+
+func (l *sampledLogger) With(keyvals ...interface{}) Logger {
+	return &sampledLogger{
+		next:    l.next.With(keyvals...),
+		sampler: l.sampler,
+		context: l.context + l.sampler.values(keyvals),
+	}
+}
+
+// sample reports whether the message is logged, and returns its key-value
+// pairs with the number of messages suppressed before it, if any.
+func (l *sampledLogger) sample(level, msg string, keyvals []interface{}) ([]interface{}, bool) {
+	key := level + "\x00" + msg + "\x00" + l.context + l.sampler.values(keyvals)
+	ok, suppressed := l.sampler.allow(key, time.Now())
+	if !ok {
+		return nil, false
+	}
+	if suppressed > 0 {
+		keyvals = append(keyvals[:len(keyvals):len(keyvals)], "suppressed", suppressed)
+	}
+	return keyvals, true
+}
+
+type sampler struct {
+	mtx       sync.Mutex
+	interval  time.Duration
+	keys      []string
+	samples   map[string]*sample
+	lastPrune time.Time
+}
+
+type sample struct {
+	last       time.Time // time of the last message logged
+	suppressed int       // messages dropped since last
+}
+
+// values returns the values of the sampler keys in keyvals.
+func (s *sampler) values(keyvals []interface{}) string {
+	if len(s.keys) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		k, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		for _, key := range s.keys {
+			if k == key {
+				fmt.Fprintf(&b, "%s=%v\x00", k, keyvals[i+1])
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+// allow reports whether the message of the key is logged at time now, and
+// returns the number of messages of the key dropped since the last one logged.
+func (s *sampler) allow(key string, now time.Time) (bool, int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// the keys without any message logged for two intervals are forgotten, not
+	// to grow with e.g. the peers
+	if now.Sub(s.lastPrune) >= s.interval {
+		for k, smp := range s.samples {
+			if now.Sub(smp.last) >= 2*s.interval {
+				delete(s.samples, k)
+			}
+		}
+		s.lastPrune = now
+	}
+
+	smp, ok := s.samples[key]
+	if !ok {
+		s.samples[key] = &sample{last: now}
+		return true, 0
+	}
+	if now.Sub(smp.last) < s.interval {
+		smp.suppressed++
+		return false, 0
+	}
+	suppressed := smp.suppressed
+	smp.last, smp.suppressed = now, 0
+	return true, suppressed
+}
@@ -0,0 +1,41 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Finschia/ostracon/libs/log"
+)
+
+func TestSampledLogger(t *testing.T) {
+	var buf bytes.Buffer
+	interval := 200 * time.Millisecond
+	logger := log.NewSampledLogger(log.NewOCJSONLoggerNoTS(&buf), interval, "peer")
+
+	for i := 0; i < 5; i++ {
+		logger.Error("bad message", "peer", "a", "i", i)
+		logger.Error("bad message", "peer", "b", "i", i)
+		logger.With("peer", "c").Error("bad message", "i", i)
+		logger.Info("bad message", "peer", "a", "i", i)
+		logger.Error("other message", "peer", "a", "i", i)
+	}
+	assert.Equal(t, []string{
+		`{"_msg":"bad message","i":0,"level":"error","peer":"a"}`,
+		`{"_msg":"bad message","i":0,"level":"error","peer":"b"}`,
+		`{"_msg":"bad message","i":0,"level":"error","peer":"c"}`,
+		`{"_msg":"bad message","i":0,"level":"info","peer":"a"}`,
+		`{"_msg":"other message","i":0,"level":"error","peer":"a"}`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	// the messages suppressed are counted in the next one
+	buf.Reset()
+	time.Sleep(interval)
+	logger.Error("bad message", "peer", "a", "i", 5)
+	logger.Error("bad message", "peer", "a", "i", 6)
+	assert.Equal(t, `{"_msg":"bad message","i":5,"level":"error","peer":"a","suppressed":4}`,
+		strings.TrimSpace(buf.String()))
+}
@@ -1,8 +1,10 @@
 package log
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -10,6 +12,10 @@ import (
 
 var _ Logger = (*ZeroLogWrapper)(nil)
 
+// MissingValue is the value of the last key of an odd number of key/value
+// tuples.
+const MissingValue = "(MISSING)"
+
 // ZeroLogWrapper provides a wrapper around a zerolog.Logger instance. It implements
 // Tendermint's Logger interface.
 type ZeroLogWrapper struct {
@@ -37,6 +43,12 @@ func NewZeroLogConfig(isLogPlain bool, logLevel string, logPath string, logMaxAg
 	}
 }
 
+// NewZeroLogLogger returns a Logger, backed by zerolog, writing to
+// consoleWriter and, if cfg.LogPath is set, to the rotated log file, whose
+// levels per module are given by cfg.LogLevel (see ParseLogLevel). Unless
+// cfg.IsLogPlain, each event is written as a single line JSON object with the
+// "level", "time" (in Unix milliseconds) and "message" fields, and a field for
+// each key/value tuple (e.g. "module"), suited for log aggregation systems.
 func NewZeroLogLogger(cfg ZeroLogConfig, consoleWriter io.Writer) (Logger, error) {
 	var logWriter io.Writer
 	if cfg.IsLogPlain {
@@ -115,15 +127,36 @@ func (z ZeroLogWrapper) With(keyVals ...interface{}) Logger {
 	return ZeroLogWrapper{z.Logger.With().Fields(getLogFields(keyVals...)).Logger()}
 }
 
+// getLogFields returns the fields of the key/value tuples, encoded the same way
+// by the plain and JSON formats: the keys as strings, the []byte values in
+// upper case hexadecimal, and the errors and fmt.Stringer values as strings.
+// A missing value is logged as MissingValue.
 func getLogFields(keyVals ...interface{}) map[string]interface{} {
-	if len(keyVals)%2 != 0 {
-		return nil
-	}
-
-	fields := make(map[string]interface{})
+	fields := make(map[string]interface{}, (len(keyVals)+1)/2)
 	for i := 0; i < len(keyVals); i += 2 {
-		fields[keyVals[i].(string)] = keyVals[i+1]
+		key, ok := keyVals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyVals[i])
+		}
+		if i+1 == len(keyVals) {
+			fields[key] = MissingValue
+			continue
+		}
+		fields[key] = logFieldValue(keyVals[i+1])
 	}
 
 	return fields
 }
+
+func logFieldValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return strings.ToUpper(hex.EncodeToString(v))
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return value
+	}
+}
@@ -2,6 +2,8 @@ package log_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -398,6 +400,44 @@ func TestZeroLogRotateFiles(t *testing.T) {
 	}
 }
 
+func TestZeroLogJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	config := log.NewZeroLogConfig(false, "*:info", "", 0, 100, 0)
+	logger, err := log.NewZeroLogLogger(config, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.With("module", "consensus").Info("Committed block",
+		"hash", []byte{0xab, 0xcd},
+		"err", errors.New("some error"),
+		"duration", time.Second,
+		"height", 42,
+		"odd")
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("expected a JSON object, got %s: %v", buf.String(), err)
+	}
+	expected := map[string]interface{}{
+		"level":    "info",
+		"message":  "Committed block",
+		"module":   "consensus",
+		"hash":     "ABCD",
+		"err":      "some error",
+		"duration": "1s",
+		"height":   float64(42),
+		"odd":      log.MissingValue,
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, fields[key])
+		}
+	}
+	if _, ok := fields["time"]; !ok {
+		t.Errorf("expected a time field, got %s", buf.String())
+	}
+}
+
 func BenchmarkZeroLogLoggerSimple(b *testing.B) {
 	dir, err := os.MkdirTemp("/tmp", "zerolog-test")
 	if err != nil {
@@ -0,0 +1,93 @@
+// Package metrics provides the construction of the metrics of the node (see
+// the Metrics of e.g. consensus or mempool) behind a Provider, so that they
+// can be exported with the observability stack of the operators: served to
+// Prometheus, pushed to a StatsD agent, or pushed to an OpenTelemetry
+// collector with OTLP.
+package metrics
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// Opts are the options of a metric.
+type Opts struct {
+	Namespace string
+	Subsystem string
+	Name      string
+	Help      string
+
+	// Upper bounds of the buckets of a histogram, in increasing order. The
+	// Prometheus default buckets are used if empty.
+	Buckets []float64
+}
+
+// FullName returns the name of the metric: its namespace, subsystem and name
+// joined by "_".
+func (o Opts) FullName() string {
+	return stdprometheus.BuildFQName(o.Namespace, o.Subsystem, o.Name)
+}
+
+func (o Opts) buckets() []float64 {
+	if len(o.Buckets) == 0 {
+		return stdprometheus.DefBuckets
+	}
+	return o.Buckets
+}
+
+// Provider creates the metrics of a backend. The labels of the metrics are
+// given by their names, and their values are set with With.
+type Provider interface {
+	NewCounter(opts Opts, labelNames []string) metrics.Counter
+	NewGauge(opts Opts, labelNames []string) metrics.Gauge
+	NewHistogram(opts Opts, labelNames []string) metrics.Histogram
+}
+
+// LinearBuckets returns count buckets, width wide, the lowest upper bound
+// being start.
+func LinearBuckets(start, width float64, count int) []float64 {
+	return stdprometheus.LinearBuckets(start, width, count)
+}
+
+// ExponentialBuckets returns count buckets, the lowest upper bound being
+// start and each upper bound being factor times the previous one.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	return stdprometheus.ExponentialBuckets(start, factor, count)
+}
+
+// PrometheusProvider returns the Provider of the metrics registered to the
+// default Prometheus registry, served by the Prometheus server of the node.
+func PrometheusProvider() Provider {
+	return prometheusProvider{}
+}
+
+type prometheusProvider struct{}
+
+func (prometheusProvider) NewCounter(opts Opts, labelNames []string) metrics.Counter {
+	return prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+	}, labelNames)
+}
+
+func (prometheusProvider) NewGauge(opts Opts, labelNames []string) metrics.Gauge {
+	return prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+	}, labelNames)
+}
+
+func (prometheusProvider) NewHistogram(opts Opts, labelNames []string) metrics.Histogram {
+	return prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+		Buckets:   opts.buckets(),
+	}, labelNames)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptsFullName(t *testing.T) {
+	assert.Equal(t, "ostracon_mempool_size", Opts{Namespace: "ostracon", Subsystem: "mempool", Name: "size"}.FullName())
+	assert.Equal(t, "mempool_size", Opts{Subsystem: "mempool", Name: "size"}.FullName())
+}
+
+func TestPrometheusProvider(t *testing.T) {
+	p := PrometheusProvider()
+	opts := Opts{Namespace: "test_prometheus_provider", Subsystem: "sub", Help: "Help."}
+
+	opts.Name = "counter"
+	p.NewCounter(opts, []string{"chain_id"}).With("chain_id", "a").Add(2)
+	opts.Name = "gauge"
+	p.NewGauge(opts, []string{"chain_id"}).With("chain_id", "a").Set(3)
+	opts.Name = "histogram"
+	opts.Buckets = LinearBuckets(1, 1, 3)
+	p.NewHistogram(opts, []string{"chain_id"}).With("chain_id", "a").Observe(2)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	values := make(map[string]float64)
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "test_prometheus_provider_") {
+			continue
+		}
+		m := family.GetMetric()[0]
+		require.Equal(t, "a", m.GetLabel()[0].GetValue())
+		switch {
+		case m.Counter != nil:
+			values[family.GetName()] = m.Counter.GetValue()
+		case m.Gauge != nil:
+			values[family.GetName()] = m.Gauge.GetValue()
+		case m.Histogram != nil:
+			values[family.GetName()] = m.Histogram.GetSampleSum()
+			assert.Len(t, m.Histogram.GetBucket(), 3)
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"test_prometheus_provider_sub_counter":   2,
+		"test_prometheus_provider_sub_gauge":     3,
+		"test_prometheus_provider_sub_histogram": 2,
+	}, values)
+}
+
+func TestStatsdProvider(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer agent.Close()
+
+	p := NewStatsdProvider(agent.LocalAddr().String(), time.Hour)
+	opts := Opts{Namespace: "ostracon", Subsystem: "sub"}
+	opts.Name = "counter"
+	counter := p.NewCounter(opts, []string{"chain_id"}).With("chain_id", "a")
+	opts.Name = "gauge"
+	gauge := p.NewGauge(opts, []string{"chain_id"}).With("chain_id", "a")
+	opts.Name = "histogram"
+	histogram := p.NewHistogram(opts, []string{"chain_id"}).With("chain_id", "a")
+
+	require.NoError(t, p.Start())
+	counter.Add(1)
+	counter.Add(2)
+	gauge.Set(4)
+	histogram.Observe(5)
+	// the metrics left are pushed when stopping
+	require.NoError(t, p.Stop())
+
+	var lines []string
+	buf := make([]byte, 1024)
+	for len(lines) < 3 {
+		require.NoError(t, agent.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := agent.ReadFrom(buf)
+		require.NoError(t, err)
+		lines = append(lines, strings.Split(strings.TrimSpace(string(buf[:n])), "\n")...)
+	}
+	assert.ElementsMatch(t, []string{
+		"ostracon_sub_counter:3.000000|c|#chain_id:a",
+		"ostracon_sub_gauge:4.000000|g|#chain_id:a",
+		"ostracon_sub_histogram:5.000000|h|#chain_id:a",
+	}, lines)
+}
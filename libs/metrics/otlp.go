@@ -0,0 +1,439 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+const (
+	// otlpScope is the instrumentation scope of the metrics exported with OTLP.
+	otlpScope = "github.com/Finschia/ostracon"
+
+	// otlpExportTimeout is the maximum time of an export, if shorter than the
+	// interval between them.
+	otlpExportTimeout = 5 * time.Second
+)
+
+// OTLPClient sends the metrics to an OpenTelemetry collector.
+type OTLPClient interface {
+	Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error
+	Close() error
+}
+
+type otlpGRPCClient struct {
+	conn   *grpc.ClientConn
+	client colmetricspb.MetricsServiceClient
+}
+
+// NewOTLPGRPCClient returns an OTLPClient sending the metrics over gRPC to the
+// collector at endpoint (host:port), without TLS if insecure.
+func NewOTLPGRPCClient(endpoint string, insecureTransport bool) (OTLPClient, error) {
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if insecureTransport {
+		creds = insecure.NewCredentials()
+	}
+	// the client connects in the background, so this doesn't block
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &otlpGRPCClient{
+		conn:   conn,
+		client: colmetricspb.NewMetricsServiceClient(conn),
+	}, nil
+}
+
+func (c *otlpGRPCClient) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	_, err := c.client.Export(ctx, req)
+	return err
+}
+
+func (c *otlpGRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+type otlpHTTPClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewOTLPHTTPClient returns an OTLPClient sending the metrics over HTTP, as
+// protobuf, to the collector at endpoint (host:port), without TLS if
+// insecure.
+func NewOTLPHTTPClient(endpoint string, insecureTransport bool) OTLPClient {
+	scheme := "https"
+	if insecureTransport {
+		scheme = "http"
+	}
+	return &otlpHTTPClient{
+		url:    scheme + "://" + endpoint + "/v1/metrics",
+		client: &http.Client{},
+	}
+}
+
+func (c *otlpHTTPClient) Export(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *otlpHTTPClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// OTLPProvider is the Provider of the metrics pushed to an OpenTelemetry
+// collector with OTLP, every interval while it is running.
+//
+// The metrics are aggregated by the provider, for each of their label values:
+// the counters and the histograms are exported as cumulative since the
+// provider was created, like the Prometheus ones.
+type OTLPProvider struct {
+	service.BaseService
+
+	client   OTLPClient
+	interval time.Duration
+	resource *resourcepb.Resource
+	start    time.Time
+
+	mtx         tmsync.Mutex
+	instruments []*otlpInstrument
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+var _ Provider = (*OTLPProvider)(nil)
+
+// NewOTLPProvider returns an OTLPProvider pushing the metrics with client
+// every interval. The attributes ("foo", "fooValue") describe the node which
+// exports the metrics, e.g. its "service.name".
+func NewOTLPProvider(client OTLPClient, interval time.Duration, attributes ...string) *OTLPProvider {
+	p := &OTLPProvider{
+		client:   client,
+		interval: interval,
+		resource: &resourcepb.Resource{Attributes: keyValues(attributes)},
+		start:    time.Now(),
+	}
+	p.BaseService = *service.NewBaseService(nil, "OTLPProvider", p)
+	return p
+}
+
+// NewCounter implements Provider.
+func (p *OTLPProvider) NewCounter(opts Opts, _ []string) metrics.Counter {
+	return otlpCounter{inst: p.newInstrument(kindCounter, opts)}
+}
+
+// NewGauge implements Provider.
+func (p *OTLPProvider) NewGauge(opts Opts, _ []string) metrics.Gauge {
+	return otlpGauge{inst: p.newInstrument(kindGauge, opts)}
+}
+
+// NewHistogram implements Provider.
+func (p *OTLPProvider) NewHistogram(opts Opts, _ []string) metrics.Histogram {
+	return otlpHistogram{inst: p.newInstrument(kindHistogram, opts)}
+}
+
+func (p *OTLPProvider) newInstrument(kind instrumentKind, opts Opts) *otlpInstrument {
+	inst := &otlpInstrument{
+		kind:   kind,
+		opts:   opts,
+		series: make(map[string]*otlpSeries),
+	}
+	if kind == kindHistogram {
+		inst.bounds = opts.buckets()
+	}
+
+	p.mtx.Lock()
+	p.instruments = append(p.instruments, inst)
+	p.mtx.Unlock()
+	return inst
+}
+
+// OnStart implements service.Service. It starts pushing the metrics.
+func (p *OTLPProvider) OnStart() error {
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.pushRoutine()
+	return nil
+}
+
+// OnStop implements service.Service. It pushes the metrics left, and stops
+// pushing them.
+func (p *OTLPProvider) OnStop() {
+	close(p.quit)
+	<-p.done
+	p.push()
+	if err := p.client.Close(); err != nil {
+		p.Logger.Error("Error closing the OTLP client", "err", err)
+	}
+}
+
+func (p *OTLPProvider) pushRoutine() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.push()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (p *OTLPProvider) push() {
+	req := p.request(time.Now())
+	if req == nil {
+		return
+	}
+	timeout := p.interval
+	if timeout > otlpExportTimeout {
+		timeout = otlpExportTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := p.client.Export(ctx, req); err != nil {
+		p.Logger.Error("Error pushing the metrics to the OpenTelemetry collector", "err", err)
+	}
+}
+
+// request returns the request exporting the metrics at time now, or nil if
+// there isn't any yet.
+func (p *OTLPProvider) request(now time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	p.mtx.Lock()
+	instruments := p.instruments
+	p.mtx.Unlock()
+
+	var ms []*metricspb.Metric
+	for _, inst := range instruments {
+		if m := inst.metric(p.start, now); m != nil {
+			ms = append(ms, m)
+		}
+	}
+	if len(ms) == 0 {
+		return nil
+	}
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: p.resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: otlpScope},
+				Metrics: ms,
+			}},
+		}},
+	}
+}
+
+type instrumentKind int
+
+const (
+	kindCounter instrumentKind = iota
+	kindGauge
+	kindHistogram
+)
+
+// otlpInstrument aggregates the values of a metric, by label values.
+type otlpInstrument struct {
+	kind   instrumentKind
+	opts   Opts
+	bounds []float64 // of the histogram buckets
+
+	mtx    tmsync.Mutex
+	series map[string]*otlpSeries
+	keys   []string // of the series, in the order they were created
+}
+
+type otlpSeries struct {
+	attributes []*commonpb.KeyValue
+	value      float64  // sum of the counter or the histogram, or value of the gauge
+	count      uint64   // observations of the histogram
+	buckets    []uint64 // observations of the histogram by bucket
+}
+
+func (inst *otlpInstrument) update(labelValues []string, update func(s *otlpSeries)) {
+	key := strings.Join(labelValues, "\x00")
+
+	inst.mtx.Lock()
+	defer inst.mtx.Unlock()
+
+	s, ok := inst.series[key]
+	if !ok {
+		s = &otlpSeries{attributes: keyValues(labelValues)}
+		if inst.kind == kindHistogram {
+			s.buckets = make([]uint64, len(inst.bounds)+1)
+		}
+		inst.series[key] = s
+		inst.keys = append(inst.keys, key)
+	}
+	update(s)
+}
+
+// metric returns the metric of the series at time now, or nil if there isn't
+// any yet.
+func (inst *otlpInstrument) metric(start, now time.Time) *metricspb.Metric {
+	inst.mtx.Lock()
+	defer inst.mtx.Unlock()
+
+	if len(inst.keys) == 0 {
+		return nil
+	}
+	m := &metricspb.Metric{
+		Name:        inst.opts.FullName(),
+		Description: inst.opts.Help,
+	}
+	startNano, nowNano := uint64(start.UnixNano()), uint64(now.UnixNano())
+
+	switch inst.kind {
+	case kindHistogram:
+		points := make([]*metricspb.HistogramDataPoint, 0, len(inst.keys))
+		for _, key := range inst.keys {
+			s := inst.series[key]
+			sum := s.value
+			points = append(points, &metricspb.HistogramDataPoint{
+				Attributes:        s.attributes,
+				StartTimeUnixNano: startNano,
+				TimeUnixNano:      nowNano,
+				Count:             s.count,
+				Sum:               &sum,
+				BucketCounts:      append([]uint64(nil), s.buckets...),
+				ExplicitBounds:    inst.bounds,
+			})
+		}
+		m.Data = &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints:             points,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}}
+	default:
+		points := make([]*metricspb.NumberDataPoint, 0, len(inst.keys))
+		for _, key := range inst.keys {
+			s := inst.series[key]
+			point := &metricspb.NumberDataPoint{
+				Attributes:   s.attributes,
+				TimeUnixNano: nowNano,
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: s.value},
+			}
+			if inst.kind == kindCounter {
+				point.StartTimeUnixNano = startNano
+			}
+			points = append(points, point)
+		}
+		if inst.kind == kindCounter {
+			m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+				DataPoints:             points,
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}}
+		} else {
+			m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}
+		}
+	}
+	return m
+}
+
+type otlpCounter struct {
+	inst        *otlpInstrument
+	labelValues []string
+}
+
+func (c otlpCounter) With(labelValues ...string) metrics.Counter {
+	return otlpCounter{inst: c.inst, labelValues: with(c.labelValues, labelValues)}
+}
+
+func (c otlpCounter) Add(delta float64) {
+	c.inst.update(c.labelValues, func(s *otlpSeries) { s.value += delta })
+}
+
+type otlpGauge struct {
+	inst        *otlpInstrument
+	labelValues []string
+}
+
+func (g otlpGauge) With(labelValues ...string) metrics.Gauge {
+	return otlpGauge{inst: g.inst, labelValues: with(g.labelValues, labelValues)}
+}
+
+func (g otlpGauge) Set(value float64) {
+	g.inst.update(g.labelValues, func(s *otlpSeries) { s.value = value })
+}
+
+func (g otlpGauge) Add(delta float64) {
+	g.inst.update(g.labelValues, func(s *otlpSeries) { s.value += delta })
+}
+
+type otlpHistogram struct {
+	inst        *otlpInstrument
+	labelValues []string
+}
+
+func (h otlpHistogram) With(labelValues ...string) metrics.Histogram {
+	return otlpHistogram{inst: h.inst, labelValues: with(h.labelValues, labelValues)}
+}
+
+func (h otlpHistogram) Observe(value float64) {
+	h.inst.update(h.labelValues, func(s *otlpSeries) {
+		s.count++
+		s.value += value
+		// the upper bounds of the buckets are inclusive, like the Prometheus ones
+		s.buckets[sort.SearchFloat64s(h.inst.bounds, value)]++
+	})
+}
+
+// with returns the label values ("foo", "fooValue") of labelValues followed by
+// more, like the go-kit metrics.
+func with(labelValues, more []string) []string {
+	if len(more)%2 != 0 {
+		more = append(more, "unknown")
+	}
+	return append(append([]string(nil), labelValues...), more...)
+}
+
+func keyValues(pairs []string) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   pairs[i],
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: pairs[i+1]}},
+		})
+	}
+	return kvs
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPProviderRequest(t *testing.T) {
+	p := NewOTLPProvider(nil, time.Hour, "service.name", "ostracon")
+	now := p.start.Add(time.Second)
+	assert.Nil(t, p.request(now), "no metric yet")
+
+	opts := Opts{Namespace: "ostracon", Subsystem: "sub", Help: "Help."}
+	opts.Name = "counter"
+	counter := p.NewCounter(opts, []string{"chain_id", "type"}).With("chain_id", "a")
+	opts.Name = "gauge"
+	gauge := p.NewGauge(opts, []string{"chain_id"}).With("chain_id", "a")
+	opts.Name = "histogram"
+	opts.Buckets = []float64{1, 2}
+	histogram := p.NewHistogram(opts, []string{"chain_id"}).With("chain_id", "a")
+
+	counter.With("type", "x").Add(1)
+	counter.With("type", "y").Add(2)
+	counter.With("type", "x").Add(3)
+	gauge.Set(5)
+	gauge.Add(-1)
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+	histogram.Observe(3)
+
+	req := p.request(now)
+	require.NotNil(t, req)
+	require.Len(t, req.ResourceMetrics, 1)
+	rm := req.ResourceMetrics[0]
+	assert.Equal(t, "service.name", rm.Resource.Attributes[0].Key)
+	assert.Equal(t, "ostracon", rm.Resource.Attributes[0].Value.GetStringValue())
+	ms := rm.ScopeMetrics[0].Metrics
+	require.Len(t, ms, 3)
+
+	assert.Equal(t, "ostracon_sub_counter", ms[0].Name)
+	assert.Equal(t, "Help.", ms[0].Description)
+	sum := ms[0].GetSum()
+	require.NotNil(t, sum)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 2)
+	assert.Equal(t, 4.0, sum.DataPoints[0].GetAsDouble())
+	assert.Equal(t, 2.0, sum.DataPoints[1].GetAsDouble())
+	attrs := sum.DataPoints[1].Attributes
+	require.Len(t, attrs, 2)
+	assert.Equal(t, "chain_id", attrs[0].Key)
+	assert.Equal(t, "a", attrs[0].Value.GetStringValue())
+	assert.Equal(t, "type", attrs[1].Key)
+	assert.Equal(t, "y", attrs[1].Value.GetStringValue())
+	assert.Equal(t, uint64(p.start.UnixNano()), sum.DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, uint64(now.UnixNano()), sum.DataPoints[0].TimeUnixNano)
+
+	assert.Equal(t, "ostracon_sub_gauge", ms[1].Name)
+	require.NotNil(t, ms[1].GetGauge())
+	assert.Equal(t, 4.0, ms[1].GetGauge().DataPoints[0].GetAsDouble())
+
+	assert.Equal(t, "ostracon_sub_histogram", ms[2].Name)
+	require.NotNil(t, ms[2].GetHistogram())
+	point := ms[2].GetHistogram().DataPoints[0]
+	assert.Equal(t, uint64(3), point.Count)
+	assert.Equal(t, 5.5, point.GetSum())
+	assert.Equal(t, []float64{1, 2}, point.ExplicitBounds)
+	// the upper bounds are inclusive
+	assert.Equal(t, []uint64{1, 1, 1}, point.BucketCounts)
+}
+
+func TestOTLPProviderHTTP(t *testing.T) {
+	reqs := make(chan *colmetricspb.ExportMetricsServiceRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := new(colmetricspb.ExportMetricsServiceRequest)
+		require.NoError(t, proto.Unmarshal(body, req))
+		reqs <- req
+	}))
+	defer srv.Close()
+
+	client := NewOTLPHTTPClient(strings.TrimPrefix(srv.URL, "http://"), true)
+	testOTLPProvider(t, client, reqs)
+}
+
+type testMetricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	reqs chan *colmetricspb.ExportMetricsServiceRequest
+}
+
+func (s *testMetricsService) Export(
+	_ context.Context,
+	req *colmetricspb.ExportMetricsServiceRequest,
+) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	s.reqs <- req
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func TestOTLPProviderGRPC(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	reqs := make(chan *colmetricspb.ExportMetricsServiceRequest, 10)
+	srv := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(srv, &testMetricsService{reqs: reqs})
+	go srv.Serve(ln) //nolint:errcheck
+	defer srv.Stop()
+
+	client, err := NewOTLPGRPCClient(ln.Addr().String(), true)
+	require.NoError(t, err)
+	testOTLPProvider(t, client, reqs)
+}
+
+func testOTLPProvider(t *testing.T, client OTLPClient, reqs <-chan *colmetricspb.ExportMetricsServiceRequest) {
+	p := NewOTLPProvider(client, 50*time.Millisecond)
+	counter := p.NewCounter(Opts{Namespace: "ostracon", Name: "counter"}, nil)
+	require.NoError(t, p.Start())
+	counter.Add(1)
+
+	// pushed every interval
+	var req *colmetricspb.ExportMetricsServiceRequest
+	select {
+	case req = <-reqs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the metrics")
+	}
+	ms := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, ms, 1)
+	assert.Equal(t, "ostracon_counter", ms[0].Name)
+
+	// the metrics left are pushed when stopping
+	counter.Add(2)
+	require.NoError(t, p.Stop())
+	for len(reqs) > 0 {
+		req = <-reqs
+	}
+	assert.Equal(t, 3.0, req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetSum().DataPoints[0].GetAsDouble())
+}
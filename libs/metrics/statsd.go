@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/dogstatsd"
+	kitlog "github.com/go-kit/log"
+
+	"github.com/Finschia/ostracon/libs/service"
+)
+
+// StatsdProvider is the Provider of the metrics pushed to a StatsD agent over
+// UDP, every interval while it is running. The labels of the metrics are sent
+// as DogStatsD tags ("label:value"), which the agents ignore if they don't
+// support them.
+//
+// The counters are sent as the increments since the previous push, and the
+// histograms as their observations, the buckets being computed by the agent.
+type StatsdProvider struct {
+	service.BaseService
+
+	address  string
+	interval time.Duration
+	statsd   *dogstatsd.Dogstatsd
+	conn     net.Conn
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+var _ Provider = (*StatsdProvider)(nil)
+
+// NewStatsdProvider returns a StatsdProvider pushing the metrics to the agent
+// at address (host:port) every interval.
+func NewStatsdProvider(address string, interval time.Duration) *StatsdProvider {
+	p := &StatsdProvider{
+		address:  address,
+		interval: interval,
+	}
+	// the metrics are written by push, which logs the errors
+	p.statsd = dogstatsd.New("", kitlog.NewNopLogger())
+	p.BaseService = *service.NewBaseService(nil, "StatsdProvider", p)
+	return p
+}
+
+// NewCounter implements Provider.
+func (p *StatsdProvider) NewCounter(opts Opts, _ []string) metrics.Counter {
+	return p.statsd.NewCounter(opts.FullName(), 1)
+}
+
+// NewGauge implements Provider.
+func (p *StatsdProvider) NewGauge(opts Opts, _ []string) metrics.Gauge {
+	return p.statsd.NewGauge(opts.FullName())
+}
+
+// NewHistogram implements Provider.
+func (p *StatsdProvider) NewHistogram(opts Opts, _ []string) metrics.Histogram {
+	return p.statsd.NewHistogram(opts.FullName(), 1)
+}
+
+// OnStart implements service.Service. It starts pushing the metrics.
+func (p *StatsdProvider) OnStart() error {
+	// UDP doesn't connect, so this doesn't wait for the agent
+	conn, err := net.Dial("udp", p.address)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.pushRoutine()
+	return nil
+}
+
+// OnStop implements service.Service. It pushes the metrics left, and stops
+// pushing them.
+func (p *StatsdProvider) OnStop() {
+	close(p.quit)
+	<-p.done
+	p.push()
+	if err := p.conn.Close(); err != nil {
+		p.Logger.Error("Error closing the StatsD connection", "err", err)
+	}
+}
+
+func (p *StatsdProvider) pushRoutine() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.push()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (p *StatsdProvider) push() {
+	if _, err := p.statsd.WriteTo(p.conn); err != nil {
+		p.Logger.Error("Error pushing the metrics to StatsD", "err", err)
+	}
+}
@@ -31,6 +31,19 @@ func TrapSignal(logger logger, cb func()) {
 	}()
 }
 
+// TrapReloadSignal catches SIGHUP and executes the callback function, e.g. to
+// reload the configuration. Unlike TrapSignal, it doesn't exit the process.
+func TrapReloadSignal(logger logger, cb func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for sig := range c {
+			logger.Info("signal trapped", "msg", log.NewLazySprintf("captured %v, reloading...", sig))
+			cb()
+		}
+	}()
+}
+
 // Kill the running process by sending itself SIGTERM.
 func Kill() error {
 	p, err := os.FindProcess(os.Getpid())
@@ -0,0 +1,118 @@
+// Package protoany is the registry of the custom protobuf types of the
+// applications (e.g. the messages of their reactors), so that Ostracon can pack
+// them into and unpack them from google.protobuf.Any:
+//
+//   - the p2p layer unpacks the Any messages received on a channel whose message
+//     type is google.protobuf.Any (see p2p.ChannelDescriptor.MessageType) into
+//     the registered types before routing them to the reactor, and packs the
+//     registered messages sent on such a channel, so that a reactor can exchange
+//     any number of message types on a channel without wrapping them itself;
+//   - the registered types are also registered with libs/json under their type
+//     URL, so that the RPC server can encode and decode them.
+//
+// Note that the evidence can't be extended this way, as its wire format
+// (tendermint.types.Evidence) is a closed oneof shared with Tendermint.
+package protoany
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
+
+	tmjson "github.com/Finschia/ostracon/libs/json"
+)
+
+// ErrUnknownType is returned when unpacking an Any of an unregistered type.
+type ErrUnknownType struct {
+	TypeURL string
+}
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("unknown protobuf type %q, did you register it with protoany.Register?", e.TypeURL)
+}
+
+var (
+	mtx      sync.RWMutex
+	registry = make(map[string]proto.Message)
+)
+
+// TypeURL returns the type URL of the message in an Any, i.e. "/" followed by
+// its fully qualified protobuf name (e.g. "/myapp.oracle.Price").
+func TypeURL(msg proto.Message) string {
+	return "/" + proto.MessageName(msg)
+}
+
+// Register registers the type of the message, typically from an init function
+// of the application. It panics if the type has no protobuf name (i.e. it
+// isn't a generated message) or if it is already registered.
+func Register(msg proto.Message) {
+	name := proto.MessageName(msg)
+	if name == "" {
+		panic(fmt.Sprintf("%T has no protobuf name", msg))
+	}
+	typeURL := "/" + name
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if _, ok := registry[typeURL]; ok {
+		panic(fmt.Sprintf("protobuf type %q is already registered", typeURL))
+	}
+	tmjson.RegisterType(msg, typeURL)
+	registry[typeURL] = msg
+}
+
+// IsRegistered returns true if the type of the message is registered.
+func IsRegistered(msg proto.Message) bool {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	_, ok := registry[TypeURL(msg)]
+	return ok
+}
+
+// RegisteredTypes returns the sorted type URLs of the registered types.
+func RegisteredTypes() []string {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	typeURLs := make([]string, 0, len(registry))
+	for typeURL := range registry {
+		typeURLs = append(typeURLs, typeURL)
+	}
+	sort.Strings(typeURLs)
+	return typeURLs
+}
+
+// Pack packs the message of a registered type into an Any.
+func Pack(msg proto.Message) (*gogotypes.Any, error) {
+	typeURL := TypeURL(msg)
+	mtx.RLock()
+	_, ok := registry[typeURL]
+	mtx.RUnlock()
+	if !ok {
+		return nil, ErrUnknownType{TypeURL: typeURL}
+	}
+	bz, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s: %w", typeURL, err)
+	}
+	return &gogotypes.Any{TypeUrl: typeURL, Value: bz}, nil
+}
+
+// Unpack unpacks the message of a registered type from the Any. It returns
+// ErrUnknownType if the type isn't registered.
+func Unpack(msgAny *gogotypes.Any) (proto.Message, error) {
+	mtx.RLock()
+	prototype, ok := registry[msgAny.TypeUrl]
+	mtx.RUnlock()
+	if !ok {
+		return nil, ErrUnknownType{TypeURL: msgAny.TypeUrl}
+	}
+	msg := proto.Clone(prototype)
+	msg.Reset()
+	if err := proto.Unmarshal(msgAny.Value, msg); err != nil {
+		return nil, fmt.Errorf("unmarshaling %s: %w", msgAny.TypeUrl, err)
+	}
+	return msg, nil
+}
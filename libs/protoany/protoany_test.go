@@ -0,0 +1,66 @@
+package protoany_test
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	"github.com/Finschia/ostracon/libs/protoany"
+)
+
+func init() {
+	protoany.Register(&gogotypes.StringValue{})
+	protoany.Register(&gogotypes.Int64Value{})
+}
+
+func TestRegister(t *testing.T) {
+	assert.True(t, protoany.IsRegistered(&gogotypes.StringValue{}))
+	assert.False(t, protoany.IsRegistered(&gogotypes.BoolValue{}))
+	assert.Equal(t, []string{"/google.protobuf.Int64Value", "/google.protobuf.StringValue"}, protoany.RegisteredTypes())
+
+	assert.Panics(t, func() { protoany.Register(&gogotypes.StringValue{}) })
+}
+
+func TestPackUnpack(t *testing.T) {
+	msgAny, err := protoany.Pack(&gogotypes.StringValue{Value: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "/google.protobuf.StringValue", msgAny.TypeUrl)
+
+	msg, err := protoany.Unpack(msgAny)
+	require.NoError(t, err)
+	assert.Equal(t, &gogotypes.StringValue{Value: "foo"}, msg)
+
+	// the unpacked messages don't share the registered prototype
+	msgAny, err = protoany.Pack(&gogotypes.StringValue{Value: "bar"})
+	require.NoError(t, err)
+	msg2, err := protoany.Unpack(msgAny)
+	require.NoError(t, err)
+	assert.Equal(t, &gogotypes.StringValue{Value: "foo"}, msg)
+	assert.Equal(t, &gogotypes.StringValue{Value: "bar"}, msg2)
+
+	_, err = protoany.Pack(&gogotypes.BoolValue{Value: true})
+	assert.Equal(t, protoany.ErrUnknownType{TypeURL: "/google.protobuf.BoolValue"}, err)
+
+	bz, err := proto.Marshal(&gogotypes.BoolValue{Value: true})
+	require.NoError(t, err)
+	_, err = protoany.Unpack(&gogotypes.Any{TypeUrl: "/google.protobuf.BoolValue", Value: bz})
+	assert.Equal(t, protoany.ErrUnknownType{TypeURL: "/google.protobuf.BoolValue"}, err)
+
+	_, err = protoany.Unpack(&gogotypes.Any{TypeUrl: "/google.protobuf.Int64Value", Value: []byte{0xff}})
+	assert.Error(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	var msg proto.Message = &gogotypes.Int64Value{Value: 42}
+	bz, err := tmjson.Marshal(&msg)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"/google.protobuf.Int64Value","value":{"value":"42"}}`, string(bz))
+
+	var decoded proto.Message
+	require.NoError(t, tmjson.Unmarshal(bz, &decoded))
+	assert.Equal(t, msg, decoded)
+}
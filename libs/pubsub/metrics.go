@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "pubsub"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of messages dropped because the buffer of a subscription was
+	// full, by overflow policy.
+	DroppedMessages metrics.Counter
+	// Number of subscriptions terminated because their buffer was full.
+	DisconnectedSubscriptions metrics.Counter
+	// Number of times the publisher was blocked by a full subscription buffer.
+	BlockedPublishes metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		DroppedMessages: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "dropped_messages",
+			Help:      "Number of messages dropped because the buffer of a subscription was full, by overflow policy.",
+		}, append(labels, "policy")).With(labelsAndValues...),
+		DisconnectedSubscriptions: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "disconnected_subscriptions",
+			Help:      "Number of subscriptions terminated because their buffer was full.",
+		}, labels).With(labelsAndValues...),
+		BlockedPublishes: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "blocked_publishes",
+			Help:      "Number of times the publisher was blocked by a full subscription buffer.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		DroppedMessages:           discard.NewCounter(),
+		DisconnectedSubscriptions: discard.NewCounter(),
+		BlockedPublishes:          discard.NewCounter(),
+	}
+}
@@ -31,6 +31,12 @@
 //	        return subscription.Err()
 //	    }
 //	}
+//
+// Each subscription has a bounded buffer, and an OverflowPolicy deciding what
+// happens when the publisher finds it full: the subscription is terminated
+// (Subscribe), the publisher is blocked (SubscribeUnbuffered), or the oldest
+// or the new message is dropped (SubscribeWithPolicy). The messages dropped
+// and the subscriptions terminated are counted by the Metrics of the server.
 package pubsub
 
 import (
@@ -97,6 +103,8 @@ type Server struct {
 	// subscribing or unsubscribing
 	mtx           tmsync.RWMutex
 	subscriptions map[string]map[string]struct{} // subscriber -> query (string) -> empty struct
+
+	metrics *Metrics
 }
 
 // Option sets a parameter for the server.
@@ -108,6 +116,7 @@ type Option func(*Server)
 func NewServer(options ...Option) *Server {
 	s := &Server{
 		subscriptions: make(map[string]map[string]struct{}),
+		metrics:       NopMetrics(),
 	}
 	s.BaseService = *service.NewBaseService(nil, "PubSub", s)
 
@@ -133,6 +142,13 @@ func BufferCapacity(cap int) Option {
 	}
 }
 
+// WithMetrics sets the metrics of the server.
+func WithMetrics(metrics *Metrics) Option {
+	return func(s *Server) {
+		s.metrics = metrics
+	}
+}
+
 // BufferCapacity returns capacity of the internal server's queue.
 func (s *Server) BufferCapacity() int {
 	return s.cmdsCap
@@ -159,17 +175,35 @@ func (s *Server) Subscribe(
 		outCap = outCapacity[0]
 	}
 
-	return s.subscribe(ctx, clientID, query, outCap)
+	return s.subscribe(ctx, clientID, query, NewSubscription(outCap))
 }
 
 // SubscribeUnbuffered does the same as Subscribe, except it returns a
 // subscription with unbuffered channel. Use with caution as it can freeze the
 // server.
 func (s *Server) SubscribeUnbuffered(ctx context.Context, clientID string, query Query) (*Subscription, error) {
-	return s.subscribe(ctx, clientID, query, 0)
+	return s.subscribe(ctx, clientID, query, NewSubscription(0))
+}
+
+// SubscribeWithPolicy does the same as Subscribe, except it returns a
+// subscription with the given policy when its buffer of outCapacity messages
+// is full, so that a slow client neither blocks the publisher nor is
+// terminated unless requested. Panics if outCapacity is less than or equal to
+// zero.
+func (s *Server) SubscribeWithPolicy(
+	ctx context.Context,
+	clientID string,
+	query Query,
+	outCapacity int,
+	policy OverflowPolicy,
+) (*Subscription, error) {
+	if outCapacity <= 0 {
+		panic("Negative or zero capacity. Use SubscribeUnbuffered if you want an unbuffered channel")
+	}
+	return s.subscribe(ctx, clientID, query, NewSubscriptionWithPolicy(outCapacity, policy))
 }
 
-func (s *Server) subscribe(ctx context.Context, clientID string, query Query, outCapacity int) (*Subscription, error) {
+func (s *Server) subscribe(ctx context.Context, clientID string, query Query, subscription *Subscription) (*Subscription, error) {
 	s.mtx.RLock()
 	clientSubscriptions, ok := s.subscriptions[clientID]
 	if ok {
@@ -180,7 +214,6 @@ func (s *Server) subscribe(ctx context.Context, clientID string, query Query, ou
 		return nil, ErrAlreadySubscribed
 	}
 
-	subscription := NewSubscription(outCapacity)
 	select {
 	case s.cmds <- cmd{op: sub, clientID: clientID, query: query, subscription: subscription}:
 		s.mtx.Lock()
@@ -334,7 +367,7 @@ loop:
 		case sub:
 			state.add(cmd.clientID, cmd.query, cmd.subscription)
 		case pub:
-			if err := state.send(cmd.msg, cmd.events); err != nil {
+			if err := state.send(cmd.msg, cmd.events, s.metrics); err != nil {
 				s.Logger.Error("Error querying for events", "err", err)
 			}
 		}
@@ -403,7 +436,7 @@ func (state *state) removeAll(reason error) {
 	}
 }
 
-func (state *state) send(msg interface{}, events map[string][]string) error {
+func (state *state) send(msg interface{}, events map[string][]string, metrics *Metrics) error {
 	for qStr, clientSubscriptions := range state.subscriptions {
 		q := state.queries[qStr].q
 
@@ -414,16 +447,8 @@ func (state *state) send(msg interface{}, events map[string][]string) error {
 
 		if match {
 			for clientID, subscription := range clientSubscriptions {
-				if cap(subscription.out) == 0 {
-					// block on unbuffered channel
-					subscription.out <- NewMessage(msg, events)
-				} else {
-					// don't block on buffered channels
-					select {
-					case subscription.out <- NewMessage(msg, events):
-					default:
-						state.remove(clientID, qStr, ErrOutOfCapacity)
-					}
+				if !subscription.push(NewMessage(msg, events), metrics) {
+					state.remove(clientID, qStr, ErrOutOfCapacity)
 				}
 			}
 		}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -149,6 +151,106 @@ func TestSlowClientIsRemovedWithErrOutOfCapacity(t *testing.T) {
 	assertCancelled(t, subscription, pubsub.ErrOutOfCapacity)
 }
 
+func TestSubscribeWithPolicy(t *testing.T) {
+	metrics := pubsub.NopMetrics()
+	dropped, disconnected, blocked := new(testCounter), new(testCounter), new(testCounter)
+	metrics.DroppedMessages, metrics.DisconnectedSubscriptions, metrics.BlockedPublishes = dropped, disconnected, blocked
+	s := pubsub.NewServer(pubsub.WithMetrics(metrics))
+	s.SetLogger(log.TestingLogger())
+	err := s.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := s.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	ctx := context.Background()
+	q := query.MustParse("tm.events.type='NewBlock'")
+	events := map[string][]string{"tm.events.type": {"NewBlock"}}
+	dropOldest, err := s.SubscribeWithPolicy(ctx, "drop-oldest", q, 2, pubsub.DropOldest)
+	require.NoError(t, err)
+	dropNew, err := s.SubscribeWithPolicy(ctx, "drop-new", q, 2, pubsub.DropNew)
+	require.NoError(t, err)
+	disconnect, err := s.SubscribeWithPolicy(ctx, "disconnect", q, 2, pubsub.Disconnect)
+	require.NoError(t, err)
+	block, err := s.SubscribeWithPolicy(ctx, "block", q, 2, pubsub.BlockPublisher)
+	require.NoError(t, err)
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for _, msg := range []string{"Fat Cobra", "Viper", "Black Mamba"} {
+			err := s.PublishWithEvents(ctx, msg, events)
+			require.NoError(t, err)
+		}
+		// the message doesn't match the query, but it is received by the server
+		// only once the previous one was sent to all the subscriptions
+		err := s.Publish(ctx, "Sync")
+		require.NoError(t, err)
+	}()
+
+	// the publisher is blocked until the slow subscriber pulls a message
+	select {
+	case <-published:
+		t.Fatal("expected the publisher to be blocked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assertReceive(t, "Fat Cobra", block.Out())
+	<-published
+	assertReceive(t, "Viper", block.Out())
+	assertReceive(t, "Black Mamba", block.Out())
+	assert.Equal(t, float64(1), blocked.Value())
+
+	assertReceive(t, "Viper", dropOldest.Out())
+	assertReceive(t, "Black Mamba", dropOldest.Out())
+	assert.Equal(t, uint64(1), dropOldest.Dropped())
+	assertReceive(t, "Fat Cobra", dropNew.Out())
+	assertReceive(t, "Viper", dropNew.Out())
+	assert.Equal(t, uint64(1), dropNew.Dropped())
+	assert.Equal(t, float64(2), dropped.Value())
+
+	assertCancelled(t, disconnect, pubsub.ErrOutOfCapacity)
+	assert.Equal(t, float64(1), disconnected.Value())
+	for _, subscription := range []*pubsub.Subscription{dropOldest, dropNew, block} {
+		assert.NoError(t, subscription.Err())
+	}
+}
+
+// testCounter is a counter whose values with labels are added to it.
+type testCounter struct {
+	mtx   sync.Mutex
+	value float64
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return c
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.mtx.Lock()
+	c.value += delta
+	c.mtx.Unlock()
+}
+
+func (c *testCounter) Value() float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.value
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, policy := range []pubsub.OverflowPolicy{
+		pubsub.Disconnect, pubsub.BlockPublisher, pubsub.DropOldest, pubsub.DropNew,
+	} {
+		parsed, err := pubsub.ParseOverflowPolicy(policy.String())
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+	_, err := pubsub.ParseOverflowPolicy("drop_all")
+	assert.Error(t, err)
+}
+
 func TestDifferentClients(t *testing.T) {
 	s := pubsub.NewServer()
 	s.SetLogger(log.TestingLogger())
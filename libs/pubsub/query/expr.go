@@ -0,0 +1,177 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExprKind is the kind of an Expr.
+type ExprKind uint8
+
+const (
+	// ExprCondition is a single condition, e.g. "tx.gas > 7".
+	ExprCondition ExprKind = iota
+	// ExprAnd is the conjunction of its operands ("AND").
+	ExprAnd
+	// ExprOr is the disjunction of its operands ("OR").
+	ExprOr
+	// ExprNot is the negation of its single operand ("NOT").
+	ExprNot
+)
+
+// Expr is a node of the syntax tree of a query: a condition, or the
+// conjunction, the disjunction or the negation of other expressions. A list
+// "key IN (a, b)" is parsed as the disjunction "key = a OR key = b".
+type Expr struct {
+	Kind ExprKind
+	// Condition is the condition of an ExprCondition.
+	Condition Condition
+	// Operands are the operands of an ExprAnd or an ExprOr (at least two), or
+	// the negated expression of an ExprNot.
+	Operands []*Expr
+
+	// date is true if the operand of the condition is a date (DATE), in which
+	// case the times are compared by date.
+	date bool
+}
+
+// Conditions returns the conditions of the expression, if it is a condition or
+// a conjunction of conditions, i.e. if it has neither OR, NOT nor IN.
+func (e *Expr) Conditions() ([]Condition, bool) {
+	switch e.Kind {
+	case ExprCondition:
+		return []Condition{e.Condition}, true
+	case ExprAnd:
+		conditions := make([]Condition, 0, len(e.Operands))
+		for _, operand := range e.Operands {
+			if operand.Kind != ExprCondition {
+				return nil, false
+			}
+			conditions = append(conditions, operand.Condition)
+		}
+		return conditions, true
+	default:
+		return nil, false
+	}
+}
+
+// String returns the expression in the query syntax, with the conjunctions and
+// the disjunctions in parentheses.
+func (e *Expr) String() string {
+	switch e.Kind {
+	case ExprCondition:
+		return e.Condition.string(e.date)
+	case ExprNot:
+		return "NOT " + e.Operands[0].String()
+	default:
+		sep := " AND "
+		if e.Kind == ExprOr {
+			sep = " OR "
+		}
+		operands := make([]string, len(e.Operands))
+		for i, operand := range e.Operands {
+			operands[i] = operand.String()
+		}
+		return "(" + strings.Join(operands, sep) + ")"
+	}
+}
+
+func (c Condition) string(date bool) string {
+	if c.Op == OpExists {
+		return c.CompositeKey + " EXISTS"
+	}
+	return c.CompositeKey + " " + c.Op.String() + " " + formatOperand(c.Operand, date)
+}
+
+// String returns the operator in the query syntax.
+func (op Operator) String() string {
+	switch op {
+	case OpLessEqual:
+		return "<="
+	case OpGreaterEqual:
+		return ">="
+	case OpLess:
+		return "<"
+	case OpGreater:
+		return ">"
+	case OpEqual:
+		return "="
+	case OpContains:
+		return "CONTAINS"
+	case OpExists:
+		return "EXISTS"
+	default:
+		return fmt.Sprintf("Operator(%d)", uint8(op))
+	}
+}
+
+// matches returns true if the expression matches the events, see
+// Query.Matches.
+func (e *Expr) matches(events map[string][]string) (bool, error) {
+	switch e.Kind {
+	case ExprCondition:
+		return e.matchCondition(events)
+	case ExprAnd:
+		for _, operand := range e.Operands {
+			match, err := operand.matches(events)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+		return true, nil
+	case ExprOr:
+		for _, operand := range e.Operands {
+			match, err := operand.matches(events)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
+	case ExprNot:
+		match, err := e.Operands[0].matches(events)
+		if err != nil {
+			return false, err
+		}
+		return !match, nil
+	default:
+		return false, fmt.Errorf("unknown kind of expression %d", e.Kind)
+	}
+}
+
+func (e *Expr) matchCondition(events map[string][]string) (bool, error) {
+	c := e.Condition
+	if c.Op != OpExists {
+		// see if the triplet (event attribute, operator, operand) matches any event
+		// "tx.gas", "=", "7", { "tx.gas": 7, "tx.ID": "4AE393495334" }
+		return match(c.CompositeKey, c.Op, c.Operand, e.date, events)
+	}
+
+	if strings.Contains(c.CompositeKey, ".") {
+		// Searching for a full "type.attribute" event.
+		_, ok := events[c.CompositeKey]
+		return ok, nil
+	}
+	for compositeKey := range events {
+		if strings.Index(compositeKey, c.CompositeKey) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// join returns the conjunction or the disjunction (depending on kind) of the
+// operands, flattening the operands of the same kind.
+func join(kind ExprKind, operands []*Expr) *Expr {
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	e := &Expr{Kind: kind}
+	for _, operand := range operands {
+		if operand.Kind == kind {
+			e.Operands = append(e.Operands, operand.Operands...)
+		} else {
+			e.Operands = append(e.Operands, operand)
+		}
+	}
+	return e
+}
@@ -0,0 +1,353 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The grammar of the queries, where the operators NOT, AND and OR are given
+// from the highest to the lowest precedence:
+//
+//	query     <- or
+//	or        <- and ( "OR" and )*
+//	and       <- unary ( "AND" unary )*
+//	unary     <- "NOT" unary / "(" or ")" / condition
+//	condition <- tag ( ("<=" / ">=" / "<" / ">") (number / time / date)
+//	                 / "=" (number / time / date / value)
+//	                 / "CONTAINS" value
+//	                 / "EXISTS"
+//	                 / "IN" "(" operand ( "," operand )* ")"
+//	                 )
+//	operand   <- number / time / date / value
+//	tag       <- (![ \t\n\r\\()"'=><] .)+
+//	value     <- '\'' (!["'] .)* '\''
+//	number    <- ('0' / [1-9] [0-9]*) ('.' [0-9]*)?
+//	time      <- "TIME " <RFC3339 time, e.g. 2013-05-03T14:45:00Z>
+//	date      <- "DATE " <date, e.g. 2013-05-03>
+//
+// The keywords are separated from the following tokens by white spaces.
+var (
+	timeRegex = regexp.MustCompile(`^[12]\d{3}-[01]\d-[0-3]\dT\d\d:\d\d:\d\d(Z|[+-]\d\d:\d\d)$`)
+	dateRegex = regexp.MustCompile(`^[12]\d{3}-[01]\d-[0-3]\d$`)
+)
+
+// parser is a recursive descent parser of the queries.
+type parser struct {
+	s   string
+	pos int
+}
+
+// parse parses the query.
+func parse(s string) (*Expr, error) {
+	p := &parser{s: s}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return e, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("parse error near position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+// keyword consumes the keyword if it is next, and followed by a white space,
+// or one of the given characters (or the end of the query, if end is true).
+func (p *parser) keyword(kw string, end bool, followers string) bool {
+	if !strings.HasPrefix(p.s[p.pos:], kw) {
+		return false
+	}
+	next := p.pos + len(kw)
+	if next == len(p.s) {
+		if !end {
+			return false
+		}
+	} else if !isSpace(p.s[next]) && strings.IndexByte(followers, p.s[next]) < 0 {
+		return false
+	}
+	p.pos = next
+	return true
+}
+
+func (p *parser) or() (*Expr, error) {
+	var operands []*Expr
+	for {
+		e, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+		p.skipSpaces()
+		if !p.keyword("OR", false, "(") {
+			return join(ExprOr, operands), nil
+		}
+	}
+}
+
+func (p *parser) and() (*Expr, error) {
+	var operands []*Expr
+	for {
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+		p.skipSpaces()
+		if !p.keyword("AND", false, "(") {
+			return join(ExprAnd, operands), nil
+		}
+	}
+}
+
+func (p *parser) unary() (*Expr, error) {
+	p.skipSpaces()
+	switch {
+	case p.keyword("NOT", false, "("):
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: ExprNot, Operands: []*Expr{e}}, nil
+
+	case !p.eof() && p.s[p.pos] == '(':
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.eof() || p.s[p.pos] != ')' {
+			return nil, p.errorf("expected )")
+		}
+		p.pos++
+		return e, nil
+
+	default:
+		return p.condition()
+	}
+}
+
+func (p *parser) condition() (*Expr, error) {
+	start := p.pos
+	for !p.eof() && isTagChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.errorf("expected a tag")
+	}
+	c := Condition{CompositeKey: p.s[start:p.pos]}
+
+	p.skipSpaces()
+	switch {
+	case p.keyword("EXISTS", true, ")"):
+		c.Op = OpExists
+		return &Expr{Kind: ExprCondition, Condition: c}, nil
+
+	case p.keyword("CONTAINS", false, "'"):
+		c.Op = OpContains
+		p.skipSpaces()
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = value
+		return &Expr{Kind: ExprCondition, Condition: c}, nil
+
+	case p.keyword("IN", false, "("):
+		return p.in(c.CompositeKey)
+
+	case strings.HasPrefix(p.s[p.pos:], "<="):
+		c.Op = OpLessEqual
+	case strings.HasPrefix(p.s[p.pos:], ">="):
+		c.Op = OpGreaterEqual
+	case strings.HasPrefix(p.s[p.pos:], "<"):
+		c.Op = OpLess
+	case strings.HasPrefix(p.s[p.pos:], ">"):
+		c.Op = OpGreater
+	case strings.HasPrefix(p.s[p.pos:], "="):
+		c.Op = OpEqual
+	default:
+		return nil, p.errorf("expected an operator after %s", c.CompositeKey)
+	}
+	p.pos += len(c.Op.String())
+
+	p.skipSpaces()
+	e, err := p.operand(c, c.Op == OpEqual)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// in parses the list of an IN condition, as the disjunction of the equalities.
+func (p *parser) in(tag string) (*Expr, error) {
+	p.skipSpaces()
+	if p.eof() || p.s[p.pos] != '(' {
+		return nil, p.errorf("expected ( after IN")
+	}
+	p.pos++
+
+	var operands []*Expr
+	for {
+		p.skipSpaces()
+		e, err := p.operand(Condition{CompositeKey: tag, Op: OpEqual}, true)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+
+		p.skipSpaces()
+		if p.eof() {
+			return nil, p.errorf("expected )")
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return join(ExprOr, operands), nil
+		default:
+			return nil, p.errorf("expected , or )")
+		}
+	}
+}
+
+// operand parses the operand of the condition: a number, a time or a date, or
+// a value if withValue is true.
+func (p *parser) operand(c Condition, withValue bool) (*Expr, error) {
+	e := &Expr{Kind: ExprCondition}
+	switch {
+	case p.keyword("TIME", false, ""):
+		p.skipSpaces()
+		t, err := p.time(timeRegex, TimeLayout)
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = t
+
+	case p.keyword("DATE", false, ""):
+		p.skipSpaces()
+		t, err := p.time(dateRegex, DateLayout)
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = t
+		e.date = true
+
+	case !p.eof() && p.s[p.pos] == '\'':
+		if !withValue {
+			return nil, p.errorf("expected a number, a time or a date after %s", c.Op)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = value
+
+	default:
+		number, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		c.Operand = number
+	}
+	e.Condition = c
+	return e, nil
+}
+
+// value parses a string between single quotes.
+func (p *parser) value() (string, error) {
+	if p.eof() || p.s[p.pos] != '\'' {
+		return "", p.errorf("expected a value in single quotes")
+	}
+	end := strings.IndexAny(p.s[p.pos+1:], `'"`)
+	if end < 0 || p.s[p.pos+1+end] != '\'' {
+		return "", p.errorf("unterminated value")
+	}
+	value := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return value, nil
+}
+
+// number parses an int64, or a float64 if it has a decimal point.
+func (p *parser) number() (interface{}, error) {
+	start := p.pos
+	if !p.eof() && p.s[p.pos] == '0' {
+		p.pos++
+	} else {
+		if p.eof() || p.s[p.pos] < '1' || p.s[p.pos] > '9' {
+			return nil, p.errorf("expected an operand")
+		}
+		p.skipDigits()
+	}
+	float := !p.eof() && p.s[p.pos] == '.'
+	if float {
+		p.pos++
+		p.skipDigits()
+	}
+	if !p.eof() && !isSpace(p.s[p.pos]) && p.s[p.pos] != ')' && p.s[p.pos] != ',' {
+		return nil, p.errorf("invalid number %s", p.s[start:])
+	}
+
+	number := p.s[start:p.pos]
+	if float {
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %s: %v", number, err)
+		}
+		return value, nil
+	}
+	value, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %s: %v", number, err)
+	}
+	return value, nil
+}
+
+func (p *parser) skipDigits() {
+	for !p.eof() && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+}
+
+// time parses a time matching the regular expression, in the layout.
+func (p *parser) time(re *regexp.Regexp, layout string) (time.Time, error) {
+	start := p.pos
+	for !p.eof() && !isSpace(p.s[p.pos]) && p.s[p.pos] != ')' && p.s[p.pos] != ',' {
+		p.pos++
+	}
+	s := p.s[start:p.pos]
+	if !re.MatchString(s) {
+		return time.Time{}, p.errorf("invalid time %s", s)
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, p.errorf("invalid time %s: %v", s, err)
+	}
+	return t, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isTagChar(c byte) bool {
+	return !isSpace(c) && strings.IndexByte(`\()"'=><`, c) < 0
+}
@@ -84,6 +84,31 @@ func TestParser(t *testing.T) {
 
 		{"hash='136E18F7E4C348B780CF873A0BF43922E5BAFA63'", true},
 		{"hash=136E18F7E4C348B780CF873A0BF43922E5BAFA63", false},
+
+		{"tm.events.type='NewBlock' OR tm.events.type='Tx'", true},
+		{"tm.events.type='NewBlock' OR", false},
+		{"OR tm.events.type='NewBlock'", false},
+		{"tm.events.type='NewBlock' ORtm.events.type='Tx'", false},
+		{"NOT tm.events.type='NewBlock'", true},
+		{"NOT NOT slashing EXISTS", true},
+		{"NOT", false},
+		{"NOTE.name='x'", true},
+		{"(tm.events.type='NewBlock')", true},
+		{"account.balance>1 AND (account.owner='Ivan' OR NOT account.owner EXISTS)", true},
+		{"(account.owner='Ivan' OR account.owner='Igor') AND(account.balance<1.5)", true},
+		{"(account.owner='Ivan'", false},
+		{"account.owner='Ivan')", false},
+		{"()", false},
+
+		{"account.owner IN ('Ivan', 'Igor')", true},
+		{"account.owner IN('Ivan','Igor')", true},
+		{"account.balance IN (1, 2.5)", true},
+		{"tx.date IN (DATE 2013-05-03, TIME 2013-05-03T14:45:00Z)", true},
+		{"account.owner IN ()", false},
+		{"account.owner IN ('Ivan',)", false},
+		{"account.owner IN ('Ivan' 'Igor')", false},
+		{"account.owner IN 'Ivan'", false},
+		{"account.owner IN ('Ivan'", false},
 	}
 
 	for _, c := range cases {
@@ -1,3 +0,0 @@
-package query
-
-//go:generate peg -inline -switch query.peg
@@ -1,16 +1,19 @@
 // Package query provides a parser for a custom query format:
 //
-//	abci.invoice.number=22 AND abci.invoice.owner=Ivan
+//	abci.invoice.number=22 AND (abci.invoice.owner='Ivan' OR NOT abci.invoice.paid EXISTS)
 //
-// See query.peg for the grammar, which is a https://en.wikipedia.org/wiki/Parsing_expression_grammar.
-// More: https://github.com/PhilippeSigaud/Pegged/wiki/PEG-Basics
+// See parser.go for the grammar. The conditions can be combined with the AND,
+// OR and NOT operators and parentheses, and "key IN (a, b)" is a shorthand for
+// "key = a OR key = b".
 //
 // It has a support for numbers (integer and floating point), dates and times.
+// The numbers in the events are compared exactly to the operands, whatever
+// their types, and the times to the dates by day.
 package query
 
 import (
 	"fmt"
-	"reflect"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -21,10 +24,10 @@ var (
 	numRegex = regexp.MustCompile(`([0-9\.]+)`)
 )
 
-// Query holds the query string and the query parser.
+// Query holds the query string and its syntax tree.
 type Query struct {
-	str    string
-	parser *QueryParser
+	str  string
+	expr *Expr
 }
 
 // Condition represents a single condition within a query and consists of composite key
@@ -38,12 +41,11 @@ type Condition struct {
 // New parses the given string and returns a query or error if the string is
 // invalid.
 func New(s string) (*Query, error) {
-	p := &QueryParser{Buffer: fmt.Sprintf(`"%s"`, s)}
-	p.Init()
-	if err := p.Parse(); err != nil {
+	expr, err := parse(s)
+	if err != nil {
 		return nil, err
 	}
-	return &Query{str: s, parser: p}, nil
+	return &Query{str: s, expr: expr}, nil
 }
 
 // MustParse turns the given string into a query or panics; for tests or others
@@ -89,274 +91,36 @@ const (
 	TimeLayout = time.RFC3339
 )
 
-// Conditions returns a list of conditions. It returns an error if there is any
-// error with the provided grammar in the Query.
-func (q *Query) Conditions() ([]Condition, error) {
-	var (
-		eventAttr string
-		op        Operator
-	)
-
-	conditions := make([]Condition, 0)
-	buffer, begin, end := q.parser.Buffer, 0, 0
-
-	// tokens must be in the following order: tag ("tx.gas") -> operator ("=") -> operand ("7")
-	for token := range q.parser.Tokens() {
-		switch token.pegRule {
-		case rulePegText:
-			begin, end = int(token.begin), int(token.end)
-
-		case ruletag:
-			eventAttr = buffer[begin:end]
-
-		case rulele:
-			op = OpLessEqual
-
-		case rulege:
-			op = OpGreaterEqual
-
-		case rulel:
-			op = OpLess
-
-		case ruleg:
-			op = OpGreater
-
-		case ruleequal:
-			op = OpEqual
-
-		case rulecontains:
-			op = OpContains
-
-		case ruleexists:
-			op = OpExists
-			conditions = append(conditions, Condition{eventAttr, op, nil})
-
-		case rulevalue:
-			// strip single quotes from value (i.e. "'NewBlock'" -> "NewBlock")
-			valueWithoutSingleQuotes := buffer[begin+1 : end-1]
-			conditions = append(conditions, Condition{eventAttr, op, valueWithoutSingleQuotes})
-
-		case rulenumber:
-			number := buffer[begin:end]
-			if strings.ContainsAny(number, ".") { // if it looks like a floating-point number
-				value, err := strconv.ParseFloat(number, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as float64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return nil, err
-				}
-
-				conditions = append(conditions, Condition{eventAttr, op, value})
-			} else {
-				value, err := strconv.ParseInt(number, 10, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as int64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return nil, err
-				}
-
-				conditions = append(conditions, Condition{eventAttr, op, value})
-			}
-
-		case ruletime:
-			value, err := time.Parse(TimeLayout, buffer[begin:end])
-			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / RFC3339 (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return nil, err
-			}
-
-			conditions = append(conditions, Condition{eventAttr, op, value})
-
-		case ruledate:
-			value, err := time.Parse("2006-01-02", buffer[begin:end])
-			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / '2006-01-02' (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return nil, err
-			}
+// Expr returns the syntax tree of the query.
+func (q *Query) Expr() *Expr {
+	return q.expr
+}
 
-			conditions = append(conditions, Condition{eventAttr, op, value})
-		}
+// Conditions returns a list of conditions. It returns an error if the query
+// isn't a conjunction of conditions, i.e. if it has OR, NOT or IN operators, in
+// which case its syntax tree must be used instead (see Expr).
+func (q *Query) Conditions() ([]Condition, error) {
+	conditions, ok := q.expr.Conditions()
+	if !ok {
+		return nil, fmt.Errorf("query %q isn't a conjunction of conditions", q.str)
 	}
-
 	return conditions, nil
 }
 
 // Matches returns true if the query matches against any event in the given set
 // of events, false otherwise. For each event, a match exists if the query is
-// matched against *any* value in a slice of values. An error is returned if
+// matched against *any* value in a slice of values, and a negated condition
+// matches if the condition doesn't match any value. An error is returned if
 // any attempted event match returns an error.
 //
-// For example, query "name=John" matches events = {"name": ["John", "Eric"]}.
+// For example, query "name=John" matches events = {"name": ["John", "Eric"]},
+// and query "NOT name=John" doesn't.
 // More examples could be found in parser_test.go and query_test.go.
 func (q *Query) Matches(events map[string][]string) (bool, error) {
 	if len(events) == 0 {
 		return false, nil
 	}
-
-	var (
-		eventAttr string
-		op        Operator
-	)
-
-	buffer, begin, end := q.parser.Buffer, 0, 0
-
-	// tokens must be in the following order:
-
-	// tag ("tx.gas") -> operator ("=") -> operand ("7")
-	for token := range q.parser.Tokens() {
-		switch token.pegRule {
-		case rulePegText:
-			begin, end = int(token.begin), int(token.end)
-
-		case ruletag:
-			eventAttr = buffer[begin:end]
-
-		case rulele:
-			op = OpLessEqual
-
-		case rulege:
-			op = OpGreaterEqual
-
-		case rulel:
-			op = OpLess
-
-		case ruleg:
-			op = OpGreater
-
-		case ruleequal:
-			op = OpEqual
-
-		case rulecontains:
-			op = OpContains
-		case ruleexists:
-			op = OpExists
-			if strings.Contains(eventAttr, ".") {
-				// Searching for a full "type.attribute" event.
-				_, ok := events[eventAttr]
-				if !ok {
-					return false, nil
-				}
-			} else {
-				foundEvent := false
-
-			loop:
-				for compositeKey := range events {
-					if strings.Index(compositeKey, eventAttr) == 0 {
-						foundEvent = true
-						break loop
-					}
-				}
-				if !foundEvent {
-					return false, nil
-				}
-			}
-
-		case rulevalue:
-			// strip single quotes from value (i.e. "'NewBlock'" -> "NewBlock")
-			valueWithoutSingleQuotes := buffer[begin+1 : end-1]
-
-			// see if the triplet (event attribute, operator, operand) matches any event
-			// "tx.gas", "=", "7", { "tx.gas": 7, "tx.ID": "4AE393495334" }
-			match, err := match(eventAttr, op, reflect.ValueOf(valueWithoutSingleQuotes), events)
-			if err != nil {
-				return false, err
-			}
-
-			if !match {
-				return false, nil
-			}
-
-		case rulenumber:
-			number := buffer[begin:end]
-			if strings.ContainsAny(number, ".") { // if it looks like a floating-point number
-				value, err := strconv.ParseFloat(number, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as float64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return false, err
-				}
-
-				match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-				if err != nil {
-					return false, err
-				}
-
-				if !match {
-					return false, nil
-				}
-			} else {
-				value, err := strconv.ParseInt(number, 10, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as int64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return false, err
-				}
-
-				match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-				if err != nil {
-					return false, err
-				}
-
-				if !match {
-					return false, nil
-				}
-			}
-
-		case ruletime:
-			value, err := time.Parse(TimeLayout, buffer[begin:end])
-			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / RFC3339 (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return false, err
-			}
-
-			match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-			if err != nil {
-				return false, err
-			}
-
-			if !match {
-				return false, nil
-			}
-
-		case ruledate:
-			value, err := time.Parse("2006-01-02", buffer[begin:end])
-			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / '2006-01-02' (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return false, err
-			}
-
-			match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-			if err != nil {
-				return false, err
-			}
-
-			if !match {
-				return false, nil
-			}
-		}
-	}
-
-	return true, nil
+	return q.expr.matches(events)
 }
 
 // match returns true if the given triplet (attribute, operator, operand) matches
@@ -367,7 +131,7 @@ func (q *Query) Matches(events map[string][]string) (bool, error) {
 // all the values from it to the operand using the operator.
 //
 // "tx.gas", "=", "7", {"tx": [{"gas": 7, "ID": "4AE393495334"}]}
-func match(attr string, op Operator, operand reflect.Value, events map[string][]string) (bool, error) {
+func match(attr string, op Operator, operand interface{}, date bool, events map[string][]string) (bool, error) {
 	// look up the tag from the query in tags
 	values, ok := events[attr]
 	if !ok {
@@ -376,7 +140,7 @@ func match(attr string, op Operator, operand reflect.Value, events map[string][]
 
 	for _, value := range values {
 		// return true if any value in the set of the event's values matches
-		match, err := matchValue(value, op, operand)
+		match, err := matchValue(value, op, operand, date)
 		if err != nil {
 			return false, err
 		}
@@ -392,111 +156,106 @@ func match(attr string, op Operator, operand reflect.Value, events map[string][]
 // matchValue will attempt to match a string value against an operator an
 // operand. A boolean is returned representing the match result. It will return
 // an error if the value cannot be parsed and matched against the operand type.
-func matchValue(value string, op Operator, operand reflect.Value) (bool, error) {
-	switch operand.Kind() {
-	case reflect.Struct: // time
-		operandAsTime := operand.Interface().(time.Time)
-
+func matchValue(value string, op Operator, operand interface{}, date bool) (bool, error) {
+	switch operand := operand.(type) {
+	case time.Time:
 		// try our best to convert value from events to time.Time
-		var (
-			v   time.Time
-			err error
-		)
-
-		if strings.ContainsAny(value, "T") {
-			v, err = time.Parse(TimeLayout, value)
-		} else {
-			v, err = time.Parse(DateLayout, value)
-		}
+		v, err := parseTime(value)
 		if err != nil {
 			return false, fmt.Errorf("failed to convert value %v from event attribute to time.Time: %w", value, err)
 		}
-
-		switch op {
-		case OpLessEqual:
-			return (v.Before(operandAsTime) || v.Equal(operandAsTime)), nil
-		case OpGreaterEqual:
-			return (v.Equal(operandAsTime) || v.After(operandAsTime)), nil
-		case OpLess:
-			return v.Before(operandAsTime), nil
-		case OpGreater:
-			return v.After(operandAsTime), nil
-		case OpEqual:
-			return v.Equal(operandAsTime), nil
+		if date {
+			// compare the day of the time, in its time zone
+			v = time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)
 		}
+		return compare(op, v.Compare(operand)), nil
 
-	case reflect.Float64:
-		var v float64
-
-		operandFloat64 := operand.Interface().(float64)
+	case int64, float64:
 		filteredValue := numRegex.FindString(value)
 
-		// try our best to convert value from tags to float64
-		v, err := strconv.ParseFloat(filteredValue, 64)
-		if err != nil {
-			return false, fmt.Errorf("failed to convert value %v from event attribute to float64: %w", filteredValue, err)
+		// try our best to convert value from tags to a number, compared
+		// exactly to the operand whether either is an integer or not
+		v, ok := new(big.Rat).SetString(filteredValue)
+		if !ok {
+			return false, fmt.Errorf("failed to convert value %v from event attribute to a number", filteredValue)
 		}
+		return compare(op, v.Cmp(numberRat(operand))), nil
 
+	case string:
 		switch op {
-		case OpLessEqual:
-			return v <= operandFloat64, nil
-		case OpGreaterEqual:
-			return v >= operandFloat64, nil
-		case OpLess:
-			return v < operandFloat64, nil
-		case OpGreater:
-			return v > operandFloat64, nil
 		case OpEqual:
-			return v == operandFloat64, nil
+			return value == operand, nil
+		case OpContains:
+			return strings.Contains(value, operand), nil
 		}
 
-	case reflect.Int64:
-		var v int64
+	default:
+		return false, fmt.Errorf("unknown type of operand %T", operand)
+	}
 
-		operandInt := operand.Interface().(int64)
-		filteredValue := numRegex.FindString(value)
+	return false, nil
+}
 
-		// if value looks like float, we try to parse it as float
-		if strings.ContainsAny(filteredValue, ".") {
-			v1, err := strconv.ParseFloat(filteredValue, 64)
-			if err != nil {
-				return false, fmt.Errorf("failed to convert value %v from event attribute to float64: %w", filteredValue, err)
-			}
+// compare returns the result of the comparison operator, given the result of
+// the comparison of the value to the operand (-1, 0 or +1).
+func compare(op Operator, cmp int) bool {
+	switch op {
+	case OpLessEqual:
+		return cmp <= 0
+	case OpGreaterEqual:
+		return cmp >= 0
+	case OpLess:
+		return cmp < 0
+	case OpGreater:
+		return cmp > 0
+	case OpEqual:
+		return cmp == 0
+	default:
+		return false
+	}
+}
 
-			v = int64(v1)
-		} else {
-			var err error
-			// try our best to convert value from tags to int64
-			v, err = strconv.ParseInt(filteredValue, 10, 64)
-			if err != nil {
-				return false, fmt.Errorf("failed to convert value %v from event attribute to int64: %w", filteredValue, err)
-			}
-		}
+// parseTime parses a time, in the RFC3339 layout (with or without fractional
+// seconds), or a date.
+func parseTime(value string) (time.Time, error) {
+	if strings.ContainsAny(value, "T") {
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return time.Parse(DateLayout, value)
+}
 
-		switch op {
-		case OpLessEqual:
-			return v <= operandInt, nil
-		case OpGreaterEqual:
-			return v >= operandInt, nil
-		case OpLess:
-			return v < operandInt, nil
-		case OpGreater:
-			return v > operandInt, nil
-		case OpEqual:
-			return v == operandInt, nil
-		}
+// numberRat returns the int64 or float64 number as a rational number, the
+// float64 numbers being taken as written in the query (e.g. 0.1 and not its
+// binary approximation).
+func numberRat(number interface{}) *big.Rat {
+	switch number := number.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(number)
+	default:
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(number.(float64), 'f', -1, 64))
+		return r
+	}
+}
 
-	case reflect.String:
-		switch op {
-		case OpEqual:
-			return value == operand.String(), nil
-		case OpContains:
-			return strings.Contains(value, operand.String()), nil
+// formatOperand formats the operand in the query syntax.
+func formatOperand(operand interface{}, date bool) string {
+	switch operand := operand.(type) {
+	case string:
+		return "'" + operand + "'"
+	case int64:
+		return strconv.FormatInt(operand, 10)
+	case float64:
+		s := strconv.FormatFloat(operand, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
 		}
-
+		return s
+	case time.Time:
+		if date {
+			return "DATE " + operand.Format(DateLayout)
+		}
+		return "TIME " + operand.Format(TimeLayout)
 	default:
-		return false, fmt.Errorf("unknown kind of operand %v", operand.Kind())
+		return fmt.Sprintf("%v", operand)
 	}
-
-	return false, nil
 }
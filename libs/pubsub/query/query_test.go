@@ -220,3 +220,100 @@ func TestConditions(t *testing.T) {
 		assert.Equal(t, tc.conditions, c)
 	}
 }
+
+func TestMatchesExpr(t *testing.T) {
+	events := map[string][]string{
+		"account.owner":   {"Ivan", "Igor"},
+		"account.balance": {"100"},
+		"tx.date":         {"2017-01-01T10:00:00Z"},
+	}
+	testCases := []struct {
+		s       string
+		matches bool
+	}{
+		{"account.owner='Pavel' OR account.owner='Igor'", true},
+		{"account.owner='Pavel' OR account.owner='John'", false},
+		{"account.owner='Pavel' OR account.balance=100", true},
+		{"NOT account.owner='Pavel'", true},
+		{"NOT account.owner='Igor'", false},
+		{"NOT slashing EXISTS", true},
+		{"NOT NOT account EXISTS", true},
+		{"account.balance > 10 AND NOT account.owner='Ivan'", false},
+		{"account.balance > 10 AND (account.owner='Pavel' OR account.owner='Ivan')", true},
+		{"account.balance > 1000 OR account.owner='Pavel' AND account.balance=100", false},
+		{"account.owner IN ('Pavel', 'Igor')", true},
+		{"account.owner IN ('Pavel', 'John')", false},
+		{"account.balance IN (99, 100)", true},
+		{"NOT account.balance IN (99, 100)", false},
+
+		// the numbers are compared exactly, whatever their types
+		{"account.balance = 100.0", true},
+		{"account.balance < 100.5", true},
+		{"account.balance > 99.999", true},
+		// the times are compared to the dates by day
+		{"tx.date = DATE 2017-01-01", true},
+		{"tx.date > DATE 2016-12-31", true},
+		{"tx.date < DATE 2017-01-02", true},
+		{"tx.date > TIME 2017-01-01T09:00:00Z", true},
+		{"tx.date = TIME 2017-01-01T12:00:00+02:00", true},
+	}
+	for _, tc := range testCases {
+		match, err := query.MustParse(tc.s).Matches(events)
+		require.NoError(t, err, tc.s)
+		assert.Equal(t, tc.matches, match, tc.s)
+	}
+}
+
+func TestMatchesTypedNumbers(t *testing.T) {
+	testCases := []struct {
+		s       string
+		value   string
+		matches bool
+	}{
+		// the float values aren't truncated to the integer operands
+		{"apples.kg <= 4", "4.5", false},
+		{"apples.kg > 4", "4.5", true},
+		{"apples.kg = 4", "4.5", false},
+		// the float operands aren't approximated
+		{"apples.kg > 0.1", "0.1", false},
+		{"apples.kg = 0.3", "0.3", true},
+		// the integers aren't approximated
+		{"apples.kg = 9007199254740993", "9007199254740993", true},
+		{"apples.kg < 9007199254740993", "9007199254740992", true},
+		{"apples.kg > 9007199254740993", "99999999999999999999", true},
+	}
+	for _, tc := range testCases {
+		match, err := query.MustParse(tc.s).Matches(map[string][]string{"apples.kg": {tc.value}})
+		require.NoError(t, err, tc.s)
+		assert.Equal(t, tc.matches, match, "%s with %s", tc.s, tc.value)
+	}
+}
+
+func TestExpr(t *testing.T) {
+	q := query.MustParse("a.b > 1 AND (a.c = 'x' OR NOT a.d EXISTS) AND a.e IN (DATE 2013-05-03, 1.5)")
+	e := q.Expr()
+	assert.Equal(t, query.ExprAnd, e.Kind)
+	require.Len(t, e.Operands, 3)
+	assert.Equal(t, query.ExprCondition, e.Operands[0].Kind)
+	assert.Equal(t, query.Condition{CompositeKey: "a.b", Op: query.OpGreater, Operand: int64(1)}, e.Operands[0].Condition)
+	assert.Equal(t, query.ExprOr, e.Operands[1].Kind)
+	assert.Equal(t, query.ExprNot, e.Operands[1].Operands[1].Kind)
+	assert.Equal(t,
+		"(a.b > 1 AND (a.c = 'x' OR NOT a.d EXISTS) AND (a.e = DATE 2013-05-03 OR a.e = 1.5))",
+		e.String())
+	_, ok := e.Conditions()
+	assert.False(t, ok)
+	_, err := q.Conditions()
+	assert.Error(t, err)
+
+	// the string of the expression is parsed as the same expression
+	assert.Equal(t, e, query.MustParse(e.String()).Expr())
+
+	// the parentheses around a conjunction don't matter
+	c, err := query.MustParse("(a.b > 1 AND a.c = 'x') AND a.d EXISTS").Conditions()
+	require.NoError(t, err)
+	assert.Len(t, c, 3)
+	c, err = query.MustParse("a.b IN (1)").Conditions()
+	require.NoError(t, err)
+	assert.Equal(t, []query.Condition{{CompositeKey: "a.b", Op: query.OpEqual, Operand: int64(1)}}, c)
+}
@@ -2,6 +2,8 @@ package pubsub
 
 import (
 	"errors"
+	"fmt"
+	"sync/atomic"
 
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 )
@@ -15,23 +17,85 @@ var (
 	ErrOutOfCapacity = errors.New("internal subscription event buffer is out of capacity")
 )
 
+// OverflowPolicy is what the server does when it publishes a message to a
+// subscription whose buffer (the channel returned by Out) is full.
+type OverflowPolicy int
+
+const (
+	// Disconnect terminates the subscription with ErrOutOfCapacity. It is the
+	// policy of the subscriptions created by Subscribe.
+	Disconnect OverflowPolicy = iota
+	// BlockPublisher blocks the publisher until the subscriber pulls a message.
+	// It is the policy of the subscriptions created by SubscribeUnbuffered. Use
+	// with caution as a slow subscriber delays the delivery of the messages to
+	// all the subscribers, and the publisher itself.
+	BlockPublisher
+	// DropOldest drops the oldest message of the buffer to make room for the
+	// new one.
+	DropOldest
+	// DropNew drops the new message.
+	DropNew
+)
+
+var overflowPolicyNames = map[OverflowPolicy]string{
+	Disconnect:     "disconnect",
+	BlockPublisher: "block",
+	DropOldest:     "drop_oldest",
+	DropNew:        "drop_new",
+}
+
+// String returns the name of the policy, as parsed by ParseOverflowPolicy.
+func (p OverflowPolicy) String() string {
+	if name, ok := overflowPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// ParseOverflowPolicy returns the policy of the given name: "disconnect",
+// "block", "drop_oldest" or "drop_new".
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	for p, n := range overflowPolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown overflow policy %q", name)
+}
+
 // A Subscription represents a client subscription for a particular query and
 // consists of three things:
 // 1) channel onto which messages and events are published
 // 2) channel which is closed if a client is too slow or choose to unsubscribe
 // 3) err indicating the reason for (2)
 type Subscription struct {
-	out chan Message
+	out    chan Message
+	policy OverflowPolicy
+	// number of messages dropped because the buffer was full
+	dropped uint64
 
 	canceled chan struct{}
 	mtx      tmsync.RWMutex
 	err      error
 }
 
-// NewSubscription returns a new subscription with the given outCapacity.
+// NewSubscription returns a new subscription with the given outCapacity,
+// which blocks the publisher if it is unbuffered and is terminated when its
+// buffer is full otherwise.
 func NewSubscription(outCapacity int) *Subscription {
+	policy := Disconnect
+	if outCapacity == 0 {
+		policy = BlockPublisher
+	}
+	return NewSubscriptionWithPolicy(outCapacity, policy)
+}
+
+// NewSubscriptionWithPolicy returns a new subscription with the given
+// outCapacity and policy when its buffer is full.
+func NewSubscriptionWithPolicy(outCapacity int, policy OverflowPolicy) *Subscription {
 	return &Subscription{
 		out:      make(chan Message, outCapacity),
+		policy:   policy,
 		canceled: make(chan struct{}),
 	}
 }
@@ -50,11 +114,23 @@ func (s *Subscription) Cancelled() <-chan struct{} {
 	return s.canceled
 }
 
+// Policy returns what the server does when the buffer of the subscription is
+// full.
+func (s *Subscription) Policy() OverflowPolicy {
+	return s.policy
+}
+
+// Dropped returns the number of messages dropped because the buffer of the
+// subscription was full, with the DropOldest and DropNew policies.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
 // Err returns nil if the channel returned is not yet closed.
 // If the channel is closed, Err returns a non-nil error explaining why:
 //   - ErrUnsubscribed if the subscriber choose to unsubscribe,
 //   - ErrOutOfCapacity if the subscriber is not pulling messages fast enough
-//     and the channel returned by Out became full,
+//     and the channel returned by Out became full, with the Disconnect policy,
 //
 // After Err returns a non-nil error, successive calls to Err return the same
 // error.
@@ -71,6 +147,39 @@ func (s *Subscription) cancel(err error) {
 	close(s.canceled)
 }
 
+// push sends the message to the subscription, applying its policy if its
+// buffer is full. It returns false if the subscription must be terminated.
+func (s *Subscription) push(msg Message, metrics *Metrics) bool {
+	select {
+	case s.out <- msg:
+		return true
+	default:
+	}
+
+	switch s.policy {
+	case BlockPublisher:
+		metrics.BlockedPublishes.Add(1)
+		s.out <- msg
+	case DropOldest:
+		select {
+		case <-s.out:
+			atomic.AddUint64(&s.dropped, 1)
+			metrics.DroppedMessages.With("policy", s.policy.String()).Add(1)
+		default:
+			// the subscriber pulled a message meanwhile
+		}
+		// there is room for the message, as the server is the only sender
+		s.out <- msg
+	case DropNew:
+		atomic.AddUint64(&s.dropped, 1)
+		metrics.DroppedMessages.With("policy", s.policy.String()).Add(1)
+	default:
+		metrics.DisconnectedSubscriptions.Add(1)
+		return false
+	}
+	return true
+}
+
 // Message glues data and events together.
 type Message struct {
 	data   interface{}
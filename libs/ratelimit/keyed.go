@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// Keyed limits the events by key (e.g. by peer or by client address), each key
+// having its own Limiter, under an optional limit shared by all the keys.
+type Keyed struct {
+	mtx        tmsync.Mutex
+	newLimiter func() Limiter
+	parent     Limiter
+	idle       time.Duration
+	limiters   map[string]*keyedLimiter
+	lastPrune  time.Time
+}
+
+type keyedLimiter struct {
+	Limiter
+	last time.Time // time of the last event
+}
+
+// NewKeyed returns a Keyed creating the Limiter of a key with newLimiter, on
+// its first event. If parent isn't nil, the events of all the keys are also
+// limited by it.
+//
+// If idle is positive, the limiters of the keys without any event for idle
+// are forgotten, which must not be shorter than the time their limits take
+// to be reset (e.g. the time to refill a TokenBucket, or two windows of a
+// SlidingWindow). Otherwise, the keys have to be removed with Remove.
+func NewKeyed(newLimiter func() Limiter, parent Limiter, idle time.Duration) *Keyed {
+	return &Keyed{
+		newLimiter: newLimiter,
+		parent:     parent,
+		idle:       idle,
+		limiters:   make(map[string]*keyedLimiter),
+	}
+}
+
+// AllowN reports whether n events of the key may happen at time now, and
+// records them if so, see Limiter.
+func (k *Keyed) AllowN(key string, now time.Time, n int) bool {
+	l := k.limiter(key, now)
+	if k.parent == nil {
+		return l.AllowN(now, n)
+	}
+	if !l.AllowN(now, n) {
+		return false
+	}
+	if !k.parent.AllowN(now, n) {
+		l.ReturnN(now, n)
+		return false
+	}
+	return true
+}
+
+// Remove forgets the limiter of the key, e.g. when a peer is removed.
+func (k *Keyed) Remove(key string) {
+	k.mtx.Lock()
+	delete(k.limiters, key)
+	k.mtx.Unlock()
+}
+
+// Len returns the number of keys with a limiter.
+func (k *Keyed) Len() int {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	return len(k.limiters)
+}
+
+func (k *Keyed) limiter(key string, now time.Time) Limiter {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if k.idle > 0 && now.Sub(k.lastPrune) >= k.idle {
+		for key, l := range k.limiters {
+			if now.Sub(l.last) >= k.idle {
+				delete(k.limiters, key)
+			}
+		}
+		k.lastPrune = now
+	}
+
+	l, ok := k.limiters[key]
+	if !ok {
+		l = &keyedLimiter{Limiter: k.newLimiter()}
+		k.limiters[key] = l
+	}
+	if now.After(l.last) {
+		l.last = now
+	}
+	return l
+}
@@ -0,0 +1,53 @@
+// Package ratelimit provides the rate limiters of the events (e.g. the
+// messages, the requests or the txs) received by the node: a token bucket, a
+// sliding window, and their combinations into hierarchical limits, e.g. a
+// limit per peer under a limit for all the peers.
+//
+// The limiters take the time of the events as an argument, rather than
+// reading the clock, so that they can be tested deterministically.
+package ratelimit
+
+import (
+	"time"
+)
+
+// Limiter limits the rate of events.
+//
+// The implementations are goroutine-safe.
+type Limiter interface {
+	// AllowN reports whether n events may happen at time now, and records them
+	// if so.
+	AllowN(now time.Time, n int) bool
+
+	// ReturnN forgets n events recorded by AllowN at time now, e.g. because
+	// they were denied by another limiter (see All).
+	ReturnN(now time.Time, n int)
+}
+
+// All returns a Limiter allowing the events only if all the limiters allow
+// them, e.g. a limit per client and a limit for all of them. The events denied
+// by a limiter are returned to the limiters which allowed them, so that they
+// don't count against their limits.
+func All(limiters ...Limiter) Limiter {
+	return all(limiters)
+}
+
+type all []Limiter
+
+func (a all) AllowN(now time.Time, n int) bool {
+	for i, l := range a {
+		if !l.AllowN(now, n) {
+			for _, allowed := range a[:i] {
+				allowed.ReturnN(now, n)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func (a all) ReturnN(now time.Time, n int) {
+	for _, l := range a {
+		l.ReturnN(now, n)
+	}
+}
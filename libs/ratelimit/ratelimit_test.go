@@ -0,0 +1,176 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var t0 = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTokenBucket(t *testing.T) {
+	tb := NewTokenBucket(10, 5)
+
+	// the bucket is full at first
+	for i := 0; i < 5; i++ {
+		assert.True(t, tb.AllowN(t0, 1), i)
+	}
+	assert.False(t, tb.AllowN(t0, 1))
+
+	// a token every 100ms
+	assert.False(t, tb.AllowN(t0.Add(50*time.Millisecond), 1))
+	assert.True(t, tb.AllowN(t0.Add(100*time.Millisecond), 1))
+	assert.False(t, tb.AllowN(t0.Add(100*time.Millisecond), 1))
+
+	// the bucket doesn't hold more than the burst
+	now := t0.Add(time.Hour)
+	assert.Equal(t, float64(5), tb.Tokens(now))
+	assert.False(t, tb.AllowN(now, 6))
+	assert.True(t, tb.AllowN(now, 5))
+
+	// the tokens can be returned, up to the burst
+	tb.ReturnN(now, 2)
+	assert.Equal(t, float64(2), tb.Tokens(now))
+	tb.ReturnN(now, 10)
+	assert.Equal(t, float64(5), tb.Tokens(now))
+
+	// the times before the last don't refill the bucket
+	assert.True(t, tb.AllowN(now, 5))
+	assert.False(t, tb.AllowN(now.Add(-time.Second), 1))
+}
+
+func TestTokenBucketDefaults(t *testing.T) {
+	// one second of events
+	tb := NewTokenBucket(2.5, 0)
+	assert.Equal(t, float64(3), tb.Tokens(t0))
+
+	tb = NewTokenBucket(0.1, 0)
+	assert.Equal(t, float64(1), tb.Tokens(t0))
+
+	// unlimited
+	tb = NewTokenBucket(0, 0)
+	for i := 0; i < 1000; i++ {
+		require.True(t, tb.AllowN(t0, 1000))
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw := NewSlidingWindow(10, time.Second)
+
+	assert.True(t, sw.AllowN(t0, 8))
+	assert.True(t, sw.AllowN(t0.Add(900*time.Millisecond), 2))
+	assert.False(t, sw.AllowN(t0.Add(900*time.Millisecond), 1))
+
+	// the previous window counts for its part of the sliding window: 10 * 0.5
+	now := t0.Add(1500 * time.Millisecond)
+	assert.InDelta(t, 5, sw.Count(now), 1e-9)
+	assert.True(t, sw.AllowN(now, 5))
+	assert.False(t, sw.AllowN(now, 1))
+
+	// at the end of the current window: 10 * 0.1 + 5
+	now = t0.Add(1900 * time.Millisecond)
+	assert.InDelta(t, 6, sw.Count(now), 1e-9)
+	assert.True(t, sw.AllowN(now, 4))
+	assert.False(t, sw.AllowN(now, 1))
+
+	// the events can be returned
+	sw.ReturnN(now, 4)
+	assert.InDelta(t, 6, sw.Count(now), 1e-9)
+
+	// two windows later, nothing is left
+	now = t0.Add(4 * time.Second)
+	assert.Zero(t, sw.Count(now))
+	assert.True(t, sw.AllowN(now, 10))
+	assert.False(t, sw.AllowN(now, 1))
+
+	// unlimited
+	sw = NewSlidingWindow(0, time.Second)
+	assert.True(t, sw.AllowN(t0, 1000))
+}
+
+func TestAll(t *testing.T) {
+	child := NewTokenBucket(1, 2)
+	parent := NewTokenBucket(1, 3)
+	l := All(child, parent)
+
+	assert.True(t, l.AllowN(t0, 2))
+	// denied by the child
+	assert.False(t, l.AllowN(t0, 1))
+	assert.Equal(t, float64(1), parent.Tokens(t0))
+
+	// denied by the parent: the tokens are returned to the child
+	child.ReturnN(t0, 2)
+	assert.False(t, l.AllowN(t0, 2))
+	assert.Equal(t, float64(2), child.Tokens(t0))
+	assert.True(t, l.AllowN(t0, 1))
+}
+
+func TestKeyed(t *testing.T) {
+	parent := NewTokenBucket(1, 3)
+	k := NewKeyed(func() Limiter { return NewTokenBucket(1, 2) }, parent, 0)
+
+	assert.True(t, k.AllowN("a", t0, 2))
+	assert.False(t, k.AllowN("a", t0, 1))
+
+	// the keys have their own limits, under the shared one
+	assert.True(t, k.AllowN("b", t0, 1))
+	assert.False(t, k.AllowN("b", t0, 1))
+	assert.False(t, k.AllowN("c", t0, 1))
+	assert.Equal(t, 3, k.Len())
+
+	// the tokens denied by the parent are returned to the key
+	now := t0.Add(time.Second)
+	assert.True(t, k.AllowN("b", now, 1))
+	assert.False(t, k.AllowN("c", now, 1))
+	now = now.Add(time.Second)
+	assert.True(t, k.AllowN("c", now, 1))
+
+	k.Remove("a")
+	assert.Equal(t, 2, k.Len())
+}
+
+func TestKeyedIdle(t *testing.T) {
+	k := NewKeyed(func() Limiter { return NewSlidingWindow(1, time.Second) }, nil, 2*time.Second)
+
+	assert.True(t, k.AllowN("a", t0, 1))
+	assert.True(t, k.AllowN("b", t0.Add(time.Second), 1))
+	assert.False(t, k.AllowN("b", t0.Add(time.Second), 1))
+	assert.Equal(t, 2, k.Len())
+
+	// a is forgotten, b isn't idle yet
+	assert.True(t, k.AllowN("c", t0.Add(2*time.Second), 1))
+	assert.Equal(t, 2, k.Len())
+
+	// b is forgotten
+	assert.True(t, k.AllowN("c", t0.Add(4*time.Second), 1))
+	assert.Equal(t, 1, k.Len())
+}
+
+func TestKeyedConcurrent(t *testing.T) {
+	k := NewKeyed(func() Limiter { return NewTokenBucket(1, 10) }, NewTokenBucket(1, 50), 0)
+
+	var (
+		wg      sync.WaitGroup
+		mtx     sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 10; i++ {
+		key := string(rune('a' + i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if k.AllowN(key, t0, 1) {
+					mtx.Lock()
+					allowed++
+					mtx.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, allowed)
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// SlidingWindow limits the events to limit per window, over any window of
+// time: contrary to the fixed windows, twice the limit can't happen around
+// the end of a window.
+//
+// The events of the window are estimated from the counts of the current and
+// the previous fixed windows, the previous events being taken as spread evenly
+// over their window, so that it takes a constant memory.
+type SlidingWindow struct {
+	mtx    tmsync.Mutex
+	limit  int           // events per window (unlimited when <= 0)
+	window time.Duration // duration of the window
+	start  time.Time     // start of the current fixed window
+	curr   int           // events of the current fixed window
+	prev   int           // events of the previous fixed window
+}
+
+var _ Limiter = (*SlidingWindow)(nil)
+
+// NewSlidingWindow returns a SlidingWindow allowing limit events per window.
+// The rate is unlimited if limit or window is 0 or less.
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:  limit,
+		window: window,
+	}
+}
+
+// AllowN implements Limiter.
+func (sw *SlidingWindow) AllowN(now time.Time, n int) bool {
+	if sw.limit <= 0 || sw.window <= 0 {
+		return true
+	}
+
+	sw.mtx.Lock()
+	defer sw.mtx.Unlock()
+
+	if sw.count(now)+float64(n) > float64(sw.limit) {
+		return false
+	}
+	sw.curr += n
+	return true
+}
+
+// ReturnN implements Limiter.
+func (sw *SlidingWindow) ReturnN(now time.Time, n int) {
+	if sw.limit <= 0 || sw.window <= 0 {
+		return
+	}
+
+	sw.mtx.Lock()
+	defer sw.mtx.Unlock()
+
+	sw.advance(now)
+	sw.curr -= n
+	if sw.curr < 0 {
+		sw.curr = 0
+	}
+}
+
+// Count returns the estimated number of events in the window ending at time
+// now.
+func (sw *SlidingWindow) Count(now time.Time) float64 {
+	if sw.limit <= 0 || sw.window <= 0 {
+		return 0
+	}
+
+	sw.mtx.Lock()
+	defer sw.mtx.Unlock()
+
+	return sw.count(now)
+}
+
+func (sw *SlidingWindow) count(now time.Time) float64 {
+	sw.advance(now)
+	elapsed := now.Sub(sw.start)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	weight := 1 - float64(elapsed)/float64(sw.window)
+	return float64(sw.prev)*weight + float64(sw.curr)
+}
+
+// advance moves the current fixed window to the one of now. The times before
+// the current window (e.g. the times of concurrent events, taken out of order)
+// are counted in the current window.
+func (sw *SlidingWindow) advance(now time.Time) {
+	if sw.start.IsZero() {
+		sw.start = now
+		return
+	}
+	elapsed := now.Sub(sw.start)
+	if elapsed < sw.window {
+		return
+	}
+	windows := elapsed / sw.window
+	if windows == 1 {
+		sw.prev = sw.curr
+	} else {
+		sw.prev = 0
+	}
+	sw.curr = 0
+	sw.start = sw.start.Add(windows * sw.window)
+}
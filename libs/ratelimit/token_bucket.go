@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// TokenBucket limits the events to a rate, while allowing bursts: the bucket
+// holds up to burst tokens, refilled at rate tokens per second, and each event
+// takes a token.
+type TokenBucket struct {
+	mtx    tmsync.Mutex
+	rate   float64 // tokens per second (unlimited when <= 0)
+	burst  float64 // capacity of the bucket
+	tokens float64 // tokens in the bucket at last
+	last   time.Time
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// NewTokenBucket returns a full TokenBucket allowing rate events per second,
+// and up to burst events at once. burst is one second of events if it is less
+// than 1, and the rate is unlimited if it is 0 or less.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := float64(burst)
+	if burst < 1 {
+		b = math.Max(1, math.Ceil(rate))
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+	}
+}
+
+// AllowN implements Limiter.
+func (tb *TokenBucket) AllowN(now time.Time, n int) bool {
+	if tb.rate <= 0 {
+		return true
+	}
+
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.refill(now)
+	if tb.tokens < float64(n) {
+		return false
+	}
+	tb.tokens -= float64(n)
+	return true
+}
+
+// ReturnN implements Limiter.
+func (tb *TokenBucket) ReturnN(now time.Time, n int) {
+	if tb.rate <= 0 {
+		return
+	}
+
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.refill(now)
+	tb.tokens = math.Min(tb.burst, tb.tokens+float64(n))
+}
+
+// Tokens returns the number of tokens in the bucket at time now.
+func (tb *TokenBucket) Tokens(now time.Time) float64 {
+	if tb.rate <= 0 {
+		return math.Inf(1)
+	}
+
+	tb.mtx.Lock()
+	defer tb.mtx.Unlock()
+
+	tb.refill(now)
+	return tb.tokens
+}
+
+// refill adds the tokens since last. The times before last (e.g. the times
+// of concurrent events, taken out of order) don't add any token.
+func (tb *TokenBucket) refill(now time.Time) {
+	if tb.last.IsZero() {
+		tb.last = now
+		return
+	}
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens = math.Min(tb.burst, tb.tokens+elapsed.Seconds()*tb.rate)
+		tb.last = now
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RestartPolicy tells a Supervisor how to restart a unit which failed while
+// running.
+type RestartPolicy struct {
+	// MaxRestarts is the maximum number of times the unit is restarted, zero
+	// not to restart it.
+	MaxRestarts int
+	// Backoff is the delay before the first restart, doubled at each
+	// following restart up to MaxBackoff (if not zero).
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Delay returns the delay before the given restart (starting from 0).
+func (p RestartPolicy) Delay(restart int) time.Duration {
+	d := p.Backoff
+	for i := 0; i < restart; i++ {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// Unit is a part of a program, e.g. a Service, started and stopped by a
+// Supervisor in the order of the dependencies between the units.
+type Unit struct {
+	// Name identifies the unit, e.g. in the DependsOn of the other units.
+	Name string
+	// DependsOn are the names of the units which must be started before this
+	// one, and stopped after it.
+	DependsOn []string
+
+	// Start starts the unit. It can be nil if the unit is started elsewhere,
+	// e.g. when it is built, and only has to be stopped by the supervisor.
+	Start func() error
+	// Stop stops the unit. It can be nil if the unit doesn't have to be
+	// stopped.
+	Stop func()
+	// StopTimeout is how long the supervisor waits for Stop to return, or
+	// zero to wait until it does. If it times out, the units this one depends
+	// on aren't stopped, not to be stopped under the unit, which may still be
+	// using them.
+	StopTimeout time.Duration
+
+	// Failed, if not nil, returns a channel which is closed if the running
+	// unit fails (it is called each time the unit is started). The failed unit
+	// is then stopped and started again according to Restart, if its
+	// MaxRestarts isn't zero.
+	Failed  func() <-chan struct{}
+	Restart RestartPolicy
+}
+
+// ServiceUnit returns a unit starting and stopping the service. The service is
+// considered as failed if it quits while the supervisor is running, in which
+// case it is reset before being started again, so a restart policy must only
+// be given to the services implementing OnReset.
+func ServiceUnit(name string, svc Service, restart RestartPolicy, dependsOn ...string) Unit {
+	return Unit{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func() error {
+			if !svc.IsRunning() {
+				select {
+				case <-svc.Quit():
+					// the service was stopped
+					if err := svc.Reset(); err != nil {
+						return err
+					}
+				default:
+				}
+			}
+			return svc.Start()
+		},
+		Stop: func() {
+			svc.Stop() //nolint:errcheck // the errors are logged by the service
+		},
+		Failed:  svc.Quit,
+		Restart: restart,
+	}
+}
+
+type unitState struct {
+	Unit
+	restarts int
+	running  bool
+}
+
+// Supervisor is a Service starting the units added to it in the order of their
+// dependencies, restarting them with backoff if they fail according to their
+// restart policy, and stopping them in the reverse order.
+type Supervisor struct {
+	BaseService
+
+	mtx     sync.Mutex
+	units   []*unitState          // in the order they were added
+	byName  map[string]*unitState // units by name
+	started []*unitState          // in the order they were started
+
+	stopping chan struct{}
+	watchers sync.WaitGroup
+}
+
+// NewSupervisor returns a new supervisor, without units.
+func NewSupervisor() *Supervisor {
+	s := &Supervisor{
+		byName:   make(map[string]*unitState),
+		stopping: make(chan struct{}),
+	}
+	s.BaseService = *NewBaseService(nil, "Supervisor", s)
+	return s
+}
+
+// Add adds the unit to the supervisor. It must be called before starting the
+// supervisor. The units the unit depends on can be added after it.
+func (s *Supervisor) Add(unit Unit) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if unit.Name == "" {
+		return errors.New("unit without name")
+	}
+	if _, ok := s.byName[unit.Name]; ok {
+		return fmt.Errorf("unit %s already added", unit.Name)
+	}
+	u := &unitState{Unit: unit}
+	s.units = append(s.units, u)
+	s.byName[unit.Name] = u
+	return nil
+}
+
+// Order returns the names of the units in the order they are started: each
+// unit after the units it depends on, and otherwise in the order they were
+// added. It returns an error if a unit depends on an unknown unit, or if the
+// dependencies are cyclic.
+func (s *Supervisor) Order() ([]string, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	units, err := s.order()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(units))
+	for i, u := range units {
+		names[i] = u.Name
+	}
+	return names, nil
+}
+
+func (s *Supervisor) order() ([]*unitState, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(s.units))
+	ordered := make([]*unitState, 0, len(s.units))
+	var visit func(u *unitState, path []string) error
+	visit = func(u *unitState, path []string) error {
+		switch marks[u.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("cyclic dependencies between units: %v", append(path, u.Name))
+		}
+		marks[u.Name] = visiting
+		for _, name := range u.DependsOn {
+			dep, ok := s.byName[name]
+			if !ok {
+				return fmt.Errorf("unit %s depends on unknown unit %s", u.Name, name)
+			}
+			if err := visit(dep, append(path, u.Name)); err != nil {
+				return err
+			}
+		}
+		marks[u.Name] = visited
+		ordered = append(ordered, u)
+		return nil
+	}
+	for _, u := range s.units {
+		if err := visit(u, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Restarts returns the number of times the unit was restarted after failing.
+func (s *Supervisor) Restarts(name string) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if u, ok := s.byName[name]; ok {
+		return u.restarts
+	}
+	return 0
+}
+
+// OnStart implements Service by starting the units in the order of their
+// dependencies. If a unit fails to start, the units already started are
+// stopped and the error is returned.
+func (s *Supervisor) OnStart() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	units, err := s.order()
+	if err != nil {
+		return err
+	}
+	for _, u := range units {
+		s.Logger.Debug("Starting unit", "unit", u.Name)
+		if err := s.startUnit(u); err != nil {
+			s.stopUnits()
+			return fmt.Errorf("failed to start %s: %w", u.Name, err)
+		}
+		s.started = append(s.started, u)
+	}
+	for _, u := range s.started {
+		if u.Failed != nil && u.Restart.MaxRestarts > 0 {
+			s.watchers.Add(1)
+			go s.watch(u, u.Failed())
+		}
+	}
+	return nil
+}
+
+func (s *Supervisor) startUnit(u *unitState) error {
+	if u.Start != nil {
+		if err := u.Start(); err != nil {
+			return err
+		}
+	}
+	u.running = true
+	return nil
+}
+
+// watch restarts the unit when it fails, until the supervisor is stopped.
+func (s *Supervisor) watch(u *unitState, failed <-chan struct{}) {
+	defer s.watchers.Done()
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-failed:
+		}
+
+		s.mtx.Lock()
+		select {
+		case <-s.stopping:
+			// the unit is stopped by the supervisor
+			s.mtx.Unlock()
+			return
+		default:
+		}
+		restarts := u.restarts
+		if restarts >= u.Restart.MaxRestarts {
+			s.Logger.Error("Unit failed", "unit", u.Name, "restarts", restarts)
+			s.mtx.Unlock()
+			return
+		}
+		backoff := u.Restart.Delay(restarts)
+		u.restarts++
+		s.mtx.Unlock()
+
+		s.Logger.Error("Unit failed, restarting it", "unit", u.Name, "backoff", backoff, "restart", restarts+1)
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(backoff):
+		}
+
+		s.mtx.Lock()
+		select {
+		case <-s.stopping:
+			s.mtx.Unlock()
+			return
+		default:
+		}
+		if u.Stop != nil && u.running {
+			// clean up what is left of the failed unit
+			u.Stop()
+		}
+		u.running = false
+		err := s.startUnit(u)
+		if err == nil {
+			failed = u.Failed()
+		} else {
+			s.Logger.Error("Failed to restart unit", "unit", u.Name, "err", err)
+			// try again after the next backoff
+			closed := make(chan struct{})
+			close(closed)
+			failed = closed
+		}
+		s.mtx.Unlock()
+	}
+}
+
+// OnStop implements Service by stopping the started units in the reverse
+// order they were started.
+func (s *Supervisor) OnStop() {
+	// no unit is restarted anymore
+	close(s.stopping)
+	s.watchers.Wait()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.stopUnits()
+}
+
+// stopUnits stops the started units in the reverse order they were started,
+// except the units on which a unit whose Stop timed out depends.
+func (s *Supervisor) stopUnits() {
+	stuck := make(map[string]bool)
+	for i := len(s.started) - 1; i >= 0; i-- {
+		u := s.started[i]
+		if stuck[u.Name] {
+			s.Logger.Error("Not stopping unit, since a unit depending on it failed to stop", "unit", u.Name)
+			s.markStuck(u, stuck)
+			continue
+		}
+		if !u.running || u.Stop == nil {
+			u.running = false
+			continue
+		}
+		if !s.stopUnit(u) {
+			s.markStuck(u, stuck)
+		}
+		u.running = false
+	}
+	s.started = nil
+}
+
+// markStuck marks the dependencies of the unit as not to be stopped.
+func (s *Supervisor) markStuck(u *unitState, stuck map[string]bool) {
+	for _, name := range u.DependsOn {
+		stuck[name] = true
+	}
+}
+
+// stopUnit stops the unit, waiting at most its StopTimeout. It returns false if
+// it timed out.
+func (s *Supervisor) stopUnit(u *unitState) bool {
+	s.Logger.Info("Stopping unit", "unit", u.Name)
+	start := time.Now()
+	if u.StopTimeout == 0 {
+		u.Stop()
+		s.Logger.Info("Stopped unit", "unit", u.Name, "took", time.Since(start))
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		u.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.Logger.Info("Stopped unit", "unit", u.Name, "took", time.Since(start))
+		return true
+	case <-time.After(u.StopTimeout):
+		s.Logger.Error("Timed out stopping unit", "unit", u.Name, "timeout", u.StopTimeout)
+		return false
+	}
+}
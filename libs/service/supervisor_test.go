@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUnits records the order the units are started and stopped.
+type testUnits struct {
+	mtx    sync.Mutex
+	events []string
+}
+
+func (tu *testUnits) record(event string) {
+	tu.mtx.Lock()
+	defer tu.mtx.Unlock()
+	tu.events = append(tu.events, event)
+}
+
+func (tu *testUnits) recorded() []string {
+	tu.mtx.Lock()
+	defer tu.mtx.Unlock()
+	return append([]string(nil), tu.events...)
+}
+
+func (tu *testUnits) unit(name string, dependsOn ...string) Unit {
+	return Unit{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func() error {
+			tu.record("start " + name)
+			return nil
+		},
+		Stop: func() {
+			tu.record("stop " + name)
+		},
+	}
+}
+
+func TestSupervisorOrder(t *testing.T) {
+	tu := &testUnits{}
+	s := NewSupervisor()
+	for _, unit := range []Unit{
+		tu.unit("rpc", "switch"),
+		tu.unit("switch", "reactors"),
+		tu.unit("reactors", "stores"),
+		tu.unit("stores"),
+		tu.unit("metrics"),
+	} {
+		require.NoError(t, s.Add(unit))
+	}
+	assert.Error(t, s.Add(tu.unit("stores")))
+	assert.Error(t, s.Add(tu.unit("")))
+
+	order, err := s.Order()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stores", "reactors", "switch", "rpc", "metrics"}, order)
+
+	require.NoError(t, s.Start())
+	require.NoError(t, s.Stop())
+	assert.Equal(t, []string{
+		"start stores", "start reactors", "start switch", "start rpc", "start metrics",
+		"stop metrics", "stop rpc", "stop switch", "stop reactors", "stop stores",
+	}, tu.recorded())
+}
+
+func TestSupervisorBadDependencies(t *testing.T) {
+	tu := &testUnits{}
+	s := NewSupervisor()
+	require.NoError(t, s.Add(tu.unit("a", "b")))
+	require.NoError(t, s.Add(tu.unit("b", "c")))
+	_, err := s.Order()
+	assert.Error(t, err)
+	assert.Error(t, s.Start())
+
+	s = NewSupervisor()
+	require.NoError(t, s.Add(tu.unit("a", "b")))
+	require.NoError(t, s.Add(tu.unit("b", "c")))
+	require.NoError(t, s.Add(tu.unit("c", "a")))
+	_, err = s.Order()
+	assert.Error(t, err)
+	assert.Error(t, s.Start())
+	assert.Empty(t, tu.recorded())
+}
+
+func TestSupervisorStartFailure(t *testing.T) {
+	tu := &testUnits{}
+	s := NewSupervisor()
+	require.NoError(t, s.Add(tu.unit("a")))
+	require.NoError(t, s.Add(tu.unit("b", "a")))
+	require.NoError(t, s.Add(Unit{
+		Name:      "c",
+		DependsOn: []string{"b"},
+		Start: func() error {
+			return errors.New("test")
+		},
+		Stop: func() {
+			tu.record("stop c")
+		},
+	}))
+
+	// the units already started are stopped
+	assert.Error(t, s.Start())
+	assert.Equal(t, []string{"start a", "start b", "stop b", "stop a"}, tu.recorded())
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		failed chan struct{}
+		starts int
+		stops  int
+	)
+	s := NewSupervisor()
+	require.NoError(t, s.Add(Unit{
+		Name: "a",
+		Start: func() error {
+			mtx.Lock()
+			defer mtx.Unlock()
+			starts++
+			failed = make(chan struct{})
+			return nil
+		},
+		Stop: func() {
+			mtx.Lock()
+			defer mtx.Unlock()
+			stops++
+		},
+		Failed: func() <-chan struct{} {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return failed
+		},
+		Restart: RestartPolicy{MaxRestarts: 2, Backoff: 10 * time.Millisecond, MaxBackoff: 15 * time.Millisecond},
+	}))
+	fail := func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+		close(failed)
+	}
+	started := func() int {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return starts
+	}
+
+	require.NoError(t, s.Start())
+	assert.Equal(t, 1, started())
+
+	// the failed unit is stopped and started again
+	fail()
+	require.Eventually(t, func() bool { return started() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, s.Restarts("a"))
+	fail()
+	require.Eventually(t, func() bool { return started() == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, 2, s.Restarts("a"))
+
+	// until its MaxRestarts
+	fail()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 3, started())
+	assert.Equal(t, 2, s.Restarts("a"))
+
+	require.NoError(t, s.Stop())
+	mtx.Lock()
+	defer mtx.Unlock()
+	assert.Equal(t, 3, stops)
+}
+
+func TestRestartPolicyBackoff(t *testing.T) {
+	p := RestartPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.Delay(0))
+	assert.Equal(t, 2*time.Second, p.Delay(1))
+	assert.Equal(t, 4*time.Second, p.Delay(2))
+	assert.Equal(t, 5*time.Second, p.Delay(3))
+	assert.Equal(t, 5*time.Second, p.Delay(10))
+
+	p.MaxBackoff = 0
+	assert.Equal(t, 8*time.Second, p.Delay(3))
+}
+
+func TestSupervisorStopTimeout(t *testing.T) {
+	tu := &testUnits{}
+	s := NewSupervisor()
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, s.Add(tu.unit("stores")))
+	require.NoError(t, s.Add(tu.unit("other")))
+	require.NoError(t, s.Add(Unit{
+		Name:      "consensus",
+		DependsOn: []string{"stores"},
+		Stop: func() {
+			<-release
+		},
+		StopTimeout: 10 * time.Millisecond,
+	}))
+	require.NoError(t, s.Add(tu.unit("rpc", "consensus")))
+
+	require.NoError(t, s.Start())
+	require.NoError(t, s.Stop())
+	// the stores aren't closed under the stuck unit
+	assert.Equal(t, []string{
+		"start stores", "start other", "start rpc",
+		"stop rpc", "stop other",
+	}, tu.recorded())
+}
+
+func TestServiceUnit(t *testing.T) {
+	ts := &testService{}
+	ts.BaseService = *NewBaseService(nil, "TestService", ts)
+	s := NewSupervisor()
+	require.NoError(t, s.Add(ServiceUnit("test", ts, RestartPolicy{MaxRestarts: 1, Backoff: time.Millisecond})))
+	require.NoError(t, s.Start())
+	assert.True(t, ts.IsRunning())
+
+	// the stopped service is reset and started again
+	require.NoError(t, ts.Stop())
+	require.Eventually(t, func() bool { return ts.IsRunning() }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, s.Restarts("test"))
+
+	require.NoError(t, s.Stop())
+	assert.False(t, ts.IsRunning())
+}
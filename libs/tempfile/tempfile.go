@@ -72,27 +72,73 @@ func randWriteFileSuffix() string {
 }
 
 // WriteFileAtomic creates a temporary file with data and provided perm and
-// swaps it atomically with filename if successful.
-func WriteFileAtomic(filename string, data []byte, perm os.FileMode) (err error) {
+// swaps it atomically with filename if successful, see WriteFileAtomicFunc.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicFunc(filename, perm, func(w io.Writer) error {
+		if n, err := w.Write(data); err != nil {
+			return err
+		} else if n < len(data) {
+			return io.ErrShortWrite
+		}
+		return nil
+	})
+}
+
+// WriteFileAtomicFunc creates a temporary file with provided perm, in the
+// directory of filename, writes it with write and swaps it atomically with
+// filename if successful.
+//
+// The temporary file is synced to the disk before it is renamed, and the
+// directory after it is (except on Windows, where the directories can't be
+// synced), so that filename has either its previous or its new content, fully
+// written, even after a crash or a power loss.
+func WriteFileAtomicFunc(filename string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(filename)
+	f, err := createWriteFile(dir, perm)
+	if err != nil {
+		return err
+	}
+	// Clean up, unless the file was renamed.
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err = write(f); err != nil {
+		return err
+	}
+	// O_SYNC isn't honoured by all the file systems
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	// Close the file before renaming it, otherwise it will cause "The process
+	// cannot access the file because it is being used by another process." on windows.
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = renameFile(f.Name(), filename); err != nil {
+		return err
+	}
+	return SyncDir(dir)
+}
+
+// createWriteFile creates a new temporary file in dir.
+func createWriteFile(dir string, perm os.FileMode) (*os.File, error) {
 	// This implementation is inspired by the golang stdlibs method of creating
 	// tempfiles. Notable differences are that we use different flags, a 64 bit LCG
 	// and handle negatives differently.
 	// The core reason we can't use golang's TempFile is that we must write
 	// to the file synchronously, as we need this to persist to disk.
 	// We also open it in write-only mode, to avoid concerns that arise with read.
-	var (
-		dir = filepath.Dir(filename)
-		f   *os.File
-	)
-
 	nconflict := 0
 	// Limit the number of attempts to create a file. Something is seriously
 	// wrong if it didn't get created after 1000 attempts, and we don't want
 	// an infinite loop
-	i := 0
-	for ; i < atomicWriteFileMaxNumWriteAttempts; i++ {
+	for i := 0; i < atomicWriteFileMaxNumWriteAttempts; i++ {
 		name := filepath.Join(dir, atomicWriteFilePrefix+randWriteFileSuffix())
-		f, err = os.OpenFile(name, atomicWriteFileFlag, perm)
+		f, err := os.OpenFile(name, atomicWriteFileFlag, perm)
 		// If the file already exists, try a new file
 		if os.IsExist(err) {
 			// If the files exists too many times, start reseeding as we've
@@ -104,26 +150,9 @@ func WriteFileAtomic(filename string, data []byte, perm os.FileMode) (err error)
 			}
 			continue
 		} else if err != nil {
-			return err
+			return nil, err
 		}
-		break
-	}
-	if i == atomicWriteFileMaxNumWriteAttempts {
-		return fmt.Errorf("could not create atomic write file after %d attempts", i)
+		return f, nil
 	}
-
-	// Clean up in any case. Defer stacking order is last-in-first-out.
-	defer os.Remove(f.Name())
-	defer f.Close()
-
-	if n, err := f.Write(data); err != nil {
-		return err
-	} else if n < len(data) {
-		return io.ErrShortWrite
-	}
-	// Close the file before renaming it, otherwise it will cause "The process
-	// cannot access the file because it is being used by another process." on windows.
-	f.Close()
-
-	return os.Rename(f.Name(), filename)
+	return nil, fmt.Errorf("could not create atomic write file after %d attempts", atomicWriteFileMaxNumWriteAttempts)
 }
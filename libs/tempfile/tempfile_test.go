@@ -4,8 +4,11 @@ package tempfile
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	testing "testing"
 
 	"github.com/stretchr/testify/require"
@@ -141,3 +144,41 @@ func TestWriteFileAtomicManyDuplicates(t *testing.T) {
 	require.Nil(t, err, "Error reading resultant file")
 	require.Equal(t, []byte(expectedString), resultantFileBytes, "Written file had incorrect bytes")
 }
+
+// This tests that a failed write leaves the file and the directory untouched.
+func TestWriteFileAtomicFuncError(t *testing.T) {
+	dir := t.TempDir()
+	fileToWrite := filepath.Join(dir, "file.txt")
+	require.NoError(t, WriteFileAtomic(fileToWrite, []byte("old"), 0o600))
+
+	errWrite := errors.New("write error")
+	err := WriteFileAtomicFunc(fileToWrite, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("partial"))
+		require.NoError(t, err)
+		return errWrite
+	})
+	require.ErrorIs(t, err, errWrite)
+
+	data, err := os.ReadFile(fileToWrite)
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), data)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the temporary file wasn't removed")
+
+	err = WriteFileAtomicFunc(fileToWrite, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	})
+	require.NoError(t, err)
+	data, err = os.ReadFile(fileToWrite)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), data)
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestSyncDir(t *testing.T) {
+	require.NoError(t, SyncDir(t.TempDir()))
+}
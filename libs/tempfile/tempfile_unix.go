@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package tempfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// renameFile renames oldpath to newpath, replacing it atomically.
+func renameFile(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// SyncDir syncs the directory to the disk, so that the files created, renamed
+// or removed in it persist after a crash. The file systems which can't sync
+// the directories are ignored.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return err
+	}
+	return d.Close()
+}
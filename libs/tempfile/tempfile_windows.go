@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package tempfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+const (
+	// ERROR_ACCESS_DENIED and ERROR_SHARING_VIOLATION, when another process
+	// (e.g. a virus scanner or an indexer) has the file open.
+	errorAccessDenied     syscall.Errno = 5
+	errorSharingViolation syscall.Errno = 32
+
+	renameAttempts = 10
+	renameBackoff  = 10 * time.Millisecond
+)
+
+// renameFile renames oldpath to newpath, replacing it (os.Rename uses
+// MoveFileEx with MOVEFILE_REPLACE_EXISTING). The rename is retried while the
+// file is open by another process.
+func renameFile(oldpath, newpath string) (err error) {
+	backoff := renameBackoff
+	for i := 0; i < renameAttempts; i++ {
+		err = os.Rename(oldpath, newpath)
+		if !errors.Is(err, errorAccessDenied) && !errors.Is(err, errorSharingViolation) {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return err
+}
+
+// SyncDir does nothing on Windows, where the directories can't be synced (the
+// renames are journaled by NTFS).
+func SyncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// messageSampleThreshold is the threshold of the sampled message traces, 0 if
+// the messages aren't traced (see SetMessageSampleRate).
+var messageSampleThreshold uint64 // atomic
+
+// SetMessageSampleRate sets the fraction of the messages of the peers which
+// are traced, between 0 and 1: they aren't traced if 0.
+func SetMessageSampleRate(rate float64) {
+	var threshold uint64
+	if rate >= 1 {
+		threshold = math.MaxUint64
+	} else if rate > 0 {
+		threshold = uint64(rate * math.MaxUint64)
+	}
+	atomic.StoreUint64(&messageSampleThreshold, threshold)
+}
+
+// MessagesTraced returns true if the messages of the peers are traced, so that
+// the callers can skip computing MessageContext otherwise.
+func MessagesTraced() bool {
+	return atomic.LoadUint64(&messageSampleThreshold) > 0
+}
+
+// MessageContext returns the context of the spans of a message (its bytes) of
+// the channel chID. As the messages carry no trace context, the context is
+// derived from the hash of the message, so that the spans of the message on
+// the node sending it and on the ones receiving it are in the same trace, e.g.
+// the sends of a proposal to the peers and their receipts. The message is
+// sampled by its hash too: the returned context carries no span if the message
+// isn't sampled, or the messages aren't traced.
+func MessageContext(chID byte, msgBytes []byte) context.Context {
+	threshold := atomic.LoadUint64(&messageSampleThreshold)
+	if threshold == 0 {
+		return context.Background()
+	}
+	h := sha256.New()
+	h.Write([]byte{chID})
+	h.Write(msgBytes)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	if threshold < math.MaxUint64 && binary.BigEndian.Uint64(sum[:8]) >= threshold {
+		return context.Background()
+	}
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	copy(traceID[:], sum[:16])
+	copy(spanID[:], sum[16:24])
+	// the span of the message itself is never exported: its spans on the nodes
+	// are its children
+	return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+}
+
+// StartMessageSpan starts a span of a message, as a child of the span of ctx
+// returned by MessageContext. The returned span does nothing if the message
+// isn't traced.
+func StartMessageSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return Tracer().Start(ctx, name, opts...)
+}
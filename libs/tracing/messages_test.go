@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMessageContext(t *testing.T) {
+	recorder := setupRecorder(t, sdktrace.ParentBased(sdktrace.NeverSample()))
+	t.Cleanup(func() { SetMessageSampleRate(0) })
+
+	// the messages aren't traced by default
+	assert.False(t, MessagesTraced())
+	assert.False(t, trace.SpanContextFromContext(MessageContext(0x20, []byte("proposal"))).IsValid())
+
+	// the contexts of a message are the same on all the nodes
+	SetMessageSampleRate(1)
+	require.True(t, MessagesTraced())
+	sc := trace.SpanContextFromContext(MessageContext(0x20, []byte("proposal")))
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, sc, trace.SpanContextFromContext(MessageContext(0x20, []byte("proposal"))))
+	assert.NotEqual(t, sc.TraceID(), trace.SpanContextFromContext(MessageContext(0x21, []byte("proposal"))).TraceID())
+
+	_, send := StartMessageSpan(MessageContext(0x20, []byte("proposal")), "p2p.send")
+	send.End()
+	_, receive := StartMessageSpan(MessageContext(0x20, []byte("proposal")), "p2p.receive")
+	receive.End()
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.Equal(t, sc.TraceID(), span.SpanContext().TraceID(), span.Name())
+		assert.Equal(t, sc.SpanID(), span.Parent().SpanID(), span.Name())
+	}
+
+	// the spans of the messages not traced do nothing
+	_, span := StartMessageSpan(context.Background(), "p2p.send")
+	assert.False(t, span.IsRecording())
+
+	// the messages are sampled by their hash
+	SetMessageSampleRate(0.5)
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if trace.SpanContextFromContext(MessageContext(0x22, []byte{byte(i), byte(i >> 8)})).IsSampled() {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
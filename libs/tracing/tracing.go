@@ -0,0 +1,136 @@
+// Package tracing traces the transactions submitted to the node with
+// OpenTelemetry: the span of the request submitting a transaction (e.g.
+// broadcast_tx) is tracked by the key of the transaction, so that the spans of
+// its journey through the node (CheckTx, waiting in the mempool, DeliverTx) are
+// its children, giving the latency breakdown of the transaction.
+//
+// The spans are created with the global tracer provider, which discards them
+// unless it is set up (see the tracing settings of the instrumentation
+// configuration).
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// InstrumentationName is the name of the tracer of Ostracon.
+	InstrumentationName = "github.com/Finschia/ostracon"
+
+	// maxTrackedTxs is the maximum number of transactions tracked at once, the
+	// oldest ones are forgotten first.
+	maxTrackedTxs = 10000
+)
+
+// Tracer returns the tracer of Ostracon.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// TxKey is the key of a transaction (see types.Tx.Key).
+type TxKey = [32]byte
+
+type trackedTx struct {
+	spanContext trace.SpanContext
+	// waitSpan is the span of the current wait of the transaction, if any
+	waitSpan trace.Span
+}
+
+var (
+	mtx        sync.Mutex
+	trackedTxs = make(map[TxKey]*trackedTx)
+	// order is the keys of the tracked transactions, oldest first
+	order []TxKey
+)
+
+// TrackTx tracks the transaction with the span of ctx, if it is sampled, so
+// that the spans started by StartTxSpan and StartTxWait are its children.
+func TrackTx(ctx context.Context, key TxKey) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsSampled() {
+		return
+	}
+	mtx.Lock()
+	defer mtx.Unlock()
+	if _, ok := trackedTxs[key]; ok {
+		return
+	}
+	if len(order) >= maxTrackedTxs {
+		forget(order[0])
+	}
+	trackedTxs[key] = &trackedTx{spanContext: sc}
+	order = append(order, key)
+}
+
+// UntrackTx ends the current wait of the transaction and stops tracking it,
+// e.g. once it is committed or removed from the mempool.
+func UntrackTx(key TxKey, attrs ...attribute.KeyValue) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	if tx, ok := trackedTxs[key]; ok && tx.waitSpan != nil {
+		tx.waitSpan.SetAttributes(attrs...)
+	}
+	forget(key)
+}
+
+// IsTracked returns true if the transaction is tracked.
+func IsTracked(key TxKey) bool {
+	mtx.Lock()
+	defer mtx.Unlock()
+	_, ok := trackedTxs[key]
+	return ok
+}
+
+// StartTxSpan starts a span of the journey of the transaction, as a child of
+// the span it is tracked with. The returned span does nothing if the
+// transaction isn't tracked.
+func StartTxSpan(key TxKey, name string, opts ...trace.SpanStartOption) trace.Span {
+	mtx.Lock()
+	tx, ok := trackedTxs[key]
+	mtx.Unlock()
+	if !ok {
+		return trace.SpanFromContext(context.Background())
+	}
+	_, span := Tracer().Start(trace.ContextWithSpanContext(context.Background(), tx.spanContext), name, opts...)
+	return span
+}
+
+// StartTxWait starts a span of the transaction waiting for the next stage of
+// its journey, e.g. in the mempool, which is ended by the next call to
+// StartTxWait or UntrackTx.
+func StartTxWait(key TxKey, name string, opts ...trace.SpanStartOption) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	tx, ok := trackedTxs[key]
+	if !ok {
+		return
+	}
+	if tx.waitSpan != nil {
+		tx.waitSpan.End()
+	}
+	_, tx.waitSpan = Tracer().Start(trace.ContextWithSpanContext(context.Background(), tx.spanContext), name, opts...)
+}
+
+// forget ends the current wait of the transaction and stops tracking it. It
+// must be called with mtx held.
+func forget(key TxKey) {
+	tx, ok := trackedTxs[key]
+	if !ok {
+		return
+	}
+	if tx.waitSpan != nil {
+		tx.waitSpan.End()
+	}
+	delete(trackedTxs, key)
+	for i, k := range order {
+		if k == key {
+			order = append(order[:i], order[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T, sampler sdktrace.Sampler) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		mtx.Lock()
+		trackedTxs = make(map[TxKey]*trackedTx)
+		order = nil
+		mtx.Unlock()
+	})
+	return recorder
+}
+
+func TestTrackTx(t *testing.T) {
+	recorder := setupRecorder(t, sdktrace.AlwaysSample())
+	key := TxKey{1}
+
+	ctx, root := Tracer().Start(context.Background(), "broadcast_tx")
+	TrackTx(ctx, key)
+	root.End()
+	require.True(t, IsTracked(key))
+
+	checkTx := StartTxSpan(key, "check_tx")
+	checkTx.End()
+	StartTxWait(key, "mempool")
+	StartTxWait(key, "commit")
+	UntrackTx(key, attribute.Int64("height", 2))
+	assert.False(t, IsTracked(key))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	names := make([]string, 0, len(spans))
+	for _, span := range spans[1:] {
+		names = append(names, span.Name())
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+		assert.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID(), span.Name())
+	}
+	assert.Equal(t, []string{"check_tx", "mempool", "commit"}, names)
+	assert.Contains(t, spans[3].Attributes(), attribute.Int64("height", 2))
+
+	// the spans of an untracked transaction aren't recorded
+	span := StartTxSpan(key, "deliver_tx")
+	assert.False(t, span.IsRecording())
+	span.End()
+	assert.Len(t, recorder.Ended(), 4)
+}
+
+func TestTrackTxNotSampled(t *testing.T) {
+	setupRecorder(t, sdktrace.NeverSample())
+	key := TxKey{1}
+
+	ctx, root := Tracer().Start(context.Background(), "broadcast_tx")
+	defer root.End()
+	TrackTx(ctx, key)
+	assert.False(t, IsTracked(key))
+}
+
+func TestTrackTxMax(t *testing.T) {
+	recorder := setupRecorder(t, sdktrace.AlwaysSample())
+
+	ctx, root := Tracer().Start(context.Background(), "broadcast_tx")
+	defer root.End()
+	for i := 0; i <= maxTrackedTxs; i++ {
+		key := TxKey{byte(i), byte(i >> 8)}
+		TrackTx(ctx, key)
+		StartTxWait(key, "mempool")
+	}
+
+	// the oldest transaction is forgotten, and its wait ended
+	assert.False(t, IsTracked(TxKey{0, 0}))
+	assert.True(t, IsTracked(TxKey{1, 0}))
+	assert.Len(t, recorder.Ended(), 1)
+	mtx.Lock()
+	defer mtx.Unlock()
+	assert.Len(t, trackedTxs, maxTrackedTxs)
+	assert.Len(t, order, maxTrackedTxs)
+}
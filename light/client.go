@@ -5,12 +5,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/Finschia/ostracon/libs/log"
 	tmmath "github.com/Finschia/ostracon/libs/math"
+	"github.com/Finschia/ostracon/libs/service"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	"github.com/Finschia/ostracon/light/provider"
 	"github.com/Finschia/ostracon/light/store"
@@ -69,6 +71,30 @@ func SkippingVerification(trustLevel tmmath.Fraction) Option {
 	}
 }
 
+// SequentialFallback option configures the light client to fall back to
+// sequential verification when skipping verification can't make progress
+// because too much of the validator set has changed (>1-trustLevel of voting
+// power). The fallback is only attempted if the gap between the trusted and
+// the new light block is no bigger than maxHeaders. The interim light blocks
+// the primary can't provide are fetched from the witnesses, the primary staying
+// the primary. A maxHeaders of 0 disables the fallback (default).
+func SequentialFallback(maxHeaders uint64) Option {
+	return func(c *Client) {
+		c.maxSequentialFallbackHeaders = maxHeaders
+	}
+}
+
+// EntropyVerification option configures the light client to verify the
+// Ostracon-specific entropy (VRF proof and round) of every new light block
+// against the expected proposer selection. The primary must implement
+// provider.EntropyProvider. The entropy of the initial block can't be
+// verified since it depends on the genesis hash.
+func EntropyVerification() Option {
+	return func(c *Client) {
+		c.verifyEntropy = true
+	}
+}
+
 // PruningSize option sets the maximum amount of light blocks that the light
 // client stores. When Prune() is run, all light blocks that are earlier than
 // the h amount of light blocks will be removed from the store.
@@ -125,6 +151,47 @@ func MaxBlockLag(d time.Duration) Option {
 	}
 }
 
+// ClientMetrics option sets the metrics of the light client. Default: no-op
+// metrics.
+func ClientMetrics(m *Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// Events option sets a handler that is notified when the trusted header is
+// updated or an attack is detected. Default: NopEventHandler.
+func Events(h EventHandler) Option {
+	return func(c *Client) {
+		c.events = h
+	}
+}
+
+// TrustedHeaderRenewal option configures the light client to proactively
+// update its trusted header in the background, before the trusting period
+// expires. Every checkInterval, the client checks whether the latest trusted
+// header expires within renewBefore and, if so, calls Update. This prevents
+// intermittently used clients from ending up with an expired trusted header,
+// which requires a subjective reset. The background routine is started with
+// Start and stopped with Stop.
+func TrustedHeaderRenewal(checkInterval, renewBefore time.Duration) Option {
+	return func(c *Client) {
+		c.renewalCheckInterval = checkInterval
+		c.renewBefore = renewBefore
+	}
+}
+
+// ClockSanityCheck option configures the light client to cross-check the
+// local clock against the witnesses when the primary returns a latest header
+// from the future. If a witness reports a header from the future too, the
+// local clock is deemed to be behind and ErrClockDrift is returned instead of
+// treating the primary as faulty.
+func ClockSanityCheck() Option {
+	return func(c *Client) {
+		c.checkClockSanity = true
+	}
+}
+
 // Client represents a light client, connected to a single chain, which gets
 // light blocks from a primary provider, verifies them either sequentially or by
 // skipping some and stores them in a trusted store (usually, a local FS).
@@ -138,6 +205,15 @@ type Client struct {
 	maxRetryAttempts uint16 // see MaxRetryAttempts option
 	maxClockDrift    time.Duration
 	maxBlockLag      time.Duration
+	// see SequentialFallback option
+	maxSequentialFallbackHeaders uint64
+	// see EntropyVerification option
+	verifyEntropy bool
+	// see TrustedHeaderRenewal option
+	renewalCheckInterval time.Duration
+	renewBefore          time.Duration
+	// see ClockSanityCheck option
+	checkClockSanity bool
 
 	// Mutex for locking during changes of the light clients providers
 	providerMutex tmsync.Mutex
@@ -156,9 +232,15 @@ type Client struct {
 	// See ConfirmationFunction option
 	confirmationFn func(action string) bool
 
-	quit chan struct{}
+	// the client is started and stopped once, see Start and Stop
+	runMtx  sync.Mutex
+	started bool
+	stopped bool
+	quit    chan struct{}
 
-	logger log.Logger
+	logger  log.Logger
+	metrics *Metrics
+	events  EventHandler
 }
 
 // NewClient returns a new light client. It returns an error if it fails to
@@ -232,6 +314,8 @@ func NewClientFromTrustedStore(
 		confirmationFn:   func(action string) bool { return true },
 		quit:             make(chan struct{}),
 		logger:           log.NewNopLogger(),
+		metrics:          NopMetrics(),
+		events:           NopEventHandler{},
 	}
 
 	for _, o := range options {
@@ -256,6 +340,12 @@ func NewClientFromTrustedStore(
 		return nil, err
 	}
 
+	if c.renewalCheckInterval < 0 ||
+		(c.renewalCheckInterval > 0 && (c.renewBefore <= 0 || c.renewBefore >= c.trustingPeriod)) {
+		return nil, fmt.Errorf("invalid trusted header renewal: interval %v, renew before %v (trusting period %v)",
+			c.renewalCheckInterval, c.renewBefore, c.trustingPeriod)
+	}
+
 	if err := c.restoreTrustedLightBlock(); err != nil {
 		return nil, err
 	}
@@ -449,6 +539,12 @@ func (c *Client) Update(ctx context.Context, now time.Time) (*types.LightBlock,
 		return nil, err
 	}
 
+	if c.checkClockSanity {
+		if err := c.checkClockDrift(ctx, latestBlock, now); err != nil {
+			return nil, err
+		}
+	}
+
 	if latestBlock.Height > lastTrustedHeight {
 		err = c.verifyLightBlock(ctx, latestBlock, now)
 		if err != nil {
@@ -461,6 +557,105 @@ func (c *Client) Update(ctx context.Context, now time.Time) (*types.LightBlock,
 	return nil, nil
 }
 
+// Start starts the background routine renewing the trusted header if the
+// TrustedHeaderRenewal option is set. Otherwise, it does nothing. It returns
+// service.ErrAlreadyStarted if the client is already started, and
+// service.ErrAlreadyStopped if it is stopped, as it can't be restarted.
+func (c *Client) Start() error {
+	c.runMtx.Lock()
+	defer c.runMtx.Unlock()
+	if c.stopped {
+		return service.ErrAlreadyStopped
+	}
+	if c.started {
+		return service.ErrAlreadyStarted
+	}
+	c.started = true
+	if c.renewalCheckInterval > 0 {
+		go c.renewalRoutine()
+	}
+	return nil
+}
+
+// Stop stops the background routines of the client. It's safe to call Stop
+// multiple times, and before Start.
+func (c *Client) Stop() {
+	c.runMtx.Lock()
+	defer c.runMtx.Unlock()
+	if !c.stopped {
+		c.stopped = true
+		close(c.quit)
+	}
+}
+
+func (c *Client) renewalRoutine() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(c.renewalCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.renewTrustedHeader(ctx, time.Now()); err != nil {
+				c.logger.Error("Failed to renew trusted header", "err", err)
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// renewTrustedHeader updates the trusted header if it expires within
+// renewBefore.
+func (c *Client) renewTrustedHeader(ctx context.Context, now time.Time) error {
+	latest, err := c.TrustedLightBlock(0)
+	if err != nil {
+		return err
+	}
+	expiresAt := latest.Time.Add(c.trustingPeriod)
+	if expiresAt.After(now.Add(c.renewBefore)) {
+		return nil
+	}
+	if !expiresAt.After(now) {
+		return ErrOldHeaderExpired{expiresAt, now}
+	}
+
+	c.logger.Info("Trusted header is about to expire, renewing", "height", latest.Height, "expiresAt", expiresAt)
+	_, err = c.Update(ctx, now)
+	return err
+}
+
+// checkClockDrift returns ErrClockDrift if both the primary's latest block and
+// the latest block of at least one witness are from the future, meaning the
+// local clock is most likely behind.
+func (c *Client) checkClockDrift(ctx context.Context, latestBlock *types.LightBlock, now time.Time) error {
+	if latestBlock.Time.Before(now.Add(c.maxClockDrift)) {
+		return nil
+	}
+
+	for _, witness := range c.Witnesses() {
+		lb, err := witness.LightBlock(ctx, 0)
+		if err != nil {
+			c.logger.Debug("Failed to get latest light block from witness", "witness", witness, "err", err)
+			continue
+		}
+		if !lb.Time.Before(now.Add(c.maxClockDrift)) {
+			return ErrClockDrift{Now: now, HeaderTime: latestBlock.Time, MaxClockDrift: c.maxClockDrift}
+		}
+	}
+
+	return nil
+}
+
 // VerifyLightBlockAtHeight fetches the light block at the given height
 // and verifies it. It returns the block immediately if it exists in
 // the trustedStore (no verification is needed).
@@ -493,6 +688,148 @@ func (c *Client) VerifyLightBlockAtHeight(ctx context.Context, height int64, now
 	return l, c.verifyLightBlock(ctx, l, now)
 }
 
+// VerifyRange fetches and verifies the contiguous range of light blocks
+// [from, to] and returns them in ascending height order. It is meant for
+// relayers which need many consecutive verified headers.
+//
+// The light block at from is verified like VerifyLightBlockAtHeight does.
+// Then, the remaining light blocks are fetched from the primary, checked to be
+// linked to each other, and their commits are verified concurrently. Only the
+// last light block is cross-checked with the witnesses, which covers the whole
+// range since the blocks are hash-linked. All light blocks are saved to the
+// trusted store (subject to the PruningSize option).
+//
+// from must be > 0 and to must be >= from.
+func (c *Client) VerifyRange(ctx context.Context, from, to int64, now time.Time) ([]*types.LightBlock, error) {
+	if from <= 0 {
+		return nil, errors.New("negative or zero height")
+	}
+	if to < from {
+		return nil, fmt.Errorf("invalid range [%d, %d]", from, to)
+	}
+
+	first, err := c.VerifyLightBlockAtHeight(ctx, from, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify light block at height %d: %w", from, err)
+	}
+
+	blocks := make([]*types.LightBlock, 0, to-from+1)
+	blocks = append(blocks, first)
+	for height := from + 1; height <= to; height++ {
+		prev := blocks[len(blocks)-1]
+
+		// Reuse the trusted light block if the range overlaps the store.
+		l, err := c.TrustedLightBlock(height)
+		if err != nil {
+			l, err = c.lightBlockFromPrimary(ctx, height)
+			if err != nil {
+				return nil, ErrVerificationFailed{From: prev.Height, To: height, Reason: err}
+			}
+		}
+
+		if !bytes.Equal(l.LastBlockID.Hash, prev.Hash()) {
+			return nil, ErrVerificationFailed{From: prev.Height, To: height, Reason: ErrInvalidHeader{
+				fmt.Errorf("last block hash %X does not match the previous header %X", l.LastBlockID.Hash, prev.Hash())}}
+		}
+		// Amortize the validator set: adjacent blocks usually share the same one
+		// and the light block has already been validated against its header.
+		if bytes.Equal(l.ValidatorsHash, prev.ValidatorsHash) {
+			l.ValidatorSet = prev.ValidatorSet
+		}
+		blocks = append(blocks, l)
+	}
+
+	if err := c.verifyAdjacentConcurrently(blocks, now); err != nil {
+		return nil, err
+	}
+	for _, l := range blocks[1:] {
+		if err := c.verifyLightBlockEntropy(ctx, l); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(blocks) > 1 {
+		if err := c.detectDivergence(ctx, blocks, now); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, l := range blocks[1:] {
+		c.metrics.HeadersVerified.Add(1)
+		if err := c.updateTrustedLightBlock(l); err != nil {
+			return nil, err
+		}
+	}
+
+	return blocks, nil
+}
+
+// verifyAdjacentConcurrently verifies every pair of adjacent light blocks
+// using a bounded number of goroutines, each verifying the commit signatures
+// of its share of the range in a batch. It returns the error of the lowest
+// failing height, if any.
+func (c *Client) verifyAdjacentConcurrently(blocks []*types.LightBlock, now time.Time) error {
+	var (
+		errs = make([]error, len(blocks))
+		wg   sync.WaitGroup
+	)
+	pairs := len(blocks) - 1
+	workers := runtime.NumCPU()
+	if workers > pairs {
+		workers = pairs
+	}
+	for w := 0; w < workers; w++ {
+		// the pairs ending at the indexes [from, to)
+		from, to := 1+w*pairs/workers, 1+(w+1)*pairs/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.verifyAdjacentBatch(blocks, from, to, now, errs)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyAdjacentBatch verifies the pairs of adjacent light blocks ending at
+// the indexes [from, to), with their commit signatures in one batch, and sets
+// their errors in errs. If the batch is invalid, the pair with the first
+// invalid signature is verified again on its own for its error.
+func (c *Client) verifyAdjacentBatch(blocks []*types.LightBlock, from, to int, now time.Time, errs []error) {
+	var (
+		sigs = types.NewSignatureBatch()
+		// the index of the first signature of each pair in the batch
+		starts = make([]int, 0, to-from)
+	)
+	for i := from; i < to; i++ {
+		trusted, untrusted := blocks[i-1], blocks[i]
+		starts = append(starts, sigs.Len())
+		err := verifyAdjacent(trusted.SignedHeader, untrusted.SignedHeader, untrusted.ValidatorSet,
+			c.trustingPeriod, now, c.maxClockDrift, sigs)
+		if err != nil {
+			errs[i] = ErrVerificationFailed{From: trusted.Height, To: untrusted.Height, Reason: err}
+		}
+	}
+
+	invalid := sigs.FirstInvalid()
+	if invalid < 0 {
+		return
+	}
+	i := from + sort.SearchInts(starts, invalid+1) - 1
+	trusted, untrusted := blocks[i-1], blocks[i]
+	err := VerifyAdjacent(trusted.SignedHeader, untrusted.SignedHeader, untrusted.ValidatorSet,
+		c.trustingPeriod, now, c.maxClockDrift)
+	if err != nil {
+		errs[i] = ErrVerificationFailed{From: trusted.Height, To: untrusted.Height, Reason: err}
+	}
+}
+
 // VerifyHeader verifies a new header against the trusted state. It returns
 // immediately if newHeader exists in trustedStore (no verification is
 // needed). Else it performs one of the two types of verification:
@@ -600,21 +937,73 @@ func (c *Client) verifyLightBlock(ctx context.Context, newLightBlock *types.Ligh
 		}
 		err = verifyFunc(ctx, closestBlock, newLightBlock, now)
 	}
+	if err == nil {
+		err = c.verifyLightBlockEntropy(ctx, newLightBlock)
+	}
 	if err != nil {
 		c.logger.Error("Can't verify", "err", err)
 		return err
 	}
 
 	// Once verified, save and return
+	c.metrics.HeadersVerified.Add(1)
 	return c.updateTrustedLightBlock(newLightBlock)
 }
 
+// verifyLightBlockEntropy fetches the entropy of the given and the previous
+// light block from the primary and verifies it if the EntropyVerification
+// option is enabled.
+func (c *Client) verifyLightBlockEntropy(ctx context.Context, lb *types.LightBlock) error {
+	if !c.verifyEntropy {
+		return nil
+	}
+	if lb.Height <= 1 {
+		c.logger.Debug("Skipping entropy verification of the initial block", "height", lb.Height)
+		return nil
+	}
+
+	c.providerMutex.Lock()
+	primary := c.primary
+	c.providerMutex.Unlock()
+
+	ep, ok := primary.(provider.EntropyProvider)
+	if !ok {
+		return fmt.Errorf("primary %v does not provide entropy", primary)
+	}
+
+	entropy, err := ep.Entropy(ctx, lb.Height)
+	if err != nil {
+		return fmt.Errorf("failed to obtain the entropy at height #%d: %w", lb.Height, err)
+	}
+	lastEntropy, err := ep.Entropy(ctx, lb.Height-1)
+	if err != nil {
+		return fmt.Errorf("failed to obtain the entropy at height #%d: %w", lb.Height-1, err)
+	}
+
+	return VerifyEntropy(lb, entropy, lastEntropy)
+}
+
 // see VerifyHeader
 func (c *Client) verifySequential(
 	ctx context.Context,
 	trustedBlock *types.LightBlock,
 	newLightBlock *types.LightBlock,
 	now time.Time) error {
+	return c.verifySequentialWith(ctx, trustedBlock, newLightBlock, now, false)
+}
+
+// verifySequentialWith verifies the light blocks sequentially. With fallback
+// (see SequentialFallback), the interim light blocks are fetched from the
+// primary or, if it can't serve them, from the witnesses, without replacing
+// the primary, which failed to serve the skipping verification rather than
+// sent anything invalid. Each interim block is verified against the previous
+// one, whoever provided it, and an invalid one fails the verification.
+func (c *Client) verifySequentialWith(
+	ctx context.Context,
+	trustedBlock *types.LightBlock,
+	newLightBlock *types.LightBlock,
+	now time.Time,
+	fallback bool) error {
 
 	var (
 		verifiedBlock = trustedBlock
@@ -627,6 +1016,11 @@ func (c *Client) verifySequential(
 		// 1) Fetch interim light block if needed.
 		if height == newLightBlock.Height { // last light block
 			interimBlock = newLightBlock
+		} else if fallback {
+			interimBlock, err = c.lightBlockFromAnyProvider(ctx, height)
+			if err != nil {
+				return ErrVerificationFailed{From: verifiedBlock.Height, To: height, Reason: err}
+			}
 		} else { // intermediate light blocks
 			interimBlock, err = c.lightBlockFromPrimary(ctx, height)
 			if err != nil {
@@ -653,6 +1047,10 @@ func (c *Client) verifySequential(
 					c.logger.Debug("Target header is invalid", "err", err)
 					return err
 				}
+				// The interim header may come from a witness.
+				if fallback {
+					return err
+				}
 
 				// If some intermediate header is invalid, replace the primary and try
 				// again.
@@ -725,6 +1123,7 @@ func (c *Client) verifySkipping(
 			"newHeight", blockCache[depth].Height,
 			"newHash", blockCache[depth].Hash())
 
+		c.metrics.BisectionSteps.Add(1)
 		err := Verify(verifiedBlock.SignedHeader, verifiedBlock.ValidatorSet, blockCache[depth].SignedHeader,
 			blockCache[depth].ValidatorSet, c.trustingPeriod, now, c.maxClockDrift, c.trustLevel)
 		switch err.(type) {
@@ -782,6 +1181,12 @@ func (c *Client) verifySkippingAgainstPrimary(
 
 	trace, err := c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now)
 
+	if _, ok := err.(ErrNewValSetCantBeTrusted); ok && c.canFallbackToSequential(trustedBlock, newLightBlock) {
+		c.logger.Info("Skipping verification can't make progress, falling back to sequential verification",
+			"trustedHeight", trustedBlock.Height, "newHeight", newLightBlock.Height, "err", err)
+		return c.verifySequentialWith(ctx, trustedBlock, newLightBlock, now, true)
+	}
+
 	switch errors.Unwrap(err).(type) {
 	case ErrInvalidHeader:
 		// If the target header is invalid, return immediately.
@@ -827,6 +1232,16 @@ func (c *Client) verifySkippingAgainstPrimary(
 	return nil
 }
 
+// canFallbackToSequential returns true if the SequentialFallback option is
+// enabled and the gap between trustedBlock and newLightBlock fits within the
+// configured maximum number of headers.
+func (c *Client) canFallbackToSequential(trustedBlock, newLightBlock *types.LightBlock) bool {
+	if c.maxSequentialFallbackHeaders == 0 || newLightBlock.Height <= trustedBlock.Height {
+		return false
+	}
+	return uint64(newLightBlock.Height-trustedBlock.Height) <= c.maxSequentialFallbackHeaders
+}
+
 // LastTrustedHeight returns a last trusted height. -1 and nil are returned if
 // there are no trusted headers.
 //
@@ -924,6 +1339,8 @@ func (c *Client) updateTrustedLightBlock(l *types.LightBlock) error {
 		c.latestTrustedBlock = l
 	}
 
+	c.events.OnTrustedHeaderUpdated(l)
+
 	return nil
 }
 
@@ -989,7 +1406,9 @@ func (c *Client) backwards(
 //     any other error, the primary is permanently dropped and is replaced by a witness.
 func (c *Client) lightBlockFromPrimary(ctx context.Context, height int64) (*types.LightBlock, error) {
 	c.providerMutex.Lock()
+	start := time.Now()
 	l, err := c.primary.LightBlock(ctx, height)
+	c.metrics.ProviderLatency.Observe(time.Since(start).Seconds())
 	c.providerMutex.Unlock()
 
 	switch err {
@@ -1015,6 +1434,28 @@ func (c *Client) lightBlockFromPrimary(ctx context.Context, height int64) (*type
 	}
 }
 
+// lightBlockFromAnyProvider returns the light block at the height from the
+// primary or, if it can't provide it, from the first witness which can. The
+// providers aren't replaced or removed. The light block must be verified.
+func (c *Client) lightBlockFromAnyProvider(ctx context.Context, height int64) (*types.LightBlock, error) {
+	c.providerMutex.Lock()
+	providers := append([]provider.Provider{c.primary}, c.witnesses...)
+	c.providerMutex.Unlock()
+
+	var errs []error
+	for _, p := range providers {
+		l, err := p.LightBlock(ctx, height)
+		if err == nil {
+			return l, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%v: %w", p, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
 // NOTE: requires a providerMutex lock
 func (c *Client) removeWitnesses(indexes []int) error {
 	// check that we will still have witnesses remaining
@@ -1154,6 +1595,7 @@ func (c *Client) compareFirstHeaderWithWitnesses(ctx context.Context, h *types.S
 		case nil:
 			continue
 		case errConflictingHeaders:
+			c.metrics.WitnessDisagreements.Add(1)
 			c.logger.Error(fmt.Sprintf(`Witness #%d has a different header. Please check primary is correct
 and remove witness. Otherwise, use the different primary`, e.WitnessIndex), "witness", c.witnesses[e.WitnessIndex])
 			return err
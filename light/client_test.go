@@ -13,6 +13,7 @@ import (
 	dbm "github.com/tendermint/tm-db"
 
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/service"
 	"github.com/Finschia/ostracon/light"
 	"github.com/Finschia/ostracon/light/provider"
 	mockp "github.com/Finschia/ostracon/light/provider/mock"
@@ -380,6 +381,123 @@ func TestClient_SkippingVerification(t *testing.T) {
 
 }
 
+func TestClient_SkippingVerificationFallsBackToSequential(t *testing.T) {
+	newKeys := genPrivKeys(4)
+	newVals := newKeys.ToValidators(10, 1)
+
+	// val set changes 100% at height 2
+	headers := map[int64]*types.SignedHeader{
+		1: h1,
+		2: keys.GenSignedHeader(chainID, 2, bTime.Add(1*time.Hour), nil, vals, newVals,
+			hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(keys)),
+		3: newKeys.GenSignedHeader(chainID, 3, bTime.Add(2*time.Hour), nil, newVals, newVals,
+			hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(newKeys)),
+	}
+	valSets := map[int64]*types.ValidatorSet{
+		1: vals,
+		2: vals,
+		3: newVals,
+	}
+	// the primary has pruned the interim header, so bisection can't proceed
+	primaryHeaders := map[int64]*types.SignedHeader{
+		1: headers[1],
+		3: headers[3],
+	}
+
+	testCases := []struct {
+		name       string
+		maxHeaders uint64
+		verifyErr  bool
+	}{
+		{"fallback disabled", 0, true},
+		{"gap exceeds max headers", 1, true},
+		{"fallback to sequential", 2, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			primary := mockp.New(chainID, primaryHeaders, valSets)
+			c, err := light.NewClient(
+				ctx,
+				chainID,
+				trustOptions,
+				primary,
+				[]provider.Provider{mockp.New(chainID, headers, valSets)},
+				dbs.New(dbm.NewMemDB(), chainID),
+				light.SkippingVerification(light.DefaultTrustLevel),
+				light.SequentialFallback(tc.maxHeaders),
+				light.Logger(log.TestingLogger()),
+			)
+			require.NoError(t, err)
+
+			_, err = c.VerifyLightBlockAtHeight(ctx, 3, bTime.Add(3*time.Hour))
+			if tc.verifyErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				// the interim header is fetched from the witness, the primary
+				// staying the primary
+				assert.Equal(t, primary, c.Primary())
+			}
+		})
+	}
+}
+
+func TestClient_EntropyVerification(t *testing.T) {
+	entropies := map[int64]*types.Entropy{1: genInitialEntropy()}
+	headers := map[int64]*types.SignedHeader{1: h1}
+	for height := int64(2); height <= 3; height++ {
+		entropy, proposer := keys.genEntropy(vals, entropies[height-1], height, 0)
+		header := genHeader(chainID, height, bTime.Add(time.Duration(height)*time.Hour), nil, vals, vals,
+			hash("app_hash"), hash("cons_hash"), hash("results_hash"), nil)
+		header.ProposerAddress = proposer
+		header.LastBlockID = types.BlockID{Hash: headers[height-1].Hash()}
+		headers[height] = &types.SignedHeader{Header: header, Commit: keys.signHeader(header, vals, 0, len(keys))}
+		entropies[height] = entropy
+	}
+
+	testCases := []struct {
+		name      string
+		entropies map[int64]*types.Entropy
+		verifyErr bool
+	}{
+		{"good", entropies, false},
+		{"bad: entropy forged", map[int64]*types.Entropy{1: entropies[1], 2: entropies[2], 3: genInitialEntropy()}, true},
+		{"bad: entropy missing", map[int64]*types.Entropy{1: entropies[1], 2: entropies[2]}, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			primary := mockp.New(chainID, headers, valSet)
+			for height, entropy := range tc.entropies {
+				primary.AddEntropy(height, entropy)
+			}
+
+			c, err := light.NewClient(
+				ctx,
+				chainID,
+				trustOptions,
+				primary,
+				[]provider.Provider{mockp.New(chainID, headers, valSet)},
+				dbs.New(dbm.NewMemDB(), chainID),
+				light.SequentialVerification(),
+				light.EntropyVerification(),
+				light.Logger(log.TestingLogger()),
+			)
+			require.NoError(t, err)
+
+			_, err = c.VerifyLightBlockAtHeight(ctx, 3, bTime.Add(3*time.Hour))
+			if tc.verifyErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 // start from a large light block to make sure that the pivot height doesn't select a height outside
 // the appropriate range
 func TestClientLargeBisectionVerification(t *testing.T) {
@@ -407,6 +525,90 @@ func TestClientLargeBisectionVerification(t *testing.T) {
 	assert.Equal(t, h, h2)
 }
 
+func TestClient_VerifyRange(t *testing.T) {
+	node := mockp.New(genMockNode(chainID, 20, 4, 1, bTime))
+	trustedLightBlock, err := node.LightBlock(ctx, 1)
+	require.NoError(t, err)
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Period: 4 * time.Hour,
+			Height: trustedLightBlock.Height,
+			Hash:   trustedLightBlock.Hash(),
+		},
+		node,
+		[]provider.Provider{node},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+
+	now := bTime.Add(30 * time.Minute)
+
+	_, err = c.VerifyRange(ctx, 0, 5, now)
+	assert.Error(t, err)
+	_, err = c.VerifyRange(ctx, 5, 4, now)
+	assert.Error(t, err)
+
+	blocks, err := c.VerifyRange(ctx, 5, 15, now)
+	require.NoError(t, err)
+	require.Len(t, blocks, 11)
+	for i, l := range blocks {
+		height := int64(5 + i)
+		assert.EqualValues(t, height, l.Height)
+		expected, err := node.LightBlock(ctx, height)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Hash(), l.Hash())
+
+		trusted, err := c.TrustedLightBlock(height)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Hash(), trusted.Hash())
+	}
+
+	// overlapping with already trusted light blocks
+	blocks, err = c.VerifyRange(ctx, 10, 20, now)
+	require.NoError(t, err)
+	assert.Len(t, blocks, 11)
+	lastHeight, err := c.LastTrustedHeight()
+	require.NoError(t, err)
+	assert.EqualValues(t, 20, lastHeight)
+}
+
+func TestClient_VerifyRangeInvalidSignature(t *testing.T) {
+	_, headers, vals := genMockNode(chainID, 20, 4, 1, bTime)
+	commit := headers[12].Commit
+	commit.Signatures[0].Signature = commit.Signatures[1].Signature
+	node := mockp.New(chainID, headers, vals)
+	trustedLightBlock, err := node.LightBlock(ctx, 1)
+	require.NoError(t, err)
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Period: 4 * time.Hour,
+			Height: trustedLightBlock.Height,
+			Hash:   trustedLightBlock.Hash(),
+		},
+		node,
+		[]provider.Provider{node},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+	)
+	require.NoError(t, err)
+
+	// the signatures of the range are verified in batches, the invalid one is
+	// still found
+	_, err = c.VerifyRange(ctx, 5, 15, bTime.Add(30*time.Minute))
+	var errVerification light.ErrVerificationFailed
+	require.ErrorAs(t, err, &errVerification)
+	assert.EqualValues(t, 11, errVerification.From)
+	assert.EqualValues(t, 12, errVerification.To)
+	assert.ErrorContains(t, errVerification.Reason, "wrong signature (#0)")
+	_, err = c.TrustedLightBlock(12)
+	assert.Error(t, err)
+}
+
 func TestClientBisectionBetweenTrustedHeaders(t *testing.T) {
 	c, err := light.NewClient(
 		ctx,
@@ -1161,3 +1363,76 @@ func TestClientHandlesContexts(t *testing.T) {
 	require.True(t, errors.Is(err, context.Canceled))
 
 }
+
+func TestClient_TrustedHeaderRenewal(t *testing.T) {
+	// the trusted header at height 1 expires in a minute, the latest header in ten minutes
+	var (
+		now         = time.Now()
+		startTime   = now.Add(-time.Hour - 10*time.Minute)
+		trustPeriod = time.Hour + 10*time.Minute
+	)
+	headers, vals, _ := genMockNodeWithKeys(chainID, 10, 3, 0, startTime)
+	node := mockp.New(chainID, headers, vals)
+
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Period: trustPeriod,
+			Height: 1,
+			Hash:   headers[1].Hash(),
+		},
+		node,
+		[]provider.Provider{node},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+		light.TrustedHeaderRenewal(10*time.Millisecond, 5*time.Minute),
+	)
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	assert.Equal(t, service.ErrAlreadyStarted, c.Start())
+
+	assert.Eventually(t, func() bool {
+		height, err := c.LastTrustedHeight()
+		return err == nil && height == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the client can't be restarted
+	c.Stop()
+	c.Stop()
+	assert.Equal(t, service.ErrAlreadyStopped, c.Start())
+
+	// renewBefore must be less than the trusting period
+	_, err = light.NewClient(
+		ctx,
+		chainID,
+		trustOptions,
+		fullNode,
+		[]provider.Provider{fullNode},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.TrustedHeaderRenewal(time.Second, trustOptions.Period),
+	)
+	assert.Error(t, err)
+}
+
+func TestClient_ClockSanityCheck(t *testing.T) {
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		trustOptions,
+		fullNode,
+		[]provider.Provider{fullNode},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+		light.ClockSanityCheck(),
+	)
+	require.NoError(t, err)
+
+	// the latest header of both providers is from the future
+	_, err = c.Update(ctx, bTime.Add(30*time.Minute))
+	assert.IsType(t, light.ErrClockDrift{}, err)
+
+	_, err = c.Update(ctx, bTime.Add(2*time.Hour))
+	assert.NoError(t, err)
+}
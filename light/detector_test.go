@@ -59,6 +59,7 @@ func TestLightClientAttackEvidence_Lunatic(t *testing.T) {
 		primaryValidators[height] = vals
 	}
 	primary := mockp.New(chainID, primaryHeaders, primaryValidators)
+	events := &recordingEventHandler{}
 
 	c, err := light.NewClient(
 		ctx,
@@ -73,6 +74,7 @@ func TestLightClientAttackEvidence_Lunatic(t *testing.T) {
 		dbs.New(dbm.NewMemDB(), chainID),
 		light.Logger(log.TestingLogger()),
 		light.MaxRetryAttempts(1),
+		light.Events(events),
 	)
 	require.NoError(t, err)
 
@@ -82,6 +84,9 @@ func TestLightClientAttackEvidence_Lunatic(t *testing.T) {
 		assert.Equal(t, light.ErrLightClientAttack, err)
 	}
 
+	// Check the handler was notified of both evidences.
+	assert.Len(t, events.evidences, 2)
+
 	// Check evidence was sent to both full nodes.
 	evAgainstPrimary := &types.LightClientAttackEvidence{
 		// after the divergence height the valset doesn't change, so we expect the evidence to be for height 10
@@ -40,6 +40,36 @@ func (e ErrInvalidHeader) Error() string {
 	return fmt.Sprintf("invalid header: %v", e.Reason)
 }
 
+// ErrInvalidEntropy means the entropy (VRF proof and round) of the block was
+// not generated by the legitimately elected proposer.
+type ErrInvalidEntropy struct {
+	Height int64
+	Reason error
+}
+
+// Unwrap returns underlying reason.
+func (e ErrInvalidEntropy) Unwrap() error {
+	return e.Reason
+}
+
+func (e ErrInvalidEntropy) Error() string {
+	return fmt.Sprintf("invalid entropy at height %d: %v", e.Height, e.Reason)
+}
+
+// ErrClockDrift means the latest headers of the primary and witnesses are
+// from the future relative to the local time, i.e. the local clock is most
+// likely behind.
+type ErrClockDrift struct {
+	Now           time.Time
+	HeaderTime    time.Time
+	MaxClockDrift time.Duration
+}
+
+func (e ErrClockDrift) Error() string {
+	return fmt.Sprintf("local clock is behind: latest header time %v is after now %v (max clock drift: %v)",
+		e.HeaderTime, e.Now, e.MaxClockDrift)
+}
+
 // ErrFailedHeaderCrossReferencing is returned when the detector was not able to cross reference the header
 // with any of the connected witnesses.
 var ErrFailedHeaderCrossReferencing = errors.New("all witnesses have either not responded, don't have the " +
@@ -0,0 +1,30 @@
+package light
+
+import (
+	"github.com/Finschia/ostracon/types"
+)
+
+// EventHandler receives notifications about the light client's progress, so
+// that embedding applications can integrate monitoring and alerting.
+//
+// Handlers are called synchronously from the verification path and must not
+// block or call back into the Client.
+type EventHandler interface {
+	// OnTrustedHeaderUpdated is called after a light block has been verified
+	// and saved to the trusted store.
+	OnTrustedHeaderUpdated(lb *types.LightBlock)
+
+	// OnAttackDetected is called when the light client has detected an attack,
+	// right before the evidence is reported to the given provider.
+	OnAttackDetected(ev *types.LightClientAttackEvidence, receiver string)
+}
+
+// NopEventHandler is an EventHandler that ignores all events. It may be
+// embedded to implement only a subset of EventHandler.
+type NopEventHandler struct{}
+
+var _ EventHandler = NopEventHandler{}
+
+func (NopEventHandler) OnTrustedHeaderUpdated(*types.LightBlock) {}
+
+func (NopEventHandler) OnAttackDetected(*types.LightClientAttackEvidence, string) {}
@@ -0,0 +1,61 @@
+package light_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/light"
+	"github.com/Finschia/ostracon/light/provider"
+	dbs "github.com/Finschia/ostracon/light/store/db"
+	"github.com/Finschia/ostracon/types"
+)
+
+// recordingEventHandler records all the events it receives.
+type recordingEventHandler struct {
+	mtx       sync.Mutex
+	heights   []int64
+	evidences []*types.LightClientAttackEvidence
+}
+
+var _ light.EventHandler = (*recordingEventHandler)(nil)
+
+func (h *recordingEventHandler) OnTrustedHeaderUpdated(lb *types.LightBlock) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.heights = append(h.heights, lb.Height)
+}
+
+func (h *recordingEventHandler) OnAttackDetected(ev *types.LightClientAttackEvidence, _ string) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.evidences = append(h.evidences, ev)
+}
+
+func TestClient_Events(t *testing.T) {
+	handler := &recordingEventHandler{}
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		trustOptions,
+		fullNode,
+		[]provider.Provider{fullNode},
+		dbs.New(dbm.NewMemDB(), chainID),
+		light.Logger(log.TestingLogger()),
+		light.ClientMetrics(light.NopMetrics()),
+		light.Events(handler),
+	)
+	require.NoError(t, err)
+
+	_, err = c.VerifyLightBlockAtHeight(ctx, 3, bTime.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 3}, handler.heights)
+	assert.Empty(t, handler.evidences)
+}
@@ -234,6 +234,39 @@ func (pkz privKeys) GenSignedHeaderLastBlockID(chainID string, height int64, bTi
 	}
 }
 
+// genEntropy generates the entropy of the block at the given height, proven by
+// the proposer elected with the VRF output of lastEntropy. It returns the
+// address of the proposer, which must be set as the header's ProposerAddress.
+func (pkz privKeys) genEntropy(valset *types.ValidatorSet, lastEntropy *types.Entropy,
+	height int64, round int32) (*types.Entropy, types.Address) {
+
+	lastProofHash, err := ed25519.ProofToHash(lastEntropy.Proof)
+	if err != nil {
+		panic(err)
+	}
+	proposer := valset.SelectProposer(lastProofHash, height, round)
+	for _, pk := range pkz {
+		if !bytes.Equal(pk.PubKey().Address(), proposer.Address) {
+			continue
+		}
+		proof, err := pk.VRFProve(types.MakeRoundHash(lastProofHash, height-1, round))
+		if err != nil {
+			panic(err)
+		}
+		return &types.Entropy{Round: round, Proof: tmbytes.HexBytes(proof)}, proposer.Address
+	}
+	panic("proposer is not in the keys")
+}
+
+// genInitialEntropy generates an arbitrary entropy to start a chain of entropies.
+func genInitialEntropy() *types.Entropy {
+	proof, err := ed25519.GenPrivKey().VRFProve(rand.Bytes(10))
+	if err != nil {
+		panic(err)
+	}
+	return &types.Entropy{Proof: tmbytes.HexBytes(proof)}
+}
+
 func (pkz privKeys) ChangeKeys(delta int) privKeys {
 	newKeys := pkz[delta:]
 	return newKeys.Extend(delta)
@@ -0,0 +1,89 @@
+package light
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "light"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Number of light blocks verified and saved to the trusted store.
+	HeadersVerified metrics.Counter
+	// Number of verification steps made while bisecting.
+	BisectionSteps metrics.Counter
+	// Number of times a witness reported a header different from the primary.
+	WitnessDisagreements metrics.Counter
+	// Number of detected attacks on the light client.
+	AttacksDetected metrics.Counter
+	// Time taken by the primary to return a light block in seconds.
+	ProviderLatency metrics.Histogram
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		HeadersVerified: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "headers_verified",
+			Help:      "Number of light blocks verified and saved to the trusted store.",
+		}, labels).With(labelsAndValues...),
+		BisectionSteps: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "bisection_steps",
+			Help:      "Number of verification steps made while bisecting.",
+		}, labels).With(labelsAndValues...),
+		WitnessDisagreements: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "witness_disagreements",
+			Help:      "Number of times a witness reported a header different from the primary.",
+		}, labels).With(labelsAndValues...),
+		AttacksDetected: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "attacks_detected",
+			Help:      "Number of detected attacks on the light client.",
+		}, labels).With(labelsAndValues...),
+		ProviderLatency: provider.NewHistogram(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "provider_latency",
+			Help:      "Time taken by the primary to return a light block in seconds.",
+			Buckets:   tmmetrics.ExponentialBuckets(0.01, 2, 12),
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		HeadersVerified:      discard.NewCounter(),
+		BisectionSteps:       discard.NewCounter(),
+		WitnessDisagreements: discard.NewCounter(),
+		AttacksDetected:      discard.NewCounter(),
+		ProviderLatency:      discard.NewHistogram(),
+	}
+}
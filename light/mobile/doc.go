@@ -0,0 +1,19 @@
+/*
+Package mobile provides gomobile-friendly bindings for the light client.
+
+The exported API only uses types supported by gomobile (strings, byte slices,
+integers, booleans and errors), and never channels or slices of structs, so
+that iOS and Android applications can verify Ostracon headers and proofs
+on-device:
+
+	gomobile bind -target=android github.com/Finschia/ostracon/light/mobile
+
+Light blocks are exchanged as protobuf-encoded tendermint.types.LightBlock
+messages, hashes as hex-encoded strings, durations in seconds and times as
+Unix timestamps in seconds.
+
+The Client type wraps a light.Client using HTTP providers and a trusted store
+persisted in a directory, while the Verify* functions expose the stateless
+verification functions of the light package.
+*/
+package mobile
@@ -0,0 +1,310 @@
+package mobile
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/Finschia/ostracon/crypto/merkle"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	tmmath "github.com/Finschia/ostracon/libs/math"
+	"github.com/Finschia/ostracon/light"
+	dbs "github.com/Finschia/ostracon/light/store/db"
+	"github.com/Finschia/ostracon/types"
+)
+
+// DefaultMaxClockDriftSeconds is the max clock drift used by the Verify*
+// functions.
+const DefaultMaxClockDriftSeconds = 10
+
+// Client is a light client connected to a single chain through HTTP
+// providers.
+type Client struct {
+	lc *light.Client
+	db dbm.DB
+}
+
+// NewClient creates a new light client. witnesses is a comma-separated list
+// of RPC addresses, trustHash is hex-encoded and dbDir is the directory
+// where the trusted light blocks are persisted. If the trusted store already
+// contains light blocks, trustHeight may be 0 and trustHash empty to continue
+// from the latest trusted state.
+func NewClient(
+	chainID string,
+	primary string,
+	witnesses string,
+	trustHeight int64,
+	trustHash string,
+	trustingPeriodSeconds int64,
+	dbDir string,
+) (*Client, error) {
+	if trustingPeriodSeconds <= 0 {
+		return nil, errors.New("trusting period must be positive")
+	}
+	witnessAddrs := splitAddresses(witnesses)
+	if len(witnessAddrs) == 0 {
+		return nil, errors.New("at least one witness must be given")
+	}
+
+	db, err := dbm.NewGoLevelDB("light-client-db", dbDir)
+	if err != nil {
+		return nil, fmt.Errorf("can't create a db: %w", err)
+	}
+
+	var (
+		trustingPeriod = time.Duration(trustingPeriodSeconds) * time.Second
+		store          = dbs.New(db, chainID)
+		lc             *light.Client
+	)
+	if trustHeight > 0 {
+		hash, err := hex.DecodeString(trustHash)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("invalid trust hash: %w", err)
+		}
+		lc, err = light.NewHTTPClient(
+			context.Background(),
+			chainID,
+			light.TrustOptions{
+				Period: trustingPeriod,
+				Height: trustHeight,
+				Hash:   hash,
+			},
+			primary,
+			witnessAddrs,
+			store,
+		)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	} else {
+		lc, err = light.NewHTTPClientFromTrustedStore(chainID, trustingPeriod, primary, witnessAddrs, store)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return &Client{lc: lc, db: db}, nil
+}
+
+// ChainID returns the chain ID the client was configured with.
+func (c *Client) ChainID() string {
+	return c.lc.ChainID()
+}
+
+// Update advances the client to the latest light block of the primary. It
+// returns the new trusted height, or 0 if there was no newer block.
+func (c *Client) Update() (int64, error) {
+	lb, err := c.lc.Update(context.Background(), time.Now())
+	if err != nil || lb == nil {
+		return 0, err
+	}
+	return lb.Height, nil
+}
+
+// VerifyLightBlockAtHeight fetches and verifies the light block at the given
+// height. It returns the protobuf-encoded light block.
+func (c *Client) VerifyLightBlockAtHeight(height int64) ([]byte, error) {
+	lb, err := c.lc.VerifyLightBlockAtHeight(context.Background(), height, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return encodeLightBlock(lb)
+}
+
+// TrustedLightBlock returns the protobuf-encoded trusted light block at the
+// given height (0 - the latest).
+func (c *Client) TrustedLightBlock(height int64) ([]byte, error) {
+	lb, err := c.lc.TrustedLightBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	return encodeLightBlock(lb)
+}
+
+// LastTrustedHeight returns the last trusted height, or -1 if there are no
+// trusted light blocks.
+func (c *Client) LastTrustedHeight() (int64, error) {
+	return c.lc.LastTrustedHeight()
+}
+
+// TrustedAppHash returns the hex-encoded app hash of the state after the
+// trusted block at the given height, i.e. the AppHash of the next header.
+func (c *Client) TrustedAppHash(height int64) (string, error) {
+	lb, err := c.lc.VerifyLightBlockAtHeight(context.Background(), height+1, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(lb.AppHash), nil
+}
+
+// Close stops the client and closes the trusted store.
+func (c *Client) Close() error {
+	c.lc.Stop()
+	return c.db.Close()
+}
+
+// VerifyAdjacent verifies the protobuf-encoded untrusted light block against
+// the adjacent trusted one. See light.VerifyAdjacent.
+func VerifyAdjacent(
+	chainID string,
+	trusted []byte,
+	untrusted []byte,
+	trustingPeriodSeconds int64,
+	nowUnix int64,
+) error {
+	trustedBlock, untrustedBlock, err := decodeLightBlocks(chainID, trusted, untrusted)
+	if err != nil {
+		return err
+	}
+	return light.VerifyAdjacent(trustedBlock.SignedHeader, untrustedBlock.SignedHeader,
+		untrustedBlock.ValidatorSet, time.Duration(trustingPeriodSeconds)*time.Second,
+		time.Unix(nowUnix, 0), DefaultMaxClockDriftSeconds*time.Second)
+}
+
+// VerifyNonAdjacent verifies the protobuf-encoded untrusted light block
+// against the non-adjacent trusted one, requiring trustLevelNumerator /
+// trustLevelDenominator of the trusted validators to have signed it. See
+// light.VerifyNonAdjacent.
+func VerifyNonAdjacent(
+	chainID string,
+	trusted []byte,
+	untrusted []byte,
+	trustingPeriodSeconds int64,
+	nowUnix int64,
+	trustLevelNumerator int64,
+	trustLevelDenominator int64,
+) error {
+	trustedBlock, untrustedBlock, err := decodeLightBlocks(chainID, trusted, untrusted)
+	if err != nil {
+		return err
+	}
+	if trustLevelNumerator <= 0 || trustLevelDenominator <= 0 {
+		return errors.New("trust level must be positive")
+	}
+	trustLevel := tmmath.Fraction{
+		Numerator:   uint64(trustLevelNumerator),
+		Denominator: uint64(trustLevelDenominator),
+	}
+	if err := light.ValidateTrustLevel(trustLevel); err != nil {
+		return err
+	}
+	return light.VerifyNonAdjacent(trustedBlock.SignedHeader, trustedBlock.ValidatorSet,
+		untrustedBlock.SignedHeader, untrustedBlock.ValidatorSet,
+		time.Duration(trustingPeriodSeconds)*time.Second, time.Unix(nowUnix, 0),
+		DefaultMaxClockDriftSeconds*time.Second, trustLevel)
+}
+
+// VerifyValue verifies the protobuf-encoded merkle proof (tendermint.crypto.ProofOps)
+// of the value at the given key path against the hex-encoded app hash. See
+// merkle.ProofRuntime.VerifyValue.
+func VerifyValue(appHash string, proofOps []byte, keyPath string, value []byte) error {
+	root, ops, err := decodeProof(appHash, proofOps)
+	if err != nil {
+		return err
+	}
+	return merkle.DefaultProofRuntime().VerifyValue(ops, root, keyPath, value)
+}
+
+// VerifyAbsence verifies the protobuf-encoded merkle proof (tendermint.crypto.ProofOps)
+// of the absence of the given key path against the hex-encoded app hash. See
+// merkle.ProofRuntime.VerifyAbsence.
+func VerifyAbsence(appHash string, proofOps []byte, keyPath string) error {
+	root, ops, err := decodeProof(appHash, proofOps)
+	if err != nil {
+		return err
+	}
+	return merkle.DefaultProofRuntime().VerifyAbsence(ops, root, keyPath)
+}
+
+// LightBlockToJSON converts the protobuf-encoded light block to JSON.
+func LightBlockToJSON(lightBlock []byte) (string, error) {
+	lb, err := decodeLightBlock(lightBlock)
+	if err != nil {
+		return "", err
+	}
+	bz, err := tmjson.Marshal(lb)
+	if err != nil {
+		return "", err
+	}
+	return string(bz), nil
+}
+
+// LightBlockHash returns the hex-encoded header hash of the protobuf-encoded
+// light block.
+func LightBlockHash(lightBlock []byte) (string, error) {
+	lb, err := decodeLightBlock(lightBlock)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(lb.Hash()), nil
+}
+
+func encodeLightBlock(lb *types.LightBlock) ([]byte, error) {
+	pb, err := lb.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	return pb.Marshal()
+}
+
+func decodeLightBlock(bz []byte) (*types.LightBlock, error) {
+	var pb tmproto.LightBlock
+	if err := pb.Unmarshal(bz); err != nil {
+		return nil, fmt.Errorf("invalid light block: %w", err)
+	}
+	lb, err := types.LightBlockFromProto(&pb)
+	if err != nil {
+		return nil, fmt.Errorf("invalid light block: %w", err)
+	}
+	if lb.SignedHeader == nil || lb.ValidatorSet == nil {
+		return nil, errors.New("invalid light block: missing signed header or validator set")
+	}
+	return lb, nil
+}
+
+func decodeLightBlocks(chainID string, trusted, untrusted []byte) (*types.LightBlock, *types.LightBlock, error) {
+	trustedBlock, err := decodeLightBlock(trusted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("trusted: %w", err)
+	}
+	if err := trustedBlock.ValidateBasic(chainID); err != nil {
+		return nil, nil, fmt.Errorf("trusted: %w", err)
+	}
+	untrustedBlock, err := decodeLightBlock(untrusted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("untrusted: %w", err)
+	}
+	return trustedBlock, untrustedBlock, nil
+}
+
+func decodeProof(appHash string, proofOps []byte) ([]byte, *tmcrypto.ProofOps, error) {
+	root, err := hex.DecodeString(appHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid app hash: %w", err)
+	}
+	var ops tmcrypto.ProofOps
+	if err := ops.Unmarshal(proofOps); err != nil {
+		return nil, nil, fmt.Errorf("invalid proof: %w", err)
+	}
+	return root, &ops, nil
+}
+
+func splitAddresses(addrs string) []string {
+	var res []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
@@ -0,0 +1,115 @@
+package mobile
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+
+	"github.com/Finschia/ostracon/crypto/merkle"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	"github.com/Finschia/ostracon/types"
+	"github.com/Finschia/ostracon/version"
+)
+
+const chainID = "mobile-test"
+
+var bTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func genLightBlock(t *testing.T, height int64, lastBlockID types.BlockID,
+	vals *types.ValidatorSet, privVals []types.PrivValidator) *types.LightBlock {
+
+	header := &types.Header{
+		Version:            tmversion.Consensus{Block: version.BlockProtocol, App: version.AppProtocol},
+		ChainID:            chainID,
+		Height:             height,
+		Time:               bTime.Add(time.Duration(height) * time.Minute),
+		LastBlockID:        lastBlockID,
+		ValidatorsHash:     vals.Hash(),
+		NextValidatorsHash: vals.Hash(),
+		ConsensusHash:      tmhash.Sum([]byte("cons_hash")),
+		AppHash:            tmhash.Sum([]byte("app_hash")),
+		ProposerAddress:    vals.Validators[0].Address,
+	}
+	blockID := types.BlockID{
+		Hash:          header.Hash(),
+		PartSetHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+	}
+	voteSet := types.NewVoteSet(chainID, height, 0, tmproto.PrecommitType, vals)
+	commit, err := types.MakeCommit(blockID, height, 0, voteSet, privVals, header.Time)
+	require.NoError(t, err)
+
+	return &types.LightBlock{
+		SignedHeader: &types.SignedHeader{Header: header, Commit: commit},
+		ValidatorSet: vals,
+	}
+}
+
+func TestVerify(t *testing.T) {
+	vals, privVals := types.RandValidatorSet(4, 10)
+	lb1 := genLightBlock(t, 1, types.BlockID{}, vals, privVals)
+	lb2 := genLightBlock(t, 2, lb1.Commit.BlockID, vals, privVals)
+	lb3 := genLightBlock(t, 3, lb2.Commit.BlockID, vals, privVals)
+
+	bz1, err := encodeLightBlock(lb1)
+	require.NoError(t, err)
+	bz2, err := encodeLightBlock(lb2)
+	require.NoError(t, err)
+	bz3, err := encodeLightBlock(lb3)
+	require.NoError(t, err)
+
+	now := bTime.Add(time.Hour).Unix()
+	period := int64(24 * 60 * 60)
+
+	assert.NoError(t, VerifyAdjacent(chainID, bz1, bz2, period, now))
+	assert.Error(t, VerifyAdjacent(chainID, bz1, bz3, period, now))
+	assert.Error(t, VerifyAdjacent("other-chain", bz1, bz2, period, now))
+	assert.Error(t, VerifyAdjacent(chainID, bz1, []byte("garbage"), period, now))
+	// trusted header expired
+	assert.Error(t, VerifyAdjacent(chainID, bz1, bz2, 60, now))
+
+	assert.NoError(t, VerifyNonAdjacent(chainID, bz1, bz3, period, now, 1, 3))
+	assert.Error(t, VerifyNonAdjacent(chainID, bz1, bz3, period, now, 1, 4))
+	assert.Error(t, VerifyNonAdjacent(chainID, bz1, bz2, period, now, 1, 3))
+
+	hash, err := LightBlockHash(bz2)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(lb2.Hash()), hash)
+
+	js, err := LightBlockToJSON(bz2)
+	require.NoError(t, err)
+	assert.Contains(t, js, chainID)
+}
+
+func TestVerifyValue(t *testing.T) {
+	kvLeaf := func(key, value string) []byte {
+		vhash := tmhash.Sum([]byte(value))
+		bz := []byte{byte(len(key))}
+		bz = append(bz, key...)
+		bz = append(bz, byte(len(vhash)))
+		return append(bz, vhash...)
+	}
+	root, proofs := merkle.ProofsFromByteSlices([][]byte{kvLeaf("baz", "qux"), kvLeaf("foo", "bar")})
+
+	op := merkle.NewValueOp([]byte("foo"), proofs[1])
+	ops := tmcrypto.ProofOps{Ops: []tmcrypto.ProofOp{op.ProofOp()}}
+	bz, err := ops.Marshal()
+	require.NoError(t, err)
+
+	appHash := hex.EncodeToString(root)
+	assert.NoError(t, VerifyValue(appHash, bz, "/foo", []byte("bar")))
+	assert.Error(t, VerifyValue(appHash, bz, "/foo", []byte("baz")))
+	assert.Error(t, VerifyValue("zz", bz, "/foo", []byte("bar")))
+	assert.Error(t, VerifyValue(appHash, []byte("garbage"), "/foo", []byte("bar")))
+}
+
+func TestSplitAddresses(t *testing.T) {
+	assert.Equal(t, []string{"a:1", "b:2"}, splitAddresses(" a:1, ,b:2,"))
+	assert.Empty(t, splitAddresses(""))
+}
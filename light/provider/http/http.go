@@ -1,11 +1,13 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Finschia/ostracon/light/provider"
@@ -14,11 +16,14 @@ import (
 	"github.com/Finschia/ostracon/types"
 )
 
+var _ provider.EntropyProvider = (*http)(nil)
+
 var (
 	// This is very brittle, see: https://github.com/tendermint/tendermint/issues/4740
 	regexpMissingHeight = regexp.MustCompile(`height \d+ is not available`)
 	regexpTooHigh       = regexp.MustCompile(`height \d+ must be less than or equal to`)
 	regexpTimedOut      = regexp.MustCompile(`Timeout exceeded`)
+	regexpNoMethod      = regexp.MustCompile(`Method not found`)
 
 	maxRetryAttempts      = 5
 	timeout          uint = 5 // sec.
@@ -28,6 +33,14 @@ var (
 type http struct {
 	chainID string
 	client  rpcclient.RemoteClient
+
+	mtx sync.Mutex
+	// the last validator set fetched and its height, from which the next ones
+	// are fetched as diffs
+	lastVals       *types.ValidatorSet
+	lastValsHeight int64
+	// set if the node doesn't support validators_diff
+	noValidatorsDiff bool
 }
 
 // New creates a HTTP provider, which is using the rpchttp.HTTP client under
@@ -83,9 +96,12 @@ func (p *http) LightBlock(ctx context.Context, height int64) (*types.LightBlock,
 		}
 	}
 
-	vs, err := p.validatorSet(ctx, &sh.Height)
-	if err != nil {
-		return nil, err
+	vs := p.validatorSetFromDiff(ctx, sh)
+	if vs == nil {
+		vs, err = p.validatorSet(ctx, &sh.Height)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	lb := &types.LightBlock{
@@ -98,15 +114,90 @@ func (p *http) LightBlock(ctx context.Context, height int64) (*types.LightBlock,
 		return nil, provider.ErrBadLightBlock{Reason: err}
 	}
 
+	p.mtx.Lock()
+	p.lastVals, p.lastValsHeight = vs, sh.Height
+	p.mtx.Unlock()
+
 	return lb, nil
 }
 
+// Entropy fetches the block at the given height and returns its Entropy.
+func (p *http) Entropy(ctx context.Context, height int64) (*types.Entropy, error) {
+	if height <= 0 {
+		return nil, provider.ErrBadLightBlock{Reason: fmt.Errorf("expected height > 0, got height %d", height)}
+	}
+
+	for attempt := 1; attempt <= maxRetryAttempts; attempt++ {
+		res, err := p.client.Block(ctx, &height)
+		switch {
+		case err == nil:
+			if res.Block == nil {
+				return nil, provider.ErrBadLightBlock{Reason: fmt.Errorf("nil block at height %d", height)}
+			}
+			if res.Block.Height != height {
+				return nil, provider.ErrBadLightBlock{
+					Reason: fmt.Errorf("height %d responded doesn't match height %d requested", res.Block.Height, height),
+				}
+			}
+			return &res.Block.Entropy, nil
+
+		case regexpTooHigh.MatchString(err.Error()):
+			return nil, provider.ErrHeightTooHigh
+
+		case regexpMissingHeight.MatchString(err.Error()):
+			return nil, provider.ErrLightBlockNotFound
+
+		case regexpTimedOut.MatchString(err.Error()):
+			// we wait and try again with exponential backoff
+			time.Sleep(backoffTimeout(uint16(attempt)))
+			continue
+
+		// either context was cancelled or connection refused.
+		default:
+			return nil, err
+		}
+	}
+	return nil, provider.ErrNoResponse
+}
+
 // ReportEvidence calls `/broadcast_evidence` endpoint.
 func (p *http) ReportEvidence(ctx context.Context, ev types.Evidence) error {
 	_, err := p.client.BroadcastEvidence(ctx, ev)
 	return err
 }
 
+// validatorSetFromDiff fetches the validator set of the signed header as the
+// diff from the last validator set fetched, which avoids fetching all the
+// validators when only a few of them changed. It returns nil if the validator
+// set must be fetched in full, e.g. if the node doesn't support validators_diff
+// or if the resulting validator set doesn't match the header.
+func (p *http) validatorSetFromDiff(ctx context.Context, sh *types.SignedHeader) *types.ValidatorSet {
+	p.mtx.Lock()
+	lastVals, from, noValidatorsDiff := p.lastVals, p.lastValsHeight, p.noValidatorsDiff
+	p.mtx.Unlock()
+	if lastVals == nil || noValidatorsDiff {
+		return nil
+	}
+
+	res, err := p.client.ValidatorsDiff(ctx, from, &sh.Height)
+	if err != nil {
+		if regexpNoMethod.MatchString(err.Error()) {
+			p.mtx.Lock()
+			p.noValidatorsDiff = true
+			p.mtx.Unlock()
+		}
+		return nil
+	}
+	if res.FromHeight != from || res.BlockHeight != sh.Height {
+		return nil
+	}
+	vals, err := lastVals.ApplyDiff(&res.Diff)
+	if err != nil || vals.Size() != res.Total || !bytes.Equal(vals.Hash(), sh.ValidatorsHash) {
+		return nil
+	}
+	return vals
+}
+
 func (p *http) validatorSet(ctx context.Context, height *int64) (*types.ValidatorSet, error) {
 	// Since the malicious node could report a massive number of pages, making us
 	// spend a considerable time iterating, we restrict the number of pages here.
@@ -2,22 +2,30 @@ package http_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
 
 	"github.com/Finschia/ostracon/abci/example/kvstore"
+	"github.com/Finschia/ostracon/crypto/tmhash"
 	"github.com/Finschia/ostracon/light/provider"
 	lighthttp "github.com/Finschia/ostracon/light/provider/http"
 	rpcclient "github.com/Finschia/ostracon/rpc/client"
 	rpchttp "github.com/Finschia/ostracon/rpc/client/http"
+	"github.com/Finschia/ostracon/rpc/client/mocks"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpcjson "github.com/Finschia/ostracon/rpc/jsonrpc/client"
 	rpctest "github.com/Finschia/ostracon/rpc/test"
 	"github.com/Finschia/ostracon/types"
+	"github.com/Finschia/ostracon/version"
 )
 
 func TestNewProvider(t *testing.T) {
@@ -94,3 +102,90 @@ func TestProvider(t *testing.T) {
 	require.Contains(t, err.Error(), "connection refused")
 	require.Nil(t, lb)
 }
+
+func TestProviderValidatorsDiff(t *testing.T) {
+	const chainID = "chain-test"
+	vals, privVals := types.RandValidatorSet(4, 10)
+	newVal, newPrivVal := types.RandValidator(false, 20)
+	privVals = append(privVals, newPrivVal)
+	newVals, err := vals.ApplyDiff(&types.ValidatorSetDiff{Updates: []*types.Validator{newVal}})
+	require.NoError(t, err)
+
+	// the validator joins at height 2
+	valsByHeight := map[int64]*types.ValidatorSet{1: vals, 2: newVals, 3: newVals}
+	c := mocks.NewRemoteClient(t)
+	atHeight := func(height int64) interface{} {
+		return mock.MatchedBy(func(h *int64) bool { return h != nil && *h == height })
+	}
+	for height, vals := range valsByHeight {
+		c.On("Commit", mock.Anything, atHeight(height)).
+			Return(&ctypes.ResultCommit{SignedHeader: *signedHeader(t, chainID, height, vals, privVals)}, nil)
+	}
+	c.On("Validators", mock.Anything, atHeight(1), mock.Anything, mock.Anything).
+		Return(&ctypes.ResultValidators{BlockHeight: 1, Validators: vals.Validators, Count: 4, Total: 4}, nil).Once()
+	c.On("ValidatorsDiff", mock.Anything, int64(1), atHeight(2)).
+		Return(&ctypes.ResultValidatorsDiff{FromHeight: 1, BlockHeight: 2, Diff: *vals.ComputeDiff(newVals), Total: 5}, nil).Once()
+
+	p := lighthttp.NewWithClient(chainID, c)
+	lb, err := p.LightBlock(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, vals.Hash(), lb.ValidatorSet.Hash())
+
+	// the validator set is fetched as the diff from the one at height 1
+	lb, err = p.LightBlock(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, newVals.Hash(), lb.ValidatorSet.Hash())
+
+	// and in full if the node doesn't support the diffs
+	c.On("ValidatorsDiff", mock.Anything, int64(2), atHeight(3)).
+		Return(nil, errors.New(`error in json rpc client: {"code":-32601,"message":"Method not found"}`)).Once()
+	c.On("Validators", mock.Anything, atHeight(3), mock.Anything, mock.Anything).
+		Return(&ctypes.ResultValidators{BlockHeight: 3, Validators: newVals.Validators, Count: 5, Total: 5}, nil).Once()
+	lb, err = p.LightBlock(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, newVals.Hash(), lb.ValidatorSet.Hash())
+
+	c.On("Validators", mock.Anything, atHeight(1), mock.Anything, mock.Anything).
+		Return(&ctypes.ResultValidators{BlockHeight: 1, Validators: vals.Validators, Count: 4, Total: 4}, nil).Once()
+	_, err = p.LightBlock(context.Background(), 1)
+	require.NoError(t, err)
+	c.AssertNumberOfCalls(t, "ValidatorsDiff", 2)
+}
+
+func signedHeader(
+	t *testing.T,
+	chainID string,
+	height int64,
+	vals *types.ValidatorSet,
+	privVals []types.PrivValidator,
+) *types.SignedHeader {
+	header := &types.Header{
+		Version:            tmversion.Consensus{Block: version.BlockProtocol},
+		ChainID:            chainID,
+		Height:             height,
+		Time:               time.Now(),
+		ValidatorsHash:     vals.Hash(),
+		NextValidatorsHash: vals.Hash(),
+		ProposerAddress:    vals.Validators[0].Address,
+	}
+	blockID := types.BlockID{
+		Hash:          header.Hash(),
+		PartSetHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+	}
+
+	// the validators sign in the order of the set
+	var signers []types.PrivValidator
+	for _, val := range vals.Validators {
+		for _, privVal := range privVals {
+			pubKey, err := privVal.GetPubKey()
+			require.NoError(t, err)
+			if pubKey.Equals(val.PubKey) {
+				signers = append(signers, privVal)
+			}
+		}
+	}
+	voteSet := types.NewVoteSet(chainID, height, 1, tmproto.PrecommitType, vals)
+	commit, err := types.MakeCommit(blockID, height, 1, voteSet, signers, time.Now())
+	require.NoError(t, err)
+	return &types.SignedHeader{Header: header, Commit: commit}
+}
@@ -18,11 +18,15 @@ type Mock struct {
 	mtx              sync.Mutex
 	headers          map[int64]*types.SignedHeader
 	vals             map[int64]*types.ValidatorSet
+	entropies        map[int64]*types.Entropy
 	evidenceToReport map[string]types.Evidence // hash => evidence
 	latestHeight     int64
 }
 
-var _ provider.Provider = (*Mock)(nil)
+var (
+	_ provider.Provider        = (*Mock)(nil)
+	_ provider.EntropyProvider = (*Mock)(nil)
+)
 
 // New creates a mock provider with the given set of headers and validator
 // sets.
@@ -41,6 +45,7 @@ func New(
 		chainID:          chainID,
 		headers:          headers,
 		vals:             vals,
+		entropies:        make(map[int64]*types.Entropy),
 		evidenceToReport: make(map[string]types.Evidence),
 		latestHeight:     height,
 	}
@@ -106,6 +111,34 @@ func (p *Mock) LightBlock(ctx context.Context, height int64) (*types.LightBlock,
 	return lb, nil
 }
 
+func (p *Mock) Entropy(ctx context.Context, height int64) (*types.Entropy, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if height > p.latestHeight {
+		return nil, provider.ErrHeightTooHigh
+	}
+	entropy, ok := p.entropies[height]
+	if !ok {
+		return nil, provider.ErrLightBlockNotFound
+	}
+	return entropy, nil
+}
+
+// AddEntropy sets the Entropy returned for the block at the given height.
+func (p *Mock) AddEntropy(height int64, entropy *types.Entropy) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.entropies[height] = entropy
+}
+
 func (p *Mock) ReportEvidence(_ context.Context, ev types.Evidence) error {
 	p.evidenceToReport[string(ev.Hash())] = ev
 	return nil
@@ -131,5 +164,9 @@ func (p *Mock) AddLightBlock(lb *types.LightBlock) {
 }
 
 func (p *Mock) Copy(id string) *Mock {
-	return New(id, p.headers, p.vals)
+	c := New(id, p.headers, p.vals)
+	for h, e := range p.entropies {
+		c.entropies[h] = e
+	}
+	return c
 }
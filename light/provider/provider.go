@@ -27,3 +27,17 @@ type Provider interface {
 	// ReportEvidence reports an evidence of misbehavior.
 	ReportEvidence(context.Context, types.Evidence) error
 }
+
+// EntropyProvider is an optional interface implemented by providers, which
+// can return the Ostracon-specific entropy (VRF proof and round) of a block.
+// The entropy is not part of the header hash, so it must be verified by the
+// light client against the proposer before it can be relied on.
+type EntropyProvider interface {
+	// Entropy returns the Entropy of the block at the given height.
+	//
+	// height must be > 0.
+	//
+	// If there's no block for the given height, ErrLightBlockNotFound error
+	// is returned.
+	Entropy(ctx context.Context, height int64) (*types.Entropy, error)
+}
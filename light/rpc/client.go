@@ -25,7 +25,11 @@ import (
 var errNegOrZeroHeight = errors.New("negative or zero height")
 
 // KeyPathFunc builds a merkle path out of the given path and key.
-type KeyPathFunc func(path string, key []byte) (merkle.KeyPath, error)
+type KeyPathFunc = rpcclient.KeyPathFunc
+
+// QueryKeyFunc returns the key queried by the data of an ABCIQuery on the
+// path (see rpcclient.ProofVerifier.QueryKey).
+type QueryKeyFunc func(path string, data []byte) ([]byte, error)
 
 // LightClient is an interface that contains functionality needed by Client from the light client.
 //
@@ -51,6 +55,11 @@ type Client struct {
 	// proof runtime used to verify values returned by ABCIQuery
 	prt       *merkle.ProofRuntime
 	keyPathFn KeyPathFunc
+	// key queried by the data of an ABCIQuery, the data itself if nil
+	queryKeyFn QueryKeyFunc
+
+	// size of the block parts of the chain, to verify the block IDs
+	blockPartSize uint32
 }
 
 var _ rpcclient.Client = (*Client)(nil)
@@ -67,6 +76,26 @@ func KeyPathFn(fn KeyPathFunc) Option {
 	}
 }
 
+// QueryKeyFn option can be used to set a function returning the key queried by
+// the data of an ABCIQuery on a path, which the key of the response must be.
+// By default the data is the key, as with the store queries of the Cosmos SDK.
+func QueryKeyFn(fn QueryKeyFunc) Option {
+	return func(c *Client) {
+		c.queryKeyFn = fn
+	}
+}
+
+// BlockPartSize option sets the size of the block parts of the chain (see
+// types.GenesisDoc.BlockPartSizeBytes), which is needed to verify the blocks.
+// The default, also used if size is zero, is types.BlockPartSizeBytes.
+func BlockPartSize(size uint32) Option {
+	return func(c *Client) {
+		if size != 0 {
+			c.blockPartSize = size
+		}
+	}
+}
+
 // DefaultMerkleKeyPathFn creates a function used to generate merkle key paths
 // from a path string and a key. This is the default used by the cosmos SDK.
 // This merkle key paths are required when verifying /abci_query calls
@@ -94,6 +123,8 @@ func NewClient(next rpcclient.Client, lc LightClient, opts ...Option) *Client {
 		next: next,
 		lc:   lc,
 		prt:  merkle.DefaultProofRuntime(),
+
+		blockPartSize: types.BlockPartSizeBytes,
 	}
 	c.BaseService = *service.NewBaseService(nil, "Client", c)
 	for _, o := range opts {
@@ -130,66 +161,27 @@ func (c *Client) ABCIQuery(ctx context.Context, path string, data tmbytes.HexByt
 	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
 }
 
-// ABCIQueryWithOptions returns an error if opts.Prove is false.
+// ABCIQueryWithOptions always requests the proof, and verifies the value
+// returned (or its absence) against the app hash of the header of the next
+// height verified by the light client (see
+// rpcclient.ABCIQueryWithProofVerification). The decoders of the proof ops of
+// the app must be registered with RegisterOpDecoder.
 func (c *Client) ABCIQueryWithOptions(ctx context.Context, path string, data tmbytes.HexBytes,
 	opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
 
-	// always request the proof
-	opts.Prove = true
-
-	res, err := c.next.ABCIQueryWithOptions(ctx, path, data, opts)
-	if err != nil {
-		return nil, err
-	}
-	resp := res.Response
-
-	// Validate the response.
-	if resp.IsErr() {
-		return nil, fmt.Errorf("err response code: %v", resp.Code)
-	}
-	if len(resp.Key) == 0 {
-		return nil, errors.New("empty key")
-	}
-	if resp.ProofOps == nil || len(resp.ProofOps.Ops) == 0 {
-		return nil, errors.New("no proof ops")
-	}
-	if resp.Height <= 0 {
-		return nil, errNegOrZeroHeight
-	}
-
-	// Update the light client if we're behind.
-	// NOTE: AppHash for height H is in header H+1.
-	nextHeight := resp.Height + 1
-	l, err := c.updateLightClientIfNeededTo(ctx, &nextHeight)
-	if err != nil {
-		return nil, err
-	}
-
-	// Validate the value proof against the trusted header.
-	if resp.Value != nil {
-		// 1) build a Merkle key path from path and resp.Key
-		if c.keyPathFn == nil {
-			return nil, errors.New("please configure Client with KeyPathFn option")
-		}
-
-		kp, err := c.keyPathFn(path, resp.Key)
-		if err != nil {
-			return nil, fmt.Errorf("can't build merkle key path: %w", err)
-		}
-
-		// 2) verify value
-		err = c.prt.VerifyValue(resp.ProofOps, l.AppHash, kp.String(), resp.Value)
-		if err != nil {
-			return nil, fmt.Errorf("verify value proof: %w", err)
-		}
-	} else { // OR validate the absence proof against the trusted header.
-		err = c.prt.VerifyAbsence(resp.ProofOps, l.AppHash, string(resp.Key))
-		if err != nil {
-			return nil, fmt.Errorf("verify absence proof: %w", err)
-		}
-	}
-
-	return &ctypes.ResultABCIQuery{Response: resp}, nil
+	return rpcclient.ABCIQueryWithProofVerification(ctx, c.next, path, data, opts, rpcclient.ProofVerifier{
+		ProofRuntime: c.prt,
+		KeyPathFn:    c.keyPathFn,
+		QueryKey:     c.queryKeyFn,
+		TrustedHeader: func(ctx context.Context, height int64) (*types.Header, error) {
+			// Update the light client if we're behind.
+			l, err := c.updateLightClientIfNeededTo(ctx, &height)
+			if err != nil {
+				return nil, err
+			}
+			return l.Header, nil
+		},
+	})
 }
 
 func (c *Client) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
@@ -212,6 +204,23 @@ func (c *Client) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirm
 	return c.next.NumUnconfirmedTxs(ctx)
 }
 
+// PendingTx calls rpcclient#PendingTx. The mempool txs aren't committed, so
+// there is nothing to verify.
+func (c *Client) PendingTx(ctx context.Context, hash []byte) (*ctypes.ResultPendingTx, error) {
+	return c.next.PendingTx(ctx, hash)
+}
+
+// PendingTxs calls rpcclient#PendingTxs.
+func (c *Client) PendingTxs(
+	ctx context.Context,
+	page,
+	perPage *int,
+	minGasWanted,
+	minHeight *int64,
+) (*ctypes.ResultPendingTxs, error) {
+	return c.next.PendingTxs(ctx, page, perPage, minGasWanted, minHeight)
+}
+
 func (c *Client) CheckTx(ctx context.Context, tx types.Tx) (*ctypes.ResultCheckTx, error) {
 	return c.next.CheckTx(ctx, tx)
 }
@@ -324,7 +333,7 @@ func (c *Client) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock,
 	if err := res.Block.ValidateBasic(); err != nil {
 		return nil, err
 	}
-	rbID := types.BlockID{Hash: res.Block.Hash(), PartSetHeader: res.Block.MakePartSet(types.BlockPartSizeBytes).Header()}
+	rbID := types.BlockID{Hash: res.Block.Hash(), PartSetHeader: res.Block.MakePartSet(c.blockPartSize).Header()}
 	if !res.BlockID.Equals(rbID) {
 		return nil, fmt.Errorf("blockID %v does not match with block %v", res.BlockID.String(), rbID.String())
 	}
@@ -455,6 +464,88 @@ func (c *Client) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommi
 	}, nil
 }
 
+// CanonicalJSON calls rpcclient#CanonicalJSON and then verifies the decoded
+// block, header, commit or vote against the light block at its height.
+func (c *Client) CanonicalJSON(
+	ctx context.Context,
+	typ string,
+	height *int64,
+	index *int,
+	amino bool,
+) (*ctypes.ResultCanonicalJSON, error) {
+	res, err := c.next.CanonicalJSON(ctx, typ, height, index, amino)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate res.
+	if res.Height <= 0 {
+		return nil, errNegOrZeroHeight
+	}
+	if res.Type != typ {
+		return nil, fmt.Errorf("expected the encoding of a %s, got a %s", typ, res.Type)
+	}
+
+	// Update the light client if we're behind.
+	l, err := c.updateLightClientIfNeededTo(ctx, &res.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := types.CanonicalJSONSorted
+	if amino {
+		mode = types.CanonicalJSONAmino
+	}
+	bz := []byte(res.JSON)
+	switch typ {
+	case "block":
+		block := new(types.Block)
+		if err := types.UnmarshalCanonicalJSON(bz, block, mode); err != nil {
+			return nil, err
+		}
+		if bh, lh := block.Hash(), l.Hash(); !bytes.Equal(bh, lh) {
+			return nil, fmt.Errorf("block header %X does not match with trusted header %X", bh, lh)
+		}
+	case "header":
+		header := new(types.Header)
+		if err := types.UnmarshalCanonicalJSON(bz, header, mode); err != nil {
+			return nil, err
+		}
+		if hh, lh := header.Hash(), l.Hash(); !bytes.Equal(hh, lh) {
+			return nil, fmt.Errorf("header %X does not match with trusted header %X", hh, lh)
+		}
+	case "commit":
+		// the commit may differ from the one of the light block, as long as it
+		// is signed by the validators
+		commit := new(types.Commit)
+		if err := types.UnmarshalCanonicalJSON(bz, commit, mode); err != nil {
+			return nil, err
+		}
+		if err := l.ValidatorSet.VerifyCommitLight(c.lc.ChainID(), l.Commit.BlockID, res.Height, commit); err != nil {
+			return nil, err
+		}
+	case "vote":
+		vote := new(types.Vote)
+		if err := types.UnmarshalCanonicalJSON(bz, vote, mode); err != nil {
+			return nil, err
+		}
+		if vote.Height != res.Height {
+			return nil, fmt.Errorf("expected a vote at height %d, got %d", res.Height, vote.Height)
+		}
+		_, val := l.ValidatorSet.GetByIndex(vote.ValidatorIndex)
+		if val == nil {
+			return nil, fmt.Errorf("no validator %d at height %d", vote.ValidatorIndex, res.Height)
+		}
+		if err := vote.Verify(c.lc.ChainID(), val.PubKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+
+	return res, nil
+}
+
 // Tx calls rpcclient#Tx method and then verifies the proof if such was
 // requested.
 func (c *Client) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
@@ -531,10 +622,75 @@ func (c *Client) Validators(
 		Total:       totalCount}, nil
 }
 
+// ValidatorsDiff computes the diff between the validator sets of the light
+// blocks at the given heights.
+func (c *Client) ValidatorsDiff(
+	ctx context.Context,
+	from int64,
+	height *int64,
+) (*ctypes.ResultValidatorsDiff, error) {
+	l, err := c.updateLightClientIfNeededTo(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	if from <= 0 {
+		return nil, errNegOrZeroHeight
+	}
+	lFrom, err := c.updateLightClientIfNeededTo(ctx, &from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctypes.ResultValidatorsDiff{
+		FromHeight:  from,
+		BlockHeight: l.Height,
+		Diff:        *lFrom.ValidatorSet.ComputeDiff(l.ValidatorSet),
+		Total:       l.ValidatorSet.Size()}, nil
+}
+
 func (c *Client) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
 	return c.next.BroadcastEvidence(ctx, ev)
 }
 
+// LightClientAttacks calls rpcclient#LightClientAttacks. The evidence is self
+// contained and therefore forwarded as is.
+func (c *Client) LightClientAttacks(
+	ctx context.Context,
+	minHeight,
+	maxHeight int64,
+) (*ctypes.ResultLightClientAttacks, error) {
+	return c.next.LightClientAttacks(ctx, minHeight, maxHeight)
+}
+
+// Snapshots calls rpcclient#Snapshots, the snapshots of the node not being
+// verifiable.
+func (c *Client) Snapshots(ctx context.Context) (*ctypes.ResultSnapshots, error) {
+	return c.next.Snapshots(ctx)
+}
+
+// PruningStatus calls rpcclient#PruningStatus, the status of the pruning of
+// the node not being verifiable.
+func (c *Client) PruningStatus(ctx context.Context) (*ctypes.ResultPruningStatus, error) {
+	return c.next.PruningStatus(ctx)
+}
+
+// CrawlStats calls rpcclient#CrawlStats, the statistics of the crawl of the
+// node not being verifiable.
+func (c *Client) CrawlStats(ctx context.Context) (*ctypes.ResultCrawlStats, error) {
+	return c.next.CrawlStats(ctx)
+}
+
+// EvidenceSearch calls rpcclient#EvidenceSearch. The evidence is self contained
+// and therefore forwarded as is.
+func (c *Client) EvidenceSearch(
+	ctx context.Context,
+	validator []byte,
+	from,
+	to int64,
+) (*ctypes.ResultEvidenceSearch, error) {
+	return c.next.EvidenceSearch(ctx, validator, from, to)
+}
+
 func (c *Client) Subscribe(ctx context.Context, subscriber, query string,
 	outCapacity ...int) (out <-chan ctypes.ResultEvent, err error) {
 	return c.next.Subscribe(ctx, subscriber, query, outCapacity...)
@@ -569,8 +725,9 @@ func (c *Client) RegisterOpDecoder(typ string, dec merkle.OpDecoder) {
 }
 
 // SubscribeWS subscribes for events using the given query and remote address as
-// a subscriber, but does not verify responses (UNSAFE)!
-// TODO: verify data
+// a subscriber. NewBlockHeader and NewBlock events are verified against the
+// light client before being forwarded; events which fail the verification are
+// dropped. Other events are forwarded without verification (UNSAFE)!
 func (c *Client) SubscribeWS(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
 	out, err := c.next.Subscribe(context.Background(), ctx.RemoteAddr(), query)
 	if err != nil {
@@ -580,9 +737,15 @@ func (c *Client) SubscribeWS(ctx *rpctypes.Context, query string) (*ctypes.Resul
 	go func() {
 		for {
 			select {
-			case resultEvent := <-out:
-				// We should have a switch here that performs a validation
-				// depending on the event's type.
+			case resultEvent, ok := <-out:
+				if !ok {
+					return
+				}
+				if err := c.verifyEvent(context.Background(), resultEvent); err != nil {
+					c.Logger.Error("Dropping event which failed verification",
+						"query", resultEvent.Query, "err", err)
+					continue
+				}
 				ctx.WSConn.TryWriteRPCResponse(
 					rpctypes.NewRPCSuccessResponse(
 						rpctypes.JSONRPCStringID(fmt.Sprintf("%v#event", ctx.JSONReq.ID)),
@@ -597,6 +760,38 @@ func (c *Client) SubscribeWS(ctx *rpctypes.Context, query string) (*ctypes.Resul
 	return &ctypes.ResultSubscribe{}, nil
 }
 
+// verifyEvent verifies the header carried by the event against the light
+// client's trusted store, if the event type is supported.
+func (c *Client) verifyEvent(ctx context.Context, resultEvent ctypes.ResultEvent) error {
+	var header *types.Header
+	switch data := resultEvent.Data.(type) {
+	case types.EventDataNewBlockHeader:
+		header = &data.Header
+	case types.EventDataNewBlock:
+		if data.Block == nil {
+			return errors.New("nil block")
+		}
+		if err := data.Block.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid block: %w", err)
+		}
+		header = &data.Block.Header
+	default:
+		return nil
+	}
+
+	if header.Height <= 0 {
+		return errNegOrZeroHeight
+	}
+	l, err := c.updateLightClientIfNeededTo(ctx, &header.Height)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(l.Hash(), header.Hash()) {
+		return fmt.Errorf("header %X does not match with trusted header %X", header.Hash(), l.Hash())
+	}
+	return nil
+}
+
 // UnsubscribeWS calls original client's Unsubscribe using remote address as a
 // subscriber.
 func (c *Client) UnsubscribeWS(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	lcmock "github.com/Finschia/ostracon/light/rpc/mocks"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestVerifyEvent(t *testing.T) {
+	vals, _ := types.RandValidatorSet(1, 10)
+	header := types.Header{
+		ChainID:        "test",
+		Height:         5,
+		ValidatorsHash: vals.Hash(),
+		AppHash:        tmhash.Sum([]byte("app_hash")),
+	}
+	forged := header
+	forged.AppHash = tmhash.Sum([]byte("forged"))
+
+	lc := &lcmock.LightClient{}
+	lc.On("VerifyLightBlockAtHeight", mock.Anything, int64(5), mock.Anything).Return(
+		&types.LightBlock{SignedHeader: &types.SignedHeader{Header: &header}, ValidatorSet: vals}, nil)
+	c := NewClient(nil, lc)
+
+	testCases := []struct {
+		name   string
+		data   types.OCEventData
+		expErr bool
+	}{
+		{"verified header", types.EventDataNewBlockHeader{Header: header}, false},
+		{"forged header", types.EventDataNewBlockHeader{Header: forged}, true},
+		{"zero height", types.EventDataNewBlockHeader{Header: types.Header{}}, true},
+		{"nil block", types.EventDataNewBlock{}, true},
+		{"unverified event type", types.EventDataTx{}, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := c.verifyEvent(context.Background(), ctypes.ResultEvent{Data: tc.data})
+			if tc.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -98,6 +98,20 @@ func VerifyAdjacent(
 	now time.Time,
 	maxClockDrift time.Duration) error {
 
+	return verifyAdjacent(trustedHeader, untrustedHeader, untrustedVals, trustingPeriod, now, maxClockDrift, nil)
+}
+
+// verifyAdjacent is VerifyAdjacent, adding the commit signatures to verify to
+// sigs instead of verifying them if it's not nil.
+func verifyAdjacent(
+	trustedHeader *types.SignedHeader, // height=X
+	untrustedHeader *types.SignedHeader, // height=X+1
+	untrustedVals *types.ValidatorSet, // height=X+1
+	trustingPeriod time.Duration,
+	now time.Time,
+	maxClockDrift time.Duration,
+	sigs *types.SignatureBatch) error {
+
 	if untrustedHeader.Height != trustedHeader.Height+1 {
 		return errors.New("headers must be adjacent in height")
 	}
@@ -123,8 +137,15 @@ func VerifyAdjacent(
 	}
 
 	// Ensure that +2/3 of new validators signed correctly.
-	if err := untrustedVals.VerifyCommitLight(trustedHeader.ChainID, untrustedHeader.Commit.BlockID,
-		untrustedHeader.Height, untrustedHeader.Commit); err != nil {
+	var err error
+	if sigs != nil {
+		err = untrustedVals.AddCommitLightSignatures(trustedHeader.ChainID, untrustedHeader.Commit.BlockID,
+			untrustedHeader.Height, untrustedHeader.Commit, sigs)
+	} else {
+		err = untrustedVals.VerifyCommitLight(trustedHeader.ChainID, untrustedHeader.Commit.BlockID,
+			untrustedHeader.Height, untrustedHeader.Commit)
+	}
+	if err != nil {
 		return ErrInvalidHeader{err}
 	}
 
@@ -150,6 +171,39 @@ func Verify(
 	return VerifyAdjacent(trustedHeader, untrustedHeader, untrustedVals, trustingPeriod, now, maxClockDrift)
 }
 
+// VerifyEntropy verifies the Ostracon-specific entropy (VRF proof and round)
+// of untrustedBlock against the entropy of the previous block. It ensures
+// that:
+//
+//	a) untrustedBlock.ProposerAddress is the proposer elected using the VRF
+//	   output of lastEntropy
+//	b) entropy.Proof is a valid VRF proof generated by that proposer
+//
+// Since the entropy is not included in the header hash, untrustedBlock must
+// be verified by one of the functions above first. If any check fails,
+// ErrInvalidEntropy is returned.
+func VerifyEntropy(
+	untrustedBlock *types.LightBlock, // height=X
+	entropy *types.Entropy, // height=X
+	lastEntropy *types.Entropy, // height=X-1
+) error {
+	if entropy == nil || lastEntropy == nil {
+		return ErrInvalidEntropy{untrustedBlock.Height, errors.New("nil entropy")}
+	}
+
+	lastProofHash, err := lastEntropy.ProofHash()
+	if err != nil {
+		return ErrInvalidEntropy{untrustedBlock.Height, fmt.Errorf("invalid last proof: %w", err)}
+	}
+
+	if _, err := types.VerifyEntropy(*entropy, untrustedBlock.ValidatorSet, lastProofHash,
+		untrustedBlock.Height-1, untrustedBlock.Height, untrustedBlock.ProposerAddress); err != nil {
+		return ErrInvalidEntropy{untrustedBlock.Height, err}
+	}
+
+	return nil
+}
+
 func verifyNewHeaderAndVals(
 	untrustedHeader *types.SignedHeader,
 	untrustedVals *types.ValidatorSet,
@@ -1,6 +1,7 @@
 package light_test
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 	"time"
@@ -305,6 +306,62 @@ func TestVerifyReturnsErrorIfTrustLevelIsInvalid(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestVerifyEntropy(t *testing.T) {
+	const chainID = "TestVerifyEntropy"
+
+	var (
+		keys     = genPrivKeys(4)
+		vals     = keys.ToValidators(20, 10)
+		bTime, _ = time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
+
+		lastEntropy       = genInitialEntropy()
+		entropy, proposer = keys.genEntropy(vals, lastEntropy, 2, 1)
+		otherEntropy      = genInitialEntropy()
+	)
+
+	genLightBlock := func(proposer types.Address) *types.LightBlock {
+		header := keys.GenSignedHeader(chainID, 2, bTime, nil, vals, vals,
+			hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(keys))
+		header.ProposerAddress = proposer
+		return &types.LightBlock{SignedHeader: header, ValidatorSet: vals}
+	}
+
+	var notProposer types.Address
+	for _, val := range vals.Validators {
+		if !bytes.Equal(val.Address, proposer) {
+			notProposer = val.Address
+			break
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		lb          *types.LightBlock
+		entropy     *types.Entropy
+		lastEntropy *types.Entropy
+		expErr      bool
+	}{
+		{"valid", genLightBlock(proposer), entropy, lastEntropy, false},
+		{"nil entropy", genLightBlock(proposer), nil, lastEntropy, true},
+		{"wrong proposer", genLightBlock(notProposer), entropy, lastEntropy, true},
+		{"proof not generated by the proposer", genLightBlock(proposer), otherEntropy, lastEntropy, true},
+		{"wrong round", genLightBlock(proposer), &types.Entropy{Round: 2, Proof: entropy.Proof}, lastEntropy, true},
+		{"wrong last entropy", genLightBlock(proposer), entropy, otherEntropy, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := light.VerifyEntropy(tc.lb, tc.entropy, tc.lastEntropy)
+			if tc.expErr {
+				assert.IsType(t, light.ErrInvalidEntropy{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestValidateTrustLevel(t *testing.T) {
 	testCases := []struct {
 		lvl   tmmath.Fraction
@@ -62,11 +62,15 @@ func (c *LRUTxCache) Reset() {
 }
 
 func (c *LRUTxCache) Push(tx types.Tx) bool {
+	return c.PushKey(tx.Key())
+}
+
+// PushKey adds the key of a raw transaction to the cache and returns true if
+// it was newly added, like Push.
+func (c *LRUTxCache) PushKey(key types.TxKey) bool {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
-	key := tx.Key()
-
 	moved, ok := c.cacheMap[key]
 	if ok {
 		c.list.MoveToBack(moved)
@@ -109,6 +113,19 @@ func (c *LRUTxCache) Has(tx types.Tx) bool {
 	return ok
 }
 
+// Keys returns the keys of the transactions in the cache, from the least
+// recently used.
+func (c *LRUTxCache) Keys() []types.TxKey {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	keys := make([]types.TxKey, 0, c.list.Len())
+	for e := c.list.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(types.TxKey))
+	}
+	return keys
+}
+
 // NopTxCache defines a no-op raw transaction cache.
 type NopTxCache struct{}
 
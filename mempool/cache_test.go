@@ -51,3 +51,14 @@ func TestCacheRemove(t *testing.T) {
 		require.Equal(t, numTxs-(i+1), cache.list.Len())
 	}
 }
+
+func TestCacheKeys(t *testing.T) {
+	cache := NewLRUTxCache(2)
+	require.True(t, cache.Push(types.Tx{0}))
+	require.True(t, cache.PushKey(types.Tx{1}.Key()))
+	require.False(t, cache.PushKey(types.Tx{0}.Key())) // MoveToBack
+	require.Equal(t, []types.TxKey{types.Tx{1}.Key(), types.Tx{0}.Key()}, cache.Keys())
+	require.True(t, cache.PushKey(types.Tx{2}.Key())) // Cache out
+	require.Equal(t, []types.TxKey{types.Tx{0}.Key(), types.Tx{2}.Key()}, cache.Keys())
+	require.True(t, cache.Has(types.Tx{2}))
+}
@@ -0,0 +1,175 @@
+package mempool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ocabci "github.com/Finschia/ostracon/abci/types"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/p2p"
+	ocmempl "github.com/Finschia/ostracon/proto/ostracon/mempool"
+	"github.com/Finschia/ostracon/types"
+)
+
+// haveTxInterval is the interval between the announcements of the txs received
+// to the peers, batching them.
+const haveTxInterval = 10 * time.Millisecond
+
+// HaveTxGossip announces the txs received to the peers on MempoolHaveTxChannel
+// and remembers the txs known by each peer, not to send them to it again. It's
+// shared by the mempool reactors, a nil HaveTxGossip being disabled.
+type HaveTxGossip struct {
+	filters  *PeerTxFilters   // txs known by peer
+	haveTxCh chan types.TxKey // txs received to be announced
+}
+
+// NewHaveTxGossip returns the gossip remembering at least the last filterSize
+// txs known by each peer, or nil if filterSize isn't positive.
+func NewHaveTxGossip(filterSize int) *HaveTxGossip {
+	if filterSize <= 0 {
+		return nil
+	}
+	return &HaveTxGossip{
+		filters:  NewPeerTxFilters(filterSize),
+		haveTxCh: make(chan types.TxKey, MaxHaveTxKeys),
+	}
+}
+
+// ChannelDescriptor returns the descriptor of MempoolHaveTxChannel.
+func (g *HaveTxGossip) ChannelDescriptor() *p2p.ChannelDescriptor {
+	haveTxMsg := ocmempl.Message{
+		Sum: &ocmempl.Message_HaveTx{
+			HaveTx: &ocmempl.HaveTx{Keys: make([][]byte, MaxHaveTxKeys)},
+		},
+	}
+	for i := range haveTxMsg.GetHaveTx().Keys {
+		haveTxMsg.GetHaveTx().Keys[i] = make([]byte, types.TxKeySize)
+	}
+	return &p2p.ChannelDescriptor{
+		ID:                  MempoolHaveTxChannel,
+		Priority:            5,
+		RecvMessageCapacity: haveTxMsg.Size(),
+		MessageType:         &ocmempl.Message{},
+		// the announcements only save sending the txs known by the peers
+		RecvQueueDrop: true,
+	}
+}
+
+// Received remembers the txs received from the peer as known by it.
+func (g *HaveTxGossip) Received(id p2p.ID, txs [][]byte) {
+	if g == nil {
+		return
+	}
+	for _, tx := range txs {
+		g.filters.Add(id, types.Tx(tx).Key())
+	}
+}
+
+// ReceiveHaveTx remembers the txs announced by the peer as known by it. The
+// keys must have been validated with ValidateHaveTx.
+func (g *HaveTxGossip) ReceiveHaveTx(id p2p.ID, keys [][]byte) {
+	if g == nil {
+		return
+	}
+	for _, key := range keys {
+		g.filters.Add(id, types.TxKey(key))
+	}
+}
+
+// Sent remembers the tx sent to the peer as known by it.
+func (g *HaveTxGossip) Sent(id p2p.ID, key types.TxKey) {
+	if g == nil {
+		return
+	}
+	g.filters.Add(id, key)
+}
+
+// Knows reports whether the peer is known to have the tx.
+func (g *HaveTxGossip) Knows(id p2p.ID, key types.TxKey) bool {
+	return g != nil && g.filters.Has(id, key)
+}
+
+// RemovePeer forgets the txs known by the peer.
+func (g *HaveTxGossip) RemovePeer(id p2p.ID) {
+	if g == nil {
+		return
+	}
+	g.filters.Remove(id)
+}
+
+// AnnounceCb returns the callback of the CheckTx of a tx received from a peer,
+// queueing it to be announced to the other peers if it's added to the mempool
+// (nil if the announcements are disabled).
+func (g *HaveTxGossip) AnnounceCb(key types.TxKey) func(*ocabci.Response) {
+	if g == nil {
+		return nil
+	}
+	return func(res *ocabci.Response) {
+		r := res.GetCheckTx()
+		if r == nil || r.Code != ocabci.CodeTypeOK || r.MempoolError != "" {
+			return
+		}
+		select {
+		case g.haveTxCh <- key:
+		default:
+			// the announcements only save bandwidth, they can be dropped
+		}
+	}
+}
+
+// AnnounceTxsRoutine announces the txs received to the peers not known to have
+// them, in batches, for them not to send them back, until quit is closed.
+func (g *HaveTxGossip) AnnounceTxsRoutine(peers func() []p2p.Peer, quit <-chan struct{}, logger log.Logger) {
+	ticker := time.NewTicker(haveTxInterval)
+	defer ticker.Stop()
+
+	var keys []types.TxKey
+	for {
+		select {
+		case key := <-g.haveTxCh:
+			keys = append(keys, key)
+			if len(keys) < MaxHaveTxKeys {
+				continue
+			}
+		case <-ticker.C:
+			if len(keys) == 0 {
+				continue
+			}
+		case <-quit:
+			return
+		}
+
+		for _, peer := range peers() {
+			msg := &ocmempl.HaveTx{}
+			for i := range keys {
+				if !g.filters.Has(peer.ID(), keys[i]) {
+					msg.Keys = append(msg.Keys, keys[i][:])
+				}
+			}
+			if len(msg.Keys) > 0 {
+				p2p.TrySendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
+					ChannelID: MempoolHaveTxChannel,
+					Message:   msg,
+				}, logger)
+			}
+		}
+		keys = nil
+	}
+}
+
+// ValidateHaveTx returns an error if the keys of an announcement are invalid.
+func ValidateHaveTx(keys [][]byte) error {
+	if len(keys) == 0 {
+		return errors.New("no keys")
+	}
+	if len(keys) > MaxHaveTxKeys {
+		return fmt.Errorf("too many keys: %d > %d", len(keys), MaxHaveTxKeys)
+	}
+	for _, key := range keys {
+		if len(key) != types.TxKeySize {
+			return fmt.Errorf("invalid key size: %d != %d", len(key), types.TxKeySize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ocabci "github.com/Finschia/ostracon/abci/types"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestHaveTxGossip(t *testing.T) {
+	var disabled *HaveTxGossip
+	require.Nil(t, NewHaveTxGossip(0))
+	disabled.Received("peer", [][]byte{[]byte("tx")})
+	disabled.Sent("peer", types.Tx("tx").Key())
+	assert.False(t, disabled.Knows("peer", types.Tx("tx").Key()))
+	assert.Nil(t, disabled.AnnounceCb(types.Tx("tx").Key()))
+
+	g := NewHaveTxGossip(100)
+	require.NotNil(t, g)
+	const peer = p2p.ID("peer")
+
+	g.Received(peer, [][]byte{[]byte("received")})
+	assert.True(t, g.Knows(peer, types.Tx("received").Key()))
+	g.Sent(peer, types.Tx("sent").Key())
+	assert.True(t, g.Knows(peer, types.Tx("sent").Key()))
+	key := randTxKey(t)
+	g.ReceiveHaveTx(peer, [][]byte{key[:]})
+	assert.True(t, g.Knows(peer, key))
+	assert.False(t, g.Knows("other", key))
+
+	g.RemovePeer(peer)
+	assert.False(t, g.Knows(peer, key))
+
+	// only the txs added to the mempool are announced
+	key = randTxKey(t)
+	g.AnnounceCb(key)(ocabci.ToResponseCheckTx(ocabci.ResponseCheckTx{Code: 1}))
+	require.Len(t, g.haveTxCh, 0)
+	g.AnnounceCb(key)(ocabci.ToResponseCheckTx(ocabci.ResponseCheckTx{Code: ocabci.CodeTypeOK}))
+	require.Len(t, g.haveTxCh, 1)
+	assert.Equal(t, key, <-g.haveTxCh)
+}
+
+func TestValidateHaveTx(t *testing.T) {
+	key := randTxKey(t)
+	assert.NoError(t, ValidateHaveTx([][]byte{key[:]}))
+	assert.Error(t, ValidateHaveTx(nil))
+	assert.Error(t, ValidateHaveTx([][]byte{key[:1]}))
+	assert.Error(t, ValidateHaveTx(make([][]byte, MaxHaveTxKeys+1)))
+}
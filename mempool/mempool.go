@@ -15,6 +15,18 @@ import (
 const (
 	MempoolChannel = byte(0x30)
 
+	// MempoolHaveTxChannel is the channel of the announcements of the txs the
+	// peers have, apart from MempoolChannel for the peers without it not to
+	// receive them
+	MempoolHaveTxChannel = byte(0x32)
+
+	// MaxHaveTxKeys is the maximum number of tx keys announced in a message
+	MaxHaveTxKeys = 1000
+
+	// MaxCheckTxBatchSize is the maximum number of txs checked in one call to
+	// the app (see mempool.check_tx_batch_window)
+	MaxCheckTxBatchSize = 1000
+
 	// PeerCatchupSleepIntervalMS defines how much time to sleep if a peer is behind
 	PeerCatchupSleepIntervalMS = 100
 
@@ -55,6 +67,14 @@ type Mempool interface {
 	// (~ all available transactions).
 	ReapMaxTxs(max int) types.Txs
 
+	// GetPendingTx returns the transaction of the mempool identified by its
+	// key, and false if it isn't in the mempool.
+	GetPendingTx(txKey types.TxKey) (PendingTx, bool)
+
+	// PendingTxs returns all the transactions of the mempool, in the order
+	// they're reaped.
+	PendingTxs() []PendingTx
+
 	// Lock locks the mempool. The consensus must be able to hold lock to safely
 	// update.
 	Lock()
@@ -3,8 +3,8 @@ package mempool
 import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 )
 
 const (
@@ -37,6 +37,10 @@ type Metrics struct {
 	// CheckTx.
 	EvictedTxs metrics.Counter
 
+	// Number of transactions evicted for not being committed within their TTL
+	// (see the ttl-duration and ttl-num-blocks of the mempool configuration).
+	ExpiredTxs metrics.Counter
+
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
 	// Time of recheck transactions in the mempool.
@@ -47,47 +51,61 @@ type Metrics struct {
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		Size: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Size: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "size",
 			Help:      "Size of the mempool (number of uncommitted transactions).",
 		}, labels).With(labelsAndValues...),
 
-		TxSizeBytes: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		TxSizeBytes: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "tx_size_bytes",
 			Help:      "Transaction sizes in bytes.",
-			Buckets:   stdprometheus.ExponentialBuckets(1, 3, 17),
+			Buckets:   tmmetrics.ExponentialBuckets(1, 3, 17),
 		}, labels).With(labelsAndValues...),
 
-		FailedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		FailedTxs: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "failed_txs",
 			Help:      "Number of failed transactions.",
 		}, labels).With(labelsAndValues...),
-		RejectedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		RejectedTxs: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "rejected_txs",
 			Help:      "Number of rejected transactions.",
 		}, labels).With(labelsAndValues...),
 
-		EvictedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		EvictedTxs: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "evicted_txs",
 			Help:      "Number of evicted transactions.",
 		}, labels).With(labelsAndValues...),
 
-		RecheckTimes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		ExpiredTxs: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "expired_txs",
+			Help:      "Number of transactions evicted for outliving their TTL.",
+		}, labels).With(labelsAndValues...),
+
+		RecheckTimes: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "recheck_times",
@@ -95,7 +113,7 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 		}, labels).With(labelsAndValues...),
 
 		// Add by Ostracon
-		RecheckTime: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		RecheckTime: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "recheck_time",
@@ -112,6 +130,7 @@ func NopMetrics() *Metrics {
 		FailedTxs:    discard.NewCounter(),
 		RejectedTxs:  discard.NewCounter(),
 		EvictedTxs:   discard.NewCounter(),
+		ExpiredTxs:   discard.NewCounter(),
 		RecheckTimes: discard.NewCounter(),
 		RecheckTime:  discard.NewGauge(),
 	}
@@ -26,6 +26,10 @@ func (Mempool) RemoveTxByKey(txKey types.TxKey) error            { return nil }
 func (Mempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs          { return types.Txs{} }
 func (Mempool) ReapMaxBytesMaxGasMaxTxs(_, _, _ int64) types.Txs { return types.Txs{} }
 func (Mempool) ReapMaxTxs(n int) types.Txs                       { return types.Txs{} }
+func (Mempool) GetPendingTx(_ types.TxKey) (mempool.PendingTx, bool) {
+	return mempool.PendingTx{}, false
+}
+func (Mempool) PendingTxs() []mempool.PendingTx { return nil }
 func (Mempool) Update(
 	_ *types.Block,
 	_ []*abci.ResponseDeliverTx,
@@ -0,0 +1,101 @@
+package mempool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+
+	"github.com/Finschia/ostracon/libs/protoio"
+	"github.com/Finschia/ostracon/libs/tempfile"
+	"github.com/Finschia/ostracon/types"
+)
+
+// TxCacheMempool is a mempool whose cache of the txs already seen can be
+// saved and restored, e.g. across restarts (see mempool.persist-to-disk).
+type TxCacheMempool interface {
+	// CacheKeys returns the keys of the txs in the cache, from the least
+	// recently used.
+	CacheKeys() []types.TxKey
+
+	// RestoreCacheKeys adds the keys to the cache, as if their txs were seen
+	// in this order.
+	RestoreCacheKeys(keys []types.TxKey)
+}
+
+// SaveTxs writes to the file the txs of the mempool, in the order they're
+// reaped, and the keys of its cache if it's a TxCacheMempool. The file is
+// replaced atomically.
+//
+// The mempool must not be updated meanwhile, e.g. the consensus must be
+// stopped.
+func SaveTxs(mp Mempool, file string) error {
+	pending := mp.PendingTxs()
+	txs := &protomem.Txs{Txs: make([][]byte, len(pending))}
+	for i, tx := range pending {
+		txs.Txs[i] = tx.Tx
+	}
+	keys := &protomem.Txs{}
+	if cacheMp, ok := mp.(TxCacheMempool); ok {
+		for _, key := range cacheMp.CacheKeys() {
+			key := key
+			keys.Txs = append(keys.Txs, key[:])
+		}
+	}
+	return tempfile.WriteFileAtomicFunc(file, 0600, func(w io.Writer) error {
+		pw := protoio.NewDelimitedWriter(w)
+		if _, err := pw.WriteMsg(txs); err != nil {
+			return err
+		}
+		_, err := pw.WriteMsg(keys)
+		return err
+	})
+}
+
+// LoadTxs re-checks the txs saved to the file by SaveTxs with the app, adding
+// the ones still valid to the mempool, restores the cache of the mempool if
+// it's a TxCacheMempool, then removes the file not to load the txs again. It
+// returns the number of txs added, 0 if there's no file.
+func LoadTxs(mp Mempool, file string) (int, error) {
+	bz, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var txs, keys protomem.Txs
+	pr := protoio.NewDelimitedReader(bytes.NewReader(bz), len(bz))
+	if _, err := pr.ReadMsg(&txs); err != nil {
+		return 0, fmt.Errorf("reading the txs of %s: %w", file, err)
+	}
+	if _, err := pr.ReadMsg(&keys); err != nil {
+		return 0, fmt.Errorf("reading the cache of %s: %w", file, err)
+	}
+
+	// the txs rejected, no longer valid or not fitting in the mempool, are
+	// dropped
+	size := mp.Size()
+	for _, tx := range txs.Txs {
+		_ = mp.CheckTxSync(tx, nil, TxInfo{SenderID: UnknownPeerID})
+	}
+	added := mp.Size() - size
+
+	if cacheMp, ok := mp.(TxCacheMempool); ok {
+		cacheKeys := make([]types.TxKey, 0, len(keys.Txs))
+		for _, key := range keys.Txs {
+			if len(key) != len(types.TxKey{}) {
+				return added, fmt.Errorf("wrong tx key %X in %s", key, file)
+			}
+			var txKey types.TxKey
+			copy(txKey[:], key)
+			cacheKeys = append(cacheKeys, txKey)
+		}
+		cacheMp.RestoreCacheKeys(cacheKeys)
+	}
+
+	return added, os.Remove(file)
+}
@@ -1,7 +1,10 @@
 package mempool
 
 import (
+	"time"
+
 	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/types"
 )
 
 // TxInfo are parameters that get passed when attempting to add a tx to the
@@ -15,3 +18,19 @@ type TxInfo struct {
 	// SenderP2PID is the actual p2p.ID of the sender, used e.g. for logging.
 	SenderP2PID p2p.ID
 }
+
+// PendingTx is a transaction waiting in the mempool to be committed, with the
+// metadata the mempool keeps about it.
+type PendingTx struct {
+	Tx types.Tx
+	// Height is the height of the last block when the tx was checked.
+	Height int64
+	// Timestamp is the time the tx was added to the mempool (or revalidated
+	// with mempool.ttl-duration).
+	Timestamp time.Time
+	// GasWanted is the gas the tx requires, as returned by CheckTx.
+	GasWanted int64
+	// Priority is the priority of the tx, as returned by CheckTx (always 0 in
+	// the FIFO mempool).
+	Priority int64
+}
@@ -0,0 +1,134 @@
+package mempool
+
+import (
+	"encoding/binary"
+	"math"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	// number of hashes of a key in a filter, and bits by key of a generation,
+	// for a false positive rate of at most about 2% for the two generations
+	txFilterHashes     = 7
+	txFilterBitsPerKey = 9.6
+)
+
+// txFilter is a bloom filter of the tx keys, remembering at least the last
+// size keys added: the keys are added to the current generation, which
+// replaces the previous one once it has size keys.
+//
+// As the keys are sha256 hashes, the hashes of a key in the filter are derived
+// from its bytes.
+type txFilter struct {
+	size int
+	bits uint64 // of a generation
+	cur  []uint64
+	prev []uint64
+	n    int // keys in cur
+}
+
+func newTxFilter(size int) *txFilter {
+	words := int(math.Ceil(float64(size) * txFilterBitsPerKey / 64))
+	return &txFilter{
+		size: size,
+		bits: uint64(words) * 64,
+		cur:  make([]uint64, words),
+		prev: make([]uint64, words),
+	}
+}
+
+// add adds the key to the filter.
+func (f *txFilter) add(key types.TxKey) {
+	if has(f.cur, f.bits, key) {
+		return
+	}
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < txFilterHashes; i++ {
+		b := (h1 + i*h2) % f.bits
+		f.cur[b/64] |= 1 << (b % 64)
+	}
+	f.n++
+	if f.n >= f.size {
+		f.prev, f.cur = f.cur, f.prev
+		for i := range f.cur {
+			f.cur[i] = 0
+		}
+		f.n = 0
+	}
+}
+
+// has reports whether the key was added to the filter, with false positives.
+func (f *txFilter) has(key types.TxKey) bool {
+	return has(f.cur, f.bits, key) || has(f.prev, f.bits, key)
+}
+
+func has(gen []uint64, bits uint64, key types.TxKey) bool {
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < txFilterHashes; i++ {
+		b := (h1 + i*h2) % bits
+		if gen[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hashes returns the two hashes of the key combined into the ones of the
+// filter (h2 being odd, the hashes don't repeat).
+func hashes(key types.TxKey) (h1, h2 uint64) {
+	return binary.LittleEndian.Uint64(key[0:8]), binary.LittleEndian.Uint64(key[8:16]) | 1
+}
+
+//-----------------------------------------------------------------------------
+
+// PeerTxFilters remembers the txs known by each peer, sent by it, sent to it
+// or announced by it, not to send them to it again. The txs are remembered in
+// bloom filters of the last txs of each peer, so that a few txs not known by a
+// peer may be reported as known (it receives them from the other peers).
+type PeerTxFilters struct {
+	mtx     tmsync.Mutex
+	size    int
+	filters map[p2p.ID]*txFilter
+}
+
+// NewPeerTxFilters returns the filters remembering at least the last size txs
+// of each peer.
+func NewPeerTxFilters(size int) *PeerTxFilters {
+	return &PeerTxFilters{
+		size:    size,
+		filters: make(map[p2p.ID]*txFilter),
+	}
+}
+
+// Add remembers the tx of the key as known by the peer.
+func (pf *PeerTxFilters) Add(id p2p.ID, key types.TxKey) {
+	pf.mtx.Lock()
+	defer pf.mtx.Unlock()
+
+	f, ok := pf.filters[id]
+	if !ok {
+		f = newTxFilter(pf.size)
+		pf.filters[id] = f
+	}
+	f.add(key)
+}
+
+// Has reports whether the tx of the key is known by the peer.
+func (pf *PeerTxFilters) Has(id p2p.ID, key types.TxKey) bool {
+	pf.mtx.Lock()
+	defer pf.mtx.Unlock()
+
+	f, ok := pf.filters[id]
+	return ok && f.has(key)
+}
+
+// Remove forgets the txs of the peer, e.g. when it's disconnected.
+func (pf *PeerTxFilters) Remove(id p2p.ID) {
+	pf.mtx.Lock()
+	defer pf.mtx.Unlock()
+
+	delete(pf.filters, id)
+}
@@ -0,0 +1,75 @@
+package mempool
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/types"
+)
+
+func randTxKey(t *testing.T) types.TxKey {
+	var key types.TxKey
+	_, err := rand.Read(key[:])
+	require.NoError(t, err)
+	return key
+}
+
+func TestTxFilter(t *testing.T) {
+	const size = 1000
+	f := newTxFilter(size)
+
+	keys := make([]types.TxKey, size)
+	for i := range keys {
+		keys[i] = randTxKey(t)
+		f.add(keys[i])
+	}
+	for _, key := range keys {
+		assert.True(t, f.has(key))
+	}
+
+	// the false positives are rare
+	falsePositives := 0
+	for i := 0; i < 10*size; i++ {
+		if f.has(randTxKey(t)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 10*size*3/100)
+
+	// the last size keys are remembered, the older ones are forgotten after two
+	// generations
+	for i := 0; i < size/2; i++ {
+		f.add(randTxKey(t))
+	}
+	for _, key := range keys[size/2:] {
+		assert.True(t, f.has(key))
+	}
+	for i := 0; i < 2*size; i++ {
+		f.add(randTxKey(t))
+	}
+	forgotten := 0
+	for _, key := range keys {
+		if !f.has(key) {
+			forgotten++
+		}
+	}
+	assert.Greater(t, forgotten, size*97/100)
+}
+
+func TestPeerTxFilters(t *testing.T) {
+	filters := NewPeerTxFilters(10)
+	peer1, peer2 := p2p.ID("peer1"), p2p.ID("peer2")
+	key := randTxKey(t)
+
+	assert.False(t, filters.Has(peer1, key))
+	filters.Add(peer1, key)
+	assert.True(t, filters.Has(peer1, key))
+	assert.False(t, filters.Has(peer2, key))
+
+	filters.Remove(peer1)
+	assert.False(t, filters.Has(peer1, key))
+}
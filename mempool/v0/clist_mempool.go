@@ -11,12 +11,16 @@ import (
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
+	abcicli "github.com/Finschia/ostracon/abci/client"
 	ocabci "github.com/Finschia/ostracon/abci/types"
 	"github.com/Finschia/ostracon/libs/clist"
 	"github.com/Finschia/ostracon/libs/log"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tracing"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/proxy"
 	"github.com/Finschia/ostracon/types"
@@ -73,6 +77,7 @@ type requestCheckTxAsync struct {
 }
 
 var _ mempool.Mempool = &CListMempool{}
+var _ mempool.TxCacheMempool = &CListMempool{}
 
 // CListMempoolOption sets an optional parameter on the mempool.
 type CListMempoolOption func(*CListMempool)
@@ -224,11 +229,16 @@ func (mem *CListMempool) CheckTxSync(
 	}
 
 	// CONTRACT: `app.CheckTxSync()` should check whether `GasWanted` is valid (0 <= GasWanted <= block.masGas)
+	span := tracing.StartTxSpan(tx.Key(), "check_tx")
 	var r *ocabci.ResponseCheckTx
 	r, err := mem.proxyAppConn.CheckTxSync(abci.RequestCheckTx{Tx: tx})
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
 		return err
 	}
+	span.SetAttributes(attribute.Int64("code", int64(r.Code)))
+	span.End()
 
 	res := ocabci.ToResponseCheckTx(*r)
 	mem.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, res, cb)
@@ -250,11 +260,81 @@ func (mem *CListMempool) CheckTxAsync(
 }
 
 func (mem *CListMempool) checkTxAsyncReactor() {
+	if mem.config.CheckTxBatchWindow > 0 {
+		for req := range mem.chReqCheckTx {
+			mem.checkTxBatchAsync(req)
+		}
+		return
+	}
 	for req := range mem.chReqCheckTx {
 		mem.checkTxAsync(req.tx, req.txInfo, req.prepareCb, req.checkTxCb)
 	}
 }
 
+// checkTxBatchAsync checks the txs requested within the batch window from the
+// first one in one call to the app. The batch holds a read lock of updateMtx
+// until all its txs are checked, so it blocks Update() for up to the window.
+func (mem *CListMempool) checkTxBatchAsync(first *requestCheckTxAsync) {
+	mem.updateMtx.RLock()
+	defer func() {
+		if r := recover(); r != nil {
+			mem.updateMtx.RUnlock()
+			panic(r)
+		}
+	}()
+
+	var batch []*requestCheckTxAsync
+	add := func(req *requestCheckTxAsync) {
+		err := mem.prepareCheckTx(req.tx, req.txInfo)
+		if req.prepareCb != nil {
+			req.prepareCb(err)
+		}
+		if err == nil {
+			batch = append(batch, req)
+		}
+	}
+	add(first)
+	timer := time.NewTimer(mem.config.CheckTxBatchWindow)
+	defer timer.Stop()
+BATCH:
+	for len(batch) < mempool.MaxCheckTxBatchSize {
+		select {
+		case req := <-mem.chReqCheckTx:
+			add(req)
+		case <-timer.C:
+			break BATCH
+		}
+	}
+	if len(batch) == 0 {
+		mem.updateMtx.RUnlock()
+		return
+	}
+
+	reqs := make([]abci.RequestCheckTx, len(batch))
+	cbs := make([]abcicli.ResponseCallback, len(batch))
+	pending := int32(len(batch))
+	for i, req := range batch {
+		req := req
+		span := tracing.StartTxSpan(req.tx.Key(), "check_tx")
+		reqs[i] = abci.RequestCheckTx{Tx: req.tx}
+		cbs[i] = func(res *ocabci.Response) {
+			if r := res.GetCheckTx(); r != nil {
+				span.SetAttributes(attribute.Int64("code", int64(r.Code)))
+			}
+			span.End()
+			mem.reqResCb(req.tx, req.txInfo.SenderID, req.txInfo.SenderP2PID, res, func(response *ocabci.Response) {
+				if req.checkTxCb != nil {
+					req.checkTxCb(response)
+				}
+				if atomic.AddInt32(&pending, -1) == 0 {
+					mem.updateMtx.RUnlock()
+				}
+			})
+		}
+	}
+	mem.proxyAppConn.CheckTxBatchAsync(reqs, cbs)
+}
+
 // It blocks if we're waiting on Update() or Reap().
 func (mem *CListMempool) checkTxAsync(
 	tx types.Tx,
@@ -280,7 +360,12 @@ func (mem *CListMempool) checkTxAsync(
 	}
 
 	// CONTRACT: `app.CheckTxAsync()` should check whether `GasWanted` is valid (0 <= GasWanted <= block.masGas)
+	span := tracing.StartTxSpan(tx.Key(), "check_tx")
 	mem.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{Tx: tx}, func(res *ocabci.Response) {
+		if r := res.GetCheckTx(); r != nil {
+			span.SetAttributes(attribute.Int64("code", int64(r.Code)))
+		}
+		span.End()
 		mem.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, res, func(response *ocabci.Response) {
 			if checkTxCb != nil {
 				checkTxCb(response)
@@ -406,6 +491,7 @@ func (mem *CListMempool) addTx(memTx *mempoolTx) {
 	mem.txsMap.Store(memTx.tx.Key(), e)
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
+	tracing.StartTxWait(memTx.tx.Key(), "mempool")
 }
 
 // Called from:
@@ -416,6 +502,7 @@ func (mem *CListMempool) removeTx(tx types.Tx, elem *clist.CElement, removeFromC
 	elem.DetachPrev()
 	mem.txsMap.Delete(tx.Key())
 	atomic.AddInt64(&mem.txsBytes, int64(-len(tx)))
+	tracing.UntrackTx(tx.Key())
 
 	if removeFromCache {
 		mem.cache.Remove(tx)
@@ -499,6 +586,7 @@ func (mem *CListMempool) resCbFirstTime(
 		if r.CheckTx.Code == ocabci.CodeTypeOK {
 			memTx := &mempoolTx{
 				height:    mem.height,
+				timestamp: time.Now(),
 				gasWanted: r.CheckTx.GasWanted,
 				tx:        tx,
 			}
@@ -521,6 +609,7 @@ func (mem *CListMempool) resCbFirstTime(
 				"res", r,
 			)
 			mem.metrics.FailedTxs.Add(1)
+			tracing.UntrackTx(types.Tx(tx).Key())
 
 			if !mem.config.KeepInvalidTxsInCache {
 				// remove from cache (it might be good later)
@@ -700,6 +789,56 @@ func (mem *CListMempool) ReapMaxTxs(max int) types.Txs {
 	return txs
 }
 
+// GetPendingTx returns the tx of the mempool with the key.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) GetPendingTx(txKey types.TxKey) (mempool.PendingTx, bool) {
+	mem.updateMtx.RLock()
+	defer mem.updateMtx.RUnlock()
+
+	e, ok := mem.txsMap.Load(txKey)
+	if !ok {
+		return mempool.PendingTx{}, false
+	}
+	return e.(*clist.CElement).Value.(*mempoolTx).pendingTx(), true
+}
+
+// PendingTxs returns all the txs of the mempool, by order of arrival.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) PendingTxs() []mempool.PendingTx {
+	mem.updateMtx.RLock()
+	defer mem.updateMtx.RUnlock()
+
+	txs := make([]mempool.PendingTx, 0, mem.txs.Len())
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		txs = append(txs, e.Value.(*mempoolTx).pendingTx())
+	}
+	return txs
+}
+
+// CacheKeys returns the keys of the txs in the cache, from the least recently
+// used, none if the cache is disabled.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) CacheKeys() []types.TxKey {
+	if cache, ok := mem.cache.(*mempool.LRUTxCache); ok {
+		return cache.Keys()
+	}
+	return nil
+}
+
+// RestoreCacheKeys adds the keys to the cache, unless it's disabled.
+//
+// Safe for concurrent use by multiple goroutines.
+func (mem *CListMempool) RestoreCacheKeys(keys []types.TxKey) {
+	if cache, ok := mem.cache.(*mempool.LRUTxCache); ok {
+		for _, key := range keys {
+			cache.PushKey(key)
+		}
+	}
+}
+
 // Lock() must be held by the caller during execution.
 func (mem *CListMempool) Update(
 	block *types.Block,
@@ -737,13 +876,18 @@ func (mem *CListMempool) Update(
 		// Mempool after:
 		//   100
 		// https://github.com/tendermint/tendermint/issues/3322.
+		tracing.UntrackTx(tx.Key(), attribute.Int64("height", block.Height))
 		if e, ok := mem.txsMap.Load(tx.Key()); ok {
 			mem.removeTx(tx, e.(*clist.CElement), false)
 		}
 	}
 
-	if mem.config.Recheck {
-		// recheck non-committed txs to see if they became invalid
+	// evict (or recheck) the txs outliving their TTL
+	expired := mem.purgeExpiredTxs(block.Height)
+
+	if mem.config.Recheck || len(expired) > 0 {
+		// recheck non-committed txs to see if they became invalid, or just the
+		// expired ones
 		recheckStartTime := time.Now().UnixNano()
 
 		_, err = mem.proxyAppConn.BeginRecheckTxSync(ocabci.RequestBeginRecheckTx{
@@ -752,8 +896,13 @@ func (mem *CListMempool) Update(
 		if err != nil {
 			mem.logger.Error("error in proxyAppConn.BeginRecheckTxSync", "err", err)
 		}
-		mem.logger.Debug("recheck txs", "numtxs", mem.Size(), "height", block.Height)
-		mem.recheckTxs()
+		if mem.config.Recheck {
+			mem.logger.Debug("recheck txs", "numtxs", mem.Size(), "height", block.Height)
+			mem.recheckTxs(nil)
+		} else {
+			mem.logger.Debug("recheck expired txs", "numtxs", len(expired), "height", block.Height)
+			mem.recheckTxs(expired)
+		}
 		_, err = mem.proxyAppConn.EndRecheckTxSync(ocabci.RequestEndRecheckTx{Height: block.Height})
 		if err != nil {
 			mem.logger.Error("error in proxyAppConn.EndRecheckTxSync", "err", err)
@@ -776,16 +925,54 @@ func (mem *CListMempool) Update(
 	return err
 }
 
-func (mem *CListMempool) recheckTxs() {
+// purgeExpiredTxs removes the txs not committed within their TTL (TTLDuration
+// and TTLNumBlocks), keeping them in the cache not to receive them again from
+// the peers. If TTLRecheck, they're kept for a new TTL instead, and returned to
+// be rechecked.
+//
+// Lock() must be held by the caller during execution.
+func (mem *CListMempool) purgeExpiredTxs(blockHeight int64) (expired []*clist.CElement) {
+	if mem.config.TTLDuration == 0 && mem.config.TTLNumBlocks == 0 {
+		return nil
+	}
+	now := time.Now()
+	var next *clist.CElement
+	for e := mem.txs.Front(); e != nil; e = next {
+		next = e.Next()
+		memTx := e.Value.(*mempoolTx)
+		if !(mem.config.TTLNumBlocks > 0 && blockHeight-memTx.Height() >= mem.config.TTLNumBlocks) &&
+			!(mem.config.TTLDuration > 0 && now.Sub(memTx.timestamp) >= mem.config.TTLDuration) {
+			continue
+		}
+		if mem.config.TTLRecheck {
+			atomic.StoreInt64(&memTx.height, blockHeight)
+			memTx.timestamp = now
+			expired = append(expired, e)
+			continue
+		}
+		mem.logger.Debug("tx expired", "tx", memTx.tx.Hash(), "height", memTx.Height())
+		mem.removeTx(memTx.tx, e, false)
+		mem.metrics.ExpiredTxs.Add(1)
+	}
+	return expired
+}
+
+// recheckTxs rechecks the txs of elems, or all the txs if nil.
+func (mem *CListMempool) recheckTxs(elems []*clist.CElement) {
 	if mem.Size() == 0 {
 		return
 	}
+	if elems == nil {
+		for e := mem.txs.Front(); e != nil; e = e.Next() {
+			elems = append(elems, e)
+		}
+	}
 
 	wg := sync.WaitGroup{}
 
 	// Push txs to proxyAppConn
 	// NOTE: globalCb may be called concurrently.
-	for e := mem.txs.Front(); e != nil; e = e.Next() {
+	for _, e := range elems {
 		wg.Add(1)
 
 		memTx := e.Value.(*mempoolTx)
@@ -807,9 +994,10 @@ func (mem *CListMempool) recheckTxs() {
 
 // mempoolTx is a transaction that successfully ran
 type mempoolTx struct {
-	height    int64    // height that this tx had been validated in
-	gasWanted int64    // amount of gas this tx states it will require
-	tx        types.Tx //
+	height    int64     // height that this tx had been validated in
+	timestamp time.Time // time that this tx had been validated at
+	gasWanted int64     // amount of gas this tx states it will require
+	tx        types.Tx  //
 
 	// ids of peers who've sent us this tx (as a map for quick lookups).
 	// senders: PeerID -> bool
@@ -820,3 +1008,15 @@ type mempoolTx struct {
 func (memTx *mempoolTx) Height() int64 {
 	return atomic.LoadInt64(&memTx.height)
 }
+
+// pendingTx returns the tx with its metadata.
+//
+// CONTRACT: the caller should hold mem.updateMtx.RLock().
+func (memTx *mempoolTx) pendingTx() mempool.PendingTx {
+	return mempool.PendingTx{
+		Tx:        memTx.tx,
+		Height:    memTx.Height(),
+		Timestamp: memTx.timestamp,
+		GasWanted: memTx.gasWanted,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	mrand "math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -249,6 +250,128 @@ func TestMempoolUpdate(t *testing.T) {
 	}
 }
 
+func TestMempoolPendingTxs(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mp, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	before := time.Now()
+	txs := checkTxs(t, mp, 2, mempool.UnknownPeerID)
+	require.NoError(t, mp.Update(newTestBlock(1, nil), abciResponses(0, ocabci.CodeTypeOK), nil, nil))
+	txs = append(txs, checkTxs(t, mp, 1, mempool.UnknownPeerID)...)
+
+	// by order of arrival, with the height they were checked at
+	pending := mp.PendingTxs()
+	require.Len(t, pending, 3)
+	for i, tx := range pending {
+		assert.Equal(t, txs[i], tx.Tx)
+		assert.EqualValues(t, 1, tx.GasWanted)
+		assert.Zero(t, tx.Priority)
+		assert.False(t, tx.Timestamp.Before(before))
+	}
+	assert.EqualValues(t, 0, pending[0].Height)
+	assert.EqualValues(t, 1, pending[2].Height)
+
+	tx, ok := mp.GetPendingTx(txs[2].Key())
+	require.True(t, ok)
+	assert.Equal(t, pending[2], tx)
+
+	require.NoError(t, mp.Update(newTestBlock(2, txs[2:]), abciResponses(1, ocabci.CodeTypeOK), nil, nil))
+	_, ok = mp.GetPendingTx(txs[2].Key())
+	assert.False(t, ok)
+	assert.Len(t, mp.PendingTxs(), 2)
+}
+
+func TestMempoolPersist(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mp, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	txs := checkTxs(t, mp, 3, mempool.UnknownPeerID)
+	require.NoError(t, mp.Update(newTestBlock(1, txs[2:]), abciResponses(1, ocabci.CodeTypeOK), nil, nil))
+	file := filepath.Join(t.TempDir(), "mempool.txs")
+	require.NoError(t, mempool.SaveTxs(mp, file))
+
+	// the txs are re-checked, in the same order, and the committed one is
+	// still in the cache
+	restarted, cleanup2 := newMempoolWithApp(cc)
+	defer cleanup2()
+	added, err := mempool.LoadTxs(restarted, file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, txs[:2], restarted.ReapMaxTxs(-1))
+	assert.Equal(t, mempool.ErrTxInCache, restarted.CheckTxSync(txs[2], nil, mempool.TxInfo{}))
+	assert.Equal(t, mp.CacheKeys(), restarted.CacheKeys())
+
+	// the file is loaded once
+	assert.NoFileExists(t, file)
+	added, err = mempool.LoadTxs(restarted, file)
+	require.NoError(t, err)
+	assert.Zero(t, added)
+}
+
+func TestMempoolTTL(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	conf := config.ResetTestRoot("mempool_test")
+	conf.Mempool.Recheck = false
+	conf.Mempool.TTLNumBlocks = 2
+	mp, cleanup := newMempoolWithAppAndConfig(cc, conf)
+	defer cleanup()
+
+	// 1. Evicts the txs not committed within ttl-num-blocks
+	{
+		txs := checkTxs(t, mp, 2, mempool.UnknownPeerID)
+		require.NoError(t, mp.Update(newTestBlock(1, nil), abciResponses(0, ocabci.CodeTypeOK), nil, nil))
+		assert.Equal(t, 2, mp.Size())
+		checkTxs(t, mp, 1, mempool.UnknownPeerID)
+		require.NoError(t, mp.Update(newTestBlock(2, nil), abciResponses(0, ocabci.CodeTypeOK), nil, nil))
+		assert.Equal(t, 1, mp.Size())
+
+		// they're kept in the cache
+		err := mp.CheckTxSync(txs[0], nil, mempool.TxInfo{})
+		assert.Equal(t, mempool.ErrTxInCache, err)
+		mp.Flush()
+	}
+
+	// 2. Evicts the txs not committed within ttl-duration
+	{
+		conf.Mempool.TTLNumBlocks = 0
+		conf.Mempool.TTLDuration = 10 * time.Millisecond
+		checkTxs(t, mp, 2, mempool.UnknownPeerID)
+		time.Sleep(conf.Mempool.TTLDuration)
+		checkTxs(t, mp, 1, mempool.UnknownPeerID)
+		require.NoError(t, mp.Update(newTestBlock(3, nil), abciResponses(0, ocabci.CodeTypeOK), nil, nil))
+		assert.Equal(t, 1, mp.Size())
+		mp.Flush()
+	}
+
+	// 3. Rechecks the expired txs with ttl-recheck, keeping the valid ones
+	{
+		conf.Mempool.TTLNumBlocks = 1
+		conf.Mempool.TTLDuration = 0
+		conf.Mempool.TTLRecheck = true
+		txs := checkTxs(t, mp, 2, mempool.UnknownPeerID)
+		postCheck := func(tx types.Tx, res *ocabci.ResponseCheckTx) error {
+			if tx.Key() == txs[0].Key() {
+				return errors.New("invalid")
+			}
+			return nil
+		}
+		require.NoError(t, mp.Update(newTestBlock(4, nil), abciResponses(0, ocabci.CodeTypeOK), nil, postCheck))
+		require.Equal(t, 1, mp.Size())
+		assert.Equal(t, txs[1], mp.ReapMaxTxs(-1)[0])
+
+		// the txs kept are expired again after a new TTL
+		require.NoError(t, mp.Update(newTestBlock(4, nil), abciResponses(0, ocabci.CodeTypeOK), nil, nil))
+		assert.Equal(t, 1, mp.Size())
+		require.NoError(t, mp.Update(newTestBlock(5, nil), abciResponses(0, ocabci.CodeTypeOK), nil, postCheck))
+		assert.Equal(t, 1, mp.Size())
+	}
+}
+
 // FIXME: need to adjust Ostracon's abci
 //func TestMempoolUpdateDoesNotPanicWhenApplicationMissedTx(t *testing.T) {
 //	var callback abciclient.Callback
@@ -783,3 +906,62 @@ func TestTxMempoolPostCheckError(t *testing.T) {
 		})
 	}
 }
+
+// batchApplication is a kvstore application checking the txs of a batch in one
+// call.
+type batchApplication struct {
+	*kvstore.Application
+
+	mtx     sync.Mutex
+	batches []int // sizes of the batches checked
+}
+
+func (app *batchApplication) CheckTxBatchAsync(reqs []abci.RequestCheckTx, callback ocabci.CheckTxBatchCallback) {
+	app.mtx.Lock()
+	app.batches = append(app.batches, len(reqs))
+	app.mtx.Unlock()
+
+	res := make([]ocabci.ResponseCheckTx, len(reqs))
+	for i, req := range reqs {
+		res[i] = app.CheckTxSync(req)
+	}
+	callback(res)
+}
+
+func TestMempoolCheckTxBatch(t *testing.T) {
+	app := &batchApplication{Application: kvstore.NewApplication()}
+	cc := proxy.NewLocalClientCreator(app)
+	conf := config.ResetTestRoot("mempool_test")
+	conf.Mempool.CheckTxBatchWindow = 100 * time.Millisecond
+	mp, cleanup := newMempoolWithAppAndConfig(cc, conf)
+	defer cleanup()
+
+	// the txs requested within the window are checked in one call
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		mp.CheckTxAsync(types.Tx{byte(i)}, mempool.TxInfo{}, nil, func(res *ocabci.Response) {
+			assert.Equal(t, ocabci.CodeTypeOK, res.GetCheckTx().Code)
+			wg.Done()
+		})
+	}
+	// but the ones already requested
+	var prepareErr error
+	mp.CheckTxAsync(types.Tx{0}, mempool.TxInfo{}, func(err error) { prepareErr = err }, nil)
+	wg.Wait()
+	assert.ErrorIs(t, prepareErr, mempool.ErrTxInCache)
+	assert.Equal(t, 3, mp.Size())
+
+	// Update waits for the txs of the batch to be checked
+	wg.Add(1)
+	mp.CheckTxAsync(types.Tx{3}, mempool.TxInfo{}, nil, func(*ocabci.Response) { wg.Done() })
+	time.Sleep(10 * time.Millisecond)
+	mp.Lock()
+	assert.Equal(t, 4, mp.Size())
+	mp.Unlock()
+	wg.Wait()
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	assert.Equal(t, []int{3, 1}, app.batches)
+}
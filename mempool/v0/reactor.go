@@ -12,12 +12,18 @@ import (
 	cfg "github.com/Finschia/ostracon/config"
 	"github.com/Finschia/ostracon/libs/clist"
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/ratelimit"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	"github.com/Finschia/ostracon/mempool"
 	"github.com/Finschia/ostracon/p2p"
+	ocmempl "github.com/Finschia/ostracon/proto/ostracon/mempool"
 	"github.com/Finschia/ostracon/types"
 )
 
+// peerLogInterval is the minimum interval between the same messages logged for
+// a peer, so that a misbehaving peer can't flood the logs.
+const peerLogInterval = time.Second
+
 // Reactor handles mempool tx broadcasting amongst peers.
 // It maintains a map from peer ID to counter, to prevent gossiping txs to the
 // peers you received it from.
@@ -26,6 +32,10 @@ type Reactor struct {
 	config  *cfg.MempoolConfig
 	mempool *CListMempool
 	ids     *mempoolIDs
+	limiter *ratelimit.Keyed      // rate of the txs received by peer (nil if unlimited)
+	haveTx  *mempool.HaveTxGossip // announces the txs, remembers the ones of the peers (nil if disabled)
+
+	peerLogger log.Logger // samples the messages logged for each received message
 }
 
 type mempoolIDs struct {
@@ -92,13 +102,24 @@ func newMempoolIDs() *mempoolIDs {
 }
 
 // NewReactor returns a new Reactor with the given config and mempool.
-func NewReactor(config *cfg.MempoolConfig, async bool, recvBufSize int, mempool *CListMempool) *Reactor {
+func NewReactor(config *cfg.MempoolConfig, async bool, recvBufSize int, mp *CListMempool) *Reactor {
 	memR := &Reactor{
 		config:  config,
-		mempool: mempool,
+		mempool: mp,
 		ids:     newMempoolIDs(),
 	}
+	if config.PeerTxRate > 0 || config.TotalPeerTxRate > 0 {
+		var total ratelimit.Limiter
+		if config.TotalPeerTxRate > 0 {
+			total = ratelimit.NewTokenBucket(config.TotalPeerTxRate, config.TotalPeerTxBurst)
+		}
+		memR.limiter = ratelimit.NewKeyed(func() ratelimit.Limiter {
+			return ratelimit.NewTokenBucket(config.PeerTxRate, config.PeerTxBurst)
+		}, total, 0)
+	}
+	memR.haveTx = mempool.NewHaveTxGossip(config.HaveTxFilterSize)
 	memR.BaseReactor = *p2p.NewBaseReactor("Mempool", memR, async, recvBufSize)
+	memR.peerLogger = log.NewSampledLogger(memR.Logger, peerLogInterval, "src")
 	return memR
 }
 
@@ -111,6 +132,7 @@ func (memR *Reactor) InitPeer(peer p2p.Peer) p2p.Peer {
 // SetLogger sets the Logger on the reactor and the underlying mempool.
 func (memR *Reactor) SetLogger(l log.Logger) {
 	memR.Logger = l
+	memR.peerLogger = log.NewSampledLogger(l, peerLogInterval, "src")
 	memR.mempool.SetLogger(l)
 }
 
@@ -125,6 +147,9 @@ func (memR *Reactor) OnStart() error {
 	if !memR.config.Broadcast {
 		memR.Logger.Info("Tx broadcasting is disabled")
 	}
+	if memR.haveTx != nil {
+		go memR.haveTx.AnnounceTxsRoutine(memR.Switch.Peers().List, memR.Quit(), memR.Logger)
+	}
 	return nil
 }
 
@@ -138,14 +163,22 @@ func (memR *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 		},
 	}
 
-	return []*p2p.ChannelDescriptor{
+	channels := []*p2p.ChannelDescriptor{
 		{
 			ID:                  mempool.MempoolChannel,
 			Priority:            5,
 			RecvMessageCapacity: batchMsg.Size(),
 			MessageType:         &protomem.Message{},
+			SendRateLimit:       memR.config.PeerSendRate,
+			RecvRateLimit:       memR.config.PeerRecvRate,
+			// the txs are gossiped by the other peers as well
+			RecvQueueDrop: true,
 		},
 	}
+	if memR.haveTx != nil {
+		channels = append(channels, memR.haveTx.ChannelDescriptor())
+	}
+	return channels
 }
 
 // AddPeer implements Reactor.
@@ -159,9 +192,33 @@ func (memR *Reactor) AddPeer(peer p2p.Peer) {
 // RemovePeer implements Reactor.
 func (memR *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
 	memR.ids.Reclaim(peer)
+	if memR.limiter != nil {
+		memR.limiter.Remove(string(peer.ID()))
+	}
+	memR.haveTx.RemovePeer(peer.ID())
 	// broadcast routine checks if peer is gone and returns
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the empty txs messages, the ones with a tx over
+// mempool.max_tx_bytes and the invalid have tx ones before they are queued.
+func (memR *Reactor) ValidateMessage(chID byte, msg proto.Message) error {
+	switch msg := msg.(type) {
+	case *protomem.Txs:
+		if len(msg.GetTxs()) == 0 {
+			return errors.New("no txs")
+		}
+		for _, tx := range msg.GetTxs() {
+			if len(tx) > memR.config.MaxTxBytes {
+				return fmt.Errorf("tx too large: %d > %d", len(tx), memR.config.MaxTxBytes)
+			}
+		}
+	case *ocmempl.HaveTx:
+		return mempool.ValidateHaveTx(msg.GetKeys())
+	}
+	return nil
+}
+
 // Receive implements Reactor.
 // It adds any received transactions to the mempool.
 func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
@@ -170,13 +227,27 @@ func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 	case *protomem.Txs:
 		protoTxs := msg.GetTxs()
 		if len(protoTxs) == 0 {
-			memR.Logger.Error("received empty txs from peer", "src", e.Src)
+			memR.peerLogger.Error("received empty txs from peer", "src", e.Src)
 			return
 		}
 		txInfo := mempool.TxInfo{SenderID: memR.ids.GetForPeer(e.Src)}
 		if e.Src != nil {
 			txInfo.SenderP2PID = e.Src.ID()
 		}
+		if e.Src != nil {
+			memR.haveTx.Received(e.Src.ID(), protoTxs)
+		}
+
+		if memR.limiter != nil {
+			now := time.Now()
+			for i := range protoTxs {
+				if !memR.limiter.AllowN(string(txInfo.SenderP2PID), now, 1) {
+					memR.peerLogger.Debug("Dropped txs over the rate limit", "src", e.Src, "txs", len(protoTxs)-i)
+					protoTxs = protoTxs[:i]
+					break
+				}
+			}
+		}
 
 		for _, tx := range protoTxs {
 			ntx := types.Tx(tx)
@@ -186,12 +257,20 @@ func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 				} else if errors.Is(err, mempool.ErrTxInCache) {
 					memR.Logger.Debug("Tx already exists in cache", "tx", ntx.String())
 				} else if err != nil {
-					memR.Logger.Info("Could not check tx", "tx", ntx.String(), "err", err)
+					memR.peerLogger.Info("Could not check tx", "src", e.Src, "tx", ntx.String(), "err", err)
 				}
-			}, nil)
+			}, memR.haveTx.AnnounceCb(ntx.Key()))
+		}
+	case *ocmempl.HaveTx:
+		keys := msg.GetKeys()
+		if err := mempool.ValidateHaveTx(keys); err != nil {
+			memR.peerLogger.Error("received invalid have tx from peer", "src", e.Src, "err", err)
+			memR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
+			return
 		}
+		memR.haveTx.ReceiveHaveTx(e.Src.ID(), keys)
 	default:
-		memR.Logger.Error("unknown message type", "src", e.Src, "chId", e.ChannelID, "msg", e.Message)
+		memR.peerLogger.Error("unknown message type", "src", e.Src, "chId", e.ChannelID, "msg", e.Message)
 		memR.Switch.StopPeerForError(e.Src, fmt.Errorf("mempool cannot handle message of type: %T", e.Message))
 		return
 	}
@@ -200,7 +279,10 @@ func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 }
 
 func (memR *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
-	msg := &protomem.Message{}
+	var msg p2p.Unwrapper = &protomem.Message{}
+	if chID == mempool.MempoolHaveTxChannel {
+		msg = &ocmempl.Message{}
+	}
 	err := proto.Unmarshal(msgBytes, msg)
 	if err != nil {
 		panic(err)
@@ -269,7 +351,7 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 		// NOTE: Transaction batching was disabled due to
 		// https://github.com/tendermint/tendermint/issues/5796
 
-		if _, ok := memTx.senders.Load(peerID); !ok {
+		if _, ok := memTx.senders.Load(peerID); !ok && !memR.knows(peer, memTx.tx) {
 			success := p2p.SendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
 				ChannelID: mempool.MempoolChannel,
 				Message:   &protomem.Txs{Txs: [][]byte{memTx.tx}},
@@ -278,6 +360,7 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 				time.Sleep(mempool.PeerCatchupSleepIntervalMS * time.Millisecond)
 				continue
 			}
+			memR.haveTx.Sent(peer.ID(), memTx.tx.Key())
 		}
 
 		select {
@@ -292,6 +375,11 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 	}
 }
 
+// knows reports whether the peer is known to have the tx.
+func (memR *Reactor) knows(peer p2p.Peer, tx types.Tx) bool {
+	return memR.haveTx.Knows(peer.ID(), tx.Key())
+}
+
 // TxsMessage is a Message containing transactions.
 type TxsMessage struct {
 	Txs []types.Tx
@@ -24,6 +24,7 @@ import (
 	"github.com/Finschia/ostracon/mempool"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/p2p/mock"
+	ocmempl "github.com/Finschia/ostracon/proto/ostracon/mempool"
 	"github.com/Finschia/ostracon/proxy"
 	"github.com/Finschia/ostracon/types"
 )
@@ -326,6 +327,160 @@ func TestLegacyReactorReceiveBasic(t *testing.T) {
 	})
 }
 
+func TestReactorPeerTxRate(t *testing.T) {
+	config := cfg.TestConfig()
+	config.Mempool.PeerTxRate = 0.001
+	config.Mempool.PeerTxBurst = 2
+	config.Mempool.TotalPeerTxRate = 0.001
+	config.Mempool.TotalPeerTxBurst = 3
+	reactors := makeAndConnectReactors(config, 1)
+	reactor := reactors[0]
+	defer func() {
+		err := reactor.Stop()
+		assert.NoError(t, err)
+	}()
+
+	peer1, peer2 := mock.NewPeer(nil), mock.NewPeer(nil)
+	for _, peer := range []p2p.Peer{peer1, peer2} {
+		reactor.InitPeer(peer)
+	}
+	receive := func(peer p2p.Peer, n int) {
+		txs := make([][]byte, n)
+		for i := range txs {
+			txs[i] = tmrand.Bytes(20)
+		}
+		reactor.ReceiveEnvelope(p2p.Envelope{
+			ChannelID: mempool.MempoolChannel,
+			Src:       peer,
+			Message:   &memproto.Txs{Txs: txs},
+		})
+	}
+
+	// the txs over the limit of the peer, then of all the peers, are dropped
+	receive(peer1, 3)
+	receive(peer2, 3)
+	assert.Eventually(t, func() bool { return reactor.mempool.Size() == 3 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 3, reactor.mempool.Size())
+
+	// the limiter of a removed peer is forgotten
+	assert.Equal(t, 2, reactor.limiter.Len())
+	reactor.RemovePeer(peer1, nil)
+	assert.Equal(t, 1, reactor.limiter.Len())
+}
+
+func TestReactorHaveTx(t *testing.T) {
+	config := cfg.TestConfig()
+	const N = 2
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			if err := r.Stop(); err != nil {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+	for _, r := range reactors {
+		for _, peer := range r.Switch.Peers().List() {
+			peer.Set(types.PeerStateKey, peerState{1})
+		}
+	}
+
+	// the txs announced by the peer aren't sent to it
+	peer := reactors[0].Switch.Peers().List()[0]
+	txs := types.Txs{types.Tx("a=1"), types.Tx("b=2")}
+	keys := make([][]byte, len(txs))
+	for i, tx := range txs {
+		key := tx.Key()
+		keys[i] = key[:]
+	}
+	reactors[0].ReceiveEnvelope(p2p.Envelope{
+		ChannelID: mempool.MempoolHaveTxChannel,
+		Src:       peer,
+		Message:   &ocmempl.HaveTx{Keys: keys},
+	})
+	for _, tx := range txs {
+		assert.True(t, reactors[0].knows(peer, tx))
+		require.NoError(t, reactors[0].mempool.CheckTxSync(tx, nil, mempool.TxInfo{}))
+	}
+	ensureNoTxs(t, reactors[1], 100*time.Millisecond)
+
+	// an invalid announcement is reported
+	reactors[0].ReceiveEnvelope(p2p.Envelope{
+		ChannelID: mempool.MempoolHaveTxChannel,
+		Src:       peer,
+		Message:   &ocmempl.HaveTx{Keys: [][]byte{[]byte("key")}},
+	})
+	assert.Negative(t, reactors[0].Switch.PeerScore(peer.ID()))
+
+	// the filter of a removed peer is forgotten
+	reactors[0].RemovePeer(peer, nil)
+	assert.False(t, reactors[0].knows(peer, txs[0]))
+}
+
+func TestReactorValidateMessage(t *testing.T) {
+	config := cfg.TestConfig()
+	reactor := makeAndConnectReactors(config, 1)[0]
+	defer func() {
+		assert.NoError(t, reactor.Stop())
+	}()
+
+	tx := types.Tx("a=1")
+	key := tx.Key()
+	testCases := []struct {
+		name      string
+		chID      byte
+		msg       proto.Message
+		expectErr bool
+	}{
+		{"txs", mempool.MempoolChannel, &memproto.Txs{Txs: [][]byte{tx}}, false},
+		{"no txs", mempool.MempoolChannel, &memproto.Txs{}, true},
+		{"tx too large", mempool.MempoolChannel,
+			&memproto.Txs{Txs: [][]byte{make([]byte, config.Mempool.MaxTxBytes+1)}}, true},
+		{"have tx", mempool.MempoolHaveTxChannel, &ocmempl.HaveTx{Keys: [][]byte{key[:]}}, false},
+		{"have tx invalid key", mempool.MempoolHaveTxChannel, &ocmempl.HaveTx{Keys: [][]byte{key[1:]}}, true},
+	}
+	for _, tc := range testCases {
+		err := reactor.ValidateMessage(tc.chID, tc.msg)
+		if tc.expectErr {
+			assert.Error(t, err, tc.name)
+		} else {
+			assert.NoError(t, err, tc.name)
+		}
+	}
+}
+
+func TestReactorAnnounceTxs(t *testing.T) {
+	config := cfg.TestConfig()
+	// the txs are only announced
+	config.Mempool.Broadcast = false
+	const N = 3
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			if err := r.Stop(); err != nil {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	// a tx received from a peer is announced to the other ones
+	peerOf := func(r *Reactor, other *Reactor) p2p.Peer {
+		return r.Switch.Peers().Get(other.Switch.NodeInfo().ID())
+	}
+	tx := types.Tx("a=1")
+	reactors[0].ReceiveEnvelope(p2p.Envelope{
+		ChannelID: mempool.MempoolChannel,
+		Src:       peerOf(reactors[0], reactors[1]),
+		Message:   &memproto.Txs{Txs: [][]byte{tx}},
+	})
+	assert.Eventually(t, func() bool {
+		return reactors[2].knows(peerOf(reactors[2], reactors[0]), tx)
+	}, time.Second, 10*time.Millisecond)
+	// but to its sender
+	assert.False(t, reactors[1].knows(peerOf(reactors[1], reactors[0]), tx))
+}
+
 // mempoolLogger is a TestingLogger which uses a different
 // color for each validator ("validator" key must exist).
 func mempoolLogger() log.Logger {
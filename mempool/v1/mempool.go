@@ -1,771 +1,1021 @@
-//go:build deprecated
-
 package v1
 
 import (
+	"errors"
 	"fmt"
-	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/creachadair/taskgroup"
-
 	abci "github.com/tendermint/tendermint/abci/types"
-	"github.com/tendermint/tendermint/config"
-	"github.com/tendermint/tendermint/libs/clist"
-	"github.com/tendermint/tendermint/libs/log"
-	"github.com/tendermint/tendermint/mempool"
-	"github.com/tendermint/tendermint/proxy"
-	"github.com/tendermint/tendermint/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	abcicli "github.com/Finschia/ostracon/abci/client"
+	ocabci "github.com/Finschia/ostracon/abci/types"
+	"github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/clist"
+	"github.com/Finschia/ostracon/libs/log"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tracing"
+	"github.com/Finschia/ostracon/mempool"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/proxy"
+	"github.com/Finschia/ostracon/types"
 )
 
-var _ mempool.Mempool = (*TxMempool)(nil)
-
-// TxMempoolOption sets an optional parameter on the TxMempool.
-type TxMempoolOption func(*TxMempool)
-
-// TxMempool implemements the Mempool interface and allows the application to
+// TxMempool implements the Mempool interface and allows the application to
 // set priority values on transactions in the CheckTx response. When selecting
 // transactions to include in a block, higher-priority transactions are chosen
-// first.  When evicting transactions from the mempool for size constraints,
-// lower-priority transactions are evicted sooner.
+// first. When the mempool is full, lower-priority transactions are evicted to
+// make room for higher-priority ones, instead of rejecting the latter.
 //
 // Within the mempool, transactions are ordered by time of arrival, and are
 // gossiped to the rest of the network based on that order (gossip order does
 // not take priority into account).
 type TxMempool struct {
-	// Immutable fields
-	logger       log.Logger
-	config       *config.MempoolConfig
+	// Atomic integers
+	height   int64 // the last block Update()'d to
+	txsBytes int64 // total size of mempool, in bytes
+
+	// notify listeners (ie. consensus) when txs are available
+	notifiedTxsAvailable bool
+	txsAvailable         chan struct{} // fires once for each height, when the mempool is not empty
+
+	config *config.MempoolConfig
+
+	// Exclusive mutex for Update method to prevent concurrent execution of
+	// CheckTx or ReapMaxBytesMaxGas(ReapMaxTxs) methods.
+	updateMtx tmsync.RWMutex
+	preCheck  mempool.PreCheckFunc
+	postCheck mempool.PostCheckFunc
+
+	chReqCheckTx chan *requestCheckTxAsync
+
+	// Mutex serializing the insertions and evictions of txs, which happen
+	// concurrently while updateMtx is read-locked.
+	txsMtx       tmsync.Mutex
+	txs          *clist.CList // concurrent linked-list of good txs, by arrival
 	proxyAppConn proxy.AppConnMempool
-	metrics      *mempool.Metrics
-	cache        mempool.TxCache // seen transactions
 
-	// Atomically-updated fields
-	txsBytes int64 // atomic: the total size of all transactions in the mempool, in bytes
+	// Map for quick access to txs to record sender in CheckTx.
+	// txsMap: txKey -> CElement
+	txsMap sync.Map
+	// Map of the txs with a sender assigned by the app, to reject the other
+	// txs of their senders.
+	// txsBySender: sender -> CElement
+	txsBySender sync.Map
 
-	// Synchronized fields, protected by mtx.
-	mtx                  *sync.RWMutex
-	notifiedTxsAvailable bool
-	txsAvailable         chan struct{} // one value sent per height when mempool is not empty
-	preCheck             mempool.PreCheckFunc
-	postCheck            mempool.PostCheckFunc
-	height               int64 // the latest height passed to Update
+	// Keep a cache of already-seen txs.
+	// This reduces the pressure on the proxyApp.
+	cache mempool.TxCache
+
+	logger  log.Logger
+	metrics *mempool.Metrics
+}
 
-	txs        *clist.CList // valid transactions (passed CheckTx)
-	txByKey    map[types.TxKey]*clist.CElement
-	txBySender map[string]*clist.CElement // for sender != ""
+type requestCheckTxAsync struct {
+	tx        types.Tx
+	txInfo    mempool.TxInfo
+	prepareCb func(error)
+	checkTxCb func(*ocabci.Response)
 }
 
-// NewTxMempool constructs a new, empty priority mempool at the specified
-// initial height and using the given config and options.
+var _ mempool.Mempool = &TxMempool{}
+var _ mempool.TxCacheMempool = &TxMempool{}
+
+// TxMempoolOption sets an optional parameter on the TxMempool.
+type TxMempoolOption func(*TxMempool)
+
+// NewTxMempool returns a new priority mempool with the given configuration and
+// connection to an application.
 func NewTxMempool(
-	logger log.Logger,
 	cfg *config.MempoolConfig,
 	proxyAppConn proxy.AppConnMempool,
 	height int64,
 	options ...TxMempoolOption,
 ) *TxMempool {
-
 	txmp := &TxMempool{
-		logger:       logger,
 		config:       cfg,
 		proxyAppConn: proxyAppConn,
-		metrics:      mempool.NopMetrics(),
-		cache:        mempool.NopTxCache{},
 		txs:          clist.New(),
-		mtx:          new(sync.RWMutex),
 		height:       height,
-		txByKey:      make(map[types.TxKey]*clist.CElement),
-		txBySender:   make(map[string]*clist.CElement),
+		chReqCheckTx: make(chan *requestCheckTxAsync, cfg.Size),
+		logger:       log.NewNopLogger(),
+		metrics:      mempool.NopMetrics(),
 	}
+
 	if cfg.CacheSize > 0 {
 		txmp.cache = mempool.NewLRUTxCache(cfg.CacheSize)
+	} else {
+		txmp.cache = mempool.NopTxCache{}
 	}
+	proxyAppConn.SetGlobalCallback(txmp.globalCb)
 
-	for _, opt := range options {
-		opt(txmp)
+	for _, option := range options {
+		option(txmp)
 	}
-
+	go txmp.checkTxAsyncReactor()
 	return txmp
 }
 
-// WithPreCheck sets a filter for the mempool to reject a transaction if f(tx)
-// returns an error. This is executed before CheckTx. It only applies to the
-// first created block. After that, Update() overwrites the existing value.
+// NOTE: not thread safe - should only be called once, on startup
+func (txmp *TxMempool) EnableTxsAvailable() {
+	txmp.txsAvailable = make(chan struct{}, 1)
+}
+
+// SetLogger sets the Logger.
+func (txmp *TxMempool) SetLogger(l log.Logger) {
+	txmp.logger = l
+}
+
+// WithPreCheck sets a filter for the mempool to reject a tx if f(tx) returns
+// false. This is ran before CheckTx. Only applies to the first created block.
+// After that, Update overwrites the existing value.
 func WithPreCheck(f mempool.PreCheckFunc) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.preCheck = f }
 }
 
-// WithPostCheck sets a filter for the mempool to reject a transaction if
-// f(tx, resp) returns an error. This is executed after CheckTx. It only applies
-// to the first created block. After that, Update overwrites the existing value.
+// WithPostCheck sets a filter for the mempool to reject a tx if f(tx) returns
+// false. This is ran after CheckTx. Only applies to the first created block.
+// After that, Update overwrites the existing value.
 func WithPostCheck(f mempool.PostCheckFunc) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.postCheck = f }
 }
 
-// WithMetrics sets the mempool's metrics collector.
+// WithMetrics sets the metrics.
 func WithMetrics(metrics *mempool.Metrics) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.metrics = metrics }
 }
 
-// Lock obtains a write-lock on the mempool. A caller must be sure to explicitly
-// release the lock when finished.
-func (txmp *TxMempool) Lock() { txmp.mtx.Lock() }
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) Lock() {
+	txmp.updateMtx.Lock()
+}
 
-// Unlock releases a write-lock on the mempool.
-func (txmp *TxMempool) Unlock() { txmp.mtx.Unlock() }
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) Unlock() {
+	txmp.updateMtx.Unlock()
+}
 
-// Size returns the number of valid transactions in the mempool. It is
-// thread-safe.
-func (txmp *TxMempool) Size() int { return txmp.txs.Len() }
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) Size() int {
+	return txmp.txs.Len()
+}
 
-// SizeBytes return the total sum in bytes of all the valid transactions in the
-// mempool. It is thread-safe.
-func (txmp *TxMempool) SizeBytes() int64 { return atomic.LoadInt64(&txmp.txsBytes) }
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) SizeBytes() int64 {
+	return atomic.LoadInt64(&txmp.txsBytes)
+}
 
-// FlushAppConn executes FlushSync on the mempool's proxyAppConn.
-//
-// The caller must hold an exclusive mempool lock (by calling txmp.Lock) before
-// calling FlushAppConn.
+// Lock() must be help by the caller during execution.
 func (txmp *TxMempool) FlushAppConn() error {
-	// N.B.: We have to issue the call outside the lock so that its callback can
-	// fire.  It's safe to do this, the flush will block until complete.
-	//
-	// We could just not require the caller to hold the lock at all, but the
-	// semantics of the Mempool interface require the caller to hold it, and we
-	// can't change that without disrupting existing use.
-	txmp.mtx.Unlock()
-	defer txmp.mtx.Lock()
-
-	return txmp.proxyAppConn.FlushSync()
+	_, err := txmp.proxyAppConn.FlushSync()
+	return err
 }
 
-// EnableTxsAvailable enables the mempool to trigger events when transactions
-// are available on a block by block basis.
-func (txmp *TxMempool) EnableTxsAvailable() {
-	txmp.mtx.Lock()
-	defer txmp.mtx.Unlock()
+// XXX: Unsafe! Calling Flush may leave mempool in inconsistent state.
+func (txmp *TxMempool) Flush() {
+	txmp.updateMtx.Lock()
+	defer txmp.updateMtx.Unlock()
 
-	txmp.txsAvailable = make(chan struct{}, 1)
+	_ = atomic.SwapInt64(&txmp.txsBytes, 0)
+	txmp.cache.Reset()
+
+	for e := txmp.txs.Front(); e != nil; e = e.Next() {
+		txmp.txs.Remove(e)
+		e.DetachPrev()
+	}
+
+	txmp.txsMap.Range(func(key, _ interface{}) bool {
+		txmp.txsMap.Delete(key)
+		return true
+	})
+	txmp.txsBySender.Range(func(key, _ interface{}) bool {
+		txmp.txsBySender.Delete(key)
+		return true
+	})
 }
 
-// TxsAvailable returns a channel which fires once for every height, and only
-// when transactions are available in the mempool. It is thread-safe.
-func (txmp *TxMempool) TxsAvailable() <-chan struct{} { return txmp.txsAvailable }
+// TxsFront returns the first transaction in the ordered list for peer
+// goroutines to call .NextWait() on.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) TxsFront() *clist.CElement {
+	return txmp.txs.Front()
+}
 
-// CheckTx adds the given transaction to the mempool if it fits and passes the
-// application's ABCI CheckTx method.
+// TxsWaitChan returns a channel to wait on transactions. It will be closed
+// once the mempool is not empty (ie. the internal `txmp.txs` has at least one
+// element)
 //
-// CheckTx reports an error without adding tx if:
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) TxsWaitChan() <-chan struct{} {
+	return txmp.txs.WaitChan()
+}
+
+// CheckTxSync : It blocks if we're waiting on Update() or Reap().
+// cb: A callback from the CheckTxSync command.
 //
-// - The size of tx exceeds the configured maximum transaction size.
-// - The pre-check hook is defined and reports an error for tx.
-// - The transaction already exists in the cache.
-// - The proxy connection to the application fails.
+//	It gets called from another goroutine.
 //
-// If tx passes all of the above conditions, it is passed (asynchronously) to
-// the application's ABCI CheckTx method and this CheckTx method returns nil.
-// If cb != nil, it is called when the ABCI request completes to report the
-// application response.
+// CONTRACT: Either cb will get called, or err returned.
 //
-// If the application accepts the transaction and the mempool is full, the
-// mempool evicts one or more of the lowest-priority transaction whose priority
-// is (strictly) lower than the priority of tx and whose size together exceeds
-// the size of tx, and adds tx instead. If no such transactions exist, tx is
-// discarded.
-func (txmp *TxMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo mempool.TxInfo) error {
-
-	// During the initial phase of CheckTx, we do not need to modify any state.
-	// A transaction will not actually be added to the mempool until it survives
-	// a call to the ABCI CheckTx method and size constraint checks.
-	height, err := func() (int64, error) {
-		txmp.mtx.RLock()
-		defer txmp.mtx.RUnlock()
-
-		// Reject transactions in excess of the configured maximum transaction size.
-		if len(tx) > txmp.config.MaxTxBytes {
-			return 0, mempool.ErrTxTooLarge{Max: txmp.config.MaxTxBytes, Actual: len(tx)}
-		}
-
-		// If a precheck hook is defined, call it before invoking the application.
-		if txmp.preCheck != nil {
-			if err := txmp.preCheck(tx); err != nil {
-				return 0, mempool.ErrPreCheck{Reason: err}
-			}
-		}
-
-		// Early exit if the proxy connection has an error.
-		if err := txmp.proxyAppConn.Error(); err != nil {
-			return 0, err
-		}
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) CheckTxSync(
+	tx types.Tx,
+	cb func(*ocabci.Response),
+	txInfo mempool.TxInfo,
+) error {
 
-		txKey := tx.Key()
+	txmp.updateMtx.RLock()
+	// use defer to unlock mutex because application (*local client*) might panic
+	defer txmp.updateMtx.RUnlock()
 
-		// Check for the transaction in the cache.
-		if !txmp.cache.Push(tx) {
-			// If the cached transaction is also in the pool, record its sender.
-			if elt, ok := txmp.txByKey[txKey]; ok {
-				w := elt.Value.(*WrappedTx)
-				w.SetPeer(txInfo.SenderID)
-			}
-			return 0, mempool.ErrTxInCache
-		}
-		return txmp.height, nil
-	}()
-	if err != nil {
+	if err := txmp.prepareCheckTx(tx, txInfo); err != nil {
 		return err
 	}
 
-	// Invoke an ABCI CheckTx for this transaction.
-	rsp, err := txmp.proxyAppConn.CheckTxSync(abci.RequestCheckTx{Tx: tx})
+	// CONTRACT: `app.CheckTxSync()` should check whether `GasWanted` is valid (0 <= GasWanted <= block.masGas)
+	span := tracing.StartTxSpan(tx.Key(), "check_tx")
+	r, err := txmp.proxyAppConn.CheckTxSync(abci.RequestCheckTx{Tx: tx})
 	if err != nil {
-		txmp.cache.Remove(tx)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
 		return err
 	}
-	wtx := &WrappedTx{
-		tx:        tx,
-		hash:      tx.Key(),
-		timestamp: time.Now().UTC(),
-		height:    height,
-	}
-	wtx.SetPeer(txInfo.SenderID)
-	txmp.addNewTransaction(wtx, rsp)
-	if cb != nil {
-		cb(&abci.Response{Value: &abci.Response_CheckTx{CheckTx: rsp}})
-	}
+	span.SetAttributes(attribute.Int64("code", int64(r.Code)))
+	span.End()
+
+	res := ocabci.ToResponseCheckTx(*r)
+	txmp.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, res, cb)
 	return nil
 }
 
-// RemoveTxByKey removes the transaction with the specified key from the
-// mempool. It reports an error if no such transaction exists.  This operation
-// does not remove the transaction from the cache.
-func (txmp *TxMempool) RemoveTxByKey(txKey types.TxKey) error {
-	txmp.mtx.Lock()
-	defer txmp.mtx.Unlock()
-	return txmp.removeTxByKey(txKey)
-}
-
-// removeTxByKey removes the specified transaction key from the mempool.
-// The caller must hold txmp.mtx excluxively.
-func (txmp *TxMempool) removeTxByKey(key types.TxKey) error {
-	if elt, ok := txmp.txByKey[key]; ok {
-		w := elt.Value.(*WrappedTx)
-		delete(txmp.txByKey, key)
-		delete(txmp.txBySender, w.sender)
-		txmp.txs.Remove(elt)
-		elt.DetachPrev()
-		elt.DetachNext()
-		atomic.AddInt64(&txmp.txsBytes, -w.Size())
-		return nil
-	}
-	return fmt.Errorf("transaction %x not found", key)
+// cb: A callback from the CheckTx command.
+//
+//	It gets called from another goroutine.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) CheckTxAsync(
+	tx types.Tx,
+	txInfo mempool.TxInfo,
+	prepareCb func(error),
+	checkTxCb func(*ocabci.Response),
+) {
+	txmp.chReqCheckTx <- &requestCheckTxAsync{tx: tx, txInfo: txInfo, prepareCb: prepareCb, checkTxCb: checkTxCb}
 }
 
-// removeTxByElement removes the specified transaction element from the mempool.
-// The caller must hold txmp.mtx exclusively.
-func (txmp *TxMempool) removeTxByElement(elt *clist.CElement) {
-	w := elt.Value.(*WrappedTx)
-	delete(txmp.txByKey, w.tx.Key())
-	delete(txmp.txBySender, w.sender)
-	txmp.txs.Remove(elt)
-	elt.DetachPrev()
-	elt.DetachNext()
-	atomic.AddInt64(&txmp.txsBytes, -w.Size())
+func (txmp *TxMempool) checkTxAsyncReactor() {
+	if txmp.config.CheckTxBatchWindow > 0 {
+		for req := range txmp.chReqCheckTx {
+			txmp.checkTxBatchAsync(req)
+		}
+		return
+	}
+	for req := range txmp.chReqCheckTx {
+		txmp.checkTxAsync(req.tx, req.txInfo, req.prepareCb, req.checkTxCb)
+	}
 }
 
-// Flush purges the contents of the mempool and the cache, leaving both empty.
-// The current height is not modified by this operation.
-func (txmp *TxMempool) Flush() {
-	txmp.mtx.Lock()
-	defer txmp.mtx.Unlock()
+// checkTxBatchAsync checks the txs requested within the batch window from the
+// first one in one call to the app. The batch holds a read lock of updateMtx
+// until all its txs are checked, so it blocks Update() for up to the window.
+func (txmp *TxMempool) checkTxBatchAsync(first *requestCheckTxAsync) {
+	txmp.updateMtx.RLock()
+	defer func() {
+		if r := recover(); r != nil {
+			txmp.updateMtx.RUnlock()
+			panic(r)
+		}
+	}()
 
-	// Remove all the transactions in the list explicitly, so that the sizes
-	// and indexes get updated properly.
-	cur := txmp.txs.Front()
-	for cur != nil {
-		next := cur.Next()
-		txmp.removeTxByElement(cur)
-		cur = next
+	var batch []*requestCheckTxAsync
+	add := func(req *requestCheckTxAsync) {
+		err := txmp.prepareCheckTx(req.tx, req.txInfo)
+		if req.prepareCb != nil {
+			req.prepareCb(err)
+		}
+		if err == nil {
+			batch = append(batch, req)
+		}
 	}
-	txmp.cache.Reset()
+	add(first)
+	timer := time.NewTimer(txmp.config.CheckTxBatchWindow)
+	defer timer.Stop()
+BATCH:
+	for len(batch) < mempool.MaxCheckTxBatchSize {
+		select {
+		case req := <-txmp.chReqCheckTx:
+			add(req)
+		case <-timer.C:
+			break BATCH
+		}
+	}
+	if len(batch) == 0 {
+		txmp.updateMtx.RUnlock()
+		return
+	}
+
+	reqs := make([]abci.RequestCheckTx, len(batch))
+	cbs := make([]abcicli.ResponseCallback, len(batch))
+	pending := int32(len(batch))
+	for i, req := range batch {
+		req := req
+		span := tracing.StartTxSpan(req.tx.Key(), "check_tx")
+		reqs[i] = abci.RequestCheckTx{Tx: req.tx}
+		cbs[i] = func(res *ocabci.Response) {
+			if r := res.GetCheckTx(); r != nil {
+				span.SetAttributes(attribute.Int64("code", int64(r.Code)))
+			}
+			span.End()
+			txmp.reqResCb(req.tx, req.txInfo.SenderID, req.txInfo.SenderP2PID, res, func(response *ocabci.Response) {
+				if req.checkTxCb != nil {
+					req.checkTxCb(response)
+				}
+				if atomic.AddInt32(&pending, -1) == 0 {
+					txmp.updateMtx.RUnlock()
+				}
+			})
+		}
+	}
+	txmp.proxyAppConn.CheckTxBatchAsync(reqs, cbs)
 }
 
-// allEntriesSorted returns a slice of all the transactions currently in the
-// mempool, sorted in nonincreasing order by priority with ties broken by
-// increasing order of arrival time.
-func (txmp *TxMempool) allEntriesSorted() []*WrappedTx {
-	txmp.mtx.RLock()
-	defer txmp.mtx.RUnlock()
+// It blocks if we're waiting on Update() or Reap().
+func (txmp *TxMempool) checkTxAsync(
+	tx types.Tx,
+	txInfo mempool.TxInfo,
+	prepareCb func(error),
+	checkTxCb func(*ocabci.Response),
+) {
+	txmp.updateMtx.RLock()
+	defer func() {
+		if r := recover(); r != nil {
+			txmp.updateMtx.RUnlock()
+			panic(r)
+		}
+	}()
 
-	all := make([]*WrappedTx, 0, len(txmp.txByKey))
-	for _, tx := range txmp.txByKey {
-		all = append(all, tx.Value.(*WrappedTx))
+	err := txmp.prepareCheckTx(tx, txInfo)
+	if prepareCb != nil {
+		prepareCb(err)
+	}
+	if err != nil {
+		txmp.updateMtx.RUnlock()
+		return
 	}
-	sort.Slice(all, func(i, j int) bool {
-		if all[i].priority == all[j].priority {
-			return all[i].timestamp.Before(all[j].timestamp)
+
+	// CONTRACT: `app.CheckTxAsync()` should check whether `GasWanted` is valid (0 <= GasWanted <= block.masGas)
+	span := tracing.StartTxSpan(tx.Key(), "check_tx")
+	txmp.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{Tx: tx}, func(res *ocabci.Response) {
+		if r := res.GetCheckTx(); r != nil {
+			span.SetAttributes(attribute.Int64("code", int64(r.Code)))
 		}
-		return all[i].priority > all[j].priority // N.B. higher priorities first
+		span.End()
+		txmp.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, res, func(response *ocabci.Response) {
+			if checkTxCb != nil {
+				checkTxCb(response)
+			}
+			txmp.updateMtx.RUnlock()
+		})
 	})
-	return all
 }
 
-// ReapMaxBytesMaxGas returns a slice of valid transactions that fit within the
-// size and gas constraints. The results are ordered by nonincreasing priority,
-// with ties broken by increasing order of arrival.  Reaping transactions does
-// not remove them from the mempool.
-//
-// If maxBytes < 0, no limit is set on the total size in bytes.
-// If maxGas < 0, no limit is set on the total gas cost.
+// Unlike the CListMempool, the mempool being full doesn't reject a tx here:
+// its priority is only known once the app checked it, and it might evict
+// lower-priority txs then (see addTx).
 //
-// If the mempool is empty or has no transactions fitting within the given
-// constraints, the result will also be empty.
-func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
-	var totalGas, totalBytes int64
-
-	var keep []types.Tx //nolint:prealloc
-	for _, w := range txmp.allEntriesSorted() {
-		// N.B. When computing byte size, we need to include the overhead for
-		// encoding as protobuf to send to the application.
-		totalGas += w.gasWanted
-		totalBytes += types.ComputeProtoSizeForTxs([]types.Tx{w.tx})
-		if (maxGas >= 0 && totalGas > maxGas) || (maxBytes >= 0 && totalBytes > maxBytes) {
-			break
+// CONTRACT: `caller` should held `txmp.updateMtx.RLock()`
+func (txmp *TxMempool) prepareCheckTx(tx types.Tx, txInfo mempool.TxInfo) error {
+	// For keeping the consistency between `txmp.txs` and `txmp.txsMap`
+	if _, ok := txmp.txsMap.Load(tx.Key()); ok {
+		return mempool.ErrTxInMap
+	}
+
+	txSize := len(tx)
+	if txSize > txmp.config.MaxTxBytes {
+		return mempool.ErrTxTooLarge{
+			Max:    txmp.config.MaxTxBytes,
+			Actual: txSize,
 		}
-		keep = append(keep, w.tx)
 	}
-	return keep
-}
 
-// TxsWaitChan returns a channel that is closed when there is at least one
-// transaction available to be gossiped.
-func (txmp *TxMempool) TxsWaitChan() <-chan struct{} { return txmp.txs.WaitChan() }
-
-// TxsFront returns the frontmost element of the pending transaction list.
-// It will be nil if the mempool is empty.
-func (txmp *TxMempool) TxsFront() *clist.CElement { return txmp.txs.Front() }
+	if txmp.preCheck != nil {
+		if err := txmp.preCheck(tx); err != nil {
+			return mempool.ErrPreCheck{
+				Reason: err,
+			}
+		}
+	}
 
-// ReapMaxTxs returns up to max transactions from the mempool. The results are
-// ordered by nonincreasing priority with ties broken by increasing order of
-// arrival. Reaping transactions does not remove them from the mempool.
-//
-// If max < 0, all transactions in the mempool are reaped.
-//
-// The result may have fewer than max elements (possibly zero) if the mempool
-// does not have that many transactions available.
-func (txmp *TxMempool) ReapMaxTxs(max int) types.Txs {
-	var keep []types.Tx //nolint:prealloc
+	// NOTE: proxyAppConn may error if tx buffer is full
+	if err := txmp.proxyAppConn.Error(); err != nil {
+		return err
+	}
 
-	for _, w := range txmp.allEntriesSorted() {
-		if max >= 0 && len(keep) >= max {
-			break
+	if !txmp.cache.Push(tx) { // if the transaction already exists in the cache
+		// Record a new sender for a tx we've already seen.
+		// Note it's possible a tx is still in the cache but no longer in the mempool
+		// (eg. after committing a block, txs are removed from mempool but not cache),
+		// so we only record the sender for txs still in the mempool.
+		if e, ok := txmp.txsMap.Load(tx.Key()); ok {
+			wtx := e.(*clist.CElement).Value.(*WrappedTx)
+			wtx.SetPeer(txInfo.SenderID)
 		}
-		keep = append(keep, w.tx)
+		return mempool.ErrTxInCache
 	}
-	return keep
+
+	return nil
 }
 
-// Update removes all the given transactions from the mempool and the cache,
-// and updates the current block height. The blockTxs and deliverTxResponses
-// must have the same length with each response corresponding to the tx at the
-// same offset.
+// Global callback that will be called after every ABCI response.
+// If we're not in the midst of a recheck, this function will just return,
+// so the request specific callback can do the work.
 //
-// If the configuration enables recheck, Update sends each remaining
-// transaction after removing blockTxs to the ABCI CheckTx method.  Any
-// transactions marked as invalid during recheck are also removed.
-//
-// The caller must hold an exclusive mempool lock (by calling txmp.Lock) before
-// calling Update.
-func (txmp *TxMempool) Update(
-	blockHeight int64,
-	blockTxs types.Txs,
-	deliverTxResponses []*abci.ResponseDeliverTx,
-	newPreFn mempool.PreCheckFunc,
-	newPostFn mempool.PostCheckFunc,
-) error {
-	// Safety check: Transactions and responses must match in number.
-	if len(blockTxs) != len(deliverTxResponses) {
-		panic(fmt.Sprintf("mempool: got %d transactions but %d DeliverTx responses",
-			len(blockTxs), len(deliverTxResponses)))
+// When rechecking, we don't need the peerID, so the recheck callback happens
+// here.
+func (txmp *TxMempool) globalCb(req *ocabci.Request, res *ocabci.Response) {
+	checkTxReq := req.GetCheckTx()
+	if checkTxReq == nil {
+		return
 	}
 
-	txmp.height = blockHeight
-	txmp.notifiedTxsAvailable = false
+	if checkTxReq.Type == abci.CheckTxType_Recheck {
+		txmp.metrics.RecheckTimes.Add(1)
+		txmp.resCbRecheck(req, res)
 
-	if newPreFn != nil {
-		txmp.preCheck = newPreFn
+		// update metrics
+		txmp.metrics.Size.Set(float64(txmp.Size()))
 	}
-	if newPostFn != nil {
-		txmp.postCheck = newPostFn
+}
+
+// Request specific callback that should be set on individual reqRes objects
+// to incorporate local information when processing the response.
+// This allows us to track the peer that sent us this tx, so we can avoid sending it back to them.
+//
+// External callers of CheckTx, like the RPC, can also pass an externalCb through here that is called
+// when all other response processing is complete.
+func (txmp *TxMempool) reqResCb(
+	tx []byte,
+	peerID uint16,
+	peerP2PID p2p.ID,
+	res *ocabci.Response,
+	externalCb func(*ocabci.Response),
+) {
+	txmp.resCbFirstTime(tx, peerID, peerP2PID, res)
+
+	// update metrics
+	txmp.metrics.Size.Set(float64(txmp.Size()))
+
+	// passed in by the caller of CheckTx, eg. the RPC
+	if externalCb != nil {
+		externalCb(res)
 	}
+}
 
-	for i, tx := range blockTxs {
-		// Add successful committed transactions to the cache (if they are not
-		// already present).  Transactions that failed to commit are removed from
-		// the cache unless the operator has explicitly requested we keep them.
-		if deliverTxResponses[i].Code == abci.CodeTypeOK {
-			_ = txmp.cache.Push(tx)
-		} else if !txmp.config.KeepInvalidTxsInCache {
-			txmp.cache.Remove(tx)
+// addTx adds the tx to the mempool, evicting lower-priority txs if it's full.
+// It returns an error, without evicting any tx, if there aren't enough
+// lower-priority txs to make room for it, or if the mempool has a tx of its
+// sender already.
+//
+// Called from:
+//   - resCbFirstTime (lock not held) if tx is valid
+func (txmp *TxMempool) addTx(wtx *WrappedTx) error {
+	txmp.txsMtx.Lock()
+	defer txmp.txsMtx.Unlock()
+
+	if wtx.sender != "" {
+		if e, ok := txmp.txsBySender.Load(wtx.sender); ok {
+			return fmt.Errorf("tx %X already exists for sender %q",
+				e.(*clist.CElement).Value.(*WrappedTx).tx.Hash(), wtx.sender)
+		}
+	}
+	if err := txmp.isFull(wtx.Size()); err != nil {
+		victims := txmp.evictionVictims(wtx)
+		if victims == nil {
+			return err
+		}
+		for _, e := range victims {
+			victim := e.Value.(*WrappedTx)
+			txmp.logger.Debug(
+				"evicted valid transaction; mempool is full",
+				"tx", victim.tx.Hash(),
+				"priority", victim.Priority(),
+				"new_tx", wtx.tx.Hash(),
+				"new_priority", wtx.Priority(),
+			)
+			// NOTE: we remove tx from the cache because it might be added later
+			txmp.removeTx(victim.tx, e, true)
+			txmp.metrics.EvictedTxs.Add(1)
 		}
+	}
 
-		// Regardless of success, remove the transaction from the mempool.
-		_ = txmp.removeTxByKey(tx.Key())
+	e := txmp.txs.PushBack(wtx)
+	txmp.txsMap.Store(wtx.tx.Key(), e)
+	if wtx.sender != "" {
+		txmp.txsBySender.Store(wtx.sender, e)
 	}
+	atomic.AddInt64(&txmp.txsBytes, wtx.Size())
+	txmp.metrics.TxSizeBytes.Observe(float64(wtx.Size()))
+	tracing.StartTxWait(wtx.tx.Key(), "mempool")
+	return nil
+}
 
-	txmp.purgeExpiredTxs(blockHeight)
+// evictionVictims returns the txs to evict to make room for wtx, i.e. the
+// fewest of the txs with a strictly lower priority, the lowest priority and the
+// latest first, or nil if evicting all of them wouldn't make enough room.
+//
+// The caller must hold txmp.txsMtx.
+func (txmp *TxMempool) evictionVictims(wtx *WrappedTx) []*clist.CElement {
+	priority := wtx.Priority()
+	var candidates []*clist.CElement
+	for e := txmp.txs.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*WrappedTx).Priority() < priority {
+			candidates = append(candidates, e)
+		}
+	}
+	// the candidates are the latest first, which the stable sort keeps for the
+	// ones of the same priority
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value.(*WrappedTx).Priority() < candidates[j].Value.(*WrappedTx).Priority()
+	})
 
-	// If there any uncommitted transactions left in the mempool, we either
-	// initiate re-CheckTx per remaining transaction or notify that remaining
-	// transactions are left.
-	size := txmp.Size()
-	txmp.metrics.Size.Set(float64(size))
-	if size > 0 {
-		if txmp.config.Recheck {
-			txmp.recheckTransactions()
-		} else {
-			txmp.notifyTxsAvailable()
+	numTxs, txsBytes := txmp.Size(), txmp.SizeBytes()
+	for i, e := range candidates {
+		numTxs--
+		txsBytes -= e.Value.(*WrappedTx).Size()
+		if numTxs < txmp.config.Size && wtx.Size()+txsBytes <= txmp.config.MaxTxsBytes {
+			return candidates[:i+1]
 		}
 	}
 	return nil
 }
 
-// addNewTransaction handles the ABCI CheckTx response for the first time a
-// transaction is added to the mempool.  A recheck after a block is committed
-// goes to handleRecheckResult.
-//
-// If either the application rejected the transaction or a post-check hook is
-// defined and rejects the transaction, it is discarded.
-//
-// Otherwise, if the mempool is full, check for lower-priority transactions
-// that can be evicted to make room for the new one. If no such transactions
-// exist, this transaction is logged and dropped; otherwise the selected
-// transactions are evicted.
-//
-// Finally, the new transaction is added and size stats updated.
-func (txmp *TxMempool) addNewTransaction(wtx *WrappedTx, checkTxRes *abci.ResponseCheckTx) {
-	txmp.mtx.Lock()
-	defer txmp.mtx.Unlock()
-
-	var err error
-	if txmp.postCheck != nil {
-		err = txmp.postCheck(wtx.tx, checkTxRes)
-	}
-
-	if err != nil || checkTxRes.Code != abci.CodeTypeOK {
-		txmp.logger.Info(
-			"rejected bad transaction",
-			"priority", wtx.Priority(),
-			"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-			"peer_id", wtx.peers,
-			"code", checkTxRes.Code,
-			"post_check_err", err,
-		)
-
-		txmp.metrics.FailedTxs.Add(1)
+// Called from:
+//   - Update (lock held) if tx was committed
+//   - resCbRecheck (lock not held) if tx was invalidated
+//   - addTx (txsMtx held) if tx was evicted
+func (txmp *TxMempool) removeTx(tx types.Tx, elem *clist.CElement, removeFromCache bool) {
+	txmp.txs.Remove(elem)
+	elem.DetachPrev()
+	txmp.txsMap.Delete(tx.Key())
+	if sender := elem.Value.(*WrappedTx).sender; sender != "" {
+		txmp.txsBySender.Delete(sender)
+	}
+	atomic.AddInt64(&txmp.txsBytes, int64(-len(tx)))
+	tracing.UntrackTx(tx.Key())
+
+	if removeFromCache {
+		txmp.cache.Remove(tx)
+	}
+}
 
-		// Remove the invalid transaction from the cache, unless the operator has
-		// instructed us to keep invalid transactions.
-		if !txmp.config.KeepInvalidTxsInCache {
-			txmp.cache.Remove(wtx.tx)
+// RemoveTxByKey removes a transaction from the mempool by its TxKey index.
+func (txmp *TxMempool) RemoveTxByKey(txKey types.TxKey) error {
+	txmp.txsMtx.Lock()
+	defer txmp.txsMtx.Unlock()
+
+	if e, ok := txmp.txsMap.Load(txKey); ok {
+		wtx := e.(*clist.CElement).Value.(*WrappedTx)
+		if wtx != nil {
+			txmp.removeTx(wtx.tx, e.(*clist.CElement), false)
+			return nil
 		}
+		return errors.New("transaction not found")
+	}
+	return errors.New("invalid transaction found")
+}
 
-		// If there was a post-check error, record its text in the result for
-		// debugging purposes.
-		if err != nil {
-			checkTxRes.MempoolError = err.Error()
+func (txmp *TxMempool) isFull(txSize int64) error {
+	var (
+		memSize  = txmp.Size()
+		txsBytes = txmp.SizeBytes()
+	)
+
+	if memSize >= txmp.config.Size || txSize+txsBytes > txmp.config.MaxTxsBytes {
+		return mempool.ErrMempoolIsFull{
+			NumTxs:      memSize,
+			MaxTxs:      txmp.config.Size,
+			TxsBytes:    txsBytes,
+			MaxTxsBytes: txmp.config.MaxTxsBytes,
 		}
-		return
 	}
 
-	priority := checkTxRes.Priority
-	sender := checkTxRes.Sender
+	return nil
+}
 
-	// Disallow multiple concurrent transactions from the same sender assigned
-	// by the ABCI application. As a special case, an empty sender is not
-	// restricted.
-	if sender != "" {
-		elt, ok := txmp.txBySender[sender]
-		if ok {
-			w := elt.Value.(*WrappedTx)
+// callback, which is called after the app checked the tx for the first time.
+//
+// The case where the app checks the tx for the second and subsequent times is
+// handled by the resCbRecheck callback.
+func (txmp *TxMempool) resCbFirstTime(
+	tx []byte,
+	peerID uint16,
+	peerP2PID p2p.ID,
+	res *ocabci.Response,
+) {
+	switch r := res.Value.(type) {
+	case *ocabci.Response_CheckTx:
+		if r.CheckTx.Code != ocabci.CodeTypeOK {
+			// ignore bad transaction
 			txmp.logger.Debug(
-				"rejected valid incoming transaction; tx already exists for sender",
-				"tx", fmt.Sprintf("%X", w.tx.Hash()),
-				"sender", sender,
+				"rejected bad transaction",
+				"tx", types.Tx(tx).Hash(),
+				"peerID", peerP2PID,
+				"res", r,
 			)
-			checkTxRes.MempoolError =
-				fmt.Sprintf("rejected valid incoming transaction; tx already exists for sender %q (%X)",
-					sender, w.tx.Hash())
-			txmp.metrics.RejectedTxs.Add(1)
-			return
-		}
-	}
-
-	// At this point the application has ruled the transaction valid, but the
-	// mempool might be full. If so, find the lowest-priority items with lower
-	// priority than the application assigned to this new one, and evict as many
-	// of them as necessary to make room for tx. If no such items exist, we
-	// discard tx.
+			txmp.metrics.FailedTxs.Add(1)
+			tracing.UntrackTx(types.Tx(tx).Key())
 
-	if err := txmp.canAddTx(wtx); err != nil {
-		var victims []*clist.CElement // eligible transactions for eviction
-		var victimBytes int64         // total size of victims
-		for cur := txmp.txs.Front(); cur != nil; cur = cur.Next() {
-			cw := cur.Value.(*WrappedTx)
-			if cw.priority < priority {
-				victims = append(victims, cur)
-				victimBytes += cw.Size()
+			if !txmp.config.KeepInvalidTxsInCache {
+				// remove from cache (it might be good later)
+				txmp.cache.Remove(tx)
 			}
+			return
 		}
 
-		// If there are no suitable eviction candidates, or the total size of
-		// those candidates is not enough to make room for the new transaction,
-		// drop the new one.
-		if len(victims) == 0 || victimBytes < wtx.Size() {
-			txmp.cache.Remove(wtx.tx)
-			txmp.logger.Error(
-				"rejected valid incoming transaction; mempool is full",
-				"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-				"err", err.Error(),
+		wtx := &WrappedTx{
+			height:    txmp.height,
+			timestamp: time.Now(),
+			gasWanted: r.CheckTx.GasWanted,
+			priority:  r.CheckTx.Priority,
+			sender:    r.CheckTx.Sender,
+			tx:        tx,
+		}
+		wtx.SetPeer(peerID)
+		if err := txmp.addTx(wtx); err != nil {
+			txmp.logger.Debug(
+				"rejected valid transaction",
+				"tx", wtx.tx.Hash(),
+				"priority", wtx.priority,
+				"err", err,
 			)
-			checkTxRes.MempoolError =
-				fmt.Sprintf("rejected valid incoming transaction; mempool is full (%X)",
-					wtx.tx.Hash())
+			r.CheckTx.MempoolError = err.Error()
 			txmp.metrics.RejectedTxs.Add(1)
+			tracing.UntrackTx(wtx.tx.Key())
+			// remove from cache (there might be room, or no tx of the sender,
+			// later)
+			txmp.cache.Remove(tx)
 			return
 		}
-
-		txmp.logger.Debug("evicting lower-priority transactions",
-			"new_tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-			"new_priority", priority,
+		txmp.logger.Debug(
+			"added good transaction",
+			"tx", wtx.tx.Hash(),
+			"priority", wtx.priority,
+			"height", wtx.height,
+			"total", txmp.Size(),
 		)
+		txmp.notifyTxsAvailable()
+	default:
+		// ignore other messages
+	}
+}
 
-		// Sort lowest priority items first so they will be evicted first.  Break
-		// ties in favor of newer items (to maintain FIFO semantics in a group).
-		sort.Slice(victims, func(i, j int) bool {
-			iw := victims[i].Value.(*WrappedTx)
-			jw := victims[j].Value.(*WrappedTx)
-			if iw.Priority() == jw.Priority() {
-				return iw.timestamp.After(jw.timestamp)
-			}
-			return iw.Priority() < jw.Priority()
-		})
+// callback, which is called after the app rechecked the tx, updating its
+// priority.
+//
+// The case where the app checks the tx for the first time is handled by the
+// resCbFirstTime callback.
+func (txmp *TxMempool) resCbRecheck(req *ocabci.Request, res *ocabci.Response) {
+	switch r := res.Value.(type) {
+	case *ocabci.Response_CheckTx:
+		tx := req.GetCheckTx().Tx
+		e, ok := txmp.txsMap.Load(types.Tx(tx).Key())
+		if !ok {
+			txmp.logger.Debug("re-CheckTx transaction does not exist", "expected", types.Tx(tx).Hash())
+			return
+		}
+		celem := e.(*clist.CElement)
 
-		// Evict as many of the victims as necessary to make room.
-		var evictedBytes int64
-		for _, vic := range victims {
-			w := vic.Value.(*WrappedTx)
+		var postCheckErr error
+		if r.CheckTx.Code == ocabci.CodeTypeOK {
+			if txmp.postCheck != nil {
+				postCheckErr = txmp.postCheck(tx, r.CheckTx)
+			}
+			if postCheckErr == nil {
+				wtx := celem.Value.(*WrappedTx)
+				wtx.SetGasWanted(r.CheckTx.GasWanted)
+				wtx.SetPriority(r.CheckTx.Priority)
+				return
+			}
+			r.CheckTx.MempoolError = postCheckErr.Error()
+		}
+		// Tx became invalidated due to newly committed block.
+		txmp.logger.Debug("tx is no longer valid",
+			"tx", types.Tx(tx).Hash(),
+			"res", r,
+			"err", postCheckErr,
+		)
+		// NOTE: we remove tx from the cache because it might be good later
+		txmp.removeTx(tx, celem, !txmp.config.KeepInvalidTxsInCache)
+	default:
+		// ignore other messages
+	}
+}
 
-			txmp.logger.Debug(
-				"evicted valid existing transaction; mempool full",
-				"old_tx", fmt.Sprintf("%X", w.tx.Hash()),
-				"old_priority", w.priority,
-			)
-			txmp.removeTxByElement(vic)
-			txmp.cache.Remove(w.tx)
-			txmp.metrics.EvictedTxs.Add(1)
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) TxsAvailable() <-chan struct{} {
+	return txmp.txsAvailable
+}
 
-			// We may not need to evict all the eligible transactions.  Bail out
-			// early if we have made enough room.
-			evictedBytes += w.Size()
-			if evictedBytes >= wtx.Size() {
-				break
-			}
+func (txmp *TxMempool) notifyTxsAvailable() {
+	if txmp.Size() == 0 {
+		txmp.logger.Info("notified txs available but mempool is empty!")
+	}
+	if txmp.txsAvailable != nil && !txmp.notifiedTxsAvailable {
+		// channel cap is 1, so this will send once
+		txmp.notifiedTxsAvailable = true
+		select {
+		case txmp.txsAvailable <- struct{}{}:
+		default:
 		}
 	}
+}
 
-	wtx.SetGasWanted(checkTxRes.GasWanted)
-	wtx.SetPriority(priority)
-	wtx.SetSender(sender)
-	txmp.insertTx(wtx)
+// allEntriesSorted returns all the txs of the mempool in nonincreasing order
+// by priority, with ties broken by increasing order of arrival.
+//
+// CONTRACT: `caller` should held `txmp.updateMtx.RLock()`
+func (txmp *TxMempool) allEntriesSorted() []*WrappedTx {
+	all := make([]*WrappedTx, 0, txmp.txs.Len())
+	for e := txmp.txs.Front(); e != nil; e = e.Next() {
+		all = append(all, e.Value.(*WrappedTx))
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Priority() > all[j].Priority() // N.B. higher priorities first
+	})
+	return all
+}
 
-	txmp.metrics.TxSizeBytes.Observe(float64(wtx.Size()))
-	txmp.metrics.Size.Set(float64(txmp.Size()))
-	txmp.logger.Debug(
-		"inserted new valid transaction",
-		"priority", wtx.Priority(),
-		"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-		"height", txmp.height,
-		"num_txs", txmp.Size(),
+// ReapMaxBytesMaxGas returns the txs of the highest priorities fitting within
+// the size and gas constraints, with ties broken by increasing order of
+// arrival.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
+	return txmp.ReapMaxBytesMaxGasMaxTxs(maxBytes, maxGas, -1)
+}
+
+// ReapMaxBytesMaxGasMaxTxs puts cap on txs as well on top of
+// ReapMaxBytesMaxGas.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) ReapMaxBytesMaxGasMaxTxs(maxBytes, maxGas, maxTxs int64) types.Txs {
+	txmp.updateMtx.RLock()
+	defer txmp.updateMtx.RUnlock()
+
+	all := txmp.allEntriesSorted()
+	if maxTxs <= 0 {
+		maxTxs = int64(len(all))
+	}
+
+	var (
+		totalGas    int64
+		runningSize int64
 	)
-	txmp.notifyTxsAvailable()
+
+	txs := make([]types.Tx, 0, len(all))
+	for _, wtx := range all {
+		if len(txs) >= int(maxTxs) {
+			break
+		}
+
+		protoTxs := tmproto.Data{}
+		protoTxs.Txs = append(protoTxs.Txs, wtx.tx)
+		dataSize := int64(protoTxs.Size())
+
+		// Check total size requirement
+		if maxBytes > -1 && runningSize+dataSize > maxBytes {
+			break
+		}
+		runningSize += dataSize
+
+		// Check total gas requirement.
+		// If maxGas is negative, skip this check.
+		newTotalGas := totalGas + wtx.GasWanted()
+		if maxGas > -1 && newTotalGas > maxGas {
+			break
+		}
+		totalGas = newTotalGas
+
+		txs = append(txs, wtx.tx)
+	}
+	return txs
 }
 
-func (txmp *TxMempool) insertTx(wtx *WrappedTx) {
-	elt := txmp.txs.PushBack(wtx)
-	txmp.txByKey[wtx.tx.Key()] = elt
-	if s := wtx.Sender(); s != "" {
-		txmp.txBySender[s] = elt
+// ReapMaxTxs returns up to max txs of the highest priorities, with ties broken
+// by increasing order of arrival.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) ReapMaxTxs(max int) types.Txs {
+	txmp.updateMtx.RLock()
+	defer txmp.updateMtx.RUnlock()
+
+	all := txmp.allEntriesSorted()
+	if max < 0 || max > len(all) {
+		max = len(all)
 	}
 
-	atomic.AddInt64(&txmp.txsBytes, wtx.Size())
+	txs := make([]types.Tx, 0, max)
+	for _, wtx := range all[:max] {
+		txs = append(txs, wtx.tx)
+	}
+	return txs
 }
 
-// handleRecheckResult handles the responses from ABCI CheckTx calls issued
-// during the recheck phase of a block Update.  It removes any transactions
-// invalidated by the application.
+// GetPendingTx returns the tx of the mempool with the key.
 //
-// This method is NOT executed for the initial CheckTx on a new transaction;
-// that case is handled by addNewTransaction instead.
-func (txmp *TxMempool) handleRecheckResult(tx types.Tx, checkTxRes *abci.ResponseCheckTx) {
-	txmp.metrics.RecheckTimes.Add(1)
-	txmp.mtx.Lock()
-	defer txmp.mtx.Unlock()
-
-	// Find the transaction reported by the ABCI callback. It is possible the
-	// transaction was evicted during the recheck, in which case the transaction
-	// will be gone.
-	elt, ok := txmp.txByKey[tx.Key()]
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) GetPendingTx(txKey types.TxKey) (mempool.PendingTx, bool) {
+	txmp.updateMtx.RLock()
+	defer txmp.updateMtx.RUnlock()
+
+	e, ok := txmp.txsMap.Load(txKey)
 	if !ok {
-		return
+		return mempool.PendingTx{}, false
 	}
-	wtx := elt.Value.(*WrappedTx)
+	return e.(*clist.CElement).Value.(*WrappedTx).pendingTx(), true
+}
 
-	// If a postcheck hook is defined, call it before checking the result.
-	var err error
-	if txmp.postCheck != nil {
-		err = txmp.postCheck(tx, checkTxRes)
-	}
+// PendingTxs returns all the txs of the mempool in nonincreasing order by
+// priority, with ties broken by increasing order of arrival.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) PendingTxs() []mempool.PendingTx {
+	txmp.updateMtx.RLock()
+	defer txmp.updateMtx.RUnlock()
 
-	if checkTxRes.Code == abci.CodeTypeOK && err == nil {
-		wtx.SetPriority(checkTxRes.Priority)
-		return // N.B. Size of mempool did not change
+	all := txmp.allEntriesSorted()
+	txs := make([]mempool.PendingTx, len(all))
+	for i, wtx := range all {
+		txs[i] = wtx.pendingTx()
 	}
+	return txs
+}
 
-	txmp.logger.Debug(
-		"existing transaction no longer valid; failed re-CheckTx callback",
-		"priority", wtx.Priority(),
-		"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-		"err", err,
-		"code", checkTxRes.Code,
-	)
-	txmp.removeTxByElement(elt)
-	txmp.metrics.FailedTxs.Add(1)
-	if !txmp.config.KeepInvalidTxsInCache {
-		txmp.cache.Remove(wtx.tx)
+// CacheKeys returns the keys of the txs in the cache, from the least recently
+// used, none if the cache is disabled.
+//
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) CacheKeys() []types.TxKey {
+	if cache, ok := txmp.cache.(*mempool.LRUTxCache); ok {
+		return cache.Keys()
 	}
-	txmp.metrics.Size.Set(float64(txmp.Size()))
+	return nil
 }
 
-// recheckTransactions initiates re-CheckTx ABCI calls for all the transactions
-// currently in the mempool. It reports the number of recheck calls that were
-// successfully initiated.
+// RestoreCacheKeys adds the keys to the cache, unless it's disabled.
 //
-// Precondition: The mempool is not empty.
-// The caller must hold txmp.mtx exclusively.
-func (txmp *TxMempool) recheckTransactions() {
-	if txmp.Size() == 0 {
-		panic("mempool: cannot run recheck on an empty mempool")
+// Safe for concurrent use by multiple goroutines.
+func (txmp *TxMempool) RestoreCacheKeys(keys []types.TxKey) {
+	if cache, ok := txmp.cache.(*mempool.LRUTxCache); ok {
+		for _, key := range keys {
+			cache.PushKey(key)
+		}
 	}
-	txmp.logger.Debug(
-		"executing re-CheckTx for all remaining transactions",
-		"num_txs", txmp.Size(),
-		"height", txmp.height,
-	)
+}
 
-	// Collect transactions currently in the mempool requiring recheck.
-	wtxs := make([]*WrappedTx, 0, txmp.txs.Len())
-	for e := txmp.txs.Front(); e != nil; e = e.Next() {
-		wtxs = append(wtxs, e.Value.(*WrappedTx))
-	}
-
-	// Issue CheckTx calls for each remaining transaction, and when all the
-	// rechecks are complete signal watchers that transactions may be available.
-	go func() {
-		g, start := taskgroup.New(nil).Limit(2 * runtime.NumCPU())
-
-		for _, wtx := range wtxs {
-			wtx := wtx
-			start(func() error {
-				// The response for this CheckTx is handled by the default recheckTxCallback.
-				rsp, err := txmp.proxyAppConn.CheckTxSync(abci.RequestCheckTx{
-					Tx:   wtx.tx,
-					Type: abci.CheckTxType_Recheck,
-				})
-				if err != nil {
-					txmp.logger.Error("failed to execute CheckTx during recheck",
-						"err", err, "hash", fmt.Sprintf("%x", wtx.tx.Hash()))
-				} else {
-					txmp.handleRecheckResult(wtx.tx, rsp)
-				}
-				return nil
-			})
+// Lock() must be held by the caller during execution.
+func (txmp *TxMempool) Update(
+	block *types.Block,
+	deliverTxResponses []*abci.ResponseDeliverTx,
+	preCheck mempool.PreCheckFunc,
+	postCheck mempool.PostCheckFunc,
+) (err error) {
+	// Set height
+	txmp.height = block.Height
+	txmp.notifiedTxsAvailable = false
+
+	if preCheck != nil {
+		txmp.preCheck = preCheck
+	}
+	if postCheck != nil {
+		txmp.postCheck = postCheck
+	}
+
+	for i, tx := range block.Txs {
+		if deliverTxResponses[i].Code == ocabci.CodeTypeOK {
+			// Add valid committed tx to the cache (if missing).
+			_ = txmp.cache.Push(tx)
+		} else if !txmp.config.KeepInvalidTxsInCache {
+			// Allow invalid transactions to be resubmitted.
+			txmp.cache.Remove(tx)
 		}
-		_ = txmp.proxyAppConn.FlushAsync()
 
-		// When recheck is complete, trigger a notification for more transactions.
-		_ = g.Wait()
-		txmp.mtx.Lock()
-		defer txmp.mtx.Unlock()
-		txmp.notifyTxsAvailable()
-	}()
-}
+		// Remove committed tx from the mempool.
+		tracing.UntrackTx(tx.Key(), attribute.Int64("height", block.Height))
+		if e, ok := txmp.txsMap.Load(tx.Key()); ok {
+			txmp.removeTx(tx, e.(*clist.CElement), false)
+		}
+	}
 
-// canAddTx returns an error if we cannot insert the provided *WrappedTx into
-// the mempool due to mempool configured constraints. Otherwise, nil is
-// returned and the transaction can be inserted into the mempool.
-func (txmp *TxMempool) canAddTx(wtx *WrappedTx) error {
-	numTxs := txmp.Size()
-	txBytes := txmp.SizeBytes()
+	// evict (or recheck) the txs outliving their TTL
+	expired := txmp.purgeExpiredTxs(block.Height)
 
-	if numTxs >= txmp.config.Size || wtx.Size()+txBytes > txmp.config.MaxTxsBytes {
-		return mempool.ErrMempoolIsFull{
-			NumTxs:      numTxs,
-			MaxTxs:      txmp.config.Size,
-			TxsBytes:    txBytes,
-			MaxTxsBytes: txmp.config.MaxTxsBytes,
+	if txmp.config.Recheck || len(expired) > 0 {
+		// recheck non-committed txs to see if they became invalid, or just the
+		// expired ones, updating their priorities
+		recheckStartTime := time.Now().UnixNano()
+
+		_, err = txmp.proxyAppConn.BeginRecheckTxSync(ocabci.RequestBeginRecheckTx{
+			Header: types.OC2PB.Header(&block.Header),
+		})
+		if err != nil {
+			txmp.logger.Error("error in proxyAppConn.BeginRecheckTxSync", "err", err)
+		}
+		if txmp.config.Recheck {
+			txmp.logger.Debug("recheck txs", "numtxs", txmp.Size(), "height", block.Height)
+			txmp.recheckTxs(nil)
+		} else {
+			txmp.logger.Debug("recheck expired txs", "numtxs", len(expired), "height", block.Height)
+			txmp.recheckTxs(expired)
 		}
+		_, err = txmp.proxyAppConn.EndRecheckTxSync(ocabci.RequestEndRecheckTx{Height: block.Height})
+		if err != nil {
+			txmp.logger.Error("error in proxyAppConn.EndRecheckTxSync", "err", err)
+		}
+
+		recheckEndTime := time.Now().UnixNano()
+
+		recheckTimeMs := float64(recheckEndTime-recheckStartTime) / 1000000
+		txmp.metrics.RecheckTime.Set(recheckTimeMs)
 	}
 
-	return nil
+	// notify there're some txs left.
+	if txmp.Size() > 0 {
+		txmp.notifyTxsAvailable()
+	}
+
+	// Update metrics
+	txmp.metrics.Size.Set(float64(txmp.Size()))
+
+	return err
 }
 
-// purgeExpiredTxs removes all transactions from the mempool that have exceeded
-// their respective height or time-based limits as of the given blockHeight.
-// Transactions removed by this operation are not removed from the cache.
+// purgeExpiredTxs removes the txs not committed within their TTL (TTLDuration
+// and TTLNumBlocks), keeping them in the cache not to receive them again from
+// the peers. If TTLRecheck, they're kept for a new TTL instead, and returned to
+// be rechecked.
 //
-// The caller must hold txmp.mtx exclusively.
-func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
-	if txmp.config.TTLNumBlocks == 0 && txmp.config.TTLDuration == 0 {
-		return // nothing to do
+// Lock() must be held by the caller during execution.
+func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) (expired []*clist.CElement) {
+	if txmp.config.TTLDuration == 0 && txmp.config.TTLNumBlocks == 0 {
+		return nil
 	}
-
 	now := time.Now()
-	cur := txmp.txs.Front()
-	for cur != nil {
-		// N.B. Grab the next element first, since if we remove cur its successor
-		// will be invalidated.
-		next := cur.Next()
-
-		w := cur.Value.(*WrappedTx)
-		if txmp.config.TTLNumBlocks > 0 && (blockHeight-w.height) > txmp.config.TTLNumBlocks {
-			txmp.removeTxByElement(cur)
-			txmp.cache.Remove(w.tx)
-			txmp.metrics.EvictedTxs.Add(1)
-		} else if txmp.config.TTLDuration > 0 && now.Sub(w.timestamp) > txmp.config.TTLDuration {
-			txmp.removeTxByElement(cur)
-			txmp.cache.Remove(w.tx)
-			txmp.metrics.EvictedTxs.Add(1)
+	var next *clist.CElement
+	for e := txmp.txs.Front(); e != nil; e = next {
+		next = e.Next()
+		wtx := e.Value.(*WrappedTx)
+		if !(txmp.config.TTLNumBlocks > 0 && blockHeight-wtx.Height() >= txmp.config.TTLNumBlocks) &&
+			!(txmp.config.TTLDuration > 0 && now.Sub(wtx.timestamp) >= txmp.config.TTLDuration) {
+			continue
+		}
+		if txmp.config.TTLRecheck {
+			atomic.StoreInt64(&wtx.height, blockHeight)
+			wtx.timestamp = now
+			expired = append(expired, e)
+			continue
 		}
-		cur = next
+		txmp.logger.Debug("tx expired", "tx", wtx.tx.Hash(), "height", wtx.Height())
+		txmp.removeTx(wtx.tx, e, false)
+		txmp.metrics.ExpiredTxs.Add(1)
 	}
+	return expired
 }
 
-func (txmp *TxMempool) notifyTxsAvailable() {
+// recheckTxs rechecks the txs of elems, or all the txs if nil.
+func (txmp *TxMempool) recheckTxs(elems []*clist.CElement) {
 	if txmp.Size() == 0 {
-		return // nothing to do
+		return
+	}
+	if elems == nil {
+		for e := txmp.txs.Front(); e != nil; e = e.Next() {
+			elems = append(elems, e)
+		}
 	}
 
-	if txmp.txsAvailable != nil && !txmp.notifiedTxsAvailable {
-		// channel cap is 1, so this will send once
-		txmp.notifiedTxsAvailable = true
+	wg := sync.WaitGroup{}
 
-		select {
-		case txmp.txsAvailable <- struct{}{}:
-		default:
+	// Push txs to proxyAppConn
+	// NOTE: globalCb may be called concurrently.
+	for _, e := range elems {
+		wg.Add(1)
+
+		wtx := e.Value.(*WrappedTx)
+		req := abci.RequestCheckTx{
+			Tx:   wtx.tx,
+			Type: abci.CheckTxType_Recheck,
 		}
+
+		txmp.proxyAppConn.CheckTxAsync(req, func(res *ocabci.Response) {
+			wg.Done()
+		})
 	}
+
+	txmp.proxyAppConn.FlushAsync(func(res *ocabci.Response) {})
+	wg.Wait()
 }
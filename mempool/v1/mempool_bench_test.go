@@ -1,34 +1,32 @@
-//go:build deprecated
-
 package v1
 
 import (
+	"crypto/rand"
 	"fmt"
-	"math/rand"
+	mrand "math/rand"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/require"
 
-	"github.com/tendermint/tendermint/mempool"
+	"github.com/Finschia/ostracon/mempool"
 )
 
 func BenchmarkTxMempool_CheckTx(b *testing.B) {
 	txmp := setup(b, 10000)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	txmp.config.Size = b.N
 
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
 		b.StopTimer()
 		prefix := make([]byte, 20)
-		_, err := rng.Read(prefix)
+		_, err := rand.Read(prefix)
 		require.NoError(b, err)
 
-		priority := int64(rng.Intn(9999-1000) + 1000)
+		priority := int64(mrand.Intn(9000) + 1000)
 		tx := []byte(fmt.Sprintf("%X=%d", prefix, priority))
 		b.StartTimer()
 
-		require.NoError(b, txmp.CheckTx(tx, nil, mempool.TxInfo{}))
+		require.NoError(b, txmp.CheckTxSync(tx, nil, mempool.TxInfo{}))
 	}
 }
@@ -1,30 +1,32 @@
-//go:build deprecated
-
 package v1
 
 import (
 	"bytes"
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"math/rand"
+	mrand "math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/tendermint/tendermint/abci/example/code"
-	"github.com/tendermint/tendermint/abci/example/kvstore"
 	abci "github.com/tendermint/tendermint/abci/types"
-	"github.com/tendermint/tendermint/config"
-	"github.com/tendermint/tendermint/libs/log"
-	"github.com/tendermint/tendermint/mempool"
-	"github.com/tendermint/tendermint/proxy"
-	"github.com/tendermint/tendermint/types"
+
+	"github.com/Finschia/ostracon/abci/example/code"
+	"github.com/Finschia/ostracon/abci/example/kvstore"
+	ocabci "github.com/Finschia/ostracon/abci/types"
+	"github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/mempool"
+	"github.com/Finschia/ostracon/proxy"
+	"github.com/Finschia/ostracon/types"
 )
 
 // application extends the KV store application by overriding CheckTx to provide
@@ -33,45 +35,27 @@ type application struct {
 	*kvstore.Application
 }
 
-type testTx struct {
-	tx       types.Tx
-	priority int64
-}
-
-func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
-	var (
-		priority int64
-		sender   string
-	)
-
-	// infer the priority from the raw transaction value (sender=key=value)
+func (app *application) CheckTxSync(req abci.RequestCheckTx) ocabci.ResponseCheckTx {
+	// infer the priority from the raw transaction value (key=value), and the
+	// sender from its prefix if any (sender=key=value)
 	parts := bytes.Split(req.Tx, []byte("="))
-	if len(parts) == 3 {
-		v, err := strconv.ParseInt(string(parts[2]), 10, 64)
-		if err != nil {
-			return abci.ResponseCheckTx{
-				Priority:  priority,
-				Code:      100,
-				GasWanted: 1,
-			}
-		}
-
-		priority = v
+	var sender string
+	switch len(parts) {
+	case 2:
+	case 3:
 		sender = string(parts[0])
-	} else {
-		return abci.ResponseCheckTx{
-			Priority:  priority,
-			Code:      101,
-			GasWanted: 1,
-		}
+	default:
+		return ocabci.ResponseCheckTx{Code: 101, GasWanted: 1}
 	}
-
-	return abci.ResponseCheckTx{
-		Priority:  priority,
-		Sender:    sender,
-		Code:      code.CodeTypeOK,
-		GasWanted: 1,
+	priority, err := strconv.ParseInt(string(parts[len(parts)-1]), 10, 64)
+	if err != nil {
+		return ocabci.ResponseCheckTx{Code: 100, GasWanted: 1}
 	}
+	return ocabci.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: 1, Priority: priority, Sender: sender}
+}
+
+func (app *application) CheckTxAsync(req abci.RequestCheckTx, callback ocabci.CheckTxCallback) {
+	callback(app.CheckTxSync(req))
 }
 
 func setup(t testing.TB, cacheSize int, options ...TxMempoolOption) *TxMempool {
@@ -80,7 +64,7 @@ func setup(t testing.TB, cacheSize int, options ...TxMempoolOption) *TxMempool {
 	app := &application{kvstore.NewApplication()}
 	cc := proxy.NewLocalClientCreator(app)
 
-	cfg := config.ResetTestRoot(strings.ReplaceAll(t.Name(), "/", "|"))
+	cfg := config.ResetTestRoot("mempool_test")
 	cfg.Mempool.CacheSize = cacheSize
 
 	appConnMem, err := cc.NewABCIClient()
@@ -92,42 +76,73 @@ func setup(t testing.TB, cacheSize int, options ...TxMempoolOption) *TxMempool {
 		require.NoError(t, appConnMem.Stop())
 	})
 
-	return NewTxMempool(log.TestingLogger().With("test", t.Name()), cfg.Mempool, appConnMem, 0, options...)
+	txmp := NewTxMempool(cfg.Mempool, appConnMem, 0, options...)
+	txmp.SetLogger(log.TestingLogger())
+	return txmp
 }
 
-// mustCheckTx invokes txmp.CheckTx for the given transaction and waits until
-// its callback has finished executing. It fails t if CheckTx fails.
-func mustCheckTx(t *testing.T, txmp *TxMempool, spec string) {
-	done := make(chan struct{})
-	if err := txmp.CheckTx([]byte(spec), func(*abci.Response) {
-		close(done)
-	}, mempool.TxInfo{}); err != nil {
-		t.Fatalf("CheckTx for %q failed: %v", spec, err)
-	}
-	<-done
+type testTx struct {
+	tx       types.Tx
+	priority int64
 }
 
-func checkTxs(t *testing.T, txmp *TxMempool, numTxs int, peerID uint16) []testTx {
-	txs := make([]testTx, numTxs)
+// checkTxs checks count random txs of random priorities, failing t if any
+// fails.
+func checkTxs(t *testing.T, txmp *TxMempool, count int, peerID uint16) []testTx {
+	txs := make([]testTx, count)
 	txInfo := mempool.TxInfo{SenderID: peerID}
-
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	for i := 0; i < numTxs; i++ {
+	for i := 0; i < count; i++ {
 		prefix := make([]byte, 20)
-		_, err := rng.Read(prefix)
+		_, err := rand.Read(prefix)
 		require.NoError(t, err)
+		priority := int64(mrand.Intn(9000) + 1000)
+		txs[i] = testTx{tx: []byte(fmt.Sprintf("%X=%d", prefix, priority)), priority: priority}
+		require.NoError(t, txmp.CheckTxSync(txs[i].tx, nil, txInfo))
+	}
+	return txs
+}
 
-		priority := int64(rng.Intn(9999-1000) + 1000)
+// rawTxs returns the txs of txs.
+func rawTxs(txs []testTx) types.Txs {
+	raw := make(types.Txs, len(txs))
+	for i, tx := range txs {
+		raw[i] = tx.tx
+	}
+	return raw
+}
 
-		txs[i] = testTx{
-			tx:       []byte(fmt.Sprintf("sender-%d-%d=%X=%d", i, peerID, prefix, priority)),
-			priority: priority,
-		}
-		require.NoError(t, txmp.CheckTx(txs[i].tx, nil, txInfo))
+// okResponses returns the successful DeliverTx responses of count txs.
+func okResponses(count int) []*abci.ResponseDeliverTx {
+	responses := make([]*abci.ResponseDeliverTx, count)
+	for i := range responses {
+		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
 	}
+	return responses
+}
 
-	return txs
+// checkTxsBytes is the size of the txs of checkTxs.
+const checkTxsBytes = 45
+
+// mustCheckTx checks the tx, failing t if it fails, and returns the response of
+// the app.
+func mustCheckTx(t *testing.T, txmp *TxMempool, tx string) *ocabci.ResponseCheckTx {
+	var res *ocabci.ResponseCheckTx
+	require.NoError(t, txmp.CheckTxSync([]byte(tx), func(r *ocabci.Response) {
+		res = r.GetCheckTx()
+	}, mempool.TxInfo{}))
+	require.NotNil(t, res)
+	return res
+}
+
+func newTestBlock(height int64, txs types.Txs) *types.Block {
+	return &types.Block{
+		Header: types.Header{
+			Height: height,
+		},
+		Data: types.Data{
+			Txs: txs,
+		},
+	}
 }
 
 func TestTxMempool_TxsAvailable(t *testing.T) {
@@ -135,373 +150,351 @@ func TestTxMempool_TxsAvailable(t *testing.T) {
 	txmp.EnableTxsAvailable()
 
 	ensureNoTxFire := func() {
-		timer := time.NewTimer(500 * time.Millisecond)
 		select {
 		case <-txmp.TxsAvailable():
-			require.Fail(t, "unexpected transactions event")
-		case <-timer.C:
+			t.Fatal("unexpected transactions event")
+		case <-time.After(500 * time.Millisecond):
 		}
 	}
-
 	ensureTxFire := func() {
-		timer := time.NewTimer(500 * time.Millisecond)
 		select {
 		case <-txmp.TxsAvailable():
-		case <-timer.C:
-			require.Fail(t, "expected transactions event")
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("expected transactions event")
 		}
 	}
 
 	// ensure no event as we have not executed any transactions yet
 	ensureNoTxFire()
 
-	// Execute CheckTx for some transactions and ensure TxsAvailable only fires
-	// once.
+	// the first txs fire the event just once
 	txs := checkTxs(t, txmp, 100, 0)
 	ensureTxFire()
 	ensureNoTxFire()
 
-	rawTxs := make([]types.Tx, len(txs))
-	for i, tx := range txs {
-		rawTxs[i] = tx.tx
-	}
-
-	responses := make([]*abci.ResponseDeliverTx, len(rawTxs[:50]))
-	for i := 0; i < len(responses); i++ {
-		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
-	}
-
-	// commit half the transactions and ensure we fire an event
+	// committing some of the txs fires the event again for the ones left
 	txmp.Lock()
-	require.NoError(t, txmp.Update(1, rawTxs[:50], responses, nil, nil))
+	require.NoError(t, txmp.Update(newTestBlock(1, rawTxs(txs[:50])), okResponses(50), nil, nil))
 	txmp.Unlock()
 	ensureTxFire()
 	ensureNoTxFire()
-
-	// Execute CheckTx for more transactions and ensure we do not fire another
-	// event as we're still on the same height (1).
-	_ = checkTxs(t, txmp, 100, 0)
-	ensureNoTxFire()
+	assert.Equal(t, 50, txmp.Size())
 }
 
 func TestTxMempool_Size(t *testing.T) {
 	txmp := setup(t, 0)
 	txs := checkTxs(t, txmp, 100, 0)
 	require.Equal(t, len(txs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
+	require.EqualValues(t, 100*checkTxsBytes, txmp.SizeBytes())
 
-	rawTxs := make([]types.Tx, len(txs))
-	for i, tx := range txs {
-		rawTxs[i] = tx.tx
-	}
+	txmp.Lock()
+	require.NoError(t, txmp.Update(newTestBlock(1, rawTxs(txs[:50])), okResponses(50), nil, nil))
+	txmp.Unlock()
 
-	responses := make([]*abci.ResponseDeliverTx, len(rawTxs[:50]))
-	for i := 0; i < len(responses); i++ {
-		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
-	}
+	require.Equal(t, len(txs)/2, txmp.Size())
+	require.EqualValues(t, 50*checkTxsBytes, txmp.SizeBytes())
+}
+
+func TestTxMempool_Flush(t *testing.T) {
+	txmp := setup(t, 100)
+	txs := checkTxs(t, txmp, 100, 0)
+	require.Equal(t, len(txs), txmp.Size())
 
 	txmp.Lock()
-	require.NoError(t, txmp.Update(1, rawTxs[:50], responses, nil, nil))
+	require.NoError(t, txmp.Update(newTestBlock(1, rawTxs(txs[:50])), okResponses(50), nil, nil))
 	txmp.Unlock()
 
-	require.Equal(t, len(rawTxs)/2, txmp.Size())
-	require.Equal(t, int64(2850), txmp.SizeBytes())
+	// the mempool and the cache are emptied
+	txmp.Flush()
+	require.Zero(t, txmp.Size())
+	require.Zero(t, txmp.SizeBytes())
+	require.Nil(t, txmp.TxsFront())
+	for _, tx := range txs {
+		assert.False(t, txmp.cache.Has(tx.tx))
+	}
+	mustCheckTx(t, txmp, string(txs[0].tx))
+	require.Equal(t, 1, txmp.Size())
 }
 
-func TestTxMempool_Eviction(t *testing.T) {
-	txmp := setup(t, 1000)
-	txmp.config.Size = 5
-	txmp.config.MaxTxsBytes = 60
-	txExists := func(spec string) bool {
-		txmp.Lock()
-		defer txmp.Unlock()
-		key := types.Tx(spec).Key()
-		_, ok := txmp.txByKey[key]
-		return ok
+// ensurePrioritized fails t unless reaped are the txs of txs of the highest
+// priorities, by decreasing priority.
+func ensurePrioritized(t *testing.T, txs []testTx, reaped types.Txs) {
+	t.Helper()
+	priorityByKey := make(map[types.TxKey]int64, len(txs))
+	priorities := make([]int64, len(txs))
+	for i, tx := range txs {
+		priorityByKey[tx.tx.Key()] = tx.priority
+		priorities[i] = tx.priority
 	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] > priorities[j] })
 
-	// A transaction bigger than the mempool should be rejected even when there
-	// are slots available.
-	mustCheckTx(t, txmp, "big=0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef=1")
-	require.Equal(t, 0, txmp.Size())
-
-	// Nearly-fill the mempool with a low-priority transaction, to show that it
-	// is evicted even when slots are available for a higher-priority tx.
-	const bigTx = "big=0123456789abcdef0123456789abcdef0123456789abcdef01234=2"
-	mustCheckTx(t, txmp, bigTx)
-	require.Equal(t, 1, txmp.Size()) // bigTx is the only element
-	require.True(t, txExists(bigTx))
-	require.Equal(t, int64(len(bigTx)), txmp.SizeBytes())
-
-	// The next transaction should evict bigTx, because it is higher priority
-	// but does not fit on size.
-	mustCheckTx(t, txmp, "key1=0000=25")
-	require.True(t, txExists("key1=0000=25"))
-	require.False(t, txExists(bigTx))
-	require.False(t, txmp.cache.Has([]byte(bigTx)))
-	require.Equal(t, int64(len("key1=0000=25")), txmp.SizeBytes())
-
-	// Now fill up the rest of the slots with other transactions.
-	mustCheckTx(t, txmp, "key2=0001=5")
-	mustCheckTx(t, txmp, "key3=0002=10")
-	mustCheckTx(t, txmp, "key4=0003=3")
-	mustCheckTx(t, txmp, "key5=0004=3")
-
-	// A new transaction with low priority should be discarded.
-	mustCheckTx(t, txmp, "key6=0005=1")
-	require.False(t, txExists("key6=0005=1"))
-
-	// A new transaction with higher priority should evict key5, which is the
-	// newest of the two transactions with lowest priority.
-	mustCheckTx(t, txmp, "key7=0006=7")
-	require.True(t, txExists("key7=0006=7"))  // new transaction added
-	require.False(t, txExists("key5=0004=3")) // newest low-priority tx evicted
-	require.True(t, txExists("key4=0003=3"))  // older low-priority tx retained
-
-	// Another new transaction evicts the other low-priority element.
-	mustCheckTx(t, txmp, "key8=0007=20")
-	require.True(t, txExists("key8=0007=20"))
-	require.False(t, txExists("key4=0003=3"))
-
-	// Now the lowest-priority tx is 5, so that should be the next to go.
-	mustCheckTx(t, txmp, "key9=0008=9")
-	require.True(t, txExists("key9=0008=9"))
-	require.False(t, txExists("k3y2=0001=5"))
-
-	// Add a transaction that requires eviction of multiple lower-priority
-	// entries, in order to fit the size of the element.
-	mustCheckTx(t, txmp, "key10=0123456789abcdef=11") // evict 10, 9, 7; keep 25, 20, 11
-	require.True(t, txExists("key1=0000=25"))
-	require.True(t, txExists("key8=0007=20"))
-	require.True(t, txExists("key10=0123456789abcdef=11"))
-	require.False(t, txExists("key3=0002=10"))
-	require.False(t, txExists("key9=0008=9"))
-	require.False(t, txExists("key7=0006=7"))
+	reapedPriorities := make([]int64, len(reaped))
+	for i, tx := range reaped {
+		reapedPriorities[i] = priorityByKey[tx.Key()]
+	}
+	require.Equal(t, priorities[:len(reaped)], reapedPriorities)
 }
 
-func TestTxMempool_Flush(t *testing.T) {
+func TestTxMempool_ReapMaxBytesMaxGas(t *testing.T) {
+	txmp := setup(t, 0)
+	txs := checkTxs(t, txmp, 100, 0) // all txs request 1 gas unit
+	require.Equal(t, len(txs), txmp.Size())
+
+	// reap by gas capacity only
+	reaped := txmp.ReapMaxBytesMaxGas(-1, 50)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, 50)
+
+	// reap by transaction bytes only, each tx taking 2 more bytes encoded
+	reaped = txmp.ReapMaxBytesMaxGas(1000, -1)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, 1000/(checkTxsBytes+2))
+
+	// reap by both transaction bytes and gas, where the size yields 31 reaped
+	// transactions and the gas limit 30
+	reaped = txmp.ReapMaxBytesMaxGas(1500, 30)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, 30)
+
+	// the txs are left in the mempool
+	require.Equal(t, len(txs), txmp.Size())
+	require.EqualValues(t, 100*checkTxsBytes, txmp.SizeBytes())
+}
+
+func TestTxMempool_ReapMaxTxs(t *testing.T) {
 	txmp := setup(t, 0)
 	txs := checkTxs(t, txmp, 100, 0)
 	require.Equal(t, len(txs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
 
-	rawTxs := make([]types.Tx, len(txs))
-	for i, tx := range txs {
-		rawTxs[i] = tx.tx
-	}
+	// reap all transactions
+	reaped := txmp.ReapMaxTxs(-1)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, len(txs))
 
-	responses := make([]*abci.ResponseDeliverTx, len(rawTxs[:50]))
-	for i := 0; i < len(responses); i++ {
-		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
-	}
+	// reap a single transaction
+	reaped = txmp.ReapMaxTxs(1)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, 1)
 
-	txmp.Lock()
-	require.NoError(t, txmp.Update(1, rawTxs[:50], responses, nil, nil))
-	txmp.Unlock()
+	// reap half of the transactions
+	reaped = txmp.ReapMaxTxs(len(txs) / 2)
+	ensurePrioritized(t, txs, reaped)
+	require.Len(t, reaped, len(txs)/2)
 
-	txmp.Flush()
-	require.Zero(t, txmp.Size())
-	require.Equal(t, int64(0), txmp.SizeBytes())
+	// the txs are left in the mempool
+	require.Equal(t, len(txs), txmp.Size())
+	require.EqualValues(t, 100*checkTxsBytes, txmp.SizeBytes())
 }
 
-func TestTxMempool_ReapMaxBytesMaxGas(t *testing.T) {
+func TestTxMempool_Reap(t *testing.T) {
 	txmp := setup(t, 0)
-	tTxs := checkTxs(t, txmp, 100, 0) // all txs request 1 gas unit
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-
-	txMap := make(map[types.TxKey]testTx)
-	priorities := make([]int64, len(tTxs))
-	for i, tTx := range tTxs {
-		txMap[tTx.tx.Key()] = tTx
-		priorities[i] = tTx.priority
+
+	for _, tx := range []string{"a=1", "b=3", "c=2", "d=3", "e=-1"} {
+		mustCheckTx(t, txmp, tx)
 	}
+	assert.Equal(t, 5, txmp.Size())
+	assert.EqualValues(t, 16, txmp.SizeBytes())
+
+	// the txs are reaped by priority, then by arrival
+	expected := types.Txs{[]byte("b=3"), []byte("d=3"), []byte("c=2"), []byte("a=1"), []byte("e=-1")}
+	assert.Equal(t, expected, txmp.ReapMaxTxs(-1))
+	assert.Equal(t, expected[:2], txmp.ReapMaxTxs(2))
+	assert.Equal(t, expected, txmp.ReapMaxBytesMaxGas(-1, -1))
+	// each tx has 1 gas wanted, and 5 bytes encoded but the last one
+	assert.Equal(t, expected[:3], txmp.ReapMaxBytesMaxGas(-1, 3))
+	assert.Equal(t, expected[:2], txmp.ReapMaxBytesMaxGas(14, -1))
+	assert.Equal(t, expected[:1], txmp.ReapMaxBytesMaxGasMaxTxs(-1, -1, 1))
+
+	// the gossip order is still the arrival one
+	assert.Equal(t, types.Tx("a=1"), txmp.TxsFront().Value.(*WrappedTx).tx)
+}
 
-	sort.Slice(priorities, func(i, j int) bool {
-		// sort by priority, i.e. decreasing order
-		return priorities[i] > priorities[j]
-	})
+func TestTxMempool_PendingTxs(t *testing.T) {
+	txmp := setup(t, 0)
 
-	ensurePrioritized := func(reapedTxs types.Txs) {
-		reapedPriorities := make([]int64, len(reapedTxs))
-		for i, rTx := range reapedTxs {
-			reapedPriorities[i] = txMap[rTx.Key()].priority
-		}
+	before := time.Now()
+	for _, tx := range []string{"a=1", "b=3", "c=2"} {
+		mustCheckTx(t, txmp, tx)
+	}
 
-		require.Equal(t, priorities[:len(reapedPriorities)], reapedPriorities)
+	// the txs are returned in the order they're reaped
+	pending := txmp.PendingTxs()
+	require.Len(t, pending, 3)
+	assert.Equal(t, txmp.ReapMaxTxs(-1), types.Txs{pending[0].Tx, pending[1].Tx, pending[2].Tx})
+	for i, priority := range []int64{3, 2, 1} {
+		assert.Equal(t, priority, pending[i].Priority)
+		assert.EqualValues(t, 1, pending[i].GasWanted)
+		assert.False(t, pending[i].Timestamp.Before(before))
 	}
 
-	// reap by gas capacity only
-	reapedTxs := txmp.ReapMaxBytesMaxGas(-1, 50)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.Len(t, reapedTxs, 50)
-
-	// reap by transaction bytes only
-	reapedTxs = txmp.ReapMaxBytesMaxGas(1000, -1)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.GreaterOrEqual(t, len(reapedTxs), 16)
-
-	// Reap by both transaction bytes and gas, where the size yields 31 reaped
-	// transactions and the gas limit reaps 25 transactions.
-	reapedTxs = txmp.ReapMaxBytesMaxGas(1500, 30)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.Len(t, reapedTxs, 25)
+	tx, ok := txmp.GetPendingTx(types.Tx("c=2").Key())
+	require.True(t, ok)
+	assert.Equal(t, pending[1], tx)
+	_, ok = txmp.GetPendingTx(types.Tx("d=4").Key())
+	assert.False(t, ok)
 }
 
-func TestTxMempool_ReapMaxTxs(t *testing.T) {
-	txmp := setup(t, 0)
-	tTxs := checkTxs(t, txmp, 100, 0)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-
-	txMap := make(map[types.TxKey]testTx)
-	priorities := make([]int64, len(tTxs))
-	for i, tTx := range tTxs {
-		txMap[tTx.tx.Key()] = tTx
-		priorities[i] = tTx.priority
+func TestTxMempool_Persist(t *testing.T) {
+	txmp := setup(t, 100)
+	for _, tx := range []string{"a=1", "b=3", "c=2"} {
+		mustCheckTx(t, txmp, tx)
 	}
+	file := filepath.Join(t.TempDir(), "mempool.txs")
+	require.NoError(t, mempool.SaveTxs(txmp, file))
 
-	sort.Slice(priorities, func(i, j int) bool {
-		// sort by priority, i.e. decreasing order
-		return priorities[i] > priorities[j]
-	})
+	// the txs are re-checked, with their priorities
+	restarted := setup(t, 100)
+	added, err := mempool.LoadTxs(restarted, file)
+	require.NoError(t, err)
+	assert.Equal(t, 3, added)
+	assert.Equal(t, txmp.ReapMaxTxs(-1), restarted.ReapMaxTxs(-1))
+	assert.ElementsMatch(t, txmp.CacheKeys(), restarted.CacheKeys())
+	assert.NoFileExists(t, file)
+}
 
-	ensurePrioritized := func(reapedTxs types.Txs) {
-		reapedPriorities := make([]int64, len(reapedTxs))
-		for i, rTx := range reapedTxs {
-			reapedPriorities[i] = txMap[rTx.Key()].priority
-		}
+func TestTxMempool_Eviction(t *testing.T) {
+	metrics := mempool.NopMetrics()
+	txmp := setup(t, 1000, WithMetrics(metrics))
+	txmp.config.Size = 3
 
-		require.Equal(t, priorities[:len(reapedPriorities)], reapedPriorities)
+	for _, tx := range []string{"a=2", "b=1", "c=1"} {
+		mustCheckTx(t, txmp, tx)
 	}
 
-	// reap all transactions
-	reapedTxs := txmp.ReapMaxTxs(-1)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.Len(t, reapedTxs, len(tTxs))
+	// a tx of a priority not higher than all the others is rejected when full
+	res := mustCheckTx(t, txmp, "d=1")
+	assert.NotEmpty(t, res.MempoolError)
+	assert.Equal(t, 3, txmp.Size())
+
+	// a higher priority one evicts the lowest priority and latest one
+	res = mustCheckTx(t, txmp, "e=3")
+	assert.Empty(t, res.MempoolError)
+	assert.Equal(t, types.Txs{[]byte("e=3"), []byte("a=2"), []byte("b=1")}, txmp.ReapMaxTxs(-1))
+
+	// the rejected and evicted txs can be added again later
+	assert.True(t, txmp.cache.Push([]byte("c=1")))
+	assert.True(t, txmp.cache.Push([]byte("d=1")))
+
+	// as many txs as needed are evicted for the ones of the bytes limit
+	txmp.config.Size = 10
+	txmp.config.MaxTxsBytes = txmp.SizeBytes()
+	mustCheckTx(t, txmp, "ff=4")
+	assert.Equal(t, types.Txs{[]byte("ff=4"), []byte("e=3")}, txmp.ReapMaxTxs(-1))
+
+	// nothing is evicted if there isn't room enough for the tx
+	res = mustCheckTx(t, txmp, "gggggggg=5")
+	assert.NotEmpty(t, res.MempoolError)
+	assert.Equal(t, types.Txs{[]byte("ff=4"), []byte("e=3")}, txmp.ReapMaxTxs(-1))
+}
 
-	// reap a single transaction
-	reapedTxs = txmp.ReapMaxTxs(1)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.Len(t, reapedTxs, 1)
+func TestTxMempool_RecheckPriority(t *testing.T) {
+	txmp := setup(t, 0)
+	txmp.config.Recheck = true
 
-	// reap half of the transactions
-	reapedTxs = txmp.ReapMaxTxs(len(tTxs) / 2)
-	ensurePrioritized(reapedTxs)
-	require.Equal(t, len(tTxs), txmp.Size())
-	require.Equal(t, int64(5690), txmp.SizeBytes())
-	require.Len(t, reapedTxs, len(tTxs)/2)
+	mustCheckTx(t, txmp, "a=1")
+	mustCheckTx(t, txmp, "b=2")
+	wtx := txmp.TxsFront().Value.(*WrappedTx)
+
+	// the priorities are updated by the rechecks
+	wtx.SetPriority(5)
+	assert.Equal(t, types.Txs{[]byte("a=1"), []byte("b=2")}, txmp.ReapMaxTxs(-1))
+	txmp.Lock()
+	require.NoError(t, txmp.Update(newTestBlock(1, nil), nil, nil, nil))
+	txmp.Unlock()
+	assert.EqualValues(t, 1, wtx.Priority())
+	assert.Equal(t, types.Txs{[]byte("b=2"), []byte("a=1")}, txmp.ReapMaxTxs(-1))
 }
 
 func TestTxMempool_CheckTxExceedsMaxSize(t *testing.T) {
 	txmp := setup(t, 0)
 
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	tx := make([]byte, txmp.config.MaxTxBytes+1)
-	_, err := rng.Read(tx)
+	_, err := rand.Read(tx)
 	require.NoError(t, err)
-
-	require.Error(t, txmp.CheckTx(tx, nil, mempool.TxInfo{SenderID: 0}))
+	require.Error(t, txmp.CheckTxSync(tx, nil, mempool.TxInfo{SenderID: 0}))
 
 	tx = make([]byte, txmp.config.MaxTxBytes-1)
-	_, err = rng.Read(tx)
+	_, err = rand.Read(tx)
 	require.NoError(t, err)
-
-	require.NoError(t, txmp.CheckTx(tx, nil, mempool.TxInfo{SenderID: 0}))
+	require.NoError(t, txmp.CheckTxSync(tx, nil, mempool.TxInfo{SenderID: 0}))
 }
 
 func TestTxMempool_CheckTxSamePeer(t *testing.T) {
 	txmp := setup(t, 100)
 	peerID := uint16(1)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	prefix := make([]byte, 20)
-	_, err := rng.Read(prefix)
-	require.NoError(t, err)
-
-	tx := []byte(fmt.Sprintf("sender-0=%X=%d", prefix, 50))
-
-	require.NoError(t, txmp.CheckTx(tx, nil, mempool.TxInfo{SenderID: peerID}))
-	require.Error(t, txmp.CheckTx(tx, nil, mempool.TxInfo{SenderID: peerID}))
+	tx := []byte("a=50")
+	require.NoError(t, txmp.CheckTxSync(tx, nil, mempool.TxInfo{SenderID: peerID}))
+	require.ErrorIs(t, txmp.CheckTxSync(tx, nil, mempool.TxInfo{SenderID: peerID}), mempool.ErrTxInMap)
 }
 
 func TestTxMempool_CheckTxSameSender(t *testing.T) {
 	txmp := setup(t, 100)
-	peerID := uint16(1)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	prefix1 := make([]byte, 20)
-	_, err := rng.Read(prefix1)
-	require.NoError(t, err)
 
-	prefix2 := make([]byte, 20)
-	_, err = rng.Read(prefix2)
-	require.NoError(t, err)
+	// the mempool has one tx per sender assigned by the app
+	mustCheckTx(t, txmp, "sender=a=50")
+	require.Equal(t, 1, txmp.Size())
+	res := mustCheckTx(t, txmp, "sender=b=60")
+	assert.NotEmpty(t, res.MempoolError)
+	require.Equal(t, types.Txs{[]byte("sender=a=50")}, txmp.ReapMaxTxs(-1))
 
-	tx1 := []byte(fmt.Sprintf("sender-0=%X=%d", prefix1, 50))
-	tx2 := []byte(fmt.Sprintf("sender-0=%X=%d", prefix2, 50))
+	// the txs without a sender aren't restricted
+	mustCheckTx(t, txmp, "c=50")
+	mustCheckTx(t, txmp, "d=50")
+	require.Equal(t, 3, txmp.Size())
 
-	require.NoError(t, txmp.CheckTx(tx1, nil, mempool.TxInfo{SenderID: peerID}))
-	require.Equal(t, 1, txmp.Size())
-	require.NoError(t, txmp.CheckTx(tx2, nil, mempool.TxInfo{SenderID: peerID}))
-	require.Equal(t, 1, txmp.Size())
+	// the rejected tx can be added once the tx of its sender is committed
+	txmp.Lock()
+	require.NoError(t, txmp.Update(newTestBlock(1, types.Txs{[]byte("sender=a=50")}), okResponses(1), nil, nil))
+	txmp.Unlock()
+	res = mustCheckTx(t, txmp, "sender=b=60")
+	assert.Empty(t, res.MempoolError)
+	require.Equal(t, types.Txs{[]byte("sender=b=60"), []byte("c=50"), []byte("d=50")}, txmp.ReapMaxTxs(-1))
+
+	// or evicted
+	txmp.config.Size = 3
+	for _, tx := range []string{"e=70", "f=70", "g=70"} {
+		mustCheckTx(t, txmp, tx)
+	}
+	require.Equal(t, types.Txs{[]byte("e=70"), []byte("f=70"), []byte("g=70")}, txmp.ReapMaxTxs(-1))
+	res = mustCheckTx(t, txmp, "sender=h=80")
+	assert.Empty(t, res.MempoolError)
+	require.Equal(t, types.Txs{[]byte("sender=h=80"), []byte("e=70"), []byte("f=70")}, txmp.ReapMaxTxs(-1))
 }
 
 func TestTxMempool_ConcurrentTxs(t *testing.T) {
 	txmp := setup(t, 100)
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	checkTxDone := make(chan struct{})
 
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func() {
+		defer wg.Done()
+		defer close(checkTxDone)
 		for i := 0; i < 20; i++ {
 			_ = checkTxs(t, txmp, 100, 0)
-			dur := rng.Intn(1000-500) + 500
-			time.Sleep(time.Duration(dur) * time.Millisecond)
+			time.Sleep(time.Duration(mrand.Intn(50)+50) * time.Millisecond)
 		}
-
-		wg.Done()
-		close(checkTxDone)
 	}()
 
 	wg.Add(1)
 	go func() {
-		ticker := time.NewTicker(time.Second)
+		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
 		defer wg.Done()
 
 		var height int64 = 1
-
 		for range ticker.C {
-			reapedTxs := txmp.ReapMaxTxs(200)
-			if len(reapedTxs) > 0 {
-				responses := make([]*abci.ResponseDeliverTx, len(reapedTxs))
-				for i := 0; i < len(responses); i++ {
-					var code uint32
-
-					if i%10 == 0 {
-						code = 100
-					} else {
-						code = abci.CodeTypeOK
-					}
-
-					responses[i] = &abci.ResponseDeliverTx{Code: code}
+			reaped := txmp.ReapMaxTxs(200)
+			if len(reaped) > 0 {
+				responses := okResponses(len(reaped))
+				for i := 0; i < len(responses); i += 10 {
+					responses[i].Code = 100
 				}
 
 				txmp.Lock()
-				require.NoError(t, txmp.Update(height, reapedTxs, responses, nil, nil))
+				require.NoError(t, txmp.Update(newTestBlock(height, reaped), responses, nil, nil))
 				txmp.Unlock()
 
 				height++
@@ -523,7 +516,7 @@ func TestTxMempool_ConcurrentTxs(t *testing.T) {
 
 func TestTxMempool_ExpiredTxs_Timestamp(t *testing.T) {
 	txmp := setup(t, 5000)
-	txmp.config.TTLDuration = 5 * time.Millisecond
+	txmp.config.TTLDuration = 100 * time.Millisecond
 
 	added1 := checkTxs(t, txmp, 10, 0)
 	require.Equal(t, len(added1), txmp.Size())
@@ -531,42 +524,37 @@ func TestTxMempool_ExpiredTxs_Timestamp(t *testing.T) {
 	// Wait a while, then add some more transactions that should not be expired
 	// when the first batch TTLs out.
 	//
-	// ms: 0   1   2   3   4   5   6
-	//     ^           ^       ^   ^
-	//     |           |       |   +-- Update (triggers pruning)
-	//     |           |       +------ first batch expires
-	//     |           +-------------- second batch added
-	//     +-------------------------- first batch added
-	//
-	// The exact intervals are not important except that the delta should be
-	// large relative to the cost of CheckTx (ms vs. ns is fine here).
-	time.Sleep(3 * time.Millisecond)
+	// ms: 0   60   100  120
+	//     ^   ^    ^    ^
+	//     |   |    |    +-- Update (triggers pruning)
+	//     |   |    +------- first batch expires
+	//     |   +------------ second batch added
+	//     +---------------- first batch added
+	time.Sleep(60 * time.Millisecond)
 	added2 := checkTxs(t, txmp, 10, 1)
 
 	// Wait a while longer, so that the first batch will expire.
-	time.Sleep(3 * time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
 
 	// Trigger an update so that pruning will occur.
 	txmp.Lock()
 	defer txmp.Unlock()
-	require.NoError(t, txmp.Update(txmp.height+1, nil, nil, nil, nil))
+	require.NoError(t, txmp.Update(newTestBlock(txmp.height+1, nil), nil, nil, nil))
 
-	// All the transactions in the original set should have been purged.
+	// All the transactions in the original set should have been purged, and
+	// kept in the cache not to be received again.
 	for _, tx := range added1 {
-		if _, ok := txmp.txByKey[tx.tx.Key()]; ok {
-			t.Errorf("Transaction %X should have been purged for TTL", tx.tx.Key())
-		}
-		if txmp.cache.Has(tx.tx) {
-			t.Errorf("Transaction %X should have been removed from the cache", tx.tx.Key())
-		}
+		_, ok := txmp.txsMap.Load(tx.tx.Key())
+		assert.False(t, ok, "tx %X should have been purged for TTL", tx.tx.Key())
+		assert.True(t, txmp.cache.Has(tx.tx), "tx %X should still be in the cache", tx.tx.Key())
 	}
 
 	// All the transactions added later should still be around.
 	for _, tx := range added2 {
-		if _, ok := txmp.txByKey[tx.tx.Key()]; !ok {
-			t.Errorf("Transaction %X should still be in the mempool, but is not", tx.tx.Key())
-		}
+		_, ok := txmp.txsMap.Load(tx.tx.Key())
+		assert.True(t, ok, "tx %X should still be in the mempool", tx.tx.Key())
 	}
+	assert.Equal(t, len(added2), txmp.Size())
 }
 
 func TestTxMempool_ExpiredTxs_NumBlocks(t *testing.T) {
@@ -574,45 +562,41 @@ func TestTxMempool_ExpiredTxs_NumBlocks(t *testing.T) {
 	txmp.height = 100
 	txmp.config.TTLNumBlocks = 10
 
-	tTxs := checkTxs(t, txmp, 100, 0)
-	require.Equal(t, len(tTxs), txmp.Size())
-
-	// reap 5 txs at the next height -- no txs should expire
-	reapedTxs := txmp.ReapMaxTxs(5)
-	responses := make([]*abci.ResponseDeliverTx, len(reapedTxs))
-	for i := 0; i < len(responses); i++ {
-		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
-	}
+	added1 := checkTxs(t, txmp, 100, 0)
+	require.Equal(t, len(added1), txmp.Size())
 
+	// commit 5 txs at the next height -- no txs should expire
+	reaped := txmp.ReapMaxTxs(5)
 	txmp.Lock()
-	require.NoError(t, txmp.Update(txmp.height+1, reapedTxs, responses, nil, nil))
+	require.NoError(t, txmp.Update(newTestBlock(txmp.height+1, reaped), okResponses(len(reaped)), nil, nil))
 	txmp.Unlock()
-
 	require.Equal(t, 95, txmp.Size())
 
 	// check more txs at height 101
-	_ = checkTxs(t, txmp, 50, 1)
+	added2 := checkTxs(t, txmp, 50, 1)
 	require.Equal(t, 145, txmp.Size())
 
-	// Reap 5 txs at a height that would expire all the transactions from before
-	// the previous Update (height 100).
-	//
-	// NOTE: When we reap txs below, we do not know if we're picking txs from the
-	// initial CheckTx calls or from the second round of CheckTx calls. Thus, we
-	// cannot guarantee that all 95 txs are remaining that should be expired and
-	// removed. However, we do know that that at most 95 txs can be expired and
-	// removed.
-	reapedTxs = txmp.ReapMaxTxs(5)
-	responses = make([]*abci.ResponseDeliverTx, len(reapedTxs))
-	for i := 0; i < len(responses); i++ {
-		responses[i] = &abci.ResponseDeliverTx{Code: abci.CodeTypeOK}
-	}
-
+	// Commit 5 txs at the height expiring all the txs checked at height 100,
+	// but not the ones checked at height 101.
+	reaped = txmp.ReapMaxTxs(5)
 	txmp.Lock()
-	require.NoError(t, txmp.Update(txmp.height+10, reapedTxs, responses, nil, nil))
+	require.NoError(t, txmp.Update(newTestBlock(txmp.height+9, reaped), okResponses(len(reaped)), nil, nil))
 	txmp.Unlock()
 
-	require.GreaterOrEqual(t, txmp.Size(), 45)
+	committed := make(map[types.TxKey]bool, len(reaped))
+	for _, tx := range reaped {
+		committed[tx.Key()] = true
+	}
+	left := 0
+	for _, tx := range added2 {
+		if !committed[tx.tx.Key()] {
+			left++
+		}
+	}
+	require.Equal(t, left, txmp.Size())
+	for e := txmp.TxsFront(); e != nil; e = e.Next() {
+		assert.EqualValues(t, 101, e.Value.(*WrappedTx).Height())
+	}
 }
 
 func TestTxMempool_CheckTxPostCheckError(t *testing.T) {
@@ -632,25 +616,74 @@ func TestTxMempool_CheckTxPostCheckError(t *testing.T) {
 	for _, tc := range cases {
 		testCase := tc
 		t.Run(testCase.name, func(t *testing.T) {
-			postCheckFn := func(_ types.Tx, _ *abci.ResponseCheckTx) error {
+			postCheckFn := func(_ types.Tx, _ *ocabci.ResponseCheckTx) error {
 				return testCase.err
 			}
 			txmp := setup(t, 0, WithPostCheck(postCheckFn))
-			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-			tx := make([]byte, txmp.config.MaxTxBytes-1)
-			_, err := rng.Read(tx)
-			require.NoError(t, err)
-
-			callback := func(res *abci.Response) {
-				checkTxRes, ok := res.Value.(*abci.Response_CheckTx)
-				require.True(t, ok)
-				expectedErrString := ""
-				if testCase.err != nil {
-					expectedErrString = testCase.err.Error()
-				}
-				require.Equal(t, expectedErrString, checkTxRes.CheckTx.MempoolError)
+			tx := []byte("a=1")
+			mustCheckTx(t, txmp, string(tx))
+
+			// the post check applies to the rechecks, evicting the tx on error
+			req := ocabci.ToRequestCheckTx(abci.RequestCheckTx{Tx: tx, Type: abci.CheckTxType_Recheck})
+			res := ocabci.ToResponseCheckTx(ocabci.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: 1, Priority: 1})
+			txmp.resCbRecheck(req, res)
+
+			expectedErrString := ""
+			if testCase.err != nil {
+				expectedErrString = testCase.err.Error()
 			}
-			require.NoError(t, txmp.CheckTx(tx, callback, mempool.TxInfo{SenderID: 0}))
+			require.Equal(t, expectedErrString, res.GetCheckTx().MempoolError)
+			require.Equal(t, testCase.err == nil, txmp.Size() == 1)
 		})
 	}
 }
+
+// batchApplication checks the txs of a batch in one call.
+type batchApplication struct {
+	application
+
+	mtx     sync.Mutex
+	batches []int // sizes of the batches checked
+}
+
+func (app *batchApplication) CheckTxBatchAsync(reqs []abci.RequestCheckTx, callback ocabci.CheckTxBatchCallback) {
+	app.mtx.Lock()
+	app.batches = append(app.batches, len(reqs))
+	app.mtx.Unlock()
+
+	res := make([]ocabci.ResponseCheckTx, len(reqs))
+	for i, req := range reqs {
+		res[i] = app.CheckTxSync(req)
+	}
+	callback(res)
+}
+
+func TestTxMempool_CheckTxBatch(t *testing.T) {
+	app := &batchApplication{application: application{kvstore.NewApplication()}}
+	cc := proxy.NewLocalClientCreator(app)
+	cfg := config.ResetTestRoot("mempool_test")
+	cfg.Mempool.CheckTxBatchWindow = 100 * time.Millisecond
+	appConnMem, err := cc.NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConnMem.Start())
+	t.Cleanup(func() {
+		os.RemoveAll(cfg.RootDir)
+		require.NoError(t, appConnMem.Stop())
+	})
+	txmp := NewTxMempool(cfg.Mempool, appConnMem, 0)
+	txmp.SetLogger(log.TestingLogger())
+
+	// the txs requested within the window are checked in one call, with their
+	// priorities
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for _, tx := range []string{"a=1", "b=3", "c=2"} {
+		txmp.CheckTxAsync([]byte(tx), mempool.TxInfo{}, nil, func(*ocabci.Response) { wg.Done() })
+	}
+	wg.Wait()
+	require.Equal(t, types.Txs{[]byte("b=3"), []byte("c=2"), []byte("a=1")}, txmp.ReapMaxTxs(-1))
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	assert.Equal(t, []int{3}, app.batches)
+}
@@ -1,5 +1,3 @@
-//go:build deprecated
-
 package v1
 
 import (
@@ -9,16 +7,23 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 
-	cfg "github.com/tendermint/tendermint/config"
-	"github.com/tendermint/tendermint/libs/clist"
-	"github.com/tendermint/tendermint/libs/log"
-	tmsync "github.com/tendermint/tendermint/libs/sync"
-	"github.com/tendermint/tendermint/mempool"
-	"github.com/tendermint/tendermint/p2p"
 	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
-	"github.com/tendermint/tendermint/types"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/clist"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/ratelimit"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/mempool"
+	"github.com/Finschia/ostracon/p2p"
+	ocmempl "github.com/Finschia/ostracon/proto/ostracon/mempool"
+	"github.com/Finschia/ostracon/types"
 )
 
+// peerLogInterval is the minimum interval between the same messages logged for
+// a peer, so that a misbehaving peer can't flood the logs.
+const peerLogInterval = time.Second
+
 // Reactor handles mempool tx broadcasting amongst peers.
 // It maintains a map from peer ID to counter, to prevent gossiping txs to the
 // peers you received it from.
@@ -27,6 +32,10 @@ type Reactor struct {
 	config  *cfg.MempoolConfig
 	mempool *TxMempool
 	ids     *mempoolIDs
+	limiter *ratelimit.Keyed      // rate of the txs received by peer (nil if unlimited)
+	haveTx  *mempool.HaveTxGossip // announces the txs, remembers the ones of the peers (nil if disabled)
+
+	peerLogger log.Logger // samples the messages logged for each received message
 }
 
 type mempoolIDs struct {
@@ -93,13 +102,24 @@ func newMempoolIDs() *mempoolIDs {
 }
 
 // NewReactor returns a new Reactor with the given config and mempool.
-func NewReactor(config *cfg.MempoolConfig, mempool *TxMempool) *Reactor {
+func NewReactor(config *cfg.MempoolConfig, async bool, recvBufSize int, mp *TxMempool) *Reactor {
 	memR := &Reactor{
 		config:  config,
-		mempool: mempool,
+		mempool: mp,
 		ids:     newMempoolIDs(),
 	}
-	memR.BaseReactor = *p2p.NewBaseReactor("Mempool", memR)
+	if config.PeerTxRate > 0 || config.TotalPeerTxRate > 0 {
+		var total ratelimit.Limiter
+		if config.TotalPeerTxRate > 0 {
+			total = ratelimit.NewTokenBucket(config.TotalPeerTxRate, config.TotalPeerTxBurst)
+		}
+		memR.limiter = ratelimit.NewKeyed(func() ratelimit.Limiter {
+			return ratelimit.NewTokenBucket(config.PeerTxRate, config.PeerTxBurst)
+		}, total, 0)
+	}
+	memR.haveTx = mempool.NewHaveTxGossip(config.HaveTxFilterSize)
+	memR.BaseReactor = *p2p.NewBaseReactor("Mempool", memR, async, recvBufSize)
+	memR.peerLogger = log.NewSampledLogger(memR.Logger, peerLogInterval, "src")
 	return memR
 }
 
@@ -112,13 +132,24 @@ func (memR *Reactor) InitPeer(peer p2p.Peer) p2p.Peer {
 // SetLogger sets the Logger on the reactor and the underlying mempool.
 func (memR *Reactor) SetLogger(l log.Logger) {
 	memR.Logger = l
+	memR.peerLogger = log.NewSampledLogger(l, peerLogInterval, "src")
+	memR.mempool.SetLogger(l)
 }
 
 // OnStart implements p2p.BaseReactor.
 func (memR *Reactor) OnStart() error {
+	// call BaseReactor's OnStart()
+	err := memR.BaseReactor.OnStart()
+	if err != nil {
+		return err
+	}
+
 	if !memR.config.Broadcast {
 		memR.Logger.Info("Tx broadcasting is disabled")
 	}
+	if memR.haveTx != nil {
+		go memR.haveTx.AnnounceTxsRoutine(memR.Switch.Peers().List, memR.Quit(), memR.Logger)
+	}
 	return nil
 }
 
@@ -132,14 +163,22 @@ func (memR *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 		},
 	}
 
-	return []*p2p.ChannelDescriptor{
+	channels := []*p2p.ChannelDescriptor{
 		{
 			ID:                  mempool.MempoolChannel,
 			Priority:            5,
 			RecvMessageCapacity: batchMsg.Size(),
 			MessageType:         &protomem.Message{},
+			SendRateLimit:       memR.config.PeerSendRate,
+			RecvRateLimit:       memR.config.PeerRecvRate,
+			// the txs are gossiped by the other peers as well
+			RecvQueueDrop: true,
 		},
 	}
+	if memR.haveTx != nil {
+		channels = append(channels, memR.haveTx.ChannelDescriptor())
+	}
+	return channels
 }
 
 // AddPeer implements Reactor.
@@ -153,9 +192,33 @@ func (memR *Reactor) AddPeer(peer p2p.Peer) {
 // RemovePeer implements Reactor.
 func (memR *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
 	memR.ids.Reclaim(peer)
+	if memR.limiter != nil {
+		memR.limiter.Remove(string(peer.ID()))
+	}
+	memR.haveTx.RemovePeer(peer.ID())
 	// broadcast routine checks if peer is gone and returns
 }
 
+// ValidateMessage implements p2p.MessageValidator.
+// It drops the empty txs messages, the ones with a tx over
+// mempool.max_tx_bytes and the invalid have tx ones before they are queued.
+func (memR *Reactor) ValidateMessage(chID byte, msg proto.Message) error {
+	switch msg := msg.(type) {
+	case *protomem.Txs:
+		if len(msg.GetTxs()) == 0 {
+			return errors.New("no txs")
+		}
+		for _, tx := range msg.GetTxs() {
+			if len(tx) > memR.config.MaxTxBytes {
+				return fmt.Errorf("tx too large: %d > %d", len(tx), memR.config.MaxTxBytes)
+			}
+		}
+	case *ocmempl.HaveTx:
+		return mempool.ValidateHaveTx(msg.GetKeys())
+	}
+	return nil
+}
+
 // Receive implements Reactor.
 // It adds any received transactions to the mempool.
 func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
@@ -164,26 +227,50 @@ func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 	case *protomem.Txs:
 		protoTxs := msg.GetTxs()
 		if len(protoTxs) == 0 {
-			memR.Logger.Error("received tmpty txs from peer", "src", e.Src)
+			memR.peerLogger.Error("received empty txs from peer", "src", e.Src)
 			return
 		}
 		txInfo := mempool.TxInfo{SenderID: memR.ids.GetForPeer(e.Src)}
 		if e.Src != nil {
 			txInfo.SenderP2PID = e.Src.ID()
 		}
+		if e.Src != nil {
+			memR.haveTx.Received(e.Src.ID(), protoTxs)
+		}
+
+		if memR.limiter != nil {
+			now := time.Now()
+			for i := range protoTxs {
+				if !memR.limiter.AllowN(string(txInfo.SenderP2PID), now, 1) {
+					memR.peerLogger.Debug("Dropped txs over the rate limit", "src", e.Src, "txs", len(protoTxs)-i)
+					protoTxs = protoTxs[:i]
+					break
+				}
+			}
+		}
 
-		var err error
 		for _, tx := range protoTxs {
 			ntx := types.Tx(tx)
-			err = memR.mempool.CheckTx(ntx, nil, txInfo)
-			if errors.Is(err, mempool.ErrTxInCache) {
-				memR.Logger.Debug("Tx already exists in cache", "tx", ntx.String())
-			} else if err != nil {
-				memR.Logger.Info("Could not check tx", "tx", ntx.String(), "err", err)
-			}
+			memR.mempool.CheckTxAsync(tx, txInfo, func(err error) {
+				if errors.Is(err, mempool.ErrTxInMap) {
+					memR.Logger.Debug("Tx already exists in Map", "tx", ntx.String())
+				} else if errors.Is(err, mempool.ErrTxInCache) {
+					memR.Logger.Debug("Tx already exists in cache", "tx", ntx.String())
+				} else if err != nil {
+					memR.peerLogger.Info("Could not check tx", "src", e.Src, "tx", ntx.String(), "err", err)
+				}
+			}, memR.haveTx.AnnounceCb(ntx.Key()))
 		}
+	case *ocmempl.HaveTx:
+		keys := msg.GetKeys()
+		if err := mempool.ValidateHaveTx(keys); err != nil {
+			memR.peerLogger.Error("received invalid have tx from peer", "src", e.Src, "err", err)
+			memR.Switch.ReportPeerBehavior(e.Src.ID(), p2p.InvalidMessage(err.Error()))
+			return
+		}
+		memR.haveTx.ReceiveHaveTx(e.Src.ID(), keys)
 	default:
-		memR.Logger.Error("unknown message type", "src", e.Src, "chId", e.ChannelID, "msg", e.Message)
+		memR.peerLogger.Error("unknown message type", "src", e.Src, "chId", e.ChannelID, "msg", e.Message)
 		memR.Switch.StopPeerForError(e.Src, fmt.Errorf("mempool cannot handle message of type: %T", e.Message))
 		return
 	}
@@ -192,7 +279,10 @@ func (memR *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 }
 
 func (memR *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
-	msg := &protomem.Message{}
+	var msg p2p.Unwrapper = &protomem.Message{}
+	if chID == mempool.MempoolHaveTxChannel {
+		msg = &ocmempl.Message{}
+	}
 	err := proto.Unmarshal(msgBytes, msg)
 	if err != nil {
 		panic(err)
@@ -223,7 +313,6 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 		if !memR.IsRunning() || !peer.IsRunning() {
 			return
 		}
-
 		// This happens because the CElement we were looking at got garbage
 		// collected (removed). That is, .NextWait() returned nil. Go ahead and
 		// start from the beginning.
@@ -233,10 +322,8 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 				if next = memR.mempool.TxsFront(); next == nil {
 					continue
 				}
-
 			case <-peer.Quit():
 				return
-
 			case <-memR.Quit():
 				return
 			}
@@ -256,14 +343,15 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 
 		// Allow for a lag of 1 block.
 		memTx := next.Value.(*WrappedTx)
-		if peerState.GetHeight() < memTx.height-1 {
+		if peerState.GetHeight() < memTx.Height()-1 {
 			time.Sleep(mempool.PeerCatchupSleepIntervalMS * time.Millisecond)
 			continue
 		}
 
 		// NOTE: Transaction batching was disabled due to
 		// https://github.com/tendermint/tendermint/issues/5796
-		if !memTx.HasPeer(peerID) {
+
+		if !memTx.HasPeer(peerID) && !memR.knows(peer, memTx.tx) {
 			success := p2p.SendEnvelopeShim(peer, p2p.Envelope{ //nolint: staticcheck
 				ChannelID: mempool.MempoolChannel,
 				Message:   &protomem.Txs{Txs: [][]byte{memTx.tx}},
@@ -272,24 +360,25 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 				time.Sleep(mempool.PeerCatchupSleepIntervalMS * time.Millisecond)
 				continue
 			}
+			memR.haveTx.Sent(peer.ID(), memTx.tx.Key())
 		}
 
 		select {
 		case <-next.NextWaitChan():
 			// see the start of the for loop for nil check
 			next = next.Next()
-
 		case <-peer.Quit():
 			return
-
 		case <-memR.Quit():
 			return
 		}
 	}
 }
 
-//-----------------------------------------------------------------------------
-// Messages
+// knows reports whether the peer is known to have the tx.
+func (memR *Reactor) knows(peer p2p.Peer, tx types.Tx) bool {
+	return memR.haveTx.Knows(peer.ID(), tx.Key())
+}
 
 // TxsMessage is a Message containing transactions.
 type TxsMessage struct {
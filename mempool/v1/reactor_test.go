@@ -1,5 +1,3 @@
-//go:build deprecated
-
 package v1
 
 import (
@@ -14,17 +12,17 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/tendermint/tendermint/abci/example/kvstore"
-	"github.com/tendermint/tendermint/p2p/mock"
-
-	cfg "github.com/tendermint/tendermint/config"
-
-	"github.com/tendermint/tendermint/libs/log"
-	"github.com/tendermint/tendermint/mempool"
-	"github.com/tendermint/tendermint/p2p"
 	memproto "github.com/tendermint/tendermint/proto/tendermint/mempool"
-	"github.com/tendermint/tendermint/proxy"
-	"github.com/tendermint/tendermint/types"
+
+	"github.com/Finschia/ostracon/abci/example/kvstore"
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/mempool"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/mock"
+	ocmempl "github.com/Finschia/ostracon/proto/ostracon/mempool"
+	"github.com/Finschia/ostracon/proxy"
+	"github.com/Finschia/ostracon/types"
 )
 
 const (
@@ -49,6 +47,11 @@ func TestReactorBroadcastTxsMessage(t *testing.T) {
 	// replace Connect2Switches (full mesh) with a func, which connects first
 	// reactor to others and nothing else, this test should also pass with >2 reactors.
 	const N = 2
+
+	// In this test, a reactor receives 1000 tx message from a peer.
+	// A reactor has N peer, so up to (N-1)×1000 txs can be stacked
+	config.P2P.MempoolRecvBufSize = (N - 1) * 1000
+
 	reactors := makeAndConnectReactors(config, N)
 	defer func() {
 		for _, r := range reactors {
@@ -63,13 +66,8 @@ func TestReactorBroadcastTxsMessage(t *testing.T) {
 		}
 	}
 
-	txs := checkTxs(t, reactors[0].mempool, numTxs, mempool.UnknownPeerID)
-	transactions := make(types.Txs, len(txs))
-	for idx, tx := range txs {
-		transactions[idx] = tx.tx
-	}
-
-	waitForTxsOnReactors(t, transactions, reactors)
+	txs := rawTxs(checkTxs(t, reactors[0].mempool, numTxs, mempool.UnknownPeerID))
+	waitForTxsOnReactors(t, txs, reactors)
 }
 
 func TestMempoolVectors(t *testing.T) {
@@ -126,6 +124,76 @@ func TestLegacyReactorReceiveBasic(t *testing.T) {
 	})
 }
 
+func TestReactorHaveTx(t *testing.T) {
+	config := cfg.TestConfig()
+	const N = 2
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			if err := r.Stop(); err != nil {
+				assert.NoError(t, err)
+			}
+		}
+	}()
+	for _, r := range reactors {
+		for _, peer := range r.Switch.Peers().List() {
+			peer.Set(types.PeerStateKey, peerState{1})
+		}
+	}
+
+	// the txs announced by the peer aren't sent to it
+	peer := reactors[0].Switch.Peers().List()[0]
+	txs := types.Txs{types.Tx("a=1"), types.Tx("b=2")}
+	keys := make([][]byte, len(txs))
+	for i, tx := range txs {
+		key := tx.Key()
+		keys[i] = key[:]
+	}
+	reactors[0].ReceiveEnvelope(p2p.Envelope{
+		ChannelID: mempool.MempoolHaveTxChannel,
+		Src:       peer,
+		Message:   &ocmempl.HaveTx{Keys: keys},
+	})
+	for _, tx := range txs {
+		assert.True(t, reactors[0].knows(peer, tx))
+		require.NoError(t, reactors[0].mempool.CheckTxSync(tx, nil, mempool.TxInfo{}))
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, reactors[1].mempool.Size())
+}
+
+func TestReactorValidateMessage(t *testing.T) {
+	config := cfg.TestConfig()
+	reactor := makeAndConnectReactors(config, 1)[0]
+	defer func() {
+		assert.NoError(t, reactor.Stop())
+	}()
+
+	tx := types.Tx("a=1")
+	key := tx.Key()
+	testCases := []struct {
+		name      string
+		chID      byte
+		msg       proto.Message
+		expectErr bool
+	}{
+		{"txs", mempool.MempoolChannel, &memproto.Txs{Txs: [][]byte{tx}}, false},
+		{"no txs", mempool.MempoolChannel, &memproto.Txs{}, true},
+		{"tx too large", mempool.MempoolChannel,
+			&memproto.Txs{Txs: [][]byte{make([]byte, config.Mempool.MaxTxBytes+1)}}, true},
+		{"have tx", mempool.MempoolHaveTxChannel, &ocmempl.HaveTx{Keys: [][]byte{key[:]}}, false},
+		{"have tx invalid key", mempool.MempoolHaveTxChannel, &ocmempl.HaveTx{Keys: [][]byte{key[1:]}}, true},
+	}
+	for _, tc := range testCases {
+		err := reactor.ValidateMessage(tc.chID, tc.msg)
+		if tc.expectErr {
+			assert.Error(t, err, tc.name)
+		} else {
+			assert.NoError(t, err, tc.name)
+		}
+	}
+}
+
 func makeAndConnectReactors(config *cfg.Config, n int) []*Reactor {
 	reactors := make([]*Reactor, n)
 	logger := mempoolLogger()
@@ -135,11 +203,12 @@ func makeAndConnectReactors(config *cfg.Config, n int) []*Reactor {
 		mempool, cleanup := newMempoolWithApp(cc)
 		defer cleanup()
 
-		reactors[i] = NewReactor(config.Mempool, mempool) // so we dont start the consensus states
+		// so we dont start the consensus states
+		reactors[i] = NewReactor(config.Mempool, config.P2P.RecvAsync, config.P2P.MempoolRecvBufSize, mempool)
 		reactors[i].SetLogger(logger.With("validator", i))
 	}
 
-	p2p.MakeConnectedSwitches(config.P2P, n, func(i int, s *p2p.Switch) *p2p.Switch {
+	p2p.MakeConnectedSwitches(config.P2P, n, func(i int, s *p2p.Switch, config *cfg.P2PConfig) *p2p.Switch {
 		s.AddReactor("MEMPOOL", reactors[i])
 		return s
 
@@ -175,7 +244,8 @@ func newMempoolWithAppAndConfig(cc proxy.ClientCreator, conf *cfg.Config) (*TxMe
 		panic(err)
 	}
 
-	mp := NewTxMempool(log.TestingLogger(), conf.Mempool, appConnMem, 0)
+	mp := NewTxMempool(conf.Mempool, appConnMem, 0)
+	mp.SetLogger(log.TestingLogger())
 
 	return mp, func() { os.RemoveAll(conf.RootDir) }
 }
@@ -1,48 +1,43 @@
-//go:build deprecated
-
 package v1
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/tendermint/tendermint/types"
+	"github.com/Finschia/ostracon/mempool"
+	"github.com/Finschia/ostracon/types"
 )
 
 // WrappedTx defines a wrapper around a raw transaction with additional metadata
 // that is used for indexing.
 type WrappedTx struct {
-	tx        types.Tx    // the original transaction data
-	hash      types.TxKey // the transaction hash
-	height    int64       // height when this transaction was initially checked (for expiry)
-	timestamp time.Time   // time when transaction was entered (for TTL)
+	height    int64     // atomic: height when this transaction was validated (for expiry)
+	timestamp time.Time // time when this transaction was validated (for TTL)
+	tx        types.Tx  // the original transaction data
+	sender    string    // app: assigned sender label, at most one tx per sender
 
 	mtx       sync.Mutex
-	gasWanted int64           // app: gas required to execute this transaction
-	priority  int64           // app: priority value for this transaction
-	sender    string          // app: assigned sender label
-	peers     map[uint16]bool // peer IDs who have sent us this transaction
+	gasWanted int64 // app: gas required to execute this transaction
+	priority  int64 // app: priority value for this transaction
+
+	// ids of peers who've sent us this tx (as a map for quick lookups).
+	// senders: PeerID -> bool
+	senders sync.Map
 }
 
+// Height returns the height for this transaction.
+func (w *WrappedTx) Height() int64 { return atomic.LoadInt64(&w.height) }
+
 // Size reports the size of the raw transaction in bytes.
 func (w *WrappedTx) Size() int64 { return int64(len(w.tx)) }
 
 // SetPeer adds the specified peer ID as a sender of w.
-func (w *WrappedTx) SetPeer(id uint16) {
-	w.mtx.Lock()
-	defer w.mtx.Unlock()
-	if w.peers == nil {
-		w.peers = map[uint16]bool{id: true}
-	} else {
-		w.peers[id] = true
-	}
-}
+func (w *WrappedTx) SetPeer(id uint16) { w.senders.Store(id, true) }
 
 // HasPeer reports whether the specified peer ID is a sender of w.
 func (w *WrappedTx) HasPeer(id uint16) bool {
-	w.mtx.Lock()
-	defer w.mtx.Unlock()
-	_, ok := w.peers[id]
+	_, ok := w.senders.Load(id)
 	return ok
 }
 
@@ -60,20 +55,6 @@ func (w *WrappedTx) GasWanted() int64 {
 	return w.gasWanted
 }
 
-// SetSender sets the application-assigned sender of w.
-func (w *WrappedTx) SetSender(sender string) {
-	w.mtx.Lock()
-	defer w.mtx.Unlock()
-	w.sender = sender
-}
-
-// Sender reports the application-assigned sender of w.
-func (w *WrappedTx) Sender() string {
-	w.mtx.Lock()
-	defer w.mtx.Unlock()
-	return w.sender
-}
-
 // SetPriority sets the application-assigned priority of w.
 func (w *WrappedTx) SetPriority(p int64) {
 	w.mtx.Lock()
@@ -87,3 +68,16 @@ func (w *WrappedTx) Priority() int64 {
 	defer w.mtx.Unlock()
 	return w.priority
 }
+
+// pendingTx returns the tx of w with its metadata.
+//
+// CONTRACT: the caller should hold txmp.updateMtx.RLock().
+func (w *WrappedTx) pendingTx() mempool.PendingTx {
+	return mempool.PendingTx{
+		Tx:        w.tx,
+		Height:    w.Height(),
+		Timestamp: w.timestamp,
+		GasWanted: w.GasWanted(),
+		Priority:  w.Priority(),
+	}
+}
@@ -21,7 +21,14 @@ fi
 ##
 ## Run binary with all parameters
 ##
-export OCHOME="/ostracon/node${ID}"
+export OCHOME="/ostracon/${NODE_DIR:-node${ID}}"
+
+##
+## Inject the link conditions generated by ostracon testnet (needs NET_ADMIN)
+##
+if [ -f "${OCHOME}/netem.sh" ]; then
+  sh "${OCHOME}/netem.sh"
+fi
 
 if [ -d "`dirname ${OCHOME}/${LOG}`" ]; then
   "$BINARY" "$@" | tee "${OCHOME}/${LOG}"
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"strings"
+	"time"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/proxy"
+)
+
+// the delay before restarting the application which failed to start, doubled
+// at each restart
+const (
+	appRestartBackoff    = time.Second
+	appRestartMaxBackoff = 30 * time.Second
+)
+
+// DefaultClientCreator returns the ClientCreator of proxy_app: the
+// proxy.AppProcess running the application if it's prefixed with "exec:",
+// supervised as configured, or else proxy.DefaultClientCreator.
+func DefaultClientCreator(config *cfg.Config) (proxy.ClientCreator, error) {
+	if !strings.HasPrefix(config.ProxyApp, proxy.AppExecPrefix) {
+		return proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()), nil
+	}
+	return proxy.NewAppProcess(
+		strings.TrimPrefix(config.ProxyApp, proxy.AppExecPrefix),
+		proxy.DefaultAppProcessAddr(config.DBDir()),
+		config.ABCI,
+		proxy.AppProcessConfig{
+			StartTimeout:        config.ProxyAppStartTimeout,
+			StopTimeout:         config.ProxyAppStopTimeout,
+			HealthCheckInterval: config.ProxyAppHealthCheckInterval,
+			RestartPolicy: service.RestartPolicy{
+				MaxRestarts: config.ProxyAppMaxRestarts,
+				Backoff:     appRestartBackoff,
+				MaxBackoff:  appRestartMaxBackoff,
+			},
+		},
+	)
+}
+
+// startAppProcess starts the application, if run by the node, before the
+// connections to it.
+func startAppProcess(clientCreator proxy.ClientCreator, logger log.Logger) (*proxy.AppProcess, error) {
+	p, ok := clientCreator.(*proxy.AppProcess)
+	if !ok {
+		return nil, nil
+	}
+	p.SetLogger(logger.With("module", "app"))
+	if err := p.Start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// stopApp closes the connections to the application, and then stops it if run
+// by the node, for the connections not to fail under the node.
+func (n *Node) stopApp() {
+	if n.appProcess == nil {
+		return
+	}
+	if n.proxyApp != nil {
+		if err := n.proxyApp.Stop(); err != nil {
+			n.Logger.Error("Error closing the connections to the application", "err", err)
+		}
+	}
+	if err := n.appProcess.Stop(); err != nil {
+		n.Logger.Error("Error stopping the application", "err", err)
+	}
+}
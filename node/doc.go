@@ -35,5 +35,35 @@ To replace the built-in p2p.Reactor, use the CustomReactors option:
 	)
 
 The list of existing reactors can be found in CustomReactors documentation.
+A single reactor can also be added or replaced with the WithReactor option.
+
+An application can also register the constructors of its reactors by name at
+build time, typically from an init function, so that they are added to the node
+by listing their names in the custom_reactors setting of the configuration:
+
+	func init() {
+		node.RegisterReactor("myapp.oracle", func(ctx node.ReactorContext) (p2p.Reactor, error) {
+			return oracle.NewReactor(ctx.Config, ctx.MetricsLabels...), nil
+		})
+	}
+
+Besides the reactors, the mempool, the block store and the transport can be
+replaced with the WithMempool, WithBlockStore and WithTransport options. The
+mempool and the transport depend on objects created while building the node
+(e.g. the connections to the application), so they are given as providers,
+which can wrap the default ones:
+
+	node, err := NewNode(
+			config,
+			privVal,
+			nodeKey,
+			clientCreator,
+			genesisDocProvider,
+			dbProvider,
+			metricsProvider,
+			logger,
+			WithBlockStore(store.NewBlockStore(customDB)),
+			WithMempool(customMempoolProvider),
+	)
 */
 package node
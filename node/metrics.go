@@ -0,0 +1,52 @@
+package node
+
+import (
+	"fmt"
+
+	cfg "github.com/Finschia/ostracon/config"
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/version"
+)
+
+// metricsPusher is the provider of the metrics pushed to their backend while
+// it is running.
+type metricsPusher interface {
+	tmmetrics.Provider
+	service.Service
+}
+
+// newMetricsPusher returns the provider pushing the metrics to the StatsD
+// agent or the OpenTelemetry collector of the metrics_backend. It returns nil
+// if the metrics are served to Prometheus.
+func newMetricsPusher(config *cfg.Config, nodeID p2p.ID) (metricsPusher, error) {
+	instrumentation := config.Instrumentation
+	switch instrumentation.MetricsBackend {
+	case cfg.MetricsBackendStatsd:
+		return tmmetrics.NewStatsdProvider(instrumentation.StatsdAddress, instrumentation.MetricsPushInterval), nil
+
+	case cfg.MetricsBackendOTLP:
+		var client tmmetrics.OTLPClient
+		switch instrumentation.MetricsOTLPProtocol {
+		case cfg.TracingOTLPProtocolHTTP:
+			client = tmmetrics.NewOTLPHTTPClient(instrumentation.MetricsOTLPEndpoint, instrumentation.MetricsOTLPInsecure)
+		default:
+			var err error
+			client, err = tmmetrics.NewOTLPGRPCClient(instrumentation.MetricsOTLPEndpoint,
+				instrumentation.MetricsOTLPInsecure)
+			if err != nil {
+				return nil, fmt.Errorf("could not create the OTLP metrics client: %w", err)
+			}
+		}
+		return tmmetrics.NewOTLPProvider(client, instrumentation.MetricsPushInterval,
+			"service.name", "ostracon",
+			"service.version", version.OCCoreSemVer,
+			"service.instance.id", string(nodeID),
+			"moniker", config.Moniker,
+		), nil
+
+	default:
+		return nil, nil
+	}
+}
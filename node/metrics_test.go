@@ -0,0 +1,69 @@
+package node
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
+)
+
+func TestNewMetricsPusher(t *testing.T) {
+	config := cfg.ResetTestRoot("node_new_metrics_pusher_test")
+	defer os.RemoveAll(config.RootDir)
+
+	pusher, err := newMetricsPusher(config, "node")
+	require.NoError(t, err)
+	assert.Nil(t, pusher)
+
+	config.Instrumentation.MetricsBackend = cfg.MetricsBackendStatsd
+	pusher, err = newMetricsPusher(config, "node")
+	require.NoError(t, err)
+	assert.IsType(t, &tmmetrics.StatsdProvider{}, pusher)
+
+	config.Instrumentation.MetricsBackend = cfg.MetricsBackendOTLP
+	config.Instrumentation.MetricsOTLPEndpoint = "localhost:4317"
+	config.Instrumentation.MetricsOTLPInsecure = true
+	for _, protocol := range []string{cfg.TracingOTLPProtocolGRPC, cfg.TracingOTLPProtocolHTTP} {
+		config.Instrumentation.MetricsOTLPProtocol = protocol
+		pusher, err = newMetricsPusher(config, "node")
+		require.NoError(t, err, protocol)
+		assert.IsType(t, &tmmetrics.OTLPProvider{}, pusher, protocol)
+	}
+}
+
+func TestNodeMetricsStatsd(t *testing.T) {
+	config := cfg.ResetTestRoot("node_metrics_statsd_test")
+	defer os.RemoveAll(config.RootDir)
+
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer agent.Close()
+	config.Instrumentation.MetricsBackend = cfg.MetricsBackendStatsd
+	config.Instrumentation.StatsdAddress = agent.LocalAddr().String()
+	config.Instrumentation.MetricsPushInterval = 100 * time.Millisecond
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	defer n.Stop() //nolint:errcheck // ignore for tests
+
+	// the metrics of the node are pushed with their chain ID
+	buf := make([]byte, 64*1024)
+	for {
+		require.NoError(t, agent.SetReadDeadline(time.Now().Add(10*time.Second)))
+		size, _, err := agent.ReadFrom(buf)
+		require.NoError(t, err)
+		if strings.HasPrefix(string(buf[:size]), "ostracon_consensus_height:") {
+			assert.Contains(t, string(buf[:size]), "|g|#chain_id:"+n.GenesisDoc().ChainID)
+			break
+		}
+	}
+}
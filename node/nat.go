@@ -0,0 +1,96 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/upnp"
+)
+
+// startPortMapping maps the port of p2p.laddr, or of the first of p2p.laddrs,
+// on the gateway with NAT-PMP or UPnP, if enabled, and advertises its external
+// address unless p2p.external_address or p2p.external_addresses is set. The
+// node runs without it if no gateway maps the port.
+func (n *Node) startPortMapping() error {
+	if !n.config.P2P.UPNP && !n.config.P2P.NATPMP {
+		return nil
+	}
+	lAddrs := n.config.P2P.ListenAddrs()
+	if len(lAddrs) == 0 {
+		return errors.New("no p2p.laddr to map on the gateway")
+	}
+	addr, err := p2p.NewNetAddressString(p2p.IDAddressString(n.nodeKey.ID(), lAddrs[0]))
+	if err != nil {
+		return err
+	}
+	if addr.Port == 0 {
+		n.Logger.Error("Can't map the random port of p2p.laddr on the gateway")
+		return nil
+	}
+
+	nat, err := discoverNAT(n.config.P2P)
+	if err != nil {
+		n.Logger.Error("Failed to discover the gateway to map the p2p port", "err", err)
+		return nil
+	}
+	mapping := upnp.NewPortMapping(nat, int(addr.Port), n.config.P2P.NATLeaseDuration)
+	mapping.SetLogger(n.Logger.With("module", "nat"))
+	if len(n.config.P2P.ExternalAddrs()) == 0 {
+		mapping.SetOnChange(n.setExternalAddress)
+	}
+	if err := mapping.Start(); err != nil {
+		n.Logger.Error("Failed to map the p2p port", "port", addr.Port, "err", err)
+		return nil
+	}
+	n.portMapping = mapping
+
+	ip, port := mapping.ExternalAddress()
+	n.Logger.Info("Mapped the p2p port", "port", addr.Port, "external_ip", ip, "external_port", port)
+	if len(n.config.P2P.ExternalAddrs()) == 0 {
+		n.setExternalAddress(ip, port)
+	}
+	return nil
+}
+
+func (n *Node) stopPortMapping() {
+	if n.portMapping == nil {
+		return
+	}
+	if err := n.portMapping.Stop(); err != nil {
+		n.Logger.Error("Error stopping port mapping", "err", err)
+	}
+}
+
+// discoverNAT returns the gateway answering NAT-PMP, if enabled, or else UPnP.
+func discoverNAT(config *cfg.P2PConfig) (upnp.NAT, error) {
+	var errs []error
+	if config.NATPMP {
+		nat, err := upnp.DiscoverNATPMP()
+		if err == nil {
+			return nat, nil
+		}
+		errs = append(errs, fmt.Errorf("NAT-PMP: %w", err))
+	}
+	if config.UPNP {
+		nat, err := upnp.Discover()
+		if err == nil {
+			return nat, nil
+		}
+		errs = append(errs, fmt.Errorf("UPnP: %w", err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// setExternalAddress advertises the address to the peers connected after it.
+func (n *Node) setExternalAddress(ip net.IP, port int) {
+	addr := p2p.NewNetAddressIPPort(ip, uint16(port))
+	addr.ID = n.nodeKey.ID()
+	if err := n.sw.SetExternalAddress(addr); err != nil {
+		n.Logger.Error("Failed to set the external address", "addr", addr, "err", err)
+		return
+	}
+	n.Logger.Info("Advertising the external address", "addr", addr)
+}
@@ -7,14 +7,17 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/rs/cors"
 	abci "github.com/tendermint/tendermint/abci/types"
 	dbm "github.com/tendermint/tm-db"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	bcv0 "github.com/Finschia/ostracon/blockchain/v0"
 	bcv1 "github.com/Finschia/ostracon/blockchain/v1"
@@ -25,16 +28,19 @@ import (
 	"github.com/Finschia/ostracon/evidence"
 	tmjson "github.com/Finschia/ostracon/libs/json"
 	"github.com/Finschia/ostracon/libs/log"
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 	tmpubsub "github.com/Finschia/ostracon/libs/pubsub"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
 	"github.com/Finschia/ostracon/libs/service"
 	"github.com/Finschia/ostracon/light"
 	mempl "github.com/Finschia/ostracon/mempool"
 	mempoolv0 "github.com/Finschia/ostracon/mempool/v0"
-
-	//mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
+	mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/p2p/pex"
+	"github.com/Finschia/ostracon/p2p/upnp"
 	"github.com/Finschia/ostracon/privval"
+	pvpsql "github.com/Finschia/ostracon/privval/psql"
 	"github.com/Finschia/ostracon/proxy"
 	rpccore "github.com/Finschia/ostracon/rpc/core"
 	grpccore "github.com/Finschia/ostracon/rpc/grpc"
@@ -71,6 +77,23 @@ type DBProvider func(*DBContext) (dbm.DB, error)
 
 const readHeaderTimeout = 10 * time.Second
 
+// timeouts of the shutdown stages of the node, see Node.OnStop
+const (
+	shutdownRPCTimeout       = 5 * time.Second
+	shutdownDrainTimeout     = 10 * time.Second
+	shutdownWALTimeout       = 5 * time.Second
+	shutdownConsensusTimeout = 10 * time.Second
+	shutdownServicesTimeout  = 10 * time.Second
+	shutdownStoresTimeout    = 5 * time.Second
+	shutdownMempoolTimeout   = 10 * time.Second
+	shutdownTracingTimeout   = 5 * time.Second
+	shutdownMetricsTimeout   = 5 * time.Second
+)
+
+// seedNodeDisconnectWaitPeriod is how long a seed node (see cfg.ModeSeed) stays
+// connected to the peers it crawls.
+const seedNodeDisconnectWaitPeriod = 3 * time.Minute
+
 // DefaultDBProvider returns a database using the DBBackend and DBDir
 // specified in the ctx.Config.
 func DefaultDBProvider(ctx *DBContext) (dbm.DB, error) {
@@ -84,9 +107,20 @@ func DefaultDBProvider(ctx *DBContext) (dbm.DB, error) {
 type GenesisDocProvider func() (*types.GenesisDoc, error)
 
 // DefaultGenesisDocProviderFunc returns a GenesisDocProvider that loads
-// the GenesisDoc from the config.GenesisFile() on the filesystem.
+// the GenesisDoc from the config.GenesisFile() on the filesystem. With state
+// sync and a statesync.genesis_hash, a missing genesis file is first fetched
+// from the statesync.rpc_servers.
 func DefaultGenesisDocProviderFunc(config *cfg.Config) GenesisDocProvider {
 	return func() (*types.GenesisDoc, error) {
+		if config.StateSync.Enable && config.StateSync.GenesisHash != "" {
+			if _, err := os.Stat(config.GenesisFile()); errors.Is(err, os.ErrNotExist) {
+				err := statesync.FetchGenesis(context.Background(), config.StateSync.RPCServers,
+					config.StateSync.GenesisHashBytes(), config.GenesisFile())
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
 		return types.GenesisDocFromFile(config.GenesisFile())
 	}
 }
@@ -103,11 +137,26 @@ func DefaultNewNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 		return nil, fmt.Errorf("failed to load or gen node key %s: %w", config.NodeKeyFile(), err)
 	}
 
-	pv := privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+	var pv types.PrivValidator
+	if config.PrivValidatorBackend == cfg.PrivValidatorBackendPKCS11 {
+		pv, err = CreatePrivValidatorPKCS11(config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		pv = privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+	}
+	if err := setPrivValidatorSignStateStore(config, pv); err != nil {
+		return nil, err
+	}
+	clientCreator, err := DefaultClientCreator(config)
+	if err != nil {
+		return nil, err
+	}
 	return NewNode(config,
 		pv,
 		nodeKey,
-		proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+		clientCreator,
 		DefaultGenesisDocProviderFunc(config),
 		DefaultDBProvider,
 		DefaultMetricsProvider(config.Instrumentation),
@@ -124,16 +173,32 @@ func NewOstraconNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 	}
 
 	var privKey types.PrivValidator
-	if config.PrivValidatorListenAddr == "" {
+	switch {
+	case config.PrivValidatorListenAddr != "" || config.PrivValidatorGRPCAddr != "":
+	case config.PrivValidatorBackend == cfg.PrivValidatorBackendPKCS11:
+		privKey, err = CreatePrivValidatorPKCS11(config)
+		if err != nil {
+			return nil, err
+		}
+	default:
 		privKey = privval.LoadFilePV(
 			config.PrivValidatorKeyFile(),
 			config.PrivValidatorStateFile())
 	}
+	if privKey != nil {
+		if err := setPrivValidatorSignStateStore(config, privKey); err != nil {
+			return nil, err
+		}
+	}
+	clientCreator, err := DefaultClientCreator(config)
+	if err != nil {
+		return nil, err
+	}
 	return NewNode(
 		config,
 		privKey,
 		nodeKey,
-		proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+		clientCreator,
 		DefaultGenesisDocProviderFunc(config),
 		DefaultDBProvider,
 		DefaultMetricsProvider(config.Instrumentation),
@@ -141,24 +206,46 @@ func NewOstraconNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 	)
 }
 
-// MetricsProvider returns a consensus, p2p and mempool Metrics.
-type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics)
+// MetricsProvider returns a consensus, p2p, mempool, state, evidence and pubsub
+// Metrics.
+type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *evidence.Metrics,
+	*tmpubsub.Metrics)
 
 // DefaultMetricsProvider returns Metrics build using Prometheus client library
 // if Prometheus is enabled. Otherwise, it returns no-op Metrics.
+//
+// If the metrics_backend is statsd or otlp, the node ignores its
+// MetricsProvider and builds the Metrics with the provider pushing them, see
+// NewNode.
 func DefaultMetricsProvider(config *cfg.InstrumentationConfig) MetricsProvider {
-	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics) {
-		if config.Prometheus {
-			return cs.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				p2p.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				mempl.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				sm.PrometheusMetrics(config.Namespace, "chain_id", chainID)
-		}
-		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics()
+	if config.Prometheus {
+		return NewMetricsProvider(tmmetrics.PrometheusProvider(), config.Namespace)
+	}
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *evidence.Metrics,
+		*tmpubsub.Metrics) {
+		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics(), evidence.NopMetrics(),
+			tmpubsub.NopMetrics()
+	}
+}
+
+// NewMetricsProvider returns a MetricsProvider building the Metrics with the
+// provider, e.g. exporting them to StatsD or OTLP.
+func NewMetricsProvider(provider tmmetrics.Provider, namespace string) MetricsProvider {
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *evidence.Metrics,
+		*tmpubsub.Metrics) {
+		return cs.NewMetrics(provider, namespace, "chain_id", chainID),
+			p2p.NewMetrics(provider, namespace, "chain_id", chainID),
+			mempl.NewMetrics(provider, namespace, "chain_id", chainID),
+			sm.NewMetrics(provider, namespace, "chain_id", chainID),
+			evidence.NewMetrics(provider, namespace, "chain_id", chainID),
+			tmpubsub.NewMetrics(provider, namespace, "chain_id", chainID)
 	}
 }
 
-// Option sets a parameter for the node.
+// Option sets a parameter for the node. The options are applied twice: before
+// the node is built, for the ones replacing its subsystems (which only record
+// them, see WithMempool), and on the node built, for the ones setting its
+// fields. So they must do nothing else than setting the node.
 type Option func(*Node)
 
 // Temporary interface for switching to fast sync, we should get rid of v0 and v1 reactors.
@@ -167,6 +254,71 @@ type fastSyncReactor interface {
 	SwitchToFastSync(sm.State) error
 }
 
+// nodeOverrides are the subsystems replacing the default ones, set by the
+// options.
+type nodeOverrides struct {
+	mempoolProvider   MempoolProvider
+	blockStore        *store.BlockStore
+	transportProvider TransportProvider
+	reactors          map[string]p2p.Reactor
+}
+
+// MempoolProvider creates the mempool and the reactor gossiping its
+// transactions.
+type MempoolProvider func(
+	config *cfg.Config,
+	proxyApp proxy.AppConns,
+	state sm.State,
+	memplMetrics *mempl.Metrics,
+	logger log.Logger,
+) (mempl.Mempool, p2p.Reactor)
+
+// TransportProvider creates the transport of the node's Switch and the filters
+// applied to its peers.
+type TransportProvider func(
+	config *cfg.Config,
+	nodeInfo p2p.NodeInfo,
+	nodeKey *p2p.NodeKey,
+	proxyApp proxy.AppConns,
+) (*p2p.MultiplexTransport, []p2p.PeerFilterFunc)
+
+// WithMempool replaces the mempool and the mempool reactor by the ones created
+// by the given provider.
+func WithMempool(provider MempoolProvider) Option {
+	return func(n *Node) {
+		n.overrides.mempoolProvider = provider
+	}
+}
+
+// WithBlockStore replaces the block store, which is otherwise created on the
+// "blockstore" database of the DBProvider.
+func WithBlockStore(blockStore *store.BlockStore) Option {
+	return func(n *Node) {
+		n.overrides.blockStore = blockStore
+	}
+}
+
+// WithTransport replaces the transport of the node's Switch by the one created
+// by the given provider.
+func WithTransport(provider TransportProvider) Option {
+	return func(n *Node) {
+		n.overrides.transportProvider = provider
+	}
+}
+
+// WithReactor adds a custom reactor to the node's Switch.
+//
+// WARNING: using any name from the list of the existing reactors (see
+// CustomReactors) will result in replacing it with the custom one.
+func WithReactor(name string, reactor p2p.Reactor) Option {
+	return func(n *Node) {
+		if n.overrides.reactors == nil {
+			n.overrides.reactors = make(map[string]p2p.Reactor)
+		}
+		n.overrides.reactors[name] = reactor
+	}
+}
+
 // CustomReactors allows you to add custom reactors (name -> p2p.Reactor) to
 // the node's Switch.
 //
@@ -182,30 +334,48 @@ type fastSyncReactor interface {
 func CustomReactors(reactors map[string]p2p.Reactor) Option {
 	return func(n *Node) {
 		for name, reactor := range reactors {
-			if existingReactor := n.sw.Reactor(name); existingReactor != nil {
-				n.sw.Logger.Info("Replacing existing reactor with a custom one",
-					"name", name, "existing", existingReactor, "custom", reactor)
-				n.sw.RemoveReactor(name, existingReactor)
-			}
-			n.sw.AddReactor(name, reactor)
-			// register the new channels to the nodeInfo
-			// NOTE: This is a bit messy now with the type casting but is
-			// cleaned up in the following version when NodeInfo is changed from
-			// and interface to a concrete type
-			if ni, ok := n.nodeInfo.(p2p.DefaultNodeInfo); ok {
-				for _, chDesc := range reactor.GetChannels() {
-					if !ni.HasChannel(chDesc.ID) {
-						ni.Channels = append(ni.Channels, chDesc.ID)
-						err := n.transport.AddChannel(chDesc.ID)
-						if err != nil {
-							n.Logger.Debug("AddChannel failed", "err", err)
-						}
+			WithReactor(name, reactor)(n)
+		}
+	}
+}
+
+// applyOptions applies the options to the node built, keeping the overrides
+// recorded before it was built.
+func (n *Node) applyOptions(overrides nodeOverrides, options []Option) {
+	n.overrides = overrides
+	for _, option := range options {
+		option(n)
+	}
+}
+
+// addCustomReactors adds the custom reactors to the Switch, replacing the
+// existing ones with the same name.
+func (n *Node) addCustomReactors(reactors map[string]p2p.Reactor) {
+	for name, reactor := range reactors {
+		if existingReactor := n.sw.Reactor(name); existingReactor != nil {
+			n.sw.Logger.Info("Replacing existing reactor with a custom one",
+				"name", name, "existing", existingReactor, "custom", reactor)
+			n.sw.RemoveReactor(name, existingReactor)
+		}
+		n.sw.AddReactor(name, reactor)
+		// register the new channels to the nodeInfo
+		// NOTE: This is a bit messy now with the type casting but is
+		// cleaned up in the following version when NodeInfo is changed from
+		// and interface to a concrete type
+		if ni, ok := n.nodeInfo.(p2p.DefaultNodeInfo); ok {
+			for _, chDesc := range reactor.GetChannels() {
+				if !ni.HasChannel(chDesc.ID) {
+					ni.Channels = append(ni.Channels, chDesc.ID)
+					err := n.transport.AddChannel(chDesc.ID)
+					if err != nil {
+						n.Logger.Debug("AddChannel failed", "err", err)
 					}
 				}
-				n.nodeInfo = ni
-			} else {
-				n.Logger.Error("Node info is not of type DefaultNodeInfo. Custom reactor channels can not be added.")
 			}
+			n.nodeInfo = ni
+			n.sw.SetNodeInfo(ni)
+		} else {
+			n.Logger.Error("Node info is not of type DefaultNodeInfo. Custom reactor channels can not be added.")
 		}
 	}
 }
@@ -219,6 +389,8 @@ type Node struct {
 
 	// config
 	config        *cfg.Config
+	configMtx     sync.Mutex          // serializes config reloads
+	configLoader  ConfigLoader        // loads the latest config on reload
 	genesisDoc    *types.GenesisDoc   // initial validator set
 	privValidator types.PrivValidator // local node's validator key
 
@@ -229,6 +401,8 @@ type Node struct {
 	nodeInfo    p2p.NodeInfo
 	nodeKey     *p2p.NodeKey // our node privkey
 	isListening bool
+	portMapping *upnp.PortMapping // maps the p2p port on the gateway, if enabled
+	appProcess  *proxy.AppProcess // runs the ABCI app, if configured so
 
 	// services
 	eventBus          *types.EventBus // pub/sub for services
@@ -241,25 +415,44 @@ type Node struct {
 	stateSyncReactor  *statesync.Reactor      // for hosting and restoring state sync snapshots
 	stateSyncProvider statesync.StateProvider // provides state data for bootstrapping a node
 	stateSyncGenesis  sm.State                // provides the genesis state for state sync
+	snapshotter       *statesync.Snapshotter  // archives the snapshots of the app, if enabled
+	pruner            *sm.Pruner              // prunes the old blocks in the background
 	consensusState    *cs.State               // latest consensus state
 	consensusReactor  *cs.Reactor             // for participating in the consensus
 	pexReactor        *pex.Reactor            // for exchanging peer addresses
 	evidencePool      *evidence.Pool          // tracking evidence
 	proxyApp          proxy.AppConns          // connection to the application
 	rpcListeners      []net.Listener          // rpc servers
+	rpcCORSHandlers   []*corsHandler          // apply the CORS settings of the rpc servers
 	txIndexer         txindex.TxIndexer
 	blockIndexer      indexer.BlockIndexer
 	indexerService    *txindex.IndexerService
 	prometheusSrv     *http.Server
+	probesSrv         *http.Server
+	tracerProvider    *sdktrace.TracerProvider // exports the spans, if the tracing is enabled
+	metricsPusher     metricsPusher            // pushes the metrics to StatsD or OTLP, if enabled
+	watchdog          *profilingWatchdog       // captures the profiles, if enabled
+	supervisor        *service.Supervisor      // starts and stops the above
+
+	overrides nodeOverrides // only used while building the node
 }
 
-func initDBs(config *cfg.Config, dbProvider DBProvider) (blockStore *store.BlockStore, stateDB dbm.DB, err error) {
-	var blockStoreDB dbm.DB
-	blockStoreDB, err = dbProvider(&DBContext{"blockstore", config})
-	if err != nil {
-		return
+// initDBs opens the block store, unless a custom one is given, and the state
+// database.
+func initDBs(
+	config *cfg.Config,
+	dbProvider DBProvider,
+	customBlockStore *store.BlockStore,
+) (blockStore *store.BlockStore, stateDB dbm.DB, err error) {
+	blockStore = customBlockStore
+	if blockStore == nil {
+		var blockStoreDB dbm.DB
+		blockStoreDB, err = dbProvider(&DBContext{"blockstore", config})
+		if err != nil {
+			return
+		}
+		blockStore = store.NewBlockStore(blockStoreDB)
 	}
-	blockStore = store.NewBlockStore(blockStoreDB)
 
 	stateDB, err = dbProvider(&DBContext{"state", config})
 	if err != nil {
@@ -269,8 +462,14 @@ func initDBs(config *cfg.Config, dbProvider DBProvider) (blockStore *store.Block
 	return
 }
 
-func createAndStartProxyAppConns(clientCreator proxy.ClientCreator, logger log.Logger) (proxy.AppConns, error) {
-	proxyApp := proxy.NewAppConns(clientCreator)
+func createAndStartProxyAppConns(
+	config *cfg.Config,
+	clientCreator proxy.ClientCreator,
+	logger log.Logger,
+) (proxy.AppConns, error) {
+	proxyApp := proxy.NewAppConns(clientCreator,
+		proxy.WithQueryConnections(config.ProxyQueryConnections),
+		proxy.WithMempoolConnections(config.ProxyMempoolConnections))
 	proxyApp.SetLogger(logger.With("module", "proxy"))
 	if err := proxyApp.Start(); err != nil {
 		return nil, fmt.Errorf("error starting proxy app connections: %v", err)
@@ -278,8 +477,8 @@ func createAndStartProxyAppConns(clientCreator proxy.ClientCreator, logger log.L
 	return proxyApp, nil
 }
 
-func createAndStartEventBus(logger log.Logger) (*types.EventBus, error) {
-	eventBus := types.NewEventBus()
+func createAndStartEventBus(pubsubMetrics *tmpubsub.Metrics, logger log.Logger) (*types.EventBus, error) {
+	eventBus := types.NewEventBusWithOptions(tmpubsub.WithMetrics(pubsubMetrics))
 	eventBus.SetLogger(logger.With("module", "events"))
 	if err := eventBus.Start(); err != nil {
 		return nil, err
@@ -396,31 +595,31 @@ func createMempoolAndMempoolReactor(
 	logger log.Logger,
 ) (mempl.Mempool, p2p.Reactor) {
 	switch config.Mempool.Version {
-	case cfg.MempoolV1: // XXX Deprecated MempoolV1
-		panic("Deprecated MempoolV1")
-		/*
-			// TODO(thane): Remove log once https://github.com/tendermint/tendermint/issues/8775 is resolved.
-			logger.Error("While the prioritized mempool API is stable, there is a critical bug in it that is currently under investigation. See https://github.com/tendermint/tendermint/issues/8775 for details")
-			mp := mempoolv1.NewTxMempool(
-				logger,
-				config.Mempool,
-				proxyApp.Mempool(),
-				state.LastBlockHeight,
-				mempoolv1.WithMetrics(memplMetrics),
-				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-			)
+	case cfg.MempoolV1:
+		mp := mempoolv1.NewTxMempool(
+			config.Mempool,
+			proxyApp.Mempool(),
+			state.LastBlockHeight,
+			mempoolv1.WithMetrics(memplMetrics),
+			mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+			mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+		)
 
-			reactor := mempoolv1.NewReactor(
-				config.Mempool,
-				mp,
-			)
-			if config.Consensus.WaitForTxs() {
-				mp.EnableTxsAvailable()
-			}
+		mp.SetLogger(logger)
+
+		reactor := mempoolv1.NewReactor(
+			config.Mempool,
+			config.P2P.RecvAsync,
+			config.P2P.MempoolRecvBufSize,
+			mp,
+		)
+
+		if config.Consensus.WaitForTxs() {
+			mp.EnableTxsAvailable()
+		}
+
+		return mp, reactor
 
-			return mp, reactor
-		*/
 	case cfg.MempoolV0:
 		mp := mempoolv0.NewCListMempool(
 			config.Mempool,
@@ -452,7 +651,7 @@ func createMempoolAndMempoolReactor(
 }
 
 func createEvidenceReactor(config *cfg.Config, dbProvider DBProvider,
-	stateDB dbm.DB, blockStore *store.BlockStore, logger log.Logger,
+	stateDB dbm.DB, blockStore *store.BlockStore, evidenceMetrics *evidence.Metrics, logger log.Logger,
 ) (*evidence.Reactor, *evidence.Pool, error) {
 	evidenceDB, err := dbProvider(&DBContext{"evidence", config})
 	if err != nil {
@@ -461,7 +660,8 @@ func createEvidenceReactor(config *cfg.Config, dbProvider DBProvider,
 	evidenceLogger := logger.With("module", "evidence")
 	evidencePool, err := evidence.NewPool(evidenceDB, sm.NewStore(stateDB, sm.StoreOptions{
 		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
-	}), blockStore)
+		RetainStates:         config.Storage.RetainStates,
+	}), blockStore, evidence.WithMetrics(evidenceMetrics), evidence.WithStartupVerification())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -637,15 +837,15 @@ func createAddrBookAndSetOnSwitch(config *cfg.Config, sw *p2p.Switch,
 	addrBook.SetLogger(p2pLogger.With("book", config.P2P.AddrBookFile()))
 
 	// Add ourselves to addrbook to prevent dialing ourselves
-	if config.P2P.ExternalAddress != "" {
-		addr, err := p2p.NewNetAddressString(p2p.IDAddressString(nodeKey.ID(), config.P2P.ExternalAddress))
+	for _, extAddr := range config.P2P.ExternalAddrs() {
+		addr, err := p2p.NewNetAddressString(p2p.IDAddressString(nodeKey.ID(), extAddr))
 		if err != nil {
 			return nil, fmt.Errorf("p2p.external_address is incorrect: %w", err)
 		}
 		addrBook.AddOurAddress(addr)
 	}
-	if config.P2P.ListenAddress != "" {
-		addr, err := p2p.NewNetAddressString(p2p.IDAddressString(nodeKey.ID(), config.P2P.ListenAddress))
+	for _, lAddr := range config.P2P.ListenAddrs() {
+		addr, err := p2p.NewNetAddressString(p2p.IDAddressString(nodeKey.ID(), lAddr))
 		if err != nil {
 			return nil, fmt.Errorf("p2p.laddr is incorrect: %w", err)
 		}
@@ -658,22 +858,59 @@ func createAddrBookAndSetOnSwitch(config *cfg.Config, sw *p2p.Switch,
 }
 
 func createPEXReactorAndAddToSwitch(addrBook pex.AddrBook, config *cfg.Config,
-	sw *p2p.Switch, logger log.Logger,
+	sw *p2p.Switch, proxyApp proxy.AppConns, logger log.Logger,
 ) *pex.Reactor {
+	// See consensus/reactor.go: blocksToContributeToBecomeGoodPeer 10000
+	// blocks assuming 10s blocks ~ 28 hours.
+	// TODO (melekes): make it dynamic based on the actual block latencies
+	// from the live network.
+	// https://github.com/tendermint/tendermint/issues/3523
+	return createPEXReactorWithSeedMode(addrBook, config, sw, proxyApp, config.P2P.SeedMode, 28*time.Hour, logger)
+}
+
+func createPEXReactorWithSeedMode(addrBook pex.AddrBook, config *cfg.Config,
+	sw *p2p.Switch, proxyApp proxy.AppConns, seedMode bool, seedDisconnectWaitPeriod time.Duration, logger log.Logger,
+) *pex.Reactor {
+	var discoveries []pex.Discovery
+	if config.P2P.DiscoverySRV != "" {
+		discoveries = append(discoveries, pex.NewSRVDiscovery(config.P2P.DiscoverySRV))
+	}
+	// NOTE: the seed node has no application to query
+	if config.P2P.DiscoverValidators && proxyApp != nil {
+		discoveries = append(discoveries, pex.NewValidatorsDiscovery(
+			// ABCI query for the addresses of the validators.
+			func(context.Context) ([]string, error) {
+				res, err := proxyApp.Query().QuerySync(abci.RequestQuery{
+					Path: "/p2p/validators",
+				})
+				if err != nil {
+					return nil, err
+				}
+				if res.IsErr() {
+					return nil, fmt.Errorf("error querying abci app: %v", res)
+				}
+
+				return splitAndTrimEmpty(string(res.Value), ",", " "), nil
+			},
+		))
+	}
+
+	observedAddrReports := 0
+	if config.P2P.LearnExternalAddress && len(config.P2P.ExternalAddrs()) == 0 {
+		observedAddrReports = pex.DefaultObservedAddrReports
+	}
+
 	// TODO persistent peers ? so we can have their DNS addrs saved
 	pexReactor := pex.NewReactor(addrBook,
 		config.P2P.RecvAsync,
 		&pex.ReactorConfig{
-			Seeds:    splitAndTrimEmpty(config.P2P.Seeds, ",", " "),
-			SeedMode: config.P2P.SeedMode,
-			// See consensus/reactor.go: blocksToContributeToBecomeGoodPeer 10000
-			// blocks assuming 10s blocks ~ 28 hours.
-			// TODO (melekes): make it dynamic based on the actual block latencies
-			// from the live network.
-			// https://github.com/tendermint/tendermint/issues/3523
-			SeedDisconnectWaitPeriod:     28 * time.Hour,
+			Seeds:                        splitAndTrimEmpty(config.P2P.Seeds, ",", " "),
+			SeedMode:                     seedMode,
+			SeedDisconnectWaitPeriod:     seedDisconnectWaitPeriod,
 			PersistentPeersMaxDialPeriod: config.P2P.PersistentPeersMaxDialPeriod,
+			Discoveries:                  discoveries,
 			RecvBufSize:                  config.P2P.PexRecvBufSize,
+			ObservedAddrReports:          observedAddrReports,
 		})
 	pexReactor.SetLogger(logger.With("module", "pex"))
 	sw.AddReactor("PEX", pexReactor)
@@ -693,7 +930,7 @@ func startStateSync(ssR *statesync.Reactor, bcR fastSyncReactor, conR *cs.Reacto
 		defer cancel()
 		stateProvider, err = statesync.NewLightClientStateProvider(
 			ctx,
-			state.ChainID, state.Version, state.InitialHeight,
+			state.ChainID, state.Version, state.InitialHeight, state.BlockPartSizeBytes,
 			config.RPCServers, light.TrustOptions{
 				Period: config.TrustPeriod,
 				Height: config.TrustHeight,
@@ -754,14 +991,46 @@ func NewNode(config *cfg.Config,
 	metricsProvider MetricsProvider,
 	logger log.Logger,
 	options ...Option,
-) (*Node, error) {
-	blockStore, stateDB, err := initDBs(config, dbProvider)
+) (_ *Node, err error) {
+	node := &Node{}
+	for _, option := range options {
+		option(node)
+	}
+	overrides := node.overrides
+	// the options setting the fields of the node are applied once it is built
+	defer func() {
+		if err == nil {
+			node.applyOptions(overrides, options)
+		}
+	}()
+
+	// the metrics pushed to StatsD or OTLP are built with the pusher, which
+	// pushes them while the node is running
+	metricsPusher, err := newMetricsPusher(config, nodeKey.ID())
+	if err != nil {
+		return nil, err
+	}
+	if metricsPusher != nil {
+		metricsProvider = NewMetricsProvider(metricsPusher, config.Instrumentation.Namespace)
+	}
+
+	if config.Mode == cfg.ModeSeed {
+		node, err := makeSeedNode(node, config, nodeKey, genesisDocProvider, metricsProvider, logger)
+		if err != nil {
+			return nil, err
+		}
+		node.metricsPusher = metricsPusher
+		return node, nil
+	}
+
+	blockStore, stateDB, err := initDBs(config, dbProvider, overrides.blockStore)
 	if err != nil {
 		return nil, err
 	}
 
 	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
 		DiscardABCIResponses: config.Storage.DiscardABCIResponses,
+		RetainStates:         config.Storage.RetainStates,
 	})
 
 	state, genDoc, err := LoadStateFromDBOrGenesisDocProvider(stateDB, genesisDocProvider)
@@ -769,8 +1038,23 @@ func NewNode(config *cfg.Config,
 		return nil, err
 	}
 
+	// Run the ABCI app if configured so, stopped if the node can't be built.
+	appProcess, err := startAppProcess(clientCreator, logger)
+	if err != nil {
+		return nil, err
+	}
+	if appProcess != nil {
+		defer func() {
+			if err != nil {
+				if err := appProcess.Stop(); err != nil {
+					logger.Error("Error stopping the application", "err", err)
+				}
+			}
+		}()
+	}
+
 	// Create the proxyApp and establish connections to the ABCI app (consensus, mempool, query).
-	proxyApp, err := createAndStartProxyAppConns(clientCreator, logger)
+	proxyApp, err := createAndStartProxyAppConns(config, clientCreator, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -779,7 +1063,9 @@ func NewNode(config *cfg.Config,
 	// we might need to index the txs of the replayed block as this might not have happened
 	// when the node stopped last time (i.e. the node stopped after it saved the block
 	// but before it indexed the txs, or, endblocker panicked)
-	eventBus, err := createAndStartEventBus(logger)
+	csMetrics, p2pMetrics, memplMetrics, smMetrics, evidenceMetrics, pubsubMetrics := metricsProvider(genDoc.ChainID)
+
+	eventBus, err := createAndStartEventBus(pubsubMetrics, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -799,6 +1085,13 @@ func NewNode(config *cfg.Config,
 			return nil, fmt.Errorf("error with private validator socket client: %w", err)
 		}
 	}
+	// If a gRPC address is provided, dial the external signing process.
+	if config.PrivValidatorGRPCAddr != "" {
+		privValidator, err = CreatePrivValidatorGRPCClient(config, genDoc.ChainID)
+		if err != nil {
+			return nil, fmt.Errorf("error with private validator gRPC client: %w", err)
+		}
+	}
 
 	pubKey, err := privValidator.GetPubKey()
 	if err != nil {
@@ -835,17 +1128,36 @@ func NewNode(config *cfg.Config,
 
 	logNodeStartupInfo(state, pubKey, logger, consensusLogger)
 
-	csMetrics, p2pMetrics, memplMetrics, smMetrics := metricsProvider(genDoc.ChainID)
+	tracerProvider, err := setupTracing(config, nodeKey.ID(), genDoc.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	registeredReactors, err := createRegisteredReactors(config, genDoc.ChainID, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Make MempoolReactor
-	mempool, mempoolReactor := createMempoolAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+	mempoolProvider := MempoolProvider(createMempoolAndMempoolReactor)
+	if overrides.mempoolProvider != nil {
+		mempoolProvider = overrides.mempoolProvider
+	}
+	mempool, mempoolReactor := mempoolProvider(config, proxyApp, state, memplMetrics, logger)
 
 	// Make Evidence Reactor
-	evidenceReactor, evidencePool, err := createEvidenceReactor(config, dbProvider, stateDB, blockStore, logger)
+	evidenceReactor, evidencePool, err := createEvidenceReactor(config, dbProvider, stateDB, blockStore, evidenceMetrics, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	// prunes the blocks in the background, as retained by the config or the app
+	pruner := sm.NewPruner(stateStore, blockStore, config.Storage.RetainBlocks,
+		sm.PrunerWithInterval(config.Storage.PruningInterval),
+		sm.PrunerWithMetrics(smMetrics),
+	)
+	pruner.SetLogger(logger.With("module", "pruner"))
+
 	// make block executor for consensus and blockchain reactors to execute blocks
 	blockExec := sm.NewBlockExecutor(
 		stateStore,
@@ -854,6 +1166,7 @@ func NewNode(config *cfg.Config,
 		mempool,
 		evidencePool,
 		sm.BlockExecutorWithMetrics(smMetrics),
+		sm.BlockExecutorWithPruner(pruner),
 	)
 
 	// Make BlockchainReactor. Don't start fast sync if we're doing a state sync first.
@@ -885,6 +1198,18 @@ func NewNode(config *cfg.Config,
 		config.P2P.RecvAsync,
 		config.P2P.StatesyncRecvBufSize)
 	stateSyncReactor.SetLogger(logger.With("module", "statesync"))
+	chunkDir := config.StateSync.TempDir
+	if chunkDir == "" {
+		chunkDir = filepath.Join(config.DBDir(), "statesync")
+	}
+	stateSyncReactor.SetChunkDir(chunkDir)
+	var snapshotter *statesync.Snapshotter
+	if config.StateSync.SnapshotInterval > 0 {
+		snapshotter = statesync.NewSnapshotter(filepath.Join(config.DBDir(), "snapshots"),
+			config.StateSync.SnapshotInterval, config.StateSync.SnapshotKeepRecent, proxyApp.Snapshot(), eventBus)
+		snapshotter.SetLogger(logger.With("module", "snapshotter"))
+		stateSyncReactor.SetSnapshotter(snapshotter)
+	}
 
 	nodeInfo, err := makeNodeInfo(config, nodeKey, txIndexer, genDoc, state)
 	if err != nil {
@@ -892,7 +1217,11 @@ func NewNode(config *cfg.Config,
 	}
 
 	// Setup Transport.
-	transport, peerFilters := createTransport(config, nodeInfo, nodeKey, proxyApp)
+	transportProvider := TransportProvider(createTransport)
+	if overrides.transportProvider != nil {
+		transportProvider = overrides.transportProvider
+	}
+	transport, peerFilters := transportProvider(config, nodeInfo, nodeKey, proxyApp)
 
 	// Setup Switch.
 	p2pLogger := logger.With("module", "p2p")
@@ -930,7 +1259,7 @@ func NewNode(config *cfg.Config,
 	// Note we currently use the addrBook regardless at least for AddOurAddress
 	var pexReactor *pex.Reactor
 	if config.P2P.PexReactor {
-		pexReactor = createPEXReactorAndAddToSwitch(addrBook, config, sw, logger)
+		pexReactor = createPEXReactorAndAddToSwitch(addrBook, config, sw, proxyApp, logger)
 	}
 
 	if config.RPC.PprofListenAddress != "" {
@@ -941,7 +1270,7 @@ func NewNode(config *cfg.Config,
 		}()
 	}
 
-	node := &Node{
+	*node = Node{
 		config:        config,
 		genesisDoc:    genDoc,
 		privValidator: privValidator,
@@ -960,146 +1289,212 @@ func NewNode(config *cfg.Config,
 		consensusState:   consensusState,
 		consensusReactor: consensusReactor,
 		stateSyncReactor: stateSyncReactor,
+		snapshotter:      snapshotter,
+		pruner:           pruner,
 		stateSync:        stateSync,
 		stateSyncGenesis: state, // Shouldn't be necessary, but need a way to pass the genesis state
 		pexReactor:       pexReactor,
 		evidencePool:     evidencePool,
 		proxyApp:         proxyApp,
+		appProcess:       appProcess,
 		txIndexer:        txIndexer,
 		indexerService:   indexerService,
 		blockIndexer:     blockIndexer,
 		eventBus:         eventBus,
+		tracerProvider:   tracerProvider,
+		metricsPusher:    metricsPusher,
 	}
 	node.BaseService = *service.NewBaseService(logger, "Node", node)
 
-	for _, option := range options {
-		option(node)
+	if config.Instrumentation.WatchdogEnabled() {
+		node.watchdog = newProfilingWatchdog(config.Instrumentation, eventBus, consensusReactor.WaitSync,
+			logger.With("module", "watchdog"))
 	}
 
+	node.addCustomReactors(registeredReactors)
+	node.addCustomReactors(overrides.reactors)
+
 	return node, nil
 }
 
-// OnStart starts the Node. It implements service.Service.
-func (n *Node) OnStart() error {
-	now := tmtime.Now()
-	genTime := n.genesisDoc.GenesisTime
-	if genTime.After(now) {
-		n.Logger.Info("Genesis time is in the future. Sleeping until then...", "genTime", genTime)
-		time.Sleep(genTime.Sub(now))
+// makeSeedNode builds a node only running the PEX reactor in seed mode and the
+// address book (see cfg.ModeSeed). It neither connects to the application nor
+// opens the block store and the state database.
+func makeSeedNode(
+	node *Node,
+	config *cfg.Config,
+	nodeKey *p2p.NodeKey,
+	genesisDocProvider GenesisDocProvider,
+	metricsProvider MetricsProvider,
+	logger log.Logger,
+) (*Node, error) {
+	overrides := node.overrides
+	if config.FilterPeers {
+		return nil, errors.New("filter_peers can't be used in seed mode: seed nodes don't connect to the application")
 	}
 
-	// Add private IDs to addrbook to block those peers being added
-	n.addrBook.AddPrivateIDs(splitAndTrimEmpty(n.config.P2P.PrivatePeerIDs, ",", " "))
-
-	// Start the RPC server before the P2P server
-	// so we can eg. receive txs for the first block
-	if n.config.RPC.ListenAddress != "" {
-		listeners, err := n.startRPC()
-		if err != nil {
-			return err
-		}
-		n.rpcListeners = listeners
+	genDoc, err := genesisDocProvider()
+	if err != nil {
+		return nil, err
 	}
+	_, p2pMetrics, _, _, _, _ := metricsProvider(genDoc.ChainID)
 
-	if n.config.Instrumentation.Prometheus &&
-		n.config.Instrumentation.PrometheusListenAddr != "" {
-		n.prometheusSrv = n.startPrometheusServer(n.config.Instrumentation.PrometheusListenAddr)
+	registeredReactors, err := createRegisteredReactors(config, genDoc.ChainID, logger)
+	if err != nil {
+		return nil, err
 	}
 
-	// Start the transport.
-	addr, err := p2p.NewNetAddressString(p2p.IDAddressString(n.nodeKey.ID(), n.config.P2P.ListenAddress))
+	nodeInfo, err := makeSeedNodeInfo(config, nodeKey, genDoc)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := n.transport.Listen(*addr); err != nil {
-		return err
+
+	// Setup Transport.
+	transportProvider := TransportProvider(createTransport)
+	if overrides.transportProvider != nil {
+		transportProvider = overrides.transportProvider
 	}
+	transport, peerFilters := transportProvider(config, nodeInfo, nodeKey, nil)
+
+	// Setup Switch.
+	p2pLogger := logger.With("module", "p2p")
+	sw := p2p.NewSwitch(
+		config.P2P,
+		transport,
+		p2p.WithMetrics(p2pMetrics),
+		p2p.SwitchPeerFilters(peerFilters...),
+	)
+	sw.SetLogger(p2pLogger)
+	sw.SetNodeInfo(nodeInfo)
+	sw.SetNodeKey(nodeKey)
+	p2pLogger.Info("P2P Node ID", "ID", nodeKey.ID(), "file", config.NodeKeyFile())
 
-	n.isListening = true
+	err = sw.AddPersistentPeers(splitAndTrimEmpty(config.P2P.PersistentPeers, ",", " "))
+	if err != nil {
+		return nil, fmt.Errorf("could not add peers from persistent_peers field: %w", err)
+	}
 
-	// Start the switch (the P2P server).
-	err = n.sw.Start()
+	err = sw.AddUnconditionalPeerIDs(splitAndTrimEmpty(config.P2P.UnconditionalPeerIDs, ",", " "))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("could not add peer ids from unconditional_peer_ids field: %w", err)
 	}
 
-	// Always connect to persistent peers
-	err = n.sw.DialPeersAsync(splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " "))
+	addrBook, err := createAddrBookAndSetOnSwitch(config, sw, p2pLogger, nodeKey)
 	if err != nil {
-		return fmt.Errorf("could not dial peers from persistent_peers field: %w", err)
+		return nil, fmt.Errorf("could not create addrbook: %w", err)
 	}
 
-	// Run state sync
-	if n.stateSync {
-		bcR, ok := n.bcReactor.(fastSyncReactor)
-		if !ok {
-			return fmt.Errorf("this blockchain reactor does not support switching from state sync")
-		}
-		err := startStateSync(n.stateSyncReactor, bcR, n.consensusReactor, n.stateSyncProvider,
-			n.config.StateSync, n.config.FastSyncMode, n.stateStore, n.blockStore, n.stateSyncGenesis)
-		if err != nil {
-			return fmt.Errorf("failed to start state sync: %w", err)
-		}
+	// The seed node has no blocks to contribute, so the peers can't become good
+	// while connected to it: disconnect them soon after crawling them to make
+	// room for the next ones.
+	pexReactor := createPEXReactorWithSeedMode(addrBook, config, sw, nil, true, seedNodeDisconnectWaitPeriod, logger)
+
+	*node = Node{
+		config:     config,
+		genesisDoc: genDoc,
+
+		transport: transport,
+		sw:        sw,
+		addrBook:  addrBook,
+		nodeInfo:  nodeInfo,
+		nodeKey:   nodeKey,
+
+		pexReactor: pexReactor,
+	}
+	node.BaseService = *service.NewBaseService(logger, "SeedNode", node)
+
+	node.addCustomReactors(registeredReactors)
+	node.addCustomReactors(overrides.reactors)
+
+	return node, nil
+}
+
+// OnStart starts the Node. It implements service.Service.
+//
+// The parts of the node are started by a supervisor in the order of their
+// dependencies, see newSupervisor.
+func (n *Node) OnStart() error {
+	now := tmtime.Now()
+	genTime := n.genesisDoc.GenesisTime
+	if genTime.After(now) {
+		n.Logger.Info("Genesis time is in the future. Sleeping until then...", "genTime", genTime)
+		time.Sleep(genTime.Sub(now))
 	}
 
+	supervisor, err := n.newSupervisor()
+	if err != nil {
+		return err
+	}
+	if err := supervisor.Start(); err != nil {
+		return err
+	}
+	n.supervisor = supervisor
 	return nil
 }
 
 // OnStop stops the Node. It implements service.Service.
+//
+// The parts of the node are stopped by the supervisor in the reverse order
+// they were started:
+//
+//  1. stop accepting RPC requests (and answering the probes)
+//  2. drain the queues of the reactors receiving messages asynchronously
+//  3. flush the consensus WAL
+//  4. stop the consensus
+//  5. stop the p2p switch and the transport
+//  6. save the mempool, with mempool.persist-to-disk
+//  7. stop the other services and close the stores
+//  8. export the remaining spans, if the tracing is enabled
+//
+// Each stage is given up after a timeout (see the shutdown*Timeout constants),
+// in which case the stores are left open, not to be closed under services which
+// may still be writing to them.
 func (n *Node) OnStop() {
 	n.BaseService.OnStop()
 
 	n.Logger.Info("Stopping Node")
 
-	// first stop the non-reactor services
-	if err := n.eventBus.Stop(); err != nil {
-		n.Logger.Error("Error closing eventBus", "err", err)
+	if n.supervisor == nil {
+		return
 	}
-	if err := n.indexerService.Stop(); err != nil {
-		n.Logger.Error("Error closing indexerService", "err", err)
-	}
-
-	// now stop the reactors
-	if err := n.sw.Stop(); err != nil {
-		n.Logger.Error("Error closing switch", "err", err)
+	if err := n.supervisor.Stop(); err != nil {
+		n.Logger.Error("Error stopping supervisor", "err", err)
 	}
+}
 
-	if err := n.transport.Close(); err != nil {
-		n.Logger.Error("Error closing transport", "err", err)
+// loadMempool re-checks the txs saved to disk when the node last stopped and
+// adds the ones still valid to the mempool, with mempool.persist-to-disk.
+func (n *Node) loadMempool() error {
+	if !n.config.Mempool.PersistToDisk || n.mempool == nil {
+		return nil
 	}
-
-	n.isListening = false
-
-	// finally stop the listeners / external services
-	for _, l := range n.rpcListeners {
-		n.Logger.Info("Closing rpc listener", "listener", l)
-		if err := l.Close(); err != nil {
-			n.Logger.Error("Error closing listener", "listener", l, "err", err)
-		}
+	added, err := mempl.LoadTxs(n.mempool, n.config.Mempool.PersistFile())
+	if err != nil {
+		return fmt.Errorf("loading the mempool: %w", err)
 	}
-
-	if pvsc, ok := n.privValidator.(service.Service); ok {
-		if err := pvsc.Stop(); err != nil {
-			n.Logger.Error("Error closing private validator", "err", err)
-		}
+	if added > 0 {
+		n.Logger.Info("Loaded the mempool", "txs", added)
 	}
+	return nil
+}
 
-	if n.prometheusSrv != nil {
-		if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
-			// Error from closing listeners, or context timeout:
-			n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
-		}
-	}
-	if n.blockStore != nil {
-		if err := n.blockStore.Close(); err != nil {
-			n.Logger.Error("problem closing blockstore", "err", err)
-		}
+// saveMempool saves the txs of the mempool and its cache to disk, with
+// mempool.persist-to-disk.
+func (n *Node) saveMempool() {
+	if !n.config.Mempool.PersistToDisk || n.mempool == nil {
+		return
 	}
-	if n.stateStore != nil {
-		if err := n.stateStore.Close(); err != nil {
-			n.Logger.Error("problem closing statestore", "err", err)
-		}
+	if err := mempl.SaveTxs(n.mempool, n.config.Mempool.PersistFile()); err != nil {
+		n.Logger.Error("Error saving the mempool", "err", err)
+		return
 	}
+	n.Logger.Info("Saved the mempool", "txs", n.mempool.Size())
+}
+
+// queueDrainer is implemented by the reactors processing the received messages
+// asynchronously (see p2p.BaseReactor).
+type queueDrainer interface {
+	DrainQueue()
 }
 
 // ConfigureRPC makes sure RPC has all the objects it needs to operate.
@@ -1108,7 +1503,7 @@ func (n *Node) ConfigureRPC() error {
 	if err != nil {
 		return fmt.Errorf("can't get pubkey: %w", err)
 	}
-	rpccore.SetEnvironment(&rpccore.Environment{
+	env := &rpccore.Environment{
 		ProxyAppQuery:   n.proxyApp.Query(),
 		ProxyAppMempool: n.proxyApp.Mempool(),
 
@@ -1119,6 +1514,9 @@ func (n *Node) ConfigureRPC() error {
 		P2PPeers:       n.sw,
 		P2PTransport:   n,
 
+		CommittedEvidence: n.evidencePool,
+		ConfigReloader:    n,
+
 		PubKey:           pubKey,
 		GenDoc:           n.genesisDoc,
 		TxIndexer:        n.txIndexer,
@@ -1130,7 +1528,17 @@ func (n *Node) ConfigureRPC() error {
 		Logger: n.Logger.With("module", "rpc"),
 
 		Config: *n.config.RPC,
-	})
+	}
+	if n.snapshotter != nil {
+		env.Snapshotter = n.snapshotter
+	}
+	if n.pruner != nil {
+		env.Pruner = n.pruner
+	}
+	if n.pexReactor != nil && n.config.P2P.SeedMode {
+		env.Crawler = n.pexReactor
+	}
+	rpccore.SetEnvironment(env)
 	if err := rpccore.InitGenesisChunks(); err != nil {
 		return err
 	}
@@ -1138,15 +1546,31 @@ func (n *Node) ConfigureRPC() error {
 	return nil
 }
 
+// configureSeedRPC sets up the environment of the RPC of a seed node, only
+// serving rpccore.SeedRoutes.
+func (n *Node) configureSeedRPC() {
+	rpccore.SetEnvironment(&rpccore.Environment{
+		P2PPeers:     n.sw,
+		P2PTransport: n,
+		Crawler:      n.pexReactor,
+		GenDoc:       n.genesisDoc,
+		Logger:       n.Logger.With("module", "rpc"),
+		Config:       *n.config.RPC,
+	})
+}
+
 func (n *Node) startRPC() ([]net.Listener, error) {
-	err := n.ConfigureRPC()
-	if err != nil {
+	// the seed nodes have no blocks nor application to serve
+	seedMode := n.config.Mode == cfg.ModeSeed
+	if seedMode {
+		n.configureSeedRPC()
+	} else if err := n.ConfigureRPC(); err != nil {
 		return nil, err
 	}
 
 	listenAddrs := splitAndTrimEmpty(n.config.RPC.ListenAddress, ",", " ")
 
-	if n.config.RPC.Unsafe {
+	if n.config.RPC.Unsafe && !seedMode {
 		rpccore.AddUnsafeRoutes()
 	}
 
@@ -1155,6 +1579,16 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 	config.MaxBatchRequestNum = n.config.RPC.MaxBatchRequestNum
 	config.MaxHeaderBytes = n.config.RPC.MaxHeaderBytes
 	config.MaxOpenConnections = n.config.RPC.MaxOpenConnections
+	config.RateLimitRequests = n.config.RPC.RateLimitRequests
+	config.RateLimitTotalRequests = n.config.RPC.RateLimitTotalRequests
+	config.RateLimitWindow = n.config.RPC.RateLimitWindow
+	config.EnabledMethods = n.config.RPC.EnabledMethods
+	config.DisabledMethods = n.config.RPC.DisabledMethods
+	var err error
+	config.MethodRateLimits, err = n.config.RPC.MethodRateLimits()
+	if err != nil {
+		return nil, err
+	}
 	config.ReadTimeout = n.config.RPC.ReadTimeout
 	config.WriteTimeout = n.config.RPC.WriteTimeout
 	config.IdleTimeout = n.config.RPC.IdleTimeout
@@ -1165,25 +1599,33 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 		config.WriteTimeout = n.config.RPC.TimeoutBroadcastTxCommit + 1*time.Second
 	}
 
+	routes := rpcserver.FilterFuncs(rpccore.Routes, config)
+	if seedMode {
+		routes = rpcserver.FilterFuncs(rpccore.SeedRoutes, config)
+	}
+
 	// we may expose the rpc over both a unix and tcp socket
 	listeners := make([]net.Listener, len(listenAddrs))
 	for i, listenAddr := range listenAddrs {
 		mux := http.NewServeMux()
 		rpcLogger := n.Logger.With("module", "rpc-server")
-		wmLogger := rpcLogger.With("protocol", "websocket")
-		wm := rpcserver.NewWebsocketManager(rpccore.Routes,
-			rpcserver.OnDisconnect(func(remoteAddr string) {
-				err := n.eventBus.UnsubscribeAll(context.Background(), remoteAddr)
-				if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
-					wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
-				}
-			}),
-			rpcserver.ReadLimit(config.MaxBodyBytes),
-			rpcserver.WriteChanCapacity(n.config.RPC.WebSocketWriteBufferSize),
-		)
-		wm.SetLogger(wmLogger)
-		mux.HandleFunc("/websocket", wm.WebsocketHandler)
-		rpcserver.RegisterRPCFuncs(mux, rpccore.Routes, rpcLogger)
+		if !seedMode {
+			wmLogger := rpcLogger.With("protocol", "websocket")
+			wm := rpcserver.NewWebsocketManager(routes,
+				rpcserver.OnDisconnect(func(remoteAddr string) {
+					err := n.eventBus.UnsubscribeAll(context.Background(), remoteAddr)
+					if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
+						wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
+					}
+				}),
+				rpcserver.ReadLimit(config.MaxBodyBytes),
+				rpcserver.WriteChanCapacity(n.config.RPC.WebSocketWriteBufferSize),
+			)
+			wm.SetLogger(wmLogger)
+			mux.HandleFunc("/websocket", wm.WebsocketHandler)
+		}
+		n.handleProbes(mux, "/health/live", "/health/ready")
+		rpcserver.RegisterRPCFuncs(mux, routes, rpcLogger)
 		listener, err := rpcserver.Listen(
 			listenAddr,
 			config,
@@ -1192,15 +1634,8 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 			return nil, err
 		}
 
-		var rootHandler http.Handler = mux
-		if n.config.RPC.IsCorsEnabled() {
-			corsMiddleware := cors.New(cors.Options{
-				AllowedOrigins: n.config.RPC.CORSAllowedOrigins,
-				AllowedMethods: n.config.RPC.CORSAllowedMethods,
-				AllowedHeaders: n.config.RPC.CORSAllowedHeaders,
-			})
-			rootHandler = corsMiddleware.Handler(mux)
-		}
+		rootHandler := newCORSHandler(mux, n.config.RPC)
+		n.rpcCORSHandlers = append(n.rpcCORSHandlers, rootHandler)
 		if n.config.RPC.IsTLSEnabled() {
 			go func() {
 				if err := rpcserver.ServeTLS(
@@ -1232,7 +1667,7 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 
 	// we expose a simplified api over grpc for convenience to app devs
 	grpcListenAddr := n.config.RPC.GRPCListenAddress
-	if grpcListenAddr != "" {
+	if grpcListenAddr != "" && !seedMode {
 		config := rpcserver.DefaultConfig()
 		config.MaxBodyBytes = n.config.RPC.MaxBodyBytes
 		config.MaxBatchRequestNum = n.config.RPC.MaxBatchRequestNum
@@ -1250,7 +1685,8 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 			return nil, err
 		}
 		go func() {
-			if err := grpccore.StartGRPCServer(listener); err != nil {
+			grpcConfig := &grpccore.Config{FullAPI: n.config.RPC.GRPCFullAPI}
+			if err := grpccore.StartGRPCServerWithConfig(listener, grpcConfig); err != nil {
 				n.Logger.Error("Error starting gRPC server", "err", err)
 			}
 		}()
@@ -1262,8 +1698,8 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 }
 
 // startPrometheusServer starts a Prometheus HTTP server, listening for metrics
-// collectors on addr.
-func (n *Node) startPrometheusServer(addr string) *http.Server {
+// collectors on addr. The returned channel is closed if the server fails.
+func (n *Node) startPrometheusServer(addr string) (*http.Server, <-chan struct{}) {
 	srv := &http.Server{
 		Addr: addr,
 		Handler: promhttp.InstrumentMetricHandler(
@@ -1274,13 +1710,15 @@ func (n *Node) startPrometheusServer(addr string) *http.Server {
 		),
 		ReadHeaderTimeout: readHeaderTimeout,
 	}
+	failed := make(chan struct{})
 	go func() {
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 			// Error starting or closing listener:
 			n.Logger.Error("Prometheus HTTP server ListenAndServe", "err", err)
+			close(failed)
 		}
 	}()
-	return srv
+	return srv, failed
 }
 
 // Switch returns the Node's Switch.
@@ -1352,9 +1790,15 @@ func (n *Node) Config() *cfg.Config {
 //------------------------------------------------------------------------------
 
 func (n *Node) Listeners() []string {
-	return []string{
-		fmt.Sprintf("Listener(@%v)", n.config.P2P.ExternalAddress),
+	extAddrs := n.config.P2P.ExternalAddrs()
+	if len(extAddrs) == 0 {
+		return []string{"Listener(@)"}
 	}
+	listeners := make([]string, len(extAddrs))
+	for i, addr := range extAddrs {
+		listeners[i] = fmt.Sprintf("Listener(@%v)", addr)
+	}
+	return listeners
 }
 
 func (n *Node) IsListening() bool {
@@ -1363,7 +1807,36 @@ func (n *Node) IsListening() bool {
 
 // NodeInfo returns the Node's Info from the Switch.
 func (n *Node) NodeInfo() p2p.NodeInfo {
-	return n.nodeInfo
+	return n.sw.NodeInfo()
+}
+
+// makeSeedNodeInfo returns the node info of a seed node, which only has the PEX
+// channel.
+func makeSeedNodeInfo(
+	config *cfg.Config,
+	nodeKey *p2p.NodeKey,
+	genDoc *types.GenesisDoc,
+) (p2p.DefaultNodeInfo, error) {
+	nodeInfo := p2p.DefaultNodeInfo{
+		ProtocolVersion: p2p.NewProtocolVersion(
+			version.P2PProtocol, // global
+			version.BlockProtocol,
+			0,
+		),
+		DefaultNodeID: nodeKey.ID(),
+		Network:       genDoc.ChainID,
+		Version:       version.OCCoreSemVer,
+		Channels:      []byte{pex.PexChannel, pex.ObservedAddrChannel},
+		Moniker:       config.Moniker,
+		Other: p2p.DefaultNodeInfoOther{
+			TxIndex: "off",
+		},
+	}
+
+	setListenAddrs(&nodeInfo, config.P2P)
+
+	err := nodeInfo.Validate()
+	return nodeInfo, err
 }
 
 func makeNodeInfo(
@@ -1401,7 +1874,7 @@ func makeNodeInfo(
 		Version:       version.OCCoreSemVer,
 		Channels: []byte{
 			bcChannel,
-			cs.StateChannel, cs.DataChannel, cs.VoteChannel, cs.VoteSetBitsChannel,
+			cs.StateChannel, cs.DataChannel, cs.VoteChannel, cs.VoteSetBitsChannel, cs.VoteExtensionChannel,
 			mempl.MempoolChannel,
 			evidence.EvidenceChannel,
 			statesync.SnapshotChannel, statesync.ChunkChannel,
@@ -1414,21 +1887,33 @@ func makeNodeInfo(
 	}
 
 	if config.P2P.PexReactor {
-		nodeInfo.Channels = append(nodeInfo.Channels, pex.PexChannel)
+		nodeInfo.Channels = append(nodeInfo.Channels, pex.PexChannel, pex.ObservedAddrChannel)
 	}
-
-	lAddr := config.P2P.ExternalAddress
-
-	if lAddr == "" {
-		lAddr = config.P2P.ListenAddress
+	if config.Mempool.HaveTxFilterSize > 0 {
+		nodeInfo.Channels = append(nodeInfo.Channels, mempl.MempoolHaveTxChannel)
 	}
 
-	nodeInfo.ListenAddr = lAddr
+	setListenAddrs(&nodeInfo, config.P2P)
 
 	err := nodeInfo.Validate()
 	return nodeInfo, err
 }
 
+// setListenAddrs sets the addresses advertised to the peers, the external
+// addresses or else the listen addresses, the first one being the ListenAddr
+// and the others the alternates the peers dial when they fail to dial it.
+func setListenAddrs(nodeInfo *p2p.DefaultNodeInfo, config *cfg.P2PConfig) {
+	addrs := config.ExternalAddrs()
+	if len(addrs) == 0 {
+		addrs = config.ListenAddrs()
+	}
+	if len(addrs) == 0 {
+		return
+	}
+	nodeInfo.ListenAddr = addrs[0]
+	nodeInfo.ListenAddrs = addrs[1:]
+}
+
 //------------------------------------------------------------------------------
 
 var genesisDocKey = []byte("genesisDoc")
@@ -1549,6 +2034,83 @@ func CreateAndStartPrivValidatorSocketClient(config *cfg.Config, chainID string,
 	return pvscWithRetries, nil
 }
 
+// CreatePrivValidatorGRPCClient returns the client of the external signing
+// process at the PrivValidatorGRPCAddr, with the mutual TLS authentication if
+// the certificate files are configured.
+func CreatePrivValidatorGRPCClient(config *cfg.Config, chainID string) (types.PrivValidator, error) {
+	var options []privval.GRPCSignerClientOption
+	if config.PrivValidatorGRPCCert != "" {
+		tlsConfig, err := privval.NewMTLSConfig(
+			config.PrivValidatorGRPCCertFile(),
+			config.PrivValidatorGRPCKeyFile(),
+			config.PrivValidatorGRPCRootCAFile(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS config of private validator: %w", err)
+		}
+		options = append(options, privval.GRPCSignerClientTLS(tlsConfig))
+	}
+
+	pvsc, err := privval.NewGRPCSignerClient(config.PrivValidatorGRPCAddr, chainID, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start private validator: %w", err)
+	}
+
+	// try to get a pubkey from private validate first time
+	_, err = pvsc.GetPubKey()
+	if err != nil {
+		_ = pvsc.Close()
+		return nil, fmt.Errorf("can't get pubkey: %w", err)
+	}
+
+	return pvsc, nil
+}
+
+// CreatePrivValidatorPKCS11 returns the PrivValidator of the key of the
+// PKCS#11 token of the config.
+func CreatePrivValidatorPKCS11(config *cfg.Config) (*privval.PKCS11PV, error) {
+	pv, err := privval.NewPKCS11PV(privval.PKCS11Config{
+		Module:       config.PrivValidatorPKCS11Module,
+		Slot:         config.PrivValidatorPKCS11Slot,
+		PIN:          config.PrivValidatorPKCS11PIN,
+		KeyLabel:     config.PrivValidatorPKCS11KeyLabel,
+		VRFMechanism: config.PrivValidatorPKCS11VRFMechanism,
+	}, config.PrivValidatorStateFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the PKCS#11 private validator: %w", err)
+	}
+	return pv, nil
+}
+
+// setPrivValidatorSignStateStore persists the last sign state of the local
+// private validator with the priv_validator_state_backend of the config, if
+// it's not the state file.
+func setPrivValidatorSignStateStore(config *cfg.Config, pv types.PrivValidator) error {
+	if config.PrivValidatorStateBackend != cfg.PrivValidatorStateBackendPsql {
+		return nil
+	}
+	setter, ok := pv.(interface {
+		SetSignStateStore(privval.SignStateStore)
+	})
+	if !ok {
+		return fmt.Errorf("the private validator %T doesn't support the %s priv_validator_state_backend",
+			pv, config.PrivValidatorStateBackend)
+	}
+	pubKey, err := pv.GetPubKey()
+	if err != nil {
+		return fmt.Errorf("can't get pubkey: %w", err)
+	}
+	// a random holder, so that two nodes of the same moniker can't share the lease
+	holder := config.Moniker + "-" + tmrand.Str(8)
+	store, err := pvpsql.NewSignStateStore(config.PrivValidatorStatePsqlConn,
+		pubKey.Address().String(), holder, config.PrivValidatorStateLeaseTTL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the sign state database: %w", err)
+	}
+	setter.SetSignStateStore(store)
+	return nil
+}
+
 // splitAndTrimEmpty slices s into all subslices separated by sep and returns a
 // slice of the string s with all leading and trailing Unicode code points
 // contained in cutset removed. If sep is empty, SplitAndTrim splits after each
@@ -23,18 +23,21 @@ import (
 	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/evidence"
 	"github.com/Finschia/ostracon/libs/log"
+	tmnet "github.com/Finschia/ostracon/libs/net"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	mempl "github.com/Finschia/ostracon/mempool"
 	mempoolv0 "github.com/Finschia/ostracon/mempool/v0"
-
-	//mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
+	mempoolv1 "github.com/Finschia/ostracon/mempool/v1"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/p2p/conn"
 	p2pmock "github.com/Finschia/ostracon/p2p/mock"
 	p2pmocks "github.com/Finschia/ostracon/p2p/mocks"
+	"github.com/Finschia/ostracon/p2p/pex"
 	"github.com/Finschia/ostracon/privval"
 	"github.com/Finschia/ostracon/proxy"
+	rpchttp "github.com/Finschia/ostracon/rpc/client/http"
 	sm "github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/state/txindex/null"
 	"github.com/Finschia/ostracon/store"
 	"github.com/Finschia/ostracon/types"
 	tmtime "github.com/Finschia/ostracon/types/time"
@@ -102,6 +105,68 @@ func TestNodeStartStop(t *testing.T) {
 	}
 }
 
+func TestNodeStopOrder(t *testing.T) {
+	n := &Node{config: cfg.TestConfig()}
+	n.Logger = log.TestingLogger()
+	s, err := n.newSupervisor()
+	require.NoError(t, err)
+	order, err := s.Order()
+	require.NoError(t, err)
+
+	// the units are stopped in the reverse order
+	stopped := make(map[string]int, len(order))
+	for i, name := range order {
+		stopped[name] = len(order) - i
+	}
+	stages := []string{
+		"rpc writes", "rpc", "mempool gossip", "consensus height", "reactor queues",
+		"consensus wal", "consensus", "switch", "services", "stores",
+	}
+	for i := 1; i < len(stages); i++ {
+		assert.Less(t, stopped[stages[i-1]], stopped[stages[i]], "%s stopped after %s", stages[i-1], stages[i])
+	}
+}
+
+func TestNodeSeedMode(t *testing.T) {
+	config := cfg.ResetTestRoot("node_seed_mode_test")
+	defer os.RemoveAll(config.RootDir)
+	config.Mode = cfg.ModeSeed
+	port, err := tmnet.GetFreePort()
+	require.NoError(t, err)
+	config.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", port)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	err = n.Start()
+	require.NoError(t, err)
+	defer n.Stop() //nolint:errcheck // ignore for tests
+
+	// only the PEX reactor runs, without block store nor mempool
+	assert.Len(t, n.Switch().Reactors(), 1)
+	require.NotNil(t, n.PEXReactor())
+	assert.Equal(t, n.PEXReactor(), n.Switch().Reactor("PEX"))
+	assert.Nil(t, n.BlockStore())
+	assert.Nil(t, n.Mempool())
+	assert.Equal(t, []byte{pex.PexChannel, pex.ObservedAddrChannel}, []byte(n.NodeInfo().(p2p.DefaultNodeInfo).Channels))
+
+	// the RPC server only serves the network info and the crawl statistics
+	client, err := rpchttp.New(config.RPC.ListenAddress, "/websocket")
+	require.NoError(t, err)
+	_, err = client.Health(context.Background())
+	require.NoError(t, err)
+	_, err = client.NetInfo(context.Background())
+	require.NoError(t, err)
+	_, err = client.CrawlStats(context.Background())
+	require.NoError(t, err)
+	_, err = client.Status(context.Background())
+	assert.Error(t, err)
+
+	// filter_peers needs the application
+	config.FilterPeers = true
+	_, err = DefaultNewNode(config, log.TestingLogger())
+	assert.Error(t, err)
+}
+
 func TestSplitAndTrimEmpty(t *testing.T) {
 	testCases := []struct {
 		s        string
@@ -159,6 +224,37 @@ func TestNodeSetAppVersion(t *testing.T) {
 	assert.Equal(t, n.nodeInfo.(p2p.DefaultNodeInfo).ProtocolVersion.App, appVersion)
 }
 
+func TestNodeMempoolVersion(t *testing.T) {
+	config := cfg.ResetTestRoot("node_mempool_version_test")
+	defer os.RemoveAll(config.RootDir)
+
+	config.Mempool.Version = cfg.MempoolV1
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &mempoolv1.TxMempool{}, n.Mempool())
+	assert.IsType(t, &mempoolv1.Reactor{}, n.Switch().Reactor("MEMPOOL"))
+}
+
+func TestNodeMempoolPersist(t *testing.T) {
+	config := cfg.ResetTestRoot("node_mempool_persist_test")
+	defer os.RemoveAll(config.RootDir)
+
+	config.Mempool.PersistToDisk = true
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	tx := types.Tx("persisted=tx")
+	require.NoError(t, n.Mempool().CheckTxSync(tx, nil, mempl.TxInfo{}))
+	n.saveMempool()
+	assert.FileExists(t, config.Mempool.PersistFile())
+
+	// the tx is back in the mempool of the restarted node
+	restarted, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, restarted.loadMempool())
+	assert.Equal(t, types.Txs{tx}, restarted.Mempool().ReapMaxTxs(-1))
+	assert.NoFileExists(t, config.Mempool.PersistFile())
+}
+
 func TestNodeSetPrivValTCP(t *testing.T) {
 	address := testFreeAddr(t)
 	addr := "tcp://" + testFreeAddr(t)
@@ -198,6 +294,23 @@ func TestNodeSetPrivValTCP(t *testing.T) {
 	assert.IsType(t, &privval.RetrySignerClient{}, n.PrivValidator())
 }
 
+func TestNodeSetPrivValGRPC(t *testing.T) {
+	addr := "tcp://" + testFreeAddr(t)
+
+	config := cfg.ResetTestRoot("node_priv_val_grpc_test")
+	defer os.RemoveAll(config.RootDir)
+	config.BaseConfig.PrivValidatorGRPCAddr = addr
+
+	signerServer := privval.NewGRPCSignerServer(addr, config.ChainID(), types.NewMockPV(), nil)
+	signerServer.SetLogger(log.TestingLogger())
+	require.NoError(t, signerServer.Start())
+	defer signerServer.Stop() //nolint:errcheck // ignore for tests
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &privval.GRPCSignerClient{}, n.PrivValidator())
+}
+
 // address without a protocol must result in error
 func TestPrivValidatorListenAddrNoProtocol(t *testing.T) {
 	addrNoPrefix := testFreeAddr(t)
@@ -288,18 +401,14 @@ func TestCreateProposalBlock(t *testing.T) {
 			mempoolv0.WithMetrics(memplMetrics),
 			mempoolv0.WithPreCheck(sm.TxPreCheck(state)),
 			mempoolv0.WithPostCheck(sm.TxPostCheck(state)))
-	case cfg.MempoolV1: // XXX Deprecated MempoolV1
-		panic("Deprecated MempoolV1")
-		/*
-			mempool = mempoolv1.NewTxMempool(logger,
-				config.Mempool,
-				proxyApp.Mempool(),
-				state.LastBlockHeight,
-				mempoolv1.WithMetrics(memplMetrics),
-				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-			)
-		*/
+	case cfg.MempoolV1:
+		mempool = mempoolv1.NewTxMempool(config.Mempool,
+			proxyApp.Mempool(),
+			state.LastBlockHeight,
+			mempoolv1.WithMetrics(memplMetrics),
+			mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+			mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+		)
 	}
 
 	// Make EvidencePool
@@ -343,14 +452,16 @@ func TestCreateProposalBlock(t *testing.T) {
 	commit := types.NewCommit(height-1, 0, types.BlockID{}, nil)
 	message := state.MakeHashMessage(0)
 	proof, _ := privVals[0].GenerateVRFProof(message)
-	block, _ := blockExec.CreateProposalBlock(
+	block, _, err := blockExec.CreateProposalBlock(
 		height,
 		state, commit,
 		proposerAddr,
 		0,
 		proof,
 		0,
+		nil,
 	)
+	require.NoError(t, err)
 
 	// check that the part set does not exceed the maximum block size
 	partSet := block.MakePartSet(partSize)
@@ -400,17 +511,14 @@ func TestMaxProposalBlockSize(t *testing.T) {
 			mempoolv0.WithMetrics(memplMetrics),
 			mempoolv0.WithPreCheck(sm.TxPreCheck(state)),
 			mempoolv0.WithPostCheck(sm.TxPostCheck(state)))
-	case cfg.MempoolV1: // XXX Deprecated
-		/*
-			mempool = mempoolv1.NewTxMempool(logger,
-				config.Mempool,
-				proxyApp.Mempool(),
-				state.LastBlockHeight,
-				mempoolv1.WithMetrics(memplMetrics),
-				mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
-				mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
-			)
-		*/
+	case cfg.MempoolV1:
+		mempool = mempoolv1.NewTxMempool(config.Mempool,
+			proxyApp.Mempool(),
+			state.LastBlockHeight,
+			mempoolv1.WithMetrics(memplMetrics),
+			mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
+			mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+		)
 	}
 
 	// fill the mempool with one txs just below the maximum size
@@ -430,14 +538,16 @@ func TestMaxProposalBlockSize(t *testing.T) {
 	commit := types.NewCommit(height-1, 0, types.BlockID{}, nil)
 	message := state.MakeHashMessage(0)
 	proof, _ := privVals[0].GenerateVRFProof(message)
-	block, _ := blockExec.CreateProposalBlock(
+	block, _, err := blockExec.CreateProposalBlock(
 		height,
 		state, commit,
 		proposerAddr,
 		0,
 		proof,
 		0,
+		nil,
 	)
+	require.NoError(t, err)
 
 	pb, err := block.ToProto()
 	require.NoError(t, err)
@@ -493,6 +603,55 @@ func TestNodeNewNodeCustomReactors(t *testing.T) {
 	assert.Contains(t, channels, cr.Channels[0].ID)
 }
 
+func TestNodeNewNodeWithOptions(t *testing.T) {
+	config := cfg.ResetTestRoot("node_new_node_with_options_test")
+	defer os.RemoveAll(config.RootDir)
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	customReactor := p2pmock.NewReactor()
+	var mempool mempl.Mempool
+	var transport *p2p.MultiplexTransport
+	txIndexer := &null.TxIndex{}
+
+	n, err := NewNode(config,
+		privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
+		nodeKey,
+		proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+		DefaultGenesisDocProviderFunc(config),
+		DefaultDBProvider,
+		DefaultMetricsProvider(config.Instrumentation),
+		log.TestingLogger(),
+		WithBlockStore(blockStore),
+		WithMempool(func(config *cfg.Config, proxyApp proxy.AppConns, state sm.State,
+			memplMetrics *mempl.Metrics, logger log.Logger) (mempl.Mempool, p2p.Reactor) {
+			var reactor p2p.Reactor
+			mempool, reactor = createMempoolAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+			return mempool, reactor
+		}),
+		WithTransport(func(config *cfg.Config, nodeInfo p2p.NodeInfo, nodeKey *p2p.NodeKey,
+			proxyApp proxy.AppConns) (*p2p.MultiplexTransport, []p2p.PeerFilterFunc) {
+			var peerFilters []p2p.PeerFilterFunc
+			transport, peerFilters = createTransport(config, nodeInfo, nodeKey, proxyApp)
+			return transport, peerFilters
+		}),
+		WithReactor("CUSTOM", customReactor),
+		// an option setting a field of the node, applied once it is built
+		func(n *Node) { n.txIndexer = txIndexer },
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, blockStore, n.BlockStore())
+	assert.NotNil(t, mempool)
+	assert.Equal(t, mempool, n.Mempool())
+	assert.NotNil(t, transport)
+	assert.Equal(t, transport, n.transport)
+	assert.Equal(t, customReactor, n.Switch().Reactor("CUSTOM"))
+	assert.Same(t, txIndexer, n.txIndexer)
+}
+
 func TestNodeNewNodeTxIndexIndexer(t *testing.T) {
 	config := cfg.ResetTestRoot("node_new_node_tx_index_indexer_test")
 	defer os.RemoveAll(config.RootDir)
@@ -651,6 +810,22 @@ func TestNodeInvalidNodeInfoCustomReactors(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNodeSetListenAddrs(t *testing.T) {
+	config := cfg.TestP2PConfig()
+	config.ListenAddresses = []string{"tcp://0.0.0.0:26656", "tcp://[::]:26656"}
+
+	var nodeInfo p2p.DefaultNodeInfo
+	setListenAddrs(&nodeInfo, config)
+	assert.Equal(t, "tcp://0.0.0.0:26656", nodeInfo.ListenAddr)
+	assert.Equal(t, []string{"tcp://[::]:26656"}, nodeInfo.ListenAddrs)
+
+	// the external addresses are advertised instead
+	config.ExternalAddresses = []string{"1.2.3.4:26656"}
+	setListenAddrs(&nodeInfo, config)
+	assert.Equal(t, "1.2.3.4:26656", nodeInfo.ListenAddr)
+	assert.Empty(t, nodeInfo.ListenAddrs)
+}
+
 func TestSaveAndLoadBigGensisFile(t *testing.T) {
 	stateDB, err := dbm.NewGoLevelDB("state", os.TempDir())
 	require.NoError(t, err)
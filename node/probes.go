@@ -0,0 +1,178 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	cs "github.com/Finschia/ostracon/consensus"
+	"github.com/Finschia/ostracon/types"
+	tmtime "github.com/Finschia/ostracon/types/time"
+)
+
+// probeResult is the body of the responses to the probes.
+type probeResult struct {
+	Status string `json:"status"`
+	// the result of each check, "ok" or the reason of the failure
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// startProbesServer starts an HTTP server serving the liveness (/livez) and
+// readiness (/readyz) probes on addr. Both return 200 on success and 503
+// otherwise, with the result of each check in a JSON body. The RPC server
+// serves them too, on /health/live and /health/ready.
+func (n *Node) startProbesServer(addr string) (*http.Server, error) {
+	mux := http.NewServeMux()
+	n.handleProbes(mux, "/livez", "/readyz")
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	// listen right away, so that a wrong address is reported on start
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for probes on %s: %w", addr, err)
+	}
+	go func() {
+		if err := srv.Serve(listener); err != http.ErrServerClosed {
+			// Error closing listener:
+			n.Logger.Error("Probes HTTP server Serve", "err", err)
+		}
+	}()
+	return srv, nil
+}
+
+// handleProbes serves the liveness and readiness probes on the paths of the
+// mux.
+func (n *Node) handleProbes(mux *http.ServeMux, livenessPath, readinessPath string) {
+	mux.HandleFunc(livenessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResult(w, n.checkLiveness())
+	})
+	mux.HandleFunc(readinessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeProbeResult(w, n.checkReadiness())
+	})
+}
+
+// checkLiveness checks that the node and its consensus are running.
+func (n *Node) checkLiveness() map[string]string {
+	checks := map[string]string{"node": checkResult(n.checkRunning())}
+	if n.consensusState != nil {
+		checks["consensus"] = "ok"
+		if !n.consensusState.IsRunning() && !n.consensusReactor.WaitSync() {
+			checks["consensus"] = "consensus is not running"
+		}
+	}
+	return checks
+}
+
+// checkReadiness checks that the node is caught up, participates in
+// consensus, the application is connected and the signer is reachable.
+func (n *Node) checkReadiness() map[string]string {
+	checks := map[string]string{"node": checkResult(n.checkRunning())}
+	if n.consensusReactor != nil {
+		checks["caught_up"] = checkResult(n.checkCaughtUp())
+		checks["consensus"] = "ok"
+		if !n.consensusState.IsRunning() {
+			checks["consensus"] = "consensus is not running"
+		}
+	}
+	timeout := n.config.Instrumentation.ProbeTimeout
+	if n.proxyApp != nil {
+		checks["app"] = checkResult(withTimeout(timeout, func() error {
+			_, err := n.proxyApp.Query().EchoSync("ready")
+			return err
+		}))
+	}
+	if n.privValidator != nil {
+		checks["signer"] = checkResult(withTimeout(timeout, func() error {
+			_, err := n.privValidator.GetPubKey()
+			return err
+		}))
+	}
+	return checks
+}
+
+func (n *Node) checkRunning() error {
+	if !n.IsRunning() {
+		return errors.New("node is not running")
+	}
+	return nil
+}
+
+func (n *Node) checkCaughtUp() error {
+	if n.consensusReactor.WaitSync() {
+		return errors.New("node is syncing")
+	}
+	if maxBehind := n.config.Instrumentation.ReadinessMaxBlocksBehind; maxBehind > 0 {
+		height := n.blockStore.Height()
+		if behind := n.maxPeerHeight() - height; behind > maxBehind {
+			return fmt.Errorf("%d blocks behind the peers at height %d (maximum %d)", behind, height, maxBehind)
+		}
+	}
+	maxAge := n.config.Instrumentation.ReadinessMaxBlockAge
+	if maxAge == 0 {
+		return nil
+	}
+	meta := n.blockStore.LoadBlockMeta(n.blockStore.Height())
+	if meta == nil {
+		return errors.New("no blocks")
+	}
+	if age := tmtime.Now().Sub(meta.Header.Time); age > maxAge {
+		return fmt.Errorf("latest block at height %d is %v old (maximum %v)",
+			meta.Header.Height, age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// maxPeerHeight returns the height of the latest block of the most advanced
+// peer.
+func (n *Node) maxPeerHeight() int64 {
+	var maxHeight int64
+	for _, peer := range n.sw.Peers().List() {
+		peerState, ok := peer.Get(types.PeerStateKey).(*cs.PeerState)
+		if !ok { // peer does not have a state yet
+			continue
+		}
+		// the peers are in consensus at the height after their latest block
+		if height := peerState.GetHeight() - 1; height > maxHeight {
+			maxHeight = height
+		}
+	}
+	return maxHeight
+}
+
+// withTimeout runs f, returning an error if it doesn't return within timeout.
+func withTimeout(timeout time.Duration, f func() error) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- f() }()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("no answer within %v", timeout)
+	}
+}
+
+func checkResult(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+func writeProbeResult(w http.ResponseWriter, checks map[string]string) {
+	res := probeResult{Status: "ok", Checks: checks}
+	status := http.StatusOK
+	for _, result := range checks {
+		if result != "ok" {
+			res.Status = "failed"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,97 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	cs "github.com/Finschia/ostracon/consensus"
+	"github.com/Finschia/ostracon/libs/log"
+	tmnet "github.com/Finschia/ostracon/libs/net"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/mock"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestNodeProbes(t *testing.T) {
+	config := cfg.ResetTestRoot("node_probes_test")
+	defer os.RemoveAll(config.RootDir)
+	port, err := tmnet.GetFreePort()
+	require.NoError(t, err)
+	config.Instrumentation.ProbesListenAddr = fmt.Sprintf("127.0.0.1:%d", port)
+	rpcPort, err := tmnet.GetFreePort()
+	require.NoError(t, err)
+	config.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", rpcPort)
+	// only the first blocks are made
+	config.Consensus.CreateEmptyBlocks = false
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+
+	probe := func(path string) (int, probeResult) {
+		return probeAt(t, port, path)
+	}
+
+	status, result := probe("/livez")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", result.Status)
+
+	// the single validator is caught up at once
+	require.Eventually(t, func() bool {
+		status, _ := probe("/readyz")
+		return status == http.StatusOK
+	}, 10*time.Second, 100*time.Millisecond)
+	_, result = probe("/readyz")
+	assert.Equal(t, map[string]string{"node": "ok", "caught_up": "ok", "consensus": "ok", "app": "ok", "signer": "ok"},
+		result.Checks)
+
+	// the RPC server serves them too
+	status, _ = probeAt(t, rpcPort, "/health/live")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = probeAt(t, rpcPort, "/health/ready")
+	assert.Equal(t, http.StatusOK, status)
+
+	// a peer is ahead
+	peer := mock.NewPeer(nil)
+	peerState := cs.NewPeerState(peer)
+	peerState.ApplyNewRoundStepMessage(&cs.NewRoundStepMessage{Height: n.BlockStore().Height() + 11})
+	peer.Set(types.PeerStateKey, peerState)
+	p2p.AddPeerToSwitchPeerSet(n.Switch(), peer)
+	assert.Equal(t, "ok", n.checkReadiness()["caught_up"])
+	n.config.Instrumentation.ReadinessMaxBlocksBehind = 5
+	assert.Contains(t, n.checkReadiness()["caught_up"], "10 blocks behind the peers")
+	n.config.Instrumentation.ReadinessMaxBlocksBehind = 10
+	assert.Equal(t, "ok", n.checkReadiness()["caught_up"])
+	n.Switch().StopPeerGracefully(peer)
+
+	// the latest block gets too old without new blocks
+	n.config.Instrumentation.ReadinessMaxBlockAge = 100 * time.Millisecond
+	var checks map[string]string
+	require.Eventually(t, func() bool {
+		checks = n.checkReadiness()
+		return checks["caught_up"] != "ok"
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Contains(t, checks["caught_up"], "old (maximum 100ms)")
+	assert.Equal(t, "ok", checks["app"])
+
+	require.NoError(t, n.Stop())
+	_, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/livez", port))
+	assert.Error(t, err)
+}
+
+func probeAt(t *testing.T, port int, path string) (int, probeResult) {
+	res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, path))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var result probeResult
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	return res.StatusCode, result
+}
@@ -0,0 +1,97 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/p2p"
+)
+
+// ReactorContext is given to the constructors of the registered reactors.
+type ReactorContext struct {
+	// Config is the configuration of the node.
+	Config *cfg.Config
+	// ChainID is the chain ID of the genesis.
+	ChainID string
+	// Logger is the logger of the reactor, with the "module" set to its name.
+	Logger log.Logger
+	// MetricsLabels are the labels (and their values) of the metrics of the
+	// built-in reactors, e.g. "chain_id". The metrics of the reactor should be
+	// Prometheus ones within Config.Instrumentation.Namespace if
+	// Config.Instrumentation.Prometheus is true, and no-op ones otherwise.
+	MetricsLabels []string
+}
+
+// ReactorConstructor creates a custom reactor registered with RegisterReactor.
+type ReactorConstructor func(ctx ReactorContext) (p2p.Reactor, error)
+
+var (
+	reactorRegistryMtx sync.RWMutex
+	reactorRegistry    = make(map[string]ReactorConstructor)
+)
+
+// RegisterReactor registers the constructor of a custom reactor under the given
+// name (e.g. "myapp.oracle"), typically from an init function of the
+// application, so that it can be added to the node by listing its name in the
+// custom_reactors setting of the configuration. The reactor is added to the
+// Switch under that name, its channels are advertised to the peers and the
+// traffic on them is reported by the p2p metrics, as for the built-in reactors.
+//
+// WARNING: using any name from the list of the existing reactors (see
+// CustomReactors) will result in replacing it with the custom one.
+//
+// It panics if the name is already registered.
+func RegisterReactor(name string, constructor ReactorConstructor) {
+	reactorRegistryMtx.Lock()
+	defer reactorRegistryMtx.Unlock()
+	if name == "" {
+		panic("the name of a reactor can't be empty")
+	}
+	if _, ok := reactorRegistry[name]; ok {
+		panic(fmt.Sprintf("reactor %q is already registered", name))
+	}
+	reactorRegistry[name] = constructor
+}
+
+// RegisteredReactors returns the sorted names of the registered reactors.
+func RegisteredReactors() []string {
+	reactorRegistryMtx.RLock()
+	defer reactorRegistryMtx.RUnlock()
+	names := make([]string, 0, len(reactorRegistry))
+	for name := range reactorRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createRegisteredReactors creates the registered reactors listed in the
+// custom_reactors setting.
+func createRegisteredReactors(config *cfg.Config, chainID string, logger log.Logger) (map[string]p2p.Reactor, error) {
+	reactorRegistryMtx.RLock()
+	defer reactorRegistryMtx.RUnlock()
+
+	reactors := make(map[string]p2p.Reactor, len(config.CustomReactors))
+	for _, name := range config.CustomReactors {
+		constructor, ok := reactorRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("custom reactor %q is not registered", name)
+		}
+		reactorLogger := logger.With("module", name)
+		reactor, err := constructor(ReactorContext{
+			Config:        config,
+			ChainID:       chainID,
+			Logger:        reactorLogger,
+			MetricsLabels: []string{"chain_id", chainID},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create custom reactor %q: %w", name, err)
+		}
+		reactor.SetLogger(reactorLogger)
+		reactors[name] = reactor
+	}
+	return reactors, nil
+}
@@ -0,0 +1,75 @@
+package node
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/conn"
+	p2pmock "github.com/Finschia/ostracon/p2p/mock"
+	"github.com/Finschia/ostracon/privval"
+	"github.com/Finschia/ostracon/proxy"
+)
+
+func TestNodeRegisteredReactors(t *testing.T) {
+	config := cfg.ResetTestRoot("node_registered_reactors_test")
+	defer os.RemoveAll(config.RootDir)
+
+	cr := p2pmock.NewReactor()
+	cr.Channels = []*conn.ChannelDescriptor{
+		{
+			ID:                  byte(0x71),
+			Priority:            5,
+			SendQueueCapacity:   100,
+			RecvMessageCapacity: 100,
+		},
+	}
+	var reactorCtx ReactorContext
+	RegisterReactor("test.registered", func(ctx ReactorContext) (p2p.Reactor, error) {
+		reactorCtx = ctx
+		return cr, nil
+	})
+	assert.Contains(t, RegisteredReactors(), "test.registered")
+	assert.Panics(t, func() {
+		RegisterReactor("test.registered", func(ctx ReactorContext) (p2p.Reactor, error) { return cr, nil })
+	})
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+	newNode := func() (*Node, error) {
+		return NewNode(config,
+			privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
+			nodeKey,
+			proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+			DefaultGenesisDocProviderFunc(config),
+			DefaultDBProvider,
+			DefaultMetricsProvider(config.Instrumentation),
+			log.TestingLogger(),
+		)
+	}
+
+	// unknown reactor
+	config.CustomReactors = []string{"test.unknown"}
+	_, err = newNode()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `custom reactor "test.unknown" is not registered`)
+
+	config.CustomReactors = []string{"test.registered"}
+	n, err := newNode()
+	require.NoError(t, err)
+	assert.Equal(t, n.GenesisDoc().ChainID, reactorCtx.ChainID)
+	assert.Equal(t, []string{"chain_id", n.GenesisDoc().ChainID}, reactorCtx.MetricsLabels)
+
+	err = n.Start()
+	require.NoError(t, err)
+	defer n.Stop() //nolint:errcheck // ignore for tests
+
+	assert.True(t, cr.IsRunning())
+	assert.Equal(t, cr, n.Switch().Reactor("test.registered"))
+	assert.Contains(t, n.NodeInfo().(p2p.DefaultNodeInfo).Channels, cr.Channels[0].ID)
+}
@@ -0,0 +1,248 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/cors"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+)
+
+// reloadableSettings are the settings which can be changed while the node is
+// running, by their key in the configuration file.
+var reloadableSettings = map[string]struct{}{
+	"log_level":                {},
+	"log_format":               {},
+	"mempool.size":             {},
+	"mempool.max_txs_bytes":    {},
+	"p2p.persistent_peers":     {},
+	"p2p.send_rate":            {},
+	"p2p.recv_rate":            {},
+	"rpc.cors_allowed_origins": {},
+	"rpc.cors_allowed_methods": {},
+	"rpc.cors_allowed_headers": {},
+	"storage.retain_blocks":    {},
+	"storage.pruning_interval": {},
+}
+
+// ConfigLoader loads the latest configuration, e.g. from the configuration
+// file and the command line flags.
+type ConfigLoader func() (*cfg.Config, error)
+
+// SetConfigLoader sets the function used by ReloadConfigFile to load the
+// latest configuration. It must be called before starting the node.
+func (n *Node) SetConfigLoader(loader ConfigLoader) {
+	n.configLoader = loader
+}
+
+// ReloadConfigFile loads the latest configuration with the ConfigLoader and
+// applies it with ReloadConfig. It returns the keys of the changed settings
+// which are applied, and of the ones which require restarting the node.
+func (n *Node) ReloadConfigFile() (changed, restart []string, err error) {
+	if n.configLoader == nil {
+		return nil, nil, errors.New("reloading the configuration is not supported by this node")
+	}
+	newConfig, err := n.configLoader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return n.ReloadConfig(newConfig)
+}
+
+// ReloadConfig applies the settings of newConfig which differ from the running
+// configuration. Only the settings which are safe to change while running are
+// applied:
+//
+//   - log_level and log_format (if the node logger is a log.ReloadableLogger)
+//   - mempool.size and mempool.max_txs_bytes
+//   - p2p.persistent_peers (new persistent peers are dialed right away)
+//   - p2p.send_rate and p2p.recv_rate, of the connected peers too
+//   - rpc.cors_allowed_origins, rpc.cors_allowed_methods and
+//     rpc.cors_allowed_headers
+//   - storage.retain_blocks and storage.pruning_interval
+//
+// It returns the keys of the changed settings which are applied, and of the
+// other ones, which require restarting the node: they are left as they are
+// until then. Nothing is applied if newConfig is invalid.
+func (n *Node) ReloadConfig(newConfig *cfg.Config) (changed, restart []string, err error) {
+	if err := newConfig.ValidateBasic(); err != nil {
+		return nil, nil, fmt.Errorf("error in config file: %v", err)
+	}
+
+	n.configMtx.Lock()
+	defer n.configMtx.Unlock()
+
+	for _, key := range diffConfig(n.config, newConfig) {
+		if _, ok := reloadableSettings[key]; ok {
+			changed = append(changed, key)
+		} else {
+			restart = append(restart, key)
+		}
+	}
+	if len(restart) > 0 {
+		n.Logger.Error("Some settings can't be changed without restarting the node", "settings", restart)
+	}
+	if len(changed) == 0 {
+		return nil, restart, nil
+	}
+
+	if newConfig.LogLevel != n.config.LogLevel || newConfig.LogFormat != n.config.LogFormat {
+		logger, ok := n.Logger.(*log.ReloadableLogger)
+		if !ok {
+			return nil, restart, errors.New("log_level and log_format can't be changed: the node logger is not reloadable")
+		}
+		if err := logger.Reload(newConfig.LogFormat, newConfig.LogLevel); err != nil {
+			return nil, restart, fmt.Errorf("failed to reload the logger: %w", err)
+		}
+		n.config.LogLevel = newConfig.LogLevel
+		n.config.LogFormat = newConfig.LogFormat
+	}
+
+	if newConfig.Mempool.Size != n.config.Mempool.Size ||
+		newConfig.Mempool.MaxTxsBytes != n.config.Mempool.MaxTxsBytes {
+		// the mempool reads its limits from the config while holding the lock
+		// (there is no mempool in seed mode)
+		if n.mempool != nil {
+			n.mempool.Lock()
+		}
+		n.config.Mempool.Size = newConfig.Mempool.Size
+		n.config.Mempool.MaxTxsBytes = newConfig.Mempool.MaxTxsBytes
+		if n.mempool != nil {
+			n.mempool.Unlock()
+		}
+	}
+
+	if newConfig.P2P.PersistentPeers != n.config.P2P.PersistentPeers {
+		peers := splitAndTrimEmpty(newConfig.P2P.PersistentPeers, ",", " ")
+		if err := n.sw.AddPersistentPeers(peers); err != nil {
+			return nil, restart, fmt.Errorf("could not add peers from persistent_peers field: %w", err)
+		}
+		n.config.P2P.PersistentPeers = newConfig.P2P.PersistentPeers
+		if n.IsRunning() {
+			if err := n.sw.DialPeersAsync(peers); err != nil {
+				n.Logger.Error("Could not dial persistent peers", "err", err)
+			}
+		}
+	}
+
+	if newConfig.P2P.SendRate != n.config.P2P.SendRate || newConfig.P2P.RecvRate != n.config.P2P.RecvRate {
+		n.sw.SetRates(newConfig.P2P.SendRate, newConfig.P2P.RecvRate)
+		n.config.P2P.SendRate = newConfig.P2P.SendRate
+		n.config.P2P.RecvRate = newConfig.P2P.RecvRate
+	}
+
+	if !reflect.DeepEqual(newConfig.RPC.CORSAllowedOrigins, n.config.RPC.CORSAllowedOrigins) ||
+		!reflect.DeepEqual(newConfig.RPC.CORSAllowedMethods, n.config.RPC.CORSAllowedMethods) ||
+		!reflect.DeepEqual(newConfig.RPC.CORSAllowedHeaders, n.config.RPC.CORSAllowedHeaders) {
+		n.config.RPC.CORSAllowedOrigins = newConfig.RPC.CORSAllowedOrigins
+		n.config.RPC.CORSAllowedMethods = newConfig.RPC.CORSAllowedMethods
+		n.config.RPC.CORSAllowedHeaders = newConfig.RPC.CORSAllowedHeaders
+		for _, h := range n.rpcCORSHandlers {
+			h.reload(n.config.RPC)
+		}
+	}
+
+	if newConfig.Storage.RetainBlocks != n.config.Storage.RetainBlocks ||
+		newConfig.Storage.PruningInterval != n.config.Storage.PruningInterval {
+		n.pruner.SetRetainBlocks(newConfig.Storage.RetainBlocks)
+		n.pruner.SetInterval(newConfig.Storage.PruningInterval)
+		n.config.Storage.RetainBlocks = newConfig.Storage.RetainBlocks
+		n.config.Storage.PruningInterval = newConfig.Storage.PruningInterval
+	}
+
+	n.Logger.Info("Reloaded configuration", "changed", changed)
+	return changed, restart, nil
+}
+
+// SetLogLevel changes the log level of the running node, for all the modules
+// or per module, e.g. "consensus:debug,p2p:error,*:info" (see log_level in the
+// configuration). It returns the previous log level.
+func (n *Node) SetLogLevel(level string) (string, error) {
+	n.configMtx.Lock()
+	defer n.configMtx.Unlock()
+
+	logger, ok := n.Logger.(*log.ReloadableLogger)
+	if !ok {
+		return "", errors.New("log_level can't be changed: the node logger is not reloadable")
+	}
+	if err := logger.Reload(n.config.LogFormat, level); err != nil {
+		return "", fmt.Errorf("failed to reload the logger: %w", err)
+	}
+	previous := n.config.LogLevel
+	n.config.LogLevel = level
+
+	n.Logger.Info("Changed log level", "previous", previous, "log_level", level)
+	return previous, nil
+}
+
+// corsHandler serves the RPC with the CORS settings of the configuration, which
+// can be reloaded while the node is running.
+type corsHandler struct {
+	next    http.Handler
+	handler atomic.Pointer[http.Handler]
+}
+
+func newCORSHandler(next http.Handler, config *cfg.RPCConfig) *corsHandler {
+	h := &corsHandler{next: next}
+	h.reload(config)
+	return h
+}
+
+// reload applies the CORS settings of the configuration to the next requests,
+// disabling CORS if no origin is allowed.
+func (h *corsHandler) reload(config *cfg.RPCConfig) {
+	handler := h.next
+	if config.IsCorsEnabled() {
+		handler = cors.New(cors.Options{
+			AllowedOrigins: config.CORSAllowedOrigins,
+			AllowedMethods: config.CORSAllowedMethods,
+			AllowedHeaders: config.CORSAllowedHeaders,
+		}).Handler(h.next)
+	}
+	h.handler.Store(&handler)
+}
+
+func (h *corsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.handler.Load()).ServeHTTP(w, r)
+}
+
+// diffConfig returns the sorted keys of the settings which differ between the
+// two configurations, e.g. "log_level" or "mempool.size".
+func diffConfig(a, b *cfg.Config) []string {
+	var keys []string
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if field.Anonymous {
+			// the base config is squashed into the top level
+			keys = append(keys, diffSection(va.Field(i), vb.Field(i), "")...)
+			continue
+		}
+		keys = append(keys, diffSection(va.Field(i).Elem(), vb.Field(i).Elem(), name+".")...)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func diffSection(a, b reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			keys = append(keys, prefix+name)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,148 @@
+package node
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+)
+
+func copyConfig(c *cfg.Config) *cfg.Config {
+	rpc, p2p, mempool := *c.RPC, *c.P2P, *c.Mempool
+	statesync, fastsync, consensus := *c.StateSync, *c.FastSync, *c.Consensus
+	storage, txIndex, instrumentation := *c.Storage, *c.TxIndex, *c.Instrumentation
+	return &cfg.Config{
+		BaseConfig:      c.BaseConfig,
+		RPC:             &rpc,
+		P2P:             &p2p,
+		Mempool:         &mempool,
+		StateSync:       &statesync,
+		FastSync:        &fastsync,
+		Consensus:       &consensus,
+		Storage:         &storage,
+		TxIndex:         &txIndex,
+		Instrumentation: &instrumentation,
+	}
+}
+
+func TestNodeReloadConfig(t *testing.T) {
+	config := cfg.ResetTestRoot("node_reload_config_test")
+	defer os.RemoveAll(config.RootDir)
+
+	build := func(format, level string) (log.Logger, error) {
+		return log.ParseLogLevel(level, log.TestingLogger(), cfg.DefaultLogLevel)
+	}
+	logger, err := log.NewReloadableLogger(build, config.LogFormat, config.LogLevel)
+	require.NoError(t, err)
+	n, err := DefaultNewNode(config, logger)
+	require.NoError(t, err)
+
+	// nothing changed
+	changed, restart, err := n.ReloadConfig(copyConfig(config))
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, restart)
+
+	// safe settings are applied
+	newConfig := copyConfig(config)
+	newConfig.LogLevel = "debug"
+	newConfig.Mempool.Size = 10
+	newConfig.P2P.PersistentPeers = "9188b4b7472e1a9348dc8b2c01ad9ca59937c1c6@127.0.0.1:26656"
+	newConfig.P2P.SendRate = 1000
+	newConfig.RPC.CORSAllowedOrigins = []string{"*"}
+	newConfig.Storage.RetainBlocks = 100
+	changed, restart, err = n.ReloadConfig(newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log_level", "mempool.size", "p2p.persistent_peers", "p2p.send_rate",
+		"rpc.cors_allowed_origins", "storage.retain_blocks"}, changed)
+	assert.Empty(t, restart)
+	assert.Equal(t, "debug", config.LogLevel)
+	assert.Equal(t, 10, config.Mempool.Size)
+	assert.Equal(t, newConfig.P2P.PersistentPeers, config.P2P.PersistentPeers)
+	assert.EqualValues(t, 1000, config.P2P.SendRate)
+	assert.Equal(t, []string{"*"}, config.RPC.CORSAllowedOrigins)
+	assert.EqualValues(t, 100, n.pruner.Status().RetainBlocks)
+
+	// unsafe settings are reported and not applied, unlike the safe ones
+	newConfig = copyConfig(config)
+	newConfig.Mempool.Size = 20
+	newConfig.Moniker = "other"
+	newConfig.Consensus.TimeoutCommit++
+	changed, restart, err = n.ReloadConfig(newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mempool.size"}, changed)
+	assert.Equal(t, []string{"consensus.timeout_commit", "moniker"}, restart)
+	assert.Equal(t, 20, config.Mempool.Size)
+	assert.NotEqual(t, "other", config.Moniker)
+
+	// an invalid configuration isn't applied
+	newConfig = copyConfig(config)
+	newConfig.Mempool.Size = -1
+	_, _, err = n.ReloadConfig(newConfig)
+	require.Error(t, err)
+	assert.Equal(t, 20, config.Mempool.Size)
+
+	// reloading without a config loader is not supported
+	_, _, err = n.ReloadConfigFile()
+	require.Error(t, err)
+}
+
+func TestCORSHandlerReload(t *testing.T) {
+	config := cfg.TestRPCConfig()
+	h := newCORSHandler(http.NotFoundHandler(), config)
+	allowedOrigin := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "http://example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Header().Get("Access-Control-Allow-Origin")
+	}
+	assert.Empty(t, allowedOrigin())
+
+	config.CORSAllowedOrigins = []string{"*"}
+	h.reload(config)
+	assert.Equal(t, "*", allowedOrigin())
+
+	config.CORSAllowedOrigins = nil
+	h.reload(config)
+	assert.Empty(t, allowedOrigin())
+}
+
+func TestNodeSetLogLevel(t *testing.T) {
+	config := cfg.ResetTestRoot("node_set_log_level_test")
+	defer os.RemoveAll(config.RootDir)
+
+	var levels []string
+	build := func(format, level string) (log.Logger, error) {
+		levels = append(levels, level)
+		return log.ParseLogLevel(level, log.TestingLogger(), cfg.DefaultLogLevel)
+	}
+	logger, err := log.NewReloadableLogger(build, config.LogFormat, config.LogLevel)
+	require.NoError(t, err)
+	n, err := DefaultNewNode(config, logger)
+	require.NoError(t, err)
+
+	initial := config.LogLevel
+	previous, err := n.SetLogLevel("consensus:debug,*:error")
+	require.NoError(t, err)
+	assert.Equal(t, initial, previous)
+	assert.Equal(t, "consensus:debug,*:error", config.LogLevel)
+	assert.Equal(t, "consensus:debug,*:error", levels[len(levels)-1])
+
+	// an invalid level is rejected and nothing is applied
+	_, err = n.SetLogLevel("consensus:verbose")
+	require.Error(t, err)
+	assert.Equal(t, "consensus:debug,*:error", config.LogLevel)
+
+	// the node logger must be reloadable
+	n, err = DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	_, err = n.SetLogLevel("debug")
+	require.Error(t, err)
+}
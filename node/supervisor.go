@@ -0,0 +1,437 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/libs/tracing"
+	"github.com/Finschia/ostracon/p2p"
+	rpccore "github.com/Finschia/ostracon/rpc/core"
+)
+
+// prometheusRestartPolicy restarts the Prometheus server if it fails, e.g.
+// because its address is still in use.
+var prometheusRestartPolicy = service.RestartPolicy{
+	MaxRestarts: 10,
+	Backoff:     time.Second,
+	MaxBackoff:  time.Minute,
+}
+
+// newSupervisor returns the supervisor starting and stopping the parts of the
+// node in the order of their dependencies:
+//
+//	stores → services → switch (and its reactors) → consensus → RPC
+//
+// They are stopped in the reverse order they are started, and each stop is
+// given up after a timeout (see the shutdown*Timeout constants), in which case
+// the units it depends on, e.g. the stores, are left open, not to be closed
+// under it. When stopping, the RPC writes and the mempool gossip are stopped
+// first, then the height the node is voting in is given up to
+// shutdown_drain_timeout to be committed, before the WAL is flushed and the
+// peers are stopped.
+func (n *Node) newSupervisor() (*service.Supervisor, error) {
+	s := service.NewSupervisor()
+	s.SetLogger(n.Logger.With("module", "supervisor"))
+
+	units := []service.Unit{
+		{
+			// the remaining spans are exported last
+			Name: "tracing",
+			Stop: func() {
+				if n.tracerProvider == nil {
+					return
+				}
+				tracing.SetMessageSampleRate(0)
+				ctx, cancel := context.WithTimeout(context.Background(), shutdownTracingTimeout)
+				defer cancel()
+				if err := n.tracerProvider.Shutdown(ctx); err != nil {
+					n.Logger.Error("Error exporting the remaining spans", "err", err)
+				}
+			},
+			StopTimeout: shutdownTracingTimeout,
+		},
+		{
+			// the remaining metrics are pushed last
+			Name: "metrics",
+			Start: func() error {
+				if n.metricsPusher == nil {
+					return nil
+				}
+				n.metricsPusher.SetLogger(n.Logger.With("module", "metrics"))
+				return n.metricsPusher.Start()
+			},
+			Stop: func() {
+				if n.metricsPusher == nil {
+					return
+				}
+				if err := n.metricsPusher.Stop(); err != nil {
+					n.Logger.Error("Error pushing the remaining metrics", "err", err)
+				}
+			},
+			StopTimeout: shutdownMetricsTimeout,
+		},
+		{
+			// the stores are opened when the node is built
+			Name:        "stores",
+			Stop:        n.closeStores,
+			StopTimeout: shutdownStoresTimeout,
+		},
+		{
+			// the application run by the node, if configured so, is started
+			// with the connections to it when the node is built, and stopped
+			// after the parts using them
+			Name:        "app",
+			Stop:        n.stopApp,
+			StopTimeout: n.config.ProxyAppStopTimeout + shutdownServicesTimeout,
+		},
+		{
+			// the non-reactor services are started when the node is built
+			Name:        "services",
+			DependsOn:   []string{"stores", "app"},
+			Stop:        n.stopServices,
+			StopTimeout: shutdownServicesTimeout,
+		},
+		n.prometheusUnit(),
+		{
+			Name:      "watchdog",
+			DependsOn: []string{"services"},
+			Start: func() error {
+				if n.watchdog == nil {
+					return nil
+				}
+				return n.watchdog.Start()
+			},
+			Stop: func() {
+				if n.watchdog == nil {
+					return
+				}
+				if err := n.watchdog.Stop(); err != nil {
+					n.Logger.Error("Error closing watchdog", "err", err)
+				}
+			},
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			Name:      "snapshotter",
+			DependsOn: []string{"services"},
+			Start: func() error {
+				if n.snapshotter == nil {
+					return nil
+				}
+				return n.snapshotter.Start()
+			},
+			Stop: func() {
+				if n.snapshotter == nil {
+					return
+				}
+				if err := n.snapshotter.Stop(); err != nil {
+					n.Logger.Error("Error stopping snapshotter", "err", err)
+				}
+			},
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			Name:      "pruner",
+			DependsOn: []string{"stores"},
+			Start: func() error {
+				if n.pruner == nil {
+					return nil
+				}
+				return n.pruner.Start()
+			},
+			Stop: func() {
+				if n.pruner == nil {
+					return
+				}
+				if err := n.pruner.Stop(); err != nil {
+					n.Logger.Error("Error stopping pruner", "err", err)
+				}
+			},
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			// the txs saved are back in the mempool before being gossiped,
+			// and saved once the consensus stopped updating it
+			Name:        "mempool persistence",
+			DependsOn:   []string{"services"},
+			Start:       n.loadMempool,
+			Stop:        n.saveMempool,
+			StopTimeout: shutdownMempoolTimeout,
+		},
+		{
+			Name:        "switch",
+			DependsOn:   []string{"services", "mempool persistence"},
+			Start:       n.startSwitch,
+			Stop:        n.stopSwitch,
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			Name:        "port mapping",
+			DependsOn:   []string{"switch"},
+			Start:       n.startPortMapping,
+			Stop:        n.stopPortMapping,
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			// the consensus reactor is started by the switch
+			Name:      "consensus",
+			DependsOn: []string{"switch"},
+			Stop: func() {
+				if n.consensusReactor != nil && n.consensusReactor.IsRunning() {
+					if err := n.consensusReactor.Stop(); err != nil {
+						n.Logger.Error("Error stopping consensus reactor", "err", err)
+					}
+				}
+			},
+			StopTimeout: shutdownConsensusTimeout,
+		},
+		{
+			Name:      "consensus wal",
+			DependsOn: []string{"consensus"},
+			Stop: func() {
+				if n.consensusState == nil {
+					return
+				}
+				if err := n.consensusState.FlushWAL(); err != nil {
+					n.Logger.Error("Error flushing WAL", "err", err)
+				}
+			},
+			StopTimeout: shutdownWALTimeout,
+		},
+		{
+			// drain the queues of the reactors receiving messages
+			// asynchronously, before the consensus is stopped
+			Name:      "reactor queues",
+			DependsOn: []string{"switch"},
+			Stop: func() {
+				for name, reactor := range n.sw.Reactors() {
+					if d, ok := reactor.(queueDrainer); ok && reactor.IsRunning() {
+						n.Logger.Debug("Draining reactor queue", "reactor", name)
+						d.DrainQueue()
+					}
+				}
+			},
+			StopTimeout: shutdownDrainTimeout,
+		},
+		{
+			// the height the node is voting in is committed, with the peers,
+			// before the consensus is stopped
+			Name:        "consensus height",
+			DependsOn:   []string{"consensus"},
+			Stop:        n.finishHeight,
+			StopTimeout: n.config.ShutdownDrainTimeout + shutdownConsensusTimeout,
+		},
+		{
+			Name:      "state sync",
+			DependsOn: []string{"switch"},
+			Start: func() error {
+				if !n.stateSync {
+					return nil
+				}
+				bcR, ok := n.bcReactor.(fastSyncReactor)
+				if !ok {
+					return fmt.Errorf("this blockchain reactor does not support switching from state sync")
+				}
+				err := startStateSync(n.stateSyncReactor, bcR, n.consensusReactor, n.stateSyncProvider,
+					n.config.StateSync, n.config.FastSyncMode, n.stateStore, n.blockStore, n.stateSyncGenesis)
+				if err != nil {
+					return fmt.Errorf("failed to start state sync: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			// the txs aren't gossiped anymore once the RPC stopped accepting
+			// them
+			Name:      "mempool gossip",
+			DependsOn: []string{"switch"},
+			Stop: func() {
+				if n.mempoolReactor != nil && n.mempoolReactor.IsRunning() {
+					if err := n.mempoolReactor.Stop(); err != nil {
+						n.Logger.Error("Error stopping mempool reactor", "err", err)
+					}
+				}
+			},
+			StopTimeout: shutdownServicesTimeout,
+		},
+		{
+			// the RPC requests are not accepted anymore (and the probes not
+			// answered) once the writes are closed
+			Name:        "rpc",
+			DependsOn:   []string{"services", "switch"},
+			Start:       n.startRPCServers,
+			Stop:        n.stopRPCServers,
+			StopTimeout: shutdownRPCTimeout,
+		},
+		{
+			// the txs and evidence aren't accepted anymore first when stopping,
+			// over the connections still open too
+			Name:      "rpc writes",
+			DependsOn: []string{"rpc"},
+			Stop: func() {
+				if n.rpcListeners != nil {
+					rpccore.CloseWrites()
+				}
+			},
+		},
+	}
+	for _, unit := range units {
+		if err := s.Add(unit); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// prometheusUnit returns the unit of the Prometheus server, which is restarted
+// if it fails.
+func (n *Node) prometheusUnit() service.Unit {
+	var failed <-chan struct{}
+	return service.Unit{
+		Name: "prometheus",
+		Start: func() error {
+			if n.config.Instrumentation.Prometheus && n.config.Instrumentation.PrometheusListenAddr != "" {
+				n.prometheusSrv, failed = n.startPrometheusServer(n.config.Instrumentation.PrometheusListenAddr)
+			}
+			return nil
+		},
+		Stop: func() {
+			if n.prometheusSrv == nil {
+				return
+			}
+			if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
+				// Error from closing listeners, or context timeout:
+				n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
+			}
+		},
+		StopTimeout: shutdownServicesTimeout,
+		Failed: func() <-chan struct{} {
+			return failed
+		},
+		Restart: prometheusRestartPolicy,
+	}
+}
+
+func (n *Node) startSwitch() error {
+	// Add private IDs to addrbook to block those peers being added
+	n.addrBook.AddPrivateIDs(splitAndTrimEmpty(n.config.P2P.PrivatePeerIDs, ",", " "))
+
+	// Start the transport, listening on every address.
+	for _, lAddr := range n.config.P2P.ListenAddrs() {
+		addr, err := p2p.NewNetAddressString(p2p.IDAddressString(n.nodeKey.ID(), lAddr))
+		if err != nil {
+			return err
+		}
+		if err := n.transport.Listen(*addr); err != nil {
+			return err
+		}
+	}
+
+	n.isListening = true
+
+	// Start the switch (the P2P server).
+	if err := n.sw.Start(); err != nil {
+		return err
+	}
+
+	// Always connect to persistent peers
+	err := n.sw.DialPeersAsync(splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " "))
+	if err != nil {
+		return fmt.Errorf("could not dial peers from persistent_peers field: %w", err)
+	}
+	return nil
+}
+
+// finishHeight waits, up to shutdown_drain_timeout, for the height the node is
+// voting in to be committed, see consensus.State.WaitForCommit.
+func (n *Node) finishHeight() {
+	if n.consensusState == nil || n.config.ShutdownDrainTimeout == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), n.config.ShutdownDrainTimeout)
+	defer cancel()
+	if err := n.consensusState.WaitForCommit(ctx); err != nil {
+		n.Logger.Error("Stopping before the height in progress is committed",
+			"timeout", n.config.ShutdownDrainTimeout, "err", err)
+	}
+}
+
+func (n *Node) stopSwitch() {
+	if err := n.sw.Stop(); err != nil {
+		n.Logger.Error("Error closing switch", "err", err)
+	}
+
+	if err := n.transport.Close(); err != nil {
+		n.Logger.Error("Error closing transport", "err", err)
+	}
+
+	n.isListening = false
+}
+
+func (n *Node) startRPCServers() error {
+	if n.config.RPC.ListenAddress != "" {
+		listeners, err := n.startRPC()
+		if err != nil {
+			return err
+		}
+		n.rpcListeners = listeners
+	}
+
+	if n.config.Instrumentation.ProbesListenAddr != "" {
+		probesSrv, err := n.startProbesServer(n.config.Instrumentation.ProbesListenAddr)
+		if err != nil {
+			return err
+		}
+		n.probesSrv = probesSrv
+	}
+	return nil
+}
+
+func (n *Node) stopRPCServers() {
+	for _, l := range n.rpcListeners {
+		n.Logger.Info("Closing rpc listener", "listener", l)
+		if err := l.Close(); err != nil {
+			n.Logger.Error("Error closing listener", "listener", l, "err", err)
+		}
+	}
+	if n.probesSrv != nil {
+		if err := n.probesSrv.Close(); err != nil {
+			n.Logger.Error("Error closing probes HTTP server", "err", err)
+		}
+	}
+}
+
+func (n *Node) stopServices() {
+	// the non-reactor services are not running in seed mode
+	if n.eventBus != nil {
+		if err := n.eventBus.Stop(); err != nil {
+			n.Logger.Error("Error closing eventBus", "err", err)
+		}
+	}
+	if n.indexerService != nil {
+		if err := n.indexerService.Stop(); err != nil {
+			n.Logger.Error("Error closing indexerService", "err", err)
+		}
+	}
+
+	if pvsc, ok := n.privValidator.(service.Service); ok {
+		if err := pvsc.Stop(); err != nil {
+			n.Logger.Error("Error closing private validator", "err", err)
+		}
+	}
+}
+
+func (n *Node) closeStores() {
+	if n.blockStore != nil {
+		if err := n.blockStore.Close(); err != nil {
+			n.Logger.Error("problem closing blockstore", "err", err)
+		}
+	}
+	if n.stateStore != nil {
+		if err := n.stateStore.Close(); err != nil {
+			n.Logger.Error("problem closing statestore", "err", err)
+		}
+	}
+}
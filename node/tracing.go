@@ -0,0 +1,70 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/tracing"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/version"
+)
+
+// setupTracing sets the global tracer provider up to export the spans to the
+// OpenTelemetry collector at tracing_otlp_endpoint, sampling the traces started
+// by the node at tracing_sample_rate, and the messages of the peers too if
+// tracing_enabled. It returns nil if the tracing is disabled.
+func setupTracing(config *cfg.Config, nodeID p2p.ID, chainID string) (*sdktrace.TracerProvider, error) {
+	instrumentation := config.Instrumentation
+	if instrumentation.TracingOTLPEndpoint == "" {
+		return nil, nil
+	}
+
+	var client otlptrace.Client
+	switch instrumentation.TracingOTLPProtocol {
+	case cfg.TracingOTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(instrumentation.TracingOTLPEndpoint)}
+		if instrumentation.TracingOTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		client = otlptracehttp.NewClient(opts...)
+	default:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(instrumentation.TracingOTLPEndpoint)}
+		if instrumentation.TracingOTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		client = otlptracegrpc.NewClient(opts...)
+	}
+	// the client connects in the background, so this doesn't block
+	exporter, err := otlptrace.New(context.Background(), client)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the OTLP exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(instrumentation.TracingSampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "ostracon"),
+			attribute.String("service.version", version.OCCoreSemVer),
+			attribute.String("service.instance.id", string(nodeID)),
+			attribute.String("chain_id", chainID),
+			attribute.String("moniker", config.Moniker),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if instrumentation.TracingEnabled {
+		tracing.SetMessageSampleRate(instrumentation.TracingSampleRate)
+	}
+	return tp, nil
+}
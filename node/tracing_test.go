@@ -0,0 +1,35 @@
+package node
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	cfg "github.com/Finschia/ostracon/config"
+)
+
+func TestSetupTracing(t *testing.T) {
+	config := cfg.ResetTestRoot("node_setup_tracing_test")
+	defer os.RemoveAll(config.RootDir)
+
+	tp, err := setupTracing(config, "node", "chain")
+	require.NoError(t, err)
+	assert.Nil(t, tp)
+
+	prev := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(prev)
+	for _, protocol := range []string{cfg.TracingOTLPProtocolGRPC, cfg.TracingOTLPProtocolHTTP} {
+		config.Instrumentation.TracingOTLPEndpoint = "localhost:4317"
+		config.Instrumentation.TracingOTLPProtocol = protocol
+		config.Instrumentation.TracingOTLPInsecure = true
+		tp, err = setupTracing(config, "node", "chain")
+		require.NoError(t, err, protocol)
+		require.NotNil(t, tp, protocol)
+		assert.Equal(t, tp, otel.GetTracerProvider(), protocol)
+		require.NoError(t, tp.Shutdown(context.Background()), protocol)
+	}
+}
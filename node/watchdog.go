@@ -0,0 +1,264 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	watchdogSubscriber = "profiling-watchdog"
+
+	// watchdogCPUProfileDuration is how long the CPU profile of a capture lasts.
+	watchdogCPUProfileDuration = 10 * time.Second
+
+	// watchdogCheckInterval is the maximum interval between the checks of the
+	// watchdog.
+	watchdogCheckInterval = time.Second
+)
+
+// profilingWatchdog captures the CPU, heap and goroutine profiles of the node
+// when a consensus round lasts more than watchdog_round_timeout or when no
+// block is committed for watchdog_commit_timeout (while the node isn't
+// syncing), at most once per round and per stall. The captures are written to
+// a directory per capture in watchdog_profiles_dir, keeping the latest
+// watchdog_max_captures ones.
+type profilingWatchdog struct {
+	service.BaseService
+
+	config   *cfg.InstrumentationConfig
+	eventBus *types.EventBus
+	// syncing returns true while the node is syncing, in which case it isn't
+	// expected to commit blocks
+	syncing func() bool
+
+	cpuProfileDuration time.Duration
+	checkInterval      time.Duration
+
+	mtx sync.Mutex
+	// the current round and when it started
+	height        int64
+	round         int32
+	roundStart    time.Time
+	roundCaptured bool
+	// the last committed block and when it was committed
+	lastHeight     int64
+	lastCommit     time.Time
+	commitCaptured bool
+	capturing      bool
+}
+
+func newProfilingWatchdog(
+	config *cfg.InstrumentationConfig,
+	eventBus *types.EventBus,
+	syncing func() bool,
+	logger log.Logger,
+) *profilingWatchdog {
+	w := &profilingWatchdog{
+		config:             config,
+		eventBus:           eventBus,
+		syncing:            syncing,
+		cpuProfileDuration: watchdogCPUProfileDuration,
+		checkInterval:      watchdogCheckInterval,
+	}
+	for _, timeout := range []time.Duration{config.WatchdogRoundTimeout, config.WatchdogCommitTimeout} {
+		if timeout > 0 && timeout/4 < w.checkInterval {
+			w.checkInterval = timeout / 4
+		}
+	}
+	w.BaseService = *service.NewBaseService(logger, "ProfilingWatchdog", w)
+	return w
+}
+
+// OnStart implements service.Service.
+func (w *profilingWatchdog) OnStart() error {
+	if err := os.MkdirAll(w.config.ProfilesDir(), 0o700); err != nil {
+		return fmt.Errorf("failed to create the profiles directory: %w", err)
+	}
+	roundSub, err := w.eventBus.Subscribe(context.Background(), watchdogSubscriber, types.EventQueryNewRound)
+	if err != nil {
+		return err
+	}
+	blockSub, err := w.eventBus.Subscribe(context.Background(), watchdogSubscriber, types.EventQueryNewBlock)
+	if err != nil {
+		return err
+	}
+
+	w.mtx.Lock()
+	w.lastCommit = time.Now()
+	w.mtx.Unlock()
+	go w.run(roundSub, blockSub)
+	return nil
+}
+
+// OnStop implements service.Service. The CPU profile in progress, if any, is
+// stopped.
+func (w *profilingWatchdog) OnStop() {
+	if err := w.eventBus.UnsubscribeAll(context.Background(), watchdogSubscriber); err != nil {
+		w.Logger.Error("Error unsubscribing from the event bus", "err", err)
+	}
+}
+
+func (w *profilingWatchdog) run(roundSub, blockSub types.Subscription) {
+	ticker := time.NewTicker(w.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-roundSub.Out():
+			if data, ok := msg.Data().(types.EventDataNewRound); ok {
+				w.mtx.Lock()
+				w.height, w.round = data.Height, data.Round
+				w.roundStart = time.Now()
+				w.roundCaptured = false
+				w.mtx.Unlock()
+			}
+		case msg := <-blockSub.Out():
+			w.mtx.Lock()
+			if data, ok := msg.Data().(types.EventDataNewBlock); ok {
+				w.lastHeight = data.Block.Height
+			}
+			w.lastCommit = time.Now()
+			w.commitCaptured = false
+			w.mtx.Unlock()
+		case <-ticker.C:
+			w.check(time.Now())
+		case <-roundSub.Cancelled():
+			return
+		case <-blockSub.Cancelled():
+			return
+		case <-w.Quit():
+			return
+		}
+	}
+}
+
+// check starts a capture if the current round lasts too long or no block has
+// been committed for too long.
+func (w *profilingWatchdog) check(now time.Time) {
+	syncing := w.syncing != nil && w.syncing()
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.capturing {
+		return
+	}
+	var reason string
+	switch {
+	case w.config.WatchdogRoundTimeout > 0 && !w.roundStart.IsZero() && !w.roundCaptured &&
+		now.Sub(w.roundStart) > w.config.WatchdogRoundTimeout:
+		w.roundCaptured = true
+		reason = fmt.Sprintf("round-%d-%d", w.height, w.round)
+		w.Logger.Error("Consensus round exceeded watchdog_round_timeout, capturing the profiles",
+			"height", w.height, "round", w.round, "duration", now.Sub(w.roundStart))
+	case w.config.WatchdogCommitTimeout > 0 && !syncing && !w.commitCaptured &&
+		now.Sub(w.lastCommit) > w.config.WatchdogCommitTimeout:
+		w.commitCaptured = true
+		reason = fmt.Sprintf("commit-stall-%d", w.lastHeight)
+		w.Logger.Error("No block committed for watchdog_commit_timeout, capturing the profiles",
+			"last_height", w.lastHeight, "since", now.Sub(w.lastCommit))
+	default:
+		// the stall timer restarts once the node stops syncing
+		if syncing {
+			w.lastCommit = now
+		}
+		return
+	}
+
+	w.capturing = true
+	go func() {
+		dir, err := w.capture(now, reason)
+		if err != nil {
+			w.Logger.Error("Failed to capture the profiles", "dir", dir, "err", err)
+		} else {
+			w.Logger.Info("Captured the profiles", "dir", dir)
+		}
+		w.prune()
+		w.mtx.Lock()
+		w.capturing = false
+		w.mtx.Unlock()
+	}()
+}
+
+// capture writes the CPU, heap and goroutine profiles to a new directory named
+// after the time and the reason of the capture, which it returns.
+func (w *profilingWatchdog) capture(now time.Time, reason string) (string, error) {
+	dir := filepath.Join(w.config.ProfilesDir(), now.UTC().Format("20060102T150405.000Z")+"-"+reason)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return dir, err
+	}
+
+	// the goroutines and the heap are captured first, as the CPU profile takes
+	// a while
+	for _, p := range []struct {
+		file, profile string
+		debug         int
+	}{
+		{"goroutine.txt", "goroutine", 2},
+		{"goroutine.pprof", "goroutine", 0},
+		{"heap.pprof", "heap", 0},
+	} {
+		if err := writeProfile(filepath.Join(dir, p.file), func(f *os.File) error {
+			return pprof.Lookup(p.profile).WriteTo(f, p.debug)
+		}); err != nil {
+			return dir, err
+		}
+	}
+
+	return dir, writeProfile(filepath.Join(dir, "cpu.pprof"), func(f *os.File) error {
+		// fails if the CPU is already being profiled, e.g. through pprof_laddr
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(w.cpuProfileDuration):
+		case <-w.Quit():
+		}
+		pprof.StopCPUProfile()
+		return nil
+	})
+}
+
+func writeProfile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return f.Close()
+}
+
+// prune removes the oldest captures beyond watchdog_max_captures.
+func (w *profilingWatchdog) prune() {
+	entries, err := os.ReadDir(w.config.ProfilesDir())
+	if err != nil {
+		w.Logger.Error("Failed to list the captured profiles", "err", err)
+		return
+	}
+	var captures []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			captures = append(captures, entry.Name())
+		}
+	}
+	// the captures are named after their time, so they sort chronologically
+	sort.Strings(captures)
+	for len(captures) > w.config.WatchdogMaxCaptures {
+		if err := os.RemoveAll(filepath.Join(w.config.ProfilesDir(), captures[0])); err != nil {
+			w.Logger.Error("Failed to remove the captured profiles", "dir", captures[0], "err", err)
+		}
+		captures = captures[1:]
+	}
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/types"
+)
+
+func newTestWatchdog(t *testing.T, config *cfg.InstrumentationConfig, syncing func() bool) *profilingWatchdog {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	t.Cleanup(func() { _ = eventBus.Stop() })
+
+	config.RootDir = t.TempDir()
+	w := newProfilingWatchdog(config, eventBus, syncing, log.TestingLogger())
+	w.cpuProfileDuration = 10 * time.Millisecond
+	require.NoError(t, w.Start())
+	t.Cleanup(func() { _ = w.Stop() })
+	return w
+}
+
+// waitCaptures waits for n captures to be complete and returns their names.
+func waitCaptures(t *testing.T, w *profilingWatchdog, n int) []string {
+	var names []string
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(w.config.ProfilesDir())
+		require.NoError(t, err)
+		names = names[:0]
+		for _, entry := range entries {
+			if _, err := os.Stat(filepath.Join(w.config.ProfilesDir(), entry.Name(), "cpu.pprof")); err != nil {
+				return false
+			}
+			names = append(names, entry.Name())
+		}
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		return len(names) == n && !w.capturing
+	}, 5*time.Second, 10*time.Millisecond)
+	return names
+}
+
+func TestProfilingWatchdogRound(t *testing.T) {
+	config := cfg.TestInstrumentationConfig()
+	config.WatchdogRoundTimeout = 50 * time.Millisecond
+	w := newTestWatchdog(t, config, nil)
+
+	require.NoError(t, w.eventBus.PublishEventNewRound(types.EventDataNewRound{Height: 3, Round: 1}))
+	names := waitCaptures(t, w, 1)
+	assert.True(t, strings.HasSuffix(names[0], "-round-3-1"), names[0])
+	for _, file := range []string{"cpu.pprof", "heap.pprof", "goroutine.pprof", "goroutine.txt"} {
+		info, err := os.Stat(filepath.Join(config.ProfilesDir(), names[0], file))
+		require.NoError(t, err, file)
+		assert.NotZero(t, info.Size(), file)
+	}
+
+	// the round is captured only once
+	time.Sleep(100 * time.Millisecond)
+	waitCaptures(t, w, 1)
+}
+
+func TestProfilingWatchdogCommitStall(t *testing.T) {
+	config := cfg.TestInstrumentationConfig()
+	config.WatchdogCommitTimeout = 50 * time.Millisecond
+	var syncing atomic.Bool
+	syncing.Store(true)
+	w := newTestWatchdog(t, config, syncing.Load)
+
+	// no capture while syncing
+	time.Sleep(150 * time.Millisecond)
+	waitCaptures(t, w, 0)
+
+	syncing.Store(false)
+	names := waitCaptures(t, w, 1)
+	assert.True(t, strings.HasSuffix(names[0], "-commit-stall-0"), names[0])
+}
+
+func TestProfilingWatchdogPrune(t *testing.T) {
+	config := cfg.TestInstrumentationConfig()
+	config.WatchdogRoundTimeout = time.Hour
+	config.WatchdogMaxCaptures = 2
+	w := newTestWatchdog(t, config, nil)
+
+	for _, name := range []string{"20230101T000000.000Z-round-1-0", "20230102T000000.000Z-round-2-0", "20230103T000000.000Z-round-3-0"} {
+		require.NoError(t, os.Mkdir(filepath.Join(config.ProfilesDir(), name), 0o700))
+	}
+	w.prune()
+	entries, err := os.ReadDir(config.ProfilesDir())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "20230102T000000.000Z-round-2-0", entries[0].Name())
+	assert.Equal(t, "20230103T000000.000Z-round-3-0", entries[1].Name())
+}
@@ -1,7 +1,16 @@
 package p2p
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/libs/tracing"
 	"github.com/Finschia/ostracon/p2p/conn"
 )
 
@@ -52,13 +61,34 @@ type Reactor interface {
 	// Receive will be deprecated in favor of ReceiveEnvelope in v0.37.
 	Receive(chID byte, peer Peer, msgBytes []byte)
 
-	// receive async version
-	GetRecvChan() chan *BufferedMsg
+	// QueueMsg queues the message received by an async reactor on the queue
+	// of its channel, to be received by RecvRoutine. It blocks while the queue
+	// is full, unless the channel drops the messages then (see
+	// ChannelDescriptor.RecvQueueDrop), returning false if the message is
+	// dropped.
+	QueueMsg(msg *BufferedMsg) bool
+
+	// RecvQueueSize returns the number of messages queued on the channel of
+	// an async reactor.
+	RecvQueueSize(chID byte) int
 
 	// receive routine per reactor
 	RecvRoutine()
 }
 
+// MessageValidator is implemented by the reactors validating the messages of
+// their channels before they are dispatched to them, and queued for them in
+// async mode. The messages failing the validation are dropped and their peer
+// reported with an InvalidMessage behavior, see Switch.ReportPeerBehavior.
+//
+// The validation runs on the receive routine of the peer, so it must be cheap,
+// e.g. checking the sizes and the number of the items of the message.
+type MessageValidator interface {
+	// ValidateMessage validates the message received on the channel, already
+	// unmarshaled and unwrapped as for ReceiveEnvelope.
+	ValidateMessage(chID byte, msg proto.Message) error
+}
+
 type EnvelopeReceiver interface {
 	// ReceiveEnvelope is called by the switch when an envelope is received from any connected
 	// peer on any of the channels registered by the reactor.
@@ -71,11 +101,19 @@ type EnvelopeReceiver interface {
 
 //--------------------------------------
 
+// how often DrainQueue checks whether the queue is empty
+const drainQueuePollInterval = 10 * time.Millisecond
+
+// the metrics of the reactors not added to a switch
+var nopMetrics = NopMetrics()
+
 type BaseReactor struct {
 	service.BaseService // Provides Start, Stop, .Quit
 	Switch              *Switch
-	recvMsgBuf          chan *BufferedMsg
+	recvQueues          *recvQueues // nil if not async
 	impl                Reactor
+	// 1 while RecvRoutine is processing a message taken off recvQueues
+	receiving int32
 }
 
 func NewBaseReactor(name string, impl Reactor, async bool, recvBufSize int) *BaseReactor {
@@ -85,7 +123,7 @@ func NewBaseReactor(name string, impl Reactor, async bool, recvBufSize int) *Bas
 		impl:        impl,
 	}
 	if async {
-		baseReactor.recvMsgBuf = make(chan *BufferedMsg, recvBufSize)
+		baseReactor.recvQueues = newRecvQueues(impl, recvBufSize)
 	}
 	return baseReactor
 }
@@ -101,35 +139,218 @@ func (*BaseReactor) Receive(chID byte, peer Peer, msgBytes []byte) {}
 func (*BaseReactor) InitPeer(peer Peer) Peer                       { return peer }
 
 func (br *BaseReactor) OnStart() error {
-	if br.recvMsgBuf != nil {
+	if br.recvQueues != nil {
 		// if it is async mode it starts RecvRoutine()
 		go br.RecvRoutine()
 	}
 	return nil
 }
 
+// RecvRoutine receives the messages queued by QueueMsg, taking them off the
+// queues of the channels in proportion to their priorities
+// (ChannelDescriptor.Priority), so that a flood of messages on a channel
+// doesn't delay the messages of the others, e.g. the consensus votes.
 func (br *BaseReactor) RecvRoutine() {
 	for {
 		select {
-		case msg := <-br.recvMsgBuf:
-			if nr, ok := br.impl.(EnvelopeReceiver); ok {
-				nr.ReceiveEnvelope(Envelope{
-					ChannelID: msg.ChID,
-					Src:       msg.Peer,
-					Message:   msg.ProtoMsg,
-				})
-			} else {
-				br.impl.Receive(msg.ChID, msg.Peer, msg.Msg)
+		case <-br.Quit():
+			return
+		default:
+		}
+		msg := br.recvQueues.next()
+		if msg == nil {
+			select {
+			case <-br.recvQueues.queued:
+				continue
+			case <-br.Quit():
+				return
 			}
+		}
+
+		atomic.StoreInt32(&br.receiving, 1)
+		chLabel := fmt.Sprintf("%#x", msg.ChID)
+		metrics := br.metrics()
+		metrics.RecvQueueSize.With("reactor", br.String(), "chID", chLabel).
+			Set(float64(len(br.recvQueues.queue(msg.ChID).msgs)))
+		start := time.Now()
+		if msg.ctx != nil {
+			// the wait of the message in its queue
+			_, span := tracing.StartMessageSpan(msg.ctx, "p2p.recv_queue", trace.WithTimestamp(msg.queuedAt))
+			span.End()
+		}
+		if nr, ok := br.impl.(EnvelopeReceiver); ok {
+			nr.ReceiveEnvelope(Envelope{
+				ChannelID: msg.ChID,
+				Src:       msg.Peer,
+				Message:   msg.ProtoMsg,
+				ctx:       msg.ctx,
+			})
+		} else {
+			br.impl.Receive(msg.ChID, msg.Peer, msg.Msg)
+		}
+		if msg.release != nil {
+			msg.release()
+		}
+		metrics.MessageHandleDuration.With("reactor", br.String(), "chID", chLabel).
+			Observe(time.Since(start).Seconds())
+		atomic.StoreInt32(&br.receiving, 0)
+	}
+}
+
+// DrainQueue blocks until all the messages received asynchronously have been
+// processed, or the reactor is stopped. It returns immediately for a
+// synchronous reactor.
+func (br *BaseReactor) DrainQueue() {
+	for br.recvQueues.len() > 0 || atomic.LoadInt32(&br.receiving) == 1 {
+		select {
+		case <-time.After(drainQueuePollInterval):
 		case <-br.Quit():
 			return
 		}
 	}
 }
 
-func (br *BaseReactor) GetRecvChan() chan *BufferedMsg {
-	if br.recvMsgBuf == nil {
-		panic("It's not async reactor, but GetRecvChan() is called ")
+func (br *BaseReactor) QueueMsg(msg *BufferedMsg) bool {
+	if br.recvQueues == nil {
+		panic("It's not async reactor, but QueueMsg() is called ")
+	}
+	q := br.recvQueues.queue(msg.ChID)
+	select {
+	case q.msgs <- msg:
+	default:
+		if q.desc.RecvQueueDrop {
+			return false
+		}
+		// if the queue is full, we are blocking a message until it can be queued
+		select {
+		case q.msgs <- msg:
+		case <-br.Quit():
+			return false
+		}
+	}
+	br.metrics().RecvQueueSize.With("reactor", br.String(), "chID", fmt.Sprintf("%#x", msg.ChID)).
+		Set(float64(len(q.msgs)))
+	select {
+	case br.recvQueues.queued <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// metrics returns the metrics of the switch of the reactor.
+func (br *BaseReactor) metrics() *Metrics {
+	if br.Switch == nil {
+		return nopMetrics
+	}
+	return br.Switch.metrics
+}
+
+func (br *BaseReactor) RecvQueueSize(chID byte) int {
+	if br.recvQueues == nil {
+		panic("It's not async reactor, but RecvQueueSize() is called ")
+	}
+	return len(br.recvQueues.queue(chID).msgs)
+}
+
+//--------------------------------------
+
+// recvQueues are the queues of the messages received by an async reactor, one
+// per channel.
+//
+// The messages are taken off the queues with start-time fair queuing: the
+// message at the head of a queue starts at the finish of the previous message
+// of its channel, or at the start of the last message taken off if later (i.e.
+// if the channel was idle), and finishes 1/priority later. The message
+// starting first is taken first, and so the channels get messages taken off in
+// proportion to their priorities.
+type recvQueues struct {
+	impl   Reactor
+	size   int
+	once   sync.Once
+	queues []*recvQueue // by channel, built on first use from impl.GetChannels()
+	byID   map[byte]*recvQueue
+	queued chan struct{} // signaled when a message is queued
+
+	// only used by next, i.e. by RecvRoutine
+	lastStart float64 // start of the last message taken off
+}
+
+type recvQueue struct {
+	desc   *conn.ChannelDescriptor
+	msgs   chan *BufferedMsg
+	finish float64 // finish of the last message taken off
+}
+
+func newRecvQueues(impl Reactor, size int) *recvQueues {
+	// the messages are taken off the queues holding some, which an unbuffered
+	// one never does
+	if size < 1 {
+		size = 1
+	}
+	return &recvQueues{
+		impl:   impl,
+		size:   size,
+		queued: make(chan struct{}, 1),
+	}
+}
+
+// init builds the queues of the channels, which the reactors can only return
+// once constructed.
+func (rq *recvQueues) init() {
+	rq.once.Do(func() {
+		rq.byID = make(map[byte]*recvQueue)
+		for _, desc := range rq.impl.GetChannels() {
+			q := &recvQueue{desc: desc, msgs: make(chan *BufferedMsg, rq.size)}
+			rq.queues = append(rq.queues, q)
+			rq.byID[desc.ID] = q
+		}
+	})
+}
+
+func (rq *recvQueues) queue(chID byte) *recvQueue {
+	rq.init()
+	q, ok := rq.byID[chID]
+	if !ok {
+		panic(fmt.Sprintf("Unknown channel %X", chID))
+	}
+	return q
+}
+
+// next takes the next message off the queues, or returns nil if they are
+// empty.
+func (rq *recvQueues) next() *BufferedMsg {
+	rq.init()
+	var least *recvQueue
+	var leastStart float64
+	for _, q := range rq.queues {
+		if len(q.msgs) == 0 {
+			continue
+		}
+		start := q.finish
+		if start < rq.lastStart {
+			start = rq.lastStart
+		}
+		if least == nil || start < leastStart {
+			least, leastStart = q, start
+		}
+	}
+	if least == nil {
+		return nil
+	}
+	rq.lastStart = leastStart
+	least.finish = leastStart + 1/float64(least.desc.Priority)
+	return <-least.msgs
+}
+
+// len returns the number of messages queued, 0 if rq is nil.
+func (rq *recvQueues) len() int {
+	if rq == nil {
+		return 0
+	}
+	rq.init()
+	n := 0
+	for _, q := range rq.queues {
+		n += len(q.msgs)
 	}
-	return br.recvMsgBuf
+	return n
 }
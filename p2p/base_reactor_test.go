@@ -0,0 +1,180 @@
+package p2p
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/p2p/conn"
+)
+
+type queueingReactor struct {
+	BaseReactor
+	channels   []*conn.ChannelDescriptor
+	receivedCh chan byte
+}
+
+func newQueueingReactor(channels []*conn.ChannelDescriptor, recvBufSize int) *queueingReactor {
+	r := &queueingReactor{
+		channels:   channels,
+		receivedCh: make(chan byte, 100),
+	}
+	r.BaseReactor = *NewBaseReactor("QueueingReactor", r, true, recvBufSize)
+	r.SetLogger(log.TestingLogger())
+	return r
+}
+
+func (r *queueingReactor) GetChannels() []*conn.ChannelDescriptor {
+	return r.channels
+}
+
+func (r *queueingReactor) ReceiveEnvelope(e Envelope) {
+	r.receivedCh <- e.ChannelID
+}
+
+func TestBaseReactorRecvQueuesPriority(t *testing.T) {
+	r := newQueueingReactor([]*conn.ChannelDescriptor{
+		{ID: 0x01, Priority: 1},
+		{ID: 0x02, Priority: 4},
+	}, 10)
+
+	// the flood of the low priority channel is queued first
+	for i := 0; i < 10; i++ {
+		require.True(t, r.QueueMsg(&BufferedMsg{ChID: 0x01}))
+	}
+	for i := 0; i < 10; i++ {
+		require.True(t, r.QueueMsg(&BufferedMsg{ChID: 0x02}))
+	}
+	assert.Equal(t, 10, r.RecvQueueSize(0x01))
+	assert.Equal(t, 10, r.RecvQueueSize(0x02))
+
+	require.NoError(t, r.Start())
+	defer r.Stop() //nolint:errcheck // ignore for tests
+
+	var received []byte
+	for i := 0; i < 20; i++ {
+		select {
+		case chID := <-r.receivedCh:
+			received = append(received, chID)
+		case <-time.After(time.Second):
+			t.Fatal("Did not receive the messages in 1s")
+		}
+	}
+	// 4 messages of the high priority channel for one of the low priority one
+	expected := []byte{0x01, 0x02, 0x02, 0x02, 0x02, 0x01, 0x02, 0x02, 0x02, 0x02, 0x01}
+	assert.Equal(t, expected, received[:len(expected)])
+	r.DrainQueue()
+	assert.Zero(t, r.RecvQueueSize(0x01))
+}
+
+func TestBaseReactorRecvQueuesFull(t *testing.T) {
+	r := newQueueingReactor([]*conn.ChannelDescriptor{
+		{ID: 0x01, Priority: 1},
+		{ID: 0x02, Priority: 1, RecvQueueDrop: true},
+	}, 1)
+
+	// the messages of a dropping channel are dropped when its queue is full
+	assert.True(t, r.QueueMsg(&BufferedMsg{ChID: 0x02}))
+	assert.False(t, r.QueueMsg(&BufferedMsg{ChID: 0x02}))
+
+	// the messages of the other channels wait for a room in the queue
+	assert.True(t, r.QueueMsg(&BufferedMsg{ChID: 0x01}))
+	queued := make(chan bool)
+	go func() {
+		queued <- r.QueueMsg(&BufferedMsg{ChID: 0x01})
+	}()
+	select {
+	case <-queued:
+		t.Fatal("the message was queued in a full queue")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, r.Start())
+	defer r.Stop() //nolint:errcheck // ignore for tests
+	select {
+	case ok := <-queued:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("the message wasn't queued in 1s")
+	}
+	for i := 0; i < 3; i++ {
+		<-r.receivedCh
+	}
+}
+
+// labeledValues records the last value set, or observed, by label values.
+type labeledValues struct {
+	mtx    sync.Mutex
+	values map[string]float64
+}
+
+type testGauge struct {
+	*labeledValues
+	lvs []string
+}
+
+func newTestGauge() *testGauge {
+	return &testGauge{labeledValues: &labeledValues{values: make(map[string]float64)}}
+}
+
+func (g *testGauge) With(labelValues ...string) metrics.Gauge {
+	return &testGauge{labeledValues: g.labeledValues, lvs: append(append([]string{}, g.lvs...), labelValues...)}
+}
+
+func (g *testGauge) Set(value float64) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	g.values[strings.Join(g.lvs, ",")] = value
+}
+
+func (g *testGauge) Add(delta float64) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	g.values[strings.Join(g.lvs, ",")] += delta
+}
+
+func (g *testGauge) Observe(value float64) { g.Set(value) }
+
+func (g *testGauge) value(labelValues ...string) (float64, bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	v, ok := g.values[strings.Join(labelValues, ",")]
+	return v, ok
+}
+
+type testHistogram struct{ *testGauge }
+
+func (h testHistogram) With(labelValues ...string) metrics.Histogram {
+	return testHistogram{h.testGauge.With(labelValues...).(*testGauge)}
+}
+
+func TestBaseReactorRecvQueuesMetrics(t *testing.T) {
+	r := newQueueingReactor([]*conn.ChannelDescriptor{{ID: 0x01, Priority: 1}}, 10)
+	queueSize, handleDuration := newTestGauge(), testHistogram{newTestGauge()}
+	m := NopMetrics()
+	m.RecvQueueSize, m.MessageHandleDuration = queueSize, handleDuration
+	r.SetSwitch(&Switch{metrics: m})
+
+	for i := 0; i < 3; i++ {
+		require.True(t, r.QueueMsg(&BufferedMsg{ChID: 0x01}))
+	}
+	size, _ := queueSize.value("reactor", "QueueingReactor", "chID", "0x1")
+	assert.EqualValues(t, 3, size)
+
+	require.NoError(t, r.Start())
+	defer r.Stop() //nolint:errcheck // ignore for tests
+	for i := 0; i < 3; i++ {
+		<-r.receivedCh
+	}
+	r.DrainQueue()
+	size, _ = queueSize.value("reactor", "QueueingReactor", "chID", "0x1")
+	assert.Zero(t, size)
+	_, observed := handleDuration.value("reactor", "QueueingReactor", "chID", "0x1")
+	assert.True(t, observed)
+}
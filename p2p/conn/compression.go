@@ -0,0 +1,121 @@
+package conn
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The compression algorithms of the messages of the channels with
+// ChannelDescriptor.Compress.
+const (
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+// SupportedCompressions are the algorithms the messages can be decompressed
+// with, advertised in the handshake.
+var SupportedCompressions = []string{CompressionSnappy, CompressionZstd}
+
+// Once MConnConfig.CompressionHeaders is set, each message is prefixed with a
+// byte telling how its payload is compressed.
+const (
+	compressionHeaderNone   byte = 0
+	compressionHeaderSnappy byte = 1
+	compressionHeaderZstd   byte = 2
+
+	// the messages smaller than that aren't worth compressing
+	minCompressedMsgSize = 256
+)
+
+// the zstd encoder and decoder of the messages, whose EncodeAll and DecodeAll
+// are goroutine-safe
+var (
+	msgZstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+	msgZstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecodeAllCapLimit(true))
+)
+
+// IsSupportedCompression returns true if the messages can be compressed with
+// the algorithm.
+func IsSupportedCompression(algorithm string) bool {
+	for _, a := range SupportedCompressions {
+		if a == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// compressMsg returns the message prefixed with its compression header,
+// compressed with the algorithm ("" for none) if it's smaller so.
+func compressMsg(algorithm string, msg []byte) []byte {
+	if len(msg) >= minCompressedMsgSize {
+		var compressed []byte
+		switch algorithm {
+		case CompressionSnappy:
+			dst := make([]byte, 1+s2.MaxEncodedLen(len(msg)))
+			dst[0] = compressionHeaderSnappy
+			compressed = dst[:1+len(s2.EncodeSnappy(dst[1:], msg))]
+		case CompressionZstd:
+			compressed = msgZstdEncoder.EncodeAll(msg, []byte{compressionHeaderZstd})
+		}
+		if compressed != nil && len(compressed) < 1+len(msg) {
+			return compressed
+		}
+	}
+	framed := make([]byte, 1+len(msg))
+	framed[0] = compressionHeaderNone
+	copy(framed[1:], msg)
+	return framed
+}
+
+// decompressMsg returns the payload of the message prefixed with its
+// compression header, failing if it's bigger than maxSize once decompressed.
+func decompressMsg(msg []byte, maxSize int) ([]byte, error) {
+	if len(msg) == 0 {
+		return nil, errors.New("message without compression header")
+	}
+	payload := msg[1:]
+	switch msg[0] {
+	case compressionHeaderNone:
+		if len(payload) > maxSize {
+			return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", maxSize, len(payload))
+		}
+		return payload, nil
+	case compressionHeaderSnappy:
+		size, err := s2.DecodedLen(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snappy message: %w", err)
+		}
+		if size > maxSize {
+			return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", maxSize, size)
+		}
+		decompressed, err := s2.Decode(make([]byte, size), payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snappy message: %w", err)
+		}
+		return decompressed, nil
+	case compressionHeaderZstd:
+		// the size of the frame is checked before allocating it, and the
+		// decoder can't write more than it
+		var header zstd.Header
+		if err := header.Decode(payload); err != nil {
+			return nil, fmt.Errorf("invalid zstd message: %w", err)
+		}
+		if !header.HasFCS {
+			return nil, errors.New("invalid zstd message: no frame content size")
+		}
+		if header.FrameContentSize > uint64(maxSize) {
+			return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", maxSize, header.FrameContentSize)
+		}
+		decompressed, err := msgZstdDecoder.DecodeAll(payload, make([]byte, 0, header.FrameContentSize))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd message: %w", err)
+		}
+		return decompressed, nil
+	default:
+		return nil, fmt.Errorf("unknown compression header %d", msg[0])
+	}
+}
@@ -0,0 +1,55 @@
+package conn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+)
+
+func TestCompressMsg(t *testing.T) {
+	compressible := bytes.Repeat([]byte("block part "), 1000)
+	for _, algorithm := range []string{"", CompressionSnappy, CompressionZstd} {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			for _, msg := range [][]byte{{}, []byte("vote"), compressible} {
+				compressed := compressMsg(algorithm, msg)
+				if algorithm != "" && len(msg) >= minCompressedMsgSize {
+					assert.Less(t, len(compressed), len(msg))
+				} else {
+					assert.Equal(t, append([]byte{compressionHeaderNone}, msg...), compressed)
+				}
+				decompressed, err := decompressMsg(compressed, len(msg))
+				require.NoError(t, err)
+				assert.Equal(t, msg, decompressed)
+			}
+
+			// the message is checked against the capacity once decompressed
+			_, err := decompressMsg(compressMsg(algorithm, compressible), len(compressible)-1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompressMsgIncompressible(t *testing.T) {
+	// the random bytes grow once compressed, so are sent as is
+	msg := tmrand.Bytes(1000)
+	for _, algorithm := range SupportedCompressions {
+		assert.Equal(t, append([]byte{compressionHeaderNone}, msg...), compressMsg(algorithm, msg))
+	}
+}
+
+func TestDecompressMsgInvalid(t *testing.T) {
+	for _, msg := range [][]byte{
+		{},
+		{42, 1, 2, 3},
+		{compressionHeaderSnappy, 0xff, 0xff, 0xff},
+		{compressionHeaderZstd, 1, 2, 3, 4},
+	} {
+		_, err := decompressMsg(msg, 1000)
+		assert.Error(t, err, "%X", msg)
+	}
+}
@@ -2,6 +2,7 @@ package conn
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
 
@@ -20,9 +23,11 @@ import (
 	"github.com/Finschia/ostracon/libs/log"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	"github.com/Finschia/ostracon/libs/protoio"
+	"github.com/Finschia/ostracon/libs/ratelimit"
 	"github.com/Finschia/ostracon/libs/service"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	"github.com/Finschia/ostracon/libs/timer"
+	"github.com/Finschia/ostracon/libs/tracing"
 )
 
 const (
@@ -49,6 +54,9 @@ const (
 	defaultRecvAsync           = true
 )
 
+// ErrConnStopped is returned by SendContext when the connection is stopped.
+var ErrConnStopped = errors.New("connection is stopped")
+
 type receiveCbFunc func(chID byte, msgBytes []byte)
 type errorCbFunc func(interface{})
 
@@ -85,6 +93,7 @@ type MConnection struct {
 	bufConnWriter *bufio.Writer
 	sendMonitor   *flow.Monitor
 	recvMonitor   *flow.Monitor
+	recvLimiter   ratelimit.Limiter // rate of the messages received (nil if unlimited)
 	send          chan struct{}
 	pong          chan struct{}
 	channels      []*Channel
@@ -92,6 +101,7 @@ type MConnection struct {
 	onReceive     receiveCbFunc
 	onError       errorCbFunc
 	errored       uint32
+	sendWakeup    uint32 // 1 if the sendRoutine is to be woken up, see wakeSendRoutineAfter
 	config        MConnConfig
 
 	// Closing quitSendRoutine will cause the sendRoutine to eventually quit.
@@ -125,6 +135,12 @@ type MConnConfig struct {
 	SendRate int64 `mapstructure:"send_rate"`
 	RecvRate int64 `mapstructure:"recv_rate"`
 
+	// Rate at which messages can be received, in messages/second, and the
+	// number of messages which can be received at once above it (see
+	// ratelimit.TokenBucket). The rate is unlimited when <= 0.
+	RecvMessageRate  float64 `mapstructure:"recv_message_rate"`
+	RecvMessageBurst int     `mapstructure:"recv_message_burst"`
+
 	// Maximum payload size
 	MaxPacketMsgPayloadSize int `mapstructure:"max_packet_msg_payload_size"`
 
@@ -139,6 +155,15 @@ type MConnConfig struct {
 
 	// Action method of reactor's receive function
 	RecvAsync bool `mapstructure:"recv_async"`
+
+	// Whether the messages are prefixed with a compression header, which both
+	// ends of the connection must agree on in the handshake.
+	CompressionHeaders bool `mapstructure:"-"`
+
+	// Algorithm the messages of the channels with ChannelDescriptor.Compress
+	// are compressed with ("" for none, see SupportedCompressions), once
+	// CompressionHeaders is set.
+	Compression string `mapstructure:"compression"`
 }
 
 // DefaultMConnConfig returns the default config.
@@ -194,6 +219,9 @@ func NewMConnectionWithConfig(
 		config:        config,
 		created:       time.Now(),
 	}
+	if config.RecvMessageRate > 0 {
+		mconn.recvLimiter = ratelimit.NewTokenBucket(config.RecvMessageRate, config.RecvMessageBurst)
+	}
 
 	// Create channels
 	var channelsIdx = map[byte]*Channel{}
@@ -215,6 +243,13 @@ func NewMConnectionWithConfig(
 	return mconn
 }
 
+// SetRates changes the SendRate and RecvRate of the connection while it is
+// running.
+func (c *MConnection) SetRates(sendRate, recvRate int64) {
+	atomic.StoreInt64(&c.config.SendRate, sendRate)
+	atomic.StoreInt64(&c.config.RecvRate, recvRate)
+}
+
 func (c *MConnection) SetLogger(l log.Logger) {
 	c.BaseService.SetLogger(l)
 	for _, ch := range c.channels {
@@ -409,6 +444,38 @@ func (c *MConnection) TrySend(chID byte, msgBytes []byte) bool {
 	return ok
 }
 
+// SendContext queues a message to be sent to the channel, waiting for room in
+// its send queue until the context is done. Unlike Send, it doesn't give up
+// after defaultSendTimeout, and returns the reason the message isn't queued.
+func (c *MConnection) SendContext(ctx context.Context, chID byte, msgBytes []byte) error {
+	if !c.IsRunning() {
+		return ErrConnStopped
+	}
+
+	channel, ok := c.channelsIdx[chID]
+	if !ok {
+		return fmt.Errorf("unknown channel %X", chID)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case channel.sendQueue <- msgBytes:
+		channel.queued(msgBytes)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.Quit():
+		return ErrConnStopped
+	}
+	// Wake up sendRoutine if necessary
+	select {
+	case c.send <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
 // CanSend returns true if you can send more data onto the chID, false
 // otherwise. Use only as a heuristic.
 func (c *MConnection) CanSend(chID byte) bool {
@@ -528,11 +595,20 @@ func (c *MConnection) sendPacketMsg() bool {
 	// The chosen channel will be the one whose recentlySent/priority is the least.
 	var leastRatio float32 = math.MaxFloat32
 	var leastChannel *Channel
+	var limitWait time.Duration
+	now := time.Now()
 	for _, channel := range c.channels {
 		// If nothing to send, skip this channel
 		if !channel.isSendPending() {
 			continue
 		}
+		// If over its rate, skip this channel until it can send again
+		if wait := channel.sendLimitWait(now); wait > 0 {
+			if limitWait == 0 || wait < limitWait {
+				limitWait = wait
+			}
+			continue
+		}
 		// Get ratio, and keep track of lowest ratio.
 		ratio := float32(channel.recentlySent) / float32(channel.desc.Priority)
 		if ratio < leastRatio {
@@ -543,6 +619,9 @@ func (c *MConnection) sendPacketMsg() bool {
 
 	// Nothing to send?
 	if leastChannel == nil {
+		if limitWait > 0 {
+			c.wakeSendRoutineAfter(limitWait)
+		}
 		return true
 	}
 	// c.Logger.Info("Found a msgPacket to send")
@@ -559,6 +638,21 @@ func (c *MConnection) sendPacketMsg() bool {
 	return false
 }
 
+// wakeSendRoutineAfter wakes the sendRoutine up after d, when the channels
+// over their rate can send again, unless it's already to be woken up.
+func (c *MConnection) wakeSendRoutineAfter(d time.Duration) {
+	if !atomic.CompareAndSwapUint32(&c.sendWakeup, 0, 1) {
+		return
+	}
+	time.AfterFunc(d, func() {
+		atomic.StoreUint32(&c.sendWakeup, 0)
+		select {
+		case c.send <- struct{}{}:
+		default:
+		}
+	})
+}
+
 // recvRoutine reads PacketMsgs and reconstructs the message using the channels' "recving" buffer.
 // After a whole message has been assembled, it's pushed to onReceive().
 // Blocks depending on how the connection is throttled.
@@ -649,6 +743,12 @@ FOR_LOOP:
 				break FOR_LOOP
 			}
 			if msgBytes != nil {
+				if c.recvLimiter != nil && !c.recvLimiter.AllowN(time.Now(), 1) {
+					err := fmt.Errorf("exceeded the rate of %v messages/s", c.config.RecvMessageRate)
+					c.Logger.Debug("Connection failed @ recvRoutine", "conn", c, "err", err)
+					c.stopForError(err)
+					break FOR_LOOP
+				}
 				c.Logger.Debug("Received bytes", "chID", channelID, "msgBytes", msgBytes)
 				// NOTE: This means the reactor.Receive runs in the same thread as the p2p recv routine
 				c.onReceive(channelID, msgBytes)
@@ -700,6 +800,7 @@ type ChannelStatus struct {
 	ID                byte
 	SendQueueCapacity int
 	SendQueueSize     int
+	SendQueueBytes    int64 // bytes of the messages queued, not written yet
 	Priority          int
 	RecentlySent      int64
 }
@@ -711,17 +812,22 @@ func (c *MConnection) Status() ConnectionStatus {
 	status.RecvMonitor = c.recvMonitor.Status()
 	status.Channels = make([]ChannelStatus, len(c.channels))
 	for i, channel := range c.channels {
-		status.Channels[i] = ChannelStatus{
-			ID:                channel.desc.ID,
-			SendQueueCapacity: cap(channel.sendQueue),
-			SendQueueSize:     int(atomic.LoadInt32(&channel.sendQueueSize)),
-			Priority:          channel.desc.Priority,
-			RecentlySent:      atomic.LoadInt64(&channel.recentlySent),
-		}
+		status.Channels[i] = channel.status()
 	}
 	return status
 }
 
+// ChannelStatus returns the status of the channel, without the monitors of
+// Status, e.g. for the reactors to pace the messages they send. It returns
+// false if there is no such channel.
+func (c *MConnection) ChannelStatus(chID byte) (ChannelStatus, bool) {
+	channel, ok := c.channelsIdx[chID]
+	if !ok {
+		return ChannelStatus{}, false
+	}
+	return channel.status(), true
+}
+
 //-----------------------------------------------------------------------------
 
 type ChannelDescriptor struct {
@@ -731,6 +837,28 @@ type ChannelDescriptor struct {
 	RecvBufferCapacity  int
 	RecvMessageCapacity int
 	MessageType         proto.Message
+
+	// Rates at which the messages of the channel are sent and received, in
+	// bytes/second, e.g. to cap the bandwidth of the txs gossip without
+	// throttling the consensus votes, unlike MConnConfig.SendRate and RecvRate
+	// which apply to all the channels. The rate is unlimited when <= 0.
+	//
+	// The messages over SendRateLimit wait in the send queue, while the other
+	// channels keep sending. A peer sending more than RecvRateLimit (with
+	// twice its burst, for the network jitter) is disconnected.
+	SendRateLimit int64
+	RecvRateLimit int64
+
+	// Whether the messages received while the queue of the channel in its
+	// reactor is full (in async mode, see MConnConfig.RecvAsync) are dropped,
+	// rather than blocking the receive routine of the peer, and so its other
+	// channels, e.g. for the txs gossip.
+	RecvQueueDrop bool
+
+	// Whether the messages of the channel are compressed with
+	// MConnConfig.Compression, for the big and compressible ones like the
+	// block parts.
+	Compress bool
 }
 
 func (chDesc ChannelDescriptor) FillDefaults() (filled ChannelDescriptor) {
@@ -750,13 +878,19 @@ func (chDesc ChannelDescriptor) FillDefaults() (filled ChannelDescriptor) {
 // TODO: lowercase.
 // NOTE: not goroutine-safe.
 type Channel struct {
-	conn          *MConnection
-	desc          ChannelDescriptor
-	sendQueue     chan []byte
-	sendQueueSize int32 // atomic.
-	recving       []byte
-	sending       []byte
-	recentlySent  int64 // exponential moving average
+	conn           *MConnection
+	desc           ChannelDescriptor
+	sendQueue      chan []byte
+	sendQueueSize  int32 // atomic.
+	sendQueueBytes int64 // atomic.
+	recving        []byte
+	sending        []byte
+	sendingSize    int                    // size of sending when dequeued, before compression
+	recentlySent   int64                  // exponential moving average
+	sendingSpan    trace.Span             // span of the writes of sending, if traced
+	recvingStart   time.Time              // time of the first packet of recving, if traced
+	sendLimiter    *ratelimit.TokenBucket // nil if unlimited
+	recvLimiter    *ratelimit.TokenBucket // nil if unlimited
 
 	maxPacketMsgPayloadSize int
 
@@ -768,13 +902,23 @@ func newChannel(conn *MConnection, desc ChannelDescriptor) *Channel {
 	if desc.Priority <= 0 {
 		panic("Channel default priority must be a positive integer")
 	}
-	return &Channel{
+	ch := &Channel{
 		conn:                    conn,
 		desc:                    desc,
 		sendQueue:               make(chan []byte, desc.SendQueueCapacity),
 		recving:                 make([]byte, 0, desc.RecvBufferCapacity),
 		maxPacketMsgPayloadSize: conn.config.MaxPacketMsgPayloadSize,
 	}
+	// the bursts hold one second of bytes, and at least a packet
+	if desc.SendRateLimit > 0 {
+		burst := tmmath.MaxInt(int(desc.SendRateLimit), ch.maxPacketMsgPayloadSize)
+		ch.sendLimiter = ratelimit.NewTokenBucket(float64(desc.SendRateLimit), burst)
+	}
+	if desc.RecvRateLimit > 0 {
+		burst := tmmath.MaxInt(int(desc.RecvRateLimit), ch.maxPacketMsgPayloadSize)
+		ch.recvLimiter = ratelimit.NewTokenBucket(float64(desc.RecvRateLimit), 2*burst)
+	}
+	return ch
 }
 
 func (ch *Channel) SetLogger(l log.Logger) {
@@ -787,7 +931,7 @@ func (ch *Channel) SetLogger(l log.Logger) {
 func (ch *Channel) sendBytes(bytes []byte) bool {
 	select {
 	case ch.sendQueue <- bytes:
-		atomic.AddInt32(&ch.sendQueueSize, 1)
+		ch.queued(bytes)
 		return true
 	case <-time.After(defaultSendTimeout):
 		return false
@@ -800,13 +944,32 @@ func (ch *Channel) sendBytes(bytes []byte) bool {
 func (ch *Channel) trySendBytes(bytes []byte) bool {
 	select {
 	case ch.sendQueue <- bytes:
-		atomic.AddInt32(&ch.sendQueueSize, 1)
+		ch.queued(bytes)
 		return true
 	default:
 		return false
 	}
 }
 
+// Counts the message queued to the sendQueue.
+// Goroutine-safe
+func (ch *Channel) queued(bytes []byte) {
+	atomic.AddInt32(&ch.sendQueueSize, 1)
+	atomic.AddInt64(&ch.sendQueueBytes, int64(len(bytes)))
+}
+
+// Goroutine-safe
+func (ch *Channel) status() ChannelStatus {
+	return ChannelStatus{
+		ID:                ch.desc.ID,
+		SendQueueCapacity: cap(ch.sendQueue),
+		SendQueueSize:     ch.loadSendQueueSize(),
+		SendQueueBytes:    atomic.LoadInt64(&ch.sendQueueBytes),
+		Priority:          ch.desc.Priority,
+		RecentlySent:      atomic.LoadInt64(&ch.recentlySent),
+	}
+}
+
 // Goroutine-safe
 func (ch *Channel) loadSendQueueSize() (size int) {
 	return int(atomic.LoadInt32(&ch.sendQueueSize))
@@ -827,10 +990,48 @@ func (ch *Channel) isSendPending() bool {
 			return false
 		}
 		ch.sending = <-ch.sendQueue
+		ch.sendingSize = len(ch.sending)
+		if tracing.MessagesTraced() {
+			_, ch.sendingSpan = tracing.StartMessageSpan(tracing.MessageContext(ch.desc.ID, ch.sending), "mconn.write",
+				ch.messageSpanAttributes(len(ch.sending)))
+		}
+		if ch.conn.config.CompressionHeaders {
+			var algorithm string
+			if ch.desc.Compress {
+				algorithm = ch.conn.config.Compression
+			}
+			ch.sending = compressMsg(algorithm, ch.sending)
+		}
 	}
 	return true
 }
 
+// messageSpanAttributes returns the attributes of the spans of the messages
+// written and read by the channel.
+func (ch *Channel) messageSpanAttributes(size int) trace.SpanStartEventOption {
+	return trace.WithAttributes(
+		attribute.String("remote_addr", ch.conn.conn.RemoteAddr().String()),
+		attribute.String("channel", fmt.Sprintf("%#x", ch.desc.ID)),
+		attribute.Int("bytes", size),
+	)
+}
+
+// Returns the time to wait until the next PacketMsg can be sent within
+// SendRateLimit, or 0 if it can be sent now.
+// Call after isSendPending() returned true.
+// Not goroutine-safe
+func (ch *Channel) sendLimitWait(now time.Time) time.Duration {
+	if ch.sendLimiter == nil {
+		return 0
+	}
+	size := float64(tmmath.MinInt(ch.maxPacketMsgPayloadSize, len(ch.sending)))
+	missing := size - ch.sendLimiter.Tokens(now)
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / float64(ch.desc.SendRateLimit) * float64(time.Second))
+}
+
 // Creates a new PacketMsg to send.
 // Not goroutine-safe
 func (ch *Channel) nextPacketMsg() tmp2p.PacketMsg {
@@ -841,6 +1042,11 @@ func (ch *Channel) nextPacketMsg() tmp2p.PacketMsg {
 		packet.EOF = true
 		ch.sending = nil
 		atomic.AddInt32(&ch.sendQueueSize, -1) // decrement sendQueueSize
+		atomic.AddInt64(&ch.sendQueueBytes, -int64(ch.sendingSize))
+		if ch.sendingSpan != nil {
+			ch.sendingSpan.End()
+			ch.sendingSpan = nil
+		}
 	} else {
 		packet.EOF = false
 		ch.sending = ch.sending[tmmath.MinInt(maxSize, len(ch.sending)):]
@@ -852,6 +1058,10 @@ func (ch *Channel) nextPacketMsg() tmp2p.PacketMsg {
 // Not goroutine-safe
 func (ch *Channel) writePacketMsgTo(w io.Writer) (n int, err error) {
 	packet := ch.nextPacketMsg()
+	if ch.sendLimiter != nil {
+		// can't be denied after sendLimitWait
+		ch.sendLimiter.AllowN(time.Now(), len(packet.Data))
+	}
 	n, err = protoio.NewDelimitedWriter(w).WriteMsg(mustWrapPacket(&packet))
 	atomic.AddInt64(&ch.recentlySent, int64(n))
 	return
@@ -862,13 +1072,35 @@ func (ch *Channel) writePacketMsgTo(w io.Writer) (n int, err error) {
 // Not goroutine-safe
 func (ch *Channel) recvPacketMsg(packet tmp2p.PacketMsg) ([]byte, error) {
 	ch.Logger.Debug("Read PacketMsg", "conn", ch.conn, "packet", packet)
+	if ch.recvLimiter != nil && !ch.recvLimiter.AllowN(time.Now(), len(packet.Data)) {
+		return nil, fmt.Errorf("exceeded the rate of %v bytes/s on channel %X", ch.desc.RecvRateLimit, ch.desc.ID)
+	}
 	var recvCap, recvReceived = ch.desc.RecvMessageCapacity, len(ch.recving) + len(packet.Data)
+	if ch.conn.config.CompressionHeaders {
+		// the compressed messages are smaller than the raw ones
+		recvCap++
+	}
 	if recvCap < recvReceived {
 		return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", recvCap, recvReceived)
 	}
+	if len(ch.recving) == 0 && tracing.MessagesTraced() {
+		ch.recvingStart = time.Now()
+	}
 	ch.recving = append(ch.recving, packet.Data...)
 	if packet.EOF {
 		msgBytes := ch.recving
+		if ch.conn.config.CompressionHeaders {
+			var err error
+			if msgBytes, err = decompressMsg(msgBytes, ch.desc.RecvMessageCapacity); err != nil {
+				return nil, fmt.Errorf("channel %X: %w", ch.desc.ID, err)
+			}
+		}
+		if !ch.recvingStart.IsZero() {
+			_, span := tracing.StartMessageSpan(tracing.MessageContext(ch.desc.ID, msgBytes), "mconn.read",
+				ch.messageSpanAttributes(len(msgBytes)), trace.WithTimestamp(ch.recvingStart))
+			span.End()
+			ch.recvingStart = time.Time{}
+		}
 
 		// clear the slice without re-allocating.
 		// http://stackoverflow.com/questions/16971741/how-do-you-clear-a-slice-in-go
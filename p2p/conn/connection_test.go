@@ -1,8 +1,13 @@
 package conn
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
+	"fmt"
+	"io"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -164,6 +169,25 @@ func TestMConnectionStatus(t *testing.T) {
 	assert.Zero(t, status.Channels[0].SendQueueSize)
 }
 
+func TestMConnectionSetRates(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	mconn := createTestMConnection(client)
+	err := mconn.Start()
+	require.Nil(t, err)
+	defer mconn.Stop() // nolint:errcheck // ignore for tests
+
+	// the rates are changed while the connection is sending
+	go func() { _, _ = io.Copy(io.Discard, server) }()
+	assert.True(t, mconn.Send(0x01, []byte("abc")))
+	mconn.SetRates(1000, 2000)
+	assert.True(t, mconn.Send(0x01, []byte("abc")))
+	assert.EqualValues(t, 1000, atomic.LoadInt64(&mconn.config.SendRate))
+	assert.EqualValues(t, 2000, atomic.LoadInt64(&mconn.config.RecvRate))
+}
+
 func TestMConnectionPongTimeoutResultsInError(t *testing.T) {
 	server, client := net.Pipe()
 	defer server.Close()
@@ -538,6 +562,148 @@ func TestMConnectionReadErrorUnknownMsgType(t *testing.T) {
 	assert.True(t, expectSend(chOnErr), "unknown msg type")
 }
 
+func TestMConnectionRecvMessageRate(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	receivedCh := make(chan []byte, 3)
+	errorsCh := make(chan interface{}, 1)
+	onReceive := func(chID byte, msgBytes []byte) {
+		// msgBytes is reused by the connection
+		receivedCh <- append([]byte(nil), msgBytes...)
+	}
+	onError := func(r interface{}) {
+		errorsCh <- r
+	}
+	cfg := DefaultMConnConfig()
+	cfg.RecvMessageRate = 0.1
+	cfg.RecvMessageBurst = 2
+	chDescs := []*ChannelDescriptor{{ID: 0x01, Priority: 1, SendQueueCapacity: 1}}
+	mconn1 := NewMConnectionWithConfig(client, chDescs, onReceive, onError, cfg)
+	mconn1.SetLogger(log.TestingLogger())
+	err := mconn1.Start()
+	require.Nil(t, err)
+	defer mconn1.Stop() // nolint:errcheck // ignore for tests
+
+	mconn2 := createTestMConnection(server)
+	err = mconn2.Start()
+	require.Nil(t, err)
+	defer mconn2.Stop() // nolint:errcheck // ignore for tests
+
+	// the burst is received, the next message stops the connection
+	for i := 0; i < 3; i++ {
+		assert.True(t, mconn2.Send(0x01, []byte{byte(i)}))
+	}
+	select {
+	case err := <-errorsCh:
+		assert.Contains(t, fmt.Sprint(err), "exceeded the rate of 0.1 messages/s")
+		assert.False(t, mconn1.IsRunning())
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Did not receive error in 500ms")
+	}
+	require.Len(t, receivedCh, 2)
+	assert.Equal(t, []byte{0}, <-receivedCh)
+	assert.Equal(t, []byte{1}, <-receivedCh)
+}
+
+func TestMConnectionChannelSendRateLimit(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	type received struct {
+		chID byte
+		at   time.Time
+	}
+	receivedCh := make(chan received, 20)
+	onReceive := func(chID byte, msgBytes []byte) {
+		receivedCh <- received{chID, time.Now()}
+	}
+	cfg := DefaultMConnConfig()
+	cfg.MaxPacketMsgPayloadSize = 100
+	chDescs := []*ChannelDescriptor{
+		{ID: 0x01, Priority: 1, SendQueueCapacity: 20, SendRateLimit: 1000},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 20},
+	}
+	mconn1 := NewMConnectionWithConfig(client, chDescs, onReceive, func(r interface{}) {}, cfg)
+	mconn1.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn1.Start())
+	defer mconn1.Stop() // nolint:errcheck // ignore for tests
+
+	mconn2 := NewMConnectionWithConfig(server, chDescs, onReceive, func(r interface{}) {}, cfg)
+	mconn2.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn2.Start())
+	defer mconn2.Stop() // nolint:errcheck // ignore for tests
+
+	// the burst of the limited channel is one second of bytes, the next
+	// message waits for the bucket to be refilled, without throttling the
+	// other channel
+	msg := make([]byte, 100)
+	start := time.Now()
+	for i := 0; i < 12; i++ {
+		require.True(t, mconn1.Send(0x01, msg))
+	}
+	require.True(t, mconn1.Send(0x02, msg))
+
+	var last received
+	var otherAt time.Time
+	for i := 0; i < 13; i++ {
+		select {
+		case r := <-receivedCh:
+			if r.chID == 0x02 {
+				otherAt = r.at
+			} else {
+				last = r
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Did not receive the messages in 5s")
+		}
+	}
+	assert.GreaterOrEqual(t, last.at.Sub(start), 150*time.Millisecond)
+	assert.True(t, otherAt.Before(last.at), "the other channel was throttled")
+}
+
+func TestMConnectionChannelRecvRateLimit(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	receivedCh := make(chan []byte, 3)
+	errorsCh := make(chan interface{}, 1)
+	onReceive := func(chID byte, msgBytes []byte) {
+		receivedCh <- append([]byte(nil), msgBytes...)
+	}
+	onError := func(r interface{}) {
+		errorsCh <- r
+	}
+	cfg := DefaultMConnConfig()
+	cfg.MaxPacketMsgPayloadSize = 100
+	chDescs := []*ChannelDescriptor{{ID: 0x01, Priority: 1, SendQueueCapacity: 3, RecvRateLimit: 1}}
+	mconn1 := NewMConnectionWithConfig(client, chDescs, onReceive, onError, cfg)
+	mconn1.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn1.Start())
+	defer mconn1.Stop() // nolint:errcheck // ignore for tests
+
+	mconn2 := createTestMConnection(server)
+	require.NoError(t, mconn2.Start())
+	defer mconn2.Stop() // nolint:errcheck // ignore for tests
+
+	// the burst is twice a packet, the next message stops the connection
+	msg := make([]byte, 100)
+	for i := 0; i < 3; i++ {
+		assert.True(t, mconn2.Send(0x01, msg))
+	}
+	select {
+	case err := <-errorsCh:
+		assert.Contains(t, fmt.Sprint(err), "exceeded the rate of 1 bytes/s on channel 1")
+		assert.False(t, mconn1.IsRunning())
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Did not receive error in 500ms")
+	}
+	assert.Len(t, receivedCh, 2)
+}
+
 func TestMConnectionTrySend(t *testing.T) {
 	server, client := NetPipe()
 	defer server.Close()
@@ -565,6 +731,40 @@ func TestMConnectionTrySend(t *testing.T) {
 	assert.Equal(t, "TrySend", <-resultCh)
 }
 
+func TestMConnectionSendContext(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	mconn := createTestMConnection(client)
+	err := mconn.Start()
+	require.Nil(t, err)
+
+	msg := []byte("Semicolon-Woman")
+	require.NoError(t, mconn.SendContext(context.Background(), 0x01, msg))
+	_, err = server.Read(make([]byte, len(msg)))
+	require.NoError(t, err)
+
+	// queued until the writes to the conn resume
+	require.NoError(t, mconn.SendContext(context.Background(), 0x01, msg))
+	status, ok := mconn.ChannelStatus(0x01)
+	require.True(t, ok)
+	assert.Equal(t, 1, status.SendQueueSize)
+	assert.Equal(t, int64(len(msg)), status.SendQueueBytes)
+
+	// the queue is full
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, mconn.SendContext(ctx, 0x01, msg))
+	assert.Error(t, mconn.SendContext(context.Background(), 0x02, msg))
+
+	_, ok = mconn.ChannelStatus(0x02)
+	assert.False(t, ok)
+
+	require.NoError(t, mconn.Stop())
+	assert.Equal(t, ErrConnStopped, mconn.SendContext(context.Background(), 0x01, msg))
+}
+
 // nolint:lll //ignore line length for tests
 func TestConnVectors(t *testing.T) {
 
@@ -632,3 +832,52 @@ func stopAll(t *testing.T, stoppers ...stopper) func() {
 		}
 	}
 }
+
+func TestMConnectionCompression(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	chDescs := []*ChannelDescriptor{
+		{ID: 0x01, Priority: 1, SendQueueCapacity: 1, RecvMessageCapacity: 20000, Compress: true},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 1, RecvMessageCapacity: 20000},
+	}
+	received := make(chan []byte, 2)
+	onReceive := func(chID byte, msgBytes []byte) {
+		received <- append([]byte(nil), msgBytes...)
+	}
+	errored := make(chan struct{}, 1)
+	onError := func(r interface{}) {
+		select {
+		case errored <- struct{}{}:
+		default:
+		}
+	}
+	cfg := DefaultMConnConfig()
+	cfg.CompressionHeaders = true
+	cfg.Compression = CompressionZstd
+	mconnClient := NewMConnectionWithConfig(client, chDescs, onReceive, onError, cfg)
+	mconnClient.SetLogger(log.TestingLogger())
+	require.NoError(t, mconnClient.Start())
+	defer mconnClient.Stop() // nolint:errcheck // ignore for tests
+
+	// the server sends its messages uncompressed, with the headers
+	cfg.Compression = ""
+	mconnServer := NewMConnectionWithConfig(server, chDescs, onReceive, onError, cfg)
+	mconnServer.SetLogger(log.TestingLogger())
+	require.NoError(t, mconnServer.Start())
+	defer mconnServer.Stop() // nolint:errcheck // ignore for tests
+
+	msg := bytes.Repeat([]byte("block part "), 1500)
+	assert.True(t, mconnClient.Send(0x01, msg))
+	assert.Equal(t, msg, <-received)
+	assert.True(t, mconnClient.Send(0x02, msg))
+	assert.Equal(t, msg, <-received)
+	assert.True(t, mconnServer.Send(0x01, msg))
+	assert.Equal(t, msg, <-received)
+
+	// the messages over the capacity of the channel are rejected, even if they
+	// would fit once compressed
+	assert.True(t, mconnClient.Send(0x01, bytes.Repeat(msg, 2)))
+	assert.True(t, expectSend(errored))
+}
@@ -145,6 +145,22 @@ func (e ErrTransportClosed) Error() string {
 	return "transport has been closed"
 }
 
+// ErrSwitchStopped is the reason the peers are stopped for when the Switch
+// stops, e.g. with the node.
+type ErrSwitchStopped struct{}
+
+func (e ErrSwitchStopped) Error() string {
+	return "switch stopped"
+}
+
+// ErrSendQueueFull is returned when a message can't be queued to send to a
+// peer, its send queue on the channel being full.
+type ErrSendQueueFull struct{}
+
+func (e ErrSendQueueFull) Error() string {
+	return "send queue is full"
+}
+
 // ErrPeerRemoval is raised when attempting to remove a peer results in an error.
 type ErrPeerRemoval struct{}
 
@@ -189,3 +205,13 @@ type ErrCurrentlyDialingOrExistingAddress struct {
 func (e ErrCurrentlyDialingOrExistingAddress) Error() string {
 	return fmt.Sprintf("connection with %s has been established or dialed", e.Addr)
 }
+
+// ErrPeerBanned indicates that the peer is banned for its score, see
+// Switch.ReportPeerBehavior.
+type ErrPeerBanned struct {
+	ID ID
+}
+
+func (e ErrPeerBanned) Error() string {
+	return fmt.Sprintf("peer %v is banned", e.ID)
+}
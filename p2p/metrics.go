@@ -8,8 +8,8 @@ import (
 
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 )
 
 const (
@@ -47,54 +47,77 @@ type Metrics struct {
 	NumAbandonedPeerMsgs metrics.Counter
 	// Number of pooled peer messages
 	NumPooledPeerMsgs metrics.Gauge
+	// Number of messages queued on the channel of an async reactor.
+	RecvQueueSize metrics.Gauge
+	// Number of messages queued to be sent to a given peer on a channel.
+	PeerSendQueueSize metrics.Gauge
+	// Number of messages received on a channel.
+	ChannelReceiveMsgsTotal metrics.Counter
+	// Number of messages sent on a channel.
+	ChannelSendMsgsTotal metrics.Counter
+	// Time taken by the reactors to handle the messages received, in seconds.
+	MessageHandleDuration metrics.Histogram
+	// Number of messages received on a channel which were dropped as invalid
+	// before being dispatched to the reactor.
+	ChannelInvalidMsgsTotal metrics.Counter
+	// Bytes of the messages received from all the peers which are queued for
+	// the reactors or being handled by them.
+	PendingRecvBytes metrics.Gauge
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		Peers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Peers: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peers",
 			Help:      "Number of peers.",
 		}, labels).With(labelsAndValues...),
-		PeerReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		PeerReceiveBytesTotal: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_receive_bytes_total",
 			Help:      "Number of bytes received from a given peer.",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
-		PeerSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		PeerSendBytesTotal: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_send_bytes_total",
 			Help:      "Number of bytes sent to a given peer.",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
-		PeerPendingSendBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		PeerPendingSendBytes: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_pending_send_bytes",
 			Help:      "Pending bytes to be sent to a given peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
-		NumTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		NumTxs: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "num_txs",
 			Help:      "Number of transactions submitted by each peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
-		MessageReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		MessageReceiveBytesTotal: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "message_receive_bytes_total",
 			Help:      "Number of bytes of each message type received.",
 		}, append(labels, "message_type")).With(labelsAndValues...),
-		MessageSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		MessageSendBytesTotal: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "message_send_bytes_total",
@@ -102,18 +125,61 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 		}, append(labels, "message_type")).With(labelsAndValues...),
 
 		// Added by Ostracon
-		NumAbandonedPeerMsgs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		NumAbandonedPeerMsgs: provider.NewCounter(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "num_abandoned_peer_msgs",
 			Help:      "Number of peer messages abandoned because of full channel",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
-		NumPooledPeerMsgs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		NumPooledPeerMsgs: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "num_pooled_peer_msgs",
 			Help:      "Number of peer messages pooled currently",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		RecvQueueSize: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "recv_queue_size",
+			Help:      "Number of messages queued on the channel of an async reactor, up to p2p.recv_buf_size.",
+		}, append(labels, "reactor", "chID")).With(labelsAndValues...),
+		PeerSendQueueSize: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_send_queue_size",
+			Help:      "Number of messages queued to be sent to a given peer on a channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		ChannelReceiveMsgsTotal: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "channel_receive_msgs_total",
+			Help:      "Number of messages received on a channel.",
+		}, append(labels, "chID")).With(labelsAndValues...),
+		ChannelSendMsgsTotal: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "channel_send_msgs_total",
+			Help:      "Number of messages sent on a channel.",
+		}, append(labels, "chID")).With(labelsAndValues...),
+		MessageHandleDuration: provider.NewHistogram(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "message_handle_duration_seconds",
+			Help:      "Time taken by the reactors to handle the messages received, in seconds.",
+			Buckets:   tmmetrics.ExponentialBuckets(0.0001, 4, 8),
+		}, append(labels, "reactor", "chID")).With(labelsAndValues...),
+		ChannelInvalidMsgsTotal: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "channel_invalid_msgs_total",
+			Help:      "Number of messages received on a channel which were dropped as invalid before being dispatched.",
+		}, append(labels, "chID")).With(labelsAndValues...),
+		PendingRecvBytes: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pending_recv_bytes",
+			Help:      "Bytes of the messages received which are queued for the reactors or being handled by them.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -128,8 +194,15 @@ func NopMetrics() *Metrics {
 		MessageSendBytesTotal:    discard.NewCounter(),
 
 		// Added by Ostracon
-		NumAbandonedPeerMsgs: discard.NewCounter(),
-		NumPooledPeerMsgs:    discard.NewGauge(),
+		NumAbandonedPeerMsgs:    discard.NewCounter(),
+		NumPooledPeerMsgs:       discard.NewGauge(),
+		RecvQueueSize:           discard.NewGauge(),
+		PeerSendQueueSize:       discard.NewGauge(),
+		ChannelReceiveMsgsTotal: discard.NewCounter(),
+		ChannelSendMsgsTotal:    discard.NewCounter(),
+		MessageHandleDuration:   discard.NewHistogram(),
+		ChannelInvalidMsgsTotal: discard.NewCounter(),
+		PendingRecvBytes:        discard.NewGauge(),
 	}
 }
 
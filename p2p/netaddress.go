@@ -26,6 +26,12 @@ type NetAddress struct {
 	ID   ID     `json:"id"`
 	IP   net.IP `json:"ip"`
 	Port uint16 `json:"port"`
+
+	// The other addresses of the peer, e.g. of another IP family, dialed in
+	// order when this one can't be (see DialAnyTimeout). They are learned
+	// from the handshake of the peer (see DefaultNodeInfo.ListenAddrs), and
+	// aren't part of the proto of the address exchanged by the PEX reactor.
+	Alternates []*NetAddress `json:"alternates,omitempty"`
 }
 
 // IDAddressString returns id@hostPort. It strips the leading
@@ -127,6 +133,26 @@ func NewNetAddressStrings(addrs []string) ([]*NetAddress, []error) {
 	return netAddrs, errs
 }
 
+// MergeNetAddresses merges the addresses of the same ID into the first one,
+// the others becoming its alternates in their order, e.g. for a peer listed
+// at one address of each IP family.
+func MergeNetAddresses(addrs []*NetAddress) []*NetAddress {
+	merged := make([]*NetAddress, 0, len(addrs))
+	byID := make(map[ID]*NetAddress, len(addrs))
+	for _, addr := range addrs {
+		first, ok := byID[addr.ID]
+		if !ok || addr.ID == "" {
+			byID[addr.ID] = addr
+			merged = append(merged, addr)
+			continue
+		}
+		if !first.Equals(addr) {
+			first.Alternates = append(first.Alternates, addr)
+		}
+	}
+	return merged
+}
+
 // NewNetAddressIPPort returns a new NetAddress using the provided IP
 // and port number.
 func NewNetAddressIPPort(ip net.IP, port uint16) *NetAddress {
@@ -249,6 +275,22 @@ func (na *NetAddress) DialTimeout(timeout time.Duration) (net.Conn, error) {
 	return conn, nil
 }
 
+// DialAnyTimeout calls net.DialTimeout on the address, then on its alternates
+// in order until one connects, and returns the connection with the address
+// connected to. The error is the one of the address if none connects.
+func (na *NetAddress) DialAnyTimeout(timeout time.Duration) (net.Conn, *NetAddress, error) {
+	conn, err := na.DialTimeout(timeout)
+	if err == nil {
+		return conn, na, nil
+	}
+	for _, alt := range na.Alternates {
+		if conn, altErr := alt.DialTimeout(timeout); altErr == nil {
+			return conn, alt, nil
+		}
+	}
+	return nil, nil, err
+}
+
 // Routable returns true if the address is routable.
 func (na *NetAddress) Routable() bool {
 	if err := na.Valid(); err != nil {
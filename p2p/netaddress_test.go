@@ -4,6 +4,7 @@ import (
 	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -133,6 +134,49 @@ func TestNewNetAddressStrings(t *testing.T) {
 	assert.Equal(t, 2, len(addrs))
 }
 
+func TestMergeNetAddresses(t *testing.T) {
+	addrs, errs := NewNetAddressStrings([]string{
+		"deadbeefdeadbeefdeadbeefdeadbeefdeadbeef@127.0.0.1:8080",
+		"deadbeefdeadbeefdeadbeefdeadbeefdeadbeed@127.0.0.2:8080",
+		"deadbeefdeadbeefdeadbeefdeadbeefdeadbeef@[::1]:8080",
+		"deadbeefdeadbeefdeadbeefdeadbeefdeadbeef@127.0.0.1:8080",
+	})
+	require.Empty(t, errs)
+
+	merged := MergeNetAddresses(addrs)
+	require.Len(t, merged, 2)
+	assert.Equal(t, addrs[0], merged[0])
+	assert.Equal(t, addrs[1], merged[1])
+	// the duplicate isn't an alternate
+	assert.Equal(t, []*NetAddress{addrs[2]}, merged[0].Alternates)
+	assert.Empty(t, merged[1].Alternates)
+}
+
+func TestNetAddressDialAnyTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr()
+	closed.Close()
+
+	tcpAddr := func(addr net.Addr) *NetAddress {
+		return NewNetAddressIPPort(addr.(*net.TCPAddr).IP, uint16(addr.(*net.TCPAddr).Port))
+	}
+	na := tcpAddr(closedAddr)
+	_, _, err = na.DialAnyTimeout(time.Second)
+	assert.Error(t, err)
+
+	alt := tcpAddr(ln.Addr())
+	na.Alternates = []*NetAddress{alt}
+	conn, dialed, err := na.DialAnyTimeout(time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, alt, dialed)
+}
+
 func TestNewNetAddressIPPort(t *testing.T) {
 	addr := NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 8080)
 	assert.Equal(t, "127.0.0.1:8080", addr.String())
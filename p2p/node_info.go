@@ -18,6 +18,8 @@ import (
 const (
 	maxNodeInfoSize = 10240 // 10KB
 	maxNumChannels  = 16    // plenty of room for upgrades, for now
+	// max number of the other listen addresses, see DefaultNodeInfo.ListenAddrs
+	maxNumListenAddrs = 8
 )
 
 // Max size of the NodeInfo struct
@@ -87,6 +89,11 @@ type DefaultNodeInfo struct {
 	DefaultNodeID ID     `json:"id"`          // authenticated identifier
 	ListenAddr    string `json:"listen_addr"` // accepting incoming
 
+	// The other addresses accepting incoming connections, in the order of
+	// preference, e.g. of another IP family. They aren't part of the proto of
+	// the node info, but sent in the extension of the handshake.
+	ListenAddrs []string `json:"listen_addrs,omitempty"`
+
 	// Check compatibility.
 	// Channels are HexBytes so easier to read as JSON
 	Network  string           `json:"network"`  // network/chain ID
@@ -126,9 +133,11 @@ func (info DefaultNodeInfo) Validate() error {
 
 	// ID is already validated.
 
-	// Validate ListenAddr.
-	_, err := NewNetAddressString(IDAddressString(info.ID(), info.ListenAddr))
-	if err != nil {
+	// Validate ListenAddr and ListenAddrs.
+	if len(info.ListenAddrs) > maxNumListenAddrs {
+		return fmt.Errorf("info.ListenAddrs is too long (%v). Max is %v", len(info.ListenAddrs), maxNumListenAddrs)
+	}
+	if _, err := info.NetAddress(); err != nil {
 		return err
 	}
 
@@ -219,11 +228,23 @@ OUTER_LOOP:
 
 // NetAddress returns a NetAddress derived from the DefaultNodeInfo -
 // it includes the authenticated peer ID and the self-reported
-// ListenAddr. Note that the ListenAddr is not authenticated and
-// may not match that address actually dialed if its an outbound peer.
+// ListenAddr, with the ListenAddrs as its alternates. Note that the ListenAddr
+// is not authenticated and may not match that address actually dialed if its
+// an outbound peer.
 func (info DefaultNodeInfo) NetAddress() (*NetAddress, error) {
 	idAddr := IDAddressString(info.ID(), info.ListenAddr)
-	return NewNetAddressString(idAddr)
+	na, err := NewNetAddressString(idAddr)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range info.ListenAddrs {
+		alt, err := NewNetAddressString(IDAddressString(info.ID(), addr))
+		if err != nil {
+			return nil, err
+		}
+		na.Alternates = append(na.Alternates, alt)
+	}
+	return na, nil
 }
 
 func (info DefaultNodeInfo) HasChannel(chID byte) bool {
@@ -41,6 +41,13 @@ func TestNodeInfoValidate(t *testing.T) {
 
 		{"Invalid NetAddress", func(ni *DefaultNodeInfo) { ni.ListenAddr = "not-an-address" }, true},
 		{"Good NetAddress", func(ni *DefaultNodeInfo) { ni.ListenAddr = "0.0.0.0:26656" }, false},
+		{"Invalid ListenAddrs", func(ni *DefaultNodeInfo) { ni.ListenAddrs = []string{"not-an-address"} }, true},
+		{
+			"Too Many ListenAddrs",
+			func(ni *DefaultNodeInfo) { ni.ListenAddrs = make([]string, maxNumListenAddrs+1) },
+			true,
+		},
+		{"Good ListenAddrs", func(ni *DefaultNodeInfo) { ni.ListenAddrs = []string{"[::1]:26656"} }, false},
 
 		{"Non-ASCII Version", func(ni *DefaultNodeInfo) { ni.Version = nonASCII }, true},
 		{"Empty tab Version", func(ni *DefaultNodeInfo) { ni.Version = emptyTab }, true},
@@ -1,16 +1,22 @@
 package p2p
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"reflect"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Finschia/ostracon/libs/cmap"
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/protoany"
 	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/libs/tracing"
 
 	tmconn "github.com/Finschia/ostracon/p2p/conn"
 )
@@ -58,6 +64,10 @@ type BufferedMsg struct {
 	Peer     Peer
 	Msg      []byte
 	ProtoMsg proto.Message
+
+	ctx      context.Context // context of the spans of the message, if traced
+	queuedAt time.Time
+	release  func() // releases the bytes of the message from the budget, if any
 }
 
 type EnvelopeSender interface {
@@ -109,6 +119,81 @@ func TrySendEnvelopeShim(p Peer, e Envelope, lg log.Logger) bool {
 	return p.TrySend(e.ChannelID, msgBytes)
 }
 
+// SendStatus is the status of the send queue of a peer on a channel, for the
+// reactors to pace the messages they send to the peer (e.g. the block requests)
+// rather than retrying the ones which couldn't be queued.
+type SendStatus struct {
+	Queued        bool  // whether the message sent was queued
+	QueueSize     int   // messages in the send queue, not written yet
+	QueueCapacity int   // capacity of the send queue, 0 if unknown
+	PendingBytes  int64 // bytes of the messages in the send queue
+}
+
+// Full returns true if there is no room for another message in the send queue.
+func (s SendStatus) Full() bool {
+	return s.QueueCapacity > 0 && s.QueueSize >= s.QueueCapacity
+}
+
+// StatusEnvelopeSender is implemented by the peers reporting the status of
+// their send queues.
+type StatusEnvelopeSender interface {
+	SendEnvelopeContext(context.Context, Envelope) (SendStatus, error)
+	TrySendEnvelopeStatus(Envelope) SendStatus
+	SendStatus(chID byte) SendStatus
+}
+
+// SendEnvelopeContext sends the envelope to the peer, waiting for room in its
+// send queue until the context is done. It returns the status of the send
+// queue and, if the message isn't queued, the reason. The peers which don't
+// implement StatusEnvelopeSender send it with SendEnvelopeShim, which waits
+// until its own timeout.
+func SendEnvelopeContext(ctx context.Context, p Peer, e Envelope, lg log.Logger) (SendStatus, error) {
+	if es, ok := p.(StatusEnvelopeSender); ok {
+		return es.SendEnvelopeContext(ctx, e)
+	}
+	if err := ctx.Err(); err != nil {
+		return PeerSendStatus(p, e.ChannelID), err
+	}
+	queued := SendEnvelopeShim(p, e, lg) //nolint: staticcheck
+	status := PeerSendStatus(p, e.ChannelID)
+	status.Queued = queued
+	if !queued {
+		return status, ErrSendQueueFull{}
+	}
+	return status, nil
+}
+
+// TrySendEnvelopeStatus attempts to send the envelope to the peer without
+// waiting, returning the status of its send queue.
+func TrySendEnvelopeStatus(p Peer, e Envelope, lg log.Logger) SendStatus {
+	if es, ok := p.(StatusEnvelopeSender); ok {
+		return es.TrySendEnvelopeStatus(e)
+	}
+	queued := TrySendEnvelopeShim(p, e, lg) //nolint: staticcheck
+	status := PeerSendStatus(p, e.ChannelID)
+	status.Queued = queued
+	return status
+}
+
+// PeerSendStatus returns the status of the send queue of the peer on the
+// channel, out of its ConnectionStatus if it doesn't implement
+// StatusEnvelopeSender.
+func PeerSendStatus(p Peer, chID byte) SendStatus {
+	if es, ok := p.(StatusEnvelopeSender); ok {
+		return es.SendStatus(chID)
+	}
+	for _, ch := range p.Status().Channels {
+		if ch.ID == chID {
+			return SendStatus{
+				QueueSize:     ch.SendQueueSize,
+				QueueCapacity: ch.SendQueueCapacity,
+				PendingBytes:  ch.SendQueueBytes,
+			}
+		}
+	}
+	return SendStatus{}
+}
+
 //----------------------------------------------------------
 
 // peerConn contains the raw connection and its config.
@@ -187,6 +272,17 @@ type peer struct {
 	metricsTicker *time.Ticker
 	mlc           *metricsLabelCache
 
+	// the message types of the channels, see ChannelDescriptor.MessageType
+	msgTypeByChID map[byte]proto.Message
+
+	// called with the messages failing the validation of their reactor (see
+	// MessageValidator), which are dropped
+	onInvalidMsg func(Peer, error)
+
+	// caps the bytes of the messages received from all the peers, nil if
+	// unlimited
+	recvBudget *recvBudget
+
 	// When removal of a peer fails, we set this flag
 	removalAttemptFailed bool
 }
@@ -212,6 +308,7 @@ func newPeer(
 		metricsTicker: time.NewTicker(metricsTickerDuration),
 		metrics:       NopMetrics(),
 		mlc:           mlc,
+		msgTypeByChID: msgTypeByChID,
 	}
 
 	p.mconn = createMConnection(
@@ -249,6 +346,11 @@ func (p *peer) SetLogger(l log.Logger) {
 	p.mconn.SetLogger(l)
 }
 
+// SetRates changes the send and receive rates of the connection to the peer.
+func (p *peer) SetRates(sendRate, recvRate int64) {
+	p.mconn.SetRates(sendRate, recvRate)
+}
+
 // OnStart implements BaseService.
 func (p *peer) OnStart() error {
 	if err := p.BaseService.OnStart(); err != nil {
@@ -323,26 +425,117 @@ func (p *peer) Status() tmconn.ConnectionStatus {
 // Using SendEnvelope allows for tracking the message bytes sent and received by message type
 // as a metric which Send cannot support.
 func (p *peer) SendEnvelope(e Envelope) bool {
+	return p.sendEnvelope(e, func(chID byte, msgBytes []byte) error {
+		if !p.Send(chID, msgBytes) {
+			return ErrSendQueueFull{}
+		}
+		return nil
+	}) == nil
+}
+
+// SendEnvelopeContext sends the message in the envelope like SendEnvelope, but
+// waits for room in the send queue of the channel until the context is done.
+// It returns the status of the send queue, and the reason the message isn't
+// queued if it isn't.
+func (p *peer) SendEnvelopeContext(ctx context.Context, e Envelope) (SendStatus, error) {
+	err := p.sendEnvelope(e, func(chID byte, msgBytes []byte) error {
+		err := p.mconn.SendContext(ctx, chID, msgBytes)
+		if err == nil {
+			p.countSent(chID, msgBytes)
+		}
+		return err
+	})
+	status := p.SendStatus(e.ChannelID)
+	status.Queued = err == nil
+	return status, err
+}
+
+// TrySendEnvelopeStatus attempts to send the message in the envelope like
+// TrySendEnvelope, returning immediately with the status of the send queue of
+// the channel.
+func (p *peer) TrySendEnvelopeStatus(e Envelope) SendStatus {
+	queued := p.TrySendEnvelope(e)
+	status := p.SendStatus(e.ChannelID)
+	status.Queued = queued
+	return status
+}
+
+// SendStatus returns the status of the send queue of the channel.
+func (p *peer) SendStatus(chID byte) SendStatus {
+	status, _ := p.mconn.ChannelStatus(chID)
+	return SendStatus{
+		QueueSize:     status.SendQueueSize,
+		QueueCapacity: status.SendQueueCapacity,
+		PendingBytes:  status.SendQueueBytes,
+	}
+}
+
+// sendEnvelope wraps and marshals the message in the envelope and sends it
+// with the send function, tracing it and counting its bytes by message type if
+// it is queued.
+func (p *peer) sendEnvelope(e Envelope, send func(chID byte, msgBytes []byte) error) error {
 	if !p.IsRunning() {
-		return false
+		// see Switch#Broadcast, where we fetch the list of peers and loop over
+		// them - while we're looping, one peer may be removed and stopped.
+		return tmconn.ErrConnStopped
 	} else if !p.hasChannel(e.ChannelID) {
-		return false
+		return fmt.Errorf("unknown channel %#x", e.ChannelID)
 	}
-	msg := e.Message
-	metricLabelValue := p.mlc.ValueToMetricLabel(msg)
-	if w, ok := msg.(Wrapper); ok {
-		msg = w.Wrap()
+	metricLabelValue := p.mlc.ValueToMetricLabel(e.Message)
+	msg, err := p.wrapMessage(e.ChannelID, e.Message)
+	if err != nil {
+		p.Logger.Error("wrapping message to send", "error", err)
+		return err
 	}
 	msgBytes, err := proto.Marshal(msg)
 	if err != nil {
 		p.Logger.Error("marshaling message to send", "error", err)
-		return false
+		return err
 	}
-	res := p.Send(e.ChannelID, msgBytes)
-	if res {
+	_, span := p.startMessageSpan("p2p.send", e.ChannelID, msgBytes)
+	err = send(e.ChannelID, msgBytes)
+	span.SetAttributes(attribute.String("message_type", metricLabelValue), attribute.Bool("queued", err == nil))
+	span.End()
+	if err == nil {
 		p.metrics.MessageSendBytesTotal.With("message_type", metricLabelValue).Add(float64(len(msgBytes)))
 	}
-	return res
+	return err
+}
+
+// wrapMessage wraps the message sent on the channel, if it is a Wrapper or a
+// registered type sent on an Any channel (see libs/protoany).
+func (p *peer) wrapMessage(chID byte, msg proto.Message) (proto.Message, error) {
+	return wrapMessage(p.msgTypeByChID, chID, msg)
+}
+
+// startMessageSpan starts a span of a message sent to or received from the
+// peer on the channel, returning a nil context and a span doing nothing if the
+// message isn't traced (see tracing.MessageContext).
+func (p *peer) startMessageSpan(name string, chID byte, msgBytes []byte) (context.Context, trace.Span) {
+	if !tracing.MessagesTraced() {
+		return nil, trace.SpanFromContext(context.Background())
+	}
+	ctx, span := tracing.StartMessageSpan(tracing.MessageContext(chID, msgBytes), name, trace.WithAttributes(
+		attribute.String("peer_id", string(p.ID())),
+		attribute.String("channel", fmt.Sprintf("%#x", chID)),
+		attribute.Int("bytes", len(msgBytes)),
+	))
+	if !span.IsRecording() {
+		return nil, span
+	}
+	return ctx, span
+}
+
+func wrapMessage(msgTypeByChID map[byte]proto.Message, chID byte, msg proto.Message) (proto.Message, error) {
+	if w, ok := msg.(Wrapper); ok {
+		return w.Wrap(), nil
+	}
+	if _, ok := msgTypeByChID[chID].(*gogotypes.Any); ok {
+		if _, ok := msg.(*gogotypes.Any); !ok {
+			return protoany.Pack(msg)
+		}
+	}
+	return msg, nil
 }
 
 // Send msg bytes to the channel identified by chID byte. Returns false if the
@@ -356,11 +549,7 @@ func (p *peer) Send(chID byte, msgBytes []byte) bool {
 	}
 	res := p.mconn.Send(chID, msgBytes)
 	if res {
-		labels := []string{
-			"peer_id", string(p.ID()),
-			"chID", fmt.Sprintf("%#x", chID),
-		}
-		p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
+		p.countSent(chID, msgBytes)
 	}
 	return res
 }
@@ -370,28 +559,12 @@ func (p *peer) Send(chID byte, msgBytes []byte) bool {
 // Using TrySendEnvelope allows for tracking the message bytes sent and received by message type
 // as a metric which TrySend cannot support.
 func (p *peer) TrySendEnvelope(e Envelope) bool {
-	if !p.IsRunning() {
-		// see Switch#Broadcast, where we fetch the list of peers and loop over
-		// them - while we're looping, one peer may be removed and stopped.
-		return false
-	} else if !p.hasChannel(e.ChannelID) {
-		return false
-	}
-	msg := e.Message
-	metricLabelValue := p.mlc.ValueToMetricLabel(msg)
-	if w, ok := msg.(Wrapper); ok {
-		msg = w.Wrap()
-	}
-	msgBytes, err := proto.Marshal(msg)
-	if err != nil {
-		p.Logger.Error("marshaling message to send", "error", err)
-		return false
-	}
-	res := p.TrySend(e.ChannelID, msgBytes)
-	if res {
-		p.metrics.MessageSendBytesTotal.With("message_type", metricLabelValue).Add(float64(len(msgBytes)))
-	}
-	return res
+	return p.sendEnvelope(e, func(chID byte, msgBytes []byte) error {
+		if !p.TrySend(chID, msgBytes) {
+			return ErrSendQueueFull{}
+		}
+		return nil
+	}) == nil
 }
 
 // TrySend msg bytes to the channel identified by chID byte. Immediately returns
@@ -405,15 +578,21 @@ func (p *peer) TrySend(chID byte, msgBytes []byte) bool {
 	}
 	res := p.mconn.TrySend(chID, msgBytes)
 	if res {
-		labels := []string{
-			"peer_id", string(p.ID()),
-			"chID", fmt.Sprintf("%#x", chID),
-		}
-		p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
+		p.countSent(chID, msgBytes)
 	}
 	return res
 }
 
+// countSent counts the message queued to send on the channel.
+func (p *peer) countSent(chID byte, msgBytes []byte) {
+	labels := []string{
+		"peer_id", string(p.ID()),
+		"chID", fmt.Sprintf("%#x", chID),
+	}
+	p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
+	p.metrics.ChannelSendMsgsTotal.With("chID", fmt.Sprintf("%#x", chID)).Add(1)
+}
+
 // Get the data for a given key.
 func (p *peer) Get(key string) interface{} {
 	return p.Data.Get(key)
@@ -487,6 +666,22 @@ func PeerMetrics(metrics *Metrics) PeerOption {
 	}
 }
 
+// PeerOnInvalidMessage sets the function called with the messages failing the
+// validation of their reactor, see MessageValidator.
+func PeerOnInvalidMessage(f func(Peer, error)) PeerOption {
+	return func(p *peer) {
+		p.onInvalidMsg = f
+	}
+}
+
+// peerRecvBudget sets the budget of the bytes of the messages received from all
+// the peers, see P2PConfig.MaxPendingRecvBytes.
+func peerRecvBudget(b *recvBudget) PeerOption {
+	return func(p *peer) {
+		p.recvBudget = b
+	}
+}
+
 func (p *peer) metricsReporter() {
 	for {
 		select {
@@ -495,6 +690,8 @@ func (p *peer) metricsReporter() {
 			var sendQueueSize float64
 			for _, chStatus := range status.Channels {
 				sendQueueSize += float64(chStatus.SendQueueSize)
+				p.metrics.PeerSendQueueSize.With("peer_id", string(p.ID()), "chID", fmt.Sprintf("%#x", chStatus.ID)).
+					Set(float64(chStatus.SendQueueSize))
 			}
 
 			p.metrics.PeerPendingSendBytes.With("peer_id", string(p.ID())).Set(sendQueueSize)
@@ -524,6 +721,8 @@ func createMConnection(
 			// which does onPeerError.
 			panic(fmt.Sprintf("Unknown channel %X", chID))
 		}
+		ctx, span := p.startMessageSpan("p2p.receive", chID, msgBytes)
+		defer span.End()
 		mt := msgTypeByChID[chID]
 		msg := proto.Clone(mt)
 		err := proto.Unmarshal(msgBytes, msg)
@@ -539,26 +738,66 @@ func createMConnection(
 			if err != nil {
 				panic(fmt.Errorf("unwrapping message: %s", err))
 			}
+		} else if anyMsg, ok := msg.(*gogotypes.Any); ok {
+			msg, err = protoany.Unpack(anyMsg)
+			if err != nil {
+				panic(fmt.Errorf("unpacking message: %s", err))
+			}
 		}
+		if v, ok := reactor.(MessageValidator); ok {
+			if err := v.ValidateMessage(chID, msg); err != nil {
+				span.SetAttributes(attribute.Bool("invalid", true))
+				p.metrics.ChannelInvalidMsgsTotal.With("chID", fmt.Sprintf("%#x", chID)).Add(1)
+				if p.onInvalidMsg != nil {
+					p.onInvalidMsg(p, fmt.Errorf("invalid message on channel %#x: %w", chID, err))
+				}
+				return
+			}
+		}
+		release := func() {}
+		if p.recvBudget != nil {
+			// stop reading from the peer until the reactors catch up
+			var ok bool
+			if release, ok = p.recvBudget.acquire(int64(len(msgBytes)), p.Quit()); !ok {
+				return
+			}
+		}
+		metricLabelValue := p.mlc.ValueToMetricLabel(msg)
+		span.SetAttributes(attribute.String("message_type", metricLabelValue))
 		p.metrics.PeerReceiveBytesTotal.With(labels...).Add(float64(len(msgBytes)))
-		p.metrics.MessageReceiveBytesTotal.With("message_type", p.mlc.ValueToMetricLabel(msg)).Add(float64(len(msgBytes)))
+		p.metrics.MessageReceiveBytesTotal.With("message_type", metricLabelValue).Add(float64(len(msgBytes)))
+		p.metrics.ChannelReceiveMsgsTotal.With("chID", fmt.Sprintf("%#x", chID)).Add(1)
 		if config.RecvAsync {
-			ch := reactor.GetRecvChan()
-			p.metrics.NumPooledPeerMsgs.With(labels...).Set(float64(len(ch)))
+			p.metrics.NumPooledPeerMsgs.With(labels...).Set(float64(reactor.RecvQueueSize(chID)))
 			// we must use copied msgBytes
 			// because msgBytes is on socket receive buffer yet so reactor can read it concurrently
 			copied := make([]byte, len(msgBytes))
 			copy(copied, msgBytes)
-			// if the channel is full, we are blocking a message until it can send into the channel
-			ch <- &BufferedMsg{ChID: chID, Peer: p, Msg: copied, ProtoMsg: msg}
-		} else if nr, ok := reactor.(EnvelopeReceiver); ok {
-			nr.ReceiveEnvelope(Envelope{
-				ChannelID: chID,
-				Src:       p,
-				Message:   msg,
-			})
+			bufferedMsg := &BufferedMsg{ChID: chID, Peer: p, Msg: copied, ProtoMsg: msg, ctx: ctx, release: release}
+			if ctx != nil {
+				bufferedMsg.queuedAt = time.Now()
+			}
+			if !reactor.QueueMsg(bufferedMsg) {
+				release()
+				span.SetAttributes(attribute.Bool("dropped", true))
+				p.metrics.NumAbandonedPeerMsgs.With(labels...).Add(1)
+			}
 		} else {
-			reactor.Receive(chID, p, msgBytes)
+			// released even if the reactor panics, stopping the peer
+			defer release()
+			start := time.Now()
+			if nr, ok := reactor.(EnvelopeReceiver); ok {
+				nr.ReceiveEnvelope(Envelope{
+					ChannelID: chID,
+					Src:       p,
+					Message:   msg,
+					ctx:       ctx,
+				})
+			} else {
+				reactor.Receive(chID, p, msgBytes)
+			}
+			p.metrics.MessageHandleDuration.With("reactor", reactor.String(), "chID", fmt.Sprintf("%#x", chID)).
+				Observe(time.Since(start).Seconds())
 		}
 	}
 
@@ -0,0 +1,191 @@
+package p2p
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Finschia/ostracon/config"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+type behaviorKind int
+
+const (
+	behaviorInvalidMessage behaviorKind = iota
+	behaviorSlowResponse
+	behaviorUsefulMessage
+	behaviorDisconnection // reported by the Switch, see StopPeerForError
+)
+
+// the scores of the behaviors
+const (
+	invalidMessageScore = -50
+	slowResponseScore   = -10
+	usefulMessageScore  = 1
+	disconnectionScore  = -10
+
+	// maxPeerScore caps the score of the good peers, so that a long history of
+	// useful messages doesn't make up for many misbehaviors
+	maxPeerScore = 50
+)
+
+// Behavior is a behavior of a peer, reported by the reactors with
+// Switch.ReportPeerBehavior.
+type Behavior struct {
+	kind   behaviorKind
+	reason string
+}
+
+// InvalidMessage returns the Behavior of a peer which sent an invalid message,
+// e.g. failing to be decoded or validated.
+func InvalidMessage(reason string) Behavior {
+	return Behavior{kind: behaviorInvalidMessage, reason: reason}
+}
+
+// SlowResponse returns the Behavior of a peer which didn't respond to a
+// request in time.
+func SlowResponse(reason string) Behavior {
+	return Behavior{kind: behaviorSlowResponse, reason: reason}
+}
+
+// UsefulMessage returns the Behavior of a peer which sent a useful message,
+// e.g. a vote or a block part.
+func UsefulMessage(reason string) Behavior {
+	return Behavior{kind: behaviorUsefulMessage, reason: reason}
+}
+
+func (b Behavior) score() float64 {
+	switch b.kind {
+	case behaviorInvalidMessage:
+		return invalidMessageScore
+	case behaviorSlowResponse:
+		return slowResponseScore
+	case behaviorUsefulMessage:
+		return usefulMessageScore
+	case behaviorDisconnection:
+		return disconnectionScore
+	default:
+		panic(fmt.Sprintf("unknown behavior %d", b.kind))
+	}
+}
+
+func (b Behavior) String() string {
+	var kind string
+	switch b.kind {
+	case behaviorInvalidMessage:
+		kind = "invalid message"
+	case behaviorSlowResponse:
+		kind = "slow response"
+	case behaviorUsefulMessage:
+		kind = "useful message"
+	case behaviorDisconnection:
+		kind = "disconnection"
+	}
+	return fmt.Sprintf("%s: %s", kind, b.reason)
+}
+
+//-----------------------------------------------------------------------------
+
+// peerScores tracks the scores of the peers, which start at 0, are changed by
+// their behaviors and decay to 0 with a half-life, and the peers banned.
+type peerScores struct {
+	mtx             tmsync.Mutex
+	halfLife        time.Duration
+	disconnectScore float64 // 0 - never disconnect
+	banScore        float64 // 0 - never ban
+	banDuration     time.Duration
+	scores          map[ID]*peerScore
+	banned          map[ID]time.Time // by peer, until when
+	lastPrune       time.Time
+}
+
+type peerScore struct {
+	value float64
+	at    time.Time // time of value
+}
+
+func newPeerScores(cfg *config.P2PConfig) *peerScores {
+	return &peerScores{
+		halfLife:        cfg.PeerScoreHalfLife,
+		disconnectScore: cfg.PeerDisconnectScore,
+		banScore:        cfg.PeerBanScore,
+		banDuration:     cfg.PeerBanDuration,
+		scores:          make(map[ID]*peerScore),
+		banned:          make(map[ID]time.Time),
+	}
+}
+
+// add adds the score of the behavior to the one of the peer at time now, and
+// returns the score of the peer, and whether it's to be disconnected and
+// banned (banning it).
+func (ps *peerScores) add(id ID, b Behavior, now time.Time) (score float64, disconnect, ban bool) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	ps.prune(now)
+	s, ok := ps.scores[id]
+	if !ok {
+		s = &peerScore{at: now}
+		ps.scores[id] = s
+	}
+	s.value = math.Min(maxPeerScore, ps.decay(s, now)+b.score())
+	s.at = now
+
+	disconnect = ps.disconnectScore < 0 && s.value <= ps.disconnectScore
+	ban = ps.banScore < 0 && s.value <= ps.banScore
+	if ban {
+		ps.banned[id] = now.Add(ps.banDuration)
+		// a new start when the ban is over
+		delete(ps.scores, id)
+	}
+	return s.value, disconnect || ban, ban
+}
+
+// score returns the score of the peer at time now.
+func (ps *peerScores) score(id ID, now time.Time) float64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	s, ok := ps.scores[id]
+	if !ok {
+		return 0
+	}
+	return ps.decay(s, now)
+}
+
+// isBanned reports whether the peer is banned at time now.
+func (ps *peerScores) isBanned(id ID, now time.Time) bool {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	until, ok := ps.banned[id]
+	return ok && now.Before(until)
+}
+
+// decay returns the value of s decayed from its time to now.
+func (ps *peerScores) decay(s *peerScore, now time.Time) float64 {
+	if ps.halfLife <= 0 || !now.After(s.at) {
+		return s.value
+	}
+	return s.value * math.Exp2(-float64(now.Sub(s.at))/float64(ps.halfLife))
+}
+
+// prune forgets the scores decayed to about 0 and the bans over, not to grow
+// with the peers seen.
+func (ps *peerScores) prune(now time.Time) {
+	if ps.halfLife <= 0 || now.Sub(ps.lastPrune) < ps.halfLife {
+		return
+	}
+	for id, s := range ps.scores {
+		if math.Abs(ps.decay(s, now)) < 1 {
+			delete(ps.scores, id)
+		}
+	}
+	for id, until := range ps.banned {
+		if !now.Before(until) {
+			delete(ps.banned, id)
+		}
+	}
+	ps.lastPrune = now
+}
@@ -0,0 +1,144 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+)
+
+func TestPeerScores(t *testing.T) {
+	cfg := config.DefaultP2PConfig()
+	cfg.PeerScoreHalfLife = time.Minute
+	cfg.PeerDisconnectScore = -50
+	cfg.PeerBanScore = -100
+	cfg.PeerBanDuration = time.Hour
+	ps := newPeerScores(cfg)
+	id := ID("a")
+	now := time.Now()
+
+	// the scores of the good peers are capped
+	for i := 0; i < 2*maxPeerScore; i++ {
+		ps.add(id, UsefulMessage("vote"), now)
+	}
+	assert.EqualValues(t, maxPeerScore, ps.score(id, now))
+
+	// and decay with the half-life
+	now = now.Add(time.Minute)
+	assert.InDelta(t, maxPeerScore/2, ps.score(id, now), 0.001)
+
+	// a good peer isn't disconnected for a misbehavior
+	score, disconnect, ban := ps.add(id, InvalidMessage("bad"), now)
+	assert.InDelta(t, maxPeerScore/2+invalidMessageScore, score, 0.001)
+	assert.False(t, disconnect)
+	assert.False(t, ban)
+
+	// but is for more of them
+	_, disconnect, ban = ps.add(id, InvalidMessage("bad"), now)
+	assert.True(t, disconnect)
+	assert.False(t, ban)
+	assert.False(t, ps.isBanned(id, now))
+
+	// and banned for even more
+	_, disconnect, ban = ps.add(id, InvalidMessage("bad"), now)
+	assert.True(t, disconnect)
+	assert.True(t, ban)
+	assert.True(t, ps.isBanned(id, now))
+	assert.Zero(t, ps.score(id, now))
+
+	// until the ban is over
+	now = now.Add(time.Hour)
+	assert.False(t, ps.isBanned(id, now))
+	ps.add(ID("b"), SlowResponse("timeout"), now)
+	assert.NotContains(t, ps.banned, id)
+}
+
+func TestPeerScoresDisabled(t *testing.T) {
+	cfg := config.DefaultP2PConfig()
+	cfg.PeerDisconnectScore = 0
+	cfg.PeerBanScore = 0
+	ps := newPeerScores(cfg)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		_, disconnect, ban := ps.add(ID("a"), InvalidMessage("bad"), now)
+		require.False(t, disconnect)
+		require.False(t, ban)
+	}
+	assert.EqualValues(t, 10*invalidMessageScore, ps.score(ID("a"), now))
+}
+
+func TestSwitchReportPeerBehavior(t *testing.T) {
+	sw := MakeSwitch(cfg, 1, "testing", "123.123.123", initSwitchFunc)
+	require.NoError(t, sw.Start())
+	t.Cleanup(func() {
+		if err := sw.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// simulate remote peer
+	rp := &remotePeer{PrivKey: ed25519.GenPrivKey(), Config: cfg}
+	rp.Start()
+	defer rp.Stop()
+
+	p, err := sw.transport.Dial(*rp.Addr(), peerConfig{
+		chDescs:      sw.chDescs,
+		onPeerError:  sw.StopPeerForError,
+		isPersistent: sw.IsPeerPersistent,
+		reactorsByCh: sw.reactorsByCh,
+	})
+	require.NoError(t, err)
+	require.NoError(t, sw.addPeer(p))
+
+	// the peer is kept for a slow response, but not for an invalid message
+	sw.ReportPeerBehavior(rp.ID(), SlowResponse("timeout"))
+	require.NotNil(t, sw.Peers().Get(rp.ID()))
+	assert.InDelta(t, slowResponseScore, sw.PeerScore(rp.ID()), 0.001)
+	sw.ReportPeerBehavior(rp.ID(), InvalidMessage("bad"))
+	assertNoPeersAfterTimeout(t, sw, 100*time.Millisecond)
+	assert.False(t, sw.IsPeerBanned(rp.ID()))
+
+	// and is banned for more of them
+	sw.ReportPeerBehavior(rp.ID(), InvalidMessage("bad"))
+	assert.True(t, sw.IsPeerBanned(rp.ID()))
+	err = sw.DialPeerWithAddress(rp.Addr())
+	assert.Equal(t, ErrPeerBanned{rp.ID()}, err)
+}
+
+func TestSwitchReportPeerBehaviorUnconditional(t *testing.T) {
+	sw := MakeSwitch(cfg, 1, "testing", "123.123.123", initSwitchFunc)
+	require.NoError(t, sw.Start())
+	t.Cleanup(func() {
+		if err := sw.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// simulate remote peer
+	rp := &remotePeer{PrivKey: ed25519.GenPrivKey(), Config: cfg}
+	rp.Start()
+	defer rp.Stop()
+	require.NoError(t, sw.AddUnconditionalPeerIDs([]string{string(rp.ID())}))
+
+	p, err := sw.transport.Dial(*rp.Addr(), peerConfig{
+		chDescs:      sw.chDescs,
+		onPeerError:  sw.StopPeerForError,
+		isPersistent: sw.IsPeerPersistent,
+		reactorsByCh: sw.reactorsByCh,
+	})
+	require.NoError(t, err)
+	require.NoError(t, sw.addPeer(p))
+
+	// the unconditional peers aren't scored
+	for i := 0; i < 3; i++ {
+		sw.ReportPeerBehavior(rp.ID(), InvalidMessage("bad"))
+	}
+	assert.NotNil(t, sw.Peers().Get(rp.ID()))
+	assert.Zero(t, sw.PeerScore(rp.ID()))
+	assert.False(t, sw.IsPeerBanned(rp.ID()))
+}
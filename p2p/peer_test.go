@@ -1,6 +1,7 @@
 package p2p
 
 import (
+	"context"
 	"fmt"
 	"github.com/Finschia/ostracon/libs/service"
 	golog "log"
@@ -50,6 +51,12 @@ func TestSendWithOldPeer(t *testing.T) {
 	p := &OldPeer{}
 	assert.True(t, SendEnvelopeShim(p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, logger))
 	assert.True(t, TrySendEnvelopeShim(p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, logger))
+
+	status, err := SendEnvelopeContext(context.Background(), p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, SendStatus{Queued: true}, status)
+	assert.True(t, TrySendEnvelopeStatus(p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, logger).Queued)
+	assert.False(t, PeerSendStatus(p, testCh).Full())
 }
 
 func TestPeerBasic(t *testing.T) {
@@ -114,6 +121,47 @@ func TestPeerSend(t *testing.T) {
 	require.False(SendEnvelopeShim(p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, p.Logger))
 }
 
+func TestPeerSendContext(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// simulate remote peer
+	rp := &remotePeer{PrivKey: ed25519.GenPrivKey(), Config: cfg}
+	rp.Start()
+	t.Cleanup(rp.Stop)
+
+	p, err := createOutboundPeerAndPerformHandshake(rp.Addr(), cfg, tmconn.DefaultMConnConfig())
+	require.Nil(err)
+
+	// isn't running peer
+	_, err = p.SendEnvelopeContext(context.Background(), Envelope{ChannelID: testCh, Message: &p2p.Message{}})
+	require.Equal(tmconn.ErrConnStopped, err)
+
+	err = p.Start()
+	require.Nil(err)
+	t.Cleanup(func() {
+		if err := p.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	status, err := SendEnvelopeContext(context.Background(), p, Envelope{ChannelID: testCh, Message: &p2p.Message{}}, p.Logger)
+	require.NoError(err)
+	assert.True(status.Queued)
+	assert.Equal(1, status.QueueCapacity)
+	assert.False(PeerSendStatus(p, 0x02).Full())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, err = p.SendEnvelopeContext(ctx, Envelope{ChannelID: testCh, Message: &p2p.Message{}})
+	assert.Equal(context.Canceled, err)
+	assert.False(status.Queued)
+
+	// hasn't channels
+	p.channels = []byte{}
+	_, err = p.SendEnvelopeContext(context.Background(), Envelope{ChannelID: testCh, Message: &p2p.Message{}})
+	require.Error(err)
+}
+
 func createOutboundPeerAndPerformHandshake(
 	addr *NetAddress,
 	config *config.P2PConfig,
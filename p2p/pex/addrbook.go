@@ -671,6 +671,10 @@ func (a *addrBook) addAddress(addr, src *p2p.NetAddress) error {
 
 	ka := a.addrLookup[addr.ID]
 	if ka != nil {
+		// Keep the alternates of the address up to date.
+		if len(addr.Alternates) > 0 && ka.Addr.Equals(addr) {
+			ka.Addr.Alternates = addr.Alternates
+		}
 		// If its already old and the address ID's are the same, ignore it.
 		// Thereby avoiding issues with a node on the network attempting to change
 		// the IP of a known node ID. (Which could yield an eclipse attack on the node)
@@ -0,0 +1,49 @@
+package pex
+
+import (
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// CrawlStats are the statistics of the network crawl of a reactor in seed
+// mode, since it started.
+type CrawlStats struct {
+	// Number of crawl rounds, run every crawlPeerPeriod.
+	Rounds int64
+	// Time the last round started.
+	LastRound time.Time
+	// Number of peers crawled in the last 24 hours.
+	CrawledPeers int
+	// Number of dials of the crawled peers, and of those which failed.
+	Dials        int64
+	DialFailures int64
+	// Number of addresses received from the crawled peers, and of those which
+	// were added to the address book.
+	AddrsReceived int64
+	AddrsAdded    int64
+	// Number of inbound peers given addresses, then disconnected.
+	InboundServed int64
+	// Number of peers disconnected once crawled.
+	Disconnects int64
+	// Number of addresses in the address book.
+	AddrBookSize int
+}
+
+// crawlStats collects the statistics of the crawl, in seed mode.
+type crawlStats struct {
+	mtx   tmsync.Mutex
+	stats CrawlStats
+}
+
+func (c *crawlStats) update(f func(stats *CrawlStats)) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	f(&c.stats)
+}
+
+func (c *crawlStats) get() CrawlStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.stats
+}
@@ -0,0 +1,88 @@
+package pex
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Finschia/ostracon/p2p"
+)
+
+// Discovery is a backend discovering the addresses of the peers to dial, in
+// addition to the seeds and the addresses exchanged with the peers, e.g. for
+// the private networks without any seed node.
+type Discovery interface {
+	// Discover returns the addresses discovered, and the errors of the ones
+	// which couldn't be.
+	Discover(ctx context.Context) ([]*p2p.NetAddress, []error)
+}
+
+//-----------------------------------------------------------------------------
+
+type srvDiscovery struct {
+	name      string
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewSRVDiscovery returns a Discovery of the addresses in the DNS SRV records
+// of name, e.g. "_ostracon._tcp.example.com". As the records don't have any
+// room for the IDs of the peers, the target of a record is the host of a
+// peer, prefixed with its ID, e.g.
+//
+//	_ostracon._tcp.example.com. 300 IN SRV 0 0 26656 <ID>.node1.example.com.
+//
+// for the peer <ID>@node1.example.com:26656.
+func NewSRVDiscovery(name string) Discovery {
+	return &srvDiscovery{
+		name:      name,
+		lookupSRV: net.DefaultResolver.LookupSRV,
+	}
+}
+
+// Discover implements Discovery.
+func (d *srvDiscovery) Discover(ctx context.Context) ([]*p2p.NetAddress, []error) {
+	_, records, err := d.lookupSRV(ctx, "", "", d.name)
+	if err != nil {
+		return nil, []error{fmt.Errorf("looking up the SRV records of %s: %w", d.name, err)}
+	}
+	var addrs []*p2p.NetAddress
+	var errs []error
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		id, host, ok := strings.Cut(target, ".")
+		if !ok {
+			errs = append(errs, fmt.Errorf("SRV record target %s has no ID", record.Target))
+			continue
+		}
+		addr, err := p2p.NewNetAddressString(fmt.Sprintf("%s@%s", id, net.JoinHostPort(host, fmt.Sprint(record.Port))))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, errs
+}
+
+//-----------------------------------------------------------------------------
+
+type validatorsDiscovery struct {
+	validatorAddrs func(ctx context.Context) ([]string, error)
+}
+
+// NewValidatorsDiscovery returns a Discovery of the addresses of the current
+// validators (ID@host:port), returned by validatorAddrs, e.g. published by the
+// application.
+func NewValidatorsDiscovery(validatorAddrs func(ctx context.Context) ([]string, error)) Discovery {
+	return &validatorsDiscovery{validatorAddrs: validatorAddrs}
+}
+
+// Discover implements Discovery.
+func (d *validatorsDiscovery) Discover(ctx context.Context) ([]*p2p.NetAddress, []error) {
+	strs, err := d.validatorAddrs(ctx)
+	if err != nil {
+		return nil, []error{fmt.Errorf("getting the addresses of the validators: %w", err)}
+	}
+	return p2p.NewNetAddressStrings(strs)
+}
@@ -0,0 +1,77 @@
+package pex
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/p2p"
+)
+
+func TestSRVDiscovery(t *testing.T) {
+	id := "d824b13cb5d40fa1d8a614e089357c7eff31b670"
+	d := NewSRVDiscovery("_ostracon._tcp.example.com").(*srvDiscovery)
+	d.lookupSRV = func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		assert.Equal(t, "_ostracon._tcp.example.com", name)
+		return name, []*net.SRV{
+			{Target: id + ".127.0.0.1.", Port: 26656},
+			{Target: "localhost.", Port: 26656},
+		}, nil
+	}
+
+	addrs, errs := d.Discover(context.Background())
+	require.Len(t, addrs, 1)
+	assert.Equal(t, id+"@127.0.0.1:26656", addrs[0].String())
+	// the target without any ID
+	assert.Len(t, errs, 1)
+
+	d.lookupSRV = func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+	addrs, errs = d.Discover(context.Background())
+	assert.Empty(t, addrs)
+	assert.Len(t, errs, 1)
+}
+
+func TestValidatorsDiscovery(t *testing.T) {
+	id := "d824b13cb5d40fa1d8a614e089357c7eff31b670"
+	d := NewValidatorsDiscovery(func(context.Context) ([]string, error) {
+		return []string{id + "@127.0.0.1:26656", "127.0.0.1:26657"}, nil
+	})
+
+	addrs, errs := d.Discover(context.Background())
+	require.Len(t, addrs, 1)
+	assert.Equal(t, id+"@127.0.0.1:26656", addrs[0].String())
+	assert.Len(t, errs, 1)
+}
+
+func TestPEXReactorUsesDiscoveries(t *testing.T) {
+	// directory to store address books
+	dir, err := os.MkdirTemp("", "pex_reactor")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// 1. create a validator
+	validator := testCreateDefaultPeer(dir, 0)
+	require.Nil(t, validator.Start())
+	defer validator.Stop() // nolint:errcheck // ignore for tests
+
+	// 2. create a peer discovering the validator, without any seed
+	peer := testCreatePeerWithConfig(dir, 1, &ReactorConfig{
+		Discoveries: []Discovery{NewValidatorsDiscovery(func(context.Context) ([]string, error) {
+			return []string{validator.NetAddress().String()}, nil
+		})},
+		RecvBufSize: cfg.PexRecvBufSize,
+	})
+	require.Nil(t, peer.Start())
+	defer peer.Stop() // nolint:errcheck // ignore for tests
+
+	// 3. check that the peer connects to the validator immediately
+	assertPeersWithTimeout(t, []*p2p.Switch{peer}, 10*time.Millisecond, 3*time.Second, 1)
+}
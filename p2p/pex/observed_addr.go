@@ -0,0 +1,51 @@
+package pex
+
+import (
+	"net"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/p2p"
+)
+
+// observedAddrs are the IPs the peers report the node is seen from, which it
+// learns its external IP from.
+type observedAddrs struct {
+	mtx     tmsync.Mutex
+	reports map[p2p.ID]string // peer ID -> reported IP
+	learned string
+}
+
+func newObservedAddrs() *observedAddrs {
+	return &observedAddrs{reports: make(map[p2p.ID]string)}
+}
+
+// add records the IP reported by the peer, replacing the one it reported
+// before. It returns true if the IP is reported by at least minReports peers
+// and wasn't learned yet.
+func (o *observedAddrs) add(id p2p.ID, ip net.IP, minReports int) bool {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	reported := ip.String()
+	o.reports[id] = reported
+	if reported == o.learned {
+		return false
+	}
+	n := 0
+	for _, r := range o.reports {
+		if r == reported {
+			n++
+		}
+	}
+	if n < minReports {
+		return false
+	}
+	o.learned = reported
+	return true
+}
+
+// remove forgets the IP reported by the peer.
+func (o *observedAddrs) remove(id p2p.ID) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	delete(o.reports, id)
+}
@@ -1,8 +1,10 @@
 package pex
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
@@ -11,11 +13,13 @@ import (
 	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
 
 	"github.com/Finschia/ostracon/libs/cmap"
+	"github.com/Finschia/ostracon/libs/log"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	"github.com/Finschia/ostracon/libs/service"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/p2p/conn"
+	ocp2p "github.com/Finschia/ostracon/proto/ostracon/p2p"
 )
 
 type Peer = p2p.Peer
@@ -24,6 +28,10 @@ const (
 	// PexChannel is a channel for PEX messages
 	PexChannel = byte(0x00)
 
+	// ObservedAddrChannel is a channel for the reports of the IPs the peers
+	// are seen from, see ReactorConfig.ObservedAddrReports
+	ObservedAddrChannel = byte(0x08)
+
 	// over-estimate of max NetAddress size
 	// hexID (40) + IP (16) + Port (2) + Name (100) ...
 	// NOTE: dont use massive DNS name ..
@@ -33,9 +41,21 @@ const (
 	// small request results in up to maxMsgSize response
 	maxMsgSize = maxAddressSize * maxGetSelection
 
+	// DefaultObservedAddrReports is the number of outbound peers reporting the
+	// same IP for the external address of the node to be learned, see
+	// ReactorConfig.ObservedAddrReports
+	DefaultObservedAddrReports = 3
+
+	// over-estimate of the max size of an ObservedAddr, the IPv6 address
+	// being at most 45 characters long
+	maxObservedAddrMsgSize = 64
+
 	// ensure we have enough peers
 	defaultEnsurePeersPeriod = 30 * time.Second
 
+	// timeout of a discovery of the addresses with the Discoveries
+	discoveryTimeout = 10 * time.Second
+
 	// Seed/Crawler constants
 
 	// minTimeBetweenCrawls is a minimum time between attempts to crawl a peer.
@@ -53,6 +73,10 @@ const (
 
 	// if a peer is marked bad, it will be banned for at least this time period
 	defaultBanTime = 24 * time.Hour
+
+	// the same messages are logged at most once per peerLogInterval, so that
+	// the addresses sent by the peers can't flood the logs
+	peerLogInterval = time.Second
 )
 
 type errMaxAttemptsToDial struct {
@@ -99,6 +123,11 @@ type Reactor struct {
 
 	// seed/crawled mode fields
 	crawlPeerInfos map[p2p.ID]crawlPeerInfo
+	crawlStats     crawlStats
+
+	observedAddrs *observedAddrs
+
+	peerLogger log.Logger // samples the messages logged for the messages of the peers
 }
 
 func (r *Reactor) minReceiveRequestInterval() time.Duration {
@@ -124,8 +153,18 @@ type ReactorConfig struct {
 	// if it can't connect to peers in the addrbook.
 	Seeds []string
 
+	// Discoveries are the backends discovering the addresses to dial, in
+	// addition to the seeds, when the reactor needs more peers.
+	Discoveries []Discovery
+
 	// Receive channel buffer size
 	RecvBufSize int
+
+	// ObservedAddrReports is the number of outbound peers which must report
+	// the same routable IP for the node to advertise it as its external
+	// address, with the port it listens on. 0 not to learn the external
+	// address from the peers.
+	ObservedAddrReports int
 }
 
 type _attemptsToDial struct {
@@ -142,11 +181,20 @@ func NewReactor(b AddrBook, async bool, config *ReactorConfig) *Reactor {
 		requestsSent:         cmap.NewCMap(),
 		lastReceivedRequests: cmap.NewCMap(),
 		crawlPeerInfos:       make(map[p2p.ID]crawlPeerInfo),
+		observedAddrs:        newObservedAddrs(),
 	}
 	r.BaseReactor = *p2p.NewBaseReactor("PEX", r, async, config.RecvBufSize)
+	r.peerLogger = log.NewSampledLogger(r.Logger, peerLogInterval)
 	return r
 }
 
+// SetLogger implements service.Service, and sets the logger sampling the
+// messages logged for the messages of the peers.
+func (r *Reactor) SetLogger(l log.Logger) {
+	r.BaseReactor.SetLogger(l)
+	r.peerLogger = log.NewSampledLogger(l, peerLogInterval)
+}
+
 // OnStart implements BaseService
 func (r *Reactor) OnStart() error {
 	// call BaseReactor's OnStart()
@@ -163,7 +211,7 @@ func (r *Reactor) OnStart() error {
 	numOnline, seedAddrs, err := r.checkSeeds()
 	if err != nil {
 		return err
-	} else if numOnline == 0 && r.book.Empty() {
+	} else if numOnline == 0 && r.book.Empty() && len(r.config.Discoveries) == 0 {
 		return errors.New("address book is empty and couldn't resolve any seed nodes")
 	}
 
@@ -197,12 +245,26 @@ func (r *Reactor) GetChannels() []*conn.ChannelDescriptor {
 			RecvMessageCapacity: maxMsgSize,
 			MessageType:         &tmp2p.Message{},
 		},
+		{
+			ID:                  ObservedAddrChannel,
+			Priority:            1,
+			SendQueueCapacity:   1,
+			RecvMessageCapacity: maxObservedAddrMsgSize,
+			MessageType:         &ocp2p.Message{},
+		},
 	}
 }
 
 // AddPeer implements Reactor by adding peer to the address book (if inbound)
 // or by requesting more addresses (if outbound).
 func (r *Reactor) AddPeer(p Peer) {
+	// tell the peer the IP it is seen from, for it to learn its external
+	// address
+	p2p.SendEnvelopeShim(p, p2p.Envelope{ //nolint: staticcheck
+		ChannelID: ObservedAddrChannel,
+		Message:   &ocp2p.ObservedAddr{Ip: p.SocketAddr().IP.String()},
+	}, r.Logger)
+
 	if p.IsOutbound() {
 		// For outbound peers, the address is already in the books -
 		// either via DialPeersAsync or r.Receive.
@@ -233,16 +295,17 @@ func (r *Reactor) RemovePeer(p Peer, reason interface{}) {
 	id := string(p.ID())
 	r.requestsSent.Delete(id)
 	r.lastReceivedRequests.Delete(id)
+	r.observedAddrs.remove(p.ID())
 }
 
 func (r *Reactor) logErrAddrBook(err error) {
 	if err != nil {
 		switch err.(type) {
 		case ErrAddrBookNilAddr:
-			r.Logger.Error("Failed to add new address", "err", err)
+			r.peerLogger.Error("Failed to add new address", "err", err)
 		default:
 			// non-routable, self, full book, private, etc.
-			r.Logger.Debug("Failed to add new address", "err", err)
+			r.peerLogger.Debug("Failed to add new address", "err", err)
 		}
 	}
 }
@@ -273,6 +336,7 @@ func (r *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 
 			// Send addrs and disconnect
 			r.SendAddrs(e.Src, r.book.GetSelectionWithBias(biasToSelectNewPeers))
+			r.crawlStats.update(func(stats *CrawlStats) { stats.InboundServed++ })
 			go func() {
 				// In a go-routine so it doesn't block .Receive.
 				e.Src.FlushStop()
@@ -306,13 +370,24 @@ func (r *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 			return
 		}
 
+	case *ocp2p.ObservedAddr:
+		ip := net.ParseIP(msg.Ip)
+		if ip == nil {
+			r.Switch.StopPeerForError(e.Src, fmt.Errorf("invalid observed IP %q", msg.Ip))
+			return
+		}
+		r.receiveObservedIP(ip, e.Src)
+
 	default:
-		r.Logger.Error(fmt.Sprintf("Unknown message type %T", msg))
+		r.peerLogger.Error(fmt.Sprintf("Unknown message type %T", msg))
 	}
 }
 
 func (r *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
-	msg := &tmp2p.Message{}
+	var msg p2p.Unwrapper = &tmp2p.Message{}
+	if chID == ObservedAddrChannel {
+		msg = &ocp2p.Message{}
+	}
 	err := proto.Unmarshal(msgBytes, msg)
 	if err != nil {
 		panic(err)
@@ -400,6 +475,15 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 		}
 	}
 
+	added := 0
+	defer func() {
+		if r.config.SeedMode {
+			r.crawlStats.update(func(stats *CrawlStats) {
+				stats.AddrsReceived += int64(len(addrs))
+				stats.AddrsAdded += int64(added)
+			})
+		}
+	}()
 	for _, netAddr := range addrs {
 		// NOTE: we check netAddr validity and routability in book#AddAddress.
 		err = r.book.AddAddress(netAddr, srcAddr)
@@ -409,6 +493,7 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 			// peer here too?
 			continue
 		}
+		added++
 
 		// If this address came from a seed node, try to connect to it without
 		// waiting (#2093)
@@ -430,6 +515,32 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 	return nil
 }
 
+// receiveObservedIP records the IP of the node reported by the peer. Only the
+// reports of the outbound peers are counted, as the inbound ones could be
+// opened by a single host to advertise any IP.
+func (r *Reactor) receiveObservedIP(ip net.IP, src Peer) {
+	if r.config.ObservedAddrReports <= 0 || !src.IsOutbound() {
+		return
+	}
+	ourAddr, err := r.Switch.NodeInfo().NetAddress()
+	if err != nil {
+		r.Logger.Error("Failed to get our NetAddress", "err", err)
+		return
+	}
+	addr := p2p.NewNetAddressIPPort(ip, ourAddr.Port)
+	addr.ID = ourAddr.ID
+	if !addr.Routable() || addr.Equals(ourAddr) {
+		return
+	}
+	if !r.observedAddrs.add(src.ID(), ip, r.config.ObservedAddrReports) {
+		return
+	}
+	r.Logger.Info("Learned the external address from the peers", "addr", addr)
+	if err := r.Switch.SetExternalAddress(addr); err != nil {
+		r.Logger.Error("Failed to set the external address", "addr", addr, "err", err)
+	}
+}
+
 // SendAddrs sends addrs to the peer.
 func (r *Reactor) SendAddrs(p Peer, netAddrs []*p2p.NetAddress) {
 	e := p2p.Envelope{
@@ -497,6 +608,8 @@ func (r *Reactor) ensurePeers() {
 		return
 	}
 
+	r.discoverAddrs()
+
 	// bias to prefer more vetted peers when we have fewer connections.
 	// not perfect, but somewhate ensures that we prioritize connecting to more-vetted
 	// NOTE: range here is [10, 90]. Too high ?
@@ -646,6 +759,24 @@ func (r *Reactor) checkSeeds() (numOnline int, netAddrs []*p2p.NetAddress, err e
 	return numOnline, netAddrs, nil
 }
 
+// discoverAddrs adds the addresses discovered with the Discoveries to the
+// address book, each being its own source.
+func (r *Reactor) discoverAddrs() {
+	for _, d := range r.config.Discoveries {
+		ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+		addrs, errs := d.Discover(ctx)
+		cancel()
+		for _, err := range errs {
+			r.Logger.Error("Error discovering addresses", "err", err)
+		}
+		for _, addr := range addrs {
+			if err := r.book.AddAddress(addr, addr); err != nil {
+				r.logErrAddrBook(err)
+			}
+		}
+	}
+}
+
 // randomly dial seeds until we connect to one or exhaust them
 func (r *Reactor) dialSeeds() {
 	perm := tmrand.Perm(len(r.seedAddrs))
@@ -698,6 +829,7 @@ func (r *Reactor) crawlPeersRoutine() {
 		select {
 		case <-ticker.C:
 			r.attemptDisconnects()
+			r.discoverAddrs()
 			r.crawlPeers(r.book.GetSelection())
 			r.cleanupCrawlPeerInfos()
 		case <-r.Quit():
@@ -721,9 +853,27 @@ type crawlPeerInfo struct {
 	LastCrawled time.Time `json:"last_crawled"`
 }
 
+// CrawlStats returns the statistics of the network crawl, in seed mode.
+func (r *Reactor) CrawlStats() CrawlStats {
+	stats := r.crawlStats.get()
+	stats.AddrBookSize = r.book.Size()
+	return stats
+}
+
 // crawlPeers will crawl the network looking for new peer addresses.
 func (r *Reactor) crawlPeers(addrs []*p2p.NetAddress) {
 	now := time.Now()
+	var dials, dialFailures int64
+	defer func() {
+		crawledPeers := len(r.crawlPeerInfos)
+		r.crawlStats.update(func(stats *CrawlStats) {
+			stats.Rounds++
+			stats.LastRound = now
+			stats.CrawledPeers = crawledPeers
+			stats.Dials += dials
+			stats.DialFailures += dialFailures
+		})
+	}()
 
 	for _, addr := range addrs {
 		peerInfo, ok := r.crawlPeerInfos[addr.ID]
@@ -739,8 +889,10 @@ func (r *Reactor) crawlPeers(addrs []*p2p.NetAddress) {
 			LastCrawled: now,
 		}
 
+		dials++
 		err := r.dialPeer(addr)
 		if err != nil {
+			dialFailures++
 			switch err.(type) {
 			case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrCurrentlyDialingOrExistingAddress:
 				r.Logger.Debug(err.Error(), "addr", addr)
@@ -769,6 +921,8 @@ func (r *Reactor) cleanupCrawlPeerInfos() {
 			delete(r.crawlPeerInfos, id)
 		}
 	}
+	crawledPeers := len(r.crawlPeerInfos)
+	r.crawlStats.update(func(stats *CrawlStats) { stats.CrawledPeers = crawledPeers })
 }
 
 // attemptDisconnects checks if we've been with each peer long enough to disconnect
@@ -777,10 +931,11 @@ func (r *Reactor) attemptDisconnects() {
 		if peer.Status().Duration < r.config.SeedDisconnectWaitPeriod {
 			continue
 		}
-		if peer.IsPersistent() {
+		if peer.IsPersistent() || r.Switch.IsPeerUnconditional(peer.ID()) {
 			continue
 		}
 		r.Switch.StopPeerGracefully(peer)
+		r.crawlStats.update(func(stats *CrawlStats) { stats.Disconnects++ })
 	}
 }
 
@@ -18,6 +18,7 @@ import (
 	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/p2p/mock"
+	ocp2p "github.com/Finschia/ostracon/proto/ostracon/p2p"
 )
 
 var cfg *config.P2PConfig
@@ -138,6 +139,44 @@ func TestPEXReactorReceive(t *testing.T) {
 	r.ReceiveEnvelope(p2p.Envelope{ChannelID: PexChannel, Src: peer, Message: &tmp2p.PexRequest{}})
 }
 
+func TestPEXReactorLearnsExternalAddress(t *testing.T) {
+	r, book := createReactor(&ReactorConfig{ObservedAddrReports: 2})
+	defer teardownReactor(book)
+	sw := createSwitchAndAddReactors(r)
+	sw.SetAddrBook(book)
+	listenAddr := sw.NodeInfo().(p2p.DefaultNodeInfo).ListenAddr
+
+	receive := func(ip string, outbound bool) *mock.Peer {
+		peer := mock.NewPeer(nil)
+		peer.Outbound = outbound
+		r.ReceiveEnvelope(p2p.Envelope{
+			ChannelID: ObservedAddrChannel,
+			Src:       peer,
+			Message:   &ocp2p.ObservedAddr{Ip: ip},
+		})
+		return peer
+	}
+
+	// the private IPs and the reports of the inbound peers are ignored
+	receive("192.168.1.2", true)
+	receive("192.168.1.2", true)
+	receive("8.8.8.8", false)
+	outbound := receive("8.8.8.8", true)
+	assert.Equal(t, listenAddr, sw.NodeInfo().(p2p.DefaultNodeInfo).ListenAddr)
+
+	// the reports of the peers removed aren't counted
+	r.RemovePeer(outbound, nil)
+	receive("8.8.8.8", true)
+	assert.Equal(t, listenAddr, sw.NodeInfo().(p2p.DefaultNodeInfo).ListenAddr)
+
+	receive("8.8.8.8", true)
+	ourAddr, err := sw.NodeInfo().NetAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.8.8", ourAddr.IP.String())
+	assert.Equal(t, sw.NetAddress().Port, ourAddr.Port)
+	assert.True(t, book.OurAddress(ourAddr))
+}
+
 func TestPEXReactorRequestMessageAbuse(t *testing.T) {
 	r, book := createReactor(&ReactorConfig{})
 	defer teardownReactor(book)
@@ -322,6 +361,15 @@ func TestPEXReactorSeedMode(t *testing.T) {
 	assert.Equal(t, 1, sw.Peers().Size())
 	assert.True(t, sw.Peers().Has(peerSwitch.NodeInfo().ID()))
 
+	// the peer isn't crawled again soon after
+	pexR.crawlPeers([]*p2p.NetAddress{peerSwitch.NetAddress()})
+	stats := pexR.CrawlStats()
+	assert.GreaterOrEqual(t, stats.Rounds, int64(2)) // with the initial crawl on start
+	assert.False(t, stats.LastRound.IsZero())
+	assert.Equal(t, 1, stats.CrawledPeers)
+	assert.EqualValues(t, 1, stats.Dials)
+	assert.Zero(t, stats.DialFailures)
+
 	// 2. attemptDisconnects should not disconnect because of wait period
 	pexR.attemptDisconnects()
 	assert.Equal(t, 1, sw.Peers().Size())
@@ -332,6 +380,7 @@ func TestPEXReactorSeedMode(t *testing.T) {
 	// 3. attemptDisconnects should disconnect after wait period
 	pexR.attemptDisconnects()
 	assert.Equal(t, 0, sw.Peers().Size())
+	assert.EqualValues(t, 1, pexR.CrawlStats().Disconnects)
 }
 
 func TestPEXReactorDoesNotDisconnectFromPersistentPeerInSeedMode(t *testing.T) {
@@ -372,6 +421,40 @@ func TestPEXReactorDoesNotDisconnectFromPersistentPeerInSeedMode(t *testing.T) {
 	assert.Equal(t, 1, sw.Peers().Size())
 }
 
+func TestPEXReactorDoesNotDisconnectFromUnconditionalPeerInSeedMode(t *testing.T) {
+	// directory to store address books
+	dir, err := os.MkdirTemp("", "pex_reactor")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	pexRConfig := &ReactorConfig{SeedMode: true, SeedDisconnectWaitPeriod: 1 * time.Millisecond}
+	pexR, book := createReactor(pexRConfig)
+	defer teardownReactor(book)
+
+	sw := createSwitchAndAddReactors(pexR)
+	sw.SetAddrBook(book)
+	err = sw.Start()
+	require.NoError(t, err)
+	defer sw.Stop() // nolint:errcheck // ignore for tests
+
+	peerSwitch := testCreateDefaultPeer(dir, 1)
+	require.NoError(t, peerSwitch.Start())
+	defer peerSwitch.Stop() // nolint:errcheck // ignore for tests
+
+	err = sw.AddUnconditionalPeerIDs([]string{string(peerSwitch.NodeInfo().ID())})
+	require.NoError(t, err)
+
+	pexR.crawlPeers([]*p2p.NetAddress{peerSwitch.NetAddress()})
+	assert.Equal(t, 1, sw.Peers().Size())
+
+	// sleep for SeedDisconnectWaitPeriod
+	time.Sleep(pexRConfig.SeedDisconnectWaitPeriod + 1*time.Millisecond)
+
+	// attemptDisconnects should not disconnect because the peer is unconditional
+	pexR.attemptDisconnects()
+	assert.Equal(t, 1, sw.Peers().Size())
+}
+
 func TestPEXReactorDialsPeerUpToMaxAttemptsInSeedMode(t *testing.T) {
 	// directory to store address books
 	dir, err := os.MkdirTemp("", "pex_reactor")
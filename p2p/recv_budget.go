@@ -0,0 +1,65 @@
+package p2p
+
+import (
+	"github.com/go-kit/kit/metrics"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// recvBudget caps the bytes of the messages received from all the peers which
+// are queued for the reactors or being handled by them. The receive routines of
+// the peers block once it is spent, so that the peers can't make the node
+// buffer more than the reactors can handle, however many they are.
+type recvBudget struct {
+	mtx   tmsync.Mutex
+	size  int64
+	avail int64
+	// closed, and replaced, when bytes are released
+	freed chan struct{}
+
+	pending metrics.Gauge
+}
+
+func newRecvBudget(size int64, pending metrics.Gauge) *recvBudget {
+	return &recvBudget{
+		size:    size,
+		avail:   size,
+		freed:   make(chan struct{}),
+		pending: pending,
+	}
+}
+
+// acquire blocks until n bytes are available, or quit is closed, returning
+// false then. A message bigger than the budget waits for the whole budget.
+// The returned function releases the bytes, once the message is handled.
+func (b *recvBudget) acquire(n int64, quit <-chan struct{}) (func(), bool) {
+	if n > b.size {
+		n = b.size
+	}
+	for {
+		b.mtx.Lock()
+		if b.avail >= n {
+			b.avail -= n
+			b.pending.Set(float64(b.size - b.avail))
+			b.mtx.Unlock()
+			return func() { b.release(n) }, true
+		}
+		freed := b.freed
+		b.mtx.Unlock()
+
+		select {
+		case <-freed:
+		case <-quit:
+			return nil, false
+		}
+	}
+}
+
+func (b *recvBudget) release(n int64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.avail += n
+	b.pending.Set(float64(b.size - b.avail))
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
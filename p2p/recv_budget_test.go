@@ -0,0 +1,77 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecvBudget(t *testing.T) {
+	b := newRecvBudget(100, discard.NewGauge())
+	quit := make(chan struct{})
+
+	release1, ok := b.acquire(60, quit)
+	require.True(t, ok)
+
+	// the bytes over the budget wait for the ones released
+	acquired := make(chan func())
+	go func() {
+		release, ok := b.acquire(60, quit)
+		if ok {
+			acquired <- release
+		}
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired bytes over the budget")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release1()
+	var release2 func()
+	select {
+	case release2 = <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("released bytes not acquired")
+	}
+
+	// a message bigger than the budget waits for the whole budget
+	go func() {
+		release, ok := b.acquire(1000, quit)
+		if ok {
+			acquired <- release
+		}
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired the whole budget while in use")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release2()
+	select {
+	case release := <-acquired:
+		assert.EqualValues(t, 0, b.avail)
+		release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("whole budget not acquired")
+	}
+	assert.EqualValues(t, 100, b.avail)
+
+	// the waits stop on quit
+	_, ok = b.acquire(100, quit)
+	require.True(t, ok)
+	done := make(chan bool)
+	go func() {
+		_, ok := b.acquire(1, quit)
+		done <- ok
+	}()
+	close(quit)
+	select {
+	case ok := <-done:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire didn't stop on quit")
+	}
+}
@@ -1,17 +1,22 @@
 package p2p
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Finschia/ostracon/config"
 	"github.com/Finschia/ostracon/libs/cmap"
+	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/libs/rand"
 	"github.com/Finschia/ostracon/libs/service"
+	"github.com/Finschia/ostracon/libs/tracing"
 	"github.com/Finschia/ostracon/p2p/conn"
 )
 
@@ -29,6 +34,10 @@ const (
 	// ie. 3**10 = 16hrs
 	reconnectBackOffAttempts    = 10
 	reconnectBackOffBaseSeconds = 3
+
+	// the same messages are logged at most once per peerLogInterval for a
+	// peer, or for the inbound connections, so that they can't flood the logs
+	peerLogInterval = time.Second
 )
 
 // MConnConfig returns an MConnConfig with fields updated
@@ -38,8 +47,11 @@ func MConnConfig(cfg *config.P2PConfig) conn.MConnConfig {
 	mConfig.FlushThrottle = cfg.FlushThrottleTimeout
 	mConfig.SendRate = cfg.SendRate
 	mConfig.RecvRate = cfg.RecvRate
+	mConfig.RecvMessageRate = cfg.RecvMessageRate
+	mConfig.RecvMessageBurst = cfg.RecvMessageBurst
 	mConfig.MaxPacketMsgPayloadSize = cfg.MaxPacketMsgPayloadSize
 	mConfig.RecvAsync = cfg.RecvAsync
+	mConfig.Compression = cfg.Compression
 	return mConfig
 }
 
@@ -79,6 +91,7 @@ type Switch struct {
 	peers         *PeerSet
 	dialing       *cmap.CMap
 	reconnecting  *cmap.CMap
+	nodeInfoMtx   sync.RWMutex
 	nodeInfo      NodeInfo // our node info
 	nodeKey       *NodeKey // our node privkey
 	addrBook      AddrBook
@@ -93,8 +106,16 @@ type Switch struct {
 
 	rng *rand.Rand // seed for randomizing dial times and orders
 
+	scores *peerScores
+
+	// caps the bytes of the messages received from all the peers, nil if
+	// unlimited (see P2PConfig.MaxPendingRecvBytes)
+	recvBudget *recvBudget
+
 	metrics *Metrics
 	mlc     *metricsLabelCache
+
+	peerLogger log.Logger // samples the messages logged for the peers
 }
 
 // NetAddress returns the address the switch is listening on.
@@ -128,17 +149,23 @@ func NewSwitch(
 		persistentPeersAddrs: make([]*NetAddress, 0),
 		unconditionalPeerIDs: make(map[ID]struct{}),
 		mlc:                  newMetricsLabelCache(),
+		scores:               newPeerScores(cfg),
 	}
 
 	// Ensure we have a completely undeterministic PRNG.
 	sw.rng = rand.NewRand()
 
 	sw.BaseService = *service.NewBaseService(nil, "P2P Switch", sw)
+	sw.peerLogger = log.NewSampledLogger(sw.Logger, peerLogInterval, "peer")
 
 	for _, option := range options {
 		option(sw)
 	}
 
+	if cfg.MaxPendingRecvBytes > 0 {
+		sw.recvBudget = newRecvBudget(cfg.MaxPendingRecvBytes, sw.metrics.PendingRecvBytes)
+	}
+
 	return sw
 }
 
@@ -209,17 +236,27 @@ func (sw *Switch) Reactor(name string) Reactor {
 }
 
 // SetNodeInfo sets the switch's NodeInfo for checking compatibility and handshaking with other nodes.
-// NOTE: Not goroutine safe.
 func (sw *Switch) SetNodeInfo(nodeInfo NodeInfo) {
+	sw.nodeInfoMtx.Lock()
+	defer sw.nodeInfoMtx.Unlock()
 	sw.nodeInfo = nodeInfo
 }
 
 // NodeInfo returns the switch's NodeInfo.
-// NOTE: Not goroutine safe.
 func (sw *Switch) NodeInfo() NodeInfo {
+	sw.nodeInfoMtx.RLock()
+	defer sw.nodeInfoMtx.RUnlock()
 	return sw.nodeInfo
 }
 
+// SetLogger implements service.Service, and sets the logger sampling the
+// messages logged for the peers.
+// NOTE: Not goroutine safe.
+func (sw *Switch) SetLogger(l log.Logger) {
+	sw.BaseService.SetLogger(l)
+	sw.peerLogger = log.NewSampledLogger(l, peerLogInterval, "peer")
+}
+
 // SetNodeKey sets the switch's private key for authenticated encryption.
 // NOTE: Not goroutine safe.
 func (sw *Switch) SetNodeKey(nodeKey *NodeKey) {
@@ -249,12 +286,17 @@ func (sw *Switch) OnStart() error {
 func (sw *Switch) OnStop() {
 	// Stop peers
 	for _, p := range sw.peers.List() {
-		sw.stopAndRemovePeer(p, nil)
+		sw.Logger.Debug("Stopping peer", "peer", p, "reason", ErrSwitchStopped{})
+		sw.stopAndRemovePeer(p, ErrSwitchStopped{})
 	}
 
 	// Stop reactors
 	sw.Logger.Debug("Switch: Stopping reactors")
 	for _, reactor := range sw.reactors {
+		// the node may have stopped some reactors before, to stop them in order
+		if !reactor.IsRunning() {
+			continue
+		}
 		if err := reactor.Stop(); err != nil {
 			sw.Logger.Error("error while stopped reactor", "reactor", reactor, "error", err)
 		}
@@ -278,6 +320,7 @@ func (sw *Switch) BroadcastEnvelope(e Envelope) chan bool {
 	var wg sync.WaitGroup
 	wg.Add(len(peers))
 	successChan := make(chan bool, len(peers))
+	span := sw.startBroadcastSpan(e, len(peers))
 
 	for _, peer := range peers {
 		go func(p Peer) {
@@ -289,12 +332,37 @@ func (sw *Switch) BroadcastEnvelope(e Envelope) chan bool {
 
 	go func() {
 		wg.Wait()
+		span.End()
 		close(successChan)
 	}()
 
 	return successChan
 }
 
+// startBroadcastSpan starts the span of the broadcast of the message to the
+// peers, in the trace of the message (see tracing.MessageContext), or returns
+// a span doing nothing if the message isn't traced.
+func (sw *Switch) startBroadcastSpan(e Envelope, numPeers int) trace.Span {
+	if !tracing.MessagesTraced() {
+		return trace.SpanFromContext(context.Background())
+	}
+	msg, err := wrapMessage(sw.msgTypeByChID, e.ChannelID, e.Message)
+	if err != nil {
+		return trace.SpanFromContext(context.Background())
+	}
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return trace.SpanFromContext(context.Background())
+	}
+	_, span := tracing.StartMessageSpan(tracing.MessageContext(e.ChannelID, msgBytes), "p2p.broadcast", trace.WithAttributes(
+		attribute.String("channel", fmt.Sprintf("%#x", e.ChannelID)),
+		attribute.String("message_type", sw.mlc.ValueToMetricLabel(e.Message)),
+		attribute.Int("bytes", len(msgBytes)),
+		attribute.Int("peers", numPeers),
+	))
+	return span
+}
+
 // Broadcast runs a go routine for each attempted send, which will block trying
 // to send for defaultSendTimeoutSeconds. Returns a channel which receives
 // success values for each attempted send (false if times out). Channel will be
@@ -371,8 +439,10 @@ func (sw *Switch) StopPeerForError(peer Peer, reason interface{}) {
 		return
 	}
 
-	sw.Logger.Error("Stopping peer for error", "peer", peer, "err", reason)
+	sw.peerLogger.Error("Stopping peer for error", "peer", peer, "err", reason)
 	sw.stopAndRemovePeer(peer, reason)
+	// the peers disconnected repeatedly are banned
+	sw.scorePeer(peer.ID(), peer, Behavior{kind: behaviorDisconnection, reason: fmt.Sprint(reason)})
 
 	if peer.IsPersistent() {
 		var addr *NetAddress
@@ -491,6 +561,66 @@ func (sw *Switch) MarkPeerAsGood(peer Peer) {
 	}
 }
 
+// ReportPeerBehavior reports a behavior of the peer, e.g. an invalid message,
+// changing its score, rather than punishing it right away: the peer is
+// disconnected when its score falls to p2p.peer_disconnect_score, and banned
+// for p2p.peer_ban_duration when it falls to p2p.peer_ban_score. The scores
+// decay to 0 over time, and the useful messages also mark the peer as good.
+//
+// The persistent and unconditional peers are never disconnected nor banned
+// for their score.
+func (sw *Switch) ReportPeerBehavior(peerID ID, behavior Behavior) {
+	peer := sw.peers.Get(peerID)
+	if behavior.kind == behaviorUsefulMessage && peer != nil {
+		sw.MarkPeerAsGood(peer)
+	}
+	disconnect := sw.scorePeer(peerID, peer, behavior)
+	if disconnect && peer != nil {
+		sw.peerLogger.Error("Stopping peer for its score", "peer", peer, "behavior", behavior)
+		sw.stopAndRemovePeer(peer, fmt.Errorf("score too low after %v", behavior))
+	}
+}
+
+// reportInvalidMessage reports the peer for a message failing the validation
+// of its reactor, see MessageValidator.
+func (sw *Switch) reportInvalidMessage(peer Peer, err error) {
+	sw.peerLogger.Debug("Dropped invalid message", "peer", peer, "err", err)
+	sw.ReportPeerBehavior(peer.ID(), InvalidMessage(err.Error()))
+}
+
+// scorePeer changes the score of the peer (nil if not connected) for the
+// behavior, banning it if its score is too low, and returns whether it's to
+// be disconnected.
+func (sw *Switch) scorePeer(peerID ID, peer Peer, behavior Behavior) bool {
+	if sw.IsPeerUnconditional(peerID) || (peer != nil && peer.IsPersistent()) {
+		return false
+	}
+	score, disconnect, ban := sw.scores.add(peerID, behavior, time.Now())
+	sw.peerLogger.Debug("Scored peer", "peer", peerID, "behavior", behavior, "score", score)
+	if ban {
+		sw.Logger.Info("Banning peer for its score", "peer", peerID, "behavior", behavior,
+			"duration", sw.config.PeerBanDuration)
+		// not to be dialed again by the PEX reactor
+		if peer != nil && sw.addrBook != nil {
+			if addr, err := peer.NodeInfo().NetAddress(); err == nil {
+				sw.addrBook.RemoveAddress(addr)
+			}
+		}
+	}
+	return disconnect
+}
+
+// PeerScore returns the score of the peer, see ReportPeerBehavior.
+func (sw *Switch) PeerScore(peerID ID) float64 {
+	return sw.scores.score(peerID, time.Now())
+}
+
+// IsPeerBanned reports whether the peer is banned for its score, see
+// ReportPeerBehavior.
+func (sw *Switch) IsPeerBanned(peerID ID) bool {
+	return sw.scores.isBanned(peerID, time.Now())
+}
+
 //---------------------------------------------------------------------
 // Dialing
 
@@ -521,7 +651,7 @@ func (sw *Switch) DialPeersAsync(peers []string) error {
 		}
 		return err
 	}
-	sw.dialPeersAsync(netAddrs)
+	sw.dialPeersAsync(MergeNetAddresses(netAddrs))
 	return nil
 }
 
@@ -586,6 +716,9 @@ func (sw *Switch) DialPeerWithAddress(addr *NetAddress) error {
 	if sw.IsDialingOrExistingAddress(addr) {
 		return ErrCurrentlyDialingOrExistingAddress{addr.String()}
 	}
+	if sw.IsPeerBanned(addr.ID) {
+		return ErrPeerBanned{addr.ID}
+	}
 
 	sw.dialing.Set(string(addr.ID), addr)
 	defer sw.dialing.Delete(string(addr.ID))
@@ -607,9 +740,56 @@ func (sw *Switch) IsDialingOrExistingAddress(addr *NetAddress) bool {
 		(!sw.config.AllowDuplicateIP && sw.peers.HasIP(addr.IP))
 }
 
+// rateSetter is implemented by the transports and the peers whose send and
+// receive rates can be changed while running.
+type rateSetter interface {
+	SetRates(sendRate, recvRate int64)
+}
+
+// SetRates changes the send and receive rates of the connections to the
+// peers (see P2PConfig.SendRate and RecvRate), including the ones already
+// connected if the transport supports it.
+func (sw *Switch) SetRates(sendRate, recvRate int64) {
+	if t, ok := sw.transport.(rateSetter); ok {
+		t.SetRates(sendRate, recvRate)
+	}
+	for _, peer := range sw.peers.List() {
+		if p, ok := peer.(rateSetter); ok {
+			p.SetRates(sendRate, recvRate)
+		}
+	}
+}
+
+// listenAddrSetter is implemented by the transports whose address advertised
+// in the handshakes can be changed while running.
+type listenAddrSetter interface {
+	SetListenAddr(addr string) error
+}
+
+// SetExternalAddress changes the address advertised to the peers connected
+// after it, e.g. once the external address of the node is learned, and adds
+// it to the addresses of the node in the address book.
+func (sw *Switch) SetExternalAddress(addr *NetAddress) error {
+	if t, ok := sw.transport.(listenAddrSetter); ok {
+		if err := t.SetListenAddr(addr.DialString()); err != nil {
+			return err
+		}
+	}
+	sw.nodeInfoMtx.Lock()
+	if ni, ok := sw.nodeInfo.(DefaultNodeInfo); ok {
+		ni.ListenAddr = addr.DialString()
+		sw.nodeInfo = ni
+	}
+	sw.nodeInfoMtx.Unlock()
+	if sw.addrBook != nil {
+		sw.addrBook.AddOurAddress(addr)
+	}
+	return nil
+}
+
 // AddPersistentPeers allows you to set persistent peers. It ignores
 // ErrNetAddressLookup. However, if there are other errors, first encounter is
-// returned.
+// returned. The addresses of the same peer are dialed in their order.
 func (sw *Switch) AddPersistentPeers(addrs []string) error {
 	sw.Logger.Info("Adding persistent peers", "addrs", addrs)
 	netAddrs, errs := NewNetAddressStrings(addrs)
@@ -624,7 +804,7 @@ func (sw *Switch) AddPersistentPeers(addrs []string) error {
 		}
 		return err
 	}
-	sw.persistentPeersAddrs = netAddrs
+	sw.persistentPeersAddrs = MergeNetAddresses(netAddrs)
 	return nil
 }
 
@@ -660,6 +840,11 @@ func (sw *Switch) IsPeerPersistent(na *NetAddress) bool {
 		if pa.Equals(na) {
 			return true
 		}
+		for _, alt := range pa.Alternates {
+			if alt.Equals(na) {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -669,11 +854,13 @@ func (sw *Switch) acceptRoutine() {
 		p, err := sw.transport.Accept(peerConfig{
 			chDescs:       sw.chDescs,
 			onPeerError:   sw.StopPeerForError,
+			onInvalidMsg:  sw.reportInvalidMessage,
 			reactorsByCh:  sw.reactorsByCh,
 			msgTypeByChID: sw.msgTypeByChID,
 			metrics:       sw.metrics,
 			mlc:           sw.mlc,
 			isPersistent:  sw.IsPeerPersistent,
+			recvBudget:    sw.recvBudget,
 		})
 		if err != nil {
 			switch err := err.(type) {
@@ -686,7 +873,7 @@ func (sw *Switch) acceptRoutine() {
 					sw.addrBook.AddOurAddress(&addr)
 				}
 
-				sw.Logger.Info(
+				sw.peerLogger.Info(
 					"Inbound Peer rejected",
 					"err", err,
 					"numPeers", sw.peers.Size(),
@@ -694,7 +881,7 @@ func (sw *Switch) acceptRoutine() {
 
 				continue
 			case ErrFilterTimeout:
-				sw.Logger.Error(
+				sw.peerLogger.Error(
 					"Peer filter timed out",
 					"err", err,
 				)
@@ -726,7 +913,7 @@ func (sw *Switch) acceptRoutine() {
 			// Ignore connection if we already have enough peers.
 			_, in, _ := sw.NumPeers()
 			if in >= sw.config.MaxNumInboundPeers {
-				sw.Logger.Info(
+				sw.peerLogger.Info(
 					"Ignoring inbound connection: already have enough inbound peers",
 					"address", p.SocketAddr(),
 					"have", in,
@@ -745,7 +932,7 @@ func (sw *Switch) acceptRoutine() {
 			if p.IsRunning() {
 				_ = p.Stop()
 			}
-			sw.Logger.Info(
+			sw.peerLogger.Info(
 				"Ignoring inbound connection: error while adding peer",
 				"err", err,
 				"id", p.ID(),
@@ -774,11 +961,13 @@ func (sw *Switch) addOutboundPeerWithConfig(
 	p, err := sw.transport.Dial(*addr, peerConfig{
 		chDescs:       sw.chDescs,
 		onPeerError:   sw.StopPeerForError,
+		onInvalidMsg:  sw.reportInvalidMessage,
 		isPersistent:  sw.IsPeerPersistent,
 		reactorsByCh:  sw.reactorsByCh,
 		msgTypeByChID: sw.msgTypeByChID,
 		metrics:       sw.metrics,
 		mlc:           sw.mlc,
+		recvBudget:    sw.recvBudget,
 	})
 	if err != nil {
 		if e, ok := err.(ErrRejected); ok {
@@ -817,6 +1006,9 @@ func (sw *Switch) filterPeer(p Peer) error {
 	if sw.peers.Has(p.ID()) {
 		return ErrRejected{id: p.ID(), isDuplicate: true}
 	}
+	if sw.IsPeerBanned(p.ID()) {
+		return ErrRejected{id: p.ID(), err: ErrPeerBanned{p.ID()}, isFiltered: true}
+	}
 
 	errc := make(chan error, len(sw.peerFilters))
 
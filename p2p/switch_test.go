@@ -14,9 +14,13 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	p2pproto "github.com/tendermint/tendermint/proto/tendermint/p2p"
 
@@ -24,7 +28,9 @@ import (
 	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/libs/log"
 	tmnet "github.com/Finschia/ostracon/libs/net"
+	"github.com/Finschia/ostracon/libs/protoany"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tracing"
 	"github.com/Finschia/ostracon/p2p/conn"
 )
 
@@ -192,6 +198,84 @@ func TestSwitches(t *testing.T) {
 		s2.Reactor("bar").(*TestReactor), 200*time.Millisecond, 5*time.Second)
 }
 
+// validatingReactor is a TestReactor rejecting the PexAddrs without addresses.
+type validatingReactor struct {
+	*TestReactor
+}
+
+func (vr validatingReactor) ValidateMessage(chID byte, msg proto.Message) error {
+	if m, ok := msg.(*p2pproto.PexAddrs); ok && len(m.Addrs) == 0 {
+		return errors.New("no addresses")
+	}
+	return nil
+}
+
+func TestSwitchValidatesMessages(t *testing.T) {
+	s1, s2 := MakeSwitchPair(t, func(i int, sw *Switch, config *config.P2PConfig) *Switch {
+		sw.AddReactor("foo", validatingReactor{NewTestReactor([]*conn.ChannelDescriptor{
+			{ID: byte(0x00), Priority: 10, MessageType: &p2pproto.Message{}},
+		}, config.RecvAsync, 1000, true)})
+		return sw
+	})
+	t.Cleanup(func() {
+		if err := s1.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Cleanup(func() {
+		if err := s2.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	reactor := s2.Reactor("foo").(validatingReactor).TestReactor
+
+	validMsg := &p2pproto.PexAddrs{Addrs: []p2pproto.NetAddress{{ID: "1"}}}
+	s1.BroadcastEnvelope(Envelope{ChannelID: byte(0x00), Message: validMsg})
+	assertMsgReceivedWithTimeout(t, validMsg, byte(0x00), reactor, 200*time.Millisecond, 5*time.Second)
+
+	// the invalid message is dropped, and the peer reported for it
+	s1.BroadcastEnvelope(Envelope{ChannelID: byte(0x00), Message: &p2pproto.PexAddrs{}})
+	assertNoPeersAfterTimeout(t, s2, 500*time.Millisecond)
+	assert.Len(t, reactor.getMsgs(byte(0x00)), 1)
+	assert.Less(t, s2.PeerScore(s1.NetAddress().ID), 0.0)
+}
+
+func TestSwitchRecvBudget(t *testing.T) {
+	s1, s2 := MakeSwitchPair(t, func(i int, sw *Switch, config *config.P2PConfig) *Switch {
+		sw = initSwitchFunc(i, sw, config)
+		// a couple of messages at once
+		sw.recvBudget = newRecvBudget(64, sw.metrics.PendingRecvBytes)
+		return sw
+	})
+	t.Cleanup(func() {
+		if err := s1.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Cleanup(func() {
+		if err := s2.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	reactor := s2.Reactor("foo").(*TestReactor)
+
+	// the messages over the budget are received once the ones before are
+	// handled, releasing their bytes
+	const n = 50
+	for i := 0; i < n; i++ {
+		msg := &p2pproto.PexAddrs{Addrs: []p2pproto.NetAddress{{ID: fmt.Sprintf("%d", i)}}}
+		s1.BroadcastEnvelope(Envelope{ChannelID: byte(0x00), Message: msg})
+	}
+	assert.Eventually(t, func() bool {
+		return len(reactor.getMsgs(byte(0x00))) == n
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		s2.recvBudget.mtx.Lock()
+		defer s2.recvBudget.mtx.Unlock()
+		return s2.recvBudget.avail == 64
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func assertMsgReceivedWithTimeout(
 	t *testing.T,
 	msg proto.Message,
@@ -222,6 +306,48 @@ func assertMsgReceivedWithTimeout(
 	}
 }
 
+func TestSwitchTracesMessages(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	tracing.SetMessageSampleRate(1)
+	t.Cleanup(func() {
+		tracing.SetMessageSampleRate(0)
+		otel.SetTracerProvider(prev)
+	})
+
+	s1, s2 := MakeSwitchPair(t, initSwitchFunc)
+	t.Cleanup(func() {
+		if err := s1.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Cleanup(func() {
+		if err := s2.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	msg := &p2pproto.PexAddrs{Addrs: []p2pproto.NetAddress{{ID: "1"}}}
+	s1.BroadcastEnvelope(Envelope{ChannelID: byte(0x00), Message: msg})
+	require.Eventually(t, func() bool {
+		return len(s2.Reactor("foo").(*TestReactor).getMsgs(byte(0x00))) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the spans of the message on both switches are in the same trace
+	expected := []string{"p2p.broadcast", "p2p.send", "mconn.write", "mconn.read", "p2p.receive"}
+	require.Eventually(t, func() bool {
+		return len(recorder.Ended()) >= len(expected)
+	}, 5*time.Second, 10*time.Millisecond)
+	spans := recorder.Ended()
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+		assert.Equal(t, spans[0].SpanContext().TraceID(), span.SpanContext().TraceID(), span.Name())
+	}
+	assert.Subset(t, names, expected)
+}
+
 func TestSwitchFiltersOutItself(t *testing.T) {
 	s1 := MakeSwitch(cfg, 1, "127.0.0.1", "123.123.123", initSwitchFunc)
 
@@ -621,6 +747,53 @@ func TestSwitchFullConnectivity(t *testing.T) {
 	}
 }
 
+func TestSwitchSetRates(t *testing.T) {
+	switches := MakeConnectedSwitches(cfg, 2, initSwitchFunc, Connect2Switches)
+	for _, sw := range switches {
+		sw := sw
+		t.Cleanup(func() {
+			if err := sw.Stop(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	sw := switches[0]
+	sw.SetRates(1000, 2000)
+	// the peers added later use the new rates, see TestMConnectionSetRates
+	// for the connected ones
+	require.Equal(t, 1, sw.Peers().Size())
+	mConfig := sw.transport.(*MultiplexTransport).mConfig
+	assert.EqualValues(t, 1000, mConfig.SendRate)
+	assert.EqualValues(t, 2000, mConfig.RecvRate)
+}
+
+func TestSwitchSetExternalAddress(t *testing.T) {
+	switches := MakeConnectedSwitches(cfg, 2, initSwitchFunc, func([]*Switch, int, int) {})
+	for _, sw := range switches {
+		sw := sw
+		t.Cleanup(func() {
+			if err := sw.Stop(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	sw := switches[0]
+	addr := NewNetAddressIPPort(net.ParseIP("8.8.8.8"), 26656)
+	addr.ID = sw.NodeInfo().ID()
+	require.NoError(t, sw.SetExternalAddress(addr))
+	assert.Equal(t, "8.8.8.8:26656", sw.transport.(*MultiplexTransport).getNodeInfo().(DefaultNodeInfo).ListenAddr)
+
+	// the peers connected after it are advertised the address
+	Connect2Switches(switches, 0, 1)
+	peer := switches[1].Peers().Get(sw.NodeInfo().ID())
+	require.NotNil(t, peer)
+	peerAddr, err := peer.NodeInfo().NetAddress()
+	require.NoError(t, err)
+	assert.Equal(t, addr, peerAddr)
+}
+
 func TestSwitchAcceptRoutine(t *testing.T) {
 	cfg.MaxNumInboundPeers = 5
 
@@ -1090,3 +1263,45 @@ func getInitSwitchFunc(bufSize int) func(int, *Switch, *config.P2PConfig) *Switc
 		return sw
 	}
 }
+
+func TestSwitchAnyChannel(t *testing.T) {
+	for _, msg := range []proto.Message{&gogotypes.StringValue{}, &gogotypes.Int64Value{}} {
+		if !protoany.IsRegistered(msg) {
+			protoany.Register(msg)
+		}
+	}
+
+	s1, s2 := MakeSwitchPair(t, func(i int, sw *Switch, config *config.P2PConfig) *Switch {
+		sw.AddReactor("foo", NewTestReactor([]*conn.ChannelDescriptor{
+			{ID: byte(0x00), Priority: 10, MessageType: &gogotypes.Any{}},
+		}, config.RecvAsync, 1000, true))
+		return sw
+	})
+	t.Cleanup(func() {
+		if err := s1.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Cleanup(func() {
+		if err := s2.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// the registered messages are packed into Any and unpacked on reception (sent
+	// to the peer in order, unlike BroadcastEnvelope)
+	peer := s1.Peers().List()[0]
+	for _, msg := range []proto.Message{&gogotypes.StringValue{Value: "foo"}, &gogotypes.Int64Value{Value: 42}} {
+		require.True(t, SendEnvelopeShim(peer, Envelope{ChannelID: byte(0x00), Message: msg}, s1.Logger))
+	}
+	reactor := s2.Reactor("foo").(*TestReactor)
+	require.Eventually(t, func() bool { return len(reactor.getMsgs(byte(0x00))) == 2 }, 5*time.Second, 10*time.Millisecond)
+	msgs := reactor.getMsgs(byte(0x00))
+	assert.Equal(t, &gogotypes.StringValue{Value: "foo"}, msgs[0].Contents)
+	assert.Equal(t, &gogotypes.Int64Value{Value: 42}, msgs[1].Contents)
+
+	// the unregistered ones aren't sent
+	for _, peer := range s1.Peers().List() {
+		assert.False(t, SendEnvelopeShim(peer, Envelope{ChannelID: byte(0x00), Message: &gogotypes.BoolValue{Value: true}}, s1.Logger))
+	}
+}
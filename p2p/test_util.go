@@ -140,7 +140,7 @@ func (sw *Switch) addPeerWithConnection(conn net.Conn) error {
 		return err
 	}
 
-	ni, err := handshake(conn, time.Second, sw.nodeInfo)
+	ni, err := handshake(conn, time.Second, sw.NodeInfo())
 	if err != nil {
 		if err := conn.Close(); err != nil {
 			sw.Logger.Error("Error closing connection", "err", err)
@@ -157,6 +157,8 @@ func (sw *Switch) addPeerWithConnection(conn net.Conn) error {
 		sw.chDescs,
 		sw.StopPeerForError,
 		sw.mlc,
+		PeerOnInvalidMessage(sw.reportInvalidMessage),
+		peerRecvBudget(sw.recvBudget),
 	)
 
 	if err = sw.addPeer(p); err != nil {
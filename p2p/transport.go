@@ -13,7 +13,9 @@ import (
 
 	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/libs/protoio"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
 	"github.com/Finschia/ostracon/p2p/conn"
+	ocp2p "github.com/Finschia/ostracon/proto/ostracon/p2p"
 )
 
 const (
@@ -33,6 +35,7 @@ type accept struct {
 	netAddr  *NetAddress
 	conn     net.Conn
 	nodeInfo NodeInfo
+	ext      *ocp2p.NodeInfoExtension
 	err      error
 }
 
@@ -45,7 +48,10 @@ type accept struct {
 type peerConfig struct {
 	chDescs     []*conn.ChannelDescriptor
 	onPeerError func(Peer, interface{})
-	outbound    bool
+	// called with the messages failing the validation of their reactor, see
+	// MessageValidator
+	onInvalidMsg func(Peer, error)
+	outbound     bool
 	// isPersistent allows you to set a function, which, given socket address
 	// (for outbound peers) OR self-reported address (for inbound peers), tells
 	// if the peer is persistent or not.
@@ -54,6 +60,9 @@ type peerConfig struct {
 	msgTypeByChID map[byte]proto.Message
 	metrics       *Metrics
 	mlc           *metricsLabelCache
+	// caps the bytes of the messages received from all the peers, nil if
+	// unlimited
+	recvBudget *recvBudget
 }
 
 // Transport emits and connects to Peers. The implementation of Peer is left to
@@ -138,7 +147,7 @@ func MultiplexTransportMaxIncomingConnections(n int) MultiplexTransportOption {
 // multiplexed peers.
 type MultiplexTransport struct {
 	netAddr                NetAddress
-	listener               net.Listener
+	listeners              []net.Listener
 	maxIncomingConnections int // see MaxIncomingConnections
 
 	acceptc chan accept
@@ -151,6 +160,7 @@ type MultiplexTransport struct {
 	dialTimeout      time.Duration
 	filterTimeout    time.Duration
 	handshakeTimeout time.Duration
+	nodeInfoMtx      tmsync.RWMutex
 	nodeInfo         NodeInfo
 	nodeKey          NodeKey
 	resolver         IPResolver
@@ -158,7 +168,8 @@ type MultiplexTransport struct {
 	// TODO(xla): This config is still needed as we parameterise peerConn and
 	// peer currently. All relevant configuration should be refactored into options
 	// with sane defaults.
-	mConfig conn.MConnConfig
+	mConfigMtx tmsync.Mutex
+	mConfig    conn.MConnConfig
 }
 
 // Test multiplexTransport for interface completeness.
@@ -185,7 +196,16 @@ func NewMultiplexTransport(
 	}
 }
 
-// NetAddress implements Transport.
+// SetRates changes the send and receive rates of the connections to the
+// peers added after it.
+func (mt *MultiplexTransport) SetRates(sendRate, recvRate int64) {
+	mt.mConfigMtx.Lock()
+	defer mt.mConfigMtx.Unlock()
+	mt.mConfig.SendRate = sendRate
+	mt.mConfig.RecvRate = recvRate
+}
+
+// NetAddress implements Transport. It's the first address listened on.
 func (mt *MultiplexTransport) NetAddress() NetAddress {
 	return mt.netAddr
 }
@@ -202,7 +222,7 @@ func (mt *MultiplexTransport) Accept(cfg peerConfig) (Peer, error) {
 
 		cfg.outbound = false
 
-		return mt.wrapPeer(a.conn, a.nodeInfo, cfg, a.netAddr), nil
+		return mt.wrapPeer(a.conn, a.nodeInfo, a.ext, cfg, a.netAddr), nil
 	case <-mt.closec:
 		return nil, ErrTransportClosed{}
 	}
@@ -213,7 +233,9 @@ func (mt *MultiplexTransport) Dial(
 	addr NetAddress,
 	cfg peerConfig,
 ) (Peer, error) {
-	c, err := addr.DialTimeout(mt.dialTimeout)
+	// the peer keeps the address dialed, with its alternates, as its socket
+	// address, whichever of them connected
+	c, _, err := addr.DialAnyTimeout(mt.dialTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -223,14 +245,14 @@ func (mt *MultiplexTransport) Dial(
 		return nil, err
 	}
 
-	secretConn, nodeInfo, err := mt.upgrade(c, &addr)
+	secretConn, nodeInfo, ext, err := mt.upgrade(c, &addr)
 	if err != nil {
 		return nil, err
 	}
 
 	cfg.outbound = true
 
-	p := mt.wrapPeer(secretConn, nodeInfo, cfg, &addr)
+	p := mt.wrapPeer(secretConn, nodeInfo, ext, cfg, &addr)
 
 	return p, nil
 }
@@ -239,14 +261,19 @@ func (mt *MultiplexTransport) Dial(
 func (mt *MultiplexTransport) Close() error {
 	close(mt.closec)
 
-	if mt.listener != nil {
-		return mt.listener.Close()
+	var err error
+	for _, ln := range mt.listeners {
+		if lnErr := ln.Close(); lnErr != nil && err == nil {
+			err = lnErr
+		}
 	}
 
-	return nil
+	return err
 }
 
-// Listen implements transportLifecycle.
+// Listen implements transportLifecycle. It can be called for each of the
+// addresses to listen on, e.g. one of each IP family, before the transport is
+// used.
 func (mt *MultiplexTransport) Listen(addr NetAddress) error {
 	ln, err := net.Listen("tcp", addr.DialString())
 	if err != nil {
@@ -257,10 +284,12 @@ func (mt *MultiplexTransport) Listen(addr NetAddress) error {
 		ln = netutil.LimitListener(ln, mt.maxIncomingConnections)
 	}
 
-	mt.netAddr = addr
-	mt.listener = ln
+	if len(mt.listeners) == 0 {
+		mt.netAddr = addr
+	}
+	mt.listeners = append(mt.listeners, ln)
 
-	go mt.acceptPeers()
+	go mt.acceptPeers(ln)
 
 	return nil
 }
@@ -270,6 +299,8 @@ func (mt *MultiplexTransport) Listen(addr NetAddress) error {
 // This is a bit messy at the moment but is cleaned up in the following version
 // when NodeInfo changes from an interface to a concrete type
 func (mt *MultiplexTransport) AddChannel(chID byte) error {
+	mt.nodeInfoMtx.Lock()
+	defer mt.nodeInfoMtx.Unlock()
 	ni, ok := mt.nodeInfo.(DefaultNodeInfo)
 	if !ok {
 		return fmt.Errorf("nodeInfo type: %T is not supported", mt.nodeInfo)
@@ -281,9 +312,31 @@ func (mt *MultiplexTransport) AddChannel(chID byte) error {
 	return nil
 }
 
-func (mt *MultiplexTransport) acceptPeers() {
+// SetListenAddr changes the address advertised in the handshakes after it.
+func (mt *MultiplexTransport) SetListenAddr(addr string) error {
+	mt.nodeInfoMtx.Lock()
+	defer mt.nodeInfoMtx.Unlock()
+	ni, ok := mt.nodeInfo.(DefaultNodeInfo)
+	if !ok {
+		return fmt.Errorf("nodeInfo type: %T is not supported", mt.nodeInfo)
+	}
+	if _, err := NewNetAddressString(IDAddressString(ni.ID(), addr)); err != nil {
+		return err
+	}
+	ni.ListenAddr = addr
+	mt.nodeInfo = ni
+	return nil
+}
+
+func (mt *MultiplexTransport) getNodeInfo() NodeInfo {
+	mt.nodeInfoMtx.RLock()
+	defer mt.nodeInfoMtx.RUnlock()
+	return mt.nodeInfo
+}
+
+func (mt *MultiplexTransport) acceptPeers(ln net.Listener) {
 	for {
-		c, err := mt.listener.Accept()
+		c, err := ln.Accept()
 		if err != nil {
 			// If Close() has been called, silently exit.
 			select {
@@ -324,13 +377,14 @@ func (mt *MultiplexTransport) acceptPeers() {
 
 			var (
 				nodeInfo   NodeInfo
+				ext        *ocp2p.NodeInfoExtension
 				secretConn *conn.SecretConnection
 				netAddr    *NetAddress
 			)
 
 			err := mt.filterConn(c)
 			if err == nil {
-				secretConn, nodeInfo, err = mt.upgrade(c, nil)
+				secretConn, nodeInfo, ext, err = mt.upgrade(c, nil)
 				if err == nil {
 					addr := c.RemoteAddr()
 					id := PubKeyToID(secretConn.RemotePubKey())
@@ -339,7 +393,7 @@ func (mt *MultiplexTransport) acceptPeers() {
 			}
 
 			select {
-			case mt.acceptc <- accept{netAddr, secretConn, nodeInfo, err}:
+			case mt.acceptc <- accept{netAddr, secretConn, nodeInfo, ext, err}:
 				// Make the upgraded peer available.
 			case <-mt.closec:
 				// Give up if the transport was closed.
@@ -409,7 +463,7 @@ func (mt *MultiplexTransport) filterConn(c net.Conn) (err error) {
 func (mt *MultiplexTransport) upgrade(
 	c net.Conn,
 	dialedAddr *NetAddress,
-) (secretConn *conn.SecretConnection, nodeInfo NodeInfo, err error) {
+) (secretConn *conn.SecretConnection, nodeInfo NodeInfo, ext *ocp2p.NodeInfoExtension, err error) {
 	defer func() {
 		if err != nil {
 			_ = mt.cleanup(c)
@@ -418,7 +472,7 @@ func (mt *MultiplexTransport) upgrade(
 
 	secretConn, err = upgradeSecretConn(c, mt.handshakeTimeout, mt.nodeKey.PrivKey)
 	if err != nil {
-		return nil, nil, ErrRejected{
+		return nil, nil, nil, ErrRejected{
 			conn:          c,
 			err:           fmt.Errorf("secret conn failed: %v", err),
 			isAuthFailure: true,
@@ -429,7 +483,7 @@ func (mt *MultiplexTransport) upgrade(
 	connID := PubKeyToID(secretConn.RemotePubKey())
 	if dialedAddr != nil {
 		if dialedID := dialedAddr.ID; connID != dialedID {
-			return nil, nil, ErrRejected{
+			return nil, nil, nil, ErrRejected{
 				conn: c,
 				id:   connID,
 				err: fmt.Errorf(
@@ -442,17 +496,26 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	nodeInfo, err = handshake(secretConn, mt.handshakeTimeout, mt.nodeInfo)
+	ourNodeInfo := mt.getNodeInfo()
+	ourExt := &ocp2p.NodeInfoExtension{Compression: conn.SupportedCompressions}
+	if ni, ok := ourNodeInfo.(DefaultNodeInfo); ok {
+		ourExt.ListenAddrs = ni.ListenAddrs
+	}
+	nodeInfo, ext, err = handshakeWithExtension(secretConn, mt.handshakeTimeout, ourNodeInfo, ourExt)
 	if err != nil {
-		return nil, nil, ErrRejected{
+		return nil, nil, nil, ErrRejected{
 			conn:          c,
 			err:           fmt.Errorf("handshake failed: %v", err),
 			isAuthFailure: true,
 		}
 	}
+	if ni, ok := nodeInfo.(DefaultNodeInfo); ok && len(ext.ListenAddrs) > 0 {
+		ni.ListenAddrs = ext.ListenAddrs
+		nodeInfo = ni
+	}
 
 	if err := nodeInfo.Validate(); err != nil {
-		return nil, nil, ErrRejected{
+		return nil, nil, nil, ErrRejected{
 			conn:              c,
 			err:               err,
 			isNodeInfoInvalid: true,
@@ -461,7 +524,7 @@ func (mt *MultiplexTransport) upgrade(
 
 	// Ensure connection key matches self reported key.
 	if connID != nodeInfo.ID() {
-		return nil, nil, ErrRejected{
+		return nil, nil, nil, ErrRejected{
 			conn: c,
 			id:   connID,
 			err: fmt.Errorf(
@@ -474,8 +537,8 @@ func (mt *MultiplexTransport) upgrade(
 	}
 
 	// Reject self.
-	if mt.nodeInfo.ID() == nodeInfo.ID() {
-		return nil, nil, ErrRejected{
+	if ourNodeInfo.ID() == nodeInfo.ID() {
+		return nil, nil, nil, ErrRejected{
 			addr:   *NewNetAddress(nodeInfo.ID(), c.RemoteAddr()),
 			conn:   c,
 			id:     nodeInfo.ID(),
@@ -483,8 +546,8 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	if err := mt.nodeInfo.CompatibleWith(nodeInfo); err != nil {
-		return nil, nil, ErrRejected{
+	if err := ourNodeInfo.CompatibleWith(nodeInfo); err != nil {
+		return nil, nil, nil, ErrRejected{
 			conn:           c,
 			err:            err,
 			id:             nodeInfo.ID(),
@@ -492,12 +555,13 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	return secretConn, nodeInfo, nil
+	return secretConn, nodeInfo, ext, nil
 }
 
 func (mt *MultiplexTransport) wrapPeer(
 	c net.Conn,
 	ni NodeInfo,
+	ext *ocp2p.NodeInfoExtension,
 	cfg peerConfig,
 	socketAddr *NetAddress,
 ) Peer {
@@ -521,9 +585,14 @@ func (mt *MultiplexTransport) wrapPeer(
 		socketAddr,
 	)
 
+	mt.mConfigMtx.Lock()
+	mConfig := mt.mConfig
+	mt.mConfigMtx.Unlock()
+	mConfig.CompressionHeaders, mConfig.Compression = negotiateCompression(ext, mConfig.Compression)
+
 	p := newPeer(
 		peerConn,
-		mt.mConfig,
+		mConfig,
 		ni,
 		cfg.reactorsByCh,
 		cfg.msgTypeByChID,
@@ -531,6 +600,8 @@ func (mt *MultiplexTransport) wrapPeer(
 		cfg.onPeerError,
 		cfg.mlc,
 		PeerMetrics(cfg.metrics),
+		PeerOnInvalidMessage(cfg.onInvalidMsg),
+		peerRecvBudget(cfg.recvBudget),
 	)
 
 	return p
@@ -541,41 +612,111 @@ func handshake(
 	timeout time.Duration,
 	nodeInfo NodeInfo,
 ) (NodeInfo, error) {
+	peerNodeInfo, _, err := handshakeWithExtension(c, timeout, nodeInfo, nil)
+	return peerNodeInfo, err
+}
+
+// handshakeWithExtension exchanges the node infos with the peer, followed by
+// our extension if not nil. It returns the extension of the peer, empty if it
+// sent none.
+func handshakeWithExtension(
+	c net.Conn,
+	timeout time.Duration,
+	nodeInfo NodeInfo,
+	ext *ocp2p.NodeInfoExtension,
+) (NodeInfo, *ocp2p.NodeInfoExtension, error) {
 	if err := c.SetDeadline(time.Now().Add(timeout)); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var (
 		errc = make(chan error, 2)
 
 		pbpeerNodeInfo tmp2p.DefaultNodeInfo
+		peerExt        ocp2p.NodeInfoExtension
 		peerNodeInfo   DefaultNodeInfo
 		ourNodeInfo    = nodeInfo.(DefaultNodeInfo)
 	)
 
 	go func(errc chan<- error, c net.Conn) {
-		_, err := protoio.NewDelimitedWriter(c).WriteMsg(ourNodeInfo.ToProto())
+		_, err := protoio.NewDelimitedWriter(c).WriteMsg(&handshakeMsg{nodeInfo: ourNodeInfo.ToProto(), ext: ext})
 		errc <- err
 	}(errc, c)
 	go func(errc chan<- error, c net.Conn) {
 		protoReader := protoio.NewDelimitedReader(c, MaxNodeInfoSize())
-		_, err := protoReader.ReadMsg(&pbpeerNodeInfo)
+		_, err := protoReader.ReadMsg(&handshakeMsg{nodeInfo: &pbpeerNodeInfo, ext: &peerExt})
 		errc <- err
 	}(errc, c)
 
 	for i := 0; i < cap(errc); i++ {
 		err := <-errc
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	peerNodeInfo, err := DefaultNodeInfoFromToProto(&pbpeerNodeInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return peerNodeInfo, &peerExt, c.SetDeadline(time.Time{})
+}
+
+// handshakeMsg is the message of the handshake: the fields of the extension
+// are appended to the ones of the node info, which the peers without it skip
+// as unknown fields.
+type handshakeMsg struct {
+	nodeInfo *tmp2p.DefaultNodeInfo
+	ext      *ocp2p.NodeInfoExtension // nil if none
+}
+
+func (m *handshakeMsg) Reset() {
+	m.nodeInfo.Reset()
+	if m.ext != nil {
+		m.ext.Reset()
+	}
+}
+
+func (m *handshakeMsg) String() string { return m.nodeInfo.String() }
+
+func (*handshakeMsg) ProtoMessage() {}
+
+func (m *handshakeMsg) Marshal() ([]byte, error) {
+	bz, err := m.nodeInfo.Marshal()
+	if err != nil || m.ext == nil {
+		return bz, err
+	}
+	extBz, err := m.ext.Marshal()
 	if err != nil {
 		return nil, err
 	}
+	return append(bz, extBz...), nil
+}
+
+func (m *handshakeMsg) Unmarshal(bz []byte) error {
+	if err := m.nodeInfo.Unmarshal(bz); err != nil {
+		return err
+	}
+	if m.ext != nil {
+		return m.ext.Unmarshal(bz)
+	}
+	return nil
+}
 
-	return peerNodeInfo, c.SetDeadline(time.Time{})
+// negotiateCompression returns whether the messages are prefixed with a
+// compression header, once both ends of the connection can decompress some,
+// and the algorithm to compress them with, if the peer can decompress it.
+func negotiateCompression(peerExt *ocp2p.NodeInfoExtension, algorithm string) (bool, string) {
+	if peerExt == nil || len(peerExt.Compression) == 0 {
+		return false, ""
+	}
+	for _, a := range peerExt.Compression {
+		if a == algorithm {
+			return true, algorithm
+		}
+	}
+	return true, ""
 }
 
 func upgradeSecretConn(
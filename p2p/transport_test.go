@@ -17,6 +17,7 @@ import (
 	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/libs/protoio"
 	"github.com/Finschia/ostracon/p2p/conn"
+	ocp2p "github.com/Finschia/ostracon/proto/ostracon/p2p"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 )
@@ -68,7 +69,7 @@ func TestTransportMultiplexConnFilter(t *testing.T) {
 	errc := make(chan error)
 
 	go func() {
-		addr := NewNetAddress(id, mt.listener.Addr())
+		addr := NewNetAddress(id, mt.listeners[0].Addr())
 
 		_, err := addr.Dial()
 		if err != nil {
@@ -121,7 +122,7 @@ func TestTransportMultiplexConnFilterTimeout(t *testing.T) {
 
 	errc := make(chan error)
 	go func() {
-		addr := NewNetAddress(id, mt.listener.Addr())
+		addr := NewNetAddress(id, mt.listeners[0].Addr())
 
 		_, err := addr.Dial()
 		if err != nil {
@@ -166,7 +167,7 @@ func TestTransportMultiplexMaxIncomingConnections(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	laddr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+	laddr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 	// Connect more peers than max
 	for i := 0; i <= maxIncomingConns; i++ {
@@ -193,7 +194,7 @@ func TestTransportMultiplexMaxIncomingConnections(t *testing.T) {
 
 func TestTransportMultiplexAcceptMultiple(t *testing.T) {
 	mt := testSetupMultiplexTransport(t)
-	laddr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+	laddr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 	var (
 		seed     = rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -280,7 +281,7 @@ func TestTransportMultiplexAcceptNonBlocking(t *testing.T) {
 
 	// Simulate slow Peer.
 	go func() {
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		c, err := addr.Dial()
 		if err != nil {
@@ -332,7 +333,7 @@ func TestTransportMultiplexAcceptNonBlocking(t *testing.T) {
 				},
 			)
 		)
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		_, err := dialer.Dial(*addr, peerConfig{})
 		if err != nil {
@@ -375,7 +376,7 @@ func TestTransportMultiplexValidateNodeInfo(t *testing.T) {
 			)
 		)
 
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		_, err := dialer.Dial(*addr, peerConfig{})
 		if err != nil {
@@ -414,7 +415,7 @@ func TestTransportMultiplexRejectMissmatchID(t *testing.T) {
 				PrivKey: ed25519.GenPrivKey(),
 			},
 		)
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		_, err := dialer.Dial(*addr, peerConfig{})
 		if err != nil {
@@ -453,7 +454,7 @@ func TestTransportMultiplexDialRejectWrongID(t *testing.T) {
 	)
 
 	wrongID := PubKeyToID(ed25519.GenPrivKey().PubKey())
-	addr := NewNetAddress(wrongID, mt.listener.Addr())
+	addr := NewNetAddress(wrongID, mt.listeners[0].Addr())
 
 	_, err := dialer.Dial(*addr, peerConfig{})
 	if err != nil {
@@ -483,7 +484,7 @@ func TestTransportMultiplexRejectIncompatible(t *testing.T) {
 				},
 			)
 		)
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		_, err := dialer.Dial(*addr, peerConfig{})
 		if err != nil {
@@ -510,7 +511,7 @@ func TestTransportMultiplexRejectSelf(t *testing.T) {
 	errc := make(chan error)
 
 	go func() {
-		addr := NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr())
+		addr := NewNetAddress(mt.nodeKey.ID(), mt.listeners[0].Addr())
 
 		_, err := mt.Dial(*addr, peerConfig{})
 		if err != nil {
@@ -626,6 +627,117 @@ func TestTransportHandshake(t *testing.T) {
 	}
 }
 
+func TestTransportHandshakeExtension(t *testing.T) {
+	var (
+		pv       = ed25519.GenPrivKey()
+		nodeInfo = testNodeInfo(PubKeyToID(pv.PubKey()), defaultNodeName)
+		ext      = &ocp2p.NodeInfoExtension{Compression: conn.SupportedCompressions}
+	)
+
+	type result struct {
+		ni  NodeInfo
+		ext *ocp2p.NodeInfoExtension
+		err error
+	}
+	exchange := func(withExt bool) (result, result) {
+		c1, c2 := net.Pipe()
+		defer c1.Close()
+		defer c2.Close()
+		peerc := make(chan result, 1)
+		go func() {
+			var r result
+			if withExt {
+				r.ni, r.ext, r.err = handshakeWithExtension(c2, time.Second, nodeInfo, ext)
+			} else {
+				r.ni, r.err = handshake(c2, time.Second, nodeInfo)
+			}
+			peerc <- r
+		}()
+		var r result
+		r.ni, r.ext, r.err = handshakeWithExtension(c1, time.Second, nodeInfo, ext)
+		return r, <-peerc
+	}
+
+	// the peers without the extension skip it
+	r, peer := exchange(false)
+	require.NoError(t, r.err)
+	require.NoError(t, peer.err)
+	require.Equal(t, nodeInfo, r.ni)
+	require.Equal(t, nodeInfo, peer.ni)
+	require.Empty(t, r.ext.Compression)
+
+	r, peer = exchange(true)
+	require.NoError(t, r.err)
+	require.NoError(t, peer.err)
+	require.Equal(t, nodeInfo, r.ni)
+	require.Equal(t, ext, r.ext)
+	require.Equal(t, ext, peer.ext)
+}
+
+func TestTransportMultiplexListenAddrs(t *testing.T) {
+	var (
+		pv       = ed25519.GenPrivKey()
+		id       = PubKeyToID(pv.PubKey())
+		nodeInfo = testNodeInfo(id, "transport").(DefaultNodeInfo)
+	)
+	nodeInfo.ListenAddrs = []string{fmt.Sprintf("127.0.0.2:%d", getFreePort())}
+	mt := newMultiplexTransport(nodeInfo, NodeKey{PrivKey: pv})
+	for i := 0; i < 2; i++ {
+		addr, err := NewNetAddressString(IDAddressString(id, "127.0.0.1:0"))
+		require.NoError(t, err)
+		require.NoError(t, mt.Listen(*addr))
+	}
+	defer mt.Close()
+	require.Len(t, mt.listeners, 2)
+
+	// the address dialed first isn't listened on
+	addr := NewNetAddress(id, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: getFreePort()})
+	addr.Alternates = []*NetAddress{NewNetAddress(id, mt.listeners[1].Addr())}
+
+	errc := make(chan error)
+	go func() {
+		_, err := mt.Accept(peerConfig{})
+		errc <- err
+	}()
+
+	dialerPV := ed25519.GenPrivKey()
+	dialer := newMultiplexTransport(
+		testNodeInfo(PubKeyToID(dialerPV.PubKey()), "dialer"),
+		NodeKey{PrivKey: dialerPV},
+	)
+	p, err := dialer.Dial(*addr, peerConfig{})
+	require.NoError(t, err)
+	require.NoError(t, <-errc)
+
+	// the peers advertise their other listen addresses in the handshake
+	require.Equal(t, nodeInfo.ListenAddrs, p.NodeInfo().(DefaultNodeInfo).ListenAddrs)
+	na, err := p.NodeInfo().NetAddress()
+	require.NoError(t, err)
+	require.Len(t, na.Alternates, 1)
+	require.Equal(t, nodeInfo.ListenAddrs[0], na.Alternates[0].DialString())
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	testCases := []struct {
+		peerCompression []string
+		algorithm       string
+		headers         bool
+		compression     string
+	}{
+		{nil, conn.CompressionZstd, false, ""},
+		{nil, "", false, ""},
+		{[]string{conn.CompressionSnappy}, conn.CompressionZstd, true, ""},
+		{[]string{conn.CompressionSnappy}, "", true, ""},
+		{conn.SupportedCompressions, conn.CompressionZstd, true, conn.CompressionZstd},
+	}
+	for _, tc := range testCases {
+		headers, compression := negotiateCompression(
+			&ocp2p.NodeInfoExtension{Compression: tc.peerCompression}, tc.algorithm)
+		require.Equal(t, tc.headers, headers, "%v %q", tc.peerCompression, tc.algorithm)
+		require.Equal(t, tc.compression, compression, "%v %q", tc.peerCompression, tc.algorithm)
+	}
+}
+
 func TestTransportAddChannel(t *testing.T) {
 	mt := newMultiplexTransport(
 		emptyNodeInfo(),
@@ -1,6 +1,8 @@
 package p2p
 
 import (
+	"context"
+
 	"github.com/gogo/protobuf/proto"
 	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
 
@@ -15,6 +17,17 @@ type Envelope struct {
 	Src       Peer          // sender (empty if outbound)
 	Message   proto.Message // message payload
 	ChannelID byte
+
+	ctx context.Context // context of the spans of the message received, if traced
+}
+
+// Context returns the context of the spans of the message received, to trace
+// its handling with tracing.StartMessageSpan (see tracing.MessageContext).
+func (e Envelope) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
 }
 
 // Unwrapper is a Protobuf message that can contain a variety of inner messages
@@ -0,0 +1,122 @@
+package upnp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+const portMappingDescription = "Ostracon"
+
+// PortMapping maps a TCP port of the gateway to the same port of the node,
+// renewing its lease at half of it until stopped, when it is deleted.
+type PortMapping struct {
+	service.BaseService
+
+	nat   NAT
+	port  int
+	lease time.Duration
+
+	mtx          tmsync.Mutex
+	externalIP   net.IP
+	externalPort int
+	onChange     func(ip net.IP, port int)
+
+	quit chan struct{}
+}
+
+// NewPortMapping returns the mapping of the port by the gateway, leased for
+// the given duration (rounded up to the second).
+func NewPortMapping(nat NAT, port int, lease time.Duration) *PortMapping {
+	m := &PortMapping{
+		nat:   nat,
+		port:  port,
+		lease: lease,
+		quit:  make(chan struct{}),
+	}
+	m.BaseService = *service.NewBaseService(nil, "PortMapping", m)
+	return m
+}
+
+// SetOnChange sets the function called with the external address of the
+// mapping each time it changes once started, e.g. if the gateway got another
+// IP. It must be set before starting the mapping.
+func (m *PortMapping) SetOnChange(onChange func(ip net.IP, port int)) {
+	m.onChange = onChange
+}
+
+// ExternalAddress returns the external IP and port of the mapping.
+func (m *PortMapping) ExternalAddress() (net.IP, int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.externalIP, m.externalPort
+}
+
+// OnStart implements service.Service by mapping the port.
+func (m *PortMapping) OnStart() error {
+	if m.lease < time.Second {
+		return errors.New("the lease of a port mapping must be at least 1s")
+	}
+	if _, err := m.renew(); err != nil {
+		return err
+	}
+	go m.renewRoutine()
+	return nil
+}
+
+// OnStop implements service.Service by deleting the mapping.
+func (m *PortMapping) OnStop() {
+	close(m.quit)
+	_, port := m.ExternalAddress()
+	if err := m.nat.DeletePortMapping("tcp", port, m.port); err != nil {
+		m.Logger.Error("Failed to delete the port mapping", "port", m.port, "err", err)
+	}
+}
+
+// renew maps the port again, returning true if its external address changed.
+func (m *PortMapping) renew() (bool, error) {
+	seconds := int((m.lease + time.Second - 1) / time.Second)
+	port, err := m.nat.AddPortMapping("tcp", m.port, m.port, portMappingDescription, seconds)
+	if err != nil {
+		return false, fmt.Errorf("failed to map the port %d: %w", m.port, err)
+	}
+	ip, err := m.nat.GetExternalAddress()
+	if err != nil {
+		return false, fmt.Errorf("failed to get the external address: %w", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	changed := !ip.Equal(m.externalIP) || port != m.externalPort
+	m.externalIP, m.externalPort = ip, port
+	return changed, nil
+}
+
+func (m *PortMapping) renewRoutine() {
+	ticker := time.NewTicker(m.lease / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := m.renew()
+			if err != nil {
+				// retried before the lease expires
+				m.Logger.Error("Failed to renew the port mapping", "port", m.port, "err", err)
+				continue
+			}
+			if changed {
+				ip, port := m.ExternalAddress()
+				m.Logger.Info("Port mapping changed", "port", m.port, "external_ip", ip, "external_port", port)
+				if m.onChange != nil {
+					m.onChange(ip, port)
+				}
+			}
+		case <-m.quit:
+			return
+		}
+	}
+}
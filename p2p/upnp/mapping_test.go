@@ -0,0 +1,84 @@
+package upnp
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNAT struct {
+	mtx      sync.Mutex
+	ip       net.IP
+	mappings map[int]int // internal port -> lease in seconds
+	renewals int
+}
+
+func (n *fakeNAT) GetExternalAddress() (net.IP, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.ip, nil
+}
+
+func (n *fakeNAT) AddPortMapping(protocol string, externalPort, internalPort int, description string,
+	timeout int,
+) (int, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.mappings[internalPort] = timeout
+	n.renewals++
+	return externalPort, nil
+}
+
+func (n *fakeNAT) DeletePortMapping(protocol string, externalPort, internalPort int) error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	delete(n.mappings, internalPort)
+	return nil
+}
+
+func (n *fakeNAT) setIP(ip net.IP) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.ip = ip
+}
+
+func TestPortMapping(t *testing.T) {
+	nat := &fakeNAT{ip: net.ParseIP("8.8.8.8"), mappings: make(map[int]int)}
+	m := NewPortMapping(nat, 26656, 1500*time.Millisecond)
+	changed := make(chan net.IP, 10)
+	m.SetOnChange(func(ip net.IP, port int) {
+		assert.Equal(t, 26656, port)
+		changed <- ip
+	})
+	require.NoError(t, m.Start())
+
+	ip, port := m.ExternalAddress()
+	assert.Equal(t, "8.8.8.8", ip.String())
+	assert.Equal(t, 26656, port)
+	nat.mtx.Lock()
+	assert.Equal(t, map[int]int{26656: 2}, nat.mappings)
+	nat.mtx.Unlock()
+
+	// the mapping is renewed at half of its lease, with the new IP of the
+	// gateway
+	nat.setIP(net.ParseIP("8.8.4.4"))
+	select {
+	case ip := <-changed:
+		assert.Equal(t, "8.8.4.4", ip.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the mapping wasn't renewed")
+	}
+
+	require.NoError(t, m.Stop())
+	nat.mtx.Lock()
+	defer nat.mtx.Unlock()
+	assert.Empty(t, nat.mappings)
+	assert.GreaterOrEqual(t, nat.renewals, 2)
+
+	// the lease must be at least a second
+	assert.Error(t, NewPortMapping(nat, 26656, time.Millisecond).Start())
+}
@@ -0,0 +1,175 @@
+package upnp
+
+// Just enough NAT-PMP (RFC 6886) to be able to forward ports, for the
+// gateways without UPnP.
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	natPMPPort = 5351
+
+	natPMPOpExternalAddress = 0
+	natPMPOpMapUDP          = 1
+	natPMPOpMapTCP          = 2
+
+	// the first request is retried after natPMPRetryTimeout, doubled on each
+	// retry (RFC 6886 retries 9 times, up to 64 seconds)
+	natPMPRetryTimeout = 250 * time.Millisecond
+	natPMPRetries      = 4
+)
+
+type natPMP struct {
+	gateway string // host:port
+}
+
+// DiscoverNATPMP returns the NAT-PMP client of the default gateway, once it
+// answered with its external address.
+func DiscoverNATPMP() (nat NAT, err error) {
+	gateway, err := defaultGateway()
+	if err != nil {
+		return nil, err
+	}
+	nat = newNATPMP(net.JoinHostPort(gateway.String(), fmt.Sprint(natPMPPort)))
+	if _, err := nat.GetExternalAddress(); err != nil {
+		return nil, fmt.Errorf("no NAT-PMP gateway at %v: %w", gateway, err)
+	}
+	return nat, nil
+}
+
+func newNATPMP(gateway string) *natPMP {
+	return &natPMP{gateway: gateway}
+}
+
+// GetExternalAddress returns the external IP of the gateway.
+func (n *natPMP) GetExternalAddress() (addr net.IP, err error) {
+	response, err := n.request([]byte{0, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(response[8], response[9], response[10], response[11]), nil
+}
+
+// AddPortMapping maps the external port to the internal one for timeout
+// seconds, returning the external port mapped by the gateway, which may
+// differ from the requested one.
+func (n *natPMP) AddPortMapping(
+	protocol string,
+	externalPort,
+	internalPort int,
+	description string,
+	timeout int,
+) (mappedExternalPort int, err error) {
+	op, err := natPMPMapOp(protocol)
+	if err != nil {
+		return 0, err
+	}
+	request := make([]byte, 12)
+	request[1] = op
+	binary.BigEndian.PutUint16(request[4:], uint16(internalPort))
+	binary.BigEndian.PutUint16(request[6:], uint16(externalPort))
+	binary.BigEndian.PutUint32(request[8:], uint32(timeout))
+	response, err := n.request(request, 16)
+	if err != nil {
+		return 0, err
+	}
+	if port := int(binary.BigEndian.Uint16(response[8:])); port != internalPort {
+		return 0, fmt.Errorf("mapped internal port %d instead of %d", port, internalPort)
+	}
+	return int(binary.BigEndian.Uint16(response[10:])), nil
+}
+
+// DeletePortMapping deletes the mapping of the internal port.
+func (n *natPMP) DeletePortMapping(protocol string, externalPort, internalPort int) (err error) {
+	// a mapping is deleted by requesting it with a lifetime and an external
+	// port of 0
+	_, err = n.AddPortMapping(protocol, 0, internalPort, "", 0)
+	return err
+}
+
+func natPMPMapOp(protocol string) (byte, error) {
+	switch protocol {
+	case "udp":
+		return natPMPOpMapUDP, nil
+	case "tcp":
+		return natPMPOpMapTCP, nil
+	default:
+		return 0, fmt.Errorf("unknown protocol %q", protocol)
+	}
+}
+
+// request sends the request to the gateway until it answers, returning the
+// response of the given size, once its result code checked.
+func (n *natPMP) request(request []byte, size int) ([]byte, error) {
+	conn, err := net.Dial("udp", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	response := make([]byte, 16)
+	timeout := natPMPRetryTimeout
+	for i := 0; i < natPMPRetries; i++ {
+		if _, err := conn.Write(request); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			m, err := conn.Read(response)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			// skip the responses to other requests
+			if m < size || response[0] != 0 || response[1] != request[1]+128 {
+				continue
+			}
+			if code := binary.BigEndian.Uint16(response[2:]); code != 0 {
+				return nil, fmt.Errorf("NAT-PMP result code %d", code)
+			}
+			return response[:size], nil
+		}
+		timeout *= 2
+	}
+	return nil, errors.New("no NAT-PMP response")
+}
+
+// defaultGateway returns the IPv4 gateway of the default route, read from
+// /proc/net/route on Linux. Elsewhere, it guesses the first address of the
+// /24 network of the local IP, which most home routers use.
+func defaultGateway() (net.IP, error) {
+	if f, err := os.Open("/proc/net/route"); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			// Iface Destination Gateway Flags ...
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 3 || fields[1] != "00000000" {
+				continue
+			}
+			b, err := hex.DecodeString(fields[2])
+			if err != nil || len(b) != 4 {
+				continue
+			}
+			// little-endian
+			return net.IPv4(b[3], b[2], b[1], b[0]), nil
+		}
+	}
+	ip, err := localIPv4()
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(ip[0], ip[1], ip[2], 1), nil
+}
@@ -0,0 +1,78 @@
+package upnp
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATPMPGateway answers the NAT-PMP requests, mapping the ports to the
+// next ones, and returns its address.
+func fakeNATPMPGateway(t *testing.T, resultCode uint16) (string, <-chan []byte) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	requests := make(chan []byte, 10)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request := append([]byte(nil), buf[:n]...)
+			requests <- request
+			response := make([]byte, 16)
+			response[1] = request[1] + 128
+			binary.BigEndian.PutUint16(response[2:], resultCode)
+			if request[1] == natPMPOpExternalAddress {
+				copy(response[8:], net.IPv4(8, 8, 4, 4).To4())
+				response = response[:12]
+			} else {
+				copy(response[8:10], request[4:6])
+				binary.BigEndian.PutUint16(response[10:], binary.BigEndian.Uint16(request[6:])+1)
+				copy(response[12:], request[8:12])
+			}
+			if _, err := conn.WriteTo(response, addr); err != nil {
+				return
+			}
+		}
+	}()
+	return conn.LocalAddr().String(), requests
+}
+
+func TestNATPMP(t *testing.T) {
+	gateway, requests := fakeNATPMPGateway(t, 0)
+	nat := newNATPMP(gateway)
+
+	ip, err := nat.GetExternalAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "8.8.4.4", ip.String())
+	assert.Equal(t, []byte{0, 0}, <-requests)
+
+	// the gateway may map another external port
+	port, err := nat.AddPortMapping("tcp", 26656, 26656, "", 3600)
+	require.NoError(t, err)
+	assert.Equal(t, 26657, port)
+	assert.Equal(t, []byte{0, 2, 0, 0, 0x68, 0x20, 0x68, 0x20, 0, 0, 0x0e, 0x10}, <-requests)
+
+	require.NoError(t, nat.DeletePortMapping("tcp", 26657, 26656))
+	assert.Equal(t, []byte{0, 2, 0, 0, 0x68, 0x20, 0, 0, 0, 0, 0, 0}, <-requests)
+
+	_, err = nat.AddPortMapping("sctp", 26656, 26656, "", 3600)
+	assert.Error(t, err)
+}
+
+func TestNATPMPResultCode(t *testing.T) {
+	gateway, _ := fakeNATPMPGateway(t, 2) // not authorized
+	nat := newNATPMP(gateway)
+
+	_, err := nat.GetExternalAddress()
+	assert.Error(t, err)
+	_, err = nat.AddPortMapping("tcp", 26656, 26656, "", 3600)
+	assert.Error(t, err)
+}
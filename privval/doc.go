@@ -6,6 +6,19 @@ Package privval provides different implementations of the types.PrivValidator.
 FilePV is the simplest implementation and developer default.
 It uses one file for the private key and another to store state.
 
+# PKCS11PV
+
+PKCS11PV signs with the Ed25519 key of a PKCS#11 token (e.g. an HSM), which never
+exists on disk, only its state being stored in a file. It requires building with
+the pkcs11 tag (and cgo).
+
+# SignStateStore
+
+The last sign state of a FilePV or PKCS11PV can be persisted with a SignStateStore
+instead of the state file (e.g. privval/psql), shared by an active and a passive
+validator. The store fences the signers with a lease and an epoch, so that only
+one of them signs at a time.
+
 # SignerListenerEndpoint
 
 SignerListenerEndpoint establishes a connection to an external process,
@@ -23,5 +36,12 @@ SignerDialerEndpoint is a simple wrapper around a net.Conn. It's used by both IP
 SignerClient handles remote validator connections that provide signing services.
 In production, it's recommended to wrap it with RetrySignerClient to avoid
 termination in case of temporary errors.
+
+# GRPCSignerClient
+
+GRPCSignerClient dials a remote signer serving the gRPC protocol (see
+GRPCSignerServer), which runs through the load balancers and service meshes.
+The connection is authenticated with mutual TLS (see NewMTLSConfig), kept alive,
+and reconnected with an exponential backoff when it's lost.
 */
 package privval
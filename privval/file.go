@@ -17,6 +17,7 @@ import (
 	tmos "github.com/Finschia/ostracon/libs/os"
 	"github.com/Finschia/ostracon/libs/protoio"
 	"github.com/Finschia/ostracon/libs/tempfile"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 	tmtime "github.com/Finschia/ostracon/types/time"
 )
@@ -49,9 +50,23 @@ type FilePVKey struct {
 	PubKey  crypto.PubKey  `json:"pub_key"`
 	PrivKey crypto.PrivKey `json:"priv_key"`
 
+	// the next key staged with FilePV.StageKey, which signs from
+	// NextKeyHeight on
+	NextPubKey    crypto.PubKey  `json:"next_pub_key,omitempty"`
+	NextPrivKey   crypto.PrivKey `json:"next_priv_key,omitempty"`
+	NextKeyHeight int64          `json:"next_key_height,omitempty"`
+
 	filePath string
 }
 
+// privKeyAt returns the key signing at the height.
+func (pvKey FilePVKey) privKeyAt(height int64) crypto.PrivKey {
+	if pvKey.NextPrivKey != nil && height >= pvKey.NextKeyHeight {
+		return pvKey.NextPrivKey
+	}
+	return pvKey.PrivKey
+}
+
 // Save persists the FilePVKey to its filePath.
 func (pvKey FilePVKey) Save() {
 	outFile := pvKey.filePath
@@ -142,12 +157,19 @@ func (lss *FilePVLastSignState) Save() {
 
 // FilePV implements PrivValidator using data persisted to disk
 // to prevent double signing.
+//
+// Its key can be rotated by staging the next key with StageKey, which signs
+// from a given height on (see types.KeyRotatingPrivValidator). As the last
+// sign state is shared by both keys, they never sign at the same
+// height/round/step, nor at a height lower than the last one signed.
 // NOTE: the directories containing pv.Key.filePath and pv.LastSignState.filePath must already exist.
 // It includes the LastSignature and LastSignBytes so we don't lose the signature
 // if the process crashes after signing but before the resulting consensus message is processed.
 type FilePV struct {
 	Key           FilePVKey
 	LastSignState FilePVLastSignState
+
+	signStateStore SignStateStore // nil - the state file
 }
 
 // NewFilePV generates a new validator from the given key and paths.
@@ -200,6 +222,9 @@ func loadFilePV(keyFilePath, stateFilePath string, loadState bool) *FilePV {
 	// overwrite pubkey and address for convenience
 	pvKey.PubKey = pvKey.PrivKey.PubKey()
 	pvKey.Address = pvKey.PubKey.Address()
+	if pvKey.NextPrivKey != nil {
+		pvKey.NextPubKey = pvKey.NextPrivKey.PubKey()
+	}
 	pvKey.filePath = keyFilePath
 
 	pvState := FilePVLastSignState{}
@@ -242,17 +267,52 @@ func (pv *FilePV) GetAddress() types.Address {
 	return pv.Key.Address
 }
 
-// GetPubKey returns the public key of the validator.
+// GetPubKey returns the public key of the validator, the one of the staged
+// key once it has signed.
 // Implements PrivValidator.
 func (pv *FilePV) GetPubKey() (crypto.PubKey, error) {
-	return pv.Key.PubKey, nil
+	return pv.Key.privKeyAt(pv.LastSignState.Height).PubKey(), nil
+}
+
+// GetPubKeyAt returns the public key signing at the height.
+// Implements types.KeyRotatingPrivValidator.
+func (pv *FilePV) GetPubKeyAt(height int64) (crypto.PubKey, error) {
+	return pv.Key.privKeyAt(height).PubKey(), nil
+}
+
+// StageKey stages the next key of the validator, which signs the votes and
+// proposals from the given height on, replacing the current key: e.g. the
+// height from which the validator set has the next key instead of the current
+// one, as updated by the app. The height must be greater than the last one
+// signed. The staged key is saved to the key file.
+//
+// A key staged before can be replaced until it signs. Once it has signed, it
+// replaces the current key and another one can be staged.
+func (pv *FilePV) StageKey(privKey crypto.PrivKey, height int64) error {
+	lss, err := pv.lastSignState()
+	if err != nil {
+		return err
+	}
+	if height <= lss.Height {
+		return fmt.Errorf("the key can't be switched at height %d, already signed at height %d", height, lss.Height)
+	}
+	if pv.Key.NextPrivKey != nil && pv.Key.NextKeyHeight <= lss.Height {
+		pv.Key.PrivKey = pv.Key.NextPrivKey
+		pv.Key.PubKey = pv.Key.NextPubKey
+		pv.Key.Address = pv.Key.NextPubKey.Address()
+	}
+	pv.Key.NextPrivKey = privKey
+	pv.Key.NextPubKey = privKey.PubKey()
+	pv.Key.NextKeyHeight = height
+	pv.Key.Save()
+	return nil
 }
 
 // SignVote signs a canonical representation of the vote, along with the
 // chainID. Implements PrivValidator.
 func (pv *FilePV) SignVote(chainID string, vote *tmproto.Vote) error {
 	if err := pv.signVote(chainID, vote); err != nil {
-		return fmt.Errorf("error signing vote: %v", err)
+		return fmt.Errorf("error signing vote: %w", err)
 	}
 	return nil
 }
@@ -261,14 +321,34 @@ func (pv *FilePV) SignVote(chainID string, vote *tmproto.Vote) error {
 // the chainID. Implements PrivValidator.
 func (pv *FilePV) SignProposal(chainID string, proposal *tmproto.Proposal) error {
 	if err := pv.signProposal(chainID, proposal); err != nil {
-		return fmt.Errorf("error signing proposal: %v", err)
+		return fmt.Errorf("error signing proposal: %w", err)
+	}
+	return nil
+}
+
+// SignVoteExtension signs a canonical representation of the vote extension,
+// along with the chainID, with the key of its height. It isn't checked
+// against the last sign state as the extension of a precommit is only signed
+// once its precommit is. Implements types.VoteExtensionSigner.
+func (pv *FilePV) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	sig, err := pv.Key.privKeyAt(ext.Height).Sign(types.VoteExtensionSignBytes(chainID, ext))
+	if err != nil {
+		return fmt.Errorf("error signing vote extension: %w", err)
 	}
+	ext.Signature = sig
 	return nil
 }
 
 // GenerateVRFProof generates a proof for specified message.
 func (pv *FilePV) GenerateVRFProof(message []byte) (crypto.Proof, error) {
-	return pv.Key.PrivKey.VRFProve(message)
+	return pv.Key.privKeyAt(pv.LastSignState.Height).VRFProve(message)
+}
+
+// GenerateVRFProofAt generates a proof for specified message with the key of
+// the height.
+// Implements types.KeyRotatingPrivValidator.
+func (pv *FilePV) GenerateVRFProofAt(height int64, message []byte) (crypto.Proof, error) {
+	return pv.Key.privKeyAt(height).VRFProve(message)
 }
 
 // Save persists the FilePV to disk.
@@ -308,7 +388,10 @@ func (pv *FilePV) String() string {
 func (pv *FilePV) signVote(chainID string, vote *tmproto.Vote) error {
 	height, round, step := vote.Height, vote.Round, voteToStep(vote)
 
-	lss := pv.LastSignState
+	lss, err := pv.lastSignState()
+	if err != nil {
+		return err
+	}
 
 	sameHRS, err := lss.CheckHRS(height, round, step)
 	if err != nil {
@@ -335,11 +418,13 @@ func (pv *FilePV) signVote(chainID string, vote *tmproto.Vote) error {
 	}
 
 	// It passed the checks. Sign the vote
-	sig, err := pv.Key.PrivKey.Sign(signBytes)
+	sig, err := pv.Key.privKeyAt(height).Sign(signBytes)
 	if err != nil {
 		return err
 	}
-	pv.saveSigned(height, round, step, signBytes, sig)
+	if err := pv.saveSigned(height, round, step, signBytes, sig); err != nil {
+		return err
+	}
 	vote.Signature = sig
 	return nil
 }
@@ -350,7 +435,10 @@ func (pv *FilePV) signVote(chainID string, vote *tmproto.Vote) error {
 func (pv *FilePV) signProposal(chainID string, proposal *tmproto.Proposal) error {
 	height, round, step := proposal.Height, proposal.Round, stepPropose
 
-	lss := pv.LastSignState
+	lss, err := pv.lastSignState()
+	if err != nil {
+		return err
+	}
 
 	sameHRS, err := lss.CheckHRS(height, round, step)
 	if err != nil {
@@ -377,11 +465,13 @@ func (pv *FilePV) signProposal(chainID string, proposal *tmproto.Proposal) error
 	}
 
 	// It passed the checks. Sign the proposal
-	sig, err := pv.Key.PrivKey.Sign(signBytes)
+	sig, err := pv.Key.privKeyAt(height).Sign(signBytes)
 	if err != nil {
 		return err
 	}
-	pv.saveSigned(height, round, step, signBytes, sig)
+	if err := pv.saveSigned(height, round, step, signBytes, sig); err != nil {
+		return err
+	}
 	proposal.Signature = sig
 	return nil
 }
@@ -389,13 +479,17 @@ func (pv *FilePV) signProposal(chainID string, proposal *tmproto.Proposal) error
 // Persist height/round/step and signature
 func (pv *FilePV) saveSigned(height int64, round int32, step int8,
 	signBytes []byte, sig []byte,
-) {
+) error {
 	pv.LastSignState.Height = height
 	pv.LastSignState.Round = round
 	pv.LastSignState.Step = step
 	pv.LastSignState.Signature = sig
 	pv.LastSignState.SignBytes = signBytes
+	if pv.signStateStore != nil {
+		return pv.signStateStore.Save(pv.LastSignState)
+	}
 	pv.LastSignState.Save()
+	return nil
 }
 
 //-----------------------------------------------------------------------------------------
@@ -17,6 +17,7 @@ import (
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	tmjson "github.com/Finschia/ostracon/libs/json"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 	tmtime "github.com/Finschia/ostracon/types/time"
 )
@@ -299,6 +300,104 @@ func TestGenerateVRFProof(t *testing.T) {
 	}
 }
 
+func TestSignVoteExtension(t *testing.T) {
+	tempKeyFile, err := os.CreateTemp("", "priv_validator_key_")
+	require.Nil(t, err)
+	tempStateFile, err := os.CreateTemp("", "priv_validator_state_")
+	require.Nil(t, err)
+
+	privVal := GenFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	ext := &ocproto.VoteExtension{
+		Height:           1,
+		BlockHash:        tmrand.Bytes(tmhash.Size),
+		ValidatorAddress: privVal.GetAddress(),
+		Extension:        []byte("extension"),
+	}
+	require.NoError(t, privVal.SignVoteExtension("mychainid", ext))
+
+	voteExt, err := types.VoteExtensionFromProto(ext)
+	require.NoError(t, err)
+	pubKey, err := privVal.GetPubKey()
+	require.NoError(t, err)
+	assert.NoError(t, voteExt.Verify("mychainid", pubKey))
+
+	// the extensions aren't checked against the last sign state
+	require.NoError(t, privVal.SignVoteExtension("mychainid", ext))
+	assert.Equal(t, voteExt.Signature, ext.Signature)
+}
+
+func TestFilePVStageKey(t *testing.T) {
+	tempKeyFile, err := os.CreateTemp("", "priv_validator_key_")
+	require.Nil(t, err)
+	tempStateFile, err := os.CreateTemp("", "priv_validator_state_")
+	require.Nil(t, err)
+
+	privVal := GenFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	privVal.Save()
+	oldPubKey := privVal.Key.PubKey
+	blockID := types.BlockID{Hash: tmrand.Bytes(tmhash.Size), PartSetHeader: types.PartSetHeader{Total: 1}}
+	signVote := func(height int64, round int32) *tmproto.Vote {
+		vote := newVote(privVal.Key.Address, 0, height, round, tmproto.PrecommitType, blockID).ToProto()
+		require.NoError(t, privVal.SignVote("mychainid", vote))
+		return vote
+	}
+	verify := func(pubKey interface{ VerifySignature([]byte, []byte) bool }, vote *tmproto.Vote) bool {
+		return pubKey.VerifySignature(types.VoteSignBytes("mychainid", vote), vote.Signature)
+	}
+	signVote(10, 0)
+
+	// the key can't be switched at a height already signed
+	newPrivKey := ed25519.GenPrivKey()
+	require.Error(t, privVal.StageKey(newPrivKey, 10))
+	require.NoError(t, privVal.StageKey(newPrivKey, 12))
+
+	// the staged key is saved
+	loaded := LoadFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	assert.Equal(t, newPrivKey, loaded.Key.NextPrivKey)
+	assert.Equal(t, newPrivKey.PubKey(), loaded.Key.NextPubKey)
+	assert.EqualValues(t, 12, loaded.Key.NextKeyHeight)
+
+	// the votes are signed with the key of their height
+	for height, pubKey := range map[int64]interface{}{11: oldPubKey, 12: newPrivKey.PubKey()} {
+		got, err := privVal.GetPubKeyAt(height)
+		require.NoError(t, err)
+		assert.Equal(t, pubKey, got)
+	}
+	pubKey, err := privVal.GetPubKey()
+	require.NoError(t, err)
+	assert.Equal(t, oldPubKey, pubKey)
+	assert.True(t, verify(oldPubKey, signVote(11, 0)))
+	vote := signVote(12, 0)
+	assert.True(t, verify(newPrivKey.PubKey(), vote))
+	assert.False(t, verify(oldPubKey, vote))
+	pubKey, err = privVal.GetPubKey()
+	require.NoError(t, err)
+	assert.Equal(t, newPrivKey.PubKey(), pubKey)
+
+	proof, err := privVal.GenerateVRFProofAt(11, []byte("msg"))
+	require.NoError(t, err)
+	_, err = oldPubKey.VRFVerify(proof, []byte("msg"))
+	require.NoError(t, err)
+	proof, err = privVal.GenerateVRFProofAt(13, []byte("msg"))
+	require.NoError(t, err)
+	_, err = newPrivKey.PubKey().VRFVerify(proof, []byte("msg"))
+	require.NoError(t, err)
+
+	// both keys share the last sign state
+	for _, height := range []int64{11, 12} {
+		vote := newVote(privVal.Key.Address, 0, height, 0, tmproto.PrecommitType, types.BlockID{}).ToProto()
+		assert.Error(t, privVal.SignVote("mychainid", vote))
+	}
+
+	// once the staged key has signed, it replaces the current one
+	nextPrivKey := ed25519.GenPrivKey()
+	require.NoError(t, privVal.StageKey(nextPrivKey, 20))
+	assert.Equal(t, newPrivKey.PubKey(), privVal.Key.PubKey)
+	assert.Equal(t, newPrivKey.PubKey().Address(), privVal.GetAddress())
+	assert.True(t, verify(newPrivKey.PubKey(), signVote(19, 0)))
+	assert.True(t, verify(nextPrivKey.PubKey(), signVote(20, 0)))
+}
+
 func TestDifferByTimestamp(t *testing.T) {
 	tempKeyFile, err := os.CreateTemp("", "priv_validator_key_")
 	require.Nil(t, err)
@@ -0,0 +1,239 @@
+package privval
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	privvalproto "github.com/tendermint/tendermint/proto/tendermint/privval"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto"
+	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
+	tmnet "github.com/Finschia/ostracon/libs/net"
+	ocprivvalproto "github.com/Finschia/ostracon/proto/ostracon/privval"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	defaultGRPCKeepaliveInterval = 10 * time.Second
+	defaultGRPCKeepaliveTimeout  = 5 * time.Second
+	defaultGRPCRequestTimeout    = 5 * time.Second
+	defaultGRPCMaxBackoff        = 5 * time.Second
+)
+
+// grpcServiceConfig retries the requests failing on a connection lost, the
+// signers being safe to request again (e.g. the same vote is signed again).
+const grpcServiceConfig = `{"methodConfig": [{
+	"name": [{"service": "ostracon.privval.PrivValidatorAPI"}],
+	"retryPolicy": {
+		"maxAttempts": 5,
+		"initialBackoff": "0.1s",
+		"maxBackoff": "1s",
+		"backoffMultiplier": 2,
+		"retryableStatusCodes": ["UNAVAILABLE"]
+	}
+}]}`
+
+// GRPCSignerClientOption sets an optional parameter on the GRPCSignerClient.
+type GRPCSignerClientOption func(*GRPCSignerClient)
+
+// GRPCSignerClientTLS sets the TLS config of the connection, see
+// NewMTLSConfig. The connection is insecure by default.
+func GRPCSignerClientTLS(tlsConfig *tls.Config) GRPCSignerClientOption {
+	return func(sc *GRPCSignerClient) { sc.tlsConfig = tlsConfig }
+}
+
+// GRPCSignerClientKeepalive sets the interval of the keepalive pings of the
+// idle connection, and the time to wait for their ack before closing it (and
+// reconnecting). The server doesn't accept pings more often than every second.
+func GRPCSignerClientKeepalive(interval, timeout time.Duration) GRPCSignerClientOption {
+	return func(sc *GRPCSignerClient) {
+		sc.keepaliveInterval = interval
+		sc.keepaliveTimeout = timeout
+	}
+}
+
+// GRPCSignerClientRequestTimeout sets the time to wait for the response of a
+// request, including the time to reconnect.
+func GRPCSignerClientRequestTimeout(timeout time.Duration) GRPCSignerClientOption {
+	return func(sc *GRPCSignerClient) { sc.requestTimeout = timeout }
+}
+
+// GRPCSignerClientMaxBackoff sets the maximum delay between the attempts to
+// reconnect, the delay growing exponentially from 100ms.
+func GRPCSignerClientMaxBackoff(maxDelay time.Duration) GRPCSignerClientOption {
+	return func(sc *GRPCSignerClient) { sc.maxBackoff = maxDelay }
+}
+
+// GRPCSignerClient implements PrivValidator.
+// Handles the connection to a remote signer serving the gRPC protocol (see
+// GRPCSignerServer), reconnecting with an exponential backoff when it's lost.
+type GRPCSignerClient struct {
+	chainID string
+	conn    *grpc.ClientConn
+	client  ocprivvalproto.PrivValidatorAPIClient
+
+	tlsConfig         *tls.Config
+	keepaliveInterval time.Duration
+	keepaliveTimeout  time.Duration
+	requestTimeout    time.Duration
+	maxBackoff        time.Duration
+}
+
+var _ types.VoteExtensionSigner = (*GRPCSignerClient)(nil)
+
+// NewGRPCSignerClient returns a client of the remote signer at addr (e.g.
+// tcp://signer:26659 or unix:///var/run/signer.sock). It doesn't wait for the
+// connection, the requests waiting for it until their timeout.
+func NewGRPCSignerClient(addr, chainID string, options ...GRPCSignerClientOption) (*GRPCSignerClient, error) {
+	sc := &GRPCSignerClient{
+		chainID:           chainID,
+		keepaliveInterval: defaultGRPCKeepaliveInterval,
+		keepaliveTimeout:  defaultGRPCKeepaliveTimeout,
+		requestTimeout:    defaultGRPCRequestTimeout,
+		maxBackoff:        defaultGRPCMaxBackoff,
+	}
+	for _, option := range options {
+		option(sc)
+	}
+
+	creds := insecure.NewCredentials()
+	if sc.tlsConfig != nil {
+		creds = credentials.NewTLS(sc.tlsConfig)
+	}
+	bc := backoff.DefaultConfig
+	bc.MaxDelay = sc.maxBackoff
+
+	protocol, address := tmnet.ProtocolAndAddress(addr)
+	target := address
+	if protocol == "unix" {
+		target = "unix://" + address
+	}
+	conn, err := grpc.Dial(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                sc.keepaliveInterval,
+			Timeout:             sc.keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: bc, MinConnectTimeout: sc.requestTimeout}),
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
+		grpc.WithDefaultServiceConfig(grpcServiceConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial remote signer: %w", err)
+	}
+
+	sc.conn = conn
+	sc.client = ocprivvalproto.NewPrivValidatorAPIClient(conn)
+	return sc, nil
+}
+
+// Close closes the underlying connection
+func (sc *GRPCSignerClient) Close() error {
+	return sc.conn.Close()
+}
+
+//--------------------------------------------------------
+// Implement PrivValidator
+
+// GetPubKey retrieves a public key from a remote signer
+// returns an error if client is not able to provide the key
+func (sc *GRPCSignerClient) GetPubKey() (crypto.PubKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.requestTimeout)
+	defer cancel()
+
+	resp, err := sc.client.GetPubKey(ctx, &privvalproto.PubKeyRequest{ChainId: sc.chainID})
+	if err != nil {
+		return nil, fmt.Errorf("send: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	pk, err := cryptoenc.PubKeyFromProto(&resp.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pk, nil
+}
+
+// SignVote requests a remote signer to sign a vote
+func (sc *GRPCSignerClient) SignVote(chainID string, vote *tmproto.Vote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.requestTimeout)
+	defer cancel()
+
+	resp, err := sc.client.SignVote(ctx, &privvalproto.SignVoteRequest{Vote: vote, ChainId: chainID})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	*vote = resp.Vote
+
+	return nil
+}
+
+// SignProposal requests a remote signer to sign a proposal
+func (sc *GRPCSignerClient) SignProposal(chainID string, proposal *tmproto.Proposal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.requestTimeout)
+	defer cancel()
+
+	resp, err := sc.client.SignProposal(ctx, &privvalproto.SignProposalRequest{Proposal: proposal, ChainId: chainID})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	*proposal = resp.Proposal
+
+	return nil
+}
+
+// SignVoteExtension requests a remote signer to sign a vote extension
+func (sc *GRPCSignerClient) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.requestTimeout)
+	defer cancel()
+
+	resp, err := sc.client.SignVoteExtension(ctx, &ocprivvalproto.SignVoteExtensionRequest{VoteExtension: ext, ChainId: chainID})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	*ext = resp.VoteExtension
+
+	return nil
+}
+
+// GenerateVRFProof requests a remote signer to generate a VRF proof
+func (sc *GRPCSignerClient) GenerateVRFProof(message []byte) (crypto.Proof, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.requestTimeout)
+	defer cancel()
+
+	resp, err := sc.client.GenerateVRFProof(ctx, &ocprivvalproto.VRFProofRequest{Message: message})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	return resp.Proof, nil
+}
@@ -0,0 +1,199 @@
+package privval
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+
+	privvalproto "github.com/tendermint/tendermint/proto/tendermint/privval"
+
+	tmnet "github.com/Finschia/ostracon/libs/net"
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	ocprivvalproto "github.com/Finschia/ostracon/proto/ostracon/privval"
+	"github.com/Finschia/ostracon/types"
+)
+
+// minimum interval of the keepalive pings of the clients, the ones pinging
+// more often being disconnected
+const grpcMinPingInterval = time.Second
+
+// GRPCSignerServer serves the signing requests of the nodes over gRPC, see
+// GRPCSignerClient. Unlike SignerServer, the nodes dial in.
+type GRPCSignerServer struct {
+	service.BaseService
+
+	proto     string
+	addr      string
+	chainID   string
+	privVal   types.PrivValidator
+	tlsConfig *tls.Config // nil - insecure
+	server    *grpc.Server
+
+	handlerMtx               tmsync.Mutex
+	validationRequestHandler ValidationRequestHandlerFunc
+}
+
+var _ ocprivvalproto.PrivValidatorAPIServer = (*GRPCSignerServer)(nil)
+
+// NewGRPCSignerServer returns a server listening on protoAddr (e.g.
+// tcp://0.0.0.0:26659) and signing with privVal for chainID. If tlsConfig is
+// nil, the connections are insecure, see NewMTLSConfig.
+func NewGRPCSignerServer(
+	protoAddr string,
+	chainID string,
+	privVal types.PrivValidator,
+	tlsConfig *tls.Config,
+) *GRPCSignerServer {
+	proto, addr := tmnet.ProtocolAndAddress(protoAddr)
+	s := &GRPCSignerServer{
+		proto:                    proto,
+		addr:                     addr,
+		chainID:                  chainID,
+		privVal:                  privVal,
+		tlsConfig:                tlsConfig,
+		validationRequestHandler: DefaultValidationRequestHandler,
+	}
+	s.BaseService = *service.NewBaseService(nil, "GRPCSignerServer", s)
+	return s
+}
+
+// SetRequestHandler override the default function that is used to service requests
+func (s *GRPCSignerServer) SetRequestHandler(validationRequestHandler ValidationRequestHandlerFunc) {
+	s.handlerMtx.Lock()
+	defer s.handlerMtx.Unlock()
+	s.validationRequestHandler = validationRequestHandler
+}
+
+// OnStart implements service.Service.
+func (s *GRPCSignerServer) OnStart() error {
+	ln, err := net.Listen(s.proto, s.addr)
+	if err != nil {
+		return err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             grpcMinPingInterval,
+			PermitWithoutStream: true,
+		}),
+	}
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	s.server = grpc.NewServer(opts...)
+	ocprivvalproto.RegisterPrivValidatorAPIServer(s.server, s)
+
+	s.Logger.Info("Listening", "proto", s.proto, "addr", s.addr, "tls", s.tlsConfig != nil)
+	go func() {
+		if err := s.server.Serve(ln); err != nil {
+			s.Logger.Error("Error serving gRPC signer server", "err", err)
+		}
+	}()
+	return nil
+}
+
+// OnStop implements service.Service.
+func (s *GRPCSignerServer) OnStop() {
+	s.server.Stop()
+}
+
+// GetPubKey implements PrivValidatorAPIServer.
+func (s *GRPCSignerServer) GetPubKey(
+	ctx context.Context,
+	req *privvalproto.PubKeyRequest,
+) (*privvalproto.PubKeyResponse, error) {
+	res, err := s.handle(mustWrapMsg(req))
+	if err != nil {
+		return nil, err
+	}
+	if r := res.GetPubKeyResponse(); r != nil {
+		return r, nil
+	}
+	return nil, status.Error(codes.Internal, ErrUnexpectedResponse.Error())
+}
+
+// SignVote implements PrivValidatorAPIServer.
+func (s *GRPCSignerServer) SignVote(
+	ctx context.Context,
+	req *privvalproto.SignVoteRequest,
+) (*privvalproto.SignedVoteResponse, error) {
+	res, err := s.handle(mustWrapMsg(req))
+	if err != nil {
+		return nil, err
+	}
+	if r := res.GetSignedVoteResponse(); r != nil {
+		return r, nil
+	}
+	return nil, status.Error(codes.Internal, ErrUnexpectedResponse.Error())
+}
+
+// SignProposal implements PrivValidatorAPIServer.
+func (s *GRPCSignerServer) SignProposal(
+	ctx context.Context,
+	req *privvalproto.SignProposalRequest,
+) (*privvalproto.SignedProposalResponse, error) {
+	res, err := s.handle(mustWrapMsg(req))
+	if err != nil {
+		return nil, err
+	}
+	if r := res.GetSignedProposalResponse(); r != nil {
+		return r, nil
+	}
+	return nil, status.Error(codes.Internal, ErrUnexpectedResponse.Error())
+}
+
+// SignVoteExtension implements PrivValidatorAPIServer.
+func (s *GRPCSignerServer) SignVoteExtension(
+	ctx context.Context,
+	req *ocprivvalproto.SignVoteExtensionRequest,
+) (*ocprivvalproto.SignedVoteExtensionResponse, error) {
+	res, err := s.handle(mustWrapMsg(req))
+	if err != nil {
+		return nil, err
+	}
+	if r := res.GetSignedVoteExtensionResponse(); r != nil {
+		return r, nil
+	}
+	return nil, status.Error(codes.Internal, ErrUnexpectedResponse.Error())
+}
+
+// GenerateVRFProof implements PrivValidatorAPIServer.
+func (s *GRPCSignerServer) GenerateVRFProof(
+	ctx context.Context,
+	req *ocprivvalproto.VRFProofRequest,
+) (*ocprivvalproto.VRFProofResponse, error) {
+	res, err := s.handle(mustWrapMsg(req))
+	if err != nil {
+		return nil, err
+	}
+	if r := res.GetVrfProofResponse(); r != nil {
+		return r, nil
+	}
+	return nil, status.Error(codes.Internal, ErrUnexpectedResponse.Error())
+}
+
+// handle handles the request with the request handler, one at a time as the
+// PrivValidators aren't safe for concurrent use. The errors with a response
+// are only logged, the response carrying a RemoteSignerError, as for the
+// socket protocol.
+func (s *GRPCSignerServer) handle(req ocprivvalproto.Message) (ocprivvalproto.Message, error) {
+	s.handlerMtx.Lock()
+	defer s.handlerMtx.Unlock()
+
+	res, err := s.validationRequestHandler(s.privVal, req, s.chainID)
+	if err != nil {
+		s.Logger.Error("GRPCSignerServer: handleMessage", "err", err)
+		if res.Sum == nil {
+			return res, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,195 @@
+package privval
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	"github.com/Finschia/ostracon/libs/log"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// writeTestCert writes a certificate of 127.0.0.1 signed by the CA (or self
+// signed if ca is nil), and its key, as PEM files in dir.
+func writeTestCert(t *testing.T, dir, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (
+	*x509.Certificate, *ecdsa.PrivateKey, string, string,
+) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(tmrand.Int63()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parent := ca
+	if ca == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		parent, caKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, key, certFile, keyFile
+}
+
+func newTestGRPCSignerServer(addr, chainID string, pv types.PrivValidator) *GRPCSignerServer {
+	server := NewGRPCSignerServer(addr, chainID, pv, nil)
+	server.SetLogger(log.TestingLogger())
+	return server
+}
+
+func testGRPCSigner(t *testing.T, sc *GRPCSignerClient, chainID string, pv types.PrivValidator) {
+	pubKey, err := sc.GetPubKey()
+	require.NoError(t, err)
+	expectedPubKey, err := pv.GetPubKey()
+	require.NoError(t, err)
+	assert.Equal(t, expectedPubKey, pubKey)
+
+	vote := &tmproto.Vote{Type: tmproto.PrecommitType, Height: 1, Round: 2}
+	want := &tmproto.Vote{Type: tmproto.PrecommitType, Height: 1, Round: 2}
+	require.NoError(t, sc.SignVote(chainID, vote))
+	require.NoError(t, pv.SignVote(chainID, want))
+	assert.Equal(t, want.Signature, vote.Signature)
+
+	proposal := &tmproto.Proposal{Type: tmproto.ProposalType, Height: 1, Round: 2}
+	wantProposal := &tmproto.Proposal{Type: tmproto.ProposalType, Height: 1, Round: 2}
+	require.NoError(t, sc.SignProposal(chainID, proposal))
+	require.NoError(t, pv.SignProposal(chainID, wantProposal))
+	assert.Equal(t, wantProposal.Signature, proposal.Signature)
+
+	ext := &ocproto.VoteExtension{Height: 1, BlockHash: tmrand.Bytes(tmhash.Size), Extension: []byte("extension")}
+	wantExt := &ocproto.VoteExtension{Height: 1, BlockHash: ext.BlockHash, Extension: ext.Extension}
+	require.NoError(t, sc.SignVoteExtension(chainID, ext))
+	require.NoError(t, pv.(types.VoteExtensionSigner).SignVoteExtension(chainID, wantExt))
+	assert.Equal(t, wantExt.Signature, ext.Signature)
+
+	message := []byte("hello")
+	proof, err := sc.GenerateVRFProof(message)
+	require.NoError(t, err)
+	wantProof, err := pv.GenerateVRFProof(message)
+	require.NoError(t, err)
+	assert.Equal(t, wantProof, proof)
+
+	// the errors of the signer are returned as RemoteSignerError
+	err = sc.SignVote("other chain", vote)
+	assert.IsType(t, &RemoteSignerError{}, err)
+}
+
+func TestGRPCSigner(t *testing.T) {
+	chainID := tmrand.Str(12)
+	pv := types.NewMockPVWithParams(ed25519.GenPrivKey(), false, false)
+	addr := "tcp://" + GetFreeLocalhostAddrPort()
+	server := newTestGRPCSignerServer(addr, chainID, pv)
+	require.NoError(t, server.Start())
+	defer server.Stop() //nolint:errcheck // ignore for tests
+
+	sc, err := NewGRPCSignerClient(addr, chainID)
+	require.NoError(t, err)
+	defer sc.Close()
+
+	testGRPCSigner(t, sc, chainID, pv)
+}
+
+func TestGRPCSignerMTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile, _ := writeTestCert(t, dir, "ca", nil, nil)
+	_, _, serverCert, serverKey := writeTestCert(t, dir, "server", ca, caKey)
+	_, _, clientCert, clientKey := writeTestCert(t, dir, "client", ca, caKey)
+	otherCA, otherCAKey, _, _ := writeTestCert(t, dir, "other-ca", nil, nil)
+	_, _, otherCert, otherKey := writeTestCert(t, dir, "other", otherCA, otherCAKey)
+
+	chainID := tmrand.Str(12)
+	pv := types.NewMockPVWithParams(ed25519.GenPrivKey(), false, false)
+	addr := "tcp://" + GetFreeLocalhostAddrPort()
+	serverTLS, err := NewMTLSConfig(serverCert, serverKey, caFile)
+	require.NoError(t, err)
+	server := NewGRPCSignerServer(addr, chainID, pv, serverTLS)
+	server.SetLogger(log.TestingLogger())
+	require.NoError(t, server.Start())
+	defer server.Stop() //nolint:errcheck // ignore for tests
+
+	clientTLS, err := NewMTLSConfig(clientCert, clientKey, caFile)
+	require.NoError(t, err)
+	sc, err := NewGRPCSignerClient(addr, chainID, GRPCSignerClientTLS(clientTLS))
+	require.NoError(t, err)
+	defer sc.Close()
+	testGRPCSigner(t, sc, chainID, pv)
+
+	// a client without a certificate of the CA is rejected
+	otherTLS, err := NewMTLSConfig(otherCert, otherKey, caFile)
+	require.NoError(t, err)
+	other, err := NewGRPCSignerClient(addr, chainID,
+		GRPCSignerClientTLS(otherTLS), GRPCSignerClientRequestTimeout(500*time.Millisecond))
+	require.NoError(t, err)
+	defer other.Close()
+	_, err = other.GetPubKey()
+	assert.Error(t, err)
+
+	// as is an insecure one
+	insecure, err := NewGRPCSignerClient(addr, chainID, GRPCSignerClientRequestTimeout(500*time.Millisecond))
+	require.NoError(t, err)
+	defer insecure.Close()
+	_, err = insecure.GetPubKey()
+	assert.Error(t, err)
+
+	// the missing files are reported
+	_, err = NewMTLSConfig(filepath.Join(dir, "missing.crt"), clientKey, caFile)
+	assert.Error(t, err)
+}
+
+func TestGRPCSignerReconnect(t *testing.T) {
+	chainID := tmrand.Str(12)
+	pv := types.NewMockPVWithParams(ed25519.GenPrivKey(), false, false)
+	addr := "tcp://" + GetFreeLocalhostAddrPort()
+	server := newTestGRPCSignerServer(addr, chainID, pv)
+	require.NoError(t, server.Start())
+
+	sc, err := NewGRPCSignerClient(addr, chainID, GRPCSignerClientMaxBackoff(200*time.Millisecond))
+	require.NoError(t, err)
+	defer sc.Close()
+	_, err = sc.GetPubKey()
+	require.NoError(t, err)
+
+	// the requests wait for the signer to be back
+	require.NoError(t, server.Stop())
+	restarted := newTestGRPCSignerServer(addr, chainID, pv)
+	errCh := make(chan error, 1)
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		errCh <- restarted.Start()
+	}()
+	defer restarted.Stop() //nolint:errcheck // ignore for tests
+	_, err = sc.GetPubKey()
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,51 @@
+package privval
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// NewMTLSConfig returns the TLS config of a GRPCSignerServer or
+// GRPCSignerClient authenticating both ends: each one presents the certificate
+// of certFile and keyFile, and requires the certificate of the other one to be
+// signed by a CA of caFile (PEM).
+//
+// The certificate is loaded again on each handshake, so that it can be rotated
+// by replacing the files, without restarting the node nor the signer.
+func NewMTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	// fail early on the files missing or invalid
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return nil, fmt.Errorf("failed to load the key pair: %w", err)
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA file: %w", err)
+	}
+	cas := x509.NewCertPool()
+	if !cas.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no certificate in the CA file")
+	}
+
+	loadCert := func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &cert, nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return loadCert()
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loadCert()
+		},
+		RootCAs:    cas,
+		ClientCAs:  cas,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}, nil
+}
@@ -34,6 +34,10 @@ func mustWrapMsg(pb proto.Message) ocprivvalproto.Message {
 		msg.Sum = &ocprivvalproto.Message_VrfProofRequest{VrfProofRequest: pb}
 	case *ocprivvalproto.VRFProofResponse:
 		msg.Sum = &ocprivvalproto.Message_VrfProofResponse{VrfProofResponse: pb}
+	case *ocprivvalproto.SignVoteExtensionRequest:
+		msg.Sum = &ocprivvalproto.Message_SignVoteExtensionRequest{SignVoteExtensionRequest: pb}
+	case *ocprivvalproto.SignedVoteExtensionResponse:
+		msg.Sum = &ocprivvalproto.Message_SignedVoteExtensionResponse{SignedVoteExtensionResponse: pb}
 	case *privvalproto.PingRequest:
 		msg.Sum = &ocprivvalproto.Message_PingRequest{PingRequest: pb}
 	case *privvalproto.PingResponse:
@@ -0,0 +1,219 @@
+package privval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	tmjson "github.com/Finschia/ostracon/libs/json"
+	tmos "github.com/Finschia/ostracon/libs/os"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// the EdDSA mechanism of PKCS#11 v3.0, signing with the Ed25519 keys (of the
+// CKK_EC_EDWARDS type) without prehashing
+const pkcs11MechanismEdDSA = 0x1057
+
+// ErrPKCS11VRFUnsupported is returned by PKCS11PV.GenerateVRFProof when no
+// mechanism of the token generates the VRF proofs (see
+// PKCS11Config.VRFMechanism).
+var ErrPKCS11VRFUnsupported = errors.New("no VRF mechanism configured for the PKCS#11 token")
+
+// PKCS11Config is the config of the token of a PKCS11PV.
+type PKCS11Config struct {
+	// Path to the PKCS#11 module (shared library) of the token
+	Module string
+	// Slot of the token
+	Slot uint
+	// PIN of the user of the token
+	PIN string
+	// Label of the Ed25519 key pair (CKA_LABEL), the private and public keys
+	// having the same label
+	KeyLabel string
+	// Vendor-defined mechanism of the token generating, with C_Sign, the
+	// ECVRF-EDWARDS25519-SHA512-TAI proofs of the key, as the VRF isn't a
+	// standard mechanism. If 0, the validator can sign but not propose.
+	VRFMechanism uint
+}
+
+// pkcs11Token is a session logged in a PKCS#11 token, with the key pair of the
+// validator.
+type pkcs11Token interface {
+	// pubKey returns the Ed25519 public key of the validator.
+	pubKey() []byte
+	// sign signs the message with the private key of the validator, using the
+	// mechanism.
+	sign(mechanism uint, message []byte) ([]byte, error)
+	// close logs out and closes the session.
+	close() error
+}
+
+// pkcs11PrivKey is the private key of a PKCS#11 token, which never leaves it.
+type pkcs11PrivKey struct {
+	mtx          tmsync.Mutex // the sessions aren't safe for concurrent use
+	token        pkcs11Token
+	pubKey       ed25519.PubKey
+	vrfMechanism uint
+}
+
+var _ crypto.PrivKey = (*pkcs11PrivKey)(nil)
+
+// Bytes returns nil, the key being only in the token.
+func (k *pkcs11PrivKey) Bytes() []byte {
+	return nil
+}
+
+func (k *pkcs11PrivKey) Sign(msg []byte) ([]byte, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	return k.token.sign(pkcs11MechanismEdDSA, msg)
+}
+
+func (k *pkcs11PrivKey) VRFProve(seed []byte) (crypto.Proof, error) {
+	if k.vrfMechanism == 0 {
+		return nil, ErrPKCS11VRFUnsupported
+	}
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	proof, err := k.token.sign(k.vrfMechanism, seed)
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+func (k *pkcs11PrivKey) PubKey() crypto.PubKey {
+	return k.pubKey
+}
+
+func (k *pkcs11PrivKey) Equals(other crypto.PrivKey) bool {
+	o, ok := other.(*pkcs11PrivKey)
+	return ok && o.pubKey.Equals(k.pubKey)
+}
+
+func (k *pkcs11PrivKey) Type() string {
+	return ed25519.KeyType
+}
+
+//-------------------------------------------------------------------------------
+
+// PKCS11PV implements PrivValidator with the Ed25519 key of a PKCS#11 token
+// (e.g. an HSM), which never exists on disk. Only the last sign state is in a
+// file, for the double signing protection of FilePV.
+type PKCS11PV struct {
+	pv  *FilePV
+	key *pkcs11PrivKey
+}
+
+var _ types.VoteExtensionSigner = (*PKCS11PV)(nil)
+
+// NewPKCS11PV logs in the token of the config and returns the PrivValidator of
+// its key, with the last sign state of stateFilePath (created if it doesn't
+// exist). Ostracon must be built with the pkcs11 tag (and cgo).
+func NewPKCS11PV(config PKCS11Config, stateFilePath string) (*PKCS11PV, error) {
+	token, err := openPKCS11Token(config)
+	if err != nil {
+		return nil, err
+	}
+	pv, err := newPKCS11PV(token, config.VRFMechanism, stateFilePath)
+	if err != nil {
+		_ = token.close()
+		return nil, err
+	}
+	return pv, nil
+}
+
+func newPKCS11PV(token pkcs11Token, vrfMechanism uint, stateFilePath string) (*PKCS11PV, error) {
+	pubKey := token.pubKey()
+	if len(pubKey) != ed25519.PubKeySize {
+		return nil, fmt.Errorf("the public key of the token has %d bytes, expected an Ed25519 key of %d bytes",
+			len(pubKey), ed25519.PubKeySize)
+	}
+	key := &pkcs11PrivKey{
+		token:        token,
+		pubKey:       ed25519.PubKey(bytes.Clone(pubKey)),
+		vrfMechanism: vrfMechanism,
+	}
+
+	state := FilePVLastSignState{filePath: stateFilePath}
+	if tmos.FileExists(stateFilePath) {
+		stateJSONBytes, err := os.ReadFile(stateFilePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := tmjson.Unmarshal(stateJSONBytes, &state); err != nil {
+			return nil, fmt.Errorf("error reading PrivValidator state from %v: %w", stateFilePath, err)
+		}
+	} else {
+		state.Save()
+	}
+
+	return &PKCS11PV{
+		pv: &FilePV{
+			Key: FilePVKey{
+				Address: key.pubKey.Address(),
+				PubKey:  key.pubKey,
+				PrivKey: key,
+			},
+			LastSignState: state,
+		},
+		key: key,
+	}, nil
+}
+
+// GetAddress returns the address of the validator.
+func (pv *PKCS11PV) GetAddress() types.Address {
+	return pv.pv.GetAddress()
+}
+
+// GetPubKey returns the public key of the validator.
+// Implements PrivValidator.
+func (pv *PKCS11PV) GetPubKey() (crypto.PubKey, error) {
+	return pv.pv.GetPubKey()
+}
+
+// SignVote signs a canonical representation of the vote, along with the
+// chainID. Implements PrivValidator.
+func (pv *PKCS11PV) SignVote(chainID string, vote *tmproto.Vote) error {
+	return pv.pv.SignVote(chainID, vote)
+}
+
+// SignProposal signs a canonical representation of the proposal, along with
+// the chainID. Implements PrivValidator.
+func (pv *PKCS11PV) SignProposal(chainID string, proposal *tmproto.Proposal) error {
+	return pv.pv.SignProposal(chainID, proposal)
+}
+
+// SignVoteExtension signs a canonical representation of the vote extension,
+// along with the chainID. Implements types.VoteExtensionSigner.
+func (pv *PKCS11PV) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	return pv.pv.SignVoteExtension(chainID, ext)
+}
+
+// GenerateVRFProof generates a proof for specified message.
+func (pv *PKCS11PV) GenerateVRFProof(message []byte) (crypto.Proof, error) {
+	return pv.pv.GenerateVRFProof(message)
+}
+
+// SetSignStateStore persists the last sign state with the store instead of
+// the state file, see FilePV.SetSignStateStore.
+func (pv *PKCS11PV) SetSignStateStore(store SignStateStore) {
+	pv.pv.SetSignStateStore(store)
+}
+
+// Close logs out of the token.
+func (pv *PKCS11PV) Close() error {
+	return pv.key.token.close()
+}
+
+// String returns a string representation of the PKCS11PV.
+func (pv *PKCS11PV) String() string {
+	return fmt.Sprintf("PKCS11PV{%v}", pv.pv)
+}
@@ -0,0 +1,129 @@
+package privval
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+	"github.com/Finschia/ostracon/types"
+)
+
+// the vendor-defined VRF mechanism of mockPKCS11Token
+const mockPKCS11VRFMechanism = 0x80000001
+
+// mockPKCS11Token is a pkcs11Token of an in-memory key.
+type mockPKCS11Token struct {
+	privKey ed25519.PrivKey
+	closed  bool
+}
+
+func (t *mockPKCS11Token) pubKey() []byte {
+	return t.privKey.PubKey().Bytes()
+}
+
+func (t *mockPKCS11Token) sign(mechanism uint, message []byte) ([]byte, error) {
+	switch mechanism {
+	case pkcs11MechanismEdDSA:
+		return t.privKey.Sign(message)
+	case mockPKCS11VRFMechanism:
+		return t.privKey.VRFProve(message)
+	default:
+		return nil, errors.New("CKR_MECHANISM_INVALID")
+	}
+}
+
+func (t *mockPKCS11Token) close() error {
+	t.closed = true
+	return nil
+}
+
+func newTestPKCS11PV(t *testing.T, vrfMechanism uint) (*PKCS11PV, *mockPKCS11Token, string) {
+	tempStateFile, err := os.CreateTemp("", "priv_validator_state_")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(tempStateFile.Name()))
+	t.Cleanup(func() { os.Remove(tempStateFile.Name()) })
+
+	token := &mockPKCS11Token{privKey: ed25519.GenPrivKey()}
+	pv, err := newPKCS11PV(token, vrfMechanism, tempStateFile.Name())
+	require.NoError(t, err)
+	return pv, token, tempStateFile.Name()
+}
+
+func TestPKCS11PVSign(t *testing.T) {
+	pv, token, stateFile := newTestPKCS11PV(t, mockPKCS11VRFMechanism)
+	chainID := "mychainid"
+
+	pubKey, err := pv.GetPubKey()
+	require.NoError(t, err)
+	assert.Equal(t, token.privKey.PubKey(), pubKey)
+	assert.Equal(t, pubKey.Address(), pv.GetAddress())
+	assert.Nil(t, pv.key.Bytes())
+
+	randbytes := tmrand.Bytes(tmhash.Size)
+	block := types.BlockID{Hash: randbytes, PartSetHeader: types.PartSetHeader{Total: 5, Hash: randbytes}}
+	vote := newVote(pv.GetAddress(), 0, 1, 0, tmproto.PrevoteType, block)
+	v := vote.ToProto()
+	require.NoError(t, pv.SignVote(chainID, v))
+	assert.True(t, pubKey.VerifySignature(types.VoteSignBytes(chainID, v), v.Signature))
+
+	// the double signing protection of FilePV, with the state saved
+	randbytes2 := tmrand.Bytes(tmhash.Size)
+	conflicting := newVote(pv.GetAddress(), 0, 1, 0, tmproto.PrevoteType,
+		types.BlockID{Hash: randbytes2, PartSetHeader: types.PartSetHeader{Total: 10, Hash: randbytes2}})
+	assert.Error(t, pv.SignVote(chainID, conflicting.ToProto()))
+
+	reloaded, err := newPKCS11PV(token, mockPKCS11VRFMechanism, stateFile)
+	require.NoError(t, err)
+	assert.Error(t, reloaded.SignVote(chainID, conflicting.ToProto()))
+	v = vote.ToProto()
+	require.NoError(t, reloaded.SignVote(chainID, v))
+
+	proposal := newProposal(1, 1, block)
+	p := proposal.ToProto()
+	require.NoError(t, pv.SignProposal(chainID, p))
+	assert.True(t, pubKey.VerifySignature(types.ProposalSignBytes(chainID, p), p.Signature))
+
+	message := []byte("hello")
+	proof, err := pv.GenerateVRFProof(message)
+	require.NoError(t, err)
+	_, err = pubKey.VRFVerify(proof, message)
+	assert.NoError(t, err)
+
+	require.NoError(t, pv.Close())
+	assert.True(t, token.closed)
+}
+
+func TestPKCS11PVNoVRFMechanism(t *testing.T) {
+	pv, _, _ := newTestPKCS11PV(t, 0)
+
+	_, err := pv.GenerateVRFProof([]byte("hello"))
+	assert.ErrorIs(t, err, ErrPKCS11VRFUnsupported)
+
+	randbytes := tmrand.Bytes(tmhash.Size)
+	vote := newVote(pv.GetAddress(), 0, 1, 0, tmproto.PrevoteType,
+		types.BlockID{Hash: randbytes, PartSetHeader: types.PartSetHeader{Total: 5, Hash: randbytes}})
+	assert.NoError(t, pv.SignVote("mychainid", vote.ToProto()))
+}
+
+func TestPKCS11PVInvalidPubKey(t *testing.T) {
+	token := &invalidPubKeyToken{}
+	_, err := newPKCS11PV(token, 0, "")
+	assert.Error(t, err)
+}
+
+// invalidPubKeyToken is a pkcs11Token of a key which isn't an Ed25519 one.
+type invalidPubKeyToken struct {
+	mockPKCS11Token
+}
+
+func (t *invalidPubKeyToken) pubKey() []byte {
+	return make([]byte, 65)
+}
@@ -0,0 +1,310 @@
+//go:build pkcs11 && cgo
+// +build pkcs11,cgo
+
+package privval
+
+/*
+#cgo linux LDFLAGS: -ldl
+
+#include <stdlib.h>
+#include <string.h>
+#include <dlfcn.h>
+
+// The subset of the PKCS#11 v2.40 API used by the tokens, the function list
+// being declared in full up to C_Sign for its layout.
+
+typedef unsigned long CK_ULONG;
+typedef CK_ULONG CK_RV;
+
+typedef struct {
+	unsigned char major;
+	unsigned char minor;
+} CK_VERSION;
+
+typedef struct {
+	CK_ULONG type;
+	void *pValue;
+	CK_ULONG ulValueLen;
+} CK_ATTRIBUTE;
+
+typedef struct {
+	CK_ULONG mechanism;
+	void *pParameter;
+	CK_ULONG ulParameterLen;
+} CK_MECHANISM;
+
+typedef CK_RV (*CK_UNUSED)(void);
+
+typedef struct {
+	CK_VERSION version;
+	CK_RV (*C_Initialize)(void *);
+	CK_RV (*C_Finalize)(void *);
+	CK_UNUSED C_GetInfo, C_GetFunctionList, C_GetSlotList, C_GetSlotInfo,
+		C_GetTokenInfo, C_GetMechanismList, C_GetMechanismInfo, C_InitToken,
+		C_InitPIN, C_SetPIN;
+	CK_RV (*C_OpenSession)(CK_ULONG, CK_ULONG, void *, void *, CK_ULONG *);
+	CK_RV (*C_CloseSession)(CK_ULONG);
+	CK_UNUSED C_CloseAllSessions, C_GetSessionInfo, C_GetOperationState,
+		C_SetOperationState;
+	CK_RV (*C_Login)(CK_ULONG, CK_ULONG, unsigned char *, CK_ULONG);
+	CK_RV (*C_Logout)(CK_ULONG);
+	CK_UNUSED C_CreateObject, C_CopyObject, C_DestroyObject, C_GetObjectSize;
+	CK_RV (*C_GetAttributeValue)(CK_ULONG, CK_ULONG, CK_ATTRIBUTE *, CK_ULONG);
+	CK_UNUSED C_SetAttributeValue;
+	CK_RV (*C_FindObjectsInit)(CK_ULONG, CK_ATTRIBUTE *, CK_ULONG);
+	CK_RV (*C_FindObjects)(CK_ULONG, CK_ULONG *, CK_ULONG, CK_ULONG *);
+	CK_RV (*C_FindObjectsFinal)(CK_ULONG);
+	CK_UNUSED C_EncryptInit, C_Encrypt, C_EncryptUpdate, C_EncryptFinal,
+		C_DecryptInit, C_Decrypt, C_DecryptUpdate, C_DecryptFinal,
+		C_DigestInit, C_Digest, C_DigestUpdate, C_DigestKey, C_DigestFinal;
+	CK_RV (*C_SignInit)(CK_ULONG, CK_MECHANISM *, CK_ULONG);
+	CK_RV (*C_Sign)(CK_ULONG, unsigned char *, CK_ULONG, unsigned char *, CK_ULONG *);
+} CK_FUNCTION_LIST;
+
+#define CKR_OK                           0x000UL
+#define CKR_USER_ALREADY_LOGGED_IN       0x100UL
+#define CKR_CRYPTOKI_ALREADY_INITIALIZED 0x191UL
+#define CKR_MODULE_NOT_FOUND             0xFFFFFFFFUL // not a PKCS#11 code
+#define CKF_SERIAL_SESSION               0x4UL
+#define CKU_USER                         1UL
+#define CKA_CLASS                        0x000UL
+#define CKA_LABEL                        0x003UL
+#define CKA_EC_POINT                     0x181UL
+
+static CK_RV p11_open(const char *path, void **module, CK_FUNCTION_LIST **fl) {
+	CK_RV (*get)(CK_FUNCTION_LIST **);
+	CK_RV rv;
+
+	*module = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	if (*module == NULL) {
+		return CKR_MODULE_NOT_FOUND;
+	}
+	get = (CK_RV (*)(CK_FUNCTION_LIST **))dlsym(*module, "C_GetFunctionList");
+	if (get == NULL) {
+		dlclose(*module);
+		return CKR_MODULE_NOT_FOUND;
+	}
+	rv = get(fl);
+	if (rv == CKR_OK) {
+		rv = (*fl)->C_Initialize(NULL);
+		if (rv == CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+			rv = CKR_OK;
+		}
+	}
+	if (rv != CKR_OK) {
+		dlclose(*module);
+	}
+	return rv;
+}
+
+static void p11_close(void *module, CK_FUNCTION_LIST *fl) {
+	fl->C_Finalize(NULL);
+	dlclose(module);
+}
+
+static CK_RV p11_login(CK_FUNCTION_LIST *fl, CK_ULONG slot, unsigned char *pin, CK_ULONG pinLen,
+		CK_ULONG *session) {
+	CK_RV rv = fl->C_OpenSession(slot, CKF_SERIAL_SESSION, NULL, NULL, session);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	rv = fl->C_Login(*session, CKU_USER, pin, pinLen);
+	if (rv == CKR_USER_ALREADY_LOGGED_IN) {
+		rv = CKR_OK;
+	}
+	if (rv != CKR_OK) {
+		fl->C_CloseSession(*session);
+	}
+	return rv;
+}
+
+static void p11_logout(CK_FUNCTION_LIST *fl, CK_ULONG session) {
+	fl->C_Logout(session);
+	fl->C_CloseSession(session);
+}
+
+// p11_find finds the object of the class and label, found being the number
+// of the objects found (up to 2).
+static CK_RV p11_find(CK_FUNCTION_LIST *fl, CK_ULONG session, CK_ULONG class,
+		unsigned char *label, CK_ULONG labelLen, CK_ULONG *object, CK_ULONG *found) {
+	CK_ULONG objects[2];
+	CK_ATTRIBUTE templ[2] = {
+		{CKA_CLASS, &class, sizeof(class)},
+		{CKA_LABEL, label, labelLen},
+	};
+	CK_RV rv = fl->C_FindObjectsInit(session, templ, 2);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	rv = fl->C_FindObjects(session, objects, 2, found);
+	fl->C_FindObjectsFinal(session);
+	if (rv == CKR_OK && *found > 0) {
+		*object = objects[0];
+	}
+	return rv;
+}
+
+static CK_RV p11_ec_point(CK_FUNCTION_LIST *fl, CK_ULONG session, CK_ULONG object,
+		unsigned char *point, CK_ULONG *pointLen) {
+	CK_ATTRIBUTE templ = {CKA_EC_POINT, point, *pointLen};
+	CK_RV rv = fl->C_GetAttributeValue(session, object, &templ, 1);
+	*pointLen = templ.ulValueLen;
+	return rv;
+}
+
+static CK_RV p11_sign(CK_FUNCTION_LIST *fl, CK_ULONG session, CK_ULONG mechanism, CK_ULONG key,
+		unsigned char *msg, CK_ULONG msgLen, unsigned char *sig, CK_ULONG *sigLen) {
+	CK_MECHANISM mech = {mechanism, NULL, 0};
+	CK_RV rv = fl->C_SignInit(session, &mech, key);
+	if (rv != CKR_OK) {
+		return rv;
+	}
+	return fl->C_Sign(session, msg, msgLen, sig, sigLen);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// The classes of the key objects
+const (
+	pkcs11ClassPublicKey  = 2
+	pkcs11ClassPrivateKey = 3
+)
+
+// maximum length of the signatures and proofs (the Ed25519 signatures have
+// 64 bytes, the ECVRF proofs 80 bytes)
+const pkcs11MaxSignatureSize = 256
+
+// pkcs11Error is a CK_RV error code of a PKCS#11 function.
+type pkcs11Error struct {
+	operation string
+	rv        C.CK_RV
+}
+
+func (e pkcs11Error) Error() string {
+	return fmt.Sprintf("PKCS#11 %s failed: CKR 0x%X", e.operation, uint64(e.rv))
+}
+
+func checkPKCS11(operation string, rv C.CK_RV) error {
+	if rv != C.CKR_OK {
+		return pkcs11Error{operation: operation, rv: rv}
+	}
+	return nil
+}
+
+// cgoPKCS11Token is a pkcs11Token of a PKCS#11 module loaded with dlopen.
+type cgoPKCS11Token struct {
+	module  unsafe.Pointer
+	fl      *C.CK_FUNCTION_LIST
+	session C.CK_ULONG
+	privKey C.CK_ULONG
+	pub     []byte
+}
+
+func openPKCS11Token(config PKCS11Config) (pkcs11Token, error) {
+	path := C.CString(config.Module)
+	defer C.free(unsafe.Pointer(path))
+
+	t := &cgoPKCS11Token{}
+	rv := C.p11_open(path, &t.module, &t.fl)
+	if rv == C.CKR_MODULE_NOT_FOUND {
+		return nil, fmt.Errorf("failed to load the PKCS#11 module %s", config.Module)
+	}
+	if err := checkPKCS11("initialization", rv); err != nil {
+		return nil, err
+	}
+
+	pin := C.CBytes([]byte(config.PIN))
+	defer C.free(pin)
+	rv = C.p11_login(t.fl, C.CK_ULONG(config.Slot), (*C.uchar)(pin), C.CK_ULONG(len(config.PIN)), &t.session)
+	if err := checkPKCS11("login", rv); err != nil {
+		C.p11_close(t.module, t.fl)
+		return nil, err
+	}
+
+	if err := t.loadKeys(config.KeyLabel); err != nil {
+		_ = t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// loadKeys finds the private key and reads the public key of the label.
+func (t *cgoPKCS11Token) loadKeys(label string) error {
+	privKey, err := t.find(pkcs11ClassPrivateKey, label)
+	if err != nil {
+		return err
+	}
+	pubKey, err := t.find(pkcs11ClassPublicKey, label)
+	if err != nil {
+		return err
+	}
+	t.privKey = privKey
+
+	point := (*C.uchar)(C.malloc(pkcs11MaxSignatureSize))
+	defer C.free(unsafe.Pointer(point))
+	pointLen := C.CK_ULONG(pkcs11MaxSignatureSize)
+	rv := C.p11_ec_point(t.fl, t.session, pubKey, point, &pointLen)
+	if err := checkPKCS11("reading of the public key", rv); err != nil {
+		return err
+	}
+	t.pub = ecPointToPubKey(C.GoBytes(unsafe.Pointer(point), C.int(pointLen)))
+	return nil
+}
+
+func (t *cgoPKCS11Token) find(class C.CK_ULONG, label string) (C.CK_ULONG, error) {
+	cLabel := C.CBytes([]byte(label))
+	defer C.free(cLabel)
+
+	var object, found C.CK_ULONG
+	rv := C.p11_find(t.fl, t.session, class, (*C.uchar)(cLabel), C.CK_ULONG(len(label)), &object, &found)
+	if err := checkPKCS11("search of the keys", rv); err != nil {
+		return 0, err
+	}
+	switch found {
+	case 0:
+		return 0, fmt.Errorf("no key of class %d labeled %q in the PKCS#11 token", class, label)
+	case 1:
+		return object, nil
+	default:
+		return 0, fmt.Errorf("several keys of class %d labeled %q in the PKCS#11 token", class, label)
+	}
+}
+
+// ecPointToPubKey returns the public key of the CKA_EC_POINT of an Ed25519
+// key, a DER OCTET STRING of the key, or the raw key for some tokens.
+func ecPointToPubKey(point []byte) []byte {
+	if len(point) == 34 && point[0] == 0x04 && point[1] == 32 {
+		return point[2:]
+	}
+	return point
+}
+
+func (t *cgoPKCS11Token) pubKey() []byte {
+	return t.pub
+}
+
+func (t *cgoPKCS11Token) sign(mechanism uint, message []byte) ([]byte, error) {
+	msg := C.CBytes(message)
+	defer C.free(msg)
+	sig := (*C.uchar)(C.malloc(pkcs11MaxSignatureSize))
+	defer C.free(unsafe.Pointer(sig))
+	sigLen := C.CK_ULONG(pkcs11MaxSignatureSize)
+
+	rv := C.p11_sign(t.fl, t.session, C.CK_ULONG(mechanism), t.privKey,
+		(*C.uchar)(msg), C.CK_ULONG(len(message)), sig, &sigLen)
+	if err := checkPKCS11("signing", rv); err != nil {
+		return nil, err
+	}
+	return C.GoBytes(unsafe.Pointer(sig), C.int(sigLen)), nil
+}
+
+func (t *cgoPKCS11Token) close() error {
+	C.p11_logout(t.fl, t.session)
+	C.p11_close(t.module, t.fl)
+	return nil
+}
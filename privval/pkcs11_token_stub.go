@@ -0,0 +1,10 @@
+//go:build !pkcs11 || !cgo
+// +build !pkcs11 !cgo
+
+package privval
+
+import "errors"
+
+func openPKCS11Token(PKCS11Config) (pkcs11Token, error) {
+	return nil, errors.New("ostracon was built without PKCS#11 support (build with the pkcs11 tag and cgo)")
+}
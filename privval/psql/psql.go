@@ -0,0 +1,196 @@
+// Package psql implements a sign state store of the validators backed by a
+// PostgreSQL database, shared by an active and a passive validator.
+package psql
+
+import (
+	"database/sql"
+	_ "embed" // for the schema
+	"errors"
+	"time"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/privval"
+)
+
+const driverName = "postgres"
+
+//go:embed schema.sql
+var schema string
+
+// SignStateStore is a privval.SignStateStore persisting the last sign state
+// of a validator in a PostgreSQL database, using the schema of
+// privval/psql/schema.sql.
+//
+// The signers of the validator lease the state for a TTL, the lease being
+// renewed on each load and save. Once the lease of the holder expired, e.g.
+// as it crashed, another signer takes over the state with a new epoch, the
+// saves of the previous holder being fenced (conditional on its epoch). The
+// expiry is checked with the clock of the database, not the ones of the signers.
+type SignStateStore struct {
+	db       *sql.DB
+	key      string
+	holder   string
+	leaseTTL time.Duration
+
+	mtx     tmsync.Mutex
+	epoch   int64     // 0 - not holding the lease
+	renewAt time.Time // by the local clock
+	state   privval.FilePVLastSignState
+}
+
+var _ privval.SignStateStore = (*SignStateStore)(nil)
+
+// NewSignStateStore returns the store of the state of the key (e.g. the
+// address of the validator) in the PostgreSQL database specified by connStr,
+// for the signer named holder, which must be unique among the signers (e.g.
+// random). The table of the states is created if it doesn't exist.
+func NewSignStateStore(connStr, key, holder string, leaseTTL time.Duration) (*SignStateStore, error) {
+	if leaseTTL <= 0 {
+		return nil, errors.New("the lease TTL must be positive")
+	}
+	db, err := sql.Open(driverName, connStr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &SignStateStore{
+		db:       db,
+		key:      key,
+		holder:   holder,
+		leaseTTL: leaseTTL,
+	}, nil
+}
+
+// DB returns the underlying Postgres connection used by the store.
+// This is exported to support testing.
+func (s *SignStateStore) DB() *sql.DB { return s.db }
+
+// Close closes the connection to the database, keeping the lease until it
+// expires (see Release).
+func (s *SignStateStore) Close() error {
+	return s.db.Close()
+}
+
+// Load implements privval.SignStateStore. The state is only read from the
+// database when the lease is to be renewed or acquired.
+func (s *SignStateStore) Load() (privval.FilePVLastSignState, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	if s.epoch != 0 && now.Before(s.renewAt) {
+		return s.state, nil
+	}
+
+	var (
+		state  privval.FilePVLastSignState
+		epoch  int64
+		holder string
+		leased bool
+	)
+	err := runInTransaction(s.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT INTO priv_validator_sign_states (key) VALUES ($1)
+ON CONFLICT (key) DO NOTHING;`, s.key); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(`SELECT height, round, step, signature, sign_bytes, epoch, holder,
+  lease_expiry > now()
+FROM priv_validator_sign_states WHERE key = $1 FOR UPDATE;`, s.key).Scan(
+			&state.Height, &state.Round, &state.Step, &state.Signature, &state.SignBytes,
+			&epoch, &holder, &leased,
+		); err != nil {
+			return err
+		}
+
+		switch {
+		case s.epoch != 0 && epoch == s.epoch && holder == s.holder:
+			// renewing the lease
+		case !leased:
+			// taking over the state
+			epoch++
+		default:
+			return privval.ErrSignStateNotActive
+		}
+		_, err := tx.Exec(`UPDATE priv_validator_sign_states
+SET epoch = $2, holder = $3, lease_expiry = now() + $4 * interval '1 millisecond'
+WHERE key = $1;`, s.key, epoch, s.holder, s.leaseTTL.Milliseconds())
+		return err
+	})
+	if err != nil {
+		s.epoch = 0
+		return privval.FilePVLastSignState{}, err
+	}
+
+	s.epoch = epoch
+	s.renewAt = now.Add(s.leaseTTL / 2)
+	s.state = state
+	return state, nil
+}
+
+// Save implements privval.SignStateStore.
+func (s *SignStateStore) Save(state privval.FilePVLastSignState) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.epoch == 0 {
+		return privval.ErrSignStateFenced
+	}
+	now := time.Now()
+	res, err := s.db.Exec(`UPDATE priv_validator_sign_states
+SET height = $3, round = $4, step = $5, signature = $6, sign_bytes = $7,
+  lease_expiry = now() + $8 * interval '1 millisecond'
+WHERE key = $1 AND epoch = $2;`,
+		s.key, s.epoch, state.Height, state.Round, state.Step, state.Signature, []byte(state.SignBytes),
+		s.leaseTTL.Milliseconds())
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		s.epoch = 0
+		return privval.ErrSignStateFenced
+	}
+
+	s.renewAt = now.Add(s.leaseTTL / 2)
+	s.state = state
+	return nil
+}
+
+// Release ends the lease of the state if this signer holds it, so that
+// another signer can take over the state without waiting for its expiry,
+// e.g. on a graceful shutdown.
+func (s *SignStateStore) Release() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.epoch == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(`UPDATE priv_validator_sign_states SET lease_expiry = now()
+WHERE key = $1 AND epoch = $2;`, s.key, s.epoch)
+	s.epoch = 0
+	return err
+}
+
+// runInTransaction executes query in a fresh database transaction.
+// If query reports an error, the transaction is rolled back and the
+// error from query is reported to the caller.
+// Otherwise, the result of committing the transaction is returned.
+func runInTransaction(db *sql.DB, query func(*sql.Tx) error) error {
+	dbtx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := query(dbtx); err != nil {
+		_ = dbtx.Rollback() // report the initial error, not the rollback
+		return err
+	}
+	return dbtx.Commit()
+}
@@ -0,0 +1,138 @@
+package psql
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest"
+	"github.com/ory/dockertest/docker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/privval"
+
+	// Register the Postgres database driver.
+	_ "github.com/lib/pq"
+)
+
+const (
+	user     = "postgres"
+	password = "secret"
+	port     = "5432"
+	dsn      = "postgres://%s:%s@localhost:%s/%s?sslmode=disable"
+	dbName   = "postgres"
+)
+
+// the connection string of the database of the tests, set in TestMain
+var testConn string
+
+func TestMain(m *testing.M) {
+	// Set up docker and start a container running PostgreSQL.
+	pool, err := dockertest.NewPool(os.Getenv("DOCKER_URL"))
+	if err != nil {
+		log.Fatalf("Creating docker pool: %v", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "13",
+		Env: []string{
+			"POSTGRES_USER=" + user,
+			"POSTGRES_PASSWORD=" + password,
+			"POSTGRES_DB=" + dbName,
+			"listen_addresses = '*'",
+		},
+		ExposedPorts: []string{port},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{
+			Name: "no",
+		}
+	})
+	if err != nil {
+		log.Fatalf("Starting docker pool: %v", err)
+	}
+	const expireSeconds = 60
+	_ = resource.Expire(expireSeconds)
+
+	testConn = fmt.Sprintf(dsn, user, password, resource.GetPort(port+"/tcp"), dbName)
+	if err := pool.Retry(func() error {
+		store, err := NewSignStateStore(testConn, "ping", "ping", time.Second)
+		if err != nil {
+			return err
+		}
+		return store.Close()
+	}); err != nil {
+		log.Fatalf("Connecting to database: %v", err)
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Printf("WARNING: Purging pool failed: %v", err)
+	}
+	os.Exit(code)
+}
+
+func newTestStore(t *testing.T, key, holder string, leaseTTL time.Duration) *SignStateStore {
+	store, err := NewSignStateStore(testConn, key, holder, leaseTTL)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestSignStateStoreFencing(t *testing.T) {
+	const leaseTTL = 500 * time.Millisecond
+	active := newTestStore(t, "fencing", "active", leaseTTL)
+	passive := newTestStore(t, "fencing", "passive", leaseTTL)
+
+	state, err := active.Load()
+	require.NoError(t, err)
+	assert.Equal(t, privval.FilePVLastSignState{}, state)
+	signed := privval.FilePVLastSignState{Height: 10, Round: 1, Step: 2, Signature: []byte("sig"), SignBytes: []byte("bytes")}
+	require.NoError(t, active.Save(signed))
+
+	// the passive signer can't load the state while the active one holds it,
+	// the lease being renewed on each save
+	for i := 0; i < 3; i++ {
+		_, err = passive.Load()
+		assert.ErrorIs(t, err, privval.ErrSignStateNotActive)
+		time.Sleep(leaseTTL / 2)
+		signed.Height++
+		require.NoError(t, active.Save(signed))
+	}
+
+	// the passive signer takes over the state once the lease expired
+	time.Sleep(leaseTTL + 100*time.Millisecond)
+	state, err = passive.Load()
+	require.NoError(t, err)
+	assert.Equal(t, signed, state)
+
+	// and the previous one is fenced
+	assert.ErrorIs(t, active.Save(privval.FilePVLastSignState{Height: 20}), privval.ErrSignStateFenced)
+	_, err = active.Load()
+	assert.ErrorIs(t, err, privval.ErrSignStateNotActive)
+	state, err = passive.Load()
+	require.NoError(t, err)
+	assert.Equal(t, signed, state)
+}
+
+func TestSignStateStoreRelease(t *testing.T) {
+	active := newTestStore(t, "release", "active", time.Hour)
+	passive := newTestStore(t, "release", "passive", time.Hour)
+
+	_, err := active.Load()
+	require.NoError(t, err)
+	_, err = passive.Load()
+	assert.ErrorIs(t, err, privval.ErrSignStateNotActive)
+
+	// the passive signer takes over without waiting for the expiry
+	require.NoError(t, active.Release())
+	_, err = passive.Load()
+	require.NoError(t, err)
+	assert.ErrorIs(t, active.Save(privval.FilePVLastSignState{Height: 1}), privval.ErrSignStateFenced)
+}
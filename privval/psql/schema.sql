@@ -0,0 +1,21 @@
+/*
+  This file defines the database schema of the sign state store of the
+  validators backed by PostgreSQL. It is applied by NewSignStateStore when the
+  table doesn't exist.
+*/
+
+-- The last sign state of each validator, and the lease of the signer holding
+-- it: the signers take over the state with a new epoch once the lease of the
+-- previous holder expired, and save it only while the epoch is theirs.
+CREATE TABLE IF NOT EXISTS priv_validator_sign_states (
+  key          TEXT PRIMARY KEY,
+  height       BIGINT NOT NULL DEFAULT 0,
+  round        INTEGER NOT NULL DEFAULT 0,
+  step         SMALLINT NOT NULL DEFAULT 0,
+  signature    BYTEA,
+  sign_bytes   BYTEA,
+
+  epoch        BIGINT NOT NULL DEFAULT 0,
+  holder       TEXT NOT NULL DEFAULT '',
+  lease_expiry TIMESTAMPTZ NOT NULL DEFAULT 'epoch'
+);
@@ -7,6 +7,7 @@ import (
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
 	"github.com/Finschia/ostracon/crypto"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -24,7 +25,7 @@ func NewRetrySignerClient(sc *SignerClient, retries int, timeout time.Duration)
 	return &RetrySignerClient{sc, retries, timeout}
 }
 
-var _ types.PrivValidator = (*RetrySignerClient)(nil)
+var _ types.VoteExtensionSigner = (*RetrySignerClient)(nil)
 
 func (sc *RetrySignerClient) Close() error {
 	return sc.next.Close()
@@ -92,6 +93,22 @@ func (sc *RetrySignerClient) SignProposal(chainID string, proposal *tmproto.Prop
 	return fmt.Errorf("exhausted all attempts to sign proposal: %w", err)
 }
 
+func (sc *RetrySignerClient) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	var err error
+	for i := 0; i < sc.retries || sc.retries == 0; i++ {
+		err = sc.next.SignVoteExtension(chainID, ext)
+		if err == nil {
+			return nil
+		}
+		// If remote signer errors, we don't retry.
+		if _, ok := err.(*RemoteSignerError); ok {
+			return err
+		}
+		time.Sleep(sc.timeout)
+	}
+	return fmt.Errorf("exhausted all attempts to sign vote extension: %w", err)
+}
+
 func (sc *RetrySignerClient) GenerateVRFProof(message []byte) (crypto.Proof, error) {
 	var err error
 	var proof crypto.Proof
@@ -0,0 +1,53 @@
+package privval
+
+import (
+	"errors"
+)
+
+var (
+	// ErrSignStateNotActive is returned by a SignStateStore when another signer
+	// holds the lease of the state, this one being passive.
+	ErrSignStateNotActive = errors.New("the sign state is leased by another signer")
+	// ErrSignStateFenced is returned by a SignStateStore when another signer
+	// took over the state since this one loaded it, so that it must not sign.
+	ErrSignStateFenced = errors.New("the sign state was taken over by another signer")
+)
+
+// SignStateStore persists the last sign state of a FilePV (or PKCS11PV)
+// outside of its state file, e.g. in a database shared by an active and a
+// passive validator.
+//
+// The store fences the signers: only one of them can load and save the state
+// at a time, holding a lease of the state with an epoch, incremented by each
+// new holder. A save by a previous holder fails, so that a validator taken
+// over never returns a signature after the new one loaded the state.
+type SignStateStore interface {
+	// Load returns the last sign state, acquiring or renewing the lease of the
+	// state if needed. It returns ErrSignStateNotActive if another signer holds
+	// the lease.
+	Load() (FilePVLastSignState, error)
+	// Save saves the last sign state, failing with ErrSignStateFenced if
+	// another signer took over the state since it was loaded.
+	Save(state FilePVLastSignState) error
+}
+
+// SetSignStateStore persists the last sign state with the store instead of
+// the state file. The state is loaded from the store before each signature,
+// and saved to it before the signature is returned.
+func (pv *FilePV) SetSignStateStore(store SignStateStore) {
+	pv.signStateStore = store
+}
+
+// lastSignState returns the last sign state, loaded from the store if any.
+func (pv *FilePV) lastSignState() (FilePVLastSignState, error) {
+	if pv.signStateStore == nil {
+		return pv.LastSignState, nil
+	}
+	lss, err := pv.signStateStore.Load()
+	if err != nil {
+		return FilePVLastSignState{}, err
+	}
+	lss.filePath = pv.LastSignState.filePath
+	pv.LastSignState = lss
+	return lss, nil
+}
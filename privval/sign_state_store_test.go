@@ -0,0 +1,103 @@
+package privval
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+	"github.com/Finschia/ostracon/types"
+)
+
+// memSignStateStore is a SignStateStore in memory, shared by the signers of
+// the tests, the active one being the holder.
+type memSignStateStore struct {
+	shared *memSignStates
+	holder string
+}
+
+type memSignStates struct {
+	state  FilePVLastSignState
+	holder string
+}
+
+func (s *memSignStateStore) Load() (FilePVLastSignState, error) {
+	if s.shared.holder != s.holder {
+		return FilePVLastSignState{}, ErrSignStateNotActive
+	}
+	return s.shared.state, nil
+}
+
+func (s *memSignStateStore) Save(state FilePVLastSignState) error {
+	if s.shared.holder != s.holder {
+		return ErrSignStateFenced
+	}
+	s.shared.state = state
+	return nil
+}
+
+func TestFilePVSignStateStore(t *testing.T) {
+	tempKeyFile, err := os.CreateTemp("", "priv_validator_key_")
+	require.NoError(t, err)
+	defer os.Remove(tempKeyFile.Name())
+	tempStateFile, err := os.CreateTemp("", "priv_validator_state_")
+	require.NoError(t, err)
+	defer os.Remove(tempStateFile.Name())
+
+	// an active and a passive validator of the same key
+	active := GenFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	active.Save()
+	passive := LoadFilePV(tempKeyFile.Name(), tempStateFile.Name())
+	shared := &memSignStates{holder: "active"}
+	active.SetSignStateStore(&memSignStateStore{shared: shared, holder: "active"})
+	passive.SetSignStateStore(&memSignStateStore{shared: shared, holder: "passive"})
+
+	chainID := "mychainid"
+	randbytes := tmrand.Bytes(tmhash.Size)
+	block1 := types.BlockID{Hash: randbytes, PartSetHeader: types.PartSetHeader{Total: 5, Hash: randbytes}}
+	randbytes2 := tmrand.Bytes(tmhash.Size)
+	block2 := types.BlockID{Hash: randbytes2, PartSetHeader: types.PartSetHeader{Total: 10, Hash: randbytes2}}
+	vote1 := newVote(active.GetAddress(), 0, 10, 1, tmproto.PrevoteType, block1)
+	vote2 := newVote(active.GetAddress(), 0, 10, 1, tmproto.PrevoteType, block2)
+
+	// the state is saved to the store, not the file
+	require.NoError(t, active.SignVote(chainID, vote1.ToProto()))
+	assert.EqualValues(t, 10, shared.state.Height)
+	assert.Zero(t, LoadFilePV(tempKeyFile.Name(), tempStateFile.Name()).LastSignState.Height)
+
+	// the passive validator doesn't sign
+	assert.ErrorIs(t, passive.SignVote(chainID, vote2.ToProto()), ErrSignStateNotActive)
+
+	// once the passive one took over, it doesn't sign a conflicting vote
+	// with the state of the store, and the previous one is fenced
+	shared.holder = "passive"
+	assert.Error(t, passive.SignVote(chainID, vote2.ToProto()))
+	v := vote1.ToProto()
+	require.NoError(t, passive.SignVote(chainID, v))
+	assert.NotEmpty(t, v.Signature)
+	assert.ErrorIs(t, active.SignVote(chainID, vote1.ToProto()), ErrSignStateNotActive)
+
+	// the signature isn't returned if the state can't be saved, another
+	// signer having taken over the state after it was loaded
+	passive.SetSignStateStore(fencedSignStateStore{})
+	v = newVote(active.GetAddress(), 0, 11, 0, tmproto.PrevoteType, block1).ToProto()
+	assert.ErrorIs(t, passive.SignVote(chainID, v), ErrSignStateFenced)
+	assert.Empty(t, v.Signature)
+}
+
+// fencedSignStateStore is a SignStateStore taken over between the loads and
+// the saves.
+type fencedSignStateStore struct{}
+
+func (fencedSignStateStore) Load() (FilePVLastSignState, error) {
+	return FilePVLastSignState{}, nil
+}
+
+func (fencedSignStateStore) Save(FilePVLastSignState) error {
+	return ErrSignStateFenced
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Finschia/ostracon/crypto"
 	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
 	ocprivvalproto "github.com/Finschia/ostracon/proto/ostracon/privval"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -20,7 +21,7 @@ type SignerClient struct {
 	chainID  string
 }
 
-var _ types.PrivValidator = (*SignerClient)(nil)
+var _ types.VoteExtensionSigner = (*SignerClient)(nil)
 
 // NewSignerClient returns an instance of SignerClient.
 // it will start the endpoint (if not already started)
@@ -118,6 +119,28 @@ func (sc *SignerClient) SignProposal(chainID string, proposal *tmproto.Proposal)
 	return nil
 }
 
+// SignVoteExtension requests a remote signer to sign a vote extension
+func (sc *SignerClient) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	response, err := sc.endpoint.SendRequest(mustWrapMsg(
+		&ocprivvalproto.SignVoteExtensionRequest{VoteExtension: ext, ChainId: chainID},
+	))
+	if err != nil {
+		return err
+	}
+
+	resp := response.GetSignedVoteExtensionResponse()
+	if resp == nil {
+		return ErrUnexpectedResponse
+	}
+	if resp.Error != nil {
+		return &RemoteSignerError{Code: int(resp.Error.Code), Description: resp.Error.Description}
+	}
+
+	*ext = resp.VoteExtension
+
+	return nil
+}
+
 // GenerateVRFProof requests a remote signer to generate a VRF proof
 func (sc *SignerClient) GenerateVRFProof(message []byte) (crypto.Proof, error) {
 	msg := &ocprivvalproto.VRFProofRequest{Message: message}
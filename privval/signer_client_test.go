@@ -18,6 +18,7 @@ import (
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	ocprivvalproto "github.com/Finschia/ostracon/proto/ostracon/privval"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 	vrf "github.com/oasisprotocol/curve25519-voi/primitives/ed25519/extra/ecvrf"
 )
@@ -176,6 +177,35 @@ func TestSignerGenerateVRFProof(t *testing.T) {
 	}
 }
 
+func TestSignerVoteExtension(t *testing.T) {
+	for _, tc := range getSignerTestCases(t, nil, true) {
+		hash := tmrand.Bytes(tmhash.Size)
+		have := &ocproto.VoteExtension{Height: 1, BlockHash: hash, Extension: []byte("extension")}
+		want := &ocproto.VoteExtension{Height: 1, BlockHash: hash, Extension: []byte("extension")}
+
+		tc := tc
+		t.Cleanup(func() {
+			if err := tc.signerServer.Stop(); err != nil {
+				t.Error(err)
+			}
+		})
+		t.Cleanup(func() {
+			if err := tc.signerClient.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+
+		require.NoError(t, tc.mockPV.(types.VoteExtensionSigner).SignVoteExtension(tc.chainID, want))
+		require.NoError(t, tc.signerClient.SignVoteExtension(tc.chainID, have))
+
+		assert.Equal(t, want.Signature, have.Signature)
+
+		// the chain ID is checked by the signer
+		err := tc.signerClient.SignVoteExtension("other chain", have)
+		assert.IsType(t, &RemoteSignerError{}, err)
+	}
+}
+
 func TestSignerVote(t *testing.T) {
 	for _, tc := range getSignerTestCases(t, nil, true) {
 		ts := time.Now()
@@ -1,6 +1,7 @@
 package privval
 
 import (
+	"errors"
 	"fmt"
 
 	cryptoproto "github.com/tendermint/tendermint/proto/tendermint/crypto"
@@ -10,6 +11,7 @@ import (
 	"github.com/Finschia/ostracon/crypto"
 	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
 	ocprivvalproto "github.com/Finschia/ostracon/proto/ostracon/privval"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -85,6 +87,33 @@ func DefaultValidationRequestHandler(
 		} else {
 			res = mustWrapMsg(&privvalproto.SignedProposalResponse{Proposal: *proposal, Error: nil})
 		}
+
+	case *ocprivvalproto.Message_SignVoteExtensionRequest:
+		if r.SignVoteExtensionRequest.GetChainId() != chainID {
+			res = mustWrapMsg(&ocprivvalproto.SignedVoteExtensionResponse{
+				VoteExtension: ocproto.VoteExtension{}, Error: &privvalproto.RemoteSignerError{
+					Code:        0,
+					Description: "unable to sign vote extension"}})
+			return res, fmt.Errorf("want chainID: %s, got chainID: %s", r.SignVoteExtensionRequest.GetChainId(), chainID)
+		}
+
+		ext := r.SignVoteExtensionRequest.VoteExtension
+		if ext == nil {
+			ext = &ocproto.VoteExtension{}
+		}
+
+		if signer, ok := privVal.(types.VoteExtensionSigner); ok {
+			err = signer.SignVoteExtension(chainID, ext)
+		} else {
+			err = errors.New("the vote extensions can't be signed")
+		}
+		if err != nil {
+			res = mustWrapMsg(&ocprivvalproto.SignedVoteExtensionResponse{
+				VoteExtension: ocproto.VoteExtension{}, Error: &privvalproto.RemoteSignerError{Code: 0, Description: err.Error()}})
+		} else {
+			res = mustWrapMsg(&ocprivvalproto.SignedVoteExtensionResponse{VoteExtension: *ext, Error: nil})
+		}
+
 	case *ocprivvalproto.Message_PingRequest:
 		err, res = nil, mustWrapMsg(&privvalproto.PingResponse{})
 
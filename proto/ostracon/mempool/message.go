@@ -0,0 +1,28 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+var _ p2p.Wrapper = &HaveTx{}
+
+func (m *HaveTx) Wrap() proto.Message {
+	mm := &Message{}
+	mm.Sum = &Message_HaveTx{HaveTx: m}
+	return mm
+}
+
+// Unwrap implements the p2p Wrapper interface and unwraps a wrapped mempool
+// message.
+func (m *Message) Unwrap() (proto.Message, error) {
+	switch msg := m.Sum.(type) {
+	case *Message_HaveTx:
+		return m.GetHaveTx(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown message: %T", msg)
+	}
+}
@@ -0,0 +1,28 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+var _ p2p.Wrapper = &ObservedAddr{}
+
+func (m *ObservedAddr) Wrap() proto.Message {
+	mm := &Message{}
+	mm.Sum = &Message_ObservedAddr{ObservedAddr: m}
+	return mm
+}
+
+// Unwrap implements the p2p Wrapper interface and unwraps a wrapped p2p
+// message.
+func (m *Message) Unwrap() (proto.Message, error) {
+	switch msg := m.Sum.(type) {
+	case *Message_ObservedAddr:
+		return m.GetObservedAddr(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown message: %T", msg)
+	}
+}
@@ -5,6 +5,8 @@ package privval
 
 import (
 	fmt "fmt"
+	types "github.com/Finschia/ostracon/proto/ostracon/types"
+	_ "github.com/gogo/protobuf/gogoproto"
 	proto "github.com/gogo/protobuf/proto"
 	privval "github.com/tendermint/tendermint/proto/tendermint/privval"
 	io "io"
@@ -121,6 +123,112 @@ func (m *VRFProofResponse) GetError() *privval.RemoteSignerError {
 	return nil
 }
 
+// SignVoteExtensionRequest is a request to sign a vote extension
+type SignVoteExtensionRequest struct {
+	VoteExtension *types.VoteExtension `protobuf:"bytes,1,opt,name=vote_extension,json=voteExtension,proto3" json:"vote_extension,omitempty"`
+	ChainId       string               `protobuf:"bytes,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+}
+
+func (m *SignVoteExtensionRequest) Reset()         { *m = SignVoteExtensionRequest{} }
+func (m *SignVoteExtensionRequest) String() string { return proto.CompactTextString(m) }
+func (*SignVoteExtensionRequest) ProtoMessage()    {}
+func (*SignVoteExtensionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_abbbbe5131a55005, []int{2}
+}
+func (m *SignVoteExtensionRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SignVoteExtensionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SignVoteExtensionRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SignVoteExtensionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignVoteExtensionRequest.Merge(m, src)
+}
+func (m *SignVoteExtensionRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SignVoteExtensionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignVoteExtensionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignVoteExtensionRequest proto.InternalMessageInfo
+
+func (m *SignVoteExtensionRequest) GetVoteExtension() *types.VoteExtension {
+	if m != nil {
+		return m.VoteExtension
+	}
+	return nil
+}
+
+func (m *SignVoteExtensionRequest) GetChainId() string {
+	if m != nil {
+		return m.ChainId
+	}
+	return ""
+}
+
+// SignedVoteExtensionResponse is a response containing a signed vote extension or an error
+type SignedVoteExtensionResponse struct {
+	VoteExtension types.VoteExtension        `protobuf:"bytes,1,opt,name=vote_extension,json=voteExtension,proto3" json:"vote_extension"`
+	Error         *privval.RemoteSignerError `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *SignedVoteExtensionResponse) Reset()         { *m = SignedVoteExtensionResponse{} }
+func (m *SignedVoteExtensionResponse) String() string { return proto.CompactTextString(m) }
+func (*SignedVoteExtensionResponse) ProtoMessage()    {}
+func (*SignedVoteExtensionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_abbbbe5131a55005, []int{3}
+}
+func (m *SignedVoteExtensionResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SignedVoteExtensionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SignedVoteExtensionResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SignedVoteExtensionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignedVoteExtensionResponse.Merge(m, src)
+}
+func (m *SignedVoteExtensionResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *SignedVoteExtensionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignedVoteExtensionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignedVoteExtensionResponse proto.InternalMessageInfo
+
+func (m *SignedVoteExtensionResponse) GetVoteExtension() types.VoteExtension {
+	if m != nil {
+		return m.VoteExtension
+	}
+	return types.VoteExtension{}
+}
+
+func (m *SignedVoteExtensionResponse) GetError() *privval.RemoteSignerError {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
 type Message struct {
 	// Types that are valid to be assigned to Sum:
 	//	*Message_PubKeyRequest
@@ -133,6 +241,8 @@ type Message struct {
 	//	*Message_PingResponse
 	//	*Message_VrfProofRequest
 	//	*Message_VrfProofResponse
+	//	*Message_SignVoteExtensionRequest
+	//	*Message_SignedVoteExtensionResponse
 	Sum isMessage_Sum `protobuf_oneof:"sum"`
 }
 
@@ -140,7 +250,7 @@ func (m *Message) Reset()         { *m = Message{} }
 func (m *Message) String() string { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()    {}
 func (*Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_abbbbe5131a55005, []int{2}
+	return fileDescriptor_abbbbe5131a55005, []int{4}
 }
 func (m *Message) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -205,17 +315,25 @@ type Message_VrfProofRequest struct {
 type Message_VrfProofResponse struct {
 	VrfProofResponse *VRFProofResponse `protobuf:"bytes,1001,opt,name=vrf_proof_response,json=vrfProofResponse,proto3,oneof" json:"vrf_proof_response,omitempty"`
 }
+type Message_SignVoteExtensionRequest struct {
+	SignVoteExtensionRequest *SignVoteExtensionRequest `protobuf:"bytes,1002,opt,name=sign_vote_extension_request,json=signVoteExtensionRequest,proto3,oneof" json:"sign_vote_extension_request,omitempty"`
+}
+type Message_SignedVoteExtensionResponse struct {
+	SignedVoteExtensionResponse *SignedVoteExtensionResponse `protobuf:"bytes,1003,opt,name=signed_vote_extension_response,json=signedVoteExtensionResponse,proto3,oneof" json:"signed_vote_extension_response,omitempty"`
+}
 
-func (*Message_PubKeyRequest) isMessage_Sum()          {}
-func (*Message_PubKeyResponse) isMessage_Sum()         {}
-func (*Message_SignVoteRequest) isMessage_Sum()        {}
-func (*Message_SignedVoteResponse) isMessage_Sum()     {}
-func (*Message_SignProposalRequest) isMessage_Sum()    {}
-func (*Message_SignedProposalResponse) isMessage_Sum() {}
-func (*Message_PingRequest) isMessage_Sum()            {}
-func (*Message_PingResponse) isMessage_Sum()           {}
-func (*Message_VrfProofRequest) isMessage_Sum()        {}
-func (*Message_VrfProofResponse) isMessage_Sum()       {}
+func (*Message_PubKeyRequest) isMessage_Sum()               {}
+func (*Message_PubKeyResponse) isMessage_Sum()              {}
+func (*Message_SignVoteRequest) isMessage_Sum()             {}
+func (*Message_SignedVoteResponse) isMessage_Sum()          {}
+func (*Message_SignProposalRequest) isMessage_Sum()         {}
+func (*Message_SignedProposalResponse) isMessage_Sum()      {}
+func (*Message_PingRequest) isMessage_Sum()                 {}
+func (*Message_PingResponse) isMessage_Sum()                {}
+func (*Message_VrfProofRequest) isMessage_Sum()             {}
+func (*Message_VrfProofResponse) isMessage_Sum()            {}
+func (*Message_SignVoteExtensionRequest) isMessage_Sum()    {}
+func (*Message_SignedVoteExtensionResponse) isMessage_Sum() {}
 
 func (m *Message) GetSum() isMessage_Sum {
 	if m != nil {
@@ -294,6 +412,20 @@ func (m *Message) GetVrfProofResponse() *VRFProofResponse {
 	return nil
 }
 
+func (m *Message) GetSignVoteExtensionRequest() *SignVoteExtensionRequest {
+	if x, ok := m.GetSum().(*Message_SignVoteExtensionRequest); ok {
+		return x.SignVoteExtensionRequest
+	}
+	return nil
+}
+
+func (m *Message) GetSignedVoteExtensionResponse() *SignedVoteExtensionResponse {
+	if x, ok := m.GetSum().(*Message_SignedVoteExtensionResponse); ok {
+		return x.SignedVoteExtensionResponse
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -307,51 +439,65 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 		(*Message_PingResponse)(nil),
 		(*Message_VrfProofRequest)(nil),
 		(*Message_VrfProofResponse)(nil),
+		(*Message_SignVoteExtensionRequest)(nil),
+		(*Message_SignedVoteExtensionResponse)(nil),
 	}
 }
 
 func init() {
 	proto.RegisterType((*VRFProofRequest)(nil), "ostracon.privval.VRFProofRequest")
 	proto.RegisterType((*VRFProofResponse)(nil), "ostracon.privval.VRFProofResponse")
+	proto.RegisterType((*SignVoteExtensionRequest)(nil), "ostracon.privval.SignVoteExtensionRequest")
+	proto.RegisterType((*SignedVoteExtensionResponse)(nil), "ostracon.privval.SignedVoteExtensionResponse")
 	proto.RegisterType((*Message)(nil), "ostracon.privval.Message")
 }
 
 func init() { proto.RegisterFile("ostracon/privval/types.proto", fileDescriptor_abbbbe5131a55005) }
 
 var fileDescriptor_abbbbe5131a55005 = []byte{
-	// 508 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x94, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0x13, 0x46, 0x17, 0xf4, 0xda, 0xd1, 0xce, 0x0c, 0x54, 0x21, 0x14, 0x4a, 0x11, 0x30,
-	0x81, 0x94, 0x48, 0xdb, 0x91, 0xdb, 0x04, 0x63, 0xd2, 0x34, 0x54, 0x32, 0x69, 0x87, 0x49, 0xa8,
-	0x4a, 0xdb, 0xd7, 0x2c, 0x62, 0x89, 0x8d, 0xed, 0x44, 0xea, 0xb7, 0xe0, 0x63, 0x71, 0xdc, 0x11,
-	0x6e, 0xa8, 0xbd, 0xc0, 0xb7, 0x40, 0x71, 0xdc, 0x24, 0xed, 0xd2, 0x1d, 0xfd, 0xf7, 0xcb, 0xef,
-	0xfd, 0xac, 0x3c, 0x1b, 0x9e, 0x51, 0x21, 0xb9, 0x3f, 0xa6, 0xb1, 0xcb, 0x78, 0x98, 0xa6, 0xfe,
-	0xb5, 0x2b, 0x67, 0x0c, 0x85, 0xc3, 0x38, 0x95, 0x94, 0x74, 0x96, 0xbb, 0x8e, 0xde, 0x7d, 0x6a,
-	0x4b, 0x8c, 0x27, 0xc8, 0xa3, 0x30, 0x96, 0x75, 0x5f, 0xf4, 0xdf, 0x41, 0xfb, 0xc2, 0x3b, 0x1e,
-	0x70, 0x4a, 0xa7, 0x1e, 0x7e, 0x4f, 0x50, 0x48, 0xd2, 0x05, 0x2b, 0x42, 0x21, 0xfc, 0x00, 0xbb,
-	0x66, 0xcf, 0xdc, 0x6f, 0x79, 0xcb, 0x65, 0x1f, 0xa1, 0x53, 0x16, 0x0b, 0x46, 0x63, 0x81, 0x64,
-	0x0f, 0x1a, 0x2c, 0x0b, 0x74, 0x6d, 0xbe, 0x20, 0xef, 0xa1, 0x81, 0x9c, 0x53, 0xde, 0xbd, 0xd7,
-	0x33, 0xf7, 0x9b, 0x07, 0xaf, 0x9c, 0x52, 0x63, 0xa9, 0xe6, 0x78, 0x18, 0x51, 0x89, 0xe7, 0x61,
-	0x10, 0x23, 0xff, 0x98, 0x15, 0x7b, 0xf9, 0x37, 0xfd, 0xdf, 0xdb, 0x60, 0x9d, 0xe5, 0x2d, 0xc9,
-	0x29, 0xb4, 0x59, 0x32, 0x1a, 0x7e, 0xc3, 0xd9, 0x90, 0xe7, 0x7e, 0xaa, 0x51, 0xf3, 0xe0, 0x45,
-	0x1d, 0x72, 0x90, 0x8c, 0x4e, 0x71, 0xa6, 0x0f, 0x72, 0x62, 0x78, 0x3b, 0xac, 0x1a, 0x90, 0xcf,
-	0xd0, 0x29, 0x61, 0xb9, 0xbf, 0x16, 0xec, 0xdf, 0x45, 0xcb, 0x2b, 0x4f, 0x0c, 0xef, 0x21, 0x5b,
-	0x49, 0xc8, 0x17, 0xd8, 0x15, 0x61, 0x10, 0x0f, 0x53, 0x2a, 0xb1, 0xd0, 0xdb, 0x52, 0xc0, 0x97,
-	0x75, 0xc0, 0xec, 0xac, 0x17, 0x54, 0x62, 0x29, 0xd8, 0x16, 0xab, 0x11, 0xb9, 0x84, 0xbd, 0x2c,
-	0xc2, 0xc9, 0x12, 0xaa, 0x35, 0xef, 0x2b, 0xea, 0xeb, 0x4d, 0x54, 0x9c, 0xe4, 0x90, 0x42, 0x95,
-	0x88, 0x5b, 0x29, 0xf9, 0x0a, 0x8f, 0x95, 0x2e, 0xe3, 0x94, 0x51, 0xe1, 0x5f, 0x17, 0xca, 0x0d,
-	0x05, 0x7f, 0xb3, 0x09, 0x3e, 0xd0, 0xf5, 0xa5, 0xf6, 0x23, 0x71, 0x3b, 0x26, 0x53, 0xe8, 0x6a,
-	0xf5, 0x4a, 0x03, 0xad, 0xbf, 0xad, 0x3a, 0xbc, 0xdd, 0xac, 0x5f, 0xc2, 0x8a, 0x23, 0x3c, 0x11,
-	0xb5, 0x3b, 0xe4, 0x03, 0xb4, 0x58, 0x18, 0x07, 0x85, 0xbd, 0xa5, 0xd8, 0xcf, 0x6b, 0xff, 0x60,
-	0x18, 0x07, 0xa5, 0x75, 0x93, 0x95, 0x4b, 0xf2, 0x09, 0x76, 0x34, 0x45, 0x2b, 0x3e, 0x50, 0x98,
-	0xde, 0x66, 0x4c, 0x21, 0xd6, 0x62, 0x95, 0x35, 0x19, 0xc0, 0x6e, 0xca, 0xa7, 0x43, 0x35, 0xf7,
-	0x85, 0xd3, 0x5f, 0x4b, 0x0f, 0xe9, 0xfa, 0x85, 0x74, 0xd6, 0x6e, 0x5b, 0x36, 0x03, 0x29, 0x9f,
-	0xae, 0x5c, 0xc0, 0x73, 0x20, 0x55, 0xa2, 0xf6, 0xfb, 0x67, 0xe9, 0x49, 0xbd, 0x03, 0x59, 0x28,
-	0x76, 0x4a, 0x66, 0x9e, 0x1d, 0x35, 0x60, 0x4b, 0x24, 0xd1, 0xd1, 0xd9, 0xcf, 0xb9, 0x6d, 0xde,
-	0xcc, 0x6d, 0xf3, 0xcf, 0xdc, 0x36, 0x7f, 0x2c, 0x6c, 0xe3, 0x66, 0x61, 0x1b, 0xbf, 0x16, 0xb6,
-	0x71, 0x79, 0x18, 0x84, 0xf2, 0x2a, 0x19, 0x39, 0x63, 0x1a, 0xb9, 0xc7, 0x61, 0x2c, 0xc6, 0x57,
-	0xa1, 0xef, 0x56, 0x5e, 0x1b, 0x2a, 0xa9, 0xbb, 0xfe, 0xf8, 0x8c, 0xb6, 0x55, 0x7e, 0xf8, 0x3f,
-	0x00, 0x00, 0xff, 0xff, 0x26, 0x1b, 0x30, 0x10, 0x97, 0x04, 0x00, 0x00,
+	// 658 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x95, 0xcb, 0x4e, 0x14, 0x41,
+	0x14, 0x86, 0xbb, 0x85, 0xa1, 0xf1, 0x70, 0x1b, 0x4a, 0x34, 0x23, 0x68, 0x83, 0x63, 0x54, 0xa2,
+	0xb1, 0x27, 0x81, 0xa5, 0x3b, 0x02, 0x38, 0x4a, 0x30, 0x63, 0x93, 0xb0, 0x20, 0x31, 0x93, 0xb9,
+	0xd4, 0x34, 0x15, 0x99, 0xaa, 0xb2, 0xaa, 0xbb, 0x23, 0xf1, 0x25, 0x7c, 0x0a, 0x1f, 0xc3, 0x35,
+	0x4b, 0x96, 0xae, 0x8c, 0x81, 0x8d, 0x97, 0x97, 0x30, 0x5d, 0x5d, 0x7d, 0x99, 0xa1, 0x9b, 0x85,
+	0xee, 0xe6, 0xfc, 0xa7, 0xfa, 0x3f, 0xdf, 0xa9, 0x53, 0x53, 0x05, 0xf7, 0x98, 0xf4, 0x45, 0xa7,
+	0xc7, 0x68, 0x83, 0x0b, 0x12, 0x86, 0x9d, 0x93, 0x86, 0x7f, 0xca, 0xb1, 0x74, 0xb8, 0x60, 0x3e,
+	0x43, 0xd5, 0x24, 0xeb, 0xe8, 0xec, 0xb2, 0xed, 0x63, 0xda, 0xc7, 0x62, 0x48, 0xa8, 0x5f, 0xf4,
+	0xc5, 0xf2, 0x72, 0xea, 0xa7, 0xd4, 0x91, 0xdc, 0x92, 0xc7, 0x3c, 0xa6, 0x7e, 0x36, 0xa2, 0x5f,
+	0xb1, 0x5a, 0x7f, 0x06, 0x0b, 0x87, 0xee, 0x6e, 0x4b, 0x30, 0x36, 0x70, 0xf1, 0x87, 0x00, 0x4b,
+	0x1f, 0xd5, 0xc0, 0x1a, 0x62, 0x29, 0x3b, 0x1e, 0xae, 0x99, 0x6b, 0xe6, 0xfa, 0xac, 0x9b, 0x84,
+	0x75, 0x0c, 0xd5, 0x6c, 0xb1, 0xe4, 0x8c, 0x4a, 0x8c, 0x96, 0xa0, 0xc2, 0x23, 0x41, 0xaf, 0x8d,
+	0x03, 0xf4, 0x02, 0x2a, 0x58, 0x08, 0x26, 0x6a, 0x37, 0xd6, 0xcc, 0xf5, 0x99, 0x8d, 0x47, 0x4e,
+	0x06, 0x9e, 0x34, 0xe3, 0xb8, 0x78, 0xc8, 0x7c, 0x7c, 0x40, 0x3c, 0x8a, 0xc5, 0x4e, 0xb4, 0xd8,
+	0x8d, 0xbf, 0xa9, 0x7f, 0x82, 0x5a, 0xa4, 0x1e, 0x32, 0x1f, 0xef, 0x7c, 0xf4, 0x31, 0x95, 0x84,
+	0xd1, 0x04, 0x6e, 0x1b, 0xe6, 0x43, 0xe6, 0xe3, 0x36, 0x4e, 0x12, 0xaa, 0xee, 0xcc, 0xc6, 0x7d,
+	0x27, 0xdd, 0xac, 0xb8, 0xe9, 0xd1, 0xaf, 0xe7, 0xc2, 0x7c, 0x88, 0xee, 0xc2, 0x74, 0xef, 0xb8,
+	0x43, 0x68, 0x9b, 0xf4, 0x15, 0xe1, 0x4d, 0xd7, 0x52, 0xf1, 0xab, 0x7e, 0xfd, 0x8b, 0x09, 0x2b,
+	0x8a, 0xa9, 0x3f, 0x56, 0x5f, 0xf7, 0xfb, 0xfa, 0x9f, 0x00, 0xb6, 0x26, 0xcf, 0xbe, 0xaf, 0x1a,
+	0xe3, 0x18, 0xff, 0xb5, 0x4b, 0x5f, 0xa7, 0xc1, 0xda, 0x8f, 0x07, 0x83, 0xf6, 0x60, 0x81, 0x07,
+	0xdd, 0xf6, 0x7b, 0x7c, 0xda, 0x16, 0xf1, 0x46, 0x69, 0xaa, 0x07, 0x45, 0x96, 0xad, 0xa0, 0xbb,
+	0x87, 0x4f, 0xf5, 0x8e, 0x36, 0x0d, 0x77, 0x8e, 0xe7, 0x05, 0xf4, 0x06, 0xaa, 0x99, 0x59, 0xdc,
+	0xb5, 0x06, 0xac, 0x5f, 0xe7, 0x16, 0xaf, 0x6c, 0x1a, 0xee, 0x3c, 0x1f, 0x51, 0xd0, 0x5b, 0x58,
+	0x94, 0xc4, 0xa3, 0x6d, 0xb5, 0x6d, 0x09, 0xde, 0x84, 0x32, 0x7c, 0x58, 0x64, 0x98, 0xcc, 0x3e,
+	0x03, 0x5c, 0x90, 0xa3, 0x12, 0x3a, 0x82, 0x25, 0xa9, 0x66, 0x94, 0x98, 0x6a, 0xcc, 0x49, 0xe5,
+	0xfa, 0xb8, 0xcc, 0x35, 0x9e, 0x69, 0x0e, 0x15, 0xc9, 0x2b, 0x2a, 0x7a, 0x07, 0xb7, 0x15, 0x2e,
+	0x17, 0x8c, 0x33, 0xd9, 0x39, 0x49, 0x91, 0x2b, 0xca, 0xfc, 0x49, 0x99, 0x79, 0x4b, 0xaf, 0xcf,
+	0xb0, 0x6f, 0xc9, 0xab, 0x32, 0x1a, 0x40, 0x4d, 0xa3, 0xe7, 0x0a, 0x68, 0xfc, 0x29, 0x55, 0xe1,
+	0x69, 0x39, 0x7e, 0x66, 0x96, 0xb6, 0x70, 0x47, 0x16, 0x66, 0xd0, 0x36, 0xcc, 0x72, 0x42, 0xbd,
+	0x94, 0xde, 0x52, 0xde, 0xab, 0x85, 0x13, 0x24, 0xd4, 0xcb, 0xa8, 0x67, 0x78, 0x16, 0xa2, 0x97,
+	0x30, 0xa7, 0x5d, 0x34, 0xe2, 0xb4, 0xb2, 0x59, 0x2b, 0xb7, 0x49, 0xc1, 0x66, 0x79, 0x2e, 0x46,
+	0x2d, 0x58, 0x0c, 0xc5, 0xa0, 0xad, 0x6e, 0x87, 0x94, 0xe9, 0xa7, 0xa5, 0x0f, 0xe9, 0xf8, 0x45,
+	0xe7, 0x8c, 0xdd, 0x49, 0xd1, 0x19, 0x08, 0xc5, 0x20, 0x2f, 0xa1, 0x03, 0x40, 0x79, 0x47, 0xcd,
+	0xf7, 0xcb, 0xd2, 0x27, 0xf5, 0x1a, 0xcb, 0x14, 0xb1, 0x9a, 0x79, 0x6a, 0xcc, 0x13, 0x58, 0xc9,
+	0xce, 0x6a, 0xfa, 0x17, 0x4f, 0x81, 0x7f, 0x5b, 0x7a, 0x42, 0x57, 0xdc, 0xcb, 0x2e, 0xac, 0xa6,
+	0xe1, 0xd6, 0x64, 0x49, 0x0e, 0x05, 0x60, 0xe7, 0x8f, 0x71, 0xbe, 0x9e, 0x6e, 0xe7, 0x4f, 0x5c,
+	0xf0, 0x79, 0x71, 0xc1, 0x92, 0x3b, 0xaa, 0x69, 0xb8, 0x2b, 0xb2, 0x3c, 0xbd, 0x55, 0x81, 0x09,
+	0x19, 0x0c, 0xb7, 0xf6, 0xcf, 0x2e, 0x6c, 0xf3, 0xfc, 0xc2, 0x36, 0x7f, 0x5c, 0xd8, 0xe6, 0xe7,
+	0x4b, 0xdb, 0x38, 0xbf, 0xb4, 0x8d, 0x6f, 0x97, 0xb6, 0x71, 0xb4, 0xe9, 0x11, 0xff, 0x38, 0xe8,
+	0x3a, 0x3d, 0x36, 0x6c, 0xec, 0x12, 0x2a, 0x7b, 0xc7, 0xa4, 0xd3, 0xc8, 0x3d, 0x55, 0xd1, 0x1b,
+	0x32, 0xfe, 0x72, 0x75, 0xa7, 0x94, 0xbe, 0xf9, 0x77, 0x00, 0xe3, 0xfd, 0x76, 0x5b, 0xd4, 0x06,
+	0x00, 0x00,
 }
 
 func (m *VRFProofRequest) Marshal() (dAtA []byte, err error) {
@@ -426,6 +572,93 @@ func (m *VRFProofResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *SignVoteExtensionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SignVoteExtensionRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SignVoteExtensionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.ChainId) > 0 {
+		i -= len(m.ChainId)
+		copy(dAtA[i:], m.ChainId)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.ChainId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.VoteExtension != nil {
+		{
+			size, err := m.VoteExtension.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SignedVoteExtensionResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SignedVoteExtensionResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SignedVoteExtensionResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Error != nil {
+		{
+			size, err := m.Error.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	{
+		size, err := m.VoteExtension.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintTypes(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *Message) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -672,6 +905,52 @@ func (m *Message_VrfProofResponse) MarshalToSizedBuffer(dAtA []byte) (int, error
 	}
 	return len(dAtA) - i, nil
 }
+func (m *Message_SignVoteExtensionRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Message_SignVoteExtensionRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.SignVoteExtensionRequest != nil {
+		{
+			size, err := m.SignVoteExtensionRequest.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3e
+		i--
+		dAtA[i] = 0xd2
+	}
+	return len(dAtA) - i, nil
+}
+func (m *Message_SignedVoteExtensionResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Message_SignedVoteExtensionResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.SignedVoteExtensionResponse != nil {
+		{
+			size, err := m.SignedVoteExtensionResponse.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3e
+		i--
+		dAtA[i] = 0xda
+	}
+	return len(dAtA) - i, nil
+}
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTypes(v)
 	base := offset
@@ -713,6 +992,38 @@ func (m *VRFProofResponse) Size() (n int) {
 	return n
 }
 
+func (m *SignVoteExtensionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.VoteExtension != nil {
+		l = m.VoteExtension.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	l = len(m.ChainId)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
+func (m *SignedVoteExtensionResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.VoteExtension.Size()
+	n += 1 + l + sovTypes(uint64(l))
+	if m.Error != nil {
+		l = m.Error.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
 func (m *Message) Size() (n int) {
 	if m == nil {
 		return 0
@@ -845,6 +1156,30 @@ func (m *Message_VrfProofResponse) Size() (n int) {
 	}
 	return n
 }
+func (m *Message_SignVoteExtensionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SignVoteExtensionRequest != nil {
+		l = m.SignVoteExtensionRequest.Size()
+		n += 2 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+func (m *Message_SignedVoteExtensionResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SignedVoteExtensionResponse != nil {
+		l = m.SignedVoteExtensionResponse.Size()
+		n += 2 + l + sovTypes(uint64(l))
+	}
+	return n
+}
 
 func sovTypes(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
@@ -1056,6 +1391,243 @@ func (m *VRFProofResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *SignVoteExtensionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SignVoteExtensionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SignVoteExtensionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteExtension", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.VoteExtension == nil {
+				m.VoteExtension = &types.VoteExtension{}
+			}
+			if err := m.VoteExtension.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChainId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChainId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SignedVoteExtensionResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SignedVoteExtensionResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SignedVoteExtensionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteExtension", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.VoteExtension.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Error == nil {
+				m.Error = &privval.RemoteSignerError{}
+			}
+			if err := m.Error.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *Message) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1435,6 +2007,76 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Message_VrfProofResponse{v}
 			iNdEx = postIndex
+		case 1002:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignVoteExtensionRequest", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &SignVoteExtensionRequest{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Message_SignVoteExtensionRequest{v}
+			iNdEx = postIndex
+		case 1003:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedVoteExtensionResponse", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &SignedVoteExtensionResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Message_SignedVoteExtensionResponse{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
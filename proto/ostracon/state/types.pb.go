@@ -58,6 +58,8 @@ type State struct {
 	AppHash []byte `protobuf:"bytes,13,opt,name=app_hash,json=appHash,proto3" json:"app_hash,omitempty"`
 	// the VRF Proof value generated by the last Proposer
 	LastProofHash []byte `protobuf:"bytes,1000,opt,name=last_proof_hash,json=lastProofHash,proto3" json:"last_proof_hash,omitempty"`
+	// the size of the block parts, or zero for the default size
+	BlockPartSizeBytes uint32 `protobuf:"varint,1001,opt,name=block_part_size_bytes,json=blockPartSizeBytes,proto3" json:"block_part_size_bytes,omitempty"`
 }
 
 func (m *State) Reset()         { *m = State{} }
@@ -198,6 +200,13 @@ func (m *State) GetLastProofHash() []byte {
 	return nil
 }
 
+func (m *State) GetBlockPartSizeBytes() uint32 {
+	if m != nil {
+		return m.BlockPartSizeBytes
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*State)(nil), "ostracon.state.State")
 }
@@ -205,45 +214,46 @@ func init() {
 func init() { proto.RegisterFile("ostracon/state/types.proto", fileDescriptor_898987a4421067cd) }
 
 var fileDescriptor_898987a4421067cd = []byte{
-	// 593 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0x3f, 0x6f, 0xd3, 0x40,
-	0x18, 0xc6, 0x63, 0xfa, 0xc7, 0xe9, 0xa5, 0x49, 0xc0, 0x30, 0xb8, 0x06, 0x1c, 0x83, 0xf8, 0x13,
-	0x31, 0xd8, 0x02, 0x26, 0x16, 0x06, 0xa7, 0x82, 0x46, 0x54, 0xa8, 0x72, 0x51, 0x07, 0x16, 0xeb,
-	0x62, 0x5f, 0xed, 0x13, 0xce, 0x9d, 0xe5, 0xbb, 0x54, 0xf0, 0x15, 0x98, 0xfa, 0xb1, 0x3a, 0x76,
-	0x64, 0x2a, 0x28, 0x59, 0xf8, 0x18, 0xe8, 0xee, 0x6c, 0xc7, 0xad, 0x19, 0xba, 0x25, 0xcf, 0xfb,
-	0x7b, 0x1f, 0x3f, 0xf7, 0xde, 0xab, 0x03, 0x16, 0x65, 0xbc, 0x80, 0x11, 0x25, 0x1e, 0xe3, 0x90,
-	0x23, 0x8f, 0xff, 0xc8, 0x11, 0x73, 0xf3, 0x82, 0x72, 0x6a, 0x0c, 0xaa, 0x9a, 0x2b, 0x6b, 0xd6,
-	0x83, 0x84, 0x26, 0x54, 0x96, 0x3c, 0xf1, 0x4b, 0x51, 0x96, 0xc3, 0x11, 0x89, 0x51, 0x31, 0xc7,
-	0x84, 0xab, 0x6e, 0xef, 0x0c, 0x66, 0x38, 0x86, 0x9c, 0x16, 0x25, 0xf1, 0xb8, 0x45, 0xe4, 0xb0,
-	0x80, 0xf3, 0xf2, 0x33, 0xd6, 0xa3, 0x56, 0xb9, 0x11, 0xc2, 0x1a, 0x25, 0x94, 0x26, 0x19, 0xf2,
-	0xe4, 0xbf, 0xd9, 0xe2, 0xd4, 0xe3, 0x78, 0x8e, 0x18, 0x87, 0xf3, 0xfc, 0x3f, 0xed, 0xad, 0x33,
-	0x3c, 0xfd, 0xa9, 0x83, 0xad, 0x63, 0xa1, 0x1a, 0xef, 0x80, 0x7e, 0x86, 0x0a, 0x86, 0x29, 0x31,
-	0x35, 0x47, 0x1b, 0xf7, 0xde, 0xec, 0xb9, 0xeb, 0x4e, 0x75, 0x42, 0xf7, 0x44, 0x01, 0xfe, 0xe6,
-	0xc5, 0xd5, 0xa8, 0x13, 0x54, 0xbc, 0xf1, 0x02, 0x74, 0xa3, 0x14, 0x62, 0x12, 0xe2, 0xd8, 0xbc,
-	0xe3, 0x68, 0xe3, 0x1d, 0xbf, 0xb7, 0xbc, 0x1a, 0xe9, 0x13, 0xa1, 0x4d, 0xf7, 0x03, 0x5d, 0x16,
-	0xa7, 0xb1, 0xf1, 0x1c, 0x0c, 0x30, 0xc1, 0x1c, 0xc3, 0x2c, 0x4c, 0x11, 0x4e, 0x52, 0x6e, 0x0e,
-	0x1c, 0x6d, 0xbc, 0x11, 0xf4, 0x4b, 0xf5, 0x40, 0x8a, 0xc6, 0x2b, 0x70, 0x2f, 0x83, 0x8c, 0x87,
-	0xb3, 0x8c, 0x46, 0xdf, 0x2a, 0x72, 0x43, 0x92, 0x43, 0x51, 0xf0, 0x85, 0x5e, 0xb2, 0x01, 0xe8,
-	0x37, 0x58, 0x1c, 0x9b, 0x9b, 0xed, 0xec, 0xea, 0xbc, 0xb2, 0x6b, 0xba, 0xef, 0xdf, 0x17, 0xd9,
-	0x97, 0x57, 0xa3, 0xde, 0x61, 0x65, 0x35, 0xdd, 0x0f, 0x7a, 0xb5, 0xef, 0x34, 0x36, 0x0e, 0xc1,
-	0xb0, 0xe1, 0x29, 0xe6, 0x69, 0x6e, 0x49, 0x57, 0xcb, 0x55, 0xc3, 0x76, 0xab, 0x61, 0xbb, 0x5f,
-	0xaa, 0x61, 0xfb, 0x5d, 0x61, 0x7b, 0xfe, 0x7b, 0xa4, 0x05, 0xfd, 0xda, 0x4b, 0x54, 0x8d, 0x8f,
-	0x60, 0x48, 0xd0, 0x77, 0x1e, 0xd6, 0xb7, 0xce, 0xcc, 0x6d, 0xe9, 0x66, 0xb7, 0x33, 0x9e, 0x54,
-	0xcc, 0x31, 0xe2, 0xc1, 0x40, 0xb4, 0xd5, 0x0a, 0x33, 0xde, 0x03, 0xd0, 0xf0, 0xd0, 0x6f, 0xe5,
-	0xd1, 0xe8, 0x10, 0x41, 0xe4, 0xb1, 0x1a, 0x26, 0xdd, 0xdb, 0x05, 0x11, 0x6d, 0x8d, 0x20, 0x13,
-	0x60, 0x4b, 0x23, 0x75, 0x33, 0x0d, 0xbf, 0x30, 0x4a, 0x21, 0x49, 0x50, 0x6c, 0xee, 0xc8, 0xcb,
-	0x7a, 0x28, 0x28, 0x75, 0x4f, 0xeb, 0xee, 0x89, 0x42, 0x8c, 0x00, 0xdc, 0x8d, 0x28, 0x61, 0x88,
-	0xb0, 0x05, 0x0b, 0xd5, 0xbe, 0x9b, 0x40, 0xc6, 0x79, 0xd2, 0x8e, 0x33, 0xa9, 0xc8, 0x23, 0x09,
-	0x96, 0xfb, 0x37, 0x8c, 0xae, 0xcb, 0xc6, 0x67, 0xf0, 0xac, 0x19, 0xec, 0xa6, 0x7f, 0x1d, 0xaf,
-	0x27, 0xe3, 0x39, 0xeb, 0x78, 0x37, 0xfc, 0xab, 0x8c, 0xd5, 0x22, 0x16, 0x88, 0x2d, 0x32, 0xce,
-	0xc2, 0x14, 0xb2, 0xd4, 0xdc, 0x75, 0xb4, 0xf1, 0xae, 0x5a, 0xc4, 0x40, 0xe9, 0x07, 0x90, 0xa5,
-	0xc6, 0x1e, 0xe8, 0xc2, 0x3c, 0x57, 0x48, 0x5f, 0x22, 0x3a, 0xcc, 0x73, 0x59, 0x7a, 0x59, 0x0e,
-	0x3e, 0x2f, 0x28, 0x3d, 0x55, 0xc4, 0x5f, 0x5d, 0x22, 0x72, 0x55, 0x8e, 0x84, 0x2c, 0x40, 0xff,
-	0xd3, 0xc5, 0xd2, 0xd6, 0x2e, 0x97, 0xb6, 0xf6, 0x67, 0x69, 0x6b, 0xe7, 0x2b, 0xbb, 0x73, 0xb9,
-	0xb2, 0x3b, 0xbf, 0x56, 0x76, 0xe7, 0xeb, 0xeb, 0x04, 0xf3, 0x74, 0x31, 0x73, 0x23, 0x3a, 0xf7,
-	0x3e, 0x60, 0xc2, 0xa2, 0x14, 0x43, 0xaf, 0x7e, 0x9a, 0xd4, 0x9b, 0x73, 0xfd, 0xa5, 0x9a, 0x6d,
-	0x4b, 0xf5, 0xed, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x3f, 0xbb, 0x81, 0xf5, 0xc2, 0x04, 0x00,
-	0x00,
+	// 623 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0x4f, 0x6f, 0xd3, 0x30,
+	0x18, 0xc6, 0x1b, 0xf6, 0x27, 0x9d, 0xbb, 0xb6, 0x60, 0x40, 0xca, 0x0a, 0xa4, 0x01, 0xf1, 0xa7,
+	0xe2, 0x90, 0x88, 0x71, 0xe2, 0xc2, 0x21, 0x9d, 0x60, 0x15, 0x13, 0x9a, 0x32, 0xb4, 0x03, 0x97,
+	0xc8, 0x4d, 0xbc, 0xc4, 0x22, 0x8d, 0xa3, 0xd8, 0x9d, 0xd8, 0x3e, 0xc5, 0x3e, 0x11, 0xe7, 0x1d,
+	0x77, 0xe4, 0x34, 0x50, 0x77, 0x81, 0x6f, 0x81, 0x6c, 0x27, 0x69, 0xb6, 0x70, 0xd8, 0xad, 0x7d,
+	0x9f, 0xdf, 0xfb, 0xe4, 0xf1, 0xeb, 0x57, 0x06, 0x03, 0xca, 0x78, 0x8e, 0x02, 0x9a, 0x3a, 0x8c,
+	0x23, 0x8e, 0x1d, 0x7e, 0x92, 0x61, 0x66, 0x67, 0x39, 0xe5, 0x14, 0xf6, 0x4a, 0xcd, 0x96, 0xda,
+	0xe0, 0x41, 0x44, 0x23, 0x2a, 0x25, 0x47, 0xfc, 0x52, 0xd4, 0xc0, 0xe2, 0x38, 0x0d, 0x71, 0x3e,
+	0x23, 0x29, 0x57, 0xdd, 0xce, 0x31, 0x4a, 0x48, 0x88, 0x38, 0xcd, 0x0b, 0xe2, 0x49, 0x83, 0xc8,
+	0x50, 0x8e, 0x66, 0xc5, 0x67, 0x06, 0x8f, 0x1b, 0x72, 0x2d, 0xc4, 0x60, 0x18, 0x51, 0x1a, 0x25,
+	0xd8, 0x91, 0xff, 0xa6, 0xf3, 0x23, 0x87, 0x93, 0x19, 0x66, 0x1c, 0xcd, 0xb2, 0xff, 0xb4, 0x37,
+	0xce, 0xf0, 0xec, 0x87, 0x0e, 0xd6, 0x0e, 0x44, 0x15, 0xbe, 0x03, 0xfa, 0x31, 0xce, 0x19, 0xa1,
+	0xa9, 0xa1, 0x59, 0xda, 0xa8, 0xb3, 0xbd, 0x65, 0x2f, 0x3b, 0xd5, 0x09, 0xed, 0x43, 0x05, 0xb8,
+	0xab, 0xe7, 0x97, 0xc3, 0x96, 0x57, 0xf2, 0xf0, 0x25, 0x68, 0x07, 0x31, 0x22, 0xa9, 0x4f, 0x42,
+	0xe3, 0x8e, 0xa5, 0x8d, 0x36, 0xdc, 0xce, 0xe2, 0x72, 0xa8, 0x8f, 0x45, 0x6d, 0xb2, 0xe3, 0xe9,
+	0x52, 0x9c, 0x84, 0xf0, 0x05, 0xe8, 0x91, 0x94, 0x70, 0x82, 0x12, 0x3f, 0xc6, 0x24, 0x8a, 0xb9,
+	0xd1, 0xb3, 0xb4, 0xd1, 0x8a, 0xd7, 0x2d, 0xaa, 0xbb, 0xb2, 0x08, 0x5f, 0x83, 0x7b, 0x09, 0x62,
+	0xdc, 0x9f, 0x26, 0x34, 0xf8, 0x56, 0x92, 0x2b, 0x92, 0xec, 0x0b, 0xc1, 0x15, 0xf5, 0x82, 0xf5,
+	0x40, 0xb7, 0xc6, 0x92, 0xd0, 0x58, 0x6d, 0x66, 0x57, 0xe7, 0x95, 0x5d, 0x93, 0x1d, 0xf7, 0xbe,
+	0xc8, 0xbe, 0xb8, 0x1c, 0x76, 0xf6, 0x4a, 0xab, 0xc9, 0x8e, 0xd7, 0xa9, 0x7c, 0x27, 0x21, 0xdc,
+	0x03, 0xfd, 0x9a, 0xa7, 0x98, 0xa7, 0xb1, 0x26, 0x5d, 0x07, 0xb6, 0x1a, 0xb6, 0x5d, 0x0e, 0xdb,
+	0xfe, 0x52, 0x0e, 0xdb, 0x6d, 0x0b, 0xdb, 0xb3, 0x5f, 0x43, 0xcd, 0xeb, 0x56, 0x5e, 0x42, 0x85,
+	0x1f, 0x41, 0x3f, 0xc5, 0xdf, 0xb9, 0x5f, 0xdd, 0x3a, 0x33, 0xd6, 0xa5, 0x9b, 0xd9, 0xcc, 0x78,
+	0x58, 0x32, 0x07, 0x98, 0x7b, 0x3d, 0xd1, 0x56, 0x55, 0x18, 0x7c, 0x0f, 0x40, 0xcd, 0x43, 0xbf,
+	0x95, 0x47, 0xad, 0x43, 0x04, 0x91, 0xc7, 0xaa, 0x99, 0xb4, 0x6f, 0x17, 0x44, 0xb4, 0xd5, 0x82,
+	0x8c, 0x81, 0x29, 0x8d, 0xd4, 0xcd, 0xd4, 0xfc, 0xfc, 0x20, 0x46, 0x69, 0x84, 0x43, 0x63, 0x43,
+	0x5e, 0xd6, 0x23, 0x41, 0xa9, 0x7b, 0x5a, 0x76, 0x8f, 0x15, 0x02, 0x3d, 0x70, 0x37, 0xa0, 0x29,
+	0xc3, 0x29, 0x9b, 0x33, 0x5f, 0xed, 0xbb, 0x01, 0x64, 0x9c, 0xa7, 0xcd, 0x38, 0xe3, 0x92, 0xdc,
+	0x97, 0x60, 0xb1, 0x7f, 0xfd, 0xe0, 0x7a, 0x19, 0x7e, 0x06, 0xcf, 0xeb, 0xc1, 0x6e, 0xfa, 0x57,
+	0xf1, 0x3a, 0x32, 0x9e, 0xb5, 0x8c, 0x77, 0xc3, 0xbf, 0xcc, 0x58, 0x2e, 0x62, 0x8e, 0xd9, 0x3c,
+	0xe1, 0xcc, 0x8f, 0x11, 0x8b, 0x8d, 0x4d, 0x4b, 0x1b, 0x6d, 0xaa, 0x45, 0xf4, 0x54, 0x7d, 0x17,
+	0xb1, 0x18, 0x6e, 0x81, 0x36, 0xca, 0x32, 0x85, 0x74, 0x25, 0xa2, 0xa3, 0x2c, 0x93, 0xd2, 0xab,
+	0x62, 0xf0, 0x59, 0x4e, 0xe9, 0x91, 0x22, 0xfe, 0xe8, 0x12, 0x91, 0xab, 0xb2, 0x2f, 0xca, 0x12,
+	0xdc, 0x06, 0x0f, 0xd5, 0xce, 0x65, 0x28, 0xe7, 0x3e, 0x23, 0xa7, 0xd8, 0x9f, 0x9e, 0x70, 0xcc,
+	0x8c, 0xbf, 0x02, 0xef, 0x7a, 0x50, 0xaa, 0xfb, 0x28, 0xe7, 0x07, 0xe4, 0x14, 0xbb, 0x42, 0x72,
+	0x3f, 0x9d, 0x2f, 0x4c, 0xed, 0x62, 0x61, 0x6a, 0xbf, 0x17, 0xa6, 0x76, 0x76, 0x65, 0xb6, 0x2e,
+	0xae, 0xcc, 0xd6, 0xcf, 0x2b, 0xb3, 0xf5, 0xf5, 0x4d, 0x44, 0x78, 0x3c, 0x9f, 0xda, 0x01, 0x9d,
+	0x39, 0x1f, 0x48, 0xca, 0x82, 0x98, 0x20, 0xa7, 0x7a, 0xce, 0xd4, 0x3b, 0x75, 0xfd, 0x75, 0x9b,
+	0xae, 0xcb, 0xea, 0xdb, 0x7f, 0x03, 0x00, 0xc7, 0x3b, 0x62, 0x87, 0xf6, 0x04, 0x00, 0x00,
 }
 
 func (m *State) Marshal() (dAtA []byte, err error) {
@@ -266,6 +276,13 @@ func (m *State) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.BlockPartSizeBytes != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.BlockPartSizeBytes))
+		i--
+		dAtA[i] = 0x3e
+		i--
+		dAtA[i] = 0xc8
+	}
 	if len(m.LastProofHash) > 0 {
 		i -= len(m.LastProofHash)
 		copy(dAtA[i:], m.LastProofHash)
@@ -458,6 +475,9 @@ func (m *State) Size() (n int) {
 	if l > 0 {
 		n += 2 + l + sovTypes(uint64(l))
 	}
+	if m.BlockPartSizeBytes != 0 {
+		n += 2 + sovTypes(uint64(m.BlockPartSizeBytes))
+	}
 	return n
 }
 
@@ -946,6 +966,25 @@ func (m *State) Unmarshal(dAtA []byte) error {
 				m.LastProofHash = []byte{}
 			}
 			iNdEx = postIndex
+		case 1001:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockPartSizeBytes", wireType)
+			}
+			m.BlockPartSizeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BlockPartSizeBytes |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
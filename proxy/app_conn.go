@@ -23,6 +23,11 @@ type AppConnConsensus interface {
 	DeliverTxAsync(types.RequestDeliverTx, abcicli.ResponseCallback) *abcicli.ReqRes
 	EndBlockSync(types.RequestEndBlock) (*types.ResponseEndBlock, error)
 	CommitSync() (*types.ResponseCommit, error)
+
+	PrepareProposalSync(ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error)
+	ProcessProposalSync(ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error)
+	ExtendVoteSync(ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error)
+	VerifyVoteExtensionSync(ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error)
 }
 
 type AppConnMempool interface {
@@ -30,6 +35,7 @@ type AppConnMempool interface {
 	Error() error
 
 	CheckTxAsync(types.RequestCheckTx, abcicli.ResponseCallback) *abcicli.ReqRes
+	CheckTxBatchAsync([]types.RequestCheckTx, []abcicli.ResponseCallback) []*abcicli.ReqRes
 	CheckTxSync(types.RequestCheckTx) (*ocabci.ResponseCheckTx, error)
 
 	BeginRecheckTxSync(ocabci.RequestBeginRecheckTx) (*ocabci.ResponseBeginRecheckTx, error)
@@ -99,6 +105,22 @@ func (app *appConnConsensus) CommitSync() (*types.ResponseCommit, error) {
 	return app.appConn.CommitSync()
 }
 
+func (app *appConnConsensus) PrepareProposalSync(req ocabci.RequestPrepareProposal) (*ocabci.ResponsePrepareProposal, error) {
+	return app.appConn.PrepareProposalSync(req)
+}
+
+func (app *appConnConsensus) ProcessProposalSync(req ocabci.RequestProcessProposal) (*ocabci.ResponseProcessProposal, error) {
+	return app.appConn.ProcessProposalSync(req)
+}
+
+func (app *appConnConsensus) ExtendVoteSync(req ocabci.RequestExtendVote) (*ocabci.ResponseExtendVote, error) {
+	return app.appConn.ExtendVoteSync(req)
+}
+
+func (app *appConnConsensus) VerifyVoteExtensionSync(req ocabci.RequestVerifyVoteExtension) (*ocabci.ResponseVerifyVoteExtension, error) {
+	return app.appConn.VerifyVoteExtensionSync(req)
+}
+
 //------------------------------------------------
 // Implements AppConnMempool (subset of abcicli.Client)
 
@@ -132,6 +154,11 @@ func (app *appConnMempool) CheckTxAsync(req types.RequestCheckTx, cb abcicli.Res
 	return app.appConn.CheckTxAsync(req, cb)
 }
 
+func (app *appConnMempool) CheckTxBatchAsync(
+	reqs []types.RequestCheckTx, cbs []abcicli.ResponseCallback) []*abcicli.ReqRes {
+	return app.appConn.CheckTxBatchAsync(reqs, cbs)
+}
+
 func (app *appConnMempool) CheckTxSync(req types.RequestCheckTx) (*ocabci.ResponseCheckTx, error) {
 	return app.appConn.CheckTxSync(req)
 }
@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"sync/atomic"
+
+	"github.com/tendermint/tendermint/abci/types"
+
+	abcicli "github.com/Finschia/ostracon/abci/client"
+	ocabci "github.com/Finschia/ostracon/abci/types"
+)
+
+// clientPool spreads the requests of a connection over several abci clients,
+// sending each one to the client with the fewest pending requests, so a slow
+// request only delays the ones sent to the same client.
+type clientPool struct {
+	clients []abcicli.Client
+	pending []int64 // atomic, by client
+}
+
+func newClientPool(clients []abcicli.Client) *clientPool {
+	return &clientPool{
+		clients: clients,
+		pending: make([]int64, len(clients)),
+	}
+}
+
+// acquire returns the client with the fewest pending requests, and the
+// function to call once the request sent to it is done.
+func (p *clientPool) acquire() (abcicli.Client, func()) {
+	best := 0
+	for i := 1; i < len(p.clients); i++ {
+		if atomic.LoadInt64(&p.pending[i]) < atomic.LoadInt64(&p.pending[best]) {
+			best = i
+		}
+	}
+	atomic.AddInt64(&p.pending[best], 1)
+	return p.clients[best], func() { atomic.AddInt64(&p.pending[best], -1) }
+}
+
+// acquireAsync is acquire for an async request, whose callback releases the
+// client.
+func (p *clientPool) acquireAsync(cb abcicli.ResponseCallback) (abcicli.Client, abcicli.ResponseCallback) {
+	c, release := p.acquire()
+	return c, func(res *ocabci.Response) {
+		release()
+		if cb != nil {
+			cb(res)
+		}
+	}
+}
+
+func (p *clientPool) error() error {
+	for _, c := range p.clients {
+		if err := c.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//------------------------------------------------
+// Implements AppConnMempool over a pool of clients
+
+type appConnMempoolPool struct {
+	pool *clientPool
+}
+
+// NewAppConnMempoolPool returns the mempool connection sending the requests
+// over the clients. The txs are checked concurrently, so the application must
+// not depend on the order they're checked in, e.g. the sequences of the txs of
+// a same account.
+func NewAppConnMempoolPool(appConns []abcicli.Client) AppConnMempool {
+	if len(appConns) == 1 {
+		return NewAppConnMempool(appConns[0])
+	}
+	return &appConnMempoolPool{
+		pool: newClientPool(appConns),
+	}
+}
+
+func (app *appConnMempoolPool) SetGlobalCallback(globalCb abcicli.GlobalCallback) {
+	for _, c := range app.pool.clients {
+		c.SetGlobalCallback(globalCb)
+	}
+}
+
+func (app *appConnMempoolPool) Error() error {
+	return app.pool.error()
+}
+
+// FlushAsync flushes all the clients, calling cb once they're all flushed.
+func (app *appConnMempoolPool) FlushAsync(cb abcicli.ResponseCallback) *abcicli.ReqRes {
+	reqRes := abcicli.NewReqRes(ocabci.ToRequestFlush(), cb)
+	pending := int32(len(app.pool.clients))
+	for _, c := range app.pool.clients {
+		c.FlushAsync(func(res *ocabci.Response) {
+			if atomic.AddInt32(&pending, -1) == 0 {
+				reqRes.SetDone(res)
+			}
+		})
+	}
+	return reqRes
+}
+
+func (app *appConnMempoolPool) FlushSync() (res *types.ResponseFlush, err error) {
+	for _, c := range app.pool.clients {
+		if res, err = c.FlushSync(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (app *appConnMempoolPool) CheckTxAsync(req types.RequestCheckTx, cb abcicli.ResponseCallback) *abcicli.ReqRes {
+	c, cb := app.pool.acquireAsync(cb)
+	return c.CheckTxAsync(req, cb)
+}
+
+// CheckTxBatchAsync sends the whole batch to the same client, checked in order.
+func (app *appConnMempoolPool) CheckTxBatchAsync(
+	reqs []types.RequestCheckTx, cbs []abcicli.ResponseCallback) []*abcicli.ReqRes {
+	c, release := app.pool.acquire()
+	pending := int32(len(reqs))
+	if pending == 0 {
+		release()
+	}
+	wrapped := make([]abcicli.ResponseCallback, len(cbs))
+	for i, cb := range cbs {
+		cb := cb
+		wrapped[i] = func(res *ocabci.Response) {
+			if atomic.AddInt32(&pending, -1) == 0 {
+				release()
+			}
+			if cb != nil {
+				cb(res)
+			}
+		}
+	}
+	return c.CheckTxBatchAsync(reqs, wrapped)
+}
+
+func (app *appConnMempoolPool) CheckTxSync(req types.RequestCheckTx) (*ocabci.ResponseCheckTx, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.CheckTxSync(req)
+}
+
+func (app *appConnMempoolPool) BeginRecheckTxSync(req ocabci.RequestBeginRecheckTx) (*ocabci.ResponseBeginRecheckTx, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.BeginRecheckTxSync(req)
+}
+
+func (app *appConnMempoolPool) EndRecheckTxSync(req ocabci.RequestEndRecheckTx) (*ocabci.ResponseEndRecheckTx, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.EndRecheckTxSync(req)
+}
+
+//------------------------------------------------
+// Implements AppConnQuery over a pool of clients
+
+type appConnQueryPool struct {
+	pool *clientPool
+}
+
+// NewAppConnQueryPool returns the query connection sending the requests over
+// the clients, for a slow query not to block the others.
+func NewAppConnQueryPool(appConns []abcicli.Client) AppConnQuery {
+	if len(appConns) == 1 {
+		return NewAppConnQuery(appConns[0])
+	}
+	return &appConnQueryPool{
+		pool: newClientPool(appConns),
+	}
+}
+
+func (app *appConnQueryPool) Error() error {
+	return app.pool.error()
+}
+
+func (app *appConnQueryPool) EchoSync(msg string) (*types.ResponseEcho, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.EchoSync(msg)
+}
+
+func (app *appConnQueryPool) InfoSync(req types.RequestInfo) (*types.ResponseInfo, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.InfoSync(req)
+}
+
+func (app *appConnQueryPool) QuerySync(reqQuery types.RequestQuery) (*types.ResponseQuery, error) {
+	c, release := app.pool.acquire()
+	defer release()
+	return c.QuerySync(reqQuery)
+}
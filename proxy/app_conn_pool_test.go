@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/abci/types"
+
+	abcicli "github.com/Finschia/ostracon/abci/client"
+	ocabci "github.com/Finschia/ostracon/abci/types"
+)
+
+// slowQueryApp blocks the queries of the path "/slow" until unblocked.
+type slowQueryApp struct {
+	ocabci.BaseApplication
+	unblock  chan struct{}
+	checked  int32 // atomic
+	rechecks int32 // atomic
+}
+
+func (app *slowQueryApp) Query(req types.RequestQuery) types.ResponseQuery {
+	if req.Path == "/slow" {
+		<-app.unblock
+	}
+	return types.ResponseQuery{Log: req.Path}
+}
+
+func (app *slowQueryApp) CheckTxSync(req types.RequestCheckTx) ocabci.ResponseCheckTx {
+	atomic.AddInt32(&app.checked, 1)
+	return ocabci.ResponseCheckTx{}
+}
+
+func (app *slowQueryApp) CheckTxAsync(req types.RequestCheckTx, callback ocabci.CheckTxCallback) {
+	callback(app.CheckTxSync(req))
+}
+
+func (app *slowQueryApp) BeginRecheckTx(req ocabci.RequestBeginRecheckTx) ocabci.ResponseBeginRecheckTx {
+	atomic.AddInt32(&app.rechecks, 1)
+	return ocabci.ResponseBeginRecheckTx{}
+}
+
+// startLocalClients returns the local clients of the app, which serve the
+// requests concurrently, each with its own mutex.
+func startLocalClients(t *testing.T, app ocabci.Application, n int) []abcicli.Client {
+	clients := make([]abcicli.Client, n)
+	for i := range clients {
+		clients[i] = abcicli.NewLocalClient(nil, app)
+		require.NoError(t, clients[i].Start())
+		c := clients[i]
+		t.Cleanup(func() { _ = c.Stop() })
+	}
+	return clients
+}
+
+func TestAppConnQueryPool(t *testing.T) {
+	app := &slowQueryApp{unblock: make(chan struct{})}
+	conn := NewAppConnQueryPool(startLocalClients(t, app, 2))
+
+	slow := make(chan *types.ResponseQuery)
+	go func() {
+		res, err := conn.QuerySync(types.RequestQuery{Path: "/slow"})
+		assert.NoError(t, err)
+		slow <- res
+	}()
+
+	// the slow query doesn't block the other ones, sent to the other client
+	// once it is pending
+	for i := 0; i < 3; i++ {
+		res, err := conn.QuerySync(types.RequestQuery{Path: "/fast"})
+		require.NoError(t, err)
+		assert.Equal(t, "/fast", res.Log)
+	}
+	select {
+	case <-slow:
+		t.Fatal("the slow query should be blocked")
+	default:
+	}
+
+	close(app.unblock)
+	select {
+	case res := <-slow:
+		assert.Equal(t, "/slow", res.Log)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow query should be done")
+	}
+	assert.NoError(t, conn.Error())
+}
+
+func TestAppConnMempoolPool(t *testing.T) {
+	app := &slowQueryApp{}
+	conn := NewAppConnMempoolPool(startLocalClients(t, app, 3))
+
+	var globalCalls int32
+	conn.SetGlobalCallback(func(*ocabci.Request, *ocabci.Response) {
+		atomic.AddInt32(&globalCalls, 1)
+	})
+
+	var called int32
+	cb := func(*ocabci.Response) { atomic.AddInt32(&called, 1) }
+	for i := 0; i < 5; i++ {
+		conn.CheckTxAsync(types.RequestCheckTx{Tx: []byte{byte(i)}}, cb)
+	}
+	conn.CheckTxBatchAsync(
+		[]types.RequestCheckTx{{Tx: []byte("a")}, {Tx: []byte("b")}},
+		[]abcicli.ResponseCallback{cb, cb})
+	_, err := conn.CheckTxSync(types.RequestCheckTx{Tx: []byte("c")})
+	require.NoError(t, err)
+
+	flushed := make(chan struct{})
+	reqRes := conn.FlushAsync(func(*ocabci.Response) { close(flushed) })
+	reqRes.Wait()
+	<-flushed
+	_, err = conn.FlushSync()
+	require.NoError(t, err)
+
+	// the recheck is begun once
+	_, err = conn.BeginRecheckTxSync(ocabci.RequestBeginRecheckTx{})
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 8, atomic.LoadInt32(&app.checked))
+	assert.EqualValues(t, 7, atomic.LoadInt32(&called))
+	// the global callback is called by all the clients
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&globalCalls), int32(8))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&app.rechecks))
+	assert.NoError(t, conn.Error())
+
+	// the requests done, no client is pending
+	pool := conn.(*appConnMempoolPool).pool
+	for i := range pool.pending {
+		assert.Zero(t, atomic.LoadInt64(&pool.pending[i]))
+	}
+}
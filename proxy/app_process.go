@@ -0,0 +1,308 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	abcicli "github.com/Finschia/ostracon/abci/client"
+	tmnet "github.com/Finschia/ostracon/libs/net"
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+const (
+	// AppExecPrefix prefixes the command line of the application run by the
+	// node in proxy_app, e.g. "exec:/usr/bin/myapp start --addr {abci_addr}".
+	AppExecPrefix = "exec:"
+
+	// AppAddrPlaceholder is replaced with the address the application must
+	// listen to in its command line, also set in the AppAddrEnv variable of
+	// its environment.
+	AppAddrPlaceholder = "{abci_addr}"
+	AppAddrEnv         = "OSTRACON_ABCI_ADDR"
+
+	// the health check fails if the application doesn't accept a connection
+	// within appDialTimeout, and the application is stopped once it failed
+	// appMaxHealthCheckFailures times in a row
+	appDialTimeout            = time.Second
+	appMaxHealthCheckFailures = 3
+	appReadyPollInterval      = 100 * time.Millisecond
+)
+
+// AppProcessConfig configures the supervision of the application process.
+type AppProcessConfig struct {
+	// StartTimeout is the time the application has to accept the connections
+	// once started.
+	StartTimeout time.Duration
+	// StopTimeout is the time the application has to exit once sent SIGTERM,
+	// before being killed.
+	StopTimeout time.Duration
+	// HealthCheckInterval is the interval between the connections to the
+	// application checking it still accepts them (0 not to check).
+	HealthCheckInterval time.Duration
+	// RestartPolicy restarts the application if it exits or doesn't accept
+	// the connections before its StartTimeout.
+	RestartPolicy service.RestartPolicy
+}
+
+// DefaultAppProcessConfig returns the default supervision of the application.
+func DefaultAppProcessConfig() AppProcessConfig {
+	return AppProcessConfig{
+		StartTimeout:        30 * time.Second,
+		StopTimeout:         10 * time.Second,
+		HealthCheckInterval: 10 * time.Second,
+		RestartPolicy: service.RestartPolicy{
+			MaxRestarts: 3,
+			Backoff:     time.Second,
+			MaxBackoff:  30 * time.Second,
+		},
+	}
+}
+
+// AppProcess runs the ABCI application binary, the ClientCreator of the
+// clients connecting to it. It's started before the connections to the
+// application, which it waits to accept them, and stopped after them.
+//
+// Once the connections are made, they fail if the application exits, which
+// stops the node (see AppConns), so the application is only restarted while
+// starting.
+type AppProcess struct {
+	service.BaseService
+
+	path      string
+	args      []string
+	addr      string
+	transport string
+	config    AppProcessConfig
+
+	mtx    tmsync.Mutex
+	cmd    *exec.Cmd
+	exited chan struct{} // closed once cmd exited
+	err    error         // exit error of cmd
+
+	quit chan struct{}
+}
+
+var _ ClientCreator = (*AppProcess)(nil)
+
+// NewAppProcess returns the process running the command line (without
+// AppExecPrefix), which the clients connect to at the address (e.g.
+// "unix:///path/abci.sock") with the transport ("socket" or "grpc"). The
+// arguments of the command line are separated by spaces.
+func NewAppProcess(cmdline, addr, transport string, config AppProcessConfig) (*AppProcess, error) {
+	fields := strings.Fields(cmdline)
+	if len(fields) == 0 {
+		return nil, errors.New("empty application command line")
+	}
+	args := make([]string, len(fields)-1)
+	for i, arg := range fields[1:] {
+		args[i] = strings.ReplaceAll(arg, AppAddrPlaceholder, addr)
+	}
+	p := &AppProcess{
+		path:      fields[0],
+		args:      args,
+		addr:      addr,
+		transport: transport,
+		config:    config,
+		quit:      make(chan struct{}),
+	}
+	p.BaseService = *service.NewBaseService(nil, "AppProcess", p)
+	return p, nil
+}
+
+// NewABCIClient implements ClientCreator.
+func (p *AppProcess) NewABCIClient() (abcicli.Client, error) {
+	client, err := abcicli.NewClient(p.addr, p.transport, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	return client, nil
+}
+
+// Addr returns the address of the application.
+func (p *AppProcess) Addr() string {
+	return p.addr
+}
+
+// Exited returns a channel closed once the running application exited.
+func (p *AppProcess) Exited() <-chan struct{} {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.exited
+}
+
+// OnStart implements service.Service by starting the application, restarted
+// until it accepts the connections.
+func (p *AppProcess) OnStart() error {
+	policy := p.config.RestartPolicy
+	for restart := 0; ; restart++ {
+		err := p.startProcess()
+		if err == nil {
+			err = p.waitReady()
+			if err == nil {
+				break
+			}
+			p.stopProcess()
+		}
+		if restart >= policy.MaxRestarts {
+			return fmt.Errorf("failed to start the application: %w", err)
+		}
+		delay := policy.Delay(restart)
+		p.Logger.Error("Failed to start the application, restarting it", "err", err, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-p.quit:
+			return errors.New("stopped while starting the application")
+		}
+	}
+	p.Logger.Info("Application started", "path", p.path, "addr", p.addr)
+
+	go p.monitorRoutine()
+	return nil
+}
+
+// OnStop implements service.Service by stopping the application.
+func (p *AppProcess) OnStop() {
+	close(p.quit)
+	p.stopProcess()
+}
+
+func (p *AppProcess) startProcess() error {
+	if protocol, path := tmnet.ProtocolAndAddress(p.addr); protocol == "unix" {
+		// the socket of the previous run, which the application can't listen
+		// to again
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	cmd := exec.Command(p.path, p.args...) //nolint:gosec // the command line is configured by the operator
+	cmd.Env = append(os.Environ(), AppAddrEnv+"="+p.addr)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+
+	p.mtx.Lock()
+	p.cmd, p.exited, p.err = cmd, exited, nil
+	p.mtx.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		p.mtx.Lock()
+		p.err = err
+		p.mtx.Unlock()
+		close(exited)
+	}()
+	return nil
+}
+
+// waitReady waits for the application to accept the connections.
+func (p *AppProcess) waitReady() error {
+	timeout := time.After(p.config.StartTimeout)
+	for {
+		if p.healthCheck() == nil {
+			return nil
+		}
+		select {
+		case <-p.Exited():
+			return p.exitError()
+		case <-timeout:
+			return fmt.Errorf("the application didn't accept connections at %s within %v", p.addr, p.config.StartTimeout)
+		case <-p.quit:
+			return errors.New("stopped while starting the application")
+		case <-time.After(appReadyPollInterval):
+		}
+	}
+}
+
+func (p *AppProcess) exitError() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.err != nil {
+		return fmt.Errorf("the application exited: %w", p.err)
+	}
+	return errors.New("the application exited")
+}
+
+// healthCheck returns an error if the application doesn't accept a
+// connection.
+func (p *AppProcess) healthCheck() error {
+	protocol, address := tmnet.ProtocolAndAddress(p.addr)
+	conn, err := net.DialTimeout(protocol, address, appDialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// stopProcess sends SIGTERM to the application, killing it if it doesn't
+// exit within StopTimeout.
+func (p *AppProcess) stopProcess() {
+	p.mtx.Lock()
+	cmd, exited := p.cmd, p.exited
+	p.mtx.Unlock()
+	if cmd == nil {
+		return
+	}
+	select {
+	case <-exited:
+		return
+	default:
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		// e.g. on Windows
+		_ = cmd.Process.Kill()
+	}
+	select {
+	case <-exited:
+		return
+	case <-time.After(p.config.StopTimeout):
+	}
+	p.Logger.Error("The application didn't exit in time, killing it", "timeout", p.config.StopTimeout)
+	_ = cmd.Process.Kill()
+	<-exited
+}
+
+// monitorRoutine logs the exit of the application, and stops it if it fails
+// its health checks.
+func (p *AppProcess) monitorRoutine() {
+	exited := p.Exited()
+	var tick <-chan time.Time
+	if p.config.HealthCheckInterval > 0 {
+		ticker := time.NewTicker(p.config.HealthCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	failures := 0
+	for {
+		select {
+		case <-tick:
+			err := p.healthCheck()
+			if err == nil {
+				failures = 0
+				continue
+			}
+			failures++
+			p.Logger.Error("Application health check failed", "err", err, "failures", failures)
+			if failures >= appMaxHealthCheckFailures {
+				p.Logger.Error("Application is unhealthy, stopping it")
+				p.stopProcess()
+			}
+		case <-exited:
+			p.Logger.Error("Application exited", "err", p.exitError())
+			return
+		case <-p.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/abci/example/kvstore"
+	"github.com/Finschia/ostracon/abci/server"
+	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/service"
+)
+
+const appProcessModeEnv = "OSTRACON_TEST_APP_PROCESS"
+
+// TestHelperAppProcess is the application run by the tests of AppProcess,
+// from the test binary.
+func TestHelperAppProcess(t *testing.T) {
+	switch os.Getenv(appProcessModeEnv) {
+	case "":
+		t.Skip("run by the tests of AppProcess")
+	case "exit":
+		os.Exit(1)
+	case "sleep":
+		select {}
+	case "serve":
+		s := server.NewSocketServer(os.Getenv(AppAddrEnv), kvstore.NewApplication())
+		if err := s.Start(); err != nil {
+			os.Exit(2)
+		}
+		// until stopped by SIGTERM
+		select {}
+	}
+}
+
+func newTestAppProcess(t *testing.T, mode string, config AppProcessConfig) *AppProcess {
+	t.Setenv(appProcessModeEnv, mode)
+	addr := DefaultAppProcessAddr(t.TempDir())
+	p, err := NewAppProcess(os.Args[0]+" -test.run=TestHelperAppProcess", addr, "socket", config)
+	require.NoError(t, err)
+	p.SetLogger(log.TestingLogger())
+	t.Cleanup(func() {
+		if p.IsRunning() {
+			_ = p.Stop()
+		}
+	})
+	return p
+}
+
+func TestAppProcess(t *testing.T) {
+	p := newTestAppProcess(t, "serve", DefaultAppProcessConfig())
+	require.NoError(t, p.Start())
+
+	client, err := p.NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, client.Start())
+	res, err := client.EchoSync("hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", res.Message)
+	require.NoError(t, client.Stop())
+
+	// the application exits once sent SIGTERM
+	exited := p.Exited()
+	require.NoError(t, p.Stop())
+	select {
+	case <-exited:
+	default:
+		t.Fatal("the application should have exited")
+	}
+}
+
+func TestAppProcessRestarts(t *testing.T) {
+	config := DefaultAppProcessConfig()
+	config.RestartPolicy = service.RestartPolicy{MaxRestarts: 2, Backoff: 10 * time.Millisecond}
+	p := newTestAppProcess(t, "exit", config)
+
+	start := time.Now()
+	err := p.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the application exited")
+	// restarted twice, after 10ms and 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestAppProcessStartTimeout(t *testing.T) {
+	config := DefaultAppProcessConfig()
+	config.StartTimeout = 200 * time.Millisecond
+	config.StopTimeout = time.Second
+	config.RestartPolicy = service.RestartPolicy{}
+	// the application doesn't listen to the address
+	p := newTestAppProcess(t, "sleep", config)
+
+	err := p.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "didn't accept connections")
+	// the application was stopped
+	<-p.Exited()
+}
@@ -2,6 +2,8 @@ package proxy
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	abcicli "github.com/Finschia/ostracon/abci/client"
 	"github.com/Finschia/ostracon/abci/example/counter"
@@ -71,8 +73,18 @@ func (r *remoteClientCreator) NewABCIClient() (abcicli.Client, error) {
 
 // DefaultClientCreator returns a default ClientCreator, which will create a
 // local client if addr is one of: 'counter', 'counter_serial', 'kvstore',
-// 'persistent_kvstore' or 'noop', otherwise - a remote client.
+// 'persistent_kvstore' or 'noop', the clients of an AppProcess listening to
+// the abci.sock socket of dbDir if it's prefixed with "exec:", otherwise - a
+// remote client.
 func DefaultClientCreator(addr, transport, dbDir string) ClientCreator {
+	if strings.HasPrefix(addr, AppExecPrefix) {
+		p, err := NewAppProcess(strings.TrimPrefix(addr, AppExecPrefix), DefaultAppProcessAddr(dbDir), transport,
+			DefaultAppProcessConfig())
+		if err != nil {
+			panic(err)
+		}
+		return p
+	}
 	switch addr {
 	case "counter":
 		return NewLocalClientCreator(counter.NewApplication(false))
@@ -95,3 +107,9 @@ func DefaultClientCreator(addr, transport, dbDir string) ClientCreator {
 		return NewRemoteClientCreator(addr, transport, mustConnect)
 	}
 }
+
+// DefaultAppProcessAddr returns the address of the socket in dbDir the
+// AppProcess listens to.
+func DefaultAppProcessAddr(dbDir string) string {
+	return "unix://" + filepath.Join(dbDir, "abci.sock")
+}
@@ -32,8 +32,32 @@ type AppConns interface {
 }
 
 // NewAppConns calls NewMultiAppConn.
-func NewAppConns(clientCreator ClientCreator) AppConns {
-	return NewMultiAppConn(clientCreator)
+func NewAppConns(clientCreator ClientCreator, options ...MultiAppConnOption) AppConns {
+	return NewMultiAppConn(clientCreator, options...)
+}
+
+// MultiAppConnOption sets an optional parameter on the multiAppConn.
+type MultiAppConnOption func(*multiAppConn)
+
+// WithQueryConnections sets the number of abci clients the requests of the
+// query connection are spread over (1 by default).
+func WithQueryConnections(n int) MultiAppConnOption {
+	return func(app *multiAppConn) {
+		if n > 0 {
+			app.numQueryConns = n
+		}
+	}
+}
+
+// WithMempoolConnections sets the number of abci clients the requests of the
+// mempool connection are spread over (1 by default), see
+// NewAppConnMempoolPool.
+func WithMempoolConnections(n int) MultiAppConnOption {
+	return func(app *multiAppConn) {
+		if n > 0 {
+			app.numMempoolConns = n
+		}
+	}
 }
 
 // multiAppConn implements AppConns.
@@ -50,17 +74,26 @@ type multiAppConn struct {
 	snapshotConn  AppConnSnapshot
 
 	consensusConnClient abcicli.Client
-	mempoolConnClient   abcicli.Client
-	queryConnClient     abcicli.Client
+	mempoolConnClients  []abcicli.Client
+	queryConnClients    []abcicli.Client
 	snapshotConnClient  abcicli.Client
 
+	// the numbers of clients of the pooled connections
+	numMempoolConns int
+	numQueryConns   int
+
 	clientCreator ClientCreator
 }
 
 // NewMultiAppConn makes all necessary abci connections to the application.
-func NewMultiAppConn(clientCreator ClientCreator) AppConns {
+func NewMultiAppConn(clientCreator ClientCreator, options ...MultiAppConnOption) AppConns {
 	multiAppConn := &multiAppConn{
-		clientCreator: clientCreator,
+		clientCreator:   clientCreator,
+		numMempoolConns: 1,
+		numQueryConns:   1,
+	}
+	for _, option := range options {
+		option(multiAppConn)
 	}
 	multiAppConn.BaseService = *service.NewBaseService(nil, "multiAppConn", multiAppConn)
 	return multiAppConn
@@ -83,14 +116,17 @@ func (app *multiAppConn) Snapshot() AppConnSnapshot {
 }
 
 func (app *multiAppConn) OnStart() error {
-	c, err := app.abciClientFor(connQuery)
-	if err != nil {
-		return err
+	for i := 0; i < app.numQueryConns; i++ {
+		c, err := app.abciClientFor(connQuery)
+		if err != nil {
+			app.stopAllClients()
+			return err
+		}
+		app.queryConnClients = append(app.queryConnClients, c)
 	}
-	app.queryConnClient = c
-	app.queryConn = NewAppConnQuery(c)
+	app.queryConn = NewAppConnQueryPool(app.queryConnClients)
 
-	c, err = app.abciClientFor(connSnapshot)
+	c, err := app.abciClientFor(connSnapshot)
 	if err != nil {
 		app.stopAllClients()
 		return err
@@ -98,13 +134,15 @@ func (app *multiAppConn) OnStart() error {
 	app.snapshotConnClient = c
 	app.snapshotConn = NewAppConnSnapshot(c)
 
-	c, err = app.abciClientFor(connMempool)
-	if err != nil {
-		app.stopAllClients()
-		return err
+	for i := 0; i < app.numMempoolConns; i++ {
+		c, err := app.abciClientFor(connMempool)
+		if err != nil {
+			app.stopAllClients()
+			return err
+		}
+		app.mempoolConnClients = append(app.mempoolConnClients, c)
 	}
-	app.mempoolConnClient = c
-	app.mempoolConn = NewAppConnMempool(c)
+	app.mempoolConn = NewAppConnMempoolPool(app.mempoolConnClients)
 
 	c, err = app.abciClientFor(connConsensus)
 	if err != nil {
@@ -135,45 +173,48 @@ func (app *multiAppConn) killOCOnClientError() {
 		}
 	}
 
-	select {
-	case <-app.consensusConnClient.Quit():
-		if err := app.consensusConnClient.Error(); err != nil {
-			killFn(connConsensus, err, app.Logger)
-		}
-	case <-app.mempoolConnClient.Quit():
-		if err := app.mempoolConnClient.Error(); err != nil {
-			killFn(connMempool, err, app.Logger)
-		}
-	case <-app.queryConnClient.Quit():
-		if err := app.queryConnClient.Error(); err != nil {
-			killFn(connQuery, err, app.Logger)
-		}
-	case <-app.snapshotConnClient.Quit():
-		if err := app.snapshotConnClient.Error(); err != nil {
-			killFn(connSnapshot, err, app.Logger)
-		}
+	// the first client to quit
+	clients := app.allClients()
+	quit := make(chan namedClient, len(clients))
+	for _, c := range clients {
+		go func(c namedClient) {
+			<-c.client.Quit()
+			quit <- c
+		}(c)
+	}
+	c := <-quit
+	if err := c.client.Error(); err != nil {
+		killFn(c.conn, err, app.Logger)
 	}
 }
 
-func (app *multiAppConn) stopAllClients() {
+type namedClient struct {
+	conn   string
+	client abcicli.Client
+}
+
+// allClients returns the clients started, by connection.
+func (app *multiAppConn) allClients() []namedClient {
+	var clients []namedClient
 	if app.consensusConnClient != nil {
-		if err := app.consensusConnClient.Stop(); err != nil {
-			app.Logger.Error("error while stopping consensus client", "error", err)
-		}
+		clients = append(clients, namedClient{connConsensus, app.consensusConnClient})
 	}
-	if app.mempoolConnClient != nil {
-		if err := app.mempoolConnClient.Stop(); err != nil {
-			app.Logger.Error("error while stopping mempool client", "error", err)
-		}
+	for _, c := range app.mempoolConnClients {
+		clients = append(clients, namedClient{connMempool, c})
 	}
-	if app.queryConnClient != nil {
-		if err := app.queryConnClient.Stop(); err != nil {
-			app.Logger.Error("error while stopping query client", "error", err)
-		}
+	for _, c := range app.queryConnClients {
+		clients = append(clients, namedClient{connQuery, c})
 	}
 	if app.snapshotConnClient != nil {
-		if err := app.snapshotConnClient.Stop(); err != nil {
-			app.Logger.Error("error while stopping snapshot client", "error", err)
+		clients = append(clients, namedClient{connSnapshot, app.snapshotConnClient})
+	}
+	return clients
+}
+
+func (app *multiAppConn) stopAllClients() {
+	for _, c := range app.allClients() {
+		if err := c.client.Stop(); err != nil {
+			app.Logger.Error(fmt.Sprintf("error while stopping %s client", c.conn), "error", err)
 		}
 	}
 }
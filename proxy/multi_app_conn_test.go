@@ -88,3 +88,32 @@ func TestAppConns_Failure(t *testing.T) {
 		t.Fatal("expected process to receive SIGTERM signal")
 	}
 }
+
+func TestAppConns_Pools(t *testing.T) {
+	quitCh := make(<-chan struct{})
+
+	clientCreatorMock := &mocks.ClientCreator{}
+
+	// 3 query, 2 mempool, 1 consensus and 1 snapshot clients
+	clientMock := &abcimocks.Client{}
+	clientMock.On("SetLogger", mock.Anything).Return().Times(7)
+	clientMock.On("Start").Return(nil).Times(7)
+	clientMock.On("Stop").Return(nil).Times(7)
+	clientMock.On("Quit").Return(quitCh).Times(7)
+
+	clientCreatorMock.On("NewABCIClient").Return(clientMock, nil).Times(7)
+
+	appConns := NewAppConns(clientCreatorMock, WithQueryConnections(3), WithMempoolConnections(2))
+
+	err := appConns.Start()
+	require.NoError(t, err)
+	require.IsType(t, &appConnQueryPool{}, appConns.Query())
+	require.IsType(t, &appConnMempoolPool{}, appConns.Mempool())
+
+	time.Sleep(100 * time.Millisecond)
+
+	err = appConns.Stop()
+	require.NoError(t, err)
+
+	clientMock.AssertExpectations(t)
+}
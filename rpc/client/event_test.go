@@ -11,9 +11,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	abci "github.com/Finschia/ostracon/abci/types"
+	tmjson "github.com/Finschia/ostracon/libs/json"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	"github.com/Finschia/ostracon/rpc/client"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	rpcclient "github.com/Finschia/ostracon/rpc/jsonrpc/client"
+	rpctest "github.com/Finschia/ostracon/rpc/test"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -179,3 +182,39 @@ func TestHTTPReturnsErrorIfClientIsNotRunning(t *testing.T) {
 	err = c.UnsubscribeAll(context.Background(), "TestHeaderEvents")
 	assert.Error(t, err)
 }
+
+func TestTxEventsReplay(t *testing.T) {
+	c := getHTTPClient()
+	k, _, tx := MakeTxKV()
+	bres, err := c.BroadcastTxCommit(context.Background(), tx)
+	require.NoError(t, err)
+	require.True(t, bres.DeliverTx.IsOK())
+
+	ws, err := rpcclient.NewWS(rpctest.GetConfig().RPC.ListenAddress, "/websocket")
+	require.NoError(t, err)
+	require.NoError(t, ws.Start())
+	t.Cleanup(func() {
+		if err := ws.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// the tx committed before subscribing is replayed from the indexer
+	query := fmt.Sprintf("tm.event = 'Tx' AND app.key = '%s'", k)
+	require.NoError(t, ws.SubscribeFromHeight(context.Background(), query, bres.Height, true))
+	var event ctypes.ResultEvent
+	for event.Data == nil {
+		select {
+		case resp := <-ws.ResponsesCh:
+			require.Nil(t, resp.Error)
+			require.NoError(t, tmjson.Unmarshal(resp.Result, &event))
+		case <-time.After(waitForEventTimeout):
+			t.Fatal("the tx wasn't replayed")
+		}
+	}
+	txe, ok := event.Data.(types.EventDataTx)
+	require.True(t, ok)
+	assert.EqualValues(t, tx, txe.Tx)
+	assert.Equal(t, bres.Height, txe.Height)
+	assert.Equal(t, []string{string(k)}, event.Events["app.key"])
+}
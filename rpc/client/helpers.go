@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/Finschia/ostracon/types"
@@ -81,3 +83,27 @@ func WaitForOneEvent(c EventsClient, evtTyp string, timeout time.Duration) (type
 		return nil, errors.New("timed out waiting for event")
 	}
 }
+
+// WriteGenesis fetches the genesis document in chunks with GenesisChunked,
+// which works whatever its size, unlike Genesis, and writes its JSON to w as
+// the chunks are received, so that it isn't held in memory.
+func WriteGenesis(ctx context.Context, c HistoryClient, w io.Writer) error {
+	for chunk, total := 0, 1; chunk < total; chunk++ {
+		res, err := c.GenesisChunked(ctx, uint(chunk))
+		if err != nil {
+			return fmt.Errorf("failed to fetch the genesis chunk %d: %w", chunk, err)
+		}
+		if res.ChunkNumber != chunk || res.TotalChunks <= chunk {
+			return fmt.Errorf("unexpected genesis chunk %d of %d instead of %d", res.ChunkNumber, res.TotalChunks, chunk)
+		}
+		total = res.TotalChunks
+		data, err := base64.StdEncoding.DecodeString(res.Data)
+		if err != nil {
+			return fmt.Errorf("invalid genesis chunk %d: %w", chunk, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
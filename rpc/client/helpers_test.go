@@ -1,15 +1,19 @@
 package client_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	tmock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Finschia/ostracon/rpc/client"
 	"github.com/Finschia/ostracon/rpc/client/mock"
+	"github.com/Finschia/ostracon/rpc/client/mocks"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 )
 
@@ -75,3 +79,20 @@ func TestWaitForHeight(t *testing.T) {
 	require.True(ok)
 	assert.Equal(int64(15), postr.SyncInfo.LatestBlockHeight)
 }
+
+func TestWriteGenesis(t *testing.T) {
+	c := &mocks.Client{}
+	c.On("GenesisChunked", tmock.Anything, uint(0)).
+		Return(&ctypes.ResultGenesisChunk{ChunkNumber: 0, TotalChunks: 2, Data: "eyJjaGFpbl9pZCI6"}, nil)
+	c.On("GenesisChunked", tmock.Anything, uint(1)).
+		Return(&ctypes.ResultGenesisChunk{ChunkNumber: 1, TotalChunks: 2, Data: "InRlc3QifQ=="}, nil)
+	var buf bytes.Buffer
+	require.NoError(t, client.WriteGenesis(context.Background(), c, &buf))
+	assert.Equal(t, `{"chain_id":"test"}`, buf.String())
+
+	// the chunks must be the ones requested
+	c = &mocks.Client{}
+	c.On("GenesisChunked", tmock.Anything, uint(0)).
+		Return(&ctypes.ResultGenesisChunk{ChunkNumber: 1, TotalChunks: 2, Data: "InRlc3QifQ=="}, nil)
+	assert.Error(t, client.WriteGenesis(context.Background(), c, &buf))
+}
@@ -309,6 +309,43 @@ func (c *baseRPCClient) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUn
 	return result, nil
 }
 
+func (c *baseRPCClient) PendingTx(ctx context.Context, hash []byte) (*ctypes.ResultPendingTx, error) {
+	result := new(ctypes.ResultPendingTx)
+	_, err := c.caller.Call(ctx, "pending_tx", map[string]interface{}{"hash": hash}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) PendingTxs(
+	ctx context.Context,
+	page,
+	perPage *int,
+	minGasWanted,
+	minHeight *int64,
+) (*ctypes.ResultPendingTxs, error) {
+	result := new(ctypes.ResultPendingTxs)
+	params := make(map[string]interface{})
+	if page != nil {
+		params["page"] = page
+	}
+	if perPage != nil {
+		params["per_page"] = perPage
+	}
+	if minGasWanted != nil {
+		params["min_gas_wanted"] = minGasWanted
+	}
+	if minHeight != nil {
+		params["min_height"] = minHeight
+	}
+	_, err := c.caller.Call(ctx, "pending_txs", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) CheckTx(ctx context.Context, tx types.Tx) (*ctypes.ResultCheckTx, error) {
 	result := new(ctypes.ResultCheckTx)
 	_, err := c.caller.Call(ctx, "check_tx", map[string]interface{}{"tx": tx}, result)
@@ -361,6 +398,33 @@ func (c *baseRPCClient) ConsensusParams(
 	return result, nil
 }
 
+func (c *baseRPCClient) Snapshots(ctx context.Context) (*ctypes.ResultSnapshots, error) {
+	result := new(ctypes.ResultSnapshots)
+	_, err := c.caller.Call(ctx, "snapshots", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) PruningStatus(ctx context.Context) (*ctypes.ResultPruningStatus, error) {
+	result := new(ctypes.ResultPruningStatus)
+	_, err := c.caller.Call(ctx, "pruning_status", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) CrawlStats(ctx context.Context) (*ctypes.ResultCrawlStats, error) {
+	result := new(ctypes.ResultCrawlStats)
+	_, err := c.caller.Call(ctx, "crawl_stats", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) Health(ctx context.Context) (*ctypes.ResultHealth, error) {
 	result := new(ctypes.ResultHealth)
 	_, err := c.caller.Call(ctx, "health", map[string]interface{}{}, result)
@@ -457,6 +521,50 @@ func (c *baseRPCClient) Commit(ctx context.Context, height *int64) (*ctypes.Resu
 	return result, nil
 }
 
+func (c *baseRPCClient) CanonicalJSON(
+	ctx context.Context,
+	typ string,
+	height *int64,
+	index *int,
+	amino bool,
+) (*ctypes.ResultCanonicalJSON, error) {
+	result := new(ctypes.ResultCanonicalJSON)
+	params := map[string]interface{}{
+		"type":  typ,
+		"amino": amino,
+	}
+	if height != nil {
+		params["height"] = height
+	}
+	if index != nil {
+		params["index"] = index
+	}
+	_, err := c.caller.Call(ctx, "canonical_json", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) ValidatorsDiff(
+	ctx context.Context,
+	from int64,
+	height *int64,
+) (*ctypes.ResultValidatorsDiff, error) {
+	result := new(ctypes.ResultValidatorsDiff)
+	params := map[string]interface{}{
+		"from": from,
+	}
+	if height != nil {
+		params["height"] = height
+	}
+	_, err := c.caller.Call(ctx, "validators_diff", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
 	result := new(ctypes.ResultTx)
 	params := map[string]interface{}{
@@ -565,6 +673,37 @@ func (c *baseRPCClient) BroadcastEvidence(
 	return result, nil
 }
 
+func (c *baseRPCClient) LightClientAttacks(
+	ctx context.Context,
+	minHeight,
+	maxHeight int64,
+) (*ctypes.ResultLightClientAttacks, error) {
+	result := new(ctypes.ResultLightClientAttacks)
+	_, err := c.caller.Call(ctx, "light_client_attacks",
+		map[string]interface{}{"minHeight": minHeight, "maxHeight": maxHeight},
+		result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *baseRPCClient) EvidenceSearch(
+	ctx context.Context,
+	validator []byte,
+	from,
+	to int64,
+) (*ctypes.ResultEvidenceSearch, error) {
+	result := new(ctypes.ResultEvidenceSearch)
+	_, err := c.caller.Call(ctx, "evidence_search",
+		map[string]interface{}{"validator": validator, "from": from, "to": to},
+		result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 //-----------------------------------------------------------------------------
 // WSEvents
 
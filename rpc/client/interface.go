@@ -70,7 +70,9 @@ type SignClient interface {
 	BlockByHash(ctx context.Context, hash []byte) (*ctypes.ResultBlock, error)
 	BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error)
 	Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error)
+	CanonicalJSON(ctx context.Context, typ string, height *int64, index *int, amino bool) (*ctypes.ResultCanonicalJSON, error)
 	Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error)
+	ValidatorsDiff(ctx context.Context, from int64, height *int64) (*ctypes.ResultValidatorsDiff, error)
 	Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error)
 
 	// TxSearch defines a method to search for a paginated set of transactions by
@@ -113,6 +115,9 @@ type NetworkClient interface {
 	ConsensusState(context.Context) (*ctypes.ResultConsensusState, error)
 	ConsensusParams(ctx context.Context, height *int64) (*ctypes.ResultConsensusParams, error)
 	Health(context.Context) (*ctypes.ResultHealth, error)
+	Snapshots(context.Context) (*ctypes.ResultSnapshots, error)
+	PruningStatus(context.Context) (*ctypes.ResultPruningStatus, error)
+	CrawlStats(context.Context) (*ctypes.ResultCrawlStats, error)
 }
 
 // EventsClient is reactive, you can subscribe to any message, given the proper
@@ -136,13 +141,17 @@ type EventsClient interface {
 type MempoolClient interface {
 	UnconfirmedTxs(ctx context.Context, limit *int) (*ctypes.ResultUnconfirmedTxs, error)
 	NumUnconfirmedTxs(context.Context) (*ctypes.ResultUnconfirmedTxs, error)
+	PendingTx(ctx context.Context, hash []byte) (*ctypes.ResultPendingTx, error)
+	PendingTxs(ctx context.Context, page, perPage *int, minGasWanted, minHeight *int64) (*ctypes.ResultPendingTxs, error)
 	CheckTx(context.Context, types.Tx) (*ctypes.ResultCheckTx, error)
 }
 
 // EvidenceClient is used for submitting an evidence of the malicious
-// behaviour.
+// behaviour and for querying the committed evidence.
 type EvidenceClient interface {
 	BroadcastEvidence(context.Context, types.Evidence) (*ctypes.ResultBroadcastEvidence, error)
+	LightClientAttacks(ctx context.Context, minHeight, maxHeight int64) (*ctypes.ResultLightClientAttacks, error)
+	EvidenceSearch(ctx context.Context, validator []byte, from, to int64) (*ctypes.ResultEvidenceSearch, error)
 }
 
 // RemoteClient is a Client, which can also return the remote network address.
@@ -107,6 +107,20 @@ func (c *Local) NumUnconfirmedTxs(ctx context.Context) (*ctypes.ResultUnconfirme
 	return core.NumUnconfirmedTxs(c.ctx)
 }
 
+func (c *Local) PendingTx(ctx context.Context, hash []byte) (*ctypes.ResultPendingTx, error) {
+	return core.PendingTx(c.ctx, hash)
+}
+
+func (c *Local) PendingTxs(
+	ctx context.Context,
+	page,
+	perPage *int,
+	minGasWanted,
+	minHeight *int64,
+) (*ctypes.ResultPendingTxs, error) {
+	return core.PendingTxs(c.ctx, page, perPage, minGasWanted, minHeight)
+}
+
 func (c *Local) CheckTx(ctx context.Context, tx types.Tx) (*ctypes.ResultCheckTx, error) {
 	return core.CheckTx(c.ctx, tx)
 }
@@ -127,6 +141,18 @@ func (c *Local) ConsensusParams(ctx context.Context, height *int64) (*ctypes.Res
 	return core.ConsensusParams(c.ctx, height)
 }
 
+func (c *Local) Snapshots(ctx context.Context) (*ctypes.ResultSnapshots, error) {
+	return core.Snapshots(c.ctx)
+}
+
+func (c *Local) PruningStatus(ctx context.Context) (*ctypes.ResultPruningStatus, error) {
+	return core.PruningStatus(c.ctx)
+}
+
+func (c *Local) CrawlStats(ctx context.Context) (*ctypes.ResultCrawlStats, error) {
+	return core.CrawlStats(c.ctx)
+}
+
 func (c *Local) Health(ctx context.Context) (*ctypes.ResultHealth, error) {
 	return core.Health(c.ctx)
 }
@@ -173,8 +199,22 @@ func (c *Local) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit
 	return core.Commit(c.ctx, height)
 }
 
+func (c *Local) CanonicalJSON(
+	ctx context.Context,
+	typ string,
+	height *int64,
+	index *int,
+	amino bool,
+) (*ctypes.ResultCanonicalJSON, error) {
+	return core.CanonicalJSON(c.ctx, typ, height, index, amino)
+}
+
 func (c *Local) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
-	return core.Validators(c.ctx, height, page, perPage)
+	return core.Validators(c.ctx, height, page, perPage, "")
+}
+
+func (c *Local) ValidatorsDiff(ctx context.Context, from int64, height *int64) (*ctypes.ResultValidatorsDiff, error) {
+	return core.ValidatorsDiff(c.ctx, from, height)
 }
 
 func (c *Local) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
@@ -205,6 +245,23 @@ func (c *Local) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctyp
 	return core.BroadcastEvidence(c.ctx, ev)
 }
 
+func (c *Local) LightClientAttacks(
+	ctx context.Context,
+	minHeight,
+	maxHeight int64,
+) (*ctypes.ResultLightClientAttacks, error) {
+	return core.LightClientAttacks(c.ctx, minHeight, maxHeight)
+}
+
+func (c *Local) EvidenceSearch(
+	ctx context.Context,
+	validator []byte,
+	from,
+	to int64,
+) (*ctypes.ResultEvidenceSearch, error) {
+	return core.EvidenceSearch(c.ctx, validator, from, to)
+}
+
 func (c *Local) Subscribe(
 	ctx context.Context,
 	subscriber,
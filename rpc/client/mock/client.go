@@ -131,6 +131,18 @@ func (c Client) ConsensusParams(ctx context.Context, height *int64) (*ctypes.Res
 	return core.ConsensusParams(&rpctypes.Context{}, height)
 }
 
+func (c Client) Snapshots(ctx context.Context) (*ctypes.ResultSnapshots, error) {
+	return core.Snapshots(&rpctypes.Context{})
+}
+
+func (c Client) PruningStatus(ctx context.Context) (*ctypes.ResultPruningStatus, error) {
+	return core.PruningStatus(&rpctypes.Context{})
+}
+
+func (c Client) CrawlStats(ctx context.Context) (*ctypes.ResultCrawlStats, error) {
+	return core.CrawlStats(&rpctypes.Context{})
+}
+
 func (c Client) Health(ctx context.Context) (*ctypes.ResultHealth, error) {
 	return core.Health(&rpctypes.Context{})
 }
@@ -169,10 +181,41 @@ func (c Client) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit
 	return core.Commit(&rpctypes.Context{}, height)
 }
 
+func (c Client) CanonicalJSON(
+	ctx context.Context,
+	typ string,
+	height *int64,
+	index *int,
+	amino bool,
+) (*ctypes.ResultCanonicalJSON, error) {
+	return core.CanonicalJSON(&rpctypes.Context{}, typ, height, index, amino)
+}
+
 func (c Client) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
-	return core.Validators(&rpctypes.Context{}, height, page, perPage)
+	return core.Validators(&rpctypes.Context{}, height, page, perPage, "")
+}
+
+func (c Client) ValidatorsDiff(ctx context.Context, from int64, height *int64) (*ctypes.ResultValidatorsDiff, error) {
+	return core.ValidatorsDiff(&rpctypes.Context{}, from, height)
 }
 
 func (c Client) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
 	return core.BroadcastEvidence(&rpctypes.Context{}, ev)
 }
+
+func (c Client) LightClientAttacks(
+	ctx context.Context,
+	minHeight,
+	maxHeight int64,
+) (*ctypes.ResultLightClientAttacks, error) {
+	return core.LightClientAttacks(&rpctypes.Context{}, minHeight, maxHeight)
+}
+
+func (c Client) EvidenceSearch(
+	ctx context.Context,
+	validator []byte,
+	from,
+	to int64,
+) (*ctypes.ResultEvidenceSearch, error) {
+	return core.EvidenceSearch(&rpctypes.Context{}, validator, from, to)
+}
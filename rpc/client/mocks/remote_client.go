@@ -334,6 +334,32 @@ func (_m *RemoteClient) BroadcastTxSync(_a0 context.Context, _a1 types.Tx) (*cor
 	return r0, r1
 }
 
+// CanonicalJSON provides a mock function with given fields: ctx, typ, height, index, amino
+func (_m *RemoteClient) CanonicalJSON(ctx context.Context, typ string, height *int64, index *int, amino bool) (*coretypes.ResultCanonicalJSON, error) {
+	ret := _m.Called(ctx, typ, height, index, amino)
+
+	var r0 *coretypes.ResultCanonicalJSON
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *int64, *int, bool) (*coretypes.ResultCanonicalJSON, error)); ok {
+		return rf(ctx, typ, height, index, amino)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *int64, *int, bool) *coretypes.ResultCanonicalJSON); ok {
+		r0 = rf(ctx, typ, height, index, amino)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultCanonicalJSON)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *int64, *int, bool) error); ok {
+		r1 = rf(ctx, typ, height, index, amino)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckTx provides a mock function with given fields: _a0, _a1
 func (_m *RemoteClient) CheckTx(_a0 context.Context, _a1 types.Tx) (*coretypes.ResultCheckTx, error) {
 	ret := _m.Called(_a0, _a1)
@@ -438,6 +464,32 @@ func (_m *RemoteClient) ConsensusState(_a0 context.Context) (*coretypes.ResultCo
 	return r0, r1
 }
 
+// CrawlStats provides a mock function with given fields: _a0
+func (_m *RemoteClient) CrawlStats(_a0 context.Context) (*coretypes.ResultCrawlStats, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *coretypes.ResultCrawlStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*coretypes.ResultCrawlStats, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *coretypes.ResultCrawlStats); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultCrawlStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DumpConsensusState provides a mock function with given fields: _a0
 func (_m *RemoteClient) DumpConsensusState(_a0 context.Context) (*coretypes.ResultDumpConsensusState, error) {
 	ret := _m.Called(_a0)
@@ -464,6 +516,32 @@ func (_m *RemoteClient) DumpConsensusState(_a0 context.Context) (*coretypes.Resu
 	return r0, r1
 }
 
+// EvidenceSearch provides a mock function with given fields: ctx, validator, from, to
+func (_m *RemoteClient) EvidenceSearch(ctx context.Context, validator []byte, from int64, to int64) (*coretypes.ResultEvidenceSearch, error) {
+	ret := _m.Called(ctx, validator, from, to)
+
+	var r0 *coretypes.ResultEvidenceSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, int64, int64) (*coretypes.ResultEvidenceSearch, error)); ok {
+		return rf(ctx, validator, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, int64, int64) *coretypes.ResultEvidenceSearch); ok {
+		r0 = rf(ctx, validator, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultEvidenceSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte, int64, int64) error); ok {
+		r1 = rf(ctx, validator, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Genesis provides a mock function with given fields: _a0
 func (_m *RemoteClient) Genesis(_a0 context.Context) (*coretypes.ResultGenesis, error) {
 	ret := _m.Called(_a0)
@@ -556,6 +634,32 @@ func (_m *RemoteClient) IsRunning() bool {
 	return r0
 }
 
+// LightClientAttacks provides a mock function with given fields: ctx, minHeight, maxHeight
+func (_m *RemoteClient) LightClientAttacks(ctx context.Context, minHeight int64, maxHeight int64) (*coretypes.ResultLightClientAttacks, error) {
+	ret := _m.Called(ctx, minHeight, maxHeight)
+
+	var r0 *coretypes.ResultLightClientAttacks
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*coretypes.ResultLightClientAttacks, error)); ok {
+		return rf(ctx, minHeight, maxHeight)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *coretypes.ResultLightClientAttacks); ok {
+		r0 = rf(ctx, minHeight, maxHeight)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultLightClientAttacks)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, minHeight, maxHeight)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NetInfo provides a mock function with given fields: _a0
 func (_m *RemoteClient) NetInfo(_a0 context.Context) (*coretypes.ResultNetInfo, error) {
 	ret := _m.Called(_a0)
@@ -641,6 +745,84 @@ func (_m *RemoteClient) OnStop() {
 	_m.Called()
 }
 
+// PendingTx provides a mock function with given fields: ctx, hash
+func (_m *RemoteClient) PendingTx(ctx context.Context, hash []byte) (*coretypes.ResultPendingTx, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *coretypes.ResultPendingTx
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*coretypes.ResultPendingTx, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *coretypes.ResultPendingTx); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultPendingTx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PendingTxs provides a mock function with given fields: ctx, page, perPage, minGasWanted, minHeight
+func (_m *RemoteClient) PendingTxs(ctx context.Context, page *int, perPage *int, minGasWanted *int64, minHeight *int64) (*coretypes.ResultPendingTxs, error) {
+	ret := _m.Called(ctx, page, perPage, minGasWanted, minHeight)
+
+	var r0 *coretypes.ResultPendingTxs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, *int64, *int64) (*coretypes.ResultPendingTxs, error)); ok {
+		return rf(ctx, page, perPage, minGasWanted, minHeight)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, *int64, *int64) *coretypes.ResultPendingTxs); ok {
+		r0 = rf(ctx, page, perPage, minGasWanted, minHeight)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultPendingTxs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *int, *int, *int64, *int64) error); ok {
+		r1 = rf(ctx, page, perPage, minGasWanted, minHeight)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PruningStatus provides a mock function with given fields: _a0
+func (_m *RemoteClient) PruningStatus(_a0 context.Context) (*coretypes.ResultPruningStatus, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *coretypes.ResultPruningStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*coretypes.ResultPruningStatus, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *coretypes.ResultPruningStatus); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultPruningStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Quit provides a mock function with given fields:
 func (_m *RemoteClient) Quit() <-chan struct{} {
 	ret := _m.Called()
@@ -690,6 +872,32 @@ func (_m *RemoteClient) SetLogger(_a0 log.Logger) {
 	_m.Called(_a0)
 }
 
+// Snapshots provides a mock function with given fields: _a0
+func (_m *RemoteClient) Snapshots(_a0 context.Context) (*coretypes.ResultSnapshots, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *coretypes.ResultSnapshots
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*coretypes.ResultSnapshots, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *coretypes.ResultSnapshots); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultSnapshots)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *RemoteClient) Start() error {
 	ret := _m.Called()
@@ -923,6 +1131,32 @@ func (_m *RemoteClient) Validators(ctx context.Context, height *int64, page *int
 	return r0, r1
 }
 
+// ValidatorsDiff provides a mock function with given fields: ctx, from, height
+func (_m *RemoteClient) ValidatorsDiff(ctx context.Context, from int64, height *int64) (*coretypes.ResultValidatorsDiff, error) {
+	ret := _m.Called(ctx, from, height)
+
+	var r0 *coretypes.ResultValidatorsDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) (*coretypes.ResultValidatorsDiff, error)); ok {
+		return rf(ctx, from, height)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) *coretypes.ResultValidatorsDiff); ok {
+		r0 = rf(ctx, from, height)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coretypes.ResultValidatorsDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *int64) error); ok {
+		r1 = rf(ctx, from, height)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewRemoteClient creates a new instance of RemoteClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRemoteClient(t interface {
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Finschia/ostracon/crypto/merkle"
+	tmbytes "github.com/Finschia/ostracon/libs/bytes"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// KeyPathFunc builds the merkle key path of a value out of the path and the
+// key of an ABCIQuery.
+type KeyPathFunc func(path string, key []byte) (merkle.KeyPath, error)
+
+// TrustedHeaderFunc returns the trusted header at the height, e.g. verified by
+// the light client.
+type TrustedHeaderFunc func(ctx context.Context, height int64) (*types.Header, error)
+
+// ProofVerifier verifies the values returned by ABCIQuery, or their absence,
+// against the app hash of a trusted header.
+type ProofVerifier struct {
+	// ProofRuntime decodes and runs the proof ops returned by the app, which
+	// must have their decoders registered. merkle.DefaultProofRuntime if nil.
+	ProofRuntime *merkle.ProofRuntime
+
+	// KeyPathFn builds the merkle key paths of the values returned. It must be
+	// set to verify the values, not their absence.
+	KeyPathFn KeyPathFunc
+
+	// TrustedHeader returns the trusted headers holding the app hashes. It
+	// must be set.
+	TrustedHeader TrustedHeaderFunc
+
+	// QueryKey returns the key queried by the data of a query on the path,
+	// which the key of the response must be, so that a proof of another key
+	// isn't taken for the one queried. The data itself if nil, the queries
+	// being addressed by key (e.g. the store queries of the Cosmos SDK).
+	QueryKey func(path string, data []byte) ([]byte, error)
+}
+
+// ABCIQueryWithProofVerification calls ABCIQueryWithOptions, requesting the
+// proof, and verifies the value returned, or its absence if there is none,
+// against the app hash of the trusted header of the next height (the app hash
+// after the height of the query is in the header of the next height). It
+// returns an error if the response is an error, isn't for the key queried (see
+// ProofVerifier.QueryKey) or can't be verified.
+func ABCIQueryWithProofVerification(
+	ctx context.Context,
+	c ABCIClient,
+	path string,
+	data tmbytes.HexBytes,
+	opts ABCIQueryOptions,
+	v ProofVerifier,
+) (*ctypes.ResultABCIQuery, error) {
+	if v.TrustedHeader == nil {
+		return nil, errors.New("no TrustedHeader to verify the proof against")
+	}
+	prt := v.ProofRuntime
+	if prt == nil {
+		prt = merkle.DefaultProofRuntime()
+	}
+
+	// always request the proof
+	opts.Prove = true
+
+	res, err := c.ABCIQueryWithOptions(ctx, path, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp := res.Response
+
+	// Validate the response.
+	if resp.IsErr() {
+		return nil, fmt.Errorf("err response code: %v", resp.Code)
+	}
+	if len(resp.Key) == 0 {
+		return nil, errors.New("empty key")
+	}
+	if resp.ProofOps == nil || len(resp.ProofOps.Ops) == 0 {
+		return nil, errors.New("no proof ops")
+	}
+	if resp.Height <= 0 {
+		return nil, errors.New("negative or zero height")
+	}
+	key := []byte(data)
+	if v.QueryKey != nil {
+		if key, err = v.QueryKey(path, data); err != nil {
+			return nil, fmt.Errorf("can't get the key queried: %w", err)
+		}
+	}
+	if !bytes.Equal(resp.Key, key) {
+		return nil, fmt.Errorf("the key of the response %X isn't the key queried %X", resp.Key, key)
+	}
+
+	// NOTE: AppHash for height H is in header H+1.
+	header, err := v.TrustedHeader(ctx, resp.Height+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the value proof against the trusted header.
+	if resp.Value != nil {
+		// 1) build a Merkle key path from path and resp.Key
+		if v.KeyPathFn == nil {
+			return nil, errors.New("no KeyPathFn to build the merkle key path of the value")
+		}
+
+		kp, err := v.KeyPathFn(path, resp.Key)
+		if err != nil {
+			return nil, fmt.Errorf("can't build merkle key path: %w", err)
+		}
+
+		// 2) verify value
+		err = prt.VerifyValue(resp.ProofOps, header.AppHash, kp.String(), resp.Value)
+		if err != nil {
+			return nil, fmt.Errorf("verify value proof: %w", err)
+		}
+	} else { // OR validate the absence proof against the trusted header.
+		err = prt.VerifyAbsence(resp.ProofOps, header.AppHash, string(resp.Key))
+		if err != nil {
+			return nil, fmt.Errorf("verify absence proof: %w", err)
+		}
+	}
+
+	return &ctypes.ResultABCIQuery{Response: resp}, nil
+}
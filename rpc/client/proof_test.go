@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmcrypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+
+	"github.com/Finschia/ostracon/crypto/merkle"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+	"github.com/Finschia/ostracon/rpc/client"
+	"github.com/Finschia/ostracon/rpc/client/mock"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestABCIQueryWithProofVerification(t *testing.T) {
+	kvLeaf := func(key, value string) []byte {
+		vhash := tmhash.Sum([]byte(value))
+		bz := []byte{byte(len(key))}
+		bz = append(bz, key...)
+		bz = append(bz, byte(len(vhash)))
+		return append(bz, vhash...)
+	}
+	appHash, proofs := merkle.ProofsFromByteSlices([][]byte{kvLeaf("baz", "qux"), kvLeaf("foo", "bar")})
+	op := merkle.NewValueOp([]byte("foo"), proofs[1])
+
+	var trustedHeight int64
+	verifier := client.ProofVerifier{
+		KeyPathFn: func(path string, key []byte) (merkle.KeyPath, error) {
+			return merkle.KeyPath{}.AppendKey(key, merkle.KeyEncodingURL), nil
+		},
+		TrustedHeader: func(ctx context.Context, height int64) (*types.Header, error) {
+			trustedHeight = height
+			return &types.Header{Height: height, AppHash: appHash}, nil
+		},
+	}
+	queryData := func(data, value string, opts client.ABCIQueryOptions, v client.ProofVerifier) error {
+		c := mock.ABCIMock{Query: mock.Call{Response: abci.ResponseQuery{
+			Key:      []byte("foo"),
+			Value:    []byte(value),
+			ProofOps: &tmcrypto.ProofOps{Ops: []tmcrypto.ProofOp{op.ProofOp()}},
+			Height:   5,
+		}}}
+		_, err := client.ABCIQueryWithProofVerification(context.Background(), c, "/key", []byte(data), opts, v)
+		return err
+	}
+	query := func(value string, opts client.ABCIQueryOptions, v client.ProofVerifier) error {
+		return queryData("foo", value, opts, v)
+	}
+
+	// the value is verified against the app hash of the next height
+	require.NoError(t, query("bar", client.DefaultABCIQueryOptions, verifier))
+	assert.EqualValues(t, 6, trustedHeight)
+	assert.Error(t, query("baz", client.DefaultABCIQueryOptions, verifier))
+
+	// the proof of another key than the one queried is rejected
+	assert.Error(t, queryData("baz", "bar", client.DefaultABCIQueryOptions, verifier))
+	byPrefix := verifier
+	byPrefix.QueryKey = func(path string, data []byte) ([]byte, error) {
+		return append([]byte("f"), data...), nil
+	}
+	require.NoError(t, queryData("oo", "bar", client.DefaultABCIQueryOptions, byPrefix))
+	assert.Error(t, queryData("foo", "bar", client.DefaultABCIQueryOptions, byPrefix))
+
+	// the proof is always requested
+	recorder := mock.NewABCIRecorder(mock.ABCIMock{Query: mock.Call{Error: errors.New("unavailable")}})
+	_, err := client.ABCIQueryWithProofVerification(context.Background(), recorder, "/key", []byte("foo"),
+		client.DefaultABCIQueryOptions, verifier)
+	assert.Error(t, err)
+	require.Len(t, recorder.Calls, 1)
+	assert.True(t, recorder.Calls[0].Args.(mock.QueryArgs).Prove)
+
+	// the proof ops of the app must be known
+	unknown := client.ProofVerifier{
+		ProofRuntime:  merkle.NewProofRuntime(),
+		KeyPathFn:     verifier.KeyPathFn,
+		TrustedHeader: verifier.TrustedHeader,
+	}
+	assert.Error(t, query("bar", client.DefaultABCIQueryOptions, unknown))
+
+	// the trusted header must be available
+	untrusted := verifier
+	untrusted.TrustedHeader = func(ctx context.Context, height int64) (*types.Header, error) {
+		return nil, errors.New("not verified")
+	}
+	assert.Error(t, query("bar", client.DefaultABCIQueryOptions, untrusted))
+	assert.Error(t, query("bar", client.DefaultABCIQueryOptions, client.ProofVerifier{}))
+}
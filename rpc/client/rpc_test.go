@@ -163,6 +163,19 @@ func TestHealth(t *testing.T) {
 	}
 }
 
+func TestHealthProbes(t *testing.T) {
+	remote := strings.ReplaceAll(rpctest.GetConfig().RPC.ListenAddress, "tcp", "http")
+	err := client.WaitForHeight(getHTTPClient(), 1, nil)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/health/live", "/health/ready"} {
+		resp, err := http.Get(remote + path)
+		require.NoError(t, err, path)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+}
+
 func TestGenesisAndValidators(t *testing.T) {
 	for i, c := range GetClients() {
 
@@ -188,6 +201,37 @@ func TestGenesisAndValidators(t *testing.T) {
 	}
 }
 
+func TestValidatorsDiff(t *testing.T) {
+	for i, c := range GetClients() {
+		h := int64(1)
+		perPage := 100
+		vals, err := c.Validators(context.Background(), &h, nil, &perPage)
+		require.NoError(t, err, "%d", i)
+
+		// the other tests may have changed the validators since the height
+		res, err := c.ValidatorsDiff(context.Background(), h, nil)
+		require.NoError(t, err, "%d", i)
+		assert.Equal(t, h, res.FromHeight)
+		assert.True(t, res.BlockHeight >= h)
+		current, err := c.Validators(context.Background(), &res.BlockHeight, nil, &perPage)
+		require.NoError(t, err, "%d", i)
+		assert.Equal(t, current.Total, res.Total)
+
+		// the diff applied to the validators at the height gives the validators
+		// at the block height
+		valSet, err := types.ValidatorSetFromExistingValidators(vals.Validators)
+		require.NoError(t, err, "%d", i)
+		valSet, err = valSet.ApplyDiff(&res.Diff)
+		require.NoError(t, err, "%d", i)
+		currentSet, err := types.ValidatorSetFromExistingValidators(current.Validators)
+		require.NoError(t, err, "%d", i)
+		assert.Equal(t, currentSet.Hash(), valSet.Hash(), "%d", i)
+
+		_, err = c.ValidatorsDiff(context.Background(), 0, nil)
+		assert.Error(t, err, "%d", i)
+	}
+}
+
 func TestGenesisChunked(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -329,6 +373,55 @@ func TestAppCalls(t *testing.T) {
 	}
 }
 
+func TestCanonicalJSON(t *testing.T) {
+	for i, c := range GetClients() {
+		h := int64(1)
+		require.NoError(t, client.WaitForHeight(c, h+1, nil), "%d", i)
+		block, err := c.Block(context.Background(), &h)
+		require.NoError(t, err, "%d", i)
+		commit, err := c.Commit(context.Background(), &h)
+		require.NoError(t, err, "%d", i)
+
+		for _, amino := range []bool{false, true} {
+			mode := types.CanonicalJSONSorted
+			if amino {
+				mode = types.CanonicalJSONAmino
+			}
+
+			res, err := c.CanonicalJSON(context.Background(), "block", &h, nil, amino)
+			require.NoError(t, err, "%d", i)
+			assert.Equal(t, h, res.Height)
+			decodedBlock := new(types.Block)
+			require.NoError(t, types.UnmarshalCanonicalJSON([]byte(res.JSON), decodedBlock, mode))
+			assert.Equal(t, block.Block.Hash(), decodedBlock.Hash())
+
+			res, err = c.CanonicalJSON(context.Background(), "header", &h, nil, amino)
+			require.NoError(t, err, "%d", i)
+			decodedHeader := new(types.Header)
+			require.NoError(t, types.UnmarshalCanonicalJSON([]byte(res.JSON), decodedHeader, mode))
+			assert.Equal(t, block.Block.Hash(), decodedHeader.Hash())
+
+			res, err = c.CanonicalJSON(context.Background(), "commit", &h, nil, amino)
+			require.NoError(t, err, "%d", i)
+			decodedCommit := new(types.Commit)
+			require.NoError(t, types.UnmarshalCanonicalJSON([]byte(res.JSON), decodedCommit, mode))
+			assert.Equal(t, commit.Commit.Hash(), decodedCommit.Hash())
+
+			index := 0
+			res, err = c.CanonicalJSON(context.Background(), "vote", &h, &index, amino)
+			require.NoError(t, err, "%d", i)
+			decodedVote := new(types.Vote)
+			require.NoError(t, types.UnmarshalCanonicalJSON([]byte(res.JSON), decodedVote, mode))
+			assert.Equal(t, commit.Commit.GetVote(0), decodedVote)
+		}
+
+		_, err = c.CanonicalJSON(context.Background(), "vote", &h, nil, false)
+		assert.Error(t, err, "%d", i)
+		_, err = c.CanonicalJSON(context.Background(), "evidence", &h, nil, false)
+		assert.Error(t, err, "%d", i)
+	}
+}
+
 func TestBroadcastTxSync(t *testing.T) {
 	require := require.New(t)
 
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"errors"
+
 	abci "github.com/tendermint/tendermint/abci/types"
 
 	"github.com/Finschia/ostracon/libs/bytes"
@@ -41,3 +43,22 @@ func ABCIInfo(ctx *rpctypes.Context) (*ctypes.ResultABCIInfo, error) {
 
 	return &ctypes.ResultABCIInfo{Response: *resInfo}, nil
 }
+
+// Snapshots gets the snapshots of the app archived by the node (see the
+// statesync.snapshot_interval config), the most recent first, and the progress
+// of the one being archived, if any.
+func Snapshots(ctx *rpctypes.Context) (*ctypes.ResultSnapshots, error) {
+	if env.Snapshotter == nil {
+		return nil, errors.New("the node doesn't archive the snapshots (statesync.snapshot_interval is 0)")
+	}
+	result := &ctypes.ResultSnapshots{Snapshots: env.Snapshotter.Snapshots()}
+	if progress := env.Snapshotter.Progress(); progress != nil {
+		result.InProgress = &ctypes.SnapshotProgress{
+			Height:         progress.Height,
+			Format:         progress.Format,
+			Chunks:         progress.Chunks,
+			ChunksArchived: progress.ChunksArchived,
+		}
+	}
+	return result, nil
+}
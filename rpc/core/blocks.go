@@ -13,6 +13,30 @@ import (
 	"github.com/Finschia/ostracon/types"
 )
 
+// PruningStatus gets the status of the pruning of the blocks, states and ABCI
+// responses below the retain height (see the storage.retain_blocks config and
+// the retain height returned by the app in Commit).
+func PruningStatus(ctx *rpctypes.Context) (*ctypes.ResultPruningStatus, error) {
+	if env.Pruner == nil {
+		return nil, errors.New("the node doesn't prune the blocks")
+	}
+	return pruningStatus(), nil
+}
+
+func pruningStatus() *ctypes.ResultPruningStatus {
+	status := env.Pruner.Status()
+	return &ctypes.ResultPruningStatus{
+		Base:            status.Base,
+		RetainHeight:    status.RetainHeight,
+		AppRetainHeight: status.AppRetainHeight,
+		RetainBlocks:    status.RetainBlocks,
+		Pruning:         status.Pruning,
+		LastPruned:      status.LastPruned,
+		LastPruneTime:   status.LastPruneTime,
+		LastPruneError:  status.LastPruneError,
+	}
+}
+
 // BlockchainInfo gets block headers for minHeight <= height <= maxHeight.
 // Block headers are returned in descending order (highest first).
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/blockchain
@@ -84,12 +108,18 @@ func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error)
 		return nil, err
 	}
 
+	if res, ok := env.responseCache.get("block", height); ok {
+		return res.(*ctypes.ResultBlock), nil
+	}
+
 	block := env.BlockStore.LoadBlock(height)
 	blockMeta := env.BlockStore.LoadBlockMeta(height)
 	if blockMeta == nil {
 		return &ctypes.ResultBlock{BlockID: types.BlockID{}, Block: block}, nil
 	}
-	return &ctypes.ResultBlock{BlockID: blockMeta.BlockID, Block: block}, nil
+	res := &ctypes.ResultBlock{BlockID: blockMeta.BlockID, Block: block}
+	env.responseCache.add("block", height, res, env.BlockStore.Base())
+	return res, nil
 }
 
 // BlockByHash gets block by hash.
@@ -131,33 +161,107 @@ func Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, erro
 	return ctypes.NewResultCommit(&header, commit, true), nil
 }
 
+// CanonicalJSON gets the canonical JSON encoding (see
+// types.MarshalCanonicalJSON) of the block, header or commit at a given height,
+// or of the precommit of the validator at the given index in the commit. If no
+// height is provided, it will fetch the ones of the latest block. The encoding
+// is Amino-compatible if amino is true.
+func CanonicalJSON(
+	ctx *rpctypes.Context,
+	typ string,
+	heightPtr *int64,
+	indexPtr *int,
+	amino bool,
+) (*ctypes.ResultCanonicalJSON, error) {
+	height, err := getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	switch typ {
+	case "block":
+		block := env.BlockStore.LoadBlock(height)
+		if block == nil {
+			return nil, fmt.Errorf("no block at height %d", height)
+		}
+		v = block
+	case "header":
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			return nil, fmt.Errorf("no block at height %d", height)
+		}
+		v = &blockMeta.Header
+	case "commit", "vote":
+		res, err := Commit(ctx, &height)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil || res.Commit == nil {
+			return nil, fmt.Errorf("no commit at height %d", height)
+		}
+		v = res.Commit
+		if typ == "vote" {
+			if indexPtr == nil {
+				return nil, errors.New("the index of the validator is required")
+			}
+			if *indexPtr < 0 || *indexPtr >= len(res.Commit.Signatures) {
+				return nil, fmt.Errorf("index %d is out of range [0, %d)", *indexPtr, len(res.Commit.Signatures))
+			}
+			if res.Commit.Signatures[*indexPtr].Absent() {
+				return nil, fmt.Errorf("validator %d didn't vote at height %d", *indexPtr, height)
+			}
+			v = res.Commit.GetVote(int32(*indexPtr))
+		}
+	default:
+		return nil, errors.New("expected type to be either `block`, `header`, `commit` or `vote`")
+	}
+
+	mode := types.CanonicalJSONSorted
+	if amino {
+		mode = types.CanonicalJSONAmino
+	}
+	bz, err := types.MarshalCanonicalJSON(v, mode)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultCanonicalJSON{Height: height, Type: typ, JSON: string(bz)}, nil
+}
+
 // BlockResults gets ABCIResults at a given height.
 // If no height is provided, it will fetch results for the latest block.
 // When DiscardABCIResponses is enabled, an error will be returned.
+// The results of the pruned blocks are returned with storage.retain_states.
 //
 // Results are for the height of the block containing the txs.
 // Thus response.results.deliver_tx[5] is the results of executing
 // getBlock(h).Txs[5]
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/block_results
 func BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
-	height, err := getHeight(env.BlockStore.Height(), heightPtr)
+	height, err := getStateHeight(env.BlockStore.Height(), heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
+	if res, ok := env.responseCache.get("block_results", height); ok {
+		return res.(*ctypes.ResultBlockResults), nil
+	}
+
 	results, err := env.StateStore.LoadABCIResponses(height)
 	if err != nil {
-		return nil, err
+		return nil, stateLoadError(height, err)
 	}
 
-	return &ctypes.ResultBlockResults{
+	res := &ctypes.ResultBlockResults{
 		Height:                height,
 		TxsResults:            results.DeliverTxs,
 		BeginBlockEvents:      results.BeginBlock.Events,
 		EndBlockEvents:        results.EndBlock.Events,
 		ValidatorUpdates:      results.EndBlock.ValidatorUpdates,
 		ConsensusParamUpdates: results.EndBlock.ConsensusParamUpdates,
-	}, nil
+	}
+	env.responseCache.add("block_results", height, res, env.BlockStore.Base())
+	return res, nil
 }
 
 // BlockSearch searches for a paginated set of blocks matching BeginBlock and
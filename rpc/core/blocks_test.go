@@ -235,7 +235,7 @@ func TestBlockSearch_errors(t *testing.T) {
 
 		require.Error(t, err)
 		require.Equal(t,
-			"\nparse error near Unknown (line 1 symbol 1 - line 1 symbol 1):\n\"\"\n",
+			"parse error near position 0: expected a tag",
 			err.Error())
 		require.Nil(t, res)
 	}
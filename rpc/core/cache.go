@@ -0,0 +1,92 @@
+package core
+
+import (
+	"container/list"
+
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+// responseCache is an LRU cache of the responses of the endpoints at the past
+// heights, which don't change, see rpc.response_cache_size. The responses
+// cached are shared by the requests, so they must not be modified. A nil
+// cache caches nothing.
+type responseCache struct {
+	size int
+
+	mtx     tmsync.Mutex
+	lru     *list.List // of *cacheEntry, the most recently used first
+	entries map[cacheKey]*list.Element
+	base    int64 // the base of the block store when last evicting
+}
+
+type cacheKey struct {
+	endpoint string
+	height   int64
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	response interface{}
+}
+
+// newResponseCache returns a cache of size responses, nil if size is 0.
+func newResponseCache(size int) *responseCache {
+	if size <= 0 {
+		return nil
+	}
+	return &responseCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element, size),
+	}
+}
+
+// get returns the response of the endpoint at the height, if cached.
+func (c *responseCache) get(endpoint string, height int64) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	e, ok := c.entries[cacheKey{endpoint, height}]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry).response, true
+}
+
+// add caches the response of the endpoint at the height, evicting the least
+// recently used response if full, and the ones of the heights below base,
+// which were pruned.
+func (c *responseCache) add(endpoint string, height int64, response interface{}, base int64) {
+	if c == nil {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if base > c.base {
+		c.base = base
+		for key, e := range c.entries {
+			if key.height < base {
+				c.lru.Remove(e)
+				delete(c.entries, key)
+			}
+		}
+	}
+	if height < c.base {
+		return
+	}
+
+	key := cacheKey{endpoint, height}
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, response: response})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
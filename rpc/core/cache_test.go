@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	// a nil cache caches nothing
+	assert.Nil(t, newResponseCache(0))
+	var nilCache *responseCache
+	nilCache.add("block", 1, "block 1", 1)
+	_, ok := nilCache.get("block", 1)
+	assert.False(t, ok)
+
+	c := newResponseCache(2)
+	c.add("block", 1, "block 1", 1)
+	c.add("block_results", 1, "results 1", 1)
+	res, ok := c.get("block", 1)
+	assert.True(t, ok)
+	assert.Equal(t, "block 1", res)
+
+	// the least recently used response is evicted
+	c.add("block", 2, "block 2", 1)
+	_, ok = c.get("block_results", 1)
+	assert.False(t, ok)
+	_, ok = c.get("block", 1)
+	assert.True(t, ok)
+
+	// the responses of the pruned heights are evicted, and not cached
+	c.add("block", 3, "block 3", 2)
+	_, ok = c.get("block", 1)
+	assert.False(t, ok)
+	_, ok = c.get("block", 2)
+	assert.True(t, ok)
+	c.add("validators", 1, "validators 1", 2)
+	_, ok = c.get("validators", 1)
+	assert.False(t, ok)
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"encoding/hex"
+	"fmt"
+
 	cm "github.com/Finschia/ostracon/consensus"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
@@ -10,39 +13,109 @@ import (
 
 // Validators gets the validator set at the given block height.
 //
-// If no height is provided, it will fetch the latest validator set. Note the
-// validators are sorted by their voting power - this is the canonical order
-// for the validators in the set as used in computing their Merkle root.
+// If no height is provided, it will fetch the latest validator set. The
+// validator sets of the pruned blocks are returned with storage.retain_states.
+// Note the validators are sorted by their voting power - this is the canonical
+// order for the validators in the set as used in computing their Merkle root.
+//
+// Instead of a page, a cursor can be given: the validators after the one of
+// the address cursor (hex) are returned, the page being ignored. The cursor of
+// the next validators is returned in next_cursor, empty after the last ones.
 //
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/validators
-func Validators(ctx *rpctypes.Context, heightPtr *int64, pagePtr, perPagePtr *int) (*ctypes.ResultValidators, error) {
+func Validators(
+	ctx *rpctypes.Context,
+	heightPtr *int64,
+	pagePtr, perPagePtr *int,
+	cursor string,
+) (*ctypes.ResultValidators, error) {
 	// The latest validator that we know is the NextValidator of the last block.
-	height, err := getHeight(latestUncommittedHeight(), heightPtr)
+	height, err := getStateHeight(latestUncommittedHeight(), heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	validators, err := env.StateStore.LoadValidators(height)
+	validators, err := loadValidators(height)
 	if err != nil {
 		return nil, err
 	}
 
 	totalCount := len(validators.Validators)
 	perPage := validatePerPage(perPagePtr)
-	page, err := validatePage(pagePtr, perPage, totalCount)
-	if err != nil {
-		return nil, err
+	var skipCount int
+	if cursor != "" {
+		address, err := hex.DecodeString(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		index, val := validators.GetByAddress(address)
+		if val == nil {
+			return nil, fmt.Errorf("no validator %s at height %d", cursor, height)
+		}
+		skipCount = int(index) + 1
+	} else {
+		page, err := validatePage(pagePtr, perPage, totalCount)
+		if err != nil {
+			return nil, err
+		}
+		skipCount = validateSkipCount(page, perPage)
 	}
 
-	skipCount := validateSkipCount(page, perPage)
-
 	v := validators.Validators[skipCount : skipCount+tmmath.MinInt(perPage, totalCount-skipCount)]
+	var nextCursor string
+	if len(v) > 0 && skipCount+len(v) < totalCount {
+		nextCursor = v[len(v)-1].Address.String()
+	}
 
 	return &ctypes.ResultValidators{
 		BlockHeight: height,
 		Validators:  v,
 		Count:       len(v),
-		Total:       totalCount}, nil
+		Total:       totalCount,
+		NextCursor:  nextCursor}, nil
+}
+
+// loadValidators loads the validator set at the height, from the cache of the
+// responses if cached.
+func loadValidators(height int64) (*types.ValidatorSet, error) {
+	if res, ok := env.responseCache.get("validators", height); ok {
+		return res.(*types.ValidatorSet), nil
+	}
+	validators, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, stateLoadError(height, err)
+	}
+	env.responseCache.add("validators", height, validators, env.BlockStore.Base())
+	return validators, nil
+}
+
+// ValidatorsDiff gets the diff turning the validator set at the from height
+// into the one at a given height (see types.ValidatorSetDiff), so that the
+// latter can be fetched without all its validators.
+// If no height is provided, it will fetch the diff to the latest validator set.
+func ValidatorsDiff(ctx *rpctypes.Context, from int64, heightPtr *int64) (*ctypes.ResultValidatorsDiff, error) {
+	height, err := getStateHeight(latestUncommittedHeight(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getStateHeight(latestUncommittedHeight(), &from); err != nil {
+		return nil, fmt.Errorf("invalid from height: %w", err)
+	}
+
+	fromValidators, err := env.StateStore.LoadValidators(from)
+	if err != nil {
+		return nil, stateLoadError(from, err)
+	}
+	validators, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, stateLoadError(height, err)
+	}
+
+	return &ctypes.ResultValidatorsDiff{
+		FromHeight:  from,
+		BlockHeight: height,
+		Diff:        *fromValidators.ComputeDiff(validators),
+		Total:       validators.Size()}, nil
 }
 
 // DumpConsensusState dumps consensus state.
@@ -89,20 +162,27 @@ func ConsensusState(ctx *rpctypes.Context) (*ctypes.ResultConsensusState, error)
 
 // ConsensusParams gets the consensus parameters at the given block height.
 // If no height is provided, it will fetch the latest consensus params.
+// The params of the pruned blocks are returned with storage.retain_states.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/consensus_params
 func ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultConsensusParams, error) {
 	// The latest consensus params that we know is the consensus params after the
 	// last block.
-	height, err := getHeight(latestUncommittedHeight(), heightPtr)
+	height, err := getStateHeight(latestUncommittedHeight(), heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
+	if res, ok := env.responseCache.get("consensus_params", height); ok {
+		return res.(*ctypes.ResultConsensusParams), nil
+	}
+
 	consensusParams, err := env.StateStore.LoadConsensusParams(height)
 	if err != nil {
-		return nil, err
+		return nil, stateLoadError(height, err)
 	}
-	return &ctypes.ResultConsensusParams{
+	res := &ctypes.ResultConsensusParams{
 		BlockHeight:     height,
-		ConsensusParams: consensusParams}, nil
+		ConsensusParams: consensusParams}
+	env.responseCache.add("consensus_params", height, res, env.BlockStore.Base())
+	return res, nil
 }
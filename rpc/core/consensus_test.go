@@ -96,7 +96,7 @@ func TestValidators(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Validators(tt.args.ctx, tt.args.heightPtr, tt.args.pagePtr, tt.args.perPagePtr)
+			got, err := Validators(tt.args.ctx, tt.args.heightPtr, tt.args.pagePtr, tt.args.perPagePtr, "")
 			if !tt.wantErr(t, err, fmt.Sprintf("Validators(%v, %v, %v, %v)",
 				tt.args.ctx, tt.args.heightPtr, tt.args.pagePtr, tt.args.perPagePtr)) {
 				return
@@ -106,3 +106,63 @@ func TestValidators(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatorsBelowBase(t *testing.T) {
+	state, cleanup := makeTestStateStore(t)
+	defer cleanup()
+
+	// the blocks below height 5 are pruned
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(int64(5))
+	blockStore.On("Height").Return(int64(10))
+	env.BlockStore = blockStore
+
+	// the validators of height 1 are retained
+	res, err := Validators(&rpctypes.Context{}, &height, nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, state.Validators.Validators, res.Validators)
+
+	// those of height 3 aren't
+	h := int64(3)
+	_, err = Validators(&rpctypes.Context{}, &h, nil, nil, "")
+	require.EqualError(t, err, "height 3 is not available, lowest height is 5")
+}
+
+func TestValidatorsCursor(t *testing.T) {
+	state, cleanup := makeTestStateStore(t)
+	defer cleanup()
+
+	// the validators of the genesis state are saved at height 1
+	vals, _ := types.RandValidatorSet(5, 10)
+	state.LastBlockHeight = 0
+	state.Validators = vals
+	require.NoError(t, env.StateStore.Save(state))
+	height := int64(1)
+	perPage := 2
+
+	var got []*types.Validator
+	cursor := ""
+	for {
+		res, err := Validators(&rpctypes.Context{}, &height, nil, &perPage, cursor)
+		require.NoError(t, err)
+		assert.Equal(t, 5, res.Total)
+		got = append(got, res.Validators...)
+		if res.NextCursor == "" {
+			break
+		}
+		cursor = res.NextCursor
+	}
+	assert.Equal(t, vals.Validators, got)
+
+	// the page is ignored with a cursor
+	page := 3
+	res, err := Validators(&rpctypes.Context{}, &height, &page, &perPage, vals.Validators[0].Address.String())
+	require.NoError(t, err)
+	assert.Equal(t, vals.Validators[1:3], res.Validators)
+
+	_, err = Validators(&rpctypes.Context{}, &height, nil, &perPage, "not hex")
+	assert.Error(t, err)
+	unknown, _ := types.RandValidator(false, 10)
+	_, err = Validators(&rpctypes.Context{}, &height, nil, &perPage, unknown.Address.String())
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"errors"
+
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
 )
@@ -10,3 +12,43 @@ func UnsafeFlushMempool(ctx *rpctypes.Context) (*ctypes.ResultUnsafeFlushMempool
 	env.Mempool.Flush()
 	return &ctypes.ResultUnsafeFlushMempool{}, nil
 }
+
+// UnsafePrune triggers the pruning of the blocks below the retain height
+// without waiting for the storage.pruning_interval, and returns the status of
+// the pruning, which runs in the background.
+func UnsafePrune(ctx *rpctypes.Context) (*ctypes.ResultPruningStatus, error) {
+	if env.Pruner == nil {
+		return nil, errors.New("the node doesn't prune the blocks")
+	}
+	env.Pruner.Trigger()
+	return pruningStatus(), nil
+}
+
+// UnsafeReloadConfig reloads the configuration file and applies the settings
+// which are safe to change while the node is running. The other settings
+// which have changed are returned as requiring a restart, without being
+// applied.
+func UnsafeReloadConfig(ctx *rpctypes.Context) (*ctypes.ResultReloadConfig, error) {
+	if env.ConfigReloader == nil {
+		return nil, errors.New("reloading the configuration is not supported")
+	}
+	changed, restart, err := env.ConfigReloader.ReloadConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultReloadConfig{Changed: changed, RequireRestart: restart}, nil
+}
+
+// UnsafeSetLogLevel changes the log level of the node while it is running, for
+// all the modules or per module, e.g. "consensus:debug,*:info". The change
+// isn't saved in the configuration file.
+func UnsafeSetLogLevel(ctx *rpctypes.Context, level string) (*ctypes.ResultSetLogLevel, error) {
+	if env.ConfigReloader == nil {
+		return nil, errors.New("changing the log level is not supported")
+	}
+	previous, err := env.ConfigReloader.SetLogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultSetLogLevel{Previous: previous, LogLevel: level}, nil
+}
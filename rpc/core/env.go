@@ -1,10 +1,13 @@
 package core
 
 import (
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	abci "github.com/tendermint/tendermint/abci/types"
+
 	cfg "github.com/Finschia/ostracon/config"
 	"github.com/Finschia/ostracon/consensus"
 	"github.com/Finschia/ostracon/crypto"
@@ -12,10 +15,12 @@ import (
 	"github.com/Finschia/ostracon/libs/log"
 	mempl "github.com/Finschia/ostracon/mempool"
 	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/pex"
 	"github.com/Finschia/ostracon/proxy"
 	sm "github.com/Finschia/ostracon/state"
 	"github.com/Finschia/ostracon/state/indexer"
 	"github.com/Finschia/ostracon/state/txindex"
+	"github.com/Finschia/ostracon/statesync"
 	"github.com/Finschia/ostracon/types"
 )
 
@@ -39,14 +44,36 @@ const (
 var (
 	// set by Node
 	env *Environment
+
+	// ErrWritesClosed is returned by the methods adding txs or evidence to the
+	// node once it's stopping, see CloseWrites.
+	ErrWritesClosed = errors.New("the node is stopping, not accepting txs nor evidence")
 )
 
 // SetEnvironment sets up the given Environment.
 // It will race if multiple Node call SetEnvironment.
 func SetEnvironment(e *Environment) {
+	e.responseCache = newResponseCache(e.Config.ResponseCacheSize)
 	env = e
 }
 
+// CloseWrites makes the methods adding txs or evidence to the node, e.g.
+// broadcast_tx_sync, return ErrWritesClosed, the node stopping. The other
+// methods are served until the RPC server is closed.
+func CloseWrites() {
+	if env != nil {
+		env.writesClosed.Store(true)
+	}
+}
+
+// checkWritesOpen returns ErrWritesClosed once CloseWrites is called.
+func checkWritesOpen() error {
+	if env.writesClosed.Load() {
+		return ErrWritesClosed
+	}
+	return nil
+}
+
 //----------------------------------------------
 // These interfaces are used by RPC and must be thread safe
 
@@ -64,6 +91,30 @@ type transport interface {
 	NodeInfo() p2p.NodeInfo
 }
 
+type committedEvidenceStore interface {
+	LightClientAttacks(minHeight, maxHeight int64) ([]*types.LightClientAttackEvidence, error)
+	SearchEvidence(address types.Address, minHeight, maxHeight int64) ([]types.Evidence, error)
+}
+
+type snapshotter interface {
+	Snapshots() []*abci.Snapshot
+	Progress() *statesync.SnapshotProgress
+}
+
+type crawler interface {
+	CrawlStats() pex.CrawlStats
+}
+
+type pruner interface {
+	Status() sm.PruningStatus
+	Trigger()
+}
+
+type configReloader interface {
+	ReloadConfigFile() (changed, restart []string, err error)
+	SetLogLevel(level string) (string, error)
+}
+
 type peers interface {
 	AddPersistentPeers([]string) error
 	AddUnconditionalPeerIDs([]string) error
@@ -88,6 +139,12 @@ type Environment struct {
 	P2PPeers       peers
 	P2PTransport   transport
 
+	CommittedEvidence committedEvidenceStore
+	ConfigReloader    configReloader
+	Snapshotter       snapshotter
+	Pruner            pruner
+	Crawler           crawler
+
 	// objects
 	PubKey           crypto.PubKey
 	GenDoc           *types.GenesisDoc // cache the genesis structure
@@ -101,8 +158,15 @@ type Environment struct {
 
 	Config cfg.RPCConfig
 
-	// cache of chunked genesis data.
-	genChunks []string
+	// set by CloseWrites
+	writesClosed atomic.Bool
+
+	// cache of chunked genesis data, base64 encoded when served not to be
+	// held in memory encoded.
+	genChunks [][]byte
+
+	// cache of the responses at the past heights.
+	responseCache *responseCache
 }
 
 //----------------------------------------------
@@ -165,7 +229,7 @@ func InitGenesisChunks() error {
 			end = len(data)
 		}
 
-		env.genChunks = append(env.genChunks, base64.StdEncoding.EncodeToString(data[i:end]))
+		env.genChunks = append(env.genChunks, data[i:end:end])
 	}
 
 	return nil
@@ -201,9 +265,39 @@ func getHeight(latestHeight int64, heightPtr *int64) (int64, error) {
 	return latestHeight, nil
 }
 
+// getStateHeight is getHeight for the endpoints served from the state store,
+// allowing the heights below the base of the block store: the states of the
+// pruned blocks are retained with storage.retain_states, and the state store
+// returns an error for the height otherwise.
+func getStateHeight(latestHeight int64, heightPtr *int64) (int64, error) {
+	if heightPtr != nil {
+		height := *heightPtr
+		if height <= 0 {
+			return 0, fmt.Errorf("height must be greater than 0, but got %d", height)
+		}
+		if height > latestHeight {
+			return 0, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d",
+				height, latestHeight)
+		}
+		return height, nil
+	}
+	return latestHeight, nil
+}
+
+// stateLoadError returns the error loading the state at the height from the
+// state store, or the error of getHeight if the height is below the base of
+// the block store, its state not being retained.
+func stateLoadError(height int64, err error) error {
+	if base := env.BlockStore.Base(); height < base {
+		return fmt.Errorf("height %d is not available, lowest height is %d", height, base)
+	}
+	return err
+}
+
 func latestUncommittedHeight() int64 {
-	nodeIsSyncing := env.ConsensusReactor.WaitSync()
-	if nodeIsSyncing {
+	// there is no consensus reactor when only the stores are served (see the
+	// inspect package)
+	if env.ConsensusReactor != nil && env.ConsensusReactor.WaitSync() {
 		return env.BlockStore.Height()
 	}
 	return env.BlockStore.Height() + 1
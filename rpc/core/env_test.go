@@ -95,7 +95,7 @@ func TestInitGenesisChunks(t *testing.T) {
 	err := InitGenesisChunks()
 	require.NoError(t, err)
 
-	env.genChunks = []string{}
+	env.genChunks = [][]byte{}
 	err = InitGenesisChunks()
 	require.NoError(t, err)
 
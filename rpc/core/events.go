@@ -4,12 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	abci "github.com/tendermint/tendermint/abci/types"
+
 	tmpubsub "github.com/Finschia/ostracon/libs/pubsub"
 	tmquery "github.com/Finschia/ostracon/libs/pubsub/query"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
+	blockidxnull "github.com/Finschia/ostracon/state/indexer/block/null"
+	"github.com/Finschia/ostracon/state/txindex/null"
+	"github.com/Finschia/ostracon/types"
 )
 
 const (
@@ -19,29 +26,29 @@ const (
 )
 
 // Subscribe for events via WebSocket.
+//
+// If fromHeight is positive, the events of the query from that height are
+// replayed from the indexers before the live ones, so that a client can resume
+// a subscription without missing the events of the blocks committed in the
+// meantime. The query must then be restricted to the Tx, NewBlock or
+// NewBlockHeader events (tm.event = '...'). The live events received during
+// the replay are buffered, see subscription_buffer_size.
+//
+// If filterEvents is true, only the events of the types the query refers to
+// (e.g. "transfer" for "transfer.recipient = 'addr'") are sent, instead of all
+// the events of the tx or the block.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Websocket/subscribe
-func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
+func Subscribe(
+	ctx *rpctypes.Context,
+	query string,
+	fromHeight int64,
+	filterEvents bool,
+) (*ctypes.ResultSubscribe, error) {
 	addr := ctx.RemoteAddr()
 
-	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
-		return nil, fmt.Errorf("max_subscription_clients %d reached", env.Config.MaxSubscriptionClients)
-	} else if env.EventBus.NumClientSubscriptions(addr) >= env.Config.MaxSubscriptionsPerClient {
-		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", env.Config.MaxSubscriptionsPerClient)
-	} else if len(query) > maxQueryLength {
-		return nil, errors.New("maximum query length exceeded")
-	}
-
-	env.Logger.Info("Subscribe to query", "remote", addr, "query", query)
-
-	q, err := tmquery.New(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse query: %w", err)
-	}
-
 	subCtx, cancel := context.WithTimeout(ctx.Context(), SubscribeTimeout)
 	defer cancel()
-
-	sub, err := env.EventBus.Subscribe(subCtx, addr, q, env.Config.SubscriptionBufferSize)
+	sub, err := newEventSubscription(subCtx, addr, query, fromHeight, filterEvents)
 	if err != nil {
 		return nil, err
 	}
@@ -51,49 +58,33 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 	// Capture the current ID, since it can change in the future.
 	subscriptionID := ctx.JSONReq.ID
 	go func() {
-		for {
-			select {
-			case msg := <-sub.Out():
-				var (
-					resultEvent = &ctypes.ResultEvent{Query: query, Data: msg.Data(), Events: msg.Events()}
-					resp        = rpctypes.NewRPCSuccessResponse(subscriptionID, resultEvent)
-				)
-				writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
-				if err := ctx.WSConn.WriteRPCResponse(writeCtx, resp); err != nil {
-					env.Logger.Info("Can't write response (slow client)",
-						"to", addr, "subscriptionID", subscriptionID, "err", err)
-
-					if closeIfSlow {
-						var (
-							err  = errors.New("subscription was cancelled (reason: slow client)")
-							resp = rpctypes.RPCServerError(subscriptionID, err)
-						)
-						if !ctx.WSConn.TryWriteRPCResponse(resp) {
-							env.Logger.Info("Can't write response (slow client)",
-								"to", addr, "subscriptionID", subscriptionID, "err", err)
-						}
-						return
-					}
-				}
-			case <-sub.Cancelled():
-				if sub.Err() != tmpubsub.ErrUnsubscribed {
-					var reason string
-					if sub.Err() == nil {
-						reason = "Ostracon exited"
-					} else {
-						reason = sub.Err().Error()
-					}
+		err := sub.run(context.Background(), func(resultEvent *ctypes.ResultEvent) bool {
+			resp := rpctypes.NewRPCSuccessResponse(subscriptionID, resultEvent)
+			writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := ctx.WSConn.WriteRPCResponse(writeCtx, resp); err != nil {
+				env.Logger.Info("Can't write response (slow client)",
+					"to", addr, "subscriptionID", subscriptionID, "err", err)
+
+				if closeIfSlow {
 					var (
-						err  = fmt.Errorf("subscription was cancelled (reason: %s)", reason)
+						err  = errors.New("subscription was cancelled (reason: slow client)")
 						resp = rpctypes.RPCServerError(subscriptionID, err)
 					)
 					if !ctx.WSConn.TryWriteRPCResponse(resp) {
 						env.Logger.Info("Can't write response (slow client)",
 							"to", addr, "subscriptionID", subscriptionID, "err", err)
 					}
+					return false
 				}
-				return
+			}
+			return true
+		})
+		if err != nil {
+			resp := rpctypes.RPCServerError(subscriptionID, err)
+			if !ctx.WSConn.TryWriteRPCResponse(resp) {
+				env.Logger.Info("Can't write response (slow client)",
+					"to", addr, "subscriptionID", subscriptionID, "err", err)
 			}
 		}
 	}()
@@ -101,6 +92,156 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 	return &ctypes.ResultSubscribe{}, nil
 }
 
+// SubscribeEvents subscribes the subscriber to the events of the query like
+// Subscribe, calling send with each event until ctx is done or send fails.
+// It returns the error of send, or the reason the subscription was cancelled.
+func SubscribeEvents(
+	ctx context.Context,
+	subscriber string,
+	query string,
+	fromHeight int64,
+	filterEvents bool,
+	send func(*ctypes.ResultEvent) error,
+) error {
+	subCtx, cancel := context.WithTimeout(ctx, SubscribeTimeout)
+	defer cancel()
+	sub, err := newEventSubscription(subCtx, subscriber, query, fromHeight, filterEvents)
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	err = sub.run(ctx, func(resultEvent *ctypes.ResultEvent) bool {
+		sendErr = send(resultEvent)
+		return sendErr == nil
+	})
+	if sendErr != nil {
+		// unless already unsubscribed once ctx is done
+		if ctx.Err() == nil {
+			if err := env.EventBus.Unsubscribe(context.Background(), subscriber, sub.q); err != nil {
+				env.Logger.Error("Failed to unsubscribe", "subscriber", subscriber, "query", query, "err", err)
+			}
+		}
+		return sendErr
+	}
+	return err
+}
+
+// eventSubscription is a subscription of a client to the events of a query,
+// replaying and filtering them as requested, see Subscribe.
+type eventSubscription struct {
+	subscriber string
+	query      string
+	q          *tmquery.Query
+	sub        types.Subscription
+	replay     *eventReplay // nil - no replay
+	filter     *eventFilter // nil - all the events are sent
+}
+
+func newEventSubscription(
+	ctx context.Context,
+	subscriber string,
+	query string,
+	fromHeight int64,
+	filterEvents bool,
+) (*eventSubscription, error) {
+	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
+		return nil, fmt.Errorf("max_subscription_clients %d reached", env.Config.MaxSubscriptionClients)
+	} else if env.EventBus.NumClientSubscriptions(subscriber) >= env.Config.MaxSubscriptionsPerClient {
+		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", env.Config.MaxSubscriptionsPerClient)
+	} else if len(query) > maxQueryLength {
+		return nil, errors.New("maximum query length exceeded")
+	} else if fromHeight < 0 {
+		return nil, errors.New("from_height can't be negative")
+	}
+
+	env.Logger.Info("Subscribe to query", "remote", subscriber, "query", query,
+		"from_height", fromHeight, "filter_events", filterEvents)
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+	s := &eventSubscription{subscriber: subscriber, query: query, q: q}
+	if fromHeight > 0 {
+		if s.replay, err = newEventReplay(q, fromHeight); err != nil {
+			return nil, err
+		}
+	}
+	if filterEvents {
+		s.filter = newEventFilter(q)
+	}
+
+	policy, err := tmpubsub.ParseOverflowPolicy(env.Config.SubscriptionOverflowPolicy)
+	if err != nil {
+		return nil, err
+	}
+	s.sub, err = env.EventBus.SubscribeWithPolicy(ctx, subscriber, q, env.Config.SubscriptionBufferSize, policy)
+	if err != nil {
+		return nil, err
+	}
+	// the events of the heights committed until now are replayed, the
+	// subscription sending the following ones
+	if s.replay != nil {
+		s.replay.toHeight = env.BlockStore.Height()
+	}
+	return s, nil
+}
+
+// run writes the events replayed, and then the ones of the subscription,
+// until ctx is done, write returns false or the subscription is cancelled. It
+// returns the reason the subscription was cancelled, nil if unsubscribed.
+func (s *eventSubscription) run(ctx context.Context, write func(*ctypes.ResultEvent) bool) error {
+	send := func(resultEvent *ctypes.ResultEvent) bool {
+		if s.filter != nil {
+			s.filter.apply(resultEvent)
+		}
+		return write(resultEvent)
+	}
+
+	if s.replay != nil {
+		if err := s.replay.run(s.sub, send); errors.Is(err, errReplayStopped) {
+			return nil
+		} else if err != nil {
+			env.Logger.Info("Failed to replay events", "to", s.subscriber, "query", s.query, "err", err)
+			if err := env.EventBus.Unsubscribe(context.Background(), s.subscriber, s.q); err != nil {
+				env.Logger.Error("Failed to unsubscribe", "to", s.subscriber, "query", s.query, "err", err)
+			}
+			return fmt.Errorf("subscription was cancelled (reason: failed to replay events: %w)", err)
+		}
+	}
+
+	for {
+		select {
+		case msg := <-s.sub.Out():
+			// skip the events already replayed
+			if s.replay != nil {
+				if height, ok := eventHeight(msg.Data()); ok && height <= s.replay.toHeight {
+					continue
+				}
+			}
+			resultEvent := &ctypes.ResultEvent{Query: s.query, Data: msg.Data(), Events: msg.Events()}
+			if !send(resultEvent) {
+				return nil
+			}
+		case <-s.sub.Cancelled():
+			if s.sub.Err() == tmpubsub.ErrUnsubscribed {
+				return nil
+			}
+			reason := "Ostracon exited"
+			if s.sub.Err() != nil {
+				reason = s.sub.Err().Error()
+			}
+			return fmt.Errorf("subscription was cancelled (reason: %s)", reason)
+		case <-ctx.Done():
+			if err := env.EventBus.Unsubscribe(context.Background(), s.subscriber, s.q); err != nil {
+				env.Logger.Error("Failed to unsubscribe", "to", s.subscriber, "query", s.query, "err", err)
+			}
+			return nil
+		}
+	}
+}
+
 // Unsubscribe from events via WebSocket.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Websocket/unsubscribe
 func Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
@@ -128,3 +269,331 @@ func UnsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
 	}
 	return &ctypes.ResultUnsubscribe{}, nil
 }
+
+const (
+	// replaySearchHeights is the number of heights searched at once in the
+	// indexers to replay the events of a subscription.
+	replaySearchHeights = 1000
+	// replayIndexTimeout is the maximum time a replay waits for the indexers
+	// to index the last committed block.
+	replayIndexTimeout = 10 * time.Second
+)
+
+// eventReplay replays the events of a subscription from the indexers, from
+// fromHeight to toHeight, the last height committed when subscribing.
+type eventReplay struct {
+	query      string   // the query of the subscription
+	eventType  string   // the tm.event of the query
+	conditions []string // the conditions of the query, but the tm.event one
+	heightKey  string   // the composite key of the heights in the indexer
+	fromHeight int64
+	toHeight   int64
+}
+
+func newEventReplay(q *tmquery.Query, fromHeight int64) (*eventReplay, error) {
+	r := &eventReplay{query: q.String(), fromHeight: fromHeight}
+	var operands []*tmquery.Expr
+	switch expr := q.Expr(); expr.Kind {
+	case tmquery.ExprCondition:
+		operands = []*tmquery.Expr{expr}
+	case tmquery.ExprAnd:
+		operands = expr.Operands
+	}
+	for _, operand := range operands {
+		c := operand.Condition
+		if operand.Kind == tmquery.ExprCondition && c.CompositeKey == types.EventTypeKey && c.Op == tmquery.OpEqual {
+			if eventType, ok := c.Operand.(string); ok && r.eventType == "" {
+				r.eventType = eventType
+				continue
+			}
+		}
+		r.conditions = append(r.conditions, operand.String())
+	}
+
+	switch r.eventType {
+	case types.EventTx:
+		if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+			return nil, errors.New("transaction indexing is disabled")
+		}
+		r.heightKey = types.TxHeightKey
+	case types.EventNewBlock, types.EventNewBlockHeader:
+		r.heightKey = types.BlockHeightKey
+	default:
+		return nil, fmt.Errorf("the events can only be replayed for a query of the %s, %s or %s events (%s = '...')",
+			types.EventTx, types.EventNewBlock, types.EventNewBlockHeader, types.EventTypeKey)
+	}
+	// the block indexer tells the heights indexed
+	if _, ok := env.BlockIndexer.(*blockidxnull.BlockerIndexer); ok {
+		return nil, errors.New("block indexing is disabled")
+	}
+	return r, nil
+}
+
+// errReplayStopped is returned by eventReplay.run once the subscriber is gone.
+var errReplayStopped = errors.New("the events replay was stopped")
+
+// run writes the events replayed, stopping if the subscription is cancelled.
+func (r *eventReplay) run(sub types.Subscription, write func(*ctypes.ResultEvent) bool) error {
+	if r.fromHeight > r.toHeight {
+		return nil
+	}
+	if err := r.waitIndexed(sub); err != nil {
+		return err
+	}
+	for from := r.fromHeight; from <= r.toHeight; from += replaySearchHeights {
+		select {
+		case <-sub.Cancelled():
+			return nil
+		default:
+		}
+
+		to := from + replaySearchHeights - 1
+		if to > r.toHeight {
+			to = r.toHeight
+		}
+		var (
+			events []*ctypes.ResultEvent
+			err    error
+		)
+		if r.eventType == types.EventTx {
+			events, err = r.txEvents(from, to)
+		} else {
+			events, err = r.blockEvents(from, to)
+		}
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if !write(event) {
+				return errReplayStopped
+			}
+		}
+	}
+	return nil
+}
+
+// waitIndexed waits for the indexers to index the events of toHeight, which
+// may have been committed just before subscribing.
+func (r *eventReplay) waitIndexed(sub types.Subscription) error {
+	deadline := time.Now().Add(replayIndexTimeout)
+	for {
+		indexed, err := env.BlockIndexer.Has(r.toHeight)
+		if err != nil {
+			return err
+		}
+		if indexed {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("height %d isn't indexed yet", r.toHeight)
+		}
+		select {
+		case <-sub.Cancelled():
+			return nil
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// search returns the query of the indexer for the heights from from to to.
+func (r *eventReplay) search(from, to int64) (*tmquery.Query, error) {
+	conditions := append([]string{}, r.conditions...)
+	conditions = append(conditions,
+		fmt.Sprintf("%s >= %d", r.heightKey, from), fmt.Sprintf("%s <= %d", r.heightKey, to))
+	return tmquery.New(strings.Join(conditions, " AND "))
+}
+
+func (r *eventReplay) txEvents(from, to int64) ([]*ctypes.ResultEvent, error) {
+	q, err := r.search(from, to)
+	if err != nil {
+		return nil, err
+	}
+	results, err := env.TxIndexer.Search(context.Background(), q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Height == results[j].Height {
+			return results[i].Index < results[j].Index
+		}
+		return results[i].Height < results[j].Height
+	})
+
+	events := make([]*ctypes.ResultEvent, 0, len(results))
+	for _, result := range results {
+		if result.Height < from || result.Height > to {
+			continue
+		}
+		// the events of the tx, as published by the event bus
+		txEvents := stringifyEvents(result.Result.Events)
+		txEvents[types.EventTypeKey] = append(txEvents[types.EventTypeKey], types.EventTx)
+		txEvents[types.TxHashKey] = append(txEvents[types.TxHashKey], fmt.Sprintf("%X", types.Tx(result.Tx).Hash()))
+		txEvents[types.TxHeightKey] = append(txEvents[types.TxHeightKey], fmt.Sprintf("%d", result.Height))
+		events = append(events, &ctypes.ResultEvent{
+			Query:  r.query,
+			Data:   types.EventDataTx{TxResult: *result},
+			Events: txEvents,
+		})
+	}
+	return events, nil
+}
+
+func (r *eventReplay) blockEvents(from, to int64) ([]*ctypes.ResultEvent, error) {
+	q, err := r.search(from, to)
+	if err != nil {
+		return nil, err
+	}
+	heights, err := env.BlockIndexer.Search(context.Background(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*ctypes.ResultEvent, 0, len(heights))
+	for _, height := range heights {
+		block := env.BlockStore.LoadBlock(height)
+		if block == nil {
+			return nil, fmt.Errorf("block %d isn't stored (pruned?)", height)
+		}
+		responses, err := env.StateStore.LoadABCIResponses(height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the ABCI responses of block %d: %w", height, err)
+		}
+		var (
+			beginBlock abci.ResponseBeginBlock
+			endBlock   abci.ResponseEndBlock
+		)
+		if responses.BeginBlock != nil {
+			beginBlock = *responses.BeginBlock
+		}
+		if responses.EndBlock != nil {
+			endBlock = *responses.EndBlock
+		}
+
+		var data interface{}
+		if r.eventType == types.EventNewBlock {
+			data = types.EventDataNewBlock{Block: block, ResultBeginBlock: beginBlock, ResultEndBlock: endBlock}
+		} else {
+			data = types.EventDataNewBlockHeader{
+				Header:           block.Header,
+				NumTxs:           int64(len(block.Txs)),
+				ResultBeginBlock: beginBlock,
+				ResultEndBlock:   endBlock,
+			}
+		}
+		// the events of the block, as published by the event bus
+		blockEvents := stringifyEvents(beginBlock.Events)
+		for key, values := range stringifyEvents(endBlock.Events) {
+			blockEvents[key] = append(blockEvents[key], values...)
+		}
+		blockEvents[types.EventTypeKey] = append(blockEvents[types.EventTypeKey], r.eventType)
+		events = append(events, &ctypes.ResultEvent{Query: r.query, Data: data, Events: blockEvents})
+	}
+	return events, nil
+}
+
+// stringifyEvents returns the attributes of the events by composite key.
+func stringifyEvents(events []abci.Event) map[string][]string {
+	result := make(map[string][]string)
+	for _, event := range events {
+		if len(event.Type) == 0 {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 {
+				continue
+			}
+			compositeKey := types.CompositeEventKey(event.Type, string(attr.Key))
+			result[compositeKey] = append(result[compositeKey], string(attr.Value))
+		}
+	}
+	return result
+}
+
+// eventHeight returns the height of the events of a tx or a block.
+func eventHeight(data interface{}) (int64, bool) {
+	switch data := data.(type) {
+	case types.EventDataTx:
+		return data.Height, true
+	case types.EventDataNewBlock:
+		return data.Block.Height, true
+	case types.EventDataNewBlockHeader:
+		return data.Header.Height, true
+	default:
+		return 0, false
+	}
+}
+
+// eventFilter trims the events sent to a subscriber to the ones of the types
+// the query refers to, the type of an event being its composite key up to its
+// last dot.
+type eventFilter struct {
+	types map[string]bool
+}
+
+func newEventFilter(q *tmquery.Query) *eventFilter {
+	f := &eventFilter{types: make(map[string]bool)}
+	var walk func(expr *tmquery.Expr)
+	walk = func(expr *tmquery.Expr) {
+		if expr.Kind == tmquery.ExprCondition {
+			if eventType := compositeKeyType(expr.Condition.CompositeKey); eventType != "" {
+				f.types[eventType] = true
+			}
+			return
+		}
+		for _, operand := range expr.Operands {
+			walk(operand)
+		}
+	}
+	walk(q.Expr())
+	return f
+}
+
+// apply trims the events of the event, and of its tx or block results. The
+// predefined tm.event, tx.hash and tx.height events are always sent.
+func (f *eventFilter) apply(event *ctypes.ResultEvent) {
+	events := make(map[string][]string, len(event.Events))
+	for key, values := range event.Events {
+		switch key {
+		case types.EventTypeKey, types.TxHashKey, types.TxHeightKey:
+			events[key] = values
+		default:
+			if f.types[compositeKeyType(key)] {
+				events[key] = values
+			}
+		}
+	}
+	event.Events = events
+
+	switch data := event.Data.(type) {
+	case types.EventDataTx:
+		data.Result.Events = f.abciEvents(data.Result.Events)
+		event.Data = data
+	case types.EventDataNewBlock:
+		data.ResultBeginBlock.Events = f.abciEvents(data.ResultBeginBlock.Events)
+		data.ResultEndBlock.Events = f.abciEvents(data.ResultEndBlock.Events)
+		event.Data = data
+	case types.EventDataNewBlockHeader:
+		data.ResultBeginBlock.Events = f.abciEvents(data.ResultBeginBlock.Events)
+		data.ResultEndBlock.Events = f.abciEvents(data.ResultEndBlock.Events)
+		event.Data = data
+	}
+}
+
+// abciEvents returns the events of the types of the filter, in a new slice
+// since the events are shared by the subscribers.
+func (f *eventFilter) abciEvents(events []abci.Event) []abci.Event {
+	filtered := make([]abci.Event, 0, len(events))
+	for _, event := range events {
+		if f.types[event.Type] {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func compositeKeyType(compositeKey string) string {
+	if i := strings.LastIndex(compositeKey, "."); i > 0 {
+		return compositeKey[:i]
+	}
+	return ""
+}
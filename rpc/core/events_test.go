@@ -0,0 +1,79 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	dbm "github.com/tendermint/tm-db"
+
+	tmquery "github.com/Finschia/ostracon/libs/pubsub/query"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	blockidxkv "github.com/Finschia/ostracon/state/indexer/block/kv"
+	blockidxnull "github.com/Finschia/ostracon/state/indexer/block/null"
+	txidxkv "github.com/Finschia/ostracon/state/txindex/kv"
+	txidxnull "github.com/Finschia/ostracon/state/txindex/null"
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestEventFilter(t *testing.T) {
+	txEvents := []abci.Event{
+		{Type: "transfer", Attributes: []abci.EventAttribute{{Key: []byte("recipient"), Value: []byte("addr")}}},
+		{Type: "message", Attributes: []abci.EventAttribute{{Key: []byte("sender"), Value: []byte("addr")}}},
+	}
+	data := types.EventDataTx{TxResult: abci.TxResult{Height: 1, Result: abci.ResponseDeliverTx{Events: txEvents}}}
+	event := &ctypes.ResultEvent{
+		Data: data,
+		Events: map[string][]string{
+			types.EventTypeKey:   {types.EventTx},
+			types.TxHeightKey:    {"1"},
+			"transfer.recipient": {"addr"},
+			"message.sender":     {"addr"},
+		},
+	}
+
+	filter := newEventFilter(tmquery.MustParse("tm.event = 'Tx' AND (transfer.recipient = 'addr' OR tx.height > 5)"))
+	filter.apply(event)
+	assert.Equal(t, map[string][]string{
+		types.EventTypeKey:   {types.EventTx},
+		types.TxHeightKey:    {"1"},
+		"transfer.recipient": {"addr"},
+	}, event.Events)
+	filtered, ok := event.Data.(types.EventDataTx)
+	require.True(t, ok)
+	assert.Equal(t, txEvents[:1], filtered.Result.Events)
+	// the events shared with the other subscribers are left as they are
+	assert.Len(t, data.Result.Events, 2)
+}
+
+func TestNewEventReplay(t *testing.T) {
+	env = &Environment{TxIndexer: txidxkv.NewTxIndex(dbm.NewMemDB()), BlockIndexer: blockidxkv.New(dbm.NewMemDB())}
+
+	replay, err := newEventReplay(tmquery.MustParse("tm.event = 'Tx' AND (app.key = 'a' OR app.key = 'b')"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, types.EventTx, replay.eventType)
+	q, err := replay.search(5, 9)
+	require.NoError(t, err)
+	assert.Equal(t, "(app.key = 'a' OR app.key = 'b') AND tx.height >= 5 AND tx.height <= 9", q.String())
+
+	replay, err = newEventReplay(tmquery.MustParse("tm.event = 'NewBlock'"), 5)
+	require.NoError(t, err)
+	q, err = replay.search(5, 9)
+	require.NoError(t, err)
+	assert.Equal(t, "block.height >= 5 AND block.height <= 9", q.String())
+
+	// the events of the other types can't be replayed
+	_, err = newEventReplay(tmquery.MustParse("app.key = 'a'"), 5)
+	assert.Error(t, err)
+	_, err = newEventReplay(tmquery.MustParse("tm.event = 'Vote'"), 5)
+	assert.Error(t, err)
+
+	// nor the events which aren't indexed
+	env.TxIndexer = &txidxnull.TxIndex{}
+	_, err = newEventReplay(tmquery.MustParse("tm.event = 'Tx'"), 5)
+	assert.Error(t, err)
+	env.BlockIndexer = &blockidxnull.BlockerIndexer{}
+	_, err = newEventReplay(tmquery.MustParse("tm.event = 'NewBlock'"), 5)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Finschia/ostracon/crypto"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
 	"github.com/Finschia/ostracon/types"
@@ -12,6 +13,9 @@ import (
 // BroadcastEvidence broadcasts evidence of the misbehavior.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/broadcast_evidence
 func BroadcastEvidence(ctx *rpctypes.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
+	if err := checkWritesOpen(); err != nil {
+		return nil, err
+	}
 	if ev == nil {
 		return nil, errors.New("no evidence was provided")
 	}
@@ -25,3 +29,59 @@ func BroadcastEvidence(ctx *rpctypes.Context, ev types.Evidence) (*ctypes.Result
 	}
 	return &ctypes.ResultBroadcastEvidence{Hash: ev.Hash()}, nil
 }
+
+// LightClientAttacks gets the committed light client attack evidence, including
+// the complete conflicting light blocks signed by the attackers, with a common
+// height within [minHeight, maxHeight] (inclusive). minHeight defaults to 1 and
+// maxHeight to the latest height.
+func LightClientAttacks(ctx *rpctypes.Context, minHeight, maxHeight int64) (*ctypes.ResultLightClientAttacks, error) {
+	minHeight, maxHeight, err := filterEvidenceHeights(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := env.CommittedEvidence.LightClientAttacks(minHeight, maxHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load light client attacks: %w", err)
+	}
+	return &ctypes.ResultLightClientAttacks{Evidence: evidence}, nil
+}
+
+// EvidenceSearch gets the committed evidence against the validator with the
+// given address, with a height within [from, to] (inclusive). from defaults to
+// 1 and to to the latest height.
+//
+// Only the evidence committed after the node started indexing it is returned.
+func EvidenceSearch(ctx *rpctypes.Context, validator []byte, from, to int64) (*ctypes.ResultEvidenceSearch, error) {
+	if len(validator) != crypto.AddressSize {
+		return nil, fmt.Errorf("expected validator address size %d, got %d", crypto.AddressSize, len(validator))
+	}
+	from, to, err := filterEvidenceHeights(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := env.CommittedEvidence.SearchEvidence(validator, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search evidence: %w", err)
+	}
+	return &ctypes.ResultEvidenceSearch{Evidence: evidence}, nil
+}
+
+// filterEvidenceHeights applies the defaults to the height range of the
+// committed evidence queries.
+func filterEvidenceHeights(min, max int64) (int64, int64, error) {
+	if min < 0 || max < 0 {
+		return min, max, errors.New("heights must be non-negative")
+	}
+	if min == 0 {
+		min = 1
+	}
+	if max == 0 {
+		max = env.BlockStore.Height()
+	}
+	if min > max {
+		return min, max, fmt.Errorf("min height %d can't be greater than max height %d", min, max)
+	}
+	return min, max, nil
+}
@@ -7,8 +7,15 @@ import (
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	ocabci "github.com/Finschia/ostracon/abci/types"
+	tmmath "github.com/Finschia/ostracon/libs/math"
+	"github.com/Finschia/ostracon/libs/tracing"
 	mempl "github.com/Finschia/ostracon/mempool"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
@@ -22,12 +29,19 @@ import (
 // CheckTx nor DeliverTx results.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Tx/broadcast_tx_async
 func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	if err := checkWritesOpen(); err != nil {
+		return nil, err
+	}
+	span := startBroadcastTxSpan(ctx, tx, "async")
+	defer span.End()
+
 	chErr := make(chan error)
 	env.Mempool.CheckTxAsync(tx, mempl.TxInfo{}, func(err error) {
 		chErr <- err
 	}, nil)
 	err := <-chErr
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
@@ -38,6 +52,12 @@ func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadca
 // DeliverTx result.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Tx/broadcast_tx_sync
 func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	if err := checkWritesOpen(); err != nil {
+		return nil, err
+	}
+	span := startBroadcastTxSpan(ctx, tx, "sync")
+	defer span.End()
+
 	resCh := make(chan *ocabci.Response, 1)
 	err := env.Mempool.CheckTxSync(tx, func(res *ocabci.Response) {
 		select {
@@ -47,6 +67,7 @@ func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcas
 
 	}, mempl.TxInfo{})
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
@@ -55,6 +76,7 @@ func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcas
 		return nil, fmt.Errorf("broadcast confirmation not received: %w", ctx.Context().Err())
 	case res := <-resCh:
 		r := res.GetCheckTx()
+		span.SetAttributes(attribute.Int64("check_tx.code", int64(r.Code)))
 		return &ctypes.ResultBroadcastTx{
 			Code:      r.Code,
 			Data:      r.Data,
@@ -68,6 +90,12 @@ func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcas
 // BroadcastTxCommit returns with the responses from CheckTx and DeliverTx.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Tx/broadcast_tx_commit
 func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	if err := checkWritesOpen(); err != nil {
+		return nil, err
+	}
+	span := startBroadcastTxSpan(ctx, tx, "commit")
+	defer span.End()
+
 	subscriber := ctx.RemoteAddr()
 
 	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
@@ -109,6 +137,7 @@ func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadc
 		return nil, fmt.Errorf("broadcast confirmation not received: %w", ctx.Context().Err())
 	case checkTxResMsg := <-checkTxResCh:
 		checkTxRes := checkTxResMsg.GetCheckTx()
+		span.SetAttributes(attribute.Int64("check_tx.code", int64(checkTxRes.Code)))
 		if checkTxRes.Code != abci.CodeTypeOK {
 			return &ctypes.ResultBroadcastTxCommit{
 				CheckTx:   *checkTxRes,
@@ -121,6 +150,10 @@ func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadc
 		select {
 		case msg := <-deliverTxSub.Out(): // The tx was included in a block.
 			deliverTxRes := msg.Data().(types.EventDataTx)
+			span.SetAttributes(
+				attribute.Int64("height", deliverTxRes.Height),
+				attribute.Int64("deliver_tx.code", int64(deliverTxRes.Result.Code)),
+			)
 			return &ctypes.ResultBroadcastTxCommit{
 				CheckTx:   *checkTxRes,
 				DeliverTx: deliverTxRes.Result,
@@ -153,6 +186,24 @@ func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadc
 	}
 }
 
+// startBroadcastTxSpan starts the span of the request broadcasting the
+// transaction, as a child of the span given by the traceparent header of the
+// HTTP request if any, and tracks the transaction with it (see libs/tracing).
+func startBroadcastTxSpan(ctx *rpctypes.Context, tx types.Tx, mode string) trace.Span {
+	spanCtx := ctx.Context()
+	if ctx.HTTPReq != nil {
+		spanCtx = otel.GetTextMapPropagator().Extract(spanCtx, propagation.HeaderCarrier(ctx.HTTPReq.Header))
+	}
+	spanCtx, span := tracing.Tracer().Start(spanCtx, "broadcast_tx",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("tx.hash", fmt.Sprintf("%X", tx.Hash())),
+			attribute.String("mode", mode),
+		))
+	tracing.TrackTx(spanCtx, tx.Key())
+	return span
+}
+
 // UnconfirmedTxs gets unconfirmed transactions (maximum ?limit entries)
 // including their number.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Info/unconfirmed_txs
@@ -177,6 +228,71 @@ func NumUnconfirmedTxs(ctx *rpctypes.Context) (*ctypes.ResultUnconfirmedTxs, err
 		TotalBytes: env.Mempool.SizeBytes()}, nil
 }
 
+// PendingTx returns the transaction of the hash if it's in the mempool, waiting
+// to be committed, unlike Tx which returns the committed ones.
+func PendingTx(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultPendingTx, error) {
+	if len(hash) != types.TxKeySize {
+		return nil, fmt.Errorf("tx hash must be %d bytes, got %d", types.TxKeySize, len(hash))
+	}
+	var key types.TxKey
+	copy(key[:], hash)
+	tx, ok := env.Mempool.GetPendingTx(key)
+	if !ok {
+		return nil, fmt.Errorf("tx (%X) not found in the mempool", hash)
+	}
+	return newResultPendingTx(tx), nil
+}
+
+// PendingTxs returns a page of the transactions of the mempool, by order of
+// reaping, with at least minGasWanted wanted gas and checked at minHeight or
+// later, if given.
+func PendingTxs(
+	ctx *rpctypes.Context,
+	pagePtr, perPagePtr *int,
+	minGasWantedPtr, minHeightPtr *int64,
+) (*ctypes.ResultPendingTxs, error) {
+	txs := env.Mempool.PendingTxs()
+	if minGasWantedPtr != nil || minHeightPtr != nil {
+		filtered := txs[:0]
+		for _, tx := range txs {
+			if minGasWantedPtr != nil && tx.GasWanted < *minGasWantedPtr {
+				continue
+			}
+			if minHeightPtr != nil && tx.Height < *minHeightPtr {
+				continue
+			}
+			filtered = append(filtered, tx)
+		}
+		txs = filtered
+	}
+
+	totalCount := len(txs)
+	perPage := validatePerPage(perPagePtr)
+	page, err := validatePage(pagePtr, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
+
+	results := make([]*ctypes.ResultPendingTx, 0, pageSize)
+	for _, tx := range txs[skipCount : skipCount+pageSize] {
+		results = append(results, newResultPendingTx(tx))
+	}
+	return &ctypes.ResultPendingTxs{Txs: results, TotalCount: totalCount}, nil
+}
+
+func newResultPendingTx(tx mempl.PendingTx) *ctypes.ResultPendingTx {
+	return &ctypes.ResultPendingTx{
+		Hash:      tx.Tx.Hash(),
+		Tx:        tx.Tx,
+		Height:    tx.Height,
+		Timestamp: tx.Timestamp,
+		GasWanted: tx.GasWanted,
+		Priority:  tx.Priority,
+	}
+}
+
 // CheckTx checks the transaction without executing it. The transaction won't
 // be added to the mempool either.
 // More: https://docs.tendermint.com/v0.34/rpc/#/Tx/check_tx
@@ -9,6 +9,7 @@ import (
 	"github.com/Finschia/ostracon/config"
 	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/mempool"
+	memmock "github.com/Finschia/ostracon/mempool/mock"
 	memv0 "github.com/Finschia/ostracon/mempool/v0"
 	"github.com/Finschia/ostracon/proxy/mocks"
 	ctypes "github.com/Finschia/ostracon/rpc/core/types"
@@ -16,6 +17,7 @@ import (
 	"github.com/Finschia/ostracon/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"net/http"
 	"sync"
@@ -84,6 +86,18 @@ func TestBroadcastTxAsync(t *testing.T) {
 	}
 }
 
+func TestCloseWrites(t *testing.T) {
+	env = &Environment{}
+	CloseWrites()
+
+	_, err := BroadcastTxAsync(&rpctypes.Context{}, types.Tx{})
+	assert.Equal(t, ErrWritesClosed, err)
+	_, err = BroadcastTxSync(&rpctypes.Context{}, types.Tx{})
+	assert.Equal(t, ErrWritesClosed, err)
+	_, err = BroadcastTxCommit(&rpctypes.Context{}, types.Tx{})
+	assert.Equal(t, ErrWritesClosed, err)
+}
+
 func TestBroadcastTxSync(t *testing.T) {
 	type args struct {
 		ctx *rpctypes.Context
@@ -437,3 +451,87 @@ func TestBroadcastTxCommitTimeout(t *testing.T) {
 		})
 	}
 }
+
+// pendingMempool is a mempool of the given pending txs.
+type pendingMempool struct {
+	memmock.Mempool
+	txs []mempool.PendingTx
+}
+
+func (mp pendingMempool) GetPendingTx(txKey types.TxKey) (mempool.PendingTx, bool) {
+	for _, tx := range mp.txs {
+		if tx.Tx.Key() == txKey {
+			return tx, true
+		}
+	}
+	return mempool.PendingTx{}, false
+}
+
+func (mp pendingMempool) PendingTxs() []mempool.PendingTx {
+	return append([]mempool.PendingTx(nil), mp.txs...)
+}
+
+func TestPendingTxs(t *testing.T) {
+	now := time.Now()
+	var txs []mempool.PendingTx
+	for i := 0; i < 5; i++ {
+		txs = append(txs, mempool.PendingTx{
+			Tx:        types.Tx(fmt.Sprintf("tx%d", i)),
+			Height:    int64(i / 2),
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+			GasWanted: int64(100 * i),
+			Priority:  int64(i),
+		})
+	}
+	env = &Environment{Mempool: pendingMempool{txs: txs}}
+
+	// by hash
+	res, err := PendingTx(&rpctypes.Context{}, txs[3].Tx.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, &ctypes.ResultPendingTx{
+		Hash:      txs[3].Tx.Hash(),
+		Tx:        txs[3].Tx,
+		Height:    1,
+		Timestamp: txs[3].Timestamp,
+		GasWanted: 300,
+		Priority:  3,
+	}, res)
+	_, err = PendingTx(&rpctypes.Context{}, types.Tx("tx5").Hash())
+	assert.ErrorContains(t, err, "not found in the mempool")
+	_, err = PendingTx(&rpctypes.Context{}, []byte{1, 2, 3})
+	assert.Error(t, err)
+
+	txsOf := func(res *ctypes.ResultPendingTxs) []types.Tx {
+		var txs []types.Tx
+		for _, tx := range res.Txs {
+			txs = append(txs, tx.Tx)
+		}
+		return txs
+	}
+	intPtr := func(i int) *int { return &i }
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	// paginated
+	all, err := PendingTxs(&rpctypes.Context{}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, all.TotalCount)
+	assert.Equal(t, []types.Tx{txs[0].Tx, txs[1].Tx, txs[2].Tx, txs[3].Tx, txs[4].Tx}, txsOf(all))
+	page, err := PendingTxs(&rpctypes.Context{}, intPtr(2), intPtr(2), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.TotalCount)
+	assert.Equal(t, []types.Tx{txs[2].Tx, txs[3].Tx}, txsOf(page))
+	page, err = PendingTxs(&rpctypes.Context{}, intPtr(3), intPtr(2), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []types.Tx{txs[4].Tx}, txsOf(page))
+	_, err = PendingTxs(&rpctypes.Context{}, intPtr(4), intPtr(2), nil, nil)
+	assert.Error(t, err)
+
+	// filtered
+	filtered, err := PendingTxs(&rpctypes.Context{}, nil, nil, int64Ptr(200), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, filtered.TotalCount)
+	assert.Equal(t, []types.Tx{txs[2].Tx, txs[3].Tx, txs[4].Tx}, txsOf(filtered))
+	filtered, err = PendingTxs(&rpctypes.Context{}, nil, nil, int64Ptr(200), int64Ptr(2))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Tx{txs[4].Tx}, txsOf(filtered))
+}
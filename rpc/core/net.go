@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -38,6 +39,28 @@ func NetInfo(ctx *rpctypes.Context) (*ctypes.ResultNetInfo, error) {
 	}, nil
 }
 
+// CrawlStats gets the statistics of the network crawl of the node, in seed
+// mode (see the mode and p2p.seed_mode configs).
+func CrawlStats(ctx *rpctypes.Context) (*ctypes.ResultCrawlStats, error) {
+	if env.Crawler == nil {
+		return nil, errors.New("the node doesn't crawl the network (not in seed mode)")
+	}
+	stats := env.Crawler.CrawlStats()
+	return &ctypes.ResultCrawlStats{
+		Rounds:        stats.Rounds,
+		LastRound:     stats.LastRound,
+		CrawledPeers:  stats.CrawledPeers,
+		Dials:         stats.Dials,
+		DialFailures:  stats.DialFailures,
+		AddrsReceived: stats.AddrsReceived,
+		AddrsAdded:    stats.AddrsAdded,
+		InboundServed: stats.InboundServed,
+		Disconnects:   stats.Disconnects,
+		AddrBookSize:  stats.AddrBookSize,
+		NPeers:        env.P2PPeers.Peers().Size(),
+	}, nil
+}
+
 // UnsafeDialSeeds dials the given seeds (comma-separated id@IP:PORT).
 func UnsafeDialSeeds(ctx *rpctypes.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
 	if len(seeds) == 0 {
@@ -119,7 +142,7 @@ func GenesisChunked(ctx *rpctypes.Context, chunk uint) (*ctypes.ResultGenesisChu
 	return &ctypes.ResultGenesisChunk{
 		TotalChunks: len(env.genChunks),
 		ChunkNumber: id,
-		Data:        env.genChunks[id],
+		Data:        base64.StdEncoding.EncodeToString(env.genChunks[id]),
 	}, nil
 }
 
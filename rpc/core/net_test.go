@@ -9,6 +9,8 @@ import (
 	cfg "github.com/Finschia/ostracon/config"
 	"github.com/Finschia/ostracon/libs/log"
 	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/p2p/pex"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
 	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
 )
 
@@ -86,17 +88,42 @@ func TestUnsafeDialPeers(t *testing.T) {
 	}
 }
 
+type crawlerMock struct {
+	stats pex.CrawlStats
+}
+
+func (c crawlerMock) CrawlStats() pex.CrawlStats { return c.stats }
+
+func TestCrawlStats(t *testing.T) {
+	sw := p2p.MakeSwitch(cfg.DefaultP2PConfig(), 1, "testing", "123.123.123",
+		func(n int, sw *p2p.Switch, config *cfg.P2PConfig) *p2p.Switch { return sw })
+	env = &Environment{P2PPeers: sw}
+
+	// not in seed mode
+	_, err := CrawlStats(&rpctypes.Context{})
+	assert.Error(t, err)
+
+	env.Crawler = crawlerMock{stats: pex.CrawlStats{Rounds: 3, Dials: 10, DialFailures: 2, AddrBookSize: 42}}
+	res, err := CrawlStats(&rpctypes.Context{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.Rounds)
+	assert.EqualValues(t, 10, res.Dials)
+	assert.EqualValues(t, 2, res.DialFailures)
+	assert.Equal(t, 42, res.AddrBookSize)
+	assert.Zero(t, res.NPeers)
+}
+
 func TestGenesis(t *testing.T) {
 	env = &Environment{}
 
 	// success
-	env.genChunks = []string{}
+	env.genChunks = [][]byte{}
 	res, err := Genesis(&rpctypes.Context{})
 	assert.NoError(t, err)
 	assert.NotNil(t, res)
 
 	// error
-	env.genChunks = []string{"", ""}
+	env.genChunks = [][]byte{{}, {}}
 	res, err = Genesis(&rpctypes.Context{})
 	assert.Error(t, err)
 	assert.Equal(t, "genesis response is large, please use the genesis_chunked API instead", err.Error())
@@ -107,11 +134,11 @@ func TestGenesisChunked(t *testing.T) {
 	env = &Environment{}
 
 	// success
-	env.genChunks = []string{""}
-	chunk := uint(0)
+	env.genChunks = [][]byte{[]byte(`{"chain_id":`), []byte(`"test"}`)}
+	chunk := uint(1)
 	res, err := GenesisChunked(&rpctypes.Context{}, chunk)
 	assert.NoError(t, err)
-	assert.NotNil(t, res)
+	assert.Equal(t, &ctypes.ResultGenesisChunk{ChunkNumber: 1, TotalChunks: 2, Data: "InRlc3QifQ=="}, res)
 
 	//
 	// errors
@@ -124,14 +151,14 @@ func TestGenesisChunked(t *testing.T) {
 	assert.Equal(t, "service configuration error, genesis chunks are not initialized", err.Error())
 	assert.Nil(t, res)
 
-	env.genChunks = []string{}
+	env.genChunks = [][]byte{}
 	chunk = uint(0)
 	res, err = GenesisChunked(&rpctypes.Context{}, chunk)
 	assert.Error(t, err)
 	assert.Equal(t, "service configuration error, there are no chunks", err.Error())
 	assert.Nil(t, res)
 
-	env.genChunks = []string{""}
+	env.genChunks = [][]byte{{}}
 	chunk = uint(1)
 	res, err = GenesisChunked(&rpctypes.Context{}, chunk)
 	assert.Error(t, err)
@@ -9,7 +9,7 @@ import (
 // Routes is a map of available routes.
 var Routes = map[string]*rpc.RPCFunc{
 	// subscribe/unsubscribe are reserved for websocket events.
-	"subscribe":       rpc.NewWSRPCFunc(Subscribe, "query"),
+	"subscribe":       rpc.NewWSRPCFunc(Subscribe, "query,from_height,filter_events"),
 	"unsubscribe":     rpc.NewWSRPCFunc(Unsubscribe, "query"),
 	"unsubscribe_all": rpc.NewWSRPCFunc(UnsubscribeAll, ""),
 
@@ -17,6 +17,7 @@ var Routes = map[string]*rpc.RPCFunc{
 	"health":               rpc.NewRPCFunc(Health, ""),
 	"status":               rpc.NewRPCFunc(Status, ""),
 	"net_info":             rpc.NewRPCFunc(NetInfo, ""),
+	"crawl_stats":          rpc.NewRPCFunc(CrawlStats, ""),
 	"blockchain":           rpc.NewRPCFunc(BlockchainInfo, "minHeight,maxHeight", rpc.Cacheable()),
 	"genesis":              rpc.NewRPCFunc(Genesis, "", rpc.Cacheable()),
 	"genesis_chunked":      rpc.NewRPCFunc(GenesisChunked, "chunk", rpc.Cacheable()),
@@ -24,16 +25,21 @@ var Routes = map[string]*rpc.RPCFunc{
 	"block_by_hash":        rpc.NewRPCFunc(BlockByHash, "hash", rpc.Cacheable()),
 	"block_results":        rpc.NewRPCFunc(BlockResults, "height", rpc.Cacheable("height")),
 	"commit":               rpc.NewRPCFunc(Commit, "height", rpc.Cacheable("height")),
+	"canonical_json":       rpc.NewRPCFunc(CanonicalJSON, "type,height,index,amino", rpc.Cacheable("height")),
 	"check_tx":             rpc.NewRPCFunc(CheckTx, "tx"),
 	"tx":                   rpc.NewRPCFunc(Tx, "hash,prove", rpc.Cacheable()),
 	"tx_search":            rpc.NewRPCFunc(TxSearch, "query,prove,page,per_page,order_by"),
 	"block_search":         rpc.NewRPCFunc(BlockSearch, "query,page,per_page,order_by"),
-	"validators":           rpc.NewRPCFunc(Validators, "height,page,per_page", rpc.Cacheable("height")),
+	"validators":           rpc.NewRPCFunc(Validators, "height,page,per_page,cursor", rpc.Cacheable("height")),
+	"validators_diff":      rpc.NewRPCFunc(ValidatorsDiff, "from,height", rpc.Cacheable("height")),
 	"dump_consensus_state": rpc.NewRPCFunc(DumpConsensusState, ""),
 	"consensus_state":      rpc.NewRPCFunc(ConsensusState, ""),
 	"consensus_params":     rpc.NewRPCFunc(ConsensusParams, "height", rpc.Cacheable("height")),
 	"unconfirmed_txs":      rpc.NewRPCFunc(UnconfirmedTxs, "limit"),
 	"num_unconfirmed_txs":  rpc.NewRPCFunc(NumUnconfirmedTxs, ""),
+	"pending_tx":           rpc.NewRPCFunc(PendingTx, "hash"),
+	"pending_txs":          rpc.NewRPCFunc(PendingTxs, "page,per_page,min_gas_wanted,min_height"),
+	"pruning_status":       rpc.NewRPCFunc(PruningStatus, ""),
 
 	// tx broadcast API
 	"broadcast_tx_commit": rpc.NewRPCFunc(BroadcastTxCommit, "tx"),
@@ -43,9 +49,20 @@ var Routes = map[string]*rpc.RPCFunc{
 	// abci API
 	"abci_query": rpc.NewRPCFunc(ABCIQuery, "path,data,height,prove"),
 	"abci_info":  rpc.NewRPCFunc(ABCIInfo, "", rpc.Cacheable()),
+	"snapshots":  rpc.NewRPCFunc(Snapshots, ""),
 
 	// evidence API
-	"broadcast_evidence": rpc.NewRPCFunc(BroadcastEvidence, "evidence"),
+	"broadcast_evidence":   rpc.NewRPCFunc(BroadcastEvidence, "evidence"),
+	"light_client_attacks": rpc.NewRPCFunc(LightClientAttacks, "minHeight,maxHeight"),
+	"evidence_search":      rpc.NewRPCFunc(EvidenceSearch, "validator,from,to"),
+}
+
+// SeedRoutes is a map of the routes served by the seed nodes, which have no
+// blocks nor application (see cfg.ModeSeed).
+var SeedRoutes = map[string]*rpc.RPCFunc{
+	"health":      Routes["health"],
+	"net_info":    Routes["net_info"],
+	"crawl_stats": Routes["crawl_stats"],
 }
 
 // AddUnsafeRoutes adds unsafe routes.
@@ -54,4 +71,7 @@ func AddUnsafeRoutes() {
 	Routes["dial_seeds"] = rpc.NewRPCFunc(UnsafeDialSeeds, "seeds")
 	Routes["dial_peers"] = rpc.NewRPCFunc(UnsafeDialPeers, "peers,persistent,unconditional,private")
 	Routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(UnsafeFlushMempool, "")
+	Routes["unsafe_reload_config"] = rpc.NewRPCFunc(UnsafeReloadConfig, "")
+	Routes["unsafe_set_log_level"] = rpc.NewRPCFunc(UnsafeSetLogLevel, "level")
+	Routes["unsafe_prune"] = rpc.NewRPCFunc(UnsafePrune, "")
 }
@@ -47,6 +47,23 @@ type ResultCommit struct {
 	CanonicalCommit    bool `json:"canonical"`
 }
 
+// Canonical JSON encoding of a block, header, commit or vote
+type ResultCanonicalJSON struct {
+	Height int64  `json:"height"`
+	Type   string `json:"type"`
+	// JSON is the encoding, as a string so that it is returned byte for byte
+	JSON string `json:"json"`
+}
+
+// Diff of the validator sets between two heights
+type ResultValidatorsDiff struct {
+	FromHeight  int64                  `json:"from_height"`
+	BlockHeight int64                  `json:"block_height"`
+	Diff        types.ValidatorSetDiff `json:"diff"`
+	// Total number of validators at the block height
+	Total int `json:"total"`
+}
+
 // ABCI results from a block
 type ResultBlockResults struct {
 	Height                int64                     `json:"height"`
@@ -142,6 +159,8 @@ type ResultValidators struct {
 	Count int `json:"count"`
 	// Total number of validators
 	Total int `json:"total"`
+	// Cursor of the next validators, empty after the last ones
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // ConsensusParams for given height
@@ -222,6 +241,22 @@ type ResultUnconfirmedTxs struct {
 	Txs        []types.Tx `json:"txs"`
 }
 
+// A mempool tx waiting to be committed
+type ResultPendingTx struct {
+	Hash      bytes.HexBytes `json:"hash"`
+	Tx        types.Tx       `json:"tx"`
+	Height    int64          `json:"height"`
+	Timestamp time.Time      `json:"timestamp"`
+	GasWanted int64          `json:"gas_wanted"`
+	Priority  int64          `json:"priority"`
+}
+
+// Page of the mempool txs
+type ResultPendingTxs struct {
+	Txs        []*ResultPendingTx `json:"txs"`
+	TotalCount int                `json:"total_count"`
+}
+
 // Info abci msg
 type ResultABCIInfo struct {
 	Response abci.ResponseInfo `json:"response"`
@@ -237,6 +272,70 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// Committed light client attacks, including the conflicting light blocks
+type ResultLightClientAttacks struct {
+	Evidence []*types.LightClientAttackEvidence `json:"evidence"`
+}
+
+// Committed evidence against a validator
+type ResultEvidenceSearch struct {
+	Evidence types.EvidenceList `json:"evidence"`
+}
+
+// Snapshots of the app archived by the node
+type ResultSnapshots struct {
+	Snapshots  []*abci.Snapshot  `json:"snapshots"`
+	InProgress *SnapshotProgress `json:"in_progress,omitempty"`
+}
+
+// Progress of the snapshot being archived by the node
+type SnapshotProgress struct {
+	Height         uint64 `json:"height"`
+	Format         uint32 `json:"format"`
+	Chunks         uint32 `json:"chunks"`
+	ChunksArchived uint32 `json:"chunks_archived"`
+}
+
+// Status of the pruning of the old blocks
+type ResultPruningStatus struct {
+	Base            int64     `json:"base"`
+	RetainHeight    int64     `json:"retain_height"`
+	AppRetainHeight int64     `json:"app_retain_height"`
+	RetainBlocks    int64     `json:"retain_blocks"`
+	Pruning         bool      `json:"pruning"`
+	LastPruned      uint64    `json:"last_pruned"`
+	LastPruneTime   time.Time `json:"last_prune_time"`
+	LastPruneError  string    `json:"last_prune_error,omitempty"`
+}
+
+// Statistics of the network crawl of a seed node
+type ResultCrawlStats struct {
+	Rounds        int64     `json:"rounds"`
+	LastRound     time.Time `json:"last_round"`
+	CrawledPeers  int       `json:"crawled_peers"`
+	Dials         int64     `json:"dials"`
+	DialFailures  int64     `json:"dial_failures"`
+	AddrsReceived int64     `json:"addrs_received"`
+	AddrsAdded    int64     `json:"addrs_added"`
+	InboundServed int64     `json:"inbound_served"`
+	Disconnects   int64     `json:"disconnects"`
+	AddrBookSize  int       `json:"addr_book_size"`
+	NPeers        int       `json:"n_peers"`
+}
+
+// Settings changed by reloading the configuration: the applied ones, and the
+// ones which require restarting the node
+type ResultReloadConfig struct {
+	Changed        []string `json:"changed"`
+	RequireRestart []string `json:"require_restart,omitempty"`
+}
+
+// Log level changed while running
+type ResultSetLogLevel struct {
+	Previous string `json:"previous"`
+	LogLevel string `json:"log_level"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
@@ -12,14 +12,27 @@ import (
 // Config is an gRPC server configuration.
 type Config struct {
 	MaxOpenConnections int
+
+	// FullAPI serves the QueryAPI along with the BroadcastAPI.
+	FullAPI bool
 }
 
 // StartGRPCServer starts a new gRPC BroadcastAPIServer using the given
 // net.Listener.
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func StartGRPCServer(ln net.Listener) error {
+	return StartGRPCServerWithConfig(ln, &Config{})
+}
+
+// StartGRPCServerWithConfig starts a new gRPC BroadcastAPIServer using the
+// given net.Listener, and a QueryAPIServer if config.FullAPI is true.
+// NOTE: This function blocks - you may want to call it in a go-routine.
+func StartGRPCServerWithConfig(ln net.Listener, config *Config) error {
 	grpcServer := grpc.NewServer()
 	RegisterBroadcastAPIServer(grpcServer, &broadcastAPI{})
+	if config.FullAPI {
+		RegisterQueryAPIServer(grpcServer, &queryAPI{})
+	}
 	return grpcServer.Serve(ln)
 }
 
@@ -34,6 +47,17 @@ func StartGRPCClient(protoAddr string) BroadcastAPIClient {
 	return NewBroadcastAPIClient(conn)
 }
 
+// StartGRPCQueryClient dials the gRPC server using protoAddr and returns a new
+// QueryAPIClient.
+func StartGRPCQueryClient(protoAddr string) QueryAPIClient {
+	//nolint:staticcheck // SA1019 Existing use of deprecated but supported dial option.
+	conn, err := grpc.Dial(protoAddr, grpc.WithInsecure(), grpc.WithContextDialer(dialerFunc))
+	if err != nil {
+		panic(err)
+	}
+	return NewQueryAPIClient(conn)
+}
+
 func dialerFunc(ctx context.Context, addr string) (net.Conn, error) {
 	return tmnet.Connect(addr)
 }
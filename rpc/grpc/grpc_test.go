@@ -4,12 +4,15 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/Finschia/ostracon/abci/example/kvstore"
 	core_grpc "github.com/Finschia/ostracon/rpc/grpc"
 	rpctest "github.com/Finschia/ostracon/rpc/test"
+	"github.com/Finschia/ostracon/types"
 )
 
 func TestMain(m *testing.M) {
@@ -33,3 +36,60 @@ func TestBroadcastTx(t *testing.T) {
 	require.EqualValues(t, 0, res.CheckTx.Code)
 	require.EqualValues(t, 0, res.DeliverTx.Code)
 }
+
+func TestQueryAPI(t *testing.T) {
+	tx := []byte("query=api")
+	bres, err := rpctest.GetGRPCClient().BroadcastTx(
+		context.Background(),
+		&core_grpc.RequestBroadcastTx{Tx: tx},
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, bres.DeliverTx.Code)
+
+	ctx := context.Background()
+	client := rpctest.GetGRPCQueryClient()
+	status, err := client.Status(ctx, &core_grpc.RequestStatus{})
+	require.NoError(t, err)
+	height := status.SyncInfo.LatestBlockHeight
+	require.Positive(t, height)
+	assert.Equal(t, rpctest.GetConfig().Moniker, status.NodeInfo.Moniker)
+
+	block, err := client.Block(ctx, &core_grpc.RequestBlock{Height: height})
+	require.NoError(t, err)
+	assert.Equal(t, height, block.Block.Header.Height)
+	assert.EqualValues(t, status.SyncInfo.LatestBlockHash, block.BlockId.Hash)
+
+	results, err := client.BlockResults(ctx, &core_grpc.RequestBlockResults{Height: height})
+	require.NoError(t, err)
+	assert.Equal(t, height, results.Height)
+
+	vals, err := client.Validators(ctx, &core_grpc.RequestValidators{Height: height})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, vals.Total)
+	assert.Len(t, vals.Validators, 1)
+
+	txRes, err := client.Tx(ctx, &core_grpc.RequestTx{Hash: types.Tx(tx).Hash(), Prove: true})
+	require.NoError(t, err)
+	assert.Equal(t, tx, txRes.Tx)
+	require.NotNil(t, txRes.Proof)
+
+	search, err := client.TxSearch(ctx, &core_grpc.RequestTxSearch{Query: "app.key = 'query'"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, search.TotalCount)
+	assert.Equal(t, tx, search.Txs[0].Tx)
+
+	// the tx committed before subscribing is replayed
+	subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := client.Subscribe(subCtx, &core_grpc.RequestSubscribe{
+		Query:        "tm.event = 'Tx' AND app.key = 'query'",
+		FromHeight:   txRes.Height,
+		FilterEvents: true,
+	})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, event.TxResult)
+	assert.Equal(t, tx, event.TxResult.Tx)
+	assert.Equal(t, []string{"query"}, event.Events["app.key"].Values)
+}
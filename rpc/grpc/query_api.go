@@ -0,0 +1,222 @@
+package coregrpc
+
+import (
+	"context"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/grpc/peer"
+
+	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
+	core "github.com/Finschia/ostracon/rpc/core"
+	ctypes "github.com/Finschia/ostracon/rpc/core/types"
+	rpctypes "github.com/Finschia/ostracon/rpc/jsonrpc/types"
+	"github.com/Finschia/ostracon/types"
+)
+
+// queryAPI serves the JSON-RPC endpoints of the QueryAPI over gRPC, the zero
+// values of the optional parameters being their defaults.
+type queryAPI struct {
+}
+
+var _ QueryAPIServer = (*queryAPI)(nil)
+
+func (qapi *queryAPI) Status(ctx context.Context, req *RequestStatus) (*ResponseStatus, error) {
+	res, err := core.Status(&rpctypes.Context{})
+	if err != nil {
+		return nil, err
+	}
+	latestBlockTime, err := gogotypes.TimestampProto(res.SyncInfo.LatestBlockTime)
+	if err != nil {
+		return nil, err
+	}
+	earliestBlockTime, err := gogotypes.TimestampProto(res.SyncInfo.EarliestBlockTime)
+	if err != nil {
+		return nil, err
+	}
+	validatorInfo := &ValidatorInfo{
+		Address:     res.ValidatorInfo.Address,
+		VotingPower: res.ValidatorInfo.VotingPower,
+	}
+	if res.ValidatorInfo.PubKey != nil {
+		pubKey, err := cryptoenc.PubKeyToProto(res.ValidatorInfo.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		validatorInfo.PubKey = &pubKey
+	}
+	return &ResponseStatus{
+		NodeInfo: res.NodeInfo.ToProto(),
+		SyncInfo: &SyncInfo{
+			LatestBlockHash:     res.SyncInfo.LatestBlockHash,
+			LatestAppHash:       res.SyncInfo.LatestAppHash,
+			LatestBlockHeight:   res.SyncInfo.LatestBlockHeight,
+			LatestBlockTime:     latestBlockTime,
+			EarliestBlockHash:   res.SyncInfo.EarliestBlockHash,
+			EarliestAppHash:     res.SyncInfo.EarliestAppHash,
+			EarliestBlockHeight: res.SyncInfo.EarliestBlockHeight,
+			EarliestBlockTime:   earliestBlockTime,
+			CatchingUp:          res.SyncInfo.CatchingUp,
+		},
+		ValidatorInfo: validatorInfo,
+	}, nil
+}
+
+func (qapi *queryAPI) Block(ctx context.Context, req *RequestBlock) (*ResponseBlock, error) {
+	res, err := core.Block(&rpctypes.Context{}, optionalInt64(req.Height))
+	if err != nil {
+		return nil, err
+	}
+	blockID := res.BlockID.ToProto()
+	resp := &ResponseBlock{BlockId: &blockID}
+	if res.Block != nil {
+		if resp.Block, err = res.Block.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (qapi *queryAPI) BlockResults(ctx context.Context, req *RequestBlockResults) (*ResponseBlockResults, error) {
+	res, err := core.BlockResults(&rpctypes.Context{}, optionalInt64(req.Height))
+	if err != nil {
+		return nil, err
+	}
+	resp := &ResponseBlockResults{
+		Height:                res.Height,
+		TxsResults:            res.TxsResults,
+		BeginBlockEvents:      eventPointers(res.BeginBlockEvents),
+		EndBlockEvents:        eventPointers(res.EndBlockEvents),
+		ConsensusParamUpdates: res.ConsensusParamUpdates,
+	}
+	for i := range res.ValidatorUpdates {
+		resp.ValidatorUpdates = append(resp.ValidatorUpdates, &res.ValidatorUpdates[i])
+	}
+	return resp, nil
+}
+
+func (qapi *queryAPI) Validators(ctx context.Context, req *RequestValidators) (*ResponseValidators, error) {
+	res, err := core.Validators(&rpctypes.Context{}, optionalInt64(req.Height),
+		optionalInt(req.Page), optionalInt(req.PerPage), req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ResponseValidators{
+		BlockHeight: res.BlockHeight,
+		Count:       int64(res.Count),
+		Total:       int64(res.Total),
+		NextCursor:  res.NextCursor,
+	}
+	for _, val := range res.Validators {
+		pval, err := val.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		resp.Validators = append(resp.Validators, pval)
+	}
+	return resp, nil
+}
+
+func (qapi *queryAPI) Tx(ctx context.Context, req *RequestTx) (*ResponseTx, error) {
+	res, err := core.Tx(&rpctypes.Context{}, req.Hash, req.Prove)
+	if err != nil {
+		return nil, err
+	}
+	return txToProto(res, req.Prove), nil
+}
+
+func (qapi *queryAPI) TxSearch(ctx context.Context, req *RequestTxSearch) (*ResponseTxSearch, error) {
+	res, err := core.TxSearch(&rpctypes.Context{}, req.Query, req.Prove,
+		optionalInt(req.Page), optionalInt(req.PerPage), req.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ResponseTxSearch{TotalCount: int64(res.TotalCount)}
+	for _, tx := range res.Txs {
+		resp.Txs = append(resp.Txs, txToProto(tx, req.Prove))
+	}
+	return resp, nil
+}
+
+// Subscribe streams the events of the query until the client cancels the
+// call, see core.Subscribe.
+func (qapi *queryAPI) Subscribe(req *RequestSubscribe, stream QueryAPI_SubscribeServer) error {
+	// the subscriptions are counted by remote address, like the WebSocket ones
+	subscriber := "grpc"
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		subscriber = p.Addr.String()
+	}
+	return core.SubscribeEvents(stream.Context(), subscriber, req.Query, req.FromHeight, req.FilterEvents,
+		func(event *ctypes.ResultEvent) error {
+			resp, err := eventToProto(event)
+			if err != nil {
+				return err
+			}
+			return stream.Send(resp)
+		})
+}
+
+func txToProto(res *ctypes.ResultTx, prove bool) *ResponseTx {
+	resp := &ResponseTx{
+		Hash:     res.Hash,
+		Height:   res.Height,
+		Index:    res.Index,
+		TxResult: &res.TxResult,
+		Tx:       res.Tx,
+	}
+	if prove {
+		proof := res.Proof.ToProto()
+		resp.Proof = &proof
+	}
+	return resp
+}
+
+func eventToProto(event *ctypes.ResultEvent) (*ResponseEvent, error) {
+	resp := &ResponseEvent{Query: event.Query, Events: make(map[string]*EventValues, len(event.Events))}
+	for key, values := range event.Events {
+		resp.Events[key] = &EventValues{Values: values}
+	}
+	switch data := event.Data.(type) {
+	case types.EventDataTx:
+		resp.TxResult = &data.TxResult
+	case types.EventDataNewBlock:
+		block, err := data.Block.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		resp.Block = block
+		resp.ResultBeginBlock = &data.ResultBeginBlock
+		resp.ResultEndBlock = &data.ResultEndBlock
+	case types.EventDataNewBlockHeader:
+		resp.Header = data.Header.ToProto()
+		resp.NumTxs = data.NumTxs
+		resp.ResultBeginBlock = &data.ResultBeginBlock
+		resp.ResultEndBlock = &data.ResultEndBlock
+	}
+	return resp, nil
+}
+
+func eventPointers(events []abci.Event) []*abci.Event {
+	pointers := make([]*abci.Event, len(events))
+	for i := range events {
+		pointers[i] = &events[i]
+	}
+	return pointers
+}
+
+// optionalInt64 returns nil for 0, the JSON-RPC endpoints defaulting the
+// missing parameters.
+func optionalInt64(value int64) *int64 {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func optionalInt(value int32) *int {
+	if value == 0 {
+		return nil
+	}
+	v := int(value)
+	return &v
+}
@@ -0,0 +1,204 @@
+package coregrpc
+
+import (
+	gogoproto "github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	pc "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+)
+
+// The messages of ostracon/rpc/grpc/query.proto. Unlike the ones of
+// types.pb.go, they are marshaled by reflection on their struct tags, which
+// follow the ones of protoc-gen-gogo: remove them once query.pb.go is
+// generated.
+
+type RequestStatus struct {
+}
+
+func (m *RequestStatus) Reset()         { *m = RequestStatus{} }
+func (m *RequestStatus) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestStatus) ProtoMessage()    {}
+
+type RequestBlock struct {
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (m *RequestBlock) Reset()         { *m = RequestBlock{} }
+func (m *RequestBlock) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestBlock) ProtoMessage()    {}
+
+type RequestBlockResults struct {
+	Height int64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (m *RequestBlockResults) Reset()         { *m = RequestBlockResults{} }
+func (m *RequestBlockResults) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestBlockResults) ProtoMessage()    {}
+
+type RequestValidators struct {
+	Height  int64  `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Page    int32  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32  `protobuf:"varint,3,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	Cursor  string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+}
+
+func (m *RequestValidators) Reset()         { *m = RequestValidators{} }
+func (m *RequestValidators) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestValidators) ProtoMessage()    {}
+
+type RequestTx struct {
+	Hash  []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Prove bool   `protobuf:"varint,2,opt,name=prove,proto3" json:"prove,omitempty"`
+}
+
+func (m *RequestTx) Reset()         { *m = RequestTx{} }
+func (m *RequestTx) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestTx) ProtoMessage()    {}
+
+type RequestTxSearch struct {
+	Query   string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Prove   bool   `protobuf:"varint,2,opt,name=prove,proto3" json:"prove,omitempty"`
+	Page    int32  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PerPage int32  `protobuf:"varint,4,opt,name=per_page,json=perPage,proto3" json:"per_page,omitempty"`
+	OrderBy string `protobuf:"bytes,5,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+}
+
+func (m *RequestTxSearch) Reset()         { *m = RequestTxSearch{} }
+func (m *RequestTxSearch) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestTxSearch) ProtoMessage()    {}
+
+type RequestSubscribe struct {
+	Query        string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	FromHeight   int64  `protobuf:"varint,2,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	FilterEvents bool   `protobuf:"varint,3,opt,name=filter_events,json=filterEvents,proto3" json:"filter_events,omitempty"`
+}
+
+func (m *RequestSubscribe) Reset()         { *m = RequestSubscribe{} }
+func (m *RequestSubscribe) String() string { return gogoproto.CompactTextString(m) }
+func (*RequestSubscribe) ProtoMessage()    {}
+
+type SyncInfo struct {
+	LatestBlockHash     []byte               `protobuf:"bytes,1,opt,name=latest_block_hash,json=latestBlockHash,proto3" json:"latest_block_hash,omitempty"`
+	LatestAppHash       []byte               `protobuf:"bytes,2,opt,name=latest_app_hash,json=latestAppHash,proto3" json:"latest_app_hash,omitempty"`
+	LatestBlockHeight   int64                `protobuf:"varint,3,opt,name=latest_block_height,json=latestBlockHeight,proto3" json:"latest_block_height,omitempty"`
+	LatestBlockTime     *gogotypes.Timestamp `protobuf:"bytes,4,opt,name=latest_block_time,json=latestBlockTime,proto3" json:"latest_block_time,omitempty"`
+	EarliestBlockHash   []byte               `protobuf:"bytes,5,opt,name=earliest_block_hash,json=earliestBlockHash,proto3" json:"earliest_block_hash,omitempty"`
+	EarliestAppHash     []byte               `protobuf:"bytes,6,opt,name=earliest_app_hash,json=earliestAppHash,proto3" json:"earliest_app_hash,omitempty"`
+	EarliestBlockHeight int64                `protobuf:"varint,7,opt,name=earliest_block_height,json=earliestBlockHeight,proto3" json:"earliest_block_height,omitempty"`
+	EarliestBlockTime   *gogotypes.Timestamp `protobuf:"bytes,8,opt,name=earliest_block_time,json=earliestBlockTime,proto3" json:"earliest_block_time,omitempty"`
+	CatchingUp          bool                 `protobuf:"varint,9,opt,name=catching_up,json=catchingUp,proto3" json:"catching_up,omitempty"`
+}
+
+func (m *SyncInfo) Reset()         { *m = SyncInfo{} }
+func (m *SyncInfo) String() string { return gogoproto.CompactTextString(m) }
+func (*SyncInfo) ProtoMessage()    {}
+
+type ValidatorInfo struct {
+	Address     []byte        `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	PubKey      *pc.PublicKey `protobuf:"bytes,2,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	VotingPower int64         `protobuf:"varint,3,opt,name=voting_power,json=votingPower,proto3" json:"voting_power,omitempty"`
+}
+
+func (m *ValidatorInfo) Reset()         { *m = ValidatorInfo{} }
+func (m *ValidatorInfo) String() string { return gogoproto.CompactTextString(m) }
+func (*ValidatorInfo) ProtoMessage()    {}
+
+type ResponseStatus struct {
+	NodeInfo      *tmp2p.DefaultNodeInfo `protobuf:"bytes,1,opt,name=node_info,json=nodeInfo,proto3" json:"node_info,omitempty"`
+	SyncInfo      *SyncInfo              `protobuf:"bytes,2,opt,name=sync_info,json=syncInfo,proto3" json:"sync_info,omitempty"`
+	ValidatorInfo *ValidatorInfo         `protobuf:"bytes,3,opt,name=validator_info,json=validatorInfo,proto3" json:"validator_info,omitempty"`
+}
+
+func (m *ResponseStatus) Reset()         { *m = ResponseStatus{} }
+func (m *ResponseStatus) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseStatus) ProtoMessage()    {}
+
+type ResponseBlock struct {
+	BlockId *tmproto.BlockID `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"` //nolint:revive,stylecheck
+	Block   *ocproto.Block   `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+func (m *ResponseBlock) Reset()         { *m = ResponseBlock{} }
+func (m *ResponseBlock) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseBlock) ProtoMessage()    {}
+
+type ResponseBlockResults struct {
+	Height                int64                     `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	TxsResults            []*abci.ResponseDeliverTx `protobuf:"bytes,2,rep,name=txs_results,json=txsResults,proto3" json:"txs_results,omitempty"`
+	BeginBlockEvents      []*abci.Event             `protobuf:"bytes,3,rep,name=begin_block_events,json=beginBlockEvents,proto3" json:"begin_block_events,omitempty"`
+	EndBlockEvents        []*abci.Event             `protobuf:"bytes,4,rep,name=end_block_events,json=endBlockEvents,proto3" json:"end_block_events,omitempty"`
+	ValidatorUpdates      []*abci.ValidatorUpdate   `protobuf:"bytes,5,rep,name=validator_updates,json=validatorUpdates,proto3" json:"validator_updates,omitempty"`
+	ConsensusParamUpdates *abci.ConsensusParams     `protobuf:"bytes,6,opt,name=consensus_param_updates,json=consensusParamUpdates,proto3" json:"consensus_param_updates,omitempty"`
+}
+
+func (m *ResponseBlockResults) Reset()         { *m = ResponseBlockResults{} }
+func (m *ResponseBlockResults) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseBlockResults) ProtoMessage()    {}
+
+type ResponseValidators struct {
+	BlockHeight int64                `protobuf:"varint,1,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	Validators  []*tmproto.Validator `protobuf:"bytes,2,rep,name=validators,proto3" json:"validators,omitempty"`
+	Count       int64                `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Total       int64                `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	NextCursor  string               `protobuf:"bytes,5,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (m *ResponseValidators) Reset()         { *m = ResponseValidators{} }
+func (m *ResponseValidators) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseValidators) ProtoMessage()    {}
+
+type ResponseTx struct {
+	Hash     []byte                  `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Height   int64                   `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Index    uint32                  `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	TxResult *abci.ResponseDeliverTx `protobuf:"bytes,4,opt,name=tx_result,json=txResult,proto3" json:"tx_result,omitempty"`
+	Tx       []byte                  `protobuf:"bytes,5,opt,name=tx,proto3" json:"tx,omitempty"`
+	Proof    *tmproto.TxProof        `protobuf:"bytes,6,opt,name=proof,proto3" json:"proof,omitempty"`
+}
+
+func (m *ResponseTx) Reset()         { *m = ResponseTx{} }
+func (m *ResponseTx) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseTx) ProtoMessage()    {}
+
+type ResponseTxSearch struct {
+	Txs        []*ResponseTx `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+	TotalCount int64         `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+}
+
+func (m *ResponseTxSearch) Reset()         { *m = ResponseTxSearch{} }
+func (m *ResponseTxSearch) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseTxSearch) ProtoMessage()    {}
+
+type EventValues struct {
+	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *EventValues) Reset()         { *m = EventValues{} }
+func (m *EventValues) String() string { return gogoproto.CompactTextString(m) }
+func (*EventValues) ProtoMessage()    {}
+
+// ResponseEvent is an event of a subscription, the data of the Tx, NewBlock
+// and NewBlockHeader events being set in the fields of the event.
+type ResponseEvent struct {
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// the attributes of the events by composite key, e.g. "transfer.sender"
+	Events map[string]*EventValues `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Tx
+	TxResult *abci.TxResult `protobuf:"bytes,3,opt,name=tx_result,json=txResult,proto3" json:"tx_result,omitempty"`
+	// NewBlock
+	Block *ocproto.Block `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`
+	// NewBlockHeader
+	Header *tmproto.Header `protobuf:"bytes,5,opt,name=header,proto3" json:"header,omitempty"`
+	NumTxs int64           `protobuf:"varint,6,opt,name=num_txs,json=numTxs,proto3" json:"num_txs,omitempty"`
+	// NewBlock and NewBlockHeader
+	ResultBeginBlock *abci.ResponseBeginBlock `protobuf:"bytes,7,opt,name=result_begin_block,json=resultBeginBlock,proto3" json:"result_begin_block,omitempty"`
+	ResultEndBlock   *abci.ResponseEndBlock   `protobuf:"bytes,8,opt,name=result_end_block,json=resultEndBlock,proto3" json:"result_end_block,omitempty"`
+}
+
+func (m *ResponseEvent) Reset()         { *m = ResponseEvent{} }
+func (m *ResponseEvent) String() string { return gogoproto.CompactTextString(m) }
+func (*ResponseEvent) ProtoMessage()    {}
@@ -532,6 +532,15 @@ func (c *WSClient) Subscribe(ctx context.Context, query string) error {
 	return c.Call(ctx, "subscribe", params)
 }
 
+// SubscribeFromHeight subscribes to a query like Subscribe, the server
+// replaying the indexed events of the query from fromHeight (if positive)
+// before sending the new ones. If filterEvents is true, only the events of the
+// types the query refers to are sent.
+func (c *WSClient) SubscribeFromHeight(ctx context.Context, query string, fromHeight int64, filterEvents bool) error {
+	params := map[string]interface{}{"query": query, "from_height": fromHeight, "filter_events": filterEvents}
+	return c.Call(ctx, "subscribe", params)
+}
+
 // Unsubscribe from a query. Note the server must have a "unsubscribe" route
 // defined.
 func (c *WSClient) Unsubscribe(ctx context.Context, query string) error {
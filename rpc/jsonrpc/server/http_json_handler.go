@@ -111,6 +111,11 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 				continue
 			}
 			ctx := &types.Context{JSONReq: &request, HTTPReq: r}
+			if !rpcFunc.allow(ctx) {
+				responses = append(responses, types.RPCInvalidRequestError(request.ID, errTooManyCalls))
+				cache = false
+				continue
+			}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
 			if len(request.Params) > 0 {
 				fnArgs, err := jsonParamsToArgs(rpcFunc, request.Params)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"golang.org/x/net/netutil"
 
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/ratelimit"
 	types "github.com/Finschia/ostracon/rpc/jsonrpc/types"
 )
 
@@ -37,6 +39,19 @@ type Config struct {
 	MaxBatchRequestNum int
 	// mirrors http.Server#MaxHeaderBytes
 	MaxHeaderBytes int
+	// RateLimitRequests is the maximum number of requests of a client (by IP
+	// address) per RateLimitWindow, and RateLimitTotalRequests the one of all
+	// the clients (unlimited when <= 0). See rateLimitHandler.
+	RateLimitRequests      int
+	RateLimitTotalRequests int
+	RateLimitWindow        time.Duration
+	// EnabledMethods are the only methods served if any, and DisabledMethods
+	// the methods not served. MethodRateLimits is the maximum number of calls
+	// of a method by a client (by IP address) per RateLimitWindow, by method.
+	// See FilterFuncs.
+	EnabledMethods   []string
+	DisabledMethods  []string
+	MethodRateLimits map[string]int
 }
 
 // DefaultConfig returns a default configuration.
@@ -49,13 +64,15 @@ func DefaultConfig() *Config {
 		MaxBodyBytes:       int64(1000000), // 1MB
 		MaxBatchRequestNum: 10,
 		MaxHeaderBytes:     1 << 20, // same as the net/http default
+		RateLimitWindow:    time.Second,
 	}
 }
 
 // Serve creates a http.Server and calls Serve with the given listener. It
 // wraps handler with RecoverAndLogHandler and handlers. Handlers contain
-// a maxBytesHandler, which limits the max body size to config.MaxBodyBytes and
-// a maxBatchRequestHandler, which limits the max number of requests in a batch.
+// a maxBytesHandler, which limits the max body size to config.MaxBodyBytes,
+// a maxBatchRequestHandler, which limits the max number of requests in a batch
+// and a rateLimitHandler, which limits the rate of the requests.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func Serve(listener net.Listener, handler http.Handler, logger log.Logger, config *Config) error {
@@ -71,7 +88,7 @@ func Serve(listener net.Listener, handler http.Handler, logger log.Logger, confi
 	}
 
 	s := &http.Server{
-		Handler:           RecoverAndLogHandler(handlers, logger),
+		Handler:           RecoverAndLogHandler(newRateLimitHandler(handlers, config, logger), logger),
 		ReadTimeout:       config.ReadTimeout,
 		ReadHeaderTimeout: config.ReadTimeout,
 		WriteTimeout:      config.WriteTimeout,
@@ -85,8 +102,9 @@ func Serve(listener net.Listener, handler http.Handler, logger log.Logger, confi
 
 // Serve creates a http.Server and calls ServeTLS with the given listener,
 // certFile and keyFile. It wraps handler with RecoverAndLogHandler and handlers.
-// Handlers contain a maxBytesHandler, which limits the max body size to config.MaxBodyBytes and
-// a maxBatchRequestHandler, which limits the max number of requests in a batch.
+// Handlers contain a maxBytesHandler, which limits the max body size to config.MaxBodyBytes,
+// a maxBatchRequestHandler, which limits the max number of requests in a batch and
+// a rateLimitHandler, which limits the rate of the requests.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func ServeTLS(
@@ -109,7 +127,7 @@ func ServeTLS(
 	}
 
 	s := &http.Server{
-		Handler:           RecoverAndLogHandler(handlers, logger),
+		Handler:           RecoverAndLogHandler(newRateLimitHandler(handlers, config, logger), logger),
 		ReadTimeout:       config.ReadTimeout,
 		ReadHeaderTimeout: config.ReadTimeout,
 		WriteTimeout:      config.WriteTimeout,
@@ -298,6 +316,52 @@ func (h maxBatchRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	h.h.ServeHTTP(w, r)
 }
 
+// rateLimitHandler limits the rate of the requests, by client IP address and
+// for all the clients, over a sliding window. The requests over the limits are
+// rejected with the status 429 (Too Many Requests). A batch of requests, or
+// the requests of a WebSocket connection, count as a single request.
+type rateLimitHandler struct {
+	h       http.Handler
+	limiter *ratelimit.Keyed
+	window  time.Duration
+	logger  log.Logger
+}
+
+// newRateLimitHandler returns h if the rate of the requests is unlimited.
+func newRateLimitHandler(h http.Handler, config *Config, logger log.Logger) http.Handler {
+	if config.RateLimitWindow <= 0 || (config.RateLimitRequests <= 0 && config.RateLimitTotalRequests <= 0) {
+		return h
+	}
+	var total ratelimit.Limiter
+	if config.RateLimitTotalRequests > 0 {
+		total = ratelimit.NewSlidingWindow(config.RateLimitTotalRequests, config.RateLimitWindow)
+	}
+	return rateLimitHandler{
+		h: h,
+		limiter: ratelimit.NewKeyed(func() ratelimit.Limiter {
+			return ratelimit.NewSlidingWindow(config.RateLimitRequests, config.RateLimitWindow)
+		}, total, 2*config.RateLimitWindow),
+		window: config.RateLimitWindow,
+		logger: logger,
+	}
+}
+
+func (h rateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !h.limiter.AllowN(ip, time.Now(), 1) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(h.window.Seconds()))))
+		res := types.RPCInvalidRequestError(nil, errors.New("too many requests, try again later"))
+		if wErr := WriteRPCResponseHTTPError(w, http.StatusTooManyRequests, res); wErr != nil {
+			h.logger.Error("failed to write response", "res", res, "err", wErr)
+		}
+		return
+	}
+	h.h.ServeHTTP(w, r)
+}
+
 // Listen starts a new net.Listener on the given address.
 // It returns an error if the address is invalid or the call to Listen() fails.
 func Listen(addr string, config *Config) (listener net.Listener, err error) {
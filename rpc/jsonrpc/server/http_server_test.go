@@ -215,6 +215,46 @@ func TestMaxBatchRequestHandler(t *testing.T) {
 	}
 }
 
+func TestRateLimitHandler(t *testing.T) {
+	config := DefaultConfig()
+	config.RateLimitRequests = 2
+	config.RateLimitTotalRequests = 3
+	config.RateLimitWindow = time.Minute
+
+	handler := newRateLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), config, log.TestingLogger())
+
+	serve := func(remoteAddr string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(TestGoodBody))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	// the limit of a client is by IP address
+	assert.Equal(t, http.StatusOK, serve("127.0.0.1:1000").StatusCode)
+	assert.Equal(t, http.StatusOK, serve("127.0.0.1:1001").StatusCode)
+	res := serve("127.0.0.1:1002")
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "60", res.Header.Get("Retry-After"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "too many requests")
+
+	// the limit of all the clients
+	assert.Equal(t, http.StatusOK, serve("127.0.0.2:1000").StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, serve("127.0.0.3:1000").StatusCode)
+
+	// unlimited
+	config.RateLimitRequests = 0
+	config.RateLimitTotalRequests = 0
+	next := http.NewServeMux()
+	assert.Equal(t, next, newRateLimitHandler(next, config, log.TestingLogger()))
+}
+
 func TestWriteRPCResponseHTTPError(t *testing.T) {
 	w := httptest.NewRecorder()
 	err := WriteRPCResponseHTTPError(
@@ -37,6 +37,13 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 		logger.Debug("HTTP HANDLER", "req", r)
 
 		ctx := &types.Context{HTTPReq: r}
+		if !rpcFunc.allow(ctx) {
+			res := types.RPCInvalidRequestError(dummyID, errTooManyCalls)
+			if wErr := WriteRPCResponseHTTPError(w, http.StatusTooManyRequests, res); wErr != nil {
+				logger.Error("failed to write response", "res", res, "err", wErr)
+			}
+			return
+		}
 		args := []reflect.Value{reflect.ValueOf(ctx)}
 
 		fnArgs, err := httpParamsToArgs(rpcFunc, r)
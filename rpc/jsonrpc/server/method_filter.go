@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/Finschia/ostracon/libs/ratelimit"
+	types "github.com/Finschia/ostracon/rpc/jsonrpc/types"
+)
+
+// errTooManyCalls is returned for the calls of a method over its rate limit.
+var errTooManyCalls = errors.New("too many calls of the method, try again later")
+
+// FilterFuncs returns the functions of funcMap served according to config:
+// only the EnabledMethods if any, without the DisabledMethods, the calls of
+// the MethodRateLimits methods being limited by client IP address over
+// config.RateLimitWindow. The calls of the same method in a batch, or over a
+// WebSocket connection, count as several calls.
+//
+// funcMap is left as it is: the functions limited are copied.
+func FilterFuncs(funcMap map[string]*RPCFunc, config *Config) map[string]*RPCFunc {
+	enabled := make(map[string]bool, len(config.EnabledMethods))
+	for _, method := range config.EnabledMethods {
+		enabled[method] = true
+	}
+	disabled := make(map[string]bool, len(config.DisabledMethods))
+	for _, method := range config.DisabledMethods {
+		disabled[method] = true
+	}
+
+	filtered := make(map[string]*RPCFunc, len(funcMap))
+	for method, rpcFunc := range funcMap {
+		if (len(enabled) > 0 && !enabled[method]) || disabled[method] {
+			continue
+		}
+		if limit := config.MethodRateLimits[method]; limit > 0 && config.RateLimitWindow > 0 {
+			limited := *rpcFunc
+			limited.limiter = ratelimit.NewKeyed(func() ratelimit.Limiter {
+				return ratelimit.NewSlidingWindow(limit, config.RateLimitWindow)
+			}, nil, 2*config.RateLimitWindow)
+			rpcFunc = &limited
+		}
+		filtered[method] = rpcFunc
+	}
+	return filtered
+}
+
+// allow reports whether the client of ctx may call the function now, and
+// records the call if so, see FilterFuncs.
+func (f *RPCFunc) allow(ctx *types.Context) bool {
+	if f.limiter == nil {
+		return true
+	}
+	ip, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		ip = ctx.RemoteAddr()
+	}
+	return f.limiter.AllowN(ip, time.Now(), 1)
+}
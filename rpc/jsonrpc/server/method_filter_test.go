@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/libs/log"
+	types "github.com/Finschia/ostracon/rpc/jsonrpc/types"
+)
+
+func TestFilterFuncs(t *testing.T) {
+	f := func(ctx *types.Context) (string, error) { return "ok", nil }
+	funcMap := map[string]*RPCFunc{
+		"status":              NewRPCFunc(f, ""),
+		"block":               NewRPCFunc(f, ""),
+		"broadcast_tx_commit": NewRPCFunc(f, ""),
+	}
+
+	config := DefaultConfig()
+	config.DisabledMethods = []string{"broadcast_tx_commit"}
+	assert.ElementsMatch(t, []string{"status", "block"}, funcNames(FilterFuncs(funcMap, config)))
+	config.EnabledMethods = []string{"status", "broadcast_tx_commit"}
+	assert.ElementsMatch(t, []string{"status"}, funcNames(FilterFuncs(funcMap, config)))
+
+	// the calls are limited by client IP address, by method
+	config = DefaultConfig()
+	config.RateLimitWindow = time.Minute
+	config.MethodRateLimits = map[string]int{"block": 2}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, FilterFuncs(funcMap, config), log.TestingLogger())
+	assert.Nil(t, funcMap["block"].limiter, "the functions are copied")
+
+	get := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Result().StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get("/block", "127.0.0.1:1000"))
+	assert.Equal(t, http.StatusOK, get("/block", "127.0.0.1:1001"))
+	assert.Equal(t, http.StatusTooManyRequests, get("/block", "127.0.0.1:1002"))
+	assert.Equal(t, http.StatusOK, get("/status", "127.0.0.1:1002"))
+	assert.Equal(t, http.StatusOK, get("/block", "127.0.0.2:1000"))
+
+	// the calls of a batch count as several calls
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+		`[{"jsonrpc":"2.0","method":"block","id":1},{"jsonrpc":"2.0","method":"block","id":2}]`))
+	req.Header.Set("Max-Batch-Request-Num", "10")
+	req.RemoteAddr = "127.0.0.2:1000"
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	responses := w.Body.String()
+	require.Equal(t, 1, strings.Count(responses, "too many calls"), responses)
+}
+
+func funcNames(funcMap map[string]*RPCFunc) []string {
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+	return names
+}
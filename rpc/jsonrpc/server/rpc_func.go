@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/ratelimit"
 )
 
 // RegisterRPCFuncs adds a route for each function in the funcMap, as well as
@@ -57,6 +58,7 @@ type RPCFunc struct {
 	cacheable      bool                   // enable cache control
 	ws             bool                   // enable websocket communication
 	noCacheDefArgs map[string]interface{} // a lookup table of args that, if not supplied or are set to default values, cause us to not cache
+	limiter        *ratelimit.Keyed       // rate limit of the calls by client (see FilterFuncs), nil if unlimited
 }
 
 // NewRPCFunc wraps a function for introspection.
@@ -364,6 +364,12 @@ func (wsc *wsConnection) readRoutine() {
 			}
 
 			ctx := &types.Context{JSONReq: &request, WSConn: wsc}
+			if !rpcFunc.allow(ctx) {
+				if err := wsc.WriteRPCResponse(writeCtx, types.RPCInvalidRequestError(request.ID, errTooManyCalls)); err != nil {
+					wsc.Logger.Error("Error writing RPC response", "err", err)
+				}
+				continue
+			}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
 			if len(request.Params) > 0 {
 				fnArgs, err := jsonParamsToArgs(rpcFunc, request.Params)
@@ -98,6 +98,7 @@ func createConfig() *cfg.Config {
 	c.RPC.ListenAddress = rpc
 	c.RPC.CORSAllowedOrigins = []string{"https://ostracon.com/"}
 	c.RPC.GRPCListenAddress = grpc
+	c.RPC.GRPCFullAPI = true
 	return c
 }
 
@@ -114,6 +115,12 @@ func GetGRPCClient() core_grpc.BroadcastAPIClient {
 	return core_grpc.StartGRPCClient(grpcAddr)
 }
 
+// GetGRPCQueryClient gets the QueryAPI client of the gRPC server.
+func GetGRPCQueryClient() core_grpc.QueryAPIClient {
+	grpcAddr := globalConfig.RPC.GRPCListenAddress
+	return core_grpc.StartGRPCQueryClient(grpcAddr)
+}
+
 // StartOstracon starts a test ostracon server in a go routine and returns when it is initialized
 func StartOstracon(app abci.Application, opts ...func(*Options)) *nm.Node {
 	nodeOpts := defaultOptions
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,13 +9,15 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	ocabci "github.com/Finschia/ostracon/abci/types"
 	"github.com/Finschia/ostracon/crypto"
-	"github.com/Finschia/ostracon/crypto/ed25519"
 	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
 	"github.com/Finschia/ostracon/libs/fail"
 	"github.com/Finschia/ostracon/libs/log"
+	"github.com/Finschia/ostracon/libs/tracing"
 	mempl "github.com/Finschia/ostracon/mempool"
 	"github.com/Finschia/ostracon/proxy"
 	"github.com/Finschia/ostracon/types"
@@ -45,6 +48,9 @@ type BlockExecutor struct {
 	logger log.Logger
 
 	metrics *Metrics
+
+	// prunes the blocks retained by the app in the background, if set
+	pruner *Pruner
 }
 
 type CommitStepTimes struct {
@@ -79,6 +85,15 @@ func BlockExecutorWithMetrics(metrics *Metrics) BlockExecutorOption {
 	}
 }
 
+// BlockExecutorWithPruner hands the retain heights returned by the app in
+// Commit to the pruner, which prunes the blocks in the background, instead of
+// returning them from ApplyBlock.
+func BlockExecutorWithPruner(pruner *Pruner) BlockExecutorOption {
+	return func(blockExec *BlockExecutor) {
+		blockExec.pruner = pruner
+	}
+}
+
 // NewBlockExecutor returns a new BlockExecutor with a NopEventBus.
 // Call SetEventBus to provide one.
 func NewBlockExecutor(
@@ -120,6 +135,10 @@ func (blockExec *BlockExecutor) SetEventBus(eventBus types.BlockEventPublisher)
 // and txs from the mempool. The max bytes must be big enough to fit the commit.
 // Up to 1/10th of the block space is allcoated for maximum sized evidence.
 // The rest is given to txs, up to the max gas.
+//
+// The txs reaped from the mempool are passed to the application with
+// PrepareProposal, which returns the txs of the block, e.g. reordered, or with
+// some replaced or injected. It fails if they don't fit in the block.
 func (blockExec *BlockExecutor) CreateProposalBlock(
 	height int64,
 	state State, commit *types.Commit,
@@ -127,7 +146,8 @@ func (blockExec *BlockExecutor) CreateProposalBlock(
 	round int32,
 	proof crypto.Proof,
 	maxTxs int64,
-) (*types.Block, *types.PartSet) {
+	voteExtensions []*types.VoteExtension,
+) (*types.Block, *types.PartSet, error) {
 
 	maxBytes := state.ConsensusParams.Block.MaxBytes
 	maxGas := state.ConsensusParams.Block.MaxGas
@@ -139,7 +159,71 @@ func (blockExec *BlockExecutor) CreateProposalBlock(
 
 	txs := blockExec.mempool.ReapMaxBytesMaxGasMaxTxs(maxDataBytes, maxGas, maxTxs)
 
-	return state.MakeBlock(height, txs, commit, evidence, proposerAddr, round, proof)
+	res, err := blockExec.proxyApp.PrepareProposalSync(ocabci.RequestPrepareProposal{
+		MaxTxBytes:      maxDataBytes,
+		Txs:             txs.ToSliceOfBytes(),
+		Height:          height,
+		ProposerAddress: proposerAddr,
+		VoteExtensions:  extendedVoteInfos(state.LastValidators, voteExtensions),
+	})
+	if err != nil {
+		// The application is unreachable, so the block can't be executed
+		// either.
+		return nil, nil, fmt.Errorf("failed to prepare the proposal: %w", err)
+	}
+
+	txs = types.ToTxs(res.Txs)
+	if size := types.ComputeProtoSizeForTxs(txs); size > maxDataBytes {
+		return nil, nil, fmt.Errorf("the txs prepared by the application exceed the max data bytes: %d > %d",
+			size, maxDataBytes)
+	}
+
+	block, blockParts := state.MakeBlock(height, txs, commit, evidence, proposerAddr, round, proof)
+	return block, blockParts, nil
+}
+
+// ProcessProposal asks the application whether the proposed block is valid,
+// returning false if it rejects it (or answers UNKNOWN).
+func (blockExec *BlockExecutor) ProcessProposal(block *types.Block) (bool, error) {
+	res, err := blockExec.proxyApp.ProcessProposalSync(ocabci.RequestProcessProposal{
+		Txs:             block.Txs.ToSliceOfBytes(),
+		Hash:            block.Hash(),
+		Height:          block.Height,
+		ProposerAddress: block.ProposerAddress,
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Status == ocabci.ResponseProcessProposal_ACCEPT, nil
+}
+
+// ExtendVote returns the extension of the precommit for the block the
+// application adds to it, nil if none.
+func (blockExec *BlockExecutor) ExtendVote(vote *types.Vote) ([]byte, error) {
+	res, err := blockExec.proxyApp.ExtendVoteSync(ocabci.RequestExtendVote{
+		Hash:   vote.BlockID.Hash,
+		Height: vote.Height,
+		Round:  vote.Round,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.VoteExtension, nil
+}
+
+// VerifyVoteExtension asks the application whether the vote extension of a
+// validator is valid, returning false if it rejects it (or answers UNKNOWN).
+func (blockExec *BlockExecutor) VerifyVoteExtension(ext *types.VoteExtension) (bool, error) {
+	res, err := blockExec.proxyApp.VerifyVoteExtensionSync(ocabci.RequestVerifyVoteExtension{
+		Hash:             ext.BlockHash,
+		ValidatorAddress: ext.ValidatorAddress,
+		Height:           ext.Height,
+		VoteExtension:    ext.Extension,
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Status == ocabci.ResponseVerifyVoteExtension_ACCEPT, nil
 }
 
 // ValidateBlock validates the given block against the given state.
@@ -156,7 +240,8 @@ func (blockExec *BlockExecutor) ValidateBlock(state State, round int32, block *t
 
 // ApplyBlock validates the block against the state, executes it against the app,
 // fires the relevant events, commits the app, and saves the new state and responses.
-// It returns the new state and the block height to retain (pruning older blocks).
+// It returns the new state and the block height to retain (pruning older blocks),
+// 0 if the blocks are pruned by the Pruner (see BlockExecutorWithPruner).
 // It's the only function that needs to be called
 // from outside this package to process and commit an entire block.
 // It takes a blockID to avoid recomputing the parts hash.
@@ -233,6 +318,11 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	// Update evpool with the latest state.
 	blockExec.evpool.Update(state, block.Evidence.Evidence)
 
+	if blockExec.pruner != nil && retainHeight > 0 {
+		blockExec.pruner.SetApplicationRetainHeight(retainHeight)
+		retainHeight = 0
+	}
+
 	fail.Fail() // XXX
 
 	// Update the app hash and save the state.
@@ -346,10 +436,19 @@ func execBlockOnProxyApp(
 ) (*tmstate.ABCIResponses, error) {
 	var validTxs, invalidTxs = 0, 0
 
+	blockCtx, blockSpan := tracing.Tracer().Start(context.Background(), "execute_block",
+		trace.WithAttributes(
+			attribute.Int64("height", block.Height),
+			attribute.Int("num_txs", len(block.Txs)),
+		))
+	defer blockSpan.End()
+
 	txIndex := 0
 	abciResponses := new(tmstate.ABCIResponses)
 	dtxs := make([]*abci.ResponseDeliverTx, len(block.Txs))
 	abciResponses.DeliverTxs = dtxs
+	// the spans of the submitted transactions, see libs/tracing
+	txSpans := make([]trace.Span, len(block.Txs))
 
 	// Execute transactions and get hash.
 	proxyCb := func(req *ocabci.Request, res *ocabci.Response) {
@@ -366,6 +465,8 @@ func execBlockOnProxyApp(
 			}
 
 			abciResponses.DeliverTxs[txIndex] = txRes
+			txSpans[txIndex].SetAttributes(attribute.Int64("code", int64(txRes.Code)))
+			txSpans[txIndex].End()
 			txIndex++
 		}
 	}
@@ -404,7 +505,15 @@ func execBlockOnProxyApp(
 
 	startTime := time.Now()
 	// run txs of block
-	for _, tx := range block.Txs {
+	for i, tx := range block.Txs {
+		// the transaction then waits for the block to be committed
+		tracing.StartTxWait(tx.Key(), "commit", trace.WithAttributes(attribute.Int64("height", block.Height)))
+		txSpans[i] = tracing.StartTxSpan(tx.Key(), "deliver_tx",
+			trace.WithLinks(trace.LinkFromContext(blockCtx)),
+			trace.WithAttributes(
+				attribute.Int64("height", block.Height),
+				attribute.Int("index", i),
+			))
 		proxyAppConn.DeliverTxAsync(abci.RequestDeliverTx{Tx: tx}, nil)
 		if err := proxyAppConn.Error(); err != nil {
 			return nil, err
@@ -429,6 +538,32 @@ func execBlockOnProxyApp(
 	return abciResponses, nil
 }
 
+// extendedVoteInfos returns the vote extensions of the validators of the last
+// block, by order of the validators.
+func extendedVoteInfos(lastValSet *types.ValidatorSet, voteExtensions []*types.VoteExtension) []ocabci.ExtendedVoteInfo {
+	if len(voteExtensions) == 0 || lastValSet == nil {
+		return nil
+	}
+	byAddress := make(map[string]*types.VoteExtension, len(voteExtensions))
+	for _, ext := range voteExtensions {
+		byAddress[string(ext.ValidatorAddress)] = ext
+	}
+	infos := make([]ocabci.ExtendedVoteInfo, 0, len(voteExtensions))
+	for _, val := range lastValSet.Validators {
+		ext, ok := byAddress[string(val.Address)]
+		if !ok {
+			continue
+		}
+		infos = append(infos, ocabci.ExtendedVoteInfo{
+			ValidatorAddress:   val.Address,
+			Power:              val.VotingPower,
+			VoteExtension:      ext.Extension,
+			ExtensionSignature: ext.Signature,
+		})
+	}
+	return infos
+}
+
 func getBeginBlockValidatorInfo(block *types.Block, store Store,
 	initialHeight int64) abci.LastCommitInfo {
 	voteInfos := make([]abci.VoteInfo, block.LastCommit.Size())
@@ -504,19 +639,20 @@ func updateState(
 	validatorUpdates []*types.Validator,
 ) (State, error) {
 
-	// Copy the valset so we can apply changes from EndBlock
-	// and update s.LastValidators and s.Validators.
-	nValSet := state.NextValidators.Copy()
-
-	// Update the validator set with the latest abciResponses.
+	// Update the validator set with the latest abciResponses, on a copy so
+	// that we can update s.LastValidators and s.Validators.
+	nValSet := state.NextValidators
 	lastHeightValsChanged := state.LastHeightValidatorsChanged
 	if len(validatorUpdates) > 0 {
-		err := nValSet.UpdateWithChangeSet(validatorUpdates)
+		var err error
+		nValSet, err = nValSet.ApplyDiff(types.NewValidatorSetDiff(validatorUpdates))
 		if err != nil {
 			return state, fmt.Errorf("error changing validator set: %v", err)
 		}
 		// Change results from this height but only applies to the next next height.
 		lastHeightValsChanged = header.Height + 1 + 1
+	} else {
+		nValSet = nValSet.Copy()
 	}
 
 	// Update validator proposer priority and set state variables.
@@ -542,7 +678,7 @@ func updateState(
 	nextVersion := state.Version
 
 	// get proof hash from vrf proof
-	proofHash, err := ed25519.ProofToHash(entropy.Proof.Bytes())
+	proofHash, err := entropy.ProofHash()
 	if err != nil {
 		return state, fmt.Errorf("error get proof of hash: %v", err)
 	}
@@ -565,6 +701,7 @@ func updateState(
 		LastHeightConsensusParamsChanged: lastHeightParamsChanged,
 		LastResultsHash:                  ABCIResponsesResultsHash(abciResponses),
 		AppHash:                          nil,
+		BlockPartSizeBytes:               state.BlockPartSizeBytes,
 	}, nil
 }
 
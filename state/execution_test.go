@@ -2,6 +2,7 @@ package state_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -13,14 +14,17 @@ import (
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
 
+	ocabci "github.com/Finschia/ostracon/abci/types"
 	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/crypto/ed25519"
 	cryptoenc "github.com/Finschia/ostracon/crypto/encoding"
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	"github.com/Finschia/ostracon/libs/bytes"
 	"github.com/Finschia/ostracon/libs/log"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
 	mmock "github.com/Finschia/ostracon/mempool/mock"
 	"github.com/Finschia/ostracon/proxy"
+	proxymocks "github.com/Finschia/ostracon/proxy/mocks"
 	sm "github.com/Finschia/ostracon/state"
 	"github.com/Finschia/ostracon/state/mocks"
 	"github.com/Finschia/ostracon/types"
@@ -539,3 +543,172 @@ func makeBlockID(hash []byte, partSetSize uint32, partSetHash []byte) types.Bloc
 		},
 	}
 }
+
+// reapMempool reaps all its txs.
+type reapMempool struct {
+	mmock.Mempool
+	txs types.Txs
+}
+
+func (m reapMempool) ReapMaxBytesMaxGasMaxTxs(_, _, _ int64) types.Txs { return m.txs }
+
+func TestCreateProposalBlockPrepareProposal(t *testing.T) {
+	state, stateDB, privVals := makeState(1, 1)
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	proposer := state.Validators.Validators[0]
+	proof, err := privVals[proposer.Address.String()].GenerateVRFProof(state.MakeHashMessage(0))
+	require.NoError(t, err)
+	commit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	mempoolTxs := types.Txs{types.Tx("a=1"), types.Tx("oracle=2"), types.Tx("b=3")}
+	maxDataBytes := types.MaxDataBytes(state.ConsensusParams.Block.MaxBytes, 0, state.Validators.Size())
+
+	testCases := []struct {
+		desc   string
+		res    *ocabci.ResponsePrepareProposal
+		resErr error
+		expTxs types.Txs
+		expErr bool
+	}{
+		{
+			"reordered and injected txs",
+			&ocabci.ResponsePrepareProposal{Txs: [][]byte{[]byte("oracle=2"), []byte("c=4"), []byte("a=1")}},
+			nil,
+			types.Txs{types.Tx("oracle=2"), types.Tx("c=4"), types.Tx("a=1")},
+			false,
+		},
+		{
+			"no txs",
+			&ocabci.ResponsePrepareProposal{},
+			nil,
+			types.Txs{},
+			false,
+		},
+		{
+			"txs exceeding the max data bytes",
+			&ocabci.ResponsePrepareProposal{Txs: [][]byte{make([]byte, maxDataBytes)}},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"application error",
+			nil,
+			errors.New("connection closed"),
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			proxyApp := &proxymocks.AppConnConsensus{}
+			proxyApp.On("PrepareProposalSync", ocabci.RequestPrepareProposal{
+				MaxTxBytes:      maxDataBytes,
+				Txs:             mempoolTxs.ToSliceOfBytes(),
+				Height:          1,
+				ProposerAddress: proposer.Address,
+			}).Return(tc.res, tc.resErr)
+
+			blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), proxyApp,
+				reapMempool{txs: mempoolTxs}, sm.EmptyEvidencePool{})
+			block, _, err := blockExec.CreateProposalBlock(1, state, commit, proposer.Address, 0, proof, 0, nil)
+			proxyApp.AssertExpectations(t)
+			if tc.expErr {
+				assert.Error(t, err)
+				assert.Nil(t, block)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expTxs, block.Txs)
+		})
+	}
+}
+
+func TestProcessProposal(t *testing.T) {
+	state, stateDB, _ := makeState(1, 1)
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	block := makeBlock(state, 1)
+	block.Txs = types.Txs{types.Tx("a=1"), types.Tx("b=2")}
+	req := ocabci.RequestProcessProposal{
+		Txs:             block.Txs.ToSliceOfBytes(),
+		Hash:            block.Hash(),
+		Height:          block.Height,
+		ProposerAddress: block.ProposerAddress,
+	}
+
+	testCases := []struct {
+		desc     string
+		res      *ocabci.ResponseProcessProposal
+		resErr   error
+		accepted bool
+		expErr   bool
+	}{
+		{"accepted", &ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_ACCEPT}, nil, true, false},
+		{"rejected", &ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_REJECT}, nil, false, false},
+		{"unknown", &ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_UNKNOWN}, nil, false, false},
+		{"application error", nil, errors.New("connection closed"), false, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			proxyApp := &proxymocks.AppConnConsensus{}
+			proxyApp.On("ProcessProposalSync", req).Return(tc.res, tc.resErr)
+
+			blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), proxyApp,
+				mmock.Mempool{}, sm.EmptyEvidencePool{})
+			accepted, err := blockExec.ProcessProposal(block)
+			proxyApp.AssertExpectations(t)
+			if tc.expErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.accepted, accepted)
+		})
+	}
+}
+
+func TestCreateProposalBlockVoteExtensions(t *testing.T) {
+	state, stateDB, privVals := makeState(3, 2)
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	proposer := state.Validators.Validators[0]
+	proof, err := privVals[proposer.Address.String()].GenerateVRFProof(state.MakeHashMessage(0))
+	require.NoError(t, err)
+	commit := types.NewCommit(1, 0, types.BlockID{}, nil)
+	state.LastValidators = state.Validators.Copy()
+	vals := state.LastValidators.Validators
+
+	hash := tmhash.Sum([]byte("last block"))
+	voteExtensions := []*types.VoteExtension{
+		{Height: 1, BlockHash: hash, ValidatorAddress: vals[2].Address, Extension: []byte("c"), Signature: []byte("sig c")},
+		{Height: 1, BlockHash: hash, ValidatorAddress: vals[0].Address, Extension: []byte("a"), Signature: []byte("sig a")},
+		// not a validator of the last block
+		{Height: 1, BlockHash: hash, ValidatorAddress: tmrand.Bytes(20), Extension: []byte("x"), Signature: []byte("sig x")},
+	}
+	// by order of the validators
+	expected := []ocabci.ExtendedVoteInfo{
+		{ValidatorAddress: vals[0].Address, Power: vals[0].VotingPower, VoteExtension: []byte("a"),
+			ExtensionSignature: []byte("sig a")},
+		{ValidatorAddress: vals[2].Address, Power: vals[2].VotingPower, VoteExtension: []byte("c"),
+			ExtensionSignature: []byte("sig c")},
+	}
+
+	proxyApp := &proxymocks.AppConnConsensus{}
+	proxyApp.On("PrepareProposalSync", mock.MatchedBy(func(req ocabci.RequestPrepareProposal) bool {
+		return assert.Equal(t, expected, req.VoteExtensions)
+	})).Return(&ocabci.ResponsePrepareProposal{}, nil)
+
+	blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), proxyApp,
+		reapMempool{}, sm.EmptyEvidencePool{})
+	_, _, err = blockExec.CreateProposalBlock(2, state, commit, proposer.Address, 0, proof, 0, voteExtensions)
+	require.NoError(t, err)
+	proxyApp.AssertExpectations(t)
+}
@@ -82,6 +82,11 @@ func (idx *BlockerIndexer) Index(bh types.EventDataNewBlockHeader) error {
 // one or more block heights. In the case of height queries, i.e. block.height=H,
 // if the height is indexed, that height alone will be returned. An error and
 // nil slice is returned. Otherwise, a non-nil slice and nil error is returned.
+//
+// The conditions combined with OR are searched separately and their results
+// united, and the results of the negated conditions (NOT) removed from the
+// others, or from all the blocks if there is no other condition, in which case
+// all the blocks are scanned.
 func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64, error) {
 	results := make([]int64, 0)
 	select {
@@ -91,11 +96,40 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 	default:
 	}
 
-	conditions, err := q.Conditions()
+	filteredHeights, err := indexer.SearchExpr(ctx, q.Expr(), idx.searchConditions, idx.searchAll)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse query conditions: %w", err)
+		return nil, err
 	}
 
+	// fetch matching heights
+	results = make([]int64, 0, len(filteredHeights))
+	for _, hBz := range filteredHeights {
+		h := int64FromBytes(hBz)
+
+		ok, err := idx.Has(h)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, h)
+		}
+
+		select {
+		case <-ctx.Done():
+			break
+
+		default:
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+
+	return results, nil
+}
+
+// searchConditions returns the heights of the blocks matching all the
+// conditions.
+func (idx *BlockerIndexer) searchConditions(ctx context.Context, conditions []query.Condition) (indexer.Matches, error) {
 	// If there is an exact height query, return the result immediately
 	// (if it exists).
 	height, ok := lookForHeight(conditions)
@@ -106,10 +140,11 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		}
 
 		if ok {
-			return []int64{height}, nil
+			heightBz := int64ToBytes(height)
+			return indexer.Matches{string(heightBz): heightBz}, nil
 		}
 
-		return results, nil
+		return indexer.Matches{}, nil
 	}
 
 	var heightsInitialized bool
@@ -184,30 +219,12 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		}
 	}
 
-	// fetch matching heights
-	results = make([]int64, 0, len(filteredHeights))
-	for _, hBz := range filteredHeights {
-		h := int64FromBytes(hBz)
-
-		ok, err := idx.Has(h)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			results = append(results, h)
-		}
-
-		select {
-		case <-ctx.Done():
-			break
-
-		default:
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+	return filteredHeights, nil
+}
 
-	return results, nil
+// searchAll returns the heights of all the indexed blocks.
+func (idx *BlockerIndexer) searchAll(ctx context.Context) (indexer.Matches, error) {
+	return idx.match(ctx, query.Condition{CompositeKey: types.BlockHeightKey, Op: query.OpExists}, nil, nil, true)
 }
 
 // matchRange returns all matching block heights that match a given QueryRange
@@ -468,7 +485,7 @@ func (idx *BlockerIndexer) indexEvents(batch dbm.Batch, events []abci.Event, typ
 			}
 
 			// index iff the event specified index:true and it's not a reserved event
-			compositeKey := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			compositeKey := types.CompositeEventKey(event.Type, string(attr.Key))
 			if compositeKey == types.BlockHeightKey {
 				return fmt.Errorf("event type and attribute key \"%s\" is reserved; please use a different key", compositeKey)
 			}
@@ -128,6 +128,30 @@ func TestBlockIndexer(t *testing.T) {
 			q:       query.MustParse("begin_event.proposer CONTAINS 'FCAA001'"),
 			results: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
 		},
+		"end_event.foo = 2 OR end_event.foo >= 10": {
+			q:       query.MustParse("end_event.foo = 2 OR end_event.foo >= 10"),
+			results: []int64{1, 2, 10},
+		},
+		"end_event.foo IN (4, 6, 7)": {
+			q:       query.MustParse("end_event.foo IN (4, 6, 7)"),
+			results: []int64{4, 6},
+		},
+		"NOT end_event.foo EXISTS": {
+			q:       query.MustParse("NOT end_event.foo EXISTS"),
+			results: []int64{3, 5, 7, 9, 11},
+		},
+		"block.height <= 5 AND NOT end_event.foo = 4": {
+			q:       query.MustParse("block.height <= 5 AND NOT end_event.foo = 4"),
+			results: []int64{1, 2, 3, 5},
+		},
+		"block.height > 6 AND (end_event.foo = 8 OR NOT end_event.foo EXISTS)": {
+			q:       query.MustParse("block.height > 6 AND (end_event.foo = 8 OR NOT end_event.foo EXISTS)"),
+			results: []int64{7, 8, 9, 11},
+		},
+		"block.height = 5 OR block.height = 100": {
+			q:       query.MustParse("block.height = 5 OR block.height = 100"),
+			results: []int64{5},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Finschia/ostracon/libs/pubsub/query"
+)
+
+// Matches are the items (e.g. txs or blocks) matching a query, by key.
+type Matches map[string][]byte
+
+// SearchConditions returns the items matching all the conditions.
+type SearchConditions func(ctx context.Context, conditions []query.Condition) (Matches, error)
+
+// SearchAll returns all the indexed items.
+type SearchAll func(ctx context.Context) (Matches, error)
+
+// SearchExpr returns the items matching the expression of a query. The
+// conjunctions of conditions are searched by searchConditions, and the matches
+// of the other expressions are combined by set operations: the matches of the
+// disjunctions are united, the matches of the negated expressions are removed
+// from the matches of the conjunctions they are part of, or from all the
+// items (see searchAll) otherwise.
+func SearchExpr(
+	ctx context.Context,
+	e *query.Expr,
+	searchConditions SearchConditions,
+	searchAll SearchAll,
+) (Matches, error) {
+	if conditions, ok := e.Conditions(); ok {
+		return searchConditions(ctx, conditions)
+	}
+
+	switch e.Kind {
+	case query.ExprOr:
+		matches := make(Matches)
+		for _, operand := range e.Operands {
+			m, err := SearchExpr(ctx, operand, searchConditions, searchAll)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range m {
+				matches[k] = v
+			}
+		}
+		return matches, nil
+
+	case query.ExprAnd:
+		var (
+			conditions []query.Condition
+			others     []*query.Expr
+			negated    []*query.Expr
+		)
+		for _, operand := range e.Operands {
+			switch operand.Kind {
+			case query.ExprCondition:
+				conditions = append(conditions, operand.Condition)
+			case query.ExprNot:
+				negated = append(negated, operand.Operands[0])
+			default:
+				others = append(others, operand)
+			}
+		}
+
+		// the conditions are searched together, e.g. for the ranges
+		var (
+			matches Matches
+			err     error
+		)
+		switch {
+		case len(conditions) > 0:
+			matches, err = searchConditions(ctx, conditions)
+		case len(others) > 0:
+			matches, err = SearchExpr(ctx, others[0], searchConditions, searchAll)
+			others = others[1:]
+		default:
+			matches, err = searchAll(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, operand := range others {
+			if len(matches) == 0 {
+				return matches, nil
+			}
+			m, err := SearchExpr(ctx, operand, searchConditions, searchAll)
+			if err != nil {
+				return nil, err
+			}
+			for k := range matches {
+				if _, ok := m[k]; !ok {
+					delete(matches, k)
+				}
+			}
+		}
+		return removeMatches(ctx, matches, negated, searchConditions, searchAll)
+
+	case query.ExprNot:
+		matches, err := searchAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return removeMatches(ctx, matches, e.Operands, searchConditions, searchAll)
+
+	default:
+		return nil, fmt.Errorf("unknown kind of expression %d", e.Kind)
+	}
+}
+
+// removeMatches removes the matches of the expressions from matches.
+func removeMatches(
+	ctx context.Context,
+	matches Matches,
+	exprs []*query.Expr,
+	searchConditions SearchConditions,
+	searchAll SearchAll,
+) (Matches, error) {
+	for _, e := range exprs {
+		if len(matches) == 0 {
+			break
+		}
+		m, err := SearchExpr(ctx, e, searchConditions, searchAll)
+		if err != nil {
+			return nil, err
+		}
+		for k := range m {
+			delete(matches, k)
+		}
+	}
+	return matches, nil
+}
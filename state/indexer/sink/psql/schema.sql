@@ -41,6 +41,9 @@ CREATE TABLE tx_results (
   UNIQUE (block_id, index)
 );
 
+-- Index transaction results by hash, for the lookups of the transactions.
+CREATE INDEX idx_tx_results_tx_hash ON tx_results(tx_hash);
+
 -- The events table records events. All events (both block and transaction) are
 -- associated with a block ID; transaction events also have a transaction ID.
 CREATE TABLE events (
@@ -55,6 +58,10 @@ CREATE TABLE events (
   type VARCHAR NOT NULL
 );
 
+-- Index events by block and transaction, to join them with their attributes.
+CREATE INDEX idx_events_block_id ON events(block_id);
+CREATE INDEX idx_events_tx_id ON events(tx_id);
+
 -- The attributes table records event attributes.
 CREATE TABLE attributes (
    event_id      BIGINT NOT NULL REFERENCES events(rowid),
@@ -65,6 +72,11 @@ CREATE TABLE attributes (
    UNIQUE (event_id, key)
 );
 
+-- Index attributes by composite key and value, so that the queries selecting
+-- a range of values of an attribute (e.g. transfer.amount > 100), or combining
+-- several attributes, don't scan all the attributes.
+CREATE INDEX idx_attributes_composite_key_value ON attributes(composite_key, value);
+
 -- A joined view of events and their attributes. Events that do not have any
 -- attributes are represented as a single row with empty key and value fields.
 CREATE VIEW event_attributes AS
@@ -3,8 +3,8 @@ package state
 import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
-	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/Finschia/ostracon/libs/metrics"
 )
 
 const (
@@ -25,52 +25,75 @@ type Metrics struct {
 	BlockAppCommitTime metrics.Gauge
 	// Time of update mempool
 	BlockUpdateMempoolTime metrics.Gauge
+	// Number of blocks pruned
+	PrunedBlocks metrics.Counter
+	// Time of the last pruning of the blocks
+	BlockPruningTime metrics.Gauge
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return NewMetrics(tmmetrics.PrometheusProvider(), namespace, labelsAndValues...)
+}
+
+// NewMetrics returns Metrics build using the provider, e.g. exporting them to
+// StatsD or OTLP. Optionally, labels can be provided along with their values
+// ("foo", "fooValue").
+func NewMetrics(provider tmmetrics.Provider, namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
-	compositeBuckets := stdprometheus.LinearBuckets(20, 20, 5)
-	compositeBuckets = append(compositeBuckets, stdprometheus.LinearBuckets(200, 100, 4)...)
-	compositeBuckets = append(compositeBuckets, stdprometheus.LinearBuckets(1000, 500, 4)...)
+	compositeBuckets := tmmetrics.LinearBuckets(20, 20, 5)
+	compositeBuckets = append(compositeBuckets, tmmetrics.LinearBuckets(200, 100, 4)...)
+	compositeBuckets = append(compositeBuckets, tmmetrics.LinearBuckets(1000, 500, 4)...)
 
 	return &Metrics{
-		BlockProcessingTime: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		BlockProcessingTime: provider.NewHistogram(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_processing_time",
 			Help:      "Time between BeginBlock and EndBlock in ms.",
 			Buckets:   compositeBuckets,
 		}, labels).With(labelsAndValues...),
-		BlockExecutionTime: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockExecutionTime: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_execution_time",
 			Help:      "Time between BeginBlock and EndBlock in ms.",
 		}, labels).With(labelsAndValues...),
-		BlockCommitTime: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockCommitTime: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_commit_time",
 			Help:      "Time of commit in ms.",
 		}, labels).With(labelsAndValues...),
-		BlockAppCommitTime: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockAppCommitTime: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_app_commit_time",
 			Help:      "Time of app commit in ms.",
 		}, labels).With(labelsAndValues...),
-		BlockUpdateMempoolTime: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		BlockUpdateMempoolTime: provider.NewGauge(tmmetrics.Opts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_update_mempool_time",
 			Help:      "Time of update mempool in ms.",
 		}, labels).With(labelsAndValues...),
+		PrunedBlocks: provider.NewCounter(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruned_blocks",
+			Help:      "Number of blocks pruned.",
+		}, labels).With(labelsAndValues...),
+		BlockPruningTime: provider.NewGauge(tmmetrics.Opts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_pruning_time",
+			Help:      "Time of the last pruning of the blocks in ms.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -82,5 +105,7 @@ func NopMetrics() *Metrics {
 		BlockCommitTime:        discard.NewGauge(),
 		BlockAppCommitTime:     discard.NewGauge(),
 		BlockUpdateMempoolTime: discard.NewGauge(),
+		PrunedBlocks:           discard.NewCounter(),
+		BlockPruningTime:       discard.NewGauge(),
 	}
 }
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/types"
+	"github.com/Finschia/ostracon/version"
+)
+
+// MigrateFromTendermint converts the Tendermint state in the state store to an
+// Ostracon state, so that the chain can continue with Ostracon from the latest
+// height without a genesis restart. Ostracon selects the proposers with the VRF
+// output of the previous block (the proof hash), which the blocks committed by
+// Tendermint don't have: the proof hash of the next height is derived from the
+// hash of the last block instead (the genesis hash, if there is no block yet),
+// which is the same on every node.
+// Note that this function does not affect application state.
+func MigrateFromTendermint(bs BlockStore, ss Store, genDoc *types.GenesisDoc) (State, error) {
+	st, err := ss.Load()
+	if err != nil {
+		return State{}, err
+	}
+	if st.IsEmpty() {
+		return State{}, errors.New("no state found")
+	}
+	if len(st.LastProofHash) != 0 {
+		return State{}, errors.New("the state has already been migrated")
+	}
+	if st.ChainID != genDoc.ChainID {
+		return State{}, fmt.Errorf("the chain ID of the state (%s) doesn't match the genesis (%s)",
+			st.ChainID, genDoc.ChainID)
+	}
+
+	// the state must be the one of the latest block, as the proof hash is derived
+	// from it
+	if height := bs.Height(); height != st.LastBlockHeight {
+		return State{}, fmt.Errorf("statestore height (%d) is not equal to blockstore height (%d)",
+			st.LastBlockHeight, height)
+	}
+
+	// the proposers must be able to generate the VRF proofs of the blocks
+	for _, vals := range []*types.ValidatorSet{st.Validators, st.NextValidators} {
+		for _, val := range vals.Validators {
+			if _, ok := val.PubKey.(ed25519.PubKey); !ok {
+				return State{}, fmt.Errorf("validator %X has a %s key, which doesn't support VRF",
+					val.Address, val.PubKey.Type())
+			}
+		}
+	}
+
+	if st.LastBlockHeight == 0 {
+		st.LastProofHash = genDoc.Hash()
+	} else {
+		if st.LastBlockID.IsZero() {
+			return State{}, fmt.Errorf("no last block ID in the state at height %d", st.LastBlockHeight)
+		}
+		st.LastProofHash = st.LastBlockID.Hash
+	}
+	st.Version.Software = version.OCCoreSemVer
+
+	if err := ss.Save(st); err != nil {
+		return State{}, fmt.Errorf("failed to save migrated state: %w", err)
+	}
+	return st, nil
+}
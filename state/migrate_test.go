@@ -0,0 +1,101 @@
+package state_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/Finschia/ostracon/crypto/secp256k1"
+	"github.com/Finschia/ostracon/state"
+	"github.com/Finschia/ostracon/state/mocks"
+	"github.com/Finschia/ostracon/types"
+	"github.com/Finschia/ostracon/version"
+)
+
+func TestMigrateFromTendermint(t *testing.T) {
+	const height = int64(100)
+	vals, _ := types.RandValidatorSet(5, 10)
+	stateStore := setupTendermintStateStore(t, height, vals)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Height").Return(height)
+	genDoc := &types.GenesisDoc{ChainID: "test-chain"}
+
+	st, err := state.MigrateFromTendermint(blockStore, stateStore, genDoc)
+	require.NoError(t, err)
+	require.EqualValues(t, st.LastBlockID.Hash, st.LastProofHash)
+	require.Equal(t, version.OCCoreSemVer, st.Version.Software)
+
+	loadedState, err := stateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, st, loadedState)
+	proofHash, err := stateStore.LoadProofHash(height + 1)
+	require.NoError(t, err)
+	require.EqualValues(t, st.LastBlockID.Hash, proofHash)
+
+	_, err = state.MigrateFromTendermint(blockStore, stateStore, genDoc)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already been migrated")
+}
+
+func TestMigrateFromTendermintInvalidState(t *testing.T) {
+	const height = int64(100)
+	vals, _ := types.RandValidatorSet(5, 10)
+	genDoc := &types.GenesisDoc{ChainID: "test-chain"}
+
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Height").Return(height + 1)
+	_, err := state.MigrateFromTendermint(blockStore, setupTendermintStateStore(t, height, vals), genDoc)
+	require.Error(t, err)
+	require.Equal(t, "statestore height (100) is not equal to blockstore height (101)", err.Error())
+
+	blockStore = &mocks.BlockStore{}
+	blockStore.On("Height").Return(height)
+	_, err = state.MigrateFromTendermint(blockStore, setupTendermintStateStore(t, height, vals),
+		&types.GenesisDoc{ChainID: "other-chain"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't match the genesis")
+
+	// the validators must have VRF keys
+	vals = types.NewValidatorSet([]*types.Validator{
+		types.NewValidator(secp256k1.GenPrivKey().PubKey(), 10),
+	})
+	_, err = state.MigrateFromTendermint(blockStore, setupTendermintStateStore(t, height, vals), genDoc)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't support VRF")
+}
+
+// setupTendermintStateStore returns a state store with the state of a node
+// which has committed the given height with Tendermint, without proof hash.
+func setupTendermintStateStore(t *testing.T, height int64, vals *types.ValidatorSet) state.Store {
+	db := dbm.NewMemDB()
+	stateStore := state.NewStore(db, state.StoreOptions{DiscardABCIResponses: false})
+
+	st := state.State{
+		Version: tmstate.Version{
+			Consensus: tmversion.Consensus{Block: version.BlockProtocol},
+			Software:  "0.34.24",
+		},
+		ChainID:                          "test-chain",
+		InitialHeight:                    1,
+		LastBlockID:                      makeBlockIDRandom(),
+		LastBlockHeight:                  height,
+		LastValidators:                   vals,
+		Validators:                       vals,
+		NextValidators:                   vals,
+		LastHeightValidatorsChanged:      height + 1,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: height + 1,
+		LastProofHash:                    proofHash,
+	}
+	require.NoError(t, stateStore.Bootstrap(st))
+
+	// Tendermint doesn't have proof hashes
+	st.LastProofHash = nil
+	require.NoError(t, db.Delete([]byte(fmt.Sprintf("proofHashKey:%d", height+1))))
+	require.NoError(t, db.Set([]byte("stateKey"), st.Bytes()))
+	return stateStore
+}
@@ -0,0 +1,275 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+)
+
+const defaultPruningInterval = 10 * time.Second
+
+// PruningStatus is the status of the pruning of a Pruner.
+type PruningStatus struct {
+	// the lowest height of the blocks stored
+	Base int64
+	// the height the blocks are retained from, 0 if none are to be pruned
+	RetainHeight int64
+	// the retain height returned by the app in the last Commit
+	AppRetainHeight int64
+	// the number of recent blocks retained, 0 if not configured
+	RetainBlocks int64
+	// whether the blocks are being pruned
+	Pruning bool
+	// the number of blocks pruned by the last pruning, its time and error
+	LastPruned     uint64
+	LastPruneTime  time.Time
+	LastPruneError string
+}
+
+// Pruner prunes in the background the blocks of the block store, and the
+// states and ABCI responses of the state store, below the retain height: the
+// lowest of the retain height returned by the app in Commit (see
+// BlockExecutorWithPruner) and the one of the number of recent blocks to retain.
+// The retain height is lowered as needed to retain the blocks of the evidence
+// which hasn't expired (per the MaxAgeNumBlocks and MaxAgeDuration of the evidence params),
+// so that it can still be verified.
+//
+// The blocks are pruned every interval, or once triggered with Trigger, so
+// that the app committing a retain height doesn't delay the next block.
+type Pruner struct {
+	service.BaseService
+
+	stateStore Store
+	blockStore BlockStore
+	metrics    *Metrics
+
+	trigger chan struct{}
+
+	mtx          tmsync.RWMutex
+	retainBlocks int64
+	interval     time.Duration
+	status       PruningStatus
+}
+
+// PrunerOption sets an optional parameter on the Pruner.
+type PrunerOption func(*Pruner)
+
+// PrunerWithInterval sets the interval the blocks are pruned at. Default: 10s.
+func PrunerWithInterval(interval time.Duration) PrunerOption {
+	return func(p *Pruner) { p.interval = interval }
+}
+
+// PrunerWithMetrics sets the metrics.
+func PrunerWithMetrics(metrics *Metrics) PrunerOption {
+	return func(p *Pruner) { p.metrics = metrics }
+}
+
+// NewPruner returns a Pruner of the stores, retaining the retainBlocks most
+// recent blocks (or as many as the app retains if 0).
+func NewPruner(stateStore Store, blockStore BlockStore, retainBlocks int64, options ...PrunerOption) *Pruner {
+	p := &Pruner{
+		stateStore:   stateStore,
+		blockStore:   blockStore,
+		retainBlocks: retainBlocks,
+		interval:     defaultPruningInterval,
+		metrics:      NopMetrics(),
+		trigger:      make(chan struct{}, 1),
+	}
+	p.status.RetainBlocks = retainBlocks
+	p.BaseService = *service.NewBaseService(nil, "Pruner", p)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// OnStart implements service.Service by starting the pruning routine.
+func (p *Pruner) OnStart() error {
+	go p.pruneRoutine()
+	return nil
+}
+
+// SetApplicationRetainHeight sets the retain height returned by the app in
+// Commit, which is never lowered.
+func (p *Pruner) SetApplicationRetainHeight(height int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if height > p.status.AppRetainHeight {
+		p.status.AppRetainHeight = height
+	}
+}
+
+// SetRetainBlocks changes the number of recent blocks retained (as many as the
+// app retains if 0), e.g. when the configuration is reloaded.
+func (p *Pruner) SetRetainBlocks(retainBlocks int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.retainBlocks = retainBlocks
+	p.status.RetainBlocks = retainBlocks
+}
+
+// SetInterval changes the interval the blocks are pruned at, from the next
+// pruning.
+func (p *Pruner) SetInterval(interval time.Duration) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.interval = interval
+}
+
+// Trigger prunes the blocks now, without waiting for the interval, unless
+// they are being pruned.
+func (p *Pruner) Trigger() {
+	select {
+	case p.trigger <- struct{}{}:
+	default: // already triggered
+	}
+}
+
+// Status returns the status of the pruning.
+func (p *Pruner) Status() PruningStatus {
+	p.mtx.RLock()
+	status := p.status
+	p.mtx.RUnlock()
+
+	status.Base = p.blockStore.Base()
+	if retainHeight, err := p.retainHeight(status.Base); err == nil {
+		status.RetainHeight = retainHeight
+	}
+	return status
+}
+
+func (p *Pruner) pruneRoutine() {
+	interval := p.getInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.trigger:
+		case <-p.Quit():
+			return
+		}
+		p.prune()
+		if newInterval := p.getInterval(); newInterval != interval {
+			interval = newInterval
+			ticker.Reset(interval)
+		}
+	}
+}
+
+func (p *Pruner) getInterval() time.Duration {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.interval
+}
+
+func (p *Pruner) prune() {
+	base := p.blockStore.Base()
+	retainHeight, err := p.retainHeight(base)
+	if err != nil {
+		p.Logger.Error("Failed to get the retain height", "err", err)
+		return
+	}
+	if height := p.blockStore.Height(); retainHeight > height {
+		retainHeight = height
+	}
+	if retainHeight <= base {
+		return
+	}
+
+	p.mtx.Lock()
+	p.status.Pruning = true
+	p.mtx.Unlock()
+
+	start := time.Now()
+	pruned, err := p.pruneBlocks(base, retainHeight)
+	p.metrics.BlockPruningTime.Set(float64(time.Since(start).Milliseconds()))
+	p.metrics.PrunedBlocks.Add(float64(pruned))
+	if err != nil {
+		p.Logger.Error("Failed to prune blocks", "retain_height", retainHeight, "err", err)
+	} else {
+		p.Logger.Debug("Pruned blocks", "pruned", pruned, "retain_height", retainHeight)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.status.Pruning = false
+	p.status.LastPruned = pruned
+	p.status.LastPruneTime = start
+	p.status.LastPruneError = ""
+	if err != nil {
+		p.status.LastPruneError = err.Error()
+	}
+}
+
+func (p *Pruner) pruneBlocks(base, retainHeight int64) (uint64, error) {
+	pruned, err := p.blockStore.PruneBlocks(retainHeight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune block store: %w", err)
+	}
+	if err := p.stateStore.PruneStates(base, retainHeight); err != nil {
+		return pruned, fmt.Errorf("failed to prune state database: %w", err)
+	}
+	return pruned, nil
+}
+
+// retainHeight returns the height the blocks are retained from, 0 if none
+// are to be pruned, base being the lowest height of the blocks stored.
+func (p *Pruner) retainHeight(base int64) (int64, error) {
+	p.mtx.RLock()
+	retainHeight, retainBlocks := p.status.AppRetainHeight, p.retainBlocks
+	p.mtx.RUnlock()
+	if retainHeight == 0 && retainBlocks == 0 {
+		return 0, nil
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		return 0, err
+	}
+	if state.IsEmpty() {
+		return 0, nil
+	}
+	if retainBlocks > 0 {
+		height := state.LastBlockHeight - retainBlocks + 1
+		if height <= 0 {
+			return 0, nil
+		}
+		if retainHeight == 0 || height < retainHeight {
+			retainHeight = height
+		}
+	}
+	return p.evidenceRetainHeight(state, base, retainHeight), nil
+}
+
+// evidenceRetainHeight lowers the retain height so that the blocks of the
+// evidence which hasn't expired are retained, the evidence expiring once it's
+// older than both MaxAgeNumBlocks and MaxAgeDuration (see evidence.Pool).
+func (p *Pruner) evidenceRetainHeight(state State, base, retainHeight int64) int64 {
+	params := state.ConsensusParams.Evidence
+	if height := state.LastBlockHeight - params.MaxAgeNumBlocks; retainHeight > height {
+		retainHeight = height
+	}
+	if retainHeight <= base {
+		if retainHeight < 0 {
+			return 0
+		}
+		return retainHeight
+	}
+
+	// the time of the blocks increases with their height, so the blocks below
+	// the retain height have expired if the highest of them has
+	expired := func(height int64) bool {
+		meta := p.blockStore.LoadBlockMeta(height)
+		return meta == nil || state.LastBlockTime.Sub(meta.Header.Time) > params.MaxAgeDuration
+	}
+	if expired(retainHeight - 1) {
+		return retainHeight
+	}
+	return base + int64(sort.Search(int(retainHeight-1-base), func(i int) bool {
+		return !expired(base + int64(i))
+	}))
+}
@@ -0,0 +1,125 @@
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/libs/log"
+	sm "github.com/Finschia/ostracon/state"
+	statemocks "github.com/Finschia/ostracon/state/mocks"
+	"github.com/Finschia/ostracon/types"
+)
+
+// the blocks of the pruner tests are a minute apart
+var prunerGenesisTime = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newPrunerState(height, evidenceMaxAge int64, evidenceMaxAgeDuration time.Duration) sm.State {
+	val, _ := types.RandValidator(true, 10)
+	params := types.DefaultConsensusParams()
+	params.Evidence.MaxAgeNumBlocks = evidenceMaxAge
+	params.Evidence.MaxAgeDuration = evidenceMaxAgeDuration
+	return sm.State{
+		LastBlockHeight: height,
+		LastBlockTime:   prunerGenesisTime.Add(time.Duration(height) * time.Minute),
+		Validators:      types.NewValidatorSet([]*types.Validator{val}),
+		ConsensusParams: *params,
+	}
+}
+
+func newPrunerBlockStore() *statemocks.BlockStore {
+	blockStore := &statemocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.Anything).Return(func(height int64) *types.BlockMeta {
+		return &types.BlockMeta{Header: types.Header{
+			Height: height,
+			Time:   prunerGenesisTime.Add(time.Duration(height) * time.Minute),
+		}}
+	}).Maybe()
+	return blockStore
+}
+
+func TestPrunerRetainHeight(t *testing.T) {
+	testcases := map[string]struct {
+		retainBlocks           int64
+		appRetainHeight        int64
+		evidenceMaxAgeDuration time.Duration
+		expected               int64
+	}{
+		"retain all":                                {0, 0, 10 * time.Minute, 0},
+		"retain blocks":                             {20, 0, 10 * time.Minute, 81},
+		"retain app height":                         {0, 70, 10 * time.Minute, 70},
+		"retain the lowest of the two":              {20, 90, 10 * time.Minute, 81},
+		"retain blocks of evidence":                 {5, 0, 10 * time.Minute, 90},
+		"retain app height of evidence":             {0, 95, 10 * time.Minute, 90},
+		"retain blocks of evidence by time":         {5, 0, 30 * time.Minute, 70},
+		"retain app height of evidence by time":     {0, 95, 30 * time.Minute, 70},
+		"retain blocks of evidence by time at base": {5, 0, 200 * time.Minute, 1},
+		"retain more than the chain":                {200, 90, 10 * time.Minute, 0},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			stateStore := &statemocks.Store{}
+			stateStore.On("Load").Return(newPrunerState(100, 10, tc.evidenceMaxAgeDuration), nil)
+			blockStore := newPrunerBlockStore()
+			blockStore.On("Base").Return(int64(1))
+
+			pruner := sm.NewPruner(stateStore, blockStore, tc.retainBlocks)
+			pruner.SetApplicationRetainHeight(tc.appRetainHeight)
+			status := pruner.Status()
+			assert.Equal(t, tc.expected, status.RetainHeight)
+			assert.EqualValues(t, 1, status.Base)
+			assert.Equal(t, tc.retainBlocks, status.RetainBlocks)
+		})
+	}
+}
+
+func TestPrunerPrune(t *testing.T) {
+	stateStore := &statemocks.Store{}
+	stateStore.On("Load").Return(newPrunerState(100, 10, 10*time.Minute), nil)
+	stateStore.On("PruneStates", int64(1), int64(81)).Return(nil).Once()
+	blockStore := newPrunerBlockStore()
+	blockStore.On("Base").Return(int64(1)).Once()
+	blockStore.On("Height").Return(int64(100))
+	blockStore.On("PruneBlocks", int64(81)).Return(uint64(80), nil).Once()
+
+	pruner := sm.NewPruner(stateStore, blockStore, 20, sm.PrunerWithInterval(time.Hour))
+	pruner.SetLogger(log.TestingLogger())
+	require.NoError(t, pruner.Start())
+	t.Cleanup(func() { _ = pruner.Stop() })
+
+	// the blocks are pruned once triggered, without waiting for the interval
+	blockStore.On("Base").Return(int64(81))
+	pruner.Trigger()
+	require.Eventually(t, func() bool { return pruner.Status().LastPruned == 80 },
+		5*time.Second, 10*time.Millisecond)
+	status := pruner.Status()
+	assert.False(t, status.Pruning)
+	assert.Empty(t, status.LastPruneError)
+	assert.EqualValues(t, 81, status.Base)
+
+	// the app retain height is never lowered
+	pruner.SetApplicationRetainHeight(90)
+	pruner.SetApplicationRetainHeight(85)
+	assert.EqualValues(t, 90, pruner.Status().AppRetainHeight)
+	stateStore.AssertExpectations(t)
+	blockStore.AssertExpectations(t)
+}
+
+func TestPrunerSetRetainBlocks(t *testing.T) {
+	stateStore := &statemocks.Store{}
+	stateStore.On("Load").Return(newPrunerState(100, 10, 10*time.Minute), nil)
+	blockStore := newPrunerBlockStore()
+	blockStore.On("Base").Return(int64(1))
+
+	pruner := sm.NewPruner(stateStore, blockStore, 0)
+	assert.Zero(t, pruner.Status().RetainHeight)
+
+	pruner.SetRetainBlocks(20)
+	status := pruner.Status()
+	assert.EqualValues(t, 81, status.RetainHeight)
+	assert.EqualValues(t, 20, status.RetainBlocks)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
@@ -83,6 +82,24 @@ type State struct {
 
 	// the latest AppHash we've received from calling abci.Commit()
 	AppHash []byte
+
+	// BlockPartSizeBytes is the size of the block parts, set from the genesis,
+	// or zero for types.BlockPartSizeBytes (e.g. in the states saved before it
+	// could be set). Use BlockPartSize to get it.
+	BlockPartSizeBytes uint32
+}
+
+// BlockPartSize returns the size of the parts of the blocks.
+func (state State) BlockPartSize() uint32 {
+	if state.BlockPartSizeBytes == 0 {
+		return types.BlockPartSizeBytes
+	}
+	return state.BlockPartSizeBytes
+}
+
+// MaxBlockPartsCount returns the maximum number of parts of the blocks.
+func (state State) MaxBlockPartsCount() uint32 {
+	return types.MaxBlockPartsCountForSize(state.ConsensusParams.Block.MaxBytes, state.BlockPartSize())
 }
 
 func (state State) MakeHashMessage(round int32) []byte {
@@ -113,6 +130,8 @@ func (state State) Copy() State {
 		AppHash: state.AppHash,
 
 		LastResultsHash: state.LastResultsHash,
+
+		BlockPartSizeBytes: state.BlockPartSizeBytes,
 	}
 }
 
@@ -183,6 +202,7 @@ func (state *State) ToProto() (*ocstate.State, error) {
 	sm.AppHash = state.AppHash
 
 	sm.LastProofHash = state.LastProofHash
+	sm.BlockPartSizeBytes = state.BlockPartSizeBytes
 
 	return sm, nil
 }
@@ -236,6 +256,7 @@ func FromProto(pb *ocstate.State) (*State, error) { //nolint:golint
 	state.AppHash = pb.AppHash
 
 	state.LastProofHash = pb.LastProofHash
+	state.BlockPartSizeBytes = pb.BlockPartSizeBytes
 
 	return state, nil
 }
@@ -281,7 +302,7 @@ func (state State) MakeBlock(
 		proof,
 	)
 
-	return block, block.MakePartSet(types.BlockPartSizeBytes)
+	return block, block.MakePartSet(state.BlockPartSize())
 }
 
 // MedianTime computes a median time for a given Commit (based on Timestamp field of votes messages) and the
@@ -323,16 +344,9 @@ func MakeGenesisStateFromFile(genDocFile string) (State, error) {
 }
 
 // MakeGenesisDocFromFile reads and unmarshals genesis doc from the given file.
+// See types.GenesisDocFromFile.
 func MakeGenesisDocFromFile(genDocFile string) (*types.GenesisDoc, error) {
-	genDocJSON, err := os.ReadFile(genDocFile)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't read GenesisDoc file: %v", err)
-	}
-	genDoc, err := types.GenesisDocFromJSON(genDocJSON)
-	if err != nil {
-		return nil, fmt.Errorf("error reading GenesisDoc: %v", err)
-	}
-	return genDoc, nil
+	return types.GenesisDocFromFile(genDocFile)
 }
 
 // MakeGenesisState creates state from types.GenesisDoc.
@@ -376,5 +390,7 @@ func MakeGenesisState(genDoc *types.GenesisDoc) (State, error) {
 		LastHeightConsensusParamsChanged: genDoc.InitialHeight,
 
 		AppHash: genDoc.AppHash,
+
+		BlockPartSizeBytes: genDoc.BlockPartSizeBytes,
 	}, nil
 }
@@ -1050,6 +1050,38 @@ func TestStateMakeBlock(t *testing.T) {
 	assert.Equal(t, stateVersion, block.Version)
 }
 
+func TestStateBlockPartSize(t *testing.T) {
+	genDoc := &types.GenesisDoc{ChainID: "test-chain"}
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+	assert.Equal(t, types.BlockPartSizeBytes, state.BlockPartSize())
+
+	genDoc = &types.GenesisDoc{ChainID: "test-chain", BlockPartSizeBytes: 4 * types.BlockPartSizeBytes}
+	state, err = sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+	assert.Equal(t, 4*types.BlockPartSizeBytes, state.BlockPartSize())
+
+	tearDown, _, state := setupTestCase(t)
+	defer tearDown(t)
+	state.BlockPartSizeBytes = 4 * types.BlockPartSizeBytes
+	assert.Equal(t, state.BlockPartSizeBytes, state.Copy().BlockPartSizeBytes)
+
+	pbs, err := state.ToProto()
+	require.NoError(t, err)
+	state2, err := sm.FromProto(pbs)
+	require.NoError(t, err)
+	assert.Equal(t, state.BlockPartSizeBytes, state2.BlockPartSizeBytes)
+
+	// the blocks are split into parts of the size of the state
+	var txs []types.Tx
+	for i := 0; i < 10; i++ {
+		txs = append(txs, tmrand.Bytes(int(types.BlockPartSizeBytes)))
+	}
+	block, parts := state.MakeBlock(1, txs, new(types.Commit), nil, nil, 0, nil)
+	assert.EqualValues(t, 3, parts.Total())
+	assert.Equal(t, block.MakePartSet(state.BlockPartSize()).Header(), parts.Header())
+}
+
 // TestConsensusParamsChangesSaveLoad tests saving and loading consensus params
 // with changes.
 func TestConsensusParamsChangesSaveLoad(t *testing.T) {
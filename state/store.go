@@ -99,6 +99,12 @@ type StoreOptions struct {
 	// the store will maintain only the response object from the latest
 	// height.
 	DiscardABCIResponses bool
+
+	// RetainStates determines whether or not PruneStates retains the
+	// validator sets, consensus params and ABCI responses of the heights
+	// pruned, for them to be still loaded by height once the blocks are
+	// pruned.
+	RetainStates bool
 }
 
 var _ Store = (*dbStore)(nil)
@@ -250,6 +256,8 @@ func (store dbStore) Bootstrap(state State) error {
 // encoding not preserving ordering: https://github.com/tendermint/tendermint/issues/4567
 // This will cause some old states to be left behind when doing incremental partial prunes,
 // specifically older checkpoints and LastHeightChanged targets.
+//
+// Nothing is deleted with StoreOptions.RetainStates.
 func (store dbStore) PruneStates(from int64, to int64) error {
 	if from <= 0 || to <= 0 {
 		return fmt.Errorf("from height %v and to height %v must be greater than 0", from, to)
@@ -257,6 +265,9 @@ func (store dbStore) PruneStates(from int64, to int64) error {
 	if from >= to {
 		return fmt.Errorf("from height %v must be lower than to height %v", from, to)
 	}
+	if store.RetainStates {
+		return nil
+	}
 	valInfo, err := loadValidatorsInfo(store.db, to)
 	if err != nil {
 		return fmt.Errorf("validators at height %v not found: %w", to, err)
@@ -236,6 +236,39 @@ func TestPruneStates(t *testing.T) {
 	}
 }
 
+func TestPruneStatesRetainStates(t *testing.T) {
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{
+		DiscardABCIResponses: false,
+		RetainStates:         true,
+	})
+
+	for _, state := range createStates(10) {
+		require.NoError(t, stateStore.Save(state))
+		err := stateStore.SaveABCIResponses(state.LastBlockHeight+1, &tmstate.ABCIResponses{
+			DeliverTxs: []*abci.ResponseDeliverTx{{Data: []byte{1}}},
+		})
+		require.NoError(t, err)
+	}
+
+	// the arguments are still checked
+	require.Error(t, stateStore.PruneStates(3, 2))
+	require.NoError(t, stateStore.PruneStates(2, 8))
+
+	for h := int64(1); h <= 10; h++ {
+		vals, err := stateStore.LoadValidators(h)
+		require.NoError(t, err, "validators height %v", h)
+		require.NotNil(t, vals)
+
+		params, err := stateStore.LoadConsensusParams(h)
+		require.NoError(t, err, "params height %v", h)
+		require.False(t, params.Equal(&tmproto.ConsensusParams{}))
+
+		abci, err := stateStore.LoadABCIResponses(h)
+		require.NoError(t, err, "abci height %v", h)
+		require.NotNil(t, abci)
+	}
+}
+
 func TestPruneStatesDeleteErrHandle(t *testing.T) {
 	testcases := map[string]struct {
 		deleteValidatorsRet      error
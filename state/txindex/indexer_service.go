@@ -85,8 +85,10 @@ func (is *IndexerService) OnStart() error {
 				}
 			}
 
-			if err := is.blockIdxr.Index(eventDataHeader); err != nil {
-				is.Logger.Error("failed to index block", "height", height, "err", err)
+			// the txs are indexed before the block, so that the txs of the blocks
+			// which are indexed (see BlockIndexer.Has) are indexed as well
+			if err = is.txIdxr.AddBatch(batch); err != nil {
+				is.Logger.Error("failed to index block txs", "height", height, "err", err)
 				if is.terminateOnError {
 					if err := is.Stop(); err != nil {
 						is.Logger.Error("failed to stop", "err", err)
@@ -94,11 +96,11 @@ func (is *IndexerService) OnStart() error {
 					return
 				}
 			} else {
-				is.Logger.Info("indexed block exents", "height", height)
+				is.Logger.Debug("indexed transactions", "height", height, "num_txs", eventDataHeader.NumTxs)
 			}
 
-			if err = is.txIdxr.AddBatch(batch); err != nil {
-				is.Logger.Error("failed to index block txs", "height", height, "err", err)
+			if err := is.blockIdxr.Index(eventDataHeader); err != nil {
+				is.Logger.Error("failed to index block", "height", height, "err", err)
 				if is.terminateOnError {
 					if err := is.Stop(); err != nil {
 						is.Logger.Error("failed to stop", "err", err)
@@ -106,7 +108,7 @@ func (is *IndexerService) OnStart() error {
 					return
 				}
 			} else {
-				is.Logger.Debug("indexed transactions", "height", height, "num_txs", eventDataHeader.NumTxs)
+				is.Logger.Info("indexed block exents", "height", height)
 			}
 		}
 	}()
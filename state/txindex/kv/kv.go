@@ -163,7 +163,7 @@ func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Ba
 			}
 
 			// index if `index: true` is set
-			compositeTag := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			compositeTag := types.CompositeEventKey(event.Type, string(attr.Key))
 			if attr.GetIndex() {
 				err := store.Set(keyForEvent(compositeTag, attr.Value, result), hash)
 				if err != nil {
@@ -185,6 +185,11 @@ func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Ba
 // performing a full scan. Results from querying indexes are then intersected
 // and returned to the caller, in no particular order.
 //
+// The conditions combined with OR are searched separately and their results
+// united, and the results of the negated conditions (NOT) removed from the
+// others, or from all the txs if there is no other condition, in which case
+// all the txs are scanned.
+//
 // Search will exit early and return any result fetched so far,
 // when a message is received on the context chan.
 func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
@@ -195,15 +200,35 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 	default:
 	}
 
-	var hashesInitialized bool
-	filteredHashes := make(map[string][]byte)
-
-	// get a list of conditions (like "tx.height > 5")
-	conditions, err := q.Conditions()
+	filteredHashes, err := indexer.SearchExpr(ctx, q.Expr(), txi.searchConditions, txi.searchAll)
 	if err != nil {
-		return nil, fmt.Errorf("error during parsing conditions from query: %w", err)
+		return nil, err
+	}
+
+	results := make([]*abci.TxResult, 0, len(filteredHashes))
+	for _, h := range filteredHashes {
+		res, err := txi.Get(h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
+		}
+		results = append(results, res)
+
+		// Potentially exit early.
+		select {
+		case <-ctx.Done():
+			break
+		default:
+		}
 	}
 
+	return results, nil
+}
+
+// searchConditions returns the hashes of the txs matching all the conditions.
+func (txi *TxIndex) searchConditions(ctx context.Context, conditions []query.Condition) (indexer.Matches, error) {
+	var hashesInitialized bool
+	filteredHashes := make(map[string][]byte)
+
 	// if there is a hash condition, return the result immediately
 	hash, ok, err := lookForHash(conditions)
 	if err != nil {
@@ -212,12 +237,11 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		res, err := txi.Get(hash)
 		switch {
 		case err != nil:
-			return []*abci.TxResult{}, fmt.Errorf("error while retrieving the result: %w", err)
-		case res == nil:
-			return []*abci.TxResult{}, nil
-		default:
-			return []*abci.TxResult{res}, nil
+			return nil, fmt.Errorf("error while retrieving the result: %w", err)
+		case res != nil:
+			filteredHashes[string(hash)] = hash
 		}
+		return filteredHashes, nil
 	}
 
 	// conditions to skip because they're handled before "everything else"
@@ -269,23 +293,12 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		}
 	}
 
-	results := make([]*abci.TxResult, 0, len(filteredHashes))
-	for _, h := range filteredHashes {
-		res, err := txi.Get(h)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
-		}
-		results = append(results, res)
-
-		// Potentially exit early.
-		select {
-		case <-ctx.Done():
-			break
-		default:
-		}
-	}
+	return filteredHashes, nil
+}
 
-	return results, nil
+// searchAll returns the hashes of all the indexed txs.
+func (txi *TxIndex) searchAll(ctx context.Context) (indexer.Matches, error) {
+	return txi.match(ctx, query.Condition{CompositeKey: types.TxHeightKey, Op: query.OpExists}, nil, nil, true), nil
 }
 
 func lookForHash(conditions []query.Condition) (hash []byte, ok bool, err error) {
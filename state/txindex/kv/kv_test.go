@@ -408,6 +408,25 @@ func TestTxSearchMultipleTxs(t *testing.T) {
 	assert.NoError(t, err)
 
 	require.Len(t, results, 3)
+
+	testCases := []struct {
+		q       string
+		results []*abci.TxResult
+	}{
+		{"account.number = 1 OR account.number = 3", []*abci.TxResult{txResult, txResult3}},
+		{"account.number IN (2, 5)", []*abci.TxResult{txResult2}},
+		{"NOT account.number >= 2", []*abci.TxResult{txResult, txResult4}},
+		{"tx.height = 2 AND NOT account.number EXISTS", []*abci.TxResult{txResult4}},
+		{"account.number >= 1 AND NOT (account.number = 1 OR tx.height = 1)", []*abci.TxResult{}},
+		{"(account.number = 3 OR account.number.id = 1) AND tx.height = 2", []*abci.TxResult{txResult4}},
+		{fmt.Sprintf("tx.hash = '%X' OR account.number = 3", types.Tx(txResult2.Tx).Hash()),
+			[]*abci.TxResult{txResult2, txResult3}},
+	}
+	for _, tc := range testCases {
+		results, err := indexer.Search(ctx, query.MustParse(tc.q))
+		require.NoError(t, err, tc.q)
+		assert.ElementsMatch(t, tc.results, results, tc.q)
+	}
 }
 
 func txResultWithEvents(events []abci.Event) *abci.TxResult {
@@ -154,23 +154,10 @@ func validateBlock(state State, round int32, block *types.Block) error {
 		return types.NewErrInvalidRound(round, block.Round)
 	}
 
-	// validate proposer
-	proposer := state.Validators.SelectProposer(state.LastProofHash, block.Height, block.Round)
-	if !bytes.Equal(block.ProposerAddress.Bytes(), proposer.Address.Bytes()) {
-		return fmt.Errorf("block.ProposerAddress, %X, is not the proposer %X",
-			block.ProposerAddress,
-			proposer.Address,
-		)
-	}
-
-	// validate vrf proof
-	message := state.MakeHashMessage(block.Round)
-	proof := crypto.Proof(block.Proof)
-	_, err := proposer.PubKey.VRFVerify(proof, message)
-	if err != nil {
-		return types.NewErrInvalidProof(fmt.Sprintf(
-			"verification failed: %s; proof: %v, height=%d, round=%d, addr: %v",
-			err.Error(), block.Proof, block.Height, block.Round, block.ProposerAddress))
+	// validate proposer and vrf proof
+	if _, err := types.VerifyEntropy(block.Entropy, state.Validators, state.LastProofHash,
+		state.LastBlockHeight, block.Height, block.ProposerAddress); err != nil {
+		return err
 	}
 
 	return nil
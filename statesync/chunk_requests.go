@@ -0,0 +1,112 @@
+package statesync
+
+import (
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/p2p"
+)
+
+// chunkRequests tracks the chunk requests sent to the peers, to spread them
+// over the peers of the snapshot, and to switch the peer of a chunk when it's
+// requested again.
+type chunkRequests struct {
+	mtx      tmsync.Mutex
+	inFlight map[p2p.ID]int             // number of requests waiting for a response, per peer
+	peers    map[uint32]p2p.ID          // the peer a chunk is requested from
+	tried    map[uint32]map[p2p.ID]bool // the peers a chunk was requested from
+	missing  map[uint32]chan struct{}   // closed when the peer reports the chunk missing
+}
+
+func newChunkRequests() *chunkRequests {
+	return &chunkRequests{
+		inFlight: make(map[p2p.ID]int),
+		peers:    make(map[uint32]p2p.ID),
+		tried:    make(map[uint32]map[p2p.ID]bool),
+		missing:  make(map[uint32]chan struct{}),
+	}
+}
+
+// request selects the peer to request the chunk from: the one with the fewest
+// requests in flight among the peers of the snapshot the chunk wasn't requested
+// from yet, or among all of them once they were all tried. It returns nil if
+// there's no peer, and otherwise a channel closed if the peer reports the chunk
+// missing while another peer can be tried, nil if none can.
+func (r *chunkRequests) request(index uint32, peers []p2p.Peer) (p2p.Peer, <-chan struct{}) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	tried := r.tried[index]
+	if tried == nil || !hasUntriedPeer(peers, tried) {
+		tried = make(map[p2p.ID]bool)
+		r.tried[index] = tried
+	}
+	var selected p2p.Peer
+	for _, peer := range peers {
+		if tried[peer.ID()] {
+			continue
+		}
+		if selected == nil || r.inFlight[peer.ID()] < r.inFlight[selected.ID()] {
+			selected = peer
+		}
+	}
+	if selected == nil {
+		return nil, nil
+	}
+
+	tried[selected.ID()] = true
+	r.inFlight[selected.ID()]++
+	r.peers[index] = selected.ID()
+	if !hasUntriedPeer(peers, tried) {
+		return selected, nil
+	}
+	missing := make(chan struct{})
+	r.missing[index] = missing
+	return selected, missing
+}
+
+// done ends the request of the chunk, which was received, reported missing, or
+// timed out.
+func (r *chunkRequests) done(index uint32) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	peer, ok := r.peers[index]
+	if !ok {
+		return
+	}
+	if r.inFlight[peer]--; r.inFlight[peer] <= 0 {
+		delete(r.inFlight, peer)
+	}
+	delete(r.peers, index)
+	delete(r.missing, index)
+}
+
+// forget forgets the peers the chunk was requested from, once it's received.
+func (r *chunkRequests) forget(index uint32) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.tried, index)
+}
+
+// setMissing signals that the peer doesn't have the chunk, if it's requested
+// from it.
+func (r *chunkRequests) setMissing(index uint32, peer p2p.ID) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.peers[index] != peer {
+		return
+	}
+	if missing, ok := r.missing[index]; ok {
+		close(missing)
+		delete(r.missing, index)
+	}
+}
+
+func hasUntriedPeer(peers []p2p.Peer, tried map[p2p.ID]bool) bool {
+	for _, peer := range peers {
+		if !tried[peer.ID()] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/p2p"
+	p2pmocks "github.com/Finschia/ostracon/p2p/mocks"
+)
+
+func newChunkRequestsPeers(ids ...string) []p2p.Peer {
+	peers := make([]p2p.Peer, 0, len(ids))
+	for _, id := range ids {
+		peer := &p2pmocks.Peer{}
+		peer.On("ID").Return(p2p.ID(id))
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+func TestChunkRequests_Spread(t *testing.T) {
+	r := newChunkRequests()
+	peers := newChunkRequestsPeers("a", "b", "c")
+
+	// the requests in flight are spread over the peers
+	for i, id := range []p2p.ID{"a", "b", "c", "a"} {
+		peer, _ := r.request(uint32(i), peers)
+		require.NotNil(t, peer)
+		assert.Equal(t, id, peer.ID())
+	}
+	r.done(1)
+	peer, _ := r.request(4, peers)
+	assert.EqualValues(t, "b", peer.ID())
+
+	// no peer
+	peer, missing := r.request(5, nil)
+	assert.Nil(t, peer)
+	assert.Nil(t, missing)
+}
+
+func TestChunkRequests_Retry(t *testing.T) {
+	r := newChunkRequests()
+	peers := newChunkRequestsPeers("a", "b")
+
+	// a chunk requested again is requested from another peer
+	peer, missing := r.request(0, peers)
+	assert.EqualValues(t, "a", peer.ID())
+	require.NotNil(t, missing)
+
+	// which is signaled if the peer reports the chunk missing
+	r.setMissing(0, "b")
+	select {
+	case <-missing:
+		t.Fatal("the chunk was reported missing by another peer")
+	default:
+	}
+	r.setMissing(0, "a")
+	<-missing
+	r.done(0)
+
+	// the last peer to try isn't expected to report the chunk missing
+	peer, missing = r.request(0, peers)
+	assert.EqualValues(t, "b", peer.ID())
+	assert.Nil(t, missing)
+	r.done(0)
+
+	// all the peers are tried again once they were all tried
+	peer, _ = r.request(0, peers)
+	assert.EqualValues(t, "a", peer.ID())
+	r.done(0)
+
+	// and from the start once the chunk is received
+	r.forget(0)
+	peer, _ = r.request(0, peers)
+	assert.EqualValues(t, "a", peer.ID())
+}
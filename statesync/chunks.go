@@ -1,20 +1,30 @@
 package statesync
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
 	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/libs/tempfile"
 	"github.com/Finschia/ostracon/p2p"
 )
 
 // errDone is returned by chunkQueue.Next() when all chunks have been returned.
 var errDone = errors.New("chunk queue has completed")
 
+// the prefix of the directories of the chunk queues
+const chunkQueueDirPrefix = "oc-statesync"
+
 // chunk contains data for a chunk.
 type chunk struct {
 	Height uint64
@@ -41,7 +51,7 @@ type chunkQueue struct {
 // newChunkQueue creates a new chunk queue for a snapshot, using a temp dir for storage.
 // Callers must call Close() when done.
 func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
-	dir, err := os.MkdirTemp(tempDir, "oc-statesync")
+	dir, err := os.MkdirTemp(tempDir, chunkQueueDirPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create temp dir for state sync chunks: %w", err)
 	}
@@ -59,6 +69,108 @@ func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
 	}, nil
 }
 
+// openChunkQueue opens the chunk queue of a snapshot persisted in dir, with the
+// chunks fetched before the node restarted, if any, so that they aren't fetched
+// again. The queues of the other snapshots are removed. Callers must call
+// Close() when done, which removes the queue.
+func openChunkQueue(snapshot *snapshot, dir string) (*chunkQueue, error) {
+	if snapshot.Chunks == 0 {
+		return nil, errors.New("snapshot has no chunks")
+	}
+	key := snapshot.Key()
+	queueDir := filepath.Join(dir, chunkQueueDirPrefix+"-"+hex.EncodeToString(key[:]))
+	if err := removeChunkQueues(dir, queueDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(queueDir, 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create dir for state sync chunks: %w", err)
+	}
+	bz, err := proto.Marshal(&abci.Snapshot{
+		Height:   snapshot.Height,
+		Format:   snapshot.Format,
+		Chunks:   snapshot.Chunks,
+		Hash:     snapshot.Hash,
+		Metadata: snapshot.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tempfile.WriteFileAtomic(filepath.Join(queueDir, snapshotMetadataFile), bz, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to save the snapshot of the chunks: %w", err)
+	}
+
+	q := &chunkQueue{
+		snapshot:       snapshot,
+		dir:            queueDir,
+		chunkFiles:     make(map[uint32]string, snapshot.Chunks),
+		chunkSenders:   make(map[uint32]p2p.ID, snapshot.Chunks),
+		chunkAllocated: make(map[uint32]bool, snapshot.Chunks),
+		chunkReturned:  make(map[uint32]bool, snapshot.Chunks),
+		waiters:        make(map[uint32][]chan<- uint32),
+	}
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		path := filepath.Join(queueDir, strconv.FormatUint(uint64(i), 10))
+		if _, err := os.Stat(path); err == nil {
+			q.chunkFiles[i] = path
+			q.chunkAllocated[i] = true
+		}
+	}
+	return q, nil
+}
+
+// loadChunkQueueSnapshot returns the snapshot of the chunk queue persisted in
+// dir, or nil if there's none.
+func loadChunkQueueSnapshot(dir string) (*snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), chunkQueueDirPrefix+"-") {
+			continue
+		}
+		bz, err := os.ReadFile(filepath.Join(dir, entry.Name(), snapshotMetadataFile))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		s := &abci.Snapshot{}
+		if err := proto.Unmarshal(bz, s); err != nil {
+			return nil, fmt.Errorf("invalid snapshot of the chunks of %s: %w", entry.Name(), err)
+		}
+		return &snapshot{
+			Height:   s.Height,
+			Format:   s.Format,
+			Chunks:   s.Chunks,
+			Hash:     s.Hash,
+			Metadata: s.Metadata,
+		}, nil
+	}
+	return nil, nil
+}
+
+// removeChunkQueues removes the chunk queues in dir, except keep.
+func removeChunkQueues(dir, keep string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), chunkQueueDirPrefix) && path != keep {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("failed to remove the state sync chunks of %v: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Add adds a chunk to the queue. It ignores chunks that already exist, returning false.
 func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	if chunk == nil || chunk.Chunk == nil {
@@ -82,8 +194,9 @@ func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 		return false, nil
 	}
 
+	// written atomically, not to resume the sync with a partial chunk
 	path := filepath.Join(q.dir, strconv.FormatUint(uint64(chunk.Index), 10))
-	err := os.WriteFile(path, chunk.Chunk, 0o600)
+	err := tempfile.WriteFileAtomic(path, chunk.Chunk, 0o600)
 	if err != nil {
 		return false, fmt.Errorf("failed to save chunk %v to file %v: %w", chunk.Index, path, err)
 	}
@@ -194,6 +307,13 @@ func (q *chunkQueue) GetSender(index uint32) p2p.ID {
 	return q.chunkSenders[index]
 }
 
+// Fetched returns the number of chunks in the queue.
+func (q *chunkQueue) Fetched() uint32 {
+	q.Lock()
+	defer q.Unlock()
+	return uint32(len(q.chunkFiles))
+}
+
 // Has checks whether a chunk exists in the queue.
 func (q *chunkQueue) Has(index uint32) bool {
 	q.Lock()
@@ -548,3 +548,54 @@ func TestChunkQueue_WaitFor(t *testing.T) {
 	_, ok = <-w
 	assert.False(t, ok)
 }
+
+func TestOpenChunkQueue_Resume(t *testing.T) {
+	resumed := &snapshot{Height: 3, Format: 1, Chunks: 3, Hash: []byte{7}, Metadata: []byte{1}}
+	dir := t.TempDir()
+
+	loaded, err := loadChunkQueueSnapshot(dir)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	queue, err := openChunkQueue(resumed, dir)
+	require.NoError(t, err)
+	for _, index := range []uint32{0, 2} {
+		_, err := queue.Allocate()
+		require.NoError(t, err)
+		_, err = queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{byte(index)}})
+		require.NoError(t, err)
+	}
+
+	// the node restarts, the queue being reopened with the fetched chunks
+	loaded, err = loadChunkQueueSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, resumed, loaded)
+	queue, err = openChunkQueue(loaded, dir)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, queue.Fetched())
+	assert.True(t, queue.Has(0))
+	assert.True(t, queue.Has(2))
+	index, err := queue.Allocate()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, index)
+	_, err = queue.Allocate()
+	assert.Equal(t, errDone, err)
+	c, err := queue.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, c.Chunk)
+
+	// the queues of the other snapshots are removed
+	other := &snapshot{Height: 4, Format: 1, Chunks: 1, Hash: []byte{8}}
+	otherQueue, err := openChunkQueue(other, dir)
+	require.NoError(t, err)
+	assert.Zero(t, otherQueue.Fetched())
+	loaded, err = loadChunkQueueSnapshot(dir)
+	require.NoError(t, err)
+	assert.Equal(t, other, loaded)
+
+	// and the queue is removed when closed
+	require.NoError(t, otherQueue.Close())
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
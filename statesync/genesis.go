@@ -0,0 +1,46 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Finschia/ostracon/libs/tempfile"
+	rpcclient "github.com/Finschia/ostracon/rpc/client"
+)
+
+// FetchGenesis fetches the genesis document in chunks, from the first of the
+// RPC servers serving it, and writes its JSON to the file as the chunks are
+// received, whatever its size. The document must have the given hash (see
+// types.GenesisDoc.Hash, the hash of the JSON served), otherwise the file
+// isn't written.
+func FetchGenesis(ctx context.Context, servers []string, hash []byte, file string) error {
+	errs := make([]error, 0, len(servers))
+	for _, server := range servers {
+		c, err := rpcClient(server)
+		if err == nil {
+			err = writeGenesis(ctx, c, hash, file)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("failed to fetch the genesis from %s: %w", server, err))
+	}
+	return errors.Join(errs...)
+}
+
+func writeGenesis(ctx context.Context, c rpcclient.HistoryClient, hash []byte, file string) error {
+	return tempfile.WriteFileAtomicFunc(file, 0o644, func(w io.Writer) error {
+		h := sha256.New()
+		if err := rpcclient.WriteGenesis(ctx, c, io.MultiWriter(w, h)); err != nil {
+			return err
+		}
+		if sum := h.Sum(nil); !bytes.Equal(sum, hash) {
+			return fmt.Errorf("the genesis hash %X isn't the expected %X", sum, hash)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,39 @@
+package statesync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/types"
+)
+
+func TestFetchGenesis(t *testing.T) {
+	setupVars(t)
+	// as served by the nodes
+	require.NoError(t, genDoc.ValidateAndComplete())
+	listeners, servers, closeListenersFunc := serveTestRPCServers(t, cfg, 1)
+	defer closeListenersFunc(listeners)
+	file := filepath.Join(t.TempDir(), "genesis.json")
+
+	// the genesis document must have the hash
+	err := FetchGenesis(context.Background(), servers, []byte{1}, file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't the expected 01")
+	assert.NoFileExists(t, file)
+
+	// the servers are tried in turn
+	err = FetchGenesis(context.Background(), append([]string{"127.0.0.1:1"}, servers...), genDoc.Hash(), file)
+	require.NoError(t, err)
+	fetched, err := types.GenesisDocFromFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, genDoc.Hash(), fetched.Hash())
+
+	require.NoError(t, os.Remove(file))
+	assert.Error(t, FetchGenesis(context.Background(), []string{"127.0.0.1:1"}, genDoc.Hash(), file))
+	assert.NoFileExists(t, file)
+}
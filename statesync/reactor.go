@@ -1,6 +1,7 @@
 package statesync
 
 import (
+	"context"
 	"errors"
 	"sort"
 	"time"
@@ -11,6 +12,8 @@ import (
 	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
 
 	"github.com/Finschia/ostracon/config"
+	tmasync "github.com/Finschia/ostracon/libs/async"
+	"github.com/Finschia/ostracon/libs/log"
 	tmsync "github.com/Finschia/ostracon/libs/sync"
 	"github.com/Finschia/ostracon/p2p"
 	"github.com/Finschia/ostracon/proxy"
@@ -25,6 +28,11 @@ const (
 	ChunkChannel = byte(0x61)
 	// recentSnapshots is the number of recent snapshots to send and receive per peer.
 	recentSnapshots = 10
+	// number of routines loading the chunks requested by peers from the app
+	numChunkServers = 4
+	// max number of chunk requests waiting to be served. Requests received while
+	// the queue is full are dropped; the peers request the chunks again.
+	maxChunkRequestQueueSize = 64
 )
 
 // Reactor handles state sync, both restoring snapshots for the local node and serving snapshots
@@ -35,7 +43,14 @@ type Reactor struct {
 	cfg       config.StateSyncConfig
 	conn      proxy.AppConnSnapshot
 	connQuery proxy.AppConnQuery
-	tempDir   string
+	chunkDir  string
+
+	// serves the chunks requested by peers
+	chunkPool *tmasync.Pool
+
+	// the snapshots archived by the node, served along with the ones of the
+	// app, if set
+	snapshotter *Snapshotter
 
 	// This will only be set when a state sync is in progress. It is used to feed received
 	// snapshots and chunks into the sync.
@@ -56,12 +71,29 @@ func NewReactor(
 		cfg:       cfg,
 		conn:      conn,
 		connQuery: connQuery,
+		// the requests not served before the peers time out are dropped
+		chunkPool: tmasync.NewPool("StateSyncChunks", numChunkServers, maxChunkRequestQueueSize,
+			tmasync.PoolTimeout(cfg.ChunkRequestTimeout)),
 	}
 	r.BaseReactor = *p2p.NewBaseReactor("StateSync", r, async, recvBufSize)
 
 	return r
 }
 
+// SetChunkDir stores the chunks of the snapshot being restored in dir, until
+// it's restored or rejected, so that the restoration is resumed with them if
+// the node restarts, instead of a temporary directory. It must be called before
+// Sync.
+func (r *Reactor) SetChunkDir(dir string) {
+	r.chunkDir = dir
+}
+
+// SetSnapshotter serves the snapshots archived by the snapshotter along with
+// the ones of the app. It must be called before the reactor is started.
+func (r *Reactor) SetSnapshotter(snapshotter *Snapshotter) {
+	r.snapshotter = snapshotter
+}
+
 // GetChannels implements p2p.Reactor.
 func (r *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 	return []*p2p.ChannelDescriptor{
@@ -78,6 +110,7 @@ func (r *Reactor) GetChannels() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   10,
 			RecvMessageCapacity: chunkMsgSize,
 			MessageType:         &ssproto.Message{},
+			Compress:            true,
 		},
 	}
 }
@@ -89,7 +122,20 @@ func (r *Reactor) OnStart() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return r.chunkPool.Start()
+}
+
+// OnStop implements p2p.Reactor.
+func (r *Reactor) OnStop() {
+	if err := r.chunkPool.Stop(); err != nil {
+		r.Logger.Error("Error stopping the chunk serving", "err", err)
+	}
+}
+
+// SetLogger implements p2p.Reactor.
+func (r *Reactor) SetLogger(l log.Logger) {
+	r.Logger = l
+	r.chunkPool.SetLogger(l)
 }
 
 // AddPeer implements p2p.Reactor.
@@ -178,28 +224,12 @@ func (r *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 		case *ssproto.ChunkRequest:
 			r.Logger.Debug("Received chunk request", "height", msg.Height, "format", msg.Format,
 				"chunk", msg.Index, "peer", e.Src.ID())
-			resp, err := r.conn.LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
-				Height: msg.Height,
-				Format: msg.Format,
-				Chunk:  msg.Index,
-			})
-			if err != nil {
-				r.Logger.Error("Failed to load chunk", "height", msg.Height, "format", msg.Format,
-					"chunk", msg.Index, "err", err)
-				return
+			// the chunks are loaded off the receive path, as it may take the app a while
+			src := e.Src
+			if !r.chunkPool.TrySubmit(func(context.Context) { r.serveChunk(src, msg) }) {
+				r.Logger.Info("Chunk request queue is full, dropping request", "height", msg.Height,
+					"format", msg.Format, "chunk", msg.Index, "peer", e.Src.ID())
 			}
-			r.Logger.Debug("Sending chunk", "height", msg.Height, "format", msg.Format,
-				"chunk", msg.Index, "peer", e.Src.ID())
-			p2p.SendEnvelopeShim(e.Src, p2p.Envelope{ //nolint: staticcheck
-				ChannelID: ChunkChannel,
-				Message: &ssproto.ChunkResponse{
-					Height:  msg.Height,
-					Format:  msg.Format,
-					Index:   msg.Index,
-					Chunk:   resp.Chunk,
-					Missing: resp.Chunk == nil,
-				},
-			}, r.Logger)
 
 		case *ssproto.ChunkResponse:
 			r.mtx.RLock()
@@ -208,6 +238,12 @@ func (r *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 				r.Logger.Debug("Received unexpected chunk, no state sync in progress", "peer", e.Src.ID())
 				return
 			}
+			if msg.Missing {
+				r.Logger.Debug("Peer doesn't have chunk", "height", msg.Height, "format", msg.Format,
+					"chunk", msg.Index, "peer", e.Src.ID())
+				r.syncer.ChunkMissing(e.Src.ID(), msg.Height, msg.Format, msg.Index)
+				return
+			}
 			r.Logger.Debug("Received chunk, adding to sync", "height", msg.Height, "format", msg.Format,
 				"chunk", msg.Index, "peer", e.Src.ID())
 			_, err := r.syncer.AddChunk(&chunk{
@@ -232,6 +268,47 @@ func (r *Reactor) ReceiveEnvelope(e p2p.Envelope) {
 	}
 }
 
+// serveChunk loads the chunk requested by the peer from the archived snapshots
+// or the app, and sends it.
+func (r *Reactor) serveChunk(src p2p.Peer, msg *ssproto.ChunkRequest) {
+	chunk, err := r.loadChunk(msg)
+	if err != nil {
+		r.Logger.Error("Failed to load chunk", "height", msg.Height, "format", msg.Format,
+			"chunk", msg.Index, "err", err)
+		return
+	}
+	r.Logger.Debug("Sending chunk", "height", msg.Height, "format", msg.Format,
+		"chunk", msg.Index, "peer", src.ID())
+	p2p.SendEnvelopeShim(src, p2p.Envelope{ //nolint: staticcheck
+		ChannelID: ChunkChannel,
+		Message: &ssproto.ChunkResponse{
+			Height:  msg.Height,
+			Format:  msg.Format,
+			Index:   msg.Index,
+			Chunk:   chunk,
+			Missing: chunk == nil,
+		},
+	}, r.Logger)
+}
+
+func (r *Reactor) loadChunk(msg *ssproto.ChunkRequest) ([]byte, error) {
+	if r.snapshotter != nil {
+		chunk, err := r.snapshotter.LoadChunk(msg.Height, msg.Format, msg.Index)
+		if err != nil || chunk != nil {
+			return chunk, err
+		}
+	}
+	resp, err := r.conn.LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
+		Height: msg.Height,
+		Format: msg.Format,
+		Chunk:  msg.Index,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Chunk, nil
+}
+
 func (r *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
 	msg := &ssproto.Message{}
 	err := proto.Unmarshal(msgBytes, msg)
@@ -250,12 +327,16 @@ func (r *Reactor) Receive(chID byte, peer p2p.Peer, msgBytes []byte) {
 	})
 }
 
-// recentSnapshots fetches the n most recent snapshots from the app
+// recentSnapshots fetches the n most recent snapshots from the app and the
+// archived ones
 func (r *Reactor) recentSnapshots(n uint32) ([]*snapshot, error) {
 	resp, err := r.conn.ListSnapshotsSync(abci.RequestListSnapshots{})
 	if err != nil {
 		return nil, err
 	}
+	if r.snapshotter != nil {
+		resp.Snapshots = mergeSnapshots(resp.Snapshots, r.snapshotter.Snapshots())
+	}
 	sort.Slice(resp.Snapshots, func(i, j int) bool {
 		a := resp.Snapshots[i]
 		b := resp.Snapshots[j]
@@ -284,6 +365,25 @@ func (r *Reactor) recentSnapshots(n uint32) ([]*snapshot, error) {
 	return snapshots, nil
 }
 
+// mergeSnapshots appends the archived snapshots which the app doesn't have
+// anymore to the ones of the app.
+func mergeSnapshots(app, archived []*abci.Snapshot) []*abci.Snapshot {
+	type key struct {
+		height uint64
+		format uint32
+	}
+	known := make(map[key]bool, len(app))
+	for _, s := range app {
+		known[key{s.Height, s.Format}] = true
+	}
+	for _, s := range archived {
+		if !known[key{s.Height, s.Format}] {
+			app = append(app, s)
+		}
+	}
+	return app
+}
+
 // Sync runs a state sync, returning the new state, previous state and last commit at the snapshot height.
 // The caller must store the state and commit in the state database and block store.
 func (r *Reactor) Sync(
@@ -293,7 +393,7 @@ func (r *Reactor) Sync(
 		r.mtx.Unlock()
 		return sm.State{}, sm.State{}, nil, errors.New("a state sync is already in progress")
 	}
-	r.syncer = newSyncer(r.cfg, r.Logger, r.conn, r.connQuery, stateProvider, r.tempDir)
+	r.syncer = newSyncer(r.cfg, r.Logger, r.conn, r.connQuery, stateProvider, r.chunkDir)
 	r.mtx.Unlock()
 
 	hook := func() {
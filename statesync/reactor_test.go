@@ -66,7 +66,8 @@ func TestReactor_Receive_ChunkRequest(t *testing.T) {
 			// Mock peer to store response, if found
 			peer := &Peer{Peer: &p2pmocks.Peer{}, EnvelopeSender: &p2pmocks.EnvelopeSender{}}
 			peer.Peer.On("ID").Return(p2p.ID("id"))
-			var response *ssproto.ChunkResponse
+			// the chunks are served by the worker pool of the reactor
+			responseCh := make(chan *ssproto.ChunkResponse, 1)
 			if tc.expectResponse != nil {
 				peer.EnvelopeSender.On("SendEnvelope", mock.MatchedBy(func(i interface{}) bool {
 					e, ok := i.(p2p.Envelope)
@@ -79,7 +80,7 @@ func TestReactor_Receive_ChunkRequest(t *testing.T) {
 					require.NoError(t, err)
 					err = proto.Unmarshal(bz, e.Message)
 					require.NoError(t, err)
-					response = e.Message.(*ssproto.ChunkResponse)
+					responseCh <- e.Message.(*ssproto.ChunkResponse)
 				}).Return(true)
 			}
 
@@ -99,8 +100,12 @@ func TestReactor_Receive_ChunkRequest(t *testing.T) {
 				Src:       peer,
 				Message:   tc.request,
 			})
-			time.Sleep(100 * time.Millisecond)
-			assert.Equal(t, tc.expectResponse, response)
+			select {
+			case response := <-responseCh:
+				assert.Equal(t, tc.expectResponse, response)
+			case <-time.After(time.Second):
+				t.Fatal("no chunk response")
+			}
 
 			conn.AssertExpectations(t)
 			peer.Peer.AssertExpectations(t)
@@ -366,3 +371,34 @@ func TestSync(t *testing.T) {
 	require.NotNil(t, previousState)
 	require.NotNil(t, commit)
 }
+
+func TestReactor_ServeArchivedSnapshots(t *testing.T) {
+	app := newSnapshotterApp()
+	app.addSnapshot(&abci.Snapshot{Height: 3, Format: 1, Chunks: 2})
+	s, eventBus := newTestSnapshotter(t, t.TempDir(), app, 0)
+	publishBlock(t, eventBus, 3)
+	require.Eventually(t, func() bool { return len(s.Snapshots()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// the app pruned the archived snapshot
+	conn := &proxymocks.AppConnSnapshot{}
+	conn.On("ListSnapshotsSync", abci.RequestListSnapshots{}).Return(&abci.ResponseListSnapshots{
+		Snapshots: []*abci.Snapshot{{Height: 4, Format: 1, Chunks: 1}},
+	}, nil)
+	conn.On("LoadSnapshotChunkSync", abci.RequestLoadSnapshotChunk{Height: 4, Format: 1, Chunk: 0}).Return(
+		&abci.ResponseLoadSnapshotChunk{Chunk: []byte{4, 0}}, nil)
+	r := NewReactor(*config.DefaultStateSyncConfig(), conn, nil, false, 0)
+	r.SetSnapshotter(s)
+
+	snapshots, err := r.recentSnapshots(recentSnapshots)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.EqualValues(t, 4, snapshots[0].Height)
+	assert.EqualValues(t, 3, snapshots[1].Height)
+
+	chunk, err := r.loadChunk(&ssproto.ChunkRequest{Height: 3, Format: 1, Index: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 1}, chunk)
+	chunk, err = r.loadChunk(&ssproto.ChunkRequest{Height: 4, Format: 1, Index: 0})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4, 0}, chunk)
+}
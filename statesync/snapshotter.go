@@ -0,0 +1,353 @@
+package statesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/Finschia/ostracon/libs/service"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	"github.com/Finschia/ostracon/proxy"
+	"github.com/Finschia/ostracon/types"
+)
+
+const (
+	snapshotterSubscriber = "Snapshotter"
+	// the file of the metadata of an archived snapshot, next to its chunks
+	snapshotMetadataFile = "snapshot"
+	// the prefix of the directories of the snapshots being archived
+	tmpSnapshotDirPrefix = "tmp-"
+)
+
+// SnapshotProgress is the progress of the snapshot being archived by a
+// Snapshotter.
+type SnapshotProgress struct {
+	Height         uint64
+	Format         uint32
+	Chunks         uint32
+	ChunksArchived uint32
+}
+
+// Snapshotter archives the snapshots of the app every interval heights in a
+// directory, keeping the most recent ones, so that the node serves them to the
+// peers state syncing regardless of the snapshots the app still has (see
+// Reactor.SetSnapshotter).
+//
+// At each interval height, the latest snapshot of the app newer than the last
+// archived one is loaded chunk by chunk with the ABCI ListSnapshots and
+// LoadSnapshotChunk. As the apps usually take the snapshots asynchronously,
+// the snapshotter tries again on the next blocks until the app has one, or the
+// next interval height is reached.
+type Snapshotter struct {
+	service.BaseService
+
+	dir        string
+	interval   uint64
+	keepRecent uint32
+	conn       proxy.AppConnSnapshot
+	eventBus   *types.EventBus
+
+	// signaled on each block while a snapshot is pending
+	wake chan struct{}
+
+	mtx       tmsync.RWMutex
+	pending   uint64           // the interval height of the pending snapshot, if any
+	snapshots []*abci.Snapshot // archived, the most recent first
+	progress  *SnapshotProgress
+}
+
+// NewSnapshotter returns a Snapshotter archiving the snapshots of the app in
+// dir every interval heights, keeping the keepRecent most recent ones (or all
+// of them if 0).
+func NewSnapshotter(
+	dir string,
+	interval uint64,
+	keepRecent uint32,
+	conn proxy.AppConnSnapshot,
+	eventBus *types.EventBus,
+) *Snapshotter {
+	s := &Snapshotter{
+		dir:        dir,
+		interval:   interval,
+		keepRecent: keepRecent,
+		conn:       conn,
+		eventBus:   eventBus,
+		wake:       make(chan struct{}, 1),
+	}
+	s.BaseService = *service.NewBaseService(nil, "Snapshotter", s)
+	return s
+}
+
+// OnStart implements service.Service by loading the archived snapshots, and
+// subscribing to the new blocks.
+func (s *Snapshotter) OnStart() error {
+	if err := s.loadSnapshots(); err != nil {
+		return fmt.Errorf("failed to load the snapshots of %s: %w", s.dir, err)
+	}
+
+	sub, err := s.eventBus.SubscribeUnbuffered(context.Background(), snapshotterSubscriber,
+		types.EventQueryNewBlockHeader)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case msg := <-sub.Out():
+				s.onBlock(uint64(msg.Data().(types.EventDataNewBlockHeader).Header.Height))
+			case <-sub.Cancelled():
+				return
+			case <-s.Quit():
+				return
+			}
+		}
+	}()
+	go s.archiveRoutine()
+	return nil
+}
+
+// OnStop implements service.Service by unsubscribing from the new blocks.
+func (s *Snapshotter) OnStop() {
+	if s.eventBus.IsRunning() {
+		_ = s.eventBus.UnsubscribeAll(context.Background(), snapshotterSubscriber)
+	}
+}
+
+// Snapshots returns the archived snapshots, the most recent first.
+func (s *Snapshotter) Snapshots() []*abci.Snapshot {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return append([]*abci.Snapshot(nil), s.snapshots...)
+}
+
+// Progress returns the progress of the snapshot being archived, or nil if
+// none is.
+func (s *Snapshotter) Progress() *SnapshotProgress {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.progress == nil {
+		return nil
+	}
+	progress := *s.progress
+	return &progress
+}
+
+// LoadChunk returns the chunk of an archived snapshot, or nil if the snapshot
+// isn't archived.
+func (s *Snapshotter) LoadChunk(height uint64, format, index uint32) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, snapshot := range s.snapshots {
+		if snapshot.Height == height && snapshot.Format == format {
+			if index >= snapshot.Chunks {
+				return nil, nil
+			}
+			return os.ReadFile(filepath.Join(s.snapshotDir(height, format), strconv.FormatUint(uint64(index), 10)))
+		}
+	}
+	return nil, nil
+}
+
+func (s *Snapshotter) onBlock(height uint64) {
+	s.mtx.Lock()
+	if height%s.interval == 0 {
+		if s.pending != 0 {
+			s.Logger.Info("Giving up the snapshot, the app has none since the last one",
+				"height", s.pending)
+		}
+		s.pending = height
+	}
+	pending := s.pending != 0
+	s.mtx.Unlock()
+
+	if pending {
+		select {
+		case s.wake <- struct{}{}:
+		default: // already signaled
+		}
+	}
+}
+
+func (s *Snapshotter) archiveRoutine() {
+	for {
+		select {
+		case <-s.wake:
+			s.mtx.RLock()
+			height := s.pending
+			s.mtx.RUnlock()
+			if height == 0 {
+				continue
+			}
+			done, err := s.archive()
+			if err != nil {
+				s.Logger.Error("Failed to archive the snapshot", "height", height, "err", err)
+			}
+			if done || err != nil {
+				s.mtx.Lock()
+				if s.pending == height {
+					s.pending = 0
+				}
+				s.mtx.Unlock()
+			}
+		case <-s.Quit():
+			return
+		}
+	}
+}
+
+// archive archives the latest snapshot of the app newer than the last archived
+// one, returning false if the app has none.
+func (s *Snapshotter) archive() (bool, error) {
+	resp, err := s.conn.ListSnapshotsSync(abci.RequestListSnapshots{})
+	if err != nil {
+		return false, err
+	}
+	var latest *abci.Snapshot
+	for _, snapshot := range resp.Snapshots {
+		if latest == nil || snapshot.Height > latest.Height ||
+			(snapshot.Height == latest.Height && snapshot.Format > latest.Format) {
+			latest = snapshot
+		}
+	}
+	s.mtx.RLock()
+	var last uint64
+	if len(s.snapshots) > 0 {
+		last = s.snapshots[0].Height
+	}
+	s.mtx.RUnlock()
+	if latest == nil || latest.Height <= last {
+		return false, nil
+	}
+
+	s.Logger.Info("Archiving snapshot", "height", latest.Height, "format", latest.Format,
+		"chunks", latest.Chunks)
+	s.setProgress(&SnapshotProgress{Height: latest.Height, Format: latest.Format, Chunks: latest.Chunks})
+	defer s.setProgress(nil)
+
+	tmpDir := filepath.Join(s.dir, tmpSnapshotDirPrefix+snapshotDirName(latest.Height, latest.Format))
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(tmpDir, 0o700); err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for index := uint32(0); index < latest.Chunks; index++ {
+		if !s.IsRunning() {
+			return false, errors.New("snapshotter stopped")
+		}
+		resp, err := s.conn.LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
+			Height: latest.Height,
+			Format: latest.Format,
+			Chunk:  index,
+		})
+		if err != nil {
+			return false, err
+		}
+		if resp.Chunk == nil {
+			return false, fmt.Errorf("the app doesn't have the chunk %d anymore", index)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, strconv.FormatUint(uint64(index), 10)),
+			resp.Chunk, 0o600); err != nil {
+			return false, err
+		}
+		s.mtx.Lock()
+		s.progress.ChunksArchived = index + 1
+		s.mtx.Unlock()
+	}
+	bz, err := proto.Marshal(latest)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, snapshotMetadataFile), bz, 0o600); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmpDir, s.snapshotDir(latest.Height, latest.Format)); err != nil {
+		return false, err
+	}
+
+	s.mtx.Lock()
+	s.snapshots = append([]*abci.Snapshot{latest}, s.snapshots...)
+	var pruned []*abci.Snapshot
+	if s.keepRecent > 0 && len(s.snapshots) > int(s.keepRecent) {
+		pruned = s.snapshots[s.keepRecent:]
+		s.snapshots = s.snapshots[:s.keepRecent]
+	}
+	s.mtx.Unlock()
+	s.Logger.Info("Archived snapshot", "height", latest.Height, "format", latest.Format)
+
+	for _, snapshot := range pruned {
+		if err := os.RemoveAll(s.snapshotDir(snapshot.Height, snapshot.Format)); err != nil {
+			s.Logger.Error("Failed to prune the snapshot", "height", snapshot.Height,
+				"format", snapshot.Format, "err", err)
+		}
+	}
+	return true, nil
+}
+
+func (s *Snapshotter) setProgress(progress *SnapshotProgress) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.progress = progress
+}
+
+// loadSnapshots loads the metadata of the snapshots archived in the directory,
+// removing the ones being archived when the node stopped.
+func (s *Snapshotter) loadSnapshots() error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	snapshots := make([]*abci.Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), tmpSnapshotDirPrefix) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			continue
+		}
+		bz, err := os.ReadFile(filepath.Join(path, snapshotMetadataFile))
+		if err != nil {
+			return err
+		}
+		snapshot := &abci.Snapshot{}
+		if err := proto.Unmarshal(bz, snapshot); err != nil {
+			return fmt.Errorf("invalid snapshot %s: %w", entry.Name(), err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		return a.Height > b.Height || (a.Height == b.Height && a.Format > b.Format)
+	})
+
+	s.mtx.Lock()
+	s.snapshots = snapshots
+	s.mtx.Unlock()
+	return nil
+}
+
+func (s *Snapshotter) snapshotDir(height uint64, format uint32) string {
+	return filepath.Join(s.dir, snapshotDirName(height, format))
+}
+
+func snapshotDirName(height uint64, format uint32) string {
+	return fmt.Sprintf("%d-%d", height, format)
+}
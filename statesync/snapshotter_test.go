@@ -0,0 +1,130 @@
+package statesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/Finschia/ostracon/libs/log"
+	tmsync "github.com/Finschia/ostracon/libs/sync"
+	proxymocks "github.com/Finschia/ostracon/proxy/mocks"
+	"github.com/Finschia/ostracon/types"
+)
+
+// snapshotterApp is the app of the snapshotter tests, the chunks of its
+// snapshots being their height and index
+type snapshotterApp struct {
+	conn *proxymocks.AppConnSnapshot
+
+	mtx       tmsync.Mutex
+	snapshots []*abci.Snapshot
+}
+
+func (app *snapshotterApp) addSnapshot(snapshot *abci.Snapshot) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	app.snapshots = append(app.snapshots, snapshot)
+}
+
+func newSnapshotterApp() *snapshotterApp {
+	app := &snapshotterApp{conn: &proxymocks.AppConnSnapshot{}}
+	app.conn.On("ListSnapshotsSync", abci.RequestListSnapshots{}).Return(
+		func(abci.RequestListSnapshots) *abci.ResponseListSnapshots {
+			app.mtx.Lock()
+			defer app.mtx.Unlock()
+			return &abci.ResponseListSnapshots{Snapshots: app.snapshots}
+		}, nil)
+	app.conn.On("LoadSnapshotChunkSync", mock.Anything).Return(
+		func(req abci.RequestLoadSnapshotChunk) *abci.ResponseLoadSnapshotChunk {
+			app.mtx.Lock()
+			defer app.mtx.Unlock()
+			for _, s := range app.snapshots {
+				if s.Height == req.Height && s.Format == req.Format && req.Chunk < s.Chunks {
+					return &abci.ResponseLoadSnapshotChunk{Chunk: []byte{byte(req.Height), byte(req.Chunk)}}
+				}
+			}
+			return &abci.ResponseLoadSnapshotChunk{}
+		}, nil)
+	return app
+}
+
+func newTestSnapshotter(t *testing.T, dir string, app *snapshotterApp, keepRecent uint32) (*Snapshotter, *types.EventBus) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	t.Cleanup(func() { _ = eventBus.Stop() })
+
+	s := NewSnapshotter(dir, 3, keepRecent, app.conn, eventBus)
+	s.SetLogger(log.TestingLogger())
+	require.NoError(t, s.Start())
+	t.Cleanup(func() {
+		if s.IsRunning() {
+			_ = s.Stop()
+		}
+	})
+	return s, eventBus
+}
+
+func publishBlock(t *testing.T, eventBus *types.EventBus, height int64) {
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+	}))
+}
+
+func TestSnapshotter(t *testing.T) {
+	dir := t.TempDir()
+	app := newSnapshotterApp()
+	s, eventBus := newTestSnapshotter(t, dir, app, 2)
+
+	// the app has no snapshot at the interval height yet, it's archived on
+	// the next block once the app has it
+	publishBlock(t, eventBus, 3)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, s.Snapshots())
+	app.addSnapshot(&abci.Snapshot{Height: 3, Format: 1, Chunks: 2, Hash: []byte{3}})
+	publishBlock(t, eventBus, 4)
+	require.Eventually(t, func() bool { return len(s.Snapshots()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Nil(t, s.Progress())
+
+	chunk, err := s.LoadChunk(3, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 1}, chunk)
+	chunk, err = s.LoadChunk(3, 1, 2)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+	chunk, err = s.LoadChunk(4, 1, 0)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+
+	// the oldest snapshots are pruned, even if the app still has them
+	for _, height := range []uint64{6, 9} {
+		app.addSnapshot(&abci.Snapshot{Height: height, Format: 1, Chunks: 2})
+		publishBlock(t, eventBus, int64(height))
+		require.Eventually(t, func() bool { return s.Snapshots()[0].Height == height },
+			time.Second, 10*time.Millisecond)
+	}
+	snapshots := s.Snapshots()
+	require.Len(t, snapshots, 2)
+	assert.EqualValues(t, 9, snapshots[0].Height)
+	assert.EqualValues(t, 6, snapshots[1].Height)
+	_, err = os.Stat(s.snapshotDir(3, 1))
+	assert.True(t, os.IsNotExist(err))
+
+	// the snapshots are loaded on restart, and the ones being archived removed
+	require.NoError(t, s.Stop())
+	tmpDir := filepath.Join(dir, tmpSnapshotDirPrefix+snapshotDirName(12, 1))
+	require.NoError(t, os.Mkdir(tmpDir, 0o700))
+	restarted, _ := newTestSnapshotter(t, dir, app, 2)
+	assert.Equal(t, snapshots, restarted.Snapshots())
+	_, err = os.Stat(tmpDir)
+	assert.True(t, os.IsNotExist(err))
+	chunk, err = restarted.LoadChunk(9, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{9, 0}, chunk)
+}
@@ -42,15 +42,18 @@ type lightClientStateProvider struct {
 	lc            *light.Client
 	version       tmstate.Version
 	initialHeight int64
+	blockPartSize uint32 // of the genesis, zero for the default
 	providers     map[lightprovider.Provider]string
 }
 
 // NewLightClientStateProvider creates a new StateProvider using a light client and RPC clients.
+// blockPartSize is the types.GenesisDoc.BlockPartSizeBytes of the chain.
 func NewLightClientStateProvider(
 	ctx context.Context,
 	chainID string,
 	version tmstate.Version,
 	initialHeight int64,
+	blockPartSize uint32,
 	servers []string,
 	trustOptions light.TrustOptions,
 	logger log.Logger,
@@ -83,6 +86,7 @@ func NewLightClientStateProvider(
 		lc:            lc,
 		version:       version,
 		initialHeight: initialHeight,
+		blockPartSize: blockPartSize,
 		providers:     providerRemotes,
 	}, nil
 }
@@ -148,6 +152,8 @@ func (s *lightClientStateProvider) State(ctx context.Context, height uint64) (sm
 		ChainID:       s.lc.ChainID(),
 		Version:       s.version,
 		InitialHeight: s.initialHeight,
+
+		BlockPartSizeBytes: s.blockPartSize,
 	}
 	if state.InitialHeight == 0 {
 		state.InitialHeight = 1
@@ -197,7 +203,7 @@ func (s *lightClientStateProvider) State(ctx context.Context, height uint64) (sm
 	if err != nil {
 		return sm.State{}, fmt.Errorf("unable to create RPC client: %w", err)
 	}
-	rpcclient := lightrpc.NewClient(primaryRPC, s.lc)
+	rpcclient := lightrpc.NewClient(primaryRPC, s.lc, lightrpc.BlockPartSize(s.blockPartSize))
 
 	resultConsensusParams, err := rpcclient.ConsensusParams(ctx, &currentLightBlock.Height)
 	if err != nil {
@@ -2,6 +2,7 @@ package statesync
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
@@ -17,6 +18,9 @@ import (
 	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
 
 	"github.com/Finschia/ostracon/config"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	tmbytes "github.com/Finschia/ostracon/libs/bytes"
+	tmjson "github.com/Finschia/ostracon/libs/json"
 	"github.com/Finschia/ostracon/libs/log"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 	"github.com/Finschia/ostracon/light"
@@ -102,6 +106,7 @@ func TestNewLightClientStateProvider(t *testing.T) {
 				tt.args.chainID,
 				tt.args.version,
 				tt.args.initialHeight,
+				0,
 				tt.args.servers,
 				tt.args.trustOptions,
 				tt.args.logger)
@@ -113,6 +118,78 @@ func TestNewLightClientStateProvider(t *testing.T) {
 	}
 }
 
+// TestLightClientStateProviderBlockPartSize checks that the state of a chain
+// whose genesis sets the block part size is built with it, which the block
+// fetched to get the last proof hash is verified against.
+func TestLightClientStateProviderBlockPartSize(t *testing.T) {
+	const partSize = 2 * types.BlockPartSizeBytes
+	chainID := fmt.Sprintf("test-chain-%v", tmrand.Str(6))
+	params := types.DefaultConsensusParams()
+	val, privVal := types.RandValidator(false, 10)
+	valSet := types.NewValidatorSet([]*types.Validator{val})
+
+	// a tx bigger than the default part size, so that the part set headers of
+	// the blocks depend on the part size
+	txs := types.Txs{tmrand.Bytes(int(types.BlockPartSizeBytes) + 1)}
+	blocks := make(map[int64]*ctypes.ResultBlock)
+	lastCommit, lastBlockID := &types.Commit{}, types.BlockID{}
+	now := tmtime.Now()
+	for h := int64(1); h <= 3; h++ {
+		block := types.MakeBlock(h, txs, lastCommit, nil, tmversion.Consensus{Block: version.BlockProtocol})
+		block.ChainID = chainID
+		block.Time = now.Add(time.Duration(h-4) * time.Minute)
+		block.LastBlockID = lastBlockID
+		block.ValidatorsHash = valSet.Hash()
+		block.NextValidatorsHash = valSet.Hash()
+		block.ConsensusHash = types.HashConsensusParams(*params)
+		block.ProposerAddress = val.Address
+		proof, err := ed25519.GenPrivKey().VRFProve(tmrand.Bytes(10))
+		require.NoError(t, err)
+		block.Entropy = types.Entropy{Proof: tmbytes.HexBytes(proof)}
+
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: block.MakePartSet(partSize).Header()}
+		voteSet := types.NewVoteSet(chainID, h, 0, tmproto.PrecommitType, valSet)
+		lastCommit, err = types.MakeCommit(blockID, h, 0, voteSet, []types.PrivValidator{privVal}, block.Time)
+		require.NoError(t, err)
+		lastBlockID = blockID
+		blocks[h] = &ctypes.ResultBlock{BlockID: blockID, Block: block}
+	}
+	commits := map[int64]*types.Commit{1: blocks[2].Block.LastCommit, 2: blocks[3].Block.LastCommit, 3: lastCommit}
+
+	routes := map[string]*rpcserver.RPCFunc{
+		"block": rpcserver.NewRPCFunc(func(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
+			return blocks[*heightPtr], nil
+		}, "height"),
+		"commit": rpcserver.NewRPCFunc(func(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
+			return ctypes.NewResultCommit(&blocks[*heightPtr].Block.Header, commits[*heightPtr], true), nil
+		}, "height"),
+		"validators": rpcserver.NewRPCFunc(func(ctx *rpctypes.Context, heightPtr *int64, pagePtr, perPagePtr *int,
+		) (*ctypes.ResultValidators, error) {
+			return &ctypes.ResultValidators{BlockHeight: *heightPtr, Validators: valSet.Validators, Count: 1, Total: 1}, nil
+		}, "height,page,per_page"),
+		"consensus_params": rpcserver.NewRPCFunc(func(ctx *rpctypes.Context, heightPtr *int64,
+		) (*ctypes.ResultConsensusParams, error) {
+			return &ctypes.ResultConsensusParams{BlockHeight: *heightPtr, ConsensusParams: *params}, nil
+		}, "height"),
+	}
+	listeners, servers := serveRPCServers(t, routes, 2)
+	defer closeListeners(t, listeners)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sp, err := NewLightClientStateProvider(ctx, chainID, state.Version{}, 1, partSize, servers,
+		light.TrustOptions{Period: time.Hour, Height: 1, Hash: blocks[1].Block.Hash()}, log.TestingLogger())
+	require.NoError(t, err)
+
+	st, err := sp.State(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, partSize, st.BlockPartSizeBytes)
+	assert.Equal(t, blocks[1].BlockID, st.LastBlockID)
+	proofHash, err := ed25519.ProofToHash(blocks[1].Block.Proof)
+	require.NoError(t, err)
+	assert.Equal(t, proofHash, st.LastProofHash)
+}
+
 const (
 	height = int64(1)
 	round  = int32(0)
@@ -201,6 +278,16 @@ func setupVars(t *testing.T) {
 
 func serveTestRPCServers(t *testing.T, config *config.Config, num int,
 ) (listeners []*net.Listener, servers []string, closeListenersFunc func(listeners []*net.Listener)) {
+	listeners, servers = serveRPCServers(t, routes, num)
+	closeListenersFunc = func(listeners []*net.Listener) {
+		closeListeners(t, listeners)
+	}
+	return listeners, servers, closeListenersFunc
+}
+
+// serveRPCServers starts num RPC servers serving the routes.
+func serveRPCServers(t *testing.T, routes map[string]*rpcserver.RPCFunc, num int,
+) (listeners []*net.Listener, servers []string) {
 	// Start the RPC server
 	mux := http.NewServeMux()
 	rpcserver.RegisterRPCFuncs(mux, routes, log.TestingLogger())
@@ -218,24 +305,43 @@ func serveTestRPCServers(t *testing.T, config *config.Config, num int,
 			_ = rpcserver.Serve(listener, mux, log.NewNopLogger(), rpcConfig)
 		}()
 	}
-	closeListenersFunc = func(listeners []*net.Listener) {
-		for _, listener := range listeners {
-			require.NoError(t, (*listener).Close())
-		}
+	return listeners, servers
+}
+
+func closeListeners(t *testing.T, listeners []*net.Listener) {
+	for _, listener := range listeners {
+		require.NoError(t, (*listener).Close())
 	}
-	return listeners, servers, closeListenersFunc
 }
 
 var routes = map[string]*rpcserver.RPCFunc{
-	"genesis":    rpcserver.NewRPCFunc(genesisFunc, ""),
-	"commit":     rpcserver.NewRPCFunc(commitFunc, "height"),
-	"validators": rpcserver.NewRPCFunc(validatorsFunc, "height,page,per_page"),
+	"genesis":         rpcserver.NewRPCFunc(genesisFunc, ""),
+	"genesis_chunked": rpcserver.NewRPCFunc(genesisChunkedFunc, "chunk"),
+	"commit":          rpcserver.NewRPCFunc(commitFunc, "height"),
+	"validators":      rpcserver.NewRPCFunc(validatorsFunc, "height,page,per_page"),
 }
 
 func genesisFunc(ctx *rpctypes.Context) (*ctypes.ResultGenesis, error) {
 	return &ctypes.ResultGenesis{Genesis: genDoc}, nil
 }
 
+// genesisChunkedFunc serves the genesis document in two chunks.
+func genesisChunkedFunc(ctx *rpctypes.Context, chunk uint) (*ctypes.ResultGenesisChunk, error) {
+	bz, err := tmjson.Marshal(genDoc)
+	if err != nil {
+		return nil, err
+	}
+	chunks := [][]byte{bz[:len(bz)/2], bz[len(bz)/2:]}
+	if chunk >= uint(len(chunks)) {
+		return nil, fmt.Errorf("there are %d chunks, %d is invalid", len(chunks), chunk)
+	}
+	return &ctypes.ResultGenesisChunk{
+		ChunkNumber: int(chunk),
+		TotalChunks: len(chunks),
+		Data:        base64.StdEncoding.EncodeToString(chunks[chunk]),
+	}, nil
+}
+
 func commitFunc(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
 	return ctypes.NewResultCommit(header, commit, true), nil
 }
@@ -57,22 +57,25 @@ type syncer struct {
 	conn          proxy.AppConnSnapshot
 	connQuery     proxy.AppConnQuery
 	snapshots     *snapshotPool
-	tempDir       string
+	chunkDir      string
 	chunkFetchers int32
 	retryTimeout  time.Duration
+	requests      *chunkRequests
 
 	mtx    tmsync.RWMutex
 	chunks *chunkQueue
 }
 
-// newSyncer creates a new syncer.
+// newSyncer creates a new syncer. The chunks of the snapshot being restored are
+// stored in chunkDir, to resume the restoration if the node restarts, or in a
+// temporary directory if it's empty.
 func newSyncer(
 	cfg config.StateSyncConfig,
 	logger log.Logger,
 	conn proxy.AppConnSnapshot,
 	connQuery proxy.AppConnQuery,
 	stateProvider StateProvider,
-	tempDir string,
+	chunkDir string,
 ) *syncer {
 
 	return &syncer{
@@ -81,9 +84,10 @@ func newSyncer(
 		conn:          conn,
 		connQuery:     connQuery,
 		snapshots:     newSnapshotPool(),
-		tempDir:       tempDir,
+		chunkDir:      chunkDir,
 		chunkFetchers: cfg.ChunkFetchers,
 		retryTimeout:  cfg.ChunkRequestTimeout,
+		requests:      newChunkRequests(),
 	}
 }
 
@@ -109,6 +113,23 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 	return added, nil
 }
 
+// ChunkMissing notes that the peer doesn't have the requested chunk, so that it's
+// requested from another peer without waiting for the retry timeout.
+func (s *syncer) ChunkMissing(peerID p2p.ID, height uint64, format uint32, index uint32) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.chunks == nil {
+		return
+	}
+	s.chunks.Lock()
+	snapshot := s.chunks.snapshot
+	s.chunks.Unlock()
+	if snapshot == nil || snapshot.Height != height || snapshot.Format != format {
+		return
+	}
+	s.requests.setMissing(index, peerID)
+}
+
 // AddSnapshot adds a snapshot to the snapshot pool. It returns true if a new, previously unseen
 // snapshot was accepted and added.
 func (s *syncer) AddSnapshot(peer p2p.Peer, snapshot *snapshot) (bool, error) {
@@ -160,11 +181,35 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 		chunks   *chunkQueue
 		err      error
 	)
+	// The restoration of a snapshot before the node restarted is resumed with
+	// the chunks already fetched, if the peers still have it or it's complete.
+	resumed, resumedChunks, err := s.resumableSnapshot()
+	if err != nil {
+		return sm.State{}, sm.State{}, nil, err
+	}
 	for {
 		// If not nil, we're going to retry restoration of the same snapshot.
 		if snapshot == nil {
-			snapshot = s.snapshots.Best()
 			chunks = nil
+			if resumed != nil && (len(s.snapshots.GetPeers(resumed)) > 0 ||
+				resumedChunks.Fetched() == resumed.Chunks) {
+				s.logger.Info("Resuming snapshot restoration", "height", resumed.Height,
+					"format", resumed.Format, "hash", resumed.Hash,
+					"fetched", resumedChunks.Fetched(), "total", resumed.Chunks)
+				snapshot, chunks = resumed, resumedChunks
+				resumed, resumedChunks = nil, nil
+				defer chunks.Close() // in case we forget to close it elsewhere
+			} else {
+				snapshot = s.snapshots.Best()
+			}
+		}
+		if snapshot != nil && resumed != nil {
+			s.logger.Info("Not resuming snapshot restoration, no peer has the snapshot",
+				"height", resumed.Height, "format", resumed.Format, "hash", resumed.Hash)
+			if err := resumedChunks.Close(); err != nil {
+				s.logger.Error("Failed to clean up chunk queue", "err", err)
+			}
+			resumed, resumedChunks = nil, nil
 		}
 		if snapshot == nil {
 			if discoveryTime == 0 {
@@ -176,7 +221,11 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 			continue
 		}
 		if chunks == nil {
-			chunks, err = newChunkQueue(snapshot, s.tempDir)
+			if s.chunkDir != "" {
+				chunks, err = openChunkQueue(snapshot, s.chunkDir)
+			} else {
+				chunks, err = newChunkQueue(snapshot, "")
+			}
 			if err != nil {
 				return sm.State{}, sm.State{}, nil, fmt.Errorf("failed to create chunk queue: %w", err)
 			}
@@ -237,6 +286,23 @@ func (s *syncer) SyncAny(discoveryTime time.Duration, retryHook func()) (sm.Stat
 	}
 }
 
+// resumableSnapshot returns the snapshot of the chunk queue of chunkDir, and the
+// queue, if any.
+func (s *syncer) resumableSnapshot() (*snapshot, *chunkQueue, error) {
+	if s.chunkDir == "" {
+		return nil, nil, nil
+	}
+	snapshot, err := loadChunkQueueSnapshot(s.chunkDir)
+	if err != nil || snapshot == nil {
+		return nil, nil, err
+	}
+	chunks, err := openChunkQueue(snapshot, s.chunkDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open chunk queue: %w", err)
+	}
+	return snapshot, chunks, nil
+}
+
 // Sync executes a sync for a specific snapshot, returning the latest state, previous state and block commit which
 // the caller must use to bootstrap the node.
 func (s *syncer) Sync(snapshot *snapshot, chunks *chunkQueue) (sm.State, sm.State, *types.Commit, error) {
@@ -455,33 +521,42 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 		s.logger.Info("Fetching snapshot chunk", "height", snapshot.Height,
 			"format", snapshot.Format, "chunk", index, "total", chunks.Size())
 
-		ticker := time.NewTicker(s.retryTimeout)
-		defer ticker.Stop()
-
-		s.requestChunk(snapshot, index)
+		timer := time.NewTimer(s.retryTimeout)
+		missing := s.requestChunk(snapshot, index)
 
 		select {
 		case <-chunks.WaitFor(index):
+			s.requests.forget(index)
 			next = true
 
-		case <-ticker.C:
+		case <-missing:
+			s.logger.Debug("Peer doesn't have the snapshot chunk, requesting it from another peer",
+				"height", snapshot.Height, "format", snapshot.Format, "chunk", index)
+			next = false
+
+		case <-timer.C:
 			next = false
 
 		case <-ctx.Done():
+			timer.Stop()
+			s.requests.done(index)
 			return
 		}
 
-		ticker.Stop()
+		timer.Stop()
+		s.requests.done(index)
 	}
 }
 
-// requestChunk requests a chunk from a peer.
-func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
-	peer := s.snapshots.GetPeer(snapshot)
+// requestChunk requests a chunk from a peer, another one than the previous
+// requests of the chunk, if any, and the least loaded. It returns a channel
+// closed if the peer reports the chunk missing, see chunkRequests.request.
+func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) <-chan struct{} {
+	peer, missing := s.requests.request(chunk, s.snapshots.GetPeers(snapshot))
 	if peer == nil {
 		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
 			"format", snapshot.Format, "hash", snapshot.Hash)
-		return
+		return nil
 	}
 	s.logger.Debug("Requesting snapshot chunk", "height", snapshot.Height,
 		"format", snapshot.Format, "chunk", chunk, "peer", peer.ID())
@@ -493,6 +568,7 @@ func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
 			Index:  chunk,
 		},
 	}, s.logger)
+	return missing
 }
 
 // verifyApp verifies the sync, checking the app hash, last block height and app version
@@ -3,6 +3,7 @@ package statesync
 import (
 	"errors"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -247,6 +248,62 @@ func TestSyncer_SyncAny_noSnapshots(t *testing.T) {
 	assert.Equal(t, errNoSnapshots, err)
 }
 
+func TestSyncer_SyncAny_resume(t *testing.T) {
+	state := sm.State{
+		ChainID: "chain",
+		Version: tmstate.Version{
+			Consensus: tmversion.Consensus{Block: version.BlockProtocol, App: testAppVersion},
+		},
+		LastBlockHeight: 1,
+		AppHash:         []byte("app_hash"),
+	}
+	commit := &types.Commit{BlockID: types.BlockID{Hash: []byte("blockhash")}}
+	s := &snapshot{Height: 1, Format: 1, Chunks: 2, Hash: []byte{1, 2, 3}}
+
+	// the chunks were fetched before the node restarted
+	dir := t.TempDir()
+	queue, err := openChunkQueue(s, dir)
+	require.NoError(t, err)
+	for i := uint32(0); i < s.Chunks; i++ {
+		_, err := queue.Add(&chunk{Height: 1, Format: 1, Index: i, Chunk: []byte{1, 1, byte(i)}})
+		require.NoError(t, err)
+	}
+
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("AppHash", mock.Anything, uint64(1)).Return(state.AppHash, nil)
+	stateProvider.On("Commit", mock.Anything, uint64(1)).Return(commit, nil)
+	stateProvider.On("State", mock.Anything, uint64(1)).Return(state, nil)
+	connSnapshot := &proxymocks.AppConnSnapshot{}
+	connQuery := &proxymocks.AppConnQuery{}
+	connSnapshot.On("OfferSnapshotSync", abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Once().Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+	for i := byte(0); i < 2; i++ {
+		connSnapshot.On("ApplySnapshotChunkSync", abci.RequestApplySnapshotChunk{
+			Index: uint32(i), Chunk: []byte{1, 1, i},
+		}).Once().Return(&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	}
+	connQuery.On("InfoSync", proxy.RequestInfo).Return(&abci.ResponseInfo{
+		AppVersion:       testAppVersion,
+		LastBlockHeight:  1,
+		LastBlockAppHash: []byte("app_hash"),
+	}, nil)
+
+	// the snapshot is restored without any peer
+	syncer := newSyncer(*config.DefaultStateSyncConfig(), log.NewNopLogger(), connSnapshot, connQuery,
+		stateProvider, dir)
+	newState, _, lastCommit, err := syncer.SyncAny(0, func() {})
+	require.NoError(t, err)
+	assert.Equal(t, state, newState)
+	assert.Equal(t, commit, lastCommit)
+	connSnapshot.AssertExpectations(t)
+
+	// and the chunks removed
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
 func TestSyncer_SyncAny_abort(t *testing.T) {
 	syncer, connSnapshot := setupOfferSyncer(t)
 
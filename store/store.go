@@ -43,6 +43,10 @@ type BlockStore struct {
 	mtx    tmsync.RWMutex
 	base   int64
 	height int64
+
+	// serializes the saves of the base and height, which are updated
+	// concurrently by the pruning in the background
+	saveMtx tmsync.Mutex
 }
 
 // NewBlockStore returns a new BlockStore with the given DB,
@@ -324,6 +328,67 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 	return pruned, nil
 }
 
+// DeleteLatestBlock removes the block at the latest height, e.g. when rolling
+// back the node. The commit of the previous block, which was saved with the
+// latest block, is removed too, while the seen commit of the previous block is
+// kept.
+func (bs *BlockStore) DeleteLatestBlock() error {
+	bs.mtx.RLock()
+	base, height := bs.base, bs.height
+	bs.mtx.RUnlock()
+	if height == 0 {
+		return fmt.Errorf("no blocks to delete")
+	}
+	if height == base {
+		return fmt.Errorf("cannot delete the only block at height %v", height)
+	}
+	meta := bs.LoadBlockMeta(height)
+	if meta == nil {
+		return fmt.Errorf("block at height %v not found", height)
+	}
+
+	batch := bs.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Delete(calcBlockMetaKey(height)); err != nil {
+		return err
+	}
+	if err := batch.Delete(calcBlockHashKey(meta.BlockID.Hash)); err != nil {
+		return err
+	}
+	if err := batch.Delete(calcBlockCommitKey(height - 1)); err != nil {
+		return err
+	}
+	if err := batch.Delete(calcSeenCommitKey(height)); err != nil {
+		return err
+	}
+	for p := 0; p < int(meta.BlockID.PartSetHeader.Total); p++ {
+		if err := batch.Delete(calcBlockPartKey(height, p)); err != nil {
+			return err
+		}
+	}
+
+	// The new height is written in the same batch as the deletions, and only
+	// applied once the batch is written, so that the store is left as it was,
+	// and the deletion can be retried, if the write fails.
+	bs.saveMtx.Lock()
+	defer bs.saveMtx.Unlock()
+	bz, err := proto.Marshal(&tmstore.BlockStoreState{Base: bs.Base(), Height: height - 1})
+	if err != nil {
+		return fmt.Errorf("could not marshal state bytes: %w", err)
+	}
+	if err := batch.Set(blockStoreKey, bz); err != nil {
+		return err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return fmt.Errorf("failed to delete block at height %v: %w", height, err)
+	}
+
+	bs.mtx.Lock()
+	bs.height = height - 1
+	bs.mtx.Unlock()
+	return nil
+}
+
 // SaveBlock persists the given block, blockParts, and seenCommit to the underlying db.
 // blockParts: Must be parts of the block
 // seenCommit: The +2/3 precommits that were seen which committed at height.
@@ -408,6 +473,8 @@ func (bs *BlockStore) saveBlockPart(height int64, index int, part *types.Part) {
 }
 
 func (bs *BlockStore) saveState() {
+	bs.saveMtx.Lock()
+	defer bs.saveMtx.Unlock()
 	bs.mtx.RLock()
 	bss := tmstore.BlockStoreState{
 		Base:   bs.base,
@@ -2,6 +2,7 @@ package store
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -523,6 +524,104 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestDeleteLatestBlock(t *testing.T) {
+	config := cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	state, err := stateStore.LoadFromDBOrGenesisFile(config.GenesisFile())
+	require.NoError(t, err)
+	db := dbm.NewMemDB()
+	bs := NewBlockStore(db)
+
+	// deleting from an empty store should error
+	require.Error(t, bs.DeleteLatestBlock())
+
+	for h := int64(1); h <= 3; h++ {
+		block := makeBlock(h, state, new(types.Commit))
+		partSet := block.MakePartSet(2)
+		seenCommit := makeTestCommit(h, tmtime.Now())
+		bs.SaveBlock(block, partSet, seenCommit)
+	}
+	latestBlock := bs.LoadBlock(3)
+
+	require.NoError(t, bs.DeleteLatestBlock())
+	assert.EqualValues(t, 1, bs.Base())
+	assert.EqualValues(t, 2, bs.Height())
+	assert.EqualValues(t, tmstore.BlockStoreState{
+		Base:   1,
+		Height: 2,
+	}, LoadBlockStoreState(db))
+
+	require.Nil(t, bs.LoadBlock(3))
+	require.Nil(t, bs.LoadBlockByHash(latestBlock.Hash()))
+	require.Nil(t, bs.LoadBlockMeta(3))
+	require.Nil(t, bs.LoadBlockPart(3, 0))
+	require.Nil(t, bs.LoadSeenCommit(3))
+	require.Nil(t, bs.LoadBlockCommit(2))
+	require.NotNil(t, bs.LoadBlock(2))
+	require.NotNil(t, bs.LoadSeenCommit(2))
+
+	// the deleted block can be saved again
+	bs.SaveBlock(latestBlock, latestBlock.MakePartSet(2), makeTestCommit(3, tmtime.Now()))
+	assert.EqualValues(t, 3, bs.Height())
+	require.NotNil(t, bs.LoadBlock(3))
+
+	require.NoError(t, bs.DeleteLatestBlock())
+	require.NoError(t, bs.DeleteLatestBlock())
+	// the base block can't be deleted
+	require.Error(t, bs.DeleteLatestBlock())
+	assert.EqualValues(t, 1, bs.Height())
+}
+
+// failingBatchDB is a DB whose batches fail to be written.
+type failingBatchDB struct {
+	dbm.DB
+}
+
+func (db failingBatchDB) NewBatch() dbm.Batch {
+	return failingBatch{db.DB.NewBatch()}
+}
+
+type failingBatch struct {
+	dbm.Batch
+}
+
+func (b failingBatch) WriteSync() error {
+	return errors.New("write failed")
+}
+
+func TestDeleteLatestBlockWriteFailure(t *testing.T) {
+	config := cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	stateStore := sm.NewStore(dbm.NewMemDB(), sm.StoreOptions{
+		DiscardABCIResponses: false,
+	})
+	state, err := stateStore.LoadFromDBOrGenesisFile(config.GenesisFile())
+	require.NoError(t, err)
+	db := dbm.NewMemDB()
+	bs := NewBlockStore(db)
+	for h := int64(1); h <= 2; h++ {
+		block := makeBlock(h, state, new(types.Commit))
+		bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(h, tmtime.Now()))
+	}
+
+	// the store is left as it was
+	bs.db = failingBatchDB{db}
+	require.Error(t, bs.DeleteLatestBlock())
+	assert.EqualValues(t, 2, bs.Height())
+	assert.EqualValues(t, tmstore.BlockStoreState{Base: 1, Height: 2}, LoadBlockStoreState(db))
+	require.NotNil(t, bs.LoadBlock(2))
+
+	// and the deletion can be retried
+	bs.db = db
+	require.NoError(t, bs.DeleteLatestBlock())
+	assert.EqualValues(t, 1, bs.Height())
+	assert.EqualValues(t, tmstore.BlockStoreState{Base: 1, Height: 1}, LoadBlockStoreState(db))
+	require.Nil(t, bs.LoadBlock(2))
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
@@ -156,6 +156,28 @@ func (app *Application) CheckTx(req abci.RequestCheckTx) ocabci.ResponseCheckTx
 	return ocabci.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: 1}
 }
 
+// PrepareProposal implements ABCI.
+func (app *Application) PrepareProposal(req ocabci.RequestPrepareProposal) ocabci.ResponsePrepareProposal {
+	if app.cfg.PrepareProposalDelay != 0 {
+		time.Sleep(app.cfg.PrepareProposalDelay)
+	}
+	return ocabci.ResponsePrepareProposal{Txs: req.Txs}
+}
+
+// ProcessProposal implements ABCI, rejecting the blocks with txs DeliverTx
+// couldn't parse.
+func (app *Application) ProcessProposal(req ocabci.RequestProcessProposal) ocabci.ResponseProcessProposal {
+	if app.cfg.ProcessProposalDelay != 0 {
+		time.Sleep(app.cfg.ProcessProposalDelay)
+	}
+	for _, tx := range req.Txs {
+		if _, _, err := parseTx(tx); err != nil {
+			return ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_REJECT}
+		}
+	}
+	return ocabci.ResponseProcessProposal{Status: ocabci.ResponseProcessProposal_ACCEPT}
+}
+
 // DeliverTx implements ABCI.
 func (app *Application) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
 	key, value, err := parseTx(req.Tx)
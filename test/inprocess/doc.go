@@ -0,0 +1,52 @@
+/*
+Package inprocess runs a network of full Ostracon nodes in a single process,
+for fast integration tests of applications.
+
+The nodes are connected to each other with in-memory pipes: they don't dial
+each other and the PEX reactor is disabled, so the connections are entirely
+controlled by the Network. The network can be partitioned and healed:
+
+	network, err := inprocess.NewNetwork(inprocess.Config{
+		NumValidators: 4,
+		NewApp:        func(i int) abci.Application { return kvstore.NewApplication() },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer network.Cleanup()
+	if err := network.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer network.Stop() //nolint:errcheck
+
+	network.Partition([]int{0, 1}, []int{2, 3})
+	...
+	network.Heal()
+
+With Config.ManualClock, the consensus timeouts only fire when the clock of
+the nodes is advanced with Network.AdvanceTime, which makes the progress of the
+consensus deterministic. Note that the block times still come from the wall
+clock.
+
+The messages received by the nodes pass through the links of the network,
+which delay them by a latency and a random jitter, and drop them with a given
+probability (see LinkFaults), to exercise the rounds and timeouts of the
+consensus:
+
+	network.SetFaults(inprocess.LinkFaults{Latency: 10 * time.Millisecond, DropRate: 0.05})
+	network.SetLinkFaults(0, 1, inprocess.LinkFaults{DropRate: 1}) // 0 -> 1 only
+
+The random decisions of each link are drawn from Config.Seed (logged through
+Network.Seed if random), so that the same messages of a link are dropped and
+delayed alike with the same seed. The order in which the nodes send their
+messages still depends on the scheduling of the goroutines. As the reactors
+consider the messages sent to a connected peer received, a node may not
+recover from dropped messages until it is reconnected, e.g. with Partition and
+Heal.
+
+The reactors of every node can be accessed through the embedded node.Node. The
+RPC of the nodes is served in-process by Node.RPC: since the RPC environment is
+a package singleton, the calls to the RPC of the different nodes are
+serialized, and the nodes don't listen on an RPC address.
+*/
+package inprocess
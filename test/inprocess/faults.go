@@ -0,0 +1,211 @@
+package inprocess
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Finschia/ostracon/p2p"
+	sm "github.com/Finschia/ostracon/state"
+)
+
+// the number of delayed messages a link holds before blocking the sender
+const linkQueueSize = 1000
+
+// LinkFaults are the faults of the messages sent from a node to another.
+type LinkFaults struct {
+	// Latency of the messages, to which a random jitter of up to Jitter is
+	// added. The messages of a link are still received in order.
+	Latency time.Duration
+	Jitter  time.Duration
+	// DropRate is the probability of a message to be dropped, from 0 to 1.
+	DropRate float64
+}
+
+// link carries the messages received by a node from another one, applying the
+// faults of the link. Its random decisions are drawn from a source seeded with
+// the seed of the network and the indexes of the nodes.
+type link struct {
+	mtx     sync.Mutex
+	faults  LinkFaults
+	rand    *rand.Rand
+	pending int // number of messages in the queue
+
+	queue chan delayedMsg
+	quit  <-chan struct{}
+}
+
+type delayedMsg struct {
+	at      time.Time
+	deliver func() bool
+}
+
+func newLink(seed int64, from, to int, quit <-chan struct{}) *link {
+	l := &link{
+		rand:  rand.New(rand.NewSource(seed + int64(to)<<16 + int64(from))), //nolint:gosec
+		queue: make(chan delayedMsg, linkQueueSize),
+		quit:  quit,
+	}
+	go l.deliverRoutine()
+	return l
+}
+
+func (l *link) setFaults(faults LinkFaults) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.faults = faults
+}
+
+// receive delivers the message, after the latency of the link unless it is
+// dropped. It returns false if the message is dropped, or if it is delivered
+// right away and deliver returns false.
+func (l *link) receive(deliver func() bool) bool {
+	l.mtx.Lock()
+	faults := l.faults
+	if faults.DropRate > 0 && l.rand.Float64() < faults.DropRate {
+		l.mtx.Unlock()
+		return false
+	}
+	delay := faults.Latency
+	if faults.Jitter > 0 {
+		delay += time.Duration(l.rand.Int63n(int64(faults.Jitter) + 1))
+	}
+	if delay <= 0 && l.pending == 0 {
+		l.mtx.Unlock()
+		return deliver()
+	}
+	// queued behind the messages received before, if any
+	l.pending++
+	l.mtx.Unlock()
+
+	select {
+	case l.queue <- delayedMsg{at: time.Now().Add(delay), deliver: deliver}:
+	case <-l.quit:
+	}
+	return true
+}
+
+func (l *link) deliverRoutine() {
+	for {
+		select {
+		case msg := <-l.queue:
+			if wait := time.Until(msg.at); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-l.quit:
+					return
+				}
+			}
+			msg.deliver()
+			l.mtx.Lock()
+			l.pending--
+			l.mtx.Unlock()
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// faultyReactor wraps the reactor of a node to pass the messages it receives
+// through the links of the network.
+type faultyReactor struct {
+	p2p.Reactor
+
+	network *Network
+	to      int // index of the node
+}
+
+// faultyConsensusReactor is a faultyReactor of the consensus reactor, which the
+// blockchain reactors switch to once caught up.
+type faultyConsensusReactor struct {
+	*faultyReactor
+}
+
+func (r faultyConsensusReactor) SwitchToConsensus(state sm.State, skipWAL bool) {
+	r.Reactor.(interface {
+		SwitchToConsensus(state sm.State, skipWAL bool)
+	}).SwitchToConsensus(state, skipWAL)
+}
+
+// wrapReactors replaces the reactors of the switch of the i-th node with
+// faultyReactors. The switch must not be started.
+func (network *Network) wrapReactors(i int, sw *p2p.Switch) {
+	reactors := sw.Reactors()
+	names := make([]string, 0, len(reactors))
+	for name := range reactors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		reactor := reactors[name]
+		sw.RemoveReactor(name, reactor)
+		wrapped := &faultyReactor{Reactor: reactor, network: network, to: i}
+		if _, ok := reactor.(interface {
+			SwitchToConsensus(state sm.State, skipWAL bool)
+		}); ok {
+			sw.AddReactor(name, faultyConsensusReactor{wrapped})
+		} else {
+			sw.AddReactor(name, wrapped)
+		}
+	}
+}
+
+// receive passes the message of the peer through its link to the node,
+// delivering it to the reactor only if the peer is still connected.
+func (r *faultyReactor) receive(peer p2p.Peer, deliver func() bool) bool {
+	l := r.network.link(peer.ID(), r.to)
+	if l == nil {
+		return deliver()
+	}
+	return l.receive(func() bool {
+		return peer.IsRunning() && deliver()
+	})
+}
+
+// QueueMsg implements p2p.Reactor, for the async reactors.
+func (r *faultyReactor) QueueMsg(msg *p2p.BufferedMsg) bool {
+	return r.receive(msg.Peer, func() bool { return r.Reactor.QueueMsg(msg) })
+}
+
+// ReceiveEnvelope implements p2p.EnvelopeReceiver, as the BaseReactor of the
+// wrapped reactor does.
+func (r *faultyReactor) ReceiveEnvelope(e p2p.Envelope) {
+	r.receive(e.Src, func() bool {
+		r.Reactor.(p2p.EnvelopeReceiver).ReceiveEnvelope(e)
+		return true
+	})
+}
+
+// link returns the link of the messages from the peer to the to-th node, nil
+// if the peer isn't a node of the network.
+func (network *Network) link(from p2p.ID, to int) *link {
+	i, ok := network.indexes[from]
+	if !ok {
+		return nil
+	}
+	return network.links[i][to]
+}
+
+// SetFaults sets the faults of all the links of the network.
+func (network *Network) SetFaults(faults LinkFaults) {
+	for i := range network.links {
+		for j := range network.links[i] {
+			if i != j {
+				network.links[i][j].setFaults(faults)
+			}
+		}
+	}
+}
+
+// SetLinkFaults sets the faults of the messages sent by the from-th node to
+// the to-th one.
+func (network *Network) SetLinkFaults(from, to int, faults LinkFaults) {
+	network.links[from][to].setFaults(faults)
+}
+
+// Seed returns the seed of the random decisions of the links, to reproduce
+// the faults of a test.
+func (network *Network) Seed() int64 {
+	return network.seed
+}
@@ -0,0 +1,93 @@
+package inprocess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/abci/example/kvstore"
+	abci "github.com/Finschia/ostracon/abci/types"
+)
+
+func TestLinkSeed(t *testing.T) {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	received := func(seed int64) []bool {
+		l := newLink(seed, 0, 1, quit)
+		l.setFaults(LinkFaults{DropRate: 0.5})
+		var received []bool
+		for i := 0; i < 100; i++ {
+			received = append(received, l.receive(func() bool { return true }))
+		}
+		return received
+	}
+	assert.Equal(t, received(1), received(1))
+	assert.NotEqual(t, received(1), received(2))
+}
+
+func TestLinkLatency(t *testing.T) {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	l := newLink(1, 0, 1, quit)
+	l.setFaults(LinkFaults{Latency: 20 * time.Millisecond, Jitter: 20 * time.Millisecond})
+	received := make(chan int, 10)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		i := i
+		require.True(t, l.receive(func() bool { received <- i; return true }))
+	}
+	// the messages are received in order, after the latency
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i, <-received)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// the messages received without latency wait for the delayed ones
+	l.receive(func() bool { received <- 10; return true })
+	l.setFaults(LinkFaults{})
+	l.receive(func() bool { received <- 11; return true })
+	assert.Equal(t, 10, <-received)
+	assert.Equal(t, 11, <-received)
+}
+
+func TestNetworkFaults(t *testing.T) {
+	network, err := NewNetwork(Config{
+		NumValidators: 4,
+		NewApp:        func(i int) abci.Application { return kvstore.NewApplication() },
+		Faults: LinkFaults{
+			Latency:  5 * time.Millisecond,
+			Jitter:   10 * time.Millisecond,
+			DropRate: 0.02,
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(network.Cleanup)
+	t.Logf("seed: %d", network.Seed())
+	require.NoError(t, network.Start())
+	t.Cleanup(func() { assert.NoError(t, network.Stop()) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	require.NoError(t, network.WaitForHeight(ctx, 2, 10*time.Millisecond))
+
+	// the node which receives no message falls behind, the others keep on
+	// committing blocks with more than 2/3 of the voting power
+	for i := 1; i < len(network.Nodes); i++ {
+		network.SetLinkFaults(i, 0, LinkFaults{DropRate: 1})
+	}
+	height := network.Nodes[0].BlockStore().Height()
+	require.NoError(t, network.WaitForHeight(ctx, height+2, 10*time.Millisecond, 1, 2, 3))
+	assert.LessOrEqual(t, network.Nodes[0].BlockStore().Height(), height+1)
+
+	// the peers of the node consider it received the messages dropped, until
+	// it is reconnected
+	network.SetFaults(LinkFaults{})
+	network.Partition([]int{1, 2, 3})
+	network.Heal()
+	require.NoError(t, network.WaitForHeight(ctx, height+3, 10*time.Millisecond))
+}
@@ -0,0 +1,335 @@
+package inprocess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	abci "github.com/Finschia/ostracon/abci/types"
+	cfg "github.com/Finschia/ostracon/config"
+	cs "github.com/Finschia/ostracon/consensus"
+	"github.com/Finschia/ostracon/libs/log"
+	nm "github.com/Finschia/ostracon/node"
+	"github.com/Finschia/ostracon/p2p"
+	"github.com/Finschia/ostracon/privval"
+	"github.com/Finschia/ostracon/proxy"
+	rpcclient "github.com/Finschia/ostracon/rpc/client"
+	rpclocal "github.com/Finschia/ostracon/rpc/client/local"
+	"github.com/Finschia/ostracon/types"
+	tmtime "github.com/Finschia/ostracon/types/time"
+)
+
+// rpcMtx serializes the calls to the RPC of the nodes, which share the
+// RPC environment of the process.
+var rpcMtx sync.Mutex
+
+// Config is the configuration of a Network.
+type Config struct {
+	// Number of validators, with a voting power of 10 each.
+	NumValidators int
+	// Number of full nodes which are not validators.
+	NumFullNodes int
+	// NewApp returns the application of the i-th node.
+	NewApp func(i int) abci.Application
+	// ChainID of the network, "inprocess_test" if empty.
+	ChainID string
+	// ManualClock makes the consensus timeouts fire only when the clock is
+	// advanced with Network.AdvanceTime.
+	ManualClock bool
+	// Faults of the messages of all the links between the nodes, which can be
+	// changed with Network.SetFaults and Network.SetLinkFaults.
+	Faults LinkFaults
+	// Seed of the random decisions of the links (the dropped messages and the
+	// jitters), a random one if 0, see Network.Seed.
+	Seed int64
+	// Configure, if not nil, modifies the configuration of the i-th node, which
+	// is based on cfg.TestConfig.
+	Configure func(i int, config *cfg.Config)
+	// Logger of the nodes, a no-op logger if nil.
+	Logger log.Logger
+}
+
+// Node is a node of the Network.
+type Node struct {
+	*nm.Node
+
+	// Index of the node in the network.
+	Index int
+	// Config of the node.
+	Config *cfg.Config
+	// Ticker of the consensus timeouts, nil unless Config.ManualClock is set.
+	Ticker *cs.ManualTimeoutTicker
+}
+
+// RPC calls f with an RPC client of the node. The calls to the RPC of the
+// nodes are serialized.
+func (n *Node) RPC(f func(client rpcclient.Client) error) error {
+	rpcMtx.Lock()
+	defer rpcMtx.Unlock()
+	if err := n.ConfigureRPC(); err != nil {
+		return err
+	}
+	return f(rpclocal.New(n.Node))
+}
+
+// Network is a network of in-process nodes connected with in-memory pipes.
+type Network struct {
+	Nodes []*Node
+
+	mtx       sync.Mutex
+	partition []int // group of each node, nil if not partitioned
+
+	seed     int64
+	indexes  map[p2p.ID]int // index of each node by ID
+	links    [][]*link      // links[from][to], nil if from == to
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+// NewNetwork creates the nodes of a new network. Call Cleanup to remove their
+// files.
+func NewNetwork(netConfig Config) (*Network, error) {
+	numNodes := netConfig.NumValidators + netConfig.NumFullNodes
+	if netConfig.NumValidators < 1 {
+		return nil, errors.New("the network needs at least one validator")
+	}
+	if netConfig.NewApp == nil {
+		return nil, errors.New("NewApp is required")
+	}
+	chainID := netConfig.ChainID
+	if chainID == "" {
+		chainID = "inprocess_test"
+	}
+	logger := netConfig.Logger
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+
+	seed := netConfig.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	network := &Network{
+		seed:    seed,
+		indexes: make(map[p2p.ID]int, numNodes),
+		quit:    make(chan struct{}),
+	}
+	configs := make([]*cfg.Config, numNodes)
+	privVals := make([]*privval.FilePV, numNodes)
+	genDoc := &types.GenesisDoc{
+		ChainID:         chainID,
+		GenesisTime:     tmtime.Now(),
+		ConsensusParams: types.DefaultConsensusParams(),
+	}
+	for i := 0; i < numNodes; i++ {
+		config := cfg.ResetTestRootWithChainID(fmt.Sprintf("inprocess_node%d", i), chainID)
+		config.RPC.ListenAddress = ""
+		config.RPC.GRPCListenAddress = ""
+		config.P2P.ListenAddress = "tcp://127.0.0.1:0"
+		config.P2P.PexReactor = false
+		config.P2P.AllowDuplicateIP = true
+		config.P2P.AddrBookStrict = false
+		if netConfig.Configure != nil {
+			netConfig.Configure(i, config)
+		}
+		configs[i] = config
+		network.Nodes = append(network.Nodes, &Node{Index: i, Config: config})
+
+		privVals[i] = privval.GenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+		privVals[i].Save()
+		// ResetTestRoot writes the same node key for all the nodes
+		if err := os.Remove(config.NodeKeyFile()); err != nil {
+			network.Cleanup()
+			return nil, err
+		}
+
+		if i < netConfig.NumValidators {
+			pubKey, err := privVals[i].GetPubKey()
+			if err != nil {
+				network.Cleanup()
+				return nil, err
+			}
+			genDoc.Validators = append(genDoc.Validators, types.GenesisValidator{
+				Address: pubKey.Address(),
+				PubKey:  pubKey,
+				Power:   10,
+				Name:    fmt.Sprintf("validator%d", i),
+			})
+		}
+	}
+
+	for i, config := range configs {
+		if err := genDoc.SaveAs(config.GenesisFile()); err != nil {
+			network.Cleanup()
+			return nil, err
+		}
+		nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+		if err != nil {
+			network.Cleanup()
+			return nil, err
+		}
+		n, err := nm.NewNode(config,
+			privVals[i],
+			nodeKey,
+			proxy.NewLocalClientCreator(netConfig.NewApp(i)),
+			nm.DefaultGenesisDocProviderFunc(config),
+			nm.DefaultDBProvider,
+			nm.DefaultMetricsProvider(config.Instrumentation),
+			logger.With("node", i),
+		)
+		if err != nil {
+			network.Cleanup()
+			return nil, err
+		}
+		network.Nodes[i].Node = n
+		if netConfig.ManualClock {
+			network.Nodes[i].Ticker = cs.NewManualTimeoutTicker()
+			network.Nodes[i].Ticker.SetLogger(logger.With("node", i, "module", "consensus"))
+			n.ConsensusState().SetTimeoutTicker(network.Nodes[i].Ticker)
+		}
+		network.indexes[n.NodeInfo().ID()] = i
+		network.wrapReactors(i, n.Switch())
+	}
+
+	network.links = make([][]*link, numNodes)
+	for i := range network.links {
+		network.links[i] = make([]*link, numNodes)
+		for j := range network.links[i] {
+			if i != j {
+				network.links[i][j] = newLink(seed, i, j, network.quit)
+				network.links[i][j].setFaults(netConfig.Faults)
+			}
+		}
+	}
+	return network, nil
+}
+
+// Start starts all the nodes and connects each of them to all the others.
+func (network *Network) Start() error {
+	for _, n := range network.Nodes {
+		if err := n.Start(); err != nil {
+			return fmt.Errorf("failed to start node %d: %w", n.Index, err)
+		}
+	}
+	network.Heal()
+	return nil
+}
+
+// Stop stops all the running nodes, dropping the messages delayed by the
+// links.
+func (network *Network) Stop() error {
+	network.quitOnce.Do(func() { close(network.quit) })
+	var errs []error
+	for _, n := range network.Nodes {
+		if n.Node == nil || !n.IsRunning() {
+			continue
+		}
+		if err := n.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop node %d: %w", n.Index, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Cleanup removes the files of the nodes. The nodes must be stopped.
+func (network *Network) Cleanup() {
+	network.quitOnce.Do(func() { close(network.quit) })
+	for _, n := range network.Nodes {
+		os.RemoveAll(n.Config.RootDir)
+	}
+}
+
+// Partition splits the network into the given groups of node indexes: the
+// nodes of different groups are disconnected from each other, and the nodes in
+// no group are disconnected from all the others.
+func (network *Network) Partition(groups ...[]int) {
+	network.mtx.Lock()
+	defer network.mtx.Unlock()
+
+	partition := make([]int, len(network.Nodes))
+	for i := range partition {
+		partition[i] = -1 - i // alone in its own group
+	}
+	for g, group := range groups {
+		for _, i := range group {
+			partition[i] = g
+		}
+	}
+	network.partition = partition
+
+	for i, n := range network.Nodes {
+		for j, m := range network.Nodes {
+			if partition[i] == partition[j] {
+				continue
+			}
+			if peer := n.Switch().Peers().Get(m.NodeInfo().ID()); peer != nil {
+				n.Switch().StopPeerGracefully(peer)
+			}
+		}
+	}
+}
+
+// Heal removes the partition, reconnecting all the nodes to each other.
+func (network *Network) Heal() {
+	network.mtx.Lock()
+	defer network.mtx.Unlock()
+
+	network.partition = nil
+	switches := make([]*p2p.Switch, len(network.Nodes))
+	for i, n := range network.Nodes {
+		switches[i] = n.Switch()
+	}
+	for i := range network.Nodes {
+		for j := i + 1; j < len(network.Nodes); j++ {
+			if !switches[i].Peers().Has(network.Nodes[j].NodeInfo().ID()) {
+				p2p.Connect2Switches(switches, i, j)
+			}
+		}
+	}
+}
+
+// AdvanceTime advances the clock of the consensus timeouts of all the nodes by
+// d. It requires Config.ManualClock.
+func (network *Network) AdvanceTime(d time.Duration) {
+	for _, n := range network.Nodes {
+		if n.Ticker == nil {
+			panic("AdvanceTime requires Config.ManualClock")
+		}
+		n.Ticker.Advance(d)
+	}
+}
+
+// WaitForHeight waits until all the given nodes (all the nodes if none is
+// given) have committed a block at the given height. With Config.ManualClock,
+// the clock is advanced by tick while waiting.
+func (network *Network) WaitForHeight(ctx context.Context, height int64, tick time.Duration, nodes ...int) error {
+	if len(nodes) == 0 {
+		for i := range network.Nodes {
+			nodes = append(nodes, i)
+		}
+	}
+	for {
+		done := true
+		for _, i := range nodes {
+			if network.Nodes[i].BlockStore().Height() < height {
+				done = false
+				break
+			}
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tick):
+		}
+		if network.Nodes[0].Ticker != nil {
+			network.AdvanceTime(tick)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package inprocess
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/abci/example/kvstore"
+	abci "github.com/Finschia/ostracon/abci/types"
+	rpcclient "github.com/Finschia/ostracon/rpc/client"
+)
+
+func newTestNetwork(t *testing.T, manualClock bool) *Network {
+	network, err := NewNetwork(Config{
+		NumValidators: 4,
+		NewApp:        func(i int) abci.Application { return kvstore.NewApplication() },
+		ManualClock:   manualClock,
+	})
+	require.NoError(t, err)
+	t.Cleanup(network.Cleanup)
+	require.NoError(t, network.Start())
+	t.Cleanup(func() { assert.NoError(t, network.Stop()) })
+	return network
+}
+
+func TestNetworkPartition(t *testing.T) {
+	network := newTestNetwork(t, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	require.NoError(t, network.WaitForHeight(ctx, 2, 10*time.Millisecond))
+	for _, n := range network.Nodes {
+		assert.Equal(t, 3, n.Switch().Peers().Size())
+	}
+
+	// no group has more than 2/3 of the voting power
+	network.Partition([]int{0, 1}, []int{2, 3})
+	for _, n := range network.Nodes {
+		assert.Equal(t, 1, n.Switch().Peers().Size())
+	}
+	time.Sleep(200 * time.Millisecond)
+	height := network.Nodes[0].BlockStore().Height()
+	time.Sleep(500 * time.Millisecond)
+	assert.LessOrEqual(t, network.Nodes[0].BlockStore().Height(), height+1)
+
+	network.Heal()
+	require.NoError(t, network.WaitForHeight(ctx, height+3, 10*time.Millisecond))
+}
+
+func TestNetworkManualClock(t *testing.T) {
+	network := newTestNetwork(t, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	require.NoError(t, network.WaitForHeight(ctx, 3, 10*time.Millisecond))
+
+	err := network.Nodes[1].RPC(func(client rpcclient.Client) error {
+		status, err := client.Status(ctx)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, network.Nodes[1].NodeInfo().ID(), status.NodeInfo.ID())
+		assert.GreaterOrEqual(t, status.SyncInfo.LatestBlockHeight, int64(3))
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -132,8 +132,12 @@ func (b *Block) ValidateBasic() error {
 		)
 	}
 
-	if err := b.Entropy.ValidateBasic(); err != nil {
-		return fmt.Errorf("invalid entropy: %w", err)
+	// the entropy of the new blocks is verified against the state by
+	// VerifyEntropy, it can only be empty in the blocks committed by Tendermint
+	if !b.Entropy.IsEmpty() {
+		if err := b.Entropy.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid entropy: %w", err)
+		}
 	}
 
 	return nil
@@ -304,11 +308,15 @@ func BlockFromProto(bp *ocproto.Block) (*Block, error) {
 		}
 		b.LastCommit = lc
 	}
-	vp, err := EntropyFromProto(&bp.Entropy)
-	if err != nil {
-		return nil, err
+	// the blocks committed by Tendermint before migrating to Ostracon have no
+	// entropy
+	if bp.Entropy.Round != 0 || len(bp.Entropy.Proof) != 0 {
+		vp, err := EntropyFromProto(&bp.Entropy)
+		if err != nil {
+			return nil, err
+		}
+		b.Entropy = vp
 	}
-	b.Entropy = vp
 
 	return b, b.ValidateBasic()
 }
@@ -1335,6 +1343,29 @@ func (vp Entropy) ValidateBasic() error {
 	return nil
 }
 
+// IsEmpty returns true if the Entropy has neither a round nor a VRF proof, as
+// in the blocks committed by Tendermint before migrating to Ostracon.
+func (vp Entropy) IsEmpty() bool {
+	return vp.Round == 0 && len(vp.Proof) == 0
+}
+
+// ProofHash returns the VRF output of the proof, which is the seed of the
+// proposer election at the next height (the LastProofHash of the state).
+func (vp Entropy) ProofHash() ([]byte, error) {
+	return ed25519.ProofToHash(vp.Proof)
+}
+
+// VerifyProof checks that the proof was generated for the message by the
+// proposer whose public key is given, and returns its VRF output. The message
+// is MakeRoundHash of the VRF output and the height of the previous block and
+// of the round, see VerifyEntropy.
+func (vp Entropy) VerifyProof(pubKey crypto.PubKey, message []byte) (crypto.Output, error) {
+	if pubKey == nil {
+		return nil, errors.New("nil PubKey")
+	}
+	return pubKey.VRFVerify(crypto.Proof(vp.Proof), message)
+}
+
 // Hash returns the hash of the Entropy.
 // It computes a Merkle tree from the Entropy fields
 // ordered as they appear in the Entropy.
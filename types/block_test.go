@@ -131,6 +131,13 @@ func TestBlockValidateBasic(t *testing.T) {
 		{"Incorrect Proof Size", func(blk *Block) {
 			blk.Proof = []byte("wrong proof size")
 		}, true},
+		{"No Entropy", func(blk *Block) {
+			blk.Entropy = Entropy{}
+		}, false},
+		{"No Proof", func(blk *Block) {
+			blk.Round = 1
+			blk.Proof = nil
+		}, true},
 	}
 	for i, tc := range testCases {
 		tc := tc
@@ -833,6 +840,10 @@ func TestBlockProtoBuf(t *testing.T) {
 	b3 := MakeBlock(h, []Tx{}, c1, []Evidence{}, TestConsensusVersion)
 	b3.ProposerAddress = tmrand.Bytes(crypto.AddressSize)
 	b3.Entropy.Populate(round, proof)
+
+	// committed by Tendermint, before migrating to Ostracon
+	b4 := MakeBlock(h, []Tx{Tx([]byte{1})}, c1, []Evidence{}, TestConsensusVersion)
+	b4.ProposerAddress = tmrand.Bytes(crypto.AddressSize)
 	testCases := []struct {
 		msg      string
 		b1       *Block
@@ -843,6 +854,7 @@ func TestBlockProtoBuf(t *testing.T) {
 		{"b1", b1, true, true},
 		{"b2", b2, true, true},
 		{"b3", b3, true, true},
+		{"b4 without entropy", b4, true, true},
 	}
 	for _, tc := range testCases {
 		pb, err := tc.b1.ToProto()
@@ -5,6 +5,7 @@ import (
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 	tmtime "github.com/Finschia/ostracon/types/time"
 )
 
@@ -65,6 +66,18 @@ func CanonicalizeVote(chainID string, vote *tmproto.Vote) tmproto.CanonicalVote
 	}
 }
 
+// CanonicalizeVoteExtension transforms the given VoteExtension to a
+// CanonicalVoteExtension, which does not contain the ValidatorAddress and the
+// Signature fields.
+func CanonicalizeVoteExtension(chainID string, ext *ocproto.VoteExtension) ocproto.CanonicalVoteExtension {
+	return ocproto.CanonicalVoteExtension{
+		Extension: ext.Extension,
+		Height:    ext.Height, // encoded as sfixed64
+		BlockHash: ext.BlockHash,
+		ChainID:   chainID,
+	}
+}
+
 // CanonicalTime can be used to stringify time in a canonical way.
 func CanonicalTime(t time.Time) string {
 	// Note that sending time over amino resets it to
@@ -0,0 +1,256 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	tmjson "github.com/Finschia/ostracon/libs/json"
+)
+
+// CanonicalJSONMode is the mode of the canonical JSON encoding of the blocks,
+// headers, commits and votes.
+type CanonicalJSONMode int
+
+const (
+	// CanonicalJSONSorted is the canonical JSON encoding, see
+	// MarshalCanonicalJSON.
+	CanonicalJSONSorted CanonicalJSONMode = iota
+	// CanonicalJSONAmino is the Amino-compatible JSON encoding, see
+	// MarshalCanonicalJSON.
+	CanonicalJSONAmino
+)
+
+// ErrNotCanonicalJSON is returned when decoding a JSON document which isn't
+// the canonical encoding of the decoded value.
+var ErrNotCanonicalJSON = errors.New("not canonical JSON")
+
+// MarshalCanonicalJSON encodes the *Block, *Header, *Commit or *Vote as JSON,
+// so that the same value always has the same encoding, whatever the language
+// of the encoder, and the encodings can be compared (or hashed) byte by byte.
+//
+// The values are those of the JSON encoding of the RPC (libs/json):
+//   - the 64-bit integers are decimal strings, the other ones are numbers;
+//   - the times are RFC 3339 strings in UTC, with the nanoseconds without their
+//     trailing zeros (e.g. "2023-01-02T15:04:05.1Z");
+//   - the hashes and addresses (HexBytes) are upper case hexadecimal strings,
+//     the other bytes (e.g. the signatures and the txs) are base64 strings;
+//   - the evidence and the public keys are wrapped in {"type":..,"value":..}
+//     objects, as in Amino;
+//   - the fields are present, with null for the nil pointers and for the nil
+//     and empty lists (which protobuf doesn't distinguish), except the zero
+//     fields of the protobuf messages (e.g. the app version of the header),
+//     which are omitted.
+//
+// In the CanonicalJSONSorted mode, the members of the objects are sorted by
+// the byte order of their keys, there is no whitespace between the tokens and
+// the strings are escaped as little as possible (only the quotation mark, the
+// reverse solidus and the control characters, as \" \\ \b \f \n \r \t or
+// \u00XX). As all the keys are ASCII and there are no floating point numbers,
+// this is also the RFC 8785 (JCS) encoding of the document.
+//
+// In the CanonicalJSONAmino mode, the members are in the order of the fields
+// of the Go structs and the <, > and & characters are also escaped (as \u003c,
+// \u003e and \u0026), as in the JSON encoding of Amino.
+func MarshalCanonicalJSON(v interface{}, mode CanonicalJSONMode) ([]byte, error) {
+	if err := checkCanonicalJSONType(v); err != nil {
+		return nil, err
+	}
+	bz, err := tmjson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if mode != CanonicalJSONSorted && mode != CanonicalJSONAmino {
+		return nil, fmt.Errorf("unknown canonical JSON mode %d", mode)
+	}
+	return canonicalizeJSON(bz, mode)
+}
+
+// UnmarshalCanonicalJSON decodes the canonical JSON encoding (see
+// MarshalCanonicalJSON) of a *Block, *Header, *Commit or *Vote into v. It returns
+// ErrNotCanonicalJSON if the document isn't exactly the canonical encoding of
+// the decoded value in the mode, e.g. if it has whitespace or unknown fields.
+func UnmarshalCanonicalJSON(bz []byte, v interface{}, mode CanonicalJSONMode) error {
+	if err := checkCanonicalJSONType(v); err != nil {
+		return err
+	}
+	if err := tmjson.Unmarshal(bz, v); err != nil {
+		return err
+	}
+	canonical, err := MarshalCanonicalJSON(v, mode)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bz, canonical) {
+		return ErrNotCanonicalJSON
+	}
+	return nil
+}
+
+func checkCanonicalJSONType(v interface{}) error {
+	switch v.(type) {
+	case *Block, *Header, *Commit, *Vote:
+		return nil
+	default:
+		return fmt.Errorf("no canonical JSON encoding for %T", v)
+	}
+}
+
+// canonicalizeJSON re-encodes the JSON document without whitespace, with the
+// empty arrays as null and, in the CanonicalJSONSorted mode, with the members
+// of its objects sorted and the strings escaped as little as possible.
+func canonicalizeJSON(bz []byte, mode CanonicalJSONMode) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	doc, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := writeCanonicalJSON(buf, doc, mode); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonObject is a JSON object, with its members in order.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key: keyTok.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		var arr []interface{}
+		for dec.More() {
+			elem, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, elem)
+		}
+		_, err := dec.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, v interface{}, mode CanonicalJSONMode) error {
+	switch v := v.(type) {
+	case jsonObject:
+		if mode == CanonicalJSONSorted {
+			sorted := append(jsonObject(nil), v...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i].key < sorted[j].key })
+			v = sorted
+		}
+		buf.WriteByte('{')
+		for i, member := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, member.key, mode); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, member.value, mode); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		// the nil and the empty slices are the same value in protobuf
+		if len(v) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, elem, mode); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case string:
+		if mode == CanonicalJSONSorted {
+			writeJSONString(buf, v)
+			return nil
+		}
+		bz, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(bz)
+	case json.Number:
+		buf.WriteString(v.String())
+	case bool:
+		if v {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected JSON value %T", v)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,115 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+func TestVoteCanonicalJSON(t *testing.T) {
+	vote := examplePrecommit()
+	vote.Signature = []byte("signature")
+
+	testCases := []struct {
+		mode     CanonicalJSONMode
+		expected string
+	}{
+		{
+			CanonicalJSONSorted,
+			`{"block_id":{"hash":"8B01023386C371778ECB6368573E539AFC3CC860EC3A2F614E54FE5652F4FC80",` +
+				`"parts":{"hash":"72DB3D959635DFF1BB567BEDAA70573392C5159666A3F8CAF11E413AAC52207A","total":1000000}},` +
+				`"height":"12345","round":2,"signature":"c2lnbmF0dXJl","timestamp":"2017-12-25T03:00:01.234Z","type":2,` +
+				`"validator_address":"6AF1F4111082EFB388211BC72C55BCD61E9AC3D5","validator_index":56789}`,
+		},
+		{
+			CanonicalJSONAmino,
+			`{"type":2,"height":"12345","round":2,` +
+				`"block_id":{"hash":"8B01023386C371778ECB6368573E539AFC3CC860EC3A2F614E54FE5652F4FC80",` +
+				`"parts":{"total":1000000,"hash":"72DB3D959635DFF1BB567BEDAA70573392C5159666A3F8CAF11E413AAC52207A"}},` +
+				`"timestamp":"2017-12-25T03:00:01.234Z","validator_address":"6AF1F4111082EFB388211BC72C55BCD61E9AC3D5",` +
+				`"validator_index":56789,"signature":"c2lnbmF0dXJl"}`,
+		},
+	}
+	for _, tc := range testCases {
+		bz, err := MarshalCanonicalJSON(vote, tc.mode)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, string(bz))
+
+		decoded := new(Vote)
+		require.NoError(t, UnmarshalCanonicalJSON(bz, decoded, tc.mode))
+		assert.Equal(t, vote, decoded)
+	}
+}
+
+func TestBlockCanonicalJSON(t *testing.T) {
+	lastID := makeBlockIDRandom()
+	h := int64(3)
+	voteSet, _, vals := randVoteSet(h-1, 1, tmproto.PrecommitType, 10, 1)
+	commit, err := MakeCommit(lastID, h-1, 1, voteSet, vals, time.Now())
+	require.NoError(t, err)
+	ev := NewMockDuplicateVoteEvidenceWithValidator(h, time.Now(), vals[0], "block-test-chain")
+	block := MakeBlock(h, []Tx{Tx("foo"), Tx("bar")}, commit, []Evidence{ev}, TestConsensusVersion)
+	block.ChainID = "<chain&id>"
+
+	for _, mode := range []CanonicalJSONMode{CanonicalJSONSorted, CanonicalJSONAmino} {
+		bz, err := MarshalCanonicalJSON(block, mode)
+		require.NoError(t, err)
+		decoded := new(Block)
+		require.NoError(t, UnmarshalCanonicalJSON(bz, decoded, mode))
+		assert.Equal(t, block.Hash(), decoded.Hash())
+
+		for _, v := range []interface{}{&block.Header, block.LastCommit} {
+			bz, err := MarshalCanonicalJSON(v, mode)
+			require.NoError(t, err)
+			switch v.(type) {
+			case *Header:
+				decoded := new(Header)
+				require.NoError(t, UnmarshalCanonicalJSON(bz, decoded, mode))
+				assert.Equal(t, block.Header.Hash(), decoded.Hash())
+			case *Commit:
+				decoded := new(Commit)
+				require.NoError(t, UnmarshalCanonicalJSON(bz, decoded, mode))
+				assert.Equal(t, block.LastCommit.Hash(), decoded.Hash())
+			}
+		}
+	}
+
+	sorted, err := MarshalCanonicalJSON(&block.Header, CanonicalJSONSorted)
+	require.NoError(t, err)
+	assert.Contains(t, string(sorted), `"chain_id":"<chain&id>"`)
+	amino, err := MarshalCanonicalJSON(&block.Header, CanonicalJSONAmino)
+	require.NoError(t, err)
+	assert.Contains(t, string(amino), `"chain_id":"\u003cchain\u0026id\u003e"`)
+
+	// the encoding of a mode isn't canonical in the other one
+	assert.Equal(t, ErrNotCanonicalJSON, UnmarshalCanonicalJSON(sorted, new(Header), CanonicalJSONAmino))
+	assert.Equal(t, ErrNotCanonicalJSON, UnmarshalCanonicalJSON(amino, new(Header), CanonicalJSONSorted))
+	// nor is the one with whitespace
+	assert.Equal(t, ErrNotCanonicalJSON,
+		UnmarshalCanonicalJSON(append([]byte(" "), sorted...), new(Header), CanonicalJSONSorted))
+}
+
+func TestCanonicalJSONEmptyLists(t *testing.T) {
+	for _, mode := range []CanonicalJSONMode{CanonicalJSONSorted, CanonicalJSONAmino} {
+		empty, err := MarshalCanonicalJSON(MakeBlock(1, []Tx{}, &Commit{Signatures: []CommitSig{}}, nil, TestConsensusVersion), mode)
+		require.NoError(t, err)
+		nilTxs, err := MarshalCanonicalJSON(MakeBlock(1, nil, &Commit{}, nil, TestConsensusVersion), mode)
+		require.NoError(t, err)
+		assert.Equal(t, string(nilTxs), string(empty))
+		assert.Contains(t, string(empty), `"txs":null`)
+		require.NoError(t, UnmarshalCanonicalJSON(empty, new(Block), mode))
+	}
+}
+
+func TestCanonicalJSONUnsupportedType(t *testing.T) {
+	_, err := MarshalCanonicalJSON(&Proposal{}, CanonicalJSONSorted)
+	assert.Error(t, err)
+	_, err = MarshalCanonicalJSON(Vote{}, CanonicalJSONSorted)
+	assert.Error(t, err)
+	assert.Error(t, UnmarshalCanonicalJSON([]byte(`{}`), &Proposal{}, CanonicalJSONSorted))
+	_, err = MarshalCanonicalJSON(&Vote{}, CanonicalJSONMode(2))
+	assert.Error(t, err)
+}
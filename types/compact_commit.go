@@ -0,0 +1,225 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	tmprotobits "github.com/tendermint/tendermint/proto/tendermint/libs/bits"
+
+	"github.com/Finschia/ostracon/libs/bits"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+)
+
+// CompactCommit is the compact encoding of a Commit for the validator set
+// which signed it. Instead of a CommitSig for each validator, with its address
+// and its BlockIDFlag, it has the bit arrays of the validators which voted for
+// the block and for nil, and the timestamps and signatures of their precommits
+// in the order of the validator set, which saves the size of the addresses and
+// the flags of the validators in the commits of the large validator sets.
+//
+// As the addresses of the validators aren't part of it, a CompactCommit can
+// only be converted back to a Commit (see ToCommit) with the validator set,
+// e.g. to serve the commits to the RPC clients in the Commit format.
+type CompactCommit struct {
+	Height  int64   `json:"height"`
+	Round   int32   `json:"round"`
+	BlockID BlockID `json:"block_id"`
+	// Commits are the validators whose precommit for the block is in the
+	// commit. As Nils, it is nil for a commit without signatures.
+	Commits *bits.BitArray `json:"commits"`
+	// Nils are the validators whose precommit for nil is in the commit.
+	Nils *bits.BitArray `json:"nils"`
+	// Timestamps and Signatures are those of the precommits of the validators
+	// of Commits or Nils, in the order of the validator set.
+	Timestamps []time.Time `json:"timestamps"`
+	Signatures [][]byte    `json:"signatures"`
+}
+
+// NewCompactCommit returns the compact encoding of the commit signed by the
+// validator set.
+func NewCompactCommit(commit *Commit, vals *ValidatorSet) (*CompactCommit, error) {
+	if commit == nil {
+		return nil, errors.New("nil Commit")
+	}
+	if vals.Size() != commit.Size() {
+		return nil, fmt.Errorf("commit has %d signatures, but the validator set has %d validators",
+			commit.Size(), vals.Size())
+	}
+
+	cc := &CompactCommit{
+		Height:  commit.Height,
+		Round:   commit.Round,
+		BlockID: commit.BlockID,
+		Commits: bits.NewBitArray(commit.Size()),
+		Nils:    bits.NewBitArray(commit.Size()),
+	}
+	for i, commitSig := range commit.Signatures {
+		switch commitSig.BlockIDFlag {
+		case BlockIDFlagAbsent:
+			continue
+		case BlockIDFlagCommit:
+			cc.Commits.SetIndex(i, true)
+		case BlockIDFlagNil:
+			cc.Nils.SetIndex(i, true)
+		default:
+			return nil, fmt.Errorf("unknown BlockIDFlag of CommitSig #%d: %v", i, commitSig.BlockIDFlag)
+		}
+		if !bytes.Equal(commitSig.ValidatorAddress, vals.Validators[i].Address) {
+			return nil, fmt.Errorf("CommitSig #%d is signed by %X instead of validator %X",
+				i, commitSig.ValidatorAddress, vals.Validators[i].Address)
+		}
+		cc.Timestamps = append(cc.Timestamps, commitSig.Timestamp)
+		cc.Signatures = append(cc.Signatures, commitSig.Signature)
+	}
+	return cc, nil
+}
+
+// ToCommit returns the commit, signed by the validator set, of the compact
+// encoding.
+func (cc *CompactCommit) ToCommit(vals *ValidatorSet) (*Commit, error) {
+	if err := cc.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if vals.Size() != cc.Commits.Size() {
+		return nil, fmt.Errorf("commit has %d bits, but the validator set has %d validators",
+			cc.Commits.Size(), vals.Size())
+	}
+
+	sigs := make([]CommitSig, vals.Size())
+	next := 0
+	for i, val := range vals.Validators {
+		var flag BlockIDFlag
+		switch {
+		case cc.Commits.GetIndex(i):
+			flag = BlockIDFlagCommit
+		case cc.Nils.GetIndex(i):
+			flag = BlockIDFlagNil
+		default:
+			sigs[i] = NewCommitSigAbsent()
+			continue
+		}
+		sigs[i] = CommitSig{
+			BlockIDFlag:      flag,
+			ValidatorAddress: val.Address,
+			Timestamp:        cc.Timestamps[next],
+			Signature:        cc.Signatures[next],
+		}
+		next++
+	}
+	return NewCommit(cc.Height, cc.Round, cc.BlockID, sigs), nil
+}
+
+// ValidateBasic performs basic validation that doesn't involve the validator
+// set. It doesn't check the signatures.
+func (cc *CompactCommit) ValidateBasic() error {
+	if cc == nil {
+		return errors.New("nil CompactCommit")
+	}
+	if cc.Height < 0 {
+		return errors.New("negative Height")
+	}
+	if cc.Round < 0 {
+		return errors.New("negative Round")
+	}
+	if cc.Height >= 1 && cc.BlockID.IsZero() {
+		return errors.New("commit cannot be for nil block")
+	}
+	if err := cc.BlockID.ValidateBasic(); err != nil {
+		return fmt.Errorf("wrong BlockID: %v", err)
+	}
+
+	if cc.Commits.Size() != cc.Nils.Size() {
+		return fmt.Errorf("bit arrays of different sizes: %d and %d", cc.Commits.Size(), cc.Nils.Size())
+	}
+	if cc.Height >= 1 && cc.Commits.IsEmpty() && cc.Nils.IsEmpty() {
+		return errors.New("no signatures in commit")
+	}
+	count := 0
+	for i := 0; i < cc.Commits.Size(); i++ {
+		forBlock, forNil := cc.Commits.GetIndex(i), cc.Nils.GetIndex(i)
+		if forBlock && forNil {
+			return fmt.Errorf("validator #%d voted both for the block and for nil", i)
+		}
+		if forBlock || forNil {
+			count++
+		}
+	}
+
+	if len(cc.Timestamps) != count {
+		return fmt.Errorf("expected %d timestamps, got %d", count, len(cc.Timestamps))
+	}
+	if len(cc.Signatures) != count {
+		return fmt.Errorf("expected %d signatures, got %d", count, len(cc.Signatures))
+	}
+	for i, sig := range cc.Signatures {
+		if len(sig) == 0 {
+			return fmt.Errorf("signature #%d is missing", i)
+		}
+		if len(sig) > MaxSignatureSize {
+			return fmt.Errorf("signature #%d is too big (max: %d)", i, MaxSignatureSize)
+		}
+	}
+	return nil
+}
+
+// ToProto converts CompactCommit to protobuf
+func (cc *CompactCommit) ToProto() *ocproto.CompactCommit {
+	if cc == nil {
+		return nil
+	}
+
+	return &ocproto.CompactCommit{
+		Height:     cc.Height,
+		Round:      cc.Round,
+		BlockID:    cc.BlockID.ToProto(),
+		Commits:    cc.Commits.ToProto(),
+		Nils:       cc.Nils.ToProto(),
+		Timestamps: cc.Timestamps,
+		Signatures: cc.Signatures,
+	}
+}
+
+// CompactCommitFromProto converts a protobuf CompactCommit to CompactCommit.
+// It returns an error if the commit is invalid.
+func CompactCommitFromProto(pb *ocproto.CompactCommit) (*CompactCommit, error) {
+	if pb == nil {
+		return nil, errors.New("nil CompactCommit")
+	}
+
+	bi, err := BlockIDFromProto(&pb.BlockID)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := &CompactCommit{
+		Height:     pb.Height,
+		Round:      pb.Round,
+		BlockID:    *bi,
+		Timestamps: pb.Timestamps,
+		Signatures: pb.Signatures,
+	}
+	if cc.Commits, err = bitArrayFromProto(pb.Commits); err != nil {
+		return nil, fmt.Errorf("wrong Commits: %v", err)
+	}
+	if cc.Nils, err = bitArrayFromProto(pb.Nils); err != nil {
+		return nil, fmt.Errorf("wrong Nils: %v", err)
+	}
+
+	return cc, cc.ValidateBasic()
+}
+
+// bitArrayFromProto converts a protobuf BitArray to BitArray, checking that it
+// has the right number of elements.
+func bitArrayFromProto(pb *tmprotobits.BitArray) (*bits.BitArray, error) {
+	if pb.GetBits() < 0 || int64(len(pb.GetElems())) != (pb.GetBits()+63)/64 {
+		return nil, fmt.Errorf("%d bits in %d elements", pb.GetBits(), len(pb.GetElems()))
+	}
+	if pb == nil {
+		return nil, nil
+	}
+	ba := new(bits.BitArray)
+	ba.FromProto(pb)
+	return ba, nil
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+
+	"github.com/Finschia/ostracon/libs/bits"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+	tmtime "github.com/Finschia/ostracon/types/time"
+)
+
+// makeCommitWithNilVotes returns a commit of the 10 validators of the set, 7
+// of which voted for the block, 2 for nil and 1 didn't vote.
+func makeCommitWithNilVotes(t *testing.T, height int64) (*Commit, *ValidatorSet) {
+	blockID := makeBlockID([]byte("blockhash"), 1000, []byte("partshash"))
+	voteSet, valSet, vals := randVoteSet(height, 0, tmproto.PrecommitType, 10, 1)
+	for i := int32(0); i < 9; i++ {
+		pubKey, err := vals[i].GetPubKey()
+		require.NoError(t, err)
+		vote := &Vote{
+			ValidatorAddress: pubKey.Address(),
+			ValidatorIndex:   i,
+			Height:           height,
+			Round:            0,
+			Type:             tmproto.PrecommitType,
+			BlockID:          blockID,
+			Timestamp:        tmtime.Now(),
+		}
+		if i >= 7 {
+			vote.BlockID = BlockID{}
+		}
+		added, err := signAddVote(vals[i], vote, voteSet)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	return voteSet.MakeCommit(), valSet
+}
+
+func TestCompactCommit(t *testing.T) {
+	commit, valSet := makeCommitWithNilVotes(t, 3)
+
+	cc, err := NewCompactCommit(commit, valSet)
+	require.NoError(t, err)
+	require.NoError(t, cc.ValidateBasic())
+	assert.Equal(t, 10, cc.Commits.Size())
+	assert.Len(t, cc.Signatures, 9)
+	assert.True(t, cc.Commits.GetIndex(6))
+	assert.True(t, cc.Nils.GetIndex(7))
+	assert.False(t, cc.Commits.GetIndex(9) || cc.Nils.GetIndex(9))
+
+	// the compact encoding is smaller
+	bz, err := cc.ToProto().Marshal()
+	require.NoError(t, err)
+	commitBz, err := commit.ToProto().Marshal()
+	require.NoError(t, err)
+	assert.Less(t, len(bz), len(commitBz))
+
+	pb := new(ocproto.CompactCommit)
+	require.NoError(t, pb.Unmarshal(bz))
+	cc2, err := CompactCommitFromProto(pb)
+	require.NoError(t, err)
+
+	// the commit converted back is the same one
+	commit2, err := cc2.ToCommit(valSet)
+	require.NoError(t, err)
+	assert.Equal(t, commit.Hash(), commit2.Hash())
+	assert.NoError(t, valSet.VerifyCommit("test_chain_id", commit.BlockID, 3, commit2))
+
+	// the validator set must be the one which signed the commit
+	_, otherValSet, _ := randVoteSet(3, 0, tmproto.PrecommitType, 10, 1)
+	_, err = NewCompactCommit(commit, otherValSet)
+	assert.Error(t, err)
+	_, otherValSet, _ = randVoteSet(3, 0, tmproto.PrecommitType, 9, 1)
+	_, err = NewCompactCommit(commit, otherValSet)
+	assert.Error(t, err)
+	_, err = cc.ToCommit(otherValSet)
+	assert.Error(t, err)
+}
+
+func TestCompactCommitEmpty(t *testing.T) {
+	cc, err := NewCompactCommit(NewCommit(0, 0, BlockID{}, nil), NewValidatorSet(nil))
+	require.NoError(t, err)
+
+	cc2, err := CompactCommitFromProto(cc.ToProto())
+	require.NoError(t, err)
+	commit, err := cc2.ToCommit(NewValidatorSet(nil))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), commit.Height)
+	assert.Empty(t, commit.Signatures)
+}
+
+func TestCompactCommitValidateBasic(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		malleateCommit func(*CompactCommit)
+		expectErr      bool
+	}{
+		{"Random CompactCommit", func(cc *CompactCommit) {}, false},
+		{"Negative Height", func(cc *CompactCommit) { cc.Height = -1 }, true},
+		{"Negative Round", func(cc *CompactCommit) { cc.Round = -1 }, true},
+		{"Nil BlockID", func(cc *CompactCommit) { cc.BlockID = BlockID{} }, true},
+		{"No signatures", func(cc *CompactCommit) { cc.Commits, cc.Nils = nil, nil }, true},
+		{"Different sizes", func(cc *CompactCommit) { cc.Nils = bits.NewBitArray(11) }, true},
+		{"Both for the block and for nil", func(cc *CompactCommit) { cc.Nils.SetIndex(0, true) }, true},
+		{"Missing timestamp", func(cc *CompactCommit) { cc.Timestamps = cc.Timestamps[1:] }, true},
+		{"Missing signature", func(cc *CompactCommit) { cc.Signatures = cc.Signatures[1:] }, true},
+		{"Empty signature", func(cc *CompactCommit) { cc.Signatures[0] = nil }, true},
+		{"Too big signature", func(cc *CompactCommit) { cc.Signatures[0] = make([]byte, MaxSignatureSize+1) }, true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			commit, valSet := makeCommitWithNilVotes(t, 3)
+			cc, err := NewCompactCommit(commit, valSet)
+			require.NoError(t, err)
+			tc.malleateCommit(cc)
+			assert.Equal(t, tc.expectErr, cc.ValidateBasic() != nil, "Validate Basic had an unexpected result")
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// CompositeEventKey returns the key of the attribute of the event type in the
+// event queries and the indexers, i.e. "{event.Type}.{attribute.Key}".
+func CompositeEventKey(eventType, key string) string {
+	return eventType + "." + key
+}
+
+// ErrEventAttributeNotFound is returned when an event has no attribute with the
+// given key.
+type ErrEventAttributeNotFound struct {
+	EventType string
+	Key       string
+}
+
+func (e ErrEventAttributeNotFound) Error() string {
+	return fmt.Sprintf("event %s has no attribute %s", e.EventType, e.Key)
+}
+
+// EventBuilder builds an ABCI event from typed attribute values, encoded the
+// way the event queries (see libs/pubsub/query) and the indexers expect them:
+//   - the integers in decimal, so that they can be compared with the <, <=, >
+//     and >= operators;
+//   - the booleans as "true" or "false";
+//   - the bytes in upper case hexadecimal, as the tx.hash.
+//
+// The attributes are sorted by key when the event is built, so that the same
+// attributes always result in the same event (and the same results hash),
+// whatever the order they are added in.
+type EventBuilder struct {
+	event abci.Event
+	index bool
+}
+
+// NewEventBuilder returns a builder of an event of the type, whose attributes
+// are indexed unless SetIndex(false) is called.
+func NewEventBuilder(eventType string) *EventBuilder {
+	return &EventBuilder{event: abci.Event{Type: eventType}, index: true}
+}
+
+// SetIndex sets whether the attributes added next are indexed.
+func (b *EventBuilder) SetIndex(index bool) *EventBuilder {
+	b.index = index
+	return b
+}
+
+// String adds an attribute with the string value.
+func (b *EventBuilder) String(key, value string) *EventBuilder {
+	b.event.Attributes = append(b.event.Attributes, abci.EventAttribute{
+		Key:   []byte(key),
+		Value: []byte(value),
+		Index: b.index,
+	})
+	return b
+}
+
+// Int64 adds an attribute with the integer value.
+func (b *EventBuilder) Int64(key string, value int64) *EventBuilder {
+	return b.String(key, strconv.FormatInt(value, 10))
+}
+
+// Uint64 adds an attribute with the unsigned integer value.
+func (b *EventBuilder) Uint64(key string, value uint64) *EventBuilder {
+	return b.String(key, strconv.FormatUint(value, 10))
+}
+
+// Bool adds an attribute with the boolean value.
+func (b *EventBuilder) Bool(key string, value bool) *EventBuilder {
+	return b.String(key, strconv.FormatBool(value))
+}
+
+// Bytes adds an attribute with the bytes value.
+func (b *EventBuilder) Bytes(key string, value []byte) *EventBuilder {
+	return b.String(key, strings.ToUpper(hex.EncodeToString(value)))
+}
+
+// Build returns the event, with its attributes sorted by key (the attributes
+// with the same key are kept in the order they were added in).
+func (b *EventBuilder) Build() abci.Event {
+	attrs := make([]abci.EventAttribute, len(b.event.Attributes))
+	copy(attrs, b.event.Attributes)
+	sort.SliceStable(attrs, func(i, j int) bool { return string(attrs[i].Key) < string(attrs[j].Key) })
+	return abci.Event{Type: b.event.Type, Attributes: attrs}
+}
+
+// EventAttributes gives typed access to the attributes of an ABCI event, as
+// encoded by EventBuilder. If the event has several attributes with the same
+// key, the first one is returned.
+type EventAttributes struct {
+	event abci.Event
+}
+
+// ParseEventAttributes returns the typed attributes of the event.
+func ParseEventAttributes(event abci.Event) EventAttributes {
+	return EventAttributes{event: event}
+}
+
+// FindEvent returns the typed attributes of the first event of the type, and
+// false if there is none.
+func FindEvent(events []abci.Event, eventType string) (EventAttributes, bool) {
+	for _, event := range events {
+		if event.Type == eventType {
+			return ParseEventAttributes(event), true
+		}
+	}
+	return EventAttributes{}, false
+}
+
+// Has returns true if the event has an attribute with the key.
+func (a EventAttributes) Has(key string) bool {
+	_, err := a.String(key)
+	return err == nil
+}
+
+// String returns the value of the attribute as a string, or
+// ErrEventAttributeNotFound.
+func (a EventAttributes) String(key string) (string, error) {
+	for _, attr := range a.event.Attributes {
+		if string(attr.Key) == key {
+			return string(attr.Value), nil
+		}
+	}
+	return "", ErrEventAttributeNotFound{EventType: a.event.Type, Key: key}
+}
+
+// Int64 returns the value of the attribute as an integer.
+func (a EventAttributes) Int64(key string) (int64, error) {
+	s, err := a.String(key)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, a.invalid(key, err)
+	}
+	return i, nil
+}
+
+// Uint64 returns the value of the attribute as an unsigned integer.
+func (a EventAttributes) Uint64(key string) (uint64, error) {
+	s, err := a.String(key)
+	if err != nil {
+		return 0, err
+	}
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, a.invalid(key, err)
+	}
+	return u, nil
+}
+
+// Bool returns the value of the attribute as a boolean.
+func (a EventAttributes) Bool(key string) (bool, error) {
+	s, err := a.String(key)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, a.invalid(key, err)
+	}
+	return b, nil
+}
+
+// Bytes returns the value of the attribute, in hexadecimal, as bytes.
+func (a EventAttributes) Bytes(key string) ([]byte, error) {
+	s, err := a.String(key)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, a.invalid(key, err)
+	}
+	return bz, nil
+}
+
+func (a EventAttributes) invalid(key string, err error) error {
+	return fmt.Errorf("invalid attribute %s: %w", CompositeEventKey(a.event.Type, key), err)
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestEventBuilder(t *testing.T) {
+	event := NewEventBuilder("transfer").
+		String("sender", "alice").
+		Uint64("amount", math.MaxUint64).
+		Int64("balance", -42).
+		SetIndex(false).
+		Bool("success", true).
+		Bytes("memo", []byte{0xca, 0xfe}).
+		Build()
+
+	assert.Equal(t, abci.Event{Type: "transfer", Attributes: []abci.EventAttribute{
+		{Key: []byte("amount"), Value: []byte("18446744073709551615"), Index: true},
+		{Key: []byte("balance"), Value: []byte("-42"), Index: true},
+		{Key: []byte("memo"), Value: []byte("CAFE"), Index: false},
+		{Key: []byte("sender"), Value: []byte("alice"), Index: true},
+		{Key: []byte("success"), Value: []byte("true"), Index: false},
+	}}, event)
+
+	// the order the attributes are added in doesn't matter
+	assert.Equal(t, event, NewEventBuilder("transfer").
+		Int64("balance", -42).String("sender", "alice").Uint64("amount", math.MaxUint64).
+		SetIndex(false).Bool("success", true).Bytes("memo", []byte{0xca, 0xfe}).
+		Build())
+
+	attrs := ParseEventAttributes(event)
+	s, err := attrs.String("sender")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", s)
+	u, err := attrs.Uint64("amount")
+	require.NoError(t, err)
+	assert.EqualValues(t, uint64(math.MaxUint64), u)
+	i, err := attrs.Int64("balance")
+	require.NoError(t, err)
+	assert.EqualValues(t, -42, i)
+	b, err := attrs.Bool("success")
+	require.NoError(t, err)
+	assert.True(t, b)
+	bz, err := attrs.Bytes("memo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xca, 0xfe}, bz)
+
+	assert.True(t, attrs.Has("memo"))
+	assert.False(t, attrs.Has("receiver"))
+	_, err = attrs.String("receiver")
+	assert.Equal(t, ErrEventAttributeNotFound{EventType: "transfer", Key: "receiver"}, err)
+	_, err = attrs.Int64("amount")
+	assert.Error(t, err)
+	_, err = attrs.Uint64("balance")
+	assert.Error(t, err)
+	_, err = attrs.Bool("sender")
+	assert.Error(t, err)
+	_, err = attrs.Bytes("sender")
+	assert.Error(t, err)
+}
+
+func TestEventAttributesDuplicateKeys(t *testing.T) {
+	events := []abci.Event{
+		NewEventBuilder("message").String("action", "send").Build(),
+		NewEventBuilder("transfer").Int64("amount", 2).Int64("fee", 0).Int64("amount", 1).Build(),
+	}
+	assert.Equal(t, []abci.EventAttribute{
+		{Key: []byte("amount"), Value: []byte("2"), Index: true},
+		{Key: []byte("amount"), Value: []byte("1"), Index: true},
+		{Key: []byte("fee"), Value: []byte("0"), Index: true},
+	}, events[1].Attributes)
+
+	attrs, ok := FindEvent(events, "transfer")
+	require.True(t, ok)
+	amount, err := attrs.Int64("amount")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, amount)
+
+	_, ok = FindEvent(events, "burn")
+	assert.False(t, ok)
+
+	assert.Equal(t, "transfer.amount", CompositeEventKey("transfer", "amount"))
+}
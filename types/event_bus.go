@@ -44,7 +44,13 @@ func NewEventBus() *EventBus {
 // NewEventBusWithBufferCapacity returns a new event bus with the given buffer capacity.
 func NewEventBusWithBufferCapacity(cap int) *EventBus {
 	// capacity could be exposed later if needed
-	pubsub := tmpubsub.NewServer(tmpubsub.BufferCapacity(cap))
+	return NewEventBusWithOptions(tmpubsub.BufferCapacity(cap))
+}
+
+// NewEventBusWithOptions returns a new event bus whose pubsub server is
+// configured with the given options, e.g. tmpubsub.WithMetrics.
+func NewEventBusWithOptions(options ...tmpubsub.Option) *EventBus {
+	pubsub := tmpubsub.NewServer(options...)
 	b := &EventBus{pubsub: pubsub}
 	b.BaseService = *service.NewBaseService(nil, "EventBus", b)
 	return b
@@ -82,6 +88,18 @@ func (b *EventBus) Subscribe(
 	return b.pubsub.Subscribe(ctx, subscriber, query, outCapacity...)
 }
 
+// SubscribeWithPolicy subscribes with a buffer of outCapacity events and the
+// given policy when it is full (see tmpubsub.OverflowPolicy).
+func (b *EventBus) SubscribeWithPolicy(
+	ctx context.Context,
+	subscriber string,
+	query tmpubsub.Query,
+	outCapacity int,
+	policy tmpubsub.OverflowPolicy,
+) (Subscription, error) {
+	return b.pubsub.SubscribeWithPolicy(ctx, subscriber, query, outCapacity, policy)
+}
+
 // This method can be used for a local consensus explorer and synchronous
 // testing. Do not use for for public facing / untrusted subscriptions!
 func (b *EventBus) SubscribeUnbuffered(
@@ -124,7 +142,7 @@ func (b *EventBus) validateAndStringifyEvents(events []types.Event, logger log.L
 				continue
 			}
 
-			compositeTag := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			compositeTag := CompositeEventKey(event.Type, string(attr.Key))
 			result[compositeTag] = append(result[compositeTag], string(attr.Value))
 		}
 	}
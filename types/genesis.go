@@ -45,6 +45,12 @@ type GenesisDoc struct {
 	Validators      []GenesisValidator       `json:"validators,omitempty"`
 	AppHash         tmbytes.HexBytes         `json:"app_hash"`
 	AppState        json.RawMessage          `json:"app_state,omitempty"`
+
+	// BlockPartSizeBytes is the size of the block parts of the chain, or zero
+	// for BlockPartSizeBytes. Unlike the ConsensusParams, it can't be changed
+	// by the application. NOTE: it isn't completed with the default, so that
+	// the hash of the genesis of the existing chains doesn't change.
+	BlockPartSizeBytes uint32 `json:"block_part_size_bytes,omitempty"`
 }
 
 // SaveAs is a utility method for saving GenensisDoc as a JSON file.
@@ -88,6 +94,10 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		return err
 	}
 
+	if err := ValidateBlockPartSize(genDoc.BlockPartSizeBytes); err != nil {
+		return fmt.Errorf("invalid block_part_size_bytes: %w", err)
+	}
+
 	for i, v := range genDoc.Validators {
 		if v.Power == 0 {
 			return fmt.Errorf("the genesis file cannot contain validators with no voting power: %v", v)
@@ -107,6 +117,26 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 	return nil
 }
 
+// ValidateBasic validates the GenesisDoc as ValidateAndComplete, without
+// completing it with the default values (it is left unchanged), and checks
+// that the keys of the validators are of the types allowed by the consensus
+// params.
+func (genDoc *GenesisDoc) ValidateBasic() error {
+	docCopy := *genDoc
+	docCopy.Validators = append([]GenesisValidator(nil), genDoc.Validators...)
+	if err := docCopy.ValidateAndComplete(); err != nil {
+		return err
+	}
+	params := docCopy.ConsensusParams.Validator
+	for _, val := range docCopy.Validators {
+		if !IsValidPubkeyType(params, val.PubKey.Type()) {
+			return fmt.Errorf("validator %s has a %s key, which isn't allowed by the consensus params",
+				val.Address, val.PubKey.Type())
+		}
+	}
+	return nil
+}
+
 // Hash returns the hash of the GenesisDoc
 func (genDoc *GenesisDoc) Hash() []byte {
 	genDocBytes, err := tmjson.Marshal(genDoc)
@@ -135,14 +165,22 @@ func GenesisDocFromJSON(jsonBlob []byte) (*GenesisDoc, error) {
 }
 
 // GenesisDocFromFile reads JSON data from a file and unmarshalls it into a GenesisDoc.
+// The document is validated before its app_state is read (see OpenGenesisFile),
+// which is only loaded in memory once.
 func GenesisDocFromFile(genDocFile string) (*GenesisDoc, error) {
-	jsonBlob, err := os.ReadFile(genDocFile)
-	if err != nil {
+	if _, err := os.Stat(genDocFile); err != nil {
 		return nil, fmt.Errorf("couldn't read GenesisDoc file: %w", err)
 	}
-	genDoc, err := GenesisDocFromJSON(jsonBlob)
+	g, err := OpenGenesisFile(genDocFile)
 	if err != nil {
 		return nil, fmt.Errorf("error reading GenesisDoc at %s: %w", genDocFile, err)
 	}
-	return genDoc, nil
+	defer g.Close()
+	if err := g.Doc.ValidateAndComplete(); err != nil {
+		return nil, fmt.Errorf("error reading GenesisDoc at %s: %w", genDocFile, err)
+	}
+	if g.Doc.AppState, err = g.ReadAppState(); err != nil {
+		return nil, fmt.Errorf("error reading GenesisDoc at %s: %w", genDocFile, err)
+	}
+	return g.Doc, nil
 }
@@ -0,0 +1,174 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	tmjson "github.com/Finschia/ostracon/libs/json"
+)
+
+// GenesisFile is a genesis document read from a file by OpenGenesisFile. Its
+// app_state, which can be very large (e.g. several GB), isn't loaded in memory:
+// it is only checked to be valid JSON, token by token, and can be read from the
+// file afterwards, see AppStateReader and ReadAppState.
+type GenesisFile struct {
+	// Doc is the genesis document without its app_state.
+	Doc *GenesisDoc
+
+	file *os.File
+	// appStateStart and appStateEnd delimit the app_state in the file (from the
+	// colon after its key), if any (appStateStart is -1 otherwise)
+	appStateStart, appStateEnd int64
+}
+
+// OpenGenesisFile reads the genesis document in the file, except its app_state,
+// and checks that the file is a valid JSON object. The document isn't
+// validated, see GenesisDoc.ValidateBasic and ValidateAndComplete. The file
+// must be closed with Close.
+func OpenGenesisFile(path string) (*GenesisFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	g := &GenesisFile{file: f, appStateStart: -1}
+	if err := g.read(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read the genesis file %s: %w", path, err)
+	}
+	return g, nil
+}
+
+func (g *GenesisFile) read() error {
+	dec := json.NewDecoder(bufio.NewReader(g.file))
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return err
+	}
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected %v instead of a key", tok)
+		}
+		if key == "app_state" {
+			g.appStateStart = dec.InputOffset()
+			if err := skipJSONValue(dec); err != nil {
+				return fmt.Errorf("invalid app_state: %w", err)
+			}
+			g.appStateEnd = dec.InputOffset()
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		fields[key] = raw
+	}
+	if err := expectJSONDelim(dec, '}'); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the genesis document")
+	}
+
+	bz, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	g.Doc = new(GenesisDoc)
+	return tmjson.Unmarshal(bz, g.Doc)
+}
+
+// Close closes the genesis file.
+func (g *GenesisFile) Close() error {
+	return g.file.Close()
+}
+
+// HasAppState returns true if the genesis document has an app_state.
+func (g *GenesisFile) HasAppState() bool {
+	return g.appStateStart >= 0
+}
+
+// AppStateReader returns a reader of the app_state in the file, as it is.
+func (g *GenesisFile) AppStateReader() (io.Reader, error) {
+	if !g.HasAppState() {
+		return nil, errors.New("no app_state in the genesis file")
+	}
+	// the app_state starts after the key, so it is preceded by the colon
+	r := bufio.NewReader(io.NewSectionReader(g.file, g.appStateStart, g.appStateEnd-g.appStateStart))
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != ':' && b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return r, r.UnreadByte()
+		}
+	}
+}
+
+// ReadAppState reads the app_state in the file in memory, in a single buffer,
+// or returns nil if the document has no app_state or a null one (as
+// libs/json).
+func (g *GenesisFile) ReadAppState() (json.RawMessage, error) {
+	if !g.HasAppState() {
+		return nil, nil
+	}
+	r, err := g.AppStateReader()
+	if err != nil {
+		return nil, err
+	}
+	// the app_state is at most the size of its section
+	buf := make([]byte, g.appStateEnd-g.appStateStart)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if bytes.Equal(buf[:n], []byte("null")) {
+		return nil, nil
+	}
+	return buf[:n:n], nil
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// skipJSONValue reads the next value token by token, so that it isn't loaded in
+// memory at once.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/crypto/secp256k1"
+)
+
+func writeGenesisFile(t *testing.T, doc string) string {
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, os.WriteFile(path, []byte(doc), 0o644))
+	return path
+}
+
+func TestOpenGenesisFile(t *testing.T) {
+	path := writeGenesisFile(t, `{
+  "genesis_time": "2023-01-01T00:00:00Z",
+  "chain_id": "test-chain",
+  "app_state": {"bank": {"balances": [{"address": "a", "amount": "1"}]}},
+  "app_hash": ""
+}`)
+	g, err := OpenGenesisFile(path)
+	require.NoError(t, err)
+	defer g.Close()
+	assert.Equal(t, "test-chain", g.Doc.ChainID)
+	assert.Nil(t, g.Doc.AppState)
+	require.True(t, g.HasAppState())
+
+	r, err := g.AppStateReader()
+	require.NoError(t, err)
+	bz, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, `{"bank": {"balances": [{"address": "a", "amount": "1"}]}}`, string(bz))
+	appState, err := g.ReadAppState()
+	require.NoError(t, err)
+	assert.Equal(t, string(bz), string(appState))
+
+	assert.NoError(t, g.Doc.ValidateBasic())
+	// the document isn't completed
+	assert.Zero(t, g.Doc.InitialHeight)
+	assert.Nil(t, g.Doc.ConsensusParams)
+
+	path = writeGenesisFile(t, `{"chain_id": "test-chain"}`)
+	g, err = OpenGenesisFile(path)
+	require.NoError(t, err)
+	defer g.Close()
+	assert.False(t, g.HasAppState())
+	appState, err = g.ReadAppState()
+	require.NoError(t, err)
+	assert.Nil(t, appState)
+}
+
+func TestOpenGenesisFileBad(t *testing.T) {
+	testCases := []struct {
+		doc string
+		err string
+	}{
+		{`[]`, "expected {"},
+		{`{"chain_id": "test-chain", "app_state": {"bank": [}`, "invalid app_state"},
+		{`{"chain_id": "test-chain", "app_state": {"bank": []`, "invalid app_state"},
+		{`{"chain_id": "test-chain"} {}`, "unexpected data"},
+		{`{"chain_id": 1}`, ""},
+	}
+	for _, tc := range testCases {
+		_, err := OpenGenesisFile(writeGenesisFile(t, tc.doc))
+		require.Error(t, err, tc.doc)
+		assert.Contains(t, err.Error(), tc.err, tc.doc)
+	}
+}
+
+func TestGenesisDocFromFile(t *testing.T) {
+	for _, doc := range []string{
+		`{"chain_id": "test-chain", "genesis_time": "2023-01-01T00:00:00Z", "app_state": {"a" : [1, 2 ]} }`,
+		`{"chain_id": "test-chain", "genesis_time": "2023-01-01T00:00:00Z", "app_state": null}`,
+		`{"chain_id": "test-chain", "genesis_time": "2023-01-01T00:00:00Z", "app_state": "state"}`,
+		`{"chain_id": "test-chain", "genesis_time": "2023-01-01T00:00:00Z"}`,
+	} {
+		// the document is the same as if read in memory
+		genDoc, err := GenesisDocFromFile(writeGenesisFile(t, doc))
+		require.NoError(t, err, doc)
+		expected, err := GenesisDocFromJSON([]byte(doc))
+		require.NoError(t, err, doc)
+		assert.Equal(t, expected, genDoc, doc)
+		assert.Equal(t, expected.Hash(), genDoc.Hash(), doc)
+	}
+
+	_, err := GenesisDocFromFile(filepath.Join(t.TempDir(), "genesis.json"))
+	assert.Error(t, err)
+	_, err = GenesisDocFromFile(writeGenesisFile(t, `{"chain_id": "", "app_state": {}}`))
+	assert.Error(t, err)
+}
+
+func TestGenesisDocValidateBasic(t *testing.T) {
+	pubKey := secp256k1.GenPrivKey().PubKey()
+	genDoc := &GenesisDoc{
+		ChainID:    "test-chain",
+		Validators: []GenesisValidator{{PubKey: pubKey, Power: 10}},
+	}
+	// the validators of the default consensus params must have ed25519 keys
+	assert.Error(t, genDoc.ValidateBasic())
+	assert.Empty(t, genDoc.Validators[0].Address)
+
+	genDoc.ConsensusParams = DefaultConsensusParams()
+	genDoc.ConsensusParams.Validator.PubKeyTypes = []string{ABCIPubKeyTypeSecp256k1}
+	assert.NoError(t, genDoc.ValidateBasic())
+}
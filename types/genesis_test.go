@@ -18,8 +18,9 @@ func TestGenesisBad(t *testing.T) {
 		{},              // empty
 		{1, 1, 1, 1, 1}, // junk
 		[]byte(`{}`),    // empty
-		[]byte(`{"chain_id":"mychain","validators":[{}]}`),   // invalid validator
-		[]byte(`{"chain_id":"chain","initial_height":"-1"}`), // negative initial height
+		[]byte(`{"chain_id":"mychain","validators":[{}]}`),          // invalid validator
+		[]byte(`{"chain_id":"chain","initial_height":"-1"}`),        // negative initial height
+		[]byte(`{"chain_id":"chain","block_part_size_bytes":1024}`), // too small block part size
 		// missing pub_key type
 		[]byte(
 			`{"validators":[{"pub_key":{"value":"AT/+aaL1eB0477Mud9JMm8Sh8BIvOYlPGC9KkIUmFaE="},"power":"10","name":""}]}`,
@@ -16,13 +16,42 @@ const (
 	// MaxBlockSizeBytes is the maximum permitted size of the blocks.
 	MaxBlockSizeBytes = 104857600 // 100MB
 
-	// BlockPartSizeBytes is the size of one block part.
+	// BlockPartSizeBytes is the default size of one block part, used by the
+	// chains whose genesis doesn't set one (see GenesisDoc.BlockPartSizeBytes).
+	// It is also the minimum size of one block part.
 	BlockPartSizeBytes uint32 = 65536 // 64kB
 
-	// MaxBlockPartsCount is the maximum number of block parts.
+	// MaxBlockPartSizeBytes is the maximum size of one block part.
+	MaxBlockPartSizeBytes uint32 = 1048576 // 1MB
+
+	// MaxBlockPartsCount is the maximum number of block parts, whatever the size
+	// of the block parts of the chain.
 	MaxBlockPartsCount = (MaxBlockSizeBytes / BlockPartSizeBytes) + 1
 )
 
+// ValidateBlockPartSize returns an error if the size of the block parts isn't
+// zero (for the default size, BlockPartSizeBytes) or between
+// BlockPartSizeBytes and MaxBlockPartSizeBytes.
+func ValidateBlockPartSize(size uint32) error {
+	if size == 0 {
+		return nil
+	}
+	if size < BlockPartSizeBytes || size > MaxBlockPartSizeBytes {
+		return fmt.Errorf("block part size must be between %d and %d bytes, got %d",
+			BlockPartSizeBytes, MaxBlockPartSizeBytes, size)
+	}
+	return nil
+}
+
+// MaxBlockPartsCountForSize returns the maximum number of parts of the blocks
+// of at most maxBytes bytes, split into parts of the size.
+func MaxBlockPartsCountForSize(maxBytes int64, size uint32) uint32 {
+	if maxBytes <= 0 || maxBytes > MaxBlockSizeBytes {
+		maxBytes = MaxBlockSizeBytes
+	}
+	return uint32(maxBytes/int64(size)) + 1
+}
+
 // DefaultConsensusParams returns a default ConsensusParams.
 func DefaultConsensusParams() *tmproto.ConsensusParams {
 	return &tmproto.ConsensusParams{
@@ -75,6 +75,32 @@ func makeParams(
 	}
 }
 
+func TestValidateBlockPartSize(t *testing.T) {
+	testCases := []struct {
+		size  uint32
+		valid bool
+	}{
+		{0, true},
+		{BlockPartSizeBytes, true},
+		{4 * BlockPartSizeBytes, true},
+		{MaxBlockPartSizeBytes, true},
+		{BlockPartSizeBytes - 1, false},
+		{1, false},
+		{MaxBlockPartSizeBytes + 1, false},
+	}
+	for _, tc := range testCases {
+		if tc.valid {
+			assert.NoError(t, ValidateBlockPartSize(tc.size), "size %d", tc.size)
+		} else {
+			assert.Error(t, ValidateBlockPartSize(tc.size), "size %d", tc.size)
+		}
+	}
+
+	assert.EqualValues(t, 2, MaxBlockPartsCountForSize(int64(BlockPartSizeBytes), BlockPartSizeBytes))
+	assert.EqualValues(t, MaxBlockPartsCount, MaxBlockPartsCountForSize(0, BlockPartSizeBytes))
+	assert.EqualValues(t, 101, MaxBlockPartsCountForSize(MaxBlockSizeBytes, MaxBlockPartSizeBytes))
+}
+
 func TestConsensusParamsHash(t *testing.T) {
 	params := []tmproto.ConsensusParams{
 		makeParams(4, 2, 10, 3, 1, valEd25519),
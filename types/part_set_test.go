@@ -116,7 +116,7 @@ func TestPartValidateBasic(t *testing.T) {
 		expectErr    bool
 	}{
 		{"Good Part", func(pt *Part) {}, false},
-		{"Too big part", func(pt *Part) { pt.Bytes = make([]byte, BlockPartSizeBytes+1) }, true},
+		{"Too big part", func(pt *Part) { pt.Bytes = make([]byte, MaxBlockPartSizeBytes+1) }, true},
 		{"Too big proof", func(pt *Part) {
 			pt.Proof = merkle.Proof{
 				Total:    1,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/crypto/ed25519"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
 )
 
 // PrivValidator defines the functionality of a local Ostracon validator
@@ -22,6 +23,28 @@ type PrivValidator interface {
 	GenerateVRFProof(message []byte) (crypto.Proof, error)
 }
 
+// KeyRotatingPrivValidator is a PrivValidator whose key can be rotated at a
+// height, e.g. privval.FilePV with a staged key: the votes and proposals are
+// signed with the key of their height.
+type KeyRotatingPrivValidator interface {
+	PrivValidator
+
+	// GetPubKeyAt returns the public key signing at the height.
+	GetPubKeyAt(height int64) (crypto.PubKey, error)
+	// GenerateVRFProofAt generates the proof of the message with the key of
+	// the height.
+	GenerateVRFProofAt(height int64, message []byte) (crypto.Proof, error)
+}
+
+// VoteExtensionSigner is a PrivValidator which signs the extensions the
+// application adds to the precommits of the validator, see VoteExtension.
+type VoteExtensionSigner interface {
+	PrivValidator
+
+	// SignVoteExtension sets the signature of the vote extension.
+	SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error
+}
+
 type PrivValidatorsByAddress []PrivValidator
 
 func (pvs PrivValidatorsByAddress) Len() int {
@@ -104,6 +127,21 @@ func (pv MockPV) SignProposal(chainID string, proposal *tmproto.Proposal) error
 	return nil
 }
 
+// SignVoteExtension implements VoteExtensionSigner.
+func (pv MockPV) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	useChainID := chainID
+	if pv.breakVoteSigning {
+		useChainID = "incorrect-chain-id"
+	}
+
+	sig, err := pv.PrivKey.Sign(VoteExtensionSignBytes(useChainID, ext))
+	if err != nil {
+		return err
+	}
+	ext.Signature = sig
+	return nil
+}
+
 func (pv MockPV) ExtractIntoValidator(votingPower int64) *Validator {
 	pubKey, _ := pv.GetPubKey()
 	return &Validator{
@@ -146,6 +184,11 @@ func (pv *ErroringMockPV) SignProposal(chainID string, proposal *tmproto.Proposa
 	return ErroringMockPVErr
 }
 
+// Implements VoteExtensionSigner.
+func (pv *ErroringMockPV) SignVoteExtension(chainID string, ext *ocproto.VoteExtension) error {
+	return ErroringMockPVErr
+}
+
 // NewErroringMockPV returns a MockPV that fails on each signing request. Again, for testing only.
 
 func NewErroringMockPV() *ErroringMockPV {
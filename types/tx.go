@@ -75,6 +75,25 @@ func (txs Txs) IndexByHash(hash []byte) int {
 	return -1
 }
 
+// ToSliceOfBytes returns the txs as a slice of byte slices, e.g. to send them
+// to the application.
+func (txs Txs) ToSliceOfBytes() [][]byte {
+	txBzs := make([][]byte, len(txs))
+	for i := 0; i < len(txs); i++ {
+		txBzs[i] = txs[i]
+	}
+	return txBzs
+}
+
+// ToTxs converts the byte slices, e.g. returned by the application, to Txs.
+func ToTxs(txBzs [][]byte) Txs {
+	txs := make([]Tx, len(txBzs))
+	for i := 0; i < len(txBzs); i++ {
+		txs[i] = txBzs[i]
+	}
+	return txs
+}
+
 // Proof returns a simple merkle proof for this node.
 // Panics if i < 0 or i >= len(txs)
 // TODO: optimize this!
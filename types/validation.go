@@ -1,9 +1,12 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
 	"time"
 
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/batch"
 	"github.com/Finschia/ostracon/crypto/ed25519"
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	tmtime "github.com/Finschia/ostracon/types/time"
@@ -43,3 +46,194 @@ func ValidateHash(h []byte) error {
 func ValidateProof(h []byte) error {
 	return ed25519.ValidateProof(h)
 }
+
+// VerifyEntropy checks that the entropy of the block at the given height was
+// generated by the legitimately elected proposer. lastProofHash and lastHeight
+// are the VRF output and the height of the previous block (the genesis hash
+// and 0 for the initial block) and vals is the validator set of the block. It
+// returns the VRF output of the entropy, which becomes lastProofHash for the
+// next height.
+func VerifyEntropy(
+	entropy Entropy,
+	vals *ValidatorSet,
+	lastProofHash []byte,
+	lastHeight int64,
+	height int64,
+	proposerAddress Address,
+) (crypto.Output, error) {
+	proposer, err := VerifyProposer(vals, lastProofHash, height, entropy.Round, proposerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	message := MakeRoundHash(lastProofHash, lastHeight, entropy.Round)
+	output, err := entropy.VerifyProof(proposer.PubKey, message)
+	if err != nil {
+		return nil, NewErrInvalidProof(fmt.Sprintf(
+			"verification failed: %s; proof: %v, height=%d, round=%d, addr: %v",
+			err.Error(), entropy.Proof, height, entropy.Round, proposerAddress))
+	}
+
+	return output, nil
+}
+
+// VerifyProposer checks that proposerAddress is the address of the proposer
+// elected from vals in the round at the given height, with lastProofHash the
+// VRF output of the previous block (see Entropy.ProofHash), and returns it.
+func VerifyProposer(
+	vals *ValidatorSet,
+	lastProofHash []byte,
+	height int64,
+	round int32,
+	proposerAddress Address,
+) (*Validator, error) {
+	if vals.IsNilOrEmpty() {
+		return nil, fmt.Errorf("empty validator set")
+	}
+
+	proposer := vals.SelectProposer(lastProofHash, height, round)
+	if !bytes.Equal(proposerAddress.Bytes(), proposer.Address.Bytes()) {
+		return nil, fmt.Errorf("block.ProposerAddress, %X, is not the proposer %X",
+			proposerAddress,
+			proposer.Address,
+		)
+	}
+	return proposer, nil
+}
+
+// batchVerifyThreshold is the number of signatures from which they're
+// verified in batches.
+const batchVerifyThreshold = 2
+
+// SignatureBatch verifies signatures at once: in a batch for each key type
+// supporting the batch verification (see crypto/batch), individually
+// otherwise. If a batch is invalid, its signatures are verified individually
+// to find the invalid ones.
+type SignatureBatch struct {
+	pubKeys []crypto.PubKey
+	msgs    [][]byte
+	sigs    [][]byte
+}
+
+// NewSignatureBatch returns an empty SignatureBatch.
+func NewSignatureBatch() *SignatureBatch {
+	return &SignatureBatch{}
+}
+
+// Add adds the signature sig of msg by pubKey.
+func (b *SignatureBatch) Add(pubKey crypto.PubKey, msg, sig []byte) {
+	b.pubKeys = append(b.pubKeys, pubKey)
+	b.msgs = append(b.msgs, msg)
+	b.sigs = append(b.sigs, sig)
+}
+
+// Len returns the number of signatures added.
+func (b *SignatureBatch) Len() int {
+	return len(b.sigs)
+}
+
+// Verify returns whether each signature is valid, in the order they were
+// added.
+func (b *SignatureBatch) Verify() []bool {
+	valid := make([]bool, len(b.sigs))
+	if len(b.sigs) < batchVerifyThreshold {
+		for i := range b.sigs {
+			valid[i] = b.pubKeys[i].VerifySignature(b.msgs[i], b.sigs[i])
+		}
+		return valid
+	}
+
+	type keyBatch struct {
+		bv crypto.BatchVerifier
+		is []int
+	}
+	var (
+		batches = make(map[string]*keyBatch)
+		// the signatures not verified in batches
+		is []int
+	)
+	for i, pubKey := range b.pubKeys {
+		kb, ok := batches[pubKey.Type()]
+		if !ok {
+			bv, supported := batch.CreateBatchVerifier(pubKey)
+			if supported {
+				kb = &keyBatch{bv: bv}
+				batches[pubKey.Type()] = kb
+			}
+		}
+		if kb == nil || kb.bv.Add(pubKey, b.msgs[i], b.sigs[i]) != nil {
+			is = append(is, i)
+			continue
+		}
+		kb.is = append(kb.is, i)
+	}
+
+	b.verifyIndividually(valid, is)
+	for _, kb := range batches {
+		if ok, _ := kb.bv.Verify(); !ok {
+			b.verifyIndividually(valid, kb.is)
+			continue
+		}
+		for _, i := range kb.is {
+			valid[i] = true
+		}
+	}
+	return valid
+}
+
+// FirstInvalid returns the index of the first invalid signature added, -1 if
+// they're all valid.
+func (b *SignatureBatch) FirstInvalid() int {
+	for i, ok := range b.Verify() {
+		if !ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// verifyIndividually verifies the signatures at the indexes is.
+func (b *SignatureBatch) verifyIndividually(valid []bool, is []int) {
+	for _, i := range is {
+		valid[i] = b.pubKeys[i].VerifySignature(b.msgs[i], b.sigs[i])
+	}
+}
+
+// commitSigVerifier verifies the signatures of a commit at once with a
+// SignatureBatch.
+type commitSigVerifier struct {
+	chainID string
+	commit  *Commit
+	idxs    []int32 // indexes in the commit of the signatures added
+	batch   *SignatureBatch
+}
+
+func newCommitSigVerifier(chainID string, commit *Commit) *commitSigVerifier {
+	return &commitSigVerifier{chainID: chainID, commit: commit, batch: NewSignatureBatch()}
+}
+
+// add adds the signature of the commit at idx, signed with pubKey.
+func (v *commitSigVerifier) add(idx int32, pubKey crypto.PubKey) {
+	v.idxs = append(v.idxs, idx)
+	v.batch.Add(pubKey, v.commit.VoteSignBytes(v.chainID, idx), v.commit.Signatures[idx].Signature)
+}
+
+// verify returns an error with the first invalid signature added, in the
+// order of the commit.
+func (v *commitSigVerifier) verify() error {
+	invalid := v.firstInvalid()
+	if invalid < 0 {
+		return nil
+	}
+	return fmt.Errorf("wrong signature (#%d): %X", invalid, v.commit.Signatures[invalid].Signature)
+}
+
+// firstInvalid returns the index in the commit of the first invalid
+// signature, -1 if they're all valid.
+func (v *commitSigVerifier) firstInvalid() int32 {
+	i := v.batch.FirstInvalid()
+	if i < 0 {
+		return -1
+	}
+	return v.idxs[i]
+}
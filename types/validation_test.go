@@ -0,0 +1,118 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/crypto"
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/secp256k1"
+	tmbytes "github.com/Finschia/ostracon/libs/bytes"
+	tmrand "github.com/Finschia/ostracon/libs/rand"
+)
+
+func TestVerifyEntropy(t *testing.T) {
+	const height = int64(10)
+	const round = int32(2)
+	vals, privVals := RandValidatorSet(4, 10)
+	lastProof, err := ed25519.GenPrivKey().VRFProve(tmrand.Bytes(10))
+	require.NoError(t, err)
+	lastEntropy := Entropy{Proof: tmbytes.HexBytes(lastProof)}
+	lastProofHash, err := lastEntropy.ProofHash()
+	require.NoError(t, err)
+
+	// the entropy generated by the elected proposer
+	proposer := vals.SelectProposer(lastProofHash, height, round)
+	var entropy Entropy
+	message := MakeRoundHash(lastProofHash, height-1, round)
+	for _, pv := range privVals {
+		pubKey, err := pv.GetPubKey()
+		require.NoError(t, err)
+		if bytes.Equal(pubKey.Address(), proposer.Address) {
+			proof, err := pv.GenerateVRFProof(message)
+			require.NoError(t, err)
+			entropy.Populate(round, proof)
+		}
+	}
+	require.NotEmpty(t, entropy.Proof)
+
+	output, err := VerifyEntropy(entropy, vals, lastProofHash, height-1, height, proposer.Address)
+	require.NoError(t, err)
+	proofHash, err := entropy.ProofHash()
+	require.NoError(t, err)
+	assert.EqualValues(t, proofHash, output)
+
+	// the proof of the proposer only
+	output, err = entropy.VerifyProof(proposer.PubKey, message)
+	require.NoError(t, err)
+	assert.EqualValues(t, proofHash, output)
+	_, err = entropy.VerifyProof(ed25519.GenPrivKey().PubKey(), message)
+	assert.Error(t, err)
+	_, err = entropy.VerifyProof(proposer.PubKey, MakeRoundHash(lastProofHash, height-1, round+1))
+	assert.Error(t, err)
+	_, err = entropy.VerifyProof(nil, message)
+	assert.Error(t, err)
+
+	// another proposer
+	var other *Validator
+	for _, val := range vals.Validators {
+		if !bytes.Equal(val.Address, proposer.Address) {
+			other = val
+		}
+	}
+	_, err = VerifyProposer(vals, lastProofHash, height, round, other.Address)
+	assert.Error(t, err)
+	_, err = VerifyEntropy(entropy, vals, lastProofHash, height-1, height, other.Address)
+	assert.Error(t, err)
+	elected, err := VerifyProposer(vals, lastProofHash, height, round, proposer.Address)
+	require.NoError(t, err)
+	assert.Equal(t, proposer, elected)
+	_, err = VerifyProposer(NewValidatorSet(nil), lastProofHash, height, round, proposer.Address)
+	assert.Error(t, err)
+
+	// another round
+	_, err = VerifyEntropy(Entropy{Round: round + 1, Proof: entropy.Proof}, vals, lastProofHash,
+		height-1, height, vals.SelectProposer(lastProofHash, height, round+1).Address)
+	assert.Error(t, err)
+}
+
+func TestSignatureBatch(t *testing.T) {
+	msg := []byte("message")
+	var keys []crypto.PrivKey
+	for i := 0; i < 3; i++ {
+		keys = append(keys, ed25519.GenPrivKey(), secp256k1.GenPrivKey())
+	}
+	sign := func(key crypto.PrivKey) []byte {
+		sig, err := key.Sign(msg)
+		require.NoError(t, err)
+		return sig
+	}
+
+	b := NewSignatureBatch()
+	assert.Equal(t, -1, b.FirstInvalid())
+	for _, key := range keys {
+		b.Add(key.PubKey(), msg, sign(key))
+	}
+	assert.Equal(t, len(keys), b.Len())
+	assert.Equal(t, -1, b.FirstInvalid())
+
+	// the invalid signatures are found in the batch of each key type
+	b = NewSignatureBatch()
+	for i, key := range keys {
+		sig := sign(key)
+		if i == 3 || i == 4 {
+			sig = sign(keys[0])
+		}
+		b.Add(key.PubKey(), msg, sig)
+	}
+	assert.Equal(t, []bool{true, true, true, false, false, true}, b.Verify())
+	assert.Equal(t, 3, b.FirstInvalid())
+
+	// and when verifying a single signature
+	b = NewSignatureBatch()
+	b.Add(keys[1].PubKey(), msg, sign(keys[0]))
+	assert.Equal(t, 0, b.FirstInvalid())
+}
@@ -12,6 +12,7 @@ import (
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/crypto/merkle"
 	"github.com/Finschia/ostracon/crypto/tmhash"
 	tmmath "github.com/Finschia/ostracon/libs/math"
@@ -634,6 +635,9 @@ func (vals *ValidatorSet) UpdateWithChangeSet(changes []*Validator) error {
 // application that depends on the LastCommitInfo sent in BeginBlock, which
 // includes which validators signed. For instance, Gaia incentivizes proposers
 // with a bonus for including more than +2/3 of the signatures.
+//
+// The signatures are verified in a batch for each key type supporting it (see
+// crypto/batch).
 func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 	height int64, commit *Commit) error {
 
@@ -656,6 +660,7 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 
 	talliedVotingPower := int64(0)
 	votingPowerNeeded := vals.TotalVotingPower() * 2 / 3 // FIXME: 🏺 arithmetic overflow
+	sigs := newCommitSigVerifier(chainID, commit)
 	for idx, commitSig := range commit.Signatures {
 		if commitSig.Absent() {
 			continue // OK, some signatures can be absent.
@@ -665,12 +670,8 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 		// This means we don't need the validator address or to do any lookup.
 		val := vals.Validators[idx]
 
-		// Validate signature.
-		voteSignBytes := commit.VoteSignBytes(chainID, int32(idx))
-		if !val.PubKey.VerifySignature(voteSignBytes, commitSig.Signature) {
-			return fmt.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
-		}
-		// Good!
+		// The signatures are verified at once below.
+		sigs.add(int32(idx), val.PubKey)
 		if commitSig.ForBlock() {
 			talliedVotingPower += val.VotingPower
 		}
@@ -679,6 +680,9 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 		// validator availability.
 		// }
 	}
+	if err := sigs.verify(); err != nil {
+		return err
+	}
 
 	if got, needed := talliedVotingPower, votingPowerNeeded; got <= needed {
 		return ErrNotEnoughVotingPowerSigned{Got: got, Needed: needed}
@@ -696,6 +700,35 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 func (vals *ValidatorSet) VerifyCommitLight(chainID string, blockID BlockID,
 	height int64, commit *Commit) error {
 
+	sigs := newCommitSigVerifier(chainID, commit)
+	err := vals.tallyCommitLight(blockID, height, commit, sigs.add)
+	if err != nil && !errors.As(err, &ErrNotEnoughVotingPowerSigned{}) {
+		return err
+	}
+	if err := sigs.verify(); err != nil {
+		return err
+	}
+	return err
+}
+
+// AddCommitLightSignatures checks the commit like VerifyCommitLight, but adds
+// the signatures VerifyCommitLight would verify to the batch instead, for them
+// to be verified with others. The commit is verified once they're all valid
+// and no error is returned.
+func (vals *ValidatorSet) AddCommitLightSignatures(chainID string, blockID BlockID,
+	height int64, commit *Commit, batch *SignatureBatch) error {
+
+	return vals.tallyCommitLight(blockID, height, commit, func(idx int32, pubKey crypto.PubKey) {
+		batch.Add(pubKey, commit.VoteSignBytes(chainID, idx), commit.Signatures[idx].Signature)
+	})
+}
+
+// tallyCommitLight checks the commit, and adds the signatures for the block of
+// +2/3 of the set, returning ErrNotEnoughVotingPowerSigned if there aren't
+// enough of them.
+func (vals *ValidatorSet) tallyCommitLight(blockID BlockID, height int64, commit *Commit,
+	add func(idx int32, pubKey crypto.PubKey)) error {
+
 	if vals == nil || commit == nil {
 		return fmt.Errorf("invalid nil vals or commit:[%v] or [%v]", vals, commit)
 	}
@@ -725,15 +758,10 @@ func (vals *ValidatorSet) VerifyCommitLight(chainID string, blockID BlockID,
 		// This means we don't need the validator address or to do any lookup.
 		val := vals.Validators[idx]
 
-		// Validate signature.
-		voteSignBytes := commit.VoteSignBytes(chainID, int32(idx))
-		if !val.PubKey.VerifySignature(voteSignBytes, commitSig.Signature) {
-			return fmt.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
-		}
-
+		add(int32(idx), val.PubKey)
 		talliedVotingPower += val.VotingPower
 
-		// return as soon as +2/3 of the signatures are verified
+		// only verify the signatures of +2/3 of the voting power
 		if talliedVotingPower > votingPowerNeeded {
 			return nil
 		}
@@ -768,6 +796,7 @@ func (vals *ValidatorSet) VerifyCommitLightTrusting(chainID string, commit *Comm
 	}
 	votingPowerNeeded := totalVotingPowerMulByNumerator / int64(trustLevel.Denominator)
 
+	sigs := newCommitSigVerifier(chainID, commit)
 	for idx, commitSig := range commit.Signatures {
 		// No need to verify absent or nil votes.
 		if !commitSig.ForBlock() {
@@ -786,19 +815,17 @@ func (vals *ValidatorSet) VerifyCommitLightTrusting(chainID string, commit *Comm
 			}
 			seenVals[valIdx] = idx
 
-			// Verify Signature
-			voteSignBytes := commit.VoteSignBytes(chainID, int32(idx))
-			if !val.PubKey.VerifySignature(voteSignBytes, commitSig.Signature) {
-				return fmt.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
-			}
-
+			sigs.add(int32(idx), val.PubKey)
 			talliedVotingPower += val.VotingPower
 
 			if talliedVotingPower > votingPowerNeeded {
-				return nil
+				return sigs.verify()
 			}
 		}
 	}
+	if err := sigs.verify(); err != nil {
+		return err
+	}
 
 	return ErrNotEnoughVotingPowerSigned{Got: talliedVotingPower, Needed: votingPowerNeeded}
 }
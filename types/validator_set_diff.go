@@ -0,0 +1,116 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Finschia/ostracon/crypto"
+)
+
+// ValidatorSetDiff is the difference between two validator sets, so that the
+// validator set at a height can be transferred (or stored) as the changes from
+// the one at a previous height, instead of in full.
+type ValidatorSetDiff struct {
+	// Updates are the validators added to the set and the ones whose voting
+	// power changed.
+	Updates []*Validator `json:"updates"`
+	// Removals are the addresses of the validators removed from the set.
+	Removals []Address `json:"removals"`
+}
+
+// NewValidatorSetDiff splits the changes of a validator set (as returned by
+// EndBlock, i.e. with a zero voting power for the removed validators) into a
+// ValidatorSetDiff.
+func NewValidatorSetDiff(changes []*Validator) *ValidatorSetDiff {
+	diff := &ValidatorSetDiff{}
+	for _, change := range changes {
+		if change.VotingPower == 0 {
+			diff.Removals = append(diff.Removals, change.Address)
+		} else {
+			diff.Updates = append(diff.Updates, change)
+		}
+	}
+	return diff
+}
+
+// IsEmpty returns true if the diff has no changes.
+func (diff *ValidatorSetDiff) IsEmpty() bool {
+	return diff == nil || (len(diff.Updates) == 0 && len(diff.Removals) == 0)
+}
+
+// ValidateBasic performs basic validation.
+func (diff *ValidatorSetDiff) ValidateBasic() error {
+	if diff == nil {
+		return errors.New("nil validator set diff")
+	}
+	for _, val := range diff.Updates {
+		if val == nil {
+			return errors.New("nil validator update")
+		}
+		if err := val.ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid validator update %v: %w", val, err)
+		}
+		if val.VotingPower == 0 {
+			return fmt.Errorf("validator update %v has no voting power", val)
+		}
+		if !bytes.Equal(val.Address, val.PubKey.Address()) {
+			return fmt.Errorf("validator update %v has the address of another public key", val)
+		}
+	}
+	for _, address := range diff.Removals {
+		if len(address) != crypto.AddressSize {
+			return fmt.Errorf("invalid removed validator address %X", address)
+		}
+	}
+	return nil
+}
+
+// ComputeDiff returns the diff turning the validator set into the given one.
+// The updates are in the order of the given set, and the removals in the order
+// of the validator set.
+func (vals *ValidatorSet) ComputeDiff(to *ValidatorSet) *ValidatorSetDiff {
+	diff := &ValidatorSetDiff{}
+	for _, val := range to.Validators {
+		_, prev := vals.GetByAddress(val.Address)
+		if prev == nil || prev.VotingPower != val.VotingPower {
+			diff.Updates = append(diff.Updates, val.Copy())
+		}
+	}
+	for _, val := range vals.Validators {
+		if !to.HasAddress(val.Address) {
+			diff.Removals = append(diff.Removals, val.Address)
+		}
+	}
+	return diff
+}
+
+// ApplyDiff returns a copy of the validator set with the diff applied (see
+// UpdateWithChangeSet), which has the same validators as the set the diff was
+// computed to. The validator set isn't modified.
+//
+// NOTE: the proposer priorities of the diff are ignored: the ones of the added
+// validators are computed by UpdateWithChangeSet, so the resulting set only
+// has the same priorities (which aren't part of its hash) as the one the diff
+// was computed to if it was the result of UpdateWithChangeSet too.
+func (vals *ValidatorSet) ApplyDiff(diff *ValidatorSetDiff) (*ValidatorSet, error) {
+	if err := diff.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	changes := make([]*Validator, 0, len(diff.Updates)+len(diff.Removals))
+	for _, val := range diff.Updates {
+		changes = append(changes, NewValidator(val.PubKey, val.VotingPower))
+	}
+	for _, address := range diff.Removals {
+		_, val := vals.GetByAddress(address)
+		if val == nil {
+			return nil, fmt.Errorf("failed to find validator %X to remove", address)
+		}
+		changes = append(changes, &Validator{Address: address, PubKey: val.PubKey})
+	}
+	newVals := vals.Copy()
+	if err := newVals.UpdateWithChangeSet(changes); err != nil {
+		return nil, err
+	}
+	return newVals, nil
+}
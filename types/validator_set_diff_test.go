@@ -0,0 +1,68 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorSetDiff(t *testing.T) {
+	vals, _ := RandValidatorSet(10, 10)
+	added, _ := RandValidator(false, 5)
+	updated := vals.Validators[3].Copy()
+	updated.VotingPower = 42
+	removed := vals.Validators[7]
+
+	newVals := vals.Copy()
+	require.NoError(t, newVals.UpdateWithChangeSet([]*Validator{
+		added, updated, {Address: removed.Address, PubKey: removed.PubKey},
+	}))
+
+	diff := vals.ComputeDiff(newVals)
+	require.NoError(t, diff.ValidateBasic())
+	assert.Len(t, diff.Updates, 2)
+	assert.Equal(t, []Address{removed.Address}, diff.Removals)
+
+	applied, err := vals.ApplyDiff(diff)
+	require.NoError(t, err)
+	assert.Equal(t, newVals.Hash(), applied.Hash())
+	// the priorities are the ones computed by UpdateWithChangeSet
+	assert.Equal(t, newVals, applied)
+	// and the validator set isn't modified
+	assert.Equal(t, 10, vals.Size())
+
+	// the diff of the changes of EndBlock is the same
+	changesDiff := NewValidatorSetDiff([]*Validator{added, updated, {Address: removed.Address, VotingPower: 0}})
+	applied, err = vals.ApplyDiff(changesDiff)
+	require.NoError(t, err)
+	assert.Equal(t, newVals.Hash(), applied.Hash())
+
+	assert.True(t, newVals.ComputeDiff(newVals).IsEmpty())
+	// the reverse diff restores the validators
+	applied, err = newVals.ApplyDiff(newVals.ComputeDiff(vals))
+	require.NoError(t, err)
+	assert.Equal(t, vals.Hash(), applied.Hash())
+}
+
+func TestValidatorSetDiffInvalid(t *testing.T) {
+	vals, _ := RandValidatorSet(3, 10)
+	other, _ := RandValidator(false, 5)
+	wrongAddress := other.Copy()
+	wrongAddress.Address = vals.Validators[0].Address
+
+	testCases := map[string]*ValidatorSetDiff{
+		"nil diff":            nil,
+		"nil update":          {Updates: []*Validator{nil}},
+		"no voting power":     {Updates: []*Validator{{Address: other.Address, PubKey: other.PubKey}}},
+		"wrong address":       {Updates: []*Validator{wrongAddress}},
+		"invalid removal":     {Removals: []Address{{0x01}}},
+		"unknown removal":     {Removals: []Address{other.Address}},
+		"duplicate":           {Updates: []*Validator{vals.Validators[0]}, Removals: []Address{vals.Validators[0].Address}},
+		"removing everything": vals.ComputeDiff(&ValidatorSet{}),
+	}
+	for name, diff := range testCases {
+		_, err := vals.ApplyDiff(diff)
+		assert.Error(t, err, name)
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/Finschia/ostracon/crypto"
 	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/secp256k1"
 	tmmath "github.com/Finschia/ostracon/libs/math"
 	tmrand "github.com/Finschia/ostracon/libs/rand"
 )
@@ -747,6 +748,55 @@ func TestValidatorSet_VerifyCommit_CheckAllSignatures(t *testing.T) {
 	}
 }
 
+// the commits of the validators of several key types, ed25519 supporting
+// the batch verification and secp256k1 not, are verified
+func TestValidatorSet_VerifyCommit_MixedKeyTypes(t *testing.T) {
+	var (
+		chainID = "test_chain_id"
+		h       = int64(3)
+		blockID = makeBlockIDRandom()
+	)
+
+	var pvs []PrivValidator
+	var vals []*Validator
+	for i := 0; i < 6; i++ {
+		var privKey crypto.PrivKey = ed25519.GenPrivKey()
+		if i%2 == 1 {
+			privKey = secp256k1.GenPrivKey()
+		}
+		pvs = append(pvs, NewMockPVWithParams(privKey, false, false))
+		vals = append(vals, NewValidator(privKey.PubKey(), 10))
+	}
+	valSet := NewValidatorSet(vals)
+	// the private validators in the order of the set
+	sorted := make([]PrivValidator, len(pvs))
+	for i, val := range valSet.Validators {
+		for _, pv := range pvs {
+			if pubKey, _ := pv.GetPubKey(); pubKey.Equals(val.PubKey) {
+				sorted[i] = pv
+			}
+		}
+	}
+	voteSet := NewVoteSet(chainID, h, 0, tmproto.PrecommitType, valSet)
+	commit, err := MakeCommit(blockID, h, 0, voteSet, sorted, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, valSet.VerifyCommit(chainID, blockID, h, commit))
+	require.NoError(t, valSet.VerifyCommitLight(chainID, blockID, h, commit))
+
+	// the first invalid signature is reported, whatever its key type
+	for _, idx := range []int{4, 1} {
+		vote := voteSet.GetByIndex(int32(idx))
+		v := vote.ToProto()
+		require.NoError(t, sorted[idx].SignVote("CentaurusA", v))
+		vote.Signature = v.Signature
+		commit.Signatures[idx] = vote.CommitSig()
+	}
+	err = valSet.VerifyCommit(chainID, blockID, h, commit)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wrong signature (#1)")
+	}
+}
+
 func TestValidatorSet_VerifyCommitLight_ReturnsAsSoonAsMajorityOfVotingPowerSigned(t *testing.T) {
 	var (
 		chainID = "test_chain_id"
@@ -770,6 +820,37 @@ func TestValidatorSet_VerifyCommitLight_ReturnsAsSoonAsMajorityOfVotingPowerSign
 	assert.NoError(t, err)
 }
 
+func TestValidatorSet_AddCommitLightSignatures(t *testing.T) {
+	var (
+		chainID = "test_chain_id"
+		h       = int64(3)
+		blockID = makeBlockIDRandom()
+	)
+
+	voteSet, valSet, vals := randVoteSet(h, 0, tmproto.PrecommitType, 4, 10)
+	commit, err := MakeCommit(blockID, h, 0, voteSet, vals, time.Now())
+	require.NoError(t, err)
+
+	// the signatures of +2/3 are added, not verified
+	batch := NewSignatureBatch()
+	require.NoError(t, valSet.AddCommitLightSignatures(chainID, blockID, h, commit, batch))
+	assert.Equal(t, 3, batch.Len())
+	assert.Equal(t, -1, batch.FirstInvalid())
+
+	commit.Signatures[1].Signature = commit.Signatures[0].Signature
+	batch = NewSignatureBatch()
+	require.NoError(t, valSet.AddCommitLightSignatures(chainID, blockID, h, commit, batch))
+	assert.Equal(t, 1, batch.FirstInvalid())
+
+	// the commit is checked
+	err = valSet.AddCommitLightSignatures(chainID, blockID, h+1, commit, NewSignatureBatch())
+	assert.Error(t, err)
+	commit.Signatures[1] = NewCommitSigAbsent()
+	commit.Signatures[2] = NewCommitSigAbsent()
+	err = valSet.AddCommitLightSignatures(chainID, blockID, h, commit, NewSignatureBatch())
+	assert.ErrorAs(t, err, &ErrNotEnoughVotingPowerSigned{})
+}
+
 func TestValidatorSet_VerifyCommitLightTrusting_ReturnsAsSoonAsTrustLevelOfVotingPowerSigned(t *testing.T) {
 	var (
 		chainID = "test_chain_id"
@@ -1718,3 +1799,26 @@ func BenchmarkUpdates(b *testing.B) {
 		assert.NoError(b, valSetCopy.UpdateWithChangeSet(newValList))
 	}
 }
+
+func BenchmarkValidatorSet_VerifyCommit(b *testing.B) {
+	for _, n := range []int{1, 10, 100, 200} {
+		n := n
+		b.Run(fmt.Sprintf("%d validators", n), func(b *testing.B) {
+			var (
+				chainID = "test_chain_id"
+				h       = int64(3)
+				blockID = makeBlockIDRandom()
+			)
+			voteSet, valSet, vals := randVoteSet(h, 0, tmproto.PrecommitType, n, 10)
+			commit, err := MakeCommit(blockID, h, 0, voteSet, vals, time.Now())
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := valSet.VerifyCommit(chainID, blockID, h, commit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
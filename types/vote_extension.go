@@ -0,0 +1,132 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Finschia/ostracon/crypto"
+	tmbytes "github.com/Finschia/ostracon/libs/bytes"
+	"github.com/Finschia/ostracon/libs/protoio"
+	ocproto "github.com/Finschia/ostracon/proto/ostracon/types"
+)
+
+// MaxVoteExtensionSize is the maximum size of the extension the application
+// adds to a precommit.
+const MaxVoteExtensionSize = 64 * 1024
+
+var ErrVoteExtensionInvalidSignature = errors.New("invalid vote extension signature")
+
+// VoteExtension is the data the application of a validator added to its
+// precommit for a block, signed by the validator and gossiped to the other
+// validators apart from the precommit.
+type VoteExtension struct {
+	Height           int64            `json:"height"`
+	BlockHash        tmbytes.HexBytes `json:"block_hash"`
+	ValidatorAddress Address          `json:"validator_address"`
+	Extension        []byte           `json:"extension"`
+	Signature        []byte           `json:"signature"`
+}
+
+// VoteExtensionSignBytes returns the proto-encoding of the canonicalized
+// VoteExtension, for signing. Panics is the marshaling fails.
+//
+// See CanonicalizeVoteExtension
+func VoteExtensionSignBytes(chainID string, ext *ocproto.VoteExtension) []byte {
+	pb := CanonicalizeVoteExtension(chainID, ext)
+	bz, err := protoio.MarshalDelimited(&pb)
+	if err != nil {
+		panic(err)
+	}
+
+	return bz
+}
+
+// Verify returns an error if the extension isn't signed by the key.
+func (ext *VoteExtension) Verify(chainID string, pubKey crypto.PubKey) error {
+	if !bytes.Equal(pubKey.Address(), ext.ValidatorAddress) {
+		return ErrVoteInvalidValidatorAddress
+	}
+	if !pubKey.VerifySignature(VoteExtensionSignBytes(chainID, ext.ToProto()), ext.Signature) {
+		return ErrVoteExtensionInvalidSignature
+	}
+	return nil
+}
+
+// ValidateBasic performs basic validation.
+func (ext *VoteExtension) ValidateBasic() error {
+	if ext.Height <= 0 {
+		return errors.New("non positive Height")
+	}
+	if len(ext.BlockHash) == 0 {
+		return errors.New("missing BlockHash")
+	}
+	if err := ValidateHash(ext.BlockHash); err != nil {
+		return fmt.Errorf("wrong BlockHash: %w", err)
+	}
+	if len(ext.ValidatorAddress) != crypto.AddressSize {
+		return fmt.Errorf("expected ValidatorAddress size to be %d bytes, got %d bytes",
+			crypto.AddressSize,
+			len(ext.ValidatorAddress),
+		)
+	}
+	if len(ext.Extension) == 0 {
+		return errors.New("empty Extension")
+	}
+	if len(ext.Extension) > MaxVoteExtensionSize {
+		return fmt.Errorf("extension is too big %d (max: %d)", len(ext.Extension), MaxVoteExtensionSize)
+	}
+	if len(ext.Signature) == 0 {
+		return errors.New("signature is missing")
+	}
+	if len(ext.Signature) > MaxSignatureSize {
+		return fmt.Errorf("signature is too big %d (max: %d)", len(ext.Signature), MaxSignatureSize)
+	}
+	return nil
+}
+
+// String returns a string representation of VoteExtension.
+func (ext *VoteExtension) String() string {
+	if ext == nil {
+		return "nil-VoteExtension"
+	}
+	return fmt.Sprintf("VoteExtension{%X %d %X %dB %X}",
+		tmbytes.Fingerprint(ext.ValidatorAddress),
+		ext.Height,
+		tmbytes.Fingerprint(ext.BlockHash),
+		len(ext.Extension),
+		tmbytes.Fingerprint(ext.Signature),
+	)
+}
+
+// ToProto converts the handwritten type to proto generated type
+func (ext *VoteExtension) ToProto() *ocproto.VoteExtension {
+	if ext == nil {
+		return nil
+	}
+
+	return &ocproto.VoteExtension{
+		Height:           ext.Height,
+		BlockHash:        ext.BlockHash,
+		ValidatorAddress: ext.ValidatorAddress,
+		Extension:        ext.Extension,
+		Signature:        ext.Signature,
+	}
+}
+
+// VoteExtensionFromProto converts a proto generated type to a handwritten
+// type, returning an error if it's invalid.
+func VoteExtensionFromProto(pb *ocproto.VoteExtension) (*VoteExtension, error) {
+	if pb == nil {
+		return nil, errors.New("nil vote extension")
+	}
+
+	ext := &VoteExtension{
+		Height:           pb.Height,
+		BlockHash:        pb.BlockHash,
+		ValidatorAddress: pb.ValidatorAddress,
+		Extension:        pb.Extension,
+		Signature:        pb.Signature,
+	}
+	return ext, ext.ValidateBasic()
+}
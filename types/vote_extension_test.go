@@ -0,0 +1,84 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Finschia/ostracon/crypto/ed25519"
+	"github.com/Finschia/ostracon/crypto/tmhash"
+)
+
+func exampleVoteExtension(t *testing.T, privVal MockPV) *VoteExtension {
+	pubKey, err := privVal.GetPubKey()
+	require.NoError(t, err)
+	ext := &VoteExtension{
+		Height:           12345,
+		BlockHash:        tmhash.Sum([]byte("blockID_hash")),
+		ValidatorAddress: pubKey.Address(),
+		Extension:        []byte("extension"),
+	}
+	pb := ext.ToProto()
+	require.NoError(t, privVal.SignVoteExtension("test_chain_id", pb))
+	ext.Signature = pb.Signature
+	return ext
+}
+
+func TestVoteExtensionVerify(t *testing.T) {
+	privVal := NewMockPV()
+	pubKey, err := privVal.GetPubKey()
+	require.NoError(t, err)
+	ext := exampleVoteExtension(t, privVal)
+
+	assert.NoError(t, ext.Verify("test_chain_id", pubKey))
+	assert.Equal(t, ErrVoteInvalidValidatorAddress, ext.Verify("test_chain_id", ed25519.GenPrivKey().PubKey()))
+	assert.Equal(t, ErrVoteExtensionInvalidSignature, ext.Verify("other_chain_id", pubKey))
+
+	// the extension and the block are signed
+	modified := *ext
+	modified.Extension = []byte("modified")
+	assert.Equal(t, ErrVoteExtensionInvalidSignature, modified.Verify("test_chain_id", pubKey))
+	modified = *ext
+	modified.BlockHash = tmhash.Sum([]byte("other block"))
+	assert.Equal(t, ErrVoteExtensionInvalidSignature, modified.Verify("test_chain_id", pubKey))
+}
+
+func TestVoteExtensionValidateBasic(t *testing.T) {
+	privVal := NewMockPV()
+
+	testCases := []struct {
+		testName    string
+		malleateExt func(*VoteExtension)
+		expectErr   bool
+	}{
+		{"Good VoteExtension", func(e *VoteExtension) {}, false},
+		{"Zero Height", func(e *VoteExtension) { e.Height = 0 }, true},
+		{"Missing BlockHash", func(e *VoteExtension) { e.BlockHash = nil }, true},
+		{"Invalid BlockHash", func(e *VoteExtension) { e.BlockHash = []byte{1, 2, 3} }, true},
+		{"Invalid Address", func(e *VoteExtension) { e.ValidatorAddress = make([]byte, 1) }, true},
+		{"Empty Extension", func(e *VoteExtension) { e.Extension = nil }, true},
+		{"Too big Extension", func(e *VoteExtension) { e.Extension = make([]byte, MaxVoteExtensionSize+1) }, true},
+		{"Invalid Signature", func(e *VoteExtension) { e.Signature = nil }, true},
+		{"Too big Signature", func(e *VoteExtension) { e.Signature = make([]byte, MaxSignatureSize+1) }, true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			ext := exampleVoteExtension(t, privVal)
+			tc.malleateExt(ext)
+			assert.Equal(t, tc.expectErr, ext.ValidateBasic() != nil, "Validate Basic had an unexpected result")
+		})
+	}
+}
+
+func TestVoteExtensionProtobuf(t *testing.T) {
+	ext := exampleVoteExtension(t, NewMockPV())
+
+	got, err := VoteExtensionFromProto(ext.ToProto())
+	require.NoError(t, err)
+	assert.Equal(t, ext, got)
+
+	_, err = VoteExtensionFromProto(nil)
+	assert.Error(t, err)
+}
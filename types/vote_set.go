@@ -164,6 +164,25 @@ func (voteSet *VoteSet) AddVote(vote *Vote) (added bool, err error) {
 	return voteSet.addVote(vote, vote.Verify)
 }
 
+// AddVerifiedVote adds the vote like AddVote, but doesn't verify its signature
+// again if it was verified beforehand (e.g. by a pool of workers) with pubKey,
+// the public key of its validator in the set. The signature is verified if
+// pubKey is nil or another key.
+func (voteSet *VoteSet) AddVerifiedVote(vote *Vote, pubKey crypto.PubKey) (added bool, err error) {
+	if voteSet == nil {
+		panic("AddVerifiedVote() on nil VoteSet")
+	}
+	voteSet.mtx.Lock()
+	defer voteSet.mtx.Unlock()
+
+	return voteSet.addVote(vote, func(chainID string, pub crypto.PubKey) error {
+		if pubKey != nil && pubKey.Equals(pub) {
+			return nil
+		}
+		return vote.Verify(chainID, pub)
+	})
+}
+
 // NOTE: Validates as much as possible before attempting to verify the signature.
 func (voteSet *VoteSet) addVote(vote *Vote, execVoteVerify func(chainID string,
 	pub crypto.PubKey) (err error)) (added bool, err error) {
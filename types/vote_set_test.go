@@ -121,6 +121,40 @@ func TestVoteSet_AddVote_Bad(t *testing.T) {
 	}
 }
 
+func TestVoteSet_AddVerifiedVote(t *testing.T) {
+	height, round := int64(1), int32(0)
+	voteSet, _, privValidators := randVoteSet(height, round, tmproto.PrevoteType, 10, 1)
+	pubKeys := make([]crypto.PubKey, 2)
+	for i := range pubKeys {
+		pubKey, err := privValidators[i].GetPubKey()
+		require.NoError(t, err)
+		pubKeys[i] = pubKey
+	}
+
+	vote := &Vote{
+		ValidatorAddress: pubKeys[0].Address(),
+		ValidatorIndex:   0,
+		Height:           height,
+		Round:            round,
+		Type:             tmproto.PrevoteType,
+		Timestamp:        tmtime.Now(),
+		BlockID:          BlockID{nil, PartSetHeader{}},
+		Signature:        []byte("not verified"),
+	}
+
+	// the signature is verified without a key, or with another key than the
+	// one of the validator
+	_, err := voteSet.AddVerifiedVote(vote, nil)
+	assert.ErrorIs(t, err, ErrVoteInvalidSignature)
+	_, err = voteSet.AddVerifiedVote(vote, pubKeys[1])
+	assert.ErrorIs(t, err, ErrVoteInvalidSignature)
+
+	// but not with the key of the validator, as it was verified with it
+	added, err := voteSet.AddVerifiedVote(vote, pubKeys[0])
+	require.NoError(t, err)
+	assert.True(t, added)
+}
+
 func TestVoteSet_List(t *testing.T) {
 	height, round := int64(1), int32(0)
 	voteSet, _, privVals := randVoteSet(height, round, tmproto.PrevoteType, 10, 1)